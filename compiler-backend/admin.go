@@ -0,0 +1,225 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ──────────────────────── Configuración en marcha ────────────────────────
+//
+// En medio de la clase el docente puede necesitar cambiar la configuración
+// sin reiniciar el servidor (y sin cortar las sesiones abiertas): apagar la
+// ejecución real, alargar el timeout para un ejercicio o ejecutar solo el
+// lenguaje del día. GET /api/v1/admin/config devuelve los valores que se
+// pueden cambiar y PUT actualiza los que se envíen; solo lo atienden los
+// usuarios admin (ver auth.go). Los cambios no se guardan: al reiniciar
+// vuelven a regir las variables de entorno.
+
+// configMu protege los campos de GlobalConfig que cambia PUT
+// /api/v1/admin/config; el resto se fija al iniciar y se lee sin bloqueo
+var configMu sync.RWMutex
+
+// currentConfig devuelve una copia de GlobalConfig; la usan quienes leen
+// los campos que se pueden cambiar en marcha
+func currentConfig() CompilerConfig {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	return GlobalConfig
+}
+
+// languageAllowed indica si c permite ejecutar language
+func (c CompilerConfig) languageAllowed(language string) bool {
+	if len(c.AllowedLanguages) == 0 {
+		return true
+	}
+	for _, lang := range c.AllowedLanguages {
+		if lang == language {
+			return true
+		}
+	}
+	return false
+}
+
+// runtimeKey representa en la clave de la caché los campos que se pueden
+// cambiar en marcha y cambian el resultado de un análisis
+func (c CompilerConfig) runtimeKey() string {
+	languages := append([]string(nil), c.AllowedLanguages...)
+	sort.Strings(languages)
+	policy := make([]string, 0, len(c.SecurityPolicy))
+	for code, action := range c.SecurityPolicy {
+		policy = append(policy, code+"="+action)
+	}
+	sort.Strings(policy)
+	return strings.Join([]string{
+		strconv.FormatBool(c.EnableRealExecution),
+		c.ExecutionTimeout.String(),
+		strings.Join(languages, ","),
+		strconv.Itoa(c.MaxErrors),
+		strings.Join(policy, ","),
+	}, "|")
+}
+
+// APIAdminConfig son los valores de GlobalConfig que se pueden cambiar en
+// marcha. En PUT los campos omitidos no cambian; allowedLanguages vacío
+// permite todos y securityPolicy cambia solo las reglas indicadas
+type APIAdminConfig struct {
+	EnableRealExecution        *bool             `json:"enableRealExecution,omitempty"`
+	ExecutionTimeoutSeconds    *int              `json:"executionTimeoutSeconds,omitempty"`
+	MaxExecutionTimeoutSeconds *int              `json:"maxExecutionTimeoutSeconds,omitempty"`
+	AllowedLanguages           *[]string         `json:"allowedLanguages,omitempty"`
+	MaxErrors                  *int              `json:"maxErrors,omitempty"`
+	DefaultLocale              *string           `json:"defaultLocale,omitempty"`
+	SecurityPolicy             map[string]string `json:"securityPolicy,omitempty"`
+}
+
+// adminConfigOf devuelve los valores de c que se pueden cambiar
+func adminConfigOf(c CompilerConfig) APIAdminConfig {
+	timeout := int(c.ExecutionTimeout / time.Second)
+	maxTimeout := int(c.MaxExecutionTimeout / time.Second)
+	languages := append([]string{}, c.AllowedLanguages...)
+	policy := make(map[string]string, len(c.SecurityPolicy))
+	for code, action := range c.SecurityPolicy {
+		policy[code] = action
+	}
+	return APIAdminConfig{
+		EnableRealExecution:        &c.EnableRealExecution,
+		ExecutionTimeoutSeconds:    &timeout,
+		MaxExecutionTimeoutSeconds: &maxTimeout,
+		AllowedLanguages:           &languages,
+		MaxErrors:                  &c.MaxErrors,
+		DefaultLocale:              &c.DefaultLocale,
+		SecurityPolicy:             policy,
+	}
+}
+
+// apply aplica u sobre c y devuelve el motivo por el que no es válido, o ""
+func (u APIAdminConfig) apply(c *CompilerConfig) string {
+	if u.EnableRealExecution != nil {
+		c.EnableRealExecution = *u.EnableRealExecution
+	}
+	if u.ExecutionTimeoutSeconds != nil {
+		if *u.ExecutionTimeoutSeconds <= 0 {
+			return "executionTimeoutSeconds must be positive"
+		}
+		c.ExecutionTimeout = time.Duration(*u.ExecutionTimeoutSeconds) * time.Second
+	}
+	if u.MaxExecutionTimeoutSeconds != nil {
+		if *u.MaxExecutionTimeoutSeconds <= 0 {
+			return "maxExecutionTimeoutSeconds must be positive"
+		}
+		c.MaxExecutionTimeout = time.Duration(*u.MaxExecutionTimeoutSeconds) * time.Second
+	}
+	if c.MaxExecutionTimeout < c.ExecutionTimeout {
+		return "maxExecutionTimeoutSeconds must not be less than executionTimeoutSeconds"
+	}
+	if u.AllowedLanguages != nil {
+		languages := []string{}
+		for _, name := range *u.AllowedLanguages {
+			lang := mapLanguage(name)
+			if !cliLanguage(lang) {
+				return "allowedLanguages: unknown language " + name
+			}
+			languages = append(languages, lang)
+		}
+		c.AllowedLanguages = languages
+	}
+	if u.MaxErrors != nil {
+		if *u.MaxErrors < 0 {
+			return "maxErrors must be positive"
+		}
+		c.MaxErrors = *u.MaxErrors
+	}
+	if u.DefaultLocale != nil {
+		locale := normalizeLocale(*u.DefaultLocale)
+		if locale == "" {
+			return invalidLocale(*u.DefaultLocale)
+		}
+		c.DefaultLocale = locale
+	}
+	if len(u.SecurityPolicy) > 0 {
+		// La política vigente no se modifica: quien la leyó puede seguir usándola
+		policy := make(SecurityPolicyConfig, len(c.SecurityPolicy))
+		for code, action := range c.SecurityPolicy {
+			policy[code] = action
+		}
+		for name, action := range u.SecurityPolicy {
+			code, ok := diagnosticCode(name)
+			if _, isRule := policy[code]; !ok || !isRule {
+				return "securityPolicy: unknown rule " + name
+			}
+			if action != PolicyBlock && action != PolicyWarn && action != PolicyOff {
+				return "securityPolicy: action must be block, warn or off"
+			}
+			policy[code] = action
+		}
+		c.SecurityPolicy = policy
+	}
+	return ""
+}
+
+// updateConfig aplica u a GlobalConfig y devuelve la configuración
+// resultante. Solo se escriben los campos que cambia u, los únicos que se
+// leen con currentConfig
+func updateConfig(u APIAdminConfig) (CompilerConfig, string) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	c := GlobalConfig
+	if msg := u.apply(&c); msg != "" {
+		return GlobalConfig, msg
+	}
+	GlobalConfig.EnableRealExecution = c.EnableRealExecution
+	GlobalConfig.ExecutionTimeout = c.ExecutionTimeout
+	GlobalConfig.MaxExecutionTimeout = c.MaxExecutionTimeout
+	GlobalConfig.AllowedLanguages = c.AllowedLanguages
+	GlobalConfig.MaxErrors = c.MaxErrors
+	GlobalConfig.DefaultLocale = c.DefaultLocale
+	GlobalConfig.SecurityPolicy = c.SecurityPolicy
+	return c, ""
+}
+
+// adminConfigHandler atiende GET y PUT /api/v1/admin/config
+func adminConfigHandler(w http.ResponseWriter, r *http.Request) {
+	p := principalFrom(r)
+	if p == nil {
+		httpError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+	if !p.Admin {
+		httpError(w, "Admin privileges required", http.StatusForbidden)
+		return
+	}
+
+	var config CompilerConfig
+	switch r.Method {
+	case http.MethodGet:
+		config = currentConfig()
+	case http.MethodPut:
+		var update APIAdminConfig
+		dec := json.NewDecoder(r.Body)
+		// Un campo mal escrito no debe pasar por un cambio aplicado
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&update); err != nil {
+			invalidJSON(w)
+			return
+		}
+		var msg string
+		if config, msg = updateConfig(update); msg != "" {
+			httpError(w, msg, http.StatusBadRequest)
+			return
+		}
+		changes, _ := json.Marshal(update)
+		log.Printf("admin: %s cambió la configuración: %s", p.User, changes)
+	default:
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(adminConfigOf(config))
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestAdminConfig comprueba que solo un administrador cambia la
+// configuración y que un PUT con algún valor inválido no aplica ninguno
+func TestAdminConfig(t *testing.T) {
+	saved := GlobalConfig
+	defer func() { GlobalConfig = saved }()
+	admin := &Principal{Account: "key:admin", User: "admin", Admin: true}
+	ana := &Principal{Account: "key:ana", User: "ana"}
+
+	cases := []struct {
+		name      string
+		principal *Principal
+		method    string
+		body      string
+		status    int
+	}{
+		{"sin_autenticar", nil, http.MethodGet, "", http.StatusUnauthorized},
+		{"no_admin", ana, http.MethodPut, `{"executionTimeoutSeconds": 20}`, http.StatusForbidden},
+		{"leer", admin, http.MethodGet, "", http.StatusOK},
+		{"valido", admin, http.MethodPut, `{"executionTimeoutSeconds": 20, "allowedLanguages": ["py"], "securityPolicy": {"network-access": "warn"}}`, http.StatusOK},
+		{"lenguaje_desconocido", admin, http.MethodPut, `{"executionTimeoutSeconds": 20, "allowedLanguages": ["pyhton"]}`, http.StatusBadRequest},
+		{"campo_desconocido", admin, http.MethodPut, `{"executionTimeout": 20}`, http.StatusBadRequest},
+		{"mayor_que_el_maximo", admin, http.MethodPut, `{"executionTimeoutSeconds": 60}`, http.StatusBadRequest},
+		{"timeout_negativo", admin, http.MethodPut, `{"executionTimeoutSeconds": -1}`, http.StatusBadRequest},
+		{"regla_desconocida", admin, http.MethodPut, `{"executionTimeoutSeconds": 20, "securityPolicy": {"sin-regla": "warn"}}`, http.StatusBadRequest},
+		{"accion_invalida", admin, http.MethodPut, `{"securityPolicy": {"network-access": "permitir"}}`, http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			GlobalConfig.ExecutionTimeout = 10 * time.Second
+			GlobalConfig.MaxExecutionTimeout = 30 * time.Second
+			GlobalConfig.AllowedLanguages = nil
+			GlobalConfig.SecurityPolicy = defaultSecurityPolicy()
+			before := GlobalConfig.runtimeKey()
+
+			r := httptest.NewRequest(c.method, apiPrefix+"/admin/config", strings.NewReader(c.body))
+			w := httptest.NewRecorder()
+			adminConfigHandler(w, withPrincipal(r, c.principal))
+			if w.Code != c.status {
+				t.Fatalf("estado %d, esperado %d: %s", w.Code, c.status, w.Body.String())
+			}
+			if c.method != http.MethodPut || c.status != http.StatusOK {
+				if after := GlobalConfig.runtimeKey(); after != before {
+					t.Errorf("la configuración cambió: %s, antes %s", after, before)
+				}
+				return
+			}
+			if GlobalConfig.ExecutionTimeout != 20*time.Second {
+				t.Errorf("timeout %v, esperado 20s", GlobalConfig.ExecutionTimeout)
+			}
+			if !slices.Equal(GlobalConfig.AllowedLanguages, []string{"python"}) {
+				t.Errorf("lenguajes %v, esperado [python]", GlobalConfig.AllowedLanguages)
+			}
+			if GlobalConfig.SecurityPolicy[CodeNetworkAccess] != PolicyWarn || GlobalConfig.SecurityPolicy[CodeShellCommand] != PolicyBlock {
+				t.Errorf("política %v", GlobalConfig.SecurityPolicy)
+			}
+		})
+	}
+}
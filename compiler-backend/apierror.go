@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ───────────────────────────── Errores de la API ─────────────────────────
+//
+// Todas las respuestas de error tienen la misma forma, para que el frontend
+// pueda mostrar el mensaje sin adivinar si llegó texto o JSON:
+//
+//	{ "code": "invalid_json", "message": "Invalid JSON", "requestId": "3f9c2a7d41b8" }
+//
+// code es estable y sirve para decidir qué hacer; message es para personas
+// y puede cambiar; details agrega datos del error cuando los hay (el límite
+// superado, los segundos que hay que esperar). requestId es el X-Request-ID
+// de la petición, que assignRequestID fija antes que cualquier handler, para
+// buscarla en el registro del servidor.
+
+// APIError es el cuerpo de todas las respuestas de error
+type APIError struct {
+	Code      string                 `json:"code"`
+	Message   string                 `json:"message"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	RequestID string                 `json:"requestId,omitempty"`
+}
+
+// errorCodes es el code de cada estado cuando no hay uno más preciso
+var errorCodes = map[int]string{
+	http.StatusBadRequest:            "invalid_request",
+	http.StatusUnauthorized:          "unauthorized",
+	http.StatusForbidden:             "forbidden",
+	http.StatusNotFound:              "not_found",
+	http.StatusMethodNotAllowed:      "method_not_allowed",
+	http.StatusConflict:              "conflict",
+	http.StatusRequestEntityTooLarge: "request_too_large",
+	http.StatusTooManyRequests:       "rate_limited",
+	http.StatusInternalServerError:   "internal_error",
+	http.StatusBadGateway:            "bad_gateway",
+	http.StatusServiceUnavailable:    "unavailable",
+}
+
+// writeAPIErrorDetails responde status con un APIError
+func writeAPIErrorDetails(w http.ResponseWriter, status int, code, message string, details map[string]interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(APIError{
+		Code:      code,
+		Message:   message,
+		Details:   details,
+		RequestID: w.Header().Get(requestIDHeader),
+	})
+}
+
+// writeAPIError responde status con un APIError sin detalles
+func writeAPIError(w http.ResponseWriter, status int, code, message string) {
+	writeAPIErrorDetails(w, status, code, message, nil)
+}
+
+// httpError reemplaza a http.Error: el code sale del estado
+func httpError(w http.ResponseWriter, message string, status int) {
+	code, ok := errorCodes[status]
+	if !ok {
+		code = "error"
+	}
+	writeAPIError(w, status, code, message)
+}
+
+// invalidJSON responde que el cuerpo no es el JSON que esperaba el handler
+func invalidJSON(w http.ResponseWriter) {
+	writeAPIError(w, http.StatusBadRequest, "invalid_json", "Invalid JSON")
+}
+
+// notFoundHandler atiende las rutas que no existen
+func notFoundHandler(w http.ResponseWriter, r *http.Request) {
+	httpError(w, "Not found", http.StatusNotFound)
+}
+
+// assignRequestID fija el X-Request-ID de la petición antes que cualquier
+// handler, generándolo si no vino, y lo devuelve en la respuesta: así los
+// errores lo incluyen aunque el handler no llegue a leerlo. Uno inválido se
+// deja como está para que el handler lo rechace
+func assignRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if id == "" {
+			id = randomSuffix()
+			r.Header.Set(requestIDHeader, id)
+		}
+		if requestIDPattern.MatchString(id) {
+			w.Header().Set(requestIDHeader, id)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ───────────────────────────── Perfiles de tareas ─────────────────────────────
+//
+// ASSIGNMENT_PROFILES apunta a un archivo JSON o YAML (ver customrules.go)
+// con los requisitos de cada tarea, y una petición elige el suyo con
+// assignmentProfile:
+//
+//	profiles:
+//	  factorial:
+//	    languages: [python, cpp]
+//	    bannedFunctions: [eval, exec, math.factorial]
+//	    requiredFunctions: ["factorial(n)"]
+//	    compare: tokens
+//	    testCases:
+//	      - stdin: "5\n"
+//	        expectedOutput: "120"
+//	        points: 2
+//
+// El análisis semántico reporta como errores ASG lo que la entrega no
+// cumple: un lenguaje no permitido, cada llamada a una función prohibida y
+// cada función pedida que falta o no recibe esa cantidad de parámetros. Los
+// casos de prueba del perfil se ejecutan como los testCases de la petición,
+// que entonces no puede enviar los suyos. Como las reglas del curso, ni la
+// petición ni un comentario desactivan estos diagnósticos.
+
+type AssignmentProfile struct {
+	Name      string   `json:"-"`
+	Languages []string `json:"languages"`
+	// Funciones que no se pueden llamar; "sort" también prohíbe v.sort() y
+	// std::sort
+	BannedFunctions []string `json:"bannedFunctions"`
+	// Funciones que se deben definir: "factorial" o, para exigir también la
+	// cantidad de parámetros, "factorial(n)"
+	RequiredFunctions []string   `json:"requiredFunctions"`
+	TestCases         []TestCase `json:"testCases"`
+	Compare           string     `json:"compare"`
+	Tolerance         float64    `json:"tolerance"`
+
+	required []requiredFunction
+}
+
+type requiredFunction struct {
+	name      string
+	signature string
+	// -1 si no se exige una cantidad
+	params int
+}
+
+type assignmentProfilesFile struct {
+	Profiles map[string]*AssignmentProfile `json:"profiles"`
+}
+
+var requiredSignature = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*(?:\(([^()]*)\))?$`)
+
+// loadAssignmentProfiles lee y valida el archivo de perfiles path
+func loadAssignmentProfiles(path string) (map[string]*AssignmentProfile, error) {
+	var file assignmentProfilesFile
+	if err := decodeConfigFile(path, &file); err != nil {
+		return nil, err
+	}
+	if err := compileAssignmentProfiles(file.Profiles); err != nil {
+		return nil, err
+	}
+	return file.Profiles, nil
+}
+
+// compileAssignmentProfiles valida los perfiles por nombre, del archivo de
+// perfiles o de un espacio de trabajo (ver workspaces.go)
+func compileAssignmentProfiles(profiles map[string]*AssignmentProfile) error {
+	for name, profile := range profiles {
+		if profile == nil {
+			return fmt.Errorf("profile %s is empty", name)
+		}
+		profile.Name = name
+		if err := profile.compile(); err != nil {
+			return fmt.Errorf("profile %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// compile valida el perfil y separa el nombre y los parámetros de cada
+// función pedida
+func (p *AssignmentProfile) compile() error {
+	for i, lang := range p.Languages {
+		if p.Languages[i] = mapLanguage(lang); p.Languages[i] == "" {
+			return fmt.Errorf("unknown language %s", lang)
+		}
+	}
+	for _, fn := range p.RequiredFunctions {
+		m := requiredSignature.FindStringSubmatch(strings.TrimSpace(fn))
+		if m == nil {
+			return fmt.Errorf("invalid required function %q", fn)
+		}
+		req := requiredFunction{name: m[1], signature: strings.TrimSpace(fn), params: -1}
+		if strings.Contains(fn, "(") {
+			req.params = 0
+			if params := strings.TrimSpace(m[2]); params != "" {
+				req.params = strings.Count(params, ",") + 1
+			}
+		}
+		p.required = append(p.required, req)
+	}
+	if msg := invalidJudge(p.Compare, p.Tolerance); msg != "" {
+		return fmt.Errorf("%s", msg)
+	}
+	if msg := invalidTestCases(p.TestCases); msg != "" {
+		return fmt.Errorf("%s", msg)
+	}
+	return nil
+}
+
+// checkAssignmentProfile devuelve los requisitos de profile que no cumple
+// el programa
+func checkAssignmentProfile(profile *AssignmentProfile, language string, tree []ParseNode) []CompilerError {
+	if profile == nil {
+		return nil
+	}
+	var errors []CompilerError
+	report := func(pos int, code, format string, args ...interface{}) {
+		errors = append(errors, CompilerError{
+			Message:  "Error semántico: " + fmt.Sprintf(format, args...),
+			Severity: "error",
+			Type:     "semantico",
+			Pos:      pos,
+			Code:     code,
+		})
+	}
+
+	if len(profile.Languages) > 0 && !containsLanguage(profile.Languages, language) {
+		report(0, CodeLanguageNotAllowed, "La tarea '%s' se entrega en %s, no en %s",
+			profile.Name, strings.Join(profile.Languages, ", "), language)
+	}
+
+	defined := map[string]ParseNode{}
+	for _, root := range tree {
+		collectFunctions(root, defined)
+	}
+	for _, req := range profile.required {
+		fn, ok := defined[req.name]
+		if !ok {
+			report(0, CodeMissingFunction, "La tarea '%s' pide definir la función %s", profile.Name, req.signature)
+			continue
+		}
+		if n := functionParamCount(fn); req.params >= 0 && n != req.params {
+			report(fn.Pos, CodeMissingFunction, "La función '%s' debe recibir %d parámetro(s), como en %s, pero recibe %d",
+				req.name, req.params, req.signature, n)
+		}
+	}
+
+	if len(profile.BannedFunctions) > 0 {
+		for _, root := range tree {
+			walkNodes(root, func(n ParseNode) {
+				if n.Kind != "Call" {
+					return
+				}
+				if name, banned := bannedCall(calleeName(n), profile.BannedFunctions); banned {
+					report(n.Pos, CodeBannedFunction, "La tarea '%s' no permite usar '%s'", profile.Name, name)
+				}
+			})
+		}
+	}
+	return errors
+}
+
+// collectFunctions guarda las funciones y métodos con nombre definidos en n,
+// incluidas las expresiones de función asignadas (const f = (n) => ...)
+func collectFunctions(n ParseNode, defined map[string]ParseNode) {
+	switch n.Kind {
+	case "FunctionDecl", "Method":
+		if _, seen := defined[n.Label]; n.Label != "" && !seen {
+			defined[n.Label] = n
+		}
+	case "VarDecl":
+		for _, c := range n.Children {
+			if _, seen := defined[n.Label]; (c.Kind == "ArrowFunction" || c.Kind == "FunctionDecl") && !seen {
+				c.Pos = n.Pos
+				defined[n.Label] = c
+			}
+		}
+	}
+	for _, c := range n.Children {
+		collectFunctions(c, defined)
+	}
+}
+
+func functionParamCount(fn ParseNode) int {
+	for _, c := range fn.Children {
+		if c.Kind == "Params" {
+			return len(c.Children)
+		}
+	}
+	return 0
+}
+
+// bannedCall indica si la función llamada name está en banned, sola o
+// detrás de un objeto o espacio de nombres
+func bannedCall(name string, banned []string) (string, bool) {
+	for _, b := range banned {
+		if name == b || strings.HasSuffix(name, "."+b) || strings.HasSuffix(name, "::"+b) {
+			return b, true
+		}
+	}
+	return "", false
+}
+
+func walkNodes(n ParseNode, visit func(ParseNode)) {
+	visit(n)
+	for _, c := range n.Children {
+		walkNodes(c, visit)
+	}
+}
@@ -0,0 +1,598 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ───────────────────────────── Autenticación ─────────────────────────────
+//
+// El servidor de la clase es compartido: cada petición puede identificar a
+// su usuario con una clave de API (Authorization: Bearer cb_... o
+// X-API-Key) o con un token JWT firmado con HS256 y JWT_SECRET por el
+// sistema del curso. Las claves se crean con
+//
+//   compiler-backend keys create --user ana --minutes 30 --languages cpp,python
+//
+// y se guardan (solo su hash) en la misma base SQLite que el historial,
+// junto con el tiempo de ejecución consumido por día. Cada usuario tiene una
+// cuota de minutos de ejecución por día (UTC) y puede tener restringidos los
+// lenguajes; un token JWT los indica con los claims dailyMinutes y
+// languages, y con admin los usuarios que administran el servidor. Sin credenciales la petición es anónima y no tiene cuota,
+// salvo con AUTH_REQUIRED, que la rechaza con 401. Unas credenciales
+// inválidas siempre se rechazan.
+
+// Principal es el usuario autenticado de una petición
+type Principal struct {
+	// "key:<id>" o "jwt:<sub>": identifica al usuario en el consumo diario
+	Account string `json:"account"`
+	User    string `json:"user"`
+	// Minutos de ejecución por día; 0 sin límite
+	DailyMinutes float64 `json:"dailyMinutes,omitempty"`
+	// Lenguajes permitidos; vacío permite todos
+	Languages []string `json:"languages,omitempty"`
+	// Puede consultar y cambiar la configuración (ver admin.go)
+	Admin bool `json:"admin,omitempty"`
+	// Espacio de trabajo de la petición (ver workspaces.go); vacío fuera de
+	// uno
+	Workspace string `json:"workspace,omitempty"`
+
+	workspace *Workspace
+	// Dentro de un espacio, el mismo usuario fuera de él: su cuota personal
+	// también se descuenta
+	personal *Principal
+}
+
+const authSchema = `
+CREATE TABLE IF NOT EXISTS api_keys (
+	id            TEXT    PRIMARY KEY,
+	key_hash      TEXT    NOT NULL UNIQUE,
+	user          TEXT    NOT NULL,
+	daily_minutes REAL    NOT NULL,
+	languages     TEXT    NOT NULL,
+	admin         INTEGER NOT NULL DEFAULT 0,
+	created_at    INTEGER NOT NULL,
+	revoked_at    INTEGER
+);
+CREATE TABLE IF NOT EXISTS execution_usage (
+	account      TEXT    NOT NULL,
+	day          TEXT    NOT NULL,
+	execution_ms INTEGER NOT NULL,
+	PRIMARY KEY (account, day)
+);`
+
+// Prefijo de las claves de API y bytes aleatorios del id con el que se
+// listan y revocan. El id se genera aparte: si fuera el comienzo de la clave,
+// keys list y los logs revelarían parte del secreto
+const (
+	apiKeyPrefix  = "cb_"
+	apiKeyIDBytes = 4
+)
+
+var (
+	errInvalidCredentials = errors.New("invalid credentials")
+	errAuthUnavailable    = errors.New("api keys require HISTORY_DB")
+)
+
+type authStore struct {
+	db *sql.DB
+}
+
+// auth es nil si no hay base (HISTORY_DB=none): solo se aceptan tokens JWT
+// y el consumo no se registra
+var auth *authStore
+
+// openAuth crea las tablas de claves y consumo en la base db
+func openAuth(db *sql.DB) (*authStore, error) {
+	if _, err := db.Exec(authSchema); err != nil {
+		return nil, err
+	}
+	// Las bases creadas antes de los usuarios admin no tienen la columna
+	_, err := db.Exec("ALTER TABLE api_keys ADD COLUMN admin INTEGER NOT NULL DEFAULT 0")
+	if err != nil && !strings.Contains(err.Error(), "duplicate column") {
+		return nil, err
+	}
+	return &authStore{db: db}, nil
+}
+
+// APIKeyInfo describe una clave sin revelarla
+type APIKeyInfo struct {
+	ID           string     `json:"id"`
+	User         string     `json:"user"`
+	DailyMinutes float64    `json:"dailyMinutes"`
+	Languages    []string   `json:"languages,omitempty"`
+	Admin        bool       `json:"admin,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	RevokedAt    *time.Time `json:"revokedAt,omitempty"`
+}
+
+// hashAPIKey es lo que se guarda de cada clave
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// createKey genera una clave nueva para user y la devuelve; es la única vez
+// que se conoce la clave completa
+func (a *authStore) createKey(user string, minutes float64, languages []string, admin bool) (string, APIKeyInfo, error) {
+	buf := make([]byte, 20+apiKeyIDBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", APIKeyInfo{}, err
+	}
+	key := apiKeyPrefix + hex.EncodeToString(buf[:20])
+	info := APIKeyInfo{
+		ID:           hex.EncodeToString(buf[20:]),
+		User:         user,
+		DailyMinutes: minutes,
+		Languages:    languages,
+		Admin:        admin,
+		CreatedAt:    time.Now().UTC(),
+	}
+	_, err := a.db.Exec(`INSERT INTO api_keys (id, key_hash, user, daily_minutes, languages, admin, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		info.ID, hashAPIKey(key), user, minutes, strings.Join(languages, ","), admin, info.CreatedAt.UnixMilli())
+	return key, info, err
+}
+
+func (a *authStore) listKeys() ([]APIKeyInfo, error) {
+	rows, err := a.db.Query(`SELECT id, user, daily_minutes, languages, admin, created_at, revoked_at
+		FROM api_keys ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var keys []APIKeyInfo
+	for rows.Next() {
+		var k APIKeyInfo
+		var languages string
+		var createdAt int64
+		var revokedAt sql.NullInt64
+		if err := rows.Scan(&k.ID, &k.User, &k.DailyMinutes, &languages, &k.Admin, &createdAt, &revokedAt); err != nil {
+			return nil, err
+		}
+		if k.Languages, err = splitLanguages(languages); err != nil {
+			return nil, fmt.Errorf("key %s: %v", k.ID, err)
+		}
+		k.CreatedAt = time.UnixMilli(createdAt).UTC()
+		if revokedAt.Valid {
+			t := time.UnixMilli(revokedAt.Int64).UTC()
+			k.RevokedAt = &t
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+// revokeKey revoca la clave id; false si no existe o ya estaba revocada
+func (a *authStore) revokeKey(id string) (bool, error) {
+	res, err := a.db.Exec("UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL",
+		time.Now().UnixMilli(), id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// lookupKey devuelve el usuario de una clave vigente
+func (a *authStore) lookupKey(key string) (*Principal, error) {
+	var id, user, languages string
+	var minutes float64
+	var admin bool
+	err := a.db.QueryRow(`SELECT id, user, daily_minutes, languages, admin FROM api_keys
+		WHERE key_hash = ? AND revoked_at IS NULL`, hashAPIKey(key)).Scan(&id, &user, &minutes, &languages, &admin)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	allowed, err := splitLanguages(languages)
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %v", id, err)
+	}
+	return &Principal{Account: "key:" + id, User: user, DailyMinutes: minutes, Languages: allowed, Admin: admin}, nil
+}
+
+// usageDay es el día (UTC) al que se carga una ejecución
+func usageDay(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+// usedToday devuelve el tiempo de ejecución que account consumió hoy
+func (a *authStore) usedToday(account string) (time.Duration, error) {
+	var ms int64
+	err := a.db.QueryRow("SELECT execution_ms FROM execution_usage WHERE account = ? AND day = ?",
+		account, usageDay(time.Now())).Scan(&ms)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return time.Duration(ms) * time.Millisecond, err
+}
+
+func (a *authStore) addUsage(account string, d time.Duration) error {
+	_, err := a.db.Exec(`INSERT INTO execution_usage (account, day, execution_ms) VALUES (?, ?, ?)
+		ON CONFLICT (account, day) DO UPDATE SET execution_ms = execution_ms + excluded.execution_ms`,
+		account, usageDay(time.Now()), d.Milliseconds())
+	return err
+}
+
+// splitLanguages lee una lista de lenguajes separados por comas con los
+// nombres del frontend ("c++", "js") y la devuelve con los del backend. Un
+// nombre desconocido es un error: descartarlo podría dejar la lista vacía,
+// que permite todos los lenguajes
+func splitLanguages(v string) ([]string, error) {
+	var languages []string
+	for _, name := range strings.Split(v, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		lang := mapLanguage(name)
+		if !cliLanguage(lang) {
+			return nil, fmt.Errorf("unknown language %s", name)
+		}
+		languages = append(languages, lang)
+	}
+	return languages, nil
+}
+
+// ───── Tokens JWT ─────
+
+// jwtClaims son los claims que se leen de un token; sub es obligatorio
+type jwtClaims struct {
+	Subject      string   `json:"sub"`
+	Name         string   `json:"name"`
+	ExpiresAt    *int64   `json:"exp"`
+	NotBefore    *int64   `json:"nbf"`
+	DailyMinutes *float64 `json:"dailyMinutes"`
+	Languages    []string `json:"languages"`
+	Admin        bool     `json:"admin"`
+}
+
+// verifyJWT comprueba la firma HS256 de token con secret y su vigencia.
+// Sin el claim dailyMinutes se usa GlobalConfig.AuthDailyMinutes
+func verifyJWT(token string, secret []byte, now time.Time) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || len(secret) == 0 {
+		return nil, errInvalidCredentials
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || json.Unmarshal(raw, &header) != nil || header.Alg != "HS256" {
+		return nil, errInvalidCredentials
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, errInvalidCredentials
+	}
+	var claims jwtClaims
+	raw, err = base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || json.Unmarshal(raw, &claims) != nil || claims.Subject == "" {
+		return nil, errInvalidCredentials
+	}
+	if claims.ExpiresAt != nil && now.Unix() >= *claims.ExpiresAt ||
+		claims.NotBefore != nil && now.Unix() < *claims.NotBefore {
+		return nil, errInvalidCredentials
+	}
+	languages, err := splitLanguages(strings.Join(claims.Languages, ","))
+	if err != nil {
+		log.Printf("auth: token de %s rechazado, claim languages: %v", claims.Subject, err)
+		return nil, errInvalidCredentials
+	}
+	p := &Principal{
+		Account:      "jwt:" + claims.Subject,
+		User:         claims.Subject,
+		DailyMinutes: GlobalConfig.AuthDailyMinutes,
+		Languages:    languages,
+		Admin:        claims.Admin,
+	}
+	if claims.Name != "" {
+		p.User = claims.Name
+	}
+	if claims.DailyMinutes != nil {
+		p.DailyMinutes = *claims.DailyMinutes
+	}
+	return p, nil
+}
+
+// ───── Middleware ─────
+
+type principalKey struct{}
+
+// credentials devuelve la clave o el token de r: Authorization: Bearer,
+// X-API-Key o, solo al abrir un WebSocket (el navegador no permite
+// encabezados), el parámetro access_token
+func credentials(r *http.Request) string {
+	if scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return r.URL.Query().Get("access_token")
+	}
+	return ""
+}
+
+// authenticate identifica al usuario de una clave de API o un token JWT
+func authenticate(token string) (*Principal, error) {
+	if strings.HasPrefix(token, apiKeyPrefix) {
+		if auth == nil {
+			return nil, errAuthUnavailable
+		}
+		return auth.lookupKey(token)
+	}
+	return verifyJWT(token, []byte(GlobalConfig.JWTSecret), time.Now())
+}
+
+// requireAuth identifica al usuario de la petición y su espacio de trabajo
+// y los deja en su contexto (ver principalFrom)
+func requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var p *Principal
+		if token := credentials(r); token != "" {
+			var err error
+			if p, err = authenticate(token); err != nil {
+				if !errors.Is(err, errInvalidCredentials) {
+					log.Printf("auth: no se pudo verificar la credencial: %v", err)
+				}
+				w.Header().Set("WWW-Authenticate", `Bearer realm="compiler-backend", error="invalid_token"`)
+				httpError(w, "Invalid credentials", http.StatusUnauthorized)
+				return
+			}
+		} else if GlobalConfig.AuthRequired {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="compiler-backend"`)
+			httpError(w, "Authentication required", http.StatusUnauthorized)
+			return
+		}
+		if token := workspaceToken(r); token != "" {
+			ws := findWorkspace(token)
+			if ws == nil {
+				httpError(w, "Invalid workspace token", http.StatusUnauthorized)
+				return
+			}
+			p = ws.scope(p)
+		}
+		if p == nil {
+			next(w, r)
+			return
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, p)))
+	}
+}
+
+// principalFrom devuelve el usuario autenticado de r, o nil si es anónima
+func principalFrom(r *http.Request) *Principal {
+	p, _ := r.Context().Value(principalKey{}).(*Principal)
+	return p
+}
+
+// ───── Cuotas ─────
+
+// allowsLanguage indica si p y su espacio de trabajo permiten language; un
+// anónimo puede usar todos
+func (p *Principal) allowsLanguage(language string) bool {
+	if p == nil {
+		return true
+	}
+	if ws := p.workspace; ws != nil && len(ws.Languages) > 0 && !containsLanguage(ws.Languages, language) {
+		return false
+	}
+	return len(p.Languages) == 0 || containsLanguage(p.Languages, language)
+}
+
+// executionUsage devuelve lo que p consumió hoy y su límite diario; el
+// límite es 0 si no tiene cuota o no se registra el consumo
+func (p *Principal) executionUsage() (used, limit time.Duration) {
+	if p == nil || auth == nil {
+		return 0, 0
+	}
+	used, err := auth.usedToday(p.Account)
+	if err != nil {
+		log.Printf("auth: no se pudo leer el consumo de %s: %v", p.Account, err)
+	}
+	return used, time.Duration(p.DailyMinutes * float64(time.Minute))
+}
+
+// quotaExhausted indica si p ya consumió sus minutos de ejecución de hoy o,
+// dentro de un espacio, si los consumió el usuario fuera de él
+func (p *Principal) quotaExhausted() bool {
+	used, limit := p.executionUsage()
+	if limit > 0 && used >= limit {
+		return true
+	}
+	return p != nil && p.personal.quotaExhausted()
+}
+
+// recordExecution carga d al consumo de hoy de p, y al personal del usuario
+// si está dentro de un espacio, sin demorar la respuesta
+func (p *Principal) recordExecution(d time.Duration) {
+	if p == nil || auth == nil {
+		return
+	}
+	go func() {
+		for q := p; q != nil; q = q.personal {
+			if err := auth.addUsage(q.Account, d); err != nil {
+				log.Printf("auth: no se pudo registrar el consumo de %s: %v", q.Account, err)
+			}
+		}
+	}()
+}
+
+// authorizeAnalysis comprueba que p pueda analizar language y, si execute,
+// que le queden minutos de ejecución. Devuelve el código HTTP y el motivo
+// del rechazo, o 0 si se permite
+func authorizeAnalysis(p *Principal, language string, execute bool) (int, string) {
+	if !p.allowsLanguage(language) {
+		return http.StatusForbidden, "Language " + language + " is not allowed for this user"
+	}
+	if execute && p.quotaExhausted() {
+		return http.StatusTooManyRequests, "Daily execution quota exceeded"
+	}
+	return 0, ""
+}
+
+// rejectAnalysis responde el rechazo de authorizeAnalysis; el de la cuota
+// indica cuándo se renueva (medianoche UTC)
+func rejectAnalysis(w http.ResponseWriter, status int, msg string) {
+	if status == http.StatusTooManyRequests {
+		now := time.Now().UTC()
+		midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+		retry := int(midnight.Sub(now).Seconds()) + 1
+		w.Header().Set("Retry-After", strconv.Itoa(retry))
+		writeAPIErrorDetails(w, status, "quota_exceeded", msg, map[string]interface{}{"retryAfterSeconds": retry})
+		return
+	}
+	httpError(w, msg, status)
+}
+
+// Respuesta de GET /api/v1/me
+type APIPrincipalResponse struct {
+	Principal
+	UsedMinutesToday float64 `json:"usedMinutesToday"`
+}
+
+// meHandler devuelve el usuario de la petición y su consumo de hoy
+func meHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	p := principalFrom(r)
+	if p == nil {
+		httpError(w, "Authentication required", http.StatusUnauthorized)
+		return
+	}
+	used, _ := p.executionUsage()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(APIPrincipalResponse{Principal: *p, UsedMinutesToday: used.Minutes()})
+}
+
+// ───── Administración de claves (CLI) ─────
+
+// runKeysCLI atiende `compiler-backend keys create|list|revoke`
+func runKeysCLI(args []string, stdout, stderr io.Writer) int {
+	usage := func() int {
+		name := filepath.Base(os.Args[0])
+		fmt.Fprintf(stderr, "uso: %s keys create --user nombre [--minutes n] [--languages cpp,python] [--admin]\n", name)
+		fmt.Fprintf(stderr, "     %s keys list\n", name)
+		fmt.Fprintf(stderr, "     %s keys revoke id\n", name)
+		return exitUsage
+	}
+	if len(args) == 0 {
+		return usage()
+	}
+	if GlobalConfig.HistoryDB == "none" {
+		fmt.Fprintln(stderr, "las claves de API se guardan en HISTORY_DB, que está desactivada")
+		return exitUsage
+	}
+	h, err := openHistory(GlobalConfig.HistoryDB)
+	if err != nil {
+		fmt.Fprintf(stderr, "no se pudo abrir %s: %v\n", GlobalConfig.HistoryDB, err)
+		return exitUsage
+	}
+	defer h.db.Close()
+	store, err := openAuth(h.db)
+	if err != nil {
+		fmt.Fprintf(stderr, "no se pudo abrir %s: %v\n", GlobalConfig.HistoryDB, err)
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "create":
+		fset := flag.NewFlagSet("keys create", flag.ContinueOnError)
+		fset.SetOutput(stderr)
+		user := fset.String("user", "", "usuario de la clave")
+		minutes := fset.Float64("minutes", GlobalConfig.AuthDailyMinutes, "minutos de ejecución por día (0 sin límite)")
+		languages := fset.String("languages", "", "lenguajes permitidos, separados por comas (por defecto todos)")
+		admin := fset.Bool("admin", false, "puede cambiar la configuración del servidor en /api/v1/admin/config")
+		if err := fset.Parse(args[1:]); err != nil {
+			return exitUsage
+		}
+		if *user == "" || *minutes < 0 {
+			return usage()
+		}
+		allowed, err := splitLanguages(*languages)
+		if err != nil {
+			fmt.Fprintf(stderr, "--languages: %v\n", err)
+			return exitUsage
+		}
+		key, info, err := store.createKey(*user, *minutes, allowed, *admin)
+		if err != nil {
+			fmt.Fprintf(stderr, "no se pudo crear la clave: %v\n", err)
+			return exitDiagnostics
+		}
+		fmt.Fprintf(stdout, "%s\n", key)
+		fmt.Fprintf(stderr, "clave %s creada para %s; guárdela, no se vuelve a mostrar\n", info.ID, info.User)
+	case "list":
+		keys, err := store.listKeys()
+		if err != nil {
+			fmt.Fprintf(stderr, "no se pudieron leer las claves: %v\n", err)
+			return exitDiagnostics
+		}
+		for _, k := range keys {
+			minutes, languages, status := "sin límite", "todos", "activa"
+			if k.DailyMinutes > 0 {
+				minutes = strconv.FormatFloat(k.DailyMinutes, 'g', -1, 64) + " min/día"
+			}
+			if len(k.Languages) > 0 {
+				languages = strings.Join(k.Languages, ",")
+			}
+			if k.RevokedAt != nil {
+				status = "revocada"
+			} else if k.Admin {
+				status = "activa, admin"
+			}
+			fmt.Fprintf(stdout, "%s\t%s\t%s\t%s\t%s\n", k.ID, k.User, minutes, languages, status)
+		}
+	case "revoke":
+		if len(args) != 2 {
+			return usage()
+		}
+		ok, err := store.revokeKey(args[1])
+		if err != nil {
+			fmt.Fprintf(stderr, "no se pudo revocar la clave: %v\n", err)
+			return exitDiagnostics
+		}
+		if !ok {
+			fmt.Fprintf(stderr, "no hay una clave activa con id %s\n", args[1])
+			return exitDiagnostics
+		}
+	default:
+		return usage()
+	}
+	return exitClean
+}
+
+// cliLanguage indica si lang es uno de los lenguajes que analiza el servidor
+func cliLanguage(lang string) bool {
+	for _, known := range cliExtensions {
+		if known == lang {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// signJWT arma un token con header y claims firmado con HS256 y secret
+func signJWT(t *testing.T, header, claims map[string]interface{}, secret string) string {
+	t.Helper()
+	var parts []string
+	for _, v := range []map[string]interface{}{header, claims} {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts = append(parts, base64.RawURLEncoding.EncodeToString(raw))
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	return parts[0] + "." + parts[1] + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// testAuthStore abre una base de claves vacía y la deja en auth mientras
+// dura el test
+func testAuthStore(t *testing.T) *authStore {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "auth.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	store, err := openAuth(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := auth
+	auth = store
+	t.Cleanup(func() { auth = saved })
+	return store
+}
+
+// TestVerifyJWT comprueba que solo se acepta un token HS256 firmado con el
+// secreto, vigente y con lenguajes conocidos
+func TestVerifyJWT(t *testing.T) {
+	const secret = "secreto-de-prueba"
+	now := time.Unix(1_700_000_000, 0)
+	hs256 := map[string]interface{}{"alg": "HS256", "typ": "JWT"}
+
+	cases := []struct {
+		name      string
+		token     string
+		languages []string
+	}{
+		{"valido", signJWT(t, hs256, map[string]interface{}{"sub": "ana", "exp": now.Unix() + 60, "languages": []string{"c++", "py"}}, secret), []string{"cpp", "python"}},
+		{"otro_secreto", signJWT(t, hs256, map[string]interface{}{"sub": "ana"}, "otro"), nil},
+		{"vencido", signJWT(t, hs256, map[string]interface{}{"sub": "ana", "exp": now.Unix()}, secret), nil},
+		{"todavia_no_vigente", signJWT(t, hs256, map[string]interface{}{"sub": "ana", "nbf": now.Unix() + 60}, secret), nil},
+		{"alg_none", signJWT(t, map[string]interface{}{"alg": "none"}, map[string]interface{}{"sub": "ana"}, secret), nil},
+		{"sin_sub", signJWT(t, hs256, map[string]interface{}{"name": "Ana"}, secret), nil},
+		{"lenguaje_desconocido", signJWT(t, hs256, map[string]interface{}{"sub": "ana", "languages": []string{"pyhton"}}, secret), nil},
+		{"mal_formado", "no.es.jwt", nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p, err := verifyJWT(c.token, []byte(secret), now)
+			if c.languages == nil {
+				if !errors.Is(err, errInvalidCredentials) {
+					t.Fatalf("aceptado: %+v, %v", p, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Account != "jwt:ana" || strings.Join(p.Languages, ",") != strings.Join(c.languages, ",") {
+				t.Errorf("usuario %s con %v, esperado jwt:ana con %v", p.Account, p.Languages, c.languages)
+			}
+		})
+	}
+}
+
+// TestSplitLanguages comprueba que un lenguaje desconocido es un error y no
+// se descarta: la lista vacía permitiría todos
+func TestSplitLanguages(t *testing.T) {
+	if got, err := splitLanguages("c++, js,,go"); err != nil || strings.Join(got, ",") != "cpp,javascript,go" {
+		t.Errorf("splitLanguages = %v, %v", got, err)
+	}
+	if got, err := splitLanguages("cobol"); err == nil {
+		t.Errorf("splitLanguages(cobol) = %v, esperado un error", got)
+	}
+}
+
+// TestAPIKeys comprueba que el id de una clave no revela parte de ella y
+// que una clave revocada deja de autenticar
+func TestAPIKeys(t *testing.T) {
+	store := testAuthStore(t)
+	key, info, err := store.createKey("ana", 30, []string{"python"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(key, info.ID) {
+		t.Errorf("el id %s es parte de la clave %s", info.ID, key)
+	}
+	p, err := authenticate(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Account != "key:"+info.ID || p.User != "ana" || p.allowsLanguage("cpp") {
+		t.Errorf("usuario %+v", p)
+	}
+	if ok, err := store.revokeKey(info.ID); !ok || err != nil {
+		t.Fatalf("revokeKey = %v, %v", ok, err)
+	}
+	if _, err := authenticate(key); !errors.Is(err, errInvalidCredentials) {
+		t.Errorf("la clave revocada autentica: %v", err)
+	}
+}
+
+// TestQuotaExceeded comprueba que con la cuota agotada /api/v1/analyze
+// responde 429 con Retry-After si se pide ejecutar, y analiza sin ejecutar
+func TestQuotaExceeded(t *testing.T) {
+	store := testAuthStore(t)
+	key, info, err := store.createKey("ana", 1, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.addUsage("key:"+info.ID, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"ejecutar", `{"code": "print(1)", "language": "python"}`, http.StatusTooManyRequests},
+		{"sin_ejecutar", `{"code": "print(1)", "language": "python", "execute": false}`, http.StatusOK},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, apiPrefix+"/analyze", strings.NewReader(c.body))
+			r.Header.Set("X-API-Key", key)
+			w := httptest.NewRecorder()
+			requireAuth(analyzeHandler)(w, r)
+			if w.Code != c.status {
+				t.Fatalf("estado %d, esperado %d: %s", w.Code, c.status, w.Body.String())
+			}
+			if c.status == http.StatusTooManyRequests && w.Header().Get("Retry-After") == "" {
+				t.Error("falta Retry-After")
+			}
+		})
+	}
+}
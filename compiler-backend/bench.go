@@ -0,0 +1,259 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ─────────────────────────────── Benchmarks ──────────────────────────────
+//
+// `compiler-backend bench` mide cada fase del análisis sobre un corpus con
+// un programa representativo por lenguaje (testdata/bench, incluido en el
+// binario) para validar un cambio de rendimiento, como otro motor de
+// expresiones regulares o un parser reescrito:
+//
+//	compiler-backend bench --save antes.json        # en main
+//	compiler-backend bench --baseline antes.json    # con el cambio
+//
+// Las mismas fases son BenchmarkAnalyze/<lenguaje>/<fase> en bench_test.go
+// para `go test -bench`; el comando las mide con su propio ciclo, que da
+// ns/op, B/op y allocs/op como aquel, para no meter el paquete testing ni
+// sus banderas -test.* en el servidor. Con --baseline se compara cada una
+// con la guardada y el comando termina con 1 si alguna empeoró más que
+// --threshold por ciento.
+//
+//   lexical   Tokenize y los chequeos léxicos (LexicalAnalysis)
+//   syntax    el parser sobre los tokens ya calculados
+//   semantic  el análisis semántico sobre los tokens y el árbol
+//   pipeline  AnalyzeCodeWithProgress completo, sin ejecutar
+
+//go:embed testdata/bench
+var benchCorpus embed.FS
+
+// benchSource es un programa del corpus
+type benchSource struct {
+	name, language, code string
+}
+
+// benchPhases son las fases de cada programa, en el orden en que se
+// informan. prepare hace lo que no se mide y devuelve la operación medida
+var benchPhases = []struct {
+	name    string
+	prepare func(src benchSource) func()
+}{
+	{"lexical", func(src benchSource) func() {
+		return func() { LexicalAnalysis(src.code, src.language) }
+	}},
+	{"syntax", func(src benchSource) func() {
+		tokens := Tokenize(src.code, src.language)
+		return func() { NewParser(tokens, src.language, src.code).Parse() }
+	}},
+	{"semantic", func(src benchSource) func() {
+		tokens := Tokenize(src.code, src.language)
+		tree, _ := NewParser(tokens, src.language, src.code).Parse()
+		return func() { NewSemanticAnalyzer(tokens, tree, src.language).Analyze() }
+	}},
+	{"pipeline", func(src benchSource) func() {
+		opts := AnalyzeOptions{SkipExecution: true}
+		return func() { AnalyzeCodeWithProgress(src.code, src.language, opts, nil) }
+	}},
+}
+
+// BenchResult es una medición; Name es fase/lenguaje/archivo
+type BenchResult struct {
+	Name        string  `json:"name"`
+	N           int     `json:"n"`
+	NsPerOp     float64 `json:"nsPerOp"`
+	BytesPerOp  int64   `json:"bytesPerOp"`
+	AllocsPerOp int64   `json:"allocsPerOp"`
+}
+
+// BenchReport es lo que guarda --save y lee --baseline
+type BenchReport struct {
+	GoVersion string        `json:"goVersion"`
+	Platform  string        `json:"platform"`
+	Date      time.Time     `json:"date"`
+	Scale     int           `json:"scale"`
+	Results   []BenchResult `json:"results"`
+}
+
+// loadBenchCorpus lee los programas de dir o, si está vacío, los del
+// binario; el lenguaje sale de la extensión como en `analyze`
+func loadBenchCorpus(dir string, scale int) ([]benchSource, error) {
+	var fsys fs.FS = os.DirFS(dir)
+	if dir == "" {
+		sub, err := fs.Sub(benchCorpus, "testdata/bench")
+		if err != nil {
+			return nil, err
+		}
+		fsys = sub
+	}
+	var sources []benchSource
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		language := cliExtensions[strings.ToLower(path.Ext(name))]
+		if language == "" {
+			return nil
+		}
+		code, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		// Repetir el programa mide cómo crece el costo con el tamaño
+		sources = append(sources, benchSource{name: name, language: language,
+			code: strings.Repeat(string(code)+"\n", scale)})
+		return nil
+	})
+	sort.Slice(sources, func(i, j int) bool { return sources[i].name < sources[j].name })
+	return sources, err
+}
+
+// runBenchCLI atiende `compiler-backend bench [opciones]`
+func runBenchCLI(args []string, stdout, stderr io.Writer) int {
+	fset := flag.NewFlagSet("bench", flag.ContinueOnError)
+	fset.SetOutput(stderr)
+	run := fset.String("run", "", "solo los benchmarks cuyo nombre (fase/lenguaje/archivo) coincide con esta expresión")
+	corpus := fset.String("corpus", "", "directorio con los programas (por defecto el corpus incluido)")
+	scale := fset.Int("scale", 1, "veces que se repite cada programa")
+	benchtime := fset.Duration("benchtime", time.Second, "tiempo de cada benchmark")
+	save := fset.String("save", "", "archivo donde guardar los resultados en JSON")
+	baseline := fset.String("baseline", "", "resultados guardados con --save contra los que comparar")
+	threshold := fset.Float64("threshold", 10, "porcentaje de ns/op por encima del baseline que cuenta como regresión")
+	fset.Usage = func() {
+		fmt.Fprintf(stderr, "uso: %s bench [opciones]\n", filepath.Base(os.Args[0]))
+		fset.PrintDefaults()
+	}
+	if err := fset.Parse(args); err == flag.ErrHelp {
+		return exitClean
+	} else if err != nil {
+		return exitUsage
+	}
+	if fset.NArg() > 0 || *scale < 1 || *benchtime <= 0 || *threshold < 0 {
+		fset.Usage()
+		return exitUsage
+	}
+	filter, err := regexp.Compile(*run)
+	if err != nil {
+		fmt.Fprintln(stderr, "--run:", err)
+		return exitUsage
+	}
+	var base map[string]BenchResult
+	if *baseline != "" {
+		if base, err = readBenchBaseline(*baseline, *scale); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+	}
+	sources, err := loadBenchCorpus(*corpus, *scale)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	report := BenchReport{GoVersion: runtime.Version(), Platform: runtime.GOOS + "/" + runtime.GOARCH,
+		Date: time.Now().UTC(), Scale: *scale}
+	regressions := 0
+	for _, phase := range benchPhases {
+		for _, src := range sources {
+			name := phase.name + "/" + src.language + "/" + src.name
+			if !filter.MatchString(name) {
+				continue
+			}
+			result := measureBench(phase.prepare(src), *benchtime)
+			result.Name = name
+			report.Results = append(report.Results, result)
+
+			line := fmt.Sprintf("%-44s %10d %14.0f ns/op %12d B/op %9d allocs/op",
+				name, result.N, result.NsPerOp, result.BytesPerOp, result.AllocsPerOp)
+			if old, ok := base[name]; ok && old.NsPerOp > 0 {
+				delta := (result.NsPerOp - old.NsPerOp) / old.NsPerOp * 100
+				line += fmt.Sprintf("  %+7.1f%%", delta)
+				if delta > *threshold {
+					line += "  REGRESIÓN"
+					regressions++
+				}
+			}
+			fmt.Fprintln(stdout, line)
+		}
+	}
+
+	if *save != "" {
+		data, _ := json.MarshalIndent(report, "", "  ")
+		if err := os.WriteFile(*save, append(data, '\n'), 0o644); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+	}
+	if regressions > 0 {
+		fmt.Fprintf(stderr, "%d benchmarks más de %.0f%% más lentos que %s\n", regressions, *threshold, *baseline)
+		return exitDiagnostics
+	}
+	return exitClean
+}
+
+// measureBench corre op cada vez más veces, como testing.Benchmark, hasta
+// que una tanda dure al menos benchtime, y devuelve lo que costó esa tanda
+func measureBench(op func(), benchtime time.Duration) BenchResult {
+	op()
+	n := 1
+	for {
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		start := time.Now()
+		for i := 0; i < n; i++ {
+			op()
+		}
+		elapsed := time.Since(start)
+		runtime.ReadMemStats(&after)
+		if elapsed >= benchtime || n >= 1e9 {
+			return BenchResult{N: n, NsPerOp: float64(elapsed.Nanoseconds()) / float64(n),
+				BytesPerOp:  int64(after.TotalAlloc-before.TotalAlloc) / int64(n),
+				AllocsPerOp: int64(after.Mallocs-before.Mallocs) / int64(n)}
+		}
+		// La próxima tanda apunta a benchtime con un 20% de margen, sin
+		// crecer más de 100 veces ni quedarse igual
+		next := n + 1
+		if per := elapsed.Nanoseconds() / int64(n); per > 0 {
+			next = int(benchtime.Nanoseconds() * 6 / 5 / per)
+		} else {
+			next = 100 * n
+		}
+		n = max(min(next, 100*n), n+1)
+	}
+}
+
+// readBenchBaseline lee un archivo de --save, indexado por nombre; tiene que
+// ser de la misma --scale para que los números se puedan comparar
+func readBenchBaseline(name string, scale int) (map[string]BenchResult, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	var report BenchReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	if report.Scale != scale {
+		return nil, fmt.Errorf("%s se midió con --scale %d", name, report.Scale)
+	}
+	results := make(map[string]BenchResult, len(report.Results))
+	for _, r := range report.Results {
+		results[r.Name] = r
+	}
+	return results, nil
+}
@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+// BenchmarkAnalyze mide cada fase del análisis sobre los programas de
+// testdata/bench, con los mismos nombres que `compiler-backend bench` pero
+// empezando por el lenguaje:
+//
+//	go test -run '^$' -bench 'Analyze/python' -benchmem
+func BenchmarkAnalyze(b *testing.B) {
+	sources, err := loadBenchCorpus("testdata/bench", 1)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, src := range sources {
+		b.Run(src.language, func(b *testing.B) {
+			for _, phase := range benchPhases {
+				op := phase.prepare(src)
+				b.Run(phase.name, func(b *testing.B) {
+					b.ReportAllocs()
+					for i := 0; i < b.N; i++ {
+						op()
+					}
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ─────────────────────── Caché de resultados ──────────────────────────────
+//
+// En clase es muy común que muchos estudiantes envíen exactamente el mismo
+// programa. Los análisis completos se guardan en una caché LRU de
+// GlobalConfig.ResultCacheSize entradas, indexada por el SHA-256 del código,
+// el lenguaje y la entrada estándar, y una petición idéntica responde sin
+// volver a compilar ni ejecutar. No se guardan los resultados que dependen
+// de la carga del servidor (ver ExecutionResult.Transient). Con REDIS_URL
+// cada resultado se guarda también en Redis, y lo que no está en la caché
+// local se busca ahí antes de analizar (ver redisResultCache).
+
+type resultCache struct {
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List // más reciente al frente
+}
+
+type cacheEntry struct {
+	key    string
+	result AnalyzeResponse
+}
+
+var analysisCache = &resultCache{entries: make(map[string]*list.Element), order: list.New()}
+
+// analysisCacheKey combina todo lo que determina el resultado. Además del
+// código y el lenguaje se incluyen las opciones de ejecución y la entrada del
+// programa: el mismo programa con otro timeout, sin ejecutar o con otro
+// stdin produce otra respuesta. También la configuración que se cambia con
+// el servidor en marcha (ver admin.go), para no servir resultados de antes.
+func analysisCacheKey(code, language string, opts AnalyzeOptions) string {
+	h := sha256.New()
+	parts := []string{code, language, opts.Stdin, opts.Timeout.String(), strconv.FormatBool(opts.SkipExecution), strconv.FormatBool(opts.GeneratedCode), diagnosticsKey(opts.Diagnostics), severitiesKey(opts.SeverityOverrides), strconv.Itoa(opts.MaxErrors), judgeKey(opts.Judge), strconv.Itoa(opts.Breakpoint), strconv.FormatBool(opts.Deterministic), profileKey(opts.Profile), currentConfig().runtimeKey()}
+	// Cada argumento, variable, archivo y caso es una parte más, detrás de su
+	// cantidad
+	input := ProgramInput{Env: opts.Env}
+	parts = append(append(parts, strconv.Itoa(len(opts.Args))), opts.Args...)
+	parts = append(append(parts, strconv.Itoa(len(opts.Env))), input.envList()...)
+	parts = append(parts, strconv.Itoa(len(opts.Files)))
+	for _, f := range opts.Files {
+		parts = append(parts, f.Name, f.Content)
+	}
+	parts = append(parts, strconv.Itoa(len(opts.TestCases)))
+	for _, c := range opts.TestCases {
+		parts = append(parts, c.Stdin, c.ExpectedOutput, strconv.Itoa(c.points()))
+	}
+	for _, part := range parts {
+		// El largo delante de cada parte evita que ("ab", "c") y ("a", "bc")
+		// produzcan la misma clave
+		h.Write([]byte(strconv.Itoa(len(part)) + ":" + part))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *resultCache) get(key string) (AnalyzeResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return AnalyzeResponse{}, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*cacheEntry).result, true
+}
+
+// put guarda result descartando las entradas menos usadas que excedan
+// GlobalConfig.ResultCacheSize
+func (c *resultCache) put(key string, result AnalyzeResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*cacheEntry).result = result
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, result: result})
+	for c.order.Len() > GlobalConfig.ResultCacheSize {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// len devuelve cuántos resultados hay guardados
+func (c *resultCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// AnalyzeCodeCached es AnalyzeCodeWithProgress con la caché de resultados.
+// Los análisis incrementales (opts.Snapshot) no pasan por la caché porque
+// además actualizan el snapshot de la sesión.
+func AnalyzeCodeCached(code, language string, opts AnalyzeOptions) AnalyzeResponse {
+	if GlobalConfig.ResultCacheSize <= 0 || opts.Snapshot != nil {
+		return AnalyzeCodeWithProgress(code, language, opts, nil)
+	}
+	start := time.Now()
+	key := analysisCacheKey(code, language, opts)
+	// De la caché no se analiza ninguna fase: no tardan nada
+	if result, ok := analysisCache.get(key); ok {
+		result.Cached = true
+		result.ProcessingTime = time.Since(start)
+		result.Timing = AnalysisTiming{}
+		return result
+	}
+	if result, ok := sharedCache.get(key); ok {
+		analysisCache.put(key, result)
+		result.Cached = true
+		result.ProcessingTime = time.Since(start)
+		result.Timing = AnalysisTiming{}
+		return result
+	}
+	result := AnalyzeCodeWithProgress(code, language, opts, nil)
+	if (result.ExecutionResult == nil || !result.ExecutionResult.Transient) &&
+		(result.GeneratedCode == nil || !result.GeneratedCode.Transient) && !result.Executability.Transient {
+		analysisCache.put(key, result)
+		sharedCache.put(key, result)
+	}
+	return result
+}
+
+// profileKey representa el perfil de la tarea en la clave de la caché con
+// el SHA-256 de su contenido: dos espacios de trabajo pueden tener perfiles
+// con el mismo nombre y distintos requisitos, y un espacio puede cambiar los
+// suyos con el servidor en marcha
+func profileKey(p *AssignmentProfile) string {
+	if p == nil {
+		return ""
+	}
+	content, err := json.Marshal(p)
+	if err != nil {
+		return p.Name
+	}
+	sum := sha256.Sum256(append([]byte(p.Name+"\x00"), content...))
+	return hex.EncodeToString(sum[:])
+}
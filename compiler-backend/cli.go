@@ -0,0 +1,361 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ──────────────────────────────── Modo CLI ────────────────────────────────
+//
+// `compiler-backend analyze archivo.cpp dir/ --json --no-exec` ejecuta el
+// mismo pipeline que /api/v1/analyze sobre archivos locales, sin levantar el
+// servidor, para usarlo en scripts y en CI; con --sarif los diagnósticos
+// salen en SARIF (ver sarif.go) y con --junit los veredictos se escriben en
+// JUnit XML (ver junit.go). Los directorios se recorren buscando
+// archivos de los lenguajes soportados. Códigos de salida:
+//
+//   0  sin errores (puede haber advertencias, salvo con --werror)
+//   1  al menos un error en algún archivo, o un veredicto distinto de AC
+//      con --expected o en los casos de prueba de --profile
+//   2  uso incorrecto o archivo ilegible
+
+const (
+	exitClean       = 0
+	exitDiagnostics = 1
+	exitUsage       = 2
+)
+
+// Lenguaje de cada extensión reconocida al recorrer directorios
+var cliExtensions = map[string]string{
+	".cpp": "cpp", ".cc": "cpp", ".cxx": "cpp", ".hpp": "cpp", ".h": "cpp",
+	".py": "python",
+	".js": "javascript", ".mjs": "javascript", ".cjs": "javascript",
+	".ts": "typescript", ".mts": "typescript", ".cts": "typescript",
+	".go": "go", ".css": "css",
+	".html": "html", ".htm": "html",
+	".sql": "tsql", ".pls": "plsql", ".pks": "plsql", ".pkb": "plsql",
+	".pas": "pascal", ".pp": "pascal",
+	".kt": "kotlin", ".kts": "kotlin", ".swift": "swift",
+}
+
+// APIFileAnalysis es cada elemento de la salida --json
+type APIFileAnalysis struct {
+	File string `json:"file"`
+	APIAnalyzeResponse
+}
+
+type cliOptions struct {
+	json      bool
+	sarif     bool
+	noExec    bool
+	werror    bool
+	generated bool
+	language  string
+	timeout   int
+	// Diagnósticos desactivados, separados por comas
+	disable string
+	// Argumentos de cada programa ejecutado, uno por --arg
+	args []string
+	// Archivo con la salida esperada de cada programa (modo juez)
+	expected string
+	compare  string
+	// Idioma de los mensajes de error
+	locale string
+	// Perfil de la tarea (ASSIGNMENT_PROFILES)
+	profile string
+	// Archivo donde se escriben los veredictos en JUnit XML
+	junit string
+}
+
+// runCLI atiende los argumentos después del nombre del programa y devuelve
+// el código de salida
+func runCLI(args []string, stdout, stderr io.Writer) int {
+	if len(args) > 0 && args[0] == "keys" {
+		return runKeysCLI(args[1:], stdout, stderr)
+	}
+	if len(args) > 0 && args[0] == "tokens" {
+		return runTokensCLI(args[1:], os.Stdin, stdout, stderr)
+	}
+	if len(args) > 0 && args[0] == "bench" {
+		return runBenchCLI(args[1:], stdout, stderr)
+	}
+	if len(args) == 0 || args[0] != "analyze" {
+		fmt.Fprintf(stderr, "uso: %s analyze [opciones] archivo|directorio...\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(stderr, "     %s keys create|list|revoke ...\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(stderr, "     %s tokens [opciones] archivo|-\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(stderr, "     %s bench [opciones]\n", filepath.Base(os.Args[0]))
+		return exitUsage
+	}
+
+	var opts cliOptions
+	fset := flag.NewFlagSet("analyze", flag.ContinueOnError)
+	fset.SetOutput(stderr)
+	fset.BoolVar(&opts.json, "json", false, "imprime el análisis completo en JSON")
+	fset.BoolVar(&opts.sarif, "sarif", false, "imprime los diagnósticos en SARIF 2.1.0 (GitHub code scanning)")
+	fset.BoolVar(&opts.noExec, "no-exec", false, "no compila ni ejecuta el código")
+	fset.BoolVar(&opts.werror, "werror", false, "las advertencias también hacen fallar")
+	fset.BoolVar(&opts.generated, "generated", false, "agrega el ensamblador o bytecode que produce la herramienta real")
+	fset.StringVar(&opts.language, "language", "", "lenguaje de todos los archivos (por defecto según la extensión)")
+	fset.IntVar(&opts.timeout, "timeout", 0, "segundos de ejecución por archivo")
+	fset.Func("arg", "argumento para el programa ejecutado (se puede repetir)", func(v string) error {
+		opts.args = append(opts.args, v)
+		return nil
+	})
+	fset.StringVar(&opts.expected, "expected", "", "archivo con la salida esperada: agrega el veredicto del modo juez")
+	fset.StringVar(&opts.compare, "compare", "", "cómo se compara con --expected: exact, trimmed, tokens o float")
+	fset.StringVar(&opts.locale, "locale", "", "idioma de los mensajes de error: es o en (por defecto DEFAULT_LOCALE)")
+	fset.StringVar(&opts.profile, "profile", "", "perfil de la tarea definido en ASSIGNMENT_PROFILES")
+	fset.StringVar(&opts.junit, "junit", "", "archivo donde escribir en JUnit XML los veredictos de --expected o --profile")
+	fset.StringVar(&opts.disable, "disable", "", "diagnósticos a omitir, por código o nombre (SEM002,reserved-identifier)")
+	fset.Usage = func() {
+		fmt.Fprintf(stderr, "uso: %s analyze [opciones] archivo|directorio...\n", filepath.Base(os.Args[0]))
+		fset.PrintDefaults()
+	}
+
+	// flag deja de leer opciones en el primer argumento posicional; se
+	// vuelve a leer después de cada uno para aceptar `archivo.cpp --json`
+	var paths []string
+	rest := args[1:]
+	for {
+		if err := fset.Parse(rest); err == flag.ErrHelp {
+			return exitClean
+		} else if err != nil {
+			return exitUsage
+		}
+		if fset.NArg() == 0 {
+			break
+		}
+		paths = append(paths, fset.Arg(0))
+		rest = fset.Args()[1:]
+	}
+	if len(paths) == 0 {
+		fset.Usage()
+		return exitUsage
+	}
+	if opts.timeout < 0 {
+		fmt.Fprintln(stderr, "timeout must be positive")
+		return exitUsage
+	}
+	if opts.json && opts.sarif {
+		fmt.Fprintln(stderr, "--json y --sarif no se pueden usar juntos")
+		return exitUsage
+	}
+	if msg := invalidLocale(opts.locale); msg != "" {
+		fmt.Fprintln(stderr, msg)
+		return exitUsage
+	}
+
+	disabled := map[string]bool{}
+	for _, name := range strings.Split(opts.disable, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			disabled[name] = false
+		}
+	}
+	if name := unknownDiagnostic(disabled); name != "" {
+		fmt.Fprintln(stderr, "diagnóstico desconocido:", name)
+		return exitUsage
+	}
+	if msg := invalidArgs(opts.args); msg != "" {
+		fmt.Fprintln(stderr, msg)
+		return exitUsage
+	}
+	if msg := invalidJudge(opts.compare, 0); msg != "" {
+		fmt.Fprintln(stderr, msg)
+		return exitUsage
+	}
+	profile := GlobalConfig.AssignmentProfiles[opts.profile]
+	if opts.profile != "" && profile == nil {
+		fmt.Fprintln(stderr, "perfil desconocido:", opts.profile)
+		return exitUsage
+	}
+	if profile != nil && len(profile.TestCases) > 0 && opts.expected != "" {
+		fmt.Fprintln(stderr, "el perfil", opts.profile, "tiene sus propios casos de prueba: no se puede usar --expected")
+		return exitUsage
+	}
+	var judge *JudgeOptions
+	if opts.expected != "" {
+		expected, err := os.ReadFile(opts.expected)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+		judge = &JudgeOptions{ExpectedOutput: string(expected), Compare: opts.compare}
+	}
+
+	files, err := collectCLIFiles(paths, opts.language != "")
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+
+	exitCode := exitClean
+	results := []APIFileAnalysis{}
+	var sarifFiles []sarifFile
+	var submissions []junitSubmission
+	errorCount, warningCount := 0, 0
+	for _, file := range files {
+		code, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+		language := mapLanguage(opts.language)
+		if language == "" {
+			language = cliExtensions[strings.ToLower(filepath.Ext(file))]
+		}
+		analyzeOpts := AnalyzeOptions{
+			Timeout:       ExecutionTimeoutFor(opts.timeout),
+			SkipExecution: opts.noExec,
+			GeneratedCode: opts.generated,
+			Diagnostics:   diagnosticOverrides(disabled),
+			Args:          opts.args,
+			Judge:         judge,
+		}
+		if profile != nil {
+			analyzeOpts.withProfile(profile)
+		}
+		result := AnalyzeCodeWithProgress(string(code), language, analyzeOpts, nil)
+		response := buildAPIResponse(result, newSourceIndex(string(code)), requestLocale(opts.locale, nil))
+
+		for _, e := range response.Errors {
+			if e.Severity == "warning" {
+				warningCount++
+			} else {
+				errorCount++
+			}
+		}
+		submissions = append(submissions, junitSubmission{name: filepath.ToSlash(file), language: response.Language, response: response})
+		if response.Judge != nil && response.Judge.Verdict != VerdictAccepted ||
+			response.TestResults != nil && response.TestResults.Verdict != VerdictAccepted {
+			exitCode = exitDiagnostics
+		}
+		switch {
+		case opts.json:
+			results = append(results, APIFileAnalysis{File: file, APIAnalyzeResponse: response})
+		case opts.sarif:
+			sarifFiles = append(sarifFiles, sarifFile{uri: file, errors: response.Errors})
+		default:
+			printCLIDiagnostics(stdout, file, response)
+		}
+	}
+
+	switch {
+	case opts.json:
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(results)
+	case opts.sarif:
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(buildSARIF(sarifFiles, requestLocale(opts.locale, nil)))
+	default:
+		fmt.Fprintf(stdout, "%d archivo(s): %d error(es), %d advertencia(s)\n", len(files), errorCount, warningCount)
+	}
+	if opts.junit != "" {
+		if err := os.WriteFile(opts.junit, []byte(junitReport(submissions, requestLocale(opts.locale, nil))), 0o644); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+	}
+	if errorCount > 0 || (opts.werror && warningCount > 0) {
+		exitCode = exitDiagnostics
+	}
+	return exitCode
+}
+
+// collectCLIFiles expande los directorios a los archivos con extensión
+// conocida, ordenados, omitiendo carpetas ocultas y node_modules. Los
+// archivos indicados explícitamente se analizan aunque su extensión no se
+// reconozca si se indicó --language.
+func collectCLIFiles(paths []string, explicitLanguage bool) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			if _, ok := cliExtensions[strings.ToLower(filepath.Ext(path))]; !ok && !explicitLanguage {
+				return nil, fmt.Errorf("%s: unknown file extension, use --language", path)
+			}
+			files = append(files, path)
+			continue
+		}
+		var found []string
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if p != path && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if _, ok := cliExtensions[strings.ToLower(filepath.Ext(p))]; ok {
+				found = append(found, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(found)
+		files = append(files, found...)
+	}
+	return files, nil
+}
+
+// printCLIDiagnostics imprime los diagnósticos con el formato de gcc
+// (archivo:línea:columna: severidad: mensaje [código], seguido de una nota
+// con la declaración involucrada si la hay), el código generado
+// si se pidió, la salida del programa y su código de salida si se ejecutó y
+// el veredicto con --expected o el puntaje de los casos de prueba del perfil
+func printCLIDiagnostics(w io.Writer, file string, response APIAnalyzeResponse) {
+	for _, e := range response.Errors {
+		fmt.Fprintf(w, "%s:%d:%d: %s: %s", file, e.Line, e.Column, e.Severity, e.Message)
+		if e.Code != "" {
+			fmt.Fprintf(w, " [%s]", e.Code)
+		}
+		fmt.Fprintln(w)
+		if d := e.Declaration; d != nil {
+			fmt.Fprintf(w, "%s:%d:%d: note: declarada aquí\n", file, d.Line, d.Column)
+		}
+	}
+	if gen := response.GeneratedCode; gen != nil {
+		fmt.Fprintf(w, "── código generado de %s (%s) ──\n%s", file, gen.Tool, gen.Output)
+		if !strings.HasSuffix(gen.Output, "\n") {
+			fmt.Fprintln(w)
+		}
+	}
+	if res := response.ExecutionResult; res != nil && res.Output != "" {
+		fmt.Fprintf(w, "── salida de %s ──\n%s", file, res.Output)
+		if !strings.HasSuffix(res.Output, "\n") {
+			fmt.Fprintln(w)
+		}
+	}
+	if res := response.ExecutionResult; res != nil && res.ExitCode != nil {
+		fmt.Fprintf(w, "── %s terminó con código %d en %d ms", file, *res.ExitCode, res.DurationMs)
+		if res.PeakMemoryBytes > 0 {
+			fmt.Fprintf(w, " (CPU %d ms, memoria %.1f MB)", res.UserCPUMs+res.SystemCPUMs, float64(res.PeakMemoryBytes)/(1<<20))
+		}
+		fmt.Fprintln(w, " ──")
+	}
+	if j := response.Judge; j != nil {
+		fmt.Fprintf(w, "── veredicto de %s: %s", file, j.Verdict)
+		if j.Message != "" {
+			fmt.Fprintf(w, " (%s)", j.Message)
+		}
+		fmt.Fprintln(w, " ──")
+	}
+	if t := response.TestResults; t != nil {
+		fmt.Fprintf(w, "── casos de prueba de %s: %s, %d de %d puntos ──\n", file, t.Verdict, t.Score, t.MaxScore)
+	}
+}
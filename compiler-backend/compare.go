@@ -0,0 +1,310 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ───────────────────────────── Similitud de entregas ─────────────────────
+//
+// POST /api/v1/compare compara dos o más entregas de una tarea para que el
+// docente revise las que parecen copiadas. Cada entrega se reduce a la
+// secuencia de sus tokens sin comentarios, con los nombres, las cadenas y
+// los números reemplazados por una categoría: renombrar variables, cambiar
+// los mensajes o reordenar el espacio en blanco no cambia la secuencia. Los
+// tramos comunes se buscan con Greedy String Tiling (el algoritmo de JPlag):
+// primero los más largos, sin que un token pertenezca a dos tramos, y solo
+// los de al menos minMatch tokens, para no contar coincidencias que tiene
+// cualquier programa (un for, un #include). La similitud de un par es la
+// proporción de tokens de ambas entregas cubierta por tramos comunes.
+
+// Tramo común mínimo por defecto y menor valor aceptado
+const (
+	defaultCompareMinMatch = 12
+	minCompareMinMatch     = 3
+)
+
+// Máximo de entregas por petición: se compara cada par
+const maxCompareSubmissions = 50
+
+// Posiciones de la otra entrega que se prueban por cada token: en código
+// repetitivo (mil líneas iguales) un mismo fragmento aparece miles de veces
+// y probarlas todas hace cuadrática cada vuelta
+const compareMaxCandidates = 64
+
+// CompareSubmission es una entrega; sin ID se usa su número (1, 2...)
+type CompareSubmission struct {
+	ID       string `json:"id,omitempty"`
+	Code     string `json:"code"`
+	Language string `json:"language,omitempty"`
+}
+
+// CompareRequest es el cuerpo de POST /api/v1/compare; Language se aplica
+// a las entregas que no indican el suyo
+type CompareRequest struct {
+	Submissions []CompareSubmission `json:"submissions"`
+	Language    string              `json:"language,omitempty"`
+	MinMatch    int                 `json:"minMatch,omitempty"`
+}
+
+// APICompareRange ubica un tramo en el código de una entrega
+type APICompareRange struct {
+	Line      int `json:"line"`
+	Column    int `json:"column"`
+	EndLine   int `json:"endLine"`
+	EndColumn int `json:"endColumn"`
+}
+
+// APICompareMatch es un tramo común a las dos entregas de un par
+type APICompareMatch struct {
+	A      APICompareRange `json:"a"`
+	B      APICompareRange `json:"b"`
+	Tokens int             `json:"tokens"`
+}
+
+// APIComparePair es la similitud (de 0 a 1) entre dos entregas
+type APIComparePair struct {
+	A          string            `json:"a"`
+	B          string            `json:"b"`
+	Similarity float64           `json:"similarity"`
+	Matches    []APICompareMatch `json:"matches"`
+}
+
+// Respuesta de POST /api/v1/compare: los pares, del más parecido al menos
+type APICompareResponse struct {
+	MinMatch int              `json:"minMatch"`
+	Pairs    []APIComparePair `json:"pairs"`
+}
+
+// normalizedTokens es una entrega lista para comparar: los tokens sin
+// comentarios y, en paralelo, su forma normalizada
+type normalizedTokens struct {
+	tokens []Token
+	keys   []string
+}
+
+func normalizeTokens(code, language string) normalizedTokens {
+	var n normalizedTokens
+	for _, t := range Tokenize(code, language) {
+		var key string
+		switch t.Type {
+		case WHITESPACE, COMMENT:
+			continue
+		case IDENTIFIER, FUNCTION, CLASS, VARIABLE:
+			key = "ID"
+		case STRING:
+			key = "STR"
+		case NUMBER:
+			key = "NUM"
+		default:
+			// Pascal y SQL no distinguen mayúsculas en las palabras clave
+			key = strings.ToLower(t.Lexeme)
+		}
+		n.tokens = append(n.tokens, t)
+		n.keys = append(n.keys, key)
+	}
+	return n
+}
+
+// compareTile es un tramo común: length tokens desde a en la primera
+// entrega y desde b en la segunda
+type compareTile struct {
+	a, b, length int
+}
+
+// greedyStringTiling devuelve los tramos comunes de al menos minMatch
+// tokens entre a y b, de mayor a menor. Cada vuelta busca los tramos más
+// largos entre los tokens sin marcar, partiendo de los minMatch-gramas de b
+// indexados por su hash, y los marca
+func greedyStringTiling(a, b []string, minMatch int) []compareTile {
+	if len(a) < minMatch || len(b) < minMatch {
+		return nil
+	}
+	gramHash := func(s []string, i int) uint64 {
+		h := fnv.New64a()
+		for _, k := range s[i : i+minMatch] {
+			h.Write([]byte(k))
+			h.Write([]byte{0})
+		}
+		return h.Sum64()
+	}
+	index := map[uint64][]int{}
+	for j := 0; j+minMatch <= len(b); j++ {
+		h := gramHash(b, j)
+		index[h] = append(index[h], j)
+	}
+	hashesA := make([]uint64, len(a)-minMatch+1)
+	for i := range hashesA {
+		hashesA[i] = gramHash(a, i)
+	}
+
+	markedA, markedB := make([]bool, len(a)), make([]bool, len(b))
+	unmarked := func(marked []bool, from, length int) bool {
+		for _, m := range marked[from : from+length] {
+			if m {
+				return false
+			}
+		}
+		return true
+	}
+
+	var tiles []compareTile
+	for {
+		longest := 0
+		var found []compareTile
+		for i, h := range hashesA {
+			if !unmarked(markedA, i, minMatch) {
+				continue
+			}
+			tried := 0
+			for _, j := range index[h] {
+				if markedB[j] || !unmarked(markedB, j, minMatch) {
+					continue
+				}
+				if tried++; tried > compareMaxCandidates {
+					break
+				}
+				// Si el token anterior también coincide, este tramo es parte
+				// de uno más largo que empieza antes
+				if i > 0 && j > 0 && a[i-1] == b[j-1] && !markedA[i-1] && !markedB[j-1] {
+					continue
+				}
+				length := 0
+				for i+length < len(a) && j+length < len(b) && a[i+length] == b[j+length] &&
+					!markedA[i+length] && !markedB[j+length] {
+					length++
+				}
+				if length < minMatch || length < longest {
+					continue
+				}
+				if length > longest {
+					longest, found = length, nil
+				}
+				found = append(found, compareTile{i, j, length})
+			}
+		}
+		if longest == 0 {
+			return tiles
+		}
+		// Dos tramos de la misma vuelta pueden solaparse: se marca el primero
+		for _, t := range found {
+			if unmarked(markedA, t.a, t.length) && unmarked(markedB, t.b, t.length) {
+				for k := 0; k < t.length; k++ {
+					markedA[t.a+k], markedB[t.b+k] = true, true
+				}
+				tiles = append(tiles, t)
+			}
+		}
+	}
+}
+
+// compareRange ubica los tokens de from a from+length-1
+func compareRange(tokens []Token, from, length int) APICompareRange {
+	first, last := tokens[from], tokens[from+length-1]
+	return APICompareRange{Line: first.Line, Column: first.Column, EndLine: last.EndLine, EndColumn: last.EndColumn}
+}
+
+// comparePair compara dos entregas normalizadas
+func comparePair(a, b normalizedTokens, minMatch int) (float64, []APICompareMatch) {
+	matches := []APICompareMatch{}
+	covered := 0
+	for _, t := range greedyStringTiling(a.keys, b.keys, minMatch) {
+		covered += t.length
+		matches = append(matches, APICompareMatch{
+			A:      compareRange(a.tokens, t.a, t.length),
+			B:      compareRange(b.tokens, t.b, t.length),
+			Tokens: t.length,
+		})
+	}
+	total := len(a.keys) + len(b.keys)
+	if total == 0 {
+		return 0, matches
+	}
+	// Los tramos de cada lado miden lo mismo: cubren 2*covered tokens
+	return math.Round(float64(2*covered)/float64(total)*1000) / 1000, matches
+}
+
+// compareHandler atiende POST /api/v1/compare
+func compareHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req CompareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w)
+		return
+	}
+	if len(req.Submissions) < 2 || len(req.Submissions) > maxCompareSubmissions {
+		httpError(w, fmt.Sprintf("submissions must have between 2 and %d entries", maxCompareSubmissions), http.StatusBadRequest)
+		return
+	}
+	minMatch := req.MinMatch
+	if minMatch == 0 {
+		minMatch = defaultCompareMinMatch
+	}
+	if minMatch < minCompareMinMatch {
+		httpError(w, fmt.Sprintf("minMatch must be at least %d", minCompareMinMatch), http.StatusBadRequest)
+		return
+	}
+
+	principal := principalFrom(r)
+	ids := make([]string, len(req.Submissions))
+	normalized := make([]normalizedTokens, len(req.Submissions))
+	seen := map[string]bool{}
+	for i, s := range req.Submissions {
+		ids[i] = s.ID
+		if ids[i] == "" {
+			ids[i] = strconv.Itoa(i + 1)
+		}
+		if seen[ids[i]] {
+			httpError(w, "duplicate submission id "+ids[i], http.StatusBadRequest)
+			return
+		}
+		seen[ids[i]] = true
+		if s.Code == "" {
+			httpError(w, "submission "+ids[i]+": code is required", http.StatusBadRequest)
+			return
+		}
+		if msg := codeTooLarge(s.Code); msg != "" {
+			writeAPIError(w, http.StatusRequestEntityTooLarge, "code_too_large", "submission "+ids[i]+": "+msg)
+			return
+		}
+		name := s.Language
+		if name == "" {
+			name = req.Language
+		}
+		if msg := unsupportedLanguage(name); msg != "" {
+			writeAPIError(w, http.StatusBadRequest, "unsupported_language", "submission "+ids[i]+": "+msg)
+			return
+		}
+		language := mapLanguage(name)
+		if language == "" {
+			language = DetectLanguage(s.Code)
+		}
+		if status, msg := authorizeAnalysis(principal, language, false); status != 0 {
+			rejectAnalysis(w, status, msg)
+			return
+		}
+		normalized[i] = normalizeTokens(s.Code, language)
+	}
+
+	response := APICompareResponse{MinMatch: minMatch, Pairs: []APIComparePair{}}
+	for i := range normalized {
+		for j := i + 1; j < len(normalized); j++ {
+			similarity, matches := comparePair(normalized[i], normalized[j], minMatch)
+			response.Pairs = append(response.Pairs, APIComparePair{A: ids[i], B: ids[j], Similarity: similarity, Matches: matches})
+		}
+	}
+	sort.SliceStable(response.Pairs, func(i, j int) bool { return response.Pairs[i].Similarity > response.Pairs[j].Similarity })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
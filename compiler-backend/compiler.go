@@ -1,4 +1,4 @@
-// Mini‑compilador: lexer + analyzer + ejecución real (JS, Python, C++)
+// Mini‑compilador: lexer + analyzer + ejecución real (JS, Python, C++, Go)
 // -------------------------------------------------------------------------
 // $ go run main.go archivo.cpp
 //
@@ -6,8 +6,10 @@
 //   • g++  (C++17)
 //   • node (>=14)
 //   • python3 (>=3.8)
+//   • go (>=1.18)
 // Los snippets se escriben en archivos temporales, se compilan/ejecutan y se
-// devuelve stdout + stderr.  Usa context con timeout de 4 s por seguridad.
+// devuelve stdout + stderr.  Usa context con timeout (4 s por defecto,
+// configurable con CompilerConfig.ExecutionTimeout) por seguridad.
 
 package main
 
@@ -18,9 +20,11 @@ import (
     "os/exec"
     "path/filepath"
     "regexp"
+    "sort"
     "strconv"
     "strings"
     "time"
+    "unicode/utf8"
 )
 
 // ───────────────────────── Tipos básicos ────────────────────────────────
@@ -41,34 +45,74 @@ const (
     CONSTANT
     OPERATOR
     DELIMITER
+    PREPROCESSOR // directiva completa de C++ (#include <iostream>)
 )
 
 func (t TokenType) String() string {
-    return [...]string{"UNKNOWN", "WHITESPACE", "COMMENT", "STRING", "NUMBER", "KEYWORD", "IDENTIFIER", "FUNCTION", "CLASS", "VARIABLE", "CONSTANT", "OPERATOR", "DELIMITER"}[t]
+    return [...]string{"UNKNOWN", "WHITESPACE", "COMMENT", "STRING", "NUMBER", "KEYWORD", "IDENTIFIER", "FUNCTION", "CLASS", "VARIABLE", "CONSTANT", "OPERATOR", "DELIMITER", "PREPROCESSOR"}[t]
 }
 
 type Token struct {
     Type       TokenType
     Lexeme     string
     Start, End int
+    // Posición de inicio y fin en líneas y columnas (base 1, columnas en
+    // caracteres); el lexer las calcula al avanzar para no recorrer el código
+    // de nuevo por cada token
+    Line, Column       int
+    EndLine, EndColumn int
 }
 
+// ParseNode es un nodo del árbol sintáctico: Kind es la construcción
+// gramatical (FunctionDecl, If, BinaryExpr...), Label su texto representativo
+// y Pos/End el rango en bytes del código fuente que cubre.
 type ParseNode struct {
+    Kind     string
     Label    string
     Children []ParseNode
+    Pos, End int
 }
 
 type Symbol struct {
     Name string
     Kind string
-    Pos  int
+    Type string // tipo inferido por el verificador de tipos ("" si se desconoce)
+    // Valor inicial si es una expresión constante (int x = 3 * 5 → "15")
+    Value string
+    Pos   int
+    // Procedimiento o función que lo declara ("" es el alcance global)
+    Scope string
+    // Posiciones de cada uso del símbolo, en orden ("buscar usos" del editor)
+    References []int
+    // Parámetros de una función con una única firma; typ es "" si no se conoce
+    Params []funcParam
 }
 
 type CompilerError struct {
     Message  string
     Severity string // "error" | "warning"
-    Type     string // "lexico" | "sintactico" | "semantico"
+    Type     string // "lexico" | "sintactico" | "semantico" | "ejecucion"
     Pos      int
+    // Código estable del catálogo (ver errorcodes.go) y sugerencia de
+    // corrección legible por máquina; "" usa la del catálogo
+    Code     string
+    Hint     string
+    // Línea y columna (base 1, en caracteres) que informa un compilador
+    // externo; locateExternalErrors las traduce a Pos. Column 0 indica que
+    // el mensaje solo da la línea
+    Line     int
+    Column   int
+    // Quién informó el diagnóstico con ejecución real: "compilador" o
+    // "ambos" si coincidió con uno del análisis, cuyo mensaje se conserva y
+    // el del compilador queda en CompilerMessage; "" es del análisis
+    Source          string
+    CompilerMessage string
+    // Declaración a la que se refiere el diagnóstico: la función llamada con
+    // argumentos incorrectos o la variable que se llama como función
+    Declaration *int
+    // Column cuenta desde el primer carácter no blanco de la línea, como en
+    // los tracebacks de Python, que citan las líneas sin sangría
+    columnFromIndent bool
 }
 
 type AnalysisPhase struct {
@@ -85,9 +129,51 @@ type AnalysisPhases struct {
     Semantic AnalysisPhase
 }
 
+// AnalysisTiming es lo que tardó cada fase. Los chequeos léxicos corren a la
+// vez que el parser y la ejecución a la vez que el análisis semántico, así
+// que la suma puede superar ProcessingTime
+type AnalysisTiming struct {
+    Lexical   time.Duration
+    Syntax    time.Duration
+    Semantic  time.Duration
+    Execution time.Duration
+}
+
+// Output es la salida combinada que se muestra y se analiza en busca de
+// errores; las fases se separan en CompileOutput (g++, tsc, go build) y en
+// RunStdout/RunStderr del programa. ExitCode es nil si el programa no llegó
+// a terminar por sí mismo (no compiló, tiempo excedido, límite)
 type ExecutionResult struct {
-    Output string
-    Ok     bool
+    Output        string
+    Ok            bool
+    CompileOutput string
+    RunStdout     string
+    RunStderr     string
+    ExitCode      *int
+    // Tiempo real de la ejecución, memoria residente máxima y CPU de
+    // usuario y de sistema; 0 si no se midieron (Docker, fuera de Linux)
+    DurationMs    int64
+    PeakMemory    int64
+    UserCPUMs     int64
+    SystemCPUMs   int64
+    // Lo detuvo el límite de tiempo; no compiló (el programa no se ejecutó)
+    TimedOut      bool
+    CompileError  bool
+    // Cada ejecución si hubo más de una entrada (casos de prueba); los
+    // campos de arriba son los de la primera que falló
+    Runs          []ExecutionResult
+    // El resultado depende de la carga del servidor (ocupado, tiempo
+    // excedido) y no debe guardarse en la caché de resultados
+    Transient bool
+    // Líneas que el ejecutor agregó antes del código enviado; se restan de
+    // las posiciones que informa el compilador
+    LineOffset int
+    // Estado del programa al llegar a la línea del breakpoint (ver
+    // trace.go); nil si no se pidió o el programa no llegó a ella
+    Breakpoint *APITraceStep
+    // El documento para mostrar en el frontend si el código es HTML (ver
+    // html.go)
+    HTML *APIHTMLPreview
 }
 
 type AnalyzeResponse struct {
@@ -97,14 +183,24 @@ type AnalyzeResponse struct {
     SymbolTable     []Symbol
     Errors          []CompilerError
     ExecutionResult *ExecutionResult
-    CanExecute      bool
+    // Si el programa se puede ejecutar y por qué no (ver executability.go)
+    Executability   Executability
     AnalysisPhases  AnalysisPhases
     ProcessingTime  time.Duration
+    Timing          AnalysisTiming
+    // Respuesta tomada de la caché de resultados (ver cache.go)
+    Cached          bool
+    // Ensamblador o bytecode de la herramienta real (ver generated.go)
+    GeneratedCode   *GeneratedCode
+    // Veredicto del modo juez si se pidió (ver judge.go)
+    Judge           *JudgeResult
+    // Veredictos y puntaje de los casos de prueba, si se enviaron
+    Tests           *TestsResult
+    // Métricas de calidad del código (ver metrics.go); nil si no se llegó
+    // al análisis sintáctico
+    Metrics         *CodeMetrics
 }
 
-// Config global: activa la ejecución real por defecto
-var GlobalConfig = struct{ EnableRealExecution bool }{EnableRealExecution: true}
-
 // ─────────────────────────────── Lexer ───────────────────────────────────
 
 var GeneralPatterns = struct {
@@ -113,9 +209,12 @@ var GeneralPatterns = struct {
     String     *regexp.Regexp
     Whitespace *regexp.Regexp
 }{
-    Identifier: regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*`),
+    // Letras Unicode: identificadores como `año` o `número` son válidos
+    Identifier: regexp.MustCompile(`^[\p{L}_][\p{L}\p{N}_]*`),
     Number:     regexp.MustCompile(`^(?:\d+\.?\d*|\.\d+)(?:[eE][+-]?\d+)?`),
-    String:     regexp.MustCompile("^(?:\"(?:[^\"\\\\]|\\\\.)*\"|'(?:[^'\\\\]|\\\\.)*'|`(?:[^`\\\\]|\\\\.)*`)"),
+    // Las comillas simples y dobles no pasan de la línea salvo con '\' al
+    // final; el template literal sí ocupa varias
+    String:     regexp.MustCompile("^(?:\"(?:[^\"\\\\\n]|\\\\[\\s\\S])*\"|'(?:[^'\\\\\n]|\\\\[\\s\\S])*'|`(?:[^`\\\\]|\\\\[\\s\\S])*`)"),
     Whitespace: regexp.MustCompile(`^\s+`),
 }
 
@@ -127,13 +226,15 @@ type LanguagePatterns struct {
     Delimiters           *regexp.Regexp
 }
 
+// Un comentario de bloque sin cerrar llega hasta el final del código, como
+// en los compiladores: lo que sigue no se lee como código y
+// checkLexicalErrors lo reporta una sola vez, donde empieza
 var LanguageSpecificPatterns = map[string]LanguagePatterns{
     "cpp": {
         Keywords: []*regexp.Regexp{
-            regexp.MustCompile(`^\s*#\s*(?:include|define|ifdef|ifndef|endif|if|else|elif|pragma|undef|line|error|warning)\b`),
-            regexp.MustCompile(`\b(?:alignas|and|asm|auto|bool|break|case|catch|char|class|const|constexpr|continue|decltype|delete|do|double|else|enum|explicit|export|extern|false|float|for|friend|goto|if|inline|int|long|mutable|namespace|new|noexcept|nullptr|operator|override|private|protected|public|register|return|short|signed|sizeof|static|struct|switch|template|this|throw|true|try|typedef|typename|union|unsigned|using|virtual|void|volatile|while)\b`),
+            regexp.MustCompile(`^(?:alignas|and|asm|auto|bool|break|case|catch|char|class|const|constexpr|continue|decltype|default|delete|do|double|else|enum|explicit|export|extern|false|float|for|friend|goto|if|inline|int|long|mutable|namespace|new|noexcept|nullptr|operator|override|private|protected|public|register|return|short|signed|sizeof|static|struct|switch|template|this|throw|true|try|typedef|typename|union|unsigned|using|virtual|void|volatile|while)\b`),
         },
-        Comments:   regexp.MustCompile(`^(?:(?://[^\n]*)|(?:/\*[\s\S]*?\*/))`),
+        Comments:   regexp.MustCompile(`^(?:(?://[^\n]*)|(?:/\*(?:[\s\S]*?\*/|[\s\S]*)))`),
         Functions:  regexp.MustCompile(`^([a-zA-Z_]\w*(?:\s*::\s*[a-zA-Z_]\w*)?)\s*\([^()]*\)`),
         Classes:    regexp.MustCompile(`^class\s+([a-zA-Z_]\w*)`),
         Variables:  regexp.MustCompile(`^(?:auto|bool|char|double|float|int|long|short|string)\s+([a-zA-Z_]\w*)`),
@@ -143,19 +244,90 @@ var LanguageSpecificPatterns = map[string]LanguagePatterns{
     },
     "javascript": {
         Keywords: []*regexp.Regexp{
-            regexp.MustCompile(`\b(?:var|let|const|function|return|if|else|for|while|do|switch|case|break|continue|try|catch|finally|throw|new|this|typeof|instanceof|in|of|class|extends|super|static|import|export|from|as|async|await|true|false|null|undefined)\b`),
+            regexp.MustCompile(`^(?:var|let|const|function|return|if|else|for|while|do|switch|case|break|continue|try|catch|finally|throw|new|this|typeof|instanceof|in|of|class|extends|super|static|import|export|from|as|async|await|true|false|null|undefined)\b`),
         },
-        Comments:   regexp.MustCompile(`^(?:(?://[^\n]*)|(?:/\*[\s\S]*?\*/))`),
+        Comments:   regexp.MustCompile(`^(?:(?://[^\n]*)|(?:/\*(?:[\s\S]*?\*/|[\s\S]*)))`),
         Functions:  regexp.MustCompile(`^(?:function\s+)?([a-zA-Z_$][\w$]*)\s*\([^)]*\)`),
         Classes:    regexp.MustCompile(`^class\s+([a-zA-Z_$][\w$]*)`),
         Variables:  regexp.MustCompile(`^(?:var|let|const)\s+([a-zA-Z_$][\w$]*)`),
         Constants:  regexp.MustCompile(`^const\s+([a-zA-Z_$][\w$]*)`),
-        Operators:  regexp.MustCompile(`^(===|!==|>>>=?|<<=|>>=|<=|>=|==|!=|\+\+|--|\*\*|&&|\|\||=>|[+\-*/%=&|^~<>!?])`),
+        Operators:  regexp.MustCompile(`^(===|!==|>>>=?|<<=?|>>=?|<=|>=|==|!=|\+\+|--|\*\*|&&|\|\||=>|[+\-*/%=&|^~<>!?])`),
         Delimiters: regexp.MustCompile(`^[()\[\]{};,.:\?]`),
     },
+    // TypeScript: JavaScript más las palabras de tipos y declaraciones; '@'
+    // inicia un decorador
+    "typescript": {
+        Keywords: []*regexp.Regexp{
+            regexp.MustCompile(`^(?:var|let|const|function|return|if|else|for|while|do|switch|case|break|continue|try|catch|finally|throw|new|this|typeof|instanceof|in|of|class|extends|super|static|import|export|from|as|async|await|true|false|null|undefined|interface|type|enum|implements|namespace|module|declare|abstract|readonly|private|protected|public|override|keyof|infer|is|asserts|satisfies|unique|global|any|unknown|never|void|number|string|boolean|bigint)\b`),
+        },
+        Comments:   regexp.MustCompile(`^(?:(?://[^\n]*)|(?:/\*(?:[\s\S]*?\*/|[\s\S]*)))`),
+        Functions:  regexp.MustCompile(`^(?:function\s+)?([a-zA-Z_$][\w$]*)\s*(?:<[^>]*>)?\s*\([^)]*\)`),
+        Classes:    regexp.MustCompile(`^(?:abstract\s+)?class\s+([a-zA-Z_$][\w$]*)`),
+        Variables:  regexp.MustCompile(`^(?:var|let|const)\s+([a-zA-Z_$][\w$]*)`),
+        Constants:  regexp.MustCompile(`^const\s+([a-zA-Z_$][\w$]*)`),
+        Operators:  regexp.MustCompile(`^(===|!==|>>>=?|<<=?|>>=?|<=|>=|==|!=|\+\+|--|\*\*|&&|\|\||=>|[+\-*/%=&|^~<>!?])`),
+        Delimiters: regexp.MustCompile(`^[()\[\]{};,.:\?@]`),
+    },
+    // CSS: las palabras clave son las at-rules y !important; números,
+    // nombres con guiones y #hash tienen sus propios reconocedores (css.go)
+    "css": {
+        Keywords: []*regexp.Regexp{
+            regexp.MustCompile(`^@-?[a-zA-Z][\w-]*`),
+            regexp.MustCompile(`^!\s*important\b`),
+        },
+        Comments:   regexp.MustCompile(`^/\*(?:[\s\S]*?\*/|[\s\S]*)`),
+        Functions:  regexp.MustCompile(`^([a-zA-Z-]+)\(`),
+        Classes:    regexp.MustCompile(`^\.(-?[a-zA-Z_][\w-]*)`),
+        Variables:  regexp.MustCompile(`^(--[\w-]+)\s*:`),
+        Constants:  regexp.MustCompile(`^#([0-9a-fA-F]{3,8})\b`),
+        Operators:  regexp.MustCompile(`^([~|^$*]=|[>+~*=/&!-])`),
+        Delimiters: regexp.MustCompile(`^[()\[\]{};,.:]`),
+    },
+    // HTML: la única palabra clave es el doctype; etiquetas, atributos y
+    // texto tienen sus propios reconocedores (html.go)
+    "html": {
+        Keywords: []*regexp.Regexp{
+            regexp.MustCompile(`(?i)^<!doctype[^>]*>`),
+        },
+        // Un comentario sin cerrar llega hasta el final, como en el navegador
+        Comments:   regexp.MustCompile(`^<!--(?:[\s\S]*?-->|[\s\S]*)`),
+        Classes:    regexp.MustCompile(`\bclass\s*=\s*"([^"]*)"`),
+        Constants:  regexp.MustCompile(`^&(?:[a-zA-Z]+|#\d+|#x[0-9a-fA-F]+);`),
+        Operators:  regexp.MustCompile(`^=`),
+        Delimiters: regexp.MustCompile(`^/?>`),
+    },
+    // SQL: las palabras clave no distinguen mayúsculas e incluyen los tipos;
+    // cadenas, números, nombres y variables tienen sus reconocedores (sql.go)
+    "tsql": {
+        Keywords: []*regexp.Regexp{
+            regexp.MustCompile(`(?i)^(?:SELECT|FROM|WHERE|AND|OR|NOT|IN|IS|NULL|LIKE|BETWEEN|EXISTS|AS|ON|JOIN|INNER|LEFT|RIGHT|FULL|OUTER|CROSS|APPLY|UNION|ALL|INTERSECT|EXCEPT|DISTINCT|TOP|GROUP|BY|HAVING|ORDER|ASC|DESC|OFFSET|FETCH|INSERT|INTO|VALUES|UPDATE|SET|DELETE|MERGE|TRUNCATE|CREATE|ALTER|DROP|TABLE|VIEW|INDEX|PROCEDURE|PROC|FUNCTION|TRIGGER|RETURNS|RETURN|BEGIN|END|DECLARE|IF|ELSE|WHILE|BREAK|CONTINUE|CASE|WHEN|THEN|GO|EXEC|EXECUTE|PRINT|RAISERROR|THROW|TRY|CATCH|TRAN|TRANSACTION|COMMIT|ROLLBACK|GRANT|REVOKE|USE|PRIMARY|FOREIGN|KEY|REFERENCES|UNIQUE|CHECK|CONSTRAINT|DEFAULT|IDENTITY|CURSOR|OPEN|CLOSE|DEALLOCATE|OUTPUT|WITH|INT|INTEGER|BIGINT|SMALLINT|TINYINT|BIT|DECIMAL|NUMERIC|FLOAT|REAL|MONEY|CHAR|VARCHAR|NCHAR|NVARCHAR|TEXT|DATE|DATETIME|DATETIME2|TIME|UNIQUEIDENTIFIER|VARBINARY)\b`),
+        },
+        Comments:   regexp.MustCompile(`^(?:--[^\n]*|/\*(?:[\s\S]*?\*/|[\s\S]*))`),
+        Operators:  regexp.MustCompile(`^(?:<>|!=|>=|<=|\+=|-=|\*=|/=|[+\-*/%=<>&|^~])`),
+        Delimiters: regexp.MustCompile(`^[(),;.]`),
+    },
+    "plsql": {
+        Keywords: []*regexp.Regexp{
+            regexp.MustCompile(`(?i)^(?:SELECT|FROM|WHERE|AND|OR|NOT|IN|IS|NULL|LIKE|BETWEEN|EXISTS|AS|ON|JOIN|INNER|LEFT|RIGHT|FULL|OUTER|CROSS|UNION|ALL|INTERSECT|MINUS|DISTINCT|GROUP|BY|HAVING|ORDER|ASC|DESC|OFFSET|FETCH|INSERT|INTO|VALUES|UPDATE|SET|DELETE|MERGE|TRUNCATE|CREATE|REPLACE|ALTER|DROP|TABLE|VIEW|INDEX|SEQUENCE|PROCEDURE|FUNCTION|PACKAGE|BODY|TRIGGER|RETURN|BEGIN|END|DECLARE|IF|ELSIF|ELSE|WHILE|LOOP|FOR|REVERSE|EXIT|CONTINUE|CASE|WHEN|THEN|EXCEPTION|RAISE|CONSTANT|CURSOR|OPEN|CLOSE|COMMIT|ROLLBACK|GRANT|REVOKE|PRIMARY|FOREIGN|KEY|REFERENCES|UNIQUE|CHECK|CONSTRAINT|DEFAULT|WITH|TRUE|FALSE|NUMBER|INTEGER|PLS_INTEGER|BINARY_INTEGER|VARCHAR2|NVARCHAR2|VARCHAR|CHAR|DATE|TIMESTAMP|BOOLEAN|CLOB|BLOB)\b`),
+        },
+        Comments:   regexp.MustCompile(`^(?:--[^\n]*|/\*(?:[\s\S]*?\*/|[\s\S]*))`),
+        Operators:  regexp.MustCompile(`^(?:<>|!=|\^=|>=|<=|:=|=>|\|\||\.\.|[+\-*/%=<>])`),
+        Delimiters: regexp.MustCompile(`^[(),;.]`),
+    },
+    // Pascal: palabras clave sin distinguir mayúsculas; cadenas con comilla
+    // simple y números con $, % y & tienen sus reconocedores (pascal.go).
+    // Un comentario sin cerrar llega hasta el final, como en fpc.
+    "pascal": {
+        Keywords: []*regexp.Regexp{
+            regexp.MustCompile(`(?i)^(?:and|array|as|begin|case|class|const|constructor|destructor|div|do|downto|else|end|except|false|file|finally|for|function|goto|if|in|inherited|interface|is|label|mod|nil|not|object|of|or|packed|procedure|program|raise|record|repeat|set|shl|shr|then|to|true|try|type|unit|until|uses|var|while|with|xor)\b`),
+        },
+        Comments:   regexp.MustCompile(`^(?:\{[^}]*\}?|\(\*(?:[\s\S]*?\*\)|[\s\S]*)|//[^\n]*)`),
+        Operators:  regexp.MustCompile(`^(?::=|<>|<=|>=|\.\.|[+\-*/=<>^@])`),
+        Delimiters: regexp.MustCompile(`^[()\[\];,.:]`),
+    },
     "python": {
         Keywords: []*regexp.Regexp{
-            regexp.MustCompile(`\b(?:and|as|assert|async|await|break|class|continue|def|del|elif|else|except|False|finally|for|from|global|if|import|in|is|lambda|nonlocal|None|not|or|pass|raise|return|True|try|while|with|yield)\b`),
+            regexp.MustCompile(`^(?:and|as|assert|async|await|break|class|continue|def|del|elif|else|except|False|finally|for|from|global|if|import|in|is|lambda|nonlocal|None|not|or|pass|raise|return|True|try|while|with|yield)\b`),
         },
         Comments:   regexp.MustCompile(`^#[^\n]*`),
         Functions:  regexp.MustCompile(`^def\s+([a-zA-Z_]\w*)\s*\(`),
@@ -165,14 +337,57 @@ var LanguageSpecificPatterns = map[string]LanguagePatterns{
         Operators:  regexp.MustCompile(`^(//|<<|>>|<=|>=|==|!=|\*\*|and|or|not|is|in|[+\-*/%=&|^~<>])`),
         Delimiters: regexp.MustCompile(`^[()\[\]{};,.:@]`),
     },
+    "go": {
+        Keywords: []*regexp.Regexp{
+            regexp.MustCompile(`^(?:break|case|chan|const|continue|default|defer|else|fallthrough|for|func|go|goto|if|import|interface|map|package|range|return|select|struct|switch|type|var|true|false|nil)\b`),
+        },
+        Comments:   regexp.MustCompile(`^(?:(?://[^\n]*)|(?:/\*(?:[\s\S]*?\*/|[\s\S]*)))`),
+        Functions:  regexp.MustCompile(`^func\s+(?:\([^)]*\)\s*)?([a-zA-Z_]\w*)\s*\(`),
+        Classes:    regexp.MustCompile(`^type\s+([a-zA-Z_]\w*)\s+struct`),
+        Variables:  regexp.MustCompile(`^(?:var\s+([a-zA-Z_]\w*)|([a-zA-Z_]\w*)\s*:=)`),
+        Constants:  regexp.MustCompile(`^const\s+([a-zA-Z_]\w*)`),
+        Operators:  regexp.MustCompile(`^(:=|<-|&\^=?|\.\.\.|<<=?|>>=?|\+\+|--|&&|\|\||==|!=|<=|>=|[+\-*/%&|^]=|[+\-*/%=&|^~<>!])`),
+        Delimiters: regexp.MustCompile(`^[()\[\]{};,.:]`),
+    },
+    "kotlin": {
+        Keywords: []*regexp.Regexp{
+            regexp.MustCompile(`^(?:abstract|as|break|by|catch|class|companion|const|constructor|continue|data|do|else|enum|false|finally|for|fun|if|import|in|init|inline|interface|internal|is|lateinit|null|object|open|override|package|private|protected|public|return|sealed|super|suspend|this|throw|true|try|typealias|val|var|vararg|when|while)\b`),
+        },
+        Comments:   regexp.MustCompile(`^(?:(?://[^\n]*)|(?:/\*(?:[\s\S]*?\*/|[\s\S]*)))`),
+        Functions:  regexp.MustCompile(`^fun\s+(?:<[^>]*>\s*)?(?:[a-zA-Z_]\w*\.)?([a-zA-Z_]\w*)\s*\(`),
+        Classes:    regexp.MustCompile(`^(?:class|object|interface)\s+([a-zA-Z_]\w*)`),
+        Variables:  regexp.MustCompile(`^var\s+([a-zA-Z_]\w*)`),
+        Constants:  regexp.MustCompile(`^val\s+([a-zA-Z_]\w*)`),
+        Operators:  regexp.MustCompile(`^(===|!==|\.\.<|\?\.|\?:|!!|::|\.\.|->|\+\+|--|&&|\|\||==|!=|<=|>=|[+\-*/%]=|[+\-*/%=<>!?])`),
+        Delimiters: regexp.MustCompile(`^[()\[\]{};,.:@]`),
+    },
+    "swift": {
+        Keywords: []*regexp.Regexp{
+            regexp.MustCompile(`^(?:associatedtype|as|async|await|break|case|catch|class|continue|default|defer|deinit|do|else|enum|extension|fallthrough|false|fileprivate|final|for|func|guard|if|import|init|inout|internal|in|is|lazy|let|mutating|nil|open|operator|override|private|protocol|public|repeat|rethrows|return|self|Self|some|static|struct|subscript|super|switch|throws|throw|true|try|typealias|var|weak|where|while)\b`),
+        },
+        Comments:   regexp.MustCompile(`^(?:(?://[^\n]*)|(?:/\*(?:[\s\S]*?\*/|[\s\S]*)))`),
+        Functions:  regexp.MustCompile(`^func\s+([a-zA-Z_]\w*)\s*(?:<[^>]*>)?\s*\(`),
+        Classes:    regexp.MustCompile(`^(?:class|struct|enum|protocol)\s+([a-zA-Z_]\w*)`),
+        Variables:  regexp.MustCompile(`^var\s+([a-zA-Z_]\w*)`),
+        Constants:  regexp.MustCompile(`^let\s+([a-zA-Z_]\w*)`),
+        Operators:  regexp.MustCompile(`^(===|!==|\.\.\.|\.\.<|\?\?|\?\.|->|<<=?|>>=?|&&|\|\||==|!=|<=|>=|[+\-*/%&|^]=|[+\-*/%=&|^~<>!?])`),
+        Delimiters: regexp.MustCompile(`^[()\[\]{};,.:@#]`),
+    },
 }
 
-// escáner
+// escáner. Los patrones empiezan con ^: sin ancla FindStringIndex buscaría
+// la coincidencia en todo el resto del código en cada posición. Un patrón
+// nil (el lenguaje genérico no tiene comentarios ni palabras clave) no
+// coincide nunca
 func matchHere(rx *regexp.Regexp, src string, pos int) (string, bool) {
-    if pos >= len(src) {
+    if rx == nil || pos >= len(src) {
         return "", false
     }
-    if loc := rx.FindStringIndex(src[pos:]); loc != nil && loc[0] == 0 {
+    p := lexPatternOf(rx)
+    if !p.start[src[pos]] {
+        return "", false
+    }
+    if loc := rx.FindStringIndex(p.window(src, pos)); loc != nil && loc[0] == 0 {
         return src[pos : pos+loc[1]], true
     }
     return "", false
@@ -198,6 +413,43 @@ func strlit(_ *LanguagePatterns, s string, p int) (TokenType, string) {
     }
     return UNKNOWN, ""
 }
+
+// unclosedString reconoce una cadena entre comillas que strlit no pudo
+// cerrar, hasta el final de la línea. Es un token UNKNOWN, que el parser
+// salta y checkLexicalErrors reporta en su comienzo
+func unclosedString(_ *LanguagePatterns, s string, p int) (TokenType, string) {
+    if s[p] != '"' && s[p] != '\'' {
+        return UNKNOWN, ""
+    }
+    end := p + 1
+    for end < len(s) && s[end] != '\n' {
+        if s[end] == '\\' && end+1 < len(s) {
+            end++
+        }
+        end++
+    }
+    return UNKNOWN, strings.TrimRight(s[p:end], "\r")
+}
+
+// tripleString reconoce las cadenas de Python entre comillas triples, que
+// pueden ocupar varias líneas; sin cierre llega al final del código como
+// token UNKNOWN
+func tripleString(_ *LanguagePatterns, s string, p int) (TokenType, string) {
+    rest := s[p:]
+    if !strings.HasPrefix(rest, `"""`) && !strings.HasPrefix(rest, "'''") {
+        return UNKNOWN, ""
+    }
+    quote := rest[:3]
+    for i := 3; i < len(rest); i++ {
+        if rest[i] == '\\' {
+            i++
+        } else if strings.HasPrefix(rest[i:], quote) {
+            return STRING, rest[:i+3]
+        }
+    }
+    return UNKNOWN, rest
+}
+
 func number(_ *LanguagePatterns, s string, p int) (TokenType, string) {
     if lex, ok := matchHere(GeneralPatterns.Number, s, p); ok {
         return NUMBER, lex
@@ -231,216 +483,178 @@ func delim(lp *LanguagePatterns, s string, p int) (TokenType, string) {
     return UNKNOWN, ""
 }
 
-var order = []matcher{whitespace, comment, strlit, number, keyword, ident, oper, delim}
+var order = []matcher{whitespace, comment, strlit, unclosedString, number, keyword, ident, oper, delim}
+
+// En C++ las directivas del preprocesador se reconocen antes que el resto y
+// las cadenas con prefijo (ver literals.go) antes que los identificadores.
+// Los números de C++, Python, JavaScript y Go están en numbers.go
+var cppOrder = []matcher{whitespace, directive, cppString, comment, strlit, unclosedString, cppNumber, keyword, ident, oper, delim}
+
+// En Python las comillas triples se prueban antes que las simples: """ no
+// es la cadena vacía seguida de otra. Las cadenas con prefijo (f"", b"")
+// están en literals.go
+var pythonOrder = []matcher{whitespace, comment, pythonString, tripleString, strlit, unclosedString, pythonNumber, keyword, ident, oper, delim}
 
 func Tokenize(src, lang string) []Token {
-    lp := LanguageSpecificPatterns[lang]
+    return tokenizeFrom(src, lang, 0, 1, 1, nil)
+}
+
+// tokenizeFrom tokeniza src a partir de pos, que está en la línea y columna
+// indicadas. Si stop no es nil se detiene antes del primer token para el que
+// devuelve verdadero (re-tokenización incremental).
+func tokenizeFrom(src, lang string, pos, line, col int, stop func(Token) bool) []Token {
+    language := languageFor(lang)
+    lp := language.Patterns()
+    matchers := language.Matchers()
     var out []Token
-    for pos := 0; pos < len(src); {
-        matched := false
-        for _, fn := range order {
-            if typ, lex := fn(&lp, src, pos); typ != UNKNOWN {
-                if typ != WHITESPACE {
-                    out = append(out, Token{Type: typ, Lexeme: lex, Start: pos, End: pos + len(lex)})
-                }
-                pos += len(lex)
-                matched = true
+    for pos < len(src) {
+        typ, lex := UNKNOWN, ""
+        for _, fn := range matchers {
+            // Un reconocedor puede devolver un token UNKNOWN de varios
+            // caracteres, como una cadena sin cerrar
+            if typ, lex = fn(&lp, src, pos); lex != "" {
                 break
             }
         }
-        if !matched {
-            out = append(out, Token{Type: UNKNOWN, Lexeme: string(src[pos]), Start: pos, End: pos + 1})
-            pos++
+        if lex == "" {
+            // Avanzar un carácter completo y no un byte, para no partir
+            // secuencias UTF-8 como 'ñ' o un emoji en tokens inválidos
+            _, size := utf8.DecodeRuneInString(src[pos:])
+            lex = src[pos : pos+size]
         }
+        endLine, endCol := advanceLineColumn(line, col, lex)
+        if typ != WHITESPACE {
+            tk := Token{Type: typ, Lexeme: lex, Start: pos, End: pos + len(lex),
+                Line: line, Column: col, EndLine: endLine, EndColumn: endCol}
+            if stop != nil && stop(tk) {
+                break
+            }
+            out = append(out, tk)
+        }
+        pos += len(lex)
+        line, col = endLine, endCol
     }
     return out
 }
 
-// ───────────────────── Stub Parser & Semantics ───────────────────────────
-
-type Parser struct{ tokens []Token }
-func NewParser(t []Token, _ string) *Parser { return &Parser{tokens: t} }
-func (p *Parser) Parse() ([]ParseNode, []CompilerError) {
-    var n []ParseNode
-    for _, tk := range p.tokens { n = append(n, ParseNode{Label: tk.Lexeme}) }
-    
-    var errors []CompilerError
-    
-    // Errores sintácticos más realistas
-    parentheses := 0
-    braces := 0
-    brackets := 0
-    
-    for i, tk := range p.tokens {
-        switch tk.Lexeme {
-        case "(":
-            parentheses++
-        case ")":
-            parentheses--
-            if parentheses < 0 {
-                errors = append(errors, CompilerError{
-                    Message:  "Error sintáctico: Paréntesis de cierre sin apertura correspondiente",
-                    Severity: "error",
-                    Type:     "sintactico",
-                    Pos:      tk.Start,
-                })
-            }
-        case "{":
-            braces++
-        case "}":
-            braces--
-            if braces < 0 {
-                errors = append(errors, CompilerError{
-                    Message:  "Error sintáctico: Llave de cierre sin apertura correspondiente",
-                    Severity: "error",
-                    Type:     "sintactico",
-                    Pos:      tk.Start,
-                })
-            }
-        case "[":
-            brackets++
-        case "]":
-            brackets--
-            if brackets < 0 {
-                errors = append(errors, CompilerError{
-                    Message:  "Error sintáctico: Corchete de cierre sin apertura correspondiente",
-                    Severity: "error",
-                    Type:     "sintactico",
-                    Pos:      tk.Start,
-                })
-            }
-        case ";":
-            if i > 0 && p.tokens[i-1].Lexeme == ";" {
-                errors = append(errors, CompilerError{
-                    Message:  "Error sintáctico: Punto y coma duplicado",
-                    Severity: "warning",
-                    Type:     "sintactico",
-                    Pos:      tk.Start,
-                })
-            }
+// advanceLineColumn devuelve la línea y columna que siguen a text
+func advanceLineColumn(line, col int, text string) (int, int) {
+    for _, r := range text {
+        if r == '\n' {
+            line++
+            col = 1
+        } else {
+            col++
         }
     }
-    
-    // Verificar balanceo al final
-    if parentheses > 0 {
-        errors = append(errors, CompilerError{
-            Message:  fmt.Sprintf("Error sintáctico: %d paréntesis sin cerrar", parentheses),
-            Severity: "error",
-            Type:     "sintactico",
-            Pos:      0,
-        })
-    }
-    if braces > 0 {
-        errors = append(errors, CompilerError{
-            Message:  fmt.Sprintf("Error sintáctico: %d llaves sin cerrar", braces),
-            Severity: "error",
-            Type:     "sintactico",
-            Pos:      0,
-        })
-    }
-    if brackets > 0 {
-        errors = append(errors, CompilerError{
-            Message:  fmt.Sprintf("Error sintáctico: %d corchetes sin cerrar", brackets),
-            Severity: "error",
-            Type:     "sintactico",
-            Pos:      0,
-        })
-    }
-    
-    // Error de tokens vacíos
-    if len(p.tokens) == 0 {
-        errors = append(errors, CompilerError{
-            Message:  "Error sintáctico: No se encontraron tokens válidos",
-            Severity: "error",
-            Type:     "sintactico",
-            Pos:      0,
-        })
-    }
-    
-    return n, errors
+    return line, col
 }
 
+// ───────────────────────────── Semántica ─────────────────────────────────
+
 type SemanticAnalyzer struct{ 
     tokens []Token
+    tree []ParseNode
     language string 
 }
-func NewSemanticAnalyzer(t []Token, _ []ParseNode, lang string) *SemanticAnalyzer { 
-    return &SemanticAnalyzer{tokens: t, language: lang} 
+func NewSemanticAnalyzer(t []Token, tree []ParseNode, lang string) *SemanticAnalyzer { 
+    return &SemanticAnalyzer{tokens: t, tree: tree, language: lang} 
 }
 func (s *SemanticAnalyzer) Analyze() ([]Symbol, []CompilerError) {
+    // Los comentarios se buscan en los tokens originales: C++ los
+    // reemplaza al expandir las macros
+    tokens := s.tokens
+    syms, errors := languageFor(s.language).Analyze(s)
+    return syms, applySuppressions(tokens, errors)
+}
+
+// sortErrorsByPos ordena errors por posición, conservando el orden de los
+// que están en la misma
+func sortErrorsByPos(errors []CompilerError) {
+    sort.SliceStable(errors, func(i, j int) bool { return errors[i].Pos < errors[j].Pos })
+}
+
+// memberOperators preceden al nombre de un miembro (v.push_back, p->x,
+// std::string, a?.b): se resuelve según el objeto, no entre las
+// declaraciones del programa
+var memberOperators = map[string]bool{".": true, "->": true, "::": true, "?.": true}
+
+// analyzeIdentifiers es el análisis genérico: registra las declaraciones,
+// reporta los usos de nombres no declarados, las variables sin usar y las
+// palabras reservadas usadas como nombres, y luego chequea tipos y flujo
+func (s *SemanticAnalyzer) analyzeIdentifiers(lang Language) ([]Symbol, []CompilerError) {
     var syms []Symbol
     var errors []CompilerError
-    
+
     // Mapas para rastrear declaraciones y usos
     declared := make(map[string]int) // nombre -> posición de declaración
     used := make(map[string][]int)   // nombre -> posiciones de uso
+    calls := make(map[int]bool)      // usos seguidos de '(': f(...)
+
+    // Antes de que C++ expanda las macros
+    imports := collectImports(s.tokens, s.language)
     
+    // Go y TypeScript toman las declaraciones del árbol sintáctico; C++
+    // registra y expande las macros
+    decls := lang.RegisterDeclarations(s, declared, used, &syms)
+    
+    // Ámbitos: declarar de nuevo un nombre solo es un error en el mismo
+    // bloque. Lo declarado entre paréntesis (parámetros, for (int i ...))
+    // queda en un ámbito propio de ese paréntesis
+    blocks := []int{0}
+    var parens []int
+    nextBlock := 1
+    declaredIn := make(map[string]map[int]bool)
+    scope := func() int {
+        if len(parens) > 0 {
+            return -1 - parens[len(parens)-1]
+        }
+        return blocks[len(blocks)-1]
+    }
+
     // Primera pasada: identificar declaraciones y usos según el lenguaje
     for i, tk := range s.tokens {
+        switch {
+        case tk.Lexeme == "{" && tk.Type == DELIMITER:
+            blocks = append(blocks, nextBlock)
+            nextBlock++
+        case tk.Lexeme == "}" && tk.Type == DELIMITER && len(blocks) > 1:
+            blocks = blocks[:len(blocks)-1]
+        case tk.Lexeme == "(" && tk.Type == DELIMITER:
+            parens = append(parens, tk.Start)
+        case tk.Lexeme == ")" && tk.Type == DELIMITER && len(parens) > 0:
+            parens = parens[:len(parens)-1]
+        }
         if tk.Type == IDENTIFIER {
-            // Detectar declaraciones específicas por lenguaje
-            isDeclaration := false
-            if i > 0 {
-                prevToken := s.tokens[i-1]
-                
-                switch s.language {
-                case "cpp":
-                    // C++: tipos de datos y palabras clave de declaración
-                    if prevToken.Type == KEYWORD && 
-                       (strings.Contains(prevToken.Lexeme, "int") || 
-                        strings.Contains(prevToken.Lexeme, "char") ||
-                        strings.Contains(prevToken.Lexeme, "string") ||
-                        strings.Contains(prevToken.Lexeme, "float") ||
-                        strings.Contains(prevToken.Lexeme, "double") ||
-                        strings.Contains(prevToken.Lexeme, "bool") ||
-                        strings.Contains(prevToken.Lexeme, "void")) {
-                        isDeclaration = true
-                    }
-                case "javascript":
-                    // JavaScript: var, let, const, function
-                    if prevToken.Type == KEYWORD && 
-                       (prevToken.Lexeme == "var" || 
-                        prevToken.Lexeme == "let" ||
-                        prevToken.Lexeme == "const" ||
-                        prevToken.Lexeme == "function") {
-                        isDeclaration = true
-                    }
-                case "python":
-                    // Python: detectar asignaciones como declaraciones
-                    if i+1 < len(s.tokens) && s.tokens[i+1].Lexeme == "=" {
-                        isDeclaration = true
-                    }
-                    // Python: def para funciones
-                    if prevToken.Type == KEYWORD && prevToken.Lexeme == "def" {
-                        isDeclaration = true
-                    }
-                    // Python: class para clases
-                    if prevToken.Type == KEYWORD && prevToken.Lexeme == "class" {
-                        isDeclaration = true
-                    }
-                default:
-                    // Lenguaje genérico
-                    if prevToken.Type == KEYWORD && 
-                       (strings.Contains(prevToken.Lexeme, "int") || 
-                        strings.Contains(prevToken.Lexeme, "var") ||
-                        strings.Contains(prevToken.Lexeme, "let") ||
-                        strings.Contains(prevToken.Lexeme, "const") ||
-                        strings.Contains(prevToken.Lexeme, "string") ||
-                        strings.Contains(prevToken.Lexeme, "float") ||
-                        strings.Contains(prevToken.Lexeme, "double")) {
-                        isDeclaration = true
-                    }
-                }
+            // Un miembro nunca declara ni se reporta como no declarado;
+            // solo cuenta como uso de un nombre que el programa declara
+            member := i > 0 && memberOperators[s.tokens[i-1].Lexeme]
+            isDeclaration := !member && decls.declaresAt(s.tokens, i)
+            if !isDeclaration && !decls.countsUse(s.tokens, i, declared) {
+                continue
+            }
+            if _, isDeclared := declared[tk.Lexeme]; member && !isDeclared {
+                continue
             }
             
             if isDeclaration {
-                // Verificar redefinición
+                // Verificar redefinición en el mismo ámbito; en otro solo se
+                // registra la primera declaración
+                if declaredIn[tk.Lexeme] == nil {
+                    declaredIn[tk.Lexeme] = make(map[int]bool)
+                }
+                redeclared := declaredIn[tk.Lexeme][scope()]
+                declaredIn[tk.Lexeme][scope()] = true
+                if _, exists := declared[tk.Lexeme]; exists && !redeclared {
+                    continue
+                }
                 if pos, exists := declared[tk.Lexeme]; exists {
                     errors = append(errors, CompilerError{
                         Message:  fmt.Sprintf("Error semántico: Variable '%s' ya fue declarada anteriormente en posición %d", tk.Lexeme, pos),
                         Severity: "error",
                         Type:     "semantico",
                         Pos:      tk.Start,
+                        Code:     CodeRedeclaredVariable,
                     })
                 } else {
                     declared[tk.Lexeme] = tk.Start
@@ -452,53 +666,101 @@ func (s *SemanticAnalyzer) Analyze() ([]Symbol, []CompilerError) {
                         switch prevToken.Lexeme {
                         case "function", "def":
                             symbolKind = "function"
-                        case "class":
+                        case "class", "struct":
                             symbolKind = "class"
                         case "const":
                             symbolKind = "constant"
                         }
                     }
+                    // int suma(...) fuera de toda llave es una función de C
+                    if symbolKind == "var" && len(blocks) == 1 && len(parens) == 0 &&
+                        i+1 < len(s.tokens) && s.tokens[i+1].Lexeme == "(" {
+                        symbolKind = "function"
+                    }
                     
                     syms = append(syms, Symbol{Name: tk.Lexeme, Kind: symbolKind, Pos: tk.Start})
                 }
             } else {
                 // Es un uso
                 used[tk.Lexeme] = append(used[tk.Lexeme], tk.Start)
+                if i+1 < len(s.tokens) && s.tokens[i+1].Lexeme == "(" {
+                    calls[tk.Start] = true
+                }
             }
         }
     }
     
     // Segunda pasada: verificar usos de variables no declaradas
     // Excluir palabras reservadas y funciones built-in
-    builtInFunctions := s.getBuiltInFunctions()
+    keywords := lang.Keywords()
+    builtInFunctions := keywords.Builtins
     
+    // Los maps se recorren en cualquier orden: los errores de cada pasada
+    // se ordenan por posición para que la respuesta sea siempre la misma
+    undeclaredFrom := len(errors)
     for varName, positions := range used {
         if _, isDeclared := declared[varName]; !isDeclared && !builtInFunctions[varName] {
             for _, pos := range positions {
+                message, code := "Error semántico: Variable '%s' no fue declarada", CodeUndeclaredVariable
+                if calls[pos] {
+                    message, code = "Error semántico: Función '%s' no fue declarada", CodeUndeclaredFunction
+                }
                 errors = append(errors, CompilerError{
-                    Message:  fmt.Sprintf("Error semántico: Variable '%s' no fue declarada", varName),
+                    Message:  fmt.Sprintf(message, varName),
                     Severity: "error",
                     Type:     "semantico",
                     Pos:      pos,
+                    Code:     code,
                 })
             }
         }
     }
     
+    sortErrorsByPos(errors[undeclaredFrom:])
+
+    // Referencias cruzadas: cada símbolo con las posiciones de sus usos. Los
+    // tokens expandidos de una macro comparten la posición del uso, por eso
+    // se eliminan las posiciones repetidas
+    for i := range syms {
+        refs := append([]int(nil), used[syms[i].Name]...)
+        sort.Ints(refs)
+        for _, pos := range refs {
+            if n := len(syms[i].References); n == 0 || syms[i].References[n-1] != pos {
+                syms[i].References = append(syms[i].References, pos)
+            }
+        }
+    }
+    
     // Verificar variables declaradas pero no utilizadas
+    symbolKinds := make(map[string]string)
+    for _, sym := range syms {
+        symbolKinds[sym.Name] = sym.Kind
+    }
+    unusedFrom := len(errors)
     for varName, declPos := range declared {
+        if !decls.reportsUnused(varName, symbolKinds[varName]) {
+            continue
+        }
+        // Una macro sin usar no es un error: suele venir de una cabecera. A
+        // main la llama el sistema
+        if symbolKinds[varName] == "macro" || varName == "main" && symbolKinds[varName] == "function" {
+            continue
+        }
         if usages, used := used[varName]; !used || len(usages) == 0 {
             errors = append(errors, CompilerError{
                 Message:  fmt.Sprintf("Error semántico: Variable '%s' fue declarada pero nunca utilizada", varName),
                 Severity: "warning",
                 Type:     "semantico",
                 Pos:      declPos,
+                Code:     CodeUnusedVariable,
             })
         }
     }
     
+    sortErrorsByPos(errors[unusedFrom:])
+
     // Detectar palabras reservadas usadas como identificadores
-    reservedWords := s.getReservedWords()
+    reservedWords := keywords.Reserved
     
     for _, sym := range syms {
         if reservedWords[sym.Name] {
@@ -507,83 +769,40 @@ func (s *SemanticAnalyzer) Analyze() ([]Symbol, []CompilerError) {
                 Severity: "error",
                 Type:     "semantico",
                 Pos:      sym.Pos,
+                Code:     CodeReservedIdentifier,
             })
         }
     }
     
-    return syms, errors
-}
-
-// Obtener funciones built-in según el lenguaje
-func (s *SemanticAnalyzer) getBuiltInFunctions() map[string]bool {
-    switch s.language {
-    case "python":
-        return map[string]bool{
-            "print": true, "len": true, "str": true, "int": true, "float": true,
-            "range": true, "input": true, "type": true, "isinstance": true,
-            "list": true, "dict": true, "tuple": true, "set": true,
-            "min": true, "max": true, "sum": true, "abs": true,
-        }
-    case "javascript":
-        return map[string]bool{
-            "console": true, "alert": true, "prompt": true, "confirm": true,
-            "parseInt": true, "parseFloat": true, "isNaN": true, "String": true,
-            "Number": true, "Boolean": true, "Array": true, "Object": true,
-            "Math": true, "Date": true, "JSON": true, "setTimeout": true,
-            "setInterval": true, "clearTimeout": true, "clearInterval": true,
-        }
-    case "cpp":
-        return map[string]bool{
-            "cout": true, "cin": true, "endl": true, "std": true,
-            "printf": true, "scanf": true, "malloc": true, "free": true,
-            "strlen": true, "strcpy": true, "strcmp": true,
-        }
-    default:
-        return map[string]bool{}
-    }
-}
-
-// Obtener palabras reservadas según el lenguaje
-func (s *SemanticAnalyzer) getReservedWords() map[string]bool {
-    switch s.language {
-    case "python":
-        return map[string]bool{
-            "and": true, "as": true, "assert": true, "async": true, "await": true,
-            "break": true, "class": true, "continue": true, "def": true, "del": true,
-            "elif": true, "else": true, "except": true, "False": true, "finally": true,
-            "for": true, "from": true, "global": true, "if": true, "import": true,
-            "in": true, "is": true, "lambda": true, "nonlocal": true, "None": true,
-            "not": true, "or": true, "pass": true, "raise": true, "return": true,
-            "True": true, "try": true, "while": true, "with": true, "yield": true,
-        }
-    case "javascript":
-        return map[string]bool{
-            "var": true, "let": true, "const": true, "function": true, "return": true,
-            "if": true, "else": true, "for": true, "while": true, "do": true,
-            "switch": true, "case": true, "break": true, "continue": true,
-            "try": true, "catch": true, "finally": true, "throw": true,
-            "new": true, "this": true, "typeof": true, "instanceof": true,
-            "in": true, "of": true, "class": true, "extends": true, "super": true,
-            "static": true, "import": true, "export": true, "from": true, "as": true,
-            "async": true, "await": true, "true": true, "false": true, "null": true,
-            "undefined": true,
-        }
-    case "cpp":
-        return map[string]bool{
-            "if": true, "else": true, "while": true, "for": true, "return": true,
-            "int": true, "float": true, "double": true, "char": true, "void": true,
-            "class": true, "public": true, "private": true, "protected": true,
-            "namespace": true, "using": true, "include": true, "define": true,
-            "bool": true, "true": true, "false": true, "const": true, "static": true,
-            "virtual": true, "override": true, "template": true, "typename": true,
-        }
-    default:
-        return map[string]bool{
-            "if": true, "else": true, "while": true, "for": true, "return": true,
-            "int": true, "float": true, "double": true, "char": true, "void": true,
-            "class": true, "public": true, "private": true, "protected": true,
+    // Inferencia y chequeo de tipos sobre el árbol sintáctico
+    checker := NewTypeChecker(semanticLanguage(s.language))
+    checker.Declarations = declared
+    errors = append(errors, checker.Check(s.tree)...)
+    for i := range syms {
+        syms[i].Type = checker.SymbolTypes[syms[i].Name]
+        syms[i].Value = checker.SymbolValues[syms[i].Name]
+        if sigs := checker.funcs[syms[i].Name]; len(sigs) == 1 {
+            for _, p := range sigs[0].params {
+                if p.typ != tUnknown {
+                    p.typ = checker.displayType(p.typ)
+                } else {
+                    p.typ = ""
+                }
+                syms[i].Params = append(syms[i].Params, p)
+            }
         }
     }
+    
+    // Flujo de control: código inalcanzable, returns faltantes y ciclos infinitos
+    errors = append(errors, NewFlowAnalyzer(semanticLanguage(s.language)).Analyze(s.tree)...)
+    
+    // Errores frecuentes: if (x = 5), x is 5, case sin break...
+    errors = append(errors, NewLinter(semanticLanguage(s.language), s.tokens).Analyze(s.tree)...)
+    
+    // Módulos importados que no están en la biblioteca estándar
+    errors = append(errors, checkImports(imports, semanticLanguage(s.language))...)
+    
+    return syms, errors
 }
 
 // ───────────────────── Ejecutores (real y simulado) ──────────────────────
@@ -594,41 +813,43 @@ type Executor interface { Execute(code string, symbols []Symbol) ExecutionResult
 type FakeExecutor struct{ language string }
 func NewExecutor(lang string) *FakeExecutor { return &FakeExecutor{language: lang} }
 func (e *FakeExecutor) Execute(_ string, _ []Symbol) ExecutionResult {
-    return ExecutionResult{Output: fmt.Sprintf("[simulado %s] OK", e.language), Ok: true}
+    out := fmt.Sprintf("[simulado %s] OK", e.language)
+    return ExecutionResult{Output: out, Ok: true, RunStdout: out}
 }
 
 // --- Real: escribe temp file, llama al intérprete/compilador --------------
-type RealExecutor struct{ language string }
-func NewRealExecutor(lang string) *RealExecutor { return &RealExecutor{language: lang} }
+// Los procesos se ejecutan con los límites de limits.go
+type RealExecutor struct{ language string; timeout time.Duration; input ProgramInput }
+func NewRealExecutor(lang string, timeout time.Duration, input ProgramInput) *RealExecutor { return &RealExecutor{language: lang, timeout: timeout, input: input} }
 
 func (re *RealExecutor) Execute(code string, _ []Symbol) ExecutionResult {
-    switch re.language {
-    case "javascript":
-        return runTemp(".js", code, "node")
-    case "python":
-        return runTemp(".py", code, "python3")
-    case "cpp":
-        return compileAndRunCPP(code)
-    default:
-        return ExecutionResult{Output: "Real executor no soporta " + re.language, Ok: false}
-    }
+    return languageFor(re.language).Execute(re.timeout, limitsFor(re.language), code, re.input)
+}
+
+// timeoutMessage se agrega a la salida de un programa detenido por el límite
+func timeoutMessage(timeout time.Duration) string {
+    return fmt.Sprintf("\nTiempo de ejecución excedido (%s)", timeout)
 }
 
-func runTemp(ext, code, cmdName string) ExecutionResult {
-    file, err := os.CreateTemp("", "snippet-*"+ext)
+// runTemp escribe code en un archivo temporal y ejecuta cmdName args...
+// archivo, seguido de los argumentos del programa, en un directorio de
+// trabajo propio con los archivos auxiliares
+func runTemp(timeout time.Duration, limits processLimits, input ProgramInput, ext, code, cmdName string, args ...string) ExecutionResult {
+    dir, err := os.MkdirTemp("", "run-*")
+    if err != nil { return ExecutionResult{Output: err.Error(), Ok: false} }
+    defer os.RemoveAll(dir)
+    file, err := os.CreateTemp(dir, "snippet-*"+ext)
     if err != nil { return ExecutionResult{Output: err.Error(), Ok: false} }
-    defer os.Remove(file.Name())
     if _, err = file.WriteString(code); err != nil { return ExecutionResult{Output: err.Error(), Ok: false} }
     file.Close()
+    if err := input.writeFiles(dir); err != nil { return ExecutionResult{Output: err.Error(), Ok: false} }
 
-    ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
-    defer cancel()
-    cmd := exec.CommandContext(ctx, cmdName, file.Name())
-    out, err := cmd.CombinedOutput()
-    return ExecutionResult{Output: string(out), Ok: err == nil}
+    return input.runEach(timeout, limits, dir, "", func(ctx context.Context) *exec.Cmd {
+        return exec.CommandContext(ctx, cmdName, append(append(args, file.Name()), input.Args...)...)
+    })
 }
 
-func compileAndRunCPP(code string) ExecutionResult {
+func compileAndRunCPP(timeout time.Duration, limits processLimits, code string, input ProgramInput) ExecutionResult {
     dir, err := os.MkdirTemp("", "cpp-run-*")
     if err != nil { return ExecutionResult{Output: err.Error(), Ok: false} }
     defer os.RemoveAll(dir)
@@ -638,31 +859,167 @@ func compileAndRunCPP(code string) ExecutionResult {
         return ExecutionResult{Output: err.Error(), Ok: false}
     }
     exe := filepath.Join(dir, "prog")
+    // Antes de compilar: también pueden ser cabeceras que main.cpp incluye
+    if err := input.writeFiles(dir); err != nil {
+        return ExecutionResult{Output: err.Error(), Ok: false}
+    }
+
+    ctx, cancel := context.WithTimeout(input.parent(), timeout)
+    defer cancel()
+
+    args := []string{"-std=c++17", src, "-o", exe}
+    if input.Deterministic {
+        args = append(append(args, filepath.Join(dir, deterministicCPPFile)), deterministicCXXFlags...)
+    }
+    compile := exec.CommandContext(ctx, "g++", args...)
+    prepareTool(compile, dir)
+    built := runLimited(ctx, compile, limits)
+    if !built.Ok() {
+        return built.compileFailure(timeout, limits)
+    }
+
+    return input.runEach(timeout, limits, dir, built.Output, func(ctx context.Context) *exec.Cmd {
+        return exec.CommandContext(ctx, exe, input.Args...)
+    })
+}
 
-    ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+// compileAndRunGo compila con go build y ejecuta el binario, así los errores
+// de compilación no se mezclan con la salida del programa y el código de
+// salida es el del programa y no el de go run
+func compileAndRunGo(timeout time.Duration, limits processLimits, code string, input ProgramInput) ExecutionResult {
+    dir, err := os.MkdirTemp("", "go-run-*")
+    if err != nil { return ExecutionResult{Output: err.Error(), Ok: false} }
+    defer os.RemoveAll(dir)
+
+    if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(code), 0600); err != nil {
+        return ExecutionResult{Output: err.Error(), Ok: false}
+    }
+    if err := input.writeFiles(dir); err != nil {
+        return ExecutionResult{Output: err.Error(), Ok: false}
+    }
+
+    ctx, cancel := context.WithTimeout(input.parent(), timeout)
     defer cancel()
 
-    compile := exec.CommandContext(ctx, "g++", "-std=c++17", src, "-o", exe)
-    if out, err := compile.CombinedOutput(); err != nil {
-        return ExecutionResult{Output: string(out), Ok: false}
+    // go build informa las rutas relativas al directorio: ./main.go:3:2
+    compile := exec.CommandContext(ctx, "go", "build", "-o", "prog", "main.go")
+    prepareTool(compile, dir, goBuildEnv()...)
+    built := runLimited(ctx, compile, limits)
+    if !built.Ok() {
+        return built.compileFailure(timeout, limits)
     }
 
-    run := exec.CommandContext(ctx, exe)
-    out, err := run.CombinedOutput()
-    return ExecutionResult{Output: string(out), Ok: err == nil}
+    return input.runEach(timeout, limits, dir, built.Output, func(ctx context.Context) *exec.Cmd {
+        return exec.CommandContext(ctx, filepath.Join(dir, "prog"), input.Args...)
+    })
+}
+
+// Opciones de tsc para un programa suelto de un solo archivo
+var tscFlags = []string{"--pretty", "false", "--target", "es2020", "--module", "commonjs", "--skipLibCheck"}
+
+// compileAndRunTS primero verifica los tipos con tsc --noEmit: sus errores
+// detienen la ejecución igual que los de g++. Después transpila a JavaScript
+// y ejecuta el resultado con node; el source map hace que las trazas de los
+// errores en tiempo de ejecución apunten a las líneas de main.ts
+func compileAndRunTS(timeout time.Duration, limits processLimits, code string, input ProgramInput) ExecutionResult {
+    dir, err := os.MkdirTemp("", "ts-run-*")
+    if err != nil { return ExecutionResult{Output: err.Error(), Ok: false} }
+    defer os.RemoveAll(dir)
+
+    if err := os.WriteFile(filepath.Join(dir, "main.ts"), []byte(code), 0600); err != nil {
+        return ExecutionResult{Output: err.Error(), Ok: false}
+    }
+    if err := input.writeFiles(dir); err != nil {
+        return ExecutionResult{Output: err.Error(), Ok: false}
+    }
+
+    ctx, cancel := context.WithTimeout(input.parent(), timeout)
+    defer cancel()
+
+    // tsc informa las rutas relativas al directorio de trabajo: main.ts(3,7)
+    check := exec.CommandContext(ctx, "tsc", append(tscFlags, "--noEmit", "main.ts")...)
+    prepareTool(check, dir)
+    checked := runLimited(ctx, check, limits)
+    if !checked.Ok() {
+        return checked.compileFailure(timeout, limits)
+    }
+
+    emit := exec.CommandContext(ctx, "tsc", append(tscFlags, "--sourceMap", "--outDir", "out", "main.ts")...)
+    prepareTool(emit, dir)
+    emitted := runLimited(ctx, emit, limits)
+    if !emitted.Ok() {
+        return emitted.compileFailure(timeout, limits)
+    }
+
+    return input.runEach(timeout, limits, dir, checked.Output+emitted.Output, func(ctx context.Context) *exec.Cmd {
+        return exec.CommandContext(ctx, "node", append([]string{"--enable-source-maps", filepath.Join(dir, "out", "main.js")}, input.Args...)...)
+    })
 }
 
 // ───────────────────── Detectar lenguaje rápido ──────────────────────────
 
+var htmlClosingTag = regexp.MustCompile(`</(?:div|p|span|a|h[1-6]|ul|ol|li|table|body|head|section|form|button)>`)
+
+// Una sentencia SQL al comienzo de una línea
+var sqlStatementStart = regexp.MustCompile(`(?m)^\s*(?:select\s[\s\S]*?\bfrom\b|insert\s+into\b|update\s+\S+\s+set\b|delete\s+from\b|create\s+(?:or\s+replace\s+)?(?:table|view|procedure|proc|function)\b|declare\s+@)`)
+
+// Un encabezado 'program x;' o un bloque que termina con 'end.'
+var pascalProgramStart = regexp.MustCompile(`(?m)^\s*program\s+\w+\s*(?:\([^)]*\))?\s*;|\bbegin\b[\s\S]*\bend\s*\.\s*$`)
+
+var cssRuleStart = regexp.MustCompile(`(?m)^\s*[.#@:*\[]?[\w-][^{};()=]*\{\s*[\w-]+\s*:`)
+
+// Kotlin: una declaración con fun o val, una data/sealed class o println(
+// sin receptor (fmt.Println y System.out.println no cuentan)
+var kotlinSignature = regexp.MustCompile(`(?m)^\s*(?:(?:private|public|internal|protected|override|open|suspend|inline|operator|infix|tailrec)\s+)*fun\s+(?:<[^>]*>\s*)?[\w.]+\s*\(|^\s*(?:(?:private|public|internal|protected|override|const|lateinit)\s+)*val\s+\w+\s*[:=]|^\s*(?:data|sealed)\s+class\s+\w+|(?:^|[^.\w])println\(`)
+
+// Swift: los imports de Apple, if/guard let o una func con '->'
+var swiftSignature = regexp.MustCompile(`(?m)^\s*import\s+(?:Foundation|UIKit|SwiftUI|Cocoa)\b|\b(?:guard|if)\s+let\s+\w+|\bfunc\s+\w+\s*(?:<[^>]*>)?\([^)]*\)\s*(?:throws\s+)?->`)
+
+// Un script de Swift: let/var al comienzo de una línea, con tipo opcional
+var swiftDeclaration = regexp.MustCompile(`(?m)^\s*(?:let|var)\s+\w+(?:\s*:\s*[A-Z][\w<>\[\]]*[?!]?)?\s*=`)
+
 func DetectLanguage(code string) string {
     low := strings.ToLower(code)
     switch {
+    case strings.Contains(low, "<!doctype html") || strings.Contains(low, "<html"):
+        return "html"
     case strings.Contains(low, "#include") || strings.Contains(low, "std::"):
         return "cpp"
+    // Antes que JavaScript y SQL: Pascal también tiene function y begin
+    case pascalProgramStart.MatchString(low):
+        return "pascal"
+    // Antes que Go: un archivo de Kotlin también empieza con package, pero
+    // nunca tiene func
+    case kotlinSignature.MatchString(code) && !strings.Contains(code, "func "):
+        return "kotlin"
+    // Antes que Python: fmt.Println( también contiene "print("
+    case strings.HasPrefix(strings.TrimSpace(low), "package ") || strings.Contains(low, "func main()"):
+        return "go"
+    // Antes que Python (print) y TypeScript (': String' en minúsculas es
+    // ': string'); JavaScript no tiene print(
+    case swiftSignature.MatchString(code) || swiftDeclaration.MatchString(code) && strings.Contains(code, "print("):
+        return "swift"
     case strings.Contains(low, "def ") || strings.Contains(low, "print("):
         return "python"
+    // Antes que JavaScript: las anotaciones de tipo distinguen a TypeScript
+    case strings.Contains(low, "interface ") || strings.Contains(low, ": number") ||
+        strings.Contains(low, ": string") || strings.Contains(low, ": boolean") || strings.Contains(low, "): void"):
+        return "typescript"
+    // Antes que JavaScript: CREATE FUNCTION contiene "function". Los tipos
+    // y paquetes de Oracle y ':=' distinguen PL/SQL de T-SQL
+    case sqlStatementStart.MatchString(low) && (strings.Contains(low, "varchar2") ||
+        strings.Contains(low, "dbms_output") || strings.Contains(low, ":=")):
+        return "plsql"
+    case sqlStatementStart.MatchString(low):
+        return "tsql"
+    // Selector seguido de un bloque que empieza con 'propiedad:'
+    case cssRuleStart.MatchString(low) && !strings.Contains(low, "function") && !strings.Contains(low, "=>"):
+        return "css"
     case strings.Contains(low, "function") || strings.Contains(low, "=>"):
         return "javascript"
+    // Un fragmento de HTML sin <html>: <p>...</p>
+    case htmlClosingTag.MatchString(low):
+        return "html"
     default:
         return "unknown"
     }
@@ -670,17 +1027,45 @@ func DetectLanguage(code string) string {
 
 // Función para parsear errores reales de compilación y categorizarlos
 func parseCompilerErrors(output string, language string) []CompilerError {
-    var errors []CompilerError
-    
-    switch language {
-    case "cpp":
-        return parseCPPErrors(output)
-    case "python":
-        return parsePythonErrors(output)
-    case "javascript":
-        return parseJavaScriptErrors(output)
+    return languageFor(language).CompilerErrors(output)
+}
+
+// locateExternalErrors traduce la línea y columna de los errores de un
+// compilador externo a posiciones en bytes de code. lineOffset son las
+// líneas que el ejecutor agregó antes del código enviado: un error dentro
+// de ellas queda al inicio del código. Sin columna, o con una mayor que la
+// línea, el error apunta al primer carácter no blanco de la línea
+func locateExternalErrors(errors []CompilerError, code string, lineOffset int) []CompilerError {
+    lineStarts := computeLineStarts(code)
+    for i := range errors {
+        e := &errors[i]
+        line := e.Line - lineOffset
+        if line < 1 {
+            e.Line, e.Column, e.Pos = 1, 1, 0
+            continue
+        }
+        if line > len(lineStarts) {
+            line = len(lineStarts)
+        }
+        start, end := lineStarts[line-1], len(code)
+        if line < len(lineStarts) {
+            end = lineStarts[line] - 1
+        }
+        text := strings.TrimRight(code[start:end], "\r")
+        column := e.Column
+        if e.columnFromIndent && column > 0 {
+            column += utf8.RuneCountInString(text[:len(text)-len(strings.TrimLeft(text, " \t"))])
+        }
+        if column < 1 || column > utf8.RuneCountInString(text)+1 {
+            column = utf8.RuneCountInString(text[:len(text)-len(strings.TrimLeft(text, " \t"))]) + 1
+        }
+        pos := start
+        for n := 1; n < column; n++ {
+            _, size := utf8.DecodeRuneInString(code[pos:])
+            pos += size
+        }
+        e.Line, e.Column, e.Pos = line, column, pos
     }
-    
     return errors
 }
 
@@ -695,13 +1080,14 @@ func parseCPPErrors(output string) []CompilerError {
         if strings.Contains(line, "error:") {
             // Extraer información del error
             var errorType, message string
-            var lineNum, column int = 1, 1
+            var lineNum, column int = 1, 0
             var severity string = "error"
             
-            // Parsear línea y columna si están disponibles
+            // Parsear línea y columna si están disponibles; las de un
+            // encabezado incluido no corresponden al código enviado
             if colonIndex := strings.Index(line, ":"); colonIndex != -1 {
                 parts := strings.Split(line, ":")
-                if len(parts) >= 4 {
+                if len(parts) >= 4 && strings.HasSuffix(parts[0], "main.cpp") {
                     // Formato: archivo.cpp:línea:columna: error: mensaje
                     if lineStr := parts[1]; lineStr != "" {
                         if ln, err := fmt.Sscanf(lineStr, "%d", &lineNum); err == nil && ln > 0 {
@@ -745,7 +1131,9 @@ func parseCPPErrors(output string) []CompilerError {
                 Message:  message,
                 Severity: severity,
                 Type:     errorType,
-                Pos:      (lineNum-1)*100 + column, // Aproximación para posición
+                Line:     lineNum,
+                Column:   column,
+                Code:     CodeCompilerError,
             })
         }
     }
@@ -753,98 +1141,114 @@ func parseCPPErrors(output string) []CompilerError {
     return errors
 }
 
-// Parsear errores específicos de Python
+// pyExceptionLine es la última línea de un traceback de CPython: el nombre
+// de la excepción, con su módulo si no es predefinida, y el mensaje
+var pyExceptionLine = regexp.MustCompile(`^([A-Za-z_][\w.]*)(?:: (.*))?$`)
+
+// pyFrameLine es la cabecera de cada marco del traceback
+var pyFrameLine = regexp.MustCompile(`^  File "(.*)", line (\d+)`)
+
+// pySemanticExceptions son las excepciones de CPython que corresponden a
+// errores que también detecta el análisis semántico
+var pySemanticExceptions = map[string]bool{
+    "NameError": true, "UnboundLocalError": true, "TypeError": true,
+    "AttributeError": true,
+}
+
+// Parsear errores específicos de Python: un error por traceback, en la línea
+// del último marco del programa y con la columna del marcador '^' si lo hay
 func parsePythonErrors(output string) []CompilerError {
     var errors []CompilerError
-    lines := strings.Split(output, "\n")
+    lines := strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
     
+    file, frame := "", -1
     for i, line := range lines {
-        line = strings.TrimSpace(line)
-        
-        // Python muestra errores en múltiples líneas
-        if strings.Contains(line, "File \"") && strings.Contains(line, "line") {
-            // Formato: File "archivo.py", line 1
-            var lineNum int = 1
-            var severity string = "error"
-            var errorType, message string
-            
-            // Extraer número de línea
-            re := regexp.MustCompile(`line (\d+)`)
-            if matches := re.FindStringSubmatch(line); len(matches) > 1 {
-                if ln, err := strconv.Atoi(matches[1]); err == nil {
-                    lineNum = ln
-                }
+        if matches := pyFrameLine.FindStringSubmatch(line); matches != nil {
+            // El primer marco siempre es el del programa; los demás pueden
+            // ser de la biblioteca estándar
+            if file == "" {
+                file = matches[1]
             }
-            
-            // Buscar el mensaje de error en las líneas siguientes
-            if i+1 < len(lines) {
-                errorLine := strings.TrimSpace(lines[i+1])
-                
-                // Categorizar errores de Python
-                if strings.Contains(errorLine, "SyntaxError") {
-                    if strings.Contains(errorLine, "invalid character") ||
-                       strings.Contains(errorLine, "invalid decimal literal") ||
-                       strings.Contains(errorLine, "invalid token") {
-                        errorType = "lexico"
-                        message = "Error Léxico: " + strings.TrimPrefix(errorLine, "SyntaxError: ")
-                    } else {
-                        errorType = "sintactico"
-                        message = "Error Sintáctico: " + strings.TrimPrefix(errorLine, "SyntaxError: ")
-                    }
-                } else if strings.Contains(errorLine, "IndentationError") {
-                    errorType = "sintactico"
-                    message = "Error Sintáctico: " + strings.TrimPrefix(errorLine, "IndentationError: ")
-                } else if strings.Contains(errorLine, "NameError") {
-                    errorType = "semantico"
-                    message = "Error Semántico: " + strings.TrimPrefix(errorLine, "NameError: ")
-                } else if strings.Contains(errorLine, "TypeError") {
-                    errorType = "semantico"
-                    message = "Error Semántico: " + strings.TrimPrefix(errorLine, "TypeError: ")
-                } else if strings.Contains(errorLine, "ValueError") {
-                    errorType = "semantico"
-                    message = "Error Semántico: " + strings.TrimPrefix(errorLine, "ValueError: ")
-                } else if strings.Contains(errorLine, "AttributeError") {
-                    errorType = "semantico"
-                    message = "Error Semántico: " + strings.TrimPrefix(errorLine, "AttributeError: ")
-                } else {
-                    errorType = "sintactico"
-                    message = "Error: " + errorLine
-                }
-                
-                errors = append(errors, CompilerError{
-                    Message:  message,
-                    Severity: severity,
-                    Type:     errorType,
-                    Pos:      (lineNum-1)*100 + 1, // Aproximación para posición
-                })
+            if matches[1] == file {
+                frame = i
             }
+            continue
+        }
+        matches := pyExceptionLine.FindStringSubmatch(line)
+        if frame < 0 || matches == nil {
+            continue
+        }
+        name := matches[1][strings.LastIndex(matches[1], ".")+1:]
+        lineNum, _ := strconv.Atoi(pyFrameLine.FindStringSubmatch(lines[frame])[2])
+        column := pythonCaretColumn(lines[frame+1:i])
+        file, frame = "", -1
+        
+        // Categorizar según la excepción
+        msg := matches[2]
+        var errorType, message string
+        code := CodeCompilerError
+        switch {
+        case name == "SyntaxError" && (strings.Contains(msg, "invalid character") ||
+            strings.Contains(msg, "invalid decimal literal") ||
+            strings.Contains(msg, "invalid token")):
+            errorType, message = "lexico", "Error Léxico: "+msg
+        case name == "SyntaxError" || name == "IndentationError" || name == "TabError":
+            errorType, message = "sintactico", "Error Sintáctico: "+msg
+        case pySemanticExceptions[name]:
+            // Los errores semánticos de Python solo aparecen al ejecutar
+            errorType, message, code = "semantico", "Error Semántico: "+msg, CodeRuntimeError
+        default:
+            errorType, message, code = "ejecucion", "Error de Ejecución: "+line, CodeRuntimeError
         }
+        
+        errors = append(errors, CompilerError{
+            Message:          message,
+            Severity:         "error",
+            Type:             errorType,
+            Line:             lineNum,
+            Column:           column,
+            Code:             code,
+            columnFromIndent: column > 0,
+        })
     }
     
     return errors
 }
 
+// pythonCaretColumn devuelve la columna (base 1, desde el primer carácter no
+// blanco) donde empieza lo que subraya el marcador bajo la línea citada de
+// un marco, o 0 si no lo hay. CPython cita la línea sin sangría, con cuatro espacios delante, y
+// debajo subraya con '^' lo que falló y con '~' sus operandos
+func pythonCaretColumn(frame []string) int {
+    if len(frame) < 2 {
+        return 0
+    }
+    marker := frame[1]
+    if strings.TrimLeft(marker, " ^~") != "" || !strings.HasPrefix(marker, "    ") {
+        return 0
+    }
+    // La parte que falló empieza en su primer operando
+    at := strings.IndexAny(marker, "^~")
+    if at < 0 {
+        return 0
+    }
+    source := strings.TrimPrefix(frame[0], "    ")
+    return utf8.RuneCountInString(source[:min(at-4, len(source))]) + 1
+}
+
 // Parsear errores específicos de JavaScript (Node.js)
 func parseJavaScriptErrors(output string) []CompilerError {
     var errors []CompilerError
     lines := strings.Split(output, "\n")
     
-    for _, line := range lines {
+    for i, line := range lines {
         line = strings.TrimSpace(line)
         
         // Errores de sintaxis de JavaScript
         if strings.Contains(line, "SyntaxError") {
-            var lineNum int = 1
             var severity string = "error"
             var errorType, message string
-            
-            // Buscar número de línea en el formato "archivo:línea:columna"
-            re := regexp.MustCompile(`(\w+\.js):(\d+):(\d+)`)
-            if matches := re.FindStringSubmatch(line); len(matches) > 2 {
-                if ln, err := strconv.Atoi(matches[2]); err == nil {
-                    lineNum = ln
-                }
-            }
+            lineNum, column := jsErrorPosition(lines, i)
             
             // Categorizar errores de JavaScript
             if strings.Contains(line, "Unexpected token") ||
@@ -867,46 +1271,128 @@ func parseJavaScriptErrors(output string) []CompilerError {
                 Message:  message,
                 Severity: severity,
                 Type:     errorType,
-                Pos:      (lineNum-1)*100 + 1, // Aproximación para posición
+                Line:     lineNum,
+                Column:   column,
+                Code:     CodeCompilerError,
             })
         }
         
-        // Errores de referencia (ReferenceError)
-        if strings.Contains(line, "ReferenceError") {
-            var lineNum int = 1
-            
-            // Buscar número de línea
-            re := regexp.MustCompile(`at.*?:(\d+):(\d+)`)
-            if matches := re.FindStringSubmatch(line); len(matches) > 1 {
-                if ln, err := strconv.Atoi(matches[1]); err == nil {
-                    lineNum = ln
-                }
-            }
-            
+        // Errores de referencia (ReferenceError) y de tipo (TypeError)
+        if strings.Contains(line, "ReferenceError") || strings.Contains(line, "TypeError") {
+            lineNum, column := jsErrorPosition(lines, i)
             errors = append(errors, CompilerError{
                 Message:  "Error Semántico: " + extractJSErrorMessage(line),
                 Severity: "error",
                 Type:     "semantico",
-                Pos:      (lineNum-1)*100 + 1,
+                Line:     lineNum,
+                Column:   column,
+                Code:     CodeRuntimeError,
             })
         }
+    }
+    
+    return errors
+}
+
+// Encabezado que node imprime antes de un error sin capturar, seguido de la
+// línea del código y de una marca bajo la columna:
+// /tmp/run-1/snippet-1.js:3
+// let x = ;
+//         ^
+var jsErrorHeader = regexp.MustCompile(`(?:snippet-\w+|main)\.[jt]s:(\d+)$`)
+
+// Posición en la traza: at f (/tmp/run-1/snippet-1.js:3:9)
+var jsStackFrame = regexp.MustCompile(`(?:snippet-\w+|main)\.[jt]s:(\d+):(\d+)\)?$`)
+
+// jsErrorPosition devuelve la línea y columna del error de lines[i]: la del
+// encabezado con la marca '^' que lo precede o, sin él, la del primer marco
+// de la traza que le sigue. Sin ninguno de los dos es la línea 1
+func jsErrorPosition(lines []string, i int) (int, int) {
+    for k := i - 1; k >= 0 && k >= i-4; k-- {
+        matches := jsErrorHeader.FindStringSubmatch(strings.TrimSpace(lines[k]))
+        if matches == nil {
+            continue
+        }
+        lineNum, _ := strconv.Atoi(matches[1])
+        column := 0
+        if k+2 < i {
+            marker := strings.TrimRight(lines[k+2], "\r")
+            if at := strings.IndexByte(marker, '^'); at >= 0 && strings.TrimSpace(marker[:at]) == "" {
+                column = utf8.RuneCountInString(marker[:at]) + 1
+            }
+        }
+        return lineNum, column
+    }
+    for k := i; k < len(lines) && k <= i+3; k++ {
+        if matches := jsStackFrame.FindStringSubmatch(strings.TrimSpace(lines[k])); matches != nil {
+            lineNum, _ := strconv.Atoi(matches[1])
+            column, _ := strconv.Atoi(matches[2])
+            return lineNum, column
+        }
+    }
+    return 1, 0
+}
+
+// Parsear errores de Go (compilación con go build y panics en ejecución)
+func parseGoErrors(output string) []CompilerError {
+    var errors []CompilerError
+    lines := strings.Split(output, "\n")
+    
+    // Formato del compilador: ./main.go:línea:columna: mensaje
+    compileRe := regexp.MustCompile(`\.go:(\d+):(\d+): (.*)`)
+    // Traza de un panic: /tmp/go-run-123/main.go:línea +0x1d
+    traceRe := regexp.MustCompile(`(?:snippet-\w+|main)\.go:(\d+)`)
+    
+    for i, line := range lines {
+        line = strings.TrimSpace(line)
         
-        // Errores de tipo (TypeError)
-        if strings.Contains(line, "TypeError") {
-            var lineNum int = 1
+        if matches := compileRe.FindStringSubmatch(line); len(matches) > 3 {
+            lineNum, _ := strconv.Atoi(matches[1])
+            column, _ := strconv.Atoi(matches[2])
+            msg := matches[3]
             
-            re := regexp.MustCompile(`at.*?:(\d+):(\d+)`)
-            if matches := re.FindStringSubmatch(line); len(matches) > 1 {
-                if ln, err := strconv.Atoi(matches[1]); err == nil {
-                    lineNum = ln
-                }
+            var errorType, message string
+            if strings.Contains(msg, "invalid character") ||
+               strings.Contains(msg, "newline in string") ||
+               strings.Contains(msg, "rune literal") ||
+               strings.Contains(msg, "invalid digit") ||
+               strings.Contains(msg, "not terminated") {
+                errorType = "lexico"
+                message = "Error Léxico: " + msg
+            } else if strings.Contains(msg, "syntax error") {
+                errorType = "sintactico"
+                message = "Error Sintáctico: " + strings.TrimPrefix(msg, "syntax error: ")
+            } else {
+                errorType = "semantico"
+                message = "Error Semántico: " + msg
             }
             
             errors = append(errors, CompilerError{
-                Message:  "Error Semántico: " + extractJSErrorMessage(line),
+                Message:  message,
+                Severity: "error",
+                Type:     errorType,
+                Line:     lineNum,
+                Column:   column,
+                Code:     CodeCompilerError,
+            })
+            continue
+        }
+        
+        // Errores en tiempo de ejecución
+        if strings.HasPrefix(line, "panic:") {
+            lineNum := 1
+            for _, next := range lines[i+1:] {
+                if matches := traceRe.FindStringSubmatch(next); len(matches) > 1 {
+                    lineNum, _ = strconv.Atoi(matches[1])
+                    break
+                }
+            }
+            errors = append(errors, CompilerError{
+                Message:  "Error Semántico: " + strings.TrimSpace(strings.TrimPrefix(line, "panic:")),
                 Severity: "error",
                 Type:     "semantico",
-                Pos:      (lineNum-1)*100 + 1,
+                Line:     lineNum,
+                Code:     CodeRuntimeError,
             })
         }
     }
@@ -914,6 +1400,43 @@ func parseJavaScriptErrors(output string) []CompilerError {
     return errors
 }
 
+// Formato de tsc --pretty false:
+// main.ts(3,7): error TS2322: Type 'string' is not assignable to type 'number'.
+var tscDiagnostic = regexp.MustCompile(`\.ts\((\d+),(\d+)\): (error|warning) (TS(\d+)): (.*)`)
+
+// Parsear los diagnósticos de tsc; sin ellos la salida es la de node
+func parseTypeScriptErrors(output string) []CompilerError {
+    var errors []CompilerError
+    for _, line := range strings.Split(output, "\n") {
+        matches := tscDiagnostic.FindStringSubmatch(strings.TrimSpace(line))
+        if len(matches) < 7 {
+            continue
+        }
+        lineNum, _ := strconv.Atoi(matches[1])
+        column, _ := strconv.Atoi(matches[2])
+        
+        // Los códigos TS1xxx son errores de sintaxis; el resto son de tipos
+        // y de nombres
+        errorType, message := "semantico", "Error Semántico: "
+        if len(matches[5]) == 4 && matches[5][0] == '1' {
+            errorType, message = "sintactico", "Error Sintáctico: "
+        }
+        
+        errors = append(errors, CompilerError{
+            Message:  fmt.Sprintf("%s%s (%s)", message, matches[6], matches[4]),
+            Severity: matches[3],
+            Type:     errorType,
+            Line:     lineNum,
+            Column:   column,
+            Code:     CodeCompilerError,
+        })
+    }
+    if len(errors) == 0 {
+        return parseJavaScriptErrors(output)
+    }
+    return errors
+}
+
 // Extraer el mensaje de error de JavaScript
 func extractJSErrorMessage(line string) string {
     if idx := strings.Index(line, "SyntaxError: "); idx != -1 {
@@ -943,24 +1466,93 @@ func NewRegexAnalyzer(c, l string) *RegexAnalyzer { return &RegexAnalyzer{c, l}
 func (r *RegexAnalyzer) TokenizeWithRegex() ([]Token, []CompilerError) { return Tokenize(r.code, r.lang), nil }
 
 func countNodes(n []ParseNode) int { c := len(n); for _, x := range n { c += countNodes(x.Children) }; return c }
-func hasCritical(errs []CompilerError) bool { for _, e := range errs { if e.Severity == "error" { return true } }; return false }
 
-func AnalyzeCode(code, language string) AnalyzeResponse {
-    start := time.Now()
-    if language == "" || language == "auto" { language = DetectLanguage(code) }
-    resp := AnalyzeResponse{Language: language}
-    var allErrors []CompilerError
+// PhaseCallback recibe el nombre de cada fase ("lexical", "syntax", "semantic",
+// "execution") junto con la respuesta parcial en cuanto la fase termina.
+type PhaseCallback func(phase string, partial *AnalyzeResponse)
 
-    // Léxico
+// LexicalAnalysis tokeniza el código y detecta los errores léxicos. Es la
+// primera fase del pipeline y también el camino rápido de /api/v1/lex.
+func LexicalAnalysis(code, language string) ([]Token, []CompilerError) {
     tok := Tokenize(code, language)
-    resp.Tokens = tok
+    return tok, checkLexicalErrors(code, language, tok)
+}
+
+// Formas de los números mal escritos que reporta checkLexicalErrors
+var (
+    numberWithLetters     = regexp.MustCompile(`^\d+[a-zA-Z]`)
+    multipleDecimalPoints = regexp.MustCompile(`^[0-9]*\.[0-9]*\.[0-9]*`)
+)
+
+// Delimitadores de los comentarios de bloque de todos los lenguajes
+var blockComments = [][2]string{{"/*", "*/"}, {"<!--", "-->"}, {"{", "}"}, {"(*", "*)"}}
+
+// unclosedMultiline reporta un comentario de bloque, una cadena entre
+// comillas triples, una cadena cruda de C++ o un template literal que llega al final del código sin
+// cerrarse. El lexer lo deja en un solo token desde donde empieza, así que
+// el diagnóstico es uno y está en esa posición
+func unclosedMultiline(t Token, language string) (CompilerError, bool) {
+    var msg, code, severity string
+    _, lit := literalPrefix(t.Lexeme, language)
+    switch {
+    case t.Type == COMMENT:
+        for _, d := range blockComments {
+            if !strings.HasPrefix(t.Lexeme, d[0]) {
+                continue
+            }
+            if len(t.Lexeme) >= len(d[0])+len(d[1]) && strings.HasSuffix(t.Lexeme, d[1]) {
+                return CompilerError{}, false
+            }
+            switch d[0] {
+            case "/*":
+                msg = fmt.Sprintf("Error Léxico: Comentario de bloque no cerrado en línea %d", t.Line)
+            case "<!--":
+                msg = fmt.Sprintf("Error Léxico: Comentario HTML no cerrado en línea %d", t.Line)
+            default:
+                msg = fmt.Sprintf("Error Léxico: Comentario '%s' no cerrado en línea %d", d[0], t.Line)
+            }
+            code, severity = CodeUnterminatedComment, "warning"
+            break
+        }
+    case t.Type != UNKNOWN:
+    case strings.HasPrefix(t.Lexeme, "`") && (language == "javascript" || language == "typescript"):
+        msg = fmt.Sprintf("Error Léxico: Template literal no cerrado en línea %d", t.Line)
+        code, severity = CodeUnterminatedString, "error"
+    case strings.HasPrefix(t.Lexeme, "`"), strings.HasPrefix(lit, `"""`), strings.HasPrefix(lit, "'''"),
+        language == "cpp" && cppRawDelimiter.MatchString(t.Lexeme):
+        msg = fmt.Sprintf("Error Léxico: String de múltiples líneas no cerrado en línea %d", t.Line)
+        code, severity = CodeUnterminatedString, "error"
+    }
+    if msg == "" {
+        return CompilerError{}, false
+    }
+    return CompilerError{Message: msg, Severity: severity, Type: "lexico", Pos: t.Start, Code: code}, true
+}
+
+// checkLexicalErrors reporta los tokens inválidos, las cadenas y
+// comentarios sin cerrar y la indentación mixta de Python
+func checkLexicalErrors(code, language string, tok []Token) []CompilerError {
     var lexicalErrors []CompilerError
     
     // Verificar tokens UNKNOWN y analizar su causa
     for i, t := range tok {
+        if e, ok := unclosedMultiline(t, language); ok {
+            lexicalErrors = append(lexicalErrors, e)
+            continue
+        }
+        if e, ok := malformedNumber(t, language); ok {
+            lexicalErrors = append(lexicalErrors, e)
+            continue
+        }
+        if t.Type == STRING {
+            lexicalErrors = append(lexicalErrors, checkLiteral(t, language)...)
+        }
         if t.Type == UNKNOWN {
             char := t.Lexeme
+            // Sin el prefijo de una cadena sin cerrar (f"..., L'...)
+            _, unprefixed := literalPrefix(char, language)
             var errorMsg string
+            errorCode := CodeInvalidCharacter
             
             // Detectar diferentes tipos de errores léxicos según el lenguaje
             switch language {
@@ -970,31 +1562,65 @@ func AnalyzeCode(code, language string) AnalyzeResponse {
                     errorMsg = fmt.Sprintf("Error Léxico: Caracter '@' inesperado en Python (no es un decorador válido)")
                 case char == "$":
                     errorMsg = fmt.Sprintf("Error Léxico: Caracter '$' no es válido en Python")
-                case strings.HasPrefix(char, "\"") && !strings.HasSuffix(char, "\""):
+                case strings.HasPrefix(unprefixed, "\""):
                     errorMsg = fmt.Sprintf("Error Léxico: String no cerrado que comienza con '%s'", char)
-                case strings.HasPrefix(char, "'") && !strings.HasSuffix(char, "'"):
+                    errorCode = CodeUnterminatedString
+                case strings.HasPrefix(unprefixed, "'"):
                     errorMsg = fmt.Sprintf("Error Léxico: String no cerrado que comienza con '%s'", char)
-                case regexp.MustCompile(`^\d+[a-zA-Z]`).MatchString(char):
+                    errorCode = CodeUnterminatedString
+                case numberWithLetters.MatchString(char):
                     errorMsg = fmt.Sprintf("Error Léxico: Número mal formado '%s' - contiene letras", char)
-                case regexp.MustCompile(`^[0-9]*\.[0-9]*\.[0-9]*`).MatchString(char):
+                    errorCode = CodeMalformedNumber
+                case multipleDecimalPoints.MatchString(char):
                     errorMsg = fmt.Sprintf("Error Léxico: Número decimal mal formado '%s' - múltiples puntos decimales", char)
+                    errorCode = CodeMalformedNumber
                 default:
                     errorMsg = fmt.Sprintf("Error Léxico: Caracter o secuencia inesperada '%s' en Python", char)
                 }
-            case "javascript":
+            case "tsql", "plsql":
+                switch {
+                case strings.HasPrefix(char, "'"):
+                    errorMsg = fmt.Sprintf("Error Léxico: String no cerrado que comienza con '%s'; en SQL la comilla se escribe ''", char)
+                    errorCode = CodeUnterminatedString
+                case strings.HasPrefix(char, "\""):
+                    errorMsg = fmt.Sprintf("Error Léxico: Nombre entre comillas no cerrado que comienza con '%s'", char)
+                    errorCode = CodeUnterminatedString
+                case char == "[":
+                    errorMsg = fmt.Sprintf("Error Léxico: Nombre entre corchetes no cerrado; falta ']'")
+                    errorCode = CodeUnterminatedString
+                default:
+                    errorMsg = fmt.Sprintf("Error Léxico: Caracter o secuencia inesperada '%s' en SQL", char)
+                }
+            case "pascal":
+                switch {
+                case char == "'":
+                    errorMsg = fmt.Sprintf("Error Léxico: String no cerrado; en Pascal la comilla dentro de una cadena se escribe ''")
+                    errorCode = CodeUnterminatedString
+                case char == "\"":
+                    errorMsg = fmt.Sprintf("Error Léxico: Caracter '\"' no es válido en Pascal (las cadenas van entre comillas simples)")
+                case char == "}":
+                    errorMsg = fmt.Sprintf("Error Léxico: Caracter '}' sin un '{' que abra el comentario")
+                case char == "#" || char == "$":
+                    errorMsg = fmt.Sprintf("Error Léxico: '%s' debe ir seguido de un número ('#65', '$FF')", char)
+                    errorCode = CodeMalformedNumber
+                default:
+                    errorMsg = fmt.Sprintf("Error Léxico: Caracter o secuencia inesperada '%s' en Pascal", char)
+                }
+            case "javascript", "typescript":
                 switch {
                 case char == "#":
                     errorMsg = fmt.Sprintf("Error Léxico: Caracter '#' no es válido en JavaScript (use // para comentarios)")
                 case char == "@" && !strings.HasPrefix(code[t.Start:], "@@"):
                     errorMsg = fmt.Sprintf("Error Léxico: Caracter '@' inesperado en JavaScript")
-                case strings.HasPrefix(char, "\"") && !strings.HasSuffix(char, "\""):
+                case strings.HasPrefix(char, "\""):
                     errorMsg = fmt.Sprintf("Error Léxico: String no cerrado que comienza con '%s'", char)
-                case strings.HasPrefix(char, "'") && !strings.HasSuffix(char, "'"):
+                    errorCode = CodeUnterminatedString
+                case strings.HasPrefix(char, "'"):
                     errorMsg = fmt.Sprintf("Error Léxico: String no cerrado que comienza con '%s'", char)
-                case strings.HasPrefix(char, "`") && !strings.HasSuffix(char, "`"):
-                    errorMsg = fmt.Sprintf("Error Léxico: Template literal no cerrado que comienza con '%s'", char)
-                case regexp.MustCompile(`^\d+[a-zA-Z]`).MatchString(char):
+                    errorCode = CodeUnterminatedString
+                case numberWithLetters.MatchString(char):
                     errorMsg = fmt.Sprintf("Error Léxico: Número mal formado '%s' - contiene letras", char)
+                    errorCode = CodeMalformedNumber
                 default:
                     errorMsg = fmt.Sprintf("Error Léxico: Caracter o secuencia inesperada '%s' en JavaScript", char)
                 }
@@ -1004,14 +1630,18 @@ func AnalyzeCode(code, language string) AnalyzeResponse {
                     errorMsg = fmt.Sprintf("Error Léxico: Caracter '@' no válido en C++")
                 case char == "$":
                     errorMsg = fmt.Sprintf("Error Léxico: Caracter '$' no es válido en C++")
-                case strings.HasPrefix(char, "\"") && !strings.HasSuffix(char, "\""):
+                case strings.HasPrefix(unprefixed, "\""):
                     errorMsg = fmt.Sprintf("Error Léxico: String no cerrado que comienza con '%s'", char)
-                case strings.HasPrefix(char, "'") && !strings.HasSuffix(char, "'"):
+                    errorCode = CodeUnterminatedString
+                case strings.HasPrefix(unprefixed, "'"):
                     errorMsg = fmt.Sprintf("Error Léxico: Caracter literal no cerrado que comienza con '%s'", char)
-                case regexp.MustCompile(`^\d+[a-zA-Z]`).MatchString(char):
+                    errorCode = CodeUnterminatedString
+                case numberWithLetters.MatchString(char):
                     errorMsg = fmt.Sprintf("Error Léxico: Número mal formado '%s' - contiene letras", char)
-                case regexp.MustCompile(`^[0-9]*\.[0-9]*\.[0-9]*`).MatchString(char):
+                    errorCode = CodeMalformedNumber
+                case multipleDecimalPoints.MatchString(char):
                     errorMsg = fmt.Sprintf("Error Léxico: Número decimal mal formado '%s' - múltiples puntos decimales", char)
+                    errorCode = CodeMalformedNumber
                 default:
                     errorMsg = fmt.Sprintf("Error Léxico: Caracter o secuencia inesperada '%s' en C++", char)
                 }
@@ -1021,14 +1651,18 @@ func AnalyzeCode(code, language string) AnalyzeResponse {
                     errorMsg = fmt.Sprintf("Error Léxico: Caracter '%s' no válido en %s", char, language)
                 case char == "$" && language == "cpp":
                     errorMsg = fmt.Sprintf("Error Léxico: Caracter '$' no es válido en C++")
-                case strings.HasPrefix(char, "\"") && !strings.HasSuffix(char, "\""):
+                case strings.HasPrefix(char, "\""):
                     errorMsg = fmt.Sprintf("Error Léxico: String no cerrado que comienza con '%s'", char)
-                case strings.HasPrefix(char, "'") && !strings.HasSuffix(char, "'"):
+                    errorCode = CodeUnterminatedString
+                case strings.HasPrefix(char, "'"):
                     errorMsg = fmt.Sprintf("Error Léxico: Caracter literal no cerrado que comienza con '%s'", char)
-                case regexp.MustCompile(`^\d+[a-zA-Z]`).MatchString(char):
+                    errorCode = CodeUnterminatedString
+                case numberWithLetters.MatchString(char):
                     errorMsg = fmt.Sprintf("Error Léxico: Número mal formado '%s' - contiene letras", char)
-                case regexp.MustCompile(`^[0-9]*\.[0-9]*\.[0-9]*`).MatchString(char):
+                    errorCode = CodeMalformedNumber
+                case multipleDecimalPoints.MatchString(char):
                     errorMsg = fmt.Sprintf("Error Léxico: Número decimal mal formado '%s' - múltiples puntos decimales", char)
+                    errorCode = CodeMalformedNumber
                 default:
                     errorMsg = fmt.Sprintf("Error Léxico: Caracter o secuencia inesperada '%s'", char)
                 }
@@ -1039,6 +1673,7 @@ func AnalyzeCode(code, language string) AnalyzeResponse {
                 Severity: "error",
                 Type:     "lexico",
                 Pos:      t.Start,
+                Code:     errorCode,
             })
         }
         
@@ -1051,123 +1686,340 @@ func AnalyzeCode(code, language string) AnalyzeResponse {
                     Severity: "error",
                     Type:     "lexico",
                     Pos:      t.Start,
+                    Code:     CodeMalformedNumber,
                 })
             }
         }
     }
     
-    // Verificar patrones adicionales en el código fuente específicos por lenguaje
-    lines := strings.Split(code, "\n")
-    for lineNum, line := range lines {
-        // Detectar strings mal cerrados
-        if strings.Count(line, "\"")%2 != 0 {
-            pos := strings.Index(line, "\"")
-            if pos != -1 {
-                lexicalErrors = append(lexicalErrors, CompilerError{
-                    Message:  fmt.Sprintf("Error Léxico: String no cerrado en línea %d", lineNum+1),
-                    Severity: "error",
-                    Type:     "lexico",
-                    Pos:      pos,
-                })
-            }
-        }
-        
-        // Verificaciones específicas por lenguaje
-        switch language {
-        case "cpp":
-            // Detectar comentarios mal formados para C++
-            if strings.Contains(line, "/*") && !strings.Contains(line, "*/") {
-                pos := strings.Index(line, "/*")
-                lexicalErrors = append(lexicalErrors, CompilerError{
-                    Message:  fmt.Sprintf("Error Léxico: Comentario de bloque no cerrado en línea %d", lineNum+1),
-                    Severity: "warning",
-                    Type:     "lexico",
-                    Pos:      pos,
-                })
-            }
-        case "python":
+    // Lo que solo se ve por líneas: la indentación de Python. Las cadenas y
+    // comentarios que ocupan varias líneas ya son un único token
+    if language == "python" {
+        lineStart := 0 // desplazamiento de la línea actual dentro del código
+        for lineNum, line := range strings.Split(code, "\n") {
             // Detectar problemas de indentación mixta (tabs y espacios)
             if strings.Contains(line, "\t") && strings.Contains(line, "    ") {
                 lexicalErrors = append(lexicalErrors, CompilerError{
                     Message:  fmt.Sprintf("Error Léxico: Indentación mixta (tabs y espacios) en línea %d", lineNum+1),
                     Severity: "warning",
                     Type:     "lexico",
-                    Pos:      0,
-                })
-            }
-            // Detectar strings con comillas triples mal cerradas
-            if strings.Count(line, "\"\"\"")%2 != 0 || strings.Count(line, "'''")%2 != 0 {
-                lexicalErrors = append(lexicalErrors, CompilerError{
-                    Message:  fmt.Sprintf("Error Léxico: String de múltiples líneas no cerrado en línea %d", lineNum+1),
-                    Severity: "error",
-                    Type:     "lexico",
-                    Pos:      0,
-                })
-            }
-        case "javascript":
-            // Detectar template literals mal cerrados
-            if strings.Count(line, "`")%2 != 0 {
-                pos := strings.Index(line, "`")
-                if pos != -1 {
-                    lexicalErrors = append(lexicalErrors, CompilerError{
-                        Message:  fmt.Sprintf("Error Léxico: Template literal no cerrado en línea %d", lineNum+1),
-                        Severity: "error",
-                        Type:     "lexico",
-                        Pos:      pos,
-                    })
-                }
-            }
-            // Detectar comentarios mal formados para JavaScript
-            if strings.Contains(line, "/*") && !strings.Contains(line, "*/") {
-                pos := strings.Index(line, "/*")
-                lexicalErrors = append(lexicalErrors, CompilerError{
-                    Message:  fmt.Sprintf("Error Léxico: Comentario de bloque no cerrado en línea %d", lineNum+1),
-                    Severity: "warning",
-                    Type:     "lexico",
-                    Pos:      pos,
+                    Pos:      lineStart,
+                    Code:     CodeMixedIndentation,
                 })
             }
+            lineStart += len(line) + 1
         }
     }
     
+    return lexicalErrors
+}
+
+// AnalyzeOptions ajusta el pipeline para una petición concreta
+type AnalyzeOptions struct {
+    // Límite de la compilación y de cada ejecución; 0 usa
+    // GlobalConfig.ExecutionTimeout
+    Timeout time.Duration
+    // Solo análisis léxico, sintáctico y semántico: nunca invoca al ejecutor
+    SkipExecution bool
+    // Análisis anterior del mismo documento (sesiones): se reutilizan los
+    // tokens y sentencias fuera de la región modificada y al terminar se
+    // actualiza con el análisis actual
+    Snapshot *AnalysisSnapshot
+    // Agrega el ensamblador o bytecode del programa (ver generated.go); no
+    // ejecuta el código, así que también se genera con SkipExecution
+    GeneratedCode bool
+    // Código -> activado: sobrescribe GlobalConfig.Diagnostics para esta
+    // petición (ver diagnostics.go)
+    Diagnostics map[string]bool
+    // Argumentos, variables de entorno y archivos auxiliares del programa
+    // ejecutado (ver programinput.go)
+    Args  []string
+    Env   map[string]string
+    Files []ProgramFile
+    // Código o severidad -> severidad con la que se reporta
+    SeverityOverrides map[string]string
+    // Diagnósticos antes de detener el análisis; 0 usa GlobalConfig.MaxErrors
+    MaxErrors int
+    // Salida esperada del programa y cómo compararla: agrega un veredicto
+    // (ver judge.go). Con TestCases solo se usa la comparación
+    Judge *JudgeOptions
+    // Entrada estándar del programa
+    Stdin string
+    // Casos de prueba: se ejecuta el programa con la entrada de cada uno
+    TestCases []TestCase
+    // Id con el que se registra la ejecución para poder detenerla (ver
+    // executions.go); "" no la registra
+    RequestID string
+    // Usuario autenticado: la ejecución se carga a su cuota diaria y se
+    // omite si ya la agotó (ver auth.go); nil en peticiones anónimas
+    Principal *Principal
+    // Línea donde se detiene la ejecución para mostrar las variables; solo
+    // en los lenguajes con intérprete integrado. 0 sin breakpoint
+    Breakpoint int
+    // Semilla del generador y reloj fijos (ver deterministic.go)
+    Deterministic bool
+    // Recibe la salida del programa mientras se ejecuta (ver execstream.go)
+    Output OutputFunc
+    // Terminal de una sesión interactiva: el programa corre conectado a
+    // ella en lugar de a stdin (ver interactive.go)
+    Terminal *terminal
+    // Perfil de la tarea: sus requisitos se validan en la fase semántica
+    // (ver assignments.go)
+    Profile *AssignmentProfile
+}
+
+// withProfile agrega a o los requisitos y los casos de prueba de profile
+func (o *AnalyzeOptions) withProfile(profile *AssignmentProfile) {
+    o.Profile = profile
+    if len(profile.TestCases) > 0 {
+        o.TestCases = profile.TestCases
+        o.Judge = &JudgeOptions{Compare: profile.Compare, Tolerance: profile.Tolerance}
+    }
+}
+
+func AnalyzeCode(code, language string) AnalyzeResponse {
+    return AnalyzeCodeWithProgress(code, language, AnalyzeOptions{}, nil)
+}
+
+// pendingExecution es la compilación y ejecución del programa, que corre
+// mientras sigue el análisis
+type pendingExecution struct {
+    result ExecutionResult
+    // No se invocó al compilador: la salida no tiene sus errores
+    skipped bool
+    done    chan struct{}
+    // Detiene la ejecución si el resultado ya no hace falta
+    cancel func()
+    // Pánico del ejecutor, que wait repite en quien espera (ver recover.go)
+    failure *capturedPanic
+    // Lo que tardó el ejecutor, con la compilación
+    elapsed time.Duration
+}
+
+// startExecution lanza la ejecución de code, salvo que la impidan la
+// política de seguridad, el servidor o la cuota del usuario. Ningún
+// ejecutor usa la tabla de símbolos, así que no espera al análisis
+// semántico
+func startExecution(code, language string, opts AnalyzeOptions, timeout time.Duration, policyErrors []CompilerError, serverBlock string) *pendingExecution {
+    pe := &pendingExecution{skipped: true, done: make(chan struct{}), cancel: func() {}}
+    switch {
+    case policyBlocks(policyErrors):
+        pe.result = ExecutionResult{Output: "Ejecución bloqueada por la política de seguridad del servidor", Ok: false}
+    case serverBlock != "":
+        pe.result = ExecutionResult{Output: "Ejecución omitida: " + serverBlock, Ok: false, Transient: true}
+    case opts.Principal.quotaExhausted():
+        pe.result = ExecutionResult{Output: "Ejecución omitida: se agotó la cuota diaria de ejecución", Ok: false, Transient: true}
+    case opts.Breakpoint > 0 && embeddedInterpreters[language] == nil:
+        pe.result = ExecutionResult{Output: "Ejecución omitida: los breakpoints solo están disponibles en Python y JavaScript", Ok: false}
+    default:
+        pe.skipped = false
+    }
+    if pe.skipped {
+        close(pe.done)
+        return pe
+    }
+
+    input := ProgramInput{Args: opts.Args, Env: opts.Env, Files: opts.Files, Deterministic: opts.Deterministic, Output: opts.Output, Terminal: opts.Terminal}
+    if len(opts.TestCases) > 0 {
+        input.Stdin = testCaseStdins(opts.TestCases)
+    } else if opts.Stdin != "" {
+        input.Stdin = []string{opts.Stdin}
+    }
+    account := ""
+    if opts.Principal != nil {
+        account = opts.Principal.Account
+    }
+    ctx, done := executions.start(opts.RequestID, language, account)
+    input.Context = ctx
+    pe.cancel = done
+    go func() {
+        defer close(pe.done)
+        defer func() {
+            if r := recover(); r != nil {
+                pe.failure = capturePanic(r)
+                done()
+            }
+        }()
+        exec := NewConfiguredExecutor(language, timeout, input)
+        if opts.Breakpoint > 0 {
+            // El breakpoint lo detiene el intérprete integrado, aunque el
+            // lenguaje se ejecute con el instalado
+            exec = limitedExecutor{breakpointExecutor{embeddedExecutor{language, timeout, input}, opts.Breakpoint}, timeout}
+        }
+        execStart := time.Now()
+        pe.result = exec.Execute(code, nil)
+        pe.elapsed = time.Since(execStart)
+        opts.Principal.recordExecution(pe.elapsed)
+        done()
+    }()
+    return pe
+}
+
+// wait espera a que termine la ejecución y devuelve su resultado
+func (pe *pendingExecution) wait() ExecutionResult {
+    <-pe.done
+    if pe.failure != nil { panic(pe.failure) }
+    return pe.result
+}
+
+// AnalyzeCodeWithProgress ejecuta el mismo pipeline que AnalyzeCode pero notifica
+// a onPhase al completar cada fase, permitiendo transmitir resultados parciales.
+func AnalyzeCodeWithProgress(code, language string, opts AnalyzeOptions, onPhase PhaseCallback) AnalyzeResponse {
+    start := time.Now()
+    notify := func(phase string, r *AnalyzeResponse) {
+        if onPhase != nil { onPhase(phase, r) }
+    }
+    if language == "" || language == "auto" { language = DetectLanguage(code) }
+    resp := AnalyzeResponse{Language: language}
+    var allErrors []CompilerError
+    config := currentConfig()
+
+    // Al superar el presupuesto de errores se corta la lista con un resumen
+    // y no se ejecutan las fases siguientes
+    maxErrors := errorBudget(opts.MaxErrors)
+    overBudget := func() bool {
+        if maxErrors <= 0 || len(allErrors) <= maxErrors { return false }
+        allErrors = append(allErrors[:maxErrors:maxErrors], tooManyErrors(maxErrors, allErrors[maxErrors]))
+        resp.Errors = allErrors
+        resp.Executability = codeExecutability(code, language, allErrors, config.ExecutionGate)
+        resp.Executability.Allowed = false
+        resp.ProcessingTime = time.Since(start)
+        return true
+    }
+
+    // Léxico
+    phaseStart := time.Now()
+    var tok []Token
+    if opts.Snapshot != nil {
+        tok = opts.Snapshot.tokenize(code, language)
+    } else {
+        tok = Tokenize(code, language)
+    }
+    resp.Timing.Lexical = time.Since(phaseStart)
+    // Los chequeos léxicos y el parser solo leen los tokens: corren a la vez
+    var lexicalFound []CompilerError
+    var lexicalChecks time.Duration
+    waitLexical := goSafe(func() {
+        checksStart := time.Now()
+        lexicalFound = checkLexicalErrors(code, language, tok)
+        lexicalChecks = time.Since(checksStart)
+    })
+    phaseStart = time.Now()
+    var pt []ParseNode
+    var syntaxErrors []CompilerError
+    if opts.Snapshot != nil {
+        pt, syntaxErrors = opts.Snapshot.parse(code, tok)
+    } else {
+        pt, syntaxErrors = NewParser(tok, language, code).Parse()
+    }
+    resp.Timing.Syntax = time.Since(phaseStart)
+    waitLexical()
+    resp.Timing.Lexical += lexicalChecks
+    lexicalErrors := filterDiagnostics(lexicalFound, language, opts.Diagnostics)
+    lexicalErrors = remapSeverities(lexicalErrors, opts.SeverityOverrides)
+    resp.Tokens = tok
     allErrors = append(allErrors, lexicalErrors...)
     resp.AnalysisPhases.Lexical = AnalysisPhase{Completed: true, TokensFound: len(tok), ErrorsFound: len(lexicalErrors)}
+    resp.Errors = allErrors
+    stop := overBudget()
+    notify("lexical", &resp)
+    if stop { return resp }
 
     // Sintaxis
-    parser := NewParser(tok, language)
-    pt, syntaxErrors := parser.Parse()
+    syntaxErrors = filterDiagnostics(syntaxErrors, language, opts.Diagnostics)
+    syntaxErrors = remapSeverities(syntaxErrors, opts.SeverityOverrides)
     allErrors = append(allErrors, syntaxErrors...)
     resp.ParseTree = pt
+    phaseStart = time.Now()
+    metrics := computeMetrics(code, tok, pt, language)
+    resp.Metrics = &metrics
+    resp.Timing.Syntax += time.Since(phaseStart)
     resp.AnalysisPhases.Syntax = AnalysisPhase{Completed: true, NodesGenerated: countNodes(pt), ErrorsFound: len(syntaxErrors)}
+    resp.Errors = allErrors
+    stop = overBudget()
+    notify("syntax", &resp)
+    if stop { return resp }
+
+    // Con el árbol ya se sabe si el programa se va a ejecutar: la
+    // compilación, la ejecución y el código generado corren mientras sigue
+    // el análisis semántico, que no los necesita
+    // La política de seguridad es del servidor: la petición no la filtra
+    policyErrors := checkSecurityPolicy(pt, language, config.SecurityPolicy)
+    serverBlock := serverExecutionBlock(language, config)
+    timeout := opts.Timeout
+    if timeout <= 0 { timeout = config.ExecutionTimeout }
+    var generated *GeneratedCode
+    var waitGenerated func()
+    if opts.GeneratedCode {
+        waitGenerated = goSafe(func() { generated = GenerateCode(code, language, timeout) })
+    }
+    var execution *pendingExecution
+    if !opts.SkipExecution {
+        execution = startExecution(code, language, opts, timeout, policyErrors, serverBlock)
+        // Si el análisis entra en pánico, el programa no sigue corriendo
+        defer func() {
+            if r := recover(); r != nil {
+                execution.cancel()
+                panic(capturePanic(r))
+            }
+        }()
+    }
 
     // Semántica
+    phaseStart = time.Now()
     semanticAnalyzer := NewSemanticAnalyzer(tok, pt, language)
     syms, semanticErrors := semanticAnalyzer.Analyze()
+    semanticErrors = filterDiagnostics(semanticErrors, language, opts.Diagnostics)
+    semanticErrors = remapSeverities(semanticErrors, opts.SeverityOverrides)
+    semanticErrors = append(semanticErrors, policyErrors...)
+    // Las reglas del curso tampoco: son las de la tarea
+    semanticErrors = append(semanticErrors, checkCustomRules(code, tok, language, config.CustomRules)...)
+    semanticErrors = append(semanticErrors, checkAssignmentProfile(opts.Profile, language, pt)...)
     allErrors = append(allErrors, semanticErrors...)
     resp.SymbolTable = syms
     resp.AnalysisPhases.Semantic = AnalysisPhase{Completed: true, SymbolsFound: len(syms), ErrorsFound: len(semanticErrors)}
+    resp.Timing.Semantic = time.Since(phaseStart)
 
     resp.Errors = allErrors
-    resp.CanExecute = !hasCritical(resp.Errors)
+    resp.Executability = codeExecutability(code, language, resp.Errors, config.ExecutionGate).deny(serverBlock)
+    stop = overBudget()
+    notify("semantic", &resp)
+    if stop {
+        // Sobran los errores: se detiene lo que se había lanzado
+        if execution != nil { execution.cancel() }
+        return resp
+    }
+
+    if waitGenerated != nil {
+        waitGenerated()
+        resp.GeneratedCode = generated
+    }
     
-    // SIEMPRE ejecutar para capturar errores reales del compilador
-        var exec Executor
-    if GlobalConfig.EnableRealExecution { 
-        exec = NewRealExecutor(language) 
-    } else { 
-        exec = NewExecutor(language) 
-    }
-        res := exec.Execute(code, syms)
-        resp.ExecutionResult = &res
+    if execution == nil {
+        resp.ProcessingTime = time.Since(start)
+        return resp
+    }
+    
+    // Ejecutar siempre que se pida, para capturar errores reales del compilador
+    res := execution.wait()
+    resp.ExecutionResult = &res
+    resp.Timing.Execution = execution.elapsed
     
     // SIEMPRE parsear errores reales si existen (independientemente del análisis estático)
-    if res.Output != "" {
-        realErrors := parseCompilerErrors(res.Output, language)
+    if res.Output != "" && !execution.skipped {
+        realErrors := locateExternalErrors(parseCompilerErrors(res.Output, language), code, res.LineOffset)
+        realErrors = filterDiagnostics(realErrors, language, opts.Diagnostics)
+        realErrors = remapSeverities(realErrors, opts.SeverityOverrides)
         if len(realErrors) > 0 {
-            resp.Errors = append(resp.Errors, realErrors...)
+            // Los que coinciden con un error del análisis se unen a él
+            var added []CompilerError
+            resp.Errors, added = reconcileDiagnostics(code, resp.Errors, realErrors)
+            // La salida del compilador también respeta el presupuesto
+            allErrors = resp.Errors
+            overBudget()
             
             // Actualizar contadores de fases
-            for _, err := range realErrors {
+            for _, err := range added {
                 switch err.Type {
                 case "lexico":
                     resp.AnalysisPhases.Lexical.ErrorsFound++
@@ -1178,10 +2030,18 @@ func AnalyzeCode(code, language string) AnalyzeResponse {
                 }
             }
             
-            // Actualizar CanExecute basándose en errores reales también
-            resp.CanExecute = false
+            // Los errores del compilador también impiden ejecutar
+            resp.Executability = codeExecutability(code, language, resp.Errors, config.ExecutionGate).deny(serverBlock)
         }
     }
+    if len(opts.TestCases) > 0 {
+        judge := JudgeOptions{}
+        if opts.Judge != nil { judge = *opts.Judge }
+        resp.Tests = judgeTestCases(resp.ExecutionResult, opts.TestCases, judge)
+    } else if opts.Judge != nil {
+        resp.Judge = judgeExecution(resp.ExecutionResult, *opts.Judge)
+    }
+    notify("execution", &resp)
 
     resp.ProcessingTime = time.Since(start)
     return resp
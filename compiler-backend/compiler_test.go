@@ -0,0 +1,106 @@
+package main
+
+import "testing"
+
+// TestParsePythonErrors comprueba que cada traceback de CPython da un solo
+// error, con el tipo de su excepción y la posición del marcador
+func TestParsePythonErrors(t *testing.T) {
+	cases := []struct {
+		name    string
+		code    string
+		output  string
+		typ     string
+		message string
+		line    int
+		column  int
+	}{
+		{
+			name: "sintaxis_con_marcador",
+			code: "if True:\n    print(\"hola\"\n",
+			output: `  File "/tmp/run123/main.py", line 2
+    print("hola"
+         ^
+SyntaxError: '(' was never closed
+`,
+			typ:     "sintactico",
+			message: "Error Sintáctico: '(' was never closed",
+			line:    2,
+			column:  10,
+		},
+		{
+			name: "caracter_invalido",
+			code: "x = 5 € 3\n",
+			output: `  File "/tmp/run123/main.py", line 1
+    x = 5 € 3
+          ^
+SyntaxError: invalid character '€' (U+20AC)
+`,
+			typ:     "lexico",
+			message: "Error Léxico: invalid character '€' (U+20AC)",
+			line:    1,
+			column:  7,
+		},
+		{
+			name: "indice_fuera_de_rango",
+			code: "def f(xs):\n    return xs[3]\n\nprint(f([1, 2]))\n",
+			output: `Traceback (most recent call last):
+  File "/tmp/run123/main.py", line 4, in <module>
+    print(f([1, 2]))
+          ^^^^^^^^^
+  File "/tmp/run123/main.py", line 2, in f
+    return xs[3]
+           ~~^^^
+IndexError: list index out of range
+`,
+			typ:     "ejecucion",
+			message: "Error de Ejecución: IndexError: list index out of range",
+			line:    2,
+			column:  12,
+		},
+		{
+			name: "nombre_no_definido",
+			code: "total = 1\nprint(totl)\n",
+			output: `Traceback (most recent call last):
+  File "/tmp/run123/main.py", line 2, in <module>
+    print(totl)
+          ^^^^
+NameError: name 'totl' is not defined. Did you mean: 'total'?
+`,
+			typ:     "semantico",
+			message: "Error Semántico: name 'totl' is not defined. Did you mean: 'total'?",
+			line:    2,
+			column:  7,
+		},
+		{
+			name: "marco_de_la_biblioteca",
+			code: "import json\njson.loads(\"{\")\n",
+			output: `Traceback (most recent call last):
+  File "/tmp/run123/main.py", line 2, in <module>
+    json.loads("{")
+  File "/usr/lib/python3.12/json/__init__.py", line 346, in loads
+    return _default_decoder.decode(s)
+           ^^^^^^^^^^^^^^^^^^^^^^^^^^
+json.decoder.JSONDecodeError: Expecting property name enclosed in double quotes: line 1 column 2 (char 1)
+`,
+			typ:     "ejecucion",
+			message: "Error de Ejecución: json.decoder.JSONDecodeError: Expecting property name enclosed in double quotes: line 1 column 2 (char 1)",
+			line:    2,
+			column:  1,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			errs := locateExternalErrors(parsePythonErrors(c.output), c.code, 0)
+			if len(errs) != 1 {
+				t.Fatalf("%d errores, esperado 1: %+v", len(errs), errs)
+			}
+			e := errs[0]
+			if e.Type != c.typ || e.Message != c.message {
+				t.Errorf("error %q (%s), esperado %q (%s)", e.Message, e.Type, c.message, c.typ)
+			}
+			if e.Line != c.line || e.Column != c.column {
+				t.Errorf("posición %d:%d, esperada %d:%d", e.Line, e.Column, c.line, c.column)
+			}
+		})
+	}
+}
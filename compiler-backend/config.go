@@ -0,0 +1,462 @@
+package main
+
+import (
+	"log"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ───────────────────────────── Configuración ─────────────────────────────
+
+// Backends de ejecución disponibles
+const (
+	BackendLocal  = "local"  // exec.Command directamente en el host
+	BackendDocker = "docker" // contenedor aislado por ejecución
+)
+
+type CompilerConfig struct {
+	// Si es false se usa el ejecutor simulado
+	EnableRealExecution bool
+	// "local" o "docker"
+	ExecutionBackend string
+	// Límite de compilación + ejecución cuando la petición no indica uno
+	ExecutionTimeout time.Duration
+	// Máximo que un cliente puede pedir con timeoutSeconds
+	MaxExecutionTimeout time.Duration
+	// Límite de compilación + ejecución de una sesión interactiva, que
+	// espera al usuario (ver interactive.go)
+	InteractiveTimeout time.Duration
+	// Lenguajes que se ejecutan; vacío permite todos y los demás solo se
+	// analizan
+	AllowedLanguages []string
+
+	// Orígenes del frontend que pueden llamar a la API (CORS y WebSocket);
+	// admiten "*" y comodines de subdominio como "https://*.ejemplo.edu"
+	AllowedOrigins []string
+
+	// Peticiones de análisis por minuto y por IP; 0 desactiva el límite
+	RateLimitPerMinute int
+	// Bytes del cuerpo de una petición y del código que se analiza; 0
+	// desactiva el límite (ver validation.go)
+	MaxRequestBytes int64
+	MaxFileSize     int
+	// Bytes de código a partir de los cuales las respuestas traen
+	// tokenCounts en lugar de la lista de tokens; 0 la incluye siempre
+	// (ver tokenstream.go)
+	OmitTokensOver int
+	// Nodos y niveles del parseTree de /api/v1/analyze; los que sobran se
+	// resumen en un nodo "more". 0 no limita (ver treelimit.go)
+	MaxTreeNodes int
+	MaxTreeDepth int
+	// Ejecuciones reales simultáneas en todo el servidor
+	MaxConcurrentExecutions int
+
+	// Límites de cada proceso del ejecutor local; 0 desactiva el límite
+	MaxMemoryMB    int
+	MaxProcesses   int
+	MaxOutputBytes int
+	// cgroup v2 delegado donde se crea un cgroup por ejecución; vacío usa
+	// solo rlimits (RLIMIT_NPROC no limita los procesos de root)
+	CgroupParent string
+
+	// Motor de JavaScript: "native" (node) o "embedded" (intérprete
+	// integrado, ver interp.go)
+	JSEngine string
+	// Pasos de un programa en un intérprete integrado; 0 sin límite
+	MaxInterpreterSteps int64
+	// Pasos máximos de una traza de /api/v1/trace
+	MaxTraceSteps int
+
+	// Análisis completos guardados en la caché de resultados; 0 la desactiva
+	ResultCacheSize int
+
+	// Base SQLite del historial de análisis y de las claves de API; "none"
+	// la desactiva
+	HistoryDB string
+
+	// Rechaza las peticiones sin credenciales (ver auth.go)
+	AuthRequired bool
+	// Secreto con el que se firman los tokens JWT (HS256); vacío no los acepta
+	JWTSecret string
+	// Minutos de ejecución por día de un token sin el claim dailyMinutes y
+	// valor por defecto de las claves nuevas; 0 sin límite
+	AuthDailyMinutes float64
+
+	// Inactividad tras la que se descarta una sesión de análisis
+	SessionTTL time.Duration
+	// Sesiones simultáneas; al llegar al límite se descarta la menos usada
+	MaxSessions int
+
+	// Vigencia de una vista previa publicada (ver previews.go) y cuántas se
+	// mantienen; al llegar al límite se descarta la que vence primero
+	PreviewTTL  time.Duration
+	MaxPreviews int
+
+	// Vigencia de un análisis compartido (ver share.go) y cuántos se
+	// mantienen; al llegar al límite se descarta el que vence primero
+	ShareTTL  time.Duration
+	MaxShares int
+
+	// Workers que atienden los análisis asíncronos (ver jobs.go), trabajos
+	// que pueden esperar en la cola y tiempo que se guarda cada resultado
+	AsyncWorkers   int
+	AsyncQueueSize int
+	JobTTL         time.Duration
+	// Servidor Redis donde se comparten la cola y la caché de resultados
+	// entre instancias (redis://[usuario:contraseña@]host[:puerto][/db]);
+	// vacío las mantiene en memoria
+	RedisURL string
+
+	// Webhook de GitHub (ver github.go): secreto con el que GitHub firma los
+	// eventos, token para leer los repositorios y publicar el resultado, y
+	// URL de la API (la de GitHub Enterprise Server termina en /api/v3)
+	GitHubWebhookSecret string
+	GitHubToken         string
+	GitHubAPIURL        string
+
+	// Límites de los contenedores (formato de `docker run`)
+	DockerCPUs      string
+	DockerMemory    string
+	DockerPidsLimit string
+	// Red del contenedor; "none" deja el código sin acceso a la red
+	DockerNetwork string
+	// Imagen usada para cada lenguaje
+	DockerImages map[string]string
+
+	// Diagnósticos desactivados por lenguaje (ver diagnostics.go)
+	Diagnostics DiagnosticsConfig
+	// Diagnósticos por análisis antes de detenerlo; 0 sin límite
+	MaxErrors int
+	// Agrupar los diagnósticos idénticos en uno con relatedPositions
+	// cuando la petición no indica groupDiagnostics (ver diaggroup.go)
+	GroupDiagnostics bool
+	// Acción de cada regla de la política de seguridad (ver policy.go)
+	SecurityPolicy SecurityPolicyConfig
+	// Errores de cada fase que impiden ejecutar, por lenguaje (ver
+	// executability.go)
+	ExecutionGate ExecutionGateConfig
+	// Diagnósticos propios del despliegue (ver customrules.go)
+	CustomRules []customRule
+	// Requisitos de cada tarea por nombre (ver assignments.go)
+	AssignmentProfiles map[string]*AssignmentProfile
+	// Espacios de trabajo por id, cada uno con sus lenguajes, cuota y
+	// perfiles (ver workspaces.go)
+	Workspaces map[string]*Workspace
+	// Herramienta LTI 1.3 para Moodle y Canvas; nil si no hay LTI_CONFIG
+	// (ver lti.go)
+	LTI *LTIConfig
+	// Idioma de los mensajes de error cuando la petición no pide uno ("es"
+	// o "en", ver messages.go)
+	DefaultLocale string
+
+	// Variables de entorno que una petición puede definir; "APP_*" permite
+	// todas las que empiezan con APP_
+	AllowedEnvVars []string
+}
+
+// Config global: activa la ejecución real por defecto
+var GlobalConfig = CompilerConfig{
+	EnableRealExecution:     true,
+	ExecutionBackend:        BackendLocal,
+	ExecutionTimeout:        4 * time.Second,
+	MaxExecutionTimeout:     30 * time.Second,
+	InteractiveTimeout:      5 * time.Minute,
+	RateLimitPerMinute:      60,
+	MaxRequestBytes:         4 << 20,
+	MaxFileSize:             512 << 10,
+	MaxConcurrentExecutions: runtime.NumCPU(),
+	MaxMemoryMB:             256,
+	MaxProcesses:            64,
+	MaxOutputBytes:          1 << 20,
+	JSEngine:                EngineNative,
+	MaxInterpreterSteps:     100_000_000,
+	MaxTraceSteps:           1000,
+	ResultCacheSize:         256,
+	HistoryDB:               "history.db",
+	SessionTTL:              30 * time.Minute,
+	MaxSessions:             500,
+	PreviewTTL:              10 * time.Minute,
+	MaxPreviews:             200,
+	ShareTTL:                24 * time.Hour,
+	MaxShares:               500,
+	AsyncWorkers:            runtime.NumCPU(),
+	AsyncQueueSize:          100,
+	GitHubAPIURL:            "https://api.github.com",
+	JobTTL:                  10 * time.Minute,
+	DockerCPUs:              "0.5",
+	DockerMemory:            "128m",
+	DockerPidsLimit:         "64",
+	DockerNetwork:           "none",
+	MaxErrors:               1000,
+	DefaultLocale:           "es",
+	SecurityPolicy:          defaultSecurityPolicy(),
+	ExecutionGate:           defaultExecutionGate(),
+	AllowedEnvVars:          []string{"LANG", "LC_ALL", "TZ", "APP_*"},
+	AllowedOrigins: []string{
+		"http://localhost:3000",  // Next.js dev
+		"http://localhost:3001",  // Alternativo
+		"https://localhost:3000", // HTTPS local
+	},
+	DockerImages: map[string]string{
+		"cpp":        "gcc:13",
+		"python":     "python:3.12-alpine",
+		"javascript": "node:20-alpine",
+		"typescript": "mcr.microsoft.com/devcontainers/typescript-node:20",
+		"go":         "golang:1.22-alpine",
+		"pascal":     "freepascal/fpc:3.2.2",
+	},
+}
+
+// InitConfig sobrescribe la configuración por defecto con variables de
+// entorno. Se llama una sola vez al iniciar el servidor.
+func InitConfig() {
+	if v := os.Getenv("ENABLE_REAL_EXECUTION"); v != "" {
+		GlobalConfig.EnableRealExecution = v != "false" && v != "0"
+	}
+	if v := os.Getenv("EXECUTION_BACKEND"); v != "" {
+		GlobalConfig.ExecutionBackend = strings.ToLower(v)
+	}
+	if v, err := strconv.Atoi(os.Getenv("EXECUTION_TIMEOUT")); err == nil && v > 0 {
+		GlobalConfig.ExecutionTimeout = time.Duration(v) * time.Second
+	}
+	if v, err := strconv.Atoi(os.Getenv("MAX_EXECUTION_TIMEOUT")); err == nil && v > 0 {
+		GlobalConfig.MaxExecutionTimeout = time.Duration(v) * time.Second
+	}
+	if GlobalConfig.MaxExecutionTimeout < GlobalConfig.ExecutionTimeout {
+		GlobalConfig.MaxExecutionTimeout = GlobalConfig.ExecutionTimeout
+	}
+	if v, err := strconv.Atoi(os.Getenv("INTERACTIVE_TIMEOUT")); err == nil && v > 0 {
+		GlobalConfig.InteractiveTimeout = time.Duration(v) * time.Second
+	}
+	if v := os.Getenv("ALLOWED_LANGUAGES"); v != "" {
+		languages, err := splitLanguages(v)
+		if err != nil {
+			log.Fatalf("ALLOWED_LANGUAGES: %v", err)
+		}
+		GlobalConfig.AllowedLanguages = languages
+	}
+	if v := os.Getenv("ALLOWED_ORIGINS"); v != "" {
+		GlobalConfig.AllowedOrigins = strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' })
+	}
+	if v, err := strconv.Atoi(os.Getenv("RATE_LIMIT_PER_MINUTE")); err == nil && v >= 0 {
+		GlobalConfig.RateLimitPerMinute = v
+	}
+	if v, err := strconv.ParseInt(os.Getenv("MAX_REQUEST_BYTES"), 10, 64); err == nil && v >= 0 {
+		GlobalConfig.MaxRequestBytes = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("MAX_FILE_SIZE")); err == nil && v >= 0 {
+		GlobalConfig.MaxFileSize = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("OMIT_TOKENS_OVER")); err == nil && v >= 0 {
+		GlobalConfig.OmitTokensOver = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("PARSE_TREE_MAX_NODES")); err == nil && v >= 0 {
+		GlobalConfig.MaxTreeNodes = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("PARSE_TREE_MAX_DEPTH")); err == nil && v >= 0 {
+		GlobalConfig.MaxTreeDepth = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("MAX_CONCURRENT_EXECUTIONS")); err == nil && v > 0 {
+		GlobalConfig.MaxConcurrentExecutions = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("MAX_MEMORY_MB")); err == nil && v >= 0 {
+		GlobalConfig.MaxMemoryMB = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("MAX_PROCESSES")); err == nil && v >= 0 {
+		GlobalConfig.MaxProcesses = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("MAX_OUTPUT_BYTES")); err == nil && v >= 0 {
+		GlobalConfig.MaxOutputBytes = v
+	}
+	if v := os.Getenv("CGROUP_PARENT"); v != "" {
+		GlobalConfig.CgroupParent = v
+	}
+	if v := os.Getenv("JS_ENGINE"); v != "" {
+		GlobalConfig.JSEngine = strings.ToLower(v)
+	}
+	if v, err := strconv.ParseInt(os.Getenv("MAX_INTERPRETER_STEPS"), 10, 64); err == nil && v >= 0 {
+		GlobalConfig.MaxInterpreterSteps = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("MAX_TRACE_STEPS")); err == nil && v > 0 {
+		GlobalConfig.MaxTraceSteps = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("RESULT_CACHE_SIZE")); err == nil && v >= 0 {
+		GlobalConfig.ResultCacheSize = v
+	}
+	if v := os.Getenv("HISTORY_DB"); v != "" {
+		GlobalConfig.HistoryDB = v
+	}
+	if v := os.Getenv("AUTH_REQUIRED"); v != "" {
+		GlobalConfig.AuthRequired = v == "true" || v == "1"
+	}
+	if v := os.Getenv("JWT_SECRET"); v != "" {
+		GlobalConfig.JWTSecret = v
+	}
+	if v, err := strconv.ParseFloat(os.Getenv("AUTH_DAILY_MINUTES"), 64); err == nil && v >= 0 {
+		GlobalConfig.AuthDailyMinutes = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("SESSION_TTL")); err == nil && v > 0 {
+		GlobalConfig.SessionTTL = time.Duration(v) * time.Second
+	}
+	if v, err := strconv.Atoi(os.Getenv("MAX_SESSIONS")); err == nil && v > 0 {
+		GlobalConfig.MaxSessions = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("PREVIEW_TTL")); err == nil && v > 0 {
+		GlobalConfig.PreviewTTL = time.Duration(v) * time.Second
+	}
+	if v, err := strconv.Atoi(os.Getenv("MAX_PREVIEWS")); err == nil && v > 0 {
+		GlobalConfig.MaxPreviews = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("SHARE_TTL")); err == nil && v > 0 {
+		GlobalConfig.ShareTTL = time.Duration(v) * time.Second
+	}
+	if v, err := strconv.Atoi(os.Getenv("MAX_SHARES")); err == nil && v > 0 {
+		GlobalConfig.MaxShares = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("ASYNC_WORKERS")); err == nil && v > 0 {
+		GlobalConfig.AsyncWorkers = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("ASYNC_QUEUE_SIZE")); err == nil && v > 0 {
+		GlobalConfig.AsyncQueueSize = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("JOB_TTL")); err == nil && v > 0 {
+		GlobalConfig.JobTTL = time.Duration(v) * time.Second
+	}
+	if v := os.Getenv("REDIS_URL"); v != "" {
+		GlobalConfig.RedisURL = v
+	}
+	GlobalConfig.GitHubWebhookSecret = os.Getenv("GITHUB_WEBHOOK_SECRET")
+	GlobalConfig.GitHubToken = os.Getenv("GITHUB_TOKEN")
+	if v := os.Getenv("GITHUB_API_URL"); v != "" {
+		GlobalConfig.GitHubAPIURL = v
+	}
+	if GlobalConfig.GitHubWebhookSecret != "" && GlobalConfig.GitHubToken == "" {
+		log.Fatalf("GITHUB_WEBHOOK_SECRET requiere GITHUB_TOKEN para publicar el resultado de los push")
+	}
+	if v := os.Getenv("DOCKER_CPUS"); v != "" {
+		GlobalConfig.DockerCPUs = v
+	}
+	if v := os.Getenv("DOCKER_MEMORY"); v != "" {
+		GlobalConfig.DockerMemory = v
+	}
+	if v := os.Getenv("DOCKER_PIDS_LIMIT"); v != "" {
+		GlobalConfig.DockerPidsLimit = v
+	}
+	if v := os.Getenv("DOCKER_NETWORK"); v != "" {
+		GlobalConfig.DockerNetwork = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("MAX_ERRORS")); err == nil && v >= 0 {
+		GlobalConfig.MaxErrors = v
+	}
+	if v := os.Getenv("GROUP_DIAGNOSTICS"); v != "" {
+		GlobalConfig.GroupDiagnostics = v == "true" || v == "1"
+	}
+	if v := normalizeLocale(os.Getenv("DEFAULT_LOCALE")); v != "" {
+		GlobalConfig.DefaultLocale = v
+	}
+	if v, ok := os.LookupEnv("EXECUTION_ENV_ALLOWLIST"); ok {
+		GlobalConfig.AllowedEnvVars = strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' })
+	}
+	if v := os.Getenv("SECURITY_POLICY"); v != "" {
+		GlobalConfig.SecurityPolicy = parseSecurityPolicy(v)
+	}
+	if v := os.Getenv("EXECUTION_GATE"); v != "" {
+		GlobalConfig.ExecutionGate = parseExecutionGate(v)
+	}
+	if v := os.Getenv("DISABLED_DIAGNOSTICS"); v != "" {
+		GlobalConfig.Diagnostics = parseDisabledDiagnostics(v)
+	}
+	// A diferencia de las variables, un archivo de reglas con errores
+	// detiene el inicio: ignorarlo dejaría la tarea sin sus restricciones
+	if v := os.Getenv("CUSTOM_RULES"); v != "" {
+		rules, err := loadCustomRules(v)
+		if err != nil {
+			log.Fatalf("No se pudieron cargar las reglas de %s: %v", v, err)
+		}
+		registerCustomRules(rules)
+		GlobalConfig.CustomRules = rules
+	}
+	if v := os.Getenv("ASSIGNMENT_PROFILES"); v != "" {
+		profiles, err := loadAssignmentProfiles(v)
+		if err != nil {
+			log.Fatalf("No se pudieron cargar los perfiles de %s: %v", v, err)
+		}
+		GlobalConfig.AssignmentProfiles = profiles
+	}
+	if v := os.Getenv("WORKSPACES"); v != "" {
+		workspaces, err := loadWorkspaces(v)
+		if err != nil {
+			log.Fatalf("No se pudieron cargar los espacios de trabajo de %s: %v", v, err)
+		}
+		GlobalConfig.Workspaces = workspaces
+	}
+	if v := os.Getenv("LTI_CONFIG"); v != "" {
+		lti, err := loadLTIConfig(v)
+		if err != nil {
+			log.Fatalf("No se pudo cargar la configuración LTI de %s: %v", v, err)
+		}
+		GlobalConfig.LTI = lti
+	}
+	for lang := range GlobalConfig.DockerImages {
+		if v := os.Getenv("DOCKER_IMAGE_" + strings.ToUpper(lang)); v != "" {
+			GlobalConfig.DockerImages[lang] = v
+		}
+	}
+}
+
+// ExecutionTimeoutFor devuelve el límite de ejecución de una petición que
+// pide seconds segundos (0 = valor por defecto), acotado por el máximo.
+func ExecutionTimeoutFor(seconds int) time.Duration {
+	config := currentConfig()
+	if seconds <= 0 {
+		return config.ExecutionTimeout
+	}
+	timeout := time.Duration(seconds) * time.Second
+	if timeout > config.MaxExecutionTimeout {
+		return config.MaxExecutionTimeout
+	}
+	return timeout
+}
+
+// NewConfiguredExecutor devuelve el ejecutor indicado por GlobalConfig
+func NewConfiguredExecutor(lang string, timeout time.Duration, input ProgramInput) Executor {
+	// Una hoja de estilos no tiene entorno de ejecución: siempre se resume
+	if lang == "css" {
+		return cssExecutor{}
+	}
+	// El documento se devuelve para que lo muestre el frontend
+	if lang == "html" {
+		return htmlExecutor{}
+	}
+	// Sin ejecución real o sin python3 en el host, Python se interpreta
+	// dentro del servidor en lugar de devolver la salida simulada
+	realExecution := currentConfig().EnableRealExecution
+	if lang == "python" && (!realExecution ||
+		GlobalConfig.ExecutionBackend == BackendLocal && !python3Available()) {
+		return limitedExecutor{embeddedExecutor{lang, timeout, input}, timeout}
+	}
+	if !realExecution {
+		return NewExecutor(lang)
+	}
+	// El intérprete integrado no usa node ni Docker
+	if lang == "javascript" && GlobalConfig.JSEngine == EngineEmbedded {
+		return limitedExecutor{embeddedExecutor{lang, timeout, input}, timeout}
+	}
+	// El intérprete instalado recibe el preludio determinista como archivo
+	input = input.withPrelude(lang)
+	if GlobalConfig.ExecutionBackend == BackendDocker {
+		return limitedExecutor{NewDockerExecutor(lang, timeout, input), timeout}
+	}
+	return limitedExecutor{NewRealExecutor(lang, timeout, input), timeout}
+}
+
+// python3Available indica si el host tiene python3; se consulta una sola vez
+var python3Available = sync.OnceValue(func() bool {
+	_, err := exec.LookPath("python3")
+	return err == nil
+})
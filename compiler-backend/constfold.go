@@ -0,0 +1,599 @@
+package main
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ──────────────────────── Evaluación de constantes ───────────────────────
+//
+// Durante el chequeo de tipos las expresiones formadas solo por literales y
+// constantes se evalúan (int x = 3 * (4 + 1) → 15). El valor inicial de cada
+// símbolo se muestra en la tabla de símbolos y se reportan las divisiones
+// entre cero constante y los desbordamientos de enteros de C++. Se consideran
+// constantes los nombres declarados con const/constexpr (C++, JavaScript,
+// Go) y, en Python, los nombres en MAYÚSCULAS, que por convención no se
+// reasignan; una variable común puede cambiar en un ciclo y no se propaga.
+
+// constValue es el resultado de evaluar una expresión constante; kind es
+// tInt, tFloat, tBool o tString
+type constValue struct {
+	kind string
+	i    int64
+	f    float64
+	b    bool
+	s    string
+	// C++: el entero es long/long long o unsigned; si es false la operación
+	// se hace en int de 32 bits
+	wide bool
+	// La operación de este nodo desbordó (no las de sus operandos)
+	overflow bool
+}
+
+func intConst(v int64, wide bool) constValue { return constValue{kind: tInt, i: v, wide: wide} }
+func floatConst(v float64) constValue        { return constValue{kind: tFloat, f: v} }
+func boolConst(v bool) constValue            { return constValue{kind: tBool, b: v} }
+
+func (v constValue) number() float64 {
+	if v.kind == tInt {
+		return float64(v.i)
+	}
+	return v.f
+}
+
+func (v constValue) isZero() bool {
+	return v.kind == tInt && v.i == 0 || v.kind == tFloat && v.f == 0
+}
+
+// truthy sigue la conversión a booleano de cada lenguaje; ok es false si el
+// valor no es un booleano en un lenguaje sin conversiones (Go)
+func (v constValue) truthy(lang string) (bool, bool) {
+	switch {
+	case v.kind == tBool:
+		return v.b, true
+	case lang == "go":
+		return false, false
+	case v.kind == tString:
+		return v.s != "", true
+	}
+	return !v.isZero(), true
+}
+
+// format devuelve el valor escrito como en el lenguaje, para APISymbol.Value
+func (v constValue) format(lang string) string {
+	switch v.kind {
+	case tInt:
+		return strconv.FormatInt(v.i, 10)
+	case tFloat:
+		s := strconv.FormatFloat(v.f, 'g', -1, 64)
+		if lang != "javascript" && !strings.ContainsAny(s, ".eIN") {
+			s += ".0"
+		}
+		return s
+	case tBool:
+		if lang == "python" {
+			if v.b {
+				return "True"
+			}
+			return "False"
+		}
+		return strconv.FormatBool(v.b)
+	case tString:
+		if lang == "python" || lang == "javascript" {
+			q := strconv.Quote(v.s)
+			return "'" + strings.ReplaceAll(q[1:len(q)-1], `'`, `\'`) + "'"
+		}
+		return strconv.Quote(v.s)
+	}
+	return ""
+}
+
+// convert ajusta el valor al tipo declarado de una variable (double d = 7 / 2
+// guarda 3.0; int n = 3.9 guarda 3)
+func (v constValue) convert(declared string) (constValue, bool) {
+	switch {
+	case declared == tUnknown || declared == v.kind:
+		return v, true
+	case declared == tFloat && v.kind == tInt:
+		return floatConst(float64(v.i)), true
+	case declared == tInt && v.kind == tFloat:
+		return intConst(int64(v.f), true), true
+	case declared == tInt && v.kind == tBool:
+		if v.b {
+			return intConst(1, false), true
+		}
+		return intConst(0, false), true
+	case declared == tBool && (v.kind == tInt || v.kind == tFloat):
+		return boolConst(!v.isZero()), true
+	}
+	return constValue{}, false
+}
+
+// cppIntRange devuelve el rango de un tipo entero de C++ escrito en una
+// declaración; ok es false si no es un tipo entero conocido
+func cppIntRange(decl string) (lo, hi int64, unsigned, ok bool) {
+	words := map[string]int{}
+	for _, w := range strings.Fields(decl) {
+		words[w]++
+	}
+	unsigned = words["unsigned"] > 0
+	var bits uint
+	switch {
+	case words["char"] > 0:
+		bits = 8
+	case words["short"] > 0:
+		bits = 16
+	case words["long"] > 0:
+		bits = 64
+	case words["int"] > 0 || unsigned || words["signed"] > 0:
+		bits = 32
+	default:
+		return 0, 0, false, false
+	}
+	if unsigned {
+		if bits == 64 {
+			return 0, math.MaxInt64, true, true
+		}
+		return 0, 1<<bits - 1, true, true
+	}
+	if bits == 64 {
+		return math.MinInt64, math.MaxInt64, false, true
+	}
+	return -1 << (bits - 1), 1<<(bits-1) - 1, false, true
+}
+
+// foldLiteral interpreta un literal numérico, booleano o de cadena
+func (tc *TypeChecker) foldLiteral(lex string) (constValue, bool) {
+	switch lex {
+	case "true", "True":
+		return boolConst(true), true
+	case "false", "False":
+		return boolConst(false), true
+	}
+	if lex == "" {
+		return constValue{}, false
+	}
+	switch c := lex[0]; {
+	case c >= '0' && c <= '9' || c == '.' && len(lex) > 1:
+		return tc.foldNumber(lex)
+	case c == '"' || c == '`' || c == '\'' && tc.language != "cpp" && tc.language != "go":
+		if tc.language == "cpp" {
+			// "a" es un const char*, no un std::string
+			return constValue{}, false
+		}
+		body := lex
+		if c == '\'' {
+			body = `"` + strings.ReplaceAll(strings.ReplaceAll(lex[1:len(lex)-1], `\'`, `'`), `"`, `\"`) + `"`
+		}
+		if s, err := strconv.Unquote(body); err == nil {
+			return constValue{kind: tString, s: s}, true
+		}
+	}
+	return constValue{}, false
+}
+
+// foldNumber interpreta un literal numérico con los prefijos 0x/0b/0o, los
+// separadores de dígitos y, en C++, los sufijos u/l/f
+func (tc *TypeChecker) foldNumber(lex string) (constValue, bool) {
+	text := strings.ToLower(lex)
+	wide := false
+	if tc.language == "cpp" {
+		text = strings.ReplaceAll(text, "'", "")
+		hex := strings.HasPrefix(text, "0x")
+		for len(text) > 1 && strings.ContainsRune("ul", rune(text[len(text)-1])) {
+			text = text[:len(text)-1]
+			wide = true
+		}
+		if !hex && strings.HasSuffix(text, "f") {
+			text = strings.TrimSuffix(text, "f")
+		}
+	}
+	if tc.language == "python" || tc.language == "javascript" {
+		text = strings.ReplaceAll(text, "_", "")
+	}
+	isHex := strings.HasPrefix(text, "0x")
+	if !isHex && strings.ContainsAny(text, ".e") {
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return constValue{}, false
+		}
+		return floatConst(f), true
+	}
+	if tc.language == "javascript" && len(text) > 1 && text[0] == '0' && text[1] >= '0' && text[1] <= '9' {
+		// 017 es octal heredado en modo no estricto; no se evalúa
+		return constValue{}, false
+	}
+	i, err := strconv.ParseInt(text, 0, 64)
+	if err != nil {
+		return constValue{}, false
+	}
+	if tc.language == "cpp" && (i > math.MaxInt32 || i < math.MinInt32) {
+		wide = true
+	}
+	return intConst(i, wide), true
+}
+
+// fold evalúa n si es una expresión constante
+func (tc *TypeChecker) fold(n ParseNode) (constValue, bool) {
+	switch n.Kind {
+	case "Literal":
+		return tc.foldLiteral(n.Label)
+	case "Identifier":
+		return tc.lookupConst(n.Label)
+	case "UnaryExpr":
+		if len(n.Children) != 1 {
+			return constValue{}, false
+		}
+		v, ok := tc.fold(n.Children[0])
+		if !ok {
+			return constValue{}, false
+		}
+		return tc.foldUnary(n.Label, v)
+	case "BinaryExpr", "LogicalExpr":
+		if len(n.Children) != 2 {
+			return constValue{}, false
+		}
+		l, ok := tc.fold(n.Children[0])
+		if !ok {
+			return constValue{}, false
+		}
+		r, ok := tc.fold(n.Children[1])
+		if !ok {
+			return constValue{}, false
+		}
+		return tc.foldBinary(n.Label, l, r)
+	}
+	return constValue{}, false
+}
+
+func (tc *TypeChecker) foldUnary(op string, v constValue) (constValue, bool) {
+	switch op {
+	case "-":
+		if v.kind == tInt && v.i != math.MinInt64 {
+			return intConst(-v.i, v.wide), true
+		}
+		if v.kind == tFloat {
+			return floatConst(-v.f), true
+		}
+	case "+":
+		if v.kind == tInt || v.kind == tFloat {
+			return v, true
+		}
+	case "!", "not":
+		if b, ok := v.truthy(tc.language); ok {
+			return boolConst(!b), true
+		}
+	case "~":
+		if v.kind == tInt {
+			return intConst(^v.i, v.wide), true
+		}
+	}
+	return constValue{}, false
+}
+
+func (tc *TypeChecker) foldBinary(op string, l, r constValue) (constValue, bool) {
+	switch op {
+	case "&&", "and", "||", "or":
+		lb, ok := l.truthy(tc.language)
+		if !ok {
+			return constValue{}, false
+		}
+		isAnd := op == "&&" || op == "and"
+		if tc.language == "python" || tc.language == "javascript" {
+			// Devuelven uno de los operandos, no necesariamente un booleano
+			if lb == isAnd {
+				return r, true
+			}
+			return l, true
+		}
+		rb, ok := r.truthy(tc.language)
+		if !ok {
+			return constValue{}, false
+		}
+		if isAnd {
+			return boolConst(lb && rb), true
+		}
+		return boolConst(lb || rb), true
+	}
+
+	if l.kind == tString || r.kind == tString {
+		return tc.foldString(op, l, r)
+	}
+	if l.kind == tBool && r.kind == tBool {
+		switch op {
+		case "==", "===":
+			return boolConst(l.b == r.b), true
+		case "!=", "!==":
+			return boolConst(l.b != r.b), true
+		}
+		if tc.language == "go" {
+			return constValue{}, false
+		}
+	}
+	// En C++, Python y JavaScript los booleanos participan como 0 y 1
+	if l.kind == tBool {
+		l, _ = l.convert(tInt)
+	}
+	if r.kind == tBool {
+		r, _ = r.convert(tInt)
+	}
+
+	switch op {
+	case "==", "===":
+		return boolConst(l.number() == r.number()), true
+	case "!=", "!==":
+		return boolConst(l.number() != r.number()), true
+	case "<":
+		return boolConst(l.number() < r.number()), true
+	case ">":
+		return boolConst(l.number() > r.number()), true
+	case "<=":
+		return boolConst(l.number() <= r.number()), true
+	case ">=":
+		return boolConst(l.number() >= r.number()), true
+	}
+
+	if r.isZero() && (op == "/" || op == "%" || op == "//") {
+		// La división entre cero se reporta aparte; el resultado no se evalúa
+		return constValue{}, false
+	}
+	if l.kind == tFloat || r.kind == tFloat || op == "/" && tc.language == "python" {
+		return tc.foldFloat(op, l.number(), r.number())
+	}
+	if tc.language == "javascript" {
+		// En JavaScript todos los números son double
+		if op == "/" && l.i%r.i != 0 {
+			return floatConst(float64(l.i) / float64(r.i)), true
+		}
+	}
+	return tc.foldInt(op, l, r)
+}
+
+func (tc *TypeChecker) foldFloat(op string, l, r float64) (constValue, bool) {
+	switch op {
+	case "+":
+		return floatConst(l + r), true
+	case "-":
+		return floatConst(l - r), true
+	case "*":
+		return floatConst(l * r), true
+	case "/":
+		return floatConst(l / r), true
+	case "//":
+		return floatConst(math.Floor(l / r)), true
+	case "**":
+		return floatConst(math.Pow(l, r)), true
+	case "%":
+		if tc.language == "python" {
+			return floatConst(l - r*math.Floor(l/r)), true
+		}
+		if tc.language == "javascript" {
+			return floatConst(math.Mod(l, r)), true
+		}
+	}
+	return constValue{}, false
+}
+
+func (tc *TypeChecker) foldInt(op string, l, r constValue) (constValue, bool) {
+	a, b := l.i, r.i
+	wide := l.wide || r.wide
+	var res int64
+	overflow := false
+	switch op {
+	case "+":
+		res = a + b
+		overflow = (a > 0 && b > 0 && res < 0) || (a < 0 && b < 0 && res >= 0)
+	case "-":
+		res = a - b
+		overflow = (a >= 0 && b < 0 && res < 0) || (a < 0 && b > 0 && res >= 0)
+	case "*":
+		res = a * b
+		overflow = a != 0 && (res/a != b || a == -1 && b == math.MinInt64)
+	case "/":
+		res = a / b
+		overflow = a == math.MinInt64 && b == -1
+	case "//":
+		res = a / b
+		if (a%b != 0) && ((a < 0) != (b < 0)) {
+			res--
+		}
+	case "%":
+		res = a % b
+		if tc.language == "python" && res != 0 && (res < 0) != (b < 0) {
+			res += b
+		}
+	case "**":
+		if b < 0 {
+			return floatConst(math.Pow(float64(a), float64(b))), true
+		}
+		// Con estas bases el ciclo no termina por desborde
+		switch {
+		case a == 1 || b == 0:
+			return intConst(1, wide), true
+		case a == 0:
+			return intConst(0, wide), true
+		case a == -1:
+			return intConst(1-2*(b%2), wide), true
+		}
+		res = 1
+		for k := int64(0); k < b; k++ {
+			next := res * a
+			if a != 0 && next/a != res {
+				// Python usa enteros de precisión arbitraria
+				return constValue{}, false
+			}
+			res = next
+		}
+	case "&":
+		res = a & b
+	case "|":
+		res = a | b
+	case "^":
+		res = a ^ b
+	case "<<":
+		if b < 0 || b >= 63 {
+			return constValue{}, false
+		}
+		res = a << uint(b)
+		overflow = res>>uint(b) != a
+	case ">>":
+		if b < 0 || b >= 64 {
+			return constValue{}, false
+		}
+		res = a >> uint(b)
+	default:
+		return constValue{}, false
+	}
+	if tc.language == "javascript" && strings.Contains("&|^<<>>", op) && (res > math.MaxInt32 || res < math.MinInt32) {
+		// Los operadores de bits de JavaScript trabajan con enteros de 32 bits
+		return constValue{}, false
+	}
+	if overflow && tc.language != "cpp" {
+		// Python y las constantes de Go no tienen límite; no se evalúa
+		return constValue{}, false
+	}
+	v := intConst(res, wide)
+	if tc.language == "cpp" && !wide && (res > math.MaxInt32 || res < math.MinInt32) {
+		// La operación entre dos int desborda aunque el resultado se guarde
+		// en un long long; el valor se trunca como lo hace el procesador
+		v.i = int64(int32(res))
+		overflow = true
+	}
+	v.overflow = overflow
+	return v, true
+}
+
+func (tc *TypeChecker) foldString(op string, l, r constValue) (constValue, bool) {
+	if l.kind == tString && r.kind == tString {
+		switch op {
+		case "+":
+			return constValue{kind: tString, s: l.s + r.s}, true
+		case "==", "===":
+			return boolConst(l.s == r.s), true
+		case "!=", "!==":
+			return boolConst(l.s != r.s), true
+		}
+		return constValue{}, false
+	}
+	// JavaScript convierte el número a texto al concatenar
+	if tc.language == "javascript" && op == "+" {
+		str := func(v constValue) (string, bool) {
+			switch v.kind {
+			case tString:
+				return v.s, true
+			case tInt, tBool:
+				return v.format(tc.language), true
+			}
+			return "", false
+		}
+		ls, ok1 := str(l)
+		rs, ok2 := str(r)
+		if ok1 && ok2 {
+			return constValue{kind: tString, s: ls + rs}, true
+		}
+	}
+	return constValue{}, false
+}
+
+// ──────────────────────── Constantes en los ámbitos ───────────────────────
+
+// defineConst registra el valor de una constante en el ámbito actual
+func (tc *TypeChecker) defineConst(name string, v constValue) {
+	if isIdentifierName(name) {
+		tc.consts[len(tc.consts)-1][name] = v
+	}
+}
+
+// lookupConst busca el valor de name en el ámbito más interno que lo
+// declara; si allí no es constante, una constante externa queda oculta
+func (tc *TypeChecker) lookupConst(name string) (constValue, bool) {
+	for i := len(tc.scopes) - 1; i >= 0; i-- {
+		if _, ok := tc.scopes[i][name]; ok {
+			v, isConst := tc.consts[i][name]
+			return v, isConst
+		}
+	}
+	return constValue{}, false
+}
+
+// recordValue guarda el valor inicial de un símbolo para la tabla de
+// símbolos (la primera declaración, como SymbolTypes)
+func (tc *TypeChecker) recordValue(name string, v constValue) {
+	if _, ok := tc.SymbolValues[name]; !ok && isIdentifierName(name) {
+		tc.SymbolValues[name] = v.format(tc.language)
+	}
+}
+
+// isPyConstantName: en Python las constantes se escriben en MAYÚSCULAS
+func isPyConstantName(name string) bool {
+	return strings.ToUpper(name) == name && strings.ContainsAny(name, "ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+}
+
+// checkConstantOps reporta la división entre cero constante y el desborde de
+// la operación n; se llama una vez por nodo desde infer
+func (tc *TypeChecker) checkConstantOps(n ParseNode) {
+	if len(n.Children) != 2 {
+		return
+	}
+	switch n.Label {
+	case "/", "%", "//", "/=", "%=", "//=":
+		if r, ok := tc.fold(n.Children[1]); ok && r.isZero() {
+			tc.report(n.Children[1].Pos, CodeDivisionByZero, "warning", "División entre cero: el divisor de '%s' siempre vale 0", strings.TrimSuffix(n.Label, "="))
+			return
+		}
+	}
+	if tc.language == "cpp" {
+		if v, ok := tc.fold(n); ok && v.overflow {
+			tc.report(n.Pos, CodeIntegerOverflow, "warning", "Desbordamiento de entero: la operación '%s' excede el rango de 'int'; el resultado queda truncado en %d", n.Label, v.i)
+		}
+	}
+}
+
+// checkIntRange valida que el valor constante de una declaración de C++
+// quepa en el tipo entero declarado y devuelve el valor que realmente
+// guarda la variable (unsigned int u = -1 guarda 4294967295)
+func (tc *TypeChecker) checkIntRange(decl, name string, v constValue, pos int) constValue {
+	lo, hi, unsigned, ok := cppIntRange(decl)
+	if !ok || v.kind != tInt || v.i >= lo && v.i <= hi {
+		return v
+	}
+	if unsigned && v.i < 0 {
+		tc.report(pos, CodeIntegerOverflow, "warning", "El valor %d es negativo y '%s' es de tipo '%s': se convierte en un número positivo muy grande", v.i, name, decl)
+	} else {
+		tc.report(pos, CodeIntegerOverflow, "warning", "El valor %d no cabe en el tipo '%s' de '%s' (rango %d a %d)", v.i, decl, name, lo, hi)
+	}
+	if hi != math.MaxInt64 {
+		span := hi - lo + 1
+		v.i = lo + ((v.i-lo)%span+span)%span
+	}
+	return v
+}
+
+// assignConst actualiza el valor de name tras una asignación: lo conserva
+// como constante solo si isConst, si no deja de propagarse
+func (tc *TypeChecker) assignConst(name string, v constValue, isConst bool) {
+	for i := len(tc.scopes) - 1; i >= 0; i-- {
+		if _, ok := tc.scopes[i][name]; ok {
+			if isConst {
+				tc.consts[i][name] = v
+			} else {
+				delete(tc.consts[i], name)
+			}
+			return
+		}
+	}
+}
+
+// checkIntLiteral reporta los literales enteros de C++ que no caben en
+// ningún tipo entero (más de 64 bits)
+func (tc *TypeChecker) checkIntLiteral(n ParseNode) {
+	text := strings.ToLower(strings.ReplaceAll(n.Label, "'", ""))
+	if text == "" || text[0] < '0' || text[0] > '9' || !strings.HasPrefix(text, "0x") && strings.ContainsAny(text, ".e") {
+		return
+	}
+	text = strings.TrimRight(text, "ul")
+	if _, err := strconv.ParseUint(text, 0, 64); err != nil && err.(*strconv.NumError).Err == strconv.ErrRange {
+		tc.report(n.Pos, CodeIntegerOverflow, "warning", "El literal entero %s es demasiado grande para cualquier tipo entero", n.Label)
+	}
+}
@@ -0,0 +1,652 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ─────────────────────────── Flujo de control ────────────────────────────
+//
+// Para cada función (y para el nivel superior del programa) se construye un
+// grafo de flujo de control: bloques básicos unidos por las aristas que
+// generan if, ciclos, switch, try, return, break y continue. Sobre el grafo
+// se reporta código inalcanzable y funciones que pueden terminar sin
+// devolver un valor. Los ciclos infinitos se detectan con heurísticas sobre
+// la condición y el cuerpo, antes de ejecutar el programa.
+
+// flowBlock es un bloque básico: sentencias que se ejecutan en secuencia
+type flowBlock struct {
+	stmts []ParseNode
+	succs []*flowBlock
+	preds int
+}
+
+type flowGraph struct {
+	entry, exit *flowBlock
+	blocks      []*flowBlock
+	// Bloque en el que termina el cuerpo si no hay return: si es alcanzable,
+	// la función puede llegar al final sin devolver nada
+	end *flowBlock
+}
+
+// jumpTarget es el destino de break y continue dentro de un ciclo o switch
+type jumpTarget struct {
+	label   string
+	brk     *flowBlock
+	cont    *flowBlock // nil en un switch: continue pasa al ciclo exterior
+	breaks  int        // break que saltan a este destino
+	isCycle bool
+}
+
+type flowBuilder struct {
+	fa       *FlowAnalyzer
+	language string
+	g        *flowGraph
+	cur      *flowBlock
+	targets  []*jumpTarget
+	label    string // etiqueta pendiente para el próximo ciclo (Labeled)
+}
+
+type FlowAnalyzer struct {
+	language string
+	errors   []CompilerError
+}
+
+func NewFlowAnalyzer(lang string) *FlowAnalyzer {
+	return &FlowAnalyzer{language: lang}
+}
+
+// Analyze recorre el árbol, construye el grafo de cada función y devuelve
+// las advertencias de flujo de control
+func (fa *FlowAnalyzer) Analyze(tree []ParseNode) []CompilerError {
+	for _, root := range tree {
+		if root.Kind == "Program" {
+			fa.checkBody(root.Children, "", false)
+		}
+		fa.walk(root)
+	}
+	return fa.errors
+}
+
+func (fa *FlowAnalyzer) report(pos int, code, format string, args ...interface{}) {
+	fa.errors = append(fa.errors, CompilerError{
+		Message:  "Advertencia de flujo: " + fmt.Sprintf(format, args...),
+		Severity: "warning",
+		Type:     "semantico",
+		Pos:      pos,
+		Code:     code,
+	})
+}
+
+// walk busca funciones en todo el árbol, incluidas las anidadas
+func (fa *FlowAnalyzer) walk(n ParseNode) {
+	switch n.Kind {
+	case "FunctionDecl", "Method", "ArrowFunction":
+		if len(n.Children) > 0 && n.Children[len(n.Children)-1].Kind == "Block" {
+			body := n.Children[len(n.Children)-1]
+			fa.checkBody(body.Children, n.Label, fa.needsReturn(n, body))
+		}
+	}
+	for _, c := range n.Children {
+		fa.walk(c)
+	}
+}
+
+// needsReturn indica si la función debe devolver un valor en todos los
+// caminos: por su tipo declarado en C++ y Go, o porque algún return devuelve
+// un valor en JavaScript y Python
+func (fa *FlowAnalyzer) needsReturn(fn, body ParseNode) bool {
+	switch fa.language {
+	case "cpp":
+		if fn.Label == "main" || strings.HasSuffix(fn.Label, "::main") {
+			return false // main devuelve 0 implícitamente
+		}
+		for _, c := range fn.Children {
+			if c.Kind == "Type" {
+				return c.Label != "" && c.Label != "void" && !strings.HasPrefix(c.Label, "void ")
+			}
+		}
+	case "go":
+		for _, c := range fn.Children {
+			if c.Kind == "Results" || c.Kind == "Type" {
+				return true
+			}
+		}
+	default:
+		return returnsValue(body)
+	}
+	return false
+}
+
+func returnsValue(n ParseNode) bool {
+	for _, c := range n.Children {
+		switch c.Kind {
+		case "Return":
+			if len(c.Children) > 0 {
+				return true
+			}
+		case "FunctionDecl", "Method", "ArrowFunction", "Lambda", "ClassDecl":
+			continue
+		}
+		if returnsValue(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkBody construye el grafo de una secuencia de sentencias y reporta el
+// código inalcanzable y, si needsReturn, la falta de return al final
+func (fa *FlowAnalyzer) checkBody(stmts []ParseNode, name string, needsReturn bool) {
+	g := fa.buildGraph(stmts)
+	reachable := g.reachable()
+
+	// Primera sentencia de cada región inalcanzable; las sentencias anidadas
+	// o consecutivas de la misma región no se vuelven a reportar
+	type located struct {
+		node      ParseNode
+		reachable bool
+	}
+	var all []located
+	for _, b := range g.blocks {
+		for _, s := range b.stmts {
+			all = append(all, located{s, reachable[b]})
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].node.Pos < all[j].node.Pos })
+	inRegion := false
+	for _, l := range all {
+		if !l.reachable && !inRegion {
+			fa.report(l.node.Pos, CodeUnreachableCode, "Código inalcanzable: esta sentencia nunca se ejecuta")
+		}
+		inRegion = !l.reachable
+	}
+
+	if needsReturn && reachable[g.end] {
+		fa.report(fnEndPos(stmts), CodeMissingReturn, "La función '%s' puede terminar sin devolver un valor", name)
+	}
+}
+
+func fnEndPos(stmts []ParseNode) int {
+	if len(stmts) == 0 {
+		return 0
+	}
+	return stmts[len(stmts)-1].End
+}
+
+func (fa *FlowAnalyzer) buildGraph(stmts []ParseNode) *flowGraph {
+	b := &flowBuilder{fa: fa, language: fa.language, g: &flowGraph{}}
+	b.g.entry = b.newBlock()
+	b.g.exit = b.newBlock()
+	b.cur = b.g.entry
+	for _, s := range stmts {
+		b.stmt(s)
+	}
+	b.g.end = b.cur
+	return b.g
+}
+
+func (g *flowGraph) reachable() map[*flowBlock]bool {
+	seen := map[*flowBlock]bool{g.entry: true}
+	stack := []*flowBlock{g.entry}
+	for len(stack) > 0 {
+		b := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, s := range b.succs {
+			if !seen[s] {
+				seen[s] = true
+				stack = append(stack, s)
+			}
+		}
+	}
+	return seen
+}
+
+func (b *flowBuilder) newBlock() *flowBlock {
+	blk := &flowBlock{}
+	b.g.blocks = append(b.g.blocks, blk)
+	return blk
+}
+
+// edge agrega una arista salvo que el origen sea inalcanzable: un bloque sin
+// predecesores que no es la entrada (código después de return o break)
+func (b *flowBuilder) edge(from, to *flowBlock) {
+	if from != b.g.entry && from.preds == 0 {
+		return
+	}
+	from.succs = append(from.succs, to)
+	to.preds++
+}
+
+// jump termina el bloque actual con un salto; lo que sigue queda en un bloque
+// sin predecesores hasta que otra arista llegue a él
+func (b *flowBuilder) jump(to *flowBlock) {
+	b.edge(b.cur, to)
+	b.cur = b.newBlock()
+}
+
+func (b *flowBuilder) add(n ParseNode) { b.cur.stmts = append(b.cur.stmts, n) }
+
+// findTarget devuelve el destino de un break/continue con la etiqueta dada
+func (b *flowBuilder) findTarget(label string, cont bool) *jumpTarget {
+	for i := len(b.targets) - 1; i >= 0; i-- {
+		t := b.targets[i]
+		if label != "" && t.label != label {
+			continue
+		}
+		if cont && !t.isCycle {
+			continue
+		}
+		return t
+	}
+	return nil
+}
+
+func (b *flowBuilder) stmt(n ParseNode) {
+	switch n.Kind {
+	case "Block":
+		for _, c := range n.Children {
+			b.stmt(c)
+		}
+	case "Labeled":
+		b.add(n)
+		b.label = n.Label
+		for _, c := range n.Children {
+			b.stmt(c)
+		}
+		b.label = ""
+	case "If":
+		b.ifStmt(n)
+	case "While", "DoWhile", "For", "ForEach":
+		b.loop(n)
+	case "Switch", "Select":
+		b.switchStmt(n)
+	case "Try":
+		b.tryStmt(n)
+	case "Return", "Throw", "Raise":
+		b.add(n)
+		b.jump(b.g.exit)
+	case "Break", "Continue":
+		b.add(n)
+		label := ""
+		if len(n.Children) > 0 {
+			label = n.Children[0].Label
+		}
+		t := b.findTarget(label, n.Kind == "Continue")
+		switch {
+		case t == nil:
+			b.cur = b.newBlock()
+		case n.Kind == "Continue":
+			b.jump(t.cont)
+		default:
+			t.breaks++
+			b.jump(t.brk)
+		}
+	case "ExprStmt":
+		b.add(n)
+		if len(n.Children) > 0 && b.terminatingCall(n.Children[0]) {
+			b.jump(b.g.exit)
+		}
+	case "With":
+		b.add(n)
+		for _, c := range n.Children {
+			if c.Kind == "Block" {
+				b.stmt(c)
+			}
+		}
+	default:
+		b.add(n)
+	}
+}
+
+func (b *flowBuilder) ifStmt(n ParseNode) {
+	b.add(n)
+	cond := b.cur
+	after := b.newBlock()
+	for i, c := range n.Children {
+		if i == 0 {
+			continue // condición
+		}
+		branch := b.newBlock()
+		b.edge(cond, branch)
+		b.cur = branch
+		if c.Kind == "Else" {
+			for _, e := range c.Children {
+				b.stmt(e)
+			}
+		} else {
+			b.stmt(c)
+		}
+		b.edge(b.cur, after)
+	}
+	if len(n.Children) < 3 {
+		b.edge(cond, after) // sin else
+	}
+	b.cur = after
+}
+
+// loopParts separa la condición (nil si no hay), el cuerpo y la cláusula
+// else de Python de un ciclo
+func loopParts(n ParseNode) (cond *ParseNode, body ParseNode, elseClause *ParseNode, hasUpdate bool) {
+	for i := range n.Children {
+		c := n.Children[i]
+		switch c.Kind {
+		case "Condition":
+			cond = &n.Children[i]
+		case "ForHeader":
+			for j := range c.Children {
+				if c.Children[j].Kind == "Condition" {
+					cond = &c.Children[j]
+					hasUpdate = j < len(c.Children)-1
+				}
+			}
+		case "Block":
+			body = c
+		case "Else":
+			elseClause = &n.Children[i]
+		}
+	}
+	return
+}
+
+func (b *flowBuilder) loop(n ParseNode) {
+	cond, body, elseClause, hasUpdate := loopParts(n)
+	// for-each y ciclos con condición pueden no ejecutar el cuerpo; for sin
+	// condición, while(true) y similares solo salen con break
+	infinite := n.Kind != "ForEach" && (cond == nil || alwaysTrue(*cond))
+
+	b.add(n)
+	head := b.newBlock()
+	after := b.newBlock()
+	target := &jumpTarget{label: b.label, brk: after, cont: head, isCycle: true}
+	b.label = ""
+	b.targets = append(b.targets, target)
+
+	bodyBlock := b.newBlock()
+	if n.Kind == "DoWhile" {
+		b.edge(b.cur, bodyBlock)
+	} else {
+		b.edge(b.cur, head)
+		b.edge(head, bodyBlock)
+	}
+	b.cur = bodyBlock
+	b.stmt(body)
+	b.edge(b.cur, head)
+	if n.Kind == "DoWhile" {
+		b.edge(head, bodyBlock)
+	}
+	b.targets = b.targets[:len(b.targets)-1]
+	b.checkInfiniteLoop(n, cond, body, infinite, hasUpdate, target.breaks > 0)
+
+	if !infinite {
+		// El else de Python se ejecuta cuando el ciclo termina sin break
+		exit := head
+		if elseClause != nil {
+			elseBlock := b.newBlock()
+			b.edge(head, elseBlock)
+			b.cur = elseBlock
+			for _, e := range elseClause.Children {
+				b.stmt(e)
+			}
+			exit = b.cur
+		}
+		b.edge(exit, after)
+	}
+	b.cur = after
+}
+
+func (b *flowBuilder) switchStmt(n ParseNode) {
+	b.add(n)
+	dispatch := b.cur
+	after := b.newBlock()
+	target := &jumpTarget{label: b.label, brk: after}
+	b.label = ""
+	b.targets = append(b.targets, target)
+
+	// En C++ y JavaScript cada caso continúa en el siguiente sin break; en Go
+	// solo con fallthrough
+	fallsThrough := b.language != "go"
+	hasDefault := false
+	var prevEnd *flowBlock
+	prevFalls := false
+	for _, c := range n.Children {
+		if c.Kind != "Block" {
+			continue
+		}
+		for _, clause := range c.Children {
+			if clause.Kind != "Case" {
+				continue
+			}
+			hasDefault = hasDefault || clause.Label == "default"
+			caseBlock := b.newBlock()
+			b.edge(dispatch, caseBlock)
+			if prevEnd != nil && prevFalls {
+				b.edge(prevEnd, caseBlock)
+			}
+			b.cur = caseBlock
+			for _, s := range clause.Children {
+				b.stmt(s)
+			}
+			prevFalls = fallsThrough ||
+				len(clause.Children) > 0 && clause.Children[len(clause.Children)-1].Kind == "Fallthrough"
+			if !prevFalls {
+				b.edge(b.cur, after)
+			}
+			prevEnd = b.cur
+		}
+	}
+	if prevEnd != nil && prevFalls {
+		b.edge(prevEnd, after)
+	}
+	if !hasDefault {
+		b.edge(dispatch, after)
+	}
+	b.targets = b.targets[:len(b.targets)-1]
+	b.cur = after
+}
+
+func (b *flowBuilder) tryStmt(n ParseNode) {
+	b.add(n)
+	start := b.cur
+	join := b.newBlock()
+	var bodyEnd *flowBlock
+	var finally *ParseNode
+	for i, c := range n.Children {
+		switch {
+		case i == 0:
+			body := b.newBlock()
+			b.edge(start, body)
+			b.cur = body
+			b.stmt(c)
+			bodyEnd = b.cur
+		case c.Kind == "Catch":
+			// Cualquier sentencia del try puede lanzar la excepción
+			handler := b.newBlock()
+			b.edge(start, handler)
+			b.cur = handler
+			for _, h := range c.Children {
+				if h.Kind == "Block" {
+					b.stmt(h)
+				}
+			}
+			b.edge(b.cur, join)
+		case c.Kind == "Else":
+			// else de Python: solo si el try terminó sin excepción
+			elseBlock := b.newBlock()
+			b.edge(bodyEnd, elseBlock)
+			b.cur = elseBlock
+			for _, e := range c.Children {
+				b.stmt(e)
+			}
+			bodyEnd = b.cur
+		case c.Kind == "Finally":
+			finally = &n.Children[i]
+		}
+	}
+	b.edge(bodyEnd, join)
+
+	if finally == nil {
+		b.cur = join
+		return
+	}
+	// finally se ejecuta aunque el try y los catch terminen con return; si
+	// ninguno continúa, lo que sigue al try es inalcanzable
+	completes := join.preds > 0
+	fin := b.newBlock()
+	b.edge(join, fin)
+	if !completes {
+		b.edge(start, fin)
+	}
+	b.cur = fin
+	for _, f := range finally.Children {
+		b.stmt(f)
+	}
+	if !completes {
+		b.cur = b.newBlock()
+	}
+}
+
+// terminatingCall reconoce llamadas que terminan el programa (exit, panic)
+func (b *flowBuilder) terminatingCall(n ParseNode) bool {
+	if n.Kind != "Call" {
+		return false
+	}
+	name := n.Label
+	if len(n.Children) > 0 && n.Children[0].Kind == "Member" && len(n.Children[0].Children) > 0 {
+		name = n.Children[0].Children[0].Label + "." + n.Label
+	}
+	return terminatingCalls[b.language][name]
+}
+
+var terminatingCalls = map[string]map[string]bool{
+	"cpp":        {"exit": true, "abort": true, "std.exit": true, "std.abort": true, "std.terminate": true, "quick_exit": true},
+	"go":         {"panic": true, "os.Exit": true, "log.Fatal": true, "log.Fatalf": true, "log.Fatalln": true, "log.Panic": true, "log.Panicf": true},
+	"python":     {"exit": true, "quit": true, "sys.exit": true, "os._exit": true},
+	"javascript": {"process.exit": true},
+}
+
+// alwaysTrue reconoce condiciones constantes: true, True, 1
+func alwaysTrue(cond ParseNode) bool {
+	if len(cond.Children) == 0 {
+		return false
+	}
+	c := cond.Children[0]
+	return c.Kind == "Literal" && (c.Label == "true" || c.Label == "True" || c.Label == "1")
+}
+
+// ─────────────────────────── Ciclos infinitos ────────────────────────────
+
+// checkInfiniteLoop reporta ciclos que no pueden terminar: condición siempre
+// verdadera sin forma de salir, o condición cuyas variables no cambian en el
+// cuerpo. Las llamadas dentro de la condición o sobre sus variables pueden
+// modificarlas, por eso en esos casos no se reporta nada.
+func (b *flowBuilder) checkInfiniteLoop(n ParseNode, cond *ParseNode, body ParseNode, infinite, hasUpdate, hasBreak bool) {
+	if n.Kind == "ForEach" || hasBreak || b.escapes(body) {
+		return
+	}
+	if infinite {
+		b.fa.report(n.Pos, CodeInfiniteLoop, "Bucle infinito: la condición siempre es verdadera y el cuerpo no tiene break, return ni salida del programa")
+		return
+	}
+	if hasUpdate || cond == nil || containsKind(*cond, "Call", "Member", "Index") {
+		return
+	}
+	names := identifierNames(*cond)
+	if len(names) == 0 {
+		return
+	}
+	mutated := map[string]bool{}
+	mutatedNames(body, mutated)
+	for _, name := range names {
+		if mutated[name] {
+			return
+		}
+	}
+	b.fa.report(n.Pos, CodeInfiniteLoop, "Posible bucle infinito: la condición depende de '%s' pero no se modifica dentro del ciclo", strings.Join(names, "', '"))
+}
+
+// escapes indica si el cuerpo puede salir del ciclo sin break: return,
+// throw, goto, yield o una llamada que termina el programa
+func (b *flowBuilder) escapes(n ParseNode) bool {
+	switch n.Kind {
+	case "Return", "Throw", "Raise", "Goto", "Yield":
+		return true
+	case "ExprStmt":
+		if len(n.Children) > 0 && b.terminatingCall(n.Children[0]) {
+			return true
+		}
+	case "FunctionDecl", "Method", "ArrowFunction", "Lambda", "ClassDecl":
+		return false
+	}
+	for _, c := range n.Children {
+		if b.escapes(c) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsKind(n ParseNode, kinds ...string) bool {
+	for _, k := range kinds {
+		if n.Kind == k {
+			return true
+		}
+	}
+	for _, c := range n.Children {
+		if containsKind(c, kinds...) {
+			return true
+		}
+	}
+	return false
+}
+
+// identifierNames devuelve los identificadores distintos de una expresión
+func identifierNames(n ParseNode) []string {
+	var names []string
+	seen := map[string]bool{}
+	var collect func(ParseNode)
+	collect = func(n ParseNode) {
+		if n.Kind == "Identifier" && !seen[n.Label] {
+			seen[n.Label] = true
+			names = append(names, n.Label)
+		}
+		for _, c := range n.Children {
+			collect(c)
+		}
+	}
+	collect(n)
+	return names
+}
+
+// mutatedNames agrega los nombres que el cuerpo puede modificar: destinos de
+// asignaciones, ++/--, lecturas con >>, y variables pasadas a llamadas o
+// usadas como receptor de un método
+func mutatedNames(n ParseNode, out map[string]bool) {
+	mark := func(n ParseNode) {
+		for _, name := range identifierNames(n) {
+			out[name] = true
+		}
+	}
+	switch n.Kind {
+	case "Assign", "AugAssign", "AnnAssign":
+		if len(n.Children) > 0 {
+			mark(n.Children[0])
+		}
+	case "PostfixExpr", "UnaryExpr":
+		if n.Label == "++" || n.Label == "--" || n.Label == "&" {
+			mark(n)
+		}
+	case "BinaryExpr":
+		if n.Label == ">>" && len(n.Children) > 1 {
+			mark(n.Children[1])
+		}
+	case "Call":
+		mark(n)
+	case "VarDecl":
+		out[n.Label] = true
+	}
+	for _, c := range n.Children {
+		mutatedNames(c, out)
+	}
+}
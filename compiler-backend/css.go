@@ -0,0 +1,564 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ──────────────────────────────── CSS ────────────────────────────────────
+//
+// CSS no es un lenguaje de programación: no hay variables que declarar ni un
+// programa que ejecutar. El análisis semántico valida los nombres de las
+// propiedades contra una lista conocida y registra como símbolos las
+// propiedades personalizadas (--color), los @keyframes y las clases e ids de
+// los selectores. La ejecución es siempre simulada: resume las reglas con la
+// especificidad de cada selector, que decide cuál gana en la cascada.
+
+// ───────────────────────────────── Lexer ─────────────────────────────────
+
+var cssPatterns = struct {
+	URL, Hash, Number, Ident *regexp.Regexp
+}{
+	// url(imagen.png) sin comillas es un único token de cadena
+	URL:  regexp.MustCompile(`^url\(\s*[^)'"\s]*\s*\)`),
+	Hash: regexp.MustCompile(`^#[\w-]+`),
+	// Número con unidad o porcentaje: 10px, 1.5em, -2rem, 50%
+	Number: regexp.MustCompile(`^-?(?:\d+\.?\d*|\.\d+)(?:%|[a-zA-Z]+)?`),
+	// Los identificadores admiten guiones: font-size, -webkit-box, --color
+	Ident: regexp.MustCompile(`^-{0,2}[\p{L}_][\p{L}\p{N}_-]*`),
+}
+
+func cssURL(_ *LanguagePatterns, s string, p int) (TokenType, string) {
+	if lex, ok := matchHere(cssPatterns.URL, s, p); ok {
+		return STRING, lex
+	}
+	return UNKNOWN, ""
+}
+
+// cssHash reconoce #id en los selectores y #fff en los valores
+func cssHash(_ *LanguagePatterns, s string, p int) (TokenType, string) {
+	if lex, ok := matchHere(cssPatterns.Hash, s, p); ok {
+		return CONSTANT, lex
+	}
+	return UNKNOWN, ""
+}
+
+func cssNumber(_ *LanguagePatterns, s string, p int) (TokenType, string) {
+	if lex, ok := matchHere(cssPatterns.Number, s, p); ok {
+		return NUMBER, lex
+	}
+	return UNKNOWN, ""
+}
+
+func cssIdent(_ *LanguagePatterns, s string, p int) (TokenType, string) {
+	if lex, ok := matchHere(cssPatterns.Ident, s, p); ok {
+		return IDENTIFIER, lex
+	}
+	return UNKNOWN, ""
+}
+
+// En CSS las unidades forman parte del número y los guiones del nombre
+var cssOrder = []matcher{whitespace, comment, strlit, unclosedString, cssURL, cssHash, keyword, cssNumber, cssIdent, oper, delim}
+
+func init() {
+	RegisterLanguage(&languageDef{
+		name:     "css",
+		patterns: LanguageSpecificPatterns["css"],
+		matchers: cssOrder,
+		parse:    (*Parser).parseCSSProgram,
+		// CSS no declara variables: se validan propiedades y selectores
+		analyze: (*SemanticAnalyzer).analyzeCSS,
+	})
+}
+
+// ─────────────────────────── Propiedades ─────────────────────────────────
+
+// Propiedades estándar reconocidas; las personalizadas (--x) y las de
+// prefijo de navegador (-webkit-x) se aceptan siempre
+var cssProperties = makeSet(strings.Fields(`
+	accent-color align-content align-items align-self all animation animation-delay
+	animation-direction animation-duration animation-fill-mode animation-iteration-count
+	animation-name animation-play-state animation-timing-function appearance aspect-ratio
+	backdrop-filter backface-visibility background background-attachment background-blend-mode
+	background-clip background-color background-image background-origin background-position
+	background-position-x background-position-y background-repeat background-size block-size
+	border border-block border-block-end border-block-start border-bottom border-bottom-color
+	border-bottom-left-radius border-bottom-right-radius border-bottom-style border-bottom-width
+	border-collapse border-color border-image border-image-outset border-image-repeat
+	border-image-slice border-image-source border-image-width border-inline border-inline-end
+	border-inline-start border-left border-left-color border-left-style border-left-width
+	border-radius border-right border-right-color border-right-style border-right-width
+	border-spacing border-style border-top border-top-color border-top-left-radius
+	border-top-right-radius border-top-style border-top-width border-width bottom
+	box-decoration-break box-shadow box-sizing break-after break-before break-inside
+	caption-side caret-color clear clip clip-path color color-scheme column-count column-fill
+	column-gap column-rule column-rule-color column-rule-style column-rule-width column-span
+	column-width columns contain container container-name container-type content
+	content-visibility counter-increment counter-reset counter-set cursor direction display
+	empty-cells filter flex flex-basis flex-direction flex-flow flex-grow flex-shrink flex-wrap
+	float font font-display font-family font-feature-settings font-kerning font-size
+	font-size-adjust font-stretch font-style font-variant font-variant-caps
+	font-variant-numeric font-variation-settings font-weight gap grid grid-area grid-auto-columns
+	grid-auto-flow grid-auto-rows grid-column grid-column-end grid-column-gap grid-column-start
+	grid-gap grid-row grid-row-end grid-row-gap grid-row-start grid-template grid-template-areas
+	grid-template-columns grid-template-rows height hyphens image-rendering inline-size inset
+	inset-block inset-inline isolation justify-content justify-items justify-self left
+	letter-spacing line-break line-height list-style list-style-image list-style-position
+	list-style-type margin margin-block margin-block-end margin-block-start margin-bottom
+	margin-inline margin-inline-end margin-inline-start margin-left margin-right margin-top
+	mask mask-image mask-position mask-repeat mask-size max-block-size max-height
+	max-inline-size max-width min-block-size min-height min-inline-size min-width
+	mix-blend-mode object-fit object-position offset opacity order orphans outline
+	outline-color outline-offset outline-style outline-width overflow overflow-anchor
+	overflow-wrap overflow-x overflow-y overscroll-behavior padding padding-block
+	padding-block-end padding-block-start padding-bottom padding-inline padding-inline-end
+	padding-inline-start padding-left padding-right padding-top page-break-after
+	page-break-before page-break-inside perspective perspective-origin place-content
+	place-items place-self pointer-events position print-color-adjust quotes resize right
+	rotate row-gap scale scroll-behavior scroll-margin scroll-padding scroll-snap-align
+	scroll-snap-type scrollbar-color scrollbar-gutter scrollbar-width shape-outside tab-size
+	table-layout text-align text-align-last text-decoration text-decoration-color
+	text-decoration-line text-decoration-style text-decoration-thickness text-indent
+	text-justify text-orientation text-overflow text-rendering text-shadow text-transform
+	text-underline-offset text-underline-position top touch-action transform transform-origin
+	transform-style transition transition-delay transition-duration transition-property
+	transition-timing-function translate unicode-bidi unicode-range user-select vertical-align
+	visibility white-space widows width will-change word-break word-spacing word-wrap
+	writing-mode z-index zoom src size marks bleed syntax inherits initial-value
+`))
+
+// At-rules cuyo bloque contiene declaraciones y no reglas
+var cssDeclarationAtRules = map[string]bool{
+	"@font-face": true, "@page": true, "@counter-style": true, "@property": true, "@viewport": true,
+}
+
+func makeSet(words []string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
+
+// cssKnownProperty indica si la propiedad es estándar, personalizada o de
+// un navegador concreto
+func cssKnownProperty(name string) bool {
+	name = strings.ToLower(name)
+	return cssProperties[name] || strings.HasPrefix(name, "-")
+}
+
+// cssSuggestion devuelve la propiedad conocida más parecida a name (a lo sumo
+// dos ediciones de distancia) o "" si no hay ninguna
+func cssSuggestion(name string) string {
+	best, bestDist := "", 3
+	for prop := range cssProperties {
+		if d := editDistance(strings.ToLower(name), prop); d < bestDist || d == bestDist && best != "" && prop < best {
+			best, bestDist = prop, d
+		}
+	}
+	return best
+}
+
+// editDistance es la distancia de edición entre a y b; una transposición
+// (widht → width) cuenta como un solo cambio
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+// ─────────────────────────────── Semántica ───────────────────────────────
+
+// analyzeCSS reemplaza el análisis de variables: valida las declaraciones y
+// arma la tabla de símbolos de la hoja de estilos
+func (s *SemanticAnalyzer) analyzeCSS() ([]Symbol, []CompilerError) {
+	var syms []Symbol
+	var errors []CompilerError
+	index := make(map[string]int) // "tipo nombre" → posición en syms
+	declare := func(name, kind, value string, pos int) {
+		key := kind + " " + name
+		if i, ok := index[key]; ok {
+			syms[i].References = append(syms[i].References, pos)
+			return
+		}
+		index[key] = len(syms)
+		syms = append(syms, Symbol{Name: name, Kind: kind, Value: value, Pos: pos})
+	}
+	type use struct {
+		name string
+		pos  int
+	}
+	var varUses, animationUses []use
+
+	var walk func(n ParseNode)
+	walk = func(n ParseNode) {
+		switch n.Kind {
+		case "Selector":
+			// Las clases e ids aparecen primero en un selector; los usos
+			// siguientes son referencias
+			for i, tk := range s.tokens {
+				if tk.Start < n.Pos || tk.Start >= n.End {
+					continue
+				}
+				switch {
+				case tk.Type == CONSTANT:
+					declare(tk.Lexeme, "id", "", tk.Start)
+				case tk.Lexeme == "." && i+1 < len(s.tokens) && s.tokens[i+1].Type == IDENTIFIER && s.tokens[i+1].Start == tk.End:
+					declare("."+s.tokens[i+1].Lexeme, "class", "", tk.Start)
+				}
+			}
+			return
+		case "AtRule":
+			if strings.HasSuffix(n.Label, "keyframes") && len(n.Children) > 0 && n.Children[0].Kind == "Prelude" {
+				declare(n.Children[0].Label, "keyframes", "", n.Children[0].Pos)
+			}
+		case "Block":
+			errors = append(errors, s.checkCSSBlock(n)...)
+		case "Declaration":
+			value := ""
+			if len(n.Children) > 0 && n.Children[0].Kind == "Value" {
+				value = n.Children[0].Label
+			}
+			prop := strings.ToLower(n.Label)
+			switch {
+			case strings.HasPrefix(n.Label, "--"):
+				declare(n.Label, "variable", value, n.Pos)
+			case !cssKnownProperty(n.Label):
+				msg := fmt.Sprintf("Error semántico: Propiedad CSS desconocida '%s'", n.Label)
+				hint := ""
+				if suggestion := cssSuggestion(n.Label); suggestion != "" {
+					msg += fmt.Sprintf(" (¿quiso decir '%s'?)", suggestion)
+					hint = "replace:" + suggestion
+				}
+				errors = append(errors, CompilerError{
+					Message:  msg,
+					Severity: "warning",
+					Type:     "semantico",
+					Pos:      n.Pos,
+					Code:     CodeUnknownProperty,
+					Hint:     hint,
+				})
+			}
+			for i, tk := range s.tokens {
+				if tk.Start < n.Pos || tk.Start >= n.End || tk.Type != IDENTIFIER {
+					continue
+				}
+				switch {
+				case strings.HasPrefix(tk.Lexeme, "--") && i > 1 && s.tokens[i-2].Lexeme == "var":
+					varUses = append(varUses, use{tk.Lexeme, tk.Start})
+				case (prop == "animation" || prop == "animation-name") && tk.Start > n.Pos:
+					animationUses = append(animationUses, use{tk.Lexeme, tk.Start})
+				}
+			}
+			return
+		}
+		for _, c := range n.Children {
+			walk(c)
+		}
+	}
+	for _, n := range s.tree {
+		walk(n)
+	}
+
+	// var(--x) y animation: nombre hacen referencia a lo declarado en
+	// cualquier parte de la hoja, antes o después del uso
+	for _, u := range varUses {
+		if i, ok := index["variable "+u.name]; ok {
+			syms[i].References = append(syms[i].References, u.pos)
+			continue
+		}
+		// Puede estar definida en otra hoja o en el estilo en línea
+		errors = append(errors, CompilerError{
+			Message:  fmt.Sprintf("Error semántico: Propiedad personalizada '%s' no fue declarada en esta hoja de estilos", u.name),
+			Severity: "warning",
+			Type:     "semantico",
+			Pos:      u.pos,
+			Code:     CodeUndeclaredVariable,
+		})
+	}
+	for _, u := range animationUses {
+		if i, ok := index["keyframes "+u.name]; ok {
+			syms[i].References = append(syms[i].References, u.pos)
+		}
+	}
+	for i := range syms {
+		sort.Ints(syms[i].References)
+	}
+	sort.SliceStable(errors, func(i, j int) bool { return errors[i].Pos < errors[j].Pos })
+	return syms, errors
+}
+
+// checkCSSBlock advierte los bloques vacíos y las propiedades repetidas en
+// un mismo bloque, donde la última declaración anula a las anteriores
+func (s *SemanticAnalyzer) checkCSSBlock(block ParseNode) []CompilerError {
+	var errors []CompilerError
+	if len(block.Children) == 0 {
+		errors = append(errors, CompilerError{
+			Message:  "Error semántico: Bloque de reglas vacío",
+			Severity: "warning",
+			Type:     "semantico",
+			Pos:      block.Pos,
+			Code:     CodeEmptyRule,
+		})
+	}
+	seen := make(map[string]bool)
+	for _, decl := range block.Children {
+		if decl.Kind != "Declaration" || len(decl.Children) == 0 {
+			continue
+		}
+		// display: -webkit-box; display: flex; es un respaldo intencional
+		value := decl.Children[0].Label
+		if strings.HasPrefix(value, "-") || strings.Contains(value, "(") {
+			continue
+		}
+		prop := strings.ToLower(decl.Label)
+		if seen[prop] {
+			errors = append(errors, CompilerError{
+				Message:  fmt.Sprintf("Error semántico: La propiedad '%s' se repite en el mismo bloque; solo se aplica la última", decl.Label),
+				Severity: "warning",
+				Type:     "semantico",
+				Pos:      decl.Pos,
+				Code:     CodeDuplicateProperty,
+			})
+		}
+		seen[prop] = true
+	}
+	return errors
+}
+
+// ──────────────────────────── Especificidad ──────────────────────────────
+
+// cssSpecificity es la especificidad (ids, clases, tipos) de un selector
+type cssSpecificity [3]int
+
+func (a cssSpecificity) String() string { return fmt.Sprintf("(%d,%d,%d)", a[0], a[1], a[2]) }
+
+func (a cssSpecificity) less(b cssSpecificity) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+// Pseudo-elementos que también se escriben con un solo ':'
+var cssLegacyPseudoElements = map[string]bool{
+	"before": true, "after": true, "first-line": true, "first-letter": true,
+}
+
+// selectorSpecificity calcula la especificidad de un selector: cada #id
+// suma a la primera columna; clases, atributos y pseudo-clases a la segunda;
+// tipos y pseudo-elementos a la tercera. :is(), :not() y :has() valen lo
+// que su argumento más específico y :where() no suma.
+func selectorSpecificity(sel string) cssSpecificity {
+	var spec cssSpecificity
+	nameEnd := func(i int) int {
+		for i < len(sel) && (isCSSNameByte(sel[i])) {
+			i++
+		}
+		return i
+	}
+	for i := 0; i < len(sel); {
+		c := sel[i]
+		switch {
+		case c == '#':
+			spec[0]++
+			i = nameEnd(i + 1)
+		case c == '.':
+			spec[1]++
+			i = nameEnd(i + 1)
+		case c == '[':
+			spec[1]++
+			i = closingIndex(sel, i, '[', ']') + 1
+		case c == ':':
+			element := strings.HasPrefix(sel[i:], "::")
+			start := i + 1
+			if element {
+				start++
+			}
+			end := nameEnd(start)
+			name := strings.ToLower(sel[start:end])
+			i = end
+			var args string
+			if i < len(sel) && sel[i] == '(' {
+				close := closingIndex(sel, i, '(', ')')
+				args = sel[i+1 : min(close, len(sel))]
+				i = close + 1
+			}
+			switch {
+			case element || cssLegacyPseudoElements[name]:
+				spec[2]++
+			case name == "where":
+			case name == "is" || name == "not" || name == "has" || name == "matches":
+				var most cssSpecificity
+				for _, arg := range splitSelectorList(args) {
+					if s := selectorSpecificity(arg); most.less(s) {
+						most = s
+					}
+				}
+				for k := range spec {
+					spec[k] += most[k]
+				}
+			default:
+				spec[1]++
+			}
+		case isCSSNameByte(c) && c != '-' || c == '-' && i+1 < len(sel) && isCSSNameByte(sel[i+1]):
+			// Selector de tipo (div, h1); en @keyframes, from/to/50% no suman
+			end := nameEnd(i)
+			word := strings.ToLower(sel[i:end])
+			if c < '0' || c > '9' {
+				if word != "from" && word != "to" {
+					spec[2]++
+				}
+			}
+			i = end
+		default:
+			i++
+		}
+	}
+	return spec
+}
+
+func isCSSNameByte(c byte) bool {
+	return c == '-' || c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+// closingIndex devuelve el índice del delimitador que cierra al de open
+// (o el final de s si no se cierra)
+func closingIndex(s string, open int, o, c byte) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case o:
+			depth++
+		case c:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(s)
+}
+
+// splitSelectorList separa "a, b:is(c, d)" en sus selectores de nivel superior
+func splitSelectorList(list string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(list); i++ {
+		switch list[i] {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(list[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(list[start:]))
+}
+
+// ─────────────────────────── Ejecución simulada ──────────────────────────
+
+// cssExecutor "ejecuta" una hoja de estilos: lista cada selector en el orden
+// de la cascada con su especificidad, sus declaraciones y la at-rule que lo
+// contiene (@media...)
+type cssExecutor struct{}
+
+func (cssExecutor) Execute(code string, _ []Symbol) ExecutionResult {
+	tree, _ := NewParser(Tokenize(code, "css"), "css", code).Parse()
+
+	type row struct {
+		selector, context string
+		spec              cssSpecificity
+		declarations      int
+	}
+	var rows []row
+	rules, declarations := 0, 0
+	var walk func(n ParseNode, context string)
+	walk = func(n ParseNode, context string) {
+		switch n.Kind {
+		case "Rule":
+			rules++
+			count := 0
+			for _, c := range n.Children {
+				if c.Kind == "Block" {
+					for _, d := range c.Children {
+						if d.Kind == "Declaration" {
+							count++
+						}
+					}
+				}
+			}
+			declarations += count
+			for _, c := range n.Children {
+				if c.Kind == "Selector" {
+					rows = append(rows, row{c.Label, context, selectorSpecificity(c.Label), count})
+				}
+			}
+		case "AtRule":
+			// Los pasos de @keyframes (from, 50%, to) no participan de la cascada
+			if strings.HasSuffix(n.Label, "keyframes") {
+				return
+			}
+			context = strings.TrimSpace(context + " " + n.Label)
+			if len(n.Children) > 0 && n.Children[0].Kind == "Prelude" {
+				context += " " + n.Children[0].Label
+			}
+		}
+		for _, c := range n.Children {
+			walk(c, context)
+		}
+	}
+	for _, n := range tree {
+		walk(n, "")
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "[simulado css] %d regla(s), %d selector(es), %d declaración(es)\n", rules, len(rows), declarations)
+	if len(rows) == 0 {
+		return ExecutionResult{Output: out.String(), Ok: true, RunStdout: out.String()}
+	}
+	width := len("Selector")
+	for _, r := range rows {
+		width = max(width, len(r.selector))
+	}
+	fmt.Fprintf(&out, "\n%-*s  %-13s  %s\n", width, "Selector", "Especificidad", "Declaraciones")
+	most := rows[0]
+	for _, r := range rows {
+		line := fmt.Sprintf("%-*s  %-13s  %d", width, r.selector, r.spec, r.declarations)
+		if r.context != "" {
+			line += "  " + r.context
+		}
+		out.WriteString(line + "\n")
+		if most.spec.less(r.spec) {
+			most = r
+		}
+	}
+	fmt.Fprintf(&out, "\nSelector más específico: %s %s\n", most.selector, most.spec)
+	return ExecutionResult{Output: out.String(), Ok: true, RunStdout: out.String()}
+}
@@ -0,0 +1,466 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ──────────────────────────── Reglas del curso ────────────────────────────
+//
+// CUSTOM_RULES apunta a un archivo JSON o YAML con diagnósticos propios del
+// despliegue, para que un docente prohíba construcciones en una tarea (goto,
+// eval, break dentro de un for) sin tocar el código del servidor:
+//
+//	rules:
+//	  - name: no-goto
+//	    languages: [cpp]
+//	    tokens: goto
+//	    message: En esta tarea no se permite goto
+//	    severity: error
+//	  - name: no-eval
+//	    languages: [javascript, typescript, python]
+//	    pattern: '\beval\s*\('
+//	    message: No use eval; procese la entrada a mano
+//
+// Una regla busca una expresión regular en el código (pattern, fuera de
+// comentarios y cadenas) o una secuencia de tokens separados por espacios
+// (tokens, donde * es cualquier token). Cada una recibe un código USR001,
+// USR002... en el orden del archivo, salvo que indique el suyo, y su nombre
+// se agrega al catálogo de errores. Como la política de seguridad, son
+// reglas del servidor: ni la petición ni un comentario las desactivan.
+//
+// El YAML admitido es el subconjunto que usan este archivo y los perfiles de
+// tareas (ver assignments.go): mapas, listas con guiones o entre corchetes y
+// escalares con o sin comillas; true, false, null y los números sin
+// comillas tienen su tipo.
+
+type customRule struct {
+	Name      string   `json:"name"`
+	Code      string   `json:"code"`
+	Languages []string `json:"languages"`
+	Pattern   string   `json:"pattern"`
+	Tokens    string   `json:"tokens"`
+	Message   string   `json:"message"`
+	Severity  string   `json:"severity"`
+	Hint      string   `json:"hint"`
+
+	regex *regexp.Regexp
+	seq   []string
+}
+
+type customRulesFile struct {
+	Rules []customRule `json:"rules"`
+}
+
+var (
+	customRuleName = regexp.MustCompile(`^[a-z][a-z0-9]*(?:-[a-z0-9]+)*$`)
+	customRuleCode = regexp.MustCompile(`^USR[0-9]{3}$`)
+)
+
+// decodeConfigFile lee el archivo JSON o YAML (según su extensión) path en
+// v; un campo desconocido es un error
+func decodeConfigFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+		doc, err := parseSimpleYAML(string(data))
+		if err != nil {
+			return err
+		}
+		if data, err = json.Marshal(doc); err != nil {
+			return err
+		}
+	}
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// loadCustomRules lee y valida el archivo de reglas path
+func loadCustomRules(path string) ([]customRule, error) {
+	var file customRulesFile
+	if err := decodeConfigFile(path, &file); err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	for i := range file.Rules {
+		r := &file.Rules[i]
+		if r.Code == "" {
+			r.Code = fmt.Sprintf("USR%03d", i+1)
+		}
+		if err := r.compile(); err != nil {
+			return nil, fmt.Errorf("rule %d (%s): %v", i+1, r.Name, err)
+		}
+		if _, builtin := diagnosticCode(r.Name); builtin || seen[r.Name] || seen[r.Code] {
+			return nil, fmt.Errorf("rule %d (%s): duplicate name or code", i+1, r.Name)
+		}
+		seen[r.Name], seen[r.Code] = true, true
+	}
+	return file.Rules, nil
+}
+
+// compile valida la regla y prepara su expresión o secuencia de tokens
+func (r *customRule) compile() error {
+	switch {
+	case !customRuleName.MatchString(r.Name):
+		return fmt.Errorf("name must be kebab-case")
+	case !customRuleCode.MatchString(r.Code):
+		return fmt.Errorf("code must look like USR001")
+	case r.Message == "":
+		return fmt.Errorf("message is required")
+	case (r.Pattern == "") == (r.Tokens == ""):
+		return fmt.Errorf("exactly one of pattern or tokens is required")
+	}
+	switch r.Severity {
+	case "":
+		r.Severity = "warning"
+	case "error", "warning":
+	default:
+		return fmt.Errorf("severity must be error or warning")
+	}
+	if r.Hint == "" {
+		r.Hint = "follow-course-rules"
+	}
+	for i, lang := range r.Languages {
+		if r.Languages[i] = mapLanguage(lang); r.Languages[i] == "" {
+			return fmt.Errorf("unknown language %s", lang)
+		}
+	}
+	if r.Pattern != "" {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return err
+		}
+		r.regex = re
+	}
+	r.seq = strings.Fields(r.Tokens)
+	return nil
+}
+
+// registerCustomRules agrega las reglas al catálogo de errores, para que se
+// puedan nombrar como los diagnósticos propios
+func registerCustomRules(rules []customRule) {
+	for _, r := range rules {
+		errorCatalog[r.Code] = ErrorCodeInfo{r.Name, r.Hint}
+	}
+}
+
+// checkCustomRules devuelve los diagnósticos de las reglas que aplican a
+// language
+func checkCustomRules(code string, tokens []Token, language string, rules []customRule) []CompilerError {
+	var errors []CompilerError
+	var significant []Token
+	for _, tk := range tokens {
+		if tk.Type != WHITESPACE && tk.Type != COMMENT {
+			significant = append(significant, tk)
+		}
+	}
+	for _, r := range rules {
+		if len(r.Languages) > 0 && !containsLanguage(r.Languages, language) {
+			continue
+		}
+		for _, pos := range r.matches(code, tokens, significant) {
+			errors = append(errors, CompilerError{
+				Message:  "Regla del curso: " + r.Message,
+				Severity: r.Severity,
+				Type:     "semantico",
+				Pos:      pos,
+				Code:     r.Code,
+				Hint:     r.Hint,
+			})
+		}
+	}
+	return errors
+}
+
+func containsLanguage(languages []string, language string) bool {
+	for _, lang := range languages {
+		if lang == language {
+			return true
+		}
+	}
+	return false
+}
+
+// matches devuelve dónde empieza cada coincidencia de la regla
+func (r customRule) matches(code string, tokens, significant []Token) []int {
+	var found []int
+	if r.regex != nil {
+		for _, m := range r.regex.FindAllStringIndex(code, -1) {
+			if !insideCommentOrString(tokens, m[0]) {
+				found = append(found, m[0])
+			}
+		}
+		return found
+	}
+	for i := 0; i+len(r.seq) <= len(significant); i++ {
+		match := true
+		for j, lexeme := range r.seq {
+			if lexeme != "*" && significant[i+j].Lexeme != lexeme {
+				match = false
+				break
+			}
+		}
+		if match {
+			found = append(found, significant[i].Start)
+		}
+	}
+	return found
+}
+
+func insideCommentOrString(tokens []Token, pos int) bool {
+	for _, tk := range tokens {
+		if tk.Start > pos {
+			break
+		}
+		if pos < tk.End && (tk.Type == COMMENT || tk.Type == STRING) {
+			return true
+		}
+	}
+	return false
+}
+
+// ───── YAML ─────
+
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+// parseSimpleYAML convierte el subconjunto de YAML de las reglas en los
+// mismos valores que produciría json.Unmarshal
+func parseSimpleYAML(src string) (interface{}, error) {
+	var lines []yamlLine
+	for i, raw := range strings.Split(src, "\n") {
+		text := strings.TrimRight(stripYAMLComment(raw), " \t\r")
+		if strings.TrimSpace(text) == "" || text == "---" {
+			continue
+		}
+		if strings.HasPrefix(strings.TrimLeft(text, " "), "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", i+1)
+		}
+		trimmed := strings.TrimLeft(text, " ")
+		lines = append(lines, yamlLine{i + 1, len(text) - len(trimmed), trimmed})
+	}
+	if len(lines) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	p := &yamlParser{lines: lines}
+	value, err := p.block(lines[0].indent)
+	if err == nil && p.i < len(p.lines) {
+		err = fmt.Errorf("line %d: unexpected indentation", p.lines[p.i].number)
+	}
+	return value, err
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	i     int
+}
+
+// block lee el mapa o la lista que empieza en la línea actual con sangría
+// indent
+func (p *yamlParser) block(indent int) (interface{}, error) {
+	if isYAMLItem(p.lines[p.i].text) {
+		return p.sequence(indent)
+	}
+	return p.mapping(indent)
+}
+
+func (p *yamlParser) sequence(indent int) (interface{}, error) {
+	items := []interface{}{}
+	for p.i < len(p.lines) && p.lines[p.i].indent == indent && isYAMLItem(p.lines[p.i].text) {
+		line := &p.lines[p.i]
+		content := strings.TrimLeft(strings.TrimPrefix(line.text, "-"), " ")
+		if content == "" {
+			p.i++
+			if p.i >= len(p.lines) || p.lines[p.i].indent <= indent {
+				items = append(items, nil)
+				continue
+			}
+			item, err := p.block(p.lines[p.i].indent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			continue
+		}
+		if _, _, isKey := splitYAMLKey(content); isKey {
+			// - name: x abre un mapa cuyas claves siguen alineadas con name
+			line.indent += len(line.text) - len(content)
+			line.text = content
+			item, err := p.mapping(line.indent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+			continue
+		}
+		value, err := parseYAMLScalar(content, line.number)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, value)
+		p.i++
+	}
+	return items, nil
+}
+
+func (p *yamlParser) mapping(indent int) (interface{}, error) {
+	m := map[string]interface{}{}
+	for p.i < len(p.lines) && p.lines[p.i].indent == indent {
+		line := p.lines[p.i]
+		key, rest, ok := splitYAMLKey(line.text)
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key: value", line.number)
+		}
+		if _, dup := m[key]; dup {
+			return nil, fmt.Errorf("line %d: duplicate key %s", line.number, key)
+		}
+		p.i++
+		if rest != "" {
+			value, err := parseYAMLScalar(rest, line.number)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = value
+			continue
+		}
+		// El valor es un bloque más adentro, o una lista con guiones a la
+		// misma altura que la clave
+		switch {
+		case p.i < len(p.lines) && p.lines[p.i].indent > indent:
+			value, err := p.block(p.lines[p.i].indent)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = value
+		case p.i < len(p.lines) && p.lines[p.i].indent == indent && isYAMLItem(p.lines[p.i].text):
+			value, err := p.sequence(indent)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = value
+		default:
+			m[key] = nil
+		}
+	}
+	return m, nil
+}
+
+func isYAMLItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// splitYAMLKey separa "clave: valor"; la clave no lleva comillas
+func splitYAMLKey(text string) (key, rest string, ok bool) {
+	if strings.HasPrefix(text, `"`) || strings.HasPrefix(text, "'") || strings.HasPrefix(text, "[") {
+		return "", "", false
+	}
+	if strings.HasSuffix(text, ":") {
+		return strings.TrimSpace(text[:len(text)-1]), "", true
+	}
+	if i := strings.Index(text, ": "); i > 0 {
+		return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+2:]), true
+	}
+	return "", "", false
+}
+
+func parseYAMLScalar(text string, line int) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(text, "["):
+		if !strings.HasSuffix(text, "]") {
+			return nil, fmt.Errorf("line %d: unclosed list", line)
+		}
+		items := []interface{}{}
+		for _, part := range splitOutsideQuotes(text[1 : len(text)-1]) {
+			if part = strings.TrimSpace(part); part == "" {
+				continue
+			}
+			item, err := parseYAMLScalar(part, line)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case strings.HasPrefix(text, `"`):
+		s, err := strconv.Unquote(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid quoted string", line)
+		}
+		return s, nil
+	case strings.HasPrefix(text, "'"):
+		if len(text) < 2 || !strings.HasSuffix(text, "'") {
+			return nil, fmt.Errorf("line %d: invalid quoted string", line)
+		}
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	case text == "true" || text == "false":
+		return text == "true", nil
+	case text == "null" || text == "~":
+		return nil, nil
+	}
+	// Los números van sin comillas (points: 2); entre comillas son texto
+	if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(text, 64); err == nil && strings.ContainsAny(text[:1], "0123456789.+-") {
+		return f, nil
+	}
+	return text, nil
+}
+
+// splitOutsideQuotes separa s en las comas que no están entre comillas
+func splitOutsideQuotes(s string) []string {
+	var parts []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// stripYAMLComment quita un comentario # que no esté entre comillas
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			// Solo abre comillas al empezar un valor, no en don't
+			if i == 0 || strings.ContainsRune(" :[,-", rune(line[i-1])) {
+				quote = c
+			}
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
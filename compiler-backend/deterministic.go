@@ -0,0 +1,163 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// ─────────────────────────── Ejecución determinista ──────────────────────
+//
+// Con deterministic en la petición el programa ve siempre los mismos números
+// aleatorios y la misma hora, así una entrega que usa rand(),
+// random.random() o Date.now() se puede calificar con el modo juez. El
+// generador arranca con deterministicSeed y el reloj queda detenido en
+// deterministicEpoch, en UTC.
+//
+// Los intérpretes integrados lo aplican directamente. Con los instalados se
+// agrega al directorio de trabajo un preludio que se carga antes del
+// programa sin cambiar su código ni sus números de línea:
+//   - Python: sitecustomize.py, que el intérprete importa al iniciar si está
+//     en PYTHONPATH; siembra random y reemplaza time.time y los now() y
+//     today() de datetime. PYTHONHASHSEED=0 fija además el orden de los sets.
+//   - JavaScript y TypeScript: un módulo que node carga con --require
+//     (NODE_OPTIONS); reemplaza Math.random y Date.
+//   - C++: un archivo que se compila con el programa y reemplaza time() y
+//     srand() en el enlace (-Wl,--wrap); rand() queda sembrado aunque el
+//     programa llame a srand(time(NULL)).
+//
+// En Go y Pascal la opción no tiene efecto.
+
+// Semilla del generador y hora de las ejecuciones deterministas
+const deterministicSeed = 42
+
+var deterministicEpoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// now es la hora que ve el programa
+func (in ProgramInput) now() time.Time {
+	if in.Deterministic {
+		return deterministicEpoch
+	}
+	return time.Now()
+}
+
+// seed es la semilla del generador de un intérprete integrado
+func (in ProgramInput) seed() int64 {
+	if in.Deterministic {
+		return deterministicSeed
+	}
+	return time.Now().UnixNano()
+}
+
+const deterministicPython = `import datetime as _datetime
+import random as _random
+import time as _time
+
+_EPOCH = 1704067200.0
+_random.seed(42)
+_time.time = lambda: _EPOCH
+_time.time_ns = lambda: int(_EPOCH) * 1000000000
+
+
+class _FrozenDatetime(_datetime.datetime):
+    @classmethod
+    def now(cls, tz=None):
+        return cls.fromtimestamp(_EPOCH, tz)
+
+    @classmethod
+    def today(cls):
+        return cls.fromtimestamp(_EPOCH)
+
+    @classmethod
+    def utcnow(cls):
+        return cls.fromtimestamp(_EPOCH, _datetime.timezone.utc).replace(tzinfo=None)
+
+
+class _FrozenDate(_datetime.date):
+    @classmethod
+    def today(cls):
+        return cls.fromtimestamp(_EPOCH)
+
+
+_datetime.datetime = _FrozenDatetime
+_datetime.date = _FrozenDate
+`
+
+// Math.random es mulberry32: node no permite sembrar el suyo
+const deterministicJS = `'use strict';
+const EPOCH = 1704067200000;
+let seed = 42;
+Math.random = function random() {
+  seed = (seed + 0x6d2b79f5) | 0;
+  let t = Math.imul(seed ^ (seed >>> 15), 1 | seed);
+  t = (t + Math.imul(t ^ (t >>> 7), 61 | t)) ^ t;
+  return ((t ^ (t >>> 14)) >>> 0) / 4294967296;
+};
+const RealDate = Date;
+function FrozenDate(...args) {
+  if (!new.target) return new RealDate(EPOCH).toString();
+  return args.length === 0 ? new RealDate(EPOCH) : new RealDate(...args);
+}
+FrozenDate.prototype = RealDate.prototype;
+FrozenDate.now = () => EPOCH;
+FrozenDate.parse = RealDate.parse;
+FrozenDate.UTC = RealDate.UTC;
+globalThis.Date = FrozenDate;
+`
+
+const deterministicCPP = `#include <cstdlib>
+#include <ctime>
+
+extern "C" {
+void __real_srand(unsigned seed);
+
+time_t __wrap_time(time_t* t) {
+    if (t) *t = 1704067200;
+    return 1704067200;
+}
+
+void __wrap_srand(unsigned) { __real_srand(42); }
+}
+
+__attribute__((constructor)) static void deterministic_seed() { __real_srand(42); }
+`
+
+// Archivos de los preludios, reservados en files
+const (
+	deterministicPythonFile = "sitecustomize.py"
+	deterministicJSFile     = "deterministic.js"
+	deterministicCPPFile    = "deterministic.cpp"
+)
+
+// Opciones de g++ que enlazan el preludio de C++, además del archivo
+var deterministicCXXFlags = []string{"-Wl,--wrap=time", "-Wl,--wrap=srand"}
+
+// withPrelude devuelve in con el preludio determinista de language en sus
+// archivos y variables de entorno; sin Deterministic devuelve in tal cual
+func (in ProgramInput) withPrelude(language string) ProgramInput {
+	if !in.Deterministic {
+		return in
+	}
+	env := map[string]string{"TZ": "UTC"}
+	var file ProgramFile
+	switch language {
+	case "python":
+		file = ProgramFile{deterministicPythonFile, deterministicPython}
+		env["PYTHONPATH"] = "."
+		env["PYTHONHASHSEED"] = "0"
+	case "javascript", "typescript":
+		file = ProgramFile{deterministicJSFile, deterministicJS}
+		env["NODE_OPTIONS"] = "--require ./" + deterministicJSFile
+	case "cpp":
+		file = ProgramFile{deterministicCPPFile, deterministicCPP}
+		// Para el comando de Docker, que compila con $DETERMINISTIC_CXXFLAGS
+		env["DETERMINISTIC_CXXFLAGS"] = "/code/" + deterministicCPPFile + " " + strings.Join(deterministicCXXFlags, " ")
+	default:
+		return in
+	}
+	for name, value := range in.Env {
+		env[name] = value
+	}
+	in.Env = env
+	in.Files = append(in.Files[:len(in.Files):len(in.Files)], file)
+	return in
+}
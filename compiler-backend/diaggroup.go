@@ -0,0 +1,51 @@
+package main
+
+// ─────────────────────── Diagnósticos agrupados ──────────────────────────
+//
+// Una variable sin declarar que se usa 30 veces da 30 errores iguales salvo
+// la posición, y el estudiante tiene que recorrerlos todos para ver que son
+// el mismo problema. Con groupDiagnostics (o GROUP_DIAGNOSTICS) los
+// diagnósticos idénticos (mismo tipo, código, severidad, origen y mensaje)
+// se reducen al primero, que lleva en relatedPositions dónde más aparece:
+//
+//	{ "message": "Error semántico: Variable 'total' no fue declarada",
+//	  "line": 4, "column": 9, "code": "SEM004",
+//	  "relatedPositions": [ { "line": 7, "column": 5, "position": 88 }, ... ] }
+//
+// El agrupamiento se hace sobre la respuesta, después del análisis: la
+// caché, executability y los conteos de las fases ven todos los
+// diagnósticos.
+
+// groupsDiagnostics indica si la respuesta a req agrupa los diagnósticos:
+// lo que pide la petición o, si no lo indica, GROUP_DIAGNOSTICS
+func (req AnalyzeRequest) groupsDiagnostics() bool {
+	if req.GroupDiagnostics != nil {
+		return *req.GroupDiagnostics
+	}
+	return currentConfig().GroupDiagnostics
+}
+
+// diagnosticKey es lo que tienen que compartir dos diagnósticos para
+// agruparse
+type diagnosticKey struct {
+	typ, code, severity, source, message, compilerMessage string
+}
+
+// groupDiagnostics reduce cada conjunto de diagnósticos idénticos al
+// primero, con las posiciones de los demás en relatedPositions; conserva
+// el orden de errors
+func groupDiagnostics(errors []APICompilerError) []APICompilerError {
+	grouped := make([]APICompilerError, 0, len(errors))
+	first := map[diagnosticKey]int{}
+	for _, e := range errors {
+		key := diagnosticKey{e.Type, e.Code, e.Severity, e.Source, e.Message, e.CompilerMessage}
+		if i, ok := first[key]; ok {
+			grouped[i].RelatedPositions = append(grouped[i].RelatedPositions,
+				APIPosition{Line: e.Line, Column: e.Column, Position: e.Position})
+			continue
+		}
+		first[key] = len(grouped)
+		grouped = append(grouped, e)
+	}
+	return grouped
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ───────────────────────── Diagnósticos habilitados ──────────────────────
+//
+// Algunos diagnósticos molestan más de lo que ayudan en ciertos lenguajes o
+// cursos (la advertencia de variable sin usar en los bucles de Python, las
+// palabras reservadas en un ejercicio de SQL). DISABLED_DIAGNOSTICS los
+// desactiva para todo el servidor, por lenguaje, y cada petición puede
+// sobrescribirlo con diagnostics: {"unused-variable": false}. Un diagnóstico
+// se nombra por su código (SEM002) o por su nombre del catálogo
+// (unused-variable). Los desactivados se quitan de la respuesta en la fase
+// que los produce, así que tampoco cuentan en ErrorsFound ni en Executability.
+//
+// Cada petición también puede cambiar severidades con severityOverrides
+// ({"warning": "error"} para las entregas calificadas) y acotar con maxErrors
+// la cantidad de diagnósticos: al superarla el análisis se detiene y el
+// resto se resume en un único LIM001. MAX_ERRORS acota ese valor para que
+// una entrada patológica no genere miles de diagnósticos.
+
+// DiagnosticsConfig indica por lenguaje qué códigos están activados; "*"
+// aplica a todos los lenguajes y el lenguaje concreto tiene prioridad
+type DiagnosticsConfig map[string]map[string]bool
+
+// diagnosticCode devuelve el código de un diagnóstico nombrado por su
+// código o por su nombre del catálogo
+func diagnosticCode(name string) (string, bool) {
+	if _, ok := errorCatalog[strings.ToUpper(name)]; ok {
+		return strings.ToUpper(name), true
+	}
+	for code, info := range errorCatalog {
+		if info.Name == strings.ToLower(name) {
+			return code, true
+		}
+	}
+	return "", false
+}
+
+// parseDisabledDiagnostics lee DISABLED_DIAGNOSTICS: una lista separada por
+// comas de lenguaje:diagnóstico ("python:unused-variable,*:SEM003"). Las
+// entradas que no se entienden se ignoran, como el resto de las variables
+// de entorno
+func parseDisabledDiagnostics(v string) DiagnosticsConfig {
+	config := DiagnosticsConfig{}
+	for _, entry := range strings.Split(v, ",") {
+		lang, name, found := strings.Cut(strings.TrimSpace(entry), ":")
+		code, ok := diagnosticCode(name)
+		if !found || !ok {
+			continue
+		}
+		if lang = mapLanguage(lang); lang == "" {
+			lang = "*"
+		}
+		if config[lang] == nil {
+			config[lang] = map[string]bool{}
+		}
+		config[lang][code] = false
+	}
+	return config
+}
+
+// unknownDiagnostic devuelve el primer nombre de overrides que no es un
+// diagnóstico conocido, o "" si todos lo son
+func unknownDiagnostic(overrides map[string]bool) string {
+	for name := range overrides {
+		if _, ok := diagnosticCode(name); !ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// diagnosticOverrides traduce los nombres de una petición a códigos
+func diagnosticOverrides(overrides map[string]bool) map[string]bool {
+	if len(overrides) == 0 {
+		return nil
+	}
+	codes := map[string]bool{}
+	for name, enabled := range overrides {
+		if code, ok := diagnosticCode(name); ok {
+			codes[code] = enabled
+		}
+	}
+	return codes
+}
+
+// diagnosticEnabled decide si se reporta code: primero la petición, luego
+// la configuración del lenguaje y por último la de todos los lenguajes
+func diagnosticEnabled(language, code string, overrides map[string]bool) bool {
+	if enabled, ok := overrides[code]; ok {
+		return enabled
+	}
+	if enabled, ok := GlobalConfig.Diagnostics[language][code]; ok {
+		return enabled
+	}
+	if enabled, ok := GlobalConfig.Diagnostics["*"][code]; ok {
+		return enabled
+	}
+	return true
+}
+
+// filterDiagnostics quita de errs los diagnósticos desactivados
+func filterDiagnostics(errs []CompilerError, language string, overrides map[string]bool) []CompilerError {
+	var kept []CompilerError
+	for _, e := range errs {
+		if diagnosticEnabled(language, e.Code, overrides) {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+// diagnosticsKey representa overrides en la clave de la caché
+func diagnosticsKey(overrides map[string]bool) string {
+	parts := make([]string, 0, len(overrides))
+	for code, enabled := range overrides {
+		parts = append(parts, code+"="+strconv.FormatBool(enabled))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+// Severidades que puede pedir severityOverrides
+var validSeverities = map[string]bool{"error": true, "warning": true}
+
+// invalidSeverityOverride devuelve el motivo por el que overrides no es
+// válido, o "" si lo es. Las claves son diagnósticos o una severidad
+// ("warning": "error" convierte todas las advertencias en errores)
+func invalidSeverityOverride(overrides map[string]string) string {
+	for name, severity := range overrides {
+		if _, ok := diagnosticCode(name); !ok && !validSeverities[name] {
+			return "severityOverrides: unknown diagnostic " + name
+		}
+		if !validSeverities[severity] {
+			return "severityOverrides: severity must be error or warning"
+		}
+	}
+	return ""
+}
+
+// severityOverrides traduce los nombres de una petición a códigos; las
+// claves que son una severidad se conservan
+func severityOverrides(overrides map[string]string) map[string]string {
+	if len(overrides) == 0 {
+		return nil
+	}
+	codes := map[string]string{}
+	for name, severity := range overrides {
+		if validSeverities[name] {
+			codes[name] = severity
+		} else if code, ok := diagnosticCode(name); ok {
+			codes[code] = severity
+		}
+	}
+	return codes
+}
+
+// remapSeverities aplica overrides a errs; la regla del código tiene
+// prioridad sobre la de su severidad
+func remapSeverities(errs []CompilerError, overrides map[string]string) []CompilerError {
+	for i, e := range errs {
+		if severity, ok := overrides[e.Code]; ok {
+			errs[i].Severity = severity
+		} else if severity, ok := overrides[e.Severity]; ok {
+			errs[i].Severity = severity
+		}
+	}
+	return errs
+}
+
+// errorBudget es el máximo de diagnósticos de un análisis: el que pide la
+// petición, acotado por GlobalConfig.MaxErrors (0 = sin límite)
+func errorBudget(requested int) int {
+	max := currentConfig().MaxErrors
+	if requested > 0 && (max <= 0 || requested < max) {
+		return requested
+	}
+	return max
+}
+
+// tooManyErrors es el resumen que reemplaza a los diagnósticos que exceden
+// el presupuesto; dropped es el primero que no se reporta
+func tooManyErrors(max int, dropped CompilerError) CompilerError {
+	return CompilerError{
+		Message:  fmt.Sprintf("Demasiados errores: se muestran los primeros %d y se detuvo el análisis", max),
+		Severity: "error",
+		Type:     dropped.Type,
+		Pos:      dropped.Pos,
+		Code:     CodeTooManyErrors,
+	}
+}
+
+// severitiesKey representa overrides en la clave de la caché
+func severitiesKey(overrides map[string]string) string {
+	parts := make([]string, 0, len(overrides))
+	for name, severity := range overrides {
+		parts = append(parts, name+"="+severity)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+// ─────────────────── Reconciliación con el compilador real ───────────────
+//
+// Con ejecución real el compilador suele informar los mismos problemas que
+// el análisis estático con otras palabras (SEM004 'y' no fue declarada y
+// "undefined: y"). Un error del compilador en la misma línea y de la misma
+// categoría que uno del análisis se une a él: queda el del análisis, con su
+// código, posición y sugerencia, el mensaje del compilador en
+// CompilerMessage y la severidad más estricta de los dos. Source indica de
+// dónde salió cada diagnóstico.
+
+// reconcileDiagnostics une los errores external del compilador con los
+// static del análisis y devuelve la lista resultante junto con los del
+// compilador que no coincidieron con ninguno. Cada error del análisis se
+// une a lo sumo con uno del compilador
+func reconcileDiagnostics(code string, static, external []CompilerError) ([]CompilerError, []CompilerError) {
+	lineStarts := computeLineStarts(code)
+	lineOf := func(pos int) int {
+		return sort.Search(len(lineStarts), func(i int) bool { return lineStarts[i] > pos })
+	}
+	merged := append([]CompilerError(nil), static...)
+	var added []CompilerError
+	for _, ext := range external {
+		ext.Source = "compilador"
+		match := -1
+		for i, e := range merged[:len(static)] {
+			if e.Source != "ambos" && e.Code != CodeTooManyErrors && e.Type == ext.Type && lineOf(e.Pos) == ext.Line {
+				match = i
+				break
+			}
+		}
+		if match < 0 {
+			added = append(added, ext)
+			continue
+		}
+		e := &merged[match]
+		e.Source, e.CompilerMessage = "ambos", ext.Message
+		if ext.Severity == "error" {
+			e.Severity = "error"
+		}
+	}
+	return append(merged, added...), added
+}
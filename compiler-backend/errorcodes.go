@@ -0,0 +1,156 @@
+package main
+
+// ─────────────────────────── Códigos de error ────────────────────────────
+//
+// Cada CompilerError lleva un código estable (LEX001, SYN002, SEM004...) que
+// no depende del texto del mensaje, para que el frontend pueda enlazar cada
+// diagnóstico con su documentación. El prefijo indica la fase: LEX léxica,
+// SYN sintáctica, SEM semántica, EXT errores del compilador o intérprete
+// real durante la ejecución, SEC la política de seguridad del servidor (ver
+// policy.go), LNT errores frecuentes de principiantes (ver lint.go), ASG
+// requisitos del perfil de la tarea (ver assignments.go) y LIM límites del
+// propio análisis.
+
+const (
+	CodeUnterminatedString   = "LEX001"
+	CodeInvalidCharacter     = "LEX002"
+	CodeMalformedNumber      = "LEX003"
+	CodeUnterminatedComment  = "LEX004"
+	CodeMixedIndentation     = "LEX005"
+	CodeInvalidEscape        = "LEX006"
+	CodeInvalidCharLiteral   = "LEX007"
+	CodeInvalidInterpolation = "LEX008"
+
+	CodeUnexpectedToken      = "SYN001"
+	CodeUnmatchedClosing     = "SYN002"
+	CodeUnclosedDelimiter    = "SYN003"
+	CodeDuplicateSemicolon   = "SYN004"
+	CodeUnexpectedIndent     = "SYN005"
+	CodeEmptyProgram         = "SYN006"
+	CodeUnclosedTag          = "SYN007"
+	CodeUnexpectedClosingTag = "SYN008"
+
+	CodeRedeclaredVariable  = "SEM001"
+	CodeUnusedVariable      = "SEM002"
+	CodeReservedIdentifier  = "SEM003"
+	CodeUndeclaredVariable  = "SEM004"
+	CodeTypeMismatch        = "SEM005"
+	CodeInvalidOperands     = "SEM006"
+	CodeArgumentCount       = "SEM007"
+	CodeUnknownMember       = "SEM008"
+	CodeNarrowingConversion = "SEM009"
+	CodeUnreachableCode     = "SEM010"
+	CodeMissingReturn       = "SEM011"
+	CodeInfiniteLoop        = "SEM012"
+	CodeDivisionByZero      = "SEM013"
+	CodeIntegerOverflow     = "SEM014"
+	CodeUnknownProperty     = "SEM015"
+	CodeDuplicateProperty   = "SEM016"
+	CodeEmptyRule           = "SEM017"
+	CodeMissingAttribute    = "SEM018"
+	CodeDeprecatedElement   = "SEM019"
+	CodeUnknownTable        = "SEM020"
+	CodeUnknownColumn       = "SEM021"
+	CodeUndeclaredFunction  = "SEM022"
+	CodeNotCallable         = "SEM023"
+	CodeUnknownModule       = "SEM024"
+
+	CodeAssignmentInCondition = "LNT001"
+	CodeIdentityComparison    = "LNT002"
+	CodeSwitchFallthrough     = "LNT003"
+	CodeCStringComparison     = "LNT004"
+
+	CodeLanguageNotAllowed = "ASG001"
+	CodeBannedFunction     = "ASG002"
+	CodeMissingFunction    = "ASG003"
+
+	CodeCompilerError = "EXT001"
+	CodeRuntimeError  = "EXT002"
+
+	CodeShellCommand  = "SEC001"
+	CodeNetworkAccess = "SEC002"
+	CodeFileWrite     = "SEC003"
+	CodeBusyLoop      = "SEC004"
+
+	CodeTooManyErrors = "LIM001"
+)
+
+// ErrorCodeInfo describe un código: Name es su identificador legible y Hint
+// la corrección sugerida por defecto, ambos en kebab-case y estables
+type ErrorCodeInfo struct {
+	Name string
+	Hint string
+}
+
+var errorCatalog = map[string]ErrorCodeInfo{
+	CodeUnterminatedString:   {"unterminated-string", "close-string"},
+	CodeInvalidCharacter:     {"invalid-character", "remove-character"},
+	CodeMalformedNumber:      {"malformed-number", "fix-number-literal"},
+	CodeUnterminatedComment:  {"unterminated-comment", "close-comment"},
+	CodeMixedIndentation:     {"mixed-indentation", "use-consistent-indentation"},
+	CodeInvalidEscape:        {"invalid-escape", "fix-escape-sequence"},
+	CodeInvalidCharLiteral:   {"invalid-char-literal", "use-string-literal"},
+	CodeInvalidInterpolation: {"invalid-interpolation", "fix-interpolation"},
+
+	CodeUnexpectedToken:      {"unexpected-token", "check-syntax"},
+	CodeUnmatchedClosing:     {"unmatched-closing-delimiter", "remove-delimiter"},
+	CodeUnclosedDelimiter:    {"unclosed-delimiter", "close-delimiter"},
+	CodeDuplicateSemicolon:   {"duplicate-semicolon", "remove-semicolon"},
+	CodeUnexpectedIndent:     {"unexpected-indent", "fix-indentation"},
+	CodeEmptyProgram:         {"empty-program", "add-code"},
+	CodeUnclosedTag:          {"unclosed-tag", "close-tag"},
+	CodeUnexpectedClosingTag: {"unexpected-closing-tag", "remove-closing-tag"},
+
+	CodeRedeclaredVariable:  {"redeclared-variable", "rename-declaration"},
+	CodeUnusedVariable:      {"unused-variable", "remove-declaration"},
+	CodeReservedIdentifier:  {"reserved-identifier", "rename-identifier"},
+	CodeUndeclaredVariable:  {"undeclared-variable", "declare-variable"},
+	CodeTypeMismatch:        {"type-mismatch", "convert-value"},
+	CodeInvalidOperands:     {"invalid-operands", "convert-operands"},
+	CodeArgumentCount:       {"argument-count", "fix-arguments"},
+	CodeUnknownMember:       {"unknown-member", "check-member-name"},
+	CodeNarrowingConversion: {"narrowing-conversion", "add-explicit-cast"},
+	CodeUnreachableCode:     {"unreachable-code", "remove-unreachable-code"},
+	CodeMissingReturn:       {"missing-return", "add-return"},
+	CodeInfiniteLoop:        {"infinite-loop", "add-loop-exit"},
+	CodeDivisionByZero:      {"division-by-zero", "check-divisor"},
+	CodeIntegerOverflow:     {"integer-overflow", "use-wider-type"},
+	CodeUnknownProperty:     {"unknown-property", "check-property-name"},
+	CodeDuplicateProperty:   {"duplicate-property", "remove-declaration"},
+	CodeEmptyRule:           {"empty-rule", "remove-rule"},
+	CodeMissingAttribute:    {"missing-attribute", "add-attribute"},
+	CodeDeprecatedElement:   {"deprecated-element", "use-modern-element"},
+	CodeUnknownTable:        {"unknown-table", "check-table-name"},
+	CodeUnknownColumn:       {"unknown-column", "check-column-name"},
+	CodeUndeclaredFunction:  {"undeclared-function", "declare-function"},
+	CodeNotCallable:         {"not-callable", "check-callee"},
+	CodeUnknownModule:       {"unknown-module", "check-module-name"},
+
+	CodeAssignmentInCondition: {"assignment-in-condition", "use-equality-operator"},
+	CodeIdentityComparison:    {"identity-comparison", "fix-comparison-operator"},
+	CodeSwitchFallthrough:     {"switch-fallthrough", "add-break"},
+	CodeCStringComparison:     {"c-string-comparison", "use-strcmp"},
+
+	CodeLanguageNotAllowed: {"language-not-allowed", "use-assignment-language"},
+	CodeBannedFunction:     {"banned-function", "remove-banned-call"},
+	CodeMissingFunction:    {"missing-function", "define-required-function"},
+
+	CodeCompilerError: {"compiler-error", "see-compiler-output"},
+	CodeRuntimeError:  {"runtime-error", "see-program-output"},
+
+	CodeShellCommand:  {"shell-command", "remove-shell-command"},
+	CodeNetworkAccess: {"network-access", "remove-network-access"},
+	CodeFileWrite:     {"file-write", "write-inside-workdir"},
+	CodeBusyLoop:      {"busy-loop", "add-sleep"},
+
+	CodeTooManyErrors: {"too-many-errors", "fix-reported-errors"},
+}
+
+// errorHint devuelve la sugerencia del error: la específica si el analizador
+// indicó una (p. ej. "insert:;") o la del catálogo para su código
+func errorHint(err CompilerError) string {
+	if err.Hint != "" {
+		return err.Hint
+	}
+	return errorCatalog[err.Code].Hint
+}
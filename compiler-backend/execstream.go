@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"unicode/utf8"
+)
+
+// ───────────────────── Salida en vivo de la ejecución ─────────────────────
+//
+// POST /api/v1/execute/stream recibe lo mismo que /api/v1/analyze, ejecuta
+// el programa y responde con Server-Sent Events: cada fragmento que el
+// programa escribe en stdout o stderr se envía en cuanto se produce, así un
+// ciclo largo muestra su salida de a poco como en una terminal. La salida de
+// la compilación no se transmite; llega con el resultado. Al terminar se
+// envía un evento result con la misma respuesta de /api/v1/analyze.
+//
+//	event: stdout
+//	data: {"chunk":"1\n"}
+//
+//	event: result
+//	data: {"language":"python",...}
+//
+// Si el cliente cierra la conexión la ejecución se detiene.
+
+// APIOutputChunk es el cuerpo de los eventos stdout y stderr
+type APIOutputChunk struct {
+	Chunk string `json:"chunk"`
+}
+
+// sseWriter escribe eventos en la respuesta; los fragmentos llegan desde
+// las gorutinas que copian la salida, así que escribir se serializa
+type sseWriter struct {
+	mu     sync.Mutex
+	w      http.ResponseWriter
+	rc     *http.ResponseController
+	closed bool
+	// Bytes del final de un fragmento que cortaron un carácter UTF-8, por
+	// flujo; se envían con el fragmento siguiente
+	partial map[string][]byte
+}
+
+// send escribe el evento y lo envía de inmediato
+func (s *sseWriter) send(event string, v any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sendLocked(event, v)
+}
+
+// sendLocked es send con s.mu tomado; después de close no escribe nada, por
+// si el programa dejó salida pendiente
+func (s *sseWriter) sendLocked(event string, v any) {
+	data, err := json.Marshal(v)
+	if s.closed || err != nil {
+		return
+	}
+	fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, data)
+	s.rc.Flush()
+}
+
+// output envía un fragmento de stream sin partir caracteres: JSON
+// reemplazaría las dos mitades por U+FFFD
+func (s *sseWriter) output(stream string, chunk []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	buf := append(s.partial[stream], chunk...)
+	cut := completeUTF8(buf)
+	s.partial[stream] = append([]byte(nil), buf[cut:]...)
+	if cut > 0 {
+		s.sendLocked(stream, APIOutputChunk{Chunk: string(buf[:cut])})
+	}
+}
+
+// completeUTF8 devuelve cuántos bytes del principio de buf no terminan en
+// un carácter UTF-8 a medias; el resto llega con el fragmento siguiente
+func completeUTF8(buf []byte) int {
+	for i := 1; i < utf8.UTFMax && i <= len(buf); i++ {
+		if utf8.RuneStart(buf[len(buf)-i]) {
+			if !utf8.FullRune(buf[len(buf)-i:]) {
+				return len(buf) - i
+			}
+			break
+		}
+	}
+	return len(buf)
+}
+
+func (s *sseWriter) close() {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+}
+
+// executeStreamHandler atiende POST /api/v1/execute/stream
+func executeStreamHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req AnalyzeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w)
+		return
+	}
+	id, msg := requestID(r)
+	if msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if executions.active(id) {
+		httpError(w, "An execution with this request id is already running", http.StatusConflict)
+		return
+	}
+	w.Header().Set(requestIDHeader, id)
+
+	if req.Code == "" {
+		httpError(w, "Code is required", http.StatusBadRequest)
+		return
+	}
+	if msg := codeTooLarge(req.Code); msg != "" {
+		writeAPIError(w, http.StatusRequestEntityTooLarge, "code_too_large", msg)
+		return
+	}
+	if req.Execute != nil && !*req.Execute {
+		httpError(w, "execute cannot be false on /execute/stream", http.StatusBadRequest)
+		return
+	}
+	if req.TimeoutSeconds < 0 {
+		httpError(w, "timeoutSeconds must be positive", http.StatusBadRequest)
+		return
+	}
+	if name := unknownDiagnostic(req.Diagnostics); name != "" {
+		httpError(w, "diagnostics: unknown diagnostic "+name, http.StatusBadRequest)
+		return
+	}
+	if msg := invalidSeverityOverride(req.SeverityOverrides); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if req.MaxErrors < 0 {
+		httpError(w, "maxErrors must be positive", http.StatusBadRequest)
+		return
+	}
+	if msg := invalidArgs(req.Args); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if msg := invalidEnv(req.Env); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if msg := invalidFiles(req.Files); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if msg := req.invalidJudgeRequest(principalFrom(r)); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if msg := invalidLocale(req.Locale); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	locale := requestLocale(req.Locale, r)
+	principal := principalFrom(r)
+	if status, msg := req.authorize(principal); status != 0 {
+		rejectAnalysis(w, status, msg)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// nginx no debe acumular la respuesta
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	events := &sseWriter{w: w, rc: http.NewResponseController(w), partial: map[string][]byte{}}
+	events.rc.Flush()
+	defer events.close()
+	stop := context.AfterFunc(r.Context(), func() { executions.stop(id) })
+	defer stop()
+
+	language := mapLanguage(req.Language)
+	opts := req.options(principal)
+	opts.RequestID = id
+	opts.Output = events.output
+	result := AnalyzeCodeWithProgress(req.Code, language, opts, nil)
+	recordAnalysis(req.Code, result, principal)
+	events.send("result", buildAPIResponse(result, newSourceIndex(req.Code), locale))
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ─────────────────────────── ¿Se puede ejecutar? ─────────────────────────
+//
+// Executability dice si el programa se puede ejecutar y, si no, por qué, para
+// que la interfaz muestre un mensaje accionable en lugar de un botón gris.
+// Hay dos clases de motivos:
+//
+//   - del código: el primer error de cada fase, con su línea, y las reglas de
+//     la política de seguridad que bloquean (ver policy.go). El pipeline igual
+//     ejecuta el código con errores para capturar los del compilador real.
+//   - del servidor: el lenguaje desactivado por la configuración, la
+//     herramienta que falta o un lenguaje sin ejecutor (ver toolchains.go).
+//     Estos sí omiten la ejecución.
+//
+// La ejecución simulada no es un motivo: el programa "se ejecuta" con el
+// ejecutor simulado. Tampoco la cuota de un usuario, porque el resultado se
+// comparte en la caché entre usuarios (ver auth.go).
+//
+// No todo error del análisis estático impide ejecutar: la compuerta
+// (ExecutionGate) indica, por lenguaje y fase, cuántos errores hacen falta
+// para que el programa no se pueda ejecutar. Por defecto basta uno léxico o
+// sintáctico, y los semánticos no cuentan: el análisis semántico no conoce
+// las bibliotecas de cada lenguaje y el compilador real tiene la última
+// palabra. Los errores del compilador real y los de la política de seguridad
+// siempre impiden ejecutar.
+
+type Executability struct {
+	Allowed bool
+	Reasons []string
+	// Un motivo depende del estado del servidor (herramientas instaladas):
+	// el resultado no se guarda en la caché
+	Transient bool
+}
+
+// Motivo de cada fase cuando tiene errores; %d es la línea del primero
+var phaseErrorReasons = map[string]string{
+	"lexico":     "Error léxico en la línea %d",
+	"sintactico": "Error sintáctico en la línea %d",
+	"semantico":  "Error semántico en la línea %d",
+}
+
+// ExecutionGateConfig indica, por lenguaje ("*" para todos) y tipo de error
+// (lexico, sintactico, semantico), cuántos errores del análisis estático
+// impiden ejecutar; 0 o un tipo ausente no lo impiden
+type ExecutionGateConfig map[string]map[string]int
+
+// defaultExecutionGate solo deja fuera el código que no se puede leer
+func defaultExecutionGate() ExecutionGateConfig {
+	return ExecutionGateConfig{"*": {"lexico": 1, "sintactico": 1}}
+}
+
+// Nombres de fase que acepta EXECUTION_GATE además de los tipos de error
+var gatePhaseNames = map[string]string{
+	"lexical":  "lexico",
+	"syntax":   "sintactico",
+	"semantic": "semantico",
+}
+
+// parseExecutionGate lee EXECUTION_GATE: una lista de [lenguaje:]fase=n
+// ("semantico=1,python:sintactico=2") que se aplica sobre la compuerta por
+// defecto; las entradas mal escritas se ignoran
+func parseExecutionGate(v string) ExecutionGateConfig {
+	gate := defaultExecutionGate()
+	for _, entry := range strings.Split(v, ",") {
+		rule, value, found := strings.Cut(strings.TrimSpace(entry), "=")
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if !found || err != nil || n < 0 {
+			continue
+		}
+		lang, phase, scoped := strings.Cut(rule, ":")
+		if !scoped {
+			lang, phase = "*", rule
+		} else if lang = mapLanguage(lang); lang == "" {
+			lang = "*"
+		}
+		phase = strings.ToLower(strings.TrimSpace(phase))
+		if name, ok := gatePhaseNames[phase]; ok {
+			phase = name
+		}
+		if _, ok := phaseErrorReasons[phase]; !ok {
+			continue
+		}
+		if gate[lang] == nil {
+			gate[lang] = map[string]int{}
+		}
+		gate[lang][phase] = n
+	}
+	return gate
+}
+
+// threshold devuelve cuántos errores de tipo phase impiden ejecutar
+// language; la entrada del lenguaje tiene prioridad sobre la de "*"
+func (g ExecutionGateConfig) threshold(language, phase string) int {
+	if n, ok := g[language][phase]; ok {
+		return n
+	}
+	return g["*"][phase]
+}
+
+// codeExecutability resume los errores de errs según la compuerta gate de
+// language: el primero de cada fase que alcanza su límite, el primero de
+// cada regla de la política de seguridad que bloquea y el primero que
+// informó el compilador real
+func codeExecutability(code, language string, errs []CompilerError, gate ExecutionGateConfig) Executability {
+	ex := Executability{Allowed: true}
+	lineStarts := computeLineStarts(code)
+	lineOf := func(e CompilerError) int {
+		return sort.Search(len(lineStarts), func(i int) bool { return lineStarts[i] > e.Pos })
+	}
+
+	counts := map[string]int{}
+	for _, e := range errs {
+		if e.Severity == "error" && e.Source == "" && !strings.HasPrefix(e.Code, "SEC") {
+			counts[e.Type]++
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, e := range errs {
+		if e.Severity != "error" {
+			continue
+		}
+		kind, reason := e.Type, ""
+		if strings.HasPrefix(e.Code, "SEC") {
+			kind, reason = e.Code, fmt.Sprintf("La política de seguridad bloquea la línea %d (%s)", lineOf(e), e.Code)
+		} else {
+			// Los del compilador real (Source no vacío) no pasan por la compuerta
+			if n := gate.threshold(language, e.Type); e.Source == "" && (n == 0 || counts[e.Type] < n) {
+				continue
+			}
+			format, ok := phaseErrorReasons[e.Type]
+			if !ok {
+				format = "Error en la línea %d"
+			}
+			reason = fmt.Sprintf(format, lineOf(e))
+		}
+		ex.Allowed = false
+		if !seen[kind] {
+			seen[kind] = true
+			ex.Reasons = append(ex.Reasons, reason)
+		}
+	}
+	return ex
+}
+
+// deny agrega el motivo del servidor reason, si hay uno
+func (ex Executability) deny(reason string) Executability {
+	if reason != "" {
+		ex.Allowed, ex.Transient = false, true
+		ex.Reasons = append(ex.Reasons, reason)
+	}
+	return ex
+}
+
+// serverExecutionBlock devuelve por qué el servidor no ejecutaría language
+// con config, o "" si lo ejecutaría (aunque sea simulado)
+func serverExecutionBlock(language string, config CompilerConfig) string {
+	tools, _ := toolchains.snapshot(false)
+	tc := toolchainFor(language, config, tools)
+	if tc.Engine == EngineSimulated && config.languageAllowed(language) {
+		return ""
+	}
+	return tc.Reason
+}
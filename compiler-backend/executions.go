@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ─────────────────────────── Ejecuciones en curso ────────────────────────
+//
+// Mientras compila y ejecuta, cada petición queda registrada con su id.
+// GET /api/v1/executions las lista con el tiempo transcurrido y
+// DELETE /api/v1/executions/{id} detiene una antes de su límite: se cancela
+// su contexto y con él se termina el grupo de procesos (ver limits.go) o el
+// contenedor. El cliente elige el id con el encabezado X-Request-ID para
+// poder detenerla antes de recibir la respuesta; si no lo envía se genera
+// uno. En ambos casos vuelve en el mismo encabezado de la respuesta.
+//
+// Cada ejecución pertenece a la cuenta que la inició (la del espacio de
+// trabajo si la petición trae uno): solo esa cuenta la ve y la detiene,
+// salvo los administradores, que ven y detienen todas. Las anónimas no se
+// listan; como con las vistas previas, el id es lo que da acceso a ellas.
+
+const requestIDHeader = "X-Request-ID"
+
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+type runningExecution struct {
+	id       string
+	language string
+	// Cuenta que la inició (ver Principal.Account); "" si es anónima
+	account string
+	started time.Time
+	cancel  context.CancelFunc
+}
+
+type executionRegistry struct {
+	mu      sync.Mutex
+	running map[string]*runningExecution
+}
+
+var executions = &executionRegistry{running: make(map[string]*runningExecution)}
+
+// requestID devuelve el id de la petición: el de X-Request-ID o uno nuevo.
+// msg explica por qué el encabezado no es válido
+func requestID(r *http.Request) (id, msg string) {
+	id = r.Header.Get(requestIDHeader)
+	if id == "" {
+		return randomSuffix(), ""
+	}
+	if !requestIDPattern.MatchString(id) {
+		return "", requestIDHeader + " must be 1 to 64 letters, digits, '.', '_' or '-'"
+	}
+	return id, ""
+}
+
+// start registra la ejecución id y devuelve su contexto y la función que la
+// quita del registro. Sin id, o si ya hay otra con ese id, la ejecución no
+// se registra y no se puede detener. account es su dueña
+func (reg *executionRegistry) start(id, language, account string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	if id == "" {
+		return ctx, cancel
+	}
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	if _, exists := reg.running[id]; exists {
+		return ctx, cancel
+	}
+	e := &runningExecution{id: id, language: language, account: account, started: time.Now(), cancel: cancel}
+	reg.running[id] = e
+	return ctx, func() {
+		reg.mu.Lock()
+		if reg.running[id] == e {
+			delete(reg.running, id)
+		}
+		reg.mu.Unlock()
+		cancel()
+	}
+}
+
+// active indica si hay una ejecución en curso con ese id
+func (reg *executionRegistry) active(id string) bool {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	_, ok := reg.running[id]
+	return ok
+}
+
+// stop detiene la ejecución id; false si no hay ninguna en curso
+func (reg *executionRegistry) stop(id string) bool {
+	reg.mu.Lock()
+	e, ok := reg.running[id]
+	reg.mu.Unlock()
+	if ok {
+		e.cancel()
+	}
+	return ok
+}
+
+// stopFor detiene la ejecución id a pedido de p; false si no hay ninguna
+// en curso o es de otra cuenta y p no es administrador
+func (reg *executionRegistry) stopFor(id string, p *Principal) bool {
+	reg.mu.Lock()
+	e, ok := reg.running[id]
+	reg.mu.Unlock()
+	if !ok || !p.canSee(e) {
+		return false
+	}
+	e.cancel()
+	return true
+}
+
+// canSee indica si p puede ver y detener e: las de su cuenta o, si es
+// administrador, todas. nil es un cliente anónimo
+func (p *Principal) canSee(e *runningExecution) bool {
+	if p == nil {
+		return e.account == ""
+	}
+	return p.Admin || e.account == p.Account
+}
+
+// list devuelve las ejecuciones en curso que ve p, de la más antigua a la
+// más nueva. Las anónimas solo las ven los administradores
+func (reg *executionRegistry) list(p *Principal) []runningExecution {
+	reg.mu.Lock()
+	list := make([]runningExecution, 0, len(reg.running))
+	for _, e := range reg.running {
+		if p != nil && p.canSee(e) {
+			list = append(list, *e)
+		}
+	}
+	reg.mu.Unlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].started.Before(list[j].started) })
+	return list
+}
+
+// APIExecution es una ejecución en curso
+type APIExecution struct {
+	ID        string    `json:"id"`
+	Language  string    `json:"language"`
+	StartedAt time.Time `json:"startedAt"`
+	ElapsedMs int64     `json:"elapsedMs"`
+}
+
+type APIExecutionsResponse struct {
+	Executions []APIExecution `json:"executions"`
+}
+
+func executionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	now := time.Now()
+	response := APIExecutionsResponse{Executions: []APIExecution{}}
+	for _, e := range executions.list(principalFrom(r)) {
+		response.Executions = append(response.Executions, APIExecution{
+			ID:        e.id,
+			Language:  e.language,
+			StartedAt: e.started.UTC(),
+			ElapsedMs: now.Sub(e.started).Milliseconds(),
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func executionHandler(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, apiPrefix+"/executions/")
+	if id == "" || strings.Contains(id, "/") {
+		httpError(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodDelete {
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	// 404 también si es de otra cuenta: no revela qué ids están en curso
+	if !executions.stopFor(id, principalFrom(r)) {
+		httpError(w, "Execution not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
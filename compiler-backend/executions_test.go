@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// withPrincipal devuelve r con p como usuario autenticado; nil es anónimo
+func withPrincipal(r *http.Request, p *Principal) *http.Request {
+	if p == nil {
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), principalKey{}, p))
+}
+
+// TestStopExecution comprueba que una ejecución solo la detiene su cuenta o
+// un administrador, y que para las demás responde 404 como si no existiera
+func TestStopExecution(t *testing.T) {
+	ana := &Principal{Account: "key:ana", User: "ana"}
+	beto := &Principal{Account: "key:beto", User: "beto"}
+	admin := &Principal{Account: "key:admin", User: "admin", Admin: true}
+
+	cases := []struct {
+		name    string
+		account string
+		by      *Principal
+		status  int
+	}{
+		{"propia", "key:ana", ana, http.StatusNoContent},
+		{"otra_cuenta", "key:ana", beto, http.StatusNotFound},
+		{"anonimo", "key:ana", nil, http.StatusNotFound},
+		{"administrador", "key:ana", admin, http.StatusNoContent},
+		{"anonima_por_anonimo", "", nil, http.StatusNoContent},
+		{"anonima_por_usuario", "", ana, http.StatusNotFound},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			id := "rid-" + c.name
+			ctx, done := executions.start(id, "python", c.account)
+			defer done()
+
+			r := httptest.NewRequest(http.MethodDelete, apiPrefix+"/executions/"+id, nil)
+			w := httptest.NewRecorder()
+			executionHandler(w, withPrincipal(r, c.by))
+			if w.Code != c.status {
+				t.Fatalf("estado %d, esperado %d: %s", w.Code, c.status, w.Body.String())
+			}
+			if stopped := ctx.Err() != nil; stopped != (c.status == http.StatusNoContent) {
+				t.Errorf("detenida: %v con estado %d", stopped, w.Code)
+			}
+		})
+	}
+}
+
+// TestListExecutions comprueba que cada cuenta lista solo sus ejecuciones,
+// los administradores todas y los anónimos ninguna
+func TestListExecutions(t *testing.T) {
+	for _, e := range []struct{ id, account string }{{"rid-ana", "key:ana"}, {"rid-beto", "key:beto"}, {"rid-anonima", ""}} {
+		_, done := executions.start(e.id, "python", e.account)
+		defer done()
+	}
+
+	cases := []struct {
+		name string
+		by   *Principal
+		want []string
+	}{
+		{"ana", &Principal{Account: "key:ana"}, []string{"rid-ana"}},
+		{"beto", &Principal{Account: "key:beto"}, []string{"rid-beto"}},
+		{"administrador", &Principal{Account: "key:admin", Admin: true}, []string{"rid-ana", "rid-anonima", "rid-beto"}},
+		{"anonimo", nil, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			executionsHandler(w, withPrincipal(httptest.NewRequest(http.MethodGet, apiPrefix+"/executions", nil), c.by))
+			var response APIExecutionsResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("%v: %s", err, w.Body.String())
+			}
+			seen := map[string]bool{}
+			for _, e := range response.Executions {
+				seen[e.ID] = true
+			}
+			for _, id := range []string{"rid-ana", "rid-beto", "rid-anonima"} {
+				want := slices.Contains(c.want, id)
+				if seen[id] != want {
+					t.Errorf("%s listada: %v, esperado %v", id, seen[id], want)
+				}
+			}
+		})
+	}
+}
+
+// TestStopExecutionKillsProgram comprueba que detener una ejecución termina
+// el programa y los procesos que lanzó, sin esperar al límite de tiempo
+func TestStopExecutionKillsProgram(t *testing.T) {
+	mark := filepath.Join(t.TempDir(), "marca")
+	ctx, done := executions.start("rid-dormido", "python", "")
+	defer done()
+	time.AfterFunc(200*time.Millisecond, func() { executions.stop("rid-dormido") })
+
+	start := time.Now()
+	script := "(sleep 1; echo tarde > " + mark + ") &\nsleep 30\n"
+	result := runTemp(time.Minute, limitsFor("python"), ProgramInput{Context: ctx}, ".sh", script, "sh")
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("terminó en %v", elapsed)
+	}
+	if result.Ok || !strings.Contains(result.Output, strings.TrimSpace(cancelledMessage)) {
+		t.Errorf("salida %q, esperada la nota de detenida", result.Output)
+	}
+	time.Sleep(1500 * time.Millisecond)
+	if _, err := os.Stat(mark); err == nil {
+		t.Error("el proceso hijo siguió ejecutándose")
+	}
+}
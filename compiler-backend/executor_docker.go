@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ───────────────────── Ejecutor en contenedor Docker ─────────────────────
+//
+// Cada ejecución crea un contenedor desechable con límites de CPU, memoria
+// y procesos, sin red, con el sistema de archivos de solo lectura y
+// ejecutado como usuario sin privilegios. El código se monta en /code y
+// los binarios compilados se escriben en un tmpfs. Con archivos auxiliares
+// el programa se ejecuta en /code para poder leerlos, pero no escribirlos.
+//
+// La compilación y el programa corren en el mismo contenedor; al terminar
+// de compilar el comando escribe dockerCompiledMarker en stderr para poder
+// separar las dos salidas. DurationMs incluye la creación del contenedor.
+// Con casos de prueba cada uno corre en su propio contenedor, así que la
+// compilación se repite en cada caso.
+
+// La creación del contenedor añade latencia, por eso se suma este margen al
+// límite de ejecución.
+const dockerStartupGrace = 6 * time.Second
+
+type DockerExecutor struct {
+	language string
+	timeout  time.Duration
+	input    ProgramInput
+}
+
+func NewDockerExecutor(lang string, timeout time.Duration, input ProgramInput) *DockerExecutor {
+	return &DockerExecutor{language: lang, timeout: timeout, input: input}
+}
+
+// dockerCompiledMarker separa en stderr la salida de la compilación de la
+// del programa
+const dockerCompiledMarker = "__compilador_compilado__"
+
+// dockerCompiled va entre el paso de compilación y el de ejecución
+const dockerCompiled = " && echo " + dockerCompiledMarker + " >&2 && "
+
+// dockerCommands: archivo fuente y comando ejecutado dentro del contenedor.
+// Los argumentos del programa se agregan al final del comando; los que
+// pasan por sh -c los reciben en "$@"
+var dockerCommands = map[string]struct {
+	file    string
+	command []string
+}{
+	"cpp":        {"main.cpp", []string{"sh", "-c", "g++ -std=c++17 /code/main.cpp $DETERMINISTIC_CXXFLAGS -o /tmp/prog" + dockerCompiled + "/tmp/prog \"$@\"", "sh"}},
+	"python":     {"main.py", []string{"python3", "/code/main.py"}},
+	"javascript": {"main.js", []string{"node", "/code/main.js"}},
+	// tsc --noEmit primero: sus errores de tipos detienen la ejecución
+	"typescript": {"main.ts", []string{"sh", "-c", "cd /code && " +
+		"tsc --pretty false --target es2020 --module commonjs --skipLibCheck --noEmit main.ts && " +
+		"tsc --pretty false --target es2020 --module commonjs --skipLibCheck --sourceMap --outDir /tmp/out main.ts" + dockerCompiled +
+		"node --enable-source-maps /tmp/out/main.js \"$@\"", "sh"}},
+	// La caché de compilación de Go debe quedar en el tmpfs escribible
+	"go": {"main.go", []string{"sh", "-c", "cd /code && GOCACHE=/tmp/gocache HOME=/tmp go build -o /tmp/prog main.go" + dockerCompiled + "/tmp/prog \"$@\"", "sh"}},
+	// fpc deja los .o junto al fuente salvo con -FU: /code es de solo lectura
+	"pascal": {"main.pas", []string{"sh", "-c", "cd /code && fpc " + strings.Join(fpcFlags, " ") + " -FU/tmp -o/tmp/prog main.pas" + dockerCompiled + "/tmp/prog \"$@\"", "sh"}},
+}
+
+func (de *DockerExecutor) Execute(code string, _ []Symbol) ExecutionResult {
+	spec, ok := dockerCommands[de.language]
+	if !ok {
+		return ExecutionResult{Output: "Docker executor no soporta " + de.language, Ok: false}
+	}
+	// Los archivos auxiliares se leen desde /code, que es de solo lectura
+	workdir := "/tmp"
+	if len(de.input.Files) > 0 {
+		workdir = "/code"
+	}
+	command := append(spec.command[:len(spec.command):len(spec.command)], de.input.Args...)
+	if len(de.input.Stdin) <= 1 {
+		return de.run(spec.file, code, workdir, command)
+	}
+	runs := make([]ExecutionResult, 0, len(de.input.Stdin))
+	for _, stdin := range de.input.Stdin {
+		one := *de
+		one.input.Stdin = []string{stdin}
+		runs = append(runs, one.run(spec.file, code, workdir, command))
+	}
+	return combineRuns(runs)
+}
+
+// run escribe code en /code/file y ejecuta command en un contenedor nuevo
+// con workdir como directorio de trabajo y la única entrada de input.Stdin
+func (de *DockerExecutor) run(file, code, workdir string, command []string) ExecutionResult {
+	image := GlobalConfig.DockerImages[de.language]
+	if image == "" {
+		return ExecutionResult{Output: "Docker executor no soporta " + de.language, Ok: false}
+	}
+
+	dir, err := os.MkdirTemp("", "docker-run-*")
+	if err != nil {
+		return ExecutionResult{Output: err.Error(), Ok: false}
+	}
+	defer os.RemoveAll(dir)
+	// El usuario del contenedor no es el dueño del directorio
+	os.Chmod(dir, 0755)
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(code), 0644); err != nil {
+		return ExecutionResult{Output: err.Error(), Ok: false}
+	}
+	if err := de.input.writeFiles(dir); err != nil {
+		return ExecutionResult{Output: err.Error(), Ok: false}
+	}
+
+	name := "snippet-" + randomSuffix()
+	args := []string{
+		"run", "--rm", "--name", name,
+		"--network", GlobalConfig.DockerNetwork,
+		"--cpus", GlobalConfig.DockerCPUs,
+		"--memory", GlobalConfig.DockerMemory,
+		"--memory-swap", GlobalConfig.DockerMemory,
+		"--pids-limit", GlobalConfig.DockerPidsLimit,
+		"--read-only",
+		"--tmpfs", "/tmp:rw,exec,nosuid,size=64m",
+		"--cap-drop", "ALL",
+		"--security-opt", "no-new-privileges",
+		"--user", "65534:65534",
+		"-v", dir + ":/code:ro",
+		"-w", workdir,
+	}
+	for _, v := range de.input.envList() {
+		args = append(args, "-e", v)
+	}
+	stdin := ""
+	if len(de.input.Stdin) > 0 {
+		stdin = de.input.Stdin[0]
+	}
+	if stdin != "" {
+		args = append(args, "-i")
+	}
+	args = append(append(args, image), command...)
+
+	ctx, cancel := context.WithTimeout(de.input.parent(), de.timeout+dockerStartupGrace)
+	defer cancel()
+	out := &outputLimiter{onWrite: dockerOutputStream(command, de.input.Output)}
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Stdout, cmd.Stderr = out.stdoutWriter(), out.stderrWriter()
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	start := time.Now()
+	err = cmd.Run()
+	stdout, stderr := out.streams()
+	res, ran := splitDockerOutput(command, out.String(), stdout, stderr)
+	res.DurationMs = time.Since(start).Milliseconds()
+
+	if ctx.Err() != nil {
+		// Matar el proceso del cliente no detiene el contenedor
+		exec.Command("docker", "kill", name).Run()
+		res.Transient = true
+		if ctx.Err() == context.Canceled {
+			res.appendNote(ran, cancelledMessage)
+			return res
+		}
+		res.appendNote(ran, timeoutMessage(de.timeout))
+		res.TimedOut = true
+		return res
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 137 {
+		res.appendNote(ran, "\nProceso terminado: límite de memoria excedido")
+		return res
+	}
+	if err != nil && res.Output == "" {
+		return ExecutionResult{Output: "Docker no disponible: " + err.Error(), Ok: false, Transient: true}
+	}
+	if ran {
+		code := 0
+		if exitErr != nil {
+			code = exitErr.ExitCode()
+		}
+		res.ExitCode = &code
+	}
+	res.Ok = err == nil
+	return res
+}
+
+// splitDockerOutput separa la salida del contenedor en la de la compilación
+// y la del programa; ran indica si el programa llegó a ejecutarse
+func splitDockerOutput(command []string, combined, stdout, stderr string) (res ExecutionResult, ran bool) {
+	res.Output = strings.Replace(combined, dockerCompiledMarker+"\n", "", 1)
+	if !strings.Contains(strings.Join(command, " "), dockerCompiledMarker) {
+		res.RunStdout, res.RunStderr = stdout, stderr
+		return res, true
+	}
+	compileOutput, runStderr, found := strings.Cut(stderr, dockerCompiledMarker+"\n")
+	if !found {
+		// tsc informa los errores en stdout
+		res.CompileOutput, res.CompileError = res.Output, true
+		return res, false
+	}
+	res.CompileOutput, res.RunStdout, res.RunStderr = compileOutput, stdout, runStderr
+	return res, true
+}
+
+// dockerOutputStream pasa a onOutput solo la salida del programa: lo que el
+// contenedor escribe antes de dockerCompiledMarker es de la compilación
+func dockerOutputStream(command []string, onOutput OutputFunc) OutputFunc {
+	if onOutput == nil || !strings.Contains(strings.Join(command, " "), dockerCompiledMarker) {
+		return onOutput
+	}
+	var compileStderr []byte
+	compiled := false
+	// El outputLimiter llama con su mutex tomado, una vez por vez
+	return func(stream string, chunk []byte) {
+		if compiled {
+			onOutput(stream, chunk)
+			return
+		}
+		if stream != "stderr" {
+			return
+		}
+		compileStderr = append(compileStderr, chunk...)
+		_, rest, found := bytes.Cut(compileStderr, []byte(dockerCompiledMarker+"\n"))
+		if !found {
+			return
+		}
+		compiled, compileStderr = true, nil
+		if len(rest) > 0 {
+			onOutput(stream, rest)
+		}
+	}
+}
+
+// appendNote agrega la explicación del límite que detuvo el contenedor a la
+// salida de la fase en la que se detuvo
+func (res *ExecutionResult) appendNote(ran bool, note string) {
+	res.Output += note
+	if ran {
+		res.RunStderr += note
+	} else {
+		res.CompileOutput += note
+	}
+}
+
+func randomSuffix() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ───────────────────────────────── Fuzzing ───────────────────────────────
+//
+// El servicio analiza lo que escriben los estudiantes, que a la mitad de una
+// edición es cualquier cosa: un string sin cerrar, un "/*" suelto, bytes que
+// no son UTF-8. FuzzTokenize y FuzzParse reciben un código y un lenguaje
+// arbitrarios y fallan si algo que siempre debería valer no vale; un pánico
+// también es un hallazgo.
+//
+//	go test -run '^$' -fuzz FuzzParse -fuzztime 10m
+//
+// Las entradas que fallan quedan en testdata/fuzz/<objetivo>/ y `go test`
+// las vuelve a correr siempre.
+
+// addFuzzSeeds agrega como semillas los programas de testdata/bench y
+// testdata/golden, con el lenguaje según la extensión
+func addFuzzSeeds(f *testing.F) {
+	for _, dir := range []string{"testdata/bench", "testdata/golden"} {
+		err := filepath.WalkDir(dir, func(name string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			language := cliExtensions[strings.ToLower(filepath.Ext(name))]
+			if language == "" {
+				return nil
+			}
+			code, err := os.ReadFile(name)
+			if err != nil {
+				return err
+			}
+			f.Add(string(code), language)
+			return nil
+		})
+		if err != nil {
+			f.Fatal(err)
+		}
+	}
+}
+
+// FuzzTokenize comprueba que cada token sea el texto entre Start y End, que
+// los tokens avancen sin solaparse y que TokenizeReader dé los mismos tokens
+func FuzzTokenize(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, code, lang string) {
+		if _, ok := languages[lang]; !ok {
+			t.Skip()
+		}
+		tokens := Tokenize(code, lang)
+		prev := 0
+		for i, tk := range tokens {
+			if tk.Start < prev || tk.End < tk.Start || tk.End > len(code) {
+				t.Fatalf("token %d (%s %q) fuera de orden: [%d,%d) después de %d", i, tk.Type, tk.Lexeme, tk.Start, tk.End, prev)
+			}
+			if code[tk.Start:tk.End] != tk.Lexeme {
+				t.Fatalf("token %d: el lexema %q no es el código en [%d,%d)", i, tk.Lexeme, tk.Start, tk.End)
+			}
+			prev = tk.End
+		}
+
+		i := 0
+		err := TokenizeReader(strings.NewReader(code), lang, func(tk Token) error {
+			if i >= len(tokens) {
+				t.Fatalf("TokenizeReader da más tokens que Tokenize (%d)", len(tokens))
+			}
+			if tk != tokens[i] {
+				t.Fatalf("token %d: TokenizeReader da %+v y Tokenize %+v", i, tk, tokens[i])
+			}
+			i++
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i != len(tokens) {
+			t.Fatalf("TokenizeReader da %d tokens y Tokenize %d", i, len(tokens))
+		}
+	})
+}
+
+// FuzzParse corre el análisis léxico, el parser y el semántico y comprueba
+// que cada nodo del árbol cubra un rango del código; después corre el
+// análisis completo, sin ejecutar, que agrega las fases opcionales
+func FuzzParse(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, code, lang string) {
+		if _, ok := languages[lang]; !ok {
+			t.Skip()
+		}
+		tokens, _ := LexicalAnalysis(code, lang)
+		tree, _ := NewParser(tokens, lang, code).Parse()
+		var check func(nodes []ParseNode)
+		check = func(nodes []ParseNode) {
+			for _, n := range nodes {
+				if n.Pos < 0 || n.End < n.Pos || n.End > len(code) {
+					t.Fatalf("nodo %s %q con rango [%d,%d) fuera del código (%d bytes)", n.Kind, n.Label, n.Pos, n.End, len(code))
+				}
+				check(n.Children)
+			}
+		}
+		check(tree)
+		NewSemanticAnalyzer(tokens, tree, lang).Analyze()
+		AnalyzeCodeWithProgress(code, lang, AnalyzeOptions{SkipExecution: true}, nil)
+	})
+}
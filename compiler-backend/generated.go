@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ───────────────────────────── Código generado ───────────────────────────
+//
+// Con generatedCode en la petición se devuelve lo que produce la herramienta
+// real a partir del programa: el ensamblador de g++ -S para C++, el bytecode
+// de CPython (python3 -m dis) y el de V8 (node --print-bytecode) para
+// JavaScript. Ninguna de las tres ejecuta el programa, solo lo compila, pero
+// corren con los mismos límites y en el mismo backend que el ejecutor.
+
+// GeneratedCode es la salida de la herramienta para la respuesta
+type GeneratedCode struct {
+	// "assembly" o "bytecode"
+	Kind   string
+	Tool   string
+	Output string
+	Ok     bool
+	// Igual que ExecutionResult.Transient: no se guarda en la caché
+	Transient bool
+}
+
+// codeGenerator describe cómo obtener el código generado de un lenguaje.
+// command se ejecuta en el directorio donde se escribió file
+type codeGenerator struct {
+	kind, tool, file string
+	command          []string
+	// Límite de salida de la herramienta; 0 = GlobalConfig.MaxOutputBytes
+	outputBytes int
+	// Limpia la salida de la herramienta; ok indica si terminó bien
+	filter func(out string, ok bool) string
+}
+
+// Sin -O: el ensamblador sigue de cerca al código fuente
+var codeGenerators = map[string]codeGenerator{
+	"cpp": {
+		kind: "assembly", tool: "g++ -S", file: "main.cpp",
+		command: []string{"g++", "-std=c++17", "-S", "-O0", "-fno-asynchronous-unwind-tables", "-o", "-", "main.cpp"},
+		filter:  cleanAssembly,
+	},
+	"python": {
+		kind: "bytecode", tool: "python3 -m dis", file: "main.py",
+		command: []string{"python3", "-m", "dis", "main.py"},
+		filter:  pythonDisError,
+	},
+	"javascript": {
+		kind: "bytecode", tool: "node --print-bytecode", file: "main.js",
+		// --no-lazy compila también las funciones que nunca se llaman
+		command: []string{"node", "--print-bytecode", "--no-lazy", "-e", v8BytecodeScript, "main.js"},
+		// V8 imprime antes el bytecode de las funciones internas de node
+		outputBytes: 16 << 20,
+		filter:      userBytecode,
+	},
+}
+
+// v8BytecodeMarker separa el bytecode interno de node del del programa
+const v8BytecodeMarker = "__codigo_generado__"
+
+// v8BytecodeScript compila main.js con vm.Script sin ejecutarlo. La primera
+// compilación carga lo que vm necesita, la segunda deja la marca: todo lo que
+// V8 imprime después es del programa. Un error de sintaxis se imprime en una
+// sola línea, como cadena JSON después de la marca, porque stderr se mezcla
+// con el bytecode
+const v8BytecodeScript = `const vm = require("vm")
+const code = require("fs").readFileSync(process.argv[1], "utf8")
+new vm.Script("0")
+new vm.Script("function ` + v8BytecodeMarker + `() {}")
+try {
+  new vm.Script(code, { filename: "main.js" })
+} catch (e) {
+  console.error("` + v8BytecodeMarker + ` " + JSON.stringify(String(e.stack).split("\n    at ")[0]))
+  process.exitCode = 1
+}`
+
+// GenerateCode devuelve el código generado de code, o un resultado con Ok
+// false que explica por qué no se pudo obtener
+func GenerateCode(code, language string, timeout time.Duration) *GeneratedCode {
+	gen, ok := codeGenerators[language]
+	if !ok {
+		return &GeneratedCode{Output: fmt.Sprintf("No hay código generado para %s: solo C++, Python y JavaScript", language)}
+	}
+	result := &GeneratedCode{Kind: gen.kind, Tool: gen.tool}
+	if !currentConfig().EnableRealExecution {
+		result.Output = "La ejecución real está deshabilitada: no se puede invocar a " + gen.tool
+		return result
+	}
+
+	res := limitedExecutor{generatorExecutor{gen, language, timeout}, timeout}.Execute(code, nil)
+	result.Output, result.Ok, result.Transient = res.Output, res.Ok, res.Transient
+	if gen.filter != nil {
+		result.Output = gen.filter(res.Output, res.Ok)
+	}
+	if max := GlobalConfig.MaxOutputBytes; max > 0 && len(result.Output) > max {
+		// Sin partir un carácter de varios bytes
+		cut := max
+		for cut > 0 && !utf8.RuneStart(result.Output[cut]) {
+			cut--
+		}
+		result.Output = result.Output[:cut] + fmt.Sprintf("\nSalida truncada: se superó el límite de %d bytes", max)
+	}
+	return result
+}
+
+// generatorExecutor corre la herramienta de un codeGenerator como Executor,
+// para compartir los lugares de ejecución con limitedExecutor
+type generatorExecutor struct {
+	gen      codeGenerator
+	language string
+	timeout  time.Duration
+}
+
+func (ge generatorExecutor) Execute(code string, _ []Symbol) ExecutionResult {
+	if GlobalConfig.ExecutionBackend == BackendDocker {
+		return NewDockerExecutor(ge.language, ge.timeout, ProgramInput{}).run(ge.gen.file, code, "/code", ge.gen.command)
+	}
+
+	dir, err := os.MkdirTemp("", "generated-*")
+	if err != nil {
+		return ExecutionResult{Output: err.Error(), Ok: false}
+	}
+	defer os.RemoveAll(dir)
+	if err := os.WriteFile(filepath.Join(dir, ge.gen.file), []byte(code), 0600); err != nil {
+		return ExecutionResult{Output: err.Error(), Ok: false}
+	}
+
+	limits := limitsFor(ge.language)
+	if ge.gen.outputBytes > 0 {
+		limits.OutputBytes = ge.gen.outputBytes
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ge.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, ge.gen.command[0], ge.gen.command[1:]...)
+	prepareTool(cmd, dir)
+	return runLimited(ctx, cmd, limits).runExecution("", ge.timeout, limits)
+}
+
+// cleanAssembly quita las directivas .cfi_* que g++ emite para las
+// excepciones y que no aportan a la lectura del ensamblador. Si la
+// compilación falló quita todas: g++ alcanza a escribir el comienzo del
+// archivo antes de los errores
+func cleanAssembly(asm string, ok bool) string {
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(asm, "\n") {
+		directive := strings.HasPrefix(line, "\t.")
+		if ok && !strings.HasPrefix(strings.TrimSpace(line), ".cfi_") || !ok && !directive {
+			b.WriteString(line)
+		}
+	}
+	return b.String()
+}
+
+// pythonDisError recorta la traza de un error de compilación a la parte de
+// main.py, sin los marcos internos del módulo dis
+func pythonDisError(out string, ok bool) string {
+	if i := strings.Index(out, "  File \"main.py\""); !ok && i >= 0 {
+		return out[i:]
+	}
+	return out
+}
+
+// userBytecode descarta todo lo que V8 imprimió hasta la función marca
+// inclusive, dejando solo el bytecode de main.js. Si node falló devuelve el
+// error de sintaxis o, si no lo hay, la última línea (el límite excedido)
+func userBytecode(out string, ok bool) string {
+	if !ok {
+		lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+		for _, line := range lines {
+			var msg string
+			if rest, found := strings.CutPrefix(line, v8BytecodeMarker+" "); found && json.Unmarshal([]byte(rest), &msg) == nil {
+				return msg
+			}
+		}
+		return lines[len(lines)-1]
+	}
+	marker := strings.Index(out, "[generated bytecode for function: "+v8BytecodeMarker+" ")
+	if marker < 0 {
+		return out
+	}
+	rest := out[marker+1:]
+	next := strings.Index(rest, "\n[generated bytecode for function: ")
+	if next < 0 {
+		return ""
+	}
+	return rest[next+1:]
+}
@@ -0,0 +1,476 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// ──────────────────────────── Webhook de GitHub ──────────────────────────
+//
+// Con GITHUB_WEBHOOK_SECRET y GITHUB_TOKEN, POST /api/v1/webhooks/github
+// recibe los push de los repositorios de los estudiantes: descarga los
+// archivos fuente agregados o modificados en el push, los analiza y publica
+// el resultado en el último commit, así el estudiante ve los errores en su
+// propio repositorio.
+//
+// El resultado es un check run con una anotación por diagnóstico en la
+// línea del archivo. Los check runs solo los puede crear una GitHub App; con
+// un token personal la API responde 403 y se publica en su lugar un commit
+// status con la cantidad de errores y advertencias.
+//
+// La petición se autentica con la firma X-Hub-Signature-256 del cuerpo, no
+// con las credenciales de la API. GitHub corta la entrega a los 10
+// segundos, así que el handler solo encola el push y responde 202; un
+// worker lo procesa después. Los programas no se ejecutan: el código del
+// repositorio no tiene un usuario al que cargar la ejecución.
+
+const (
+	githubEventHeader     = "X-GitHub-Event"
+	githubSignatureHeader = "X-Hub-Signature-256"
+	// Nombre del check run y contexto del commit status
+	githubCheckName = "compiler-backend"
+	// Archivos que se analizan por push; el resto se nombra en el resumen
+	githubMaxFiles = 20
+	// Anotaciones que acepta la API en una sola petición
+	githubMaxAnnotations = 50
+	// Largo máximo de la descripción de un commit status
+	githubMaxDescription = 140
+)
+
+var githubHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// githubPush es lo que se usa del evento push
+type githubPush struct {
+	After      string `json:"after"`
+	Deleted    bool   `json:"deleted"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	Commits []struct {
+		Added    []string `json:"added"`
+		Removed  []string `json:"removed"`
+		Modified []string `json:"modified"`
+	} `json:"commits"`
+}
+
+// githubQueue son los push recibidos que esperan su análisis
+type githubQueue struct {
+	pending chan githubPush
+}
+
+var githubPushes *githubQueue
+
+// newGitHubQueue crea la cola con lugar para size push y arranca su worker
+func newGitHubQueue(size int) *githubQueue {
+	q := &githubQueue{pending: make(chan githubPush, size)}
+	go q.work()
+	return q
+}
+
+func (q *githubQueue) work() {
+	for push := range q.pending {
+		if err := push.processSafely(); err != nil {
+			log.Printf("github: %s@%s: %v", push.Repository.FullName, push.After, err)
+		}
+	}
+}
+
+// processSafely es process sin tirar el worker si el análisis de un archivo
+// entra en pánico (ver recover.go)
+func (p *githubPush) processSafely() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			failure := capturePanic(r)
+			logPanic(fmt.Sprintf("github push %s@%s", p.Repository.FullName, p.After), failure)
+			err = failure
+		}
+	}()
+	return p.process()
+}
+
+// validGitHubSignature comprueba el HMAC-SHA256 del cuerpo con el secreto
+// del webhook
+func validGitHubSignature(body []byte, signature string) bool {
+	sum, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil || !strings.HasPrefix(signature, "sha256=") {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(GlobalConfig.GitHubWebhookSecret))
+	mac.Write(body)
+	return hmac.Equal(sum, mac.Sum(nil))
+}
+
+func githubWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	if githubPushes == nil {
+		httpError(w, "GitHub webhook is not configured", http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodPost {
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		httpError(w, "Could not read the request body", http.StatusBadRequest)
+		return
+	}
+	if !validGitHubSignature(body, r.Header.Get(githubSignatureHeader)) {
+		httpError(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	// ping (al crear el webhook) y los demás eventos no se usan; 204 para
+	// que GitHub no los marque como fallidos
+	if r.Header.Get(githubEventHeader) != "push" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var push githubPush
+	if err := json.Unmarshal(body, &push); err != nil {
+		invalidJSON(w)
+		return
+	}
+	// Un push que borra la rama no tiene commit donde publicar
+	if push.Deleted || strings.Trim(push.After, "0") == "" || push.Repository.FullName == "" {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	files, skipped := push.sourceFiles()
+	if len(files) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	select {
+	case githubPushes.pending <- push:
+	default:
+		// GitHub permite volver a entregar el evento desde la configuración
+		// del webhook
+		httpError(w, "GitHub queue is full", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(APIGitHubPushResponse{Files: files, Skipped: skipped})
+}
+
+// APIGitHubPushResponse son los archivos del push que se van a analizar
+type APIGitHubPushResponse struct {
+	Files []string `json:"files"`
+	// Archivos que pasan de githubMaxFiles
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// sourceFiles devuelve los archivos con una extensión conocida que quedan
+// agregados o modificados después de todos los commits del push, y los que
+// no se analizan por pasar de githubMaxFiles
+func (p *githubPush) sourceFiles() (files, skipped []string) {
+	changed := map[string]bool{}
+	var order []string
+	for _, c := range p.Commits {
+		for _, list := range [][]string{c.Added, c.Modified} {
+			for _, f := range list {
+				if !changed[f] {
+					order = append(order, f)
+				}
+				changed[f] = true
+			}
+		}
+		for _, f := range c.Removed {
+			changed[f] = false
+		}
+	}
+	for _, f := range order {
+		if !changed[f] || cliExtensions[strings.ToLower(path.Ext(f))] == "" {
+			continue
+		}
+		if len(files) == githubMaxFiles {
+			skipped = append(skipped, f)
+			continue
+		}
+		files = append(files, f)
+	}
+	return files, skipped
+}
+
+// githubFileResult es el análisis de un archivo del push; err indica por
+// qué no se pudo analizar
+type githubFileResult struct {
+	path     string
+	language string
+	errors   []APICompilerError
+	err      string
+}
+
+// process analiza los archivos del push y publica el resultado
+func (p *githubPush) process() error {
+	files, skipped := p.sourceFiles()
+	locale := GlobalConfig.DefaultLocale
+	results := make([]githubFileResult, 0, len(files))
+	for _, f := range files {
+		res := githubFileResult{path: f, language: cliExtensions[strings.ToLower(path.Ext(f))]}
+		code, err := p.fetch(f)
+		switch {
+		case err != nil:
+			res.err = err.Error()
+		case codeTooLarge(code) != "":
+			res.err = codeTooLarge(code)
+		default:
+			result := AnalyzeCodeCached(code, res.language, AnalyzeOptions{SkipExecution: true})
+			recordAnalysis(code, result, nil)
+			res.errors = buildAPIResponse(result, newSourceIndex(code), locale).Errors
+		}
+		results = append(results, res)
+	}
+
+	err := p.createCheckRun(results, githubSummary(results, skipped, locale), locale)
+	if err == errGitHubForbidden {
+		return p.createStatus(results, locale)
+	}
+	return err
+}
+
+// githubRequest llama a la API de GitHub con GITHUB_TOKEN; body se envía
+// como JSON
+func githubRequest(method, endpoint, accept string, body interface{}) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, strings.TrimSuffix(GlobalConfig.GitHubAPIURL, "/")+endpoint, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+GlobalConfig.GitHubToken)
+	req.Header.Set("Accept", accept)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return githubHTTPClient.Do(req)
+}
+
+// repoPath es /repos/{owner}/{repo} seguido de rest
+func (p *githubPush) repoPath(rest string) string {
+	owner, repo, _ := strings.Cut(p.Repository.FullName, "/")
+	return "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo) + rest
+}
+
+// fetch descarga el contenido de file en el commit del push
+func (p *githubPush) fetch(file string) (string, error) {
+	segments := strings.Split(file, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	endpoint := p.repoPath("/contents/" + strings.Join(segments, "/") + "?ref=" + url.QueryEscape(p.After))
+	resp, err := githubRequest(http.MethodGet, endpoint, "application/vnd.github.raw+json", nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GET %s: %s", file, resp.Status)
+	}
+	body := io.Reader(resp.Body)
+	if limit := GlobalConfig.MaxFileSize; limit > 0 {
+		// Un byte de más para que codeTooLarge lo note
+		body = io.LimitReader(resp.Body, int64(limit)+1)
+	}
+	data, err := io.ReadAll(body)
+	return string(data), err
+}
+
+var errGitHubForbidden = fmt.Errorf("the token cannot create check runs")
+
+// createCheckRun publica el análisis como check run del commit
+func (p *githubPush) createCheckRun(results []githubFileResult, summary, locale string) error {
+	conclusion := "success"
+	annotations := []map[string]interface{}{}
+	total := 0
+	for _, f := range results {
+		for _, e := range f.errors {
+			if e.Severity == "error" {
+				conclusion = "failure"
+			}
+			total++
+			if len(annotations) == githubMaxAnnotations {
+				continue
+			}
+			a := map[string]interface{}{
+				"path":             f.path,
+				"start_line":       max(e.Line, 1),
+				"end_line":         max(e.Line, 1),
+				"annotation_level": githubAnnotationLevel(e.Severity),
+				"message":          e.Message,
+				"title":            sarifRuleID(e),
+			}
+			annotations = append(annotations, a)
+		}
+		if f.err != "" && conclusion == "success" {
+			conclusion = "neutral"
+		}
+	}
+	if total > len(annotations) {
+		summary += fmt.Sprintf("\n_%d / %d_\n", len(annotations), total)
+	}
+
+	title := githubStatusDescription(results, locale)
+	resp, err := githubRequest(http.MethodPost, p.repoPath("/check-runs"), "application/vnd.github+json", map[string]interface{}{
+		"name":       githubCheckName,
+		"head_sha":   p.After,
+		"status":     "completed",
+		"conclusion": conclusion,
+		"output": map[string]interface{}{
+			"title":       title,
+			"summary":     summary,
+			"annotations": annotations,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusForbidden:
+		return errGitHubForbidden
+	case resp.StatusCode/100 != 2:
+		return fmt.Errorf("POST check-runs: %s", resp.Status)
+	}
+	return nil
+}
+
+// createStatus publica el análisis como commit status, para los tokens que
+// no pueden crear check runs
+func (p *githubPush) createStatus(results []githubFileResult, locale string) error {
+	state := "success"
+	for _, f := range results {
+		for _, e := range f.errors {
+			if e.Severity == "error" {
+				state = "failure"
+			}
+		}
+	}
+	description := []rune(githubStatusDescription(results, locale))
+	if len(description) > githubMaxDescription {
+		description = append(description[:githubMaxDescription-1], '…')
+	}
+	resp, err := githubRequest(http.MethodPost, p.repoPath("/statuses/"+url.PathEscape(p.After)), "application/vnd.github+json", map[string]string{
+		"state":       state,
+		"description": string(description),
+		"context":     githubCheckName,
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("POST statuses: %s", resp.Status)
+	}
+	return nil
+}
+
+func githubAnnotationLevel(severity string) string {
+	switch severity {
+	case "error":
+		return "failure"
+	case "warning":
+		return "warning"
+	}
+	return "notice"
+}
+
+// Textos del resumen en cada idioma
+type githubText struct {
+	Status, File, Language, Errors, Warnings, Skipped, Failed string
+}
+
+var githubTexts = map[string]githubText{
+	"es": {
+		Status: "%d errores y %d advertencias en %d archivos",
+		File:   "Archivo", Language: "Lenguaje", Errors: "Errores", Warnings: "Advertencias",
+		Skipped: "No se analizaron (más de %d archivos en el push)",
+		Failed:  "No se pudo analizar",
+	},
+	"en": {
+		Status: "%d errors and %d warnings in %d files",
+		File:   "File", Language: "Language", Errors: "Errors", Warnings: "Warnings",
+		Skipped: "Not analyzed (more than %d files in the push)",
+		Failed:  "Could not be analyzed",
+	},
+}
+
+func githubTextsFor(locale string) githubText {
+	if text, ok := githubTexts[locale]; ok {
+		return text
+	}
+	return githubTexts["es"]
+}
+
+// githubCounts cuenta los errores y las advertencias de un archivo
+func githubCounts(f githubFileResult) (errs, warnings int) {
+	for _, e := range f.errors {
+		switch e.Severity {
+		case "error":
+			errs++
+		case "warning":
+			warnings++
+		}
+	}
+	return errs, warnings
+}
+
+// githubStatusDescription es el título del check run y la descripción del
+// commit status
+func githubStatusDescription(results []githubFileResult, locale string) string {
+	errs, warnings := 0, 0
+	for _, f := range results {
+		e, w := githubCounts(f)
+		errs += e
+		warnings += w
+	}
+	return fmt.Sprintf(githubTextsFor(locale).Status, errs, warnings, len(results))
+}
+
+// githubSummary es la tabla en Markdown del check run
+func githubSummary(results []githubFileResult, skipped []string, locale string) string {
+	text := githubTextsFor(locale)
+	var b strings.Builder
+	fmt.Fprintf(&b, "| %s | %s | %s | %s |\n|:--|:--|--:|--:|\n", text.File, text.Language, text.Errors, text.Warnings)
+	var failed []githubFileResult
+	for _, f := range results {
+		if f.err != "" {
+			failed = append(failed, f)
+			continue
+		}
+		errs, warnings := githubCounts(f)
+		fmt.Fprintf(&b, "| `%s` | %s | %d | %d |\n", f.path, f.language, errs, warnings)
+	}
+	if len(failed) > 0 {
+		fmt.Fprintf(&b, "\n**%s**\n\n", text.Failed)
+		for _, f := range failed {
+			fmt.Fprintf(&b, "- `%s`: %s\n", f.path, f.err)
+		}
+	}
+	if len(skipped) > 0 {
+		fmt.Fprintf(&b, "\n**"+text.Skipped+"**\n\n", githubMaxFiles)
+		for _, f := range skipped {
+			fmt.Fprintf(&b, "- `%s`\n", f)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestGitHubWebhookSignature comprueba que solo se encola un push firmado
+// con el secreto del webhook y sobre el mismo cuerpo que se recibe
+func TestGitHubWebhookSignature(t *testing.T) {
+	const secret = "secreto-del-webhook"
+	saved, savedSecret := githubPushes, GlobalConfig.GitHubWebhookSecret
+	// Sin worker: el push queda en la cola
+	githubPushes = &githubQueue{pending: make(chan githubPush, 10)}
+	GlobalConfig.GitHubWebhookSecret = secret
+	defer func() { githubPushes, GlobalConfig.GitHubWebhookSecret = saved, savedSecret }()
+
+	sign := func(body, key string) string {
+		mac := hmac.New(sha256.New, []byte(key))
+		mac.Write([]byte(body))
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+	push := `{"after": "1a2b3c", "repository": {"full_name": "ana/tareas"}, "commits": [{"added": ["main.py"]}]}`
+
+	cases := []struct {
+		name      string
+		event     string
+		body      string
+		signature string
+		status    int
+	}{
+		{"valida", "push", push, sign(push, secret), http.StatusAccepted},
+		{"ping", "ping", `{"zen": "hola"}`, sign(`{"zen": "hola"}`, secret), http.StatusNoContent},
+		{"otro_secreto", "push", push, sign(push, "otro"), http.StatusUnauthorized},
+		{"cuerpo_alterado", "push", strings.Replace(push, "main.py", "otro.py", 1), sign(push, secret), http.StatusUnauthorized},
+		{"sin_prefijo", "push", push, strings.TrimPrefix(sign(push, secret), "sha256="), http.StatusUnauthorized},
+		{"sin_firma", "push", push, "", http.StatusUnauthorized},
+		{"ping_sin_firma", "ping", `{}`, "", http.StatusUnauthorized},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			queued := len(githubPushes.pending)
+			r := httptest.NewRequest(http.MethodPost, apiPrefix+"/webhooks/github", strings.NewReader(c.body))
+			r.Header.Set(githubEventHeader, c.event)
+			if c.signature != "" {
+				r.Header.Set(githubSignatureHeader, c.signature)
+			}
+			w := httptest.NewRecorder()
+			githubWebhookHandler(w, r)
+			if w.Code != c.status {
+				t.Fatalf("estado %d, esperado %d: %s", w.Code, c.status, w.Body.String())
+			}
+			wantQueued := queued
+			if c.status == http.StatusAccepted {
+				wantQueued++
+			}
+			if got := len(githubPushes.pending); got != wantQueued {
+				t.Errorf("%d push en la cola, esperados %d", got, wantQueued)
+			}
+		})
+	}
+}
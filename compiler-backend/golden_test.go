@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// ───────────────────────────── Archivos golden ───────────────────────────
+//
+// testdata/golden tiene, por lenguaje, programas válidos y rotos a propósito
+// y junto a cada uno un .golden.json con sus tokens, su tabla de símbolos y
+// sus diagnósticos, tal como los devuelve /api/v1/analyze. Los cursos
+// dependen de esos mensajes, así que un cambio del pipeline que los altere
+// tiene que verse:
+//
+//	go test -run Golden                   # compara y muestra las diferencias
+//	go test -run Golden/python -update    # acepta la salida actual
+//
+// El análisis es sin ejecutar y en español, con la configuración por
+// defecto de los diagnósticos. Cada token, símbolo y error ocupa una línea
+// del .golden.json para que los diffs (los de la prueba y los de git)
+// muestren exactamente qué cambió.
+
+// Directorio del corpus, relativo a compiler-backend
+const goldenDir = "testdata/golden"
+
+// goldenOutput es el contenido de un .golden.json
+type goldenOutput struct {
+	Language string             `json:"language"`
+	Tokens   []APIToken         `json:"tokens"`
+	Symbols  []APISymbol        `json:"symbols"`
+	Errors   []APICompilerError `json:"errors"`
+}
+
+// goldenFile es el .golden.json del programa name
+func goldenFile(name string) string {
+	return strings.TrimSuffix(name, filepath.Ext(name)) + ".golden.json"
+}
+
+// goldenAnalysis analiza code como lo haría /api/v1/analyze
+func goldenAnalysis(code, language string) goldenOutput {
+	result := AnalyzeCodeWithProgress(code, language, AnalyzeOptions{SkipExecution: true}, nil)
+	response := buildAPIResponse(result, newSourceIndex(code), "es")
+	return goldenOutput{Language: response.Language, Tokens: response.Tokens,
+		Symbols: response.SymbolTable, Errors: response.Errors}
+}
+
+// marshal escribe g con un elemento de cada lista por línea
+func (g goldenOutput) marshal() []byte {
+	var b bytes.Buffer
+	language, _ := json.Marshal(g.Language)
+	fmt.Fprintf(&b, "{\n  \"language\": %s,\n", language)
+	// Sin escapar <, > y &, que abundan en los tokens
+	var item bytes.Buffer
+	enc := json.NewEncoder(&item)
+	enc.SetEscapeHTML(false)
+	section := func(name string, items []any, last bool) {
+		fmt.Fprintf(&b, "  %q: [", name)
+		for i, v := range items {
+			item.Reset()
+			enc.Encode(v)
+			b.WriteString("\n    ")
+			b.Write(bytes.TrimSuffix(item.Bytes(), []byte("\n")))
+			if i < len(items)-1 {
+				b.WriteByte(',')
+			}
+		}
+		if len(items) > 0 {
+			b.WriteString("\n  ")
+		}
+		b.WriteByte(']')
+		if !last {
+			b.WriteByte(',')
+		}
+		b.WriteByte('\n')
+	}
+	section("tokens", goldenItems(g.Tokens), false)
+	section("symbols", goldenItems(g.Symbols), false)
+	section("errors", goldenItems(g.Errors), true)
+	b.WriteString("}\n")
+	return b.Bytes()
+}
+
+func goldenItems[T any](items []T) []any {
+	out := make([]any, len(items))
+	for i, item := range items {
+		out[i] = item
+	}
+	return out
+}
+
+// goldenPrograms lista los programas de dir con un lenguaje conocido por
+// su extensión, ordenados
+func goldenPrograms(dir string) ([]string, error) {
+	var programs []string
+	err := filepath.WalkDir(dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if cliExtensions[strings.ToLower(filepath.Ext(name))] != "" {
+			programs = append(programs, name)
+		}
+		return nil
+	})
+	sort.Strings(programs)
+	return programs, err
+}
+
+var updateGolden = flag.Bool("update", false, "reescribe los .golden.json con la salida actual")
+
+// TestGolden compara el análisis de cada programa de testdata/golden con su
+// .golden.json
+func TestGolden(t *testing.T) {
+	programs, err := goldenPrograms(goldenDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range programs {
+		t.Run(strings.TrimPrefix(filepath.ToSlash(name), goldenDir+"/"), func(t *testing.T) {
+			code, err := os.ReadFile(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			language := cliExtensions[strings.ToLower(filepath.Ext(name))]
+			got := goldenAnalysis(string(code), language).marshal()
+			golden := goldenFile(name)
+
+			if *updateGolden {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if os.IsNotExist(err) {
+				t.Fatalf("falta %s (correr con -update)", golden)
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(want, got) {
+				var diff strings.Builder
+				printGoldenDiff(&diff, string(want), string(got))
+				t.Errorf("difiere de %s:\n%s", golden, diff.String())
+			}
+		})
+	}
+}
+
+// printGoldenDiff muestra las líneas de want que faltan (-) y las de got que
+// sobran (+), con una línea de contexto, según la subsecuencia común más
+// larga
+func printGoldenDiff(w io.Writer, want, got string) {
+	a := strings.Split(strings.TrimSuffix(want, "\n"), "\n")
+	b := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	// lcs[i][j] es la subsecuencia común más larga de a[i:] y b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type diffLine struct {
+		op   byte
+		text string
+	}
+	var lines []diffLine
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i, j = i+1, j+1
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	changed := func(k int) bool { return k >= 0 && k < len(lines) && lines[k].op != ' ' }
+	last := -1
+	for k, l := range lines {
+		if l.op == ' ' && !changed(k-1) && !changed(k+1) {
+			continue
+		}
+		if last >= 0 && k > last+1 {
+			fmt.Fprintln(w, "  ...")
+		}
+		fmt.Fprintf(w, "  %c %s\n", l.op, l.text)
+		last = k
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// ──────────────────────────── Estado del servidor ────────────────────────
+//
+// Para un orquestador de contenedores hay dos preguntas distintas:
+//
+//   GET /api/v1/healthz  ¿el proceso responde? (liveness) Si no, se
+//                        reinicia. No revisa nada más: un Redis caído no se
+//                        arregla reiniciando el servidor.
+//   GET /api/v1/readyz   ¿puede atender análisis? (readiness) Si no, se le
+//                        deja de enviar tráfico hasta que se recupere.
+//
+// /readyz revisa las herramientas de ejecución, que se pueda escribir en el
+// directorio temporal (ahí se compila cada programa), la cola de análisis
+// asíncronos y la caché de resultados, y devuelve el detalle de cada
+// revisión. Responde 503 si alguna falla; las degradadas (un lenguaje sin
+// su compilador, que se simula) no sacan al servidor de servicio.
+// /api/v1/health se mantiene como sinónimo de /healthz.
+
+// Resultado de cada revisión de /readyz
+const (
+	CheckOK       = "ok"
+	CheckDegraded = "degraded"
+	CheckFailed   = "failed"
+)
+
+// APIHealthCheck es una revisión de /readyz; Details depende de la revisión
+type APIHealthCheck struct {
+	Name    string                 `json:"name"`
+	Status  string                 `json:"status"` // "ok" | "degraded" | "failed"
+	Message string                 `json:"message,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
+
+// Respuesta de GET /api/v1/readyz
+type APIReadinessResponse struct {
+	Status  string           `json:"status"` // "ready" | "not ready"
+	Service string           `json:"service"`
+	Checks  []APIHealthCheck `json:"checks"`
+}
+
+// readinessChecks son las revisiones de /readyz, en el orden en que se
+// informan
+var readinessChecks = []func() APIHealthCheck{
+	checkToolchains,
+	checkTempDir,
+	checkJobQueue,
+	checkResultCache,
+}
+
+// readyHandler atiende GET /api/v1/readyz
+func readyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	response := APIReadinessResponse{Status: "ready", Service: "Compilador Go Backend"}
+	status := http.StatusOK
+	for _, check := range readinessChecks {
+		result := check()
+		if result.Status == CheckFailed {
+			response.Status, status = "not ready", http.StatusServiceUnavailable
+		}
+		response.Checks = append(response.Checks, result)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// checkToolchains falla si el motor Docker no tiene docker, porque entonces
+// no se ejecuta nada; un lenguaje sin su herramienta solo lo degrada
+func checkToolchains() APIHealthCheck {
+	check := APIHealthCheck{Name: "toolchains", Status: CheckOK}
+	config := currentConfig()
+	tools, checked := toolchains.snapshot(false)
+	check.Details = map[string]interface{}{
+		"backend":       config.ExecutionBackend,
+		"realExecution": config.EnableRealExecution,
+		"checkedAt":     checked,
+	}
+	if !config.EnableRealExecution {
+		check.Message = "La ejecución real está desactivada"
+		return check
+	}
+	if config.ExecutionBackend == BackendDocker && !tools["docker"].Available {
+		check.Status, check.Message = CheckFailed, "El motor de ejecución es docker y docker no está disponible"
+		return check
+	}
+
+	// Los lenguajes que el administrador desactivó no cuentan: no es algo
+	// que falte
+	var missing []string
+	for name := range languages {
+		tc := toolchainFor(name, config, tools)
+		if tc.Executable || tc.Engine == EngineEmbedded || !config.languageAllowed(name) {
+			continue
+		}
+		for _, tool := range tc.Tools {
+			if !tool.Available {
+				missing = append(missing, name)
+				break
+			}
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		check.Status = CheckDegraded
+		check.Message = "Lenguajes sin sus herramientas; su ejecución se simula"
+		check.Details["unavailable"] = missing
+	}
+	return check
+}
+
+// checkTempDir crea, escribe y borra un directorio como los de cada
+// compilación
+func checkTempDir() APIHealthCheck {
+	check := APIHealthCheck{Name: "tempDir", Status: CheckOK, Details: map[string]interface{}{"path": os.TempDir()}}
+	dir, err := os.MkdirTemp("", "readyz-*")
+	if err == nil {
+		err = os.WriteFile(filepath.Join(dir, "probe"), []byte("ok"), 0o600)
+		if rmErr := os.RemoveAll(dir); err == nil {
+			err = rmErr
+		}
+	}
+	if err != nil {
+		check.Status, check.Message = CheckFailed, err.Error()
+	}
+	return check
+}
+
+// checkJobQueue falla si la cola está llena: /api/v1/analyze/async
+// respondería 503
+func checkJobQueue() APIHealthCheck {
+	check := APIHealthCheck{Name: "jobQueue", Status: CheckOK}
+	backend := "memory"
+	if GlobalConfig.RedisURL != "" {
+		backend = "redis"
+	}
+	check.Details = map[string]interface{}{
+		"backend":  backend,
+		"capacity": GlobalConfig.AsyncQueueSize,
+		"workers":  GlobalConfig.AsyncWorkers,
+	}
+	if jobs == nil {
+		check.Status, check.Message = CheckFailed, "La cola no se inició"
+		return check
+	}
+	depth, err := jobs.depth()
+	if err != nil {
+		check.Status, check.Message = CheckFailed, err.Error()
+		return check
+	}
+	check.Details["depth"] = depth
+	if depth >= GlobalConfig.AsyncQueueSize {
+		check.Status, check.Message = CheckFailed, "La cola está llena"
+	}
+	return check
+}
+
+// checkResultCache informa cuánto hay en la caché local y, con Redis, si
+// responde; sin Redis no hay nada que pueda fallar
+func checkResultCache() APIHealthCheck {
+	check := APIHealthCheck{Name: "resultCache", Status: CheckOK}
+	check.Details = map[string]interface{}{
+		"entries":  analysisCache.len(),
+		"capacity": GlobalConfig.ResultCacheSize,
+		"shared":   sharedCache != nil,
+	}
+	if GlobalConfig.ResultCacheSize <= 0 {
+		check.Message = "La caché está desactivada"
+	}
+	if sharedCache != nil {
+		start := time.Now()
+		if err := sharedCache.client.ping(); err != nil {
+			check.Status, check.Message = CheckFailed, fmt.Sprintf("Redis no responde: %v", err)
+			return check
+		}
+		check.Details["redisLatency"] = time.Since(start).String()
+	}
+	return check
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"html"
+	"net/http"
+	"strings"
+)
+
+// ──────────────────────────── Resaltado de sintaxis ──────────────────────
+//
+// POST /api/v1/highlight?format=html|ansi devuelve el código resaltado a
+// partir de los tokens de Tokenize, así el color coincide siempre con lo que
+// ve el analizador (un token inválido se marca igual que en los errores
+// léxicos). En HTML cada token queda en un <span class="token-KEYWORD">, con
+// las clases de los tipos de token de la API, para que el frontend defina
+// los colores en CSS; en ANSI se usan los colores de la terminal. El texto
+// entre tokens (espacios y saltos de línea) se copia sin cambios.
+
+const (
+	highlightHTML = "html"
+	highlightANSI = "ansi"
+)
+
+var highlightContentTypes = map[string]string{
+	highlightHTML: "text/html; charset=utf-8",
+	highlightANSI: "text/plain; charset=utf-8",
+}
+
+const ansiReset = "\x1b[0m"
+
+// Color ANSI de cada tipo de token; los que no están se dejan sin color
+var ansiTokenColors = map[TokenType]string{
+	KEYWORD:      "\x1b[35m",   // magenta
+	STRING:       "\x1b[32m",   // verde
+	NUMBER:       "\x1b[33m",   // amarillo
+	COMMENT:      "\x1b[90m",   // gris
+	FUNCTION:     "\x1b[34m",   // azul
+	CLASS:        "\x1b[36m",   // cian
+	CONSTANT:     "\x1b[33m",   // amarillo
+	PREPROCESSOR: "\x1b[95m",   // magenta claro
+	UNKNOWN:      "\x1b[4;31m", // rojo subrayado
+}
+
+// highlightCode devuelve code con los tokens marcados en el formato pedido
+func highlightCode(code, language, format string) string {
+	var b strings.Builder
+	text := func(s string) {
+		if format == highlightHTML {
+			s = html.EscapeString(s)
+		}
+		b.WriteString(s)
+	}
+
+	pos := 0
+	for _, t := range Tokenize(code, language) {
+		text(code[pos:t.Start])
+		pos = t.End
+		if format == highlightHTML {
+			b.WriteString(`<span class="token-` + strings.ToUpper(t.Type.String()) + `">`)
+			text(t.Lexeme)
+			b.WriteString("</span>")
+			continue
+		}
+		color, ok := ansiTokenColors[t.Type]
+		if !ok {
+			b.WriteString(t.Lexeme)
+			continue
+		}
+		// Un comentario o cadena de varias líneas se colorea línea por
+		// línea: así un cliente que corta la salida por líneas no arrastra
+		// el color a las siguientes
+		for i, line := range strings.Split(t.Lexeme, "\n") {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			if line != "" {
+				b.WriteString(color + line + ansiReset)
+			}
+		}
+	}
+	text(code[pos:])
+	return b.String()
+}
+
+// highlightHandler recibe un AnalyzeRequest; sin format responde HTML
+func highlightHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = highlightHTML
+	}
+	contentType, ok := highlightContentTypes[format]
+	if !ok {
+		httpError(w, "format must be html or ansi", http.StatusBadRequest)
+		return
+	}
+
+	var req AnalyzeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w)
+		return
+	}
+	if req.Code == "" {
+		httpError(w, "Code is required", http.StatusBadRequest)
+		return
+	}
+
+	language := mapLanguage(req.Language)
+	if language == "" {
+		language = DetectLanguage(req.Code)
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Write([]byte(highlightCode(req.Code, language, format)))
+}
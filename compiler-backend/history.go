@@ -0,0 +1,344 @@
+package main
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// ───────────────────────── Historial de análisis ─────────────────────────
+//
+// Cada análisis enviado a /api/v1/analyze o /api/v1/analyze/stream se
+// registra en una base SQLite (GlobalConfig.HistoryDB) con el hash del
+// código, el lenguaje, los errores por fase, la duración y la fecha; el
+// código en sí no se guarda. El panel de la clase consulta el progreso con
+//
+//   GET /api/v1/history?language=cpp&since=2024-03-01T00:00:00Z&errors=true
+//
+// Los cambios de las sesiones no se registran: llegan con cada edición del
+// editor y no son envíos del estudiante. Cada análisis guarda su espacio de
+// trabajo (ver workspaces.go): una petición dentro de un espacio solo ve los
+// suyos, una fuera de todos los que no tienen espacio, y un admin puede
+// elegir cualquiera con workspace.
+
+const historySchema = `
+CREATE TABLE IF NOT EXISTS analyses (
+	id              INTEGER PRIMARY KEY AUTOINCREMENT,
+	code_hash       TEXT    NOT NULL,
+	language        TEXT    NOT NULL,
+	lexical_errors  INTEGER NOT NULL,
+	syntax_errors   INTEGER NOT NULL,
+	semantic_errors INTEGER NOT NULL,
+	warnings        INTEGER NOT NULL,
+	can_execute     INTEGER NOT NULL,
+	execution_ok    INTEGER,
+	duration_ms     REAL    NOT NULL,
+	cached          INTEGER NOT NULL,
+	created_at      INTEGER NOT NULL,
+	workspace       TEXT    NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS analyses_created_at ON analyses(created_at);
+CREATE INDEX IF NOT EXISTS analyses_code_hash ON analyses(code_hash);`
+
+// Cantidad de registros por página si la consulta no indica limit, y máximo
+const (
+	defaultHistoryLimit = 100
+	maxHistoryLimit     = 1000
+)
+
+// HistoryEntry es un análisis registrado; ExecutionOk es nil si no se ejecutó
+type HistoryEntry struct {
+	ID             int64     `json:"id"`
+	CodeHash       string    `json:"codeHash"`
+	Language       string    `json:"language"`
+	LexicalErrors  int       `json:"lexicalErrors"`
+	SyntaxErrors   int       `json:"syntaxErrors"`
+	SemanticErrors int       `json:"semanticErrors"`
+	Warnings       int       `json:"warnings"`
+	CanExecute     bool      `json:"canExecute"`
+	ExecutionOk    *bool     `json:"executionOk,omitempty"`
+	DurationMs     float64   `json:"durationMs"`
+	Cached         bool      `json:"cached"`
+	Timestamp      time.Time `json:"timestamp"`
+	Workspace      string    `json:"workspace,omitempty"`
+}
+
+// HistoryFilter son los filtros de GET /api/v1/history; los campos vacíos
+// no filtran
+type HistoryFilter struct {
+	Language  string
+	CodeHash  string
+	Since     time.Time
+	Until     time.Time
+	HasErrors *bool
+	Limit     int
+	Offset    int
+	// nil no filtra por espacio de trabajo; "" son los análisis fuera de
+	// todos
+	Workspace *string
+}
+
+// Respuesta de GET /api/v1/history; Total cuenta todos los registros que
+// cumplen el filtro, no solo los de la página
+type APIHistoryResponse struct {
+	Entries []HistoryEntry `json:"entries"`
+	Total   int            `json:"total"`
+}
+
+type historyStore struct {
+	db *sql.DB
+}
+
+// history es nil si el historial está desactivado
+var history *historyStore
+
+// openHistory abre (o crea) la base del historial
+func openHistory(path string) (*historyStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite admite un solo escritor: una conexión evita errores SQLITE_BUSY
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(historySchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	// Las bases creadas antes de los espacios de trabajo no tienen la columna
+	_, err = db.Exec("ALTER TABLE analyses ADD COLUMN workspace TEXT NOT NULL DEFAULT ''")
+	if err != nil && !strings.Contains(err.Error(), "duplicate column") {
+		db.Close()
+		return nil, err
+	}
+	return &historyStore{db: db}, nil
+}
+
+// newHistoryEntry resume un análisis para el historial
+func newHistoryEntry(code string, result AnalyzeResponse, p *Principal) HistoryEntry {
+	sum := sha256.Sum256([]byte(code))
+	entry := HistoryEntry{
+		CodeHash:   hex.EncodeToString(sum[:]),
+		Language:   result.Language,
+		CanExecute: result.Executability.Allowed,
+		DurationMs: float64(result.ProcessingTime) / float64(time.Millisecond),
+		Cached:     result.Cached,
+		Timestamp:  time.Now().UTC(),
+	}
+	if p != nil {
+		entry.Workspace = p.Workspace
+	}
+	// Los contadores de AnalysisPhases incluyen las advertencias; aquí se
+	// separan para que un programa correcto con advertencias cuente sin errores
+	for _, err := range result.Errors {
+		switch {
+		case err.Severity == "warning":
+			entry.Warnings++
+		case err.Type == "lexico":
+			entry.LexicalErrors++
+		case err.Type == "sintactico":
+			entry.SyntaxErrors++
+		case err.Type == "semantico":
+			entry.SemanticErrors++
+		}
+	}
+	if result.ExecutionResult != nil {
+		ok := result.ExecutionResult.Ok
+		entry.ExecutionOk = &ok
+	}
+	return entry
+}
+
+// recordAnalysis registra el análisis del usuario p sin demorar la
+// respuesta; un fallo de la base solo se informa en el log
+func recordAnalysis(code string, result AnalyzeResponse, p *Principal) {
+	if history == nil {
+		return
+	}
+	entry := newHistoryEntry(code, result, p)
+	go func() {
+		if err := history.insert(entry); err != nil {
+			log.Printf("historial: no se pudo registrar el análisis: %v", err)
+		}
+	}()
+}
+
+func (h *historyStore) insert(e HistoryEntry) error {
+	var executionOk interface{}
+	if e.ExecutionOk != nil {
+		executionOk = *e.ExecutionOk
+	}
+	_, err := h.db.Exec(`INSERT INTO analyses (code_hash, language, lexical_errors, syntax_errors,
+		semantic_errors, warnings, can_execute, execution_ok, duration_ms, cached, created_at, workspace)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.CodeHash, e.Language, e.LexicalErrors, e.SyntaxErrors, e.SemanticErrors, e.Warnings,
+		e.CanExecute, executionOk, e.DurationMs, e.Cached, e.Timestamp.UnixMilli(), e.Workspace)
+	return err
+}
+
+// query devuelve una página de registros, del más reciente al más antiguo,
+// y el total de registros que cumplen el filtro
+func (h *historyStore) query(f HistoryFilter) ([]HistoryEntry, int, error) {
+	var conds []string
+	var args []interface{}
+	if f.Language != "" {
+		conds = append(conds, "language = ?")
+		args = append(args, f.Language)
+	}
+	if f.CodeHash != "" {
+		conds = append(conds, "code_hash = ?")
+		args = append(args, strings.ToLower(f.CodeHash))
+	}
+	if f.Workspace != nil {
+		conds = append(conds, "workspace = ?")
+		args = append(args, *f.Workspace)
+	}
+	if !f.Since.IsZero() {
+		conds = append(conds, "created_at >= ?")
+		args = append(args, f.Since.UnixMilli())
+	}
+	if !f.Until.IsZero() {
+		conds = append(conds, "created_at < ?")
+		args = append(args, f.Until.UnixMilli())
+	}
+	if f.HasErrors != nil {
+		cond := "lexical_errors + syntax_errors + semantic_errors > 0"
+		if !*f.HasErrors {
+			cond = "lexical_errors + syntax_errors + semantic_errors = 0"
+		}
+		conds = append(conds, cond)
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	var total int
+	if err := h.db.QueryRow("SELECT COUNT(*) FROM analyses"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := h.db.Query(`SELECT id, code_hash, language, lexical_errors, syntax_errors, semantic_errors,
+		warnings, can_execute, execution_ok, duration_ms, cached, created_at, workspace FROM analyses`+where+
+		" ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?", append(args, f.Limit, f.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := []HistoryEntry{}
+	for rows.Next() {
+		var e HistoryEntry
+		var executionOk sql.NullBool
+		var createdAt int64
+		if err := rows.Scan(&e.ID, &e.CodeHash, &e.Language, &e.LexicalErrors, &e.SyntaxErrors,
+			&e.SemanticErrors, &e.Warnings, &e.CanExecute, &executionOk, &e.DurationMs, &e.Cached, &createdAt, &e.Workspace); err != nil {
+			return nil, 0, err
+		}
+		if executionOk.Valid {
+			e.ExecutionOk = &executionOk.Bool
+		}
+		e.Timestamp = time.UnixMilli(createdAt).UTC()
+		entries = append(entries, e)
+	}
+	return entries, total, rows.Err()
+}
+
+// parseHistoryFilter lee los filtros de la query string: language, hash,
+// since y until (RFC 3339 o AAAA-MM-DD), errors (true/false), limit,
+// offset y, para un admin fuera de un espacio, workspace
+func parseHistoryFilter(r *http.Request) (HistoryFilter, string) {
+	q := r.URL.Query()
+	f := HistoryFilter{
+		CodeHash: q.Get("hash"),
+		Limit:    defaultHistoryLimit,
+	}
+	p := principalFrom(r)
+	switch {
+	case p != nil && p.Workspace != "":
+		f.Workspace = &p.Workspace
+	case p != nil && p.Admin:
+		if q.Has("workspace") {
+			ws := q.Get("workspace")
+			f.Workspace = &ws
+		}
+	default:
+		none := ""
+		f.Workspace = &none
+	}
+	if lang := q.Get("language"); lang != "" {
+		f.Language = mapLanguage(lang)
+	}
+	for _, bound := range []struct {
+		name string
+		dst  *time.Time
+	}{{"since", &f.Since}, {"until", &f.Until}} {
+		v := q.Get(bound.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			if t, err = time.Parse("2006-01-02", v); err != nil {
+				return f, bound.name + " must be an RFC 3339 date"
+			}
+		}
+		*bound.dst = t
+	}
+	if v := q.Get("errors"); v != "" {
+		hasErrors, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, "errors must be true or false"
+		}
+		f.HasErrors = &hasErrors
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return f, "limit must be positive"
+		}
+		if n > maxHistoryLimit {
+			n = maxHistoryLimit
+		}
+		f.Limit = n
+	}
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return f, "offset must not be negative"
+		}
+		f.Offset = n
+	}
+	return f, ""
+}
+
+func historyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if history == nil {
+		httpError(w, "History is disabled", http.StatusServiceUnavailable)
+		return
+	}
+	filter, msg := parseHistoryFilter(r)
+	if msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	entries, total, err := history.query(filter)
+	if err != nil {
+		log.Printf("historial: error en la consulta: %v", err)
+		httpError(w, "History query failed", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(APIHistoryResponse{Entries: entries, Total: total})
+}
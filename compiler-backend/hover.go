@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// ─────────────────────────── Información al pasar el mouse ────────────────
+//
+// POST /api/v1/hover recibe el código y una posición (línea y columna base
+// 1, en caracteres, como las de los tokens) y devuelve lo que el editor
+// muestra en el tooltip del nombre que está ahí: su categoría, el tipo
+// inferido, el valor constante y dónde se declaró. Se buscan en la tabla de
+// símbolos del análisis semántico, que ya registra la declaración y cada uso
+// de cada símbolo; no se ejecuta el código. Los nombres que el programa no
+// declara pero que son de la biblioteca del lenguaje (cout, print,
+// console.log) se responden con su documentación. Si en la posición no hay
+// un nombre conocido la respuesta es null.
+
+// HoverRequest es el cuerpo de POST /api/v1/hover
+type HoverRequest struct {
+	Code     string `json:"code"`
+	Language string `json:"language"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Locale   string `json:"locale,omitempty"`
+}
+
+// APIHover describe el nombre en la posición pedida. Declaration es nil en
+// los de la biblioteca del lenguaje; Token es el token que cubre la posición
+type APIHover struct {
+	Name          string       `json:"name"`
+	Kind          string       `json:"kind"`
+	Type          string       `json:"type,omitempty"`
+	Value         string       `json:"value,omitempty"`
+	Scope         string       `json:"scope,omitempty"`
+	Declaration   *APIPosition `json:"declaration,omitempty"`
+	Documentation string       `json:"documentation,omitempty"`
+	Builtin       bool         `json:"builtin"`
+	Token         APIToken     `json:"token"`
+}
+
+// builtinDoc documenta un nombre de la biblioteca de un lenguaje
+type builtinDoc struct {
+	Kind string
+	Type string // firma o tipo, como se escribe en el lenguaje
+	Doc  messageText
+}
+
+// Nombres de la biblioteca de cada lenguaje, con su calificación (std::,
+// console., fmt.) si la lleva; los de Pascal van en minúsculas
+var builtinDocs = map[string]map[string]builtinDoc{
+	"cpp": {
+		"std::cout": {"object", "std::ostream", messageText{ES: "Salida estándar; se escribe con <<: std::cout << x << std::endl;", EN: "Standard output; write to it with <<: std::cout << x << std::endl;"}},
+		"std::cin":  {"object", "std::istream", messageText{ES: "Entrada estándar; se lee con >>: std::cin >> x;", EN: "Standard input; read from it with >>: std::cin >> x;"}},
+		"std::cerr": {"object", "std::ostream", messageText{ES: "Salida de errores, sin búfer", EN: "Unbuffered standard error"}},
+		"std::endl": {"function", "std::ostream& endl(std::ostream&)", messageText{ES: "Escribe un salto de línea y vacía el búfer de la salida", EN: "Writes a newline and flushes the stream"}},
+		"printf":    {"function", "int printf(const char* format, ...)", messageText{ES: "Escribe en la salida estándar según el formato (%d, %f, %s...)", EN: "Writes to standard output according to the format (%d, %f, %s...)"}},
+		"scanf":     {"function", "int scanf(const char* format, ...)", messageText{ES: "Lee de la entrada estándar según el formato; recibe las direcciones de las variables", EN: "Reads from standard input according to the format; takes the addresses of the variables"}},
+	},
+	"python": {
+		"print": {"function", "print(*values, sep=' ', end='\\n')", messageText{ES: "Escribe los valores separados por sep y termina con end", EN: "Writes the values separated by sep, followed by end"}},
+		"input": {"function", "input(prompt='') -> str", messageText{ES: "Lee una línea de la entrada estándar, sin el salto de línea", EN: "Reads a line from standard input, without the newline"}},
+		"len":   {"function", "len(obj) -> int", messageText{ES: "Cantidad de elementos de una secuencia o colección", EN: "Number of items in a sequence or collection"}},
+		"range": {"class", "range(start, stop, step=1)", messageText{ES: "Secuencia de enteros de start a stop, sin incluir stop", EN: "Sequence of integers from start up to, but not including, stop"}},
+		"int":   {"class", "int(x=0) -> int", messageText{ES: "Convierte un número o texto a entero", EN: "Converts a number or string to an integer"}},
+		"str":   {"class", "str(obj='') -> str", messageText{ES: "Convierte un valor a texto", EN: "Converts a value to a string"}},
+		"float": {"class", "float(x=0.0) -> float", messageText{ES: "Convierte un número o texto a punto flotante", EN: "Converts a number or string to a floating-point number"}},
+	},
+	"javascript": {
+		"console.log":   {"function", "console.log(...data: any[]): void", messageText{ES: "Escribe los valores en la consola, separados por espacios", EN: "Writes the values to the console, separated by spaces"}},
+		"console.error": {"function", "console.error(...data: any[]): void", messageText{ES: "Escribe los valores en la salida de errores", EN: "Writes the values to standard error"}},
+		"console.warn":  {"function", "console.warn(...data: any[]): void", messageText{ES: "Escribe una advertencia en la salida de errores", EN: "Writes a warning to standard error"}},
+		"parseInt":      {"function", "parseInt(string: string, radix?: number): number", messageText{ES: "Convierte el texto a entero en la base radix; NaN si no empieza con un número", EN: "Parses the string as an integer in base radix; NaN if it does not start with a number"}},
+		"parseFloat":    {"function", "parseFloat(string: string): number", messageText{ES: "Convierte el texto a número; NaN si no empieza con un número", EN: "Parses the string as a number; NaN if it does not start with a number"}},
+	},
+	"go": {
+		"fmt.Println": {"function", "func Println(a ...any) (n int, err error)", messageText{ES: "Escribe los valores separados por espacios y un salto de línea", EN: "Writes the values separated by spaces, followed by a newline"}},
+		"fmt.Printf":  {"function", "func Printf(format string, a ...any) (n int, err error)", messageText{ES: "Escribe según el formato (%d, %s, %v...)", EN: "Writes according to the format (%d, %s, %v...)"}},
+		"fmt.Sprintf": {"function", "func Sprintf(format string, a ...any) string", messageText{ES: "Devuelve el texto con el formato aplicado", EN: "Returns the formatted string"}},
+		"len":         {"function", "func len(v Type) int", messageText{ES: "Longitud de un string, slice, arreglo, mapa o canal", EN: "Length of a string, slice, array, map or channel"}},
+		"append":      {"function", "func append(slice []Type, elems ...Type) []Type", messageText{ES: "Agrega elementos al final del slice y devuelve el slice resultante", EN: "Appends elements to the end of the slice and returns the resulting slice"}},
+	},
+	"pascal": {
+		"writeln": {"procedure", "procedure WriteLn(args...)", messageText{ES: "Escribe los argumentos y un salto de línea", EN: "Writes the arguments followed by a newline"}},
+		"write":   {"procedure", "procedure Write(args...)", messageText{ES: "Escribe los argumentos sin salto de línea", EN: "Writes the arguments without a newline"}},
+		"readln":  {"procedure", "procedure ReadLn(var args...)", messageText{ES: "Lee valores de una línea de la entrada y descarta el resto", EN: "Reads values from a line of input and discards the rest"}},
+	},
+}
+
+func init() {
+	// TypeScript comparte la biblioteca de JavaScript
+	builtinDocs["typescript"] = builtinDocs["javascript"]
+}
+
+// qualifiers separan un nombre de su calificación (std::cout, console.log)
+var qualifiers = map[string]bool{".": true, "::": true}
+
+// Tokens que pueden ser parte de un nombre calificado
+var nameTokens = map[TokenType]bool{KEYWORD: true, IDENTIFIER: true, FUNCTION: true, CLASS: true, VARIABLE: true, CONSTANT: true}
+
+// hoverAt devuelve la información del nombre que cubre line:column, o nil
+func hoverAt(code, language string, line, column int, locale string) *APIHover {
+	tokens := Tokenize(code, language)
+	i := tokenAt(tokens, line, column)
+	if i < 0 {
+		return nil
+	}
+	t := tokens[i]
+	src := newSourceIndex(code)
+	token := convertToAPITokens(tokens[i:i+1], src)[0]
+
+	tree, _ := NewParser(tokens, language, code).Parse()
+	syms, _ := NewSemanticAnalyzer(tokens, tree, language).Analyze()
+	for k, sym := range syms {
+		if sym.Pos != t.Start && !slices.Contains(sym.References, t.Start) {
+			continue
+		}
+		s := convertToAPISymbols(syms[k:k+1], src)[0]
+		return &APIHover{
+			Name:        s.Name,
+			Kind:        s.Category,
+			Type:        s.Type,
+			Value:       s.Value,
+			Scope:       s.Scope,
+			Declaration: &APIPosition{Line: s.Line, Column: s.Column, Position: s.Position},
+			Token:       token,
+		}
+	}
+
+	docs := builtinDocs[language]
+	for _, name := range qualifiedNames(tokens, i, language) {
+		if language == "pascal" {
+			name = strings.ToLower(name)
+		}
+		if doc, ok := docs[name]; ok {
+			text := doc.Doc.ES
+			if locale == "en" {
+				text = doc.Doc.EN
+			}
+			return &APIHover{Name: name, Kind: doc.Kind, Type: doc.Type, Documentation: text, Builtin: true, Token: token}
+		}
+	}
+	return nil
+}
+
+// tokenAt devuelve el índice del nombre que cubre line:column, o -1; el
+// nombre que termina justo antes también cuenta, porque el cursor del
+// editor suele quedar al final de la palabra (x| o x|;)
+func tokenAt(tokens []Token, line, column int) int {
+	before := func(l1, c1, l2, c2 int) bool { return l1 < l2 || (l1 == l2 && c1 < c2) }
+	found := -1
+	for i, t := range tokens {
+		if before(line, column, t.Line, t.Column) {
+			break
+		}
+		if !nameTokens[t.Type] {
+			continue
+		}
+		if before(line, column, t.EndLine, t.EndColumn) {
+			return i
+		}
+		if line == t.EndLine && column == t.EndColumn {
+			found = i
+		}
+	}
+	return found
+}
+
+// qualifiedNames devuelve el nombre del token i con su calificación
+// completa (std::cout, console.log) y luego sin cada calificador, del más
+// largo al más corto
+func qualifiedNames(tokens []Token, i int, language string) []string {
+	start := i
+	for start >= 2 && qualifiers[tokens[start-1].Lexeme] && nameTokens[tokens[start-2].Type] {
+		start -= 2
+	}
+	var names []string
+	for j := start; j <= i; j += 2 {
+		var b strings.Builder
+		for _, t := range tokens[j : i+1] {
+			b.WriteString(t.Lexeme)
+		}
+		names = append(names, b.String())
+	}
+	// En C++ es común escribir cout con using namespace std
+	if language == "cpp" && len(names) == 1 {
+		names = append(names, "std::"+names[0])
+	}
+	return names
+}
+
+// hoverHandler atiende POST /api/v1/hover
+func hoverHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req HoverRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w)
+		return
+	}
+	if req.Code == "" {
+		httpError(w, "Code is required", http.StatusBadRequest)
+		return
+	}
+	if req.Line < 1 || req.Column < 1 {
+		httpError(w, "line and column are required and start at 1", http.StatusBadRequest)
+		return
+	}
+	if msg := invalidLocale(req.Locale); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	language := mapLanguage(req.Language)
+	if language == "" {
+		language = DetectLanguage(req.Code)
+	}
+	if status, msg := authorizeAnalysis(principalFrom(r), language, false); status != 0 {
+		rejectAnalysis(w, status, msg)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hoverAt(req.Code, language, req.Line, req.Column, requestLocale(req.Locale, r)))
+}
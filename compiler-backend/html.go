@@ -0,0 +1,563 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ──────────────────────────────── HTML ───────────────────────────────────
+//
+// En HTML el significado de un carácter depende de si está dentro de una
+// etiqueta o en el texto entre etiquetas. Los reconocedores siguen sin
+// estado (la re-tokenización incremental puede empezar en cualquier línea):
+// el texto es lo que sigue a un '>' o al comienzo del documento, y un valor
+// sin comillas es lo que sigue a un '='. El contenido de <script> y <style>
+// es un único token de texto hasta su etiqueta de cierre.
+//
+// El parser (parser_html.go) arma el árbol de elementos con una pila de
+// etiquetas abiertas; el análisis semántico revisa atributos obligatorios,
+// elementos obsoletos e ids.
+
+// ───────────────────────────────── Lexer ─────────────────────────────────
+
+var htmlPatterns = struct {
+	TagOpen, Attribute, Unquoted, Quoted *regexp.Regexp
+}{
+	// <div o </div: el nombre de la etiqueta incluye su '<'
+	TagOpen:   regexp.MustCompile(`^</?[a-zA-Z][\w:.-]*`),
+	Attribute: regexp.MustCompile(`^[^\s"'<>/=]+`),
+	Unquoted:  regexp.MustCompile("^[^\\s\"'=<>`]+"),
+	// Un valor entre comillas puede ocupar varias líneas (class, style)
+	Quoted: regexp.MustCompile(`^(?:"[^"]*"|'[^']*')`),
+}
+
+// htmlPrevByte devuelve el último carácter que no es espacio antes de p
+// (0 al comienzo del documento) y su posición
+func htmlPrevByte(s string, p int) (byte, int) {
+	i := p - 1
+	for i >= 0 && (s[i] == ' ' || s[i] == '\t' || s[i] == '\n' || s[i] == '\r') {
+		i--
+	}
+	if i < 0 {
+		return 0, i
+	}
+	return s[i], i
+}
+
+// htmlText reconoce el texto entre etiquetas, que llega hasta la siguiente
+// etiqueta, comentario o doctype ('<' seguido de letra, '/' o '!')
+func htmlText(_ *LanguagePatterns, s string, p int) (TokenType, string) {
+	prev, at := htmlPrevByte(s, p)
+	if prev != 0 && prev != '>' {
+		return UNKNOWN, ""
+	}
+	end := len(s)
+	if raw := htmlRawTextTag(s, at); raw != "" {
+		if i := strings.Index(strings.ToLower(s[p:]), "</"+raw); i >= 0 {
+			end = p + i
+		}
+	} else {
+		for i := p; i < len(s)-1; i++ {
+			if c := s[i+1]; s[i] == '<' && (c == '/' || c == '!' || c < 0x80 && unicode.IsLetter(rune(c))) {
+				end = i
+				break
+			}
+		}
+	}
+	lex := strings.TrimRightFunc(s[p:end], unicode.IsSpace)
+	if lex == "" {
+		return UNKNOWN, ""
+	}
+	return STRING, lex
+}
+
+// htmlRawTextTag devuelve "script" o "style" si el '>' en la posición gt
+// cierra esa etiqueta de apertura, cuyo contenido no es HTML
+func htmlRawTextTag(s string, gt int) string {
+	if gt < 0 {
+		return ""
+	}
+	lt := strings.LastIndexByte(s[:gt], '<')
+	if lt < 0 {
+		return ""
+	}
+	name := strings.ToLower(htmlPatterns.TagOpen.FindString(s[lt:gt]))
+	switch name {
+	case "<script", "<style":
+		return name[1:]
+	}
+	return ""
+}
+
+func htmlTagOpen(_ *LanguagePatterns, s string, p int) (TokenType, string) {
+	if lex, ok := matchHere(htmlPatterns.TagOpen, s, p); ok {
+		return KEYWORD, lex
+	}
+	return UNKNOWN, ""
+}
+
+// htmlUnquoted reconoce el valor sin comillas de un atributo: width=100
+func htmlUnquoted(_ *LanguagePatterns, s string, p int) (TokenType, string) {
+	if prev, _ := htmlPrevByte(s, p); prev != '=' {
+		return UNKNOWN, ""
+	}
+	if lex, ok := matchHere(htmlPatterns.Unquoted, s, p); ok {
+		return STRING, lex
+	}
+	return UNKNOWN, ""
+}
+
+func htmlQuoted(_ *LanguagePatterns, s string, p int) (TokenType, string) {
+	if lex, ok := matchHere(htmlPatterns.Quoted, s, p); ok {
+		return STRING, lex
+	}
+	return UNKNOWN, ""
+}
+
+func htmlAttribute(_ *LanguagePatterns, s string, p int) (TokenType, string) {
+	if lex, ok := matchHere(htmlPatterns.Attribute, s, p); ok {
+		return IDENTIFIER, lex
+	}
+	return UNKNOWN, ""
+}
+
+// El texto va antes que las cadenas: "Hola" entre etiquetas es texto
+var htmlOrder = []matcher{whitespace, comment, keyword, htmlText, htmlTagOpen, htmlQuoted, htmlUnquoted, oper, delim, htmlAttribute}
+
+func init() {
+	RegisterLanguage(&languageDef{
+		name:     "html",
+		patterns: LanguageSpecificPatterns["html"],
+		matchers: htmlOrder,
+		parse:    (*Parser).parseHTMLProgram,
+		// Atributos, elementos obsoletos e ids en lugar de variables
+		analyze: (*SemanticAnalyzer).analyzeHTML,
+	})
+}
+
+// ─────────────────────────────── Elementos ───────────────────────────────
+
+// Elementos vacíos: no tienen contenido ni etiqueta de cierre
+var htmlVoidElements = makeSet(strings.Fields(
+	"area base br col embed hr img input link meta param source track wbr"))
+
+// Elementos cuya etiqueta de cierre es opcional
+var htmlOptionalEnd = makeSet(strings.Fields(
+	"html head body p li dt dd option optgroup tr td th thead tbody tfoot colgroup caption rp rt"))
+
+// htmlAutoClose indica qué etiquetas de apertura cierran implícitamente al
+// elemento abierto: un <li> cierra el <li> anterior y un <div> cierra un <p>
+var htmlAutoClose = map[string]map[string]bool{
+	"p": makeSet(strings.Fields(`address article aside blockquote details div dl fieldset
+		figcaption figure footer form h1 h2 h3 h4 h5 h6 header hr main menu nav ol p pre section table ul`)),
+	"li":       {"li": true},
+	"dt":       {"dt": true, "dd": true},
+	"dd":       {"dt": true, "dd": true},
+	"option":   {"option": true, "optgroup": true},
+	"optgroup": {"optgroup": true},
+	"tr":       {"tr": true, "tbody": true, "tfoot": true},
+	"td":       {"td": true, "th": true, "tr": true, "tbody": true, "tfoot": true},
+	"th":       {"td": true, "th": true, "tr": true, "tbody": true, "tfoot": true},
+	"thead":    {"tbody": true, "tfoot": true},
+	"tbody":    {"tbody": true, "tfoot": true},
+	"head":     {"body": true},
+}
+
+// Elementos obsoletos en HTML5 y qué usar en su lugar
+var htmlDeprecated = map[string]string{
+	"acronym":  "<abbr>",
+	"applet":   "<object> o <embed>",
+	"basefont": "CSS (font)",
+	"big":      "CSS (font-size)",
+	"blink":    "CSS (animation)",
+	"center":   "CSS (text-align: center)",
+	"dir":      "<ul>",
+	"font":     "CSS (font-family, color)",
+	"frame":    "<iframe>",
+	"frameset": "<iframe> o CSS",
+	"isindex":  "<form> con <input>",
+	"marquee":  "CSS (animation)",
+	"noframes": "contenido normal",
+	"strike":   "<del> o <s>",
+	"tt":       "<code> o CSS (font-family: monospace)",
+}
+
+// Atributos sin los cuales el elemento no cumple su función o no es
+// accesible
+var htmlRequiredAttributes = map[string][]string{
+	"img":    {"src", "alt"},
+	"a":      {"href"},
+	"link":   {"rel", "href"},
+	"iframe": {"src", "title"},
+	"html":   {"lang"},
+}
+
+// Atributos cuyo valor nombra ids del documento
+var htmlIDReferences = map[string]bool{
+	"for": true, "list": true, "form": true, "aria-labelledby": true,
+	"aria-describedby": true, "aria-controls": true,
+}
+
+// ─────────────────────────────── Semántica ───────────────────────────────
+
+// analyzeHTML revisa los elementos del árbol y registra los ids como
+// símbolos, con las referencias de href="#id", for="id" y aria-*
+func (s *SemanticAnalyzer) analyzeHTML() ([]Symbol, []CompilerError) {
+	var syms []Symbol
+	var errors []CompilerError
+	ids := make(map[string]int) // id → posición en syms
+	type reference struct {
+		id, attr string
+		pos      int
+	}
+	var refs []reference
+	warn := func(pos int, code, format string, args ...any) {
+		errors = append(errors, CompilerError{
+			Message:  "Error semántico: " + fmt.Sprintf(format, args...),
+			Severity: "warning",
+			Type:     "semantico",
+			Pos:      pos,
+			Code:     code,
+		})
+	}
+
+	var walk func(n ParseNode)
+	walk = func(n ParseNode) {
+		if n.Kind == "Element" {
+			if alt, ok := htmlDeprecated[n.Label]; ok {
+				warn(n.Pos, CodeDeprecatedElement, "El elemento <%s> es obsoleto en HTML5; usa %s", n.Label, alt)
+			}
+			attrs := make(map[string]bool)
+			for _, a := range n.Children {
+				if a.Kind != "Attribute" {
+					continue
+				}
+				if attrs[a.Label] {
+					errors = append(errors, CompilerError{
+						Message:  fmt.Sprintf("Error semántico: El atributo '%s' se repite en <%s>", a.Label, n.Label),
+						Severity: "error",
+						Type:     "semantico",
+						Pos:      a.Pos,
+						Code:     CodeRedeclaredVariable,
+					})
+				}
+				attrs[a.Label] = true
+				if len(a.Children) == 0 {
+					continue
+				}
+				value := a.Children[0]
+				switch {
+				case a.Label == "id":
+					if i, ok := ids[value.Label]; ok {
+						errors = append(errors, CompilerError{
+							Message:  fmt.Sprintf("Error semántico: El id '%s' se repite; debe ser único en el documento", value.Label),
+							Severity: "error",
+							Type:     "semantico",
+							Pos:      value.Pos,
+							Code:     CodeRedeclaredVariable,
+						})
+						syms[i].References = append(syms[i].References, value.Pos)
+						continue
+					}
+					ids[value.Label] = len(syms)
+					syms = append(syms, Symbol{Name: value.Label, Kind: "id", Type: n.Label, Pos: value.Pos})
+				case a.Label == "href" && strings.HasPrefix(value.Label, "#") && len(value.Label) > 1:
+					refs = append(refs, reference{value.Label[1:], a.Label, value.Pos})
+				case htmlIDReferences[a.Label]:
+					for _, id := range strings.Fields(value.Label) {
+						refs = append(refs, reference{id, a.Label, value.Pos})
+					}
+				}
+			}
+			for _, required := range htmlRequiredAttributes[n.Label] {
+				if !attrs[required] {
+					warn(n.Pos, CodeMissingAttribute, "Falta el atributo '%s' en <%s>", required, n.Label)
+				}
+			}
+		}
+		for _, c := range n.Children {
+			walk(c)
+		}
+	}
+	for _, n := range s.tree {
+		walk(n)
+	}
+
+	// Un id puede usarse antes de declararse: las referencias se resuelven
+	// con el documento completo
+	for _, r := range refs {
+		if i, ok := ids[r.id]; ok {
+			syms[i].References = append(syms[i].References, r.pos)
+			continue
+		}
+		if r.id == "top" && r.attr == "href" {
+			// href="#top" vuelve al comienzo aunque no exista el id
+			continue
+		}
+		warn(r.pos, CodeUndeclaredVariable, "El id '%s' referenciado en %s no existe en el documento", r.id, r.attr)
+	}
+	for i := range syms {
+		sort.Ints(syms[i].References)
+	}
+	sort.SliceStable(errors, func(i, j int) bool { return errors[i].Pos < errors[j].Pos })
+	return syms, errors
+}
+
+// ──────────────────────────────── Ejecución ──────────────────────────────
+//
+// Un documento HTML no se ejecuta en el servidor: el frontend lo muestra. El
+// ejecutor devuelve el documento listo para mostrar (con doctype, <head> y
+// la codificación si faltaban; un fragmento se envuelve en un documento
+// completo), una versión para el atributo srcdoc de un <iframe> con
+// htmlSandbox y el resumen de la validación. La versión aislada agrega una
+// Content-Security-Policy que bloquea fetch, WebSocket y el envío de
+// formularios, y quita <base> y <meta http-equiv="refresh">; los recursos
+// https (bibliotecas de un CDN, imágenes) se siguen cargando.
+
+// Valor del atributo sandbox del <iframe> que muestra Sandboxed: scripts y
+// alert(), pero sin el origen del frontend, ventanas nuevas ni navegar la
+// página que lo contiene
+const htmlSandbox = "allow-scripts allow-modals"
+
+const htmlPreviewCSP = `<meta http-equiv="Content-Security-Policy" content="default-src 'none'; ` +
+	`script-src 'unsafe-inline' https:; style-src 'unsafe-inline' https:; img-src data: blob: https:; ` +
+	`font-src data: https:; media-src data: blob: https:; connect-src 'none'; form-action 'none'; base-uri 'none'">`
+
+// APIHTMLPreview es el documento que el frontend muestra en lugar de la
+// salida de un programa
+type APIHTMLPreview struct {
+	Title string `json:"title,omitempty"`
+	// El documento completo, para abrirlo o descargarlo
+	Document string `json:"document"`
+	// Para srcdoc de un <iframe sandbox="..."> con Sandbox
+	Sandboxed string `json:"sandboxed"`
+	Sandbox   string `json:"sandbox"`
+	Elements  int    `json:"elements"`
+	// Valid es false si el análisis encontró errores (no advertencias); el
+	// navegador lo muestra igual, corrigiéndolo a su manera
+	Valid    bool `json:"valid"`
+	Errors   int  `json:"errors"`
+	Warnings int  `json:"warnings"`
+	// Lo que se agregó en Document y lo que además se quitó en Sandboxed
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// htmlEdit reemplaza code[pos:end] por text
+type htmlEdit struct {
+	pos, end int
+	text     string
+}
+
+func applyHTMLEdits(code string, edits []htmlEdit) string {
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].pos < edits[j].pos })
+	var b strings.Builder
+	last := 0
+	for _, e := range edits {
+		b.WriteString(code[last:e.pos])
+		b.WriteString(e.text)
+		last = e.end
+	}
+	b.WriteString(code[last:])
+	return b.String()
+}
+
+// htmlStartTagEnd devuelve la posición que sigue al '>' de la etiqueta de
+// apertura de n
+func htmlStartTagEnd(code string, n ParseNode) int {
+	from := n.Pos + 1
+	for _, c := range n.Children {
+		if c.Kind == "Attribute" && c.End > from {
+			from = c.End
+		}
+	}
+	if i := strings.IndexByte(code[from:], '>'); i >= 0 {
+		return from + i + 1
+	}
+	return len(code)
+}
+
+// htmlAttr devuelve el valor del atributo name de n, en minúsculas
+func htmlAttr(n ParseNode, name string) (string, bool) {
+	for _, c := range n.Children {
+		if c.Kind == "Attribute" && c.Label == name {
+			if len(c.Children) == 0 {
+				return "", true
+			}
+			return strings.ToLower(strings.TrimSpace(c.Children[0].Label)), true
+		}
+	}
+	return "", false
+}
+
+// htmlPage es un documento ya analizado, con lo que le falta y lo que se
+// quita al aislarlo
+type htmlPage struct {
+	code                string
+	doctype, html, head *ParseNode
+	body                *ParseNode
+	headExtra           []string
+	removals            []htmlEdit
+	title               string
+	elements            int
+	errors, warnings    int
+	added, removed      []string
+}
+
+func newHTMLPage(code string) *htmlPage {
+	tokens := Tokenize(code, "html")
+	tree, syntaxErrors := NewParser(tokens, "html", code).Parse()
+	_, semanticErrors := NewSemanticAnalyzer(tokens, tree, "html").Analyze()
+	pg := &htmlPage{code: code}
+	for _, e := range append(syntaxErrors, semanticErrors...) {
+		if e.Severity == "warning" {
+			pg.warnings++
+		} else {
+			pg.errors++
+		}
+	}
+
+	hasCharset, hasViewport := false, false
+	var walk func(n *ParseNode)
+	walk = func(n *ParseNode) {
+		switch n.Kind {
+		case "Doctype":
+			pg.doctype = n
+		case "Element":
+			pg.elements++
+			switch n.Label {
+			case "html":
+				pg.html = n
+			case "head":
+				pg.head = n
+			case "body":
+				pg.body = n
+			case "title":
+				for _, c := range n.Children {
+					if c.Kind == "Text" && pg.title == "" {
+						pg.title = strings.TrimSpace(c.Label)
+					}
+				}
+			case "meta":
+				equiv, _ := htmlAttr(*n, "http-equiv")
+				name, _ := htmlAttr(*n, "name")
+				_, charset := htmlAttr(*n, "charset")
+				hasCharset = hasCharset || charset || equiv == "content-type"
+				hasViewport = hasViewport || name == "viewport"
+				if equiv == "refresh" {
+					pg.removals = append(pg.removals, htmlEdit{n.Pos, htmlStartTagEnd(code, *n), ""})
+					pg.removed = append(pg.removed, `<meta http-equiv="refresh">`)
+				}
+			case "base":
+				pg.removals = append(pg.removals, htmlEdit{n.Pos, htmlStartTagEnd(code, *n), ""})
+				pg.removed = append(pg.removed, "<base>")
+			}
+		}
+		for i := range n.Children {
+			walk(&n.Children[i])
+		}
+	}
+	for i := range tree {
+		walk(&tree[i])
+	}
+
+	if !hasCharset {
+		pg.headExtra = append(pg.headExtra, `<meta charset="utf-8">`)
+	}
+	if !hasViewport {
+		pg.headExtra = append(pg.headExtra, `<meta name="viewport" content="width=device-width, initial-scale=1">`)
+	}
+	switch {
+	case pg.fragment():
+		pg.added = append(pg.added, "<!DOCTYPE html>", "<html>, <head> y <body>")
+	case pg.doctype == nil:
+		pg.added = append(pg.added, "<!DOCTYPE html>")
+	}
+	pg.added = append(pg.added, pg.headExtra...)
+	if pg.head == nil && !pg.fragment() {
+		pg.added = append(pg.added, "<head>")
+	}
+	return pg
+}
+
+// fragment indica si el código es un fragmento sin <html>, <head> ni
+// <body>: el navegador lo pondría en <body>
+func (pg *htmlPage) fragment() bool {
+	return pg.html == nil && pg.head == nil && pg.body == nil
+}
+
+// render arma el documento completo; csp se agrega al comienzo de <head> y
+// con strip se quita lo que saldría del iframe
+func (pg *htmlPage) render(csp string, strip bool) string {
+	extra := pg.headExtra
+	if csp != "" {
+		extra = append([]string{csp}, extra...)
+	}
+	inHead := ""
+	for _, line := range extra {
+		inHead += "\n" + line
+	}
+	edits := []htmlEdit{}
+	if strip {
+		edits = append(edits, pg.removals...)
+	}
+	if pg.fragment() {
+		return "<!DOCTYPE html>\n<html lang=\"es\">\n<head>" + inHead + "\n</head>\n<body>\n" +
+			applyHTMLEdits(pg.code, edits) + "\n</body>\n</html>\n"
+	}
+	if pg.doctype == nil {
+		edits = append(edits, htmlEdit{0, 0, "<!DOCTYPE html>\n"})
+	}
+	switch {
+	case pg.head != nil:
+		at := htmlStartTagEnd(pg.code, *pg.head)
+		edits = append(edits, htmlEdit{at, at, inHead})
+	case pg.html != nil:
+		at := htmlStartTagEnd(pg.code, *pg.html)
+		edits = append(edits, htmlEdit{at, at, "\n<head>" + inHead + "\n</head>"})
+	default:
+		edits = append(edits, htmlEdit{pg.body.Pos, pg.body.Pos, "<head>" + inHead + "\n</head>\n"})
+	}
+	return applyHTMLEdits(pg.code, edits)
+}
+
+// preview devuelve el documento y su versión aislada con el resumen
+func (pg *htmlPage) preview() *APIHTMLPreview {
+	return &APIHTMLPreview{
+		Title:     pg.title,
+		Document:  pg.render("", false),
+		Sandboxed: pg.render(htmlPreviewCSP, true),
+		Sandbox:   htmlSandbox,
+		Elements:  pg.elements,
+		Valid:     pg.errors == 0,
+		Errors:    pg.errors,
+		Warnings:  pg.warnings,
+		Added:     pg.added,
+		Removed:   pg.removed,
+	}
+}
+
+// htmlExecutor "ejecuta" un documento HTML devolviéndolo para mostrarlo
+type htmlExecutor struct{}
+
+func (htmlExecutor) Execute(code string, _ []Symbol) ExecutionResult {
+	preview := newHTMLPage(code).preview()
+	var out strings.Builder
+	title := ""
+	if preview.Title != "" {
+		title = " «" + preview.Title + "»"
+	}
+	fmt.Fprintf(&out, "[html] Documento%s: %d elemento(s), %d error(es), %d advertencia(s)\n",
+		title, preview.Elements, preview.Errors, preview.Warnings)
+	if len(preview.Added) > 0 {
+		fmt.Fprintf(&out, "Se agregó: %s\n", strings.Join(preview.Added, ", "))
+	}
+	if len(preview.Removed) > 0 {
+		fmt.Fprintf(&out, "Se quitó en la vista aislada: %s\n", strings.Join(preview.Removed, ", "))
+	}
+	fmt.Fprintf(&out, "Vista previa: executionResult.html.sandboxed en <iframe sandbox=\"%s\" srcdoc=\"...\">\n", htmlSandbox)
+	return ExecutionResult{Output: out.String(), Ok: true, RunStdout: out.String(), HTML: preview}
+}
@@ -0,0 +1,442 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ──────────────────────── Importaciones y módulos ─────────────────────────
+//
+// Los #include de C++, los import de Python, JavaScript, TypeScript y Go y
+// los require('x') de Node se reconocen en los tokens. Cada uno aparece en
+// la tabla de símbolos (las cabeceras de C++ como "include", los nombres que
+// trae un import de Python o JavaScript como "import"; Go y TypeScript ya
+// registran los suyos) y su módulo se compara con el catálogo de la
+// biblioteca estándar del lenguaje: uno desconocido o mal escrito
+// (#include <iostrem>) es una advertencia SEM024, con la corrección si se
+// parece a uno del catálogo. Los módulos locales ("util.h", ./util, from .
+// import x) y los paquetes externos de Go (github.com/...) no se validan.
+
+// moduleImport es una importación: el módulo tal como se escribió y los
+// nombres que declara en el programa
+type moduleImport struct {
+	module string
+	pos    int // posición del nombre del módulo
+	local  bool
+	// Nombres que la importación declara (import math, from m import x as y)
+	bound []Token
+	// Posiciones de los identificadores de la sentencia que no son usos: la
+	// ruta del módulo y los nombres originales de los alias
+	names []int
+}
+
+// collectImports devuelve las importaciones de tokens en el orden del código
+func collectImports(tokens []Token, language string) []moduleImport {
+	switch language {
+	case "cpp":
+		return cppIncludes(tokens)
+	case "python":
+		return pythonImports(tokens)
+	case "javascript", "typescript":
+		return jsImports(tokens)
+	case "go":
+		return goImports(tokens)
+	}
+	return nil
+}
+
+func cppIncludes(tokens []Token) []moduleImport {
+	var imports []moduleImport
+	for _, tk := range tokens {
+		if tk.Type != PREPROCESSOR {
+			continue
+		}
+		d := ParseDirective(tk.Lexeme)
+		args := d.Args
+		if d.Name != "include" || len(args) < 2 {
+			continue
+		}
+		closing := map[byte]byte{'<': '>', '"': '"'}[args[0]]
+		end := strings.IndexByte(args[1:], closing)
+		if closing == 0 || end < 0 {
+			continue
+		}
+		name := args[1 : end+1]
+		imports = append(imports, moduleImport{
+			module: name,
+			pos:    tk.Start + strings.Index(tk.Lexeme, args) + 1,
+			local:  args[0] == '"',
+		})
+	}
+	return imports
+}
+
+// pythonImports reconoce import a.b as c, d y from .a import (x as y, z)
+func pythonImports(tokens []Token) []moduleImport {
+	var imports []moduleImport
+	// dotted lee a.b.c desde tokens[i] y devuelve el nombre, las posiciones
+	// de sus partes y el índice siguiente
+	dotted := func(i int) (string, []int, int) {
+		var parts []string
+		var positions []int
+		for i < len(tokens) && tokens[i].Type == IDENTIFIER {
+			parts = append(parts, tokens[i].Lexeme)
+			positions = append(positions, tokens[i].Start)
+			if i+2 < len(tokens) && tokens[i+1].Lexeme == "." && tokens[i+2].Type == IDENTIFIER {
+				i += 2
+				continue
+			}
+			i++
+			break
+		}
+		return strings.Join(parts, "."), positions, i
+	}
+	// alias lee "as nombre" si sigue en tokens[i]
+	alias := func(i int) (Token, int, bool) {
+		if i+1 < len(tokens) && tokens[i].Lexeme == "as" && tokens[i+1].Type == IDENTIFIER {
+			return tokens[i+1], i + 2, true
+		}
+		return Token{}, i, false
+	}
+	for i := 0; i < len(tokens); i++ {
+		tk := tokens[i]
+		if tk.Type != KEYWORD {
+			continue
+		}
+		switch tk.Lexeme {
+		case "import":
+			for j := i + 1; j < len(tokens); {
+				name, positions, next := dotted(j)
+				if name == "" {
+					break
+				}
+				imp := moduleImport{module: name, pos: positions[0], names: positions}
+				if as, after, ok := alias(next); ok {
+					imp.bound, next = []Token{as}, after
+				} else {
+					// import os.path declara os
+					imp.bound = []Token{tokens[j]}
+				}
+				imports = append(imports, imp)
+				i = next - 1
+				if next >= len(tokens) || tokens[next].Lexeme != "," {
+					break
+				}
+				j = next + 1
+			}
+		case "from":
+			j := i + 1
+			relative := false
+			for j < len(tokens) && (tokens[j].Lexeme == "." || tokens[j].Lexeme == "...") {
+				relative = true
+				j++
+			}
+			name, positions, next := dotted(j)
+			if next >= len(tokens) || tokens[next].Lexeme != "import" {
+				continue
+			}
+			imp := moduleImport{module: name, local: relative, names: positions}
+			if len(positions) > 0 {
+				imp.pos = positions[0]
+			} else {
+				imp.pos = tokens[j-1].Start
+			}
+			// Nombres separados por comas, entre paréntesis o no; * no
+			// declara nombres conocidos
+			j = next + 1
+			if j < len(tokens) && tokens[j].Lexeme == "(" {
+				j++
+			}
+			for j < len(tokens) && tokens[j].Type == IDENTIFIER {
+				name := tokens[j]
+				imp.names = append(imp.names, name.Start)
+				if as, after, ok := alias(j + 1); ok {
+					imp.bound = append(imp.bound, as)
+					j = after
+				} else {
+					imp.bound = append(imp.bound, name)
+					j++
+				}
+				if j >= len(tokens) || tokens[j].Lexeme != "," {
+					break
+				}
+				j++
+			}
+			imports = append(imports, imp)
+			i = j - 1
+		}
+	}
+	return imports
+}
+
+// jsImports reconoce import ... from "x", import "x", import("x"),
+// export ... from "x" y require("x")
+func jsImports(tokens []Token) []moduleImport {
+	var imports []moduleImport
+	module := func(tk Token) moduleImport {
+		name := strings.Trim(tk.Lexeme, "'\"`")
+		return moduleImport{
+			module: name,
+			pos:    tk.Start + 1,
+			local:  strings.HasPrefix(name, ".") || strings.HasPrefix(name, "/"),
+		}
+	}
+	isString := func(i int) bool { return i < len(tokens) && tokens[i].Type == STRING }
+	for i := 0; i < len(tokens); i++ {
+		tk := tokens[i]
+		switch {
+		case tk.Lexeme == "require" && tk.Type == IDENTIFIER && (i == 0 || tokens[i-1].Lexeme != ".") &&
+			i+1 < len(tokens) && tokens[i+1].Lexeme == "(" && isString(i+2):
+			imports = append(imports, module(tokens[i+2]))
+			i += 2
+		case tk.Lexeme == "from" && tk.Type == KEYWORD && isString(i+1):
+			// export { x } from "y": el import ya consumió su propio from.
+			// Los nombres que se reexportan son del otro módulo
+			imp := module(tokens[i+1])
+			for k := i - 1; k >= 0 && tokens[k].Lexeme != "export"; k-- {
+				if tokens[k].Type == IDENTIFIER {
+					imp.names = append(imp.names, tokens[k].Start)
+				}
+			}
+			imports = append(imports, imp)
+			i++
+		case tk.Lexeme == "import" && tk.Type == KEYWORD && (i == 0 || tokens[i-1].Lexeme != ".") &&
+			(i+1 >= len(tokens) || tokens[i+1].Lexeme != "."):
+			if i+1 < len(tokens) && tokens[i+1].Lexeme == "(" && isString(i+2) {
+				imports = append(imports, module(tokens[i+2]))
+				i += 2
+				continue
+			}
+			// Los nombres entre import y from; los seguidos de "as" son el
+			// nombre original de un alias
+			var bound []Token
+			var names []int
+			j := i + 1
+			for ; j < len(tokens) && !isString(j); j++ {
+				t := tokens[j]
+				if t.Lexeme == ";" || t.Lexeme == "=" || t.Type == KEYWORD && !jsImportWords[t.Lexeme] {
+					// import x = require("y") de TypeScript lo ve el caso de require
+					break
+				}
+				if t.Type != IDENTIFIER {
+					continue
+				}
+				names = append(names, t.Start)
+				if j+1 >= len(tokens) || tokens[j+1].Lexeme != "as" {
+					bound = append(bound, t)
+				}
+			}
+			if !isString(j) {
+				continue
+			}
+			imp := module(tokens[j])
+			imp.bound, imp.names = bound, names
+			imports = append(imports, imp)
+			i = j
+		}
+	}
+	return imports
+}
+
+// Palabras clave que pueden aparecer entre import y el módulo
+var jsImportWords = map[string]bool{"from": true, "as": true, "type": true, "typeof": true, "default": true}
+
+// goImports reconoce import "x" e import ( alias "x" ... ); los nombres de
+// paquete los registra el análisis de Go
+func goImports(tokens []Token) []moduleImport {
+	var imports []moduleImport
+	for i := 0; i < len(tokens); i++ {
+		if tokens[i].Lexeme != "import" || tokens[i].Type != KEYWORD {
+			continue
+		}
+		grouped := i+1 < len(tokens) && tokens[i+1].Lexeme == "("
+		for j := i + 1; j < len(tokens) && (grouped || j <= i+2); j++ {
+			t := tokens[j]
+			if t.Type == STRING {
+				name := strings.Trim(t.Lexeme, "\"`")
+				imports = append(imports, moduleImport{module: name, pos: t.Start + 1, local: strings.HasPrefix(name, ".")})
+				if !grouped {
+					i = j
+					break
+				}
+			}
+			if grouped && t.Lexeme == ")" {
+				i = j
+				break
+			}
+		}
+	}
+	return imports
+}
+
+// ──────────────────────── Validación de módulos ──────────────────────────
+
+// moduleCatalog es la biblioteca estándar de un lenguaje y cómo se
+// describen sus módulos en los mensajes
+type moduleCatalog struct {
+	names map[string]bool
+	// key devuelve el nombre que se busca en names ("" no se valida)
+	key func(module string) string
+	// Mensajes con el módulo mal escrito y sin parecido a ninguno
+	misspelled, unknown string
+}
+
+var moduleCatalogs = map[string]*moduleCatalog{
+	"cpp": {
+		names:      wordSet(cppHeaders),
+		key:        func(m string) string { return m },
+		misspelled: "Cabecera '<%s>' desconocida (¿quiso decir '<%s>'?)",
+		unknown:    "Cabecera '<%s>' desconocida: no es de la biblioteca estándar de C ni de C++",
+	},
+	"python": {
+		names: wordSet(pythonModules),
+		// Se valida el paquete: os en os.path
+		key:        func(m string) string { return strings.SplitN(m, ".", 2)[0] },
+		misspelled: "Módulo '%s' desconocido (¿quiso decir '%s'?)",
+		unknown:    "El módulo '%s' no es de la biblioteca estándar de Python; debe estar instalado para ejecutar el programa",
+	},
+	"javascript": {
+		names:      wordSet(nodeModules),
+		key:        nodeModuleKey,
+		misspelled: "Módulo '%s' desconocido (¿quiso decir '%s'?)",
+		unknown:    "El módulo '%s' no es un módulo integrado de Node.js; debe instalarse con npm para ejecutar el programa",
+	},
+	"go": {
+		names: wordSet(goPackages),
+		key: func(m string) string {
+			// Los paquetes externos empiezan con un dominio (github.com/...)
+			if strings.Contains(strings.SplitN(m, "/", 2)[0], ".") {
+				return ""
+			}
+			return m
+		},
+		misspelled: "Paquete '%s' desconocido (¿quiso decir '%s'?)",
+		unknown:    "El paquete '%s' no existe en la biblioteca estándar de Go",
+	},
+}
+
+// nodeModuleKey es el paquete de un import de JavaScript: fs en fs/promises,
+// @scope/pkg en @scope/pkg/sub; node:x siempre es un módulo integrado
+func nodeModuleKey(m string) string {
+	m = strings.TrimPrefix(m, "node:")
+	parts := strings.SplitN(m, "/", 3)
+	if strings.HasPrefix(m, "@") && len(parts) > 1 {
+		return parts[0] + "/" + parts[1]
+	}
+	return parts[0]
+}
+
+// checkImports advierte los módulos que no están en el catálogo del lenguaje
+func checkImports(imports []moduleImport, language string) []CompilerError {
+	catalog := moduleCatalogs[language]
+	if catalog == nil {
+		return nil
+	}
+	var errors []CompilerError
+	for _, imp := range imports {
+		key := catalog.key(imp.module)
+		if imp.local || key == "" || catalog.names[key] {
+			continue
+		}
+		err := CompilerError{
+			Message:  "Error semántico: " + fmt.Sprintf(catalog.unknown, imp.module),
+			Severity: "warning",
+			Type:     "semantico",
+			Pos:      imp.pos,
+			Code:     CodeUnknownModule,
+		}
+		if suggestion := moduleSuggestion(key, catalog.names); suggestion != "" {
+			fixed := strings.Replace(imp.module, key, suggestion, 1)
+			err.Message = "Error semántico: " + fmt.Sprintf(catalog.misspelled, imp.module, fixed)
+			err.Hint = "replace:" + fixed
+		}
+		errors = append(errors, err)
+	}
+	return errors
+}
+
+// moduleSuggestion devuelve el módulo del catálogo más parecido a name: a un
+// cambio de distancia, o a dos si el nombre es largo, para no confundir
+// paquetes externos cortos (attr) con otros del catálogo (stat)
+func moduleSuggestion(name string, names map[string]bool) string {
+	best, bestDist := "", 2
+	if len(name) >= 6 {
+		bestDist = 3
+	}
+	for candidate := range names {
+		if d := editDistance(strings.ToLower(name), candidate); d < bestDist || d == bestDist && best != "" && candidate < best {
+			best, bestDist = candidate, d
+		}
+	}
+	return best
+}
+
+// ─────────────────────────────── Catálogos ────────────────────────────────
+
+// Cabeceras de C++ (hasta C++23), de C y las POSIX más usadas
+const cppHeaders = "algorithm any array atomic barrier bit bitset charconv chrono codecvt compare complex " +
+	"concepts condition_variable coroutine deque exception execution expected filesystem format " +
+	"forward_list fstream functional future generator initializer_list iomanip ios iosfwd iostream " +
+	"istream iterator latch limits list locale map mdspan memory memory_resource mutex new numbers " +
+	"numeric optional ostream print queue random ranges ratio regex scoped_allocator semaphore set " +
+	"shared_mutex source_location span spanstream sstream stack stacktrace stdexcept stdfloat " +
+	"stop_token streambuf string string_view strstream syncstream system_error thread tuple " +
+	"type_traits typeindex typeinfo unordered_map unordered_set utility valarray variant vector version " +
+	"cassert cctype cerrno cfenv cfloat cinttypes climits clocale cmath csetjmp csignal cstdarg cstddef " +
+	"cstdint cstdio cstdlib cstring ctime cuchar cwchar cwctype " +
+	"assert.h complex.h ctype.h errno.h fenv.h float.h inttypes.h iso646.h limits.h locale.h math.h " +
+	"setjmp.h signal.h stdalign.h stdarg.h stdatomic.h stdbool.h stddef.h stdint.h stdio.h stdlib.h " +
+	"stdnoreturn.h string.h tgmath.h threads.h time.h uchar.h wchar.h wctype.h " +
+	"unistd.h fcntl.h pthread.h dirent.h sys/types.h sys/stat.h sys/time.h sys/wait.h sys/socket.h " +
+	"netinet/in.h arpa/inet.h bits/stdc++.h"
+
+// Biblioteca estándar de Python 3 (sys.stdlib_module_names sin los privados)
+const pythonModules = "__future__ abc aifc argparse array ast asynchat asyncio asyncore atexit audioop " +
+	"base64 bdb binascii bisect builtins bz2 cProfile calendar cgi cgitb chunk cmath cmd code codecs " +
+	"codeop collections colorsys compileall concurrent configparser contextlib contextvars copy copyreg " +
+	"crypt csv ctypes curses dataclasses datetime dbm decimal difflib dis distutils doctest email " +
+	"encodings ensurepip enum errno faulthandler fcntl filecmp fileinput fnmatch fractions ftplib " +
+	"functools gc genericpath getopt getpass gettext glob graphlib grp gzip hashlib heapq hmac html http " +
+	"idlelib imaplib imghdr imp importlib inspect io ipaddress itertools json keyword lib2to3 linecache " +
+	"locale logging lzma mailbox mailcap marshal math mimetypes mmap modulefinder msilib msvcrt " +
+	"multiprocessing netrc nis nntplib nt ntpath nturl2path numbers opcode operator optparse os " +
+	"ossaudiodev pathlib pdb pickle pickletools pipes pkgutil platform plistlib poplib posix posixpath " +
+	"pprint profile pstats pty pwd py_compile pyclbr pydoc pydoc_data pyexpat queue quopri random re " +
+	"readline reprlib resource rlcompleter runpy sched secrets select selectors shelve shlex shutil " +
+	"signal site smtpd smtplib sndhdr socket socketserver spwd sqlite3 sre_compile sre_constants " +
+	"sre_parse ssl stat statistics string stringprep struct subprocess sunau symtable sys sysconfig " +
+	"syslog tabnanny tarfile telnetlib tempfile termios textwrap this threading time timeit tkinter " +
+	"token tokenize tomllib trace traceback tracemalloc tty turtle turtledemo types typing " +
+	"unicodedata unittest urllib uu uuid venv warnings wave weakref webbrowser winreg winsound wsgiref " +
+	"xdrlib xml xmlrpc zipapp zipfile zipimport zlib zoneinfo"
+
+// Módulos integrados de Node.js (require('module').builtinModules)
+const nodeModules = "assert async_hooks buffer child_process cluster console constants crypto dgram " +
+	"diagnostics_channel dns domain events fs http http2 https inspector module net os path " +
+	"perf_hooks process punycode querystring readline repl stream string_decoder sys test timers " +
+	"tls trace_events tty url util v8 vm wasi worker_threads zlib"
+
+// Paquetes de la biblioteca estándar de Go (go list std), más el
+// seudopaquete C de cgo
+const goPackages = "C archive/tar archive/zip bufio bytes cmp compress/bzip2 compress/flate compress/gzip " +
+	"compress/lzw compress/zlib container/heap container/list container/ring context crypto crypto/aes " +
+	"crypto/cipher crypto/des crypto/dsa crypto/ecdh crypto/ecdsa crypto/ed25519 crypto/elliptic " +
+	"crypto/fips140 crypto/hkdf crypto/hmac crypto/md5 crypto/mlkem crypto/pbkdf2 crypto/rand " +
+	"crypto/rc4 crypto/rsa crypto/sha1 crypto/sha256 crypto/sha3 crypto/sha512 crypto/subtle " +
+	"crypto/tls crypto/x509 crypto/x509/pkix database/sql database/sql/driver debug/buildinfo " +
+	"debug/dwarf debug/elf debug/gosym debug/macho debug/pe debug/plan9obj embed encoding " +
+	"encoding/ascii85 encoding/asn1 encoding/base32 encoding/base64 encoding/binary encoding/csv " +
+	"encoding/gob encoding/hex encoding/json encoding/pem encoding/xml errors expvar flag fmt go/ast " +
+	"go/build go/build/constraint go/constant go/doc go/doc/comment go/format go/importer go/parser " +
+	"go/printer go/scanner go/token go/types go/version hash hash/adler32 hash/crc32 hash/crc64 " +
+	"hash/fnv hash/maphash html html/template image image/color image/color/palette image/draw " +
+	"image/gif image/jpeg image/png index/suffixarray io io/fs io/ioutil iter log log/slog log/syslog " +
+	"maps math math/big math/bits math/cmplx math/rand math/rand/v2 mime mime/multipart " +
+	"mime/quotedprintable net net/http net/http/cgi net/http/cookiejar net/http/fcgi " +
+	"net/http/httptest net/http/httptrace net/http/httputil net/http/pprof net/mail net/netip net/rpc " +
+	"net/rpc/jsonrpc net/smtp net/textproto net/url os os/exec os/signal os/user path path/filepath " +
+	"plugin reflect regexp regexp/syntax runtime runtime/cgo runtime/coverage runtime/debug " +
+	"runtime/metrics runtime/pprof runtime/race runtime/trace slices sort strconv strings structs " +
+	"sync sync/atomic syscall testing testing/fstest testing/iotest testing/quick testing/slogtest " +
+	"text/scanner text/tabwriter text/template text/template/parse time time/tzdata unicode " +
+	"unicode/utf16 unicode/utf8 unique unsafe weak"
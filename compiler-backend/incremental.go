@@ -0,0 +1,293 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ─────────────────────────── Análisis incremental ──────────────────────────
+//
+// Una sesión conserva el análisis de la versión anterior del documento. Con
+// el código nuevo se localiza la región modificada (prefijo y sufijo comunes)
+// y solo se vuelven a tokenizar y analizar las partes que la rodean; los
+// tokens y las sentencias de nivel superior posteriores se copian desplazados
+// en cuanto el análisis nuevo coincide otra vez con el anterior. La semántica
+// se recalcula completa porque la tabla de símbolos es global al programa.
+
+// AnalysisSnapshot es el resultado léxico y sintáctico de la última versión
+// analizada de un documento
+type AnalysisSnapshot struct {
+	code         string
+	language     string
+	tokens       []Token
+	tree         []ParseNode
+	syntaxErrors []CompilerError
+	// Sin delimitadores desbalanceados: Parse no recortó errores en cascada
+	balanced bool
+
+	// Cambio respecto de la versión anterior, calculado al tokenizar
+	change textChange
+}
+
+// textChange indica que code[start:oldEnd] del código anterior pasó a ser
+// code[start:newEnd] en el nuevo. Desde synced (posición en el código nuevo)
+// los tokens son los anteriores desplazados; -1 si nunca volvieron a coincidir
+type textChange struct {
+	start, oldEnd, newEnd int
+	synced                int
+	full                  bool // sin análisis anterior utilizable
+}
+
+func (c textChange) delta() int { return c.newEnd - c.oldEnd }
+
+// tokenize devuelve los tokens de code re-tokenizando solo desde la línea
+// donde empieza el cambio hasta volver a coincidir con los tokens anteriores
+func (s *AnalysisSnapshot) tokenize(code, language string) []Token {
+	if s.tokens == nil || s.language != language {
+		s.language = language
+		s.change = textChange{full: true}
+		return Tokenize(code, language)
+	}
+
+	start := 0
+	for start < len(code) && start < len(s.code) && code[start] == s.code[start] {
+		start++
+	}
+	suffix := 0
+	for suffix < len(code)-start && suffix < len(s.code)-start &&
+		code[len(code)-1-suffix] == s.code[len(s.code)-1-suffix] {
+		suffix++
+	}
+	c := textChange{start: start, oldEnd: len(s.code) - suffix, newEnd: len(code) - suffix, synced: -1}
+
+	// Se reinicia al comienzo de la línea del cambio, o antes si un token la
+	// atraviesa (comentario de bloque o string de varias líneas)
+	restart := strings.LastIndexByte(code[:start], '\n') + 1
+	keep := sort.Search(len(s.tokens), func(i int) bool { return s.tokens[i].End > restart })
+	if keep < len(s.tokens) && s.tokens[keep].Start < restart {
+		restart = s.tokens[keep].Start
+	}
+	// Una comilla o un '/*' sin cerrar antes del cambio pueden cerrarse con
+	// el texto nuevo: la tokenización se repite desde ahí
+	for i, tk := range s.tokens[:keep] {
+		openString := tk.Type == UNKNOWN && strings.ContainsAny(tk.Lexeme, "\"'`")
+		openComment := tk.Lexeme == "/" && i+1 < len(s.tokens) &&
+			s.tokens[i+1].Start == tk.End && strings.HasPrefix(s.tokens[i+1].Lexeme, "*")
+		if openString || openComment {
+			restart, keep = tk.Start, i
+			break
+		}
+	}
+
+	lineStart := strings.LastIndexByte(code[:restart], '\n') + 1
+	line := strings.Count(code[:restart], "\n") + 1
+	col := utf8.RuneCountInString(code[lineStart:restart]) + 1
+
+	// Desde el final del cambio, un token que empieza donde empezaba uno
+	// anterior implica que el resto de la tokenización es idéntica
+	delta := c.delta()
+	next := keep
+	var resumed Token
+	fresh := tokenizeFrom(code, language, restart, line, col, func(tk Token) bool {
+		if tk.Start < c.newEnd {
+			return false
+		}
+		for next < len(s.tokens) && s.tokens[next].Start < tk.Start-delta {
+			next++
+		}
+		if next < len(s.tokens) && s.tokens[next].Start == tk.Start-delta {
+			resumed = tk
+			return true
+		}
+		return false
+	})
+
+	out := make([]Token, 0, keep+len(fresh)+len(s.tokens)-next)
+	out = append(out, s.tokens[:keep]...)
+	out = append(out, fresh...)
+	if resumed.End > resumed.Start {
+		c.synced = resumed.Start
+		first := s.tokens[next]
+		dLine, dCol := resumed.Line-first.Line, resumed.Column-first.Column
+		for _, tk := range s.tokens[next:] {
+			// Solo los tokens de la misma línea que el cambio cambian de columna
+			if tk.Line == first.Line {
+				tk.Column += dCol
+			}
+			if tk.EndLine == first.Line {
+				tk.EndColumn += dCol
+			}
+			tk.Line += dLine
+			tk.EndLine += dLine
+			tk.Start += delta
+			tk.End += delta
+			out = append(out, tk)
+		}
+	}
+	s.change = c
+	return out
+}
+
+// parse analiza sintácticamente los tokens de code reutilizando las
+// sentencias de nivel superior que el cambio no alcanza, y guarda el
+// resultado como la nueva versión del documento
+func (s *AnalysisSnapshot) parse(code string, tok []Token) ([]ParseNode, []CompilerError) {
+	p := NewParser(tok, s.language, code)
+	balanceErrors, cutoff := p.checkBalance()
+	balanced := len(balanceErrors) == 0 && cutoff > len(code)
+
+	// Con delimitadores desbalanceados los errores dependen del programa
+	// completo, así que se analiza todo
+	tree, errors, ok := []ParseNode(nil), []CompilerError(nil), false
+	if balanced && s.balanced && !s.change.full {
+		tree, errors, ok = s.reparse(p)
+	}
+	if !ok {
+		tree, errors = NewParser(tok, s.language, code).Parse()
+	}
+
+	s.code, s.tokens, s.tree, s.syntaxErrors, s.balanced = code, tok, tree, errors, balanced
+	return tree, errors
+}
+
+// reparse analiza solo las sentencias de nivel superior afectadas por el
+// cambio; devuelve false si el árbol anterior no se puede reutilizar
+func (s *AnalysisSnapshot) reparse(p *Parser) ([]ParseNode, []CompilerError, bool) {
+	switch s.language {
+	case "cpp", "javascript", "typescript", "python", "go":
+	default:
+		return nil, nil, false
+	}
+	if len(s.tree) != 1 || s.tree[0].Kind != "Program" {
+		return nil, nil, false
+	}
+	c := s.change
+	oldNodes := s.tree[0].Children
+	oldToks := mergeOperatorTokens(significantTokens(s.tokens), s.language)
+
+	// Primer token significativo afectado: distinto entre ambas versiones o
+	// alcanzado por el cambio (las etiquetas de los nodos salen del texto)
+	first := sort.Search(len(oldToks), func(k int) bool { return oldToks[k].End >= c.start })
+	for k := 0; k < first; k++ {
+		if k >= len(p.toks) || !sameToken(oldToks[k], p.toks[k]) {
+			first = k
+			break
+		}
+	}
+	// En Python solo se puede reanudar al comienzo de una línea lógica
+	var lineStarts map[int]bool
+	if s.language == "python" {
+		lineStarts = make(map[int]bool)
+		for _, l := range p.pythonLines() {
+			lineStarts[l.start] = true
+		}
+	}
+	// Las sentencias miran hasta tres tokens más allá de su final (peek), así
+	// que solo se conservan las que terminan cuatro tokens antes del cambio
+	resume, regionStart := 0, 0
+	if first >= 4 {
+		limit := oldToks[first-4].Start
+		for i := sort.Search(len(oldNodes), func(k int) bool { return oldNodes[k].Pos > limit }) - 1; i > 0; i-- {
+			at := sort.Search(len(oldToks), func(k int) bool { return oldToks[k].Start >= oldNodes[i].Pos })
+			if at == len(oldToks) || oldToks[at].Start != oldNodes[i].Pos {
+				return nil, nil, false
+			}
+			if lineStarts == nil || lineStarts[at] {
+				resume, regionStart = at, oldNodes[i].Pos
+				break
+			}
+		}
+	}
+	kept := sort.Search(len(oldNodes), func(k int) bool { return oldNodes[k].Pos >= regionStart })
+
+	// Se vuelve a usar el árbol anterior al llegar, después del cambio, al
+	// comienzo de una de sus sentencias de nivel superior
+	syncFrom := c.newEnd
+	if c.synced > syncFrom {
+		syncFrom = c.synced
+	}
+	delta := c.delta()
+	resumed := len(oldNodes)
+	p.resumeAt = resume
+	p.resync = func(pos int) bool {
+		if c.synced < 0 || pos < syncFrom {
+			return false
+		}
+		// La indentación de Python es el texto previo en la misma línea
+		if s.language == "python" && strings.LastIndexByte(p.src[:pos], '\n')+1 < c.newEnd {
+			return false
+		}
+		j := sort.Search(len(oldNodes), func(k int) bool { return oldNodes[k].Pos >= pos-delta })
+		if j < len(oldNodes) && oldNodes[j].Pos == pos-delta {
+			resumed = j
+			return true
+		}
+		return false
+	}
+	tree, regionErrors := p.Parse()
+
+	root := tree[0]
+	children := append([]ParseNode{}, oldNodes[:kept]...)
+	children = append(children, root.Children...)
+	for _, n := range oldNodes[resumed:] {
+		children = append(children, shiftNode(n, delta))
+	}
+	root.Children = children
+
+	// Un error al final de una sentencia se reporta en el primer token de la
+	// siguiente; en los límites de la región no se sabe a cuál pertenece
+	resumePos := len(s.code) + 1
+	if resumed < len(oldNodes) {
+		resumePos = oldNodes[resumed].Pos
+	}
+	for _, e := range s.syntaxErrors {
+		if e.Pos == regionStart && regionStart > 0 || e.Pos == resumePos {
+			return nil, nil, false
+		}
+	}
+	for _, e := range regionErrors {
+		if e.Pos >= resumePos+delta {
+			return nil, nil, false
+		}
+	}
+
+	var errors []CompilerError
+	for _, e := range s.syntaxErrors {
+		if e.Pos < regionStart {
+			errors = append(errors, e)
+		}
+	}
+	errors = append(errors, regionErrors...)
+	if resumed < len(oldNodes) {
+		for _, e := range s.syntaxErrors {
+			if e.Pos >= resumePos {
+				e.Pos += delta
+				errors = append(errors, e)
+			}
+		}
+	}
+	// El tope de errores del parser se aplica al programa completo
+	if len(errors) >= maxParserErrors {
+		return nil, nil, false
+	}
+	return []ParseNode{root}, errors, true
+}
+
+func sameToken(a, b Token) bool {
+	return a.Start == b.Start && a.End == b.End && a.Type == b.Type && a.Lexeme == b.Lexeme
+}
+
+// shiftNode desplaza delta bytes las posiciones de un nodo y sus hijos
+func shiftNode(n ParseNode, delta int) ParseNode {
+	n.Pos += delta
+	n.End += delta
+	if len(n.Children) > 0 {
+		children := make([]ParseNode, len(n.Children))
+		for i, c := range n.Children {
+			children[i] = shiftNode(c, delta)
+		}
+		n.Children = children
+	}
+	return n
+}
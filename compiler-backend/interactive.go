@@ -0,0 +1,337 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// ─────────────────────────── Sesiones interactivas ────────────────────────
+//
+// GET /api/v1/execute/interactive abre un WebSocket donde el programa corre
+// con una pseudoterminal (PTY) como entrada y salida: cada tecla que envía
+// el cliente le llega al programa en cuanto la escribe y lo que imprime
+// vuelve de inmediato, así funcionan los menús y juegos que esperan al
+// usuario, que con stdin fijo no se pueden probar. La terminal hace eco de
+// lo que se escribe y convierte "\r" en "\n", como una terminal real; stdout
+// y stderr llegan mezclados en el mismo flujo.
+//
+// Mensajes del cliente: primero un InteractiveRequest y después
+//
+//	{"type":"input","data":"3\r"}
+//	{"type":"resize","cols":100,"rows":30}
+//
+// Mensajes del servidor: "started" cuando el programa arranca (después de
+// compilar), "output" con lo que imprime, "exit" con el resultado al
+// terminar y "error" si la petición no es válida. Cerrar el WebSocket
+// detiene el programa.
+//
+// El programa tiene los mismos límites de memoria, procesos y salida que en
+// /api/v1/analyze, pero como pasa la mayor parte del tiempo esperando al
+// usuario su tiempo es el de INTERACTIVE_TIMEOUT. Solo se ejecuta con las
+// herramientas instaladas en el servidor y en Linux: ni Docker ni los
+// intérpretes integrados tienen terminal.
+
+// InteractiveRequest es el primer mensaje de GET /api/v1/execute/interactive
+type InteractiveRequest struct {
+	Code          string            `json:"code"`
+	Language      string            `json:"language"`
+	Args          []string          `json:"args,omitempty"`
+	Env           map[string]string `json:"env,omitempty"`
+	Files         []ProgramFile     `json:"files,omitempty"`
+	Deterministic bool              `json:"deterministic,omitempty"`
+	// Tamaño inicial de la terminal; 0 usa el del sistema
+	Cols int `json:"cols,omitempty"`
+	Rows int `json:"rows,omitempty"`
+}
+
+// APIInteractiveMessage es un mensaje del WebSocket en cualquiera de los
+// dos sentidos
+type APIInteractiveMessage struct {
+	// Del cliente: "input" | "resize". Del servidor: "started" | "output" |
+	// "exit" | "error"
+	Type    string              `json:"type"`
+	Data    string              `json:"data,omitempty"`
+	Cols    int                 `json:"cols,omitempty"`
+	Rows    int                 `json:"rows,omitempty"`
+	Result  *APIExecutionResult `json:"result,omitempty"`
+	Errors  []APICompilerError  `json:"errors,omitempty"`
+	Message string              `json:"message,omitempty"`
+}
+
+// Columnas y filas máximas de la terminal
+const maxTerminalSize = 1000
+
+// Teclas que se guardan mientras el programa compila; las demás se pierden
+const maxPendingInput = 64 << 10
+
+// terminal conecta el programa de una sesión con el WebSocket. Lo que llega
+// antes de que el programa arranque (mientras compila) se guarda y se le
+// entrega al iniciar
+type terminal struct {
+	mu         sync.Mutex
+	master     *os.File
+	pending    []byte
+	cols, rows int
+	// started se llama al iniciar el programa y output con cada fragmento
+	// que imprime
+	started func()
+	output  func([]byte)
+}
+
+// write envía p al programa como si se tecleara
+func (t *terminal) write(p []byte) {
+	t.mu.Lock()
+	master := t.master
+	if master == nil {
+		if len(t.pending)+len(p) <= maxPendingInput {
+			t.pending = append(t.pending, p...)
+		}
+		t.mu.Unlock()
+		return
+	}
+	t.mu.Unlock()
+	// Fuera del lock: si el programa no lee, la escritura espera
+	master.Write(p)
+}
+
+func (t *terminal) resize(cols, rows int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cols, t.rows = cols, rows
+	if t.master != nil {
+		setWinsize(t.master, cols, rows)
+	}
+}
+
+// attach deja a master como la terminal del programa que está por iniciar
+func (t *terminal) attach(master *os.File) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.master = master
+	setWinsize(master, t.cols, t.rows)
+}
+
+// detach suelta la terminal; la entrada que llegue después se descarta
+func (t *terminal) detach() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.master = nil
+	t.pending = nil
+}
+
+// flushPending entrega al programa lo que se tecleó antes de que arrancara
+func (t *terminal) flushPending() {
+	t.mu.Lock()
+	master, pending := t.master, t.pending
+	t.pending = nil
+	t.mu.Unlock()
+	if master != nil && len(pending) > 0 {
+		master.Write(pending)
+	}
+}
+
+// run ejecuta cmd (creado con exec.CommandContext sobre ctx) con la
+// terminal como entrada y salida y los límites de runLimited
+func (t *terminal) run(ctx context.Context, cmd *exec.Cmd, limits processLimits) processResult {
+	master, slave, err := openPTY()
+	if err != nil {
+		return processResult{Output: err.Error(), Err: err, ExitCode: -1}
+	}
+	defer master.Close()
+	sandbox := newProcessSandbox(limits)
+	defer sandbox.release()
+	out := &outputLimiter{max: limits.OutputBytes, onWrite: func(_ string, p []byte) { t.output(p) }}
+	out.onExceed = func() { sandbox.kill(cmd) }
+	sandbox.prepare(cmd)
+	attachTerminal(cmd, slave)
+	t.attach(master)
+	defer t.detach()
+
+	start := time.Now()
+	err = cmd.Start()
+	slave.Close()
+	if err != nil {
+		return processResult{Output: err.Error(), Err: err, ExitCode: -1}
+	}
+	t.started()
+	t.flushPending()
+	// La lectura de master termina con EIO cuando ningún proceso tiene
+	// abierta la terminal
+	copied := make(chan struct{})
+	go func() {
+		io.Copy(out.stdoutWriter(), master)
+		close(copied)
+	}()
+	return waitLimited(ctx, cmd, sandbox, out, copied, start)
+}
+
+// interactiveBlock devuelve por qué language no se puede ejecutar en una
+// sesión interactiva con config, o "" si se puede
+func interactiveBlock(language string, config CompilerConfig) string {
+	tools, _ := toolchains.snapshot(false)
+	tc := toolchainFor(language, config, tools)
+	if !tc.Executable {
+		return tc.Reason
+	}
+	if tc.Engine != EngineNative || !ptySupported {
+		return "Las sesiones interactivas necesitan ejecutar " + language + " con las herramientas instaladas en un servidor Linux"
+	}
+	return ""
+}
+
+// interactiveConn serializa los mensajes al cliente: la salida llega desde
+// la gorutina que lee la terminal
+type interactiveConn struct {
+	mu   sync.Mutex
+	conn *websocket.Conn
+	// Bytes del final de la última salida que cortaron un carácter UTF-8
+	partial []byte
+}
+
+func (c *interactiveConn) send(msg APIInteractiveMessage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn.WriteJSON(msg)
+}
+
+func (c *interactiveConn) output(p []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	buf := append(c.partial, p...)
+	cut := completeUTF8(buf)
+	c.partial = append([]byte(nil), buf[cut:]...)
+	if cut > 0 {
+		c.conn.WriteJSON(APIInteractiveMessage{Type: "output", Data: string(buf[:cut])})
+	}
+}
+
+// interactiveHandler atiende GET /api/v1/execute/interactive
+func interactiveHandler(w http.ResponseWriter, r *http.Request) {
+	rid, msg := requestID(r)
+	if msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if executions.active(rid) {
+		httpError(w, "An execution with this request id is already running", http.StatusConflict)
+		return
+	}
+	ws, err := streamUpgrader.Upgrade(w, r, http.Header{requestIDHeader: {rid}})
+	if err != nil {
+		log.Printf("interactive: no se pudo establecer el WebSocket: %v", err)
+		return
+	}
+	defer ws.Close()
+	conn := &interactiveConn{conn: ws}
+	fail := func(msg string) { conn.send(APIInteractiveMessage{Type: "error", Message: msg}) }
+
+	var req InteractiveRequest
+	if limit := GlobalConfig.MaxRequestBytes; limit > 0 {
+		ws.SetReadLimit(limit)
+	}
+	if err := ws.ReadJSON(&req); err != nil {
+		fail("Invalid JSON")
+		return
+	}
+	if req.Code == "" {
+		fail("Code is required")
+		return
+	}
+	if msg := codeTooLarge(req.Code); msg != "" {
+		fail(msg)
+		return
+	}
+	if msg := unsupportedLanguage(req.Language); msg != "" {
+		fail(msg)
+		return
+	}
+	if req.Cols < 0 || req.Rows < 0 || req.Cols > maxTerminalSize || req.Rows > maxTerminalSize {
+		fail("cols and rows must be between 0 and 1000")
+		return
+	}
+	if msg := invalidArgs(req.Args); msg != "" {
+		fail(msg)
+		return
+	}
+	if msg := invalidEnv(req.Env); msg != "" {
+		fail(msg)
+		return
+	}
+	if msg := invalidFiles(req.Files); msg != "" {
+		fail(msg)
+		return
+	}
+	language := mapLanguage(req.Language)
+	if language == "" {
+		language = DetectLanguage(req.Code)
+	}
+	principal := principalFrom(r)
+	if _, msg := authorizeAnalysis(principal, language, true); msg != "" {
+		fail(msg)
+		return
+	}
+	config := currentConfig()
+	if msg := interactiveBlock(language, config); msg != "" {
+		fail(msg)
+		return
+	}
+
+	term := &terminal{
+		cols:    req.Cols,
+		rows:    req.Rows,
+		started: func() { conn.send(APIInteractiveMessage{Type: "started"}) },
+		output:  conn.output,
+	}
+	// Lo que envía el cliente mientras el programa corre; si cierra el
+	// WebSocket antes de que termine se detiene la ejecución
+	finished := make(chan struct{})
+	defer close(finished)
+	go func() {
+		for {
+			var in APIInteractiveMessage
+			if err := ws.ReadJSON(&in); err != nil {
+				select {
+				case <-finished:
+				default:
+					executions.stop(rid)
+				}
+				return
+			}
+			switch in.Type {
+			case "input":
+				term.write([]byte(in.Data))
+			case "resize":
+				if in.Cols > 0 && in.Rows > 0 && in.Cols <= maxTerminalSize && in.Rows <= maxTerminalSize {
+					term.resize(in.Cols, in.Rows)
+				}
+			}
+		}
+	}()
+
+	opts := AnalyzeOptions{
+		Timeout:       config.InteractiveTimeout,
+		Args:          req.Args,
+		Env:           req.Env,
+		Files:         req.Files,
+		Deterministic: req.Deterministic,
+		RequestID:     rid,
+		Principal:     principal,
+		Terminal:      term,
+	}
+	result := AnalyzeCodeWithProgress(req.Code, language, opts, nil)
+	recordAnalysis(req.Code, result, principal)
+	locale := requestLocale("", r)
+	exit := APIInteractiveMessage{Type: "exit", Errors: convertToAPIErrors(result.Errors, newSourceIndex(req.Code), locale)}
+	if result.ExecutionResult != nil {
+		exit.Result = convertToAPIExecutionResult(result.ExecutionResult)
+	}
+	conn.send(exit)
+}
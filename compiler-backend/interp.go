@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ───────────────────────── Intérpretes integrados ────────────────────────
+//
+// Algunos lenguajes pueden ejecutarse sin su intérprete instalado: el
+// programa se interpreta dentro del servidor recorriendo el árbol que ya
+// construye el parser (ver interp_javascript.go e interp_python.go). No se crea ningún proceso,
+// así que los límites los aplica el intérprete: cada sentencia y cada
+// llamada cuentan para MaxInterpreterSteps, lo que el programa reserva
+// (cadenas, arreglos, objetos) para MaxMemoryMB y lo que imprime para
+// MaxOutputBytes. El tiempo y la cancelación se controlan igual que en un
+// proceso, y la salida imita la del intérprete real para que el modo juez y
+// el análisis de errores funcionen sin cambios.
+//
+// No se usa un motor como goja porque los presupuestos se cuentan por
+// sentencia y por reserva, y un motor externo solo se puede interrumpir
+// desde afuera sin saber cuánta memoria usa el programa. Recorrer el árbol
+// del parser además garantiza que se ejecuta el mismo lenguaje que se
+// analiza, con las mismas posiciones en los errores. Para Python tampoco
+// sirve starlark-go: Starlark no tiene clases, excepciones ni while, y sus
+// mensajes no son los de CPython.
+
+// Motores de ejecución de un lenguaje interpretado
+const (
+	EngineNative   = "native"   // el intérprete instalado (node, python3)
+	EngineEmbedded = "embedded" // el intérprete integrado
+)
+
+// Cada cuántos pasos se revisa si venció el tiempo o se canceló la ejecución
+const interpCheckInterval = 1024
+
+// interpStop es el motivo por el que el intérprete detiene un programa; se
+// lanza con panic desde cualquier punto de la evaluación
+type interpStop int
+
+const (
+	stopSteps interpStop = iota + 1
+	stopMemory
+	stopOutput
+	stopContext
+	stopTrace // la traza llegó a su máximo de pasos (ver trace.go)
+)
+
+// interpBudget lleva la cuenta de lo que consume un programa interpretado
+type interpBudget struct {
+	ctx       context.Context
+	steps     int64
+	maxSteps  int64
+	memory    int64
+	maxMemory int64
+	out       *outputLimiter
+	stdout    io.Writer
+	stderr    io.Writer
+	// Pasos registrados para /api/v1/trace; nil sin traza
+	trace *traceRecorder
+}
+
+// step cuenta un paso de ejecución
+func (b *interpBudget) step() {
+	b.steps++
+	if b.maxSteps > 0 && b.steps > b.maxSteps {
+		panic(stopSteps)
+	}
+	if b.steps%interpCheckInterval == 0 && b.ctx.Err() != nil {
+		panic(stopContext)
+	}
+}
+
+// alloc cuenta n bytes reservados por el programa. Se llama antes de
+// reservarlos, así un "x".repeat(1e9) se detiene sin llegar a crear la cadena
+func (b *interpBudget) alloc(n int) {
+	if n <= 0 {
+		return
+	}
+	b.memory += int64(n)
+	if b.maxMemory > 0 && b.memory > b.maxMemory {
+		panic(stopMemory)
+	}
+}
+
+// write imprime s en stdout o stderr
+func (b *interpBudget) write(stderr bool, s string) {
+	w := b.stdout
+	if stderr {
+		w = b.stderr
+	}
+	io.WriteString(w, s)
+	if b.out.truncated() {
+		panic(stopOutput)
+	}
+}
+
+// embeddedProgram ejecuta un programa con una de las entradas estándar y
+// devuelve su código de salida
+type embeddedProgram func(b *interpBudget, input ProgramInput, stdin string) int
+
+// runEmbedded ejecuta el programa una vez por cada entrada de input.Stdin,
+// cada vez con su propio límite de tiempo, como ProgramInput.runEach
+func runEmbedded(timeout time.Duration, limits processLimits, input ProgramInput, run embeddedProgram) ExecutionResult {
+	stdins := input.Stdin
+	if len(stdins) == 0 {
+		stdins = []string{""}
+	}
+	cases := make([]ExecutionResult, 0, len(stdins))
+	for _, stdin := range stdins {
+		ctx, cancel := context.WithTimeout(input.parent(), timeout)
+		cases = append(cases, runInterpreted(ctx, limits, input, stdin, run).runExecution("", timeout, limits))
+		cancel()
+	}
+	return combineRuns(cases)
+}
+
+// runInterpreted ejecuta el programa una vez y arma su resultado
+func runInterpreted(ctx context.Context, limits processLimits, input ProgramInput, stdin string, run embeddedProgram) processResult {
+	out := &outputLimiter{max: limits.OutputBytes, onWrite: input.Output}
+	b := &interpBudget{
+		ctx:       ctx,
+		maxSteps:  limits.Steps,
+		maxMemory: limits.MemoryBytes,
+		out:       out,
+		stdout:    out.stdoutWriter(),
+		stderr:    out.stderrWriter(),
+	}
+	res := processResult{ExitCode: -1}
+	traceStop := false
+	start := time.Now()
+	func() {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			switch stop, _ := r.(interpStop); stop {
+			case stopSteps:
+				res.StepsExceeded = true
+			case stopMemory:
+				res.MemoryExceeded = true
+			case stopOutput:
+				res.Truncated = true
+			case stopContext:
+				res.TimedOut = ctx.Err() == context.DeadlineExceeded
+				res.Cancelled = ctx.Err() == context.Canceled
+			case stopTrace:
+				// El programa no terminó, pero no falló: la traza ya está
+				// completa o llegó al breakpoint
+				traceStop = true
+			default:
+				// Un error del intérprete no debe terminar el servidor
+				io.WriteString(b.stderr, fmt.Sprintf("Error interno del intérprete: %v\n", r))
+				res.ExitCode = 70
+			}
+		}()
+		res.ExitCode = run(b, input, stdin)
+	}()
+	res.Duration = time.Since(start)
+	res.UserCPU = res.Duration
+	res.PeakMemory = b.memory
+	res.Stdout, res.Stderr = out.streams()
+	res.Output = out.String()
+	if res.ExitCode != 0 && !traceStop {
+		res.Err = fmt.Errorf("exit status %d", res.ExitCode)
+	}
+	return res
+}
+
+// embeddedExecutor ejecuta un lenguaje con su intérprete integrado
+type embeddedExecutor struct {
+	language string
+	timeout  time.Duration
+	input    ProgramInput
+}
+
+// Intérpretes integrados por lenguaje: reciben el código y devuelven el
+// programa listo para ejecutarse con cada entrada
+var embeddedInterpreters = map[string]func(code string) embeddedProgram{
+	"javascript": newJSProgram,
+	"python":     newPyProgram,
+}
+
+func (e embeddedExecutor) Execute(code string, _ []Symbol) ExecutionResult {
+	return runEmbedded(e.timeout, limitsFor(e.language), e.input, embeddedInterpreters[e.language](code))
+}
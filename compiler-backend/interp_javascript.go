@@ -0,0 +1,2540 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ─────────────────── Intérprete integrado de JavaScript ──────────────────
+//
+// Con JS_ENGINE=embedded los programas JavaScript se ejecutan con este
+// intérprete en lugar de node (ver interp.go). Recorre el árbol de
+// parseCProgram y cubre lo que usan los ejercicios: variables, funciones y
+// flechas con clausuras, clases con herencia, getters y miembros estáticos,
+// arreglos, objetos, Map y Set, desestructuración, plantillas, excepciones,
+// temporizadores, lectura de stdin con fs o readline y la biblioteca
+// estándar más común (ver interp_javascript_builtins.go). No hay promesas,
+// generadores, expresiones regulares ni módulos: un programa que los usa
+// termina con un error, como lo haría con una versión antigua de node.
+
+// Profundidad máxima de llamadas, parecida a la pila por defecto de node
+const jsMaxCallDepth = 10000
+
+// Nombre del archivo en los mensajes de error
+const jsFileName = "main.js"
+
+type jsValue interface{}
+
+type jsUndefinedType struct{}
+type jsNullType struct{}
+
+var (
+	jsUndefined = jsUndefinedType{}
+	jsNull      = jsNullType{}
+)
+
+// jsObject es un objeto: propiedades en orden de creación, prototipo y
+// accesores. Las propiedades que no están en keys no son enumerables
+type jsObject struct {
+	keys    []string
+	props   map[string]jsValue
+	proto   *jsObject
+	getters map[string]*jsFunction
+	setters map[string]*jsFunction
+	// Constructor del objeto, para instanceof y console.log
+	class *jsFunction
+	// Posición donde se creó un Error, para su stack
+	trace string
+}
+
+type jsArray struct {
+	items []jsValue
+}
+
+// jsFunction es una función del programa, un constructor o una función
+// nativa de la biblioteca estándar
+type jsFunction struct {
+	name   string
+	node   *ParseNode
+	params *ParseNode
+	body   *ParseNode
+	scope  *jsScope
+	arrow  bool
+	native func(it *jsInterp, this jsValue, args []jsValue) jsValue
+	// Propiedades propias (miembros estáticos) y prototipo de las instancias
+	props *jsObject
+	proto *jsObject
+	// Clases
+	class  bool
+	parent *jsFunction
+	ctor   *jsFunction
+	fields []*ParseNode
+	home   *jsFunction // clase donde se definió el método, para super
+	// Constructores nativos: construct crea el valor (Map, Array) e init
+	// inicializa una instancia ya creada (Error y sus subclases)
+	construct func(it *jsInterp, args []jsValue) jsValue
+	init      func(it *jsInterp, this *jsObject, args []jsValue)
+}
+
+// jsMap es un Map o un Set, con las claves en orden de inserción
+type jsMap struct {
+	set    bool
+	keys   []jsValue
+	values []jsValue
+	index  map[jsValue]int
+}
+
+// jsThrow es una excepción en curso
+type jsThrow struct {
+	value jsValue
+	pos   int
+}
+
+// jsExit termina el programa desde process.exit
+type jsExit struct {
+	code int
+}
+
+type jsBinding struct {
+	value    jsValue
+	constant bool
+}
+
+type jsScope struct {
+	vars   map[string]*jsBinding
+	parent *jsScope
+	// Ámbito de una función (destino de var) y su this
+	function bool
+	this     jsValue
+	fn       *jsFunction
+}
+
+func newJSScope(parent *jsScope) *jsScope {
+	return &jsScope{vars: make(map[string]*jsBinding), parent: parent}
+}
+
+func (s *jsScope) lookup(name string) *jsBinding {
+	for ; s != nil; s = s.parent {
+		if b, ok := s.vars[name]; ok {
+			return b
+		}
+	}
+	return nil
+}
+
+func (s *jsScope) declare(name string, v jsValue, constant bool) {
+	s.vars[name] = &jsBinding{value: v, constant: constant}
+}
+
+// functionScope devuelve el ámbito de la función que contiene a s
+func (s *jsScope) functionScope() *jsScope {
+	for ; s.parent != nil && !s.function; s = s.parent {
+	}
+	return s
+}
+
+// thisScope devuelve el ámbito que define this: las flechas usan el de la
+// función que las contiene
+func (s *jsScope) thisScope() *jsScope {
+	for ; s.parent != nil && (!s.function || s.fn != nil && s.fn.arrow); s = s.parent {
+	}
+	return s
+}
+
+type jsCompletion int
+
+const (
+	jsNormal jsCompletion = iota
+	jsReturn
+	jsBreak
+	jsContinue
+)
+
+// jsTemplatePart es un tramo de una plantilla: texto o una expresión ${}
+type jsTemplatePart struct {
+	text string
+	expr *ParseNode
+}
+
+type jsInterp struct {
+	b      *interpBudget
+	src    string
+	index  *sourceIndex
+	global *jsScope
+	depth  int
+	input  ProgramInput
+	stdin  string
+	// Valores de los literales y tramos de las plantillas ya decodificados
+	literals  map[*ParseNode]jsValue
+	templates map[*ParseNode][]jsTemplatePart
+	// Posición de la llamada en curso y del último new, para el stack de los
+	// errores
+	pos    int
+	newPos int
+	// Constructores de la biblioteca estándar
+	objectClass *jsFunction
+	arrayClass  *jsFunction
+	errors      map[string]*jsFunction
+	// Temporizadores y eventos de stdin pendientes (ver runEventLoop); clock
+	// es el tiempo simulado en milisegundos
+	timers   []jsTimer
+	timerSeq int
+	clock    float64
+	stdinEv  *jsStdinEvents
+	process  *jsObject
+	// Generador de Math.random
+	rand *rand.Rand
+}
+
+// newJSProgram analiza code y devuelve el programa que lo interpreta
+func newJSProgram(code string) embeddedProgram {
+	tokens := Tokenize(code, "javascript")
+	tree, errs := NewParser(tokens, "javascript", code).Parse()
+	return func(b *interpBudget, input ProgramInput, stdin string) int {
+		it := newJSInterp(b, code, input, stdin)
+		// Lo que node acepta y el intérprete no se informa antes que los
+		// errores del parser, que tampoco conoce yield ni las etiquetas
+		if pos, msg := jsUnsupported(tokens); msg != "" {
+			it.printUncaughtHeader(pos)
+			b.write(true, "SyntaxError: "+msg+"\n")
+			return 1
+		}
+		for _, e := range errs {
+			if e.Severity == "error" {
+				pos, msg := jsSyntaxError(code, tokens, e)
+				it.printUncaughtHeader(pos)
+				b.write(true, "SyntaxError: "+msg+"\n")
+				return 1
+			}
+		}
+		if len(tree) == 0 {
+			return 0
+		}
+		return it.run(&tree[0])
+	}
+}
+
+// jsUnsupported busca generadores, sentencias con etiqueta y miembros
+// privados, que node ejecuta y el intérprete no implementa, y devuelve dónde
+// están y el mensaje; "" si el programa no los usa
+func jsUnsupported(tokens []Token) (int, string) {
+	toks := significantTokens(tokens)
+	lexeme := func(i int) string {
+		if i < 0 || i >= len(toks) {
+			return ""
+		}
+		return toks[i].Lexeme
+	}
+	for i, tk := range toks {
+		switch {
+		// function* f() y los métodos *f() de clases y objetos
+		case tk.Lexeme == "function" && lexeme(i+1) == "*",
+			tk.Lexeme == "*" && strings.Contains(" { } ; , static ", " "+lexeme(i-1)+" ") &&
+				i+2 < len(toks) && toks[i+1].Type == IDENTIFIER && lexeme(i+2) == "(":
+			return tk.Start, "Generators (function*, yield) are not supported by the embedded interpreter; use JS_ENGINE=native"
+		// break externo; y continue externo; en la misma línea
+		case (tk.Lexeme == "break" || tk.Lexeme == "continue") && i+1 < len(toks) &&
+			toks[i+1].Type == IDENTIFIER && toks[i+1].Line == tk.Line:
+			return toks[i+1].Start, "Labeled break and continue are not supported by the embedded interpreter; use JS_ENGINE=native"
+		// externo: for (...), al comienzo de una sentencia; un bloque con
+		// etiqueta no se distingue de un objeto { a: { ... } }
+		case tk.Type == IDENTIFIER && lexeme(i+1) == ":" && (i == 0 || strings.Contains(" { } ; ) ", " "+lexeme(i-1)+" ")) &&
+			strings.Contains(" for while do ", " "+lexeme(i+2)+" "):
+			return tk.Start, "Labeled statements are not supported by the embedded interpreter; use JS_ENGINE=native"
+		}
+	}
+	// #campo: el lexer deja el # como un token desconocido y el parser lo
+	// saltaría, así que el miembro privado quedaría como uno público
+	for i, tk := range tokens {
+		if tk.Type == UNKNOWN && tk.Lexeme == "#" && i+1 < len(tokens) && tokens[i+1].Type == IDENTIFIER && tokens[i+1].Start == tk.End {
+			return tk.Start, "Private class members (#name) are not supported by the embedded interpreter; use JS_ENGINE=native"
+		}
+	}
+	return 0, ""
+}
+
+// jsSyntaxError traduce el primer error del parser al mensaje de node, que
+// nombra el token inesperado: Unexpected token ')', Unexpected identifier
+// 'b', Unexpected end of input
+func jsSyntaxError(code string, tokens []Token, e CompilerError) (int, string) {
+	toks := significantTokens(tokens)
+	if e.Code == CodeUnclosedDelimiter {
+		// El parser informa cuántos quedaron abiertos: node se queja en el
+		// primer token que no puede cerrar la llamada o al final del código
+		var open []int
+		for i, tk := range toks {
+			switch tk.Lexeme {
+			case "(", "[", "{":
+				open = append(open, i)
+			case ")", "]", "}":
+				if len(open) > 0 {
+					open = open[:len(open)-1]
+				}
+			}
+		}
+		if n := len(open); n > 0 && toks[open[n-1]].Lexeme == "(" && open[n-1] > 0 && open[n-1] < len(toks)-1 {
+			if prev := toks[open[n-1]-1]; prev.Type == IDENTIFIER || prev.Lexeme == ")" || prev.Lexeme == "]" {
+				return toks[len(toks)-1].Start, "missing ) after argument list"
+			}
+		}
+		return len(code), "Unexpected end of input"
+	}
+	for _, tk := range toks {
+		if tk.Start < e.Pos {
+			continue
+		}
+		switch {
+		case tk.Type == IDENTIFIER:
+			return tk.Start, fmt.Sprintf("Unexpected identifier '%s'", tk.Lexeme)
+		case tk.Type == NUMBER:
+			return tk.Start, "Unexpected number"
+		case tk.Type == STRING && strings.HasPrefix(tk.Lexeme, "`"):
+			return tk.Start, "Unexpected template string"
+		case tk.Type == STRING:
+			return tk.Start, "Unexpected string"
+		}
+		return tk.Start, fmt.Sprintf("Unexpected token '%s'", tk.Lexeme)
+	}
+	return len(code), "Unexpected end of input"
+}
+
+func newJSInterp(b *interpBudget, code string, input ProgramInput, stdin string) *jsInterp {
+	it := &jsInterp{
+		b:         b,
+		src:       code,
+		index:     newSourceIndex(code),
+		input:     input,
+		stdin:     stdin,
+		literals:  make(map[*ParseNode]jsValue),
+		templates: make(map[*ParseNode][]jsTemplatePart),
+		rand:      rand.New(rand.NewSource(input.seed())),
+	}
+	it.global = newJSScope(nil)
+	it.global.function = true
+	it.global.this = it.newObject()
+	it.installGlobals()
+	if b.trace != nil {
+		it.traceHideGlobals()
+	}
+	return it
+}
+
+// run ejecuta el programa y después los temporizadores y eventos pendientes
+func (it *jsInterp) run(root *ParseNode) (code int) {
+	defer func() {
+		switch r := recover().(type) {
+		case nil:
+		case *jsThrow:
+			if it.b.trace != nil {
+				it.traceException(r)
+			}
+			it.printUncaught(r)
+			code = 1
+		case jsExit:
+			code = r.code
+		default:
+			panic(r)
+		}
+	}()
+	it.execStatements(root.Children, it.global)
+	it.runEventLoop()
+	return it.exitCode()
+}
+
+// exitCode es el valor de process.exitCode, 0 si el programa no lo cambió
+func (it *jsInterp) exitCode() int {
+	if code, ok := it.process.props["exitCode"].(float64); ok {
+		return int(code)
+	}
+	return 0
+}
+
+// ───── Errores ─────
+//
+// Una excepción sin capturar se imprime en stderr como lo hace node: la
+// línea del programa con una marca bajo la posición, el nombre y mensaje
+// del error y dónde ocurrió. El código de salida es 1.
+
+// throwError lanza un error de la clase indicada (TypeError, RangeError...)
+func (it *jsInterp) throwError(class string, pos int, format string, args ...interface{}) {
+	it.pos = pos
+	err := it.construct(it.errors[class], []jsValue{fmt.Sprintf(format, args...)}, pos)
+	panic(&jsThrow{value: err, pos: pos})
+}
+
+// location devuelve "main.js:línea:columna" de una posición del programa
+func (it *jsInterp) location(pos int) string {
+	line, col := it.index.lineColumn(pos)
+	return fmt.Sprintf("%s:%d:%d", jsFileName, line, col)
+}
+
+// printUncaughtHeader imprime la línea donde ocurrió el error con la marca
+// y la línea en blanco que la separa del error
+func (it *jsInterp) printUncaughtHeader(pos int) {
+	it.b.write(true, it.uncaughtSource(pos)+"\n")
+}
+
+// uncaughtSource devuelve "main.js:línea", el texto de esa línea y la marca
+// bajo la columna de pos
+func (it *jsInterp) uncaughtSource(pos int) string {
+	line, col := it.index.lineColumn(pos)
+	text := it.src
+	if line-1 < len(it.index.lineStarts) {
+		text = text[it.index.lineStarts[line-1]:]
+	}
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[:i]
+	}
+	caret := strings.Repeat(" ", col-1) + "^"
+	if pos >= len(it.src) {
+		// Al final del código node no marca ninguna columna
+		caret = ""
+	}
+	return fmt.Sprintf("%s:%d\n%s\n%s\n", jsFileName, line, strings.TrimRight(text, "\r"), caret)
+}
+
+// printUncaught imprime una excepción que nadie capturó: los errores con su
+// traza y cualquier otro valor como lo muestra node, precedido de una línea
+// en blanco y, si es primitivo, con la sugerencia de --trace-uncaught
+func (it *jsInterp) printUncaught(t *jsThrow) {
+	if obj, ok := t.value.(*jsObject); ok && obj.trace != "" {
+		it.printUncaughtHeader(t.pos)
+		it.b.write(true, it.inspect(obj)+"\n")
+		return
+	}
+	it.b.write(true, "\n"+it.uncaughtSource(t.pos))
+	switch t.value.(type) {
+	case *jsObject, *jsArray, *jsFunction, *jsMap:
+		it.b.write(true, it.inspect(t.value)+"\n")
+	default:
+		it.b.write(true, it.toString(t.value)+"\n(Use `node --trace-uncaught ...` to show where the exception was thrown)\n")
+	}
+}
+
+// ───── Sentencias ─────
+
+// execStatements ejecuta una lista de sentencias en scope, con las
+// declaraciones de funciones ya definidas al comenzar
+func (it *jsInterp) execStatements(stmts []ParseNode, scope *jsScope) (jsCompletion, jsValue) {
+	for i := range stmts {
+		n := &stmts[i]
+		if n.Kind == "Export" && len(n.Children) == 1 {
+			n = &n.Children[0]
+		}
+		if n.Kind == "FunctionDecl" && n.Label != "" {
+			scope.declare(n.Label, it.makeFunction(n, scope), false)
+		}
+	}
+	for i := range stmts {
+		if c, v := it.exec(&stmts[i], scope); c != jsNormal {
+			return c, v
+		}
+	}
+	return jsNormal, nil
+}
+
+func (it *jsInterp) exec(n *ParseNode, scope *jsScope) (jsCompletion, jsValue) {
+	it.b.step()
+	if it.b.trace != nil && n.Kind != "Block" && n.Kind != "FunctionDecl" && n.Kind != "Empty" {
+		it.traceLine(n, scope)
+	}
+	switch n.Kind {
+	case "ExprStmt":
+		it.eval(&n.Children[0], scope)
+	case "VarDecl":
+		it.execVarDecl(n, scope)
+	case "DeclGroup":
+		for i := range n.Children {
+			it.execVarDecl(&n.Children[i], scope)
+		}
+	case "FunctionDecl", "Empty":
+		// Las funciones se definen al entrar al bloque
+	case "ClassDecl":
+		scope.declare(n.Label, it.makeClass(n, scope), false)
+	case "Block":
+		return it.execStatements(n.Children, newJSScope(scope))
+	case "If":
+		if it.truthy(it.eval(&n.Children[0].Children[0], scope)) {
+			return it.exec(&n.Children[1], scope)
+		}
+		if len(n.Children) > 2 {
+			return it.exec(&n.Children[2].Children[0], scope)
+		}
+	case "While":
+		for it.truthy(it.eval(&n.Children[0].Children[0], scope)) {
+			if c, v := it.exec(&n.Children[1], scope); c == jsBreak {
+				break
+			} else if c == jsReturn {
+				return c, v
+			}
+		}
+	case "DoWhile":
+		for {
+			if c, v := it.exec(&n.Children[0], scope); c == jsBreak {
+				break
+			} else if c == jsReturn {
+				return c, v
+			}
+			if !it.truthy(it.eval(&n.Children[1].Children[0], scope)) {
+				break
+			}
+		}
+	case "For":
+		return it.execFor(n, scope)
+	case "ForEach":
+		return it.execForEach(n, scope)
+	case "Switch":
+		return it.execSwitch(n, scope)
+	case "Return":
+		if len(n.Children) == 0 {
+			return jsReturn, jsUndefined
+		}
+		return jsReturn, it.eval(&n.Children[0], scope)
+	case "Break":
+		return jsBreak, nil
+	case "Continue":
+		return jsContinue, nil
+	case "Throw":
+		panic(&jsThrow{value: it.eval(&n.Children[0], scope), pos: n.Pos})
+	case "Try":
+		return it.execTry(n, scope)
+	case "Export":
+		if len(n.Children) == 1 {
+			return it.exec(&n.Children[0], scope)
+		}
+	case "Import":
+		it.throwError("SyntaxError", n.Pos, "Cannot use import statement outside a module")
+	default:
+		it.throwError("SyntaxError", n.Pos, "%s is not supported by the embedded interpreter", n.Kind)
+	}
+	return jsNormal, nil
+}
+
+// execVarDecl ejecuta una declaración let, const o var
+func (it *jsInterp) execVarDecl(n *ParseNode, scope *jsScope) {
+	kind := n.Children[0].Label
+	rest := n.Children[1:]
+	var pattern *ParseNode
+	if len(rest) > 0 && (rest[0].Kind == "ObjectPattern" || rest[0].Kind == "ArrayPattern") {
+		pattern, rest = &rest[0], rest[1:]
+	}
+	var value jsValue = jsUndefined
+	if len(rest) > 0 {
+		value = it.eval(&rest[0], scope)
+	}
+	target := scope
+	if kind == "var" {
+		target = scope.functionScope()
+	}
+	if pattern == nil {
+		if kind == "var" && len(rest) == 0 && target.vars[n.Label] != nil {
+			return
+		}
+		it.nameFunction(value, n.Label)
+		target.declare(n.Label, value, kind == "const")
+		return
+	}
+	it.bindPattern(pattern, value, scope, func(name string, v jsValue) {
+		target.declare(name, v, kind == "const")
+	})
+}
+
+// execFor ejecuta un for clásico. Con let cada iteración tiene su propia
+// copia de las variables, así las clausuras creadas en el cuerpo ven el
+// valor de esa iteración
+func (it *jsInterp) execFor(n *ParseNode, scope *jsScope) (jsCompletion, jsValue) {
+	header := &n.Children[0]
+	var init, cond, update *ParseNode
+	semicolon := header.Pos + strings.IndexByte(it.src[header.Pos:header.End], ';')
+	for i := range header.Children {
+		child := &header.Children[i]
+		switch {
+		case child.Kind == "Condition":
+			cond = &child.Children[0]
+		case cond == nil && child.Pos < semicolon:
+			init = child
+		default:
+			update = child
+		}
+	}
+	loop := newJSScope(scope)
+	perIteration := false
+	if init != nil {
+		if init.Kind == "VarDecl" || init.Kind == "DeclGroup" {
+			it.exec(init, loop)
+			perIteration = init.Kind == "VarDecl" && init.Children[0].Label == "let" ||
+				init.Kind == "DeclGroup" && init.Label == "let"
+		} else {
+			it.eval(init, loop)
+		}
+	}
+	for {
+		if perIteration {
+			loop = loop.copyVars()
+		}
+		if cond != nil && !it.truthy(it.eval(cond, loop)) {
+			break
+		}
+		c, v := it.exec(&n.Children[1], loop)
+		if c == jsBreak {
+			break
+		} else if c == jsReturn {
+			return c, v
+		}
+		if perIteration {
+			loop = loop.copyVars()
+		}
+		if update != nil {
+			it.eval(update, loop)
+		}
+	}
+	return jsNormal, nil
+}
+
+// copyVars devuelve un ámbito con el mismo padre y una copia de las variables
+func (s *jsScope) copyVars() *jsScope {
+	c := newJSScope(s.parent)
+	for name, b := range s.vars {
+		copied := *b
+		c.vars[name] = &copied
+	}
+	return c
+}
+
+// execForEach ejecuta for...of y for...in
+func (it *jsInterp) execForEach(n *ParseNode, scope *jsScope) (jsCompletion, jsValue) {
+	target, body := &n.Children[0], &n.Children[2]
+	iterable := it.eval(&n.Children[1], scope)
+	var items []jsValue
+	if n.Label == "in" {
+		for _, k := range it.enumerableKeys(iterable) {
+			items = append(items, k)
+		}
+	} else {
+		items = it.iterate(iterable, n.Children[1].Pos)
+	}
+	for _, item := range items {
+		iter := newJSScope(scope)
+		if target.Kind == "VarDecl" {
+			constant := target.Children[0].Label == "const"
+			declScope := iter
+			if target.Children[0].Label == "var" {
+				declScope = scope.functionScope()
+			}
+			if len(target.Children) > 1 {
+				it.bindPattern(&target.Children[1], item, iter, func(name string, v jsValue) { declScope.declare(name, v, constant) })
+			} else {
+				declScope.declare(target.Label, item, constant)
+			}
+		} else {
+			it.assign(target, item, iter)
+		}
+		c, v := it.exec(body, iter)
+		if c == jsBreak {
+			break
+		} else if c == jsReturn {
+			return c, v
+		}
+	}
+	return jsNormal, nil
+}
+
+func (it *jsInterp) execSwitch(n *ParseNode, scope *jsScope) (jsCompletion, jsValue) {
+	value := it.eval(&n.Children[0].Children[0], scope)
+	cases := n.Children[1].Children
+	inner := newJSScope(scope)
+	start := -1
+	for i := range cases {
+		if cases[i].Label == "case" && it.strictEquals(value, it.eval(&cases[i].Children[0], inner)) {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		for i := range cases {
+			if cases[i].Label == "default" {
+				start = i
+			}
+		}
+	}
+	if start < 0 {
+		return jsNormal, nil
+	}
+	for i := start; i < len(cases); i++ {
+		stmts := cases[i].Children
+		if cases[i].Label == "case" {
+			stmts = stmts[1:]
+		}
+		c, v := it.execStatements(stmts, inner)
+		if c == jsBreak {
+			break
+		}
+		if c != jsNormal {
+			return c, v
+		}
+	}
+	return jsNormal, nil
+}
+
+// execTry ejecuta try/catch/finally. Solo se capturan las excepciones del
+// programa: un límite excedido (interpStop) o process.exit no pasan por
+// catch ni por finally
+func (it *jsInterp) execTry(n *ParseNode, scope *jsScope) (c jsCompletion, v jsValue) {
+	var catch, finally *ParseNode
+	for i := range n.Children[1:] {
+		child := &n.Children[i+1]
+		if child.Kind == "Catch" {
+			catch = child
+		} else if child.Kind == "Finally" {
+			finally = &child.Children[0]
+		}
+	}
+	if finally != nil {
+		defer func() {
+			r := recover()
+			if _, ok := r.(*jsThrow); r != nil && !ok {
+				panic(r)
+			}
+			if fc, fv := it.exec(finally, scope); fc != jsNormal {
+				c, v = fc, fv
+				return
+			}
+			if r != nil {
+				panic(r)
+			}
+		}()
+	}
+	if catch == nil {
+		return it.exec(&n.Children[0], scope)
+	}
+	thrown := func() (t *jsThrow) {
+		defer func() {
+			if r := recover(); r != nil {
+				var ok bool
+				if t, ok = r.(*jsThrow); !ok {
+					panic(r)
+				}
+			}
+		}()
+		c, v = it.exec(&n.Children[0], scope)
+		return nil
+	}()
+	if thrown == nil {
+		return c, v
+	}
+	handler := newJSScope(scope)
+	if len(catch.Children) == 2 {
+		it.bindPattern(&catch.Children[0], thrown.value, handler, func(name string, v jsValue) { handler.declare(name, v, false) })
+	}
+	return it.exec(&catch.Children[len(catch.Children)-1], handler)
+}
+
+// ───── Expresiones ─────
+
+func (it *jsInterp) eval(n *ParseNode, scope *jsScope) jsValue {
+	switch n.Kind {
+	case "Literal":
+		return it.literal(n, scope)
+	case "Identifier":
+		return it.identifier(n, scope)
+	case "BinaryExpr":
+		if n.Label == "instanceof" {
+			return it.instanceOf(it.eval(&n.Children[0], scope), it.eval(&n.Children[1], scope), n.Pos)
+		}
+		if n.Label == "in" {
+			obj := it.eval(&n.Children[1], scope)
+			return it.hasProperty(obj, it.propertyKey(it.eval(&n.Children[0], scope)), n.Pos)
+		}
+		return it.binary(n.Label, it.eval(&n.Children[0], scope), it.eval(&n.Children[1], scope))
+	case "LogicalExpr":
+		left := it.eval(&n.Children[0], scope)
+		switch n.Label {
+		case "&&":
+			if !it.truthy(left) {
+				return left
+			}
+		case "||":
+			if it.truthy(left) {
+				return left
+			}
+		case "??":
+			if left != jsUndefined && left != jsNull {
+				return left
+			}
+		}
+		return it.eval(&n.Children[1], scope)
+	case "UnaryExpr":
+		return it.unary(n, scope)
+	case "PostfixExpr":
+		old := it.toNumber(it.eval(&n.Children[0], scope))
+		delta := 1.0
+		if n.Label == "--" {
+			delta = -1
+		}
+		it.assign(&n.Children[0], old+delta, scope)
+		return old
+	case "Assign":
+		return it.evalAssign(n, scope)
+	case "Conditional":
+		if it.truthy(it.eval(&n.Children[0], scope)) {
+			return it.eval(&n.Children[1], scope)
+		}
+		return it.eval(&n.Children[2], scope)
+	case "Call":
+		return it.evalCall(n, scope)
+	case "New":
+		callee := it.eval(&n.Children[0], scope)
+		var args []jsValue
+		if len(n.Children) > 1 {
+			args = it.evalArgs(&n.Children[1], scope)
+		}
+		if !jsConstructible(callee) {
+			c := &n.Children[0]
+			it.throwError("TypeError", n.Pos, "%s is not a constructor", it.src[c.Pos:c.End])
+		}
+		return it.construct(callee, args, n.Pos)
+	case "Member":
+		obj := &n.Children[0]
+		if obj.Kind == "Identifier" && obj.Label == "super" {
+			return it.superMember(n.Label, scope, n.Pos)
+		}
+		target := it.eval(obj, scope)
+		if (target == jsUndefined || target == jsNull) && it.optional(obj, n) {
+			return jsUndefined
+		}
+		return it.getMember(target, n.Label, jsPropertyPos(n))
+	case "Index":
+		target := it.eval(&n.Children[0], scope)
+		key := it.eval(&n.Children[1], scope)
+		if (target == jsUndefined || target == jsNull) && it.optional(&n.Children[0], n) {
+			return jsUndefined
+		}
+		return it.getIndex(target, key, n.Pos)
+	case "ArrayLiteral":
+		items := make([]jsValue, 0, len(n.Children))
+		for i := range n.Children {
+			child := &n.Children[i]
+			if child.Kind == "Spread" {
+				items = append(items, it.iterate(it.eval(&child.Children[0], scope), child.Pos)...)
+			} else {
+				items = append(items, it.eval(child, scope))
+			}
+		}
+		return it.newArray(items)
+	case "ObjectLiteral":
+		return it.objectLiteral(n, scope)
+	case "ArrowFunction", "FunctionDecl":
+		return it.makeFunction(n, scope)
+	case "ClassDecl":
+		return it.makeClass(n, scope)
+	case "Sequence":
+		it.eval(&n.Children[0], scope)
+		return it.eval(&n.Children[1], scope)
+	case "Spread":
+		it.throwError("SyntaxError", n.Pos, "Unexpected token '...'")
+	}
+	it.throwError("SyntaxError", n.Pos, "%s is not supported by the embedded interpreter", n.Kind)
+	return nil
+}
+
+// optional indica si entre obj y el miembro n hay un ?. (a?.b, a?.[i])
+func (it *jsInterp) optional(obj, n *ParseNode) bool {
+	return obj.End < n.End && strings.HasPrefix(strings.TrimLeft(it.src[obj.End:n.End], " \t\r\n"), "?.")
+}
+
+func (it *jsInterp) identifier(n *ParseNode, scope *jsScope) jsValue {
+	switch n.Label {
+	case "this":
+		return scope.thisScope().this
+	case "undefined":
+		return jsUndefined
+	}
+	if b := scope.lookup(n.Label); b != nil {
+		return b.value
+	}
+	it.throwError("ReferenceError", n.Pos, "%s is not defined", n.Label)
+	return nil
+}
+
+// literal devuelve el valor de un literal; se decodifica una sola vez
+func (it *jsInterp) literal(n *ParseNode, scope *jsScope) jsValue {
+	if v, ok := it.literals[n]; ok {
+		return v
+	}
+	label := n.Label
+	var v jsValue
+	switch {
+	case label == "true" || label == "false":
+		v = label == "true"
+	case label == "null":
+		v = jsNull
+	case label == "undefined":
+		v = jsUndefined
+	case strings.HasPrefix(label, "`"):
+		return it.template(n, scope)
+	case strings.HasPrefix(label, "'") || strings.HasPrefix(label, "\""):
+		v = jsUnquote(label)
+	default:
+		v = jsParseNumber(label)
+	}
+	it.literals[n] = v
+	return v
+}
+
+// template evalúa una plantilla `texto ${expresión}`
+func (it *jsInterp) template(n *ParseNode, scope *jsScope) jsValue {
+	parts, ok := it.templates[n]
+	if !ok {
+		parts = it.parseTemplate(n)
+		it.templates[n] = parts
+	}
+	var sb strings.Builder
+	for _, part := range parts {
+		if part.expr == nil {
+			sb.WriteString(part.text)
+		} else {
+			sb.WriteString(it.toString(it.eval(part.expr, scope)))
+		}
+	}
+	it.b.alloc(sb.Len())
+	return sb.String()
+}
+
+// parseTemplate separa una plantilla en texto y expresiones; cada expresión
+// se analiza con el parser de JavaScript y sus posiciones se ajustan a las
+// del programa
+func (it *jsInterp) parseTemplate(n *ParseNode) []jsTemplatePart {
+	label := n.Label
+	body := label[1 : len(label)-1]
+	var parts []jsTemplatePart
+	var text strings.Builder
+	for i := 0; i < len(body); i++ {
+		if body[i] == '\\' && i+1 < len(body) {
+			// Se decodifica solo la secuencia de escape
+			size := jsEscapeLength(body[i:])
+			text.WriteString(jsUnescape(body[i : i+size]))
+			i += size - 1
+			continue
+		}
+		if body[i] == '$' && i+1 < len(body) && body[i+1] == '{' {
+			end := jsMatchingBrace(body, i+2)
+			if text.Len() > 0 {
+				parts = append(parts, jsTemplatePart{text: text.String()})
+				text.Reset()
+			}
+			exprSrc := body[i+2 : end]
+			expr := NewParser(Tokenize(exprSrc, "javascript"), "javascript", exprSrc).parseExpression()
+			shiftPositions(&expr, n.Pos+1+i+2)
+			parts = append(parts, jsTemplatePart{expr: &expr})
+			i = end
+			continue
+		}
+		text.WriteByte(body[i])
+	}
+	if text.Len() > 0 {
+		parts = append(parts, jsTemplatePart{text: text.String()})
+	}
+	return parts
+}
+
+// jsMatchingBrace devuelve la posición de la llave que cierra la expresión
+// de una plantilla que empieza en start
+func jsMatchingBrace(s string, start int) int {
+	depth := 0
+	var quote byte
+	for i := start; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '{':
+			depth++
+		case c == '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return len(s)
+}
+
+// shiftPositions suma off a las posiciones de n y sus hijos
+func shiftPositions(n *ParseNode, off int) {
+	n.Pos += off
+	n.End += off
+	for i := range n.Children {
+		shiftPositions(&n.Children[i], off)
+	}
+}
+
+func (it *jsInterp) unary(n *ParseNode, scope *jsScope) jsValue {
+	operand := &n.Children[0]
+	switch n.Label {
+	case "typeof":
+		if operand.Kind == "Identifier" && operand.Label != "this" && scope.lookup(operand.Label) == nil {
+			return "undefined"
+		}
+		return it.typeOf(it.eval(operand, scope))
+	case "delete":
+		switch operand.Kind {
+		case "Member":
+			it.deleteProperty(it.eval(&operand.Children[0], scope), operand.Label)
+		case "Index":
+			obj := it.eval(&operand.Children[0], scope)
+			it.deleteProperty(obj, it.propertyKey(it.eval(&operand.Children[1], scope)))
+		}
+		return true
+	case "++", "--":
+		v := it.toNumber(it.eval(operand, scope))
+		if n.Label == "++" {
+			v++
+		} else {
+			v--
+		}
+		it.assign(operand, v, scope)
+		return v
+	}
+	v := it.eval(operand, scope)
+	switch n.Label {
+	case "!":
+		return !it.truthy(v)
+	case "-":
+		return -it.toNumber(v)
+	case "+":
+		return it.toNumber(v)
+	case "~":
+		return float64(^jsToInt32(it.toNumber(v)))
+	case "void":
+		return jsUndefined
+	case "await":
+		return v
+	}
+	it.throwError("SyntaxError", n.Pos, "Operator '%s' is not supported by the embedded interpreter", n.Label)
+	return nil
+}
+
+func (it *jsInterp) evalAssign(n *ParseNode, scope *jsScope) jsValue {
+	target, valueNode := &n.Children[0], &n.Children[1]
+	op := n.Label
+	if op == "=" {
+		v := it.eval(valueNode, scope)
+		if target.Kind == "Identifier" {
+			it.nameFunction(v, target.Label)
+		}
+		it.assign(target, v, scope)
+		return v
+	}
+	current := it.eval(target, scope)
+	var v jsValue
+	switch op {
+	case "&&=":
+		if !it.truthy(current) {
+			return current
+		}
+		v = it.eval(valueNode, scope)
+	case "||=":
+		if it.truthy(current) {
+			return current
+		}
+		v = it.eval(valueNode, scope)
+	case "??=":
+		if current != jsUndefined && current != jsNull {
+			return current
+		}
+		v = it.eval(valueNode, scope)
+	default:
+		v = it.binary(strings.TrimSuffix(op, "="), current, it.eval(valueNode, scope))
+	}
+	it.assign(target, v, scope)
+	return v
+}
+
+// assign guarda v en el destino de una asignación: una variable, un miembro
+// o un patrón de desestructuración
+func (it *jsInterp) assign(target *ParseNode, v jsValue, scope *jsScope) {
+	switch target.Kind {
+	case "Identifier":
+		b := scope.lookup(target.Label)
+		if b == nil {
+			// Sin declarar: como en node sin modo estricto, se crea global
+			it.global.declare(target.Label, v, false)
+			return
+		}
+		if b.constant {
+			it.throwError("TypeError", target.Pos, "Assignment to constant variable.")
+		}
+		b.value = v
+	case "Member":
+		it.setMember(it.eval(&target.Children[0], scope), target.Label, v, target.Pos)
+	case "Index":
+		obj := it.eval(&target.Children[0], scope)
+		it.setIndex(obj, it.eval(&target.Children[1], scope), v, target.Pos)
+	case "ArrayLiteral", "ArrayPattern", "ObjectLiteral", "ObjectPattern":
+		it.bindPattern(target, v, scope, func(name string, v jsValue) {
+			it.assign(&ParseNode{Kind: "Identifier", Label: name, Pos: target.Pos}, v, scope)
+		})
+	default:
+		it.throwError("SyntaxError", target.Pos, "Invalid left-hand side in assignment")
+	}
+}
+
+// bindPattern desestructura v según pattern; bind define cada nombre
+func (it *jsInterp) bindPattern(pattern *ParseNode, v jsValue, scope *jsScope, bind func(name string, v jsValue)) {
+	switch pattern.Kind {
+	case "Identifier":
+		it.nameFunction(v, pattern.Label)
+		bind(pattern.Label, v)
+	case "Assign":
+		// Valor por defecto: [a = 1] o {a: b = 2}
+		if v == jsUndefined {
+			v = it.eval(&pattern.Children[1], scope)
+		}
+		it.bindPattern(&pattern.Children[0], v, scope, bind)
+	case "ArrayPattern", "ArrayLiteral":
+		items := it.iterate(v, pattern.Pos)
+		for i := range pattern.Children {
+			child := &pattern.Children[i]
+			if child.Kind == "Spread" {
+				var rest []jsValue
+				if i < len(items) {
+					rest = append(rest, items[i:]...)
+				}
+				it.bindPattern(&child.Children[0], it.newArray(rest), scope, bind)
+				break
+			}
+			var item jsValue = jsUndefined
+			if i < len(items) {
+				item = items[i]
+			}
+			it.bindPattern(child, item, scope, bind)
+		}
+	case "ObjectPattern", "ObjectLiteral":
+		if v == jsUndefined || v == jsNull {
+			it.throwError("TypeError", pattern.Pos, "Cannot destructure '%s' as it is %s.", it.toString(v), it.toString(v))
+		}
+		used := map[string]bool{}
+		for i := range pattern.Children {
+			child := &pattern.Children[i]
+			if child.Kind == "Spread" {
+				rest := it.newObject()
+				for _, k := range it.enumerableKeys(v) {
+					if !used[k] {
+						rest.set(k, it.getMember(v, k, child.Pos))
+					}
+				}
+				it.bindPattern(&child.Children[0], rest, scope, bind)
+				continue
+			}
+			key := it.propertyName(child, scope)
+			used[key] = true
+			value := it.getMember(v, key, child.Pos)
+			switch {
+			case len(child.Children) == 0:
+				it.bindPattern(&ParseNode{Kind: "Identifier", Label: key, Pos: child.Pos}, value, scope, bind)
+			case strings.Contains(it.src[child.Pos:child.Children[0].Pos], ":"):
+				it.bindPattern(&child.Children[0], value, scope, bind)
+			default:
+				// {a = 1}: valor por defecto de un nombre abreviado
+				if value == jsUndefined {
+					value = it.eval(&child.Children[0], scope)
+				}
+				it.bindPattern(&ParseNode{Kind: "Identifier", Label: key, Pos: child.Pos}, value, scope, bind)
+			}
+		}
+	case "Member", "Index":
+		it.assign(pattern, v, scope)
+	default:
+		it.throwError("SyntaxError", pattern.Pos, "Invalid destructuring assignment target")
+	}
+}
+
+// propertyName devuelve la clave de una propiedad de un objeto literal o
+// patrón: un nombre, una cadena, un número o una clave calculada [expr]
+func (it *jsInterp) propertyName(n *ParseNode, scope *jsScope) string {
+	if exprSrc := it.computedKey(n); exprSrc != "" {
+		expr := NewParser(Tokenize(exprSrc, "javascript"), "javascript", exprSrc).parseExpression()
+		return it.propertyKey(it.eval(&expr, scope))
+	}
+	label := n.Label
+	if strings.HasPrefix(label, "'") || strings.HasPrefix(label, "\"") {
+		return jsUnquote(label)
+	}
+	if label != "" && (label[0] >= '0' && label[0] <= '9' || label[0] == '.') {
+		return jsNumberToString(jsParseNumber(label))
+	}
+	return label
+}
+
+// computedKey devuelve el código de una clave calculada [expr], o "" si la
+// clave no lo es
+func (it *jsInterp) computedKey(n *ParseNode) string {
+	s := it.src[n.Pos:n.End]
+	for _, mod := range []string{"static", "get", "set", "async"} {
+		if rest := strings.TrimLeft(strings.TrimPrefix(s, mod), " \t\r\n"); rest != s && strings.HasPrefix(rest, "[") {
+			s = rest
+		}
+	}
+	if !strings.HasPrefix(s, "[") {
+		return ""
+	}
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return s[1:i]
+			}
+		}
+	}
+	return ""
+}
+
+func (it *jsInterp) objectLiteral(n *ParseNode, scope *jsScope) jsValue {
+	obj := it.newObject()
+	for i := range n.Children {
+		child := &n.Children[i]
+		switch child.Kind {
+		case "Spread":
+			src := it.eval(&child.Children[0], scope)
+			for _, k := range it.enumerableKeys(src) {
+				obj.set(k, it.getMember(src, k, child.Pos))
+			}
+		case "Method":
+			key := it.propertyName(child, scope)
+			fn := it.makeFunction(child, scope)
+			fn.name = key
+			switch {
+			case jsHasModifier(it.src[child.Pos:], "get"):
+				obj.defineGetter(key, fn)
+				obj.addKey(key)
+			case jsHasModifier(it.src[child.Pos:], "set"):
+				obj.defineSetter(key, fn)
+				obj.addKey(key)
+			default:
+				obj.set(key, fn)
+			}
+		default:
+			key := it.propertyName(child, scope)
+			var value jsValue
+			if len(child.Children) == 0 {
+				value = it.identifier(&ParseNode{Kind: "Identifier", Label: key, Pos: child.Pos}, scope)
+			} else {
+				value = it.eval(&child.Children[0], scope)
+				it.nameFunction(value, key)
+			}
+			obj.set(key, value)
+		}
+	}
+	return obj
+}
+
+// jsHasModifier indica si el miembro que empieza en src tiene el
+// modificador mod (static, get, set, async) antes de su nombre
+func jsHasModifier(src, mod string) bool {
+	for {
+		src = strings.TrimLeft(src, " \t\r\n")
+		end := 0
+		for end < len(src) && (isIdentByte(src[end])) {
+			end++
+		}
+		word := src[:end]
+		rest := strings.TrimLeft(src[end:], " \t\r\n")
+		if word == "" || rest == "" {
+			return false
+		}
+		if c := rest[0]; !(isIdentByte(c) || c == '[' || c == '*' || c == '\'' || c == '"') {
+			return false
+		}
+		if word == mod {
+			return true
+		}
+		if word != "static" && word != "get" && word != "set" && word != "async" {
+			return false
+		}
+		src = rest
+	}
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+// ───── Funciones y clases ─────
+
+func (it *jsInterp) makeFunction(n *ParseNode, scope *jsScope) *jsFunction {
+	fn := &jsFunction{name: n.Label, node: n, scope: scope, arrow: n.Kind == "ArrowFunction"}
+	if fn.arrow {
+		fn.name = ""
+	}
+	for i := range n.Children {
+		switch n.Children[i].Kind {
+		case "Params":
+			fn.params = &n.Children[i]
+		case "Block":
+			fn.body = &n.Children[i]
+		}
+	}
+	if fn.arrow && fn.body == nil {
+		fn.body = &n.Children[len(n.Children)-1]
+	}
+	if fn.body == nil {
+		fn.body = &ParseNode{Kind: "Block"}
+	}
+	it.b.alloc(64)
+	return fn
+}
+
+// nameFunction da nombre a una función anónima asignada a una variable o
+// propiedad (const f = () => {}), como hace JavaScript
+func (it *jsInterp) nameFunction(v jsValue, name string) {
+	if fn, ok := v.(*jsFunction); ok && fn.name == "" && fn.native == nil {
+		fn.name = name
+	}
+}
+
+// makeClass crea una clase: constructor, métodos en el prototipo,
+// miembros estáticos y campos de instancia
+func (it *jsInterp) makeClass(n *ParseNode, scope *jsScope) *jsFunction {
+	cls := &jsFunction{name: n.Label, node: n, scope: scope, class: true, props: it.newObject(), proto: it.newObject()}
+	cls.proto.class = cls
+	classScope := newJSScope(scope)
+	if n.Label != "" {
+		classScope.declare(n.Label, cls, true)
+	}
+	var body *ParseNode
+	for i := range n.Children {
+		switch n.Children[i].Kind {
+		case "Extends":
+			base := it.eval(&n.Children[i].Children[0], scope)
+			parent, ok := base.(*jsFunction)
+			if !ok || parent.proto == nil && parent.construct == nil {
+				it.throwError("TypeError", n.Children[i].Pos, "Class extends value %s is not a constructor or null", it.inspect(base))
+			}
+			cls.parent = parent
+			cls.proto.proto = it.prototypeOf(parent)
+			cls.props.proto = parent.props
+		case "ClassBody":
+			body = &n.Children[i]
+		}
+	}
+	var statics []*ParseNode
+	for i := range body.Children {
+		member := &body.Children[i]
+		static := jsHasModifier(it.src[member.Pos:], "static")
+		switch member.Kind {
+		case "Constructor":
+			cls.ctor = it.makeFunction(member, classScope)
+			cls.ctor.home = cls
+			cls.ctor.name = cls.name
+		case "Method":
+			key := it.propertyName(member, classScope)
+			fn := it.makeFunction(member, classScope)
+			fn.name, fn.home = key, cls
+			target := cls.proto
+			if static {
+				target = cls.props
+			}
+			switch {
+			case jsHasModifier(it.src[member.Pos:], "get"):
+				target.defineGetter(key, fn)
+			case jsHasModifier(it.src[member.Pos:], "set"):
+				target.defineSetter(key, fn)
+			default:
+				target.props[key] = fn
+			}
+		case "Field", "StaticBlock":
+			if static || member.Kind == "StaticBlock" {
+				statics = append(statics, member)
+			} else {
+				cls.fields = append(cls.fields, member)
+			}
+		}
+	}
+	// Los miembros estáticos se evalúan con this = la clase
+	staticScope := newJSScope(classScope)
+	staticScope.function, staticScope.this = true, cls
+	staticScope.fn = &jsFunction{home: cls}
+	for _, member := range statics {
+		if member.Kind == "StaticBlock" {
+			it.exec(&member.Children[0], staticScope)
+			continue
+		}
+		var value jsValue = jsUndefined
+		if len(member.Children) > 0 {
+			value = it.eval(&member.Children[len(member.Children)-1], staticScope)
+		}
+		cls.props.set(it.propertyName(member, classScope), value)
+	}
+	cls.scope = classScope
+	it.b.alloc(256)
+	return cls
+}
+
+// prototypeOf devuelve el prototipo de las instancias de un constructor; el
+// de una función común se crea al pedirlo
+func (it *jsInterp) prototypeOf(fn *jsFunction) *jsObject {
+	if fn.proto == nil {
+		fn.proto = it.newObject()
+		fn.proto.class = fn
+	}
+	return fn.proto
+}
+
+// construct ejecuta new callee(args)
+func (it *jsInterp) construct(callee jsValue, args []jsValue, pos int) jsValue {
+	if !jsConstructible(callee) {
+		it.throwError("TypeError", pos, "%s is not a constructor", it.inspect(callee))
+	}
+	fn := callee.(*jsFunction)
+	if fn.construct != nil {
+		it.pos = pos
+		return fn.construct(it, args)
+	}
+	obj := &jsObject{props: make(map[string]jsValue), proto: it.prototypeOf(fn), class: fn}
+	it.b.alloc(64)
+	it.pos = pos
+	// Los errores creados por una subclase apuntan al new, no al super()
+	defer func(newPos int) { it.newPos = newPos }(it.newPos)
+	it.newPos = pos
+	if result := it.initInstance(fn, obj, args, pos); result != nil {
+		return result
+	}
+	return obj
+}
+
+// initInstance inicializa obj como instancia de cls: primero la clase base
+// (con super() o implícitamente), después los campos y el constructor. Si
+// una función constructora devuelve un objeto, ese es el resultado
+func (it *jsInterp) initInstance(cls *jsFunction, obj *jsObject, args []jsValue, pos int) jsValue {
+	if cls.init != nil {
+		cls.init(it, obj, args)
+		return nil
+	}
+	if !cls.class {
+		result := it.callFunction(cls, obj, args, pos)
+		if _, isObj := result.(*jsObject); isObj {
+			return result
+		}
+		return nil
+	}
+	if cls.ctor == nil {
+		if cls.parent != nil {
+			it.initInstance(cls.parent, obj, args, pos)
+		}
+		it.initFields(cls, obj)
+		return nil
+	}
+	if cls.parent == nil {
+		it.initFields(cls, obj)
+	}
+	it.callFunction(cls.ctor, obj, args, pos)
+	return nil
+}
+
+// initFields asigna los campos de instancia declarados en la clase
+func (it *jsInterp) initFields(cls *jsFunction, obj *jsObject) {
+	if len(cls.fields) == 0 {
+		return
+	}
+	scope := newJSScope(cls.scope)
+	scope.function, scope.this = true, obj
+	scope.fn = &jsFunction{home: cls}
+	for _, field := range cls.fields {
+		var value jsValue = jsUndefined
+		if len(field.Children) > 0 {
+			value = it.eval(&field.Children[len(field.Children)-1], scope)
+		}
+		key := it.propertyName(field, scope)
+		it.nameFunction(value, key)
+		obj.set(key, value)
+	}
+}
+
+// homeClass devuelve la clase del método en curso, para super
+func (it *jsInterp) homeClass(scope *jsScope, pos int) *jsFunction {
+	s := scope.thisScope()
+	if s.fn == nil || s.fn.home == nil || s.fn.home.parent == nil {
+		it.throwError("SyntaxError", pos, "'super' keyword unexpected here")
+	}
+	return s.fn.home
+}
+
+// superMember devuelve super.name: el miembro de la clase base
+func (it *jsInterp) superMember(name string, scope *jsScope, pos int) jsValue {
+	home := it.homeClass(scope, pos)
+	this := scope.thisScope().this
+	if _, isClass := this.(*jsFunction); isClass {
+		return it.lookupProperty(home.parent.props, name, this)
+	}
+	return it.lookupProperty(it.prototypeOf(home.parent), name, this)
+}
+
+func (it *jsInterp) evalArgs(n *ParseNode, scope *jsScope) []jsValue {
+	args := make([]jsValue, 0, len(n.Children))
+	for i := range n.Children {
+		child := &n.Children[i]
+		if child.Kind == "Spread" {
+			args = append(args, it.iterate(it.eval(&child.Children[0], scope), child.Pos)...)
+		} else {
+			args = append(args, it.eval(child, scope))
+		}
+	}
+	return args
+}
+
+func (it *jsInterp) evalCall(n *ParseNode, scope *jsScope) jsValue {
+	callee := &n.Children[0]
+	var fn, this jsValue = nil, jsUndefined
+	switch {
+	case callee.Kind == "Identifier" && callee.Label == "super":
+		home := it.homeClass(scope, n.Pos)
+		obj, _ := scope.thisScope().this.(*jsObject)
+		it.pos = n.Pos
+		it.initInstance(home.parent, obj, it.evalArgs(&n.Children[1], scope), n.Pos)
+		it.initFields(home, obj)
+		return jsUndefined
+	case callee.Kind == "Member":
+		obj := &callee.Children[0]
+		if obj.Kind == "Identifier" && obj.Label == "super" {
+			fn, this = it.superMember(callee.Label, scope, callee.Pos), scope.thisScope().this
+			break
+		}
+		this = it.eval(obj, scope)
+		if (this == jsUndefined || this == jsNull) && it.optional(obj, callee) {
+			return jsUndefined
+		}
+		fn = it.getMember(this, callee.Label, jsPropertyPos(callee))
+	case callee.Kind == "Index":
+		this = it.eval(&callee.Children[0], scope)
+		fn = it.getIndex(this, it.eval(&callee.Children[1], scope), callee.Pos)
+	default:
+		fn = it.eval(callee, scope)
+	}
+	if (fn == jsUndefined || fn == jsNull) && it.optional(callee, n) {
+		return jsUndefined
+	}
+	args := it.evalArgs(&n.Children[1], scope)
+	if _, ok := fn.(*jsFunction); !ok {
+		pos := n.Pos
+		if callee.Kind == "Member" {
+			pos = jsPropertyPos(callee)
+		}
+		it.throwError("TypeError", pos, "%s is not a function", it.src[callee.Pos:callee.End])
+	}
+	return it.call(fn, this, args, n.Pos)
+}
+
+// jsPropertyPos devuelve dónde empieza el nombre de la propiedad de a.b,
+// que es lo que node marca cuando falla el acceso o la llamada
+func jsPropertyPos(n *ParseNode) int {
+	if pos := n.End - len(n.Label); pos > n.Pos {
+		return pos
+	}
+	return n.Pos
+}
+
+// jsConstructible indica si v se puede usar con new
+func jsConstructible(v jsValue) bool {
+	fn, ok := v.(*jsFunction)
+	return ok && !fn.arrow && (fn.native == nil || fn.construct != nil || fn.init != nil)
+}
+
+// call llama a fn con this y args
+func (it *jsInterp) call(fn jsValue, this jsValue, args []jsValue, pos int) jsValue {
+	f, ok := fn.(*jsFunction)
+	if !ok {
+		it.throwError("TypeError", pos, "%s is not a function", it.inspect(fn))
+	}
+	it.pos = pos
+	if f.native != nil {
+		it.b.step()
+		return f.native(it, this, args)
+	}
+	if f.class {
+		it.throwError("TypeError", pos, "Class constructor %s cannot be invoked without 'new'", f.name)
+	}
+	return it.callFunction(f, this, args, pos)
+}
+
+// callFunction ejecuta el cuerpo de una función del programa
+func (it *jsInterp) callFunction(f *jsFunction, this jsValue, args []jsValue, pos int) jsValue {
+	it.b.step()
+	if it.depth >= jsMaxCallDepth {
+		it.throwError("RangeError", pos, "Maximum call stack size exceeded")
+	}
+	it.depth++
+	defer func() { it.depth-- }()
+	scope := newJSScope(f.scope)
+	scope.function, scope.fn = true, f
+	if !f.arrow {
+		scope.this = this
+		scope.declare("arguments", &jsArray{items: args}, false)
+	}
+	if f.params != nil {
+		for i := range f.params.Children {
+			param := &f.params.Children[i]
+			var arg jsValue = jsUndefined
+			if strings.HasPrefix(param.Label, "...") {
+				var rest []jsValue
+				if i < len(args) {
+					rest = append(rest, args[i:]...)
+				}
+				arg = it.newArray(rest)
+			} else if i < len(args) {
+				arg = args[i]
+			}
+			var pattern *ParseNode
+			for j := range param.Children {
+				child := &param.Children[j]
+				if j == 0 && (child.Kind == "ObjectPattern" || child.Kind == "ArrayPattern") {
+					pattern = child
+				} else if arg == jsUndefined {
+					arg = it.eval(child, scope)
+				}
+			}
+			if pattern != nil {
+				it.bindPattern(pattern, arg, scope, func(name string, v jsValue) { scope.declare(name, v, false) })
+			} else {
+				scope.declare(strings.TrimPrefix(param.Label, "..."), arg, false)
+			}
+		}
+	}
+	if it.b.trace != nil {
+		it.traceEvent(traceCall, it.b.trace.line, scope, nil)
+	}
+	var result jsValue = jsUndefined
+	if f.body.Kind != "Block" {
+		result = it.eval(f.body, scope)
+	} else if c, v := it.execStatements(f.body.Children, scope); c == jsReturn {
+		result = v
+	}
+	if it.b.trace != nil {
+		it.traceEvent(traceReturn, it.b.trace.line, scope, result)
+	}
+	return result
+}
+
+func (it *jsInterp) instanceOf(v, class jsValue, pos int) bool {
+	cls, ok := class.(*jsFunction)
+	if !ok {
+		it.throwError("TypeError", pos, "Right-hand side of 'instanceof' is not callable")
+	}
+	switch v := v.(type) {
+	case *jsArray:
+		return cls == it.arrayClass || cls == it.objectClass
+	case *jsMap:
+		return cls == it.objectClass || v.set && cls.name == "Set" || !v.set && cls.name == "Map"
+	case *jsFunction:
+		return cls == it.objectClass || cls.name == "Function"
+	case *jsObject:
+		if cls == it.objectClass {
+			return true
+		}
+		target := it.prototypeOf(cls)
+		for p := v.proto; p != nil; p = p.proto {
+			if p == target {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ───── Valores ─────
+
+func (it *jsInterp) newObject() *jsObject {
+	it.b.alloc(64)
+	return &jsObject{props: make(map[string]jsValue)}
+}
+
+func (it *jsInterp) newArray(items []jsValue) *jsArray {
+	it.b.alloc(16*len(items) + 32)
+	if items == nil {
+		items = []jsValue{}
+	}
+	return &jsArray{items: items}
+}
+
+// set define o cambia una propiedad enumerable propia
+func (o *jsObject) set(key string, v jsValue) {
+	if _, exists := o.props[key]; !exists && o.getters[key] == nil && o.setters[key] == nil {
+		o.keys = append(o.keys, key)
+	}
+	o.props[key] = v
+}
+
+// addKey hace enumerable un getter o setter propio, como los de un objeto
+// literal
+func (o *jsObject) addKey(key string) {
+	if _, exists := o.props[key]; exists {
+		return
+	}
+	for _, k := range o.keys {
+		if k == key {
+			return
+		}
+	}
+	o.keys = append(o.keys, key)
+}
+
+func (o *jsObject) defineGetter(key string, fn *jsFunction) {
+	if o.getters == nil {
+		o.getters = make(map[string]*jsFunction)
+	}
+	o.getters[key] = fn
+}
+
+func (o *jsObject) defineSetter(key string, fn *jsFunction) {
+	if o.setters == nil {
+		o.setters = make(map[string]*jsFunction)
+	}
+	o.setters[key] = fn
+}
+
+func (o *jsObject) remove(key string) {
+	delete(o.props, key)
+	delete(o.getters, key)
+	delete(o.setters, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// lookupProperty busca key en o y su cadena de prototipos; los getters se
+// llaman con this. nil si no existe
+func (it *jsInterp) lookupProperty(o *jsObject, key string, this jsValue) jsValue {
+	for ; o != nil; o = o.proto {
+		if v, ok := o.props[key]; ok {
+			return v
+		}
+		if g := o.getters[key]; g != nil {
+			return it.call(g, this, nil, it.pos)
+		}
+		if o.setters[key] != nil {
+			return jsUndefined
+		}
+	}
+	return nil
+}
+
+func (it *jsInterp) getMember(v jsValue, key string, pos int) jsValue {
+	switch obj := v.(type) {
+	case jsUndefinedType, jsNullType:
+		it.throwError("TypeError", pos, "Cannot read properties of %s (reading '%s')", it.toString(v), key)
+	case *jsObject:
+		if found := it.lookupProperty(obj, key, obj); found != nil {
+			return found
+		}
+		if key == "stack" && obj.trace != "" {
+			return it.errorHeader(obj) + "\n" + obj.trace
+		}
+		return it.objectMethod(obj, key)
+	case *jsArray:
+		if key == "length" {
+			return float64(len(obj.items))
+		}
+		if i, ok := jsArrayIndex(key); ok {
+			if i < len(obj.items) {
+				return obj.items[i]
+			}
+			return jsUndefined
+		}
+		return it.arrayMethod(obj, key)
+	case string:
+		if key == "length" {
+			return float64(jsStringLength(obj))
+		}
+		if i, ok := jsArrayIndex(key); ok {
+			if ch, ok := jsCharAt(obj, i); ok {
+				return ch
+			}
+			return jsUndefined
+		}
+		return it.stringMethod(obj, key)
+	case *jsFunction:
+		if obj.props != nil {
+			if found := it.lookupProperty(obj.props, key, obj); found != nil {
+				return found
+			}
+		}
+		return it.functionMember(obj, key)
+	case *jsMap:
+		return it.mapMethod(obj, key)
+	case float64:
+		return it.numberMethod(obj, key)
+	case bool:
+		if key == "toString" {
+			return it.native("toString", func(it *jsInterp, this jsValue, args []jsValue) jsValue { return it.toString(obj) })
+		}
+	}
+	return jsUndefined
+}
+
+func (it *jsInterp) getIndex(v, key jsValue, pos int) jsValue {
+	if n, ok := key.(float64); ok {
+		switch obj := v.(type) {
+		case *jsArray:
+			if i := int(n); float64(i) == n && i >= 0 {
+				if i < len(obj.items) {
+					return obj.items[i]
+				}
+				return jsUndefined
+			}
+		case string:
+			if i := int(n); float64(i) == n && i >= 0 {
+				if ch, ok := jsCharAt(obj, i); ok {
+					return ch
+				}
+				return jsUndefined
+			}
+		}
+	}
+	return it.getMember(v, it.propertyKey(key), pos)
+}
+
+func (it *jsInterp) setMember(v jsValue, key string, value jsValue, pos int) {
+	switch obj := v.(type) {
+	case jsUndefinedType, jsNullType:
+		it.throwError("TypeError", pos, "Cannot set properties of %s (setting '%s')", it.toString(v), key)
+	case *jsObject:
+		if _, own := obj.props[key]; !own {
+			for p := obj; p != nil; p = p.proto {
+				if s := p.setters[key]; s != nil {
+					it.call(s, obj, []jsValue{value}, pos)
+					return
+				}
+				if p.getters[key] != nil {
+					return
+				}
+			}
+			it.b.alloc(48)
+		}
+		obj.set(key, value)
+	case *jsArray:
+		if key == "length" {
+			n := it.toNumber(value)
+			if n < 0 || n != math.Trunc(n) || n > math.MaxUint32 {
+				it.throwError("RangeError", pos, "Invalid array length")
+			}
+			it.resize(obj, int(n))
+			return
+		}
+		if i, ok := jsArrayIndex(key); ok {
+			it.setArrayItem(obj, i, value)
+		}
+	case *jsFunction:
+		if obj.props == nil {
+			obj.props = it.newObject()
+		}
+		if key == "prototype" {
+			if p, ok := value.(*jsObject); ok {
+				obj.proto = p
+			}
+			return
+		}
+		obj.props.set(key, value)
+	}
+}
+
+func (it *jsInterp) setIndex(v, key, value jsValue, pos int) {
+	if arr, ok := v.(*jsArray); ok {
+		if n, ok := key.(float64); ok {
+			if i := int(n); float64(i) == n && i >= 0 {
+				it.setArrayItem(arr, i, value)
+				return
+			}
+		}
+	}
+	it.setMember(v, it.propertyKey(key), value, pos)
+}
+
+func (it *jsInterp) setArrayItem(arr *jsArray, i int, value jsValue) {
+	if i >= len(arr.items) {
+		it.resize(arr, i+1)
+	}
+	arr.items[i] = value
+}
+
+// resize cambia el largo de un arreglo; los lugares nuevos son undefined
+func (it *jsInterp) resize(arr *jsArray, n int) {
+	if n <= len(arr.items) {
+		arr.items = arr.items[:n]
+		return
+	}
+	it.b.alloc(16 * (n - len(arr.items)))
+	for len(arr.items) < n {
+		arr.items = append(arr.items, jsUndefined)
+	}
+}
+
+func (it *jsInterp) deleteProperty(v jsValue, key string) {
+	switch obj := v.(type) {
+	case *jsObject:
+		obj.remove(key)
+	case *jsArray:
+		if i, ok := jsArrayIndex(key); ok && i < len(obj.items) {
+			obj.items[i] = jsUndefined
+		}
+	case *jsFunction:
+		if obj.props != nil {
+			obj.props.remove(key)
+		}
+	}
+}
+
+func (it *jsInterp) hasProperty(v jsValue, key string, pos int) bool {
+	switch obj := v.(type) {
+	case *jsObject:
+		for o := obj; o != nil; o = o.proto {
+			if _, ok := o.props[key]; ok || o.getters[key] != nil {
+				return true
+			}
+		}
+		// Los métodos de Object.prototype: "toString" in {} es true
+		switch key {
+		case "hasOwnProperty", "toString", "valueOf", "constructor":
+			return true
+		}
+		return false
+	case *jsArray:
+		i, ok := jsArrayIndex(key)
+		return key == "length" || ok && i < len(obj.items)
+	case *jsFunction:
+		return obj.props != nil && it.hasProperty(obj.props, key, pos)
+	case *jsMap:
+		return key == "size"
+	}
+	it.throwError("TypeError", pos, "Cannot use 'in' operator to search for '%s' in %s", key, it.toString(v))
+	return false
+}
+
+// enumerableKeys devuelve las claves que recorren for...in y Object.keys
+func (it *jsInterp) enumerableKeys(v jsValue) []string {
+	switch obj := v.(type) {
+	case *jsObject:
+		keys := append([]string(nil), obj.keys...)
+		return jsSortKeys(keys)
+	case *jsArray:
+		keys := make([]string, len(obj.items))
+		for i := range obj.items {
+			keys[i] = strconv.Itoa(i)
+		}
+		return keys
+	case string:
+		keys := make([]string, jsStringLength(obj))
+		for i := range keys {
+			keys[i] = strconv.Itoa(i)
+		}
+		return keys
+	case *jsFunction:
+		if obj.props != nil {
+			return jsSortKeys(append([]string(nil), obj.props.keys...))
+		}
+	}
+	return nil
+}
+
+// jsSortKeys ordena las claves como JavaScript: primero los índices enteros
+// de menor a mayor y después el resto en orden de creación
+func jsSortKeys(keys []string) []string {
+	var indices, others []string
+	for _, k := range keys {
+		if _, ok := jsArrayIndex(k); ok {
+			indices = append(indices, k)
+		} else {
+			others = append(others, k)
+		}
+	}
+	if len(indices) == 0 {
+		return keys
+	}
+	for i := 1; i < len(indices); i++ {
+		for j := i; j > 0; j-- {
+			a, _ := jsArrayIndex(indices[j-1])
+			b, _ := jsArrayIndex(indices[j])
+			if a <= b {
+				break
+			}
+			indices[j-1], indices[j] = indices[j], indices[j-1]
+		}
+	}
+	return append(indices, others...)
+}
+
+// iterate devuelve los elementos que recorre for...of o un spread
+func (it *jsInterp) iterate(v jsValue, pos int) []jsValue {
+	switch obj := v.(type) {
+	case *jsArray:
+		return append([]jsValue(nil), obj.items...)
+	case string:
+		items := make([]jsValue, 0, len(obj))
+		for _, r := range obj {
+			items = append(items, string(r))
+		}
+		it.b.alloc(16 * len(items))
+		return items
+	case *jsMap:
+		if obj.set {
+			return append([]jsValue(nil), obj.keys...)
+		}
+		items := make([]jsValue, len(obj.keys))
+		for i := range obj.keys {
+			items[i] = it.newArray([]jsValue{obj.keys[i], obj.values[i]})
+		}
+		return items
+	}
+	if v == jsUndefined || v == jsNull {
+		it.throwError("TypeError", pos, "%s is not iterable", it.describeIterable(v))
+	}
+	it.throwError("TypeError", pos, "%s is not iterable (cannot read property Symbol(Symbol.iterator))", it.describeIterable(v))
+	return nil
+}
+
+func (it *jsInterp) describeIterable(v jsValue) string {
+	switch v.(type) {
+	case *jsObject:
+		return "object"
+	case float64:
+		return "number " + it.toString(v)
+	}
+	return it.toString(v)
+}
+
+// jsArrayIndex interpreta una clave como índice de arreglo
+func jsArrayIndex(key string) (int, bool) {
+	if key == "" || len(key) > 10 || len(key) > 1 && key[0] == '0' {
+		return 0, false
+	}
+	n := 0
+	for i := 0; i < len(key); i++ {
+		if key[i] < '0' || key[i] > '9' {
+			return 0, false
+		}
+		n = n*10 + int(key[i]-'0')
+	}
+	return n, n < math.MaxUint32
+}
+
+// ───── Conversiones ─────
+
+func (it *jsInterp) truthy(v jsValue) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0 && !math.IsNaN(v)
+	case string:
+		return v != ""
+	case jsUndefinedType, jsNullType:
+		return false
+	}
+	return true
+}
+
+func (it *jsInterp) typeOf(v jsValue) string {
+	switch v.(type) {
+	case jsUndefinedType:
+		return "undefined"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case *jsFunction:
+		return "function"
+	}
+	return "object"
+}
+
+// toPrimitive convierte un objeto en primitivo con su valueOf o toString
+func (it *jsInterp) toPrimitive(v jsValue, preferNumber bool) jsValue {
+	switch obj := v.(type) {
+	case *jsObject:
+		methods := []string{"toString", "valueOf"}
+		if preferNumber {
+			methods = []string{"valueOf", "toString"}
+		}
+		for _, name := range methods {
+			if fn, ok := it.lookupProperty(obj, name, obj).(*jsFunction); ok {
+				if r := it.call(fn, obj, nil, it.pos); !isJSObject(r) {
+					return r
+				}
+			}
+		}
+		if obj.trace != "" {
+			return it.errorHeader(obj)
+		}
+		return "[object Object]"
+	case *jsArray, *jsFunction, *jsMap:
+		return it.toString(v)
+	}
+	return v
+}
+
+func isJSObject(v jsValue) bool {
+	switch v.(type) {
+	case *jsObject, *jsArray, *jsFunction, *jsMap:
+		return true
+	}
+	return false
+}
+
+func (it *jsInterp) toString(v jsValue) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return jsNumberToString(v)
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	case jsUndefinedType:
+		return "undefined"
+	case jsNullType:
+		return "null"
+	case *jsArray:
+		parts := make([]string, len(v.items))
+		for i, item := range v.items {
+			if item != jsUndefined && item != jsNull {
+				parts[i] = it.toString(item)
+			}
+		}
+		s := strings.Join(parts, ",")
+		it.b.alloc(len(s))
+		return s
+	case *jsFunction:
+		if v.native != nil || v.node == nil {
+			return "function " + v.name + "() { [native code] }"
+		}
+		return it.src[v.node.Pos:v.node.End]
+	case *jsMap:
+		if v.set {
+			return "[object Set]"
+		}
+		return "[object Map]"
+	case *jsObject:
+		return it.toString(it.toPrimitive(v, false))
+	}
+	return ""
+}
+
+func (it *jsInterp) toNumber(v jsValue) float64 {
+	switch v := v.(type) {
+	case float64:
+		return v
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	case string:
+		return jsStringToNumber(v)
+	case jsNullType:
+		return 0
+	case jsUndefinedType:
+		return math.NaN()
+	case *jsObject, *jsArray:
+		return it.toNumber(it.toPrimitive(v, true))
+	}
+	return math.NaN()
+}
+
+// propertyKey convierte un valor en clave de propiedad
+func (it *jsInterp) propertyKey(v jsValue) string {
+	return it.toString(v)
+}
+
+// jsToInt32 convierte un número en entero de 32 bits como los operadores
+// de bits
+func jsToInt32(f float64) int32 {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0
+	}
+	return int32(uint32(int64(math.Mod(math.Trunc(f), 1<<32))))
+}
+
+func jsStringToNumber(s string) float64 {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "":
+		return 0
+	case s == "Infinity" || s == "+Infinity":
+		return math.Inf(1)
+	case s == "-Infinity":
+		return math.Inf(-1)
+	}
+	if len(s) > 2 && s[0] == '0' {
+		base := map[byte]int{'x': 16, 'X': 16, 'o': 8, 'O': 8, 'b': 2, 'B': 2}[s[1]]
+		if base != 0 {
+			if n, err := strconv.ParseUint(s[2:], base, 64); err == nil {
+				return float64(n)
+			}
+			return math.NaN()
+		}
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !(c >= '0' && c <= '9' || c == '.' || c == 'e' || c == 'E' || c == '+' || c == '-') {
+			return math.NaN()
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil && !strings.Contains(err.Error(), "range") {
+		return math.NaN()
+	}
+	return n
+}
+
+// jsParseNumber interpreta un literal numérico del programa
+func jsParseNumber(s string) float64 {
+	s = strings.ReplaceAll(s, "_", "")
+	if n := jsStringToNumber(strings.TrimSuffix(s, "n")); !math.IsNaN(n) || s == "NaN" {
+		return n
+	}
+	return math.NaN()
+}
+
+// jsNumberToString formatea un número como Number.prototype.toString
+func jsNumberToString(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	case f == 0:
+		return "0"
+	}
+	sign := ""
+	if f < 0 {
+		sign, f = "-", -f
+	}
+	// Dígitos significativos más cortos y exponente: d.ddd × 10^(n-1)
+	mantissa, exp, _ := strings.Cut(strconv.FormatFloat(f, 'e', -1, 64), "e")
+	digits := strings.Replace(mantissa, ".", "", 1)
+	e, _ := strconv.Atoi(exp)
+	k, n := len(digits), e+1
+	switch {
+	case k <= n && n <= 21:
+		return sign + digits + strings.Repeat("0", n-k)
+	case 0 < n && n <= 21:
+		return sign + digits[:n] + "." + digits[n:]
+	case -6 < n && n <= 0:
+		return sign + "0." + strings.Repeat("0", -n) + digits
+	}
+	expSign := "+"
+	if n-1 < 0 {
+		expSign = "-"
+	}
+	exponent := "e" + expSign + strconv.Itoa(int(math.Abs(float64(n-1))))
+	if k == 1 {
+		return sign + digits + exponent
+	}
+	return sign + digits[:1] + "." + digits[1:] + exponent
+}
+
+// ───── Cadenas ─────
+//
+// Las cadenas se guardan en UTF-8, pero JavaScript cuenta posiciones en
+// unidades UTF-16: las cadenas con caracteres no ASCII se convierten al
+// indexarlas.
+
+func jsIsASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+func jsUTF16(s string) []uint16 {
+	return utf16.Encode([]rune(s))
+}
+
+func jsFromUTF16(u []uint16) string {
+	return string(utf16.Decode(u))
+}
+
+func jsStringLength(s string) int {
+	if jsIsASCII(s) {
+		return len(s)
+	}
+	return len(jsUTF16(s))
+}
+
+func jsCharAt(s string, i int) (string, bool) {
+	if jsIsASCII(s) {
+		if i < len(s) {
+			return s[i : i+1], true
+		}
+		return "", false
+	}
+	u := jsUTF16(s)
+	if i < len(u) {
+		return jsFromUTF16(u[i : i+1]), true
+	}
+	return "", false
+}
+
+// jsSubstring devuelve las unidades [start, end) de s
+func jsSubstring(s string, start, end int) string {
+	if jsIsASCII(s) {
+		return s[start:end]
+	}
+	return jsFromUTF16(jsUTF16(s)[start:end])
+}
+
+// jsUnquote decodifica un literal de cadena entre comillas
+func jsUnquote(lit string) string {
+	if len(lit) < 2 {
+		return ""
+	}
+	body := lit[1 : len(lit)-1]
+	if !strings.ContainsRune(body, '\\') {
+		return body
+	}
+	return jsUnescape(body)
+}
+
+// jsEscapeLength devuelve el largo de la secuencia de escape al comienzo de s
+func jsEscapeLength(s string) int {
+	if len(s) < 2 {
+		return len(s)
+	}
+	switch s[1] {
+	case 'x':
+		return min(4, len(s))
+	case 'u':
+		if len(s) > 2 && s[2] == '{' {
+			if end := strings.IndexByte(s, '}'); end > 0 {
+				return end + 1
+			}
+		}
+		return min(6, len(s))
+	}
+	_, size := utf8.DecodeRuneInString(s[1:])
+	return 1 + size
+}
+
+// jsUnescape decodifica las secuencias de escape de una cadena
+func jsUnescape(s string) string {
+	var sb strings.Builder
+	var pending []uint16 // mitades de un par sustituto 😀
+	flush := func() {
+		if len(pending) > 0 {
+			sb.WriteString(jsFromUTF16(pending))
+			pending = nil
+		}
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			flush()
+			sb.WriteByte(s[i])
+			continue
+		}
+		size := jsEscapeLength(s[i:])
+		esc := s[i+1 : i+size]
+		i += size - 1
+		switch esc[0] {
+		case 'n':
+			flush()
+			sb.WriteByte('\n')
+		case 't':
+			flush()
+			sb.WriteByte('\t')
+		case 'r':
+			flush()
+			sb.WriteByte('\r')
+		case 'b':
+			flush()
+			sb.WriteByte('\b')
+		case 'f':
+			flush()
+			sb.WriteByte('\f')
+		case 'v':
+			flush()
+			sb.WriteByte('\v')
+		case '0':
+			flush()
+			sb.WriteByte(0)
+		case '\n':
+			// Continuación de línea
+		case 'x', 'u':
+			hex := strings.Trim(esc[1:], "{}")
+			n, err := strconv.ParseUint(hex, 16, 32)
+			if err != nil {
+				flush()
+				sb.WriteString(esc)
+				continue
+			}
+			if n <= 0xFFFF && utf16.IsSurrogate(rune(n)) {
+				pending = append(pending, uint16(n))
+				continue
+			}
+			flush()
+			sb.WriteRune(rune(n))
+		default:
+			flush()
+			sb.WriteString(esc)
+		}
+	}
+	flush()
+	return sb.String()
+}
+
+// ───── Operadores ─────
+
+func (it *jsInterp) binary(op string, l, r jsValue) jsValue {
+	switch op {
+	case "+":
+		lp, rp := it.toPrimitive(l, false), it.toPrimitive(r, false)
+		ls, lok := lp.(string)
+		rs, rok := rp.(string)
+		if lok || rok {
+			if !lok {
+				ls = it.toString(lp)
+			}
+			if !rok {
+				rs = it.toString(rp)
+			}
+			it.b.alloc(len(ls) + len(rs))
+			return ls + rs
+		}
+		return it.toNumber(lp) + it.toNumber(rp)
+	case "-":
+		return it.toNumber(l) - it.toNumber(r)
+	case "*":
+		return it.toNumber(l) * it.toNumber(r)
+	case "/":
+		return it.toNumber(l) / it.toNumber(r)
+	case "%":
+		a, b := it.toNumber(l), it.toNumber(r)
+		if math.IsInf(b, 0) && !math.IsInf(a, 0) {
+			return a
+		}
+		return math.Mod(a, b)
+	case "**":
+		return math.Pow(it.toNumber(l), it.toNumber(r))
+	case "==":
+		return it.looseEquals(l, r)
+	case "!=":
+		return !it.looseEquals(l, r)
+	case "===":
+		return it.strictEquals(l, r)
+	case "!==":
+		return !it.strictEquals(l, r)
+	case "<", ">", "<=", ">=":
+		return it.compare(op, l, r)
+	case "&":
+		return float64(jsToInt32(it.toNumber(l)) & jsToInt32(it.toNumber(r)))
+	case "|":
+		return float64(jsToInt32(it.toNumber(l)) | jsToInt32(it.toNumber(r)))
+	case "^":
+		return float64(jsToInt32(it.toNumber(l)) ^ jsToInt32(it.toNumber(r)))
+	case "<<":
+		return float64(jsToInt32(it.toNumber(l)) << (uint32(jsToInt32(it.toNumber(r))) & 31))
+	case ">>":
+		return float64(jsToInt32(it.toNumber(l)) >> (uint32(jsToInt32(it.toNumber(r))) & 31))
+	case ">>>":
+		return float64(uint32(jsToInt32(it.toNumber(l))) >> (uint32(jsToInt32(it.toNumber(r))) & 31))
+	}
+	it.throwError("SyntaxError", it.pos, "Operator '%s' is not supported by the embedded interpreter", op)
+	return nil
+}
+
+func (it *jsInterp) compare(op string, l, r jsValue) bool {
+	lp, rp := it.toPrimitive(l, true), it.toPrimitive(r, true)
+	if ls, ok := lp.(string); ok {
+		if rs, ok := rp.(string); ok {
+			switch op {
+			case "<":
+				return ls < rs
+			case ">":
+				return ls > rs
+			case "<=":
+				return ls <= rs
+			}
+			return ls >= rs
+		}
+	}
+	a, b := it.toNumber(lp), it.toNumber(rp)
+	switch op {
+	case "<":
+		return a < b
+	case ">":
+		return a > b
+	case "<=":
+		return a <= b
+	}
+	return a >= b
+}
+
+func (it *jsInterp) strictEquals(l, r jsValue) bool {
+	switch a := l.(type) {
+	case float64:
+		b, ok := r.(float64)
+		return ok && a == b
+	case string:
+		b, ok := r.(string)
+		return ok && a == b
+	case bool:
+		b, ok := r.(bool)
+		return ok && a == b
+	}
+	return l == r
+}
+
+// sameValueZero compara como includes y las claves de Map: NaN es igual a NaN
+func (it *jsInterp) sameValueZero(l, r jsValue) bool {
+	if a, ok := l.(float64); ok {
+		if b, ok := r.(float64); ok && math.IsNaN(a) && math.IsNaN(b) {
+			return true
+		}
+	}
+	return it.strictEquals(l, r)
+}
+
+func (it *jsInterp) looseEquals(l, r jsValue) bool {
+	lNullish := l == jsUndefined || l == jsNull
+	rNullish := r == jsUndefined || r == jsNull
+	if lNullish || rNullish {
+		return lNullish && rNullish
+	}
+	if isJSObject(l) && isJSObject(r) {
+		return l == r
+	}
+	if isJSObject(l) {
+		l = it.toPrimitive(l, true)
+	}
+	if isJSObject(r) {
+		r = it.toPrimitive(r, true)
+	}
+	if ls, ok := l.(string); ok {
+		if rs, ok := r.(string); ok {
+			return ls == rs
+		}
+	}
+	return it.toNumber(l) == it.toNumber(r)
+}
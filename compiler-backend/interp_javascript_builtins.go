@@ -0,0 +1,2401 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ────────────── Biblioteca estándar del intérprete integrado ─────────────
+//
+// Los objetos globales (console, Math, JSON, process, Map...) y los métodos
+// de cadenas, números, arreglos y funciones. console.log formatea los
+// valores con las mismas reglas que util.inspect de node (comillas, una
+// línea si entra en 80 columnas, agrupación de arreglos largos, [Object] a
+// partir del tercer nivel), así la salida coincide con la esperada en el
+// modo juez.
+
+// native crea una función nativa
+func (it *jsInterp) native(name string, fn func(it *jsInterp, this jsValue, args []jsValue) jsValue) *jsFunction {
+	return &jsFunction{name: name, native: fn}
+}
+
+// arg devuelve el argumento i, o undefined si no se pasó
+func arg(args []jsValue, i int) jsValue {
+	if i < len(args) {
+		return args[i]
+	}
+	return jsUndefined
+}
+
+// nativeObject crea un objeto con métodos nativos
+func (it *jsInterp) nativeObject(methods map[string]func(it *jsInterp, this jsValue, args []jsValue) jsValue) *jsObject {
+	obj := it.newObject()
+	names := make([]string, 0, len(methods))
+	for name := range methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		obj.set(name, it.native(name, methods[name]))
+	}
+	return obj
+}
+
+// ───── Objetos globales ─────
+
+func (it *jsInterp) installGlobals() {
+	def := func(name string, v jsValue) { it.global.declare(name, v, false) }
+	def("NaN", math.NaN())
+	def("Infinity", math.Inf(1))
+	def("globalThis", it.global.this)
+
+	print := func(stderr bool) func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+		return func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+			it.b.write(stderr, it.formatLog(args)+"\n")
+			return jsUndefined
+		}
+	}
+	def("console", it.nativeObject(map[string]func(*jsInterp, jsValue, []jsValue) jsValue{
+		"log": print(false), "info": print(false), "debug": print(false),
+		"error": print(true), "warn": print(true), "trace": print(true),
+	}))
+
+	it.installMath(def)
+	it.installJSON(def)
+
+	// Object y Array
+	it.objectClass = &jsFunction{name: "Object", props: it.newObject(),
+		native: func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+			if v := arg(args, 0); isJSObject(v) {
+				return v
+			}
+			return it.newObject()
+		},
+		construct: func(it *jsInterp, args []jsValue) jsValue { return it.newObject() },
+	}
+	it.installObjectStatics(it.objectClass.props)
+	def("Object", it.objectClass)
+	newArray := func(it *jsInterp, args []jsValue) jsValue {
+		if n, ok := arg(args, 0).(float64); ok && len(args) == 1 {
+			if n < 0 || n != math.Trunc(n) || n > math.MaxUint32 {
+				it.throwError("RangeError", it.pos, "Invalid array length")
+			}
+			arr := it.newArray(nil)
+			it.resize(arr, int(n))
+			return arr
+		}
+		return it.newArray(append([]jsValue(nil), args...))
+	}
+	it.arrayClass = &jsFunction{name: "Array", props: it.newObject(), construct: newArray,
+		native: func(it *jsInterp, this jsValue, args []jsValue) jsValue { return newArray(it, args) }}
+	it.arrayClass.props.set("isArray", it.native("isArray", func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+		_, ok := arg(args, 0).(*jsArray)
+		return ok
+	}))
+	it.arrayClass.props.set("of", it.native("of", func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+		return it.newArray(append([]jsValue(nil), args...))
+	}))
+	it.arrayClass.props.set("from", it.native("from", func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+		var items []jsValue
+		switch src := arg(args, 0).(type) {
+		case *jsObject:
+			n := int(it.toNumber(it.getMember(src, "length", it.pos)))
+			if n < 0 || n > math.MaxUint32 {
+				n = 0
+			}
+			it.b.alloc(16 * n)
+			for i := 0; i < n; i++ {
+				items = append(items, it.getMember(src, strconv.Itoa(i), it.pos))
+			}
+		default:
+			items = it.iterate(src, it.pos)
+		}
+		if fn := arg(args, 1); fn != jsUndefined {
+			for i := range items {
+				items[i] = it.call(fn, jsUndefined, []jsValue{items[i], float64(i)}, it.pos)
+			}
+		}
+		return it.newArray(items)
+	}))
+	def("Array", it.arrayClass)
+
+	// Conversiones
+	stringFn := it.native("String", func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+		if len(args) == 0 {
+			return ""
+		}
+		return it.toString(args[0])
+	})
+	stringFn.props = it.newObject()
+	stringFn.props.set("fromCharCode", it.native("fromCharCode", func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+		units := make([]uint16, len(args))
+		for i, a := range args {
+			units[i] = uint16(jsToInt32(it.toNumber(a)))
+		}
+		return jsFromUTF16(units)
+	}))
+	def("String", stringFn)
+	numberFn := it.native("Number", func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+		if len(args) == 0 {
+			return 0.0
+		}
+		return it.toNumber(args[0])
+	})
+	numberFn.props = it.newObject()
+	it.installNumberStatics(numberFn.props)
+	def("Number", numberFn)
+	def("Boolean", it.native("Boolean", func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+		return it.truthy(arg(args, 0))
+	}))
+	def("parseInt", numberFn.props.props["parseInt"])
+	def("parseFloat", numberFn.props.props["parseFloat"])
+	def("isNaN", it.native("isNaN", func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+		return math.IsNaN(it.toNumber(arg(args, 0)))
+	}))
+	def("isFinite", it.native("isFinite", func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+		n := it.toNumber(arg(args, 0))
+		return !math.IsNaN(n) && !math.IsInf(n, 0)
+	}))
+
+	// Errores
+	it.errors = make(map[string]*jsFunction)
+	base := it.errorClass("Error", nil)
+	def("Error", base)
+	for _, name := range []string{"TypeError", "RangeError", "SyntaxError", "ReferenceError", "EvalError", "URIError"} {
+		def(name, it.errorClass(name, base))
+	}
+
+	// Map y Set
+	for _, isSet := range []bool{false, true} {
+		isSet := isSet
+		name := "Map"
+		if isSet {
+			name = "Set"
+		}
+		def(name, &jsFunction{name: name, construct: func(it *jsInterp, args []jsValue) jsValue {
+			m := &jsMap{set: isSet, index: make(map[jsValue]int)}
+			it.b.alloc(64)
+			if src := arg(args, 0); src != jsUndefined && src != jsNull {
+				for _, item := range it.iterate(src, it.pos) {
+					if isSet {
+						it.mapPut(m, item, item)
+						continue
+					}
+					entry, ok := item.(*jsArray)
+					if !ok {
+						it.throwError("TypeError", it.pos, "Iterator value %s is not an entry object", it.inspect(item))
+					}
+					it.mapPut(m, arg(entry.items, 0), arg(entry.items, 1))
+				}
+			}
+			return m
+		}})
+	}
+
+	def("Date", it.nativeObject(map[string]func(*jsInterp, jsValue, []jsValue) jsValue{
+		"now": func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+			return float64(it.input.now().UnixMilli())
+		},
+	}))
+
+	it.installTimers(def)
+	it.installProcess(def)
+}
+
+// errorClass crea Error o una de sus subclases nativas
+func (it *jsInterp) errorClass(name string, parent *jsFunction) *jsFunction {
+	cls := &jsFunction{name: name, props: it.newObject(), proto: it.newObject(), parent: parent}
+	cls.proto.class = cls
+	cls.proto.props["name"] = name
+	cls.proto.props["message"] = ""
+	if parent != nil {
+		cls.proto.proto = parent.proto
+		cls.props.proto = parent.props
+	}
+	cls.init = func(it *jsInterp, this *jsObject, args []jsValue) {
+		if msg := arg(args, 0); msg != jsUndefined {
+			this.props["message"] = it.toString(msg)
+		}
+		this.trace = "    at " + it.location(it.newPos)
+	}
+	// Error("x") sin new también crea el error
+	cls.native = func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+		return it.construct(cls, args, it.pos)
+	}
+	it.errors[name] = cls
+	return cls
+}
+
+// errorHeader devuelve "Nombre: mensaje" de un error
+func (it *jsInterp) errorHeader(obj *jsObject) string {
+	name := it.toString(it.lookupProperty(obj, "name", obj))
+	msg := it.toString(it.lookupProperty(obj, "message", obj))
+	if msg == "" {
+		return name
+	}
+	if name == "" {
+		return msg
+	}
+	return name + ": " + msg
+}
+
+func (it *jsInterp) installMath(def func(string, jsValue)) {
+	unary := func(f func(float64) float64) func(*jsInterp, jsValue, []jsValue) jsValue {
+		return func(it *jsInterp, this jsValue, args []jsValue) jsValue { return f(it.toNumber(arg(args, 0))) }
+	}
+	binary := func(f func(float64, float64) float64) func(*jsInterp, jsValue, []jsValue) jsValue {
+		return func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+			return f(it.toNumber(arg(args, 0)), it.toNumber(arg(args, 1)))
+		}
+	}
+	extreme := func(initial float64, better func(a, b float64) bool) func(*jsInterp, jsValue, []jsValue) jsValue {
+		return func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+			result := initial
+			for _, a := range args {
+				n := it.toNumber(a)
+				if math.IsNaN(n) {
+					return math.NaN()
+				}
+				if better(n, result) || n == 0 && result == 0 && better(1/n, 1/result) {
+					result = n
+				}
+			}
+			return result
+		}
+	}
+	m := it.nativeObject(map[string]func(*jsInterp, jsValue, []jsValue) jsValue{
+		"abs": unary(math.Abs), "floor": unary(math.Floor), "ceil": unary(math.Ceil),
+		"trunc": unary(math.Trunc), "sqrt": unary(math.Sqrt), "cbrt": unary(math.Cbrt),
+		"exp": unary(math.Exp), "log": unary(math.Log), "log2": unary(math.Log2), "log10": unary(math.Log10),
+		"sin": unary(math.Sin), "cos": unary(math.Cos), "tan": unary(math.Tan),
+		"asin": unary(math.Asin), "acos": unary(math.Acos), "atan": unary(math.Atan),
+		"atan2": binary(math.Atan2), "pow": binary(math.Pow),
+		"round": unary(func(x float64) float64 {
+			if math.IsNaN(x) || math.IsInf(x, 0) || x == math.Trunc(x) {
+				return x
+			}
+			return math.Floor(x + 0.5)
+		}),
+		"sign": unary(func(x float64) float64 {
+			switch {
+			case x > 0:
+				return 1
+			case x < 0:
+				return -1
+			}
+			return x
+		}),
+		"hypot": func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+			sum := 0.0
+			for _, a := range args {
+				n := it.toNumber(a)
+				sum += n * n
+			}
+			return math.Sqrt(sum)
+		},
+		"max":    extreme(math.Inf(-1), func(a, b float64) bool { return a > b }),
+		"min":    extreme(math.Inf(1), func(a, b float64) bool { return a < b }),
+		"random": func(it *jsInterp, this jsValue, args []jsValue) jsValue { return it.rand.Float64() },
+	})
+	for name, v := range map[string]float64{"PI": math.Pi, "E": math.E, "LN2": math.Ln2, "LN10": math.Ln10,
+		"LOG2E": math.Log2E, "LOG10E": math.Log10E, "SQRT2": math.Sqrt2, "SQRT1_2": math.Sqrt2 / 2} {
+		m.props[name] = v
+	}
+	def("Math", m)
+}
+
+func (it *jsInterp) installNumberStatics(props *jsObject) {
+	props.set("isInteger", it.native("isInteger", func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+		n, ok := arg(args, 0).(float64)
+		return ok && !math.IsInf(n, 0) && n == math.Trunc(n)
+	}))
+	props.set("isSafeInteger", it.native("isSafeInteger", func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+		n, ok := arg(args, 0).(float64)
+		return ok && n == math.Trunc(n) && math.Abs(n) <= 1<<53-1
+	}))
+	props.set("isFinite", it.native("isFinite", func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+		n, ok := arg(args, 0).(float64)
+		return ok && !math.IsNaN(n) && !math.IsInf(n, 0)
+	}))
+	props.set("isNaN", it.native("isNaN", func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+		n, ok := arg(args, 0).(float64)
+		return ok && math.IsNaN(n)
+	}))
+	props.set("parseFloat", it.native("parseFloat", func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+		return jsParseFloatPrefix(strings.TrimSpace(it.toString(arg(args, 0))))
+	}))
+	props.set("parseInt", it.native("parseInt", func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+		radix := 0
+		if r := arg(args, 1); r != jsUndefined {
+			radix = int(jsToInt32(it.toNumber(r)))
+		}
+		return jsParseInt(strings.TrimSpace(it.toString(arg(args, 0))), radix)
+	}))
+	for name, v := range map[string]float64{
+		"MAX_SAFE_INTEGER": 1<<53 - 1, "MIN_SAFE_INTEGER": -(1<<53 - 1), "EPSILON": math.Pow(2, -52),
+		"MAX_VALUE": math.MaxFloat64, "MIN_VALUE": 5e-324,
+		"POSITIVE_INFINITY": math.Inf(1), "NEGATIVE_INFINITY": math.Inf(-1), "NaN": math.NaN(),
+	} {
+		props.props[name] = v
+	}
+}
+
+// jsParseFloatPrefix interpreta el número más largo al comienzo de s, como
+// parseFloat
+func jsParseFloatPrefix(s string) float64 {
+	for _, inf := range []string{"Infinity", "+Infinity"} {
+		if strings.HasPrefix(s, inf) {
+			return math.Inf(1)
+		}
+	}
+	if strings.HasPrefix(s, "-Infinity") {
+		return math.Inf(-1)
+	}
+	end, digits, dot, exp := 0, false, false, false
+	for end < len(s) {
+		c := s[end]
+		switch {
+		case c >= '0' && c <= '9':
+			digits = true
+		case (c == '+' || c == '-') && (end == 0 || s[end-1] == 'e' || s[end-1] == 'E'):
+		case c == '.' && !dot && !exp:
+			dot = true
+		case (c == 'e' || c == 'E') && digits && !exp && end+1 < len(s) &&
+			(s[end+1] >= '0' && s[end+1] <= '9' || (s[end+1] == '+' || s[end+1] == '-') && end+2 < len(s) && s[end+2] >= '0' && s[end+2] <= '9'):
+			exp = true
+		default:
+			goto done
+		}
+		end++
+	}
+done:
+	if !digits {
+		return math.NaN()
+	}
+	n, _ := strconv.ParseFloat(strings.TrimRight(s[:end], "eE+-"), 64)
+	return n
+}
+
+// jsParseInt implementa parseInt(s, radix)
+func jsParseInt(s string, radix int) float64 {
+	sign := 1.0
+	if strings.HasPrefix(s, "-") {
+		sign, s = -1, s[1:]
+	} else {
+		s = strings.TrimPrefix(s, "+")
+	}
+	if (radix == 0 || radix == 16) && len(s) > 1 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		s, radix = s[2:], 16
+	}
+	if radix == 0 {
+		radix = 10
+	}
+	if radix < 2 || radix > 36 {
+		return math.NaN()
+	}
+	result, digits := 0.0, 0
+	for _, c := range s {
+		d := 99
+		switch {
+		case c >= '0' && c <= '9':
+			d = int(c - '0')
+		case c >= 'a' && c <= 'z':
+			d = int(c-'a') + 10
+		case c >= 'A' && c <= 'Z':
+			d = int(c-'A') + 10
+		}
+		if d >= radix {
+			break
+		}
+		result = result*float64(radix) + float64(d)
+		digits++
+	}
+	if digits == 0 {
+		return math.NaN()
+	}
+	return sign * result
+}
+
+func (it *jsInterp) installObjectStatics(props *jsObject) {
+	props.set("keys", it.native("keys", func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+		keys := it.enumerableKeys(arg(args, 0))
+		items := make([]jsValue, len(keys))
+		for i, k := range keys {
+			items[i] = k
+		}
+		return it.newArray(items)
+	}))
+	props.set("values", it.native("values", func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+		obj := arg(args, 0)
+		keys := it.enumerableKeys(obj)
+		items := make([]jsValue, len(keys))
+		for i, k := range keys {
+			items[i] = it.getMember(obj, k, it.pos)
+		}
+		return it.newArray(items)
+	}))
+	props.set("entries", it.native("entries", func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+		obj := arg(args, 0)
+		keys := it.enumerableKeys(obj)
+		items := make([]jsValue, len(keys))
+		for i, k := range keys {
+			items[i] = it.newArray([]jsValue{k, it.getMember(obj, k, it.pos)})
+		}
+		return it.newArray(items)
+	}))
+	props.set("fromEntries", it.native("fromEntries", func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+		obj := it.newObject()
+		for _, entry := range it.iterate(arg(args, 0), it.pos) {
+			if e, ok := entry.(*jsArray); ok {
+				obj.set(it.propertyKey(arg(e.items, 0)), arg(e.items, 1))
+			}
+		}
+		return obj
+	}))
+	props.set("assign", it.native("assign", func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+		target := arg(args, 0)
+		for _, src := range args[1:] {
+			for _, k := range it.enumerableKeys(src) {
+				it.setMember(target, k, it.getMember(src, k, it.pos), it.pos)
+			}
+		}
+		return target
+	}))
+	props.set("getOwnPropertyNames", props.props["keys"])
+	props.set("getPrototypeOf", it.native("getPrototypeOf", func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+		if obj, ok := arg(args, 0).(*jsObject); ok && obj.proto != nil {
+			return obj.proto
+		}
+		return jsNull
+	}))
+	props.set("create", it.native("create", func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+		obj := it.newObject()
+		if proto, ok := arg(args, 0).(*jsObject); ok {
+			obj.proto, obj.class = proto, proto.class
+		}
+		return obj
+	}))
+}
+
+// ───── JSON ─────
+
+func (it *jsInterp) installJSON(def func(string, jsValue)) {
+	def("JSON", it.nativeObject(map[string]func(*jsInterp, jsValue, []jsValue) jsValue{
+		"stringify": func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+			indent := ""
+			switch space := arg(args, 2).(type) {
+			case float64:
+				indent = strings.Repeat(" ", int(math.Max(0, math.Min(10, space))))
+			case string:
+				indent = space
+				if len(indent) > 10 {
+					indent = indent[:10]
+				}
+			}
+			var sb strings.Builder
+			if !it.jsonWrite(&sb, arg(args, 0), indent, "", nil) {
+				return jsUndefined
+			}
+			it.b.alloc(sb.Len())
+			return sb.String()
+		},
+		"parse": func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+			text := it.toString(arg(args, 0))
+			dec := json.NewDecoder(strings.NewReader(text))
+			dec.UseNumber()
+			v, err := it.jsonRead(dec)
+			if err == nil {
+				end := int(dec.InputOffset())
+				if _, err = dec.Token(); err == io.EOF {
+					return v
+				}
+				end += len(text[end:]) - len(strings.TrimLeft(text[end:], " \t\n\r"))
+				it.throwError("SyntaxError", it.pos, "Unexpected non-whitespace character after JSON at position %d", end)
+			}
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				it.throwError("SyntaxError", it.pos, "Unexpected end of JSON input")
+			}
+			it.throwError("SyntaxError", it.pos, "Unexpected token in JSON at position %d", dec.InputOffset())
+			return nil
+		},
+	}))
+}
+
+// jsonWrite escribe v como JSON; false si v no tiene representación
+// (undefined, una función)
+func (it *jsInterp) jsonWrite(sb *strings.Builder, v jsValue, indent, prefix string, stack []jsValue) bool {
+	it.b.step()
+	for _, seen := range stack {
+		if seen == v {
+			it.throwError("TypeError", it.pos, "Converting circular structure to JSON")
+		}
+	}
+	if obj, ok := v.(*jsObject); ok {
+		if toJSON, ok := it.lookupProperty(obj, "toJSON", obj).(*jsFunction); ok {
+			v = it.call(toJSON, obj, nil, it.pos)
+		}
+	}
+	inner := prefix + indent
+	open := func(c byte) {
+		sb.WriteByte(c)
+	}
+	sep := func(first bool) {
+		if !first {
+			sb.WriteByte(',')
+		}
+		if indent != "" {
+			sb.WriteString("\n" + inner)
+		}
+	}
+	closing := func(c byte, empty bool) {
+		if indent != "" && !empty {
+			sb.WriteString("\n" + prefix)
+		}
+		sb.WriteByte(c)
+	}
+	switch v := v.(type) {
+	case jsNullType:
+		sb.WriteString("null")
+	case bool, string:
+		sb.WriteString(jsonQuote(v, it))
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			sb.WriteString("null")
+		} else {
+			sb.WriteString(jsNumberToString(v))
+		}
+	case *jsArray:
+		open('[')
+		for i, item := range v.items {
+			sep(i == 0)
+			if !it.jsonWrite(sb, item, indent, inner, append(stack, v)) {
+				sb.WriteString("null")
+			}
+		}
+		closing(']', len(v.items) == 0)
+	case *jsObject:
+		open('{')
+		first := true
+		for _, k := range it.enumerableKeys(v) {
+			var value strings.Builder
+			if !it.jsonWrite(&value, it.getMember(v, k, it.pos), indent, inner, append(stack, v)) {
+				continue
+			}
+			sep(first)
+			first = false
+			sb.WriteString(jsonQuote(k, it))
+			sb.WriteByte(':')
+			if indent != "" {
+				sb.WriteByte(' ')
+			}
+			sb.WriteString(value.String())
+		}
+		closing('}', first)
+	case *jsMap:
+		sb.WriteString("{}")
+	default:
+		return false
+	}
+	return true
+}
+
+// jsonQuote escribe un primitivo como JSON, con los escapes de JavaScript
+func jsonQuote(v jsValue, it *jsInterp) string {
+	s, ok := v.(string)
+	if !ok {
+		return it.toString(v)
+	}
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\f':
+			sb.WriteString(`\f`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(&sb, `\u%04x`, r)
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// jsonRead lee un valor JSON conservando el orden de las claves
+func (it *jsInterp) jsonRead(dec *json.Decoder) (jsValue, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '[':
+			var items []jsValue
+			for dec.More() {
+				v, err := it.jsonRead(dec)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, v)
+			}
+			_, err := dec.Token()
+			return it.newArray(items), err
+		case '{':
+			obj := it.newObject()
+			for dec.More() {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				v, err := it.jsonRead(dec)
+				if err != nil {
+					return nil, err
+				}
+				it.b.alloc(48)
+				obj.set(key.(string), v)
+			}
+			_, err := dec.Token()
+			return obj, err
+		}
+		return nil, errors.New("unexpected delimiter")
+	case json.Number:
+		f, _ := strconv.ParseFloat(t.String(), 64)
+		return f, nil
+	case string:
+		it.b.alloc(len(t))
+		return t, nil
+	case bool:
+		return t, nil
+	case nil:
+		return jsNull, nil
+	}
+	return nil, errors.New("unexpected token")
+}
+
+// ───── process, require y eventos ─────
+//
+// No hay bucle de eventos real: después del programa se ejecutan los
+// temporizadores en orden de vencimiento y se entrega la entrada estándar a
+// readline o a process.stdin, como si llegara toda junta. Las funciones de
+// fs leen la entrada estándar y los archivos auxiliares de la petición, sin
+// tocar el disco del servidor.
+
+type jsTimer struct {
+	id       int
+	fn       jsValue
+	args     []jsValue
+	due      float64
+	interval float64
+	seq      int
+}
+
+// jsStdinEvents son los oyentes de la entrada estándar
+type jsStdinEvents struct {
+	readline  bool
+	lines     []string
+	next      int
+	closed    bool
+	line      []jsValue
+	close     []jsValue
+	questions []jsValue
+	data      []jsValue
+	end       []jsValue
+	dataSent  bool
+}
+
+func (it *jsInterp) installTimers(def func(string, jsValue)) {
+	// Las microtareas vencen antes que cualquier temporizador pendiente
+	add := func(fn jsValue, delay float64, extra []jsValue, repeat bool) jsValue {
+		if math.IsNaN(delay) {
+			delay = 0
+		}
+		it.timerSeq++
+		t := jsTimer{id: it.timerSeq, fn: fn, args: extra, due: it.clock + delay, seq: it.timerSeq}
+		if repeat {
+			t.interval = math.Max(1, delay)
+		}
+		it.timers = append(it.timers, t)
+		return float64(t.id)
+	}
+	timer := func(repeat bool) func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+		return func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+			var extra []jsValue
+			if len(args) > 2 {
+				extra = args[2:]
+			}
+			return add(arg(args, 0), math.Max(0, it.toNumber(arg(args, 1))), extra, repeat)
+		}
+	}
+	clear := func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+		id := int(it.toNumber(arg(args, 0)))
+		for i, t := range it.timers {
+			if t.id == id {
+				it.timers = append(it.timers[:i], it.timers[i+1:]...)
+				break
+			}
+		}
+		return jsUndefined
+	}
+	def("setTimeout", it.native("setTimeout", timer(false)))
+	def("setInterval", it.native("setInterval", timer(true)))
+	def("setImmediate", it.native("setImmediate", func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+		var extra []jsValue
+		if len(args) > 1 {
+			extra = args[1:]
+		}
+		return add(arg(args, 0), 0, extra, false)
+	}))
+	def("queueMicrotask", it.native("queueMicrotask", func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+		add(arg(args, 0), -1, nil, false)
+		return jsUndefined
+	}))
+	def("clearTimeout", it.native("clearTimeout", clear))
+	def("clearInterval", it.native("clearInterval", clear))
+}
+
+// runEventLoop ejecuta los temporizadores y entrega la entrada estándar
+// hasta que no quede nada pendiente
+func (it *jsInterp) runEventLoop() {
+	for {
+		it.b.step()
+		next := -1
+		for i, t := range it.timers {
+			if next < 0 || t.due < it.timers[next].due || t.due == it.timers[next].due && t.seq < it.timers[next].seq {
+				next = i
+			}
+		}
+		// Los temporizadores vencidos van antes que la entrada estándar
+		if next >= 0 && it.timers[next].due <= it.clock {
+			it.fireTimer(next)
+			continue
+		}
+		if it.deliverStdin() {
+			continue
+		}
+		if next < 0 {
+			return
+		}
+		it.clock = math.Max(it.clock, it.timers[next].due)
+		it.fireTimer(next)
+	}
+}
+
+func (it *jsInterp) fireTimer(i int) {
+	t := it.timers[i]
+	if t.interval > 0 {
+		it.timerSeq++
+		it.timers[i].due += t.interval
+		it.timers[i].seq = it.timerSeq
+	} else {
+		it.timers = append(it.timers[:i], it.timers[i+1:]...)
+	}
+	if _, ok := t.fn.(*jsFunction); ok {
+		it.call(t.fn, jsUndefined, t.args, it.pos)
+	}
+}
+
+// deliverStdin entrega el siguiente evento de la entrada estándar; false si
+// no hay ninguno pendiente
+func (it *jsInterp) deliverStdin() bool {
+	ev := it.stdinEv
+	if ev == nil {
+		return false
+	}
+	if len(ev.data) > 0 && !ev.dataSent {
+		ev.dataSent = true
+		if it.stdin != "" {
+			for _, fn := range ev.data {
+				it.call(fn, jsUndefined, []jsValue{it.stdin}, it.pos)
+			}
+		}
+		for _, fn := range ev.end {
+			it.call(fn, jsUndefined, nil, it.pos)
+		}
+		return true
+	}
+	if !ev.readline || ev.closed {
+		return false
+	}
+	if ev.next < len(ev.lines) {
+		line := ev.lines[ev.next]
+		ev.next++
+		if len(ev.questions) > 0 {
+			answer := ev.questions[0]
+			ev.questions = ev.questions[1:]
+			it.call(answer, jsUndefined, []jsValue{line}, it.pos)
+		} else {
+			for _, fn := range ev.line {
+				it.call(fn, jsUndefined, []jsValue{line}, it.pos)
+			}
+		}
+		return true
+	}
+	it.closeReadline()
+	return true
+}
+
+func (it *jsInterp) closeReadline() {
+	ev := it.stdinEv
+	if ev.closed {
+		return
+	}
+	ev.closed = true
+	for _, fn := range ev.close {
+		it.call(fn, jsUndefined, nil, it.pos)
+	}
+}
+
+func (it *jsInterp) stdinEvents() *jsStdinEvents {
+	if it.stdinEv == nil {
+		it.stdinEv = &jsStdinEvents{}
+	}
+	return it.stdinEv
+}
+
+func (it *jsInterp) installProcess(def func(string, jsValue)) {
+	process := it.newObject()
+	argv := []jsValue{"node", jsFileName}
+	for _, a := range it.input.Args {
+		argv = append(argv, a)
+	}
+	process.set("argv", it.newArray(argv))
+	env := it.newObject()
+	for _, kv := range it.input.envList() {
+		name, value, _ := strings.Cut(kv, "=")
+		env.set(name, value)
+	}
+	process.set("env", env)
+	process.set("platform", "linux")
+	process.set("exit", it.native("exit", func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+		code := it.exitCode()
+		if c := arg(args, 0); c != jsUndefined {
+			code = int(it.toNumber(c))
+		}
+		panic(jsExit{code: code})
+	}))
+	process.set("stdout", it.nativeObject(map[string]func(*jsInterp, jsValue, []jsValue) jsValue{
+		"write": func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+			it.b.write(false, it.toString(arg(args, 0)))
+			return true
+		},
+	}))
+	process.set("stderr", it.nativeObject(map[string]func(*jsInterp, jsValue, []jsValue) jsValue{
+		"write": func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+			it.b.write(true, it.toString(arg(args, 0)))
+			return true
+		},
+	}))
+	var stdin *jsObject
+	returnStdin := func(it *jsInterp, this jsValue, args []jsValue) jsValue { return stdin }
+	stdin = it.nativeObject(map[string]func(*jsInterp, jsValue, []jsValue) jsValue{
+		"on": func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+			ev := it.stdinEvents()
+			switch it.toString(arg(args, 0)) {
+			case "data":
+				ev.data = append(ev.data, arg(args, 1))
+			case "end", "close":
+				ev.end = append(ev.end, arg(args, 1))
+			}
+			return stdin
+		},
+		"setEncoding": returnStdin, "resume": returnStdin, "pause": returnStdin,
+	})
+	stdin.set("fd", 0.0)
+	process.set("stdin", stdin)
+	def("process", process)
+	it.process = process
+
+	module := it.newObject()
+	module.set("exports", it.newObject())
+	def("module", module)
+	def("exports", module.props["exports"])
+
+	files := make(map[string]string, len(it.input.Files))
+	for _, f := range it.input.Files {
+		files[f.Name] = f.Content
+	}
+	modules := map[string]jsValue{
+		"fs":       it.fsModule(files),
+		"readline": it.readlineModule(),
+		"util": it.nativeObject(map[string]func(*jsInterp, jsValue, []jsValue) jsValue{
+			"inspect": func(it *jsInterp, this jsValue, args []jsValue) jsValue { return it.inspect(arg(args, 0)) },
+			"format":  func(it *jsInterp, this jsValue, args []jsValue) jsValue { return it.formatLog(args) },
+		}),
+	}
+	def("require", it.native("require", func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+		name := strings.TrimPrefix(it.toString(arg(args, 0)), "node:")
+		if m, ok := modules[name]; ok {
+			return m
+		}
+		it.throwError("Error", it.pos, "Cannot find module '%s'", name)
+		return nil
+	}))
+}
+
+// fsModule lee la entrada estándar (descriptor 0 o /dev/stdin) y los
+// archivos auxiliares; no escribe archivos
+func (it *jsInterp) fsModule(files map[string]string) *jsObject {
+	path := func(v jsValue) string {
+		if n, ok := v.(float64); ok && n == 0 {
+			return "/dev/stdin"
+		}
+		return strings.TrimPrefix(it.toString(v), "./")
+	}
+	return it.nativeObject(map[string]func(*jsInterp, jsValue, []jsValue) jsValue{
+		"readFileSync": func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+			name := path(arg(args, 0))
+			if name == "/dev/stdin" {
+				return it.stdin
+			}
+			content, ok := files[name]
+			if !ok {
+				it.throwError("Error", it.pos, "ENOENT: no such file or directory, open '%s'", name)
+			}
+			return content
+		},
+		"existsSync": func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+			_, ok := files[path(arg(args, 0))]
+			return ok
+		},
+	})
+}
+
+// readlineModule entrega la entrada estándar línea por línea a los oyentes
+// de 'line' y a question, y al terminar emite 'close'
+func (it *jsInterp) readlineModule() *jsObject {
+	return it.nativeObject(map[string]func(*jsInterp, jsValue, []jsValue) jsValue{
+		"createInterface": func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+			ev := it.stdinEvents()
+			if !ev.readline {
+				ev.readline = true
+				if it.stdin != "" {
+					ev.lines = strings.Split(strings.TrimSuffix(it.stdin, "\n"), "\n")
+					for i, l := range ev.lines {
+						ev.lines[i] = strings.TrimSuffix(l, "\r")
+					}
+				}
+			}
+			prompt := "> "
+			var rl *jsObject
+			rl = it.nativeObject(map[string]func(*jsInterp, jsValue, []jsValue) jsValue{
+				"on": func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+					switch it.toString(arg(args, 0)) {
+					case "line":
+						ev.line = append(ev.line, arg(args, 1))
+					case "close":
+						ev.close = append(ev.close, arg(args, 1))
+					}
+					return rl
+				},
+				"question": func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+					it.b.write(false, it.toString(arg(args, 0)))
+					ev.questions = append(ev.questions, arg(args, 1))
+					return jsUndefined
+				},
+				"close": func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+					it.closeReadline()
+					return jsUndefined
+				},
+				"setPrompt": func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+					prompt = it.toString(arg(args, 0))
+					return jsUndefined
+				},
+				"prompt": func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+					it.b.write(false, prompt)
+					return jsUndefined
+				},
+				"write": func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+					it.b.write(false, it.toString(arg(args, 0)))
+					return jsUndefined
+				},
+			})
+			return rl
+		},
+	})
+}
+
+// ───── Métodos de los valores ─────
+
+func (it *jsInterp) objectMethod(obj *jsObject, key string) jsValue {
+	switch key {
+	case "hasOwnProperty":
+		return it.native(key, func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+			k := it.propertyKey(arg(args, 0))
+			_, ok := obj.props[k]
+			return ok || obj.getters[k] != nil
+		})
+	case "toString":
+		return it.native(key, func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+			if obj.trace != "" {
+				return it.errorHeader(obj)
+			}
+			return "[object Object]"
+		})
+	case "valueOf":
+		return it.native(key, func(it *jsInterp, this jsValue, args []jsValue) jsValue { return obj })
+	case "constructor":
+		if obj.class != nil {
+			return obj.class
+		}
+		return it.objectClass
+	}
+	return jsUndefined
+}
+
+func (it *jsInterp) functionMember(fn *jsFunction, key string) jsValue {
+	switch key {
+	case "name":
+		return fn.name
+	case "length":
+		if fn.params == nil {
+			return 0.0
+		}
+		n := 0
+		for _, p := range fn.params.Children {
+			if strings.HasPrefix(p.Label, "...") || len(p.Children) > 0 && p.Children[len(p.Children)-1].Kind != "ObjectPattern" && p.Children[len(p.Children)-1].Kind != "ArrayPattern" {
+				break
+			}
+			n++
+		}
+		return float64(n)
+	case "prototype":
+		if fn.arrow || fn.native != nil && fn.proto == nil {
+			return jsUndefined
+		}
+		return it.prototypeOf(fn)
+	case "call":
+		return it.native(key, func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+			if len(args) == 0 {
+				return it.call(fn, jsUndefined, nil, it.pos)
+			}
+			return it.call(fn, args[0], args[1:], it.pos)
+		})
+	case "apply":
+		return it.native(key, func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+			var list []jsValue
+			if arr, ok := arg(args, 1).(*jsArray); ok {
+				list = arr.items
+			}
+			return it.call(fn, arg(args, 0), list, it.pos)
+		})
+	case "bind":
+		return it.native(key, func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+			bound := arg(args, 0)
+			var partial []jsValue
+			if len(args) > 1 {
+				partial = append(partial, args[1:]...)
+			}
+			return it.native("bound "+fn.name, func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+				return it.call(fn, bound, append(append([]jsValue(nil), partial...), args...), it.pos)
+			})
+		})
+	case "toString":
+		return it.native(key, func(it *jsInterp, this jsValue, args []jsValue) jsValue { return it.toString(fn) })
+	}
+	return jsUndefined
+}
+
+func (it *jsInterp) numberMethod(n float64, key string) jsValue {
+	switch key {
+	case "toFixed":
+		return it.native(key, func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+			digits := int(it.toNumber(arg(args, 0)))
+			if digits < 0 || digits > 100 {
+				it.throwError("RangeError", it.pos, "toFixed() digits argument must be between 0 and 100")
+			}
+			return jsToFixed(n, digits)
+		})
+	case "toString":
+		return it.native(key, func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+			radix := 10
+			if r := arg(args, 0); r != jsUndefined {
+				radix = int(it.toNumber(r))
+			}
+			if radix < 2 || radix > 36 {
+				it.throwError("RangeError", it.pos, "toString() radix must be between 2 and 36")
+			}
+			if radix == 10 || n != math.Trunc(n) || math.IsInf(n, 0) || math.Abs(n) > 1<<53 {
+				return jsNumberToString(n)
+			}
+			return strconv.FormatInt(int64(n), radix)
+		})
+	case "toPrecision":
+		return it.native(key, func(it *jsInterp, this jsValue, args []jsValue) jsValue {
+			p := arg(args, 0)
+			if p == jsUndefined {
+				return jsNumberToString(n)
+			}
+			digits := int(it.toNumber(p))
+			if digits < 1 || digits > 100 {
+				it.throwError("RangeError", it.pos, "toPrecision() argument must be between 1 and 100")
+			}
+			mantissa, exp, _ := strings.Cut(strconv.FormatFloat(n, 'e', digits-1, 64), "e")
+			e, _ := strconv.Atoi(exp)
+			if e < -6 || e >= digits {
+				sign := "+"
+				if e < 0 {
+					sign, e = "-", -e
+				}
+				return mantissa + "e" + sign + strconv.Itoa(e)
+			}
+			return strconv.FormatFloat(n, 'f', digits-1-e, 64)
+		})
+	case "valueOf":
+		return it.native(key, func(it *jsInterp, this jsValue, args []jsValue) jsValue { return n })
+	}
+	return jsUndefined
+}
+
+// jsToFixed implementa Number.prototype.toFixed: redondea el valor exacto
+// del número, con los empates hacia arriba
+func jsToFixed(n float64, digits int) string {
+	if math.IsNaN(n) || math.IsInf(n, 0) || math.Abs(n) >= 1e21 {
+		return jsNumberToString(n)
+	}
+	exact := new(big.Float).SetFloat64(n).Text('f', 1100)
+	neg := strings.HasPrefix(exact, "-")
+	exact = strings.TrimPrefix(exact, "-")
+	whole, frac, _ := strings.Cut(exact, ".")
+	frac += strings.Repeat("0", digits+1)
+	kept := []byte(whole + frac[:digits])
+	if frac[digits] >= '5' {
+		i := len(kept) - 1
+		for ; i >= 0 && kept[i] == '9'; i-- {
+			kept[i] = '0'
+		}
+		if i < 0 {
+			kept = append([]byte{'1'}, kept...)
+		} else {
+			kept[i]++
+		}
+	}
+	intLen := len(kept) - digits
+	s := string(kept[:intLen])
+	if digits > 0 {
+		s += "." + string(kept[intLen:])
+	}
+	if neg && strings.Trim(s, "0.") != "" {
+		s = "-" + s
+	}
+	return s
+}
+
+// jsRelativeIndex interpreta un índice que puede ser negativo (desde el
+// final), acotado a [0, length]
+func (it *jsInterp) jsRelativeIndex(v jsValue, length, def int) int {
+	if v == jsUndefined {
+		return def
+	}
+	n := it.toNumber(v)
+	if math.IsNaN(n) {
+		return 0
+	}
+	n = math.Trunc(n)
+	if n < 0 {
+		return int(math.Max(0, float64(length)+n))
+	}
+	return int(math.Min(n, float64(length)))
+}
+
+func (it *jsInterp) stringMethod(s string, key string) jsValue {
+	method := func(fn func(args []jsValue) jsValue) jsValue {
+		return it.native(key, func(it *jsInterp, this jsValue, args []jsValue) jsValue { return fn(args) })
+	}
+	str := func(args []jsValue, i int) string {
+		v := arg(args, i)
+		if _, ok := v.(*jsObject); ok {
+			it.throwError("TypeError", it.pos, "El intérprete integrado no soporta expresiones regulares")
+		}
+		return it.toString(v)
+	}
+	newString := func(r string) jsValue {
+		it.b.alloc(len(r))
+		return r
+	}
+	length := jsStringLength(s)
+	switch key {
+	case "charAt", "at":
+		return method(func(args []jsValue) jsValue {
+			i := int(it.toNumber(arg(args, 0)))
+			if key == "at" && i < 0 {
+				i += length
+			}
+			if ch, ok := jsCharAt(s, i); ok && i >= 0 {
+				return ch
+			}
+			if key == "at" {
+				return jsUndefined
+			}
+			return ""
+		})
+	case "charCodeAt", "codePointAt":
+		return method(func(args []jsValue) jsValue {
+			i := int(it.toNumber(arg(args, 0)))
+			u := jsUTF16(s)
+			if i < 0 || i >= len(u) {
+				return math.NaN()
+			}
+			if key == "codePointAt" && utf16.IsSurrogate(rune(u[i])) && i+1 < len(u) {
+				return float64(utf16.DecodeRune(rune(u[i]), rune(u[i+1])))
+			}
+			return float64(u[i])
+		})
+	case "indexOf", "lastIndexOf", "includes", "startsWith", "endsWith":
+		return method(func(args []jsValue) jsValue {
+			sub := str(args, 0)
+			u, su := jsUTF16(s), jsUTF16(sub)
+			find := func(from int, last bool) int {
+				if last {
+					for i := min(from, len(u)-len(su)); i >= 0; i-- {
+						if jsUnitsEqual(u[i:i+len(su)], su) {
+							return i
+						}
+					}
+					return -1
+				}
+				for i := max(from, 0); i+len(su) <= len(u); i++ {
+					if jsUnitsEqual(u[i:i+len(su)], su) {
+						return i
+					}
+				}
+				return -1
+			}
+			switch key {
+			case "indexOf":
+				return float64(find(it.jsRelativeIndex(arg(args, 1), len(u), 0), false))
+			case "lastIndexOf":
+				from := len(u)
+				if p := arg(args, 1); p != jsUndefined && !math.IsNaN(it.toNumber(p)) {
+					from = int(it.toNumber(p))
+				}
+				return float64(find(from, true))
+			case "includes":
+				return find(it.jsRelativeIndex(arg(args, 1), len(u), 0), false) >= 0
+			case "startsWith":
+				from := it.jsRelativeIndex(arg(args, 1), len(u), 0)
+				return from+len(su) <= len(u) && jsUnitsEqual(u[from:from+len(su)], su)
+			}
+			end := it.jsRelativeIndex(arg(args, 1), len(u), len(u))
+			return end-len(su) >= 0 && jsUnitsEqual(u[end-len(su):end], su)
+		})
+	case "slice", "substring", "substr":
+		return method(func(args []jsValue) jsValue {
+			var start, end int
+			switch key {
+			case "slice":
+				start = it.jsRelativeIndex(arg(args, 0), length, 0)
+				end = it.jsRelativeIndex(arg(args, 1), length, length)
+			case "substring":
+				clamp := func(v jsValue, def int) int {
+					if v == jsUndefined {
+						return def
+					}
+					n := it.toNumber(v)
+					if math.IsNaN(n) || n < 0 {
+						return 0
+					}
+					return int(math.Min(n, float64(length)))
+				}
+				start, end = clamp(arg(args, 0), 0), clamp(arg(args, 1), length)
+				if start > end {
+					start, end = end, start
+				}
+			default:
+				start = it.jsRelativeIndex(arg(args, 0), length, 0)
+				end = length
+				if n := arg(args, 1); n != jsUndefined {
+					end = min(length, start+max(0, int(it.toNumber(n))))
+				}
+			}
+			if start >= end {
+				return ""
+			}
+			return newString(jsSubstring(s, start, end))
+		})
+	case "toUpperCase", "toLocaleUpperCase":
+		return method(func(args []jsValue) jsValue { return newString(strings.ToUpper(s)) })
+	case "toLowerCase", "toLocaleLowerCase":
+		return method(func(args []jsValue) jsValue { return newString(strings.ToLower(s)) })
+	case "trim":
+		return method(func(args []jsValue) jsValue { return strings.TrimFunc(s, unicode.IsSpace) })
+	case "trimStart", "trimLeft":
+		return method(func(args []jsValue) jsValue { return strings.TrimLeftFunc(s, unicode.IsSpace) })
+	case "trimEnd", "trimRight":
+		return method(func(args []jsValue) jsValue { return strings.TrimRightFunc(s, unicode.IsSpace) })
+	case "split":
+		return method(func(args []jsValue) jsValue {
+			limit := math.MaxInt32
+			if l := arg(args, 1); l != jsUndefined {
+				limit = int(uint32(jsToInt32(it.toNumber(l))))
+			}
+			var parts []string
+			switch {
+			case arg(args, 0) == jsUndefined:
+				parts = []string{s}
+			case str(args, 0) == "":
+				u := jsUTF16(s)
+				for i := range u {
+					parts = append(parts, jsFromUTF16(u[i:i+1]))
+				}
+			default:
+				parts = strings.Split(s, str(args, 0))
+			}
+			if len(parts) > limit {
+				parts = parts[:limit]
+			}
+			items := make([]jsValue, len(parts))
+			for i, p := range parts {
+				items[i] = p
+			}
+			it.b.alloc(len(s))
+			return it.newArray(items)
+		})
+	case "replace", "replaceAll":
+		return method(func(args []jsValue) jsValue {
+			pattern := str(args, 0)
+			replacement := arg(args, 1)
+			n := 1
+			if key == "replaceAll" {
+				n = -1
+			}
+			fn, isFn := replacement.(*jsFunction)
+			var sb strings.Builder
+			rest := s
+			for n != 0 {
+				i := strings.Index(rest, pattern)
+				if i < 0 {
+					break
+				}
+				sb.WriteString(rest[:i])
+				if isFn {
+					sb.WriteString(it.toString(it.call(fn, jsUndefined, []jsValue{pattern}, it.pos)))
+				} else {
+					sb.WriteString(strings.ReplaceAll(it.toString(replacement), "$&", pattern))
+				}
+				rest = rest[i+len(pattern):]
+				if pattern == "" {
+					if rest == "" {
+						n = 0
+						break
+					}
+					_, size := utf8.DecodeRuneInString(rest)
+					sb.WriteString(rest[:size])
+					rest = rest[size:]
+				}
+				n--
+			}
+			sb.WriteString(rest)
+			return newString(sb.String())
+		})
+	case "repeat":
+		return method(func(args []jsValue) jsValue {
+			count := it.toNumber(arg(args, 0))
+			if count < 0 || math.IsInf(count, 0) {
+				it.throwError("RangeError", it.pos, "Invalid count value: %s", jsNumberToString(count))
+			}
+			it.b.alloc(len(s) * int(count))
+			return strings.Repeat(s, int(count))
+		})
+	case "padStart", "padEnd":
+		return method(func(args []jsValue) jsValue {
+			target := int(it.toNumber(arg(args, 0)))
+			pad := " "
+			if p := arg(args, 1); p != jsUndefined {
+				pad = it.toString(p)
+			}
+			if target <= length || pad == "" {
+				return s
+			}
+			it.b.alloc(target)
+			fill := []rune(strings.Repeat(pad, (target-length)/jsStringLength(pad)+1))
+			filler := jsSubstring(string(fill), 0, target-length)
+			if key == "padStart" {
+				return filler + s
+			}
+			return s + filler
+		})
+	case "concat":
+		return method(func(args []jsValue) jsValue {
+			var sb strings.Builder
+			sb.WriteString(s)
+			for _, a := range args {
+				sb.WriteString(it.toString(a))
+			}
+			return newString(sb.String())
+		})
+	case "toString", "valueOf":
+		return method(func(args []jsValue) jsValue { return s })
+	}
+	return jsUndefined
+}
+
+func jsUnitsEqual(a, b []uint16) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (it *jsInterp) arrayMethod(arr *jsArray, key string) jsValue {
+	method := func(fn func(args []jsValue) jsValue) jsValue {
+		return it.native(key, func(it *jsInterp, this jsValue, args []jsValue) jsValue { return fn(args) })
+	}
+	callback := func(args []jsValue) jsValue {
+		fn := arg(args, 0)
+		if _, ok := fn.(*jsFunction); !ok {
+			it.throwError("TypeError", it.pos, "%s is not a function", it.inspect(fn))
+		}
+		return fn
+	}
+	// each llama a fn(elemento, índice, arreglo) hasta que stop devuelva true
+	each := func(args []jsValue, stop func(i int, result jsValue) bool) {
+		fn := callback(args)
+		this := arg(args, 1)
+		for i := 0; i < len(arr.items); i++ {
+			if stop(i, it.call(fn, this, []jsValue{arr.items[i], float64(i), arr}, it.pos)) {
+				return
+			}
+		}
+	}
+	switch key {
+	case "push":
+		return method(func(args []jsValue) jsValue {
+			it.b.alloc(16 * len(args))
+			arr.items = append(arr.items, args...)
+			return float64(len(arr.items))
+		})
+	case "pop":
+		return method(func(args []jsValue) jsValue {
+			if len(arr.items) == 0 {
+				return jsUndefined
+			}
+			last := arr.items[len(arr.items)-1]
+			arr.items = arr.items[:len(arr.items)-1]
+			return last
+		})
+	case "shift":
+		return method(func(args []jsValue) jsValue {
+			if len(arr.items) == 0 {
+				return jsUndefined
+			}
+			first := arr.items[0]
+			arr.items = append(arr.items[:0:0], arr.items[1:]...)
+			return first
+		})
+	case "unshift":
+		return method(func(args []jsValue) jsValue {
+			it.b.alloc(16 * (len(args) + len(arr.items)))
+			arr.items = append(append([]jsValue(nil), args...), arr.items...)
+			return float64(len(arr.items))
+		})
+	case "slice":
+		return method(func(args []jsValue) jsValue {
+			start := it.jsRelativeIndex(arg(args, 0), len(arr.items), 0)
+			end := it.jsRelativeIndex(arg(args, 1), len(arr.items), len(arr.items))
+			if start >= end {
+				return it.newArray(nil)
+			}
+			return it.newArray(append([]jsValue(nil), arr.items[start:end]...))
+		})
+	case "splice":
+		return method(func(args []jsValue) jsValue {
+			start := it.jsRelativeIndex(arg(args, 0), len(arr.items), 0)
+			count := len(arr.items) - start
+			if len(args) > 1 {
+				count = max(0, min(count, int(it.toNumber(args[1]))))
+			}
+			if len(args) == 0 {
+				count = 0
+			}
+			removed := append([]jsValue(nil), arr.items[start:start+count]...)
+			var inserted []jsValue
+			if len(args) > 2 {
+				inserted = args[2:]
+			}
+			it.b.alloc(16 * (len(arr.items) + len(inserted)))
+			items := append(append(append([]jsValue(nil), arr.items[:start]...), inserted...), arr.items[start+count:]...)
+			arr.items = items
+			return it.newArray(removed)
+		})
+	case "concat":
+		return method(func(args []jsValue) jsValue {
+			items := append([]jsValue(nil), arr.items...)
+			for _, a := range args {
+				if other, ok := a.(*jsArray); ok {
+					items = append(items, other.items...)
+				} else {
+					items = append(items, a)
+				}
+			}
+			return it.newArray(items)
+		})
+	case "join":
+		return method(func(args []jsValue) jsValue {
+			sep := ","
+			if s := arg(args, 0); s != jsUndefined {
+				sep = it.toString(s)
+			}
+			parts := make([]string, len(arr.items))
+			for i, item := range arr.items {
+				if item != jsUndefined && item != jsNull {
+					parts[i] = it.toString(item)
+				}
+			}
+			s := strings.Join(parts, sep)
+			it.b.alloc(len(s))
+			return s
+		})
+	case "reverse":
+		return method(func(args []jsValue) jsValue {
+			for i, j := 0, len(arr.items)-1; i < j; i, j = i+1, j-1 {
+				arr.items[i], arr.items[j] = arr.items[j], arr.items[i]
+			}
+			return arr
+		})
+	case "toReversed":
+		return method(func(args []jsValue) jsValue {
+			items := make([]jsValue, len(arr.items))
+			for i, item := range arr.items {
+				items[len(items)-1-i] = item
+			}
+			return it.newArray(items)
+		})
+	case "indexOf", "lastIndexOf", "includes":
+		return method(func(args []jsValue) jsValue {
+			target := arg(args, 0)
+			if key == "lastIndexOf" {
+				for i := len(arr.items) - 1; i >= 0; i-- {
+					if it.strictEquals(arr.items[i], target) {
+						return float64(i)
+					}
+				}
+				return -1.0
+			}
+			for i := it.jsRelativeIndex(arg(args, 1), len(arr.items), 0); i < len(arr.items); i++ {
+				if key == "includes" && it.sameValueZero(arr.items[i], target) {
+					return true
+				}
+				if key == "indexOf" && it.strictEquals(arr.items[i], target) {
+					return float64(i)
+				}
+			}
+			if key == "includes" {
+				return false
+			}
+			return -1.0
+		})
+	case "find", "findIndex", "findLast", "findLastIndex":
+		return method(func(args []jsValue) jsValue {
+			fn := callback(args)
+			indices := make([]int, len(arr.items))
+			for i := range indices {
+				indices[i] = i
+				if strings.HasPrefix(key, "findLast") {
+					indices[i] = len(arr.items) - 1 - i
+				}
+			}
+			for _, i := range indices {
+				if i < len(arr.items) && it.truthy(it.call(fn, arg(args, 1), []jsValue{arr.items[i], float64(i), arr}, it.pos)) {
+					if strings.HasSuffix(key, "Index") {
+						return float64(i)
+					}
+					return arr.items[i]
+				}
+			}
+			if strings.HasSuffix(key, "Index") {
+				return -1.0
+			}
+			return jsUndefined
+		})
+	case "filter":
+		return method(func(args []jsValue) jsValue {
+			var items []jsValue
+			each(args, func(i int, r jsValue) bool {
+				if it.truthy(r) {
+					items = append(items, arr.items[i])
+				}
+				return false
+			})
+			return it.newArray(items)
+		})
+	case "map":
+		return method(func(args []jsValue) jsValue {
+			items := make([]jsValue, 0, len(arr.items))
+			each(args, func(i int, r jsValue) bool {
+				items = append(items, r)
+				return false
+			})
+			return it.newArray(items)
+		})
+	case "forEach":
+		return method(func(args []jsValue) jsValue {
+			each(args, func(int, jsValue) bool { return false })
+			return jsUndefined
+		})
+	case "some":
+		return method(func(args []jsValue) jsValue {
+			found := false
+			each(args, func(i int, r jsValue) bool {
+				found = it.truthy(r)
+				return found
+			})
+			return found
+		})
+	case "every":
+		return method(func(args []jsValue) jsValue {
+			all := true
+			each(args, func(i int, r jsValue) bool {
+				all = it.truthy(r)
+				return !all
+			})
+			return all
+		})
+	case "reduce", "reduceRight":
+		return method(func(args []jsValue) jsValue {
+			fn := callback(args)
+			indices := make([]int, len(arr.items))
+			for i := range indices {
+				indices[i] = i
+				if key == "reduceRight" {
+					indices[i] = len(arr.items) - 1 - i
+				}
+			}
+			var acc jsValue
+			if len(args) > 1 {
+				acc = args[1]
+			} else {
+				if len(indices) == 0 {
+					it.throwError("TypeError", it.pos, "Reduce of empty array with no initial value")
+				}
+				acc = arr.items[indices[0]]
+				indices = indices[1:]
+			}
+			for _, i := range indices {
+				if i < len(arr.items) {
+					acc = it.call(fn, jsUndefined, []jsValue{acc, arr.items[i], float64(i), arr}, it.pos)
+				}
+			}
+			return acc
+		})
+	case "sort", "toSorted":
+		return method(func(args []jsValue) jsValue {
+			items := arr.items
+			if key == "toSorted" {
+				items = append([]jsValue(nil), arr.items...)
+			}
+			it.sortValues(items, arg(args, 0))
+			if key == "toSorted" {
+				return it.newArray(items)
+			}
+			return arr
+		})
+	case "fill":
+		return method(func(args []jsValue) jsValue {
+			start := it.jsRelativeIndex(arg(args, 1), len(arr.items), 0)
+			end := it.jsRelativeIndex(arg(args, 2), len(arr.items), len(arr.items))
+			for i := start; i < end; i++ {
+				arr.items[i] = arg(args, 0)
+			}
+			return arr
+		})
+	case "flat", "flatMap":
+		return method(func(args []jsValue) jsValue {
+			depth := 1
+			source := arr.items
+			if key == "flatMap" {
+				fn := callback(args)
+				source = make([]jsValue, len(arr.items))
+				for i, item := range arr.items {
+					source[i] = it.call(fn, arg(args, 1), []jsValue{item, float64(i), arr}, it.pos)
+				}
+			} else if d := arg(args, 0); d != jsUndefined {
+				depth = int(math.Min(it.toNumber(d), 1000))
+			}
+			var flatten func(items []jsValue, depth int) []jsValue
+			flatten = func(items []jsValue, depth int) []jsValue {
+				var out []jsValue
+				for _, item := range items {
+					if inner, ok := item.(*jsArray); ok && depth > 0 {
+						out = append(out, flatten(inner.items, depth-1)...)
+					} else {
+						out = append(out, item)
+					}
+				}
+				return out
+			}
+			return it.newArray(flatten(source, depth))
+		})
+	case "at":
+		return method(func(args []jsValue) jsValue {
+			i := int(it.toNumber(arg(args, 0)))
+			if i < 0 {
+				i += len(arr.items)
+			}
+			if i < 0 || i >= len(arr.items) {
+				return jsUndefined
+			}
+			return arr.items[i]
+		})
+	case "keys", "values", "entries":
+		return method(func(args []jsValue) jsValue {
+			items := make([]jsValue, len(arr.items))
+			for i, item := range arr.items {
+				switch key {
+				case "keys":
+					items[i] = float64(i)
+				case "values":
+					items[i] = item
+				default:
+					items[i] = it.newArray([]jsValue{float64(i), item})
+				}
+			}
+			return it.newArray(items)
+		})
+	case "toString":
+		return method(func(args []jsValue) jsValue { return it.toString(arr) })
+	}
+	return jsUndefined
+}
+
+// sortValues ordena items como Array.prototype.sort: estable, con undefined
+// al final y, sin comparador, por su texto
+func (it *jsInterp) sortValues(items []jsValue, compare jsValue) {
+	fn, hasCompare := compare.(*jsFunction)
+	if compare != jsUndefined && !hasCompare {
+		it.throwError("TypeError", it.pos, "The comparison function must be either a function or undefined")
+	}
+	var keys []string
+	if !hasCompare {
+		keys = make([]string, len(items))
+		for i, item := range items {
+			keys[i] = it.toString(item)
+		}
+	}
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		x, y := items[order[a]], items[order[b]]
+		if x == jsUndefined || y == jsUndefined {
+			return y == jsUndefined && x != jsUndefined
+		}
+		if !hasCompare {
+			return jsUTF16Less(keys[order[a]], keys[order[b]])
+		}
+		r := it.toNumber(it.call(fn, jsUndefined, []jsValue{x, y}, it.pos))
+		return r < 0
+	})
+	sorted := make([]jsValue, len(items))
+	for i, j := range order {
+		sorted[i] = items[j]
+	}
+	copy(items, sorted)
+}
+
+// jsUTF16Less compara cadenas por unidades UTF-16, como JavaScript
+func jsUTF16Less(a, b string) bool {
+	if jsIsASCII(a) && jsIsASCII(b) {
+		return a < b
+	}
+	ua, ub := jsUTF16(a), jsUTF16(b)
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+	return len(ua) < len(ub)
+}
+
+// jsNaNKey representa NaN como clave de Map, donde NaN es igual a NaN
+type jsNaNKey struct{}
+
+func jsMapKey(v jsValue) jsValue {
+	if n, ok := v.(float64); ok {
+		if math.IsNaN(n) {
+			return jsNaNKey{}
+		}
+		if n == 0 {
+			return 0.0
+		}
+	}
+	return v
+}
+
+func (it *jsInterp) mapPut(m *jsMap, key, value jsValue) {
+	k := jsMapKey(key)
+	if i, ok := m.index[k]; ok {
+		m.values[i] = value
+		return
+	}
+	it.b.alloc(48)
+	m.index[k] = len(m.keys)
+	m.keys = append(m.keys, key)
+	m.values = append(m.values, value)
+}
+
+func (it *jsInterp) mapDelete(m *jsMap, key jsValue) bool {
+	k := jsMapKey(key)
+	i, ok := m.index[k]
+	if !ok {
+		return false
+	}
+	m.keys = append(m.keys[:i], m.keys[i+1:]...)
+	m.values = append(m.values[:i], m.values[i+1:]...)
+	delete(m.index, k)
+	for j := i; j < len(m.keys); j++ {
+		m.index[jsMapKey(m.keys[j])] = j
+	}
+	return true
+}
+
+func (it *jsInterp) mapMethod(m *jsMap, key string) jsValue {
+	method := func(fn func(args []jsValue) jsValue) jsValue {
+		return it.native(key, func(it *jsInterp, this jsValue, args []jsValue) jsValue { return fn(args) })
+	}
+	switch key {
+	case "size":
+		return float64(len(m.keys))
+	case "get":
+		return method(func(args []jsValue) jsValue {
+			if i, ok := m.index[jsMapKey(arg(args, 0))]; ok {
+				return m.values[i]
+			}
+			return jsUndefined
+		})
+	case "set", "add":
+		if key == "set" && m.set || key == "add" && !m.set {
+			return jsUndefined
+		}
+		return method(func(args []jsValue) jsValue {
+			if m.set {
+				it.mapPut(m, arg(args, 0), arg(args, 0))
+			} else {
+				it.mapPut(m, arg(args, 0), arg(args, 1))
+			}
+			return m
+		})
+	case "has":
+		return method(func(args []jsValue) jsValue {
+			_, ok := m.index[jsMapKey(arg(args, 0))]
+			return ok
+		})
+	case "delete":
+		return method(func(args []jsValue) jsValue { return it.mapDelete(m, arg(args, 0)) })
+	case "clear":
+		return method(func(args []jsValue) jsValue {
+			m.keys, m.values, m.index = nil, nil, make(map[jsValue]int)
+			return jsUndefined
+		})
+	case "forEach":
+		return method(func(args []jsValue) jsValue {
+			fn := arg(args, 0)
+			for i := 0; i < len(m.keys); i++ {
+				it.call(fn, arg(args, 1), []jsValue{m.values[i], m.keys[i], m}, it.pos)
+			}
+			return jsUndefined
+		})
+	case "keys", "values", "entries":
+		return method(func(args []jsValue) jsValue {
+			items := make([]jsValue, len(m.keys))
+			for i := range m.keys {
+				switch key {
+				case "keys":
+					items[i] = m.keys[i]
+				case "values":
+					items[i] = m.values[i]
+				default:
+					items[i] = it.newArray([]jsValue{m.keys[i], m.values[i]})
+				}
+			}
+			return it.newArray(items)
+		})
+	}
+	return jsUndefined
+}
+
+// ───── console.log ─────
+
+// formatLog une los argumentos de console.log: las cadenas tal cual, el
+// resto con inspect, y si la primera tiene %s, %d, %i, %f, %j, %o, %O o %c
+// los reemplaza por los argumentos siguientes
+func (it *jsInterp) formatLog(args []jsValue) string {
+	var parts []string
+	rest := args
+	if format, ok := arg(args, 0).(string); ok && len(args) > 1 && strings.Contains(format, "%") {
+		rest = args[1:]
+		var sb strings.Builder
+		for i := 0; i < len(format); i++ {
+			if format[i] != '%' || i+1 >= len(format) {
+				sb.WriteByte(format[i])
+				continue
+			}
+			verb := format[i+1]
+			if verb == '%' {
+				sb.WriteByte('%')
+				i++
+				continue
+			}
+			if !strings.ContainsRune("sdifjoOc", rune(verb)) || len(rest) == 0 {
+				sb.WriteByte('%')
+				continue
+			}
+			v := rest[0]
+			rest = rest[1:]
+			i++
+			switch verb {
+			case 's':
+				if s, ok := v.(string); ok {
+					sb.WriteString(s)
+				} else if n, ok := v.(float64); ok {
+					sb.WriteString(jsInspectNumber(n))
+				} else if isJSObject(v) {
+					sb.WriteString(it.inspect(v))
+				} else {
+					sb.WriteString(it.toString(v))
+				}
+			case 'd', 'i':
+				if isJSObject(v) {
+					sb.WriteString("NaN")
+					break
+				}
+				n := it.toNumber(v)
+				if verb == 'i' {
+					n = math.Trunc(n)
+				}
+				sb.WriteString(jsInspectNumber(n))
+			case 'f':
+				sb.WriteString(jsInspectNumber(jsParseFloatPrefix(it.toString(v))))
+			case 'j':
+				var js strings.Builder
+				if it.jsonWrite(&js, v, "", "", nil) {
+					sb.WriteString(js.String())
+				} else {
+					sb.WriteString("undefined")
+				}
+			case 'o', 'O':
+				sb.WriteString(it.inspect(v))
+			}
+		}
+		parts = append(parts, sb.String())
+	}
+	for _, v := range rest {
+		if s, ok := v.(string); ok {
+			parts = append(parts, s)
+		} else {
+			parts = append(parts, it.inspect(v))
+		}
+	}
+	s := strings.Join(parts, " ")
+	it.b.alloc(len(s))
+	return s
+}
+
+func jsInspectNumber(n float64) string {
+	if n == 0 && math.Signbit(n) {
+		return "-0"
+	}
+	return jsNumberToString(n)
+}
+
+// jsInspector lleva el estado de util.inspect: sangría, profundidad y los
+// objetos en curso para detectar referencias circulares
+type jsInspector struct {
+	it           *jsInterp
+	indentation  int
+	currentDepth int
+	seen         []jsValue
+	circular     map[jsValue]int
+}
+
+// Opciones por defecto de util.inspect en node
+const (
+	jsInspectDepth       = 2
+	jsInspectBreakLength = 80
+	jsInspectCompact     = 3
+	jsInspectMaxArray    = 100
+)
+
+func (it *jsInterp) inspect(v jsValue) string {
+	ins := &jsInspector{it: it, circular: make(map[jsValue]int)}
+	return ins.format(v, 0)
+}
+
+func (ins *jsInspector) format(v jsValue, recurseTimes int) string {
+	ins.it.b.step()
+	switch v := v.(type) {
+	case string:
+		return jsQuote(v)
+	case float64:
+		return jsInspectNumber(v)
+	case bool, jsUndefinedType, jsNullType:
+		return ins.it.toString(v)
+	}
+	for _, seen := range ins.seen {
+		if seen == v {
+			idx, ok := ins.circular[v]
+			if !ok {
+				idx = len(ins.circular) + 1
+				ins.circular[v] = idx
+			}
+			return fmt.Sprintf("[Circular *%d]", idx)
+		}
+	}
+	return ins.formatRaw(v, recurseTimes)
+}
+
+func (ins *jsInspector) formatRaw(v jsValue, recurseTimes int) string {
+	it := ins.it
+	var output []string
+	var base string
+	braces := [2]string{"{", "}"}
+	isArray := false
+	name := "Object"
+	var keys []string
+	var owner *jsObject
+	switch o := v.(type) {
+	case *jsArray:
+		if len(o.items) == 0 {
+			return "[]"
+		}
+		braces, isArray, name = [2]string{"[", "]"}, true, "Array"
+	case *jsMap:
+		name = "Map"
+		if o.set {
+			name = "Set"
+		}
+		prefix := fmt.Sprintf("%s(%d) ", name, len(o.keys))
+		if len(o.keys) == 0 {
+			return prefix + "{}"
+		}
+		braces[0] = prefix + "{"
+	case *jsFunction:
+		base = ins.functionBase(o)
+		if o.props == nil || len(o.props.keys) == 0 {
+			return base
+		}
+		owner, keys = o.props, o.props.keys
+		name = "Function"
+	case *jsObject:
+		owner, keys = o, o.keys
+		if o.trace != "" {
+			base = ins.errorStack(o)
+			keys = ins.errorKeys(o, base)
+			if len(keys) == 0 {
+				return base
+			}
+			break
+		}
+		if o.class != nil && o.class != it.objectClass {
+			name = o.class.name
+			braces[0] = name + " {"
+		}
+		if len(keys) == 0 {
+			return braces[0] + "}"
+		}
+	}
+	if recurseTimes > jsInspectDepth {
+		return "[" + name + "]"
+	}
+	recurseTimes++
+	ins.seen = append(ins.seen, v)
+	ins.currentDepth = recurseTimes
+	switch o := v.(type) {
+	case *jsArray:
+		ins.indentation += 2
+		for i, item := range o.items {
+			if i == jsInspectMaxArray {
+				more := len(o.items) - i
+				output = append(output, fmt.Sprintf("... %d more item%s", more, map[bool]string{true: "s"}[more > 1]))
+				break
+			}
+			output = append(output, ins.format(item, recurseTimes))
+		}
+		ins.indentation -= 2
+	case *jsMap:
+		ins.indentation += 2
+		for i := range o.keys {
+			if o.set {
+				output = append(output, ins.format(o.keys[i], recurseTimes))
+			} else {
+				output = append(output, ins.format(o.keys[i], recurseTimes)+" => "+ins.format(o.values[i], recurseTimes))
+			}
+		}
+		ins.indentation -= 2
+	}
+	if owner != nil {
+		for _, k := range jsSortKeys(append([]string(nil), keys...)) {
+			var value string
+			switch getter, setter := owner.getters[k], owner.setters[k]; {
+			case getter != nil && setter != nil:
+				value = "[Getter/Setter]"
+			case getter != nil:
+				value = "[Getter]"
+			case setter != nil:
+				value = "[Setter]"
+			default:
+				ins.indentation += 2
+				value = ins.format(owner.props[k], recurseTimes)
+				ins.indentation -= 2
+			}
+			output = append(output, jsInspectKey(k)+": "+value)
+		}
+	}
+	ins.seen = ins.seen[:len(ins.seen)-1]
+	res := ins.reduceToSingleString(output, base, braces, isArray, recurseTimes, v)
+	if idx, ok := ins.circular[v]; ok {
+		res = fmt.Sprintf("<ref *%d> %s", idx, res)
+	}
+	return res
+}
+
+func (ins *jsInspector) functionBase(fn *jsFunction) string {
+	if fn.class {
+		base := "[class " + fn.name
+		if fn.name == "" {
+			base = "[class (anonymous)"
+		}
+		if fn.parent != nil {
+			base += " extends " + fn.parent.name
+		}
+		return base + "]"
+	}
+	kind := "Function"
+	if fn.node != nil && strings.HasPrefix(ins.it.src[fn.node.Pos:], "async") {
+		kind = "AsyncFunction"
+	}
+	if fn.name == "" {
+		return "[" + kind + " (anonymous)]"
+	}
+	return "[" + kind + ": " + fn.name + "]"
+}
+
+// errorStack devuelve el stack de un error como lo muestra node: con el
+// nombre de la clase si es una subclase que no cambió name
+func (ins *jsInspector) errorStack(obj *jsObject) string {
+	it := ins.it
+	header := it.errorHeader(obj)
+	name := it.toString(it.lookupProperty(obj, "name", obj))
+	if obj.class != nil && obj.class.name != name && strings.HasPrefix(header, name) {
+		ctor := obj.class.name
+		if strings.Contains(ctor, name) {
+			header = ctor + header[len(name):]
+		} else {
+			header = ctor + " [" + name + "]" + header[len(name):]
+		}
+	}
+	return header + "\n" + obj.trace
+}
+
+// errorKeys devuelve las claves propias de un error que se muestran junto
+// al stack: name y message se omiten si el stack ya los incluye
+func (ins *jsInspector) errorKeys(obj *jsObject, stack string) []string {
+	var keys []string
+	for _, k := range obj.keys {
+		if s, ok := obj.props[k].(string); ok && (k == "name" || k == "message") && strings.Contains(stack, s) {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// reduceToSingleString une las entradas en una línea si entran en 80
+// columnas y no hay más de tres niveles anidados; si no, una por línea. Los
+// arreglos de más de 6 elementos cortos se agrupan en columnas
+func (ins *jsInspector) reduceToSingleString(output []string, base string, braces [2]string, isArray bool, recurseTimes int, v jsValue) string {
+	entries := len(output)
+	if isArray && entries > 6 {
+		output = ins.groupArrayElements(output, v.(*jsArray))
+	}
+	prefix := ""
+	if base != "" {
+		prefix = base + " "
+	}
+	if ins.currentDepth-recurseTimes < jsInspectCompact && entries == len(output) {
+		start := len(output) + ins.indentation + jsStringLength(braces[0]) + jsStringLength(base) + 10
+		if ins.isBelowBreakLength(output, start, base) {
+			joined := strings.Join(output, ", ")
+			if !strings.Contains(joined, "\n") {
+				return prefix + braces[0] + " " + joined + " " + braces[1]
+			}
+		}
+	}
+	indentation := "\n" + strings.Repeat(" ", ins.indentation)
+	return prefix + braces[0] + indentation + "  " + strings.Join(output, ","+indentation+"  ") + indentation + braces[1]
+}
+
+func (ins *jsInspector) isBelowBreakLength(output []string, start int, base string) bool {
+	total := len(output) + start
+	if total+len(output) > jsInspectBreakLength {
+		return false
+	}
+	for _, s := range output {
+		total += jsStringLength(s)
+		if total > jsInspectBreakLength {
+			return false
+		}
+	}
+	return base == "" || !strings.Contains(base, "\n")
+}
+
+// groupArrayElements acomoda los elementos de un arreglo en columnas, con
+// el mismo cálculo que node
+func (ins *jsInspector) groupArrayElements(output []string, arr *jsArray) []string {
+	totalLength, maxLength := 0, 0
+	outputLength := len(output)
+	if len(arr.items) > jsInspectMaxArray {
+		outputLength--
+	}
+	const separatorSpace = 2
+	dataLen := make([]int, outputLength)
+	for i := 0; i < outputLength; i++ {
+		l := jsStringLength(output[i])
+		dataLen[i] = l
+		totalLength += l + separatorSpace
+		if maxLength < l {
+			maxLength = l
+		}
+	}
+	actualMax := maxLength + separatorSpace
+	if actualMax*3+ins.indentation >= jsInspectBreakLength ||
+		!(float64(totalLength)/float64(actualMax) > 5 || maxLength <= 6) {
+		return output
+	}
+	averageBias := math.Sqrt(float64(actualMax) - float64(totalLength)/float64(len(output)))
+	biasedMax := math.Max(float64(actualMax)-3-averageBias, 1)
+	columns := int(math.Min(math.Min(
+		math.Round(math.Sqrt(2.5*biasedMax*float64(outputLength))/biasedMax),
+		math.Floor(float64(jsInspectBreakLength-ins.indentation)/float64(actualMax))),
+		math.Min(jsInspectCompact*4, 15)))
+	if columns <= 1 {
+		return output
+	}
+	var maxLineLength []int
+	for i := 0; i < columns; i++ {
+		lineLength := 0
+		for j := i; j < len(output); j += columns {
+			if j < outputLength && dataLen[j] > lineLength {
+				lineLength = dataLen[j]
+			}
+		}
+		maxLineLength = append(maxLineLength, lineLength+separatorSpace)
+	}
+	padStart := true
+	for i := 0; i < len(arr.items) && i < len(output); i++ {
+		if _, ok := arr.items[i].(float64); !ok {
+			padStart = false
+			break
+		}
+	}
+	pad := func(s string, width int) string {
+		if n := width - jsStringLength(s); n > 0 {
+			if padStart {
+				return strings.Repeat(" ", n) + s
+			}
+			return s + strings.Repeat(" ", n)
+		}
+		return s
+	}
+	var tmp []string
+	for i := 0; i < outputLength; i += columns {
+		maxIdx := min(i+columns, outputLength)
+		var sb strings.Builder
+		j := i
+		for ; j < maxIdx-1; j++ {
+			sb.WriteString(pad(output[j]+", ", maxLineLength[j-i]))
+		}
+		if padStart {
+			sb.WriteString(pad(output[j], maxLineLength[j-i]-separatorSpace))
+		} else {
+			sb.WriteString(output[j])
+		}
+		tmp = append(tmp, sb.String())
+	}
+	if len(arr.items) > jsInspectMaxArray {
+		tmp = append(tmp, output[outputLength])
+	}
+	return tmp
+}
+
+// jsInspectKey muestra una clave sin comillas si es un identificador
+func jsInspectKey(k string) string {
+	if k == "" {
+		return "''"
+	}
+	for i := 0; i < len(k); i++ {
+		c := k[i]
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9') {
+			return jsQuote(k)
+		}
+	}
+	return k
+}
+
+// jsQuote pone una cadena entre comillas como util.inspect: simples, o
+// dobles si tiene comillas simples, o invertidas si tiene de los dos tipos
+func jsQuote(s string) string {
+	quote := byte('\'')
+	if strings.Contains(s, "'") {
+		if !strings.Contains(s, "\"") {
+			quote = '"'
+		} else if !strings.Contains(s, "`") && !strings.Contains(s, "${") {
+			quote = '`'
+		}
+	}
+	var sb strings.Builder
+	sb.WriteByte(quote)
+	for _, r := range s {
+		switch {
+		case r == rune(quote) || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r == '\b':
+			sb.WriteString(`\b`)
+		case r == '\f':
+			sb.WriteString(`\f`)
+		case r < 0x20 || r >= 0x7f && r <= 0x9f:
+			fmt.Fprintf(&sb, `\x%02X`, r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte(quote)
+	return sb.String()
+}
@@ -0,0 +1,892 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// Salidas de node 20 (node main.js) para el subconjunto que cubre el
+// intérprete integrado; un caso nuevo se agrega con la salida del real. De
+// stderr se omiten las líneas "    at ..." de la traza, que en node incluyen
+// los marcos internos del cargador de módulos, y el pie "Node.js v20..."
+var javascriptInterpCases = []interpCase{
+	{
+		name: "aritmetica",
+		code: `console.log(7 / 2, 7 % 3, -7 % 3, 2 ** 10, Math.floor(-3.5), Math.trunc(-3.5));
+console.log(0.1 + 0.2, 1e21, 1.5e-7, 1 / 3, 10 / 0, -1 / 0, 0 / 0, -0);
+console.log(parseInt("42px"), parseFloat("3.5e2"), Number("0x1f"), Number(""), Number("abc"), +true);
+console.log((255).toString(16), (5).toString(2), (3.14159).toFixed(2), (1234.5).toFixed(0), Number.MAX_SAFE_INTEGER);
+console.log(5 & 3, 5 | 3, 5 ^ 3, ~5, 1 << 10, -16 >> 2, -16 >>> 28);
+console.log(Math.max(3, 9, 2), Math.min(), Math.abs(-4), Math.round(2.5), Math.round(-2.5), Math.sqrt(16), Math.PI);
+console.log(1 == "1", 1 === "1", null == undefined, null === undefined, NaN == NaN, "10" < "9", 10 < 9);
+console.log(typeof 1, typeof "s", typeof null, typeof undefined, typeof {}, typeof [], typeof function () {});
+`,
+		stdout: `3.5 1 -1 1024 -4 -3
+0.30000000000000004 1e+21 1.5e-7 0.3333333333333333 Infinity -Infinity NaN -0
+42 350 31 0 NaN 1
+ff 101 3.14 1235 9007199254740991
+1 7 6 -6 1024 -4 15
+9 Infinity 4 3 -2 4 3.141592653589793
+true false true false false true false
+number string object undefined object object function
+`,
+	},
+	{
+		name: "cadenas",
+		code: `const s = "Hola, Mundo";
+console.log(s.toUpperCase(), s.toLowerCase(), s.length, s[0], s.charAt(4), s.slice(-5), s.substring(0, 4));
+console.log(s.split(", "), ["a", "b", "c"].join("-"), s.replace("o", "0"), s.replaceAll("o", "0"), s.indexOf("M"));
+console.log("  x  ".trim() + "|", "abc".startsWith("ab"), "abc".endsWith("bc"), "ab".repeat(3), "a,b,,c".split(","));
+console.log("3".padStart(4, "0"), "x".padEnd(3) + "|", "abc".includes("b"), "abc".at(-1), "b".charCodeAt(0), String.fromCharCode(97));
+const nombre = "Ana", edad = 30;
+console.log(` + "`" + `${nombre} tiene ${edad} años` + "`" + `, ` + "`" + `${1 + 2}` + "`" + `, ` + "`" + `a
+b` + "`" + `);
+console.log("abc" < "abd", "5" + 3, "5" - 3, "5" * "2", [1, 2] + "", String(null), String([1, [2, 3]]));
+console.log(s);
+console.log([s], { s });
+`,
+		stdout: `HOLA, MUNDO hola, mundo 11 H , Mundo Hola
+[ 'Hola', 'Mundo' ] a-b-c H0la, Mundo H0la, Mund0 6
+x| true true ababab [ 'a', 'b', '', 'c' ]
+0003 x  | true c 98 a
+Ana tiene 30 años 3 a
+b
+true 53 2 10 1,2 null 1,2,3
+Hola, Mundo
+[ 'Hola, Mundo' ] { s: 'Hola, Mundo' }
+`,
+	},
+	{
+		name: "colecciones",
+		code: `const xs = [5, 3, 8, 1];
+xs.push(7);
+xs.sort((a, b) => a - b);
+console.log(xs, xs.slice(1, 3), xs.at(-1), xs.indexOf(8), xs.includes(3), xs.pop(), xs);
+console.log(xs.map((x) => x * 2), xs.filter((x) => x > 2), xs.reduce((a, b) => a + b, 0), xs.find((x) => x > 3), xs.findIndex((x) => x > 3));
+console.log([10, 9, 1].sort(), [3, 1, 2].reverse(), [1, [2, [3, [4]]]].flat(), [1, 2].concat([3], 4), Array.from({ length: 3 }, (_, i) => i * i));
+console.log(xs.some((x) => x > 6), xs.every((x) => x > 0), [..."hola"], Array(3).fill(0), [1, 2, 3].splice(1, 1));
+const m = new Map();
+m.set("a", 1).set("b", 2);
+console.log(m, m.get("a"), m.has("z"), m.size, [...m.keys()]);
+const st = new Set([3, 1, 3, 2]);
+st.add(1);
+console.log(st, st.size, st.has(2), [...st]);
+const o = { a: 1, b: "dos", c: [1, 2], d: { e: null } };
+o.f = undefined;
+console.log(o, Object.keys(o), Object.values(o).length, Object.entries({ x: 1 }), "a" in o);
+console.log([1, "a", null, undefined, true, [], {}], [[1, [2, [3, [4]]]]], { a: { b: { c: { d: 1 } } } });
+console.log(JSON.stringify(o), JSON.stringify([1, "x", null]), JSON.stringify({ a: [1, 2] }, null, 2), JSON.parse('{"k":[1,2,{"z":true}]}'));
+`,
+		stdout: `[ 1, 3, 5, 7 ] [ 3, 5 ] 8 4 true 8 [ 1, 3, 5, 7 ]
+[ 2, 6, 10, 14 ] [ 3, 5, 7 ] 16 5 2
+[ 1, 10, 9 ] [ 2, 1, 3 ] [ 1, 2, [ 3, [ 4 ] ] ] [ 1, 2, 3, 4 ] [ 0, 1, 4 ]
+true true [ 'h', 'o', 'l', 'a' ] [ 0, 0, 0 ] [ 2 ]
+Map(2) { 'a' => 1, 'b' => 2 } 1 false 2 [ 'a', 'b' ]
+Set(3) { 3, 1, 2 } 3 true [ 3, 1, 2 ]
+{ a: 1, b: 'dos', c: [ 1, 2 ], d: { e: null }, f: undefined } [ 'a', 'b', 'c', 'd', 'f' ] 5 [ [ 'x', 1 ] ] true
+[ 1, 'a', null, undefined, true, [], {} ] [ [ 1, [ 2, [Array] ] ] ] { a: { b: { c: [Object] } } }
+{"a":1,"b":"dos","c":[1,2],"d":{"e":null}} [1,"x",null] {
+  "a": [
+    1,
+    2
+  ]
+} { k: [ 1, 2, { z: true } ] }
+`,
+	},
+	{
+		name: "funciones",
+		code: `function contador() {
+  let n = 0;
+  return () => ++n;
+}
+const c = contador();
+c();
+c();
+console.log(c());
+const suma = (...ns) => ns.reduce((a, b) => a + b, 0);
+console.log(suma(1, 2, 3), suma(...[4, 5]));
+const { a, b: [x, y = 9], ...resto } = { a: 1, b: [2], c: 3, d: 4 };
+console.log(a, x, y, resto);
+function saludo(nombre = "mundo") {
+  return "hola " + nombre;
+}
+console.log(saludo(), saludo("Ana"), saludo.length);
+const fib = (n) => (n < 2 ? n : fib(n - 1) + fib(n - 2));
+console.log(fib(20));
+`,
+		stdout: `3
+6 9
+1 2 9 { c: 3, d: 4 }
+hola mundo hola Ana 0
+6765
+`,
+	},
+	{
+		name: "clases",
+		code: `class Animal {
+  static cuenta = 0;
+  constructor(nombre) {
+    this.nombre = nombre;
+    Animal.cuenta++;
+  }
+  hablar() {
+    return ` + "`" + `${this.nombre} hace ruido` + "`" + `;
+  }
+  get mayus() {
+    return this.nombre.toUpperCase();
+  }
+  toString() {
+    return "Animal(" + this.nombre + ")";
+  }
+}
+class Perro extends Animal {
+  constructor(nombre, raza) {
+    super(nombre);
+    this.raza = raza;
+  }
+  hablar() {
+    return super.hablar() + " (guau)";
+  }
+}
+const p = new Perro("Rex", "labrador");
+console.log(p.hablar(), p.mayus, Animal.cuenta, p instanceof Animal, ` + "`" + `${p}` + "`" + `);
+console.log(p);
+console.log([new Animal("Gato")]);
+try {
+  null.x;
+} catch (e) {
+  console.log(e.name, e instanceof TypeError, e.message);
+} finally {
+  console.log("fin");
+}
+class MiError extends Error {}
+try {
+  throw new MiError("malo");
+} catch (e) {
+  console.log(e.name, e.message, e instanceof Error);
+}
+`,
+		stdout: `Rex hace ruido (guau) REX 1 true Animal(Rex)
+Perro { nombre: 'Rex', raza: 'labrador' }
+[ Animal { nombre: 'Gato' } ]
+TypeError true Cannot read properties of null (reading 'x')
+fin
+Error malo true
+`,
+	},
+	{
+		name: "varios",
+		code: `const o = { a: { b: null }, lista: [1, 2, 3] };
+console.log(o?.a?.b?.c, o.x?.y, o.lista?.[1], o.f?.(), o.a.b ?? "defecto", 0 || "o", 0 ?? "n");
+const copia = { ...o, extra: true };
+console.log(copia, [...o.lista, 4]);
+let n = 5;
+n += 2;
+n **= 2;
+n %= 10;
+n <<= 3;
+console.log(n, n++, ++n, n--, n);
+const palabras = "el perro y el gato y el raton".split(" ");
+const cuenta = {};
+for (const p of palabras) cuenta[p] = (cuenta[p] || 0) + 1;
+console.log(cuenta);
+for (const k in { x: 1, y: 2 }) console.log(k);
+let i = 0;
+do {
+  i += 3;
+} while (i < 10);
+switch (i) {
+  case 12:
+    console.log("doce");
+  case 13:
+    console.log("cae");
+    break;
+  default:
+    console.log("nunca");
+}
+console.log([3, 1, 2].toString(), [1, 2, 3].indexOf(4), Number.isInteger(5.0), (123.456).toFixed(1));
+console.log(1e21 + 1, 123456789012345680000, 2 ** 53 + 1, 0.000001, 0.0000001, -1e-7, 100 / 3);
+console.log([undefined, null].join("-"), [[]].length, Array.isArray([]), typeof NaN);
+console.log(` + "`" + `${[1, 2]}` + "`" + `, ` + "`" + `${{}}` + "`" + `, String(() => 1).length > 0, Boolean(""), !!"0");
+`,
+		stdout: `undefined undefined 2 undefined defecto o 0
+{ a: { b: null }, lista: [ 1, 2, 3 ], extra: true } [ 1, 2, 3, 4 ]
+72 72 74 74 73
+{ el: 3, perro: 1, y: 2, gato: 1, raton: 1 }
+x
+y
+doce
+cae
+3,1,2 -1 true 123.5
+1e+21 123456789012345680000 9007199254740992 0.000001 1e-7 -1e-7 33.333333333333336
+- 1 true number
+1,2 [object Object] true false true
+`,
+	},
+	{
+		name: "temporizadores",
+		code: `setTimeout(() => console.log("c"), 10);
+setTimeout(() => console.log("b"), 0);
+console.log("a");
+const id = setInterval(() => {
+  console.log("tick");
+  clearInterval(id);
+}, 20);
+`,
+		stdout: `a
+b
+c
+tick
+`,
+	},
+	{
+		name: "stdin_fs",
+		code: `const datos = require("fs").readFileSync(0, "utf8").trim().split("\n");
+const n = Number(datos[0]);
+const nums = datos[1].split(" ").map(Number);
+console.log(n, nums.reduce((a, b) => a + b, 0));
+`,
+		stdin: "3\n1 2 3\n",
+		stdout: `3 6
+`,
+	},
+	{
+		name: "stdin_readline",
+		code: `const readline = require("readline");
+const rl = readline.createInterface({ input: process.stdin });
+const lineas = [];
+rl.on("line", (l) => lineas.push(l));
+rl.on("close", () => {
+  console.log(lineas.length, lineas[1].split(" ").map(Number).map((x) => x * 10).join(","));
+});
+`,
+		stdin: "3\n1 2 3\n",
+		stdout: `2 10,20,30
+`,
+	},
+	{
+		name: "process_exit",
+		code: `console.log("uno");
+console.error("dos");
+process.exit(3);
+console.log("tres");
+`,
+		stdout: `uno
+`,
+		stderr: `dos
+`,
+		exit: 3,
+	},
+	{
+		name: "no_definida",
+		code: `console.log("antes");
+function f() {
+  console.log(total);
+}
+f();
+`,
+		stdout: `antes
+`,
+		stderr: `main.js:3
+  console.log(total);
+              ^
+
+ReferenceError: total is not defined
+`,
+		exit: 1,
+	},
+	{
+		name: "no_es_funcion",
+		code: `const o = {};
+o.metodo();
+`,
+		stdout: "",
+		stderr: `main.js:2
+o.metodo();
+  ^
+
+TypeError: o.metodo is not a function
+`,
+		exit: 1,
+	},
+	{
+		name: "lanzar_error",
+		code: `function validar(x) {
+  if (x < 0) throw new RangeError("negativo: " + x);
+  return x;
+}
+console.log(validar(1));
+validar(-2);
+`,
+		stdout: `1
+`,
+		stderr: `main.js:2
+  if (x < 0) throw new RangeError("negativo: " + x);
+             ^
+
+RangeError: negativo: -2
+`,
+		exit: 1,
+	},
+	{
+		name: "lanzar_objeto",
+		code: `throw { codigo: 3 };
+`,
+		stdout: "",
+		stderr: `
+main.js:1
+throw { codigo: 3 };
+^
+{ codigo: 3 }
+`,
+		exit: 1,
+	},
+	{
+		name: "lanzar_cadena",
+		code: `console.log("x");
+throw "boom";
+`,
+		stdout: `x
+`,
+		stderr: `
+main.js:2
+throw "boom";
+^
+boom
+(Use ` + "`" + `node --trace-uncaught ...` + "`" + ` to show where the exception was thrown)
+`,
+		exit: 1,
+	},
+	{
+		name: "token_inesperado",
+		code: `console.log("a");
+let x = 5 +;
+`,
+		stdout: "",
+		stderr: `main.js:2
+let x = 5 +;
+           ^
+
+SyntaxError: Unexpected token ';'
+`,
+		exit: 1,
+	},
+	{
+		name: "identificador_inesperado",
+		code: `let a = 1
+let b = a c;
+`,
+		stdout: "",
+		stderr: `main.js:2
+let b = a c;
+          ^
+
+SyntaxError: Unexpected identifier 'c'
+`,
+		exit: 1,
+	},
+	{
+		name: "fin_inesperado",
+		code: `function f() {
+  console.log("x");
+`,
+		stdout: "",
+		stderr: `main.js:3
+
+
+
+SyntaxError: Unexpected end of input
+`,
+		exit: 1,
+	},
+	{
+		name: "argumentos_sin_cerrar",
+		code: `console.log("a", 1
+`,
+		stdout: "",
+		stderr: `main.js:1
+console.log("a", 1
+                 ^
+
+SyntaxError: missing ) after argument list
+`,
+		exit: 1,
+	},
+	{
+		name: "bucles_y_alcance",
+		code: `const fns = [];
+for (let i = 0; i < 3; i++) {
+  fns.push(() => i);
+}
+console.log(fns.map((f) => f()));
+var viejas = [];
+for (var j = 0; j < 3; j++) viejas.push(() => j);
+console.log(viejas.map((f) => f()), j);
+let suma = 0;
+for (let a = 0, b = 10; a < b; a += 2, b--) suma += a * b;
+console.log(suma);
+for (let k = 0; ; k++) {
+  if (k === 1) continue;
+  if (k > 3) break;
+  console.log("k", k);
+}
+function contador() {
+  let n = 0;
+  return { mas: () => ++n, valor: () => n };
+}
+const c = contador();
+c.mas();
+c.mas();
+console.log(c.valor(), typeof izada, izada());
+function izada() {
+  return "izada";
+}
+{
+  let bloque = 1;
+  var fuera = bloque + 1;
+}
+console.log(fuera, typeof bloque);
+const arr = [10, 20, 30];
+for (const i in arr) console.log(i, typeof i);
+let w = 3;
+while (w--) if (w === 1) continue; else console.log("w", w);
+`,
+		stdout: `[ 0, 1, 2 ]
+[ 3, 3, 3 ] 3
+92
+k 0
+k 2
+k 3
+2 function izada
+2 undefined
+0 string
+1 string
+2 string
+w 2
+w 0
+`,
+	},
+	{
+		name: "cadenas",
+		code: `const s = "  Hola Mundo  ";
+console.log(s.trim() + "|", s.trimStart() + "|", "|" + s.trimEnd(), s.length, "abc".charAt(1), "abc".charAt(9) === "", "abc".at(-1));
+console.log("abc".charCodeAt(0), "ñ".codePointAt(0), "😀".length, "😀".codePointAt(0), String.fromCharCode(72, 105), "abc"[1], "abc"[5]);
+console.log("banana".indexOf("an"), "banana".lastIndexOf("an"), "banana".indexOf("an", 2), "banana".includes("nan"), "banana".startsWith("ban"), "banana".endsWith("na"), "banana".startsWith("an", 1));
+console.log("abcdef".slice(1, 3), "abcdef".slice(-2), "abcdef".substring(4, 1), "abcdef".substr(2, 2), "abcdef".slice(2, -1), "abc".substring(-1));
+console.log("Ñandú".toUpperCase(), "ÁRBOL".toLowerCase(), "a-b-c".split("-"), "a-b-c".split("-", 2), "abc".split(""), "".split(","), "a,b".split());
+console.log("aaa".replace("a", "b"), "aaa".replaceAll("a", "b"), "x.y.z".replaceAll(".", "/"), "hola".replace("o", (m) => m.toUpperCase()), "ab".replaceAll("", "-"), "precio: $".replace("$", "$&$&"));
+console.log("ab".repeat(3), "5".padStart(3, "0"), "x".padEnd(4, "ab") + "|", "abc".padStart(2), "a".concat("b", 1, null), ` + "`" + `multi
+linea` + "`" + `, "tab\tcomillas\"'", 'simple\'s');
+console.log("b" > "a", "B" < "a", "10" < "9", "10" < 9, "abc" === "abc", "1" == 1, null == undefined, null === undefined, NaN === NaN);
+console.log(String(123), String(null), String([1, [2, 3]]), String({}), (42).toString(), ` + "`" + `${1 + 1}${"x"}` + "`" + `, "a" + 1 + 2, 1 + 2 + "a");
+console.log([..."héllo"], Array.from("ab"), "año", "\x41", "línea\\n");
+`,
+		stdout: `Hola Mundo| Hola Mundo  | |  Hola Mundo 14 b true c
+97 241 2 128512 Hi b undefined
+1 3 3 true true true true
+bc ef bcd cd cde abc
+ÑANDÚ árbol [ 'a', 'b', 'c' ] [ 'a', 'b' ] [ 'a', 'b', 'c' ] [ '' ] [ 'a,b' ]
+baa bbb x/y/z hOla -a-b- precio: $$
+ababab 005 xaba| abc ab1null multi
+linea tab	comillas"' simple's
+true true true false true true true false false
+123 null 1,2,3 [object Object] 42 2x a12 3a
+[ 'h', 'é', 'l', 'l', 'o' ] [ 'a', 'b' ] año A línea\n
+`,
+	},
+	{
+		name: "arreglos",
+		code: `const a = [5, 1, 4];
+console.log(a.push(2, 3), a, a.pop(), a.shift(), a.unshift(0), a);
+console.log(a.slice(1, 3), a.slice(-2), a.splice(1, 2, "x", "y", "z"), a, a.concat([7], 8), a.join("/"), a.reverse(), a.toReversed());
+const nums = [3, 1, 10, 2];
+console.log(nums.sort(), nums.sort((x, y) => x - y), nums.toSorted((x, y) => y - x), nums, ["b", "a", "C"].sort());
+console.log(nums.indexOf(10), nums.lastIndexOf(3), nums.includes(2), nums.find((x) => x > 2), nums.findIndex((x) => x > 2), nums.findLast((x) => x < 3), nums.findLastIndex((x) => x > 100));
+console.log(nums.filter((x) => x % 2), nums.map((x, i) => x * i), nums.some((x) => x > 5), nums.every((x) => x > 0), nums.reduce((s, x) => s + x), nums.reduceRight((s, x) => s + x, ""));
+nums.forEach((x, i, arr) => {
+  if (i === 0) console.log("forEach", x, arr.length);
+});
+console.log(new Array(3).fill(0), [1, 2, 3, 4].fill(9, 1, 3), [1, [2, [3, [4]]]].flat(), [1, [2, [3, [4]]]].flat(Infinity), [1, 2].flatMap((x) => [x, x * 10]), [1, 2, 3].at(-1));
+console.log([...[1, 2].keys()], [...["a", "b"].entries()], [..."ab"].map((c) => c + c), Array.from({ length: 3 }, (_, i) => i * i), Array.of(7, 8));
+const largo = Array.from({ length: 30 }, (_, i) => i * 3);
+console.log(largo);
+console.log(Array.from({ length: 120 }, (_, i) => i));
+console.log([["a", 1], ["b", 2]], [{ x: 1 }, [2, [3, [4, [5]]]]], [undefined, null, 3], [function f() {}, class K {}, () => 1]);
+const [p, q = 9, ...resto] = [1, 2, undefined, 4, 5];
+const { x, y: { z = "def" } = {}, ...otros } = { x: 1, w: 2, v: 3 };
+console.log(p, q, resto, x, z, otros);
+const matriz = [[1, 2], [3, 4]];
+console.log(matriz.map((fila) => fila.join(" ")).join("\n"));
+console.log([1, 2, 3].length = 2, [0, 1].map(String), [" a ", "b "].map((s) => s.trim()), [1, 2, 3].join(), [].join(), [null].toString());
+`,
+		stdout: `5 [ 0, 1, 4, 2 ] 3 5 4 [ 0, 1, 4, 2 ]
+[ 1, 4 ] [ 4, 2 ] [ 1, 4 ] [ 2, 'z', 'y', 'x', 0 ] [
+  0, 'x', 'y', 'z',
+  2, 7,   8
+] 0/x/y/z/2 [ 2, 'z', 'y', 'x', 0 ] [ 0, 'x', 'y', 'z', 2 ]
+[ 1, 2, 3, 10 ] [ 1, 2, 3, 10 ] [ 10, 3, 2, 1 ] [ 1, 2, 3, 10 ] [ 'C', 'a', 'b' ]
+3 2 true 3 2 2 -1
+[ 1, 3 ] [ 0, 2, 6, 30 ] true true 16 10321
+forEach 1 4
+[ 0, 0, 0 ] [ 1, 9, 9, 4 ] [ 1, 2, [ 3, [ 4 ] ] ] [ 1, 2, 3, 4 ] [ 1, 10, 2, 20 ] 3
+[ 0, 1 ] [ [ 0, 'a' ], [ 1, 'b' ] ] [ 'aa', 'bb' ] [ 0, 1, 4 ] [ 7, 8 ]
+[
+   0,  3,  6,  9, 12, 15, 18, 21, 24,
+  27, 30, 33, 36, 39, 42, 45, 48, 51,
+  54, 57, 60, 63, 66, 69, 72, 75, 78,
+  81, 84, 87
+]
+[
+   0,  1,  2,  3,  4,  5,  6,  7,  8,  9, 10, 11,
+  12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23,
+  24, 25, 26, 27, 28, 29, 30, 31, 32, 33, 34, 35,
+  36, 37, 38, 39, 40, 41, 42, 43, 44, 45, 46, 47,
+  48, 49, 50, 51, 52, 53, 54, 55, 56, 57, 58, 59,
+  60, 61, 62, 63, 64, 65, 66, 67, 68, 69, 70, 71,
+  72, 73, 74, 75, 76, 77, 78, 79, 80, 81, 82, 83,
+  84, 85, 86, 87, 88, 89, 90, 91, 92, 93, 94, 95,
+  96, 97, 98, 99,
+  ... 20 more items
+]
+[ [ 'a', 1 ], [ 'b', 2 ] ] [ { x: 1 }, [ 2, [ 3, [Array] ] ] ] [ undefined, null, 3 ] [ [Function: f], [class K], [Function (anonymous)] ]
+1 2 [ undefined, 4, 5 ] 1 def { w: 2, v: 3 }
+1 2
+3 4
+2 [ '0', '1' ] [ 'a', 'b' ] 1,2,3  
+`,
+	},
+	{
+		name: "objetos_y_clases",
+		code: `const clave = "din";
+const o = { a: 1, [clave + "amica"]: 2, "con espacio": 3, 7: "siete", metodo() { return this.a; }, get doble() { return this.a * 2; }, set doble(v) { this.a = v / 2; } };
+console.log(o, o.metodo(), o.doble);
+o.doble = 10;
+console.log(o.a, Object.keys(o), Object.values({ x: 1, y: [2] }), Object.entries({ k: "v" }));
+delete o.dinamica;
+console.log("dinamica" in o, "a" in o, o.hasOwnProperty("a"), Object.assign({}, { a: 1 }, { b: 2 }), Object.fromEntries([["x", 1]]), { ...null, ...{ c: 3 } });
+console.log(JSON.stringify(o));
+class Animal {
+  static cuenta = 0;
+  nombre = "sin nombre";
+  constructor(nombre) {
+    if (nombre) this.nombre = nombre;
+    Animal.cuenta++;
+  }
+  hablar() {
+    return ` + "`" + `${this.nombre} hace ${this.sonido()}` + "`" + `;
+  }
+  sonido() {
+    return "...";
+  }
+  get secreto() {
+    return this.nombre.length * 10;
+  }
+  static crear(n) {
+    return new this(n);
+  }
+  toString() {
+    return ` + "`" + `Animal(${this.nombre})` + "`" + `;
+  }
+}
+class Perro extends Animal {
+  constructor(nombre) {
+    super(nombre);
+    this.patas = 4;
+  }
+  sonido() {
+    return "guau";
+  }
+  hablar() {
+    return super.hablar() + "!";
+  }
+}
+const p = new Perro("Fido");
+console.log(p.hablar(), new Animal().hablar(), Animal.cuenta, p instanceof Animal, p instanceof Perro, [] instanceof Object, p.secreto, ` + "`" + `${p}` + "`" + `, String(p));
+console.log(p, Perro.crear("Rex").nombre, Object.getPrototypeOf(p) === Perro.prototype, p.constructor.name, typeof Perro, Perro.name);
+console.log(Animal, Perro, function nombrada() {}, () => {}, async function asincrona() {}, Math.max, class {});
+function Punto(x, y) {
+  this.x = x;
+  this.y = y;
+}
+Punto.prototype.norma = function () {
+  return Math.hypot(this.x, this.y);
+};
+const pt = new Punto(3, 4);
+console.log(pt, pt.norma(), pt instanceof Punto);
+function saludo(saludo, signo) {
+  return ` + "`" + `${saludo}, ${this.nombre}${signo}` + "`" + `;
+}
+console.log(saludo.call({ nombre: "Ana" }, "Hola", "!"), saludo.apply({ nombre: "Beto" }, ["Chao", "."]), saludo.bind({ nombre: "Cris" }, "Hey")("?"), saludo.length, saludo.name);
+const anidado = { nivel1: { nivel2: { nivel3: { nivel4: "hondo" } } }, lista: [[1, [2, [3]]]] };
+console.log(anidado);
+console.log({ f() {}, g: () => 1, h: function () {}, s: "texto", n: null, u: undefined, d: -0, m: new Map([[1, { a: 1 }]]), st: new Set(["x"]) });
+`,
+		stdout: `{
+  '7': 'siete',
+  a: 1,
+  dinamica: 2,
+  'con espacio': 3,
+  metodo: [Function: metodo],
+  doble: [Getter/Setter]
+} 1 2
+5 [ '7', 'a', 'dinamica', 'con espacio', 'metodo', 'doble' ] [ 1, [ 2 ] ] [ [ 'k', 'v' ] ]
+false true true { a: 1, b: 2 } { x: 1 } { c: 3 }
+{"7":"siete","a":5,"con espacio":3,"doble":10}
+Fido hace guau! sin nombre hace ... 2 true true true 40 Animal(Fido) Animal(Fido)
+Perro { nombre: 'Fido', patas: 4 } Rex true Perro function Perro
+[class Animal] { cuenta: 3 } [class Perro extends Animal] [Function: nombrada] [Function (anonymous)] [AsyncFunction: asincrona] [Function: max] [class (anonymous)]
+Punto { x: 3, y: 4 } 5 true
+Hola, Ana! Chao, Beto. Hey, Cris? 2 saludo
+{ nivel1: { nivel2: { nivel3: [Object] } }, lista: [ [ 1, [Array] ] ] }
+{
+  f: [Function: f],
+  g: [Function: g],
+  h: [Function: h],
+  s: 'texto',
+  n: null,
+  u: undefined,
+  d: -0,
+  m: Map(1) { 1 => { a: 1 } },
+  st: Set(1) { 'x' }
+}
+`,
+	},
+	{
+		name: "numeros_y_json",
+		code: `console.log((1234.5678).toPrecision(6), (0.00012345).toPrecision(2), (123456).toPrecision(2), (255).toString(2), (-255).toString(36), (3).toPrecision());
+console.log((1.005).toFixed(2), (1e21).toFixed(2), (-1.5).toFixed(0), (0).toFixed(2), Number("  12  "), Number("1e3"), Number(null), Number(undefined), Number([5]), Number("12px"));
+console.log(parseInt("ff", 16), parseInt("0x10"), parseInt("  -7.9"), parseInt("abc"), parseFloat(".5"), parseFloat("-.5e1x"), Number.parseFloat("1.5"), parseInt("101", 2));
+console.log(Number.isNaN("x"), isNaN("x"), Number.isFinite("5"), isFinite("5"), Number.EPSILON > 0, Number.MIN_SAFE_INTEGER, Number.MAX_VALUE, Number.isSafeInteger(2 ** 53));
+console.log(Math.ceil(-0.5), Math.sign(-3), Math.cbrt(27), Math.log2(8), Math.log10(1000), Math.hypot(3, 4), Math.pow(2, 0.5), Math.E, Math.atan2(1, 1) * 4);
+console.log(0.1 * 3, 1 / 7, 123e-20, 2 ** 64, -(2 ** 31), 5 / 2 | 0, 7 >>> 1, -1 >>> 0, 2 ** 32 | 0, 1_000_000, 0b101, 0o17, 0xff);
+console.log(typeof null, typeof undefined, typeof {}, typeof [], typeof "");
+const datos = { nombre: "Ana", edad: 30, notas: [90, 85.5], activo: true, extra: null, nada: undefined, f() {}, anidado: { a: [] } };
+console.log(JSON.stringify(datos));
+console.log(JSON.stringify(datos, null, 2));
+console.log(JSON.stringify([1, "a\n\"b\"", undefined, () => 1, NaN]), JSON.stringify("ñ\u0001"), JSON.stringify(null), JSON.stringify(undefined), JSON.stringify({ a: [{}] }, null, "--"));
+const leido = JSON.parse('{"x": [1, 2.5, "tres", true, null], "y": {"z": -1e2}}');
+console.log(leido, leido.x[2], JSON.parse("42"), JSON.parse('"cadena"'), JSON.parse("[]"));
+try {
+  JSON.parse("{malo}");
+} catch (e) {
+  console.log(e.name, e instanceof SyntaxError);
+}
+const ciclo = { a: 1 };
+ciclo.yo = ciclo;
+try {
+  JSON.stringify(ciclo);
+} catch (e) {
+  console.log(e.name);
+}
+console.log(ciclo);
+`,
+		stdout: `1234.57 0.00012 1.2e+5 11111111 -73 3
+1.00 1e+21 -2 0.00 12 1000 0 NaN 5 NaN
+255 16 -7 NaN 0.5 -5 1.5 5
+false true false true true -9007199254740991 1.7976931348623157e+308 false
+-0 -1 3 3 3 5 1.4142135623730951 2.718281828459045 3.141592653589793
+0.30000000000000004 0.14285714285714285 1.23e-18 18446744073709552000 -2147483648 2 3 4294967295 0 1000000 5 15 255
+object undefined object object string
+{"nombre":"Ana","edad":30,"notas":[90,85.5],"activo":true,"extra":null,"anidado":{"a":[]}}
+{
+  "nombre": "Ana",
+  "edad": 30,
+  "notas": [
+    90,
+    85.5
+  ],
+  "activo": true,
+  "extra": null,
+  "anidado": {
+    "a": []
+  }
+}
+[1,"a\n\"b\"",null,null,null] "ñ\u0001" null undefined {
+--"a": [
+----{}
+--]
+}
+{ x: [ 1, 2.5, 'tres', true, null ], y: { z: -100 } } tres 42 cadena []
+SyntaxError true
+TypeError
+<ref *1> { a: 1, yo: [Circular *1] }
+`,
+	},
+	{
+		name: "map_set_y_errores",
+		code: `const m = new Map();
+m.set("a", 1).set(2, "dos").set(NaN, "nan");
+console.log(m, m.size, m.get("a"), m.get(NaN), m.has(2), m.delete(2), m.delete("zz"), m.size, [...m.keys()], [...m.values()], [...m.entries()]);
+m.forEach((v, k) => console.log("map", k, v));
+for (const [k, v] of m) console.log(k, "=>", v);
+m.clear();
+console.log(m, new Map([[{ o: 1 }, [1]]]));
+const s = new Set([1, 2, 2, 3, "3"]);
+s.add(4);
+console.log(s, s.size, s.has("3"), s.delete(1), [...s], [...new Set("hola mundo")].join(""));
+s.forEach((v) => v === 4 && console.log("set tiene", v));
+const errores = [() => null.x, () => undefined.y(), () => x.y, () => (1)(), () => new Array(-1), () => { throw new Error("propio"); }, () => { throw "cadena"; }, () => JSON.parse("")];
+for (const f of errores) {
+  try {
+    f();
+  } catch (e) {
+    console.log(e instanceof Error ? ` + "`" + `${e.name}: ${e.message}` + "`" + ` : ` + "`" + `valor: ${e}` + "`" + `);
+  }
+}
+class MiError extends Error {
+  constructor(msg) {
+    super(msg);
+    this.name = "MiError";
+    this.codigo = 7;
+  }
+}
+try {
+  throw new MiError("falló");
+} catch (e) {
+  console.log(e.name, e.message, e.codigo, e instanceof MiError, e instanceof Error, String(e));
+} finally {
+  console.log("finally");
+}
+function conFinally() {
+  try {
+    return "try";
+  } finally {
+    console.log("limpia");
+  }
+}
+console.log(conFinally());
+const e = new TypeError("tipo");
+console.log(e.message, e.name, ` + "`" + `${e}` + "`" + `);
+console.log("%s tiene %d años y %i hijos, %f%%", "Ana", 30.5, 2.9, 1.5, "extra");
+console.log("%o y %O", { a: 1 }, [1]);
+console.error("al error", { x: 1 });
+console.info("info");
+console.warn("aviso");
+`,
+		stdout: `Map(2) { 'a' => 1, NaN => 'nan' } 3 1 nan true true false 2 [ 'a', NaN ] [ 1, 'nan' ] [ [ 'a', 1 ], [ NaN, 'nan' ] ]
+map a 1
+map NaN nan
+a => 1
+NaN => nan
+Map(0) {} Map(1) { { o: 1 } => [ 1 ] }
+Set(4) { 2, 3, '3', 4 } 5 true true [ 2, 3, '3', 4 ] hola mund
+set tiene 4
+TypeError: Cannot read properties of null (reading 'x')
+TypeError: Cannot read properties of undefined (reading 'y')
+ReferenceError: x is not defined
+TypeError: 1 is not a function
+RangeError: Invalid array length
+Error: propio
+valor: cadena
+SyntaxError: Unexpected end of JSON input
+MiError falló 7 true true MiError: falló
+finally
+limpia
+try
+tipo TypeError TypeError: tipo
+Ana tiene 30.5 años y 2 hijos, 1.5% extra
+{ a: 1 } y [ 1 ]
+info
+`,
+		stderr: `al error { x: 1 }
+aviso
+`,
+	},
+	{
+		name: "indices_y_entrada",
+		code: `const fs = require("fs");
+const entrada = fs.readFileSync("/dev/stdin", "utf8").split("\n");
+console.log(entrada[0], fs.existsSync("datos.txt"));
+try {
+  fs.readFileSync("datos.txt", "utf8");
+} catch (e) {
+  console.log(e.message);
+}
+const a = [1, 2];
+a[2] = 5;
+a[0] += 10;
+a["1"] = "uno";
+console.log(a, a.length, 1 in a, 3 in a, "length" in a);
+a.length = 2;
+console.log(a, a[2]);
+const o = { x: 1, y: 2 };
+delete o.x;
+o["z"] = 3;
+const k = "w";
+o[k] = 4;
+console.log(o, "x" in o, "toString" in o, Object.keys(o).length);
+console.log(["it's", 'di "hola"', ` + "`" + `a'b"c` + "`" + `, "línea\nnueva\ttab", "\\"], ["😀", "z", "é", "a", "Z"].sort());
+for (const v of [() => 5, 7, {}]) {
+  try {
+    for (const x of typeof v === "function" ? v() : v) console.log(x);
+  } catch (e) {
+    console.log(e.name + ": " + e.message);
+  }
+}
+try {
+  const [u] = null;
+} catch (e) {
+  console.log(e.name);
+}
+process.stdout.write("sin salto");
+process.stdout.write(" y con\n");
+console.log(process.argv.length >= 2, typeof process.env);
+`,
+		stdin: `primera
+segunda
+`,
+		stdout: `primera false
+ENOENT: no such file or directory, open 'datos.txt'
+[ 11, 'uno', 5 ] 3 true false true
+[ 11, 'uno' ] undefined
+{ y: 2, z: 3, w: 4 } false true 3
+[ "it's", 'di "hola"', ` + "`" + `a'b"c` + "`" + `, 'línea\nnueva\ttab', '\\' ] [ 'Z', 'a', 'z', 'é', '😀' ]
+TypeError: number 5 is not iterable (cannot read property Symbol(Symbol.iterator))
+TypeError: number 7 is not iterable (cannot read property Symbol(Symbol.iterator))
+TypeError: object is not iterable (cannot read property Symbol(Symbol.iterator))
+TypeError
+sin salto y con
+true object
+`,
+	},
+}
+
+// jsWithoutFrames quita de stderr las líneas de la traza de la pila
+func jsWithoutFrames(stderr string) string {
+	lines := strings.Split(stderr, "\n")
+	kept := lines[:0]
+	for _, l := range lines {
+		if !strings.HasPrefix(l, "    at ") {
+			kept = append(kept, l)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+func TestJavaScriptInterpreter(t *testing.T) {
+	runInterpCases(t, newJSProgram, javascriptInterpCases, jsWithoutFrames)
+}
+
+// TestJavaScriptUnsupported comprueba que lo que node ejecuta y el intérprete
+// no implementa termina con un SyntaxError que sugiere JS_ENGINE=native, en
+// lugar de correr con otro significado
+func TestJavaScriptUnsupported(t *testing.T) {
+	cases := []struct {
+		name string
+		code string
+		line string
+	}{
+		{"generador", "function* g() {\n  yield 1;\n}\n", "main.js:1"},
+		{"etiqueta", "externo: for (;;) {\n  break externo;\n}\n", "main.js:1"},
+		{"miembro_privado", "class Cuenta {\n  #saldo = 0;\n}\n", "main.js:2"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			res := runInterpreted(context.Background(), interpTestLimits, ProgramInput{}, "", newJSProgram(c.code))
+			if res.ExitCode != 1 || !strings.HasPrefix(res.Stderr, c.line+"\n") || !strings.Contains(res.Stderr, "SyntaxError: ") || !strings.HasSuffix(res.Stderr, "use JS_ENGINE=native\n") {
+				t.Errorf("código %d, stderr:\n%s", res.ExitCode, res.Stderr)
+			}
+		})
+	}
+}
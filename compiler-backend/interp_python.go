@@ -0,0 +1,4220 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ───────────────────── Intérprete integrado de Python ─────────────────────
+//
+// Ejecuta programas Python cuando no hay python3 en el servidor o la
+// ejecución real está desactivada, en lugar de mostrar un resultado
+// simulado. Recorre el árbol de parsePythonProgram y cubre lo que usan los
+// ejercicios: enteros de precisión arbitraria, cadenas, listas, tuplas,
+// diccionarios y conjuntos, funciones con valores por defecto, *args y
+// **kwargs, clausuras, generadores, clases con herencia y métodos
+// especiales, excepciones, comprensiones, f-strings y los módulos más
+// comunes (ver interp_python_builtins.go). La salida imita la de CPython
+// 3.11, incluidos los tracebacks, para que el modo juez y el análisis de
+// errores de ejecución funcionen igual que con el intérprete real.
+
+const (
+	pyFileName          = "main.py"
+	pyRecursionLimit    = 1000  // sys.getrecursionlimit() por omisión
+	pyMaxRecursionLimit = 20000 // tope de sys.setrecursionlimit: cada llamada usa la pila de Go
+	pyMaxIntDigits      = 4300  // como sys.get_int_max_str_digits()
+)
+
+// Costos aproximados en bytes de lo que reserva un programa
+const (
+	pyValueCost     = 16
+	pyObjectCost    = 64
+	pyGeneratorCost = 8192 // pila de la gorutina del generador
+)
+
+// ───── Valores ─────
+//
+// None, bool, int64 (o *big.Int cuando el entero no cabe), float64 y
+// string se representan con tipos de Go y son inmutables; listas,
+// diccionarios, instancias y demás son punteros que se comparten como en
+// Python.
+
+type pyValue interface{}
+
+type pyNoneType struct{}
+type pyNotImplementedType struct{}
+type pyEllipsisType struct{}
+
+var (
+	pyNone           pyValue = pyNoneType{}
+	pyNotImplemented pyValue = pyNotImplementedType{}
+	pyEllipsis       pyValue = pyEllipsisType{}
+)
+
+type pyList struct {
+	items  []pyValue
+	deque  bool
+	maxlen int // deque(maxlen=n); -1 sin límite
+}
+
+type pyTuple struct{ items []pyValue }
+
+type pyEntry struct {
+	key, value pyValue
+	hash       interface{}
+	deleted    bool
+}
+
+// pyDict conserva el orden de inserción; con set representa un conjunto
+// (las entradas no tienen valor). Las entradas borradas se marcan y se
+// compactan cuando son muchas
+type pyDict struct {
+	entries []pyEntry
+	index   map[interface{}]int
+	live    int
+	set     bool
+	kind    string  // "defaultdict", "Counter", "OrderedDict", "frozenset" o ""
+	factory pyValue // fábrica de valores de defaultdict
+	// Tamaño y ocupación de la tabla que tendría el conjunto en CPython,
+	// para recorrer los enteros pequeños en el mismo orden
+	table, fill int
+}
+
+// pyView es dict.keys(), dict.values() o dict.items()
+type pyView struct {
+	dict *pyDict
+	kind string // "dict_keys", "dict_values" o "dict_items"
+}
+
+type pyRange struct{ start, stop, step int64 }
+
+type pySlice struct{ start, stop, step pyValue }
+
+// Claves de hash de valores que no son comparables en Go o que deben
+// distinguirse de una cadena con el mismo texto
+type (
+	pyBigKey      string
+	pyTupleKey    string
+	pyInstanceKey int64
+)
+
+type pyKeyword struct {
+	name  string
+	value pyValue
+}
+
+// pyNative implementa una función predefinida
+type pyNative func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue
+
+type pyParam struct {
+	name   string
+	star   int // 1 para *args, 2 para **kwargs
+	kwOnly bool
+	def    pyValue // valor por defecto; nil si es obligatorio
+}
+
+// pyFuncInfo es el análisis estático del cuerpo de una función: qué
+// nombres son locales (se asignan en ella), cuáles se declaran global o
+// nonlocal y si contiene yield
+type pyFuncInfo struct {
+	locals, globals, nonlocals map[string]bool
+	generator                  bool
+}
+
+type pyFunction struct {
+	name, qualname string
+	params         []pyParam
+	body           *ParseNode // Block de la función o expresión de la lambda
+	lambda         bool
+	info           *pyFuncInfo
+	scope          *pyScope // ámbito donde se definió
+	class          *pyClass // clase donde se definió, para super()
+	native         pyNative
+	attrs          map[string]pyValue
+}
+
+type pyBound struct {
+	self pyValue
+	fn   pyValue
+}
+
+type pyClass struct {
+	name      string
+	bases     []*pyClass
+	mro       []*pyClass
+	attrs     map[string]pyValue
+	builtin   bool
+	exception bool
+	// construct crea los valores de un tipo predefinido (int, list...)
+	construct pyNative
+}
+
+type pyInstance struct {
+	class     *pyClass
+	attrs     map[string]pyValue
+	order     []string
+	args      []pyValue      // argumentos de una excepción
+	traceback []pyTraceEntry // dónde se lanzó una excepción
+	// Marco que la atrapó por última vez: cuando es la causa de otra, su
+	// traceback empieza ahí, como en CPython
+	caught int
+	// Excepción que causó esta (raise ... from) o que se estaba manejando
+	// cuando se lanzó
+	cause, context  *pyInstance
+	suppressContext bool
+}
+
+type pyModule struct {
+	name  string
+	attrs map[string]pyValue
+}
+
+type pyProperty struct{ get, set pyValue }
+type pyStaticMethod struct{ fn pyValue }
+type pyClassMethod struct{ fn pyValue }
+
+// pySuper busca atributos en el MRO de self a partir de la clase siguiente
+// a class
+type pySuper struct {
+	class *pyClass
+	self  pyValue
+}
+
+// pyIterator entrega los valores de un iterador (map, zip, generadores...)
+// de a uno con next
+type pyIterator struct {
+	kind string
+	next func() (pyValue, bool)
+	gen  *pyGenerator
+}
+
+// ───── Ámbitos y marcos ─────
+//
+// Cada llamada crea un ámbito cuyo padre es el ámbito donde se definió la
+// función, salvo los cuerpos de clase, que las funciones saltan. Si un
+// nombre es local en una función (según pyFuncInfo) nunca se busca afuera,
+// así se produce UnboundLocalError como en Python. Los marcos forman la
+// pila de llamadas que se muestra en los tracebacks.
+
+type pyScope struct {
+	vars   map[string]pyValue
+	parent *pyScope
+	fn     *pyFunction // nil en el módulo, los cuerpos de clase y las comprensiones
+	class  *pyClass    // clase cuyo cuerpo se está ejecutando
+}
+
+type pyFrame struct {
+	name  string
+	node  *ParseNode // lo que se está ejecutando, para el traceback
+	scope *pyScope
+	gen   *pyGenerator
+}
+
+type pyTraceEntry struct {
+	name string
+	node *ParseNode
+}
+
+type pyCompletion int
+
+const (
+	pyNormal pyCompletion = iota
+	pyReturn
+	pyBreak
+	pyContinue
+)
+
+// pyRaise es la excepción en vuelo; se lanza con panic
+type pyRaise struct{ exc *pyInstance }
+
+type pyInterp struct {
+	b        *interpBudget
+	src      string
+	index    *sourceIndex
+	input    ProgramInput
+	stdin    string
+	stdinPos int
+	globals  *pyScope
+	builtins map[string]pyValue
+	types    map[string]*pyClass
+	modules  map[string]*pyModule
+	frames   []*pyFrame
+	limit    int
+	// Excepción que maneja el except en curso, para un raise sin argumentos
+	handling   *pyInstance
+	generators []*pyGenerator
+	literals   map[*ParseNode]pyValue
+	fstrings   map[*ParseNode][]pyFStringPart
+	funcs      map[*ParseNode]*pyFuncInfo
+	slices     map[*ParseNode][]int
+	ids        map[interface{}]int
+	reprSeen   map[interface{}]bool
+	// Última cadena no ASCII indexada, para no decodificarla en cada s[i]
+	lastStr   string
+	lastRunes []rune
+}
+
+func newPyProgram(code string) embeddedProgram {
+	tree, errs := NewParser(Tokenize(code, "python"), "python", code).Parse()
+	return func(b *interpBudget, input ProgramInput, stdin string) int {
+		it := newPyInterp(b, code, input, stdin)
+		for _, e := range errs {
+			if e.Severity == "error" {
+				it.printSyntaxError(e)
+				return 1
+			}
+		}
+		if len(tree) == 0 {
+			return 0
+		}
+		return it.run(&tree[0])
+	}
+}
+
+func newPyInterp(b *interpBudget, code string, input ProgramInput, stdin string) *pyInterp {
+	it := &pyInterp{
+		b:        b,
+		src:      code,
+		index:    newSourceIndex(code),
+		input:    input,
+		stdin:    stdin,
+		types:    make(map[string]*pyClass),
+		modules:  make(map[string]*pyModule),
+		limit:    pyRecursionLimit,
+		literals: make(map[*ParseNode]pyValue),
+		fstrings: make(map[*ParseNode][]pyFStringPart),
+		funcs:    make(map[*ParseNode]*pyFuncInfo),
+		slices:   make(map[*ParseNode][]int),
+		ids:      make(map[interface{}]int),
+		reprSeen: make(map[interface{}]bool),
+	}
+	it.globals = &pyScope{vars: map[string]pyValue{"__name__": "__main__"}}
+	it.installBuiltins()
+	return it
+}
+
+// run ejecuta el programa y devuelve el código de salida
+func (it *pyInterp) run(root *ParseNode) (code int) {
+	it.frames = []*pyFrame{{name: "<module>", node: root, scope: it.globals}}
+	defer it.closeGenerators()
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		raised, ok := r.(*pyRaise)
+		if !ok {
+			panic(r)
+		}
+		if it.b.trace != nil {
+			it.traceException(raised.exc)
+		}
+		code = it.uncaught(raised.exc)
+	}()
+	it.execBlock(root.Children, it.globals)
+	return 0
+}
+
+// ───── Errores ─────
+
+func (it *pyInterp) frame() *pyFrame { return it.frames[len(it.frames)-1] }
+
+// at registra el nodo que se está ejecutando en el marco actual
+func (it *pyInterp) at(n *ParseNode) { it.frame().node = n }
+
+func (it *pyInterp) newException(cls *pyClass, args ...pyValue) *pyInstance {
+	return &pyInstance{class: cls, attrs: make(map[string]pyValue), args: args}
+}
+
+// raise lanza una excepción predefinida con un mensaje
+func (it *pyInterp) raise(class, format string, args ...interface{}) {
+	msg := format
+	if len(args) > 0 {
+		msg = fmt.Sprintf(format, args...)
+	}
+	it.throw(it.newException(it.types[class], msg))
+}
+
+// raiseValue lanza una excepción predefinida con argumentos arbitrarios
+func (it *pyInterp) raiseValue(class string, args ...pyValue) {
+	it.throw(it.newException(it.types[class], args...))
+}
+
+func (it *pyInterp) throw(exc *pyInstance) {
+	if exc.traceback == nil {
+		if it.handling != nil && it.handling != exc {
+			exc.context = it.handling
+		}
+		exc.traceback = make([]pyTraceEntry, len(it.frames))
+		for i, f := range it.frames {
+			exc.traceback[i] = pyTraceEntry{f.name, f.node}
+		}
+	}
+	panic(&pyRaise{exc})
+}
+
+// catch ejecuta fn y devuelve la excepción de Python que lance; los demás
+// pánicos (límites del intérprete) siguen de largo
+func (it *pyInterp) catch(fn func()) (raised *pyRaise) {
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(*pyRaise)
+			if !ok {
+				panic(r)
+			}
+			raised = e
+		}
+	}()
+	fn()
+	return nil
+}
+
+func (it *pyInterp) isInstance(v pyValue, cls *pyClass) bool {
+	for _, c := range it.typeOf(v).mro {
+		if c == cls {
+			return true
+		}
+	}
+	return false
+}
+
+// uncaught informa una excepción que llegó al nivel superior
+func (it *pyInterp) uncaught(exc *pyInstance) int {
+	if exc.class == it.types["SystemExit"] || it.isInstance(exc, it.types["SystemExit"]) {
+		if len(exc.args) == 0 || exc.args[0] == pyNone {
+			return 0
+		}
+		if n, ok := exc.args[0].(int64); ok {
+			return int(n & 0xff)
+		}
+		it.b.write(true, it.str(exc.args[0])+"\n")
+		return 1
+	}
+	var sb strings.Builder
+	it.writeTraceback(&sb, exc, map[*pyInstance]bool{})
+	it.b.write(true, sb.String())
+	return 1
+}
+
+// writeTraceback escribe el traceback de exc precedido por el de la
+// excepción que la causó o que se estaba manejando
+func (it *pyInterp) writeTraceback(sb *strings.Builder, exc *pyInstance, seen map[*pyInstance]bool) {
+	entries := exc.traceback
+	if len(seen) > 0 && exc.caught < len(entries) {
+		entries = entries[exc.caught:]
+	}
+	seen[exc] = true
+	switch {
+	case exc.cause != nil && !seen[exc.cause]:
+		it.writeTraceback(sb, exc.cause, seen)
+		sb.WriteString("\nThe above exception was the direct cause of the following exception:\n\n")
+	case exc.context != nil && !exc.suppressContext && !seen[exc.context]:
+		it.writeTraceback(sb, exc.context, seen)
+		sb.WriteString("\nDuring handling of the above exception, another exception occurred:\n\n")
+	}
+	sb.WriteString("Traceback (most recent call last):\n")
+	last, repeat := "", 0
+	flush := func() {
+		if repeat > 3 {
+			fmt.Fprintf(sb, "  [Previous line repeated %d more times]\n", repeat-3)
+		}
+	}
+	for _, e := range entries {
+		line, _ := it.index.lineColumn(e.node.Pos)
+		key := fmt.Sprintf("%d %s", line, e.name)
+		if key == last {
+			repeat++
+			if repeat > 3 {
+				continue
+			}
+		} else {
+			flush()
+			last, repeat = key, 1
+		}
+		fmt.Fprintf(sb, "  File \"%s\", line %d, in %s\n", pyFileName, line, e.name)
+		sb.WriteString(it.sourceLine(e.node))
+	}
+	flush()
+	sb.WriteString(it.exceptionLine(exc))
+}
+
+func (it *pyInterp) exceptionLine(exc *pyInstance) string {
+	if msg := it.str(exc); msg != "" {
+		return exc.class.name + ": " + msg + "\n"
+	}
+	return exc.class.name + "\n"
+}
+
+// sourceLine muestra la línea de n y, como CPython 3.11, subraya la parte
+// que falló cuando no es la línea entera: '^' el operador o el índice y
+// '~' los operandos
+func (it *pyInterp) sourceLine(n *ParseNode) string {
+	if n.Kind == "Program" {
+		return ""
+	}
+	line, _ := it.index.lineColumn(n.Pos)
+	start := it.index.lineStarts[line-1]
+	end := len(it.src)
+	if i := strings.IndexByte(it.src[start:], '\n'); i >= 0 {
+		end = start + i
+	}
+	text := it.src[start:end]
+	lead := len(text) - len(strings.TrimLeft(text, " \t\f"))
+	text = strings.TrimRight(text[lead:], " \t\r\f")
+	out := "    " + text + "\n"
+	base := start + lead
+	segStart, segEnd := n.Pos-base, min(n.End, base+len(text))-base
+	if segStart < 0 || segEnd <= segStart || (segStart == 0 && segEnd >= len(text)) {
+		return out
+	}
+	marks := make([]byte, segEnd-segStart)
+	for i := range marks {
+		marks[i] = '^'
+	}
+	if n.End <= base+len(text) {
+		switch n.Kind {
+		case "BinaryExpr":
+			left, right := &n.Children[0], &n.Children[1]
+			opStart := left.End
+			for opStart < right.Pos && strings.ContainsRune(" \t)", rune(it.src[opStart])) {
+				opStart++
+			}
+			opEnd := right.Pos
+			for opEnd > opStart && strings.ContainsRune(" \t(", rune(it.src[opEnd-1])) {
+				opEnd--
+			}
+			for i := range marks {
+				if p := n.Pos + i; p < opStart || p >= opEnd {
+					marks[i] = '~'
+				}
+			}
+		case "Index":
+			for i := 0; i < n.Children[0].End-n.Pos && i < len(marks); i++ {
+				marks[i] = '~'
+			}
+		}
+	}
+	pad := strings.Repeat(" ", utf8.RuneCountInString(text[:segStart]))
+	width := utf8.RuneCountInString(text[segStart:segEnd])
+	if width < len(marks) {
+		marks = marks[:width]
+	}
+	return out + "    " + pad + string(marks) + "\n"
+}
+
+// printSyntaxError informa un error del parser con el formato de CPython
+func (it *pyInterp) printSyntaxError(e CompilerError) {
+	msg := strings.TrimPrefix(e.Message, "Error sintáctico: ")
+	if e.Code == CodeUnclosedDelimiter || e.Code == CodeUnmatchedClosing {
+		e.Pos, msg = it.bracketError(e.Pos, msg)
+	}
+	line, col := it.index.lineColumn(e.Pos)
+	start := it.index.lineStarts[line-1]
+	end := len(it.src)
+	if i := strings.IndexByte(it.src[start:], '\n'); i >= 0 {
+		end = start + i
+	}
+	text := strings.TrimRight(it.src[start:end], " \t\r")
+	lead := len(text) - len(strings.TrimLeft(text, " \t\f"))
+	col = max(col-1-utf8.RuneCountInString(text[:lead]), 0)
+	kind := "SyntaxError"
+	if e.Code == CodeUnexpectedIndent {
+		kind = "IndentationError"
+	}
+	it.b.write(true, fmt.Sprintf("  File \"%s\", line %d\n    %s\n    %s^\n%s: %s\n",
+		pyFileName, line, text[lead:], strings.Repeat(" ", col), kind, msg))
+}
+
+// bracketError da el mensaje de CPython para un paréntesis, corchete o
+// llave sin pareja: el parser informa solo cuántos quedaron abiertos, así
+// que se busca el último sin cerrar o el primer cierre sin apertura
+func (it *pyInterp) bracketError(pos int, msg string) (int, string) {
+	closing := map[string]string{")": "(", "]": "[", "}": "{"}
+	var open []Token
+	for _, tk := range significantTokens(Tokenize(it.src, "python")) {
+		switch tk.Lexeme {
+		case "(", "[", "{":
+			open = append(open, tk)
+		case ")", "]", "}":
+			if len(open) == 0 {
+				return tk.Start, fmt.Sprintf("unmatched '%s'", tk.Lexeme)
+			}
+			if top := open[len(open)-1]; top.Lexeme != closing[tk.Lexeme] {
+				return tk.Start, fmt.Sprintf("closing parenthesis '%s' does not match opening parenthesis '%s'", tk.Lexeme, top.Lexeme)
+			}
+			open = open[:len(open)-1]
+		}
+	}
+	if len(open) > 0 {
+		tk := open[len(open)-1]
+		return tk.Start, fmt.Sprintf("'%s' was never closed", tk.Lexeme)
+	}
+	return pos, msg
+}
+
+// nameSuggestion busca, como CPython, un nombre parecido a name entre las
+// variables locales, después entre las globales y por último entre las
+// predefinidas
+func (it *pyInterp) nameSuggestion(name string, s *pyScope) string {
+	scopes := []map[string]pyValue{s.vars, it.globals.vars, it.builtins}
+	if s == it.globals {
+		scopes = scopes[1:]
+	}
+	for _, vars := range scopes {
+		names := make([]string, 0, len(vars))
+		for candidate := range vars {
+			names = append(names, candidate)
+		}
+		// dir() los devuelve ordenados y ante un empate gana el primero
+		sort.Strings(names)
+		if hint := pySuggestion(name, names); hint != "" {
+			return hint
+		}
+	}
+	return ""
+}
+
+// pySuggestion es _Py_CalculateSuggestions: cambiar una letra cuesta 2 (1
+// si solo cambia de mayúscula a minúscula), agregarla o quitarla 2, y el
+// candidato sirve si cuesta a lo sumo un tercio de la suma de los largos
+func pySuggestion(name string, candidates []string) string {
+	const maxItems, maxSize = 750, 40
+	if len(candidates) > maxItems || len(name) > maxSize {
+		return ""
+	}
+	best, bestDist := "", 0
+	for _, c := range candidates {
+		if c == name || len(c) > maxSize {
+			continue
+		}
+		limit := (len(name) + len(c) + 3) * 2 / 6
+		if best != "" {
+			limit = min(limit, bestDist-1)
+		}
+		if d := pyEditDistance(name, c); d <= limit {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
+
+func pyEditDistance(a, b string) int {
+	row := make([]int, len(b)+1)
+	for j := range row {
+		row[j] = 2 * j
+	}
+	for i := 1; i <= len(a); i++ {
+		prev := row[0]
+		row[0] = 2 * i
+		for j := 1; j <= len(b); j++ {
+			cost := 0
+			switch {
+			case a[i-1] == b[j-1]:
+			case strings.EqualFold(a[i-1:i], b[j-1:j]):
+				cost = 1
+			default:
+				cost = 2
+			}
+			prev, row[j] = row[j], min(prev+cost, row[j]+2, row[j-1]+2)
+		}
+	}
+	return row[len(b)]
+}
+
+// ───── Sentencias ─────
+
+func (it *pyInterp) execBlock(stmts []ParseNode, s *pyScope) (pyCompletion, pyValue) {
+	for i := range stmts {
+		if c, v := it.exec(&stmts[i], s); c != pyNormal {
+			return c, v
+		}
+	}
+	return pyNormal, nil
+}
+
+func (it *pyInterp) exec(n *ParseNode, s *pyScope) (pyCompletion, pyValue) {
+	it.b.step()
+	it.at(n)
+	if it.b.trace != nil && n.Kind != "Block" && n.Kind != "ClassBody" {
+		it.traceLine(n, s)
+	}
+	switch n.Kind {
+	case "ExprStmt":
+		it.eval(&n.Children[0], s)
+	case "Assign":
+		if n.Label == ":=" {
+			it.eval(n, s)
+			break
+		}
+		value := it.eval(&n.Children[1], s)
+		for _, t := range assignTargets(&n.Children[0]) {
+			it.assign(t, value, s)
+		}
+	case "AugAssign":
+		it.augAssign(n, s)
+	case "AnnAssign":
+		if len(n.Children) > 2 {
+			it.assign(&n.Children[0], it.eval(&n.Children[2], s), s)
+		}
+	case "If":
+		if it.truthy(it.eval(&n.Children[0].Children[0], s)) {
+			return it.exec(&n.Children[1], s)
+		}
+		if len(n.Children) > 2 {
+			return it.exec(&n.Children[2].Children[0], s)
+		}
+	case "While":
+		for {
+			it.at(n)
+			if !it.truthy(it.eval(&n.Children[0].Children[0], s)) {
+				if len(n.Children) > 2 {
+					return it.exec(&n.Children[2].Children[0], s)
+				}
+				break
+			}
+			c, v := it.exec(&n.Children[1], s)
+			if c == pyBreak {
+				break
+			}
+			if c == pyReturn {
+				return c, v
+			}
+		}
+	case "ForEach":
+		return it.execFor(n, s)
+	case "Block", "ClassBody":
+		return it.execBlock(n.Children, s)
+	case "Pass", "Global", "Nonlocal":
+	case "Break":
+		return pyBreak, nil
+	case "Continue":
+		return pyContinue, nil
+	case "Return":
+		if len(n.Children) == 0 {
+			return pyReturn, pyNone
+		}
+		return pyReturn, it.eval(&n.Children[0], s)
+	case "FunctionDecl":
+		it.assignName(n.Label, it.decorate(n, it.makeFunction(n, s), s), s)
+	case "ClassDecl":
+		it.assignName(n.Label, it.decorate(n, it.makeClass(n, s), s), s)
+	case "Try":
+		return it.execTry(n, s)
+	case "Raise":
+		it.execRaise(n, s)
+	case "Del":
+		for i := range n.Children {
+			it.delete(&n.Children[i], s)
+		}
+	case "Assert":
+		if !it.truthy(it.eval(&n.Children[0], s)) {
+			exc := it.newException(it.types["AssertionError"])
+			if len(n.Children) > 1 {
+				exc.args = []pyValue{it.eval(&n.Children[1], s)}
+			}
+			it.at(n)
+			it.throw(exc)
+		}
+	case "Import":
+		it.execImport(n, s)
+	case "With":
+		return it.execWith(n, s, 0)
+	default:
+		it.raise("SyntaxError", "el intérprete integrado no admite '%s'", it.src[n.Pos:min(n.End, n.Pos+40)])
+	}
+	return pyNormal, nil
+}
+
+// assignTargets devuelve los destinos de a = b = valor en orden
+func assignTargets(n *ParseNode) []*ParseNode {
+	if n.Kind == "Assign" && n.Label == "=" {
+		return append(assignTargets(&n.Children[0]), &n.Children[1])
+	}
+	return []*ParseNode{n}
+}
+
+func (it *pyInterp) execFor(n *ParseNode, s *pyScope) (pyCompletion, pyValue) {
+	iterable := it.eval(&n.Children[1], s)
+	it.at(n)
+	next := it.iter(iterable)
+	for {
+		v, ok := next()
+		if !ok {
+			break
+		}
+		it.b.step()
+		it.assign(&n.Children[0], v, s)
+		c, res := it.exec(&n.Children[2], s)
+		if c == pyBreak {
+			return pyNormal, nil
+		}
+		if c == pyReturn {
+			return c, res
+		}
+	}
+	if len(n.Children) > 3 {
+		return it.exec(&n.Children[3].Children[0], s)
+	}
+	return pyNormal, nil
+}
+
+func (it *pyInterp) execTry(n *ParseNode, s *pyScope) (c pyCompletion, v pyValue) {
+	var handlers []*ParseNode
+	var elseBlock, finally *ParseNode
+	for i := 1; i < len(n.Children); i++ {
+		switch child := &n.Children[i]; child.Kind {
+		case "Catch":
+			handlers = append(handlers, child)
+		case "Else":
+			elseBlock = &child.Children[0]
+		case "Finally":
+			finally = &child.Children[0]
+		}
+	}
+	raised := it.catch(func() { c, v = it.exec(&n.Children[0], s) })
+	if raised != nil {
+		for _, h := range handlers {
+			if !it.handles(h, raised.exc, s) {
+				continue
+			}
+			raised.exc.caught = len(it.frames) - 1
+			name := strings.TrimPrefix(h.Label, "except as ")
+			if name != h.Label {
+				it.assignName(name, raised.exc, s)
+			}
+			prev := it.handling
+			it.handling = raised.exc
+			raised = it.catch(func() { c, v = it.exec(&h.Children[len(h.Children)-1], s) })
+			it.handling = prev
+			if name != h.Label {
+				delete(s.vars, name)
+			}
+			break
+		}
+	} else if elseBlock != nil {
+		raised = it.catch(func() { c, v = it.exec(elseBlock, s) })
+	}
+	if finally != nil {
+		if fc, fv := it.exec(finally, s); fc != pyNormal {
+			return fc, fv
+		}
+	}
+	if raised != nil {
+		panic(raised)
+	}
+	return c, v
+}
+
+// handles dice si el except h atrapa exc
+func (it *pyInterp) handles(h *ParseNode, exc *pyInstance, s *pyScope) bool {
+	if len(h.Children) == 1 {
+		return true
+	}
+	it.at(h)
+	var match func(pyValue) bool
+	match = func(t pyValue) bool {
+		switch t := t.(type) {
+		case *pyClass:
+			return it.isInstance(exc, t)
+		case *pyTuple:
+			for _, c := range t.items {
+				if match(c) {
+					return true
+				}
+			}
+			return false
+		}
+		it.raise("TypeError", "catching classes that do not inherit from BaseException is not allowed")
+		return false
+	}
+	return match(it.eval(&h.Children[0], s))
+}
+
+func (it *pyInterp) execRaise(n *ParseNode, s *pyScope) {
+	if len(n.Children) == 0 {
+		if it.handling == nil {
+			it.raise("RuntimeError", "No active exception to reraise")
+		}
+		panic(&pyRaise{it.handling})
+	}
+	exc := it.toException(it.eval(&n.Children[0], s))
+	if len(n.Children) > 1 {
+		if cause := it.eval(&n.Children[1], s); cause != pyNone {
+			exc.cause = it.toException(cause)
+		}
+		exc.suppressContext = true
+	}
+	it.at(n)
+	it.throw(exc)
+}
+
+// toException convierte lo que recibe raise en una instancia de excepción
+func (it *pyInterp) toException(v pyValue) *pyInstance {
+	if cls, ok := v.(*pyClass); ok && cls.exception {
+		v = it.call(cls, nil, nil)
+	}
+	if exc, ok := v.(*pyInstance); ok && exc.class.exception {
+		return exc
+	}
+	it.raise("TypeError", "exceptions must derive from BaseException")
+	return nil
+}
+
+func (it *pyInterp) execWith(n *ParseNode, s *pyScope, i int) (c pyCompletion, v pyValue) {
+	if i == len(n.Children)-1 {
+		return it.exec(&n.Children[i], s)
+	}
+	item := &n.Children[i]
+	expr, target := item, (*ParseNode)(nil)
+	if item.Kind == "WithItem" {
+		expr, target = &item.Children[0], &item.Children[1]
+	}
+	manager := it.eval(expr, s)
+	it.at(expr)
+	enter, ok := it.lookupAttr(manager, "__enter__")
+	exit, ok2 := it.lookupAttr(manager, "__exit__")
+	if !ok || !ok2 {
+		it.raise("TypeError", "'%s' object does not support the context manager protocol", it.typeName(manager))
+	}
+	value := it.call(enter, nil, nil)
+	if target != nil {
+		it.assign(target, value, s)
+	}
+	raised := it.catch(func() { c, v = it.execWith(n, s, i+1) })
+	if raised != nil {
+		exc := raised.exc
+		if it.truthy(it.call(exit, []pyValue{exc.class, exc, pyNone}, nil)) {
+			return pyNormal, nil
+		}
+		panic(raised)
+	}
+	it.call(exit, []pyValue{pyNone, pyNone, pyNone}, nil)
+	return c, v
+}
+
+func (it *pyInterp) execImport(n *ParseNode, s *pyScope) {
+	if strings.HasPrefix(n.Label, "from") {
+		fields := strings.Fields(n.Label)
+		mod := it.importModule(fields[1])
+		for _, c := range n.Children {
+			name, alias, _ := strings.Cut(c.Label, " as ")
+			if name == "*" {
+				for k, v := range mod.attrs {
+					if !strings.HasPrefix(k, "_") {
+						it.assignName(k, v, s)
+					}
+				}
+				continue
+			}
+			v, ok := mod.attrs[name]
+			if !ok {
+				it.raise("ImportError", "cannot import name '%s' from '%s' (unknown location)", name, mod.name)
+			}
+			if alias == "" {
+				alias = name
+			}
+			it.assignName(alias, v, s)
+		}
+		return
+	}
+	for _, c := range n.Children {
+		name, alias, _ := strings.Cut(c.Label, " as ")
+		mod := it.importModule(name)
+		if alias == "" {
+			alias, _, _ = strings.Cut(name, ".")
+			mod = it.importModule(alias)
+		}
+		it.assignName(alias, mod, s)
+	}
+}
+
+func (it *pyInterp) importModule(name string) *pyModule {
+	if mod, ok := it.modules[name]; ok {
+		return mod
+	}
+	build, ok := pyModules[name]
+	if !ok {
+		it.raise("ModuleNotFoundError", "No module named '%s'", name)
+	}
+	mod := &pyModule{name: name, attrs: map[string]pyValue{"__name__": name}}
+	it.modules[name] = mod
+	build(it, mod.attrs)
+	return mod
+}
+
+// decorate aplica los decoradores de n de abajo hacia arriba
+func (it *pyInterp) decorate(n *ParseNode, v pyValue, s *pyScope) pyValue {
+	var decorators []pyValue
+	for i := range n.Children {
+		if c := &n.Children[i]; c.Kind == "Decorator" {
+			decorators = append(decorators, it.eval(&c.Children[0], s))
+		}
+	}
+	for i := len(decorators) - 1; i >= 0; i-- {
+		it.at(n)
+		v = it.call(decorators[i], []pyValue{v}, nil)
+	}
+	return v
+}
+
+// ───── Asignación ─────
+
+func (it *pyInterp) assignName(name string, v pyValue, s *pyScope) {
+	if f := s.fn; f != nil {
+		if f.info.globals[name] {
+			it.globals.vars[name] = v
+			return
+		}
+		if f.info.nonlocals[name] {
+			for sc := s.parent; sc != nil; sc = sc.parent {
+				if sc.fn != nil && sc.fn.info.locals[name] && !sc.fn.info.nonlocals[name] {
+					sc.vars[name] = v
+					return
+				}
+			}
+		}
+	}
+	s.vars[name] = v
+}
+
+func (it *pyInterp) assign(t *ParseNode, v pyValue, s *pyScope) {
+	switch t.Kind {
+	case "Identifier":
+		it.assignName(t.Label, v, s)
+	case "Tuple", "ListLiteral":
+		it.unpack(t, v, s)
+	case "Index":
+		obj := it.eval(&t.Children[0], s)
+		key := it.subscript(t, s)
+		it.at(t)
+		it.setItem(obj, key, v)
+	case "Member":
+		obj := it.eval(&t.Children[0], s)
+		it.at(t)
+		it.setAttr(obj, t.Label, v)
+	default:
+		it.at(t)
+		it.raise("SyntaxError", "cannot assign to expression")
+	}
+}
+
+func (it *pyInterp) unpack(t *ParseNode, v pyValue, s *pyScope) {
+	items := it.toList(v)
+	star := -1
+	for i := range t.Children {
+		if t.Children[i].Kind == "Spread" {
+			star = i
+		}
+	}
+	want := len(t.Children)
+	it.at(t)
+	if star < 0 {
+		if len(items) > want {
+			it.raise("ValueError", "too many values to unpack (expected %d)", want)
+		}
+		if len(items) < want {
+			it.raise("ValueError", "not enough values to unpack (expected %d, got %d)", want, len(items))
+		}
+		for i := range t.Children {
+			it.assign(&t.Children[i], items[i], s)
+		}
+		return
+	}
+	if len(items) < want-1 {
+		it.raise("ValueError", "not enough values to unpack (expected at least %d, got %d)", want-1, len(items))
+	}
+	after := want - star - 1
+	for i := 0; i < star; i++ {
+		it.assign(&t.Children[i], items[i], s)
+	}
+	rest := append([]pyValue(nil), items[star:len(items)-after]...)
+	it.assign(&t.Children[star].Children[0], &pyList{items: rest, maxlen: -1}, s)
+	for i := 0; i < after; i++ {
+		it.assign(&t.Children[star+1+i], items[len(items)-after+i], s)
+	}
+}
+
+func (it *pyInterp) augAssign(n *ParseNode, s *pyScope) {
+	op := strings.TrimSuffix(n.Label, "=")
+	t := &n.Children[0]
+	switch t.Kind {
+	case "Identifier":
+		cur := it.lookup(t, s)
+		rhs := it.eval(&n.Children[1], s)
+		it.at(n)
+		it.assignName(t.Label, it.inplace(op, cur, rhs), s)
+	case "Index":
+		obj := it.eval(&t.Children[0], s)
+		key := it.subscript(t, s)
+		it.at(t)
+		cur := it.getItem(obj, key)
+		rhs := it.eval(&n.Children[1], s)
+		it.at(n)
+		it.setItem(obj, key, it.inplace(op, cur, rhs))
+	case "Member":
+		obj := it.eval(&t.Children[0], s)
+		it.at(t)
+		cur := it.getAttr(obj, t.Label)
+		rhs := it.eval(&n.Children[1], s)
+		it.at(n)
+		it.setAttr(obj, t.Label, it.inplace(op, cur, rhs))
+	default:
+		it.raise("SyntaxError", "'%s' is an illegal expression for augmented assignment", t.Kind)
+	}
+}
+
+// inplace aplica un operador compuesto: las listas, conjuntos y
+// diccionarios se modifican en el lugar
+func (it *pyInterp) inplace(op string, a, b pyValue) pyValue {
+	switch x := a.(type) {
+	case *pyList:
+		switch op {
+		case "+":
+			items := it.toList(b)
+			it.b.alloc(len(items) * pyValueCost)
+			x.items = append(x.items, items...)
+			return x
+		case "*":
+			if n, ok := b.(int64); ok {
+				it.b.alloc(len(x.items) * int(max(n-1, 0)) * pyValueCost)
+				x.items = it.repeat(x.items, n)
+				return x
+			}
+		}
+	case *pyDict:
+		if r, ok := b.(*pyDict); ok && (op == "|" || x.set && (op == "&" || op == "-" || op == "^")) {
+			res := it.binary(op, x, r).(*pyDict)
+			x.entries, x.index, x.live = res.entries, res.index, res.live
+			return x
+		}
+	case *pyInstance:
+		if m, ok := it.lookupAttr(x, pyDunders[op].inplace); ok {
+			if r := it.call(m, []pyValue{b}, nil); r != pyNotImplemented {
+				return r
+			}
+		}
+	}
+	return it.binary(op, a, b)
+}
+
+func (it *pyInterp) delete(t *ParseNode, s *pyScope) {
+	it.at(t)
+	switch t.Kind {
+	case "Identifier":
+		sc := s
+		if s.fn != nil && s.fn.info.globals[t.Label] {
+			sc = it.globals
+		}
+		if _, ok := sc.vars[t.Label]; !ok {
+			it.raise("NameError", "name '%s' is not defined", t.Label)
+		}
+		delete(sc.vars, t.Label)
+	case "Tuple", "ListLiteral":
+		for i := range t.Children {
+			it.delete(&t.Children[i], s)
+		}
+	case "Index":
+		obj := it.eval(&t.Children[0], s)
+		it.delItem(obj, it.subscript(t, s))
+	case "Member":
+		obj := it.eval(&t.Children[0], s)
+		it.delAttr(obj, t.Label)
+	default:
+		it.raise("SyntaxError", "cannot delete expression")
+	}
+}
+
+// ───── Expresiones ─────
+
+func (it *pyInterp) eval(n *ParseNode, s *pyScope) pyValue {
+	switch n.Kind {
+	case "Literal":
+		return it.literal(n, s)
+	case "Identifier":
+		return it.lookup(n, s)
+	case "BinaryExpr":
+		return it.evalBinary(n, s)
+	case "LogicalExpr":
+		l := it.eval(&n.Children[0], s)
+		if it.truthy(l) == (n.Label == "or") {
+			return l
+		}
+		return it.eval(&n.Children[1], s)
+	case "UnaryExpr":
+		v := it.eval(&n.Children[0], s)
+		if n.Label == "not" {
+			return !it.truthy(v)
+		}
+		it.at(n)
+		return it.unary(n.Label, v)
+	case "Conditional":
+		if it.truthy(it.eval(&n.Children[0], s)) {
+			return it.eval(&n.Children[1], s)
+		}
+		return it.eval(&n.Children[2], s)
+	case "Call":
+		return it.evalCall(n, s)
+	case "Member":
+		obj := it.eval(&n.Children[0], s)
+		it.at(n)
+		return it.getAttr(obj, n.Label)
+	case "Index":
+		obj := it.eval(&n.Children[0], s)
+		key := it.subscript(n, s)
+		it.at(n)
+		return it.getItem(obj, key)
+	case "Tuple":
+		return &pyTuple{it.evalItems(n.Children, s)}
+	case "ListLiteral":
+		return &pyList{items: it.evalItems(n.Children, s), maxlen: -1}
+	case "SetLiteral":
+		set := &pyDict{index: make(map[interface{}]int), set: true}
+		for _, v := range it.evalItems(n.Children, s) {
+			it.dictSet(set, v, nil)
+		}
+		return set
+	case "DictLiteral":
+		d := newPyDict()
+		for i := range n.Children {
+			c := &n.Children[i]
+			if c.Kind == "Spread" {
+				src := it.eval(&c.Children[0], s)
+				it.at(c)
+				it.dictUpdate(d, src)
+				continue
+			}
+			k := it.eval(&c.Children[0], s)
+			it.dictSet(d, k, it.eval(&c.Children[1], s))
+		}
+		return d
+	case "ListComp":
+		var items []pyValue
+		it.comprehension(n, s, func(cs *pyScope) {
+			it.b.alloc(pyValueCost)
+			items = append(items, it.eval(&n.Children[0], cs))
+		})
+		return &pyList{items: items, maxlen: -1}
+	case "SetComp":
+		set := &pyDict{index: make(map[interface{}]int), set: true}
+		it.comprehension(n, s, func(cs *pyScope) { it.dictSet(set, it.eval(&n.Children[0], cs), nil) })
+		return set
+	case "DictComp":
+		d := newPyDict()
+		pair := &n.Children[0]
+		it.comprehension(n, s, func(cs *pyScope) {
+			k := it.eval(&pair.Children[0], cs)
+			it.dictSet(d, k, it.eval(&pair.Children[1], cs))
+		})
+		return d
+	case "Generator":
+		// Las expresiones generadoras se calculan completas
+		var items []pyValue
+		it.comprehension(n, s, func(cs *pyScope) {
+			it.b.alloc(pyValueCost)
+			items = append(items, it.eval(&n.Children[0], cs))
+		})
+		return it.newIterator("generator", items)
+	case "Lambda":
+		return it.makeFunction(n, s)
+	case "Assign":
+		if n.Label == ":=" {
+			v := it.eval(&n.Children[1], s)
+			it.assign(&n.Children[0], v, s)
+			return v
+		}
+	case "Yield":
+		return it.evalYield(n, s)
+	case "Spread":
+		it.at(n)
+		it.raise("SyntaxError", "can't use starred expression here")
+	}
+	it.at(n)
+	it.raise("SyntaxError", "el intérprete integrado no admite '%s'", it.src[n.Pos:min(n.End, n.Pos+40)])
+	return nil
+}
+
+// evalItems evalúa los elementos de una lista, tupla o conjunto
+// expandiendo los *iterables
+func (it *pyInterp) evalItems(nodes []ParseNode, s *pyScope) []pyValue {
+	items := make([]pyValue, 0, len(nodes))
+	for i := range nodes {
+		if c := &nodes[i]; c.Kind == "Spread" {
+			items = append(items, it.toList(it.eval(&c.Children[0], s))...)
+		} else {
+			items = append(items, it.eval(c, s))
+		}
+	}
+	it.b.alloc(len(items) * pyValueCost)
+	return items
+}
+
+func (it *pyInterp) lookup(n *ParseNode, s *pyScope) pyValue {
+	name := n.Label
+	for sc := s; sc != nil; sc = sc.parent {
+		if sc.fn != nil && sc.fn.info.globals[name] {
+			break
+		}
+		if v, ok := sc.vars[name]; ok {
+			return v
+		}
+		if sc.fn != nil && sc.fn.info.locals[name] && !sc.fn.info.nonlocals[name] {
+			it.at(n)
+			it.raise("UnboundLocalError", "cannot access local variable '%s' where it is not associated with a value", name)
+		}
+	}
+	if v, ok := it.globals.vars[name]; ok {
+		return v
+	}
+	if v, ok := it.builtins[name]; ok {
+		return v
+	}
+	it.at(n)
+	if hint := it.nameSuggestion(name, s); hint != "" {
+		it.raise("NameError", "name '%s' is not defined. Did you mean: '%s'?", name, hint)
+	}
+	it.raise("NameError", "name '%s' is not defined", name)
+	return nil
+}
+
+func (it *pyInterp) evalBinary(n *ParseNode, s *pyScope) pyValue {
+	switch n.Label {
+	case "<", "<=", ">", ">=", "==", "!=", "in", "not in", "is", "is not":
+		return it.evalComparison(n, s)
+	}
+	l := it.eval(&n.Children[0], s)
+	r := it.eval(&n.Children[1], s)
+	it.at(n)
+	return it.binary(n.Label, l, r)
+}
+
+var pyComparisonOps = map[string]bool{
+	"<": true, "<=": true, ">": true, ">=": true, "==": true, "!=": true,
+	"in": true, "not in": true, "is": true, "is not": true,
+}
+
+// evalComparison evalúa comparaciones encadenadas (a < b <= c): el parser
+// las anida por la izquierda, salvo que el operando izquierdo tenga
+// paréntesis
+func (it *pyInterp) evalComparison(n *ParseNode, s *pyScope) pyValue {
+	chain := []*ParseNode{n}
+	for left := &chain[0].Children[0]; left.Kind == "BinaryExpr" && pyComparisonOps[left.Label] && !it.parenthesized(left); left = &left.Children[0] {
+		chain = append([]*ParseNode{left}, chain...)
+	}
+	l := it.eval(&chain[0].Children[0], s)
+	for _, c := range chain {
+		r := it.eval(&c.Children[1], s)
+		it.at(c)
+		if !it.compare(c.Label, l, r) {
+			return false
+		}
+		l = r
+	}
+	return true
+}
+
+// parenthesized dice si la expresión n está entre paréntesis en el código
+func (it *pyInterp) parenthesized(n *ParseNode) bool {
+	for i := n.End; i < len(it.src); i++ {
+		switch it.src[i] {
+		case ' ', '\t', '\n', '\r', '\\':
+			continue
+		case ')':
+			return true
+		}
+		return false
+	}
+	return false
+}
+
+func (it *pyInterp) evalCall(n *ParseNode, s *pyScope) pyValue {
+	callee := it.eval(&n.Children[0], s)
+	args, kw := it.evalArgs(&n.Children[1], s)
+	it.at(n)
+	if callee == it.builtins["super"] && len(args) == 0 {
+		return it.implicitSuper(s)
+	}
+	return it.call(callee, args, kw)
+}
+
+func (it *pyInterp) evalArgs(n *ParseNode, s *pyScope) ([]pyValue, []pyKeyword) {
+	args := make([]pyValue, 0, len(n.Children))
+	var kw []pyKeyword
+	for i := range n.Children {
+		c := &n.Children[i]
+		switch c.Kind {
+		case "Spread":
+			v := it.eval(&c.Children[0], s)
+			if c.Label == "*" {
+				args = append(args, it.toList(v)...)
+				continue
+			}
+			d, ok := v.(*pyDict)
+			if !ok || d.set {
+				it.at(c)
+				it.raise("TypeError", "argument after ** must be a mapping, not %s", it.typeName(v))
+			}
+			for _, e := range d.items() {
+				name, ok := e.key.(string)
+				if !ok {
+					it.at(c)
+					it.raise("TypeError", "keywords must be strings")
+				}
+				kw = append(kw, pyKeyword{name, e.value})
+			}
+		case "KeywordArg":
+			kw = append(kw, pyKeyword{c.Label, it.eval(&c.Children[0], s)})
+		default:
+			args = append(args, it.eval(c, s))
+		}
+	}
+	return args, kw
+}
+
+// implicitSuper resuelve super() sin argumentos con la clase y el primer
+// parámetro del método que lo llama
+func (it *pyInterp) implicitSuper(s *pyScope) pyValue {
+	sc := s
+	for sc != nil && (sc.fn == nil || sc.fn.class == nil) {
+		sc = sc.parent
+	}
+	if sc == nil || len(sc.fn.params) == 0 {
+		it.raise("RuntimeError", "super(): no arguments")
+	}
+	return &pySuper{class: sc.fn.class, self: sc.vars[sc.fn.params[0].name]}
+}
+
+// subscript evalúa lo que va entre corchetes en n (un Index)
+func (it *pyInterp) subscript(n *ParseNode, s *pyScope) pyValue {
+	idx := &n.Children[1]
+	if idx.Kind != "Slice" {
+		return it.eval(idx, s)
+	}
+	colons, ok := it.slices[n]
+	if !ok {
+		colons = it.sliceColons(n)
+		it.slices[n] = colons
+	}
+	parts := [3]pyValue{pyNone, pyNone, pyNone}
+	for i := range idx.Children {
+		c := &idx.Children[i]
+		slot := 0
+		for _, p := range colons {
+			if c.Pos > p {
+				slot++
+			}
+		}
+		parts[min(slot, 2)] = it.eval(c, s)
+	}
+	return &pySlice{parts[0], parts[1], parts[2]}
+}
+
+// sliceColons busca los ':' de primer nivel entre los corchetes de n
+func (it *pyInterp) sliceColons(n *ParseNode) []int {
+	var colons []int
+	depth := 0
+	var quote byte
+	for i := n.Children[0].End; i < n.End; i++ {
+		c := it.src[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case c == ':' && depth == 1:
+			colons = append(colons, i)
+		}
+	}
+	return colons
+}
+
+// comprehension recorre las cláusulas for/if de una comprensión en un
+// ámbito propio y llama a emit por cada combinación
+func (it *pyInterp) comprehension(n *ParseNode, s *pyScope, emit func(*pyScope)) {
+	cs := &pyScope{vars: make(map[string]pyValue), parent: s}
+	clauses := n.Children[1:]
+	var loop func(i int)
+	loop = func(i int) {
+		if i == len(clauses) {
+			emit(cs)
+			return
+		}
+		cf := &clauses[i]
+		scope := cs
+		if i == 0 {
+			scope = s
+		}
+		next := it.iter(it.eval(&cf.Children[1], scope))
+	outer:
+		for {
+			v, ok := next()
+			if !ok {
+				return
+			}
+			it.b.step()
+			it.assign(&cf.Children[0], v, cs)
+			for j := 2; j < len(cf.Children); j++ {
+				if !it.truthy(it.eval(&cf.Children[j].Children[0], cs)) {
+					continue outer
+				}
+			}
+			loop(i + 1)
+		}
+	}
+	loop(0)
+}
+
+// ───── Funciones y clases ─────
+
+func (it *pyInterp) makeFunction(n *ParseNode, s *pyScope) *pyFunction {
+	f := &pyFunction{name: n.Label}
+	var params *ParseNode
+	if n.Kind == "Lambda" {
+		f.name, f.lambda = "<lambda>", true
+		params, f.body = &n.Children[0], &n.Children[1]
+	} else {
+		for i := range n.Children {
+			switch c := &n.Children[i]; c.Kind {
+			case "Params":
+				params = c
+			case "Block":
+				f.body = c
+			}
+		}
+	}
+	f.info = it.funcInfo(n, params, f.body)
+	closure := s
+	for closure.class != nil {
+		closure = closure.parent
+	}
+	f.scope = closure
+	switch {
+	case s.class != nil:
+		f.class = s.class
+		f.qualname = s.class.name + "." + f.name
+	case s.fn != nil:
+		f.qualname = s.fn.qualname + ".<locals>." + f.name
+	default:
+		f.qualname = f.name
+	}
+	kwOnly := false
+	for i := range params.Children {
+		p := &params.Children[i]
+		switch {
+		case p.Label == "*":
+			kwOnly = true
+		case strings.HasPrefix(p.Label, "**"):
+			f.params = append(f.params, pyParam{name: p.Label[2:], star: 2})
+		case strings.HasPrefix(p.Label, "*"):
+			f.params = append(f.params, pyParam{name: p.Label[1:], star: 1})
+			kwOnly = true
+		default:
+			param := pyParam{name: p.Label, kwOnly: kwOnly}
+			if k := len(p.Children); k > 0 && p.Children[k-1].Kind != "Type" {
+				param.def = it.eval(&p.Children[k-1], s)
+			}
+			f.params = append(f.params, param)
+		}
+	}
+	return f
+}
+
+// funcInfo analiza una sola vez el cuerpo de cada función
+func (it *pyInterp) funcInfo(n, params, body *ParseNode) *pyFuncInfo {
+	if info, ok := it.funcs[n]; ok {
+		return info
+	}
+	info := &pyFuncInfo{locals: map[string]bool{}, globals: map[string]bool{}, nonlocals: map[string]bool{}}
+	for _, p := range params.Children {
+		if name := strings.TrimLeft(p.Label, "*"); name != "" {
+			info.locals[name] = true
+		}
+	}
+	if n.Kind == "Lambda" {
+		info.scan(body)
+	} else {
+		for i := range body.Children {
+			info.scan(&body.Children[i])
+		}
+	}
+	it.funcs[n] = info
+	return info
+}
+
+func (info *pyFuncInfo) scan(n *ParseNode) {
+	switch n.Kind {
+	case "FunctionDecl", "ClassDecl":
+		info.locals[n.Label] = true
+		return
+	case "Lambda":
+		return
+	case "Global":
+		for _, c := range n.Children {
+			info.globals[c.Label] = true
+		}
+		return
+	case "Nonlocal":
+		for _, c := range n.Children {
+			info.nonlocals[c.Label] = true
+		}
+		return
+	case "Assign", "AugAssign", "AnnAssign", "ForEach", "Del":
+		info.target(&n.Children[0])
+	case "WithItem":
+		info.target(&n.Children[1])
+	case "Catch":
+		if name := strings.TrimPrefix(n.Label, "except as "); name != n.Label {
+			info.locals[name] = true
+		}
+	case "Import":
+		for _, c := range n.Children {
+			name, alias, _ := strings.Cut(c.Label, " as ")
+			if alias == "" {
+				alias, _, _ = strings.Cut(name, ".")
+			}
+			if alias != "*" {
+				info.locals[alias] = true
+			}
+		}
+	case "Yield":
+		info.generator = true
+	case "ListComp", "SetComp", "DictComp", "Generator":
+		// Las variables de la comprensión son de su propio ámbito
+		for i := 1; i < len(n.Children); i++ {
+			info.scan(&n.Children[i].Children[1])
+		}
+		return
+	}
+	for i := range n.Children {
+		info.scan(&n.Children[i])
+	}
+}
+
+func (info *pyFuncInfo) target(n *ParseNode) {
+	switch n.Kind {
+	case "Identifier":
+		info.locals[n.Label] = true
+	case "Tuple", "ListLiteral", "Spread", "Assign":
+		for i := range n.Children {
+			info.target(&n.Children[i])
+		}
+	}
+}
+
+// call llama a cualquier valor invocable
+func (it *pyInterp) call(f pyValue, args []pyValue, kw []pyKeyword) pyValue {
+	switch fn := f.(type) {
+	case *pyFunction:
+		return it.callFunction(fn, args, kw)
+	case *pyBound:
+		return it.call(fn.fn, append([]pyValue{fn.self}, args...), kw)
+	case *pyClass:
+		return it.instantiate(fn, args, kw)
+	case *pyInstance:
+		if m, ok := it.lookupAttr(fn, "__call__"); ok {
+			return it.call(m, args, kw)
+		}
+	}
+	it.raise("TypeError", "'%s' object is not callable", it.typeName(f))
+	return nil
+}
+
+func (it *pyInterp) callFunction(f *pyFunction, args []pyValue, kw []pyKeyword) pyValue {
+	if f.native != nil {
+		return f.native(it, args, kw)
+	}
+	it.b.step()
+	if len(it.frames) > it.limit {
+		it.raise("RecursionError", "maximum recursion depth exceeded")
+	}
+	scope := &pyScope{vars: make(map[string]pyValue, len(f.params)+4), parent: f.scope, fn: f}
+	it.bindArgs(f, scope.vars, args, kw)
+	if f.info.generator {
+		g := &pyGenerator{fn: f, scope: scope}
+		return &pyIterator{kind: "generator", gen: g, next: func() (pyValue, bool) { return g.resume(it, pyNone) }}
+	}
+	it.frames = append(it.frames, &pyFrame{name: f.name, node: f.body, scope: scope})
+	defer func() { it.frames = it.frames[:len(it.frames)-1] }()
+	if f.lambda {
+		return it.eval(f.body, scope)
+	}
+	if it.b.trace != nil {
+		it.traceEvent(traceCall, it.b.trace.line, scope, nil)
+	}
+	var result pyValue = pyNone
+	if c, v := it.execBlock(f.body.Children, scope); c == pyReturn {
+		result = v
+	}
+	if it.b.trace != nil {
+		it.traceEvent(traceReturn, it.b.trace.line, scope, result)
+	}
+	return result
+}
+
+// bindArgs asigna los argumentos a los parámetros de f con los mismos
+// errores que CPython
+func (it *pyInterp) bindArgs(f *pyFunction, vars map[string]pyValue, args []pyValue, kw []pyKeyword) {
+	var varargs, varkw *pyParam
+	positional, required := 0, 0
+	next := 0
+	for i := range f.params {
+		p := &f.params[i]
+		switch {
+		case p.star == 1:
+			varargs = p
+		case p.star == 2:
+			varkw = p
+		case !p.kwOnly:
+			positional++
+			if p.def == nil {
+				required++
+			}
+			if next < len(args) {
+				vars[p.name] = args[next]
+				next++
+			}
+		}
+	}
+	if next < len(args) && varargs == nil {
+		takes := fmt.Sprintf("%d positional argument", positional)
+		if required != positional {
+			takes = fmt.Sprintf("from %d to %d positional argument", required, positional)
+		}
+		if positional != 1 {
+			takes += "s"
+		}
+		// Como CPython, cuenta también los keyword-only que sí se pasaron
+		given, kwOnly := fmt.Sprint(len(args)), 0
+		for _, k := range kw {
+			for _, p := range f.params {
+				if p.kwOnly && p.name == k.name {
+					kwOnly++
+				}
+			}
+		}
+		if kwOnly > 0 {
+			given += fmt.Sprintf(" positional argument%s (and %d keyword-only argument%s)", plural(len(args)), kwOnly, plural(kwOnly))
+		}
+		was := "were"
+		if len(args) == 1 && kwOnly == 0 {
+			was = "was"
+		}
+		it.raise("TypeError", "%s() takes %s but %s %s given", f.qualname, takes, given, was)
+	}
+	if varargs != nil {
+		vars[varargs.name] = &pyTuple{append([]pyValue(nil), args[next:]...)}
+	}
+	var extra *pyDict
+	if varkw != nil {
+		extra = newPyDict()
+		vars[varkw.name] = extra
+	}
+	for _, k := range kw {
+		var param *pyParam
+		for i := range f.params {
+			if p := &f.params[i]; p.star == 0 && p.name == k.name {
+				param = p
+				break
+			}
+		}
+		if param == nil {
+			if extra == nil {
+				it.raise("TypeError", "%s() got an unexpected keyword argument '%s'", f.qualname, k.name)
+			}
+			it.dictSet(extra, k.name, k.value)
+			continue
+		}
+		if _, dup := vars[k.name]; dup {
+			it.raise("TypeError", "%s() got multiple values for argument '%s'", f.qualname, k.name)
+		}
+		vars[k.name] = k.value
+	}
+	var missing, missingKw []string
+	for _, p := range f.params {
+		if _, ok := vars[p.name]; ok || p.star != 0 {
+			continue
+		}
+		switch {
+		case p.def != nil:
+			vars[p.name] = p.def
+		case p.kwOnly:
+			missingKw = append(missingKw, "'"+p.name+"'")
+		default:
+			missing = append(missing, "'"+p.name+"'")
+		}
+	}
+	if len(missing) > 0 {
+		it.raise("TypeError", "%s() missing %d required positional argument%s: %s", f.qualname, len(missing), plural(len(missing)), pyNameList(missing))
+	}
+	if len(missingKw) > 0 {
+		it.raise("TypeError", "%s() missing %d required keyword-only argument%s: %s", f.qualname, len(missingKw), plural(len(missingKw)), pyNameList(missingKw))
+	}
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
+
+// pyNameList une nombres como CPython: 'a', 'b' and 'c'
+func pyNameList(names []string) string {
+	switch len(names) {
+	case 1:
+		return names[0]
+	case 2:
+		return names[0] + " and " + names[1]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + ", and " + names[len(names)-1]
+}
+
+func (it *pyInterp) makeClass(n *ParseNode, s *pyScope) *pyClass {
+	cls := &pyClass{name: n.Label, attrs: make(map[string]pyValue)}
+	var body *ParseNode
+	for i := range n.Children {
+		switch c := &n.Children[i]; c.Kind {
+		case "Extends":
+			if c.Children[0].Kind == "KeywordArg" {
+				continue
+			}
+			base, ok := it.eval(&c.Children[0], s).(*pyClass)
+			if !ok {
+				it.at(c)
+				it.raise("TypeError", "bases must be types")
+			}
+			cls.bases = append(cls.bases, base)
+		case "ClassBody":
+			body = c
+		}
+	}
+	if len(cls.bases) == 0 {
+		cls.bases = []*pyClass{it.types["object"]}
+	}
+	it.at(n)
+	cls.mro = it.linearize(cls)
+	for _, b := range cls.bases {
+		cls.exception = cls.exception || b.exception
+	}
+	scope := &pyScope{vars: cls.attrs, parent: s, class: cls}
+	scope.vars["__module__"] = "__main__"
+	scope.vars["__qualname__"] = cls.name
+	it.execBlock(body.Children, scope)
+	if _, eq := cls.attrs["__eq__"]; eq {
+		if _, hash := cls.attrs["__hash__"]; !hash {
+			cls.attrs["__hash__"] = pyNone
+		}
+	}
+	return cls
+}
+
+// linearize calcula el MRO de una clase con el algoritmo C3
+func (it *pyInterp) linearize(cls *pyClass) []*pyClass {
+	var seqs [][]*pyClass
+	for _, b := range cls.bases {
+		seqs = append(seqs, append([]*pyClass(nil), b.mro...))
+	}
+	seqs = append(seqs, append([]*pyClass(nil), cls.bases...))
+	mro := []*pyClass{cls}
+	for {
+		var candidate *pyClass
+		empty := true
+		for _, seq := range seqs {
+			if len(seq) == 0 {
+				continue
+			}
+			empty = false
+			head := seq[0]
+			inTail := false
+			for _, other := range seqs {
+				for _, c := range other[min(1, len(other)):] {
+					if c == head {
+						inTail = true
+					}
+				}
+			}
+			if !inTail {
+				candidate = head
+				break
+			}
+		}
+		if empty {
+			return mro
+		}
+		if candidate == nil {
+			it.raise("TypeError", "Cannot create a consistent method resolution order (MRO)")
+		}
+		mro = append(mro, candidate)
+		for i, seq := range seqs {
+			if len(seq) > 0 && seq[0] == candidate {
+				seqs[i] = seq[1:]
+			}
+		}
+	}
+}
+
+func (it *pyInterp) instantiate(cls *pyClass, args []pyValue, kw []pyKeyword) pyValue {
+	if cls.construct != nil {
+		return cls.construct(it, args, kw)
+	}
+	for _, c := range cls.mro {
+		if c.construct != nil && c != it.types["object"] {
+			// Subclase de un tipo predefinido: se crea un valor del tipo base
+			return c.construct(it, args, kw)
+		}
+	}
+	it.b.alloc(pyObjectCost)
+	inst := &pyInstance{class: cls, attrs: make(map[string]pyValue)}
+	if cls.exception {
+		inst.args = args
+	}
+	m, _ := it.classAttr(cls, "__new__")
+	customNew := m != it.types["object"].attrs["__new__"]
+	if customNew {
+		if f, ok := m.(*pyStaticMethod); ok {
+			m = f.fn
+		}
+		r := it.call(m, append([]pyValue{cls}, args...), kw)
+		if obj, ok := r.(*pyInstance); !ok || !it.isInstance(obj, cls) {
+			return r
+		} else {
+			inst = obj
+		}
+	}
+	init, _ := it.classAttr(cls, "__init__")
+	if f, ok := init.(*pyFunction); ok && init != it.types["object"].attrs["__init__"] {
+		if r := it.call(&pyBound{inst, f}, args, kw); r != pyNone {
+			it.raise("TypeError", "__init__() should return None, not '%s'", it.typeName(r))
+		}
+	} else if !customNew && (len(args) > 0 || len(kw) > 0) {
+		it.raise("TypeError", "%s() takes no arguments", cls.name)
+	}
+	return inst
+}
+
+// classAttr busca un atributo en el MRO de una clase
+func (it *pyInterp) classAttr(cls *pyClass, name string) (pyValue, bool) {
+	for _, c := range cls.mro {
+		if v, ok := c.attrs[name]; ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// bind convierte un atributo de clase en lo que se obtiene al leerlo desde
+// una instancia (self) o desde la clase (self == nil)
+func (it *pyInterp) bind(attr, self pyValue, cls *pyClass) pyValue {
+	switch a := attr.(type) {
+	case *pyFunction:
+		if self != nil {
+			return &pyBound{self, a}
+		}
+	case *pyStaticMethod:
+		return a.fn
+	case *pyClassMethod:
+		return &pyBound{cls, a.fn}
+	case *pyProperty:
+		if self != nil {
+			if a.get == nil {
+				it.raise("AttributeError", "property object has no getter")
+			}
+			return it.call(a.get, []pyValue{self}, nil)
+		}
+	}
+	return attr
+}
+
+// lookupAttr lee un atributo sin lanzar AttributeError si no existe
+func (it *pyInterp) lookupAttr(v pyValue, name string) (pyValue, bool) {
+	switch x := v.(type) {
+	case *pyInstance:
+		if a, ok := x.attrs[name]; ok {
+			return a, true
+		}
+		if a, ok := it.classAttr(x.class, name); ok {
+			return it.bind(a, x, x.class), true
+		}
+		switch name {
+		case "__class__":
+			return x.class, true
+		case "__dict__":
+			d := newPyDict()
+			for _, k := range x.order {
+				if a, ok := x.attrs[k]; ok {
+					it.dictSet(d, k, a)
+				}
+			}
+			return d, true
+		case "args":
+			if x.class.exception {
+				return &pyTuple{x.args}, true
+			}
+		}
+		if g, ok := it.classAttr(x.class, "__getattr__"); ok {
+			return it.call(it.bind(g, x, x.class), []pyValue{name}, nil), true
+		}
+		return nil, false
+	case *pyClass:
+		if a, ok := it.classAttr(x, name); ok {
+			return it.bind(a, nil, x), true
+		}
+		switch name {
+		case "__name__", "__qualname__":
+			return x.name, true
+		case "__mro__":
+			items := make([]pyValue, len(x.mro))
+			for i, c := range x.mro {
+				items[i] = c
+			}
+			return &pyTuple{items}, true
+		case "__bases__":
+			items := make([]pyValue, len(x.bases))
+			for i, c := range x.bases {
+				items[i] = c
+			}
+			return &pyTuple{items}, true
+		}
+		if x.builtin {
+			return it.typeAttr(x, name)
+		}
+		return nil, false
+	case *pyModule:
+		a, ok := x.attrs[name]
+		return a, ok
+	case *pySuper:
+		cls, _ := x.self.(*pyClass)
+		if inst, ok := x.self.(*pyInstance); ok {
+			cls = inst.class
+		}
+		if cls == nil {
+			cls = it.typeOf(x.self)
+		}
+		after := false
+		for _, c := range cls.mro {
+			if after {
+				if a, ok := c.attrs[name]; ok {
+					if _, isClass := x.self.(*pyClass); isClass {
+						return it.bind(a, nil, cls), true
+					}
+					return it.bind(a, x.self, cls), true
+				}
+			}
+			after = after || c == x.class
+		}
+		return nil, false
+	case *pyFunction:
+		if a, ok := x.attrs[name]; ok {
+			return a, true
+		}
+		switch name {
+		case "__name__":
+			return x.name, true
+		case "__qualname__":
+			return x.qualname, true
+		case "__doc__":
+			return pyNone, true
+		}
+		return nil, false
+	case *pyBound:
+		switch name {
+		case "__self__":
+			return x.self, true
+		case "__func__":
+			return x.fn, true
+		}
+		return it.lookupAttr(x.fn, name)
+	case *pyProperty:
+		if name == "setter" || name == "getter" {
+			return pyBuiltin(name, func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+				p := &pyProperty{x.get, x.set}
+				if name == "setter" {
+					p.set = pyArg(args, 0)
+				} else {
+					p.get = pyArg(args, 0)
+				}
+				return p
+			}), true
+		}
+		return nil, false
+	}
+	return it.valueAttr(v, name)
+}
+
+func (it *pyInterp) getAttr(v pyValue, name string) pyValue {
+	if a, ok := it.lookupAttr(v, name); ok {
+		return a
+	}
+	switch x := v.(type) {
+	case *pyModule:
+		it.raise("AttributeError", "module '%s' has no attribute '%s'", x.name, name)
+	case *pyClass:
+		it.raise("AttributeError", "type object '%s' has no attribute '%s'", x.name, name)
+	case *pySuper:
+		it.raise("AttributeError", "'super' object has no attribute '%s'", name)
+	}
+	it.raise("AttributeError", "'%s' object has no attribute '%s'", it.typeName(v), name)
+	return nil
+}
+
+func (it *pyInterp) setAttr(v pyValue, name string, value pyValue) {
+	switch x := v.(type) {
+	case *pyInstance:
+		if m, ok := it.classAttr(x.class, "__setattr__"); ok {
+			if f, ok := m.(*pyFunction); ok && f.native == nil {
+				it.call(f, []pyValue{x, name, value}, nil)
+				return
+			}
+		}
+		it.setInstanceAttr(x, name, value)
+		return
+	case *pyClass:
+		if !x.builtin {
+			x.attrs[name] = value
+			return
+		}
+		it.raise("TypeError", "cannot set '%s' attribute of immutable type '%s'", name, x.name)
+	case *pyModule:
+		x.attrs[name] = value
+		return
+	case *pyFunction:
+		if x.attrs == nil {
+			x.attrs = make(map[string]pyValue)
+		}
+		x.attrs[name] = value
+		return
+	}
+	if _, ok := it.lookupAttr(v, name); ok {
+		it.raise("AttributeError", "'%s' object attribute '%s' is read-only", it.typeName(v), name)
+	}
+	it.raise("AttributeError", "'%s' object has no attribute '%s'", it.typeName(v), name)
+}
+
+// setInstanceAttr es object.__setattr__: respeta las propiedades y si no
+// guarda el atributo en la instancia
+func (it *pyInterp) setInstanceAttr(x *pyInstance, name string, value pyValue) {
+	if a, ok := it.classAttr(x.class, name); ok {
+		if p, ok := a.(*pyProperty); ok {
+			if p.set == nil {
+				it.raise("AttributeError", "property '%s' of '%s' object has no setter", name, x.class.name)
+			}
+			it.call(p.set, []pyValue{x, value}, nil)
+			return
+		}
+	}
+	if _, ok := x.attrs[name]; !ok {
+		it.b.alloc(pyValueCost)
+		x.order = append(x.order, name)
+	}
+	x.attrs[name] = value
+}
+
+func (it *pyInterp) delAttr(v pyValue, name string) {
+	switch x := v.(type) {
+	case *pyInstance:
+		if _, ok := x.attrs[name]; ok {
+			delete(x.attrs, name)
+			return
+		}
+	case *pyClass:
+		if _, ok := x.attrs[name]; ok && !x.builtin {
+			delete(x.attrs, name)
+			return
+		}
+	case *pyModule:
+		if _, ok := x.attrs[name]; ok {
+			delete(x.attrs, name)
+			return
+		}
+	}
+	it.raise("AttributeError", "'%s' object has no attribute '%s'", it.typeName(v), name)
+}
+
+// ───── Generadores ─────
+//
+// El cuerpo de un generador corre en su propia gorutina y se turna con
+// quien lo consume: next() lo despierta y yield le devuelve el control, así
+// que nunca corren a la vez y pueden compartir el intérprete. Mientras el
+// generador está suspendido sus marcos se guardan aparte; al reanudarlo se
+// apilan sobre los de quien lo llama, como en el traceback de CPython. Al
+// terminar el programa los generadores suspendidos se cierran para no
+// dejar gorutinas colgadas.
+
+type pyGenerator struct {
+	fn      *pyFunction
+	scope   *pyScope
+	in      chan pyValue
+	out     chan pyGenMsg
+	frames  []*pyFrame
+	base    int
+	started bool
+	running bool
+	done    bool
+}
+
+type pyGenMsg struct {
+	value pyValue
+	done  bool
+	fail  interface{} // pánico del cuerpo del generador
+}
+
+// pyGenClosed detiene un generador que se cierra
+type pyGenClosed struct{}
+
+func (g *pyGenerator) resume(it *pyInterp, send pyValue) (pyValue, bool) {
+	if g.done {
+		return nil, false
+	}
+	if g.running {
+		it.raise("ValueError", "generator already executing")
+	}
+	g.running = true
+	defer func() { g.running = false }()
+	if !g.started {
+		if send != pyNone {
+			it.raise("TypeError", "can't send non-None value to a just-started generator")
+		}
+		g.started = true
+		g.in, g.out = make(chan pyValue), make(chan pyGenMsg)
+		it.b.alloc(pyGeneratorCost)
+		it.generators = append(it.generators, g)
+		go g.run(it)
+	} else {
+		g.in <- send
+	}
+	msg := <-g.out
+	if msg.fail != nil {
+		g.done = true
+		panic(msg.fail)
+	}
+	if msg.done {
+		g.done = true
+		return nil, false
+	}
+	return msg.value, true
+}
+
+func (g *pyGenerator) run(it *pyInterp) {
+	defer func() {
+		r := recover()
+		if _, closed := r.(pyGenClosed); closed || r == nil {
+			g.out <- pyGenMsg{done: true}
+			return
+		}
+		it.frames = it.frames[:g.base]
+		g.out <- pyGenMsg{fail: r}
+	}()
+	g.base = len(it.frames)
+	it.frames = append(it.frames, &pyFrame{name: g.fn.name, node: g.fn.body, scope: g.scope, gen: g})
+	it.execBlock(g.fn.body.Children, g.scope)
+	it.frames = it.frames[:g.base]
+}
+
+// yield entrega v a quien consume el generador y espera a que lo reanude
+func (g *pyGenerator) yield(it *pyInterp, v pyValue) pyValue {
+	g.frames = append(g.frames[:0], it.frames[g.base:]...)
+	it.frames = it.frames[:g.base]
+	g.out <- pyGenMsg{value: v}
+	sent, ok := <-g.in
+	if !ok {
+		panic(pyGenClosed{})
+	}
+	g.base = len(it.frames)
+	it.frames = append(it.frames, g.frames...)
+	return sent
+}
+
+func (g *pyGenerator) close() {
+	if g.started && !g.done {
+		close(g.in)
+		<-g.out
+	}
+	g.done = true
+}
+
+func (it *pyInterp) closeGenerators() {
+	for _, g := range it.generators {
+		g.close()
+	}
+}
+
+func (it *pyInterp) evalYield(n *ParseNode, s *pyScope) pyValue {
+	g := it.frame().gen
+	if g == nil {
+		it.at(n)
+		it.raise("SyntaxError", "'yield' outside function")
+	}
+	from := strings.HasPrefix(strings.TrimLeft(it.src[n.Pos+len("yield"):], " \t"), "from")
+	var v pyValue = pyNone
+	if len(n.Children) > 0 {
+		v = it.eval(&n.Children[0], s)
+	}
+	if !from {
+		return g.yield(it, v)
+	}
+	next := it.iter(v)
+	for {
+		item, ok := next()
+		if !ok {
+			return pyNone
+		}
+		g.yield(it, item)
+	}
+}
+
+// ───── Literales ─────
+
+func (it *pyInterp) literal(n *ParseNode, s *pyScope) pyValue {
+	if v, ok := it.literals[n]; ok {
+		return v
+	}
+	if parts, ok := it.fstrings[n]; ok {
+		return it.renderFString(parts, s)
+	}
+	var v pyValue
+	switch label := n.Label; {
+	case label == "None":
+		v = pyNone
+	case label == "True":
+		v = true
+	case label == "False":
+		v = false
+	case label == "...":
+		v = pyEllipsis
+	case label[0] >= '0' && label[0] <= '9' || label[0] == '.':
+		v = it.parseNumber(label)
+	default:
+		parts, text := it.parseStringLiteral(n)
+		if parts != nil {
+			it.fstrings[n] = parts
+			return it.renderFString(parts, s)
+		}
+		v = text
+	}
+	it.literals[n] = v
+	return v
+}
+
+func (it *pyInterp) parseNumber(label string) pyValue {
+	clean := strings.ReplaceAll(label, "_", "")
+	lower := strings.ToLower(clean)
+	if !strings.HasPrefix(lower, "0x") && strings.ContainsAny(lower, ".e") {
+		f, _ := parseFloatPrefix(clean)
+		return f
+	}
+	if strings.HasSuffix(lower, "j") {
+		it.raise("SyntaxError", "el intérprete integrado no admite números complejos")
+	}
+	n, ok := new(big.Int).SetString(clean, 0)
+	if !ok {
+		n, _ = new(big.Int).SetString(strings.TrimLeft(clean, "0")+"0", 10)
+		n.Quo(n, big.NewInt(10))
+	}
+	return pyNormInt(n)
+}
+
+func parseFloatPrefix(s string) (float64, bool) {
+	var f float64
+	_, err := fmt.Sscan(s, &f)
+	return f, err == nil
+}
+
+// pyFStringPart es un trozo de una f-string: texto o un campo {expr!c:spec}
+type pyFStringPart struct {
+	text  string
+	expr  *ParseNode
+	debug string // "x=" en f"{x=}"
+	conv  byte   // 'r', 's', 'a' o 0
+	spec  []pyFStringPart
+}
+
+// parseStringLiteral decodifica un literal (con sus prefijos y las cadenas
+// adyacentes que se concatenan) desde el código original. Si alguna parte
+// es una f-string devuelve sus trozos
+func (it *pyInterp) parseStringLiteral(n *ParseNode) ([]pyFStringPart, string) {
+	src := it.src[n.Pos:n.End]
+	var parts []pyFStringPart
+	var text strings.Builder
+	isF := false
+	for pos := 0; pos < len(src); {
+		c := src[pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\\':
+			pos++
+			continue
+		case c == '#':
+			for pos < len(src) && src[pos] != '\n' {
+				pos++
+			}
+			continue
+		}
+		prefixStart := pos
+		for pos < len(src) && src[pos] != '\'' && src[pos] != '"' {
+			pos++
+		}
+		prefix := strings.ToLower(src[prefixStart:pos])
+		if pos >= len(src) {
+			break
+		}
+		quote := src[pos : pos+1]
+		if strings.HasPrefix(src[pos:], strings.Repeat(quote, 3)) {
+			quote = strings.Repeat(quote, 3)
+		}
+		bodyStart := pos + len(quote)
+		end := bodyStart
+		for end < len(src) && !strings.HasPrefix(src[end:], quote) {
+			if src[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		end = min(end, len(src))
+		body := src[bodyStart:end]
+		pos = end + len(quote)
+		raw := strings.Contains(prefix, "r")
+		if strings.Contains(prefix, "f") {
+			isF = true
+			if text.Len() > 0 {
+				parts = append(parts, pyFStringPart{text: text.String()})
+				text.Reset()
+			}
+			parts = append(parts, it.parseFString(body, raw, n.Pos+bodyStart)...)
+			continue
+		}
+		if raw {
+			text.WriteString(body)
+		} else {
+			text.WriteString(pyUnescape(body))
+		}
+	}
+	if !isF {
+		return nil, text.String()
+	}
+	if text.Len() > 0 {
+		parts = append(parts, pyFStringPart{text: text.String()})
+	}
+	if parts == nil {
+		parts = []pyFStringPart{}
+	}
+	return parts, ""
+}
+
+// parseFString divide el cuerpo de una f-string en texto y campos; offset
+// es la posición del cuerpo en el código para ubicar los errores
+func (it *pyInterp) parseFString(body string, raw bool, offset int) []pyFStringPart {
+	var parts []pyFStringPart
+	var text strings.Builder
+	flush := func() {
+		if text.Len() == 0 {
+			return
+		}
+		s := text.String()
+		if !raw {
+			s = pyUnescape(s)
+		}
+		parts = append(parts, pyFStringPart{text: s})
+		text.Reset()
+	}
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case c == '{' && i+1 < len(body) && body[i+1] == '{', c == '}' && i+1 < len(body) && body[i+1] == '}':
+			text.WriteByte(c)
+			i++
+		case c == '{':
+			end := pyMatchingBrace(body, i)
+			if end < 0 {
+				it.raise("SyntaxError", "f-string: expecting '}'")
+			}
+			flush()
+			parts = append(parts, it.parseFStringField(body[i+1:end], raw, offset+i+1))
+			i = end
+		case c == '}':
+			it.raise("SyntaxError", "f-string: single '}' is not allowed")
+		case c == '\\' && !raw && i+1 < len(body):
+			text.WriteString(body[i : i+2])
+			i++
+		default:
+			text.WriteByte(c)
+		}
+	}
+	flush()
+	return parts
+}
+
+// pyMatchingBrace devuelve la posición de la '}' que cierra la '{' en
+// start, saltando paréntesis y cadenas anidados
+func pyMatchingBrace(s string, start int) int {
+	depth := 0
+	var quote byte
+	for i := start; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '{' || c == '(' || c == '[':
+			depth++
+		case c == '}' || c == ')' || c == ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func (it *pyInterp) parseFStringField(field string, raw bool, offset int) pyFStringPart {
+	var part pyFStringPart
+	exprEnd := len(field)
+	depth := 0
+	var quote byte
+scan:
+	for i := 0; i < len(field); i++ {
+		c := field[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case depth == 0 && c == '!' && i+1 < len(field) && field[i+1] != '=':
+			exprEnd = i
+			if i+1 < len(field) {
+				part.conv = field[i+1]
+			}
+			if j := strings.IndexByte(field[i:], ':'); j >= 0 {
+				part.spec = it.parseFString(field[i+j+1:], raw, offset+i+j+1)
+			}
+			break scan
+		case depth == 0 && c == ':':
+			exprEnd = i
+			part.spec = it.parseFString(field[i+1:], raw, offset+i+1)
+			break scan
+		}
+	}
+	expr := field[:exprEnd]
+	if trimmed := strings.TrimRight(expr, " "); strings.HasSuffix(trimmed, "=") && !strings.HasSuffix(trimmed, "==") &&
+		!strings.ContainsAny(trimmed[max(len(trimmed)-2, 0):len(trimmed)-1], "!<>=") {
+		part.debug = expr
+		expr = trimmed[:len(trimmed)-1]
+	}
+	if strings.TrimSpace(expr) == "" {
+		it.raise("SyntaxError", "f-string: empty expression not allowed")
+	}
+	p := NewParser(Tokenize(expr, "python"), "python", expr)
+	p.pyLines = p.pythonLines()
+	if len(p.pyLines) == 0 {
+		it.raise("SyntaxError", "f-string: invalid syntax")
+	}
+	p.enterLine()
+	node := p.parsePyTestList()
+	if len(p.errors) > 0 || !p.atEnd() {
+		it.raise("SyntaxError", "f-string: invalid syntax")
+	}
+	shiftPositions(&node, offset)
+	part.expr = &node
+	return part
+}
+
+func (it *pyInterp) renderFString(parts []pyFStringPart, s *pyScope) string {
+	var sb strings.Builder
+	for _, p := range parts {
+		if p.expr == nil {
+			sb.WriteString(p.text)
+			continue
+		}
+		v := it.eval(p.expr, s)
+		spec := it.renderFString(p.spec, s)
+		conv := p.conv
+		if p.debug != "" {
+			sb.WriteString(p.debug)
+			if conv == 0 && spec == "" {
+				conv = 'r'
+			}
+		}
+		switch conv {
+		case 'r':
+			v = it.repr(v)
+		case 's':
+			v = it.str(v)
+		case 'a':
+			v = pyASCII(it.repr(v))
+		}
+		it.at(p.expr)
+		sb.WriteString(it.format(v, spec))
+	}
+	it.b.alloc(sb.Len())
+	return sb.String()
+}
+
+// pyUnescape decodifica las secuencias de escape de una cadena
+func pyUnescape(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i+1 >= len(s) {
+			sb.WriteByte(c)
+			continue
+		}
+		i++
+		switch e := s[i]; e {
+		case '\n':
+		case '\r':
+			if i+1 < len(s) && s[i+1] == '\n' {
+				i++
+			}
+		case '\\', '\'', '"':
+			sb.WriteByte(e)
+		case 'n':
+			sb.WriteByte('\n')
+		case 't':
+			sb.WriteByte('\t')
+		case 'r':
+			sb.WriteByte('\r')
+		case 'b':
+			sb.WriteByte('\b')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'v':
+			sb.WriteByte('\v')
+		case 'a':
+			sb.WriteByte('\a')
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			v, j := 0, i
+			for ; j < len(s) && j < i+3 && s[j] >= '0' && s[j] <= '7'; j++ {
+				v = v*8 + int(s[j]-'0')
+			}
+			sb.WriteRune(rune(v))
+			i = j - 1
+		case 'x', 'u', 'U':
+			width := map[byte]int{'x': 2, 'u': 4, 'U': 8}[e]
+			if i+1+width > len(s) {
+				sb.WriteByte('\\')
+				sb.WriteByte(e)
+				continue
+			}
+			var v rune
+			ok := true
+			for _, h := range s[i+1 : i+1+width] {
+				d := strings.IndexRune("0123456789abcdef", h|0x20)
+				if d < 0 {
+					ok = false
+					break
+				}
+				v = v*16 + rune(d)
+			}
+			if !ok {
+				sb.WriteByte('\\')
+				sb.WriteByte(e)
+				continue
+			}
+			sb.WriteRune(v)
+			i += width
+		default:
+			sb.WriteByte('\\')
+			sb.WriteByte(e)
+		}
+	}
+	return sb.String()
+}
+
+// ───── Números ─────
+
+// pyNormInt devuelve n como int64 si cabe
+func pyNormInt(n *big.Int) pyValue {
+	if n.IsInt64() {
+		return n.Int64()
+	}
+	return n
+}
+
+func pyBig(v pyValue) *big.Int {
+	switch x := v.(type) {
+	case int64:
+		return big.NewInt(x)
+	case *big.Int:
+		return x
+	case bool:
+		if x {
+			return big.NewInt(1)
+		}
+		return big.NewInt(0)
+	}
+	return nil
+}
+
+// pyNumber normaliza un número (bool cuenta como int)
+func pyNumber(v pyValue) (pyValue, bool) {
+	switch x := v.(type) {
+	case int64, *big.Int, float64:
+		return v, true
+	case bool:
+		if x {
+			return int64(1), true
+		}
+		return int64(0), true
+	}
+	return nil, false
+}
+
+func (it *pyInterp) toFloat(v pyValue) float64 {
+	switch x := v.(type) {
+	case int64:
+		return float64(x)
+	case float64:
+		return x
+	case bool:
+		if x {
+			return 1
+		}
+		return 0
+	case *big.Int:
+		f, _ := new(big.Float).SetInt(x).Float64()
+		if math.IsInf(f, 0) {
+			it.raise("OverflowError", "int too large to convert to float")
+		}
+		return f
+	}
+	return math.NaN()
+}
+
+func (it *pyInterp) numeric(op string, a, b pyValue) pyValue {
+	_, af := a.(float64)
+	_, bf := b.(float64)
+	if af || bf {
+		switch op {
+		case "&", "|", "^", "<<", ">>":
+			return nil
+		}
+		return it.floatOp(op, it.toFloat(a), it.toFloat(b))
+	}
+	if x, ok := a.(int64); ok {
+		if y, ok := b.(int64); ok {
+			if r, ok := it.smallIntOp(op, x, y); ok {
+				return r
+			}
+		}
+	}
+	return it.bigIntOp(op, pyBig(a), pyBig(b))
+}
+
+// smallIntOp opera enteros de 64 bits cuando el resultado no desborda
+func (it *pyInterp) smallIntOp(op string, x, y int64) (pyValue, bool) {
+	const small = 1 << 31
+	switch op {
+	case "+":
+		if r := x + y; (r > x) == (y > 0) {
+			return r, true
+		}
+	case "-":
+		if r := x - y; (r < x) == (y > 0) {
+			return r, true
+		}
+	case "*":
+		if x > -small && x < small && y > -small && y < small {
+			return x * y, true
+		}
+	case "/":
+		if y == 0 {
+			it.raise("ZeroDivisionError", "division by zero")
+		}
+		return float64(x) / float64(y), x > -1<<53 && x < 1<<53 && y > -1<<53 && y < 1<<53
+	case "//", "%":
+		if y == 0 {
+			if op == "%" {
+				it.raise("ZeroDivisionError", "integer modulo by zero")
+			}
+			it.raise("ZeroDivisionError", "integer division or modulo by zero")
+		}
+		if x == math.MinInt64 && y == -1 {
+			return nil, false
+		}
+		q, r := x/y, x%y
+		if r != 0 && (r < 0) != (y < 0) {
+			q--
+			r += y
+		}
+		if op == "//" {
+			return q, true
+		}
+		return r, true
+	case "&":
+		return x & y, true
+	case "|":
+		return x | y, true
+	case "^":
+		return x ^ y, true
+	case ">>":
+		if y < 0 {
+			it.raise("ValueError", "negative shift count")
+		}
+		return x >> min(y, 63), true
+	case "<<":
+		if y >= 0 && y < 32 && x > -small && x < small {
+			return x << y, true
+		}
+	}
+	return nil, false
+}
+
+func (it *pyInterp) bigIntOp(op string, x, y *big.Int) pyValue {
+	r := new(big.Int)
+	switch op {
+	case "+":
+		r.Add(x, y)
+	case "-":
+		r.Sub(x, y)
+	case "*":
+		it.b.alloc((x.BitLen() + y.BitLen()) / 8)
+		r.Mul(x, y)
+	case "/":
+		if y.Sign() == 0 {
+			it.raise("ZeroDivisionError", "division by zero")
+		}
+		f, _ := new(big.Rat).SetFrac(x, y).Float64()
+		if math.IsInf(f, 0) {
+			it.raise("OverflowError", "integer division result too large for a float")
+		}
+		return f
+	case "//", "%":
+		if y.Sign() == 0 {
+			if op == "%" {
+				it.raise("ZeroDivisionError", "integer modulo by zero")
+			}
+			it.raise("ZeroDivisionError", "integer division or modulo by zero")
+		}
+		q, m := new(big.Int).QuoRem(x, y, new(big.Int))
+		if m.Sign() != 0 && m.Sign() != y.Sign() {
+			q.Sub(q, big.NewInt(1))
+			m.Add(m, y)
+		}
+		if op == "//" {
+			return pyNormInt(q)
+		}
+		return pyNormInt(m)
+	case "**":
+		if y.Sign() < 0 {
+			if x.Sign() == 0 {
+				it.raise("ZeroDivisionError", "0.0 cannot be raised to a negative power")
+			}
+			return it.floatOp("**", it.toFloat(x), it.toFloat(y))
+		}
+		if x.BitLen() > 1 && (!y.IsInt64() || y.Int64()*int64(x.BitLen()) > 1<<33) {
+			it.b.alloc(math.MaxInt)
+		}
+		if x.BitLen() > 1 {
+			it.b.alloc(int(y.Int64() * int64(x.BitLen()) / 8))
+			// Cada paso del cuadrado y multiplicación cuenta para el tiempo
+			for i := int64(0); i < y.Int64()*int64(x.BitLen())/(1<<16); i++ {
+				it.b.step()
+			}
+		}
+		r.Exp(x, y, nil)
+	case "&":
+		r.And(x, y)
+	case "|":
+		r.Or(x, y)
+	case "^":
+		r.Xor(x, y)
+	case "<<":
+		if y.Sign() < 0 {
+			it.raise("ValueError", "negative shift count")
+		}
+		if !y.IsInt64() || y.Int64() > 1<<33 {
+			it.b.alloc(math.MaxInt)
+		}
+		it.b.alloc(int(y.Int64() / 8))
+		r.Lsh(x, uint(y.Int64()))
+	case ">>":
+		if y.Sign() < 0 {
+			it.raise("ValueError", "negative shift count")
+		}
+		if !y.IsInt64() || y.Int64() > int64(x.BitLen()) {
+			if x.Sign() < 0 {
+				return int64(-1)
+			}
+			return int64(0)
+		}
+		r.Rsh(x, uint(y.Int64()))
+	default:
+		return nil
+	}
+	return pyNormInt(r)
+}
+
+func (it *pyInterp) floatOp(op string, x, y float64) pyValue {
+	switch op {
+	case "+":
+		return x + y
+	case "-":
+		return x - y
+	case "*":
+		return x * y
+	case "/":
+		if y == 0 {
+			it.raise("ZeroDivisionError", "float division by zero")
+		}
+		return x / y
+	case "//":
+		if y == 0 {
+			it.raise("ZeroDivisionError", "float floor division by zero")
+		}
+		return math.Floor(x / y)
+	case "%":
+		if y == 0 {
+			it.raise("ZeroDivisionError", "float modulo")
+		}
+		r := math.Mod(x, y)
+		if r != 0 && (r < 0) != (y < 0) {
+			r += y
+		}
+		return r
+	case "**":
+		if x == 0 && y < 0 {
+			it.raise("ZeroDivisionError", "0.0 cannot be raised to a negative power")
+		}
+		if x < 0 && y != math.Trunc(y) {
+			it.raise("ValueError", "el intérprete integrado no admite números complejos")
+		}
+		r := math.Pow(x, y)
+		if math.IsInf(r, 0) && !math.IsInf(x, 0) {
+			it.raise("OverflowError", "(34, 'Numerical result out of range')")
+		}
+		return r
+	}
+	return nil
+}
+
+func (it *pyInterp) unary(op string, v pyValue) pyValue {
+	if n, ok := pyNumber(v); ok {
+		switch x := n.(type) {
+		case int64:
+			switch op {
+			case "-":
+				if x == math.MinInt64 {
+					return new(big.Int).Neg(big.NewInt(x))
+				}
+				return -x
+			case "+":
+				return x
+			case "~":
+				return ^x
+			}
+		case *big.Int:
+			switch op {
+			case "-":
+				return pyNormInt(new(big.Int).Neg(x))
+			case "+":
+				return x
+			case "~":
+				return pyNormInt(new(big.Int).Not(x))
+			}
+		case float64:
+			switch op {
+			case "-":
+				return -x
+			case "+":
+				return x
+			}
+		}
+	}
+	if inst, ok := v.(*pyInstance); ok {
+		name := map[string]string{"-": "__neg__", "+": "__pos__", "~": "__invert__"}[op]
+		if m, ok := it.lookupAttr(inst, name); ok {
+			return it.call(m, nil, nil)
+		}
+	}
+	if op == "await" {
+		it.raise("SyntaxError", "'await' outside async function")
+	}
+	it.raise("TypeError", "bad operand type for unary %s: '%s'", op, it.typeName(v))
+	return nil
+}
+
+// ───── Operadores ─────
+
+type pyDunder struct{ method, reflected, inplace string }
+
+var pyDunders = map[string]pyDunder{
+	"+":  {"__add__", "__radd__", "__iadd__"},
+	"-":  {"__sub__", "__rsub__", "__isub__"},
+	"*":  {"__mul__", "__rmul__", "__imul__"},
+	"/":  {"__truediv__", "__rtruediv__", "__itruediv__"},
+	"//": {"__floordiv__", "__rfloordiv__", "__ifloordiv__"},
+	"%":  {"__mod__", "__rmod__", "__imod__"},
+	"**": {"__pow__", "__rpow__", "__ipow__"},
+	"@":  {"__matmul__", "__rmatmul__", "__imatmul__"},
+	"&":  {"__and__", "__rand__", "__iand__"},
+	"|":  {"__or__", "__ror__", "__ior__"},
+	"^":  {"__xor__", "__rxor__", "__ixor__"},
+	"<<": {"__lshift__", "__rlshift__", "__ilshift__"},
+	">>": {"__rshift__", "__rrshift__", "__irshift__"},
+}
+
+func (it *pyInterp) binary(op string, a, b pyValue) pyValue {
+	if x, ok := pyNumber(a); ok {
+		if y, ok := pyNumber(b); ok {
+			if r := it.numeric(op, x, y); r != nil {
+				return r
+			}
+		}
+	}
+	switch x := a.(type) {
+	case string:
+		switch y := b.(type) {
+		case string:
+			if op == "+" {
+				it.b.alloc(len(x) + len(y))
+				return x + y
+			}
+		}
+		if op == "*" {
+			if n, ok := pyIntArg(b); ok {
+				it.b.alloc(len(x) * int(max(min(n, math.MaxInt32), 0)))
+				return strings.Repeat(x, int(max(n, 0)))
+			}
+		}
+		if op == "%" {
+			return it.percentFormat(x, b)
+		}
+	case *pyList:
+		if y, ok := b.(*pyList); ok && op == "+" {
+			it.b.alloc((len(x.items) + len(y.items)) * pyValueCost)
+			items := append(append(make([]pyValue, 0, len(x.items)+len(y.items)), x.items...), y.items...)
+			return &pyList{items: items, deque: x.deque, maxlen: -1}
+		}
+		if n, ok := pyIntArg(b); ok && op == "*" {
+			return &pyList{items: it.repeat(x.items, n), maxlen: -1}
+		}
+	case *pyTuple:
+		if y, ok := b.(*pyTuple); ok && op == "+" {
+			it.b.alloc((len(x.items) + len(y.items)) * pyValueCost)
+			return &pyTuple{append(append(make([]pyValue, 0, len(x.items)+len(y.items)), x.items...), y.items...)}
+		}
+		if n, ok := pyIntArg(b); ok && op == "*" {
+			return &pyTuple{it.repeat(x.items, n)}
+		}
+	case *pyDict:
+		if y, ok := b.(*pyDict); ok {
+			if r := it.setOp(op, x, y); r != nil {
+				return r
+			}
+		}
+	case int64, bool, *big.Int:
+		if op == "*" {
+			switch b.(type) {
+			case string, *pyList, *pyTuple:
+				return it.binary(op, b, a)
+			}
+		}
+	}
+	d := pyDunders[op]
+	if inst, ok := a.(*pyInstance); ok {
+		if m, ok := it.lookupAttr(inst, d.method); ok {
+			if r := it.call(m, []pyValue{b}, nil); r != pyNotImplemented {
+				return r
+			}
+		}
+	}
+	if inst, ok := b.(*pyInstance); ok {
+		if m, ok := it.lookupAttr(inst, d.reflected); ok {
+			if r := it.call(m, []pyValue{a}, nil); r != pyNotImplemented {
+				return r
+			}
+		}
+	}
+	ta, tb := it.typeName(a), it.typeName(b)
+	switch {
+	case op == "+" && (ta == "str" || ta == "list" || ta == "tuple"):
+		it.raise("TypeError", "can only concatenate %s (not \"%s\") to %s", ta, tb, ta)
+	case op == "*" && (ta == "str" || ta == "list" || ta == "tuple"):
+		it.raise("TypeError", "can't multiply sequence by non-int of type '%s'", tb)
+	case op == "*" && (tb == "str" || tb == "list" || tb == "tuple"):
+		it.raise("TypeError", "can't multiply sequence by non-int of type '%s'", ta)
+	case op == "**":
+		it.raise("TypeError", "unsupported operand type(s) for ** or pow(): '%s' and '%s'", ta, tb)
+	}
+	it.raise("TypeError", "unsupported operand type(s) for %s: '%s' and '%s'", op, ta, tb)
+	return nil
+}
+
+// pyIntArg devuelve v como entero de 64 bits si es int o bool
+func pyIntArg(v pyValue) (int64, bool) {
+	switch x := v.(type) {
+	case int64:
+		return x, true
+	case bool:
+		if x {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func (it *pyInterp) repeat(items []pyValue, n int64) []pyValue {
+	if n <= 0 || len(items) == 0 {
+		return []pyValue{}
+	}
+	if n > math.MaxInt32 {
+		it.b.alloc(math.MaxInt)
+	}
+	it.b.alloc(len(items) * int(n) * pyValueCost)
+	out := make([]pyValue, 0, len(items)*int(n))
+	for i := int64(0); i < n; i++ {
+		out = append(out, items...)
+	}
+	return out
+}
+
+// setOp implementa | & - ^ entre conjuntos y | entre diccionarios
+func (it *pyInterp) setOp(op string, x, y *pyDict) pyValue {
+	if x.set != y.set || !x.set && op != "|" {
+		return nil
+	}
+	r := &pyDict{index: make(map[interface{}]int), set: x.set, kind: x.kind, factory: x.factory}
+	switch op {
+	case "|":
+		for _, e := range x.items() {
+			it.dictSet(r, e.key, e.value)
+		}
+		for _, e := range y.items() {
+			it.dictSet(r, e.key, e.value)
+		}
+	case "&":
+		for _, e := range x.items() {
+			if _, ok := y.index[e.hash]; ok {
+				it.dictSet(r, e.key, nil)
+			}
+		}
+	case "-":
+		for _, e := range x.items() {
+			if _, ok := y.index[e.hash]; !ok {
+				it.dictSet(r, e.key, nil)
+			}
+		}
+	case "^":
+		for _, e := range x.items() {
+			if _, ok := y.index[e.hash]; !ok {
+				it.dictSet(r, e.key, nil)
+			}
+		}
+		for _, e := range y.items() {
+			if _, ok := x.index[e.hash]; !ok {
+				it.dictSet(r, e.key, nil)
+			}
+		}
+	default:
+		return nil
+	}
+	return r
+}
+
+func (it *pyInterp) compare(op string, a, b pyValue) bool {
+	switch op {
+	case "==":
+		return it.equal(a, b)
+	case "!=":
+		if inst, ok := a.(*pyInstance); ok {
+			if m, ok := it.lookupAttr(inst, "__ne__"); ok {
+				return it.truthy(it.call(m, []pyValue{b}, nil))
+			}
+		}
+		return !it.equal(a, b)
+	case "is":
+		return pyIs(a, b)
+	case "is not":
+		return !pyIs(a, b)
+	case "in":
+		return it.contains(b, a)
+	case "not in":
+		return !it.contains(b, a)
+	}
+	return it.order(op, a, b)
+}
+
+func pyIs(a, b pyValue) bool {
+	switch x := a.(type) {
+	case *big.Int:
+		return false
+	case float64:
+		y, ok := b.(float64)
+		return ok && x == y
+	}
+	return a == b
+}
+
+func (it *pyInterp) equal(a, b pyValue) bool {
+	if x, ok := pyNumber(a); ok {
+		if y, ok := pyNumber(b); ok {
+			return it.numCompare(x, y) == 0
+		}
+		if _, ok := b.(*pyInstance); !ok {
+			return false
+		}
+	}
+	switch x := a.(type) {
+	case string:
+		y, ok := b.(string)
+		return ok && x == y
+	case *pyList:
+		y, ok := b.(*pyList)
+		return ok && (x == y || it.equalItems(x.items, y.items))
+	case *pyTuple:
+		y, ok := b.(*pyTuple)
+		return ok && (x == y || it.equalItems(x.items, y.items))
+	case *pyDict:
+		y, ok := b.(*pyDict)
+		if !ok || x.set != y.set || x.live != y.live {
+			return false
+		}
+		for _, e := range x.items() {
+			i, ok := y.index[e.hash]
+			if !ok || !x.set && !it.equal(e.value, y.entries[i].value) {
+				return false
+			}
+		}
+		return true
+	case *pyRange:
+		y, ok := b.(*pyRange)
+		return ok && it.equalItems(it.toList(x), it.toList(y))
+	case *pyView:
+		y, ok := b.(*pyView)
+		return ok && x.kind == y.kind && x.kind != "dict_values" && it.equal(x.dict, y.dict)
+	case *pyInstance:
+		if m, ok := it.lookupAttr(x, "__eq__"); ok {
+			if r := it.call(m, []pyValue{b}, nil); r != pyNotImplemented {
+				return it.truthy(r)
+			}
+		}
+	}
+	if y, ok := b.(*pyInstance); ok && a != b {
+		if m, ok := it.lookupAttr(y, "__eq__"); ok {
+			if r := it.call(m, []pyValue{a}, nil); r != pyNotImplemented {
+				return it.truthy(r)
+			}
+		}
+	}
+	return a == b
+}
+
+func (it *pyInterp) equalItems(a, b []pyValue) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] && !it.equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// numCompare compara dos números normalizados y devuelve -1, 0 o 1
+func (it *pyInterp) numCompare(a, b pyValue) int {
+	switch x := a.(type) {
+	case int64:
+		switch y := b.(type) {
+		case int64:
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		case float64:
+			return pyFloatCompare(float64(x), y)
+		}
+	case float64:
+		if _, ok := b.(*big.Int); !ok {
+			return pyFloatCompare(x, it.toFloat(b))
+		}
+		if math.IsInf(x, 0) || math.IsNaN(x) {
+			return pyFloatCompare(x, 0)
+		}
+		f, _ := new(big.Float).SetFloat64(x).Int(nil)
+		if c := f.Cmp(pyBig(b)); c != 0 {
+			return c
+		}
+		return pyFloatCompare(x, math.Trunc(x))
+	}
+	if y, ok := b.(float64); ok {
+		return -it.numCompare(y, a)
+	}
+	return pyBig(a).Cmp(pyBig(b))
+}
+
+// pyFloatCompare compara flotantes; NaN no es igual a nada (2)
+func pyFloatCompare(x, y float64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	case x == y:
+		return 0
+	}
+	return 2
+}
+
+var pyReflectedOps = map[string]string{"<": ">", ">": "<", "<=": ">=", ">=": "<="}
+var pyOrderDunders = map[string]string{"<": "__lt__", ">": "__gt__", "<=": "__le__", ">=": "__ge__"}
+
+// order evalúa <, <=, > y >=
+func (it *pyInterp) order(op string, a, b pyValue) bool {
+	cmp, ok := it.orderCompare(a, b)
+	if !ok {
+		if inst, isInst := a.(*pyInstance); isInst {
+			if m, found := it.lookupAttr(inst, pyOrderDunders[op]); found {
+				if r := it.call(m, []pyValue{b}, nil); r != pyNotImplemented {
+					return it.truthy(r)
+				}
+			}
+		}
+		if inst, isInst := b.(*pyInstance); isInst {
+			if m, found := it.lookupAttr(inst, pyOrderDunders[pyReflectedOps[op]]); found {
+				if r := it.call(m, []pyValue{a}, nil); r != pyNotImplemented {
+					return it.truthy(r)
+				}
+			}
+		}
+		it.raise("TypeError", "'%s' not supported between instances of '%s' and '%s'", op, it.typeName(a), it.typeName(b))
+	}
+	switch op {
+	case "<":
+		return cmp == -1
+	case "<=":
+		return cmp == -1 || cmp == 0
+	case ">":
+		return cmp == 1
+	}
+	return cmp == 1 || cmp == 0
+}
+
+// orderCompare compara valores de tipos ordenables sin métodos especiales
+func (it *pyInterp) orderCompare(a, b pyValue) (int, bool) {
+	if x, ok := pyNumber(a); ok {
+		if y, ok := pyNumber(b); ok {
+			return it.numCompare(x, y), true
+		}
+		return 0, false
+	}
+	switch x := a.(type) {
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), true
+		}
+	case *pyList:
+		if y, ok := b.(*pyList); ok {
+			return it.seqCompare(x.items, y.items), true
+		}
+	case *pyTuple:
+		if y, ok := b.(*pyTuple); ok {
+			return it.seqCompare(x.items, y.items), true
+		}
+	case *pyDict:
+		if y, ok := b.(*pyDict); ok && x.set && y.set {
+			sub := func(p, q *pyDict) bool {
+				for _, e := range p.items() {
+					if _, ok := q.index[e.hash]; !ok {
+						return false
+					}
+				}
+				return true
+			}
+			switch ab, ba := sub(x, y), sub(y, x); {
+			case ab && ba:
+				return 0, true
+			case ab:
+				return -1, true
+			case ba:
+				return 1, true
+			}
+			return 2, true
+		}
+	}
+	return 0, false
+}
+
+func (it *pyInterp) seqCompare(a, b []pyValue) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] == b[i] || it.equal(a[i], b[i]) {
+			continue
+		}
+		if it.order("<", a[i], b[i]) {
+			return -1
+		}
+		return 1
+	}
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	}
+	return 0
+}
+
+// less es el orden de sorted(), min() y max()
+func (it *pyInterp) less(a, b pyValue) bool { return it.order("<", a, b) }
+
+func (it *pyInterp) contains(container, item pyValue) bool {
+	switch c := container.(type) {
+	case string:
+		s, ok := item.(string)
+		if !ok {
+			it.raise("TypeError", "'in <string>' requires string as left operand, not %s", it.typeName(item))
+		}
+		return strings.Contains(c, s)
+	case *pyList:
+		for _, v := range c.items {
+			if v == item || it.equal(v, item) {
+				return true
+			}
+		}
+		return false
+	case *pyTuple:
+		for _, v := range c.items {
+			if v == item || it.equal(v, item) {
+				return true
+			}
+		}
+		return false
+	case *pyDict:
+		_, ok := c.index[it.hashKey(item)]
+		return ok
+	case *pyView:
+		if c.kind == "dict_keys" {
+			return it.contains(c.dict, item)
+		}
+	case *pyRange:
+		n, ok := pyIntArg(item)
+		if f, isFloat := item.(float64); isFloat && f == math.Trunc(f) {
+			n, ok = int64(f), true
+		}
+		if !ok {
+			break
+		}
+		if c.step > 0 && (n < c.start || n >= c.stop) || c.step < 0 && (n > c.start || n <= c.stop) {
+			return false
+		}
+		return (n-c.start)%c.step == 0
+	case *pyInstance:
+		if m, ok := it.lookupAttr(c, "__contains__"); ok {
+			return it.truthy(it.call(m, []pyValue{item}, nil))
+		}
+	}
+	if _, ok := it.iterable(container); !ok {
+		it.raise("TypeError", "argument of type '%s' is not iterable", it.typeName(container))
+	}
+	next := it.iter(container)
+	for {
+		v, ok := next()
+		if !ok {
+			return false
+		}
+		if v == item || it.equal(v, item) {
+			return true
+		}
+	}
+}
+
+// ───── Diccionarios y hash ─────
+
+func newPyDict() *pyDict { return &pyDict{index: make(map[interface{}]int)} }
+
+func newPySet() *pyDict { return &pyDict{index: make(map[interface{}]int), set: true} }
+
+// hashKey devuelve la clave de Go de un valor hashable: 1, 1.0 y True son
+// la misma clave, como en Python
+func (it *pyInterp) hashKey(v pyValue) interface{} {
+	switch x := v.(type) {
+	case bool:
+		if x {
+			return int64(1)
+		}
+		return int64(0)
+	case int64, string, pyNoneType:
+		return v
+	case *big.Int:
+		return pyBigKey(x.String())
+	case float64:
+		if x == math.Trunc(x) && math.Abs(x) < 1<<63 {
+			return int64(x)
+		}
+		return x
+	case *pyTuple:
+		var sb strings.Builder
+		for _, item := range x.items {
+			k := it.hashKey(item)
+			fmt.Fprintf(&sb, "%T%q|", k, fmt.Sprint(k))
+		}
+		return pyTupleKey(sb.String())
+	case *pyDict:
+		if x.kind == "frozenset" {
+			keys := make([]string, 0, x.live)
+			for _, e := range x.items() {
+				keys = append(keys, fmt.Sprintf("%T%q", e.hash, fmt.Sprint(e.hash)))
+			}
+			sort.Strings(keys)
+			return pyTupleKey("frozenset" + strings.Join(keys, "|"))
+		}
+	case *pyInstance:
+		if h, ok := it.classAttr(x.class, "__hash__"); ok {
+			if h == pyNone {
+				break
+			}
+			if f, ok := h.(*pyFunction); ok {
+				n, _ := pyIntArg(it.call(f, []pyValue{x}, nil))
+				return pyInstanceKey(n)
+			}
+		}
+		return x
+	case *pyList:
+	default:
+		return v
+	}
+	it.raise("TypeError", "unhashable type: '%s'", it.typeName(v))
+	return nil
+}
+
+func (it *pyInterp) dictGet(d *pyDict, key pyValue) (pyValue, bool) {
+	i, ok := d.index[it.hashKey(key)]
+	if !ok {
+		return nil, false
+	}
+	return d.entries[i].value, true
+}
+
+func (it *pyInterp) dictSet(d *pyDict, key, value pyValue) {
+	h := it.hashKey(key)
+	if i, ok := d.index[h]; ok {
+		d.entries[i].value = value
+		return
+	}
+	it.b.alloc(2 * pyValueCost)
+	d.index[h] = len(d.entries)
+	d.entries = append(d.entries, pyEntry{key: key, value: value, hash: h})
+	d.live++
+	if d.set {
+		// set_add_entry y set_table_resize de CPython
+		if d.table == 0 {
+			d.table = 8
+		}
+		d.fill++
+		if d.fill*5 >= (d.table-1)*3 {
+			minused := d.live * 4
+			if d.live > 50000 {
+				minused = d.live * 2
+			}
+			d.table = 8
+			for d.table <= minused {
+				d.table <<= 1
+			}
+			d.fill = d.live
+		}
+	}
+}
+
+func (it *pyInterp) dictDelete(d *pyDict, key pyValue) (pyValue, bool) {
+	h := it.hashKey(key)
+	i, ok := d.index[h]
+	if !ok {
+		return nil, false
+	}
+	v := d.entries[i].value
+	delete(d.index, h)
+	d.entries[i] = pyEntry{deleted: true}
+	d.live--
+	if len(d.entries) > 32 && d.live < len(d.entries)/2 {
+		live := d.items()
+		d.entries = live
+		for j, e := range live {
+			d.index[e.hash] = j
+		}
+	}
+	return v, true
+}
+
+// items devuelve las entradas vivas en orden
+func (d *pyDict) items() []pyEntry {
+	if d.live == len(d.entries) {
+		return d.entries
+	}
+	out := make([]pyEntry, 0, d.live)
+	for _, e := range d.entries {
+		if !e.deleted {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// dictUpdate copia en d las claves de un diccionario o de pares clave-valor
+func (it *pyInterp) dictUpdate(d *pyDict, src pyValue) {
+	if m, ok := src.(*pyDict); ok && !m.set {
+		for _, e := range m.items() {
+			it.dictSet(d, e.key, e.value)
+		}
+		return
+	}
+	if inst, ok := src.(*pyInstance); ok {
+		if keys, ok := it.lookupAttr(inst, "keys"); ok {
+			for _, k := range it.toList(it.call(keys, nil, nil)) {
+				it.dictSet(d, k, it.getItem(inst, k))
+			}
+			return
+		}
+	}
+	i := 0
+	next := it.iter(src)
+	for {
+		item, ok := next()
+		if !ok {
+			return
+		}
+		pair := it.toList(item)
+		if len(pair) != 2 {
+			it.raise("ValueError", "dictionary update sequence element #%d has length %d; 2 is required", i, len(pair))
+		}
+		it.dictSet(d, pair[0], pair[1])
+		i++
+	}
+}
+
+// ───── Índices ─────
+
+func (it *pyInterp) getItem(obj, key pyValue) pyValue {
+	switch x := obj.(type) {
+	case *pyList:
+		if sl, ok := key.(*pySlice); ok {
+			return &pyList{items: it.sliceItems(x.items, sl), maxlen: -1}
+		}
+		return x.items[it.seqIndex(key, len(x.items), "list")]
+	case *pyTuple:
+		if sl, ok := key.(*pySlice); ok {
+			return &pyTuple{it.sliceItems(x.items, sl)}
+		}
+		return x.items[it.seqIndex(key, len(x.items), "tuple")]
+	case string:
+		return it.strItem(x, key)
+	case *pyDict:
+		if x.set {
+			break
+		}
+		if v, ok := it.dictGet(x, key); ok {
+			return v
+		}
+		switch x.kind {
+		case "defaultdict":
+			if x.factory != pyNone && x.factory != nil {
+				v := it.call(x.factory, nil, nil)
+				it.dictSet(x, key, v)
+				return v
+			}
+		case "Counter":
+			return int64(0)
+		}
+		it.raiseValue("KeyError", key)
+	case *pyRange:
+		items := it.toList(x)
+		if sl, ok := key.(*pySlice); ok {
+			start, _, step, count := it.sliceIndices(len(items), sl)
+			if count == 0 {
+				return &pyRange{0, 0, 1}
+			}
+			first := x.start + int64(start)*x.step
+			return &pyRange{first, first + int64(count)*x.step*int64(step), x.step * int64(step)}
+		}
+		return items[it.seqIndex(key, len(items), "range object")]
+	case *pyInstance:
+		if m, ok := it.lookupAttr(x, "__getitem__"); ok {
+			return it.call(m, []pyValue{key}, nil)
+		}
+	case *pyClass:
+		// Anotaciones como list[int]
+		return x
+	}
+	it.raise("TypeError", "'%s' object is not subscriptable", it.typeName(obj))
+	return nil
+}
+
+// seqIndex valida un índice de secuencia y lo vuelve positivo
+func (it *pyInterp) seqIndex(key pyValue, length int, kind string) int {
+	i, ok := pyIntArg(key)
+	if !ok {
+		if b, isBig := key.(*big.Int); isBig {
+			i = int64(b.Sign()) << 62
+		} else if inst, isInst := key.(*pyInstance); isInst {
+			if m, found := it.lookupAttr(inst, "__index__"); found {
+				return it.seqIndex(it.call(m, nil, nil), length, kind)
+			}
+		} else {
+			if kind == "str" {
+				it.raise("TypeError", "string indices must be integers, not '%s'", it.typeName(key))
+			}
+			it.raise("TypeError", "%s indices must be integers or slices, not %s", kind, it.typeName(key))
+		}
+	}
+	if i < 0 {
+		i += int64(length)
+	}
+	if i < 0 || i >= int64(length) {
+		if kind == "str" {
+			kind = "string"
+		}
+		it.raise("IndexError", "%s index out of range", kind)
+	}
+	return int(i)
+}
+
+func (it *pyInterp) strItem(s string, key pyValue) pyValue {
+	if pyIsASCII(s) {
+		if sl, ok := key.(*pySlice); ok {
+			start, _, step, count := it.sliceIndices(len(s), sl)
+			if step == 1 {
+				return s[start : start+count]
+			}
+			b := make([]byte, count)
+			for i := range b {
+				b[i] = s[start+i*step]
+			}
+			return string(b)
+		}
+		i := it.seqIndex(key, len(s), "str")
+		return s[i : i+1]
+	}
+	runes := it.runes(s)
+	if sl, ok := key.(*pySlice); ok {
+		start, _, step, count := it.sliceIndices(len(runes), sl)
+		out := make([]rune, count)
+		for i := range out {
+			out[i] = runes[start+i*step]
+		}
+		return string(out)
+	}
+	return string(runes[it.seqIndex(key, len(runes), "str")])
+}
+
+// runes decodifica una cadena no ASCII recordando la última
+func (it *pyInterp) runes(s string) []rune {
+	if s != it.lastStr || it.lastRunes == nil {
+		it.lastStr, it.lastRunes = s, []rune(s)
+	}
+	return it.lastRunes
+}
+
+func pyIsASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// pyLen es len() de una cadena: cuenta caracteres, no bytes
+func pyLen(s string) int {
+	if pyIsASCII(s) {
+		return len(s)
+	}
+	return utf8.RuneCountInString(s)
+}
+
+// sliceIndices calcula inicio, fin, paso y cantidad de elementos de un
+// corte sobre una secuencia de largo length, como PySlice_AdjustIndices
+func (it *pyInterp) sliceIndices(length int, sl *pySlice) (start, stop, step, count int) {
+	step = 1
+	if sl.step != pyNone {
+		step = it.sliceBound(sl.step)
+		if step == 0 {
+			it.raise("ValueError", "slice step cannot be zero")
+		}
+	}
+	adjust := func(v pyValue, def int) int {
+		if v == pyNone {
+			return def
+		}
+		i := it.sliceBound(v)
+		if i < 0 {
+			i += length
+			if i < 0 {
+				if step < 0 {
+					return -1
+				}
+				return 0
+			}
+		} else if i >= length {
+			if step < 0 {
+				return length - 1
+			}
+			return length
+		}
+		return i
+	}
+	if step > 0 {
+		start, stop = adjust(sl.start, 0), adjust(sl.stop, length)
+		if stop > start {
+			count = (stop-start-1)/step + 1
+		}
+	} else {
+		start, stop = adjust(sl.start, length-1), adjust(sl.stop, -1)
+		if start > stop {
+			count = (start-stop-1)/(-step) + 1
+		}
+	}
+	return start, stop, step, count
+}
+
+func (it *pyInterp) sliceBound(v pyValue) int {
+	if i, ok := pyIntArg(v); ok {
+		return int(max(min(i, math.MaxInt32), -math.MaxInt32))
+	}
+	if b, ok := v.(*big.Int); ok {
+		return b.Sign() * math.MaxInt32
+	}
+	if inst, ok := v.(*pyInstance); ok {
+		if m, found := it.lookupAttr(inst, "__index__"); found {
+			return it.sliceBound(it.call(m, nil, nil))
+		}
+	}
+	it.raise("TypeError", "slice indices must be integers or None or have an __index__ method")
+	return 0
+}
+
+func (it *pyInterp) sliceItems(items []pyValue, sl *pySlice) []pyValue {
+	start, _, step, count := it.sliceIndices(len(items), sl)
+	it.b.alloc(count * pyValueCost)
+	out := make([]pyValue, count)
+	for i := range out {
+		out[i] = items[start+i*step]
+	}
+	return out
+}
+
+func (it *pyInterp) setItem(obj, key, value pyValue) {
+	switch x := obj.(type) {
+	case *pyList:
+		if sl, ok := key.(*pySlice); ok {
+			it.setSlice(x, sl, value)
+			return
+		}
+		i, ok := pyIntArg(key)
+		if ok && (i >= int64(len(x.items)) || i < -int64(len(x.items))) {
+			it.raise("IndexError", "list assignment index out of range")
+		}
+		x.items[it.seqIndex(key, len(x.items), "list")] = value
+		return
+	case *pyDict:
+		if !x.set {
+			it.dictSet(x, key, value)
+			return
+		}
+	case *pyInstance:
+		if m, ok := it.lookupAttr(x, "__setitem__"); ok {
+			it.call(m, []pyValue{key, value}, nil)
+			return
+		}
+	}
+	it.raise("TypeError", "'%s' object does not support item assignment", it.typeName(obj))
+}
+
+func (it *pyInterp) setSlice(l *pyList, sl *pySlice, value pyValue) {
+	items := it.toList(value)
+	start, stop, step, count := it.sliceIndices(len(l.items), sl)
+	if step == 1 {
+		stop = max(stop, start)
+		it.b.alloc(len(items) * pyValueCost)
+		rest := append(append([]pyValue(nil), items...), l.items[stop:]...)
+		l.items = append(l.items[:start], rest...)
+		return
+	}
+	if len(items) != count {
+		it.raise("ValueError", "attempt to assign sequence of size %d to extended slice of size %d", len(items), count)
+	}
+	for i := range items {
+		l.items[start+i*step] = items[i]
+	}
+}
+
+func (it *pyInterp) delItem(obj, key pyValue) {
+	switch x := obj.(type) {
+	case *pyList:
+		if sl, ok := key.(*pySlice); ok {
+			start, _, step, count := it.sliceIndices(len(x.items), sl)
+			drop := make(map[int]bool, count)
+			for i := 0; i < count; i++ {
+				drop[start+i*step] = true
+			}
+			kept := x.items[:0]
+			for i, v := range x.items {
+				if !drop[i] {
+					kept = append(kept, v)
+				}
+			}
+			x.items = kept
+			return
+		}
+		i, ok := pyIntArg(key)
+		if ok && (i >= int64(len(x.items)) || i < -int64(len(x.items))) {
+			it.raise("IndexError", "list assignment index out of range")
+		}
+		idx := it.seqIndex(key, len(x.items), "list")
+		x.items = append(x.items[:idx], x.items[idx+1:]...)
+		return
+	case *pyDict:
+		if !x.set {
+			if _, ok := it.dictDelete(x, key); !ok {
+				it.raiseValue("KeyError", key)
+			}
+			return
+		}
+	case *pyInstance:
+		if m, ok := it.lookupAttr(x, "__delitem__"); ok {
+			it.call(m, []pyValue{key}, nil)
+			return
+		}
+	}
+	it.raise("TypeError", "'%s' object doesn't support item deletion", it.typeName(obj))
+}
+
+// ───── Iteración ─────
+
+// iterable dice si v se puede recorrer sin recorrerlo
+func (it *pyInterp) iterable(v pyValue) (pyValue, bool) {
+	switch x := v.(type) {
+	case *pyList, *pyTuple, string, *pyRange, *pyDict, *pyIterator, *pyView, *pyFile:
+		return v, true
+	case *pyInstance:
+		if m, ok := it.lookupAttr(x, "__iter__"); ok {
+			return m, true
+		}
+		if m, ok := it.lookupAttr(x, "__getitem__"); ok {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+// iter devuelve una función que entrega los elementos de v uno por vez
+func (it *pyInterp) iter(v pyValue) func() (pyValue, bool) {
+	switch x := v.(type) {
+	case *pyList:
+		i := 0
+		return func() (pyValue, bool) {
+			if i >= len(x.items) {
+				return nil, false
+			}
+			i++
+			return x.items[i-1], true
+		}
+	case *pyTuple:
+		i := 0
+		return func() (pyValue, bool) {
+			if i >= len(x.items) {
+				return nil, false
+			}
+			i++
+			return x.items[i-1], true
+		}
+	case string:
+		pos := 0
+		return func() (pyValue, bool) {
+			if pos >= len(x) {
+				return nil, false
+			}
+			_, size := utf8.DecodeRuneInString(x[pos:])
+			pos += size
+			return x[pos-size : pos], true
+		}
+	case *pyRange:
+		cur := x.start
+		return func() (pyValue, bool) {
+			if x.step > 0 && cur >= x.stop || x.step < 0 && cur <= x.stop {
+				return nil, false
+			}
+			cur += x.step
+			return cur - x.step, true
+		}
+	case *pyDict:
+		if x.set {
+			items, i, size := it.setItems(x), 0, x.live
+			return func() (pyValue, bool) {
+				if x.live != size {
+					it.raise("RuntimeError", "Set changed size during iteration")
+				}
+				if i >= len(items) {
+					return nil, false
+				}
+				i++
+				return items[i-1].key, true
+			}
+		}
+		return it.dictIter(x, func(e pyEntry) pyValue { return e.key })
+	case *pyView:
+		switch x.kind {
+		case "dict_values":
+			return it.dictIter(x.dict, func(e pyEntry) pyValue { return e.value })
+		case "dict_items":
+			return it.dictIter(x.dict, func(e pyEntry) pyValue { return &pyTuple{[]pyValue{e.key, e.value}} })
+		}
+		return it.dictIter(x.dict, func(e pyEntry) pyValue { return e.key })
+	case *pyIterator:
+		return x.next
+	case *pyFile:
+		return func() (pyValue, bool) {
+			line := it.fileReadLine(x)
+			return line, line != ""
+		}
+	case *pyInstance:
+		if m, ok := it.lookupAttr(x, "__iter__"); ok {
+			r := it.call(m, nil, nil)
+			if inst, ok := r.(*pyInstance); ok {
+				next, ok := it.lookupAttr(inst, "__next__")
+				if !ok {
+					it.raise("TypeError", "iter() returned non-iterator of type '%s'", it.typeName(r))
+				}
+				return func() (v pyValue, more bool) {
+					raised := it.catch(func() { v = it.call(next, nil, nil) })
+					if raised != nil {
+						if it.isInstance(raised.exc, it.types["StopIteration"]) {
+							return nil, false
+						}
+						panic(raised)
+					}
+					return v, true
+				}
+			}
+			return it.iter(r)
+		}
+		if m, ok := it.lookupAttr(x, "__getitem__"); ok {
+			i := int64(0)
+			return func() (v pyValue, more bool) {
+				raised := it.catch(func() { v = it.call(m, []pyValue{i}, nil) })
+				if raised != nil {
+					if it.isInstance(raised.exc, it.types["IndexError"]) || it.isInstance(raised.exc, it.types["StopIteration"]) {
+						return nil, false
+					}
+					panic(raised)
+				}
+				i++
+				return v, true
+			}
+		}
+	}
+	it.raise("TypeError", "'%s' object is not iterable", it.typeName(v))
+	return nil
+}
+
+// dictIter recorre un diccionario detectando cambios de tamaño como CPython
+func (it *pyInterp) dictIter(d *pyDict, get func(pyEntry) pyValue) func() (pyValue, bool) {
+	i, size := 0, d.live
+	return func() (pyValue, bool) {
+		if d.live != size {
+			it.raise("RuntimeError", "dictionary changed size during iteration")
+		}
+		for i < len(d.entries) && d.entries[i].deleted {
+			i++
+		}
+		if i >= len(d.entries) {
+			return nil, false
+		}
+		i++
+		return get(d.entries[i-1]), true
+	}
+}
+
+// toList devuelve los elementos de un iterable en un slice nuevo
+func (it *pyInterp) toList(v pyValue) []pyValue {
+	switch x := v.(type) {
+	case *pyList:
+		it.b.alloc(len(x.items) * pyValueCost)
+		return append([]pyValue(nil), x.items...)
+	case *pyTuple:
+		return x.items
+	case *pyRange:
+		n := pyRangeLen(x)
+		it.b.alloc(int(min(n, math.MaxInt32)) * pyValueCost)
+	}
+	var out []pyValue
+	next := it.iter(v)
+	for {
+		item, ok := next()
+		if !ok {
+			return out
+		}
+		it.b.alloc(pyValueCost)
+		out = append(out, item)
+	}
+}
+
+func pyRangeLen(r *pyRange) int64 {
+	if r.step > 0 && r.stop > r.start {
+		return (r.stop-r.start-1)/r.step + 1
+	}
+	if r.step < 0 && r.start > r.stop {
+		return (r.start-r.stop-1)/(-r.step) + 1
+	}
+	return 0
+}
+
+func (it *pyInterp) newIterator(kind string, items []pyValue) *pyIterator {
+	i := 0
+	return &pyIterator{kind: kind, next: func() (pyValue, bool) {
+		if i >= len(items) {
+			return nil, false
+		}
+		i++
+		return items[i-1], true
+	}}
+}
+
+// ───── Verdad y tipos ─────
+
+func (it *pyInterp) truthy(v pyValue) bool {
+	switch x := v.(type) {
+	case bool:
+		return x
+	case int64:
+		return x != 0
+	case float64:
+		return x != 0
+	case string:
+		return x != ""
+	case pyNoneType:
+		return false
+	case *big.Int:
+		return x.Sign() != 0
+	case *pyList:
+		return len(x.items) > 0
+	case *pyTuple:
+		return len(x.items) > 0
+	case *pyDict:
+		return x.live > 0
+	case *pyView:
+		return x.dict.live > 0
+	case *pyRange:
+		return pyRangeLen(x) > 0
+	case *pyInstance:
+		if m, ok := it.lookupAttr(x, "__bool__"); ok {
+			return it.truthy(it.call(m, nil, nil))
+		}
+		if m, ok := it.lookupAttr(x, "__len__"); ok {
+			return it.truthy(it.call(m, nil, nil))
+		}
+	}
+	return true
+}
+
+func (it *pyInterp) typeName(v pyValue) string { return it.typeOf(v).name }
+
+// typeOf devuelve la clase de un valor; los tipos predefinidos que no
+// tienen constructor se crean al pedirlos
+func (it *pyInterp) typeOf(v pyValue) *pyClass {
+	name := ""
+	switch x := v.(type) {
+	case *pyInstance:
+		return x.class
+	case pyNoneType:
+		name = "NoneType"
+	case bool:
+		name = "bool"
+	case int64, *big.Int:
+		name = "int"
+	case float64:
+		name = "float"
+	case string:
+		name = "str"
+	case *pyList:
+		name = "list"
+		if x.deque {
+			name = "deque"
+		}
+	case *pyTuple:
+		name = "tuple"
+	case *pyDict:
+		switch {
+		case x.kind != "":
+			name = x.kind
+		case x.set:
+			name = "set"
+		default:
+			name = "dict"
+		}
+	case *pyView:
+		name = x.kind
+	case *pyRange:
+		name = "range"
+	case *pySlice:
+		name = "slice"
+	case *pyFunction:
+		name = "function"
+		if x.native != nil {
+			name = "builtin_function_or_method"
+		}
+	case *pyBound:
+		name = "method"
+		if f, ok := x.fn.(*pyFunction); ok && f.native != nil {
+			name = "builtin_function_or_method"
+		}
+	case *pyClass:
+		name = "type"
+	case *pyModule:
+		name = "module"
+	case *pyIterator:
+		name = x.kind
+	case *pyFile:
+		name = "TextIOWrapper"
+	case *pyProperty:
+		name = "property"
+	case *pyStaticMethod:
+		name = "staticmethod"
+	case *pyClassMethod:
+		name = "classmethod"
+	case *pySuper:
+		name = "super"
+	case pyNotImplementedType:
+		name = "NotImplementedType"
+	case pyEllipsisType:
+		name = "ellipsis"
+	default:
+		name = fmt.Sprintf("%T", v)
+	}
+	return it.builtinType(name)
+}
+
+func (it *pyInterp) builtinType(name string) *pyClass {
+	if cls, ok := it.types[name]; ok {
+		return cls
+	}
+	object := it.types["object"]
+	cls := &pyClass{name: name, builtin: true, attrs: map[string]pyValue{}, bases: []*pyClass{object}}
+	cls.mro = []*pyClass{cls, object}
+	it.types[name] = cls
+	return cls
+}
@@ -0,0 +1,3622 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ──────────── Funciones y tipos predefinidos del intérprete Python ───────────
+//
+// Las funciones predefinidas, los tipos (int, str, list...) con sus métodos
+// y la jerarquía de excepciones. Los mensajes de error copian los de
+// CPython 3.11 porque los ejercicios y el análisis de errores de ejecución
+// los comparan textualmente. Los módulos importables están en
+// interp_python_modules.go.
+
+func pyBuiltin(name string, fn pyNative) *pyFunction {
+	return &pyFunction{name: name, qualname: name, native: fn}
+}
+
+// pyArg devuelve el argumento i o nil si no se pasó
+func pyArg(args []pyValue, i int) pyValue {
+	if i < len(args) {
+		return args[i]
+	}
+	return nil
+}
+
+// bindNative asigna los argumentos de una función predefinida a sus
+// parámetros: los primeros required son obligatorios y los que faltan
+// quedan en nil
+func (it *pyInterp) bindNative(name string, args []pyValue, kw []pyKeyword, required int, names ...string) []pyValue {
+	if len(args) > len(names) {
+		switch {
+		case len(names) == 0:
+			it.raise("TypeError", "%s() takes no arguments (%d given)", name, len(args))
+		case len(names) == 1 && required == 1:
+			it.raise("TypeError", "%s() takes exactly one argument (%d given)", name, len(args))
+		}
+		it.raise("TypeError", "%s expected at most %d argument%s, got %d", name, len(names), plural(len(names)), len(args))
+	}
+	vals := make([]pyValue, len(names))
+	copy(vals, args)
+	for _, k := range kw {
+		found := false
+		for i, n := range names {
+			if n == k.name {
+				if vals[i] != nil {
+					it.raise("TypeError", "argument for %s() given by name ('%s') and position (%d)", name, n, i+1)
+				}
+				vals[i], found = k.value, true
+			}
+		}
+		if !found {
+			it.raise("TypeError", "%s() got an unexpected keyword argument '%s'", name, k.name)
+		}
+	}
+	for i := 0; i < required; i++ {
+		if vals[i] == nil {
+			if len(names) == 1 {
+				it.raise("TypeError", "%s() takes exactly one argument (0 given)", name)
+			}
+			it.raise("TypeError", "%s expected at least %d argument%s, got %d", name, required, plural(required), len(args))
+		}
+	}
+	return vals
+}
+
+// intArg convierte un argumento que debe ser entero
+func (it *pyInterp) intArg(v pyValue) int64 {
+	if n, ok := pyIntArg(v); ok {
+		return n
+	}
+	switch x := v.(type) {
+	case *big.Int:
+		it.raise("OverflowError", "Python int too large to convert to C ssize_t")
+	case *pyInstance:
+		if m, ok := it.lookupAttr(x, "__index__"); ok {
+			return it.intArg(it.call(m, nil, nil))
+		}
+	}
+	it.raise("TypeError", "'%s' object cannot be interpreted as an integer", it.typeName(v))
+	return 0
+}
+
+// optInt devuelve el entero v o def si no se pasó o es None
+func (it *pyInterp) optInt(v pyValue, def int64) int64 {
+	if v == nil || v == pyNone {
+		return def
+	}
+	return it.intArg(v)
+}
+
+func (it *pyInterp) strArg(name string, v pyValue) string {
+	s, ok := v.(string)
+	if !ok {
+		it.raise("TypeError", "%s() argument must be str, not %s", name, it.typeName(v))
+	}
+	return s
+}
+
+// ───── Tipos y excepciones ─────
+
+func (it *pyInterp) installBuiltins() {
+	object := &pyClass{name: "object", builtin: true, attrs: map[string]pyValue{}}
+	object.mro = []*pyClass{object}
+	it.types["object"] = object
+	object.construct = func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		it.bindNative("object", args, kw, 0)
+		return &pyInstance{class: object, attrs: map[string]pyValue{}}
+	}
+	object.attrs["__init__"] = pyBuiltin("__init__", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue { return pyNone })
+	object.attrs["__new__"] = &pyStaticMethod{pyBuiltin("__new__", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		cls, ok := pyArg(args, 0).(*pyClass)
+		if !ok {
+			it.raise("TypeError", "object.__new__(X): X is not a type object")
+		}
+		it.b.alloc(pyObjectCost)
+		inst := &pyInstance{class: cls, attrs: map[string]pyValue{}}
+		if cls.exception {
+			inst.args = args[1:]
+		}
+		return inst
+	})}
+	object.attrs["__setattr__"] = pyBuiltin("__setattr__", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		v := it.bindNative("__setattr__", args, kw, 3, "self", "name", "value")
+		self, ok := v[0].(*pyInstance)
+		if !ok {
+			it.raise("TypeError", "can't apply this __setattr__ to %s object", it.typeName(v[0]))
+		}
+		it.setInstanceAttr(self, it.attrName("__setattr__", v[1]), v[2])
+		return pyNone
+	})
+	object.attrs["__repr__"] = pyBuiltin("__repr__", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		return it.defaultRepr(pyArg(args, 0))
+	})
+	object.attrs["__str__"] = pyBuiltin("__str__", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		return it.repr(pyArg(args, 0))
+	})
+
+	typ := func(name string, construct pyNative, base *pyClass) *pyClass {
+		cls := it.builtinType(name)
+		cls.construct = construct
+		if base != nil {
+			cls.bases = []*pyClass{base}
+			cls.mro = append([]*pyClass{cls}, base.mro...)
+		}
+		return cls
+	}
+	intType := typ("int", pyConstructInt, nil)
+	typ("bool", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		v := it.bindNative("bool", args, kw, 0, "x")
+		return v[0] != nil && it.truthy(v[0])
+	}, intType)
+	typ("float", pyConstructFloat, nil)
+	typ("str", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		v := it.bindNative("str", args, kw, 0, "object", "encoding", "errors")
+		if v[0] == nil {
+			return ""
+		}
+		return it.str(v[0])
+	}, nil)
+	typ("list", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		v := it.bindNative("list", args, nil, 0, "iterable")
+		if len(kw) > 0 {
+			it.raise("TypeError", "list() takes no keyword arguments")
+		}
+		if v[0] == nil {
+			return &pyList{items: []pyValue{}, maxlen: -1}
+		}
+		return &pyList{items: it.toList(v[0]), maxlen: -1}
+	}, nil)
+	typ("tuple", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		v := it.bindNative("tuple", args, kw, 0, "iterable")
+		if v[0] == nil {
+			return &pyTuple{}
+		}
+		if t, ok := v[0].(*pyTuple); ok {
+			return t
+		}
+		return &pyTuple{it.toList(v[0])}
+	}, nil)
+	typ("dict", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		v := it.bindNative("dict", args, nil, 0, "mapping")
+		d := newPyDict()
+		if v[0] != nil {
+			it.dictUpdate(d, v[0])
+		}
+		for _, k := range kw {
+			it.dictSet(d, k.name, k.value)
+		}
+		return d
+	}, nil)
+	typ("set", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		v := it.bindNative("set", args, kw, 0, "iterable")
+		return it.newSet(v[0], "")
+	}, nil)
+	typ("frozenset", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		v := it.bindNative("frozenset", args, kw, 0, "iterable")
+		return it.newSet(v[0], "frozenset")
+	}, nil)
+	typ("range", pyConstructRange, nil)
+	typ("slice", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		v := it.bindNative("slice", args, kw, 1, "start", "stop", "step")
+		for i := range v {
+			if v[i] == nil {
+				v[i] = pyNone
+			}
+		}
+		if len(args) == 1 {
+			return &pySlice{pyNone, v[0], pyNone}
+		}
+		return &pySlice{v[0], v[1], v[2]}
+	}, nil)
+	typ("type", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		if len(args) == 1 {
+			return it.typeOf(args[0])
+		}
+		v := it.bindNative("type", args, kw, 3, "name", "bases", "dict")
+		cls := &pyClass{name: it.strArg("type", v[0]), attrs: map[string]pyValue{}}
+		for _, b := range it.toList(v[1]) {
+			if base, ok := b.(*pyClass); ok {
+				cls.bases = append(cls.bases, base)
+			}
+		}
+		if len(cls.bases) == 0 {
+			cls.bases = []*pyClass{object}
+		}
+		cls.mro = it.linearize(cls)
+		for _, b := range cls.bases {
+			cls.exception = cls.exception || b.exception
+		}
+		if d, ok := v[2].(*pyDict); ok {
+			for _, e := range d.items() {
+				if k, ok := e.key.(string); ok {
+					cls.attrs[k] = e.value
+				}
+			}
+		}
+		return cls
+	}, nil)
+	typ("property", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		v := it.bindNative("property", args, kw, 0, "fget", "fset", "fdel", "doc")
+		return &pyProperty{get: v[0], set: v[1]}
+	}, nil)
+	typ("staticmethod", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		return &pyStaticMethod{it.bindNative("staticmethod", args, kw, 1, "function")[0]}
+	}, nil)
+	typ("classmethod", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		return &pyClassMethod{it.bindNative("classmethod", args, kw, 1, "function")[0]}
+	}, nil)
+	typ("super", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		v := it.bindNative("super", args, kw, 2, "type", "object")
+		cls, ok := v[0].(*pyClass)
+		if !ok {
+			it.raise("TypeError", "super() argument 1 must be a type, not %s", it.typeName(v[0]))
+		}
+		return &pySuper{class: cls, self: v[1]}
+	}, nil)
+
+	it.installExceptions()
+	it.builtins = map[string]pyValue{"__name__": "builtins", "None": pyNone, "NotImplemented": pyNotImplemented, "Ellipsis": pyEllipsis}
+	for name, cls := range it.types {
+		it.builtins[name] = cls
+	}
+	delete(it.builtins, "NoneType")
+	it.builtins["IOError"] = it.types["OSError"]
+	it.builtins["EnvironmentError"] = it.types["OSError"]
+	for name, fn := range pyBuiltinFuncs {
+		it.builtins[name] = pyBuiltin(name, fn)
+	}
+	it.builtins["exit"] = it.builtins["quit"]
+}
+
+// Jerarquía de excepciones predefinidas: nombre y clase base
+var pyExceptionTree = [][2]string{
+	{"BaseException", ""},
+	{"SystemExit", "BaseException"},
+	{"KeyboardInterrupt", "BaseException"},
+	{"GeneratorExit", "BaseException"},
+	{"Exception", "BaseException"},
+	{"StopIteration", "Exception"},
+	{"ArithmeticError", "Exception"},
+	{"FloatingPointError", "ArithmeticError"},
+	{"OverflowError", "ArithmeticError"},
+	{"ZeroDivisionError", "ArithmeticError"},
+	{"AssertionError", "Exception"},
+	{"AttributeError", "Exception"},
+	{"BufferError", "Exception"},
+	{"EOFError", "Exception"},
+	{"ImportError", "Exception"},
+	{"ModuleNotFoundError", "ImportError"},
+	{"LookupError", "Exception"},
+	{"IndexError", "LookupError"},
+	{"KeyError", "LookupError"},
+	{"MemoryError", "Exception"},
+	{"NameError", "Exception"},
+	{"UnboundLocalError", "NameError"},
+	{"OSError", "Exception"},
+	{"FileNotFoundError", "OSError"},
+	{"FileExistsError", "OSError"},
+	{"PermissionError", "OSError"},
+	{"IsADirectoryError", "OSError"},
+	{"UnsupportedOperation", "OSError"},
+	{"RuntimeError", "Exception"},
+	{"NotImplementedError", "RuntimeError"},
+	{"RecursionError", "RuntimeError"},
+	{"SyntaxError", "Exception"},
+	{"IndentationError", "SyntaxError"},
+	{"TypeError", "Exception"},
+	{"ValueError", "Exception"},
+	{"UnicodeError", "ValueError"},
+	{"Warning", "Exception"},
+	{"UserWarning", "Warning"},
+	{"DeprecationWarning", "Warning"},
+	{"RuntimeWarning", "Warning"},
+}
+
+func (it *pyInterp) installExceptions() {
+	object := it.types["object"]
+	for _, e := range pyExceptionTree {
+		base := object
+		if e[1] != "" {
+			base = it.types[e[1]]
+		}
+		cls := &pyClass{name: e[0], builtin: true, exception: true, attrs: map[string]pyValue{}, bases: []*pyClass{base}}
+		cls.mro = append([]*pyClass{cls}, base.mro...)
+		it.types[e[0]] = cls
+	}
+	base := it.types["BaseException"].attrs
+	base["__init__"] = pyBuiltin("__init__", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		if self, ok := pyArg(args, 0).(*pyInstance); ok {
+			if len(kw) > 0 {
+				it.raise("TypeError", "%s() takes no keyword arguments", self.class.name)
+			}
+			self.args = append([]pyValue(nil), args[1:]...)
+		}
+		return pyNone
+	})
+	base["__str__"] = pyBuiltin("__str__", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		self := args[0].(*pyInstance)
+		switch len(self.args) {
+		case 0:
+			return ""
+		case 1:
+			if it.isInstance(self, it.types["KeyError"]) {
+				return it.repr(self.args[0])
+			}
+			return it.str(self.args[0])
+		}
+		return it.repr(&pyTuple{self.args})
+	})
+	base["__repr__"] = pyBuiltin("__repr__", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		self := args[0].(*pyInstance)
+		if len(self.args) == 1 {
+			return self.class.name + "(" + it.repr(self.args[0]) + ")"
+		}
+		return self.class.name + it.repr(&pyTuple{self.args})
+	})
+	base["with_traceback"] = pyBuiltin("with_traceback", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		return args[0]
+	})
+}
+
+// ───── Funciones predefinidas ─────
+
+var pyBuiltinFuncs map[string]pyNative
+
+func init() {
+	pyBuiltinFuncs = map[string]pyNative{
+		"print": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			sep, end := " ", "\n"
+			var file pyValue
+			for _, k := range kw {
+				switch k.name {
+				case "sep", "end":
+					if k.value == pyNone {
+						continue
+					}
+					s, ok := k.value.(string)
+					if !ok {
+						it.raise("TypeError", "%s must be None or a string, not %s", k.name, it.typeName(k.value))
+					}
+					if k.name == "sep" {
+						sep = s
+					} else {
+						end = s
+					}
+				case "file":
+					file = k.value
+				case "flush":
+				default:
+					it.raise("TypeError", "'%s' is an invalid keyword argument for print()", k.name)
+				}
+			}
+			var sb strings.Builder
+			for i, a := range args {
+				if i > 0 {
+					sb.WriteString(sep)
+				}
+				sb.WriteString(it.str(a))
+			}
+			sb.WriteString(end)
+			it.writeTo(file, sb.String())
+			return pyNone
+		},
+		"input": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative("input", args, kw, 0, "prompt")
+			if v[0] != nil {
+				it.b.write(false, it.str(v[0]))
+			}
+			line, ok := it.readStdinLine()
+			if !ok {
+				it.raise("EOFError", "EOF when reading a line")
+			}
+			return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+		},
+		"len": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			return it.length(it.bindNative("len", args, kw, 1, "obj")[0])
+		},
+		"repr": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			return it.repr(it.bindNative("repr", args, kw, 1, "obj")[0])
+		},
+		"ascii": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			return pyASCII(it.repr(it.bindNative("ascii", args, kw, 1, "obj")[0]))
+		},
+		"format": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative("format", args, kw, 1, "value", "format_spec")
+			spec := ""
+			if v[1] != nil {
+				spec = it.strArg("format", v[1])
+			}
+			return it.format(v[0], spec)
+		},
+		"abs": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative("abs", args, kw, 1, "x")[0]
+			switch x := v.(type) {
+			case int64, bool, *big.Int:
+				if it.numCompare(pyBig(x), int64(0)) < 0 {
+					return it.unary("-", x)
+				}
+				n, _ := pyNumber(x)
+				return n
+			case float64:
+				return math.Abs(x)
+			case *pyInstance:
+				if m, ok := it.lookupAttr(x, "__abs__"); ok {
+					return it.call(m, nil, nil)
+				}
+			}
+			it.raise("TypeError", "bad operand type for abs(): '%s'", it.typeName(v))
+			return nil
+		},
+		"min": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue { return it.minMax("min", args, kw) },
+		"max": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue { return it.minMax("max", args, kw) },
+		"sum": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative("sum", args, kw, 1, "iterable", "start")
+			var total pyValue = int64(0)
+			if v[1] != nil {
+				total = v[1]
+			}
+			switch total.(type) {
+			case string:
+				it.raise("TypeError", "sum() can't sum strings [use ''.join(seq) instead]")
+			}
+			next := it.iter(v[0])
+			for {
+				item, ok := next()
+				if !ok {
+					return total
+				}
+				it.b.step()
+				total = it.binary("+", total, item)
+			}
+		},
+		"sorted": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			if len(args) != 1 {
+				it.raise("TypeError", "sorted expected 1 argument, got %d", len(args))
+			}
+			v := it.bindNative("sorted", nil, kw, 0, "key", "reverse")
+			items := it.toList(args[0])
+			if _, ok := args[0].(*pyTuple); ok {
+				items = append([]pyValue(nil), items...)
+			}
+			it.sortValues(items, v[0], v[1] != nil && it.truthy(v[1]))
+			return &pyList{items: items, maxlen: -1}
+		},
+		"reversed": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative("reversed", args, kw, 1, "sequence")[0]
+			var items []pyValue
+			switch x := v.(type) {
+			case *pyList, *pyTuple, string, *pyRange:
+				items = it.toList(x)
+			case *pyDict:
+				if x.set {
+					it.raise("TypeError", "'set' object is not reversible")
+				}
+				items = it.toList(x)
+			case *pyView:
+				items = it.toList(x)
+			case *pyInstance:
+				if m, ok := it.lookupAttr(x, "__reversed__"); ok {
+					return it.call(m, nil, nil)
+				}
+				if _, ok := it.lookupAttr(x, "__len__"); ok {
+					items = it.toList(x)
+				}
+			}
+			if items == nil && it.typeName(v) != "list" {
+				if _, ok := v.(*pyInstance); !ok {
+					it.raise("TypeError", "'%s' object is not reversible", it.typeName(v))
+				}
+			}
+			rev := make([]pyValue, len(items))
+			for i, item := range items {
+				rev[len(items)-1-i] = item
+			}
+			kind := "reversed"
+			if _, ok := v.(*pyList); ok {
+				kind = "list_reverseiterator"
+			}
+			return it.newIterator(kind, rev)
+		},
+		"enumerate": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative("enumerate", args, kw, 1, "iterable", "start")
+			var i pyValue = int64(0)
+			if v[1] != nil {
+				i = v[1]
+			}
+			next := it.iter(v[0])
+			return &pyIterator{kind: "enumerate", next: func() (pyValue, bool) {
+				item, ok := next()
+				if !ok {
+					return nil, false
+				}
+				res := &pyTuple{[]pyValue{i, item}}
+				i = it.binary("+", i, int64(1))
+				return res, true
+			}}
+		},
+		"zip": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative("zip", nil, kw, 0, "strict")
+			strict := v[0] != nil && it.truthy(v[0])
+			nexts := make([]func() (pyValue, bool), len(args))
+			for i, a := range args {
+				nexts[i] = it.iter(a)
+			}
+			done := false
+			return &pyIterator{kind: "zip", next: func() (pyValue, bool) {
+				if done || len(nexts) == 0 {
+					return nil, false
+				}
+				items := make([]pyValue, len(nexts))
+				for i, next := range nexts {
+					item, ok := next()
+					if !ok {
+						done = true
+						if strict && i > 0 {
+							it.raise("ValueError", "zip() argument %d is shorter than argument%s", i+1, map[bool]string{true: " 1", false: "s 1-" + strconv.Itoa(i)}[i == 1])
+						}
+						if strict && i == 0 {
+							for j := 1; j < len(nexts); j++ {
+								if _, ok := nexts[j](); ok {
+									it.raise("ValueError", "zip() argument %d is longer than argument%s", j+1, map[bool]string{true: " 1", false: "s 1-" + strconv.Itoa(j)}[j == 1])
+								}
+							}
+						}
+						return nil, false
+					}
+					items[i] = item
+				}
+				it.b.alloc(len(items) * pyValueCost)
+				return &pyTuple{items}, true
+			}}
+		},
+		"map": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			if len(args) < 2 {
+				it.raise("TypeError", "map() must have at least two arguments.")
+			}
+			fn := args[0]
+			nexts := make([]func() (pyValue, bool), len(args)-1)
+			for i, a := range args[1:] {
+				nexts[i] = it.iter(a)
+			}
+			return &pyIterator{kind: "map", next: func() (pyValue, bool) {
+				items := make([]pyValue, len(nexts))
+				for i, next := range nexts {
+					item, ok := next()
+					if !ok {
+						return nil, false
+					}
+					items[i] = item
+				}
+				return it.call(fn, items, nil), true
+			}}
+		},
+		"filter": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative("filter", args, kw, 2, "function", "iterable")
+			fn, next := v[0], it.iter(v[1])
+			return &pyIterator{kind: "filter", next: func() (pyValue, bool) {
+				for {
+					item, ok := next()
+					if !ok {
+						return nil, false
+					}
+					it.b.step()
+					keep := item
+					if fn != pyNone {
+						keep = it.call(fn, []pyValue{item}, nil)
+					}
+					if it.truthy(keep) {
+						return item, true
+					}
+				}
+			}}
+		},
+		"any": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			next := it.iter(it.bindNative("any", args, kw, 1, "iterable")[0])
+			for {
+				item, ok := next()
+				if !ok {
+					return false
+				}
+				if it.truthy(item) {
+					return true
+				}
+			}
+		},
+		"all": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			next := it.iter(it.bindNative("all", args, kw, 1, "iterable")[0])
+			for {
+				item, ok := next()
+				if !ok {
+					return true
+				}
+				if !it.truthy(item) {
+					return false
+				}
+			}
+		},
+		"round": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative("round", args, kw, 1, "number", "ndigits")
+			return it.round(v[0], v[1])
+		},
+		"pow": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative("pow", args, kw, 2, "base", "exp", "mod")
+			if v[2] == nil || v[2] == pyNone {
+				return it.binary("**", v[0], v[1])
+			}
+			base, exp, mod := pyBig(v[0]), pyBig(v[1]), pyBig(v[2])
+			if base == nil || exp == nil || mod == nil {
+				it.raise("TypeError", "pow() 3rd argument not allowed unless all arguments are integers")
+			}
+			if mod.Sign() == 0 {
+				it.raise("ValueError", "pow() 3rd argument cannot be 0")
+			}
+			if exp.Sign() < 0 {
+				inv := new(big.Int).ModInverse(new(big.Int).Mod(base, new(big.Int).Abs(mod)), new(big.Int).Abs(mod))
+				if inv == nil {
+					it.raise("ValueError", "base is not invertible for the given modulus")
+				}
+				base, exp = inv, new(big.Int).Neg(exp)
+			}
+			r := new(big.Int).Exp(base, exp, new(big.Int).Abs(mod))
+			if mod.Sign() < 0 && r.Sign() != 0 {
+				r.Add(r, mod)
+			}
+			return pyNormInt(r)
+		},
+		"divmod": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative("divmod", args, kw, 2, "x", "y")
+			return &pyTuple{[]pyValue{it.binary("//", v[0], v[1]), it.binary("%", v[0], v[1])}}
+		},
+		"isinstance": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative("isinstance", args, kw, 2, "obj", "class_or_tuple")
+			return it.classMatch("isinstance", it.typeOf(v[0]), v[1])
+		},
+		"issubclass": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative("issubclass", args, kw, 2, "cls", "class_or_tuple")
+			cls, ok := v[0].(*pyClass)
+			if !ok {
+				it.raise("TypeError", "issubclass() arg 1 must be a class")
+			}
+			return it.classMatch("issubclass", cls, v[1])
+		},
+		"callable": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			switch x := it.bindNative("callable", args, kw, 1, "obj")[0].(type) {
+			case *pyFunction, *pyBound, *pyClass:
+				return true
+			case *pyInstance:
+				_, ok := it.classAttr(x.class, "__call__")
+				return ok
+			}
+			return false
+		},
+		"ord": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative("ord", args, kw, 1, "c")[0]
+			s, ok := v.(string)
+			if !ok {
+				it.raise("TypeError", "ord() expected string of length 1, but %s found", it.typeName(v))
+			}
+			if n := pyLen(s); n != 1 {
+				it.raise("TypeError", "ord() expected a character, but string of length %d found", n)
+			}
+			r, _ := utf8.DecodeRuneInString(s)
+			return int64(r)
+		},
+		"chr": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			n := it.intArg(it.bindNative("chr", args, kw, 1, "i")[0])
+			if n < 0 || n > unicode.MaxRune {
+				it.raise("ValueError", "chr() arg not in range(0x110000)")
+			}
+			return string(rune(n))
+		},
+		"hex": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			return it.intBase(it.bindNative("hex", args, kw, 1, "number")[0], 16, "0x")
+		},
+		"oct": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			return it.intBase(it.bindNative("oct", args, kw, 1, "number")[0], 8, "0o")
+		},
+		"bin": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			return it.intBase(it.bindNative("bin", args, kw, 1, "number")[0], 2, "0b")
+		},
+		"hash": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			return it.hash(it.bindNative("hash", args, kw, 1, "obj")[0])
+		},
+		"id": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			return it.id(it.bindNative("id", args, kw, 1, "obj")[0])
+		},
+		"iter": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative("iter", args, kw, 1, "object", "sentinel")
+			if v[1] != nil {
+				fn, sentinel := v[0], v[1]
+				return &pyIterator{kind: "callable_iterator", next: func() (pyValue, bool) {
+					r := it.call(fn, nil, nil)
+					return r, !it.equal(r, sentinel)
+				}}
+			}
+			return it.getIter(v[0])
+		},
+		"next": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative("next", args, kw, 1, "iterator", "default")
+			switch x := v[0].(type) {
+			case *pyIterator:
+				if item, ok := x.next(); ok {
+					return item
+				}
+			case *pyInstance:
+				m, ok := it.lookupAttr(x, "__next__")
+				if !ok {
+					break
+				}
+				if v[1] == nil {
+					return it.call(m, nil, nil)
+				}
+				var r pyValue
+				raised := it.catch(func() { r = it.call(m, nil, nil) })
+				if raised == nil {
+					return r
+				}
+				if !it.isInstance(raised.exc, it.types["StopIteration"]) {
+					panic(raised)
+				}
+			default:
+				it.raise("TypeError", "'%s' object is not an iterator", it.typeName(v[0]))
+			}
+			if v[1] != nil {
+				return v[1]
+			}
+			it.raiseValue("StopIteration")
+			return nil
+		},
+		"getattr": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative("getattr", args, kw, 2, "object", "name", "default")
+			name := it.attrName("getattr", v[1])
+			if v[2] == nil {
+				return it.getAttr(v[0], name)
+			}
+			var r pyValue
+			raised := it.catch(func() { r = it.getAttr(v[0], name) })
+			if raised != nil {
+				if !it.isInstance(raised.exc, it.types["AttributeError"]) {
+					panic(raised)
+				}
+				return v[2]
+			}
+			return r
+		},
+		"setattr": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative("setattr", args, kw, 3, "obj", "name", "value")
+			it.setAttr(v[0], it.attrName("setattr", v[1]), v[2])
+			return pyNone
+		},
+		"hasattr": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative("hasattr", args, kw, 2, "obj", "name")
+			name := it.attrName("hasattr", v[1])
+			found := false
+			raised := it.catch(func() { _, found = it.lookupAttr(v[0], name) })
+			if raised != nil && !it.isInstance(raised.exc, it.types["AttributeError"]) {
+				panic(raised)
+			}
+			return found
+		},
+		"delattr": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative("delattr", args, kw, 2, "obj", "name")
+			it.delAttr(v[0], it.attrName("delattr", v[1]))
+			return pyNone
+		},
+		"vars": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative("vars", args, kw, 1, "object")[0]
+			if d, ok := it.lookupAttr(v, "__dict__"); ok {
+				return d
+			}
+			it.raise("TypeError", "vars() argument must have __dict__ attribute")
+			return nil
+		},
+		"dir": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative("dir", args, kw, 0, "object")[0]
+			names := map[string]bool{}
+			switch x := v.(type) {
+			case nil:
+				for k := range it.globals.vars {
+					names[k] = true
+				}
+			case *pyInstance:
+				for k := range x.attrs {
+					names[k] = true
+				}
+				for _, c := range x.class.mro {
+					for k := range c.attrs {
+						names[k] = true
+					}
+				}
+			case *pyClass:
+				for _, c := range x.mro {
+					for k := range c.attrs {
+						names[k] = true
+					}
+				}
+			case *pyModule:
+				for k := range x.attrs {
+					names[k] = true
+				}
+			}
+			list := make([]string, 0, len(names))
+			for k := range names {
+				list = append(list, k)
+			}
+			sort.Strings(list)
+			items := make([]pyValue, len(list))
+			for i, k := range list {
+				items[i] = k
+			}
+			return &pyList{items: items, maxlen: -1}
+		},
+		"globals": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			it.bindNative("globals", args, kw, 0)
+			d := newPyDict()
+			for k, v := range it.globals.vars {
+				it.dictSet(d, k, v)
+			}
+			return d
+		},
+		"quit": func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative("exit", args, kw, 0, "code")
+			if v[0] == nil {
+				it.raiseValue("SystemExit")
+			}
+			it.raiseValue("SystemExit", v[0])
+			return nil
+		},
+	}
+}
+
+// classMatch implementa isinstance e issubclass
+func (it *pyInterp) classMatch(name string, cls *pyClass, target pyValue) bool {
+	switch t := target.(type) {
+	case *pyClass:
+		for _, c := range cls.mro {
+			if c == t {
+				return true
+			}
+		}
+		return false
+	case *pyTuple:
+		for _, c := range t.items {
+			if it.classMatch(name, cls, c) {
+				return true
+			}
+		}
+		return false
+	}
+	it.raise("TypeError", "%s() arg 2 must be a type, a tuple of types, or a union", name)
+	return false
+}
+
+func (it *pyInterp) attrName(fn string, v pyValue) string {
+	s, ok := v.(string)
+	if !ok {
+		it.raise("TypeError", "attribute name must be string, not '%s'", it.typeName(v))
+	}
+	return s
+}
+
+func (it *pyInterp) length(v pyValue) int64 {
+	switch x := v.(type) {
+	case string:
+		return int64(pyLen(x))
+	case *pyList:
+		return int64(len(x.items))
+	case *pyTuple:
+		return int64(len(x.items))
+	case *pyDict:
+		return int64(x.live)
+	case *pyView:
+		return int64(x.dict.live)
+	case *pyRange:
+		return pyRangeLen(x)
+	case *pyInstance:
+		if m, ok := it.lookupAttr(x, "__len__"); ok {
+			n, ok := pyIntArg(it.call(m, nil, nil))
+			if !ok {
+				it.raise("TypeError", "'%s' object cannot be interpreted as an integer", it.typeName(v))
+			}
+			if n < 0 {
+				it.raise("ValueError", "__len__() should return >= 0")
+			}
+			return n
+		}
+	}
+	it.raise("TypeError", "object of type '%s' has no len()", it.typeName(v))
+	return 0
+}
+
+// getIter implementa iter(x): los iteradores se devuelven tal cual
+func (it *pyInterp) getIter(v pyValue) pyValue {
+	switch x := v.(type) {
+	case *pyIterator:
+		return x
+	case *pyInstance:
+		if m, ok := it.lookupAttr(x, "__iter__"); ok {
+			return it.call(m, nil, nil)
+		}
+	}
+	return &pyIterator{kind: it.typeName(v) + "_iterator", next: it.iter(v)}
+}
+
+func (it *pyInterp) minMax(name string, args []pyValue, kw []pyKeyword) pyValue {
+	v := it.bindNative(name, nil, kw, 0, "key", "default")
+	key, def := v[0], v[1]
+	var items []pyValue
+	switch len(args) {
+	case 0:
+		it.raise("TypeError", "%s expected at least 1 argument, got 0", name)
+	case 1:
+		items = it.toList(args[0])
+		if len(items) == 0 {
+			if def != nil {
+				return def
+			}
+			it.raise("ValueError", "%s() arg is an empty sequence", name)
+		}
+	default:
+		if def != nil {
+			it.raise("TypeError", "Cannot specify a default for %s() with multiple positional arguments", name)
+		}
+		items = args
+	}
+	best := items[0]
+	bestKey := best
+	if key != nil && key != pyNone {
+		bestKey = it.call(key, []pyValue{best}, nil)
+	}
+	for _, item := range items[1:] {
+		it.b.step()
+		k := item
+		if key != nil && key != pyNone {
+			k = it.call(key, []pyValue{item}, nil)
+		}
+		if name == "min" && it.less(k, bestKey) || name == "max" && it.less(bestKey, k) {
+			best, bestKey = item, k
+		}
+	}
+	return best
+}
+
+// sortValues ordena items de forma estable como list.sort
+func (it *pyInterp) sortValues(items []pyValue, key pyValue, reverse bool) {
+	keys := items
+	if key != nil && key != pyNone {
+		keys = make([]pyValue, len(items))
+		for i, item := range items {
+			keys[i] = it.call(key, []pyValue{item}, nil)
+		}
+	}
+	less := it.less
+	switch pyUniformKind(keys) {
+	case "int":
+		less = func(a, b pyValue) bool { return a.(int64) < b.(int64) }
+	case "str":
+		less = func(a, b pyValue) bool { return a.(string) < b.(string) }
+	case "float":
+		less = func(a, b pyValue) bool { return a.(float64) < b.(float64) }
+	}
+	idx := make([]int, len(items))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		it.b.step()
+		if reverse {
+			return less(keys[idx[b]], keys[idx[a]])
+		}
+		return less(keys[idx[a]], keys[idx[b]])
+	})
+	sorted := make([]pyValue, len(items))
+	for i, j := range idx {
+		sorted[i] = items[j]
+	}
+	copy(items, sorted)
+}
+
+// pyUniformKind dice si todos los valores son int64, string o float64
+func pyUniformKind(values []pyValue) string {
+	kind := ""
+	for _, v := range values {
+		k := ""
+		switch v.(type) {
+		case int64:
+			k = "int"
+		case string:
+			k = "str"
+		case float64:
+			if math.IsNaN(v.(float64)) {
+				return ""
+			}
+			k = "float"
+		default:
+			return ""
+		}
+		if kind != "" && k != kind {
+			return ""
+		}
+		kind = k
+	}
+	return kind
+}
+
+func (it *pyInterp) round(v, ndigits pyValue) pyValue {
+	if inst, ok := v.(*pyInstance); ok {
+		if m, ok := it.lookupAttr(inst, "__round__"); ok {
+			if ndigits == nil {
+				return it.call(m, nil, nil)
+			}
+			return it.call(m, []pyValue{ndigits}, nil)
+		}
+	}
+	n, ok := pyNumber(v)
+	if !ok {
+		it.raise("TypeError", "type %s doesn't define __round__ method", it.typeName(v))
+	}
+	if f, isFloat := n.(float64); isFloat {
+		if ndigits == nil || ndigits == pyNone {
+			if math.IsInf(f, 0) {
+				it.raise("OverflowError", "cannot convert float infinity to integer")
+			}
+			if math.IsNaN(f) {
+				it.raise("ValueError", "cannot convert float NaN to integer")
+			}
+			return pyFloatToInt(math.RoundToEven(f))
+		}
+		d := it.intArg(ndigits)
+		if math.IsInf(f, 0) || math.IsNaN(f) || d > 308 {
+			return f
+		}
+		if d < -308 {
+			return math.Copysign(0, f)
+		}
+		if d >= 0 {
+			r, _ := strconv.ParseFloat(strconv.FormatFloat(f, 'f', int(d), 64), 64)
+			return r
+		}
+		// Redondeo a decenas, centenas...: se redondea el cociente exacto
+		scale := new(big.Float).SetPrec(2000).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(-d), nil))
+		q := new(big.Float).SetPrec(2000).Quo(new(big.Float).SetPrec(2000).SetFloat64(f), scale)
+		qs := q.Text('f', 0)
+		r, _ := new(big.Float).SetPrec(2000).SetString(qs)
+		r.Mul(r, scale)
+		out, _ := r.Float64()
+		return out
+	}
+	if ndigits == nil || ndigits == pyNone {
+		return n
+	}
+	d := it.intArg(ndigits)
+	if d >= 0 {
+		return n
+	}
+	// round(1250, -2) == 1200: mitad hacia el par
+	x := pyBig(n)
+	p := new(big.Int).Exp(big.NewInt(10), big.NewInt(-d), nil)
+	q, r := new(big.Int).DivMod(x, p, new(big.Int))
+	twice := new(big.Int).Mul(r, big.NewInt(2))
+	if c := twice.Cmp(p); c > 0 || c == 0 && q.Bit(0) == 1 {
+		q.Add(q, big.NewInt(1))
+	}
+	return pyNormInt(q.Mul(q, p))
+}
+
+// pyFloatToInt convierte un flotante entero a int
+func pyFloatToInt(f float64) pyValue {
+	if f >= -(1<<63) && f < 1<<63 {
+		return int64(f)
+	}
+	n, _ := new(big.Float).SetFloat64(f).Int(nil)
+	return n
+}
+
+func (it *pyInterp) intBase(v pyValue, base int, prefix string) pyValue {
+	n := pyBig(v)
+	if n == nil {
+		if inst, ok := v.(*pyInstance); ok {
+			if m, ok := it.lookupAttr(inst, "__index__"); ok {
+				return it.intBase(it.call(m, nil, nil), base, prefix)
+			}
+		}
+		it.raise("TypeError", "'%s' object cannot be interpreted as an integer", it.typeName(v))
+	}
+	if n.Sign() < 0 {
+		return "-" + prefix + new(big.Int).Neg(n).Text(base)
+	}
+	return prefix + n.Text(base)
+}
+
+// hash imita hash(): los enteros dan el mismo valor que en CPython
+func (it *pyInterp) hash(v pyValue) pyValue {
+	const modulus = 1<<61 - 1
+	switch x := it.hashKey(v).(type) {
+	case int64:
+		h := x % modulus
+		if x < 0 {
+			h = -((-x) % modulus)
+		}
+		if h == -1 {
+			h = -2
+		}
+		return h
+	case pyBigKey:
+		n, _ := new(big.Int).SetString(string(x), 10)
+		m := new(big.Int).Rem(new(big.Int).Abs(n), big.NewInt(modulus)).Int64()
+		if n.Sign() < 0 {
+			m = -m
+		}
+		if m == -1 {
+			m = -2
+		}
+		return m
+	case pyInstanceKey:
+		return int64(x)
+	case *pyInstance:
+		return int64(it.id(x).(int64) / 16)
+	default:
+		var h uint64 = 14695981039346656037
+		for _, c := range fmt.Sprintf("%T%v", x, x) {
+			h = (h ^ uint64(c)) * 1099511628211
+		}
+		return int64(h >> 1)
+	}
+}
+
+// id devuelve un identificador estable para los objetos mutables
+func (it *pyInterp) id(v pyValue) pyValue {
+	switch v.(type) {
+	case int64, float64, string, bool, pyNoneType, *big.Int:
+		return it.hash(v).(int64)&0xffffffff*16 + 0x7f0000000000
+	}
+	n, ok := it.ids[v]
+	if !ok {
+		n = len(it.ids) + 1
+		it.ids[v] = n
+	}
+	return int64(0x7f3a1c000000 + n*48)
+}
+
+// ───── Conversiones ─────
+
+func pyConstructInt(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+	v := it.bindNative("int", args, kw, 0, "x", "base")
+	if v[0] == nil {
+		return int64(0)
+	}
+	if v[1] != nil {
+		s, ok := v[0].(string)
+		if !ok {
+			it.raise("TypeError", "int() can't convert non-string with explicit base")
+		}
+		base := it.intArg(v[1])
+		if base != 0 && (base < 2 || base > 36) {
+			it.raise("ValueError", "int() base must be >= 2 and <= 36, or 0")
+		}
+		return it.parseInt(s, int(base))
+	}
+	switch x := v[0].(type) {
+	case int64, *big.Int:
+		return x
+	case bool:
+		n, _ := pyIntArg(x)
+		return n
+	case float64:
+		if math.IsInf(x, 0) {
+			it.raise("OverflowError", "cannot convert float infinity to integer")
+		}
+		if math.IsNaN(x) {
+			it.raise("ValueError", "cannot convert float NaN to integer")
+		}
+		return pyFloatToInt(math.Trunc(x))
+	case string:
+		return it.parseInt(x, 10)
+	case *pyInstance:
+		for _, m := range []string{"__int__", "__index__", "__trunc__"} {
+			if f, ok := it.lookupAttr(x, m); ok {
+				return it.call(f, nil, nil)
+			}
+		}
+	}
+	it.raise("TypeError", "int() argument must be a string, a bytes-like object or a real number, not '%s'", it.typeName(v[0]))
+	return nil
+}
+
+// parseInt convierte una cadena a int como int(s, base)
+func (it *pyInterp) parseInt(s string, base int) pyValue {
+	invalid := func() {
+		it.raise("ValueError", "invalid literal for int() with base %d: %s", base, it.repr(s))
+	}
+	t := strings.TrimFunc(s, unicode.IsSpace)
+	neg := false
+	if t != "" && (t[0] == '+' || t[0] == '-') {
+		neg = t[0] == '-'
+		t = t[1:]
+	}
+	lower := strings.ToLower(t)
+	prefixes := map[int]string{16: "0x", 8: "0o", 2: "0b"}
+	if base == 0 {
+		base = 10
+		for b, p := range prefixes {
+			if strings.HasPrefix(lower, p) {
+				base = b
+			}
+		}
+		if base == 10 && len(t) > 1 && t[0] == '0' && strings.Trim(t, "0_") != "" {
+			base = 0
+			invalid()
+		}
+	}
+	if p, ok := prefixes[base]; ok && strings.HasPrefix(lower, p) {
+		t = t[2:]
+		if strings.HasPrefix(t, "_") {
+			t = t[1:]
+		}
+	}
+	if t == "" || t[0] == '_' || t[len(t)-1] == '_' || strings.Contains(t, "__") {
+		invalid()
+	}
+	digits := strings.ReplaceAll(t, "_", "")
+	if base == 10 && len(digits) > pyMaxIntDigits {
+		it.raise("ValueError", "Exceeds the limit (%d digits) for integer string conversion: value has %d digits; use sys.set_int_max_str_digits() to increase the limit", pyMaxIntDigits, len(digits))
+	}
+	for _, r := range digits {
+		if r > unicode.MaxASCII && unicode.IsDigit(r) {
+			digits = strings.Map(func(r rune) rune {
+				if unicode.IsDigit(r) {
+					return '0' + rune(unicode.ToLower(r)-'0')%10
+				}
+				return r
+			}, digits)
+			break
+		}
+	}
+	n, ok := new(big.Int).SetString(digits, base)
+	if !ok {
+		invalid()
+	}
+	if neg {
+		n.Neg(n)
+	}
+	return pyNormInt(n)
+}
+
+func pyConstructFloat(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+	v := it.bindNative("float", args, kw, 0, "x")
+	if v[0] == nil {
+		return 0.0
+	}
+	switch x := v[0].(type) {
+	case string:
+		if f, ok := pyParseFloat(x); ok {
+			return f
+		}
+		it.raise("ValueError", "could not convert string to float: %s", it.repr(x))
+	case *pyInstance:
+		if m, ok := it.lookupAttr(x, "__float__"); ok {
+			return it.call(m, nil, nil)
+		}
+	default:
+		if n, ok := pyNumber(x); ok {
+			return it.toFloat(n)
+		}
+	}
+	it.raise("TypeError", "float() argument must be a string or a real number, not '%s'", it.typeName(v[0]))
+	return nil
+}
+
+// pyParseFloat convierte una cadena a float como float(s)
+func pyParseFloat(s string) (float64, bool) {
+	t := strings.TrimFunc(s, unicode.IsSpace)
+	switch strings.ToLower(strings.TrimLeft(t, "+-")) {
+	case "inf", "infinity":
+		if strings.HasPrefix(t, "-") {
+			return math.Inf(-1), true
+		}
+		return math.Inf(1), true
+	case "nan":
+		return math.NaN(), true
+	}
+	lower := strings.ToLower(t)
+	if t == "" || strings.ContainsAny(lower, "xpi") || strings.Contains(t, "__") || strings.HasPrefix(t, "_") || strings.HasSuffix(t, "_") {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(strings.ReplaceAll(t, "_", ""), 64)
+	if err != nil && !strings.Contains(err.Error(), "range") {
+		return 0, false
+	}
+	return f, true
+}
+
+func pyConstructRange(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+	if len(kw) > 0 {
+		it.raise("TypeError", "range() takes no keyword arguments")
+	}
+	switch len(args) {
+	case 1:
+		return &pyRange{0, it.intArg(args[0]), 1}
+	case 2:
+		return &pyRange{it.intArg(args[0]), it.intArg(args[1]), 1}
+	case 3:
+		step := it.intArg(args[2])
+		if step == 0 {
+			it.raise("ValueError", "range() arg 3 must not be zero")
+		}
+		return &pyRange{it.intArg(args[0]), it.intArg(args[1]), step}
+	case 0:
+		it.raise("TypeError", "range expected at least 1 argument, got 0")
+	}
+	it.raise("TypeError", "range expected at most 3 arguments, got %d", len(args))
+	return nil
+}
+
+// newSet crea un set o frozenset con los elementos de un iterable
+func (it *pyInterp) newSet(src pyValue, kind string) *pyDict {
+	set := newPySet()
+	set.kind = kind
+	if src != nil {
+		next := it.iter(src)
+		for {
+			v, ok := next()
+			if !ok {
+				break
+			}
+			it.dictSet(set, v, nil)
+		}
+	}
+	return set
+}
+
+// setItems devuelve los elementos de un conjunto en el orden en que los
+// recorre CPython cuando son enteros chicos (en orden ascendente, porque
+// cada uno ocupa la posición de su valor en la tabla) y si no en orden de
+// inserción
+func (it *pyInterp) setItems(d *pyDict) []pyEntry {
+	items := d.items()
+	for _, e := range items {
+		if n, ok := e.hash.(int64); !ok || n < 0 || n >= int64(max(d.table, 8)) {
+			return items
+		}
+	}
+	sorted := append([]pyEntry(nil), items...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].hash.(int64) < sorted[j].hash.(int64) })
+	return sorted
+}
+
+// ───── Representación ─────
+
+func (it *pyInterp) str(v pyValue) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case *pyInstance:
+		m, _ := it.classAttr(x.class, "__str__")
+		r := it.call(it.bind(m, x, x.class), nil, nil)
+		s, ok := r.(string)
+		if !ok {
+			it.raise("TypeError", "__str__ returned non-string (type %s)", it.typeName(r))
+		}
+		return s
+	}
+	return it.repr(v)
+}
+
+func (it *pyInterp) repr(v pyValue) string {
+	switch x := v.(type) {
+	case pyNoneType:
+		return "None"
+	case bool:
+		if x {
+			return "True"
+		}
+		return "False"
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case *big.Int:
+		if x.BitLen() > pyMaxIntDigits*332/100 {
+			it.raise("ValueError", "Exceeds the limit (%d digits) for integer string conversion; use sys.set_int_max_str_digits() to increase the limit", pyMaxIntDigits)
+		}
+		s := x.String()
+		if len(strings.TrimPrefix(s, "-")) > pyMaxIntDigits {
+			it.raise("ValueError", "Exceeds the limit (%d digits) for integer string conversion; use sys.set_int_max_str_digits() to increase the limit", pyMaxIntDigits)
+		}
+		return s
+	case float64:
+		return pyFloatRepr(x)
+	case string:
+		return pyQuote(x)
+	case *pyList, *pyTuple, *pyDict, *pyView:
+		if it.reprSeen[v] {
+			switch v.(type) {
+			case *pyList:
+				return "[...]"
+			case *pyTuple:
+				return "(...)"
+			}
+			return "{...}"
+		}
+		it.reprSeen[v] = true
+		defer delete(it.reprSeen, v)
+		return it.containerRepr(v)
+	case *pyRange:
+		if x.step == 1 {
+			return fmt.Sprintf("range(%d, %d)", x.start, x.stop)
+		}
+		return fmt.Sprintf("range(%d, %d, %d)", x.start, x.stop, x.step)
+	case *pySlice:
+		return fmt.Sprintf("slice(%s, %s, %s)", it.repr(x.start), it.repr(x.stop), it.repr(x.step))
+	case *pyFunction:
+		if x.native != nil {
+			return "<built-in function " + x.name + ">"
+		}
+		return fmt.Sprintf("<function %s at 0x%x>", x.qualname, it.id(x))
+	case *pyBound:
+		name := ""
+		if f, ok := x.fn.(*pyFunction); ok {
+			if f.native != nil {
+				return fmt.Sprintf("<built-in method %s of %s object at 0x%x>", f.name, it.typeName(x.self), it.id(x.self))
+			}
+			name = f.qualname
+		}
+		return fmt.Sprintf("<bound method %s of %s>", name, it.repr(x.self))
+	case *pyClass:
+		if x.builtin {
+			return "<class '" + x.name + "'>"
+		}
+		return "<class '__main__." + x.name + "'>"
+	case *pyModule:
+		return "<module '" + x.name + "' (built-in)>"
+	case *pyIterator:
+		if x.gen != nil {
+			return fmt.Sprintf("<generator object %s at 0x%x>", x.gen.fn.qualname, it.id(x))
+		}
+		return fmt.Sprintf("<%s object at 0x%x>", x.kind, it.id(x))
+	case *pyFile:
+		return fmt.Sprintf("<_io.TextIOWrapper name=%s mode=%s encoding='UTF-8'>", it.repr(x.name), it.repr(x.mode))
+	case *pyInstance:
+		m, _ := it.classAttr(x.class, "__repr__")
+		r := it.call(it.bind(m, x, x.class), nil, nil)
+		s, ok := r.(string)
+		if !ok {
+			it.raise("TypeError", "__repr__ returned non-string (type %s)", it.typeName(r))
+		}
+		return s
+	case pyNotImplementedType:
+		return "NotImplemented"
+	case pyEllipsisType:
+		return "Ellipsis"
+	case *pySuper:
+		return fmt.Sprintf("<super: <class '%s'>, <%s object>>", x.class.name, it.typeName(x.self))
+	}
+	return it.defaultRepr(v)
+}
+
+func (it *pyInterp) defaultRepr(v pyValue) string {
+	cls := it.typeOf(v)
+	module := "__main__."
+	if cls.builtin {
+		module = ""
+	}
+	return fmt.Sprintf("<%s%s object at 0x%x>", module, cls.name, it.id(v))
+}
+
+func (it *pyInterp) joinRepr(items []pyValue) string {
+	parts := make([]string, len(items))
+	for i, item := range items {
+		it.b.step()
+		parts[i] = it.repr(item)
+	}
+	s := strings.Join(parts, ", ")
+	it.b.alloc(len(s))
+	return s
+}
+
+func (it *pyInterp) containerRepr(v pyValue) string {
+	switch x := v.(type) {
+	case *pyList:
+		if !x.deque {
+			return "[" + it.joinRepr(x.items) + "]"
+		}
+		if x.maxlen >= 0 {
+			return fmt.Sprintf("deque([%s], maxlen=%d)", it.joinRepr(x.items), x.maxlen)
+		}
+		return "deque([" + it.joinRepr(x.items) + "])"
+	case *pyTuple:
+		if len(x.items) == 1 {
+			return "(" + it.repr(x.items[0]) + ",)"
+		}
+		return "(" + it.joinRepr(x.items) + ")"
+	case *pyView:
+		return x.kind + "(" + it.joinRepr(it.toList(x)) + ")"
+	}
+	d := v.(*pyDict)
+	if d.set {
+		if d.live == 0 {
+			return it.typeName(d) + "()"
+		}
+		keys := make([]pyValue, 0, d.live)
+		for _, e := range it.setItems(d) {
+			keys = append(keys, e.key)
+		}
+		if d.kind == "frozenset" {
+			return "frozenset({" + it.joinRepr(keys) + "})"
+		}
+		return "{" + it.joinRepr(keys) + "}"
+	}
+	entries := d.items()
+	if d.kind == "Counter" {
+		entries = it.mostCommon(d)
+	}
+	pairs := make([]string, len(entries))
+	for i, e := range entries {
+		it.b.step()
+		if d.kind == "OrderedDict" {
+			pairs[i] = "(" + it.repr(e.key) + ", " + it.repr(e.value) + ")"
+		} else {
+			pairs[i] = it.repr(e.key) + ": " + it.repr(e.value)
+		}
+	}
+	body := strings.Join(pairs, ", ")
+	it.b.alloc(len(body))
+	switch d.kind {
+	case "defaultdict":
+		factory := pyValue(pyNone)
+		if d.factory != nil {
+			factory = d.factory
+		}
+		return "defaultdict(" + it.repr(factory) + ", {" + body + "})"
+	case "Counter":
+		if d.live == 0 {
+			return "Counter()"
+		}
+		return "Counter({" + body + "})"
+	case "OrderedDict":
+		if d.live == 0 {
+			return "OrderedDict()"
+		}
+		return "OrderedDict([" + body + "])"
+	}
+	return "{" + body + "}"
+}
+
+// pyFloatRepr escribe un flotante con los dígitos mínimos, como repr()
+func pyFloatRepr(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "nan"
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	}
+	e := strconv.FormatFloat(f, 'e', -1, 64)
+	exp, _ := strconv.Atoi(e[strings.IndexByte(e, 'e')+1:])
+	if exp < -4 || exp >= 16 {
+		return e
+	}
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if !strings.Contains(s, ".") {
+		s += ".0"
+	}
+	return s
+}
+
+// pyQuote escribe una cadena entre comillas como repr()
+func pyQuote(s string) string {
+	quote := '\''
+	if strings.ContainsRune(s, '\'') && !strings.ContainsRune(s, '"') {
+		quote = '"'
+	}
+	var sb strings.Builder
+	sb.Grow(len(s) + 2)
+	sb.WriteRune(quote)
+	for _, r := range s {
+		switch {
+		case r == quote || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&sb, `\x%02x`, r)
+		case r < utf8.RuneSelf || unicode.IsPrint(r):
+			sb.WriteRune(r)
+		case r < 0x100:
+			fmt.Fprintf(&sb, `\x%02x`, r)
+		case r < 0x10000:
+			fmt.Fprintf(&sb, `\u%04x`, r)
+		default:
+			fmt.Fprintf(&sb, `\U%08x`, r)
+		}
+	}
+	sb.WriteRune(quote)
+	return sb.String()
+}
+
+// pyASCII escapa los caracteres no ASCII de una representación
+func pyASCII(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch {
+		case r < utf8.RuneSelf:
+			sb.WriteRune(r)
+		case r < 0x100:
+			fmt.Fprintf(&sb, `\x%02x`, r)
+		case r < 0x10000:
+			fmt.Fprintf(&sb, `\u%04x`, r)
+		default:
+			fmt.Fprintf(&sb, `\U%08x`, r)
+		}
+	}
+	return sb.String()
+}
+
+// ───── Formato ─────
+//
+// format(), f-strings, str.format y el operador % comparten la mini
+// lenguaje de especificadores de formato de Python.
+
+type pyFormatSpec struct {
+	fill      rune
+	align     byte
+	sign      byte
+	alt       bool
+	zero      bool
+	width     int
+	grouping  byte
+	precision int // -1 si no se indicó
+	typ       byte
+}
+
+func (it *pyInterp) parseSpec(spec string, v pyValue) pyFormatSpec {
+	fs := pyFormatSpec{fill: ' ', precision: -1}
+	r := []rune(spec)
+	i := 0
+	if len(r) >= 2 && strings.ContainsRune("<>=^", r[1]) {
+		fs.fill, fs.align = r[0], byte(r[1])
+		i = 2
+	} else if len(r) >= 1 && strings.ContainsRune("<>=^", r[0]) {
+		fs.align = byte(r[0])
+		i = 1
+	}
+	if i < len(r) && strings.ContainsRune("+- ", r[i]) {
+		fs.sign = byte(r[i])
+		i++
+	}
+	if i < len(r) && r[i] == 'z' {
+		i++
+	}
+	if i < len(r) && r[i] == '#' {
+		fs.alt = true
+		i++
+	}
+	if i < len(r) && r[i] == '0' {
+		fs.zero = true
+		i++
+	}
+	for i < len(r) && r[i] >= '0' && r[i] <= '9' {
+		fs.width = fs.width*10 + int(r[i]-'0')
+		i++
+	}
+	if i < len(r) && (r[i] == ',' || r[i] == '_') {
+		fs.grouping = byte(r[i])
+		i++
+	}
+	if i < len(r) && r[i] == '.' {
+		i++
+		start := i
+		fs.precision = 0
+		for i < len(r) && r[i] >= '0' && r[i] <= '9' {
+			fs.precision = fs.precision*10 + int(r[i]-'0')
+			i++
+		}
+		if i == start {
+			it.raise("ValueError", "Format specifier missing precision")
+		}
+	}
+	if i < len(r) {
+		fs.typ = byte(r[i])
+		i++
+	}
+	if i < len(r) || fs.typ >= utf8.RuneSelf {
+		it.raise("ValueError", "Invalid format specifier '%s' for object of type '%s'", spec, it.typeName(v))
+	}
+	if fs.zero && fs.align == 0 {
+		fs.fill, fs.align = '0', '='
+	}
+	return fs
+}
+
+func (it *pyInterp) format(v pyValue, spec string) string {
+	if inst, ok := v.(*pyInstance); ok {
+		if m, ok := it.classAttr(inst.class, "__format__"); ok {
+			r := it.call(it.bind(m, inst, inst.class), []pyValue{spec}, nil)
+			s, ok := r.(string)
+			if !ok {
+				it.raise("TypeError", "__format__ must return a str, not %s", it.typeName(r))
+			}
+			return s
+		}
+	}
+	if spec == "" {
+		return it.str(v)
+	}
+	fs := it.parseSpec(spec, v)
+	switch x := v.(type) {
+	case string:
+		return it.formatString(x, fs)
+	case bool:
+		if fs.typ == 0 {
+			return it.pad(it.str(x), "", fs, '>')
+		}
+		n, _ := pyIntArg(x)
+		return it.formatInt(big.NewInt(n), fs)
+	case int64:
+		return it.formatInt(big.NewInt(x), fs)
+	case *big.Int:
+		return it.formatInt(x, fs)
+	case float64:
+		return it.formatFloat(x, fs)
+	}
+	it.raise("TypeError", "unsupported format string passed to %s.__format__", it.typeName(v))
+	return ""
+}
+
+// pad completa s hasta el ancho del especificador; prefix (signo y 0x) va
+// antes del relleno cuando la alineación es '='
+func (it *pyInterp) pad(s, prefix string, fs pyFormatSpec, def byte) string {
+	n := utf8.RuneCountInString(s) + utf8.RuneCountInString(prefix)
+	if fs.width <= n {
+		return prefix + s
+	}
+	it.b.alloc(fs.width)
+	fill := strings.Repeat(string(fs.fill), fs.width-n)
+	align := fs.align
+	if align == 0 {
+		align = def
+	}
+	switch align {
+	case '<':
+		return prefix + s + fill
+	case '^':
+		half := (fs.width - n) / 2
+		return strings.Repeat(string(fs.fill), half) + prefix + s + strings.Repeat(string(fs.fill), fs.width-n-half)
+	case '=':
+		return prefix + fill + s
+	}
+	return fill + prefix + s
+}
+
+func (it *pyInterp) formatString(s string, fs pyFormatSpec) string {
+	if fs.typ != 0 && fs.typ != 's' {
+		it.raise("ValueError", "Unknown format code '%c' for object of type 'str'", fs.typ)
+	}
+	if fs.sign != 0 {
+		it.raise("ValueError", "Sign not allowed in string format specifier")
+	}
+	if fs.align == '=' {
+		it.raise("ValueError", "'=' alignment not allowed in string format specifier")
+	}
+	if fs.precision >= 0 {
+		if r := []rune(s); len(r) > fs.precision {
+			s = string(r[:fs.precision])
+		}
+	}
+	return it.pad(s, "", fs, '<')
+}
+
+func pySignPrefix(neg bool, sign byte) string {
+	switch {
+	case neg:
+		return "-"
+	case sign == '+':
+		return "+"
+	case sign == ' ':
+		return " "
+	}
+	return ""
+}
+
+// pyGroup agrega separadores de miles (o cada 4 dígitos) a los dígitos
+func pyGroup(digits string, sep byte, every int) string {
+	if sep == 0 || len(digits) <= every {
+		return digits
+	}
+	var sb strings.Builder
+	first := len(digits) % every
+	if first > 0 {
+		sb.WriteString(digits[:first])
+	}
+	for i := first; i < len(digits); i += every {
+		if sb.Len() > 0 {
+			sb.WriteByte(sep)
+		}
+		sb.WriteString(digits[i : i+every])
+	}
+	return sb.String()
+}
+
+func (it *pyInterp) formatInt(n *big.Int, fs pyFormatSpec) string {
+	switch fs.typ {
+	case 'e', 'E', 'f', 'F', 'g', 'G', '%':
+		f, _ := new(big.Float).SetInt(n).Float64()
+		return it.formatFloat(f, fs)
+	case 'c':
+		return it.pad(string(rune(n.Int64())), "", fs, '>')
+	}
+	if fs.precision >= 0 {
+		it.raise("ValueError", "Precision not allowed in integer format specifier")
+	}
+	base, prefix, every := 10, "", 3
+	switch fs.typ {
+	case 0, 'd', 'n':
+	case 'x', 'X':
+		base, prefix, every = 16, "0x", 4
+	case 'o':
+		base, prefix, every = 8, "0o", 4
+	case 'b':
+		base, prefix, every = 2, "0b", 4
+	default:
+		it.raise("ValueError", "Unknown format code '%c' for object of type 'int'", fs.typ)
+	}
+	if fs.grouping == ',' && base != 10 {
+		it.raise("ValueError", "Cannot specify ',' with '%c'.", fs.typ)
+	}
+	digits := new(big.Int).Abs(n).Text(base)
+	if fs.typ == 'X' {
+		digits, prefix = strings.ToUpper(digits), "0X"
+	}
+	if !fs.alt {
+		prefix = ""
+	}
+	digits = pyGroup(digits, fs.grouping, every)
+	return it.pad(digits, pySignPrefix(n.Sign() < 0, fs.sign)+prefix, fs, '>')
+}
+
+func (it *pyInterp) formatFloat(f float64, fs pyFormatSpec) string {
+	neg := math.Signbit(f) && !math.IsNaN(f)
+	a := math.Abs(f)
+	prec := fs.precision
+	var body string
+	switch fs.typ {
+	case 'f', 'F', '%':
+		if prec < 0 {
+			prec = 6
+		}
+		if fs.typ == '%' {
+			a *= 100
+		}
+		body = strconv.FormatFloat(a, 'f', prec, 64)
+		if fs.alt && prec == 0 {
+			body += "."
+		}
+		if fs.typ == '%' {
+			body += "%"
+		}
+	case 'e', 'E':
+		if prec < 0 {
+			prec = 6
+		}
+		body = strconv.FormatFloat(a, 'e', prec, 64)
+		if fs.alt && prec == 0 {
+			body = strings.Replace(body, "e", ".e", 1)
+		}
+	case 'g', 'G', 0, 'n':
+		if fs.typ == 0 && prec < 0 {
+			body = pyFloatRepr(a)
+			break
+		}
+		if prec < 0 {
+			prec = 6
+		}
+		if prec == 0 {
+			prec = 1
+		}
+		body = pyFormatG(a, prec, fs.alt)
+		if fs.typ == 0 && !strings.ContainsAny(body, ".en") {
+			body += ".0"
+		}
+	default:
+		it.raise("ValueError", "Unknown format code '%c' for object of type 'float'", fs.typ)
+	}
+	switch {
+	case math.IsInf(a, 0):
+		body = "inf"
+		if fs.typ == '%' {
+			body += "%"
+		}
+	case math.IsNaN(a):
+		body = "nan"
+		if fs.typ == '%' {
+			body += "%"
+		}
+	}
+	if fs.typ == 'F' || fs.typ == 'E' || fs.typ == 'G' {
+		body = strings.ToUpper(body)
+	}
+	if fs.grouping != 0 {
+		end := strings.IndexAny(body, ".e%")
+		if end < 0 {
+			end = len(body)
+		}
+		if strings.Trim(body[:end], "0123456789") == "" {
+			body = pyGroup(body[:end], fs.grouping, 3) + body[end:]
+		}
+	}
+	return it.pad(body, pySignPrefix(neg, fs.sign), fs, '>')
+}
+
+// pyFormatG implementa el formato 'g' de Python con prec dígitos
+// significativos
+func pyFormatG(a float64, prec int, alt bool) string {
+	if math.IsInf(a, 0) || math.IsNaN(a) {
+		return strconv.FormatFloat(a, 'g', -1, 64)
+	}
+	e := strconv.FormatFloat(a, 'e', prec-1, 64)
+	exp, _ := strconv.Atoi(e[strings.IndexByte(e, 'e')+1:])
+	var s string
+	if exp >= -4 && exp < prec {
+		s = strconv.FormatFloat(a, 'f', prec-1-exp, 64)
+		if !alt && strings.Contains(s, ".") {
+			s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+		}
+		return s
+	}
+	mant, rest := e[:strings.IndexByte(e, 'e')], e[strings.IndexByte(e, 'e'):]
+	if !alt && strings.Contains(mant, ".") {
+		mant = strings.TrimRight(strings.TrimRight(mant, "0"), ".")
+	}
+	return mant + rest
+}
+
+// percentFormat implementa cadena % valores
+func (it *pyInterp) percentFormat(format string, args pyValue) string {
+	values := []pyValue{args}
+	if t, ok := args.(*pyTuple); ok {
+		values = t.items
+	}
+	mapping, _ := args.(*pyDict)
+	next := 0
+	take := func() pyValue {
+		if next >= len(values) {
+			it.raise("TypeError", "not enough arguments for format string")
+		}
+		next++
+		return values[next-1]
+	}
+	var sb strings.Builder
+	usedMapping := false
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c != '%' {
+			sb.WriteByte(c)
+			continue
+		}
+		i++
+		if i >= len(format) {
+			it.raise("ValueError", "incomplete format")
+		}
+		var arg pyValue
+		if format[i] == '(' {
+			end := strings.IndexByte(format[i:], ')')
+			if end < 0 || mapping == nil {
+				it.raise("TypeError", "format requires a mapping")
+			}
+			key := format[i+1 : i+end]
+			v, ok := it.dictGet(mapping, key)
+			if !ok {
+				it.raiseValue("KeyError", key)
+			}
+			arg, usedMapping = v, true
+			i += end + 1
+		}
+		fs := pyFormatSpec{fill: ' ', precision: -1}
+		for ; i < len(format) && strings.IndexByte("-+ #0", format[i]) >= 0; i++ {
+			switch format[i] {
+			case '-':
+				fs.align = '<'
+			case '+', ' ':
+				if fs.sign != '+' {
+					fs.sign = format[i]
+				}
+			case '#':
+				fs.alt = true
+			case '0':
+				fs.zero = true
+			}
+		}
+		if i < len(format) && format[i] == '*' {
+			fs.width = int(it.intArg(take()))
+			i++
+		}
+		for ; i < len(format) && format[i] >= '0' && format[i] <= '9'; i++ {
+			fs.width = fs.width*10 + int(format[i]-'0')
+		}
+		if i < len(format) && format[i] == '.' {
+			i++
+			fs.precision = 0
+			if i < len(format) && format[i] == '*' {
+				fs.precision = int(it.intArg(take()))
+				i++
+			}
+			for ; i < len(format) && format[i] >= '0' && format[i] <= '9'; i++ {
+				fs.precision = fs.precision*10 + int(format[i]-'0')
+			}
+		}
+		for i < len(format) && strings.IndexByte("hlL", format[i]) >= 0 {
+			i++
+		}
+		if i >= len(format) {
+			it.raise("ValueError", "incomplete format")
+		}
+		conv := format[i]
+		if conv == '%' {
+			sb.WriteByte('%')
+			continue
+		}
+		if arg == nil {
+			arg = take()
+		}
+		if fs.zero && fs.align != '<' && strings.IndexByte("diouxXeEfFgG", conv) >= 0 {
+			fs.fill, fs.align = '0', '='
+		}
+		switch conv {
+		case 's', 'r', 'a':
+			s := it.str(arg)
+			if conv == 'r' {
+				s = it.repr(arg)
+			} else if conv == 'a' {
+				s = pyASCII(it.repr(arg))
+			}
+			if fs.precision >= 0 && len([]rune(s)) > fs.precision {
+				s = string([]rune(s)[:fs.precision])
+			}
+			fs.precision = -1
+			sb.WriteString(it.pad(s, "", fs, '>'))
+		case 'd', 'i', 'u', 'x', 'X', 'o':
+			n, ok := pyNumber(arg)
+			if !ok {
+				if conv == 'd' || conv == 'i' || conv == 'u' {
+					it.raise("TypeError", "%%%c format: a real number is required, not %s", conv, it.typeName(arg))
+				}
+				it.raise("TypeError", "%%%c format: an integer is required, not %s", conv, it.typeName(arg))
+			}
+			if f, ok := n.(float64); ok {
+				if conv != 'd' && conv != 'i' && conv != 'u' {
+					it.raise("TypeError", "%%%c format: an integer is required, not float", conv)
+				}
+				n = pyFloatToInt(math.Trunc(f))
+			}
+			digits := fs.precision
+			fs.precision = -1
+			if conv == 'd' || conv == 'i' || conv == 'u' {
+				fs.typ = 'd'
+			} else {
+				fs.typ = conv
+			}
+			s := it.formatInt(pyBig(n), fs)
+			if digits > 0 {
+				x := pyBig(n)
+				body := new(big.Int).Abs(x).Text(map[byte]int{'d': 10, 'x': 16, 'X': 16, 'o': 8}[fs.typ])
+				if fs.typ == 'X' {
+					body = strings.ToUpper(body)
+				}
+				for len(body) < digits {
+					body = "0" + body
+				}
+				s = it.pad(body, pySignPrefix(x.Sign() < 0, fs.sign), fs, '>')
+			}
+			sb.WriteString(s)
+		case 'e', 'E', 'f', 'F', 'g', 'G':
+			n, ok := pyNumber(arg)
+			if !ok {
+				it.raise("TypeError", "must be real number, not %s", it.typeName(arg))
+			}
+			fs.typ = conv
+			sb.WriteString(it.formatFloat(it.toFloat(n), fs))
+		case 'c':
+			s, ok := arg.(string)
+			if !ok {
+				s = string(rune(it.intArg(arg)))
+			}
+			sb.WriteString(it.pad(s, "", fs, '>'))
+		default:
+			it.raise("ValueError", "unsupported format character '%c' (0x%x) at index %d", conv, conv, i)
+		}
+	}
+	if next < len(values) && !usedMapping && mapping == nil {
+		it.raise("TypeError", "not all arguments converted during string formatting")
+	}
+	it.b.alloc(sb.Len())
+	return sb.String()
+}
+
+// strFormat implementa str.format
+func (it *pyInterp) strFormat(format string, args []pyValue, kw []pyKeyword, mapping *pyDict) string {
+	auto, manual := 0, false
+	var sb strings.Builder
+	var render func(format string, depth int)
+	render = func(format string, depth int) {
+		for i := 0; i < len(format); i++ {
+			c := format[i]
+			switch {
+			case c == '{' && i+1 < len(format) && format[i+1] == '{', c == '}' && i+1 < len(format) && format[i+1] == '}':
+				sb.WriteByte(c)
+				i++
+				continue
+			case c == '}':
+				it.raise("ValueError", "Single '}' encountered in format string")
+			case c != '{':
+				sb.WriteByte(c)
+				continue
+			}
+			end := pyMatchingBrace(format, i)
+			if end < 0 {
+				it.raise("ValueError", "Single '{' encountered in format string")
+			}
+			field := format[i+1 : end]
+			i = end
+			name, spec, conv := field, "", byte(0)
+			if j := strings.IndexAny(field, "!:"); j >= 0 && !strings.Contains(field[:j], "[") || j >= 0 && strings.Contains(field[:j], "]") {
+				name = field[:j]
+				rest := field[j:]
+				if rest[0] == '!' {
+					if len(rest) < 2 {
+						it.raise("ValueError", "end of string while looking for conversion specifier")
+					}
+					conv = rest[1]
+					rest = rest[2:]
+				}
+				if strings.HasPrefix(rest, ":") {
+					spec = rest[1:]
+				}
+			}
+			first := name
+			if j := strings.IndexAny(name, ".["); j >= 0 {
+				first = name[:j]
+			}
+			var v pyValue
+			switch {
+			case first == "":
+				if manual {
+					it.raise("ValueError", "cannot switch from manual field specification to automatic field numbering")
+				}
+				if auto >= len(args) {
+					it.raise("IndexError", "Replacement index %d out of range for positional args tuple", auto)
+				}
+				v = args[auto]
+				auto++
+			case first[0] >= '0' && first[0] <= '9':
+				if auto > 0 {
+					it.raise("ValueError", "cannot switch from automatic field numbering to manual field specification")
+				}
+				manual = true
+				n, _ := strconv.Atoi(first)
+				if n >= len(args) {
+					it.raise("IndexError", "Replacement index %d out of range for positional args tuple", n)
+				}
+				v = args[n]
+			default:
+				found := false
+				for _, k := range kw {
+					if k.name == first {
+						v, found = k.value, true
+					}
+				}
+				if !found && mapping != nil {
+					v, found = it.dictGet(mapping, first)
+				}
+				if !found {
+					it.raiseValue("KeyError", first)
+				}
+			}
+			for rest := name[len(first):]; rest != ""; {
+				if rest[0] == '.' {
+					j := strings.IndexAny(rest[1:], ".[")
+					if j < 0 {
+						j = len(rest) - 1
+					}
+					v = it.getAttr(v, rest[1:j+1])
+					rest = rest[j+1:]
+					continue
+				}
+				j := strings.IndexByte(rest, ']')
+				if j < 0 {
+					it.raise("ValueError", "Missing ']' in format string")
+				}
+				key := rest[1:j]
+				if n, err := strconv.Atoi(key); err == nil {
+					v = it.getItem(v, int64(n))
+				} else {
+					v = it.getItem(v, key)
+				}
+				rest = rest[j+1:]
+			}
+			switch conv {
+			case 'r':
+				v = it.repr(v)
+			case 's':
+				v = it.str(v)
+			case 'a':
+				v = pyASCII(it.repr(v))
+			case 0:
+			default:
+				it.raise("ValueError", "Unknown conversion specifier %c", conv)
+			}
+			if strings.Contains(spec, "{") {
+				if depth > 1 {
+					it.raise("ValueError", "Max string recursion exceeded")
+				}
+				outer := sb.Len()
+				render(spec, depth+1)
+				spec = sb.String()[outer:]
+				rest := sb.String()[:outer]
+				sb.Reset()
+				sb.WriteString(rest)
+			}
+			sb.WriteString(it.format(v, spec))
+		}
+	}
+	render(format, 0)
+	it.b.alloc(sb.Len())
+	return sb.String()
+}
+
+// ───── Entrada y salida ─────
+
+// pyFile es uno de sys.stdin, sys.stdout y sys.stderr. El intérprete no
+// abre archivos: open() no está definido
+type pyFile struct {
+	name string
+	mode string
+	std  int // pyStdin, pyStdout o pyStderr
+}
+
+const (
+	pyStdin = iota + 1
+	pyStdout
+	pyStderr
+)
+
+// readStdinLine lee una línea de la entrada estándar con su salto de línea
+func (it *pyInterp) readStdinLine() (string, bool) {
+	if it.stdinPos >= len(it.stdin) {
+		return "", false
+	}
+	rest := it.stdin[it.stdinPos:]
+	if i := strings.IndexByte(rest, '\n'); i >= 0 {
+		rest = rest[:i+1]
+	}
+	it.stdinPos += len(rest)
+	return rest, true
+}
+
+func (it *pyInterp) fileReadLine(f *pyFile) string {
+	if f.std != pyStdin {
+		it.raise("UnsupportedOperation", "not readable")
+	}
+	line, _ := it.readStdinLine()
+	return line
+}
+
+func (it *pyInterp) fileRead(f *pyFile, n int64) string {
+	if f.std != pyStdin {
+		it.raise("UnsupportedOperation", "not readable")
+	}
+	rest := it.stdin[it.stdinPos:]
+	if n >= 0 && int(n) < len(rest) {
+		rest = string([]rune(rest)[:n])
+	}
+	it.stdinPos += len(rest)
+	return rest
+}
+
+func (it *pyInterp) fileWrite(f *pyFile, s string) {
+	if f.std == pyStdin {
+		it.raise("UnsupportedOperation", "not writable")
+	}
+	it.b.write(f.std == pyStderr, s)
+}
+
+// writeTo escribe s en el archivo file de print(); nil o None es stdout
+func (it *pyInterp) writeTo(file pyValue, s string) {
+	switch f := file.(type) {
+	case nil, pyNoneType:
+		it.b.write(false, s)
+	case *pyFile:
+		it.fileWrite(f, s)
+	default:
+		it.call(it.getAttr(file, "write"), []pyValue{s}, nil)
+	}
+}
+
+// ───── Métodos de los tipos predefinidos ─────
+
+// typeAttr devuelve un método leído desde el tipo (str.upper, dict.fromkeys)
+func (it *pyInterp) typeAttr(cls *pyClass, name string) (pyValue, bool) {
+	switch cls.name + "." + name {
+	case "dict.fromkeys", "str.maketrans":
+		m, _ := it.valueAttr(map[string]pyValue{"dict": newPyDict(), "str": ""}[cls.name], name)
+		return m, true
+	}
+	samples := map[string]func() pyValue{
+		"str":   func() pyValue { return "" },
+		"list":  func() pyValue { return &pyList{maxlen: -1} },
+		"tuple": func() pyValue { return &pyTuple{} },
+		"dict":  func() pyValue { return newPyDict() },
+		"set":   func() pyValue { return newPySet() },
+		"int":   func() pyValue { return int64(0) },
+		"float": func() pyValue { return 0.0 },
+	}
+	sample, ok := samples[cls.name]
+	if !ok {
+		return nil, false
+	}
+	if _, ok := it.valueAttr(sample(), name); !ok {
+		return nil, false
+	}
+	return pyBuiltin(name, func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		if len(args) == 0 {
+			it.raise("TypeError", "unbound method %s.%s() needs an argument", cls.name, name)
+		}
+		if !it.isInstance(args[0], cls) {
+			it.raise("TypeError", "descriptor '%s' for '%s' objects doesn't apply to a '%s' object", name, cls.name, it.typeName(args[0]))
+		}
+		return it.call(it.getAttr(args[0], name), args[1:], kw)
+	}), true
+}
+
+// valueAttr devuelve un atributo o método de un valor predefinido
+func (it *pyInterp) valueAttr(v pyValue, name string) (pyValue, bool) {
+	if name == "__class__" {
+		return it.typeOf(v), true
+	}
+	var m pyNative
+	switch x := v.(type) {
+	case string:
+		m = it.strMethod(x, name)
+	case *pyList:
+		if x.deque {
+			if name == "maxlen" {
+				if x.maxlen < 0 {
+					return pyNone, true
+				}
+				return int64(x.maxlen), true
+			}
+			m = it.dequeMethod(x, name)
+		}
+		if m == nil {
+			m = it.listMethod(x, name)
+		}
+	case *pyTuple:
+		m = it.tupleMethod(x, name)
+	case *pyDict:
+		if x.set {
+			m = it.setMethod(x, name)
+		} else {
+			if name == "default_factory" && x.kind == "defaultdict" {
+				if x.factory == nil {
+					return pyNone, true
+				}
+				return x.factory, true
+			}
+			m = it.dictMethod(x, name)
+		}
+	case int64, *big.Int, bool:
+		n, _ := pyNumber(x)
+		switch name {
+		case "real", "numerator":
+			return n, true
+		case "imag":
+			return int64(0), true
+		case "denominator":
+			return int64(1), true
+		}
+	case float64:
+		switch name {
+		case "real":
+			return x, true
+		case "imag":
+			return 0.0, true
+		}
+	case *pyRange:
+		switch name {
+		case "start":
+			return x.start, true
+		case "stop":
+			return x.stop, true
+		case "step":
+			return x.step, true
+		}
+		m = it.seqMethod(it.toList(x), "range", name)
+	case *pySlice:
+		switch name {
+		case "start":
+			return x.start, true
+		case "stop":
+			return x.stop, true
+		case "step":
+			return x.step, true
+		}
+	case *pyView:
+		if name == "mapping" {
+			return x.dict, true
+		}
+	case *pyIterator:
+		m = it.iteratorMethod(x, name)
+	case *pyFile:
+		switch name {
+		case "name":
+			return x.name, true
+		case "mode":
+			return x.mode, true
+		}
+		m = it.fileMethod(x, name)
+	}
+	if m == nil {
+		return nil, false
+	}
+	return pyBuiltin(name, m), true
+}
+
+func (it *pyInterp) strMethod(s, name string) pyNative {
+	q := "str." + name
+	simple := func(f func() pyValue) pyNative {
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			it.bindNative(q, args, kw, 0)
+			return f()
+		}
+	}
+	test := func(pred func(rune) bool) pyNative {
+		return simple(func() pyValue {
+			if s == "" {
+				return false
+			}
+			for _, r := range s {
+				if !pred(r) {
+					return false
+				}
+			}
+			return true
+		})
+	}
+	switch name {
+	case "upper":
+		return simple(func() pyValue { return strings.ToUpper(s) })
+	case "lower", "casefold":
+		return simple(func() pyValue { return strings.ToLower(s) })
+	case "capitalize":
+		return simple(func() pyValue {
+			r := []rune(strings.ToLower(s))
+			if len(r) > 0 {
+				r[0] = unicode.ToTitle(r[0])
+			}
+			return string(r)
+		})
+	case "title":
+		return simple(func() pyValue {
+			out := []rune(s)
+			prev := false
+			for i, r := range out {
+				if prev {
+					out[i] = unicode.ToLower(r)
+				} else {
+					out[i] = unicode.ToTitle(r)
+				}
+				prev = unicode.IsLetter(r)
+			}
+			return string(out)
+		})
+	case "swapcase":
+		return simple(func() pyValue {
+			return strings.Map(func(r rune) rune {
+				if unicode.IsUpper(r) {
+					return unicode.ToLower(r)
+				}
+				return unicode.ToUpper(r)
+			}, s)
+		})
+	case "isdigit":
+		return test(unicode.IsDigit)
+	case "isdecimal":
+		return test(func(r rune) bool { return unicode.Is(unicode.Nd, r) })
+	case "isnumeric":
+		return test(unicode.IsNumber)
+	case "isalpha":
+		return test(unicode.IsLetter)
+	case "isalnum":
+		return test(func(r rune) bool { return unicode.IsLetter(r) || unicode.IsNumber(r) })
+	case "isspace":
+		return test(unicode.IsSpace)
+	case "isascii":
+		return simple(func() pyValue { return pyIsASCII(s) })
+	case "isprintable":
+		return simple(func() pyValue {
+			for _, r := range s {
+				if r != ' ' && !unicode.IsPrint(r) {
+					return false
+				}
+			}
+			return true
+		})
+	case "isidentifier":
+		return simple(func() pyValue {
+			for i, r := range s {
+				if !(unicode.IsLetter(r) || r == '_' || i > 0 && unicode.IsDigit(r)) {
+					return false
+				}
+			}
+			return s != ""
+		})
+	case "islower", "isupper":
+		return simple(func() pyValue {
+			cased := false
+			for _, r := range s {
+				if unicode.IsUpper(r) || unicode.IsTitle(r) {
+					if name == "islower" {
+						return false
+					}
+					cased = true
+				} else if unicode.IsLower(r) {
+					if name == "isupper" {
+						return false
+					}
+					cased = true
+				}
+			}
+			return cased
+		})
+	case "istitle":
+		return simple(func() pyValue {
+			cased, prev := false, false
+			for _, r := range s {
+				switch {
+				case unicode.IsUpper(r) || unicode.IsTitle(r):
+					if prev {
+						return false
+					}
+					prev, cased = true, true
+				case unicode.IsLower(r):
+					if !prev {
+						return false
+					}
+					prev, cased = true, true
+				default:
+					prev = false
+				}
+			}
+			return cased
+		})
+	case "strip", "lstrip", "rstrip":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative(q, args, kw, 0, "chars")
+			cut := unicode.IsSpace
+			if v[0] != nil && v[0] != pyNone {
+				chars := it.strArg(name, v[0])
+				cut = func(r rune) bool { return strings.ContainsRune(chars, r) }
+			}
+			switch name {
+			case "lstrip":
+				return strings.TrimLeftFunc(s, cut)
+			case "rstrip":
+				return strings.TrimRightFunc(s, cut)
+			}
+			return strings.TrimFunc(s, cut)
+		}
+	case "split", "rsplit":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative(q, args, kw, 0, "sep", "maxsplit")
+			maxsplit := it.optInt(v[1], -1)
+			var parts []string
+			if v[0] == nil || v[0] == pyNone {
+				parts = pySplitSpace(s, int(maxsplit), name == "rsplit")
+			} else {
+				sep := it.strArg(name, v[0])
+				if sep == "" {
+					it.raise("ValueError", "empty separator")
+				}
+				switch {
+				case maxsplit < 0:
+					parts = strings.Split(s, sep)
+				case name == "split":
+					parts = strings.SplitN(s, sep, int(maxsplit)+1)
+				default:
+					parts = pyRSplit(s, sep, int(maxsplit))
+				}
+			}
+			items := make([]pyValue, len(parts))
+			for i, p := range parts {
+				items[i] = p
+			}
+			it.b.alloc(len(items) * pyValueCost)
+			return &pyList{items: items, maxlen: -1}
+		}
+	case "splitlines":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative(q, args, kw, 0, "keepends")
+			keep := v[0] != nil && it.truthy(v[0])
+			var items []pyValue
+			start := 0
+			r := []rune(s)
+			for i := 0; i < len(r); i++ {
+				switch r[i] {
+				case '\n', '\r', '\v', '\f', 0x1c, 0x1d, 0x1e, 0x85, 0x2028, 0x2029:
+					line := i
+					if r[i] == '\r' && i+1 < len(r) && r[i+1] == '\n' {
+						i++
+					}
+					if keep {
+						line = i + 1
+					}
+					items = append(items, string(r[start:line]))
+					start = i + 1
+				}
+			}
+			if start < len(r) {
+				items = append(items, string(r[start:]))
+			}
+			if items == nil {
+				items = []pyValue{}
+			}
+			return &pyList{items: items, maxlen: -1}
+		}
+	case "join":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			items := it.toList(it.bindNative(q, args, kw, 1, "iterable")[0])
+			parts := make([]string, len(items))
+			size := 0
+			for i, item := range items {
+				p, ok := item.(string)
+				if !ok {
+					it.raise("TypeError", "sequence item %d: expected str instance, %s found", i, it.typeName(item))
+				}
+				parts[i] = p
+				size += len(p) + len(s)
+			}
+			it.b.alloc(size)
+			return strings.Join(parts, s)
+		}
+	case "replace":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative(q, args, kw, 2, "old", "new", "count")
+			old, repl := it.strArg(name, v[0]), it.strArg(name, v[1])
+			n := int(it.optInt(v[2], -1))
+			if old != "" {
+				count := strings.Count(s, old)
+				if n >= 0 {
+					count = min(count, n)
+				}
+				it.b.alloc(len(s) + count*(len(repl)-len(old)))
+			} else {
+				it.b.alloc(len(s) * (len(repl) + 1))
+			}
+			return strings.Replace(s, old, repl, n)
+		}
+	case "find", "rfind", "index", "rindex", "count":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative(q, args, kw, 1, "sub", "start", "end")
+			sub := it.strArg(name, v[0])
+			part, offset, ok := it.strWindow(s, v[1], v[2])
+			if name == "count" {
+				if !ok {
+					return int64(0)
+				}
+				if sub == "" {
+					return int64(utf8.RuneCountInString(part) + 1)
+				}
+				return int64(strings.Count(part, sub))
+			}
+			i := -1
+			if ok {
+				if name == "find" || name == "index" {
+					i = strings.Index(part, sub)
+				} else {
+					i = strings.LastIndex(part, sub)
+				}
+			}
+			if i < 0 {
+				if name == "index" || name == "rindex" {
+					it.raise("ValueError", "substring not found")
+				}
+				return int64(-1)
+			}
+			return int64(offset + utf8.RuneCountInString(part[:i]))
+		}
+	case "startswith", "endswith":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative(q, args, kw, 1, "prefix", "start", "end")
+			part, _, ok := it.strWindow(s, v[1], v[2])
+			if !ok {
+				return false
+			}
+			check := func(p pyValue) bool {
+				ps, isStr := p.(string)
+				if !isStr {
+					it.raise("TypeError", "%s first arg must be str or a tuple of str, not %s", name, it.typeName(p))
+				}
+				if name == "startswith" {
+					return strings.HasPrefix(part, ps)
+				}
+				return strings.HasSuffix(part, ps)
+			}
+			if t, isTuple := v[0].(*pyTuple); isTuple {
+				for _, p := range t.items {
+					if check(p) {
+						return true
+					}
+				}
+				return false
+			}
+			return check(v[0])
+		}
+	case "center", "ljust", "rjust":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative(q, args, kw, 1, "width", "fillchar")
+			width := int(it.intArg(v[0]))
+			fill := " "
+			if v[1] != nil {
+				fill = it.strArg(name, v[1])
+				if pyLen(fill) != 1 {
+					it.raise("TypeError", "The fill character must be exactly one character long")
+				}
+			}
+			n := pyLen(s)
+			if width <= n {
+				return s
+			}
+			it.b.alloc(width)
+			marg := width - n
+			switch name {
+			case "ljust":
+				return s + strings.Repeat(fill, marg)
+			case "rjust":
+				return strings.Repeat(fill, marg) + s
+			}
+			left := marg/2 + (marg & width & 1)
+			return strings.Repeat(fill, left) + s + strings.Repeat(fill, marg-left)
+		}
+	case "zfill":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			width := int(it.intArg(it.bindNative(q, args, kw, 1, "width")[0]))
+			n := pyLen(s)
+			if width <= n {
+				return s
+			}
+			sign := ""
+			rest := s
+			if rest != "" && (rest[0] == '-' || rest[0] == '+') {
+				sign, rest = rest[:1], rest[1:]
+			}
+			return sign + strings.Repeat("0", width-n) + rest
+		}
+	case "format":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			return it.strFormat(s, args, kw, nil)
+		}
+	case "format_map":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			d, ok := it.bindNative(q, args, kw, 1, "mapping")[0].(*pyDict)
+			if !ok {
+				it.raise("TypeError", "format_map() argument must be a mapping")
+			}
+			return it.strFormat(s, nil, nil, d)
+		}
+	case "partition", "rpartition":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			sep := it.strArg(name, it.bindNative(q, args, kw, 1, "sep")[0])
+			if sep == "" {
+				it.raise("ValueError", "empty separator")
+			}
+			i := strings.Index(s, sep)
+			if name == "rpartition" {
+				i = strings.LastIndex(s, sep)
+			}
+			if i < 0 {
+				if name == "rpartition" {
+					return &pyTuple{[]pyValue{"", "", s}}
+				}
+				return &pyTuple{[]pyValue{s, "", ""}}
+			}
+			return &pyTuple{[]pyValue{s[:i], sep, s[i+len(sep):]}}
+		}
+	case "removeprefix":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			return strings.TrimPrefix(s, it.strArg(name, it.bindNative(q, args, kw, 1, "prefix")[0]))
+		}
+	case "removesuffix":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			return strings.TrimSuffix(s, it.strArg(name, it.bindNative(q, args, kw, 1, "suffix")[0]))
+		}
+	case "expandtabs":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			size := int(it.optInt(it.bindNative(q, args, kw, 0, "tabsize")[0], 8))
+			var sb strings.Builder
+			col := 0
+			for _, r := range s {
+				switch r {
+				case '\t':
+					if size > 0 {
+						n := size - col%size
+						sb.WriteString(strings.Repeat(" ", n))
+						col += n
+					}
+				case '\n', '\r':
+					sb.WriteRune(r)
+					col = 0
+				default:
+					sb.WriteRune(r)
+					col++
+				}
+			}
+			return sb.String()
+		}
+	case "translate":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			table := it.bindNative(q, args, kw, 1, "table")[0]
+			var sb strings.Builder
+			for _, r := range s {
+				var repl pyValue
+				found := false
+				raised := it.catch(func() { repl, found = it.getItem(table, int64(r)), true })
+				if raised != nil {
+					if !it.isInstance(raised.exc, it.types["LookupError"]) {
+						panic(raised)
+					}
+				}
+				switch x := repl.(type) {
+				case nil:
+					sb.WriteRune(r)
+				case pyNoneType:
+				case string:
+					sb.WriteString(x)
+				case int64:
+					sb.WriteRune(rune(x))
+				default:
+					if found {
+						it.raise("TypeError", "character mapping must return integer, None or str")
+					}
+				}
+			}
+			return sb.String()
+		}
+	case "maketrans":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative(q, args, kw, 1, "x", "y", "z")
+			d := newPyDict()
+			if v[1] == nil {
+				src, ok := v[0].(*pyDict)
+				if !ok {
+					it.raise("TypeError", "if you give only one argument to maketrans it must be a dict")
+				}
+				for _, e := range src.items() {
+					k := e.key
+					if ks, ok := k.(string); ok {
+						r, _ := utf8.DecodeRuneInString(ks)
+						k = int64(r)
+					}
+					it.dictSet(d, k, e.value)
+				}
+				return d
+			}
+			from, to := []rune(it.strArg(name, v[0])), []rune(it.strArg(name, v[1]))
+			if len(from) != len(to) {
+				it.raise("ValueError", "the first two maketrans arguments must have equal length")
+			}
+			for i := range from {
+				it.dictSet(d, int64(from[i]), int64(to[i]))
+			}
+			if v[2] != nil {
+				for _, r := range it.strArg(name, v[2]) {
+					it.dictSet(d, int64(r), pyNone)
+				}
+			}
+			return d
+		}
+	case "encode":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			it.raise("NotImplementedError", "el intérprete integrado no admite bytes")
+			return nil
+		}
+	}
+	return nil
+}
+
+// strWindow devuelve s[start:end] (en caracteres) y la posición de inicio
+func (it *pyInterp) strWindow(s string, start, end pyValue) (string, int, bool) {
+	if (start == nil || start == pyNone) && (end == nil || end == pyNone) {
+		return s, 0, true
+	}
+	r := []rune(s)
+	sl := &pySlice{pyNone, pyNone, pyNone}
+	if start != nil {
+		sl.start = start
+	}
+	if end != nil {
+		sl.stop = end
+	}
+	from, to, _, _ := it.sliceIndices(len(r), sl)
+	if from > len(r) || to < from {
+		return "", 0, from <= len(r) && to >= from
+	}
+	return string(r[from:to]), from, true
+}
+
+// pySplitSpace implementa split() y rsplit() sin separador
+func pySplitSpace(s string, maxsplit int, fromRight bool) []string {
+	fields := strings.FieldsFunc(s, unicode.IsSpace)
+	if maxsplit < 0 || len(fields) <= maxsplit {
+		return fields
+	}
+	if maxsplit == 0 {
+		if fromRight {
+			return []string{strings.TrimRightFunc(s, unicode.IsSpace)}
+		}
+		return []string{strings.TrimLeftFunc(s, unicode.IsSpace)}
+	}
+	if !fromRight {
+		rest := strings.TrimLeftFunc(s, unicode.IsSpace)
+		out := make([]string, 0, maxsplit+1)
+		for i := 0; i < maxsplit; i++ {
+			j := strings.IndexFunc(rest, unicode.IsSpace)
+			out = append(out, rest[:j])
+			rest = strings.TrimLeftFunc(rest[j:], unicode.IsSpace)
+		}
+		return append(out, rest)
+	}
+	rest := strings.TrimRightFunc(s, unicode.IsSpace)
+	out := make([]string, maxsplit+1)
+	for i := maxsplit; i > 0; i-- {
+		j := strings.LastIndexFunc(rest, unicode.IsSpace)
+		_, size := utf8.DecodeRuneInString(rest[j:])
+		out[i] = rest[j+size:]
+		rest = strings.TrimRightFunc(rest[:j], unicode.IsSpace)
+	}
+	out[0] = rest
+	return out
+}
+
+// pyRSplit divide s por sep desde la derecha como máximo n veces
+func pyRSplit(s, sep string, n int) []string {
+	var out []string
+	for n > 0 {
+		i := strings.LastIndex(s, sep)
+		if i < 0 {
+			break
+		}
+		out = append(out, s[i+len(sep):])
+		s = s[:i]
+		n--
+	}
+	out = append(out, s)
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
+
+// seqMethod implementa index y count de listas, tuplas y rangos
+func (it *pyInterp) seqMethod(items []pyValue, kind, name string) pyNative {
+	q := kind + "." + name
+	switch name {
+	case "count":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			x := it.bindNative(q, args, kw, 1, "value")[0]
+			n := int64(0)
+			for _, v := range items {
+				if v == x || it.equal(v, x) {
+					n++
+				}
+			}
+			return n
+		}
+	case "index":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative(q, args, kw, 1, "value", "start", "stop")
+			sl := &pySlice{pyNone, pyNone, pyNone}
+			if v[1] != nil {
+				sl.start = v[1]
+			}
+			if v[2] != nil {
+				sl.stop = v[2]
+			}
+			start, stop, _, _ := it.sliceIndices(len(items), sl)
+			for i := start; i < stop && i < len(items); i++ {
+				if items[i] == v[0] || it.equal(items[i], v[0]) {
+					return int64(i)
+				}
+			}
+			switch kind {
+			case "list":
+				it.raise("ValueError", "%s is not in list", it.repr(v[0]))
+			case "range":
+				it.raise("ValueError", "%s is not in range", it.repr(v[0]))
+			}
+			it.raise("ValueError", "%s.index(x): x not in %s", kind, kind)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (it *pyInterp) tupleMethod(t *pyTuple, name string) pyNative {
+	return it.seqMethod(t.items, "tuple", name)
+}
+
+func (it *pyInterp) listMethod(l *pyList, name string) pyNative {
+	q := "list." + name
+	if l.deque {
+		q = "deque." + name
+	}
+	switch name {
+	case "append":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			it.listAppend(l, it.bindNative(q, args, kw, 1, "object")[0])
+			return pyNone
+		}
+	case "extend":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			for _, v := range it.toList(it.bindNative(q, args, kw, 1, "iterable")[0]) {
+				it.listAppend(l, v)
+			}
+			return pyNone
+		}
+	case "insert":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative(q, args, kw, 2, "index", "object")
+			i := int(max(min(it.intArg(v[0]), math.MaxInt32), -math.MaxInt32))
+			if i < 0 {
+				i = max(i+len(l.items), 0)
+			}
+			i = min(i, len(l.items))
+			it.b.alloc(pyValueCost)
+			l.items = append(l.items, nil)
+			copy(l.items[i+1:], l.items[i:])
+			l.items[i] = v[1]
+			return pyNone
+		}
+	case "remove":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			x := it.bindNative(q, args, kw, 1, "value")[0]
+			for i, v := range l.items {
+				if v == x || it.equal(v, x) {
+					l.items = append(l.items[:i], l.items[i+1:]...)
+					return pyNone
+				}
+			}
+			if l.deque {
+				it.raise("ValueError", "%s is not in deque", it.repr(x))
+			}
+			it.raise("ValueError", "list.remove(x): x not in list")
+			return nil
+		}
+	case "pop":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			if l.deque {
+				it.bindNative(q, args, kw, 0)
+			}
+			v := it.bindNative(q, args, kw, 0, "index")
+			if len(l.items) == 0 {
+				if l.deque {
+					it.raise("IndexError", "pop from an empty deque")
+				}
+				it.raise("IndexError", "pop from empty list")
+			}
+			i := it.optInt(v[0], -1)
+			if i < 0 {
+				i += int64(len(l.items))
+			}
+			if i < 0 || i >= int64(len(l.items)) {
+				it.raise("IndexError", "pop index out of range")
+			}
+			item := l.items[i]
+			l.items = append(l.items[:i], l.items[i+1:]...)
+			return item
+		}
+	case "clear":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			it.bindNative(q, args, kw, 0)
+			l.items = []pyValue{}
+			return pyNone
+		}
+	case "copy":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			it.bindNative(q, args, kw, 0)
+			return &pyList{items: it.toList(l), deque: l.deque, maxlen: l.maxlen}
+		}
+	case "reverse":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			it.bindNative(q, args, kw, 0)
+			for i, j := 0, len(l.items)-1; i < j; i, j = i+1, j-1 {
+				l.items[i], l.items[j] = l.items[j], l.items[i]
+			}
+			return pyNone
+		}
+	case "sort":
+		if l.deque {
+			return nil
+		}
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			if len(args) > 0 {
+				it.raise("TypeError", "sort() takes no positional arguments")
+			}
+			v := it.bindNative(q, nil, kw, 0, "key", "reverse")
+			it.sortValues(l.items, v[0], v[1] != nil && it.truthy(v[1]))
+			return pyNone
+		}
+	case "index", "count":
+		kind := "list"
+		if l.deque {
+			kind = "deque"
+		}
+		return it.seqMethod(l.items, kind, name)
+	}
+	return nil
+}
+
+// listAppend agrega v respetando el maxlen de un deque
+func (it *pyInterp) listAppend(l *pyList, v pyValue) {
+	it.b.alloc(pyValueCost)
+	l.items = append(l.items, v)
+	if l.maxlen >= 0 && len(l.items) > l.maxlen {
+		l.items = l.items[len(l.items)-l.maxlen:]
+	}
+}
+
+func (it *pyInterp) dequeMethod(l *pyList, name string) pyNative {
+	q := "deque." + name
+	switch name {
+	case "appendleft":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative(q, args, kw, 1, "x")[0]
+			it.b.alloc(pyValueCost)
+			l.items = append([]pyValue{v}, l.items...)
+			if l.maxlen >= 0 && len(l.items) > l.maxlen {
+				l.items = l.items[:l.maxlen]
+			}
+			return pyNone
+		}
+	case "extendleft":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			for _, v := range it.toList(it.bindNative(q, args, kw, 1, "iterable")[0]) {
+				l.items = append([]pyValue{v}, l.items...)
+				if l.maxlen >= 0 && len(l.items) > l.maxlen {
+					l.items = l.items[:l.maxlen]
+				}
+			}
+			return pyNone
+		}
+	case "popleft":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			it.bindNative(q, args, kw, 0)
+			if len(l.items) == 0 {
+				it.raise("IndexError", "pop from an empty deque")
+			}
+			v := l.items[0]
+			l.items = l.items[1:]
+			return v
+		}
+	case "rotate":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			n := it.optInt(it.bindNative(q, args, kw, 0, "n")[0], 1)
+			if len(l.items) > 0 {
+				k := int(((n % int64(len(l.items))) + int64(len(l.items))) % int64(len(l.items)))
+				l.items = append(append([]pyValue(nil), l.items[len(l.items)-k:]...), l.items[:len(l.items)-k]...)
+			}
+			return pyNone
+		}
+	}
+	return nil
+}
+
+func (it *pyInterp) dictMethod(d *pyDict, name string) pyNative {
+	q := "dict." + name
+	switch name {
+	case "keys", "values", "items":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			it.bindNative(q, args, kw, 0)
+			return &pyView{dict: d, kind: "dict_" + name}
+		}
+	case "get":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative(q, args, kw, 1, "key", "default")
+			if r, ok := it.dictGet(d, v[0]); ok {
+				return r
+			}
+			if v[1] == nil {
+				return pyNone
+			}
+			return v[1]
+		}
+	case "setdefault":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative(q, args, kw, 1, "key", "default")
+			if r, ok := it.dictGet(d, v[0]); ok {
+				return r
+			}
+			if v[1] == nil {
+				v[1] = pyNone
+			}
+			it.dictSet(d, v[0], v[1])
+			return v[1]
+		}
+	case "pop":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative(q, args, kw, 1, "key", "default")
+			if r, ok := it.dictDelete(d, v[0]); ok {
+				return r
+			}
+			if v[1] == nil {
+				it.raiseValue("KeyError", v[0])
+			}
+			return v[1]
+		}
+	case "popitem":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative(q, args, kw, 0, "last")
+			items := d.items()
+			if len(items) == 0 {
+				it.raise("KeyError", "popitem(): dictionary is empty")
+			}
+			e := items[len(items)-1]
+			if v[0] != nil && !it.truthy(v[0]) {
+				e = items[0]
+			}
+			it.dictDelete(d, e.key)
+			return &pyTuple{[]pyValue{e.key, e.value}}
+		}
+	case "update":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative(q, args, nil, 0, "other")
+			if d.kind == "Counter" {
+				it.counterUpdate(d, v[0], kw, 1)
+				return pyNone
+			}
+			if v[0] != nil {
+				it.dictUpdate(d, v[0])
+			}
+			for _, k := range kw {
+				it.dictSet(d, k.name, k.value)
+			}
+			return pyNone
+		}
+	case "clear":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			it.bindNative(q, args, kw, 0)
+			d.entries, d.index, d.live = nil, make(map[interface{}]int), 0
+			return pyNone
+		}
+	case "copy":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			it.bindNative(q, args, kw, 0)
+			c := &pyDict{index: make(map[interface{}]int, d.live), kind: d.kind, factory: d.factory}
+			for _, e := range d.items() {
+				it.dictSet(c, e.key, e.value)
+			}
+			return c
+		}
+	case "fromkeys":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative(q, args, kw, 1, "iterable", "value")
+			if v[1] == nil {
+				v[1] = pyNone
+			}
+			c := newPyDict()
+			for _, k := range it.toList(v[0]) {
+				it.dictSet(c, k, v[1])
+			}
+			return c
+		}
+	}
+	switch d.kind + "." + name {
+	case "Counter.most_common":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative("most_common", args, kw, 0, "n")
+			entries := it.mostCommon(d)
+			if v[0] != nil && v[0] != pyNone {
+				entries = entries[:max(min(int(it.intArg(v[0])), len(entries)), 0)]
+			}
+			items := make([]pyValue, len(entries))
+			for i, e := range entries {
+				items[i] = &pyTuple{[]pyValue{e.key, e.value}}
+			}
+			return &pyList{items: items, maxlen: -1}
+		}
+	case "Counter.elements":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			it.bindNative("elements", args, kw, 0)
+			var items []pyValue
+			for _, e := range d.items() {
+				n, _ := pyIntArg(e.value)
+				for i := int64(0); i < n; i++ {
+					it.b.alloc(pyValueCost)
+					items = append(items, e.key)
+				}
+			}
+			return it.newIterator("itertools.chain", items)
+		}
+	case "Counter.subtract":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative("subtract", args, nil, 0, "iterable")
+			it.counterUpdate(d, v[0], kw, -1)
+			return pyNone
+		}
+	case "Counter.total":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			it.bindNative("total", args, kw, 0)
+			var total pyValue = int64(0)
+			for _, e := range d.items() {
+				total = it.binary("+", total, e.value)
+			}
+			return total
+		}
+	case "OrderedDict.move_to_end":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative("move_to_end", args, kw, 1, "key", "last")
+			value, ok := it.dictDelete(d, v[0])
+			if !ok {
+				it.raiseValue("KeyError", v[0])
+			}
+			if v[1] == nil || it.truthy(v[1]) {
+				it.dictSet(d, v[0], value)
+				return pyNone
+			}
+			rest := d.items()
+			d.entries, d.index, d.live = nil, make(map[interface{}]int), 0
+			it.dictSet(d, v[0], value)
+			for _, e := range rest {
+				it.dictSet(d, e.key, e.value)
+			}
+			return pyNone
+		}
+	}
+	return nil
+}
+
+// mostCommon ordena las entradas de un Counter por cantidad descendente
+func (it *pyInterp) mostCommon(d *pyDict) []pyEntry {
+	entries := append([]pyEntry(nil), d.items()...)
+	sort.SliceStable(entries, func(i, j int) bool { return it.less(entries[j].value, entries[i].value) })
+	return entries
+}
+
+// counterUpdate suma (o resta con sign -1) las cantidades de src a un Counter
+func (it *pyInterp) counterUpdate(d *pyDict, src pyValue, kw []pyKeyword, sign int64) {
+	add := func(k, n pyValue) {
+		cur, ok := it.dictGet(d, k)
+		if !ok {
+			cur = int64(0)
+		}
+		if sign < 0 {
+			it.dictSet(d, k, it.binary("-", cur, n))
+		} else {
+			it.dictSet(d, k, it.binary("+", cur, n))
+		}
+	}
+	switch x := src.(type) {
+	case nil:
+	case *pyDict:
+		if !x.set {
+			for _, e := range x.items() {
+				add(e.key, e.value)
+			}
+			break
+		}
+		for _, k := range it.toList(x) {
+			add(k, int64(1))
+		}
+	default:
+		next := it.iter(src)
+		for {
+			k, ok := next()
+			if !ok {
+				break
+			}
+			it.b.step()
+			add(k, int64(1))
+		}
+	}
+	for _, k := range kw {
+		add(k.name, k.value)
+	}
+}
+
+func (it *pyInterp) setMethod(d *pyDict, name string) pyNative {
+	q := "set." + name
+	if d.kind == "frozenset" {
+		q = "frozenset." + name
+		switch name {
+		case "add", "remove", "discard", "pop", "clear", "update", "intersection_update", "difference_update", "symmetric_difference_update":
+			return nil
+		}
+	}
+	others := func(args []pyValue) []*pyDict {
+		out := make([]*pyDict, len(args))
+		for i, a := range args {
+			if s, ok := a.(*pyDict); ok && s.set {
+				out[i] = s
+			} else {
+				out[i] = it.newSet(a, "")
+			}
+		}
+		return out
+	}
+	combine := func(op string, args []pyValue) *pyDict {
+		r := it.binary("|", d, newPySet()).(*pyDict)
+		r.kind = d.kind
+		for _, o := range others(args) {
+			r = it.setOp(op, r, o).(*pyDict)
+		}
+		return r
+	}
+	replace := func(r *pyDict) {
+		d.entries, d.index, d.live, d.table, d.fill = r.entries, r.index, r.live, r.table, r.fill
+	}
+	switch name {
+	case "add":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			it.dictSet(d, it.bindNative(q, args, kw, 1, "object")[0], nil)
+			return pyNone
+		}
+	case "remove", "discard":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			x := it.bindNative(q, args, kw, 1, "object")[0]
+			if _, ok := it.dictDelete(d, x); !ok && name == "remove" {
+				it.raiseValue("KeyError", x)
+			}
+			return pyNone
+		}
+	case "pop":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			it.bindNative(q, args, kw, 0)
+			items := it.setItems(d)
+			if len(items) == 0 {
+				it.raise("KeyError", "pop from an empty set")
+			}
+			it.dictDelete(d, items[0].key)
+			return items[0].key
+		}
+	case "clear":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			it.bindNative(q, args, kw, 0)
+			replace(newPySet())
+			return pyNone
+		}
+	case "copy":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			it.bindNative(q, args, kw, 0)
+			return combine("|", nil)
+		}
+	case "union", "update":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			it.bindNative(q, nil, kw, 0)
+			r := combine("|", args)
+			if name == "update" {
+				replace(r)
+				return pyNone
+			}
+			return r
+		}
+	case "intersection", "intersection_update":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			it.bindNative(q, nil, kw, 0)
+			r := combine("&", args)
+			if name == "intersection_update" {
+				replace(r)
+				return pyNone
+			}
+			return r
+		}
+	case "difference", "difference_update":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			it.bindNative(q, nil, kw, 0)
+			r := combine("-", args)
+			if name == "difference_update" {
+				replace(r)
+				return pyNone
+			}
+			return r
+		}
+	case "symmetric_difference", "symmetric_difference_update":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			r := combine("^", it.bindNative(q, args, kw, 1, "other"))
+			if name == "symmetric_difference_update" {
+				replace(r)
+				return pyNone
+			}
+			return r
+		}
+	case "issubset", "issuperset", "isdisjoint":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			o := others(it.bindNative(q, args, kw, 1, "other"))[0]
+			switch name {
+			case "issubset":
+				return it.order("<=", d, o)
+			case "issuperset":
+				return it.order(">=", d, o)
+			}
+			return it.setOp("&", d, o).(*pyDict).live == 0
+		}
+	}
+	return nil
+}
+
+func (it *pyInterp) iteratorMethod(x *pyIterator, name string) pyNative {
+	switch name {
+	case "__next__":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			if v, ok := x.next(); ok {
+				return v
+			}
+			it.raiseValue("StopIteration")
+			return nil
+		}
+	case "__iter__":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue { return x }
+	}
+	if x.gen == nil {
+		return nil
+	}
+	switch name {
+	case "send":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v, ok := x.gen.resume(it, it.bindNative("send", args, kw, 1, "value")[0])
+			if !ok {
+				it.raiseValue("StopIteration")
+			}
+			return v
+		}
+	case "close":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			it.bindNative("close", args, kw, 0)
+			x.gen.close()
+			return pyNone
+		}
+	}
+	return nil
+}
+
+func (it *pyInterp) fileMethod(f *pyFile, name string) pyNative {
+	switch name {
+	case "read":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			return it.fileRead(f, it.optInt(it.bindNative("read", args, kw, 0, "size")[0], -1))
+		}
+	case "readline":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			it.bindNative("readline", args, kw, 0, "size")
+			return it.fileReadLine(f)
+		}
+	case "readlines":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			it.bindNative("readlines", args, kw, 0, "hint")
+			return &pyList{items: it.toList(f), maxlen: -1}
+		}
+	case "write":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative("write", args, kw, 1, "text")[0]
+			s, ok := v.(string)
+			if !ok {
+				it.raise("TypeError", "write() argument must be str, not %s", it.typeName(v))
+			}
+			it.fileWrite(f, s)
+			return int64(pyLen(s))
+		}
+	case "flush":
+		return func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue { return pyNone }
+	}
+	return nil
+}
@@ -0,0 +1,575 @@
+package main
+
+import (
+	"math"
+	"math/big"
+)
+
+// ──────────── Módulos de la biblioteca estándar del intérprete Python ───────────
+//
+// Solo los módulos que usan los ejercicios de la tabla de
+// interp_python_test.go: math, sys, collections (deque, defaultdict y
+// Counter) e itertools (count, chain, permutations, combinations, product y
+// accumulate). Cada uno se crea la primera vez que se importa; un import de
+// cualquier otro módulo lanza ModuleNotFoundError, y el programa se puede
+// ejecutar con python3.
+
+var pyModules map[string]func(it *pyInterp, attrs map[string]pyValue)
+
+func init() {
+	pyModules = map[string]func(it *pyInterp, attrs map[string]pyValue){
+		"math":        pyMathModule,
+		"sys":         pySysModule,
+		"collections": pyCollectionsModule,
+		"itertools":   pyItertoolsModule,
+	}
+}
+
+// pyDefine agrega una función nativa a un módulo
+func pyDefine(attrs map[string]pyValue, name string, fn pyNative) *pyFunction {
+	f := pyBuiltin(name, fn)
+	attrs[name] = f
+	return f
+}
+
+// ───── math ─────
+
+// realArg convierte un argumento de math a float
+func (it *pyInterp) realArg(v pyValue) float64 {
+	n, ok := pyNumber(v)
+	if !ok {
+		if inst, isInst := v.(*pyInstance); isInst {
+			if m, found := it.lookupAttr(inst, "__float__"); found {
+				return it.toFloat(it.call(m, nil, nil))
+			}
+		}
+		it.raise("TypeError", "must be real number, not %s", it.typeName(v))
+	}
+	if b, isBig := n.(*big.Int); isBig {
+		f, _ := new(big.Float).SetInt(b).Float64()
+		if math.IsInf(f, 0) {
+			it.raise("OverflowError", "int too large to convert to float")
+		}
+		return f
+	}
+	return it.toFloat(n)
+}
+
+// mathResult valida el resultado de una función de math como CPython
+func (it *pyInterp) mathResult(x, r float64) pyValue {
+	switch {
+	case math.IsNaN(r) && !math.IsNaN(x):
+		it.raise("ValueError", "math domain error")
+	case math.IsInf(r, 0) && !math.IsInf(x, 0):
+		it.raise("OverflowError", "math range error")
+	}
+	return r
+}
+
+func pyMathModule(it *pyInterp, attrs map[string]pyValue) {
+	attrs["pi"], attrs["e"], attrs["tau"] = math.Pi, math.E, 2*math.Pi
+	attrs["inf"], attrs["nan"] = math.Inf(1), math.NaN()
+	unary := map[string]func(float64) float64{
+		"sin": math.Sin, "cos": math.Cos, "tan": math.Tan, "asin": math.Asin,
+		"acos": math.Acos, "atan": math.Atan, "sinh": math.Sinh, "cosh": math.Cosh,
+		"tanh": math.Tanh, "asinh": math.Asinh, "acosh": math.Acosh, "atanh": math.Atanh,
+		"exp": math.Exp, "expm1": math.Expm1, "log1p": math.Log1p, "fabs": math.Abs,
+		"sqrt": math.Sqrt, "log2": math.Log2, "log10": math.Log10, "erf": math.Erf,
+		"erfc": math.Erfc, "gamma": math.Gamma,
+		"degrees": func(x float64) float64 { return x * 180 / math.Pi },
+		"radians": func(x float64) float64 { return x * math.Pi / 180 },
+		"lgamma": func(x float64) float64 {
+			r, _ := math.Lgamma(x)
+			return r
+		},
+	}
+	for name, f := range unary {
+		name, f := name, f
+		pyDefine(attrs, name, func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			x := it.realArg(it.bindNative(name, args, kw, 1, "x")[0])
+			if (name == "log2" || name == "log10") && x <= 0 || name == "gamma" && x <= 0 && x == math.Trunc(x) {
+				it.raise("ValueError", "math domain error")
+			}
+			return it.mathResult(x, f(x))
+		})
+	}
+	pyDefine(attrs, "log", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		v := it.bindNative("log", args, kw, 1, "x", "base")
+		ln := func(v pyValue) float64 {
+			// Los enteros enormes se calculan por partes para no desbordar
+			if b, ok := v.(*big.Int); ok && b.Sign() > 0 && b.BitLen() > 1000 {
+				shift := b.BitLen() - 60
+				top, _ := new(big.Float).SetInt(new(big.Int).Rsh(b, uint(shift))).Float64()
+				return math.Log(top) + float64(shift)*math.Ln2
+			}
+			x := it.realArg(v)
+			if x <= 0 {
+				it.raise("ValueError", "math domain error")
+			}
+			return math.Log(x)
+		}
+		r := ln(v[0])
+		if v[1] != nil {
+			d := ln(v[1])
+			if d == 0 {
+				it.raise("ZeroDivisionError", "float division by zero")
+			}
+			r /= d
+		}
+		return r
+	})
+	for _, name := range []string{"floor", "ceil", "trunc"} {
+		name := name
+		pyDefine(attrs, name, func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative(name, args, kw, 1, "x")[0]
+			switch x := v.(type) {
+			case int64, *big.Int:
+				return x
+			case bool:
+				n, _ := pyIntArg(x)
+				return n
+			case *pyInstance:
+				if m, ok := it.lookupAttr(x, "__"+name+"__"); ok {
+					return it.call(m, nil, nil)
+				}
+			}
+			f := it.realArg(v)
+			if math.IsInf(f, 0) {
+				it.raise("OverflowError", "cannot convert float infinity to integer")
+			}
+			if math.IsNaN(f) {
+				it.raise("ValueError", "cannot convert float NaN to integer")
+			}
+			switch name {
+			case "floor":
+				f = math.Floor(f)
+			case "ceil":
+				f = math.Ceil(f)
+			default:
+				f = math.Trunc(f)
+			}
+			return pyFloatToInt(f)
+		})
+	}
+	pyDefine(attrs, "pow", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		v := it.bindNative("pow", args, kw, 2, "x", "y")
+		x, y := it.realArg(v[0]), it.realArg(v[1])
+		if x == 0 && y < 0 || x < 0 && y != math.Trunc(y) {
+			it.raise("ValueError", "math domain error")
+		}
+		r := math.Pow(x, y)
+		if math.IsInf(r, 0) && !math.IsInf(x, 0) && !math.IsInf(y, 0) {
+			it.raise("OverflowError", "math range error")
+		}
+		return r
+	})
+	pyDefine(attrs, "isclose", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		v := it.bindNative("isclose", args, kw, 2, "a", "b", "rel_tol", "abs_tol")
+		a, b := it.realArg(v[0]), it.realArg(v[1])
+		rel, abs := 1e-9, 0.0
+		if v[2] != nil {
+			rel = it.realArg(v[2])
+		}
+		if v[3] != nil {
+			abs = it.realArg(v[3])
+		}
+		if rel < 0 || abs < 0 {
+			it.raise("ValueError", "tolerances must be non-negative")
+		}
+		if a == b {
+			return true
+		}
+		if math.IsInf(a, 0) || math.IsInf(b, 0) {
+			return false
+		}
+		diff := math.Abs(b - a)
+		return diff <= math.Abs(rel*b) || diff <= math.Abs(rel*a) || diff <= abs
+	})
+	for name, pred := range map[string]func(float64) bool{
+		"isinf":    func(x float64) bool { return math.IsInf(x, 0) },
+		"isnan":    math.IsNaN,
+		"isfinite": func(x float64) bool { return !math.IsInf(x, 0) && !math.IsNaN(x) },
+	} {
+		name, pred := name, pred
+		pyDefine(attrs, name, func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			return pred(it.realArg(it.bindNative(name, args, kw, 1, "x")[0]))
+		})
+	}
+	pyDefine(attrs, "factorial", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		n := it.intArg(it.bindNative("factorial", args, kw, 1, "n")[0])
+		if n < 0 {
+			it.raise("ValueError", "factorial() not defined for negative values")
+		}
+		r := big.NewInt(1)
+		for i := int64(2); i <= n; i++ {
+			it.b.step()
+			r.Mul(r, big.NewInt(i))
+			it.b.alloc(8)
+		}
+		return pyNormInt(r)
+	})
+	intFold := func(name string, op func(a, b *big.Int) *big.Int, empty int64) {
+		pyDefine(attrs, name, func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			it.bindNative(name, nil, kw, 0)
+			r := big.NewInt(empty)
+			for i, a := range args {
+				n := pyBig(a)
+				if n == nil {
+					it.raise("TypeError", "'%s' object cannot be interpreted as an integer", it.typeName(a))
+				}
+				if i == 0 {
+					r = new(big.Int).Abs(n)
+					continue
+				}
+				r = op(r, new(big.Int).Abs(n))
+			}
+			return pyNormInt(r)
+		})
+	}
+	intFold("gcd", func(a, b *big.Int) *big.Int { return new(big.Int).GCD(nil, nil, a, b) }, 0)
+	intFold("lcm", func(a, b *big.Int) *big.Int {
+		if a.Sign() == 0 || b.Sign() == 0 {
+			return new(big.Int)
+		}
+		g := new(big.Int).GCD(nil, nil, a, b)
+		return new(big.Int).Mul(new(big.Int).Div(a, g), b)
+	}, 1)
+	pyDefine(attrs, "isqrt", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		v := it.bindNative("isqrt", args, kw, 1, "n")[0]
+		n := pyBig(v)
+		if n == nil {
+			it.raise("TypeError", "'%s' object cannot be interpreted as an integer", it.typeName(v))
+		}
+		if n.Sign() < 0 {
+			it.raise("ValueError", "isqrt() argument must be nonnegative")
+		}
+		return pyNormInt(new(big.Int).Sqrt(n))
+	})
+	pyDefine(attrs, "comb", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		v := it.bindNative("comb", args, kw, 2, "n", "k")
+		n, k := it.intArg(v[0]), it.intArg(v[1])
+		if n < 0 || k < 0 {
+			it.raise("ValueError", "%s must be a non-negative integer", map[bool]string{true: "n", false: "k"}[n < 0])
+		}
+		if k > n {
+			return int64(0)
+		}
+		return pyNormInt(new(big.Int).Binomial(n, k))
+	})
+}
+
+// ───── sys ─────
+
+func pySysModule(it *pyInterp, attrs map[string]pyValue) {
+	argv := []pyValue{pyFileName}
+	for _, a := range it.input.Args {
+		argv = append(argv, a)
+	}
+	attrs["argv"] = &pyList{items: argv, maxlen: -1}
+	attrs["stdin"] = &pyFile{name: "<stdin>", mode: "r", std: pyStdin}
+	attrs["stdout"] = &pyFile{name: "<stdout>", mode: "w", std: pyStdout}
+	attrs["stderr"] = &pyFile{name: "<stderr>", mode: "w", std: pyStderr}
+	attrs["maxsize"] = int64(math.MaxInt64)
+	attrs["version"] = "3.11.0 (intérprete integrado)"
+	attrs["version_info"] = &pyTuple{[]pyValue{int64(3), int64(11), int64(0), "final", int64(0)}}
+	attrs["platform"] = "linux"
+	attrs["path"] = &pyList{items: []pyValue{}, maxlen: -1}
+	attrs["exit"] = it.builtins["exit"]
+	pyDefine(attrs, "getrecursionlimit", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		it.bindNative("getrecursionlimit", args, kw, 0)
+		return int64(it.limit)
+	})
+	pyDefine(attrs, "setrecursionlimit", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		n := it.intArg(it.bindNative("setrecursionlimit", args, kw, 1, "limit")[0])
+		if n < 1 {
+			it.raise("ValueError", "recursion limit must be greater or equal than 1")
+		}
+		// El límite real lo pone la pila del servidor, no el programa
+		it.limit = int(min(n, pyMaxRecursionLimit))
+		return pyNone
+	})
+}
+
+// ───── collections ─────
+
+func pyCollectionsModule(it *pyInterp, attrs map[string]pyValue) {
+	dict := it.types["dict"]
+	subtype := func(name string, base *pyClass, construct pyNative) *pyClass {
+		cls := it.builtinType(name)
+		cls.construct = construct
+		if base != nil {
+			cls.bases = []*pyClass{base}
+			cls.mro = append([]*pyClass{cls}, base.mro...)
+		}
+		attrs[name] = cls
+		return cls
+	}
+	subtype("deque", nil, func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		v := it.bindNative("deque", args, kw, 0, "iterable", "maxlen")
+		l := &pyList{items: []pyValue{}, deque: true, maxlen: -1}
+		if v[1] != nil && v[1] != pyNone {
+			l.maxlen = int(it.intArg(v[1]))
+			if l.maxlen < 0 {
+				it.raise("ValueError", "maxlen must be non-negative")
+			}
+		}
+		if v[0] != nil {
+			for _, item := range it.toList(v[0]) {
+				it.listAppend(l, item)
+			}
+		}
+		return l
+	})
+	subtype("defaultdict", dict, func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		d := newPyDict()
+		d.kind = "defaultdict"
+		if len(args) > 0 {
+			if args[0] != pyNone {
+				switch args[0].(type) {
+				case *pyFunction, *pyBound, *pyClass:
+				default:
+					it.raise("TypeError", "first argument must be callable or None")
+				}
+			}
+			d.factory = args[0]
+			args = args[1:]
+		}
+		if len(args) > 1 {
+			it.raise("TypeError", "dict expected at most 1 argument, got %d", len(args))
+		}
+		if len(args) == 1 {
+			it.dictUpdate(d, args[0])
+		}
+		for _, k := range kw {
+			it.dictSet(d, k.name, k.value)
+		}
+		return d
+	})
+	subtype("Counter", dict, func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		v := it.bindNative("Counter", args, nil, 0, "iterable")
+		d := newPyDict()
+		d.kind = "Counter"
+		it.counterUpdate(d, v[0], kw, 1)
+		return d
+	})
+}
+
+// ───── itertools ─────
+
+func pyItertoolsModule(it *pyInterp, attrs map[string]pyValue) {
+	iterator := func(kind string, next func() (pyValue, bool)) *pyIterator {
+		return &pyIterator{kind: "itertools." + kind, next: next}
+	}
+	tuple := func(pool []pyValue, indices []int) pyValue {
+		items := make([]pyValue, len(indices))
+		for i, j := range indices {
+			items[i] = pool[j]
+		}
+		it.b.alloc(len(items) * pyValueCost)
+		return &pyTuple{items}
+	}
+	pyDefine(attrs, "count", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		v := it.bindNative("count", args, kw, 0, "start", "step")
+		var n, step pyValue = int64(0), int64(1)
+		if v[0] != nil {
+			n = v[0]
+		}
+		if v[1] != nil {
+			step = v[1]
+		}
+		return iterator("count", func() (pyValue, bool) {
+			cur := n
+			n = it.binary("+", n, step)
+			return cur, true
+		})
+	})
+	chain := func(sources func() (pyValue, bool)) *pyIterator {
+		var cur func() (pyValue, bool)
+		return iterator("chain", func() (pyValue, bool) {
+			for {
+				if cur != nil {
+					if v, ok := cur(); ok {
+						return v, true
+					}
+				}
+				src, ok := sources()
+				if !ok {
+					return nil, false
+				}
+				cur = it.iter(src)
+			}
+		})
+	}
+	ch := pyDefine(attrs, "chain", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		it.bindNative("chain", nil, kw, 0)
+		return chain(it.newIterator("tuple_iterator", args).next)
+	})
+	ch.attrs = map[string]pyValue{"from_iterable": pyBuiltin("from_iterable", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		return chain(it.iter(it.bindNative("from_iterable", args, kw, 1, "iterable")[0]))
+	})}
+	pyDefine(attrs, "permutations", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		v := it.bindNative("permutations", args, kw, 1, "iterable", "r")
+		pool := it.toList(v[0])
+		n := len(pool)
+		r := int(it.optInt(v[1], int64(n)))
+		if r < 0 {
+			it.raise("ValueError", "r must be non-negative")
+		}
+		indices := make([]int, n)
+		for i := range indices {
+			indices[i] = i
+		}
+		cycles := make([]int, max(r, 0))
+		for i := range cycles {
+			cycles[i] = n - i
+		}
+		first, done := true, r > n
+		return iterator("permutations", func() (pyValue, bool) {
+			if done {
+				return nil, false
+			}
+			if first {
+				first = false
+				return tuple(pool, indices[:r]), true
+			}
+			for i := r - 1; i >= 0; i-- {
+				it.b.step()
+				cycles[i]--
+				if cycles[i] == 0 {
+					moved := indices[i]
+					copy(indices[i:], indices[i+1:])
+					indices[n-1] = moved
+					cycles[i] = n - i
+					continue
+				}
+				j := cycles[i]
+				indices[i], indices[n-j] = indices[n-j], indices[i]
+				return tuple(pool, indices[:r]), true
+			}
+			done = true
+			return nil, false
+		})
+	})
+	combinations := func(name string, replace bool) {
+		pyDefine(attrs, name, func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+			v := it.bindNative(name, args, kw, 2, "iterable", "r")
+			pool := it.toList(v[0])
+			n, r := len(pool), int(it.intArg(v[1]))
+			if r < 0 {
+				it.raise("ValueError", "r must be non-negative")
+			}
+			indices := make([]int, r)
+			for i := range indices {
+				if !replace {
+					indices[i] = i
+				}
+			}
+			first := true
+			done := !replace && r > n || replace && n == 0 && r > 0
+			return iterator(name, func() (pyValue, bool) {
+				if done {
+					return nil, false
+				}
+				if first {
+					first = false
+					return tuple(pool, indices), true
+				}
+				i := r - 1
+				for ; i >= 0; i-- {
+					if replace && indices[i] != n-1 || !replace && indices[i] != i+n-r {
+						break
+					}
+				}
+				if i < 0 {
+					done = true
+					return nil, false
+				}
+				indices[i]++
+				for j := i + 1; j < r; j++ {
+					if replace {
+						indices[j] = indices[i]
+					} else {
+						indices[j] = indices[j-1] + 1
+					}
+				}
+				return tuple(pool, indices), true
+			})
+		})
+	}
+	combinations("combinations", false)
+	combinations("combinations_with_replacement", true)
+	pyDefine(attrs, "product", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		v := it.bindNative("product", nil, kw, 0, "repeat")
+		repeat := int(it.optInt(v[0], 1))
+		var pools [][]pyValue
+		for i := 0; i < repeat; i++ {
+			for _, a := range args {
+				pools = append(pools, it.toList(a))
+			}
+		}
+		indices := make([]int, len(pools))
+		first, done := true, false
+		for _, p := range pools {
+			done = done || len(p) == 0
+		}
+		return iterator("product", func() (pyValue, bool) {
+			if done {
+				return nil, false
+			}
+			if !first {
+				i := len(pools) - 1
+				for ; i >= 0; i-- {
+					indices[i]++
+					if indices[i] < len(pools[i]) {
+						break
+					}
+					indices[i] = 0
+				}
+				if i < 0 {
+					done = true
+					return nil, false
+				}
+			}
+			first = false
+			items := make([]pyValue, len(pools))
+			for i, p := range pools {
+				items[i] = p[indices[i]]
+			}
+			it.b.alloc(len(items) * pyValueCost)
+			return &pyTuple{items}, true
+		})
+	})
+	pyDefine(attrs, "accumulate", func(it *pyInterp, args []pyValue, kw []pyKeyword) pyValue {
+		v := it.bindNative("accumulate", args, kw, 1, "iterable", "func", "initial")
+		next := it.iter(v[0])
+		var acc pyValue
+		if v[2] != nil && v[2] != pyNone {
+			acc = v[2]
+			initial := true
+			inner := next
+			next = func() (pyValue, bool) {
+				if initial {
+					initial = false
+					return nil, true
+				}
+				return inner()
+			}
+		}
+		return iterator("accumulate", func() (pyValue, bool) {
+			item, ok := next()
+			if !ok {
+				return nil, false
+			}
+			switch {
+			case item == nil:
+			case acc == nil:
+				acc = item
+			case v[1] == nil || v[1] == pyNone:
+				acc = it.binary("+", acc, item)
+			default:
+				acc = it.call(v[1], []pyValue{acc, item}, nil)
+			}
+			return acc, true
+		})
+	})
+}
@@ -0,0 +1,1191 @@
+package main
+
+import "testing"
+
+// Salidas de CPython 3.11 (python3 main.py) para el subconjunto que cubre el
+// intérprete integrado; un caso nuevo se agrega con la salida del real
+var pythonInterpCases = []interpCase{
+	{
+		name: "aritmetica",
+		code: `print(7 // 2, -7 // 2, 7 % 3, -7 % 3, 2 ** 10, 7 / 2)
+print(2 ** 100, -(2 ** 70) // 3)
+print(0.1 + 0.2, 1e20, 1.5e-7, 10 / 4, float("inf"), round(2.5), round(3.14159, 2))
+print(divmod(17, 5), abs(-3), max(3, 9, 2), min([4, 1, 8]), sum(range(101)))
+print(int("42") + 1, int("ff", 16), hex(255), bin(5), oct(8), float("3.5"))
+print(True + True, 3 == 3.0, 1 < 2 < 3, not 0, 5 & 3, 5 | 3, 5 ^ 3, ~5, 1 << 10)
+`,
+		stdout: `3 -4 1 2 1024 3.5
+1267650600228229401496703205376 -393530540239137101142
+0.30000000000000004 1e+20 1.5e-07 2.5 inf 2 3.14
+(3, 2) 3 9 1 5050
+43 255 0xff 0b101 0o10 3.5
+2 True True True 1 7 6 -6 1024
+`,
+	},
+	{
+		name: "cadenas",
+		code: `s = "Hola, Mundo"
+print(s.upper(), s.lower(), s[::-1], s[0:4], s[-5:], len(s))
+print(s.split(", "), "-".join(["a", "b", "c"]), s.replace("o", "0"), s.find("M"))
+print("  x  ".strip() + "|", "abc".startswith("ab"), "a,b,,c".split(","), "ab" * 3)
+print("ñandú".upper(), "3".zfill(4), "hi".center(8, "*"), "x".ljust(3) + "|", "Título de prueba".title())
+nombre, edad = "Ana", 30
+print(f"{nombre} tiene {edad} años", f"{3.14159:.2f}", f"{42:>6}|", f"{255:x}", f"{edad + 1=}")
+print("{} y {}".format(1, 2), "%d-%s-%.1f" % (5, "x", 2.25), repr("it's"), str(None))
+print(ord("A"), chr(97), "abc" < "abd", "b" in "abc", list("xyz"), "a\tb".expandtabs(4))
+`,
+		stdout: `HOLA, MUNDO hola, mundo odnuM ,aloH Hola Mundo 11
+['Hola', 'Mundo'] a-b-c H0la, Mund0 6
+x| True ['a', 'b', '', 'c'] ababab
+ÑANDÚ 0003 ***hi*** x  | Título De Prueba
+Ana tiene 30 años 3.14     42| ff edad + 1=31
+1 y 2 5-x-2.2 "it's" None
+65 a True True ['x', 'y', 'z'] a   b
+`,
+	},
+	{
+		name: "colecciones",
+		code: `xs = [5, 3, 8, 1]
+xs.append(7)
+xs.sort()
+print(xs, xs[1:3], xs[-1], xs.index(8), 3 in xs, xs.pop(), xs)
+print(sorted(["pera", "uva", "manzana"], key=len), sorted(xs, reverse=True), list(reversed(xs)))
+d = {"a": 1, "b": 2}
+d["c"] = 3
+print(d, list(d.keys()), list(d.values()), d.get("z", 0), len(d))
+for k, v in d.items():
+    print(k, v, end="; ")
+print()
+s = {3, 1, 2}
+s.add(2)
+print(sorted(s), s & {1, 5}, len(s | {9}), {1, 2} <= s)
+t = (1, "dos", 3.0)
+a, b, c = t
+print(t, a, b, c, t[1:], (1,), ())
+m = [[0] * 3 for _ in range(2)]
+m[0][1] = 5
+print(m, list(zip([1, 2], "ab")), list(enumerate("xy", 1)), dict(zip("ab", [1, 2])))
+print([x * x for x in range(6) if x % 2 == 0], {x: x % 3 for x in range(4)}, {c for c in "hola"} == set("hola"))
+print(list(map(str, [1, 2])), list(filter(None, [0, 1, "", "a"])), any([0, 1]), all([]))
+`,
+		stdout: `[1, 3, 5, 7] [3, 5] 8 4 True 8 [1, 3, 5, 7]
+['uva', 'pera', 'manzana'] [7, 5, 3, 1] [7, 5, 3, 1]
+{'a': 1, 'b': 2, 'c': 3} ['a', 'b', 'c'] [1, 2, 3] 0 3
+a 1; b 2; c 3; 
+[1, 2, 3] {1} 4 True
+(1, 'dos', 3.0) 1 dos 3.0 ('dos', 3.0) (1,) ()
+[[0, 5, 0], [0, 0, 0]] [(1, 'a'), (2, 'b')] [(1, 'x'), (2, 'y')] {'a': 1, 'b': 2}
+[0, 4, 16] {0: 0, 1: 1, 2: 2, 3: 0} True
+['1', '2'] [1, 'a'] True True
+`,
+	},
+	{
+		name: "funciones",
+		code: `def saludo(nombre, signo="!"):
+    return "Hola " + nombre + signo
+
+def suma(*nums, **opciones):
+    total = sum(nums)
+    if opciones.get("doble"):
+        total *= 2
+    return total
+
+def contador():
+    n = 0
+    def inc():
+        nonlocal n
+        n += 1
+        return n
+    return inc
+
+def fib(n):
+    return n if n < 2 else fib(n - 1) + fib(n - 2)
+
+print(saludo("Ana"), saludo("Luis", signo="?"), suma(1, 2, 3), suma(1, 2, doble=True))
+c = contador()
+c(); c()
+print(c(), fib(20), (lambda x, y=2: x ** y)(5), list(map(lambda s: s[::-1], ["ab", "cd"])))
+args = [3, 4]
+print(suma(*args), suma(**{"doble": True}))
+`,
+		stdout: `Hola Ana! Hola Luis? 6 6
+3 6765 25 ['ba', 'dc']
+7 0
+`,
+	},
+	{
+		name: "generadores",
+		code: `def cuadrados(n):
+    for i in range(n):
+        yield i * i
+
+def infinito():
+    i = 0
+    while True:
+        yield i
+        i += 1
+
+g = cuadrados(4)
+print(next(g), next(g), list(g))
+for x in infinito():
+    if x > 3:
+        break
+    print(x, end=" ")
+print()
+print(sum(x for x in range(10)), max((len(w), w) for w in ["a", "ccc", "bb"]))
+def delega():
+    yield from [1, 2]
+    yield 3
+print(list(delega()))
+`,
+		stdout: `0 1 [4, 9]
+0 1 2 3 
+45 (3, 'ccc')
+[1, 2, 3]
+`,
+	},
+	{
+		name: "clases",
+		code: `class Animal:
+    especie = "animal"
+    def __init__(self, nombre):
+        self.nombre = nombre
+    def hablar(self):
+        return "..."
+    def __str__(self):
+        return f"{self.nombre} ({self.hablar()})"
+    def __repr__(self):
+        return f"Animal({self.nombre!r})"
+
+class Perro(Animal):
+    def hablar(self):
+        return "guau"
+
+class Punto:
+    def __init__(self, x, y):
+        self.x, self.y = x, y
+    def __add__(self, o):
+        return Punto(self.x + o.x, self.y + o.y)
+    def __eq__(self, o):
+        return (self.x, self.y) == (o.x, o.y)
+    def __repr__(self):
+        return f"Punto({self.x}, {self.y})"
+    @property
+    def norma(self):
+        return (self.x ** 2 + self.y ** 2) ** 0.5
+    @staticmethod
+    def origen():
+        return Punto(0, 0)
+
+p = Perro("Toby")
+print(p, [p], isinstance(p, Animal), Perro.especie, super(Perro, p).hablar())
+print(Punto(1, 2) + Punto(3, 4), Punto(1, 1) == Punto(1, 1), Punto(3, 4).norma, Punto.origen())
+print(type(p).__name__, hasattr(p, "nombre"), getattr(p, "edad", None))
+`,
+		stdout: `Toby (guau) [Animal('Toby')] True animal ...
+Punto(4, 6) True 5.0 Punto(0, 0)
+Perro True None
+`,
+	},
+	{
+		name: "excepciones",
+		code: `def divide(a, b):
+    try:
+        return a / b
+    except ZeroDivisionError as e:
+        print("error:", e)
+        return None
+    finally:
+        print("fin", a, b)
+
+print(divide(6, 3))
+print(divide(1, 0))
+
+class MiError(Exception):
+    pass
+
+try:
+    raise MiError("algo salió mal")
+except (ValueError, MiError) as e:
+    print(type(e).__name__, e, e.args)
+
+try:
+    int("abc")
+except ValueError as e:
+    print(e)
+try:
+    [][1]
+except IndexError as e:
+    print(e)
+try:
+    {}["k"]
+except KeyError as e:
+    print(repr(e))
+else:
+    print("no")
+`,
+		stdout: `fin 6 3
+2.0
+error: division by zero
+fin 1 0
+None
+MiError algo salió mal ('algo salió mal',)
+invalid literal for int() with base 10: 'abc'
+list index out of range
+KeyError('k')
+`,
+	},
+	{
+		name: "entrada",
+		code: `n = int(input())
+nums = list(map(int, input().split()))
+print(n, sum(nums), max(nums))
+nombre = input("Nombre: ")
+print(f"Hola {nombre}")
+`,
+		stdin: `3
+4 5 6
+Ana
+`,
+		stdout: `3 15 6
+Nombre: Hola Ana
+`,
+	},
+	{
+		name: "modulos",
+		code: `import math
+from collections import Counter, defaultdict, deque
+import itertools
+
+print(math.sqrt(16), math.pi, math.gcd(12, 18), math.factorial(10), math.floor(-2.5), math.ceil(2.1))
+cnt = Counter("abracadabra")
+print(cnt.most_common(2), cnt["a"])
+dd = defaultdict(list)
+dd["x"].append(1)
+print(dict(dd))
+q = deque([1, 2, 3])
+q.appendleft(0)
+q.pop()
+print(q, list(itertools.permutations([1, 2, 3], 2))[:3], list(itertools.accumulate([1, 2, 3])))
+`,
+		stdout: `4.0 3.141592653589793 6 3628800 -3 3
+[('a', 5), ('b', 2)] 5
+{'x': [1]}
+deque([0, 1, 2]) [(1, 2), (1, 3), (2, 1)] [1, 3, 6]
+`,
+	},
+	{
+		name: "control",
+		code: `for i in range(3):
+    if i == 1:
+        continue
+    print("i =", i)
+else:
+    print("terminó el for")
+n = 10
+while n > 0:
+    n -= 3
+    if n == 4:
+        break
+else:
+    print("no llega")
+print(n)
+x = 5
+print("par" if x % 2 == 0 else "impar")
+`,
+		stdout: `i = 0
+i = 2
+terminó el for
+4
+impar
+`,
+	},
+	{
+		name: "traceback",
+		code: `def f(x):
+    return 10 / x
+
+print("antes")
+f(0)
+`,
+		stdout: `antes
+`,
+		stderr: `Traceback (most recent call last):
+  File "main.py", line 5, in <module>
+    f(0)
+  File "main.py", line 2, in f
+    return 10 / x
+           ~~~^~~
+ZeroDivisionError: division by zero
+`,
+		exit: 1,
+	},
+	{
+		name: "nombre_no_definido",
+		code: `total = 1
+print(totl)
+`,
+		stdout: "",
+		stderr: `Traceback (most recent call last):
+  File "main.py", line 2, in <module>
+    print(totl)
+          ^^^^
+NameError: name 'totl' is not defined. Did you mean: 'total'?
+`,
+		exit: 1,
+	},
+	{
+		name: "sintaxis",
+		code: `print("hola"
+`,
+		stdout: "",
+		stderr: `  File "main.py", line 1
+    print("hola"
+         ^
+SyntaxError: '(' was never closed
+`,
+		exit: 1,
+	},
+	{
+		name: "excepcion_propia",
+		code: `class SaldoInsuficiente(Exception):
+    def __init__(self, falta):
+        super().__init__(f"faltan {falta}")
+        self.falta = falta
+
+class Cuenta:
+    def __init__(self, saldo):
+        self.saldo = saldo
+    def retirar(self, monto):
+        if monto > self.saldo:
+            raise SaldoInsuficiente(monto - self.saldo)
+        self.saldo -= monto
+
+c = Cuenta(100)
+c.retirar(30)
+print(c.saldo)
+c.retirar(100)
+`,
+		stdout: `70
+`,
+		stderr: `Traceback (most recent call last):
+  File "main.py", line 17, in <module>
+    c.retirar(100)
+  File "main.py", line 11, in retirar
+    raise SaldoInsuficiente(monto - self.saldo)
+SaldoInsuficiente: faltan 30
+`,
+		exit: 1,
+	},
+	{
+		name: "encadenada",
+		code: `def convertir(s):
+    try:
+        return int(s)
+    except ValueError as e:
+        raise RuntimeError("dato inválido") from e
+
+print(convertir("12"))
+convertir("x")
+`,
+		stdout: `12
+`,
+		stderr: `Traceback (most recent call last):
+  File "main.py", line 3, in convertir
+    return int(s)
+           ^^^^^^
+ValueError: invalid literal for int() with base 10: 'x'
+
+The above exception was the direct cause of the following exception:
+
+Traceback (most recent call last):
+  File "main.py", line 8, in <module>
+    convertir("x")
+  File "main.py", line 5, in convertir
+    raise RuntimeError("dato inválido") from e
+RuntimeError: dato inválido
+`,
+		exit: 1,
+	},
+	{
+		name: "tipos",
+		code: `print(type(1), type("a"), type([]), type(None).__name__, type(3.0) is float)
+print(1 + "a")
+`,
+		stdout: `<class 'int'> <class 'str'> <class 'list'> NoneType True
+`,
+		stderr: `Traceback (most recent call last):
+  File "main.py", line 2, in <module>
+    print(1 + "a")
+          ~~^~~~~
+TypeError: unsupported operand type(s) for +: 'int' and 'str'
+`,
+		exit: 1,
+	},
+	{
+		name: "atributo",
+		code: `xs = [3, 1, 2]
+xs.push(4)
+`,
+		stdout: "",
+		stderr: `Traceback (most recent call last):
+  File "main.py", line 2, in <module>
+    xs.push(4)
+    ^^^^^^^
+AttributeError: 'list' object has no attribute 'push'
+`,
+		exit: 1,
+	},
+	{
+		name: "recursion",
+		code: `import sys
+sys.setrecursionlimit(3000)
+def suma(n):
+    return 0 if n == 0 else n + suma(n - 1)
+print(suma(2500))
+memo = {}
+def caminos(n):
+    if n <= 1:
+        return 1
+    if n not in memo:
+        memo[n] = caminos(n - 1) + caminos(n - 2)
+    return memo[n]
+print(caminos(90))
+`,
+		stdout: `3126250
+4660046610375530309
+`,
+	},
+	{
+		name: "indices",
+		code: `m = {"a": [1, 2, 3]}
+print(m["a"][-1], m["a"][::2], "abcdef"[1:5:2], list(range(10, 0, -3)))
+print(m["b"])
+`,
+		stdout: `3 [1, 3] bd [10, 7, 4, 1]
+`,
+		stderr: `Traceback (most recent call last):
+  File "main.py", line 3, in <module>
+    print(m["b"])
+          ~^^^^^
+KeyError: 'b'
+`,
+		exit: 1,
+	},
+	{
+		name: "durante",
+		code: `def leer(d, k):
+    try:
+        return d[k]
+    except KeyError:
+        return d["defecto"]
+
+print(leer({"a": 1, "defecto": 0}, "z"))
+leer({}, "z")
+`,
+		stdout: `0
+`,
+		stderr: `Traceback (most recent call last):
+  File "main.py", line 3, in leer
+    return d[k]
+           ~^^^
+KeyError: 'z'
+
+During handling of the above exception, another exception occurred:
+
+Traceback (most recent call last):
+  File "main.py", line 8, in <module>
+    leer({}, "z")
+  File "main.py", line 5, in leer
+    return d["defecto"]
+           ~^^^^^^^^^^^
+KeyError: 'defecto'
+`,
+		exit: 1,
+	},
+	{
+		name: "relanzada",
+		code: `def paso():
+    raise ValueError("malo")
+
+def proceso():
+    try:
+        paso()
+    except ValueError:
+        print("registrado")
+        raise
+
+proceso()
+`,
+		stdout: `registrado
+`,
+		stderr: `Traceback (most recent call last):
+  File "main.py", line 11, in <module>
+    proceso()
+  File "main.py", line 6, in proceso
+    paso()
+  File "main.py", line 2, in paso
+    raise ValueError("malo")
+ValueError: malo
+`,
+		exit: 1,
+	},
+	{
+		name: "metodos_de_cadenas",
+		code: `s = "  Compiladores 2024  "
+print(s.strip(), s.lstrip() + "|", "|" + s.rstrip(), "xxhixx".strip("x"), "abc".lstrip("a"), "abc".rstrip("c"))
+print("hola mundo".capitalize(), "ÁRBOL".casefold(), "AbC".swapcase(), "Hola Mundo".istitle())
+print("123".isdigit(), "12a".isdigit(), "²".isdecimal(), "½".isnumeric(), "abc".isalpha(), "ab1".isalnum(), " \t".isspace(), "ñ".isascii())
+print("abc".islower(), "ABC".isupper(), "aB".islower(), "x1".isidentifier(), "1x".isidentifier(), "a\n".isprintable())
+print("a b  c".split(), "a,b,c".split(",", 1), "a,b,c".rsplit(",", 1), "  a  b ".split(None, 1), "a b c".rsplit(None, 1))
+print("l1\nl2\r\nl3".splitlines(), "l1\nl2\n".splitlines(True), "".split(","), "".split())
+print("banana".find("na"), "banana".rfind("na"), "banana".index("n"), "banana".rindex("n"), "banana".count("a"), "banana".find("x"), "banana".count("an", 2))
+print("banana".replace("a", "o", 2), "texto.py".endswith((".py", ".js")), "abc".startswith("b", 1), "abc".endswith("b", 0, 2))
+print("7".rjust(3, "0"), "ab".ljust(5, "."), "-42".zfill(6), "+7".zfill(4), "clave=valor=x".partition("="), "clave=valor=x".rpartition("="), "abc".partition("z"))
+print("prefijo_nombre".removeprefix("prefijo_"), "archivo.txt".removesuffix(".txt"), "x".removeprefix("y"))
+print("{0}-{1}-{0}".format("a", "b"), "{nombre}:{n:03d}".format(nombre="id", n=7), "{:>{w}}|".format("x", w=4), "{0[1]} {1[k]}".format([1, 2], {"k": "v"}))
+print("a\tbc\td".expandtabs(), "a\tb".expandtabs(2))
+tabla = str.maketrans("ae", "43")
+print("paquete".translate(tabla), "abc".translate({ord("b"): None}), "{x} y {y}".format_map({"x": 1, "y": 2}))
+print(",".join(str(i) for i in range(4)), "".join(reversed("abc")), "ab" in "cabd", "ab" not in "xyz", "x" * 0 == "")
+print("Ab" < "a", "abc"[1], "abc"[-1], "abcdef"[1:5:2], "abc"[::-1], "abc"[5:], max("hola"), sorted("cba"))
+try:
+    "abc".index("z")
+except ValueError as e:
+    print("ValueError:", e)
+try:
+    "x" + 1
+except TypeError as e:
+    print("TypeError:", e)
+`,
+		stdout: `Compiladores 2024 Compiladores 2024  | |  Compiladores 2024 hi bc ab
+Hola mundo árbol aBc True
+True False False True True True True False
+True True False True False False
+['a', 'b', 'c'] ['a', 'b,c'] ['a,b', 'c'] ['a', 'b '] ['a b', 'c']
+['l1', 'l2', 'l3'] ['l1\n', 'l2\n'] [''] []
+2 4 2 4 3 -1 1
+bonona True True True
+007 ab... -00042 +007 ('clave', '=', 'valor=x') ('clave=valor', '=', 'x') ('abc', '', '')
+nombre archivo x
+a-b-a id:007    x| 2 v
+a       bc      d a b
+p4qu3t3 ac 1 y 2
+0,1,2,3 cba True True True
+True b c bd cba  o ['a', 'b', 'c']
+ValueError: substring not found
+TypeError: can only concatenate str (not "int") to str
+`,
+	},
+	{
+		name: "formato",
+		code: `n, x = 1234567, 3.14159
+print(f"{n:,}", f"{n:_}", f"{n:+}", f"{-n: }", f"{n:e}", f"{x:.3e}", f"{x:g}", f"{0.00001234:g}", f"{1e20:g}", f"{0.25:%}", f"{0.256:.1%}")
+print(f"{'centro':^10}|", f"{'izq':<6}|", f"{'der':>6}|", f"{'x':*^5}", f"{x:08.3f}", f"{-x:08.2f}", f"{n:>12,}", f"{42:#x}", f"{42:#o}", f"{42:#b}", f"{255:X}", f"{5:04b}")
+print(f"{x!r}", f"{'a'!r}", f"{'ñ'!a}", f"{3!s:>3}", f"{True}", f"{None}", f"{[1, 'a']}", f"{n:.2f}", f"{7:.1f}", f"{2.5:.0f}", f"{3.5:.0f}")
+print("%5d|%-5s|%x|%o|%e|%g|%r|%%|%05.1f|%+d|%c" % (42, "ab", 255, 8, 12345.678, 0.0001, "s", 3.14159, 5, 65))
+print("%(a)s y %(b)d" % {"a": "uno", "b": 2}, "%s" % [1, 2], "%s" % (1,), "%10.3s|" % "abcdef", "%-6.2f|" % 2.5)
+print(format(3.5, ".1f"), format(12, "x"), format("s", ">3"), format(1.0), format(10**20, ","))
+print(1 / 3, 2 / 3, 1e16, 1.0e-5, 123456789.0, 1e22, 0.1 * 3, -0.0, 2 ** 0.5, 100.0, float(10**16), 5e-324)
+print(repr(1.5), str(2.0), repr("a'b"), repr('a"b'), repr("a'b\"c"), repr("tab\tnueva\n"), repr("\x00é\u200b"), ascii("ñandú"))
+print(str(10**30), repr(-(10**25)), hex(-255), bin(-5), oct(2**70))
+print(f"{n=}", f"{x = :.2f}", f"{{literal}}", f"{'anidado' + f'{n}'}")
+`,
+		stdout: `1,234,567 1_234_567 +1234567 -1234567 1.234567e+06 3.142e+00 3.14159 1.234e-05 1e+20 25.000000% 25.6%
+  centro  | izq   |    der| **x** 0003.142 -0003.14    1,234,567 0x2a 0o52 0b101010 FF 0101
+3.14159 'a' '\xf1'   3 True None [1, 'a'] 1234567.00 7.0 2 4
+   42|ab   |ff|10|1.234568e+04|0.0001|'s'|%|003.1|+5|A
+uno y 2 [1, 2] 1        abc| 2.50  |
+3.5 c   s 1.0 100,000,000,000,000,000,000
+0.3333333333333333 0.6666666666666666 1e+16 1e-05 123456789.0 1e+22 0.30000000000000004 -0.0 1.4142135623730951 100.0 1e+16 5e-324
+1.5 2.0 "a'b" 'a"b' 'a\'b"c' 'tab\tnueva\n' '\x00é\u200b' '\xf1and\xfa'
+1000000000000000000000000000000 -10000000000000000000000000 -0xff -0b101 0o200000000000000000000000
+n=1234567 x = 3.14 {literal} anidado1234567
+`,
+	},
+	{
+		name: "listas_y_tuplas",
+		code: `xs = [3, 1, 2]
+xs.insert(0, 9)
+xs.insert(100, 7)
+xs.insert(-1, 5)
+xs.extend((4, 4))
+print(xs, xs.count(4), xs.index(4), xs.index(4, 7))
+xs.remove(4)
+print(xs, xs.pop(0), xs.pop(-2), xs)
+ys = xs.copy()
+ys.reverse()
+ys.sort(key=lambda v: -v)
+print(xs, ys, sorted(xs, key=str, reverse=True))
+xs[1:3] = [10, 20, 30]
+print(xs)
+xs[::2] = [0] * len(xs[::2])
+print(xs)
+del xs[0]
+del xs[1:3]
+print(xs)
+xs += [1]
+xs *= 2
+print(xs, [1, 2] + [3], [1, 2] < [1, 3], [1, 2] == [1, 2], [[1]] * 2, [] == [])
+ys.clear()
+print(ys, len(ys), bool(ys))
+t = (1, 2, 2, 3)
+print(t.count(2), t.index(3), t + (4,), t * 2, t[::-1], (1, 2) < (1, 2, 0), tuple("ab"), tuple([1]))
+a, *b = [1, 2, 3]
+*c, d = "xyz"
+(e, f), g = (1, 2), 3
+print(a, b, c, d, e, f, g)
+m = [[1, 2], [3, 4]]
+print([fila[::-1] for fila in m], [x for fila in m for x in fila], list(map(list, zip(*m))))
+print(list(range(10, 0, -3)), range(5)[2], range(1, 10, 2)[-1], len(range(0, 10, 3)), 4 in range(0, 10, 2), list(reversed(range(3))), range(3))
+r = range(2, 20, 5)
+print(r.start, r.stop, r.step, r.index(12), r.count(7), range(0, 5)[1:3])
+try:
+    [1, 2].remove(5)
+except ValueError as e:
+    print("ValueError:", e)
+try:
+    [].pop()
+except IndexError as e:
+    print("IndexError:", e)
+try:
+    (1, 2)[0] = 5
+except TypeError as e:
+    print("TypeError:", e)
+`,
+		stdout: `[9, 3, 1, 2, 5, 7, 4, 4] 2 6 7
+[3, 1, 2, 5, 4] 9 7 [3, 1, 2, 5, 4]
+[3, 1, 2, 5, 4] [5, 4, 3, 2, 1] [5, 4, 3, 2, 1]
+[3, 10, 20, 30, 5, 4]
+[0, 10, 0, 30, 0, 4]
+[10, 0, 4]
+[10, 0, 4, 1, 10, 0, 4, 1] [1, 2, 3] True True [[1], [1]] True
+[] 0 False
+2 3 (1, 2, 2, 3, 4) (1, 2, 2, 3, 1, 2, 2, 3) (3, 2, 2, 1) True ('a', 'b') (1,)
+1 [2, 3] ['x', 'y'] z 1 2 3
+[[2, 1], [4, 3]] [1, 2, 3, 4] [[1, 3], [2, 4]]
+[10, 7, 4, 1] 2 9 4 True [2, 1, 0] range(0, 3)
+2 20 5 2 1 range(1, 3)
+ValueError: list.remove(x): x not in list
+IndexError: pop from empty list
+TypeError: 'tuple' object does not support item assignment
+`,
+	},
+	{
+		name: "diccionarios_y_conjuntos",
+		code: `d = dict(a=1, b=2)
+print(d.setdefault("c", 3), d.setdefault("a", 9), d.pop("b"), d.pop("z", "no"), d)
+d.update({"x": 5}, y=6)
+d.update([("z", 7)])
+print(d, d.popitem(), len(d), "x" in d, list(d))
+e = dict.fromkeys("ab", 0)
+f = e.copy()
+f["a"] = 1
+print(e, f, e | {"c": 1}, {**e, "b": 5}, dict([(1, 2)]), dict(e, c=3))
+e |= {"q": 1}
+del e["a"]
+print(e, list(e.items()), sorted(f.values()), e == {"b": 0, "q": 1}, {1: "a"} != {1: "b"})
+e.clear()
+print(e, d.get("nada"), {(1, 2): "tupla"}[(1, 2)], {1: "uno", 1.0: "uno float", True: "v"})
+s = set([1, 2, 3])
+s.update([4], {5})
+s.discard(10)
+s.remove(1)
+print(s, s.union({9}), s.intersection([2, 3, 8]), s.difference({2}), s.symmetric_difference({5, 6}))
+print(s - {2}, s ^ {2, 7}, s | {0}, {1, 2}.issubset({1, 2, 3}), {1, 2, 3}.issuperset({1}), {1}.isdisjoint({2}), {1, 2} < {1, 2}, {1} >= set())
+t = s.copy()
+t.intersection_update({2, 3, 4})
+u = {1, 2, 3}
+u.difference_update({1})
+u.symmetric_difference_update({3, 4})
+print(t, u, t.pop() in {2, 3, 4}, len(t))
+fs = frozenset([3, 1])
+print(fs, sorted(fs | {2}), fs == {1, 3}, {fs: 1}[frozenset([1, 3])], set(), frozenset())
+for k in sorted({"b": 1, "a": 2}):
+    print(k, end=" ")
+print()
+try:
+    {}["falta"]
+except KeyError as e:
+    print("KeyError:", e)
+try:
+    s.remove(100)
+except KeyError as e:
+    print("KeyError:", e)
+try:
+    {[1]: 2}
+except TypeError as e:
+    print("TypeError:", e)
+try:
+    {}.popitem()
+except KeyError as e:
+    print("KeyError:", e)
+`,
+		stdout: `3 1 2 no {'a': 1, 'c': 3}
+{'a': 1, 'c': 3, 'x': 5, 'y': 6} ('z', 7) 4 True ['a', 'c', 'x', 'y']
+{'a': 0, 'b': 0} {'a': 1, 'b': 0} {'a': 0, 'b': 0, 'c': 1} {'a': 0, 'b': 5} {1: 2} {'a': 0, 'b': 0, 'c': 3}
+{'b': 0, 'q': 1} [('b', 0), ('q', 1)] [0, 1] True True
+{} None tupla {1: 'v'}
+{2, 3, 4, 5} {2, 3, 4, 5, 9} {2, 3} {3, 4, 5} {2, 3, 4, 6}
+{3, 4, 5} {3, 4, 5, 7} {0, 2, 3, 4, 5} True True True False True
+{3, 4} {2, 4} True 2
+frozenset({1, 3}) [1, 2, 3] True 1 set() frozenset()
+a b 
+KeyError: 'falta'
+KeyError: 100
+TypeError: unhashable type: 'list'
+KeyError: 'popitem(): dictionary is empty'
+`,
+	},
+	{
+		name: "numeros",
+		code: `print(int("  -12 "), int(3.9), int(-3.9), int("1_000"), int("0x1f", 16), int("z", 36), int("0b101", 0), int(True), int("+5"))
+print(float(" -2.5 "), float("-inf"), float("1e3"), float(7), float("nan") != float("nan"), float("1_0.5"))
+print(round(-2.5), round(3.5), round(1234, -2), round(2.675, 2), round(-0.5), round(7.0), round(1.25, 1), round(15, -1))
+print(pow(2, 10, 1000), pow(2, -1), pow(-8, 1 / 3) if False else 0, pow(3, 4), 2 ** -2, (-2) ** 3, 0 ** 0, pow(3, -1, 7))
+big = 10 ** 20
+print(big // -7, big % -7, -big // 3, abs(-big), divmod(big, 9), big * big, big - big, big / 4, big > 1e19, big == 10.0 ** 20)
+print(divmod(-7, 2), divmod(7.5, 2), -7.5 // 2, 7.5 % -2, 5 % 0.75, 2 ** 62 + 2 ** 62, -(2 ** 63), (2 ** 63) // 2, 9 ** 0.5)
+print(1e308 * 10, -1e308 * 10, 3 * True, True / 2, 10 // 2.5, 1 == 1.0, 0.1 + 0.2 == 0.3, 2 < 2.5 < 3, 10 ** 20 < 10 ** 21)
+print(abs(-2.5), abs(False), max(1, 2.5), min(-1, -1.5), sum([0.1] * 10), sum([1, 2], 10), sum([[1], [2]], []), -(-3), +4, ~(-1))
+print(7 >> 1, -7 >> 1, 1 << 70, (1 << 70) >> 68, 6 & -2, big & 0xFF, big | 1, big ^ big, ~big)
+print(isinstance(1, int), isinstance(True, int), isinstance(1.0, (int, float)), type(1) is int, type(2.0).__name__, type(big), type(True))
+print(bool(0.0), bool(""), bool([0]), bool(None), 0 or "x", 1 and 2, None or 0, not [], 3 if 0 else 4)
+print(hash(1) == hash(1.0), hash("a") == hash("a"), hash((1, 2)) == hash((1, 2)), id(big) == id(big), callable(len), callable(3))
+casos = [lambda: 1 / 0, lambda: 1 // 0, lambda: 1 % 0, lambda: int("x"), lambda: float("y"), lambda: 2 ** 10000 * 1.0, lambda: pow(2, 3, 0), lambda: int("12", 1)]
+for caso in casos:
+    try:
+        caso()
+    except (ZeroDivisionError, ValueError, OverflowError) as e:
+        print(type(e).__name__ + ":", e)
+`,
+		stdout: `-12 3 -3 1000 31 35 5 1 5
+-2.5 -inf 1000.0 7.0 True 10.5
+-2 4 1200 2.67 0 7 1.2 20
+24 0.5 0 81 0.25 -8 1 5
+-14285714285714285715 -5 -33333333333333333334 100000000000000000000 (11111111111111111111, 1) 10000000000000000000000000000000000000000 0 2.5e+19 True True
+(-4, 1) (3.0, 1.5) -4.0 -0.5 0.5 9223372036854775808 -9223372036854775808 4611686018427387904 3.0
+inf -inf 3 0.5 4.0 True False True True
+2.5 0 2.5 -1.5 0.9999999999999999 13 [1, 2] 3 4 0
+3 -4 1180591620717411303424 4 6 0 100000000000000000001 0 -100000000000000000001
+True True True True float <class 'int'> <class 'bool'>
+False False True False x 2 0 True 4
+True True True True True False
+ZeroDivisionError: division by zero
+ZeroDivisionError: integer division or modulo by zero
+ZeroDivisionError: integer modulo by zero
+ValueError: invalid literal for int() with base 10: 'x'
+ValueError: could not convert string to float: 'y'
+OverflowError: int too large to convert to float
+ValueError: pow() 3rd argument cannot be 0
+ValueError: int() base must be >= 2 and <= 36, or 0
+`,
+	},
+	{
+		name: "iteradores",
+		code: `class Cuenta:
+    def __init__(self, n):
+        self.n = n
+
+    def __iter__(self):
+        return self
+
+    def __next__(self):
+        if self.n == 0:
+            raise StopIteration
+        self.n -= 1
+        return self.n
+
+print(list(Cuenta(3)), [x * 2 for x in Cuenta(2)], sum(Cuenta(4)))
+it = iter([1, 2])
+print(next(it), it.__next__(), next(it, "fin"), list(it))
+try:
+    next(iter(()))
+except StopIteration:
+    print("StopIteration")
+def eco():
+    recibido = yield "listo"
+    while True:
+        recibido = yield recibido * 2
+
+g = eco()
+print(next(g), g.send(5), g.send("ab"))
+g.close()
+print(next(g, "cerrado"))
+def hasta(n):
+    yield from range(n)
+    return "fin"
+gen = hasta(2)
+print(list(gen), iter(gen) is gen, gen.__iter__() is gen)
+lineas = iter(["a", "b", "", "c"])
+print(list(iter(lambda: next(lineas), "")))
+print(min([3, 1, 2], key=lambda v: -v), max([], default=0), min("b", "a"), max([(1, "b"), (1, "a")]), max({"x": 1, "y": 2}, key={"x": 1, "y": 2}.get))
+print(list(enumerate(["a", "b"], start=5)), list(zip("ab", range(3), [True, False])), sorted([(2, "a"), (1, "b")]), any(x > 2 for x in [1, 3]))
+print(list(map(lambda a, b: a + b, [1, 2], [10, 20])), list(filter(lambda v: v % 2, range(6))), list(reversed([1, 2, 3])), list(reversed("ab")))
+print(sorted([3, 1, 2], reverse=True), sorted({"b": 1, "a": 2}.items(), key=lambda kv: kv[1]), sorted(["B", "a", "C"], key=str.lower))
+g2 = (x * x for x in range(3))
+print(next(g2), list(g2), list(g2))
+`,
+		stdout: `[2, 1, 0] [2, 0] 6
+1 2 fin []
+StopIteration
+listo 10 abab
+cerrado
+[0, 1] True True
+['a', 'b']
+3 0 a (1, 'b') y
+[(5, 'a'), (6, 'b')] [('a', 0, True), ('b', 1, False)] [(1, 'b'), (2, 'a')] True
+[11, 22] [1, 3, 5] [3, 2, 1] ['b', 'a']
+[3, 2, 1] [('b', 1), ('a', 2)] ['a', 'B', 'C']
+0 [1, 4] []
+`,
+	},
+	{
+		name: "sys_y_with",
+		code: `import sys
+
+class Recurso:
+    def __init__(self, nombre, silenciar=False):
+        self.nombre = nombre
+        self.silenciar = silenciar
+
+    def __enter__(self):
+        print("abre", self.nombre)
+        return self
+
+    def __exit__(self, tipo, valor, tb):
+        print("cierra", self.nombre, tipo.__name__ if tipo else None)
+        return self.silenciar
+
+with Recurso("a") as r, Recurso("b"):
+    print("dentro", r.nombre)
+with Recurso("c", silenciar=True):
+    raise ValueError("ignorado")
+print("sigue")
+try:
+    with Recurso("d"):
+        raise KeyError("k")
+except KeyError as e:
+    print("propagado", e)
+primera = sys.stdin.readline()
+resto = sys.stdin.readlines()
+print(repr(primera), resto)
+sys.stdout.write("sin salto")
+sys.stdout.write("\n")
+print("error", file=sys.stderr)
+sys.stderr.write("directo\n")
+print("a", "b", sep="-", end="!\n", flush=True)
+print(sys.maxsize, len(sys.argv), sys.version_info[0], sys.getrecursionlimit() > 0)
+sys.exit(3)
+`,
+		stdin: `uno
+dos
+tres
+`,
+		stdout: `abre a
+abre b
+dentro a
+cierra b None
+cierra a None
+abre c
+cierra c ValueError
+sigue
+abre d
+cierra d KeyError
+propagado 'k'
+'uno\n' ['dos\n', 'tres\n']
+sin salto
+a-b!
+9223372036854775807 1 3 True
+`,
+		stderr: `error
+directo
+`,
+		exit: 3,
+	},
+	{
+		name: "math_itertools",
+		code: `import math
+from itertools import count, chain, combinations, product, permutations, accumulate
+import itertools as it
+
+print(math.log(math.e), math.log(8, 2), math.log2(1024), math.log10(1000), math.exp(0), math.pow(2, 3), math.sqrt(2))
+print(math.lcm(4, 6), math.gcd(0, 5), math.isqrt(17), math.comb(5, 2), math.isclose(0.1 + 0.2, 0.3), math.trunc(-2.7), math.fabs(-3))
+print(math.inf > 10 ** 100, math.isnan(math.nan), math.isinf(-math.inf), math.tau / 2 == math.pi, math.floor(7), math.ceil(-0.5))
+print(round(math.sin(math.pi / 2), 6), round(math.cos(0), 6), round(math.atan(1) * 4, 10), math.degrees(math.pi), math.radians(180) == math.pi)
+for f in (lambda: math.sqrt(-1), lambda: math.log(0), lambda: math.factorial(-1), lambda: math.exp(1000)):
+    try:
+        f()
+    except (ValueError, OverflowError) as e:
+        print(type(e).__name__ + ":", e)
+c = count(10, 5)
+print(next(c), next(c), list(zip(count(), "ab")))
+print(list(chain([1], (2, 3), "ab")), list(chain.from_iterable([[1], [2, 3]])))
+print(list(combinations("abc", 2)), list(combinations(range(4), 3))[:2], list(product([0, 1], repeat=2)), list(product("ab", [1])))
+print(list(permutations("ab")), list(accumulate([3, 1, 4], max)), list(accumulate([1, 2, 3], initial=100)), list(it.accumulate([])))
+`,
+		stdout: `1.0 3.0 10.0 3.0 1.0 8.0 1.4142135623730951
+12 5 4 10 True -2 3.0
+True True True True 7 0
+1.0 1.0 3.1415926536 180.0 True
+ValueError: math domain error
+ValueError: math domain error
+ValueError: factorial() not defined for negative values
+OverflowError: math range error
+10 15 [(0, 'a'), (1, 'b')]
+[1, 2, 3, 'a', 'b'] [1, 2, 3]
+[('a', 'b'), ('a', 'c'), ('b', 'c')] [(0, 1, 2), (0, 1, 3)] [(0, 0), (0, 1), (1, 0), (1, 1)] [('a', 1), ('b', 1)]
+[('a', 'b'), ('b', 'a')] [3, 3, 4] [100, 101, 103, 106] []
+`,
+	},
+	{
+		name: "objetos",
+		code: `class Vector:
+    dimension = 2
+
+    def __init__(self, x, y):
+        self.x, self.y = x, y
+
+    def __repr__(self):
+        return f"Vector({self.x}, {self.y})"
+
+    def __str__(self):
+        return f"({self.x}, {self.y})"
+
+    def __add__(self, otro):
+        return Vector(self.x + otro.x, self.y + otro.y)
+
+    def __radd__(self, otro):
+        return self if otro == 0 else NotImplemented
+
+    def __mul__(self, k):
+        return Vector(self.x * k, self.y * k)
+
+    def __eq__(self, otro):
+        return isinstance(otro, Vector) and (self.x, self.y) == (otro.x, otro.y)
+
+    def __lt__(self, otro):
+        return (self.x, self.y) < (otro.x, otro.y)
+
+    def __hash__(self):
+        return hash((self.x, self.y))
+
+    def __len__(self):
+        return 2
+
+    def __getitem__(self, i):
+        return (self.x, self.y)[i]
+
+    def __contains__(self, v):
+        return v in (self.x, self.y)
+
+    def __bool__(self):
+        return bool(self.x or self.y)
+
+    @property
+    def norma2(self):
+        return self.x ** 2 + self.y ** 2
+
+    @staticmethod
+    def cero():
+        return Vector(0, 0)
+
+    @classmethod
+    def unitario(cls):
+        return cls(1, 0)
+
+v = Vector(3, 4)
+print(v, repr(v), [v], v + Vector(1, 1), v * 2, sum([v, v]), v == Vector(3, 4), v != v, len(v), v[1], 4 in v, list(v))
+print(sorted([Vector(2, 0), Vector(1, 5)]), len({v, Vector(3, 4)}), bool(Vector.cero()), Vector.unitario(), v.norma2, Vector.dimension)
+setattr(v, "z", 9)
+print(getattr(v, "z"), hasattr(v, "w"), getattr(v, "w", "nada"), sorted(vars(v)), v.__dict__["x"], type(v).__name__, v.__class__ is Vector)
+delattr(v, "z")
+del v.y
+print(hasattr(v, "z"), hasattr(v, "y"))
+class Base:
+    def hola(self):
+        return "base"
+class Hija(Base):
+    def hola(self):
+        return "hija+" + super().hola()
+h = Hija()
+print(h.hola(), isinstance(h, Base), issubclass(Hija, Base), [c.__name__ for c in Hija.__mro__], Hija.__bases__[0].__name__)
+print(Hija.hola(h), Base().hola.__name__, callable(h.hola))
+x = 1
+del x
+try:
+    print(x)
+except NameError as e:
+    print("NameError:", e)
+try:
+    v.inexistente
+except AttributeError as e:
+    print("AttributeError:", e)
+try:
+    Vector(1)
+except TypeError as e:
+    print("TypeError:", e)
+def f(a, b, c, *, d):
+    return a
+for llamada in (lambda: f(), lambda: f(1, 2, 3), lambda: f(1, 2, 3, 4, d=1), lambda: f(1, 2, 3, d=4, e=5), lambda: f(1, 2, a=1, d=1)):
+    try:
+        llamada()
+    except TypeError as e:
+        print("TypeError:", e)
+try:
+    len(5)
+except TypeError as e:
+    print("TypeError:", e)
+try:
+    [1] < "a"
+except TypeError as e:
+    print("TypeError:", e)
+try:
+    Vector(1, 2) + 3
+except AttributeError as e:
+    print("AttributeError:", e)
+`,
+		stdout: `(3, 4) Vector(3, 4) [Vector(3, 4)] (4, 5) (6, 8) (6, 8) True False 2 4 True [3, 4]
+[Vector(1, 5), Vector(2, 0)] 1 False (1, 0) 25 2
+9 False nada ['x', 'y', 'z'] 3 Vector True
+False False
+hija+base True True ['Hija', 'Base', 'object'] Base
+hija+base hola True
+NameError: name 'x' is not defined
+AttributeError: 'Vector' object has no attribute 'inexistente'
+TypeError: Vector.__init__() missing 1 required positional argument: 'y'
+TypeError: f() missing 3 required positional arguments: 'a', 'b', and 'c'
+TypeError: f() missing 1 required keyword-only argument: 'd'
+TypeError: f() takes 3 positional arguments but 4 positional arguments (and 1 keyword-only argument) were given
+TypeError: f() got an unexpected keyword argument 'e'
+TypeError: f() got multiple values for argument 'a'
+TypeError: object of type 'int' has no len()
+TypeError: '<' not supported between instances of 'list' and 'str'
+AttributeError: 'int' object has no attribute 'x'
+`,
+	},
+	{
+		name: "atributos_y_secuencias",
+		code: `from collections import deque, defaultdict, Counter
+import sys
+
+class Temperatura:
+    def __init__(self):
+        self._c = 0
+
+    @property
+    def celsius(self):
+        return self._c
+
+    @celsius.setter
+    def celsius(self, valor):
+        if valor < -273.15:
+            raise ValueError("bajo el cero absoluto")
+        self._c = valor
+
+    @property
+    def kelvin(self):
+        return self._c + 273.15
+
+class Registro:
+    def __setattr__(self, nombre, valor):
+        print("asigna", nombre, valor)
+        object.__setattr__(self, nombre, valor)
+
+t = Temperatura()
+t.celsius = 25
+print(t.celsius, t.kelvin)
+for accion in (lambda: setattr(t, "celsius", -300), lambda: setattr(t, "kelvin", 0)):
+    try:
+        accion()
+    except (ValueError, AttributeError) as e:
+        print(type(e).__name__ + ":", e)
+r = Registro()
+r.x = 1
+print(r.x)
+Temperatura.escala = "C"
+print(t.escala)
+del Temperatura.escala
+print(hasattr(t, "escala"))
+def f():
+    pass
+f.etiqueta = "marcada"
+print(f.etiqueta, f.__name__)
+for accion in (lambda: setattr(5, "x", 1), lambda: setattr(int, "x", 1), lambda: delattr(t, "nada"), lambda: "abc".upper.x):
+    try:
+        accion()
+    except (AttributeError, TypeError) as e:
+        print(type(e).__name__ + ":", e)
+s = "añoñ"
+print(s[1], s[-1], s[1:3], s[::-2], s.find("ñ"), len(s), "ñ" in s, s.index("o"))
+xs = list(range(10))
+print(xs[-100:3], xs[8:100], xs[::-3], xs[7:2:-2], xs[-1:-4:-1], xs[5:5], "hola"[10:], (1, 2, 3)[-2:])
+print(xs[: 10 ** 30], xs[-(10 ** 30) : 2], 3 in iter([1, 3]), 4 in (x for x in range(3)))
+for accion in (lambda: 1 in 5, lambda: xs["a":], lambda: xs[1.5]):
+    try:
+        accion()
+    except TypeError as e:
+        print("TypeError:", e)
+print(3 in {1: 2, 3: 4}, "a" in {"a"}, 5 in range(0, 10, 5), 5 in (1, 2), [1] in [[1]], "b" in deque("abc"))
+print(None is None, [] is [], xs is not None, -True, -(-2.5), not None, ~True, type(None).__name__)
+print(type([]).__name__, type({}).__name__, type(set()).__name__, type(range(1)).__name__, type(len).__name__, type(f).__name__, type(t.kelvin).__name__)
+print(type(int).__name__, type("".join).__name__, type(deque()).__name__, type({}.keys()).__name__, type(slice(1)).__name__, type(0.5).__name__)
+q = deque([1, 2, 3], maxlen=4)
+q.append(4)
+q.append(5)
+q.extendleft([0, -1])
+print(q, q.popleft(), q.maxlen, len(q), q[0], q[-1])
+q.rotate(1)
+print(q)
+q.rotate(-2)
+print(q, list(q), q.count(2), q.index(3))
+q.clear()
+print(q, deque(), deque("ab", 1))
+dd = defaultdict(int)
+for c in "hola":
+    dd[c] += 1
+print(dd, dd.default_factory, sorted(Counter("hola mundo").items())[:3])
+cnt = Counter([1, 1, 2])
+cnt.update([2, 3])
+print(cnt, cnt[9], list(cnt.elements()), cnt.total())
+print(sys.stdin.read().split())
+`,
+		stdin: `1 2
+3
+`,
+		stdout: `25 298.15
+ValueError: bajo el cero absoluto
+AttributeError: property 'kelvin' of 'Temperatura' object has no setter
+asigna x 1
+1
+C
+False
+marcada f
+AttributeError: 'int' object has no attribute 'x'
+TypeError: cannot set 'x' attribute of immutable type 'int'
+AttributeError: 'Temperatura' object has no attribute 'nada'
+AttributeError: 'builtin_function_or_method' object has no attribute 'x'
+ñ ñ ño ññ 1 4 True 2
+[0, 1, 2] [8, 9] [9, 6, 3, 0] [7, 5, 3] [9, 8, 7] []  (2, 3)
+[0, 1, 2, 3, 4, 5, 6, 7, 8, 9] [0, 1] True False
+TypeError: argument of type 'int' is not iterable
+TypeError: slice indices must be integers or None or have an __index__ method
+TypeError: list indices must be integers or slices, not float
+True True True False True True
+True False True -1 2.5 True -2 NoneType
+list dict set range builtin_function_or_method function float
+type builtin_function_or_method deque dict_keys slice float
+deque([0, 2, 3], maxlen=4) -1 4 3 0 3
+deque([3, 0, 2], maxlen=4)
+deque([2, 3, 0], maxlen=4) [2, 3, 0] 1 1
+deque([], maxlen=4) deque([]) deque(['b'], maxlen=1)
+defaultdict(<class 'int'>, {'h': 1, 'o': 1, 'l': 1, 'a': 1}) <class 'int'> [(' ', 1), ('a', 1), ('d', 1)]
+Counter({1: 2, 2: 2, 3: 1}) 0 [1, 1, 2, 2, 3] 5
+['1', '2', '3']
+`,
+	},
+}
+
+func TestPythonInterpreter(t *testing.T) {
+	runInterpCases(t, newPyProgram, pythonInterpCases, nil)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+// interpCase es un programa con la salida que da el intérprete real
+type interpCase struct {
+	name   string
+	code   string
+	stdin  string
+	stdout string
+	stderr string
+	exit   int
+}
+
+// Límites holgados para las pruebas: ningún caso se acerca a ellos
+var interpTestLimits = processLimits{MemoryBytes: 256 << 20, OutputBytes: 1 << 20, Steps: 50_000_000}
+
+// runInterpCases ejecuta cada caso con el intérprete integrado y compara
+// stdout, stderr y el código de salida con los esperados; si clean no es nil,
+// stderr pasa por clean antes de compararlo
+func runInterpCases(t *testing.T, newProgram func(code string) embeddedProgram, cases []interpCase, clean func(string) string) {
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			res := runInterpreted(context.Background(), interpTestLimits, ProgramInput{}, c.stdin, newProgram(c.code))
+			if res.Stdout != c.stdout {
+				t.Errorf("stdout:\n%s\nesperado:\n%s", res.Stdout, c.stdout)
+			}
+			stderr := res.Stderr
+			if clean != nil {
+				stderr = clean(stderr)
+			}
+			if stderr != c.stderr {
+				t.Errorf("stderr:\n%s\nesperado:\n%s", stderr, c.stderr)
+			}
+			if res.ExitCode != c.exit {
+				t.Errorf("código de salida %d, esperado %d", res.ExitCode, c.exit)
+			}
+		})
+	}
+}
@@ -0,0 +1,532 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ──────────────────────────── Análisis asíncrono ─────────────────────────
+//
+// POST /api/v1/analyze/async recibe el mismo cuerpo que /api/v1/analyze, lo
+// deja en una cola y responde enseguida 202 con el id del trabajo; el
+// cliente consulta GET /api/v1/jobs/{id} hasta que el estado es "done" y la
+// respuesta trae result. Así una compilación larga de C++ no mantiene
+// abierta la conexión HTTP. La cola tiene a lo sumo
+// GlobalConfig.AsyncQueueSize trabajos esperando (si no, 503); los atienden
+// GlobalConfig.AsyncWorkers workers y cada resultado se guarda
+// GlobalConfig.JobTTL desde que termina. Está en memoria (jobQueue) o, con
+// REDIS_URL, en Redis (redisJobQueue): ahí la comparten todas las instancias
+// y cualquiera de ellas atiende un trabajo o responde por su estado.
+//
+// Cada trabajo tiene además su X-Request-ID, con el que se puede detener la
+// ejecución mientras corre (ver executions.go). Solo lo consulta quien lo
+// encoló: para cualquier otro usuario responde 404, como si no existiera.
+
+// Estados de un trabajo
+const (
+	JobQueued  = "queued"
+	JobRunning = "running"
+	JobDone    = "done"
+)
+
+var (
+	errQueueFull        = errors.New("job queue is full")
+	errDuplicateRequest = errors.New("an execution with this request id is already running")
+)
+
+// jobBackend guarda los trabajos y los reparte entre los workers
+type jobBackend interface {
+	// enqueue agrega a la cola el análisis de req, con rid como X-Request-ID,
+	// cargando la ejecución a principal
+	enqueue(req AnalyzeRequest, rid string, principal *Principal) (APIJob, error)
+	// get devuelve el estado del trabajo id de owner, o false si no existe,
+	// venció o lo encoló otro usuario
+	get(id, owner string) (APIJob, bool, error)
+	// depth devuelve cuántos trabajos esperan un worker
+	depth() (int, error)
+}
+
+type analysisJob struct {
+	id        string
+	requestID string
+	seq       uint64 // orden de llegada, para la posición en la cola
+	req       AnalyzeRequest
+	principal *Principal
+	owner     string // ver jobOwner
+	status    string
+	created   time.Time
+	started   time.Time
+	finished  time.Time
+	result    *APIAnalyzeResponse
+	failure   *APIError
+}
+
+type jobQueue struct {
+	mu      sync.Mutex
+	jobs    map[string]*analysisJob
+	pending chan *analysisJob
+	nextSeq uint64
+}
+
+var jobs jobBackend
+
+// newJobQueue crea la cola con lugar para size trabajos en espera y arranca
+// workers workers
+func newJobQueue(size, workers int) *jobQueue {
+	q := &jobQueue{
+		jobs:    make(map[string]*analysisJob),
+		pending: make(chan *analysisJob, size),
+	}
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+	return q
+}
+
+// newJobID devuelve un id difícil de adivinar: el resultado incluye el código
+// y la salida del programa
+func newJobID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// jobOwner identifica al usuario p entre los dueños de los trabajos; los
+// anónimos comparten el dueño ""
+func jobOwner(p *Principal) string {
+	if p == nil {
+		return ""
+	}
+	return p.Account
+}
+
+// enqueue descarta antes los resultados vencidos
+func (q *jobQueue) enqueue(req AnalyzeRequest, rid string, principal *Principal) (APIJob, error) {
+	id, err := newJobID()
+	if err != nil {
+		return APIJob{}, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := time.Now()
+	for jid, job := range q.jobs {
+		if job.status == JobDone && now.Sub(job.finished) > GlobalConfig.JobTTL {
+			delete(q.jobs, jid)
+		} else if job.status != JobDone && job.requestID == rid {
+			return APIJob{}, errDuplicateRequest
+		}
+	}
+	q.nextSeq++
+	job := &analysisJob{
+		id:        id,
+		requestID: rid,
+		seq:       q.nextSeq,
+		req:       req,
+		principal: principal,
+		owner:     jobOwner(principal),
+		status:    JobQueued,
+		created:   now,
+	}
+	select {
+	case q.pending <- job:
+	default:
+		return APIJob{}, errQueueFull
+	}
+	q.jobs[job.id] = job
+	return q.describe(job), nil
+}
+
+// work atiende los trabajos de la cola uno por vez
+func (q *jobQueue) work() {
+	for job := range q.pending {
+		q.mu.Lock()
+		job.status = JobRunning
+		job.started = time.Now()
+		req := job.req
+		q.mu.Unlock()
+
+		result, failure := runJob(req, job.requestID, job.principal)
+
+		q.mu.Lock()
+		job.status = JobDone
+		job.finished = time.Now()
+		job.result, job.failure = result, failure
+		// El código ya no hace falta; solo se guarda el resultado
+		job.req, job.principal = AnalyzeRequest{}, nil
+		q.mu.Unlock()
+	}
+}
+
+func (q *jobQueue) get(id, owner string) (APIJob, bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.jobs[id]
+	if !ok || job.owner != owner {
+		return APIJob{}, false, nil
+	}
+	if job.status == JobDone && time.Since(job.finished) > GlobalConfig.JobTTL {
+		delete(q.jobs, id)
+		return APIJob{}, false, nil
+	}
+	return q.describe(job), true, nil
+}
+
+func (q *jobQueue) depth() (int, error) {
+	return len(q.pending), nil
+}
+
+// runJob analiza req como analyzeRequest; si el análisis entra en pánico
+// lo registra y devuelve el error del trabajo en lugar de tirar el worker
+// (ver recover.go)
+func runJob(req AnalyzeRequest, rid string, principal *Principal) (result *APIAnalyzeResponse, failure *APIError) {
+	defer func() {
+		if r := recover(); r != nil {
+			logPanic(fmt.Sprintf("job (request %q)", rid), capturePanic(r))
+			result, failure = nil, &APIError{Code: "internal_error", Message: "Internal server error"}
+		}
+	}()
+	response := analyzeRequest(req, rid, principal, nil)
+	return &response, nil
+}
+
+// describe convierte job a su forma de la API; q.mu debe estar tomado
+func (q *jobQueue) describe(job *analysisJob) APIJob {
+	api := APIJob{
+		ID:        job.id,
+		RequestID: job.requestID,
+		Status:    job.status,
+		CreatedAt: job.created.UTC(),
+		Result:    job.result,
+		Error:     job.failure,
+	}
+	switch job.status {
+	case JobQueued:
+		// Trabajos que llegaron antes y todavía esperan, más este
+		api.Position = 1
+		for _, other := range q.jobs {
+			if other.status == JobQueued && other.seq < job.seq {
+				api.Position++
+			}
+		}
+	case JobDone:
+		finished := job.finished.UTC()
+		api.FinishedAt = &finished
+		fallthrough
+	case JobRunning:
+		started := job.started.UTC()
+		api.StartedAt = &started
+	}
+	return api
+}
+
+// APIJob es el estado de un análisis asíncrono; Result está cuando Status es
+// "done" y tiene la forma de la respuesta de /api/v1/analyze, salvo que el
+// análisis haya fallado: entonces está Error
+type APIJob struct {
+	ID        string `json:"id"`
+	RequestID string `json:"requestId"`
+	Status    string `json:"status"` // "queued" | "running" | "done"
+	// Lugar en la cola (1 = el próximo), mientras espera
+	Position   int                 `json:"position,omitempty"`
+	CreatedAt  time.Time           `json:"createdAt"`
+	StartedAt  *time.Time          `json:"startedAt,omitempty"`
+	FinishedAt *time.Time          `json:"finishedAt,omitempty"`
+	Result     *APIAnalyzeResponse `json:"result,omitempty"`
+	Error      *APIError           `json:"error,omitempty"`
+}
+
+func analyzeAsyncHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req AnalyzeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w)
+		return
+	}
+	rid, msg := requestID(r)
+	if msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if executions.active(rid) {
+		httpError(w, "An execution with this request id is already running", http.StatusConflict)
+		return
+	}
+	w.Header().Set(requestIDHeader, rid)
+	principal := principalFrom(r)
+	if msg := req.validate(principal); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	req.Locale = requestLocale(req.Locale, r)
+
+	if status, msg := req.authorize(principal); status != 0 {
+		rejectAnalysis(w, status, msg)
+		return
+	}
+
+	status, err := jobs.enqueue(req, rid, principal)
+	switch {
+	case errors.Is(err, errDuplicateRequest):
+		httpError(w, "An execution with this request id is already running", http.StatusConflict)
+		return
+	case errors.Is(err, errQueueFull):
+		httpError(w, "Job queue is full", http.StatusServiceUnavailable)
+		return
+	case err != nil:
+		log.Printf("jobs: no se pudo encolar el análisis: %v", err)
+		httpError(w, "Job queue unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Location", apiPrefix+"/jobs/"+status.ID)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(status)
+}
+
+func jobHandler(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, apiPrefix+"/jobs/")
+	if id == "" || strings.Contains(id, "/") {
+		httpError(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodGet {
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	status, ok, err := jobs.get(id, jobOwner(principalFrom(r)))
+	if err != nil {
+		log.Printf("jobs: no se pudo leer el trabajo %s: %v", id, err)
+		httpError(w, "Job queue unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if !ok {
+		httpError(w, "Job not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// ───────────────────────────── Cola en Redis ─────────────────────────────
+//
+// La cola es la lista compiler:jobs (LPUSH al encolar, BRPOP en cada
+// worker) y cada trabajo se guarda en compiler:job:{id} como JSON con la
+// petición mientras espera y el resultado al terminar; solo entonces vence.
+// compiler:request:{rid} reserva el X-Request-ID mientras el trabajo no
+// terminó, para rechazar el mismo id desde cualquier instancia. Un trabajo
+// que corría en una instancia que se cae queda en "running" hasta que se
+// descarta a mano.
+
+type redisJobQueue struct {
+	client *redisClient
+}
+
+// redisJob es lo que se guarda de cada trabajo
+type redisJob struct {
+	APIJob
+	Request   *AnalyzeRequest `json:"request,omitempty"`
+	Principal *Principal      `json:"principal,omitempty"`
+	Owner     string          `json:"owner,omitempty"`
+}
+
+// Tiempo máximo que se reserva un X-Request-ID, por si la instancia que
+// atendía el trabajo se cae
+const redisRequestTTL = 24 * time.Hour
+
+// Segundos que BRPOP espera un trabajo antes de volver a intentar
+const redisPollSeconds = 5
+
+// newRedisJobQueue arranca workers workers que toman trabajos de Redis
+func newRedisJobQueue(client *redisClient, workers int) *redisJobQueue {
+	q := &redisJobQueue{client: client}
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+	return q
+}
+
+func (q *redisJobQueue) enqueue(req AnalyzeRequest, rid string, principal *Principal) (APIJob, error) {
+	id, err := newJobID()
+	if err != nil {
+		return APIJob{}, err
+	}
+	reply, err := q.client.do("LLEN", redisPrefix+"jobs")
+	if err != nil {
+		return APIJob{}, err
+	}
+	waiting, _ := reply.(int64)
+	if waiting >= int64(GlobalConfig.AsyncQueueSize) {
+		return APIJob{}, errQueueFull
+	}
+	ridKey := redisPrefix + "request:" + rid
+	reply, err = q.client.do("SET", ridKey, id, "NX", "EX", strconv.Itoa(int(redisRequestTTL.Seconds())))
+	if err != nil {
+		return APIJob{}, err
+	}
+	if reply == nil {
+		return APIJob{}, errDuplicateRequest
+	}
+
+	job := redisJob{
+		APIJob:    APIJob{ID: id, RequestID: rid, Status: JobQueued, CreatedAt: time.Now().UTC()},
+		Request:   &req,
+		Principal: principal,
+		Owner:     jobOwner(principal),
+	}
+	if err := q.save(job, 0); err != nil {
+		q.client.do("DEL", ridKey)
+		return APIJob{}, err
+	}
+	if reply, err = q.client.do("LPUSH", redisPrefix+"jobs", id); err != nil {
+		q.client.do("DEL", ridKey, redisPrefix+"job:"+id)
+		return APIJob{}, err
+	}
+	job.Position = int(waiting) + 1
+	if length, ok := reply.(int64); ok {
+		job.Position = int(length)
+	}
+	return job.APIJob, nil
+}
+
+func (q *redisJobQueue) get(id, owner string) (APIJob, bool, error) {
+	job, ok, err := q.load(id)
+	if err != nil || !ok || job.Owner != owner {
+		return APIJob{}, false, err
+	}
+	if job.Status == JobQueued {
+		// LPUSH agrega al principio y BRPOP toma del final: la posición se
+		// cuenta desde el final. LPOS requiere Redis 6.0.6; si no está, la
+		// posición se omite
+		index, err1 := q.client.do("LPOS", redisPrefix+"jobs", id)
+		length, err2 := q.client.do("LLEN", redisPrefix+"jobs")
+		i, ok1 := index.(int64)
+		n, ok2 := length.(int64)
+		if err1 == nil && err2 == nil && ok1 && ok2 {
+			job.Position = int(n - i)
+		}
+	}
+	return job.APIJob, true, nil
+}
+
+// depth es el largo de la lista compartida: incluye los trabajos que
+// esperan a cualquier instancia
+func (q *redisJobQueue) depth() (int, error) {
+	reply, err := q.client.do("LLEN", redisPrefix+"jobs")
+	if err != nil {
+		return 0, err
+	}
+	n, _ := reply.(int64)
+	return int(n), nil
+}
+
+func (q *redisJobQueue) load(id string) (redisJob, bool, error) {
+	var job redisJob
+	reply, err := q.client.do("GET", redisPrefix+"job:"+id)
+	if err != nil {
+		return job, false, err
+	}
+	data, ok := reply.(string)
+	if !ok {
+		return job, false, nil
+	}
+	if err := json.Unmarshal([]byte(data), &job); err != nil {
+		return job, false, err
+	}
+	return job, true, nil
+}
+
+// save guarda el trabajo; con ttl > 0 vence después de ese tiempo
+func (q *redisJobQueue) save(job redisJob, ttl time.Duration) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	args := []string{"SET", redisPrefix + "job:" + job.ID, string(data)}
+	if ttl > 0 {
+		args = append(args, "EX", strconv.Itoa(int(ttl.Seconds())))
+	}
+	_, err = q.client.do(args...)
+	return err
+}
+
+// work espera trabajos en una conexión propia, porque BRPOP la bloquea; si
+// Redis no responde reintenta cada segundo
+func (q *redisJobQueue) work() {
+	for {
+		rc, err := q.client.dial()
+		if err != nil {
+			log.Printf("jobs: no se pudo conectar a Redis: %v", err)
+			time.Sleep(time.Second)
+			continue
+		}
+		for {
+			reply, err := rc.do(redisTimeout+redisPollSeconds*time.Second, "BRPOP", redisPrefix+"jobs", strconv.Itoa(redisPollSeconds))
+			if err != nil {
+				log.Printf("jobs: error esperando trabajos en Redis: %v", err)
+				break
+			}
+			// nil si no llegó ningún trabajo; si no, [lista, id]
+			if item, ok := reply.([]interface{}); ok && len(item) == 2 {
+				if id, ok := item[1].(string); ok {
+					q.run(id)
+				}
+			}
+		}
+		rc.conn.Close()
+		time.Sleep(time.Second)
+	}
+}
+
+// run analiza el trabajo id y guarda su resultado
+func (q *redisJobQueue) run(id string) {
+	job, ok, err := q.load(id)
+	if err != nil || !ok || job.Request == nil {
+		log.Printf("jobs: no se encontró el trabajo %s en Redis: %v", id, err)
+		return
+	}
+	req := *job.Request
+	started := time.Now().UTC()
+	job.Status = JobRunning
+	job.StartedAt = &started
+	if err := q.save(job, 0); err != nil {
+		log.Printf("jobs: no se pudo actualizar el trabajo %s: %v", id, err)
+	}
+
+	// El espacio de trabajo no viaja en el JSON: se recupera por su id, y
+	// del usuario fuera del espacio basta la cuenta para cargarle el consumo
+	if p := job.Principal; p != nil && p.Workspace != "" {
+		p.workspace = GlobalConfig.Workspaces[p.Workspace]
+		if account, ok := strings.CutPrefix(p.Account, "ws:"+p.Workspace+"/"); ok {
+			p.personal = &Principal{Account: account, User: p.User}
+		}
+	}
+	result, failure := runJob(req, job.RequestID, job.Principal)
+
+	finished := time.Now().UTC()
+	job.Status = JobDone
+	job.FinishedAt = &finished
+	job.Result, job.Error = result, failure
+	// El código ya no hace falta; solo se guarda el resultado
+	job.Request, job.Principal = nil, nil
+	if err := q.save(job, GlobalConfig.JobTTL); err != nil {
+		log.Printf("jobs: no se pudo guardar el resultado del trabajo %s: %v", id, err)
+	}
+	q.client.do("DEL", redisPrefix+"request:"+job.RequestID)
+}
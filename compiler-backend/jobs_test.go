@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestJobVisibleOnlyToOwner comprueba que GET /api/v1/jobs/{id} responde
+// 404 a quien no encoló el trabajo
+func TestJobVisibleOnlyToOwner(t *testing.T) {
+	saved := jobs
+	// Sin workers: el trabajo queda en la cola
+	queue := newJobQueue(1, 0)
+	jobs = queue
+	defer func() { jobs = saved }()
+
+	owner := &Principal{Account: "key:ana", User: "ana"}
+	job, err := queue.enqueue(AnalyzeRequest{Code: "print(1)", Language: "python"}, "rid-ana", owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name      string
+		principal *Principal
+		status    int
+	}{
+		{"dueño", owner, http.StatusOK},
+		{"otro_usuario", &Principal{Account: "key:beto", User: "beto"}, http.StatusNotFound},
+		{"anonimo", nil, http.StatusNotFound},
+		{"mismo_usuario_en_un_espacio", &Principal{Account: "ws:curso/key:ana", User: "ana"}, http.StatusNotFound},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, apiPrefix+"/jobs/"+job.ID, nil)
+			if c.principal != nil {
+				r = r.WithContext(context.WithValue(r.Context(), principalKey{}, c.principal))
+			}
+			w := httptest.NewRecorder()
+			jobHandler(w, r)
+			if w.Code != c.status {
+				t.Errorf("estado %d, esperado %d: %s", w.Code, c.status, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ───────────────────────────── Modo juez ─────────────────────────────────
+//
+// Con expectedOutput en la petición la salida estándar del programa se
+// compara con la esperada y la respuesta trae un veredicto, como en un juez
+// en línea: AC (aceptado), WA (respuesta incorrecta), TLE (tiempo excedido),
+// RE (error en ejecución: código de salida distinto de 0 o un límite) y CE
+// (no compiló). Solo se compara stdout; lo que el programa escribe en stderr
+// no afecta al veredicto.
+//
+// compare elige cómo se comparan:
+//
+//   exact    byte a byte
+//   trimmed  sin espacios al final de cada línea ni líneas vacías al final,
+//            y \r\n igual a \n (por defecto)
+//   tokens   palabra por palabra, sin importar espacios ni saltos de línea
+//   float    como tokens, pero los números se comparan con tolerance de
+//            error absoluto o relativo (1e-6 por defecto)
+//
+// Con testCases el programa se compila una vez y se ejecuta con la entrada
+// de cada caso (ver ProgramInput.runEach). Cada caso tiene su veredicto y
+// suma sus puntos si es AC; el veredicto general es el del primer caso que
+// no pasó, o AC si pasaron todos.
+
+// Modos de comparación
+const (
+	CompareExact   = "exact"
+	CompareTrimmed = "trimmed"
+	CompareTokens  = "tokens"
+	CompareFloat   = "float"
+)
+
+// Veredictos
+const (
+	VerdictAccepted     = "AC"
+	VerdictWrongAnswer  = "WA"
+	VerdictTimeLimit    = "TLE"
+	VerdictRuntimeError = "RE"
+	VerdictCompileError = "CE"
+)
+
+const defaultFloatTolerance = 1e-6
+
+// Casos de prueba por envío
+const maxTestCases = 50
+
+// TestCase es un caso de prueba: la entrada, la salida esperada y cuántos
+// puntos vale (0 = 1 punto)
+type TestCase struct {
+	Stdin          string `json:"stdin"`
+	ExpectedOutput string `json:"expectedOutput"`
+	Points         int    `json:"points,omitempty"`
+}
+
+// TestCaseResult es el veredicto de un caso
+type TestCaseResult struct {
+	JudgeResult
+	Points     int
+	Earned     int
+	DurationMs int64
+	ExitCode   *int
+	Stdout     string
+	// Recursos de la ejecución del caso (ver ExecutionResult)
+	PeakMemory  int64
+	UserCPUMs   int64
+	SystemCPUMs int64
+}
+
+// TestsResult son los veredictos de todos los casos y el puntaje total
+type TestsResult struct {
+	Verdict  string
+	Compare  string
+	Cases    []TestCaseResult
+	Score    int
+	MaxScore int
+}
+
+// JudgeOptions es la salida esperada y cómo compararla
+type JudgeOptions struct {
+	ExpectedOutput string
+	Compare        string  // "" = trimmed
+	Tolerance      float64 // 0 = defaultFloatTolerance; solo para float
+}
+
+// JudgeResult es el veredicto; Message explica la primera diferencia (WA)
+// o el código de salida (RE)
+type JudgeResult struct {
+	Verdict string
+	Compare string
+	Message string
+}
+
+// invalidJudge devuelve el motivo por el que compare o tolerance no son
+// válidos, o "" si lo son
+func invalidJudge(compare string, tolerance float64) string {
+	switch compare {
+	case "", CompareExact, CompareTrimmed, CompareTokens, CompareFloat:
+	default:
+		return "compare must be exact, trimmed, tokens or float"
+	}
+	if tolerance < 0 || math.IsNaN(tolerance) {
+		return "tolerance must be positive"
+	}
+	return ""
+}
+
+// invalidTestCases devuelve el motivo por el que los casos no son válidos, o
+// "" si lo son
+func invalidTestCases(cases []TestCase) string {
+	if len(cases) > maxTestCases {
+		return fmt.Sprintf("testCases must have at most %d elements", maxTestCases)
+	}
+	for _, c := range cases {
+		if c.Points < 0 {
+			return "testCases: points must be positive"
+		}
+	}
+	return invalidStdin(testCaseStdins(cases))
+}
+
+// testCaseStdins devuelve la entrada de cada caso
+func testCaseStdins(cases []TestCase) []string {
+	stdins := make([]string, len(cases))
+	for i, c := range cases {
+		stdins[i] = c.Stdin
+	}
+	return stdins
+}
+
+func (c TestCase) points() int {
+	if c.Points == 0 {
+		return 1
+	}
+	return c.Points
+}
+
+func (o JudgeOptions) compare() string {
+	if o.Compare == "" {
+		return CompareTrimmed
+	}
+	return o.Compare
+}
+
+func (o JudgeOptions) tolerance() float64 {
+	if o.Tolerance <= 0 {
+		return defaultFloatTolerance
+	}
+	return o.Tolerance
+}
+
+// judgeKey resume las opciones para la clave de la caché
+func judgeKey(o *JudgeOptions) string {
+	if o == nil {
+		return ""
+	}
+	return o.compare() + ":" + strconv.FormatFloat(o.tolerance(), 'g', -1, 64) + ":" + o.ExpectedOutput
+}
+
+// judgeExecution da el veredicto de res. Devuelve nil si el programa no se
+// ejecutó o el servidor estaba ocupado: no hay nada que juzgar
+func judgeExecution(res *ExecutionResult, opts JudgeOptions) *JudgeResult {
+	if res == nil || res.Transient && !res.TimedOut {
+		return nil
+	}
+	r := &JudgeResult{Compare: opts.compare()}
+	switch {
+	case res.TimedOut:
+		r.Verdict = VerdictTimeLimit
+	case res.CompileError:
+		r.Verdict = VerdictCompileError
+	case !res.Ok:
+		r.Verdict = VerdictRuntimeError
+		if res.ExitCode != nil {
+			r.Message = fmt.Sprintf("el programa terminó con código %d", *res.ExitCode)
+		}
+	default:
+		r.Message = compareOutput(res.RunStdout, opts)
+		r.Verdict = VerdictAccepted
+		if r.Message != "" {
+			r.Verdict = VerdictWrongAnswer
+		}
+	}
+	return r
+}
+
+// judgeTestCases da el veredicto de cada caso. res.Runs tiene una ejecución
+// por caso; si el programa no compiló (o hubo un solo caso) res es el
+// resultado de todos. opts da la comparación; su ExpectedOutput no se usa
+func judgeTestCases(res *ExecutionResult, cases []TestCase, opts JudgeOptions) *TestsResult {
+	if res == nil || res.Transient && !res.TimedOut && len(res.Runs) == 0 {
+		return nil
+	}
+	tests := &TestsResult{Verdict: VerdictAccepted, Compare: opts.compare()}
+	for i, c := range cases {
+		run := res
+		if i < len(res.Runs) {
+			run = &res.Runs[i]
+		}
+		caseOpts := opts
+		caseOpts.ExpectedOutput = c.ExpectedOutput
+		r := TestCaseResult{
+			Points:      c.points(),
+			DurationMs:  run.DurationMs,
+			ExitCode:    run.ExitCode,
+			Stdout:      run.RunStdout,
+			PeakMemory:  run.PeakMemory,
+			UserCPUMs:   run.UserCPUMs,
+			SystemCPUMs: run.SystemCPUMs,
+		}
+		if j := judgeExecution(run, caseOpts); j != nil {
+			r.JudgeResult = *j
+		}
+		if r.Verdict == VerdictAccepted {
+			r.Earned = r.Points
+		} else if tests.Verdict == VerdictAccepted {
+			tests.Verdict = r.Verdict
+		}
+		tests.Score += r.Earned
+		tests.MaxScore += r.Points
+		tests.Cases = append(tests.Cases, r)
+	}
+	return tests
+}
+
+// compareOutput compara la salida con la esperada y devuelve la primera
+// diferencia, o "" si son iguales
+func compareOutput(actual string, opts JudgeOptions) string {
+	expected := opts.ExpectedOutput
+	switch opts.compare() {
+	case CompareExact:
+		if actual == expected {
+			return ""
+		}
+		return lineDifference(strings.Split(actual, "\n"), strings.Split(expected, "\n"))
+	case CompareTokens:
+		return tokenDifference(strings.Fields(actual), strings.Fields(expected), nil)
+	case CompareFloat:
+		tolerance := opts.tolerance()
+		return tokenDifference(strings.Fields(actual), strings.Fields(expected), func(a, b string) bool {
+			return floatsMatch(a, b, tolerance)
+		})
+	default:
+		return lineDifference(trimmedLines(actual), trimmedLines(expected))
+	}
+}
+
+// trimmedLines divide s en líneas sin espacios al final ni líneas vacías al
+// final
+func trimmedLines(s string) []string {
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " \t\r")
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// lineDifference describe la primera línea distinta, o "" si no hay
+func lineDifference(actual, expected []string) string {
+	for i := 0; i < len(actual) || i < len(expected); i++ {
+		if i < len(actual) && i < len(expected) && actual[i] == expected[i] {
+			continue
+		}
+		return fmt.Sprintf("línea %d: se esperaba %s, se obtuvo %s", i+1, quoteOrEnd(expected, i), quoteOrEnd(actual, i))
+	}
+	return ""
+}
+
+// tokenDifference describe la primera palabra distinta, o "" si no hay;
+// equal compara dos palabras (nil: iguales byte a byte)
+func tokenDifference(actual, expected []string, equal func(a, b string) bool) string {
+	for i := 0; i < len(actual) || i < len(expected); i++ {
+		if i < len(actual) && i < len(expected) && (actual[i] == expected[i] || equal != nil && equal(actual[i], expected[i])) {
+			continue
+		}
+		return fmt.Sprintf("palabra %d: se esperaba %s, se obtuvo %s", i+1, quoteOrEnd(expected, i), quoteOrEnd(actual, i))
+	}
+	return ""
+}
+
+func quoteOrEnd(items []string, i int) string {
+	if i >= len(items) {
+		return "el fin de la salida"
+	}
+	return strconv.Quote(items[i])
+}
+
+// floatsMatch indica si a y b son números que difieren a lo sumo en
+// tolerance, en valor absoluto o relativo a b
+func floatsMatch(a, b string, tolerance float64) bool {
+	x, err := strconv.ParseFloat(a, 64)
+	if err != nil {
+		return false
+	}
+	y, err := strconv.ParseFloat(b, 64)
+	if err != nil {
+		return false
+	}
+	diff := math.Abs(x - y)
+	return diff <= tolerance || diff <= tolerance*math.Abs(y)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// ──────────────────────────────── JUnit XML ──────────────────────────────
+//
+// Los veredictos del modo juez y de los casos de prueba se exportan también
+// como JUnit XML, el formato de resultados de pruebas que muestran Jenkins,
+// GitLab, GitHub Actions y muchos LMS: testsFormat "junit" en
+// /api/v1/analyze agrega el documento en testsJunit, y `analyze --junit
+// archivo.xml` lo escribe con una suite por archivo.
+//
+// Cada caso es un <testcase>: AC pasa, WA es un <failure> y RE, TLE y CE
+// son un <error> porque el programa no llegó a dar una respuesta. El
+// mensaje del veredicto va en el atributo message y lo que imprimió el
+// programa en <system-out>. El puntaje y el modo de comparación quedan en
+// las propiedades de la suite.
+
+const testsFormatJUnit = "junit"
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Time       string          `xml:"time,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure"`
+	Error     *junitProblem `xml:"error"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr,omitempty"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junitSubmission es una entrega con sus veredictos; name identifica la
+// suite (el archivo o el fileName de la petición)
+type junitSubmission struct {
+	name     string
+	language string
+	response APIAnalyzeResponse
+}
+
+// Nombre de los casos en cada idioma: "caso 1", o "salida esperada" para
+// el veredicto de expectedOutput
+var junitCaseNames = map[string][2]string{
+	"es": {"caso", "salida esperada"},
+	"en": {"case", "expected output"},
+}
+
+// validTestsFormat indica si format es un formato de exportación conocido
+func validTestsFormat(format string) bool {
+	return format == testsFormatJUnit
+}
+
+// hasVerdicts indica si la respuesta trae algo que exportar
+func hasVerdicts(response APIAnalyzeResponse) bool {
+	return response.Judge != nil || response.TestResults != nil
+}
+
+// junitReport genera el documento con una suite por entrega que tenga
+// veredictos
+func junitReport(submissions []junitSubmission, locale string) string {
+	names, ok := junitCaseNames[locale]
+	if !ok {
+		names = junitCaseNames["es"]
+	}
+	report := junitTestSuites{Suites: []junitTestSuite{}}
+	var totalMs int64
+	for _, s := range submissions {
+		if !hasVerdicts(s.response) {
+			continue
+		}
+		suite := junitTestSuite{Name: s.name}
+		var suiteMs int64
+		add := func(name, verdict, message, stdout string, ms int64) {
+			c := junitTestCase{Name: name, Classname: s.name, Time: junitSeconds(ms), SystemOut: stdout}
+			switch verdict {
+			case VerdictAccepted:
+			case VerdictWrongAnswer:
+				c.Failure = &junitProblem{Message: message, Type: verdict, Text: message}
+				suite.Failures++
+			default:
+				c.Error = &junitProblem{Message: message, Type: verdict, Text: message}
+				suite.Errors++
+			}
+			suite.Cases = append(suite.Cases, c)
+			suiteMs += ms
+		}
+
+		if tests := s.response.TestResults; tests != nil {
+			for i, c := range tests.Cases {
+				add(fmt.Sprintf("%s %d", names[0], i+1), c.Verdict, c.Message, c.Stdout, c.DurationMs)
+			}
+			suite.Properties = append(suite.Properties,
+				junitProperty{"score", fmt.Sprint(tests.Score)},
+				junitProperty{"maxScore", fmt.Sprint(tests.MaxScore)},
+				junitProperty{"verdict", tests.Verdict},
+				junitProperty{"compare", tests.Compare})
+		} else if judge := s.response.Judge; judge != nil {
+			var stdout string
+			var ms int64
+			if exec := s.response.ExecutionResult; exec != nil {
+				stdout, ms = exec.RunStdout, exec.DurationMs
+			}
+			add(names[1], judge.Verdict, judge.Message, stdout, ms)
+			suite.Properties = append(suite.Properties,
+				junitProperty{"verdict", judge.Verdict},
+				junitProperty{"compare", judge.Compare})
+		}
+		if s.language != "" {
+			suite.Properties = append(suite.Properties, junitProperty{"language", s.language})
+		}
+
+		suite.Tests = len(suite.Cases)
+		suite.Time = junitSeconds(suiteMs)
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Errors += suite.Errors
+		totalMs += suiteMs
+		report.Suites = append(report.Suites, suite)
+	}
+	report.Time = junitSeconds(totalMs)
+
+	out, _ := xml.MarshalIndent(report, "", "  ")
+	return xml.Header + string(out) + "\n"
+}
+
+func junitSeconds(ms int64) string {
+	return fmt.Sprintf("%.3f", float64(ms)/1000)
+}
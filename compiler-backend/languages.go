@@ -0,0 +1,457 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// ─────────────────────────── Registro de lenguajes ───────────────────────
+//
+// Cada lenguaje soportado se describe con un Language y se registra con
+// RegisterLanguage desde el archivo que lo implementa (css.go, html.go,
+// sql.go...). El lexer, el parser, el analizador semántico y el ejecutor
+// real lo buscan por nombre con languageFor en lugar de repetir un switch
+// por lenguaje, así agregar uno nuevo es escribir un archivo con sus
+// patrones, su gramática y su registro. Un nombre no registrado usa
+// genericLanguage: tokens con los patrones generales, sin árbol y con las
+// reglas de declaración más comunes.
+
+// Language es lo que el pipeline necesita de un lenguaje
+type Language interface {
+	// Nombre usado en las peticiones ("cpp", "python"...)
+	Name() string
+	// Expresiones regulares del lexer y orden en que se prueban los
+	// reconocedores de tokens
+	Patterns() LanguagePatterns
+	Matchers() []matcher
+	// Nombres predefinidos y palabras reservadas
+	Keywords() LanguageKeywords
+	// Construye la raíz del árbol sintáctico; false si no hay gramática
+	Parse(p *Parser) (ParseNode, bool)
+	// Análisis semántico completo
+	Analyze(s *SemanticAnalyzer) ([]Symbol, []CompilerError)
+	// Registra las declaraciones que se conocen antes de recorrer los tokens
+	// y devuelve cómo reconocer declaraciones y usos en ese recorrido
+	RegisterDeclarations(s *SemanticAnalyzer, declared map[string]int, used map[string][]int, syms *[]Symbol) declarationIndex
+	// Compila y ejecuta code localmente (ver RealExecutor)
+	Execute(timeout time.Duration, limits processLimits, code string, input ProgramInput) ExecutionResult
+	// Convierte la salida del compilador o intérprete en errores
+	CompilerErrors(output string) []CompilerError
+}
+
+// LanguageKeywords son los nombres que el análisis semántico trata aparte:
+// los predefinidos no se reportan como no declarados y los reservados no
+// pueden declararse
+type LanguageKeywords struct {
+	Builtins map[string]bool
+	Reserved map[string]bool
+}
+
+// declarationIndex guía la pasada sobre los identificadores del análisis
+// semántico genérico
+type declarationIndex interface {
+	// El identificador tokens[i] declara un nombre nuevo
+	declaresAt(tokens []Token, i int) bool
+	// El identificador tokens[i], que no declara, cuenta como uso
+	countsUse(tokens []Token, i int, declared map[string]int) bool
+	// Un nombre declarado y nunca usado merece una advertencia
+	reportsUnused(name, kind string) bool
+}
+
+// tokenDeclarations reconoce las declaraciones por los tokens que las
+// preceden (int x, let x, def f); todos los demás identificadores son usos
+type tokenDeclarations func(tokens []Token, i int) bool
+
+func (f tokenDeclarations) declaresAt(tokens []Token, i int) bool     { return i > 0 && f(tokens, i) }
+func (tokenDeclarations) countsUse([]Token, int, map[string]int) bool { return true }
+func (tokenDeclarations) reportsUnused(string, string) bool           { return true }
+
+var languages = map[string]Language{}
+
+// RegisterLanguage agrega l al registro; registrar dos veces el mismo
+// nombre es un error de programación
+func RegisterLanguage(l Language) {
+	if _, exists := languages[l.Name()]; exists {
+		panic("lenguaje registrado dos veces: " + l.Name())
+	}
+	languages[l.Name()] = l
+}
+
+// languageFor devuelve el lenguaje registrado con ese nombre o
+// genericLanguage
+func languageFor(name string) Language {
+	if l, ok := languages[name]; ok {
+		return l
+	}
+	return genericLanguage
+}
+
+// languageDef implementa Language con campos; los que quedan en cero toman
+// el comportamiento genérico
+type languageDef struct {
+	name     string
+	patterns LanguagePatterns
+	// nil: order
+	matchers []matcher
+	keywords LanguageKeywords
+	parse    func(p *Parser) ParseNode
+	// Reemplaza al análisis genérico de declaraciones y usos (CSS, HTML, SQL)
+	analyze func(s *SemanticAnalyzer) ([]Symbol, []CompilerError)
+	// nil: declarationIndex por tokens con declares
+	register func(s *SemanticAnalyzer, declared map[string]int, used map[string][]int, syms *[]Symbol) declarationIndex
+	declares tokenDeclarations
+	execute  func(timeout time.Duration, limits processLimits, code string, input ProgramInput) ExecutionResult
+	errors   func(output string) []CompilerError
+}
+
+func (d *languageDef) Name() string               { return d.name }
+func (d *languageDef) Patterns() LanguagePatterns { return d.patterns }
+func (d *languageDef) Keywords() LanguageKeywords { return d.keywords }
+
+func (d *languageDef) Matchers() []matcher {
+	if d.matchers == nil {
+		return order
+	}
+	return d.matchers
+}
+
+func (d *languageDef) Parse(p *Parser) (ParseNode, bool) {
+	if d.parse == nil {
+		return ParseNode{}, false
+	}
+	return d.parse(p), true
+}
+
+func (d *languageDef) Analyze(s *SemanticAnalyzer) ([]Symbol, []CompilerError) {
+	if d.analyze != nil {
+		return d.analyze(s)
+	}
+	return s.analyzeIdentifiers(d)
+}
+
+func (d *languageDef) RegisterDeclarations(s *SemanticAnalyzer, declared map[string]int, used map[string][]int, syms *[]Symbol) declarationIndex {
+	if d.register != nil {
+		return d.register(s, declared, used, syms)
+	}
+	if d.declares == nil {
+		return genericDeclarations
+	}
+	return d.declares
+}
+
+func (d *languageDef) Execute(timeout time.Duration, limits processLimits, code string, input ProgramInput) ExecutionResult {
+	if d.execute == nil {
+		return ExecutionResult{Output: "Real executor no soporta " + d.name, Ok: false}
+	}
+	return d.execute(timeout, limits, code, input)
+}
+
+func (d *languageDef) CompilerErrors(output string) []CompilerError {
+	if d.errors == nil {
+		return nil
+	}
+	return d.errors(output)
+}
+
+// keywordBefore indica si el token anterior a tokens[i] es una palabra clave
+// que contiene alguna de words
+func keywordBefore(tokens []Token, i int, words ...string) bool {
+	prev := tokens[i-1]
+	if prev.Type != KEYWORD {
+		return false
+	}
+	for _, w := range words {
+		if strings.Contains(prev.Lexeme, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// Lenguaje genérico: tipos y palabras de declaración de la familia de C
+var genericDeclarations = tokenDeclarations(func(tokens []Token, i int) bool {
+	return keywordBefore(tokens, i, "int", "var", "let", "const", "string", "float", "double")
+})
+
+var genericLanguage = &languageDef{
+	name:     "unknown",
+	declares: genericDeclarations,
+	keywords: LanguageKeywords{
+		Builtins: map[string]bool{},
+		Reserved: map[string]bool{
+			"if": true, "else": true, "while": true, "for": true, "return": true,
+			"int": true, "float": true, "double": true, "char": true, "void": true,
+			"class": true, "public": true, "private": true, "protected": true,
+		},
+	},
+}
+
+// ───────────────────────── C++, JavaScript y Python ──────────────────────
+
+var jsBuiltins = map[string]bool{
+	"console": true, "alert": true, "prompt": true, "confirm": true,
+	"parseInt": true, "parseFloat": true, "isNaN": true, "String": true,
+	"Number": true, "Boolean": true, "Array": true, "Object": true,
+	"Math": true, "Date": true, "JSON": true, "setTimeout": true,
+	"setInterval": true, "clearTimeout": true, "clearInterval": true,
+	"require": true, "module": true, "exports": true,
+}
+
+var jsReserved = map[string]bool{
+	"var": true, "let": true, "const": true, "function": true, "return": true,
+	"if": true, "else": true, "for": true, "while": true, "do": true,
+	"switch": true, "case": true, "break": true, "continue": true,
+	"try": true, "catch": true, "finally": true, "throw": true,
+	"new": true, "this": true, "typeof": true, "instanceof": true,
+	"in": true, "of": true, "class": true, "extends": true, "super": true,
+	"static": true, "import": true, "export": true, "from": true, "as": true,
+	"async": true, "await": true, "true": true, "false": true, "null": true,
+	"undefined": true,
+}
+
+func init() {
+	RegisterLanguage(&languageDef{
+		name:     "cpp",
+		patterns: LanguageSpecificPatterns["cpp"],
+		matchers: cppOrder,
+		parse:    (*Parser).parseCProgram,
+		register: registerCPPMacros,
+		keywords: LanguageKeywords{
+			Builtins: wordSet("cout cin endl std printf scanf malloc free strlen strcpy strcmp\n" + cppLibraryNames),
+			Reserved: map[string]bool{
+				"if": true, "else": true, "while": true, "for": true, "return": true,
+				"int": true, "float": true, "double": true, "char": true, "void": true,
+				"class": true, "public": true, "private": true, "protected": true,
+				"namespace": true, "using": true, "include": true, "define": true,
+				"bool": true, "true": true, "false": true, "const": true, "static": true,
+				"virtual": true, "override": true, "template": true, "typename": true,
+			},
+		},
+		execute: compileAndRunCPP,
+		errors:  parseCPPErrors,
+	})
+
+	RegisterLanguage(&languageDef{
+		name:     "javascript",
+		patterns: LanguageSpecificPatterns["javascript"],
+		matchers: jsOrder,
+		parse:    (*Parser).parseCProgram,
+		register: func(s *SemanticAnalyzer, declared map[string]int, used map[string][]int, syms *[]Symbol) declarationIndex {
+			return s.registerJSDeclarations(declared, used, syms)
+		},
+		keywords: LanguageKeywords{Builtins: jsNodeBuiltins(), Reserved: jsReserved},
+		execute: func(timeout time.Duration, limits processLimits, code string, input ProgramInput) ExecutionResult {
+			return runTemp(timeout, limits, input, ".js", code, "node")
+		},
+		errors: parseJavaScriptErrors,
+	})
+
+	RegisterLanguage(&languageDef{
+		name:     "python",
+		patterns: LanguageSpecificPatterns["python"],
+		matchers: pythonOrder,
+		parse:    (*Parser).parsePythonProgram,
+		register: func(s *SemanticAnalyzer, declared map[string]int, _ map[string][]int, syms *[]Symbol) declarationIndex {
+			return s.registerPythonDeclarations(declared, syms)
+		},
+		keywords: LanguageKeywords{
+			Builtins: map[string]bool{
+				"print": true, "len": true, "str": true, "int": true, "float": true,
+				"range": true, "input": true, "type": true, "isinstance": true,
+				"list": true, "dict": true, "tuple": true, "set": true,
+				"min": true, "max": true, "sum": true, "abs": true,
+				"enumerate": true, "zip": true, "map": true, "filter": true,
+				"sorted": true, "reversed": true, "round": true, "bool": true,
+				"open": true, "any": true, "all": true, "iter": true, "next": true,
+				"chr": true, "ord": true, "repr": true, "format": true, "pow": true,
+				"divmod": true, "hash": true, "id": true, "hex": true, "bin": true,
+				"oct": true, "frozenset": true, "bytes": true, "bytearray": true,
+				"complex": true, "slice": true, "object": true, "super": true,
+				"property": true, "staticmethod": true, "classmethod": true,
+				"hasattr": true, "getattr": true, "setattr": true, "delattr": true,
+				"callable": true, "vars": true, "dir": true, "globals": true,
+				"locals": true, "issubclass": true, "exit": true, "quit": true,
+				"__name__": true, "__file__": true, "NotImplemented": true,
+				"Exception": true, "BaseException": true, "ValueError": true,
+				"TypeError": true, "KeyError": true, "IndexError": true,
+				"ZeroDivisionError": true, "AttributeError": true, "NameError": true,
+				"RuntimeError": true, "StopIteration": true, "AssertionError": true,
+				"NotImplementedError": true, "ImportError": true, "OSError": true,
+				"FileNotFoundError": true, "IOError": true, "OverflowError": true,
+				"RecursionError": true, "ArithmeticError": true, "LookupError": true,
+				"KeyboardInterrupt": true, "EOFError": true,
+			},
+			Reserved: map[string]bool{
+				"and": true, "as": true, "assert": true, "async": true, "await": true,
+				"break": true, "class": true, "continue": true, "def": true, "del": true,
+				"elif": true, "else": true, "except": true, "False": true, "finally": true,
+				"for": true, "from": true, "global": true, "if": true, "import": true,
+				"in": true, "is": true, "lambda": true, "nonlocal": true, "None": true,
+				"not": true, "or": true, "pass": true, "raise": true, "return": true,
+				"True": true, "try": true, "while": true, "with": true, "yield": true,
+			},
+		},
+		execute: func(timeout time.Duration, limits processLimits, code string, input ProgramInput) ExecutionResult {
+			return runTemp(timeout, limits, input, ".py", code, "python3")
+		},
+		errors: parsePythonErrors,
+	})
+}
+
+// cppLibraryNames son los nombres de la biblioteca estándar que un programa
+// usa sin declarar, con o sin std::
+const cppLibraryNames = `string vector map set multimap multiset unordered_map unordered_set
+pair tuple array list deque queue stack priority_queue size_t getline
+to_string stoi stol stod sort swap max min reverse find count
+accumulate make_pair abs sqrt pow floor ceil round fabs fixed
+setprecision setw cerr ios ifstream ofstream stringstream
+istringstream ostringstream rand srand time exit memset memcpy atoi
+strcat isdigit isalpha toupper tolower puts putchar getchar NULL
+INT_MAX INT_MIN EXIT_SUCCESS EXIT_FAILURE numeric_limits move
+unique_ptr shared_ptr make_unique make_shared function optional begin
+end`
+
+// cppDeclarations reconoce el nombre que sigue a un tipo: una palabra clave
+// (int x, struct S), otro nombre (S s, std::string t), una plantilla
+// (vector<int> v) o un puntero o referencia a alguno de ellos (const S& s,
+// int *p). Después de * y & solo cuentan los tipos conocidos, los de types
+// y los de la biblioteca, para no confundir a * b con una declaración
+func cppDeclarations(types map[string]bool) tokenDeclarations {
+	isType := func(tokens []Token, j int) bool {
+		switch tk := tokens[j]; {
+		case tk.Type == IDENTIFIER:
+			return types[tk.Lexeme]
+		case tk.Type == KEYWORD:
+			return isCppTypeKeyword(tk.Lexeme)
+		}
+		return cppTemplateClose(tokens, j)
+	}
+	return func(tokens []Token, i int) bool {
+		prev := tokens[i-1]
+		switch prev.Lexeme {
+		case "struct", "class", "enum", "union", "typename":
+			return true
+		}
+		// const T& v, std::string s: el nombre de un tipo no es una variable
+		if types[tokens[i].Lexeme] {
+			return false
+		}
+		switch {
+		// Las palabras de tipo de parser.go
+		case prev.Type == KEYWORD:
+			return isCppTypeKeyword(prev.Lexeme)
+		case prev.Lexeme == ",":
+			return cppDeclaratorList(tokens, i-1, types)
+		case prev.Type == IDENTIFIER:
+			return true
+		case prev.Lexeme == ">" || prev.Lexeme == ">>":
+			return cppTemplateClose(tokens, i-1)
+		case prev.Lexeme == "*" || prev.Lexeme == "&" || prev.Lexeme == "&&":
+			return i >= 2 && isType(tokens, i-2)
+		}
+		return false
+	}
+}
+
+// cppDeclaratorList indica si la ',' de tokens[comma] separa los nombres de
+// una declaración: int a = 3, b = 4; o for (int i = 0, j = n; ...). Se
+// retrocede hasta el comienzo de la sentencia, saltando los inicializadores
+func cppDeclaratorList(tokens []Token, comma int, types map[string]bool) bool {
+	depth, start := 0, 0
+	for j := comma - 1; j >= 0 && start == 0; j-- {
+		switch tokens[j].Lexeme {
+		case ")", "]":
+			depth++
+		case "[":
+			depth--
+		case "(":
+			if depth--; depth >= 0 {
+				continue
+			}
+			// Argumentos o parámetros: solo el paréntesis de un for abre
+			// una sentencia
+			if j == 0 || tokens[j-1].Lexeme != "for" {
+				return false
+			}
+			start = j + 1
+		case "{", "}", ";":
+			if depth == 0 {
+				start = j + 1
+			}
+		}
+	}
+	first := tokens[start]
+	return first.Type == KEYWORD && isCppTypeKeyword(first.Lexeme) ||
+		first.Type == IDENTIFIER && types[first.Lexeme]
+}
+
+// cppTemplateClose indica si tokens[i], > o >>, cierra los argumentos de una
+// plantilla: vector<int>, map<string, vector<int>>
+func cppTemplateClose(tokens []Token, i int) bool {
+	depth := 0
+	for j := i; j >= 0; j-- {
+		switch tk := tokens[j]; tk.Lexeme {
+		case ">":
+			depth++
+		case ">>":
+			depth += 2
+		case "<":
+			if depth--; depth == 0 {
+				return j > 0 && tokens[j-1].Type == IDENTIFIER
+			}
+		case ",", "::", "*", "&":
+		default:
+			if tk.Type != IDENTIFIER && tk.Type != KEYWORD && tk.Type != NUMBER {
+				return false
+			}
+		}
+	}
+	return false
+}
+
+// cppTypeNames son los tipos que declara el programa (struct, class, enum,
+// union, parámetros de plantilla, using T = y typedef) y los de la
+// biblioteca
+func cppTypeNames(tokens []Token) map[string]bool {
+	types := wordSet(cppLibraryNames)
+	for i, tk := range tokens {
+		if i+1 >= len(tokens) || tokens[i+1].Type != IDENTIFIER {
+			continue
+		}
+		switch tk.Lexeme {
+		case "struct", "class", "enum", "union", "typename", "using":
+			types[tokens[i+1].Lexeme] = true
+		case "typedef":
+			for j := i + 1; j+1 < len(tokens) && tokens[j].Lexeme != ";"; j++ {
+				if tokens[j+1].Lexeme == ";" && tokens[j].Type == IDENTIFIER {
+					types[tokens[j].Lexeme] = true
+				}
+			}
+		}
+	}
+	return types
+}
+
+// registerCPPMacros registra las macros y las cabeceras incluidas como
+// símbolos y expande las macros de objeto antes de buscar declaraciones y
+// usos. Los usos de las macros de objeto desaparecen al expandirlas, por eso
+// se anotan antes
+func registerCPPMacros(s *SemanticAnalyzer, declared map[string]int, used map[string][]int, syms *[]Symbol) declarationIndex {
+	macros := CollectMacros(s.tokens)
+	for _, m := range macros {
+		declared[m.Name] = m.Pos
+		*syms = append(*syms, Symbol{Name: m.Name, Kind: "macro", Pos: m.Pos})
+	}
+	for _, inc := range cppIncludes(s.tokens) {
+		*syms = append(*syms, Symbol{Name: inc.module, Kind: "include", Pos: inc.pos})
+	}
+	sort.Slice(*syms, func(i, j int) bool { return (*syms)[i].Pos < (*syms)[j].Pos })
+	for _, tk := range s.tokens {
+		if _, ok := macros[tk.Lexeme]; ok && tk.Type == IDENTIFIER {
+			used[tk.Lexeme] = append(used[tk.Lexeme], tk.Start)
+		}
+	}
+	s.tokens = ExpandObjectMacros(s.tokens, macros)
+	return cppDeclarations(cppTypeNames(s.tokens))
+}
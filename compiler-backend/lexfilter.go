@@ -0,0 +1,188 @@
+package main
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ───────────────────────── Patrones del lexer ────────────────────────────
+//
+// El lexer prueba en cada posición los reconocedores de su lenguaje en
+// orden, y casi todos fallan: en un identificador ya fallaron el de
+// espacios, el de comentarios, el de cadenas y el de números. Correr la
+// expresión regular solo para descubrirlo es lo que más cuesta al tokenizar
+// un archivo grande. De cada patrón se calcula una sola vez con qué bytes
+// puede empezar una coincidencia, para descartar el resto sin ejecutarlo, y
+// cuánto puede medir, para no pasarle a la expresión todo el resto del
+// código cuando la coincidencia es corta (una palabra clave, un operador):
+// el costo de una búsqueda crece con el largo del texto que recibe.
+
+// lexPattern es lo que matchHere sabe de un patrón
+type lexPattern struct {
+	start [256]bool
+	// Bytes que puede medir una coincidencia; -1 si no tiene límite
+	maxLen int
+}
+
+var lexPatterns sync.Map // *regexp.Regexp → *lexPattern
+
+// lexPatternOf devuelve lo que se sabe de rx, calculándolo la primera vez
+func lexPatternOf(rx *regexp.Regexp) *lexPattern {
+	if p, ok := lexPatterns.Load(rx); ok {
+		return p.(*lexPattern)
+	}
+	p := &lexPattern{maxLen: -1}
+	re, err := syntax.Parse(rx.String(), syntax.Perl)
+	if err == nil {
+		re = re.Simplify()
+		p.maxLen = maxMatchLen(re)
+	}
+	if err != nil || firstBytes(re, &p.start) {
+		// Coincide con la cadena vacía (o no se pudo analizar): cualquier
+		// byte puede iniciar una coincidencia
+		for i := range p.start {
+			p.start[i] = true
+		}
+	}
+	actual, _ := lexPatterns.LoadOrStore(rx, p)
+	return actual.(*lexPattern)
+}
+
+// window devuelve el texto que necesita el patrón para reconocer una
+// coincidencia en src[pos:]: hasta maxLen bytes más un carácter, que leen
+// \b y $ para decidir
+func (p *lexPattern) window(src string, pos int) string {
+	if p.maxLen >= 0 && pos+p.maxLen+utf8.UTFMax < len(src) {
+		return src[pos : pos+p.maxLen+utf8.UTFMax]
+	}
+	return src[pos:]
+}
+
+// firstBytes marca en t los bytes con los que puede empezar re y devuelve
+// si re coincide con la cadena vacía
+func firstBytes(re *syntax.Regexp, t *[256]bool) bool {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return false
+	case syntax.OpLiteral:
+		if len(re.Rune) == 0 {
+			return true
+		}
+		markRune(t, re.Rune[0], re.Flags&syntax.FoldCase != 0)
+		return false
+	case syntax.OpCharClass:
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			markRange(t, re.Rune[i], re.Rune[i+1])
+		}
+		return false
+	case syntax.OpAnyCharNotNL:
+		for i := range t {
+			t[i] = i != '\n'
+		}
+		return false
+	case syntax.OpAnyChar:
+		for i := range t {
+			t[i] = true
+		}
+		return false
+	case syntax.OpCapture:
+		return firstBytes(re.Sub[0], t)
+	case syntax.OpStar, syntax.OpQuest:
+		firstBytes(re.Sub[0], t)
+		return true
+	case syntax.OpPlus:
+		return firstBytes(re.Sub[0], t)
+	case syntax.OpRepeat:
+		return firstBytes(re.Sub[0], t) || re.Min == 0
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if !firstBytes(sub, t) {
+				return false
+			}
+		}
+		return true
+	case syntax.OpAlternate:
+		nullable := false
+		for _, sub := range re.Sub {
+			if firstBytes(sub, t) {
+				nullable = true
+			}
+		}
+		return nullable
+	}
+	// OpEmptyMatch, ^, $, \b y \B no consumen caracteres
+	return true
+}
+
+// maxMatchLen devuelve cuántos bytes puede medir una coincidencia de re, o
+// -1 si no tiene límite
+func maxMatchLen(re *syntax.Regexp) int {
+	switch re.Op {
+	case syntax.OpLiteral:
+		if re.Flags&syntax.FoldCase != 0 {
+			// Otra forma de la letra puede ocupar más bytes (K y el signo Kelvin)
+			return len(re.Rune) * utf8.UTFMax
+		}
+		return len(string(re.Rune))
+	case syntax.OpCharClass:
+		if len(re.Rune) == 0 {
+			return 0
+		}
+		return utf8.RuneLen(re.Rune[len(re.Rune)-1])
+	case syntax.OpAnyCharNotNL, syntax.OpAnyChar:
+		return utf8.UTFMax
+	case syntax.OpCapture, syntax.OpQuest:
+		return maxMatchLen(re.Sub[0])
+	case syntax.OpStar, syntax.OpPlus:
+		return -1
+	case syntax.OpRepeat:
+		n := maxMatchLen(re.Sub[0])
+		if n < 0 || re.Max < 0 {
+			return -1
+		}
+		return n * re.Max
+	case syntax.OpConcat, syntax.OpAlternate:
+		total := 0
+		for _, sub := range re.Sub {
+			n := maxMatchLen(sub)
+			if n < 0 {
+				return -1
+			}
+			if re.Op == syntax.OpConcat {
+				total += n
+			} else if n > total {
+				total = n
+			}
+		}
+		return total
+	}
+	// Las aserciones y la cadena vacía no consumen caracteres
+	return 0
+}
+
+// markRune marca el primer byte de r (y de sus otras mayúsculas/minúsculas
+// si fold)
+func markRune(t *[256]bool, r rune, fold bool) {
+	markRange(t, r, r)
+	if fold {
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			markRange(t, f, f)
+		}
+	}
+}
+
+// markRange marca el primer byte de las runas de lo a hi; las que no son
+// ASCII marcan todos los bytes iniciales de UTF-8
+func markRange(t *[256]bool, lo, hi rune) {
+	for r := lo; r <= hi && r < utf8.RuneSelf; r++ {
+		t[r] = true
+	}
+	if hi >= utf8.RuneSelf {
+		for b := 0xC0; b < 0x100; b++ {
+			t[b] = true
+		}
+	}
+}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// ─────────────────── Límites de los procesos ejecutados ──────────────────
+//
+// El ejecutor local corre cada programa en su propio grupo de procesos, así
+// al vencer el tiempo o superar un límite se termina el programa junto con
+// todos los procesos que haya creado, no solo el padre. La salida se acota a
+// MaxOutputBytes: al superarla se trunca y el programa se detiene. En Linux
+// la memoria y los procesos se limitan con RLIMIT_AS y RLIMIT_NPROC (ver
+// limits_linux.go) o, si se configura un cgroup v2 delegado
+// (CGROUP_PARENT), con memory.max y pids.max de un cgroup propio por
+// ejecución.
+
+// processLimits son los límites aplicados a un proceso; 0 = sin límite
+type processLimits struct {
+	MemoryBytes int64
+	Processes   int
+	OutputBytes int
+	// RLIMIT_AS limita la memoria virtual, no la residente: V8 y el runtime
+	// de Go reservan mucho espacio de direcciones al iniciar, así que para
+	// node, tsc y Go solo se puede limitar la memoria con el cgroup
+	VirtualMemory bool
+	// Pasos del intérprete integrado (ver interp.go)
+	Steps int64
+}
+
+// limitsFor devuelve los límites configurados para un lenguaje
+func limitsFor(lang string) processLimits {
+	return processLimits{
+		MemoryBytes:   int64(GlobalConfig.MaxMemoryMB) << 20,
+		Processes:     GlobalConfig.MaxProcesses,
+		OutputBytes:   GlobalConfig.MaxOutputBytes,
+		VirtualMemory: lang != "javascript" && lang != "typescript" && lang != "go",
+		Steps:         GlobalConfig.MaxInterpreterSteps,
+	}
+}
+
+// processResult es el resultado de runLimited
+type processResult struct {
+	Output         string // stdout y stderr en el orden en que llegaron
+	Stdout         string
+	Stderr         string
+	ExitCode       int // -1 si el proceso no terminó por sí mismo
+	Duration       time.Duration
+	Err            error
+	TimedOut       bool
+	Cancelled      bool // detenido con DELETE /api/v1/executions/{id}
+	Truncated      bool
+	MemoryExceeded bool
+	StepsExceeded  bool // superó los pasos del intérprete integrado
+	// Recursos consumidos según el kernel; 0 si no se pudieron medir. Sin
+	// cgroup la memoria es ru_maxrss, que puede incluir unos MB del
+	// servidor que creó el proceso
+	PeakMemory int64 // bytes de memoria residente
+	UserCPU    time.Duration
+	SystemCPU  time.Duration
+}
+
+// cancelledMessage se agrega a la salida de un programa detenido a pedido
+const cancelledMessage = "\nEjecución detenida a pedido"
+
+// Message devuelve la salida del proceso con la explicación del límite que
+// lo detuvo, si hubo alguno
+func (r processResult) Message(timeout time.Duration, limits processLimits) string {
+	return r.Output + r.limitNote(timeout, limits)
+}
+
+// limitNote explica el límite que detuvo el proceso, o "" si no hubo
+func (r processResult) limitNote(timeout time.Duration, limits processLimits) string {
+	switch {
+	case r.TimedOut:
+		return timeoutMessage(timeout)
+	case r.Cancelled:
+		return cancelledMessage
+	case r.Truncated:
+		return fmt.Sprintf("\nSalida truncada: se superó el límite de %d bytes", limits.OutputBytes)
+	case r.MemoryExceeded:
+		return fmt.Sprintf("\nLímite de memoria excedido (%d MB)", limits.MemoryBytes>>20)
+	case r.StepsExceeded:
+		return fmt.Sprintf("\nLímite de instrucciones excedido (%d pasos)", limits.Steps)
+	}
+	return ""
+}
+
+// compileFailure arma el resultado de una compilación que falló: el
+// programa no llegó a ejecutarse
+func (r processResult) compileFailure(timeout time.Duration, limits processLimits) ExecutionResult {
+	out := r.Message(timeout, limits)
+	return ExecutionResult{Output: out, Transient: r.TimedOut || r.Cancelled, CompileOutput: out, TimedOut: r.TimedOut, CompileError: !r.Cancelled}
+}
+
+// runExecution arma el resultado de ejecutar el programa; compileOutput es
+// lo que imprimió la compilación previa, si la hubo. La explicación del
+// límite va al final de stderr
+func (r processResult) runExecution(compileOutput string, timeout time.Duration, limits processLimits) ExecutionResult {
+	res := ExecutionResult{
+		Output:        r.Message(timeout, limits),
+		Ok:            r.Ok(),
+		Transient:     r.TimedOut || r.Cancelled,
+		TimedOut:      r.TimedOut,
+		CompileOutput: compileOutput,
+		RunStdout:     r.Stdout,
+		RunStderr:     r.Stderr + r.limitNote(timeout, limits),
+		DurationMs:    r.Duration.Milliseconds(),
+		PeakMemory:    r.PeakMemory,
+		UserCPUMs:     r.UserCPU.Milliseconds(),
+		SystemCPUMs:   r.SystemCPU.Milliseconds(),
+	}
+	if r.ExitCode >= 0 {
+		code := r.ExitCode
+		res.ExitCode = &code
+	}
+	return res
+}
+
+// Ok indica si el proceso terminó bien y dentro de los límites
+func (r processResult) Ok() bool {
+	return r.Err == nil && !r.TimedOut && !r.Cancelled && !r.Truncated && !r.MemoryExceeded && !r.StepsExceeded
+}
+
+// outputLimiter acumula stdout y stderr hasta max bytes entre los dos. Al
+// superarlo descarta el resto y llama a onExceed una sola vez. Escribir
+// nunca falla: si el programa recibiera un error de escritura podría
+// ignorarlo y seguir consumiendo CPU hasta el timeout.
+//
+// Cada flujo llega por su propio pipe, así que en la salida combinada se
+// intercalan por líneas completas: un print partido en varias escrituras no
+// queda mezclado con una línea del otro flujo.
+type outputLimiter struct {
+	mu       sync.Mutex
+	buf      bytes.Buffer
+	stdout   outputStream
+	stderr   outputStream
+	total    int
+	max      int
+	exceeded bool
+	onExceed func()
+	// Recibe cada fragmento conservado en cuanto se escribe, con el nombre
+	// de su flujo (ver ProgramInput.Output)
+	onWrite OutputFunc
+}
+
+// outputStream es lo conservado de un flujo y su última línea incompleta,
+// que todavía no pasó a la salida combinada
+type outputStream struct {
+	buf     bytes.Buffer
+	partial []byte
+}
+
+// write agrega p al flujo s y sus líneas completas a la salida combinada
+func (w *outputLimiter) write(p []byte, s *outputStream) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	kept := p
+	if remaining := w.max - w.total; w.max > 0 && len(p) > remaining {
+		kept = p[:remaining]
+		if !w.exceeded {
+			w.exceeded = true
+			if w.onExceed != nil {
+				w.onExceed()
+			}
+		}
+	}
+	w.total += len(kept)
+	if w.onWrite != nil && len(kept) > 0 {
+		stream := "stdout"
+		if s == &w.stderr {
+			stream = "stderr"
+		}
+		w.onWrite(stream, kept)
+	}
+	s.buf.Write(kept)
+	s.partial = append(s.partial, kept...)
+	if i := bytes.LastIndexByte(s.partial, '\n'); i >= 0 {
+		w.buf.Write(s.partial[:i+1])
+		s.partial = append(s.partial[:0], s.partial[i+1:]...)
+	}
+	return len(p), nil
+}
+
+// streamWriter escribe en el outputLimiter como uno de sus flujos
+type streamWriter struct {
+	limiter *outputLimiter
+	stream  *outputStream
+}
+
+func (s streamWriter) Write(p []byte) (int, error) {
+	return s.limiter.write(p, s.stream)
+}
+
+func (w *outputLimiter) stdoutWriter() io.Writer { return streamWriter{w, &w.stdout} }
+func (w *outputLimiter) stderrWriter() io.Writer { return streamWriter{w, &w.stderr} }
+
+// streams devuelve lo conservado de stdout y stderr por separado
+func (w *outputLimiter) streams() (stdout, stderr string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.stdout.buf.String(), w.stderr.buf.String()
+}
+
+// stdoutLen devuelve cuántos bytes de stdout se conservaron
+func (w *outputLimiter) stdoutLen() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.stdout.buf.Len()
+}
+
+func (w *outputLimiter) truncated() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.exceeded
+}
+
+// String devuelve la salida combinada, con las líneas incompletas de cada
+// flujo al final
+func (w *outputLimiter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String() + string(w.stdout.partial) + string(w.stderr.partial)
+}
+
+// Tiempo que se espera a que se cierre la salida después de terminar el
+// proceso; un proceso que escapó del grupo podría mantenerla abierta
+const outputWaitDelay = time.Second
+
+// runLimited ejecuta cmd (creado con exec.CommandContext sobre ctx) con los
+// límites indicados y devuelve su salida, combinada y por flujo
+func runLimited(ctx context.Context, cmd *exec.Cmd, limits processLimits) processResult {
+	return runWatched(ctx, cmd, limits, nil)
+}
+
+// runWatched es runLimited pasando además la salida a onOutput mientras el
+// proceso corre; nil no la transmite
+func runWatched(ctx context.Context, cmd *exec.Cmd, limits processLimits, onOutput OutputFunc) processResult {
+	sandbox := newProcessSandbox(limits)
+	defer sandbox.release()
+
+	// Con un *os.File exec no copia la salida por su cuenta, así Wait vuelve
+	// en cuanto termina el proceso aunque sus hijos sigan con la salida
+	// abierta, y se les puede terminar antes de esperar el resto
+	stdoutR, stdoutW, err := os.Pipe()
+	if err != nil {
+		return processResult{Output: err.Error(), Err: err, ExitCode: -1}
+	}
+	defer stdoutR.Close()
+	stderrR, stderrW, err := os.Pipe()
+	if err != nil {
+		stdoutW.Close()
+		return processResult{Output: err.Error(), Err: err, ExitCode: -1}
+	}
+	defer stderrR.Close()
+	out := &outputLimiter{max: limits.OutputBytes, onWrite: onOutput}
+	out.onExceed = func() { sandbox.kill(cmd) }
+	cmd.Stdout, cmd.Stderr = stdoutW, stderrW
+	sandbox.prepare(cmd)
+
+	start := time.Now()
+	err = cmd.Start()
+	stdoutW.Close()
+	stderrW.Close()
+	if err != nil {
+		return processResult{Output: err.Error(), Err: err, ExitCode: -1}
+	}
+	var copying sync.WaitGroup
+	copying.Add(2)
+	go func() {
+		io.Copy(out.stdoutWriter(), stdoutR)
+		copying.Done()
+	}()
+	go func() {
+		io.Copy(out.stderrWriter(), stderrR)
+		copying.Done()
+	}()
+	copied := make(chan struct{})
+	go func() {
+		copying.Wait()
+		close(copied)
+	}()
+	return waitLimited(ctx, cmd, sandbox, out, copied, start)
+}
+
+// waitLimited espera a cmd, ya iniciado con start, y arma su resultado con
+// lo que out recibió hasta que se cerró copied
+func waitLimited(ctx context.Context, cmd *exec.Cmd, sandbox *processSandbox, out *outputLimiter, copied <-chan struct{}, start time.Time) processResult {
+	err := cmd.Wait()
+	duration := time.Since(start)
+	// Los procesos que el programa dejó en segundo plano también terminan
+	sandbox.kill(cmd)
+	select {
+	case <-copied:
+	case <-time.After(outputWaitDelay):
+	}
+
+	stdout, stderr := out.streams()
+	res := processResult{
+		Output:         out.String(),
+		Stdout:         stdout,
+		Stderr:         stderr,
+		ExitCode:       -1,
+		Duration:       duration,
+		Err:            err,
+		TimedOut:       ctx.Err() == context.DeadlineExceeded,
+		Cancelled:      ctx.Err() == context.Canceled,
+		Truncated:      out.truncated(),
+		MemoryExceeded: sandbox.memoryExceeded(),
+	}
+	if state := cmd.ProcessState; state != nil {
+		res.ExitCode = state.ExitCode()
+		res.PeakMemory = sandbox.peakMemory(state)
+		res.UserCPU, res.SystemCPU = state.UserTime(), state.SystemTime()
+	}
+	return res
+}
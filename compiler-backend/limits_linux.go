@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// processSandbox aplica los límites en Linux: grupo de procesos propio,
+// RLIMIT_AS y RLIMIT_NPROC y, si hay un cgroup v2 delegado, un cgroup por
+// ejecución
+type processSandbox struct {
+	limits processLimits
+	cgroup string   // directorio del cgroup propio; "" si no se usa
+	dir    *os.File // descriptor del cgroup para clonar el proceso dentro
+}
+
+// Solo se avisa una vez si el cgroup configurado no se puede usar
+var cgroupWarning sync.Once
+
+func newProcessSandbox(limits processLimits) *processSandbox {
+	sb := &processSandbox{limits: limits}
+	if GlobalConfig.CgroupParent == "" {
+		return sb
+	}
+	dir, err := os.MkdirTemp(GlobalConfig.CgroupParent, "snippet-")
+	if err == nil {
+		err = sb.configureCgroup(dir)
+		if err != nil {
+			os.Remove(dir)
+		}
+	}
+	if err != nil {
+		cgroupWarning.Do(func() {
+			log.Printf("cgroup %s no disponible, solo se usan rlimits: %v", GlobalConfig.CgroupParent, err)
+		})
+	}
+	return sb
+}
+
+func (sb *processSandbox) configureCgroup(dir string) error {
+	settings := map[string]string{}
+	if sb.limits.MemoryBytes > 0 {
+		settings["memory.max"] = strconv.FormatInt(sb.limits.MemoryBytes, 10)
+		settings["memory.swap.max"] = "0"
+	}
+	if sb.limits.Processes > 0 {
+		settings["pids.max"] = strconv.Itoa(sb.limits.Processes)
+	}
+	for file, value := range settings {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(value), 0644); err != nil {
+			return err
+		}
+	}
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	sb.cgroup, sb.dir = dir, f
+	return nil
+}
+
+// prepare coloca el proceso en su propio grupo (y cgroup), le aplica los
+// rlimits y hace que la cancelación del contexto termine el grupo completo
+func (sb *processSandbox) prepare(cmd *exec.Cmd) {
+	sb.wrapRlimits(cmd)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if sb.dir != nil {
+		cmd.SysProcAttr.UseCgroupFD = true
+		cmd.SysProcAttr.CgroupFD = int(sb.dir.Fd())
+	}
+	cmd.Cancel = func() error {
+		sb.kill(cmd)
+		return nil
+	}
+}
+
+// rlimits devuelve RLIMIT_AS y RLIMIT_NPROC del proceso; 0 no lo limita.
+// Con cgroup memory.max y pids.max ya los limitan, y mejor: memory.max
+// cuenta la memoria residente real y pids.max solo los procesos de esta
+// ejecución, mientras que RLIMIT_NPROC cuenta todos los del usuario
+func (sb *processSandbox) rlimits() (memory int64, processes int) {
+	if sb.cgroup != "" {
+		return 0, 0
+	}
+	if sb.limits.VirtualMemory {
+		memory = sb.limits.MemoryBytes
+	}
+	return memory, sb.limits.Processes
+}
+
+// enforcedProcessLimit es el límite de procesos que realmente se aplica: sin
+// cgroup depende de RLIMIT_NPROC, que el kernel no aplica a root
+func enforcedProcessLimit() int {
+	if GlobalConfig.CgroupParent == "" && os.Geteuid() == 0 {
+		return 0
+	}
+	return GlobalConfig.MaxProcesses
+}
+
+// ───────────────────────── Ayudante de rlimits ──────────────────────────
+//
+// Go no permite fijar rlimits entre el fork y el exec, y aplicarlos con
+// prlimit después de Start deja un momento en que el programa corre sin
+// ellos. Por eso el proceso se crea con el propio servidor como ayudante:
+// /proc/self/exe __rlimits <memoria> <procesos> <ruta> <argv...> fija los
+// límites con setrlimit y recién entonces reemplaza su imagen por la del
+// programa, que así nunca corre sin límites. Si algún límite no se puede
+// aplicar el ayudante termina con rlimitFailure y el programa no corre.
+
+const (
+	rlimitHelper = "__rlimits"
+	// Código de salida del ayudante cuando no pudo aplicar un límite
+	rlimitFailure = 126
+)
+
+// wrapRlimits hace que cmd corra a través del ayudante si hay rlimits
+func (sb *processSandbox) wrapRlimits(cmd *exec.Cmd) {
+	memory, processes := sb.rlimits()
+	if (memory <= 0 && processes <= 0) || cmd.Err != nil {
+		return
+	}
+	args := []string{"/proc/self/exe", rlimitHelper,
+		strconv.FormatInt(memory, 10), strconv.Itoa(processes), cmd.Path}
+	cmd.Args = append(args, cmd.Args...)
+	cmd.Path = "/proc/self/exe"
+}
+
+// El ayudante corre antes que main (y que los tests): no necesita la
+// configuración ni nada más del servidor
+func init() {
+	if len(os.Args) > 5 && os.Args[1] == rlimitHelper {
+		runRlimitHelper(os.Args[2], os.Args[3], os.Args[4], os.Args[5:])
+	}
+}
+
+// runRlimitHelper fija los límites y ejecuta path con argv; no vuelve
+func runRlimitHelper(memory, processes, path string, argv []string) {
+	fail := func(format string, args ...any) {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+		os.Exit(rlimitFailure)
+	}
+	// Se convierte todo antes de limitar la memoria: después el runtime de
+	// Go podría no conseguir más
+	env := os.Environ()
+	mem, err1 := strconv.ParseUint(memory, 10, 64)
+	procs, err2 := strconv.ParseUint(processes, 10, 64)
+	if err1 != nil || err2 != nil {
+		fail("límites inválidos: %s %s", memory, processes)
+	}
+	if procs > 0 {
+		if err := unix.Setrlimit(unix.RLIMIT_NPROC, &unix.Rlimit{Cur: procs, Max: procs}); err != nil {
+			fail("No se pudo limitar el número de procesos a %d: %v", procs, err)
+		}
+	}
+	if mem > 0 {
+		if err := unix.Setrlimit(unix.RLIMIT_AS, &unix.Rlimit{Cur: mem, Max: mem}); err != nil {
+			fail("No se pudo limitar la memoria a %d MB: %v", mem>>20, err)
+		}
+	}
+	err := syscall.Exec(path, argv, env)
+	fail("%s: %v", path, err)
+}
+
+// kill termina todos los procesos del grupo (y del cgroup, que también
+// alcanza a los que hayan creado su propio grupo con setsid)
+func (sb *processSandbox) kill(cmd *exec.Cmd) {
+	if cmd.Process != nil {
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	if sb.cgroup != "" {
+		os.WriteFile(filepath.Join(sb.cgroup, "cgroup.kill"), []byte("1"), 0644)
+	}
+}
+
+// memoryExceeded indica si el kernel terminó algún proceso del cgroup por
+// superar memory.max
+func (sb *processSandbox) memoryExceeded() bool {
+	if sb.cgroup == "" {
+		return false
+	}
+	events, err := os.ReadFile(filepath.Join(sb.cgroup, "memory.events"))
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(events), "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "oom_kill" {
+			return fields[1] != "0"
+		}
+	}
+	return false
+}
+
+// peakMemory devuelve el máximo de memoria residente del proceso en bytes:
+// memory.peak del cgroup, que incluye a todos sus hijos, o si no hay cgroup
+// el ru_maxrss del proceso (en KB en Linux)
+func (sb *processSandbox) peakMemory(state *os.ProcessState) int64 {
+	if sb.cgroup != "" {
+		if peak, err := os.ReadFile(filepath.Join(sb.cgroup, "memory.peak")); err == nil {
+			if v, err := strconv.ParseInt(strings.TrimSpace(string(peak)), 10, 64); err == nil {
+				return v
+			}
+		}
+	}
+	if usage, ok := state.SysUsage().(*syscall.Rusage); ok {
+		return usage.Maxrss << 10
+	}
+	return 0
+}
+
+// release elimina el cgroup de la ejecución; solo se puede borrar cuando ya
+// no contiene procesos
+func (sb *processSandbox) release() {
+	if sb.dir != nil {
+		sb.dir.Close()
+	}
+	if sb.cgroup != "" {
+		os.Remove(sb.cgroup)
+	}
+}
@@ -0,0 +1,33 @@
+//go:build !linux
+
+package main
+
+import (
+	"os"
+	"os/exec"
+)
+
+// processSandbox fuera de Linux solo acota la salida; al cancelar se
+// termina únicamente el proceso padre
+type processSandbox struct{}
+
+func newProcessSandbox(processLimits) *processSandbox { return &processSandbox{} }
+
+func (sb *processSandbox) prepare(*exec.Cmd) {}
+
+// enforcedProcessLimit es 0: fuera de Linux no se limitan los procesos
+func enforcedProcessLimit() int { return 0 }
+
+func (sb *processSandbox) kill(cmd *exec.Cmd) {
+	if cmd.Process != nil {
+		cmd.Process.Kill()
+	}
+}
+
+func (sb *processSandbox) memoryExceeded() bool { return false }
+
+// peakMemory no se informa fuera de Linux: ru_maxrss cambia de unidad según
+// el sistema
+func (sb *processSandbox) peakMemory(*os.ProcessState) int64 { return 0 }
+
+func (sb *processSandbox) release() {}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestOutputLimit comprueba que un programa que no deja de imprimir se
+// detiene al superar MaxOutputBytes, con la salida acotada y la nota
+func TestOutputLimit(t *testing.T) {
+	limits := processLimits{OutputBytes: 4096}
+	start := time.Now()
+	result := runTemp(time.Minute, limits, ProgramInput{}, ".sh", "while :; do echo 0123456789; done\n", "sh")
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("terminó en %v", elapsed)
+	}
+	if result.Ok || !strings.Contains(result.Output, "Salida truncada: se superó el límite de 4096 bytes") {
+		t.Fatalf("salida sin la nota de truncada: %q", result.Output[max(0, len(result.Output)-200):])
+	}
+	if len(result.RunStdout) > limits.OutputBytes {
+		t.Errorf("stdout de %d bytes, límite %d", len(result.RunStdout), limits.OutputBytes)
+	}
+}
+
+// TestProcessGroupKilled comprueba que al terminar el programa, por sí
+// mismo o por el límite de tiempo, también terminan los procesos que dejó
+// en segundo plano
+func TestProcessGroupKilled(t *testing.T) {
+	cases := []struct {
+		name     string
+		timeout  time.Duration
+		script   string
+		timedOut bool
+	}{
+		{"termina_solo", time.Minute, "exit 0\n", false},
+		{"limite_de_tiempo", 300 * time.Millisecond, "sleep 30\n", true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mark := filepath.Join(t.TempDir(), "marca")
+			script := "(sleep 1; echo tarde > " + mark + ") &\n" + c.script
+			result := runTemp(c.timeout, processLimits{}, ProgramInput{}, ".sh", script, "sh")
+			if result.TimedOut != c.timedOut {
+				t.Fatalf("timedOut = %v: %s", result.TimedOut, result.Output)
+			}
+			time.Sleep(1500 * time.Millisecond)
+			if _, err := os.Stat(mark); err == nil {
+				t.Error("el proceso en segundo plano siguió ejecutándose")
+			}
+		})
+	}
+}
+
+// TestMemoryLimit comprueba que sin cgroup RLIMIT_AS impide reservar más
+// memoria que MaxMemoryMB
+func TestMemoryLimit(t *testing.T) {
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 no está instalado")
+	}
+	saved := GlobalConfig.CgroupParent
+	GlobalConfig.CgroupParent = ""
+	defer func() { GlobalConfig.CgroupParent = saved }()
+
+	limits := processLimits{MemoryBytes: 128 << 20, VirtualMemory: true}
+	cases := []struct {
+		name string
+		size string
+		ok   bool
+	}{
+		{"dentro_del_limite", "16 << 20", true},
+		{"fuera_del_limite", "512 << 20", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cmd := exec.CommandContext(context.Background(), "python3", "-c", "x = bytearray("+c.size+"); print(len(x))")
+			result := runLimited(context.Background(), cmd, limits)
+			if result.Ok() != c.ok {
+				t.Fatalf("ok = %v: %s", result.Ok(), result.Output)
+			}
+			if !c.ok && !strings.Contains(result.Output, "MemoryError") {
+				t.Errorf("salida sin MemoryError: %s", result.Output)
+			}
+		})
+	}
+}
@@ -0,0 +1,384 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ────────────────────────── Errores frecuentes ──────────────────────────
+//
+// Reglas pedagógicas sobre errores típicos de quien empieza a programar:
+// código que compila pero casi seguro no hace lo que se quiso. Cada regla
+// tiene su código LNT, los lenguajes en los que aplica y los tipos de nodo
+// que mira; el linter recorre el árbol una vez y le pasa a cada regla los
+// nodos que le interesan. Una regla nueva solo se agrega a lintRules.
+//
+//	LNT001  if (x = 5) en lugar de if (x == 5)            C++, JavaScript
+//	LNT002  x is 5 en lugar de x == 5, x == None          Python
+//	LNT003  un case que sigue en el siguiente sin break   C++, JavaScript
+//	LNT004  cadenas de C comparadas con ==                C++
+
+type lintRule struct {
+	code      string
+	languages []string
+	kinds     []string
+	check     func(l *linter, n ParseNode)
+}
+
+var lintRules = []lintRule{
+	{CodeAssignmentInCondition, []string{"cpp", "javascript"}, []string{"If", "While", "DoWhile", "For"}, checkAssignmentInCondition},
+	{CodeIdentityComparison, []string{"python"}, []string{"BinaryExpr"}, checkIdentityComparison},
+	{CodeSwitchFallthrough, []string{"cpp", "javascript"}, []string{"Switch"}, checkSwitchFallthrough},
+	{CodeCStringComparison, []string{"cpp"}, []string{"BinaryExpr"}, checkCStringComparison},
+}
+
+type linter struct {
+	language string
+	tokens   []Token
+	rules    map[string][]lintRule
+	// Variables de C++ declaradas como char* o char[] en el ámbito actual
+	cstrings map[string]bool
+	errors   []CompilerError
+}
+
+func NewLinter(lang string, tokens []Token) *linter {
+	l := &linter{language: lang, tokens: tokens, rules: map[string][]lintRule{}, cstrings: map[string]bool{}}
+	for _, rule := range lintRules {
+		if !slices.Contains(rule.languages, lang) {
+			continue
+		}
+		for _, kind := range rule.kinds {
+			l.rules[kind] = append(l.rules[kind], rule)
+		}
+	}
+	return l
+}
+
+// Analyze recorre el árbol y devuelve las advertencias de las reglas
+func (l *linter) Analyze(tree []ParseNode) []CompilerError {
+	if len(l.rules) == 0 {
+		return nil
+	}
+	for _, root := range tree {
+		l.walk(root)
+	}
+	return l.errors
+}
+
+func (l *linter) walk(n ParseNode) {
+	for _, rule := range l.rules[n.Kind] {
+		rule.check(l, n)
+	}
+	switch n.Kind {
+	case "FunctionDecl", "Block":
+		// Las declaraciones de un bloque no salen de él
+		saved := l.cstrings
+		l.cstrings = make(map[string]bool, len(saved))
+		for name, ok := range saved {
+			l.cstrings[name] = ok
+		}
+		defer func() { l.cstrings = saved }()
+	case "VarDecl", "Param":
+		l.cstrings[n.Label] = isCStringDecl(n)
+	}
+	for _, c := range n.Children {
+		l.walk(c)
+	}
+}
+
+func (l *linter) report(pos int, code, hint, format string, args ...interface{}) {
+	l.errors = append(l.errors, CompilerError{
+		Message:  "Posible error: " + fmt.Sprintf(format, args...),
+		Severity: "warning",
+		Type:     "semantico",
+		Pos:      pos,
+		Code:     code,
+		Hint:     hint,
+	})
+}
+
+// tokenAt devuelve el índice del primer token significativo que empieza en
+// pos o después, o -1
+func (l *linter) tokenAt(pos int) int {
+	for i, tk := range l.tokens {
+		if tk.Start >= pos && tk.Type != WHITESPACE && tk.Type != COMMENT {
+			return i
+		}
+	}
+	return -1
+}
+
+// operator devuelve la posición del operador op entre los operandos left y
+// right de un nodo binario
+func (l *linter) operator(left, right ParseNode, op string) int {
+	for _, tk := range l.tokens {
+		if tk.Start >= left.End && tk.Start < right.Pos && tk.Lexeme == op {
+			return tk.Start
+		}
+	}
+	return left.End
+}
+
+// text reconstruye el código de n a partir de sus tokens
+func (l *linter) text(n ParseNode) string {
+	var parts []string
+	for _, tk := range l.tokens {
+		if tk.Start >= n.Pos && tk.End <= n.End && tk.Type != WHITESPACE && tk.Type != COMMENT {
+			parts = append(parts, tk.Lexeme)
+		}
+	}
+	if len(parts) == 0 {
+		return n.Label
+	}
+	text := strings.Join(parts, " ")
+	for _, p := range []string{"( ", "[ ", " )", " ]", " .", ". ", " ,", " ("} {
+		text = strings.ReplaceAll(text, p, strings.TrimSpace(p))
+	}
+	return text
+}
+
+// ───── LNT001: asignación en una condición ─────
+
+func checkAssignmentInCondition(l *linter, n ParseNode) {
+	cond := childOfKind(n, "Condition")
+	if cond == nil && n.Kind == "For" {
+		if header := childOfKind(n, "ForHeader"); header != nil {
+			cond = childOfKind(*header, "Condition")
+		}
+	}
+	if cond == nil || len(cond.Children) != 1 {
+		return
+	}
+	assign := cond.Children[0]
+	if assign.Kind != "Assign" || assign.Label != "=" || len(assign.Children) != 2 {
+		return
+	}
+	// if ((x = f())) es la forma habitual de indicar que es a propósito
+	if i := l.prevSignificant(l.tokenAt(assign.Pos)); i >= 0 && l.tokens[i].Lexeme == "(" {
+		if j := l.prevSignificant(i); j >= 0 && l.tokens[j].Lexeme == "(" {
+			return
+		}
+	}
+	keyword := n.Label
+	if n.Kind == "DoWhile" {
+		keyword = "while"
+	}
+	l.report(l.operator(assign.Children[0], assign.Children[1], "="), CodeAssignmentInCondition, "replace:==",
+		"Asignación '=' en la condición del %s: se le asigna un valor a '%s' en lugar de compararlo. Use '==' para comparar, o paréntesis dobles si la asignación es intencional",
+		keyword, l.text(assign.Children[0]))
+}
+
+// prevSignificant devuelve el índice del token significativo anterior a i,
+// o -1
+func (l *linter) prevSignificant(i int) int {
+	for j := i - 1; j >= 0; j-- {
+		if tk := l.tokens[j]; tk.Type != WHITESPACE && tk.Type != COMMENT {
+			return j
+		}
+	}
+	return -1
+}
+
+// ───── LNT002: is y == en Python ─────
+
+func checkIdentityComparison(l *linter, n ParseNode) {
+	if len(n.Children) != 2 {
+		return
+	}
+	left, right := n.Children[0], n.Children[1]
+	switch n.Label {
+	case "is", "is not":
+		// Con un número o una cadena el resultado depende de si el
+		// intérprete reutiliza el objeto: 1000 is 1000 puede ser False
+		lit := right
+		if isValueLiteral(left) {
+			lit = left
+		}
+		if !isValueLiteral(lit) {
+			return
+		}
+		replacement := map[string]string{"is": "==", "is not": "!="}[n.Label]
+		hint := ""
+		if n.Label == "is" {
+			hint = "replace:=="
+		}
+		l.report(l.operator(left, right, "is"), CodeIdentityComparison, hint,
+			"'%s' compara si son el mismo objeto, no si tienen el mismo valor: con el literal %s use '%s'",
+			n.Label, lit.Label, replacement)
+	case "==", "!=":
+		if !isLiteral(left, "None") && !isLiteral(right, "None") {
+			return
+		}
+		replacement := map[string]string{"==": "is", "!=": "is not"}[n.Label]
+		l.report(l.operator(left, right, n.Label), CodeIdentityComparison, "replace:"+replacement,
+			"Compare con None usando '%s' en lugar de '%s': None es un único objeto y '%s' puede redefinirse con __eq__",
+			replacement, n.Label, n.Label)
+	}
+}
+
+func isLiteral(n ParseNode, label string) bool {
+	return n.Kind == "Literal" && n.Label == label
+}
+
+// isValueLiteral indica si n es un número o una cadena: literales cuya
+// identidad no está garantizada, a diferencia de None, True y False
+func isValueLiteral(n ParseNode) bool {
+	if n.Kind != "Literal" {
+		return false
+	}
+	switch n.Label {
+	case "None", "True", "False", "...":
+		return false
+	}
+	return true
+}
+
+// ───── LNT003: case sin break ─────
+
+// Comentario que indica que el fallthrough es intencional, como en ESLint
+// y en -Wimplicit-fallthrough de GCC
+var fallthroughComment = regexp.MustCompile(`(?i)fall(?:s|\s*-?\s*)?\s*through`)
+
+func checkSwitchFallthrough(l *linter, n ParseNode) {
+	body := childOfKind(n, "Block")
+	if body == nil {
+		return
+	}
+	var clauses []ParseNode
+	for _, c := range body.Children {
+		if c.Kind == "Case" {
+			clauses = append(clauses, c)
+		}
+	}
+	for i := 0; i+1 < len(clauses); i++ {
+		stmts := caseStatements(clauses[i])
+		// case 1: case 2: comparten el mismo código
+		if len(stmts) == 0 || l.terminates(stmts[len(stmts)-1]) {
+			continue
+		}
+		next := clauses[i+1]
+		if l.markedFallthrough(clauses[i].Pos, next.Pos) {
+			continue
+		}
+		label := "default"
+		if next.Label == "case" && len(next.Children) > 0 {
+			label = "case " + l.text(next.Children[0])
+		}
+		l.report(next.Pos, CodeSwitchFallthrough, "insert:break;",
+			"El caso anterior no termina con 'break' y sigue ejecutando el código de '%s'. Agregue 'break;' al final del caso anterior, o un comentario // fallthrough si es intencional",
+			label)
+	}
+}
+
+// caseStatements devuelve las sentencias de un case, sin su valor
+func caseStatements(clause ParseNode) []ParseNode {
+	if clause.Label == "case" && len(clause.Children) > 0 {
+		return clause.Children[1:]
+	}
+	return clause.Children
+}
+
+// terminates indica si la sentencia n nunca continúa en la siguiente
+func (l *linter) terminates(n ParseNode) bool {
+	switch n.Kind {
+	case "Break", "Return", "Throw", "Continue", "Goto":
+		return true
+	case "Block":
+		return len(n.Children) > 0 && l.terminates(n.Children[len(n.Children)-1])
+	case "If":
+		// Solo si ambas ramas terminan
+		var branches []ParseNode
+		for _, c := range n.Children {
+			if c.Kind != "Condition" {
+				branches = append(branches, c)
+			}
+		}
+		if len(branches) < 2 {
+			return false
+		}
+		for _, b := range branches {
+			if b.Kind == "Else" && len(b.Children) > 0 {
+				b = b.Children[len(b.Children)-1]
+			}
+			if !l.terminates(b) {
+				return false
+			}
+		}
+		return true
+	case "ExprStmt":
+		// exit(1), abort() y process.exit() terminan el programa
+		if len(n.Children) == 1 && n.Children[0].Kind == "Call" && len(n.Children[0].Children) > 0 {
+			switch l.text(n.Children[0].Children[0]) {
+			case "exit", "abort", "std::exit", "std::abort", "process.exit":
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// markedFallthrough indica si entre from y to hay un [[fallthrough]] o un
+// comentario // fallthrough
+func (l *linter) markedFallthrough(from, to int) bool {
+	for _, tk := range l.tokens {
+		if tk.Start < from || tk.Start >= to {
+			continue
+		}
+		if tk.Lexeme == "fallthrough" || tk.Type == COMMENT && fallthroughComment.MatchString(tk.Lexeme) {
+			return true
+		}
+	}
+	return false
+}
+
+// ───── LNT004: cadenas de C comparadas con == ─────
+
+func checkCStringComparison(l *linter, n ParseNode) {
+	switch n.Label {
+	case "==", "!=", "<", ">", "<=", ">=":
+	default:
+		return
+	}
+	if len(n.Children) != 2 || !l.isCString(n.Children[0]) || !l.isCString(n.Children[1]) {
+		return
+	}
+	left, right := l.text(n.Children[0]), l.text(n.Children[1])
+	l.report(l.operator(n.Children[0], n.Children[1], n.Label), CodeCStringComparison, "",
+		"'%s' entre cadenas de C compara sus direcciones de memoria, no su texto. Use strcmp(%s, %s) %s 0 o guarde las cadenas en std::string",
+		n.Label, left, right, n.Label)
+}
+
+// isCString indica si n es un literal de cadena o una variable char* o
+// char[]; con std::string == compara el texto y no se reporta
+func (l *linter) isCString(n ParseNode) bool {
+	switch n.Kind {
+	case "Literal":
+		return strings.HasPrefix(n.Label, `"`)
+	case "Identifier":
+		return l.cstrings[n.Label]
+	}
+	return false
+}
+
+// isCStringDecl indica si la declaración n es de tipo char*, const char*
+// o un arreglo de char
+func isCStringDecl(n ParseNode) bool {
+	typ := childOfKind(n, "Type")
+	if typ == nil {
+		return false
+	}
+	t := strings.NewReplacer("const", "", " ", "").Replace(typ.Label)
+	return t == "char*" || t == "char[]" || t == "char" && childOfKind(n, "ArraySize") != nil
+}
+
+// childOfKind devuelve el primer hijo de n de tipo kind, o nil
+func childOfKind(n ParseNode, kind string) *ParseNode {
+	for i := range n.Children {
+		if n.Children[i].Kind == kind {
+			return &n.Children[i]
+		}
+	}
+	return nil
+}
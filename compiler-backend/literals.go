@@ -0,0 +1,530 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// ──────────────────────────────── Literales ───────────────────────────────
+//
+// GeneralPatterns.String reconoce las cadenas entre comillas de la familia
+// de C. Acá están las que tienen prefijo o una sintaxis propia y que sin un
+// reconocedor se partirían en varios tokens:
+//
+//	C++         R"(...)" y R"delim(...)delim", u8"...", L'x'
+//	Python      f"...", b'...', r"...", rb"""...""" (sin distinguir mayúsculas)
+//	JavaScript  `texto ${expresión con `otro template` y {llaves}}`
+//	Go          `cadena sin formato`, sin secuencias de escape
+//
+// checkLiteral revisa después lo que hay dentro de cada cadena: que las
+// secuencias de escape existan y estén completas, que un caracter literal
+// tenga un solo caracter y que las interpolaciones ${} y {} no estén vacías
+// ni sin cerrar. Cada diagnóstico apunta a la posición exacta dentro de la
+// cadena, no a su comienzo.
+
+// Prefijos de codificación y de cadena cruda de C++ y de Python
+var (
+	cppStringPrefix    = regexp.MustCompile(`^(?:u8|[uUL])?R?["']`)
+	cppRawDelimiter    = regexp.MustCompile(`^(?:u8|[uUL])?R"([^()\\ \t\r\n]{0,16})\(`)
+	pythonStringPrefix = regexp.MustCompile(`^(?i:rb|br|fr|rf|[rbfu])["']`)
+)
+
+// literalPrefix devuelve el prefijo de una cadena de language (R, u8, f,
+// rb...) y el resto del lexema desde la comilla
+func literalPrefix(lexeme, language string) (prefix, rest string) {
+	var rx *regexp.Regexp
+	switch language {
+	case "cpp":
+		rx = cppStringPrefix
+	case "python":
+		rx = pythonStringPrefix
+	default:
+		return "", lexeme
+	}
+	if m := rx.FindString(lexeme); m != "" {
+		return m[:len(m)-1], lexeme[len(m)-1:]
+	}
+	return "", lexeme
+}
+
+// cppString reconoce las cadenas y caracteres con prefijo de C++. Una cadena
+// cruda llega hasta )delim" aunque ocupe varias líneas; sin ese cierre es un
+// token UNKNOWN hasta el final del código
+func cppString(lp *LanguagePatterns, s string, p int) (TokenType, string) {
+	if s[p] == '"' || s[p] == '\'' {
+		return UNKNOWN, ""
+	}
+	if m := cppRawDelimiter.FindStringSubmatch(s[p:]); m != nil {
+		closing := ")" + m[1] + `"`
+		if end := strings.Index(s[p+len(m[0]):], closing); end >= 0 {
+			return STRING, s[p : p+len(m[0])+end+len(closing)]
+		}
+		return UNKNOWN, s[p:]
+	}
+	prefix, _ := literalPrefix(s[p:], "cpp")
+	if prefix == "" || strings.HasSuffix(prefix, "R") {
+		return UNKNOWN, ""
+	}
+	return prefixedString(lp, s, p, len(prefix), strlit, unclosedString)
+}
+
+// pythonString reconoce las cadenas de Python con prefijo: f-strings,
+// bytes y cadenas crudas, con comillas simples o triples
+func pythonString(lp *LanguagePatterns, s string, p int) (TokenType, string) {
+	prefix, _ := literalPrefix(s[p:], "python")
+	if prefix == "" {
+		return UNKNOWN, ""
+	}
+	return prefixedString(lp, s, p, len(prefix), tripleString, strlit, unclosedString)
+}
+
+// prefixedString reconoce con el primero de matchers que coincida la
+// cadena que empieza n bytes después de p, y le antepone el prefijo
+func prefixedString(lp *LanguagePatterns, s string, p, n int, matchers ...matcher) (TokenType, string) {
+	for _, fn := range matchers {
+		if typ, lex := fn(lp, s, p+n); lex != "" {
+			return typ, s[p:p+n] + lex
+		}
+	}
+	return UNKNOWN, ""
+}
+
+// jsTemplate reconoce un template literal con sus interpolaciones, que
+// pueden tener llaves, cadenas y otros templates. Sin cerrar es un token
+// UNKNOWN hasta el final del código
+func jsTemplate(_ *LanguagePatterns, s string, p int) (TokenType, string) {
+	if s[p] != '`' {
+		return UNKNOWN, ""
+	}
+	if end := jsTemplateEnd(s, p+1); end >= 0 {
+		return STRING, s[p:end]
+	}
+	return UNKNOWN, s[p:]
+}
+
+// jsTemplateEnd devuelve la posición siguiente al '`' que cierra el
+// template cuyo texto empieza en i, o -1 si no se cierra
+func jsTemplateEnd(s string, i int) int {
+	for i < len(s) {
+		switch {
+		case s[i] == '\\':
+			i += 2
+		case s[i] == '`':
+			return i + 1
+		case strings.HasPrefix(s[i:], "${"):
+			end := jsInterpolationEnd(s, i+2)
+			if end < 0 {
+				return -1
+			}
+			i = end + 1
+		default:
+			i++
+		}
+	}
+	return -1
+}
+
+// jsInterpolationEnd devuelve la posición de la '}' que cierra la
+// interpolación cuya expresión empieza en i, o -1 si no se cierra
+func jsInterpolationEnd(s string, i int) int {
+	depth := 0
+	for i < len(s) {
+		switch c := s[i]; c {
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		case '`':
+			end := jsTemplateEnd(s, i+1)
+			if end < 0 {
+				return -1
+			}
+			i = end
+			continue
+		case '"', '\'':
+			end := quotedEnd(s, i)
+			if end < 0 {
+				return -1
+			}
+			i = end
+			continue
+		}
+		i++
+	}
+	return -1
+}
+
+// quotedEnd devuelve la posición siguiente a la comilla que cierra la
+// cadena que abre s[i] en la misma línea, o -1
+func quotedEnd(s string, i int) int {
+	quote := s[i]
+	for j := i + 1; j < len(s) && s[j] != '\n'; j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case quote:
+			return j + 1
+		}
+	}
+	return -1
+}
+
+// goRawString reconoce las cadenas entre acentos graves de Go, donde '\' no
+// escapa nada y que pueden ocupar varias líneas
+func goRawString(_ *LanguagePatterns, s string, p int) (TokenType, string) {
+	if s[p] != '`' {
+		return UNKNOWN, ""
+	}
+	if end := strings.IndexByte(s[p+1:], '`'); end >= 0 {
+		return STRING, s[p : p+end+2]
+	}
+	return UNKNOWN, s[p:]
+}
+
+// En JavaScript y TypeScript el template va antes que las demás cadenas
+var jsOrder = []matcher{whitespace, comment, jsTemplate, strlit, unclosedString, jsNumber, keyword, ident, oper, delim}
+
+var goOrder = []matcher{whitespace, comment, goRawString, strlit, unclosedString, goNumber, keyword, ident, oper, delim}
+
+// ─────────────────────── Validación del contenido ────────────────────────
+
+// literalIssue es un problema dentro de una cadena; at es su posición
+// dentro del lexema
+type literalIssue struct {
+	at       int
+	message  string
+	severity string
+	code     string
+}
+
+// checkLiteral revisa las secuencias de escape, los caracteres literales y
+// las interpolaciones del token STRING t
+func checkLiteral(t Token, language string) []CompilerError {
+	var issues []literalIssue
+	switch language {
+	case "cpp":
+		issues = checkCPPLiteral(t.Lexeme)
+	case "python":
+		issues = checkPythonLiteral(t.Lexeme)
+	case "javascript", "typescript":
+		issues = checkJSLiteral(t.Lexeme)
+	}
+	var errors []CompilerError
+	for _, is := range issues {
+		errors = append(errors, CompilerError{
+			Message:  "Error Léxico: " + is.message,
+			Severity: is.severity,
+			Type:     "lexico",
+			Pos:      t.Start + is.at,
+			Code:     is.code,
+		})
+	}
+	return errors
+}
+
+// escapeRule valida la secuencia de escape que empieza en body[i] ('\');
+// devuelve su largo y, si no es válida, el problema
+type escapeRule func(body string, i int) (int, *literalIssue)
+
+// checkEscapes aplica rule a cada '\' de body, que empieza en la posición
+// offset del lexema; count recibe la cantidad de caracteres que representa
+// body contando cada secuencia como uno
+func checkEscapes(body string, offset int, rule escapeRule) (issues []literalIssue, count int) {
+	for i := 0; i < len(body); {
+		if body[i] != '\\' {
+			_, size := utf8.DecodeRuneInString(body[i:])
+			i += size
+			count++
+			continue
+		}
+		n, issue := rule(body, i)
+		if issue != nil {
+			issue.at += offset
+			issues = append(issues, *issue)
+		}
+		i += n
+		count++
+	}
+	return issues, count
+}
+
+// hexDigits cuenta los dígitos hexadecimales de s desde i, hasta max
+func hexDigits(s string, i, max int) int {
+	n := 0
+	for i+n < len(s) && n < max && strings.IndexByte("0123456789abcdefABCDEF", s[i+n]) >= 0 {
+		n++
+	}
+	return n
+}
+
+// incompleteEscape es el error de una secuencia a la que le faltan dígitos
+func incompleteEscape(body string, i, n int) (int, *literalIssue) {
+	return n, &literalIssue{at: i, message: fmt.Sprintf("Secuencia de escape incompleta '%s'", body[i:i+n]),
+		severity: "error", code: CodeInvalidEscape}
+}
+
+// unknownEscape es la advertencia de una secuencia que no existe: el
+// compilador la acepta pero no significa lo que se espera
+func unknownEscape(body string, i int) (int, *literalIssue) {
+	_, size := utf8.DecodeRuneInString(body[i+1:])
+	return 1 + size, &literalIssue{at: i, message: fmt.Sprintf("Secuencia de escape desconocida '%s'", body[i:i+1+size]),
+		severity: "warning", code: CodeInvalidEscape}
+}
+
+// fixedHexEscape valida \x, \u y \U con want dígitos hexadecimales
+func fixedHexEscape(body string, i, want int) (int, *literalIssue) {
+	if n := hexDigits(body, i+2, want); n < want {
+		return incompleteEscape(body, i, 2+n)
+	}
+	return 2 + want, nil
+}
+
+// octalEscape mide \ooo: hasta tres dígitos octales
+func octalEscape(body string, i int) int {
+	n := 1
+	for n < 3 && i+1+n < len(body) && body[i+1+n] >= '0' && body[i+1+n] <= '7' {
+		n++
+	}
+	return 1 + n
+}
+
+// splitQuotes separa el contenido de una cadena de sus comillas; quote es
+// la comilla de apertura: simple, doble o triple
+func splitQuotes(lit string) (quote, body string) {
+	quote = lit[:1]
+	if len(lit) >= 6 && (strings.HasPrefix(lit, `"""`) || strings.HasPrefix(lit, "'''")) {
+		quote = lit[:3]
+	}
+	if len(lit) < 2*len(quote) {
+		return quote, ""
+	}
+	return quote, lit[len(quote) : len(lit)-len(quote)]
+}
+
+// cppEscape son las secuencias de escape de C++
+func cppEscape(body string, i int) (int, *literalIssue) {
+	if i+1 >= len(body) {
+		return 1, nil
+	}
+	switch c := body[i+1]; {
+	case strings.IndexByte("'\"?\\abfnrtv\r\n", c) >= 0:
+		return 2, nil
+	case c >= '0' && c <= '7':
+		return octalEscape(body, i), nil
+	case c == 'x':
+		// \x toma todos los dígitos hexadecimales que siguen
+		n := hexDigits(body, i+2, len(body))
+		if n == 0 {
+			return incompleteEscape(body, i, 2)
+		}
+		return 2 + n, nil
+	case c == 'u':
+		return fixedHexEscape(body, i, 4)
+	case c == 'U':
+		return fixedHexEscape(body, i, 8)
+	}
+	return unknownEscape(body, i)
+}
+
+func checkCPPLiteral(lexeme string) []literalIssue {
+	prefix, lit := literalPrefix(lexeme, "cpp")
+	if strings.HasSuffix(prefix, "R") || len(lit) < 2 {
+		return nil
+	}
+	_, body := splitQuotes(lit)
+	issues, count := checkEscapes(body, len(prefix)+1, cppEscape)
+	if lit[0] != '\'' {
+		return issues
+	}
+	switch {
+	case count == 0:
+		issues = append(issues, literalIssue{at: 0, message: "Caracter literal vacío",
+			severity: "error", code: CodeInvalidCharLiteral})
+	case count > 1:
+		// g++ lo acepta como un int con un valor que depende del compilador
+		issues = append(issues, literalIssue{at: 0,
+			message:  fmt.Sprintf("Caracter literal con %d caracteres %s; las cadenas van entre comillas dobles", count, lexeme),
+			severity: "warning", code: CodeInvalidCharLiteral})
+	}
+	return issues
+}
+
+// pythonEscape son las secuencias de escape de las cadenas de Python; las
+// de bytes no tienen \N, \u ni \U
+func pythonEscape(bytes bool) escapeRule {
+	return func(body string, i int) (int, *literalIssue) {
+		if i+1 >= len(body) {
+			return 1, nil
+		}
+		switch c := body[i+1]; {
+		case strings.IndexByte("'\"\\abfnrtv\r\n", c) >= 0:
+			return 2, nil
+		case c >= '0' && c <= '7':
+			return octalEscape(body, i), nil
+		case c == 'x':
+			return fixedHexEscape(body, i, 2)
+		case bytes:
+		case c == 'u':
+			return fixedHexEscape(body, i, 4)
+		case c == 'U':
+			return fixedHexEscape(body, i, 8)
+		case c == 'N':
+			if strings.HasPrefix(body[i+2:], "{") {
+				if end := strings.IndexByte(body[i+2:], '}'); end > 1 {
+					return 2 + end + 1, nil
+				}
+			}
+			return incompleteEscape(body, i, 2)
+		}
+		// Python 3.12 lo advierte y en una versión futura será un error
+		return unknownEscape(body, i)
+	}
+}
+
+func checkPythonLiteral(lexeme string) []literalIssue {
+	prefix, lit := literalPrefix(lexeme, "python")
+	prefix = strings.ToLower(prefix)
+	quote, body := splitQuotes(lit)
+	offset := len(prefix) + len(quote)
+	bytes := strings.Contains(prefix, "b")
+	var issues []literalIssue
+	if !strings.Contains(prefix, "r") {
+		issues, _ = checkEscapes(body, offset, pythonEscape(bytes))
+	}
+	if bytes {
+		for i, r := range body {
+			if r >= utf8.RuneSelf {
+				issues = append(issues, literalIssue{at: offset + i,
+					message:  fmt.Sprintf("Los bytes literales solo admiten caracteres ASCII: '%c'", r),
+					severity: "error", code: CodeInvalidCharacter})
+				break
+			}
+		}
+	}
+	if strings.Contains(prefix, "f") {
+		issues = append(issues, checkFStringBraces(body, offset)...)
+	}
+	return issues
+}
+
+// checkFStringBraces revisa las llaves de una f-string: {{ y }} son llaves
+// literales, { abre una expresión que cierra su } y una } suelta es un error
+func checkFStringBraces(body string, offset int) []literalIssue {
+	var issues []literalIssue
+	for i := 0; i < len(body); i++ {
+		switch {
+		case strings.HasPrefix(body[i:], "{{"), strings.HasPrefix(body[i:], "}}"):
+			i++
+		case body[i] == '{':
+			end := pythonExpressionEnd(body, i+1)
+			if end < 0 {
+				return append(issues, literalIssue{at: offset + i, message: "f-string: falta '}' para cerrar la expresión",
+					severity: "error", code: CodeInvalidInterpolation})
+			}
+			// La expresión termina donde empiezan la conversión o el formato
+			expr := body[i+1 : end]
+			if j := strings.IndexAny(expr, "!:"); j >= 0 && !strings.HasPrefix(expr[j:], "!=") {
+				expr = expr[:j]
+			}
+			if strings.TrimSpace(expr) == "" {
+				issues = append(issues, literalIssue{at: offset + i,
+					message: fmt.Sprintf("Interpolación vacía '%s'", body[i:end+1]), severity: "error", code: CodeInvalidInterpolation})
+			}
+			i = end
+		case body[i] == '}':
+			issues = append(issues, literalIssue{at: offset + i, message: "f-string: '}' sin '{'; para una llave literal use '}}'",
+				severity: "error", code: CodeInvalidInterpolation})
+		}
+	}
+	return issues
+}
+
+// pythonExpressionEnd devuelve la posición de la '}' que cierra la
+// expresión de una f-string que empieza en i, o -1
+func pythonExpressionEnd(s string, i int) int {
+	depth := 0
+	for ; i < len(s); i++ {
+		switch s[i] {
+		case '{', '[', '(':
+			depth++
+		case ']', ')':
+			depth--
+		case '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		case '"', '\'':
+			end := quotedEnd(s, i)
+			if end < 0 {
+				return -1
+			}
+			i = end - 1
+		}
+	}
+	return -1
+}
+
+// jsEscape son las secuencias de escape de JavaScript: cualquier otro
+// caracter se escapa a sí mismo, pero \x y \u deben estar completas
+func jsEscape(body string, i int) (int, *literalIssue) {
+	if i+1 >= len(body) {
+		return 1, nil
+	}
+	switch body[i+1] {
+	case 'x':
+		return fixedHexEscape(body, i, 2)
+	case 'u':
+		if strings.HasPrefix(body[i+2:], "{") {
+			n := hexDigits(body, i+3, 6)
+			if n == 0 || !strings.HasPrefix(body[i+3+n:], "}") {
+				return incompleteEscape(body, i, 3+n)
+			}
+			return 4 + n, nil
+		}
+		return fixedHexEscape(body, i, 4)
+	}
+	_, size := utf8.DecodeRuneInString(body[i+1:])
+	return 1 + size, nil
+}
+
+func checkJSLiteral(lexeme string) []literalIssue {
+	if len(lexeme) < 2 {
+		return nil
+	}
+	if lexeme[0] != '`' {
+		issues, _ := checkEscapes(lexeme[1:len(lexeme)-1], 1, jsEscape)
+		return issues
+	}
+	// En un template se revisa el texto entre interpolaciones; las
+	// expresiones son código y sus cadenas son otros tokens para el parser
+	var issues []literalIssue
+	body := lexeme[1 : len(lexeme)-1]
+	start := 0
+	for i := 0; i < len(body); i++ {
+		switch {
+		case body[i] == '\\':
+			i++
+		case strings.HasPrefix(body[i:], "${"):
+			text, _ := checkEscapes(body[start:i], 1+start, jsEscape)
+			issues = append(issues, text...)
+			end := jsInterpolationEnd(body, i+2)
+			if end < 0 {
+				return issues
+			}
+			if strings.TrimSpace(body[i+2:end]) == "" {
+				issues = append(issues, literalIssue{at: 1 + i,
+					message: fmt.Sprintf("Interpolación vacía '%s'", body[i:end+1]), severity: "error", code: CodeInvalidInterpolation})
+			}
+			i = end
+			start = end + 1
+		}
+	}
+	text, _ := checkEscapes(body[start:], 1+start, jsEscape)
+	return append(issues, text...)
+}
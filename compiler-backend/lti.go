@@ -0,0 +1,909 @@
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ─────────────────────────────── LTI 1.3 ─────────────────────────────────
+//
+// El servidor funciona como herramienta LTI 1.3 para Moodle, Canvas y otros
+// LMS: una actividad externa abre la página de entrega, el estudiante pega
+// su código, se ejecutan los casos de prueba del perfil de la tarea (ver
+// assignments.go) y la nota vuelve al libro de calificaciones con Assignment
+// and Grade Services. LTI_CONFIG apunta a un archivo JSON o YAML (ver
+// customrules.go):
+//
+//	toolUrl: https://compilador.ejemplo.edu
+//	privateKey: /etc/compilador/lti.pem
+//	platforms:
+//	  - issuer: https://moodle.ejemplo.edu
+//	    clientId: Xk2PqYd8
+//	    deploymentIds: ["3"]
+//	    authUrl: https://moodle.ejemplo.edu/mod/lti/auth.php
+//	    tokenUrl: https://moodle.ejemplo.edu/mod/lti/token.php
+//	    jwksUrl: https://moodle.ejemplo.edu/mod/lti/certs.php
+//
+// En el LMS la herramienta se registra con /lti/login como URL de inicio de
+// sesión, /lti/launch como URL de redirección y /lti/jwks como conjunto de
+// claves; cada actividad indica su perfil con el parámetro personalizado
+// profile=factorial. El lanzamiento sigue el flujo de OpenID Connect:
+//
+//  1. /lti/login recibe iss y login_hint y redirige al authUrl de la
+//     plataforma con un state y un nonce nuevos; el state también queda en
+//     una cookie del navegador.
+//  2. La plataforma envía a /lti/launch el id_token firmado con RS256; se
+//     verifica con las claves de su jwksUrl, y también el emisor, el
+//     destinatario, la vigencia, el nonce y el deployment. El state debe
+//     coincidir con la cookie: un lanzamiento iniciado en otro navegador
+//     no abre sesión en este.
+//  3. La página de entrega envía el código a /lti/submit, que lo califica y
+//     publica la nota en el lineitem de la actividad con un token de acceso
+//     que la herramienta pide firmando una aserción con su privateKey.
+//
+// El lanzamiento se guarda en memoria durante ltiLaunchTTL: pasado ese
+// tiempo el estudiante vuelve a abrir la actividad desde el LMS.
+
+const (
+	ltiLoginTTL = 10 * time.Minute
+	// Prefijo de la cookie que liga cada state al navegador que inició la
+	// sesión; lleva el state en el nombre para admitir varias actividades
+	// abiertas a la vez
+	ltiStateCookie = "lti_state_"
+	ltiLaunchTTL   = 2 * time.Hour
+	// Diferencia de reloj tolerada con la plataforma
+	ltiClockSkew = time.Minute
+	// Las claves de la plataforma se vuelven a pedir si aparece un kid
+	// desconocido, como mucho una vez por ltiJWKSRefresh
+	ltiJWKSRefresh = time.Minute
+
+	ltiScoreScope   = "https://purl.imsglobal.org/spec/lti-ags/scope/score"
+	ltiScoreType    = "application/vnd.ims.lis.v1.score+json"
+	ltiAssertionJWT = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+)
+
+var ltiHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// ───── Configuración ─────
+
+// LTIConfig es la herramienta ya cargada: su URL pública, su clave y las
+// plataformas registradas
+type LTIConfig struct {
+	toolURL   string
+	key       *rsa.PrivateKey
+	keyID     string
+	platforms []*ltiPlatform
+}
+
+type ltiConfigFile struct {
+	ToolURL    string         `json:"toolUrl"`
+	PrivateKey string         `json:"privateKey"`
+	Platforms  []*ltiPlatform `json:"platforms"`
+}
+
+// ltiPlatform es un LMS registrado; deploymentIds vacío acepta todos
+type ltiPlatform struct {
+	Issuer        string   `json:"issuer"`
+	ClientID      string   `json:"clientId"`
+	DeploymentIDs []string `json:"deploymentIds"`
+	AuthURL       string   `json:"authUrl"`
+	TokenURL      string   `json:"tokenUrl"`
+	JWKSURL       string   `json:"jwksUrl"`
+
+	mu          sync.Mutex
+	keys        map[string]*rsa.PublicKey
+	keysFetched time.Time
+	token       string
+	tokenExpiry time.Time
+}
+
+// loadLTIConfig lee y valida el archivo path y la clave privada que indica
+func loadLTIConfig(path string) (*LTIConfig, error) {
+	var file ltiConfigFile
+	if err := decodeConfigFile(path, &file); err != nil {
+		return nil, err
+	}
+	if u, err := url.Parse(file.ToolURL); err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("toolUrl must be an absolute URL")
+	}
+	if file.PrivateKey == "" {
+		return nil, fmt.Errorf("privateKey is required")
+	}
+	key, err := readRSAPrivateKey(file.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(file.Platforms) == 0 {
+		return nil, fmt.Errorf("at least one platform is required")
+	}
+	seen := map[string]bool{}
+	for i, p := range file.Platforms {
+		if p == nil || p.Issuer == "" || p.ClientID == "" || p.AuthURL == "" || p.TokenURL == "" || p.JWKSURL == "" {
+			return nil, fmt.Errorf("platform %d: issuer, clientId, authUrl, tokenUrl and jwksUrl are required", i+1)
+		}
+		if seen[p.Issuer+" "+p.ClientID] {
+			return nil, fmt.Errorf("platform %d: %s with client %s is registered twice", i+1, p.Issuer, p.ClientID)
+		}
+		seen[p.Issuer+" "+p.ClientID] = true
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(der)
+	return &LTIConfig{
+		toolURL:   strings.TrimSuffix(file.ToolURL, "/"),
+		key:       key,
+		keyID:     base64.RawURLEncoding.EncodeToString(sum[:12]),
+		platforms: file.Platforms,
+	}, nil
+}
+
+// readRSAPrivateKey acepta claves PEM en PKCS#1 (openssl genrsa) o PKCS#8
+func readRSAPrivateKey(path string) (*rsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not a PEM file", path)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an RSA key", path)
+	}
+	return key, nil
+}
+
+// platform busca la plataforma de issuer; clientID puede faltar en el
+// inicio de sesión si el emisor tiene un solo registro
+func (c *LTIConfig) platform(issuer, clientID string) *ltiPlatform {
+	var found *ltiPlatform
+	for _, p := range c.platforms {
+		if p.Issuer != issuer || clientID != "" && p.ClientID != clientID {
+			continue
+		}
+		if found != nil {
+			return nil
+		}
+		found = p
+	}
+	return found
+}
+
+func (p *ltiPlatform) allowsDeployment(id string) bool {
+	if len(p.DeploymentIDs) == 0 {
+		return true
+	}
+	for _, d := range p.DeploymentIDs {
+		if d == id {
+			return true
+		}
+	}
+	return false
+}
+
+// ───── Lanzamientos ─────
+
+type ltiLogin struct {
+	nonce    string
+	platform *ltiPlatform
+	expires  time.Time
+}
+
+// ltiLaunch es un lanzamiento verificado: quién entrega, qué tarea y dónde
+// se publica la nota
+type ltiLaunch struct {
+	platform *ltiPlatform
+	subject  string
+	name     string
+	title    string
+	locale   string
+	profile  *AssignmentProfile
+	// URL del lineitem de la actividad; vacía si la plataforma no habilitó
+	// la publicación de notas
+	lineItem string
+	expires  time.Time
+}
+
+type ltiStore struct {
+	mu       sync.Mutex
+	logins   map[string]*ltiLogin
+	launches map[string]*ltiLaunch
+}
+
+var ltiSessions = &ltiStore{logins: make(map[string]*ltiLogin), launches: make(map[string]*ltiLaunch)}
+
+// newLTIID devuelve un valor aleatorio para state, nonce y los
+// lanzamientos
+func newLTIID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// purge descarta los inicios de sesión y lanzamientos vencidos; se llama
+// con mu tomado
+func (st *ltiStore) purge(now time.Time) {
+	for id, l := range st.logins {
+		if now.After(l.expires) {
+			delete(st.logins, id)
+		}
+	}
+	for id, l := range st.launches {
+		if now.After(l.expires) {
+			delete(st.launches, id)
+		}
+	}
+}
+
+func (st *ltiStore) addLogin(state string, l *ltiLogin) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.purge(time.Now())
+	st.logins[state] = l
+}
+
+// takeLogin devuelve el inicio de sesión de state y lo descarta: cada state
+// sirve para un solo lanzamiento
+func (st *ltiStore) takeLogin(state string) *ltiLogin {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.purge(time.Now())
+	l := st.logins[state]
+	delete(st.logins, state)
+	return l
+}
+
+func (st *ltiStore) addLaunch(l *ltiLaunch) (string, error) {
+	id, err := newLTIID()
+	if err != nil {
+		return "", err
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.purge(time.Now())
+	st.launches[id] = l
+	return id, nil
+}
+
+func (st *ltiStore) launch(id string) *ltiLaunch {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.purge(time.Now())
+	return st.launches[id]
+}
+
+// ───── id_token ─────
+
+// ltiClaims son los claims del id_token que usa la herramienta
+type ltiClaims struct {
+	Issuer       string      `json:"iss"`
+	Subject      string      `json:"sub"`
+	Audience     ltiAudience `json:"aud"`
+	AuthorizedBy string      `json:"azp"`
+	ExpiresAt    int64       `json:"exp"`
+	IssuedAt     int64       `json:"iat"`
+	Nonce        string      `json:"nonce"`
+	Name         string      `json:"name"`
+
+	MessageType  string `json:"https://purl.imsglobal.org/spec/lti/claim/message_type"`
+	Version      string `json:"https://purl.imsglobal.org/spec/lti/claim/version"`
+	DeploymentID string `json:"https://purl.imsglobal.org/spec/lti/claim/deployment_id"`
+	ResourceLink struct {
+		ID    string `json:"id"`
+		Title string `json:"title"`
+	} `json:"https://purl.imsglobal.org/spec/lti/claim/resource_link"`
+	Presentation struct {
+		Locale string `json:"locale"`
+	} `json:"https://purl.imsglobal.org/spec/lti/claim/launch_presentation"`
+	Custom map[string]interface{} `json:"https://purl.imsglobal.org/spec/lti/claim/custom"`
+	AGS    *struct {
+		Scope    []string `json:"scope"`
+		LineItem string   `json:"lineitem"`
+	} `json:"https://purl.imsglobal.org/spec/lti-ags/claim/endpoint"`
+}
+
+// ltiAudience es aud, que puede ser un texto o una lista
+type ltiAudience []string
+
+func (a *ltiAudience) UnmarshalJSON(data []byte) error {
+	var one string
+	if json.Unmarshal(data, &one) == nil {
+		*a = ltiAudience{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+var errInvalidLaunch = errors.New("invalid id_token")
+
+// verifyLaunch comprueba la firma y los claims del id_token de p
+func (p *ltiPlatform) verifyLaunch(token, nonce string, now time.Time) (*ltiClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errInvalidLaunch
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || json.Unmarshal(raw, &header) != nil || header.Alg != "RS256" {
+		return nil, errInvalidLaunch
+	}
+	key, err := p.publicKey(header.Kid, now)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errInvalidLaunch
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) != nil {
+		return nil, errInvalidLaunch
+	}
+
+	var claims ltiClaims
+	raw, err = base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || json.Unmarshal(raw, &claims) != nil {
+		return nil, errInvalidLaunch
+	}
+	switch {
+	case claims.Issuer != p.Issuer:
+		return nil, fmt.Errorf("%w: issuer %s", errInvalidLaunch, claims.Issuer)
+	case !slices.Contains(claims.Audience, p.ClientID),
+		len(claims.Audience) > 1 && claims.AuthorizedBy != p.ClientID:
+		return nil, fmt.Errorf("%w: audience", errInvalidLaunch)
+	case now.Add(-ltiClockSkew).Unix() >= claims.ExpiresAt,
+		now.Add(ltiClockSkew).Unix() < claims.IssuedAt:
+		return nil, fmt.Errorf("%w: expired", errInvalidLaunch)
+	case claims.Nonce != nonce:
+		return nil, fmt.Errorf("%w: nonce", errInvalidLaunch)
+	case claims.Subject == "":
+		return nil, fmt.Errorf("%w: anonymous launch", errInvalidLaunch)
+	case claims.Version != "1.3.0" || claims.MessageType != "LtiResourceLinkRequest":
+		return nil, fmt.Errorf("%w: message %s %s", errInvalidLaunch, claims.MessageType, claims.Version)
+	case !p.allowsDeployment(claims.DeploymentID):
+		return nil, fmt.Errorf("%w: deployment %s", errInvalidLaunch, claims.DeploymentID)
+	}
+	return &claims, nil
+}
+
+// publicKey devuelve la clave kid de la plataforma, pidiendo de nuevo su
+// jwksUrl si no la conoce
+func (p *ltiPlatform) publicKey(kid string, now time.Time) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	if now.Sub(p.keysFetched) < ltiJWKSRefresh {
+		return nil, fmt.Errorf("%w: unknown key %s", errInvalidLaunch, kid)
+	}
+	keys, err := fetchJWKS(p.JWKSURL)
+	if err != nil {
+		return nil, err
+	}
+	p.keys, p.keysFetched = keys, now
+	// Sin kid vale la única clave publicada
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, nil
+		}
+	}
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: unknown key %s", errInvalidLaunch, kid)
+}
+
+// jwk es una clave pública RSA en formato JWK
+type jwk struct {
+	Kty string `json:"kty"`
+	Alg string `json:"alg,omitempty"`
+	Use string `json:"use,omitempty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// fetchJWKS descarga las claves RSA publicadas en url
+func fetchJWKS(url string) (map[string]*rsa.PublicKey, error) {
+	resp, err := ltiHTTPClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&set); err != nil {
+		return nil, fmt.Errorf("%s: %v", url, err)
+	}
+	keys := map[string]*rsa.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(k.N)
+		e, errE := base64.RawURLEncoding.DecodeString(k.E)
+		if errN != nil || errE != nil || len(e) > 4 {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	return keys, nil
+}
+
+// signJWT firma claims con la clave de la herramienta (RS256)
+func (c *LTIConfig) signJWT(claims interface{}) (string, error) {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": c.keyID})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, c.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// ───── Notas ─────
+
+// accessToken pide a la plataforma un token para publicar notas, o
+// devuelve el anterior si sigue vigente
+func (p *ltiPlatform) accessToken(c *LTIConfig) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	if p.token != "" && now.Before(p.tokenExpiry) {
+		return p.token, nil
+	}
+	jti, err := newLTIID()
+	if err != nil {
+		return "", err
+	}
+	assertion, err := c.signJWT(map[string]interface{}{
+		"iss": p.ClientID, "sub": p.ClientID, "aud": p.TokenURL,
+		"iat": now.Unix(), "exp": now.Add(5 * time.Minute).Unix(), "jti": jti,
+	})
+	if err != nil {
+		return "", err
+	}
+	resp, err := ltiHTTPClient.PostForm(p.TokenURL, url.Values{
+		"grant_type":            {"client_credentials"},
+		"client_assertion_type": {ltiAssertionJWT},
+		"client_assertion":      {assertion},
+		"scope":                 {ltiScoreScope},
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body) != nil || body.AccessToken == "" {
+		return "", fmt.Errorf("token endpoint: %s", resp.Status)
+	}
+	p.token = body.AccessToken
+	// Se renueva un poco antes de que venza
+	p.tokenExpiry = now.Add(time.Duration(body.ExpiresIn)*time.Second - 30*time.Second)
+	return p.token, nil
+}
+
+// postScore publica la nota del lanzamiento en su lineitem
+func (l *ltiLaunch) postScore(c *LTIConfig, score, maxScore int, comment string) error {
+	token, err := l.platform.accessToken(c)
+	if err != nil {
+		return err
+	}
+	// Los scores van en {lineitem}/scores, conservando la query del lineitem
+	u, err := url.Parse(l.lineItem)
+	if err != nil {
+		return err
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/scores"
+	body, _ := json.Marshal(map[string]interface{}{
+		"userId":           l.subject,
+		"scoreGiven":       score,
+		"scoreMaximum":     maxScore,
+		"comment":          comment,
+		"timestamp":        time.Now().UTC().Format("2006-01-02T15:04:05.000Z07:00"),
+		"activityProgress": "Completed",
+		"gradingProgress":  "FullyGraded",
+	})
+	req, err := http.NewRequest(http.MethodPost, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", ltiScoreType)
+	resp, err := ltiHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("score endpoint: %s", resp.Status)
+	}
+	return nil
+}
+
+// ───── Handlers ─────
+
+// ltiConfigured responde 404 si el servidor no tiene LTI_CONFIG
+func ltiConfigured(w http.ResponseWriter) *LTIConfig {
+	if GlobalConfig.LTI == nil {
+		httpError(w, "LTI is not configured", http.StatusNotFound)
+	}
+	return GlobalConfig.LTI
+}
+
+// ltiLoginHandler atiende el inicio de sesión de OpenID Connect, por GET o
+// POST según la plataforma
+func ltiLoginHandler(w http.ResponseWriter, r *http.Request) {
+	cfg := ltiConfigured(w)
+	if cfg == nil {
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	r.ParseForm()
+	platform := cfg.platform(r.Form.Get("iss"), r.Form.Get("client_id"))
+	if platform == nil {
+		httpError(w, "Unknown LTI platform "+r.Form.Get("iss"), http.StatusBadRequest)
+		return
+	}
+	if r.Form.Get("login_hint") == "" {
+		httpError(w, "login_hint is required", http.StatusBadRequest)
+		return
+	}
+	state, err := newLTIID()
+	nonce, err2 := newLTIID()
+	if err != nil || err2 != nil {
+		httpError(w, "Could not start the launch", http.StatusInternalServerError)
+		return
+	}
+	ltiSessions.addLogin(state, &ltiLogin{nonce: nonce, platform: platform, expires: time.Now().Add(ltiLoginTTL)})
+	// La plataforma envía el lanzamiento con un POST desde otro sitio: la
+	// cookie necesita SameSite=None, que a su vez exige Secure
+	http.SetCookie(w, &http.Cookie{
+		Name:     ltiStateCookie + state,
+		Value:    state,
+		Path:     "/lti/launch",
+		MaxAge:   int(ltiLoginTTL / time.Second),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+	})
+
+	query := url.Values{
+		"scope":         {"openid"},
+		"response_type": {"id_token"},
+		"response_mode": {"form_post"},
+		"prompt":        {"none"},
+		"client_id":     {platform.ClientID},
+		"redirect_uri":  {cfg.toolURL + "/lti/launch"},
+		"login_hint":    {r.Form.Get("login_hint")},
+		"state":         {state},
+		"nonce":         {nonce},
+	}
+	if hint := r.Form.Get("lti_message_hint"); hint != "" {
+		query.Set("lti_message_hint", hint)
+	}
+	target := platform.AuthURL
+	if strings.Contains(target, "?") {
+		target += "&" + query.Encode()
+	} else {
+		target += "?" + query.Encode()
+	}
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+// ltiLaunchHandler verifica el id_token y muestra la página de entrega
+func ltiLaunchHandler(w http.ResponseWriter, r *http.Request) {
+	cfg := ltiConfigured(w)
+	if cfg == nil {
+		return
+	}
+	if r.Method != http.MethodPost {
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	r.ParseForm()
+	locale := requestLocale("", r)
+	text := ltiTextsFor(locale)
+	state := r.PostForm.Get("state")
+	cookie, err := r.Cookie(ltiStateCookie + state)
+	if err != nil || cookie.Value != state {
+		ltiPage(w, http.StatusBadRequest, locale, text.Error, "<p>"+text.Expired+"</p>")
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: cookie.Name, Path: "/lti/launch", MaxAge: -1, HttpOnly: true, Secure: true, SameSite: http.SameSiteNoneMode})
+	login := ltiSessions.takeLogin(state)
+	if login == nil {
+		ltiPage(w, http.StatusBadRequest, locale, text.Error, "<p>"+text.Expired+"</p>")
+		return
+	}
+	claims, err := login.platform.verifyLaunch(r.PostForm.Get("id_token"), login.nonce, time.Now())
+	if err != nil {
+		ltiPage(w, http.StatusUnauthorized, locale, text.Error, "<p>"+html.EscapeString(err.Error())+"</p>")
+		return
+	}
+	if l := normalizeLocale(claims.Presentation.Locale); l != "" {
+		locale, text = l, ltiTextsFor(l)
+	}
+
+	name := fmt.Sprint(claims.Custom["profile"])
+	profile := GlobalConfig.AssignmentProfiles[name]
+	if claims.Custom["profile"] == nil || profile == nil || len(profile.TestCases) == 0 {
+		ltiPage(w, http.StatusBadRequest, locale, text.Error, "<p>"+text.NoProfile+"</p>")
+		return
+	}
+	launch := &ltiLaunch{
+		platform: login.platform,
+		subject:  claims.Subject,
+		name:     claims.Name,
+		title:    claims.ResourceLink.Title,
+		locale:   locale,
+		profile:  profile,
+		expires:  time.Now().Add(ltiLaunchTTL),
+	}
+	if launch.title == "" {
+		launch.title = profile.Name
+	}
+	if ags := claims.AGS; ags != nil && ags.LineItem != "" && slices.Contains(ags.Scope, ltiScoreScope) {
+		launch.lineItem = ags.LineItem
+	}
+	id, err := ltiSessions.addLaunch(launch)
+	if err != nil {
+		httpError(w, "Could not start the launch", http.StatusInternalServerError)
+		return
+	}
+	ltiPage(w, http.StatusOK, locale, launch.title, launch.form(id, "", ""))
+}
+
+// ltiSubmitHandler califica el código con los casos del perfil y publica la
+// nota
+func ltiSubmitHandler(w http.ResponseWriter, r *http.Request) {
+	cfg := ltiConfigured(w)
+	if cfg == nil {
+		return
+	}
+	if r.Method != http.MethodPost {
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	r.ParseForm()
+	id := r.PostForm.Get("launch")
+	launch := ltiSessions.launch(id)
+	if launch == nil {
+		locale := requestLocale("", r)
+		ltiPage(w, http.StatusBadRequest, locale, ltiTextsFor(locale).Error, "<p>"+ltiTextsFor(locale).Expired+"</p>")
+		return
+	}
+	text := ltiTextsFor(launch.locale)
+	code := r.PostForm.Get("code")
+	language := mapLanguage(r.PostForm.Get("language"))
+	if strings.TrimSpace(code) == "" {
+		ltiPage(w, http.StatusBadRequest, launch.locale, launch.title, "<p class=\"error\">"+text.EmptyCode+"</p>"+launch.form(id, language, code))
+		return
+	}
+	if msg := codeTooLarge(code); msg != "" {
+		ltiPage(w, http.StatusRequestEntityTooLarge, launch.locale, launch.title, "<p class=\"error\">"+html.EscapeString(msg)+"</p>"+launch.form(id, language, code))
+		return
+	}
+	if language == "" {
+		language = DetectLanguage(code)
+	}
+	principal := &Principal{
+		Account:      "lti:" + launch.platform.Issuer + "#" + launch.subject,
+		User:         launch.name,
+		DailyMinutes: GlobalConfig.AuthDailyMinutes,
+	}
+	if principal.User == "" {
+		principal.User = launch.subject
+	}
+	if status, msg := authorizeAnalysis(principal, language, true); status != 0 {
+		ltiPage(w, status, launch.locale, launch.title, "<p class=\"error\">"+html.EscapeString(msg)+"</p>")
+		return
+	}
+
+	opts := AnalyzeOptions{Principal: principal}
+	opts.withProfile(launch.profile)
+	result := AnalyzeCodeWithProgress(code, language, opts, nil)
+	recordAnalysis(code, result, principal)
+	response := buildAPIResponse(result, newSourceIndex(code), launch.locale)
+
+	var b strings.Builder
+	tests := response.TestResults
+	score, maxScore := 0, 0
+	if tests != nil {
+		score, maxScore = tests.Score, tests.MaxScore
+		fmt.Fprintf(&b, "<h2>%s: %d / %d</h2>\n<table>\n<thead><tr><th>#</th><th>%s</th><th>%s</th><th></th></tr></thead>\n<tbody>\n",
+			text.Score, score, maxScore, text.Verdict, text.Points)
+		for i, c := range tests.Cases {
+			fmt.Fprintf(&b, "<tr class=\"%s\"><td>%d</td><td>%s</td><td>%d / %d</td><td>%s</td></tr>\n",
+				strings.ToLower(c.Verdict), i+1, c.Verdict, c.Earned, c.Points, html.EscapeString(c.Message))
+		}
+		b.WriteString("</tbody>\n</table>\n")
+	}
+	if len(response.Errors) > 0 {
+		b.WriteString("<ul class=\"diagnostics\">\n")
+		for _, e := range response.Errors {
+			fmt.Fprintf(&b, "<li class=\"%s\">%d:%d %s</li>\n", html.EscapeString(e.Severity), e.Line, e.Column, html.EscapeString(e.Message))
+		}
+		b.WriteString("</ul>\n")
+	}
+
+	switch {
+	case launch.lineItem == "":
+		fmt.Fprintf(&b, "<p>%s</p>\n", text.NoGrades)
+	case maxScore == 0:
+	default:
+		verdict := VerdictCompileError
+		if tests != nil {
+			verdict = tests.Verdict
+		}
+		comment := fmt.Sprintf("%s: %s, %d / %d", launch.profile.Name, verdict, score, maxScore)
+		if err := launch.postScore(cfg, score, maxScore, comment); err != nil {
+			fmt.Fprintf(&b, "<p class=\"error\">%s: %s</p>\n", text.GradeFailed, html.EscapeString(err.Error()))
+		} else {
+			fmt.Fprintf(&b, "<p class=\"ac\">%s</p>\n", text.GradePosted)
+		}
+	}
+	b.WriteString(launch.form(id, language, code))
+	ltiPage(w, http.StatusOK, launch.locale, launch.title, b.String())
+}
+
+// ltiJWKSHandler publica la clave pública de la herramienta, con la que la
+// plataforma verifica las aserciones de los pedidos de token
+func ltiJWKSHandler(w http.ResponseWriter, r *http.Request) {
+	cfg := ltiConfigured(w)
+	if cfg == nil {
+		return
+	}
+	if r.Method != http.MethodGet {
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	pub := cfg.key.PublicKey
+	key := jwk{
+		Kty: "RSA", Alg: "RS256", Use: "sig", Kid: cfg.keyID,
+		N: base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+		E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string][]jwk{"keys": {key}})
+}
+
+// ───── Páginas ─────
+
+// Textos de las páginas de la herramienta en cada idioma
+type ltiText struct {
+	Error, Expired, NoProfile, EmptyCode          string
+	Language, Submit, Score, Verdict, Points      string
+	GradePosted, GradeFailed, NoGrades, Submitter string
+}
+
+var ltiTexts = map[string]ltiText{
+	"es": {
+		Error:     "No se pudo abrir la actividad",
+		Expired:   "La sesión venció o no es válida. Vuelve a abrir la actividad desde el curso.",
+		NoProfile: "La actividad no indica un perfil de tarea con casos de prueba (parámetro personalizado profile).",
+		EmptyCode: "Pega el código antes de enviarlo.",
+		Language:  "Lenguaje", Submit: "Enviar", Score: "Puntaje", Verdict: "Veredicto", Points: "Puntos",
+		GradePosted: "La nota se publicó en el curso.", GradeFailed: "No se pudo publicar la nota",
+		NoGrades:  "El curso no recibe notas de esta actividad.",
+		Submitter: "Entrega de",
+	},
+	"en": {
+		Error:     "The activity could not be opened",
+		Expired:   "The session expired or is not valid. Open the activity again from the course.",
+		NoProfile: "The activity does not name an assignment profile with test cases (custom parameter profile).",
+		EmptyCode: "Paste your code before submitting.",
+		Language:  "Language", Submit: "Submit", Score: "Score", Verdict: "Verdict", Points: "Points",
+		GradePosted: "The grade was posted to the course.", GradeFailed: "The grade could not be posted",
+		NoGrades:  "The course does not receive grades from this activity.",
+		Submitter: "Submission by",
+	},
+}
+
+func ltiTextsFor(locale string) ltiText {
+	if text, ok := ltiTexts[locale]; ok {
+		return text
+	}
+	return ltiTexts["es"]
+}
+
+const ltiStyle = `body{font-family:Helvetica,Arial,sans-serif;margin:1.5em;max-width:60em}` +
+	`textarea{width:100%;font-family:Menlo,Consolas,monospace;font-size:.9em}` +
+	`table{border-collapse:collapse;margin:.5em 0}th,td{border:1px solid #ccc;padding:.3em .6em;text-align:left}` +
+	`.ac{color:#1a7f37}.wa,.re,.tle,.ce,.error{color:#b00020}.warning{color:#8a6d00}`
+
+// ltiPage responde una página completa; body ya viene escapado
+func ltiPage(w http.ResponseWriter, status int, locale, title, body string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<!DOCTYPE html>\n<html lang=\"%s\">\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n<style>%s</style>\n</head>\n<body>\n<h1>%s</h1>\n%s</body>\n</html>\n",
+		locale, html.EscapeString(title), ltiStyle, html.EscapeString(title), body)
+}
+
+// form es el formulario de entrega, con el lenguaje y el código anteriores
+// si el estudiante vuelve a enviar
+func (l *ltiLaunch) form(id, language, code string) string {
+	text := ltiTextsFor(l.locale)
+	options := l.profile.Languages
+	if len(options) == 0 {
+		// Sin lenguajes en el perfil, los que se pueden ejecutar
+		for lang := range GlobalConfig.DockerImages {
+			options = append(options, lang)
+		}
+		sort.Strings(options)
+	}
+	var b strings.Builder
+	if l.name != "" {
+		fmt.Fprintf(&b, "<p>%s %s</p>\n", text.Submitter, html.EscapeString(l.name))
+	}
+	fmt.Fprintf(&b, "<form method=\"post\" action=\"submit\">\n<input type=\"hidden\" name=\"launch\" value=\"%s\">\n<label>%s <select name=\"language\">",
+		html.EscapeString(id), text.Language)
+	for _, lang := range options {
+		selected := ""
+		if lang == language {
+			selected = " selected"
+		}
+		fmt.Fprintf(&b, "<option value=\"%s\"%s>%s</option>", html.EscapeString(lang), selected, html.EscapeString(lang))
+	}
+	fmt.Fprintf(&b, "</select></label>\n<p><textarea name=\"code\" rows=\"24\" spellcheck=\"false\">%s</textarea></p>\n<button type=\"submit\">%s</button>\n</form>\n",
+		html.EscapeString(code), text.Submit)
+	return b.String()
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// signLTIToken arma un id_token RS256 con kid firmado con key
+func signLTIToken(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	var parts []string
+	for _, v := range []map[string]interface{}{{"alg": "RS256", "typ": "JWT", "kid": kid}, claims} {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts = append(parts, base64.RawURLEncoding.EncodeToString(raw))
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parts[0] + "." + parts[1] + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// testLTIPlatform registra una plataforma con la clave pública de key ya
+// conocida, para que verifyLaunch no pida su jwksUrl
+func testLTIPlatform(key *rsa.PrivateKey) *ltiPlatform {
+	return &ltiPlatform{
+		Issuer:        "https://moodle.example.edu",
+		ClientID:      "herramienta",
+		DeploymentIDs: []string{"1"},
+		keys:          map[string]*rsa.PublicKey{"k1": &key.PublicKey},
+		keysFetched:   time.Now(),
+	}
+}
+
+// ltiTestClaims son los claims de un lanzamiento válido en now
+func ltiTestClaims(now time.Time, nonce string) map[string]interface{} {
+	return map[string]interface{}{
+		"iss":   "https://moodle.example.edu",
+		"sub":   "estudiante-7",
+		"aud":   "herramienta",
+		"exp":   now.Unix() + 300,
+		"iat":   now.Unix(),
+		"nonce": nonce,
+		"https://purl.imsglobal.org/spec/lti/claim/message_type":  "LtiResourceLinkRequest",
+		"https://purl.imsglobal.org/spec/lti/claim/version":       "1.3.0",
+		"https://purl.imsglobal.org/spec/lti/claim/deployment_id": "1",
+		"https://purl.imsglobal.org/spec/lti/claim/custom":        map[string]interface{}{"profile": "tarea"},
+	}
+}
+
+// TestVerifyLTILaunch comprueba que solo se acepta un id_token firmado por
+// la plataforma, para esta herramienta, vigente y con el nonce del inicio
+func TestVerifyLTILaunch(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	platform := testLTIPlatform(key)
+	now := time.Unix(1_700_000_000, 0)
+	with := func(name string, value interface{}) map[string]interface{} {
+		claims := ltiTestClaims(now, "nonce-1")
+		claims[name] = value
+		return claims
+	}
+
+	cases := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{"valido", signLTIToken(t, key, "k1", ltiTestClaims(now, "nonce-1")), true},
+		{"otra_clave", signLTIToken(t, other, "k1", ltiTestClaims(now, "nonce-1")), false},
+		{"kid_desconocido", signLTIToken(t, key, "k2", ltiTestClaims(now, "nonce-1")), false},
+		{"otra_audiencia", signLTIToken(t, key, "k1", with("aud", "otra-herramienta")), false},
+		{"varias_audiencias_sin_azp", signLTIToken(t, key, "k1", with("aud", []string{"herramienta", "otra"})), false},
+		{"otro_emisor", signLTIToken(t, key, "k1", with("iss", "https://canvas.example.edu")), false},
+		{"vencido", signLTIToken(t, key, "k1", with("exp", now.Unix()-120)), false},
+		{"otro_nonce", signLTIToken(t, key, "k1", with("nonce", "nonce-2")), false},
+		{"otro_despliegue", signLTIToken(t, key, "k1", with("https://purl.imsglobal.org/spec/lti/claim/deployment_id", "9")), false},
+		{"anonimo", signLTIToken(t, key, "k1", with("sub", "")), false},
+		{"mal_formado", "no.es.jwt", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			claims, err := platform.verifyLaunch(c.token, "nonce-1", now)
+			if !c.valid {
+				if !errors.Is(err, errInvalidLaunch) {
+					t.Fatalf("aceptado: %+v, %v", claims, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if claims.Subject != "estudiante-7" {
+				t.Errorf("sub %q", claims.Subject)
+			}
+		})
+	}
+}
+
+// TestLTILaunchReplay comprueba que un state sirve para un solo
+// lanzamiento: reenviar el mismo formulario no abre otra entrega
+func TestLTILaunchReplay(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	platform := testLTIPlatform(key)
+	savedLTI, savedProfiles := GlobalConfig.LTI, GlobalConfig.AssignmentProfiles
+	GlobalConfig.LTI = &LTIConfig{toolURL: "https://compilador.example.edu", platforms: []*ltiPlatform{platform}}
+	GlobalConfig.AssignmentProfiles = map[string]*AssignmentProfile{
+		"tarea": {Name: "tarea", TestCases: []TestCase{{Stdin: "2\n", ExpectedOutput: "4\n"}}},
+	}
+	defer func() { GlobalConfig.LTI, GlobalConfig.AssignmentProfiles = savedLTI, savedProfiles }()
+
+	const state, nonce = "state-de-prueba", "nonce-de-prueba"
+	ltiSessions.addLogin(state, &ltiLogin{nonce: nonce, platform: platform, expires: time.Now().Add(ltiLoginTTL)})
+	form := url.Values{"state": {state}, "id_token": {signLTIToken(t, key, "k1", ltiTestClaims(time.Now(), nonce))}}
+
+	for i, want := range []int{http.StatusOK, http.StatusBadRequest} {
+		r := httptest.NewRequest(http.MethodPost, "/lti/launch", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.AddCookie(&http.Cookie{Name: ltiStateCookie + state, Value: state})
+		w := httptest.NewRecorder()
+		ltiLaunchHandler(w, r)
+		if w.Code != want {
+			t.Fatalf("envío %d: estado %d, esperado %d: %s", i+1, w.Code, want, w.Body.String())
+		}
+	}
+}
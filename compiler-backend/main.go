@@ -4,9 +4,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"os"
+	"sort"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/rs/cors"
 )
@@ -15,6 +19,238 @@ import (
 type AnalyzeRequest struct {
 	Code     string `json:"code"`
 	Language string `json:"language"`
+	// Límite de ejecución pedido por el cliente; 0 usa el valor por defecto
+	// y se acota a GlobalConfig.MaxExecutionTimeout
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+	// Con false se omite la ejecución; si no se envía se ejecuta el código
+	Execute *bool `json:"execute,omitempty"`
+	// Argumentos de la línea de comandos del programa (sys.argv[1:],
+	// process.argv.slice(2), argv en C++, os.Args[1:])
+	Args []string `json:"args,omitempty"`
+	// Variables de entorno del programa; solo las permitidas por
+	// EXECUTION_ENV_ALLOWLIST
+	Env map[string]string `json:"env,omitempty"`
+	// Archivos auxiliares escritos en el directorio de trabajo
+	Files []ProgramFile `json:"files,omitempty"`
+	// Con true se agrega el ensamblador (C++) o el bytecode (Python y
+	// JavaScript) que produce la herramienta real
+	GeneratedCode bool `json:"generatedCode,omitempty"`
+	// "dot" o "mermaid": agrega el árbol sintáctico en ese formato
+	TreeFormat string `json:"treeFormat,omitempty"`
+	// Nodos y niveles de parseTree; 0 usa PARSE_TREE_MAX_NODES y
+	// PARSE_TREE_MAX_DEPTH (ver treelimit.go)
+	TreeMaxNodes int `json:"treeMaxNodes,omitempty"`
+	TreeMaxDepth int `json:"treeMaxDepth,omitempty"`
+	// "csv" agrega tokensCsv; "textmate" completa el scope de cada token
+	TokensFormat string `json:"tokensFormat,omitempty"`
+	// Bytes de código a partir de los cuales la respuesta trae tokenCounts
+	// en lugar de tokens; 0 usa OMIT_TOKENS_OVER
+	OmitTokensOver int `json:"omitTokensOver,omitempty"`
+	// Solo los tokens de esos tipos (COMMENT, STRING...) y de ellos los
+	// tokenLimit que siguen a tokenOffset; 0 no limita (ver tokenpage.go)
+	TokenTypes  []string `json:"tokenTypes,omitempty"`
+	TokenOffset int      `json:"tokenOffset,omitempty"`
+	TokenLimit  int      `json:"tokenLimit,omitempty"`
+	// "csv", "html" o "dot": agrega la tabla de símbolos en ese formato
+	SymbolFormat string `json:"symbolFormat,omitempty"`
+	// "sarif": agrega los diagnósticos en SARIF 2.1.0; fileName es la ruta
+	// del archivo en sus resultados (main si no se indica)
+	ErrorsFormat string `json:"errorsFormat,omitempty"`
+	FileName     string `json:"fileName,omitempty"`
+	// "junit": agrega los veredictos de expectedOutput o testCases en
+	// JUnit XML
+	TestsFormat string `json:"testsFormat,omitempty"`
+	// Diagnóstico (código o nombre) -> activado; sobrescribe
+	// DISABLED_DIAGNOSTICS para esta petición
+	Diagnostics map[string]bool `json:"diagnostics,omitempty"`
+	// Diagnóstico o severidad -> "error" o "warning"; {"warning": "error"}
+	// hace que las advertencias cuenten como errores
+	SeverityOverrides map[string]string `json:"severityOverrides,omitempty"`
+	// Diagnósticos antes de detener el análisis; se acota a MAX_ERRORS
+	MaxErrors int `json:"maxErrors,omitempty"`
+	// Con true los diagnósticos idénticos se agrupan en uno con
+	// relatedPositions; si no se envía se usa GROUP_DIAGNOSTICS
+	GroupDiagnostics *bool `json:"groupDiagnostics,omitempty"`
+	// Modo juez: la salida esperada y cómo compararla (exact, trimmed,
+	// tokens o float); la respuesta trae judge con el veredicto
+	ExpectedOutput *string `json:"expectedOutput,omitempty"`
+	Compare        string  `json:"compare,omitempty"`
+	Tolerance      float64 `json:"tolerance,omitempty"`
+	// Entrada estándar del programa
+	Stdin string `json:"stdin,omitempty"`
+	// Casos de prueba: la respuesta trae testResults con el veredicto de
+	// cada uno y el puntaje; se comparan con compare y tolerance
+	TestCases []TestCase `json:"testCases,omitempty"`
+	// Idioma de los mensajes de error: "es" o "en". Sin él se usa la
+	// cabecera Accept-Language y después DEFAULT_LOCALE
+	Locale string `json:"locale,omitempty"`
+	// Línea donde se detiene la ejecución: executionResult.breakpoint trae
+	// las variables en ese punto (solo Python y JavaScript)
+	Breakpoint int `json:"breakpoint,omitempty"`
+	// Con true los números aleatorios y la hora son siempre los mismos, para
+	// calificar programas que usan rand(), random o Date.now()
+	Deterministic bool `json:"deterministic,omitempty"`
+	// Perfil de la tarea (ASSIGNMENT_PROFILES): sus requisitos se validan y
+	// sus casos de prueba reemplazan a testCases
+	AssignmentProfile string `json:"assignmentProfile,omitempty"`
+}
+
+// options traduce los campos opcionales de la petición a AnalyzeOptions;
+// la ejecución se carga al usuario p y el perfil se busca entre los suyos
+func (req AnalyzeRequest) options(p *Principal) AnalyzeOptions {
+	opts := AnalyzeOptions{
+		Principal:     p,
+		Timeout:       ExecutionTimeoutFor(req.TimeoutSeconds),
+		SkipExecution: req.Execute != nil && !*req.Execute,
+		GeneratedCode: req.GeneratedCode,
+		Args:          req.Args,
+		Env:           req.Env,
+		Files:         req.Files,
+		Diagnostics:   diagnosticOverrides(req.Diagnostics),
+
+		SeverityOverrides: severityOverrides(req.SeverityOverrides),
+		MaxErrors:         req.MaxErrors,
+		Judge:             req.judge(),
+		Stdin:             req.Stdin,
+		TestCases:         req.TestCases,
+		Breakpoint:        req.Breakpoint,
+		Deterministic:     req.Deterministic,
+	}
+	if profile := p.assignmentProfiles()[req.AssignmentProfile]; profile != nil {
+		opts.withProfile(profile)
+	}
+	return opts
+}
+
+// authorize comprueba que el usuario p pueda analizar req con su lenguaje
+// y, si pide ejecución, con su cuota (ver authorizeAnalysis)
+func (req AnalyzeRequest) authorize(p *Principal) (int, string) {
+	language := mapLanguage(req.Language)
+	if language == "" {
+		language = DetectLanguage(req.Code)
+	}
+	return authorizeAnalysis(p, language, req.Execute == nil || *req.Execute)
+}
+
+// judge devuelve las opciones del modo juez, o nil si no se envió
+// expectedOutput ni testCases
+func (req AnalyzeRequest) judge() *JudgeOptions {
+	if req.ExpectedOutput == nil && len(req.TestCases) == 0 {
+		return nil
+	}
+	judge := &JudgeOptions{Compare: req.Compare, Tolerance: req.Tolerance}
+	if req.ExpectedOutput != nil {
+		judge.ExpectedOutput = *req.ExpectedOutput
+	}
+	return judge
+}
+
+// invalidJudgeRequest devuelve el motivo por el que los campos del modo juez,
+// stdin y el perfil de la tarea del usuario p no son válidos, o "" si lo son
+func (req AnalyzeRequest) invalidJudgeRequest(p *Principal) string {
+	if msg := invalidJudge(req.Compare, req.Tolerance); msg != "" {
+		return msg
+	}
+	if req.AssignmentProfile != "" {
+		profile := p.assignmentProfiles()[req.AssignmentProfile]
+		if profile == nil {
+			return "unknown assignmentProfile " + req.AssignmentProfile
+		}
+		if len(profile.TestCases) > 0 && (req.ExpectedOutput != nil || len(req.TestCases) > 0 || req.Stdin != "") {
+			return "assignmentProfile " + req.AssignmentProfile + " has its own test cases: testCases, expectedOutput and stdin are not allowed"
+		}
+	}
+	if len(req.TestCases) > 0 && (req.ExpectedOutput != nil || req.Stdin != "") {
+		return "testCases cannot be combined with expectedOutput or stdin"
+	}
+	if msg := invalidTestCases(req.TestCases); msg != "" {
+		return msg
+	}
+	if req.Breakpoint < 0 {
+		return "breakpoint must be a line number starting at 1"
+	}
+	if req.Breakpoint > 0 && (req.ExpectedOutput != nil || len(req.TestCases) > 0) {
+		return "breakpoint cannot be combined with expectedOutput or testCases"
+	}
+	return invalidStdin([]string{req.Stdin})
+}
+
+// validate devuelve el motivo por el que req no es una petición de análisis
+// válida para el usuario p, o "" si lo es; la comparten /api/v1/analyze,
+// /api/v1/analyze/async y /api/v1/analyze/stream
+func (req AnalyzeRequest) validate(p *Principal) string {
+	if req.Code == "" {
+		return "Code is required"
+	}
+	if req.TimeoutSeconds < 0 {
+		return "timeoutSeconds must be positive"
+	}
+	if req.TreeFormat != "" && !validTreeFormat(req.TreeFormat) {
+		return "treeFormat must be dot or mermaid"
+	}
+	if msg := invalidTreeLimits(req.TreeMaxNodes, req.TreeMaxDepth); msg != "" {
+		return msg
+	}
+	if req.TokensFormat != "" && !validTokensFormat(req.TokensFormat) {
+		return "tokensFormat must be csv or textmate"
+	}
+	if req.OmitTokensOver < 0 {
+		return "omitTokensOver must be positive"
+	}
+	if msg := req.invalidTokenPage(); msg != "" {
+		return msg
+	}
+	if req.SymbolFormat != "" && !validSymbolFormat(req.SymbolFormat) {
+		return "symbolFormat must be csv, html or dot"
+	}
+	if req.ErrorsFormat != "" && !validErrorsFormat(req.ErrorsFormat) {
+		return "errorsFormat must be sarif"
+	}
+	if req.TestsFormat != "" && !validTestsFormat(req.TestsFormat) {
+		return "testsFormat must be junit"
+	}
+	if name := unknownDiagnostic(req.Diagnostics); name != "" {
+		return "diagnostics: unknown diagnostic " + name
+	}
+	if msg := invalidSeverityOverride(req.SeverityOverrides); msg != "" {
+		return msg
+	}
+	if req.MaxErrors < 0 {
+		return "maxErrors must be positive"
+	}
+	if msg := invalidArgs(req.Args); msg != "" {
+		return msg
+	}
+	if msg := invalidEnv(req.Env); msg != "" {
+		return msg
+	}
+	if msg := invalidFiles(req.Files); msg != "" {
+		return msg
+	}
+	if msg := req.invalidJudgeRequest(p); msg != "" {
+		return msg
+	}
+	if msg := invalidLocale(req.Locale); msg != "" {
+		return msg
+	}
+	return ""
+}
+
+// Respuesta de /api/v1/lex: solo la fase léxica, para resaltado de sintaxis
+type APILexResponse struct {
+	Language string             `json:"language"`
+	Tokens   []APIToken         `json:"tokens"`
+	Errors   []APICompilerError `json:"errors"`
+	// Milisegundos que tardó el análisis léxico
+	ProcessingTime float64 `json:"processingTime"`
+	// Los tokens en CSV si la petición pidió tokensFormat "csv"
+	TokensCSV string `json:"tokensCsv,omitempty"`
+	// Con el código más grande que omitTokensOver, tokens queda vacío y
+	// estos son los tokens de cada tipo
+	TokensOmitted bool           `json:"tokensOmitted,omitempty"`
+	TokenCounts   map[string]int `json:"tokenCounts,omitempty"`
+	// Tokens de los tipos de tokenTypes, si la petición pidió una página
+	TokenTotal *int `json:"tokenTotal,omitempty"`
 }
 
 type HealthResponse struct {
@@ -23,11 +259,15 @@ type HealthResponse struct {
 }
 
 type APIToken struct {
-	Type     string `json:"type"`
-	Value    string `json:"value"`
-	Line     int    `json:"line"`
-	Column   int    `json:"column"`
-	Position int    `json:"position"`
+	Type      string `json:"type"`
+	Value     string `json:"value"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndLine   int    `json:"endLine"`
+	EndColumn int    `json:"endColumn"`
+	Position  int    `json:"position"`
+	// Ámbito de TextMate (keyword.control.cpp) si se pidió tokensFormat
+	Scope string `json:"scope,omitempty"`
 }
 
 type APIParseNode struct {
@@ -36,16 +276,36 @@ type APIParseNode struct {
 	Children []APIParseNode `json:"children"`
 	Line     int            `json:"line"`
 	Column   int            `json:"column"`
+	// Solo en los nodos "more", que resumen los que no entraron
+	More *APIParseMore `json:"more,omitempty"`
 }
 
 type APISymbol struct {
-	Name     string `json:"name"`
-	Type     string `json:"type"`
-	Value    string `json:"value"`
-	Scope    string `json:"scope"`
-	Line     int    `json:"line"`
-	Column   int    `json:"column"`
-	Category string `json:"category"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Value  string `json:"value"`
+	Scope  string `json:"scope"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	// Desplazamiento en caracteres de la declaración, como en tokens y errores
+	Position   int           `json:"position"`
+	Category   string        `json:"category"`
+	References []APIPosition `json:"references"`
+	// Parámetros de las funciones, en orden
+	Parameters []APIParameter `json:"parameters,omitempty"`
+}
+
+// APIParameter es un parámetro de una función; Type se omite si no se declara
+type APIParameter struct {
+	Name string `json:"name"`
+	Type string `json:"type,omitempty"`
+}
+
+// APIPosition ubica un uso de un símbolo en el código
+type APIPosition struct {
+	Line     int `json:"line"`
+	Column   int `json:"column"`
+	Position int `json:"position"`
 }
 
 type APICompilerError struct {
@@ -55,6 +315,20 @@ type APICompilerError struct {
 	Column   int    `json:"column"`
 	Position int    `json:"position"`
 	Severity string `json:"severity"`
+	Code     string `json:"code,omitempty"`
+	Hint     string `json:"hint,omitempty"`
+	// Identificador estable del mensaje, igual en todos los idiomas; vacío
+	// si el mensaje no está en el catálogo (salida del compilador real)
+	MessageID string `json:"messageId,omitempty"`
+	// "analizador", "compilador" o "ambos"; con "ambos" compilerMessage es
+	// el mensaje del compilador real
+	Source          string `json:"source"`
+	CompilerMessage string `json:"compilerMessage,omitempty"`
+	// Declaración a la que se refiere el error (la función llamada o la
+	// variable usada como función)
+	Declaration *APIPosition `json:"declaration,omitempty"`
+	// Con groupDiagnostics, dónde más aparece el mismo diagnóstico
+	RelatedPositions []APIPosition `json:"relatedPositions,omitempty"`
 }
 
 type APIAnalysisPhase struct {
@@ -71,119 +345,420 @@ type APIAnalysisPhases struct {
 	Semantic APIAnalysisPhase `json:"semantic"`
 }
 
+// APITiming es lo que tardó cada fase en milisegundos, para graficarlo. Los
+// chequeos léxicos corren a la vez que el parser y la ejecución (con la
+// compilación) a la vez que el análisis semántico: la suma puede superar
+// totalMs. En una respuesta de la caché las fases son 0
+type APITiming struct {
+	TotalMs     float64 `json:"totalMs"`
+	LexicalMs   float64 `json:"lexicalMs"`
+	SyntaxMs    float64 `json:"syntaxMs"`
+	SemanticMs  float64 `json:"semanticMs"`
+	ExecutionMs float64 `json:"executionMs"`
+}
+
+// durationMs convierte d a milisegundos con tres decimales
+func durationMs(d time.Duration) float64 {
+	return math.Round(float64(d)/float64(time.Microsecond)) / 1000
+}
+
+func convertToAPITiming(total time.Duration, t AnalysisTiming) APITiming {
+	return APITiming{
+		TotalMs:     durationMs(total),
+		LexicalMs:   durationMs(t.Lexical),
+		SyntaxMs:    durationMs(t.Syntax),
+		SemanticMs:  durationMs(t.Semantic),
+		ExecutionMs: durationMs(t.Execution),
+	}
+}
+
+// APIExecutionResult: output es la salida combinada de siempre; las demás
+// separan la compilación de la ejecución. exitCode falta si el programa no
+// llegó a terminar por sí mismo. durationMs es el tiempo real y
+// peakMemoryBytes, userCpuMs y systemCpuMs lo que midió el kernel; faltan si
+// no se midieron
 type APIExecutionResult struct {
+	Success         bool   `json:"success"`
+	Output          string `json:"output"`
+	Error           string `json:"error,omitempty"`
+	CompileOutput   string `json:"compileOutput,omitempty"`
+	RunStdout       string `json:"runStdout,omitempty"`
+	RunStderr       string `json:"runStderr,omitempty"`
+	ExitCode        *int   `json:"exitCode,omitempty"`
+	DurationMs      int64  `json:"durationMs,omitempty"`
+	PeakMemoryBytes int64  `json:"peakMemoryBytes,omitempty"`
+	UserCPUMs       int64  `json:"userCpuMs,omitempty"`
+	SystemCPUMs     int64  `json:"systemCpuMs,omitempty"`
+	// Las variables al llegar a la línea del breakpoint; sin él si el
+	// programa no pasó por esa línea
+	Breakpoint *APITraceStep `json:"breakpoint,omitempty"`
+	// El documento listo para mostrar si el código es HTML
+	HTML *APIHTMLPreview `json:"html,omitempty"`
+}
+
+// APIJudgeResult es el veredicto del modo juez: AC, WA, TLE, RE o CE
+type APIJudgeResult struct {
+	Verdict string `json:"verdict"`
+	Compare string `json:"compare"`
+	Message string `json:"message,omitempty"`
+}
+
+// APITestCaseResult es el veredicto de un caso de prueba; stdout es lo que
+// imprimió el programa con la entrada del caso
+type APITestCaseResult struct {
+	Verdict    string `json:"verdict"`
+	Message    string `json:"message,omitempty"`
+	Points     int    `json:"points"`
+	Earned     int    `json:"earned"`
+	DurationMs int64  `json:"durationMs"`
+	ExitCode   *int   `json:"exitCode,omitempty"`
+	Stdout     string `json:"stdout,omitempty"`
+
+	PeakMemoryBytes int64 `json:"peakMemoryBytes,omitempty"`
+	UserCPUMs       int64 `json:"userCpuMs,omitempty"`
+	SystemCPUMs     int64 `json:"systemCpuMs,omitempty"`
+}
+
+// APITestsResult son los veredictos de los casos y el puntaje; verdict es el
+// del primer caso que no pasó, o AC
+type APITestsResult struct {
+	Verdict  string              `json:"verdict"`
+	Compare  string              `json:"compare"`
+	Score    int                 `json:"score"`
+	MaxScore int                 `json:"maxScore"`
+	Cases    []APITestCaseResult `json:"cases"`
+}
+
+// APIGeneratedCode es la salida de g++ -S, python3 -m dis o
+// node --print-bytecode; si la herramienta falla, Error explica por qué
+type APIGeneratedCode struct {
+	Kind    string `json:"kind,omitempty"`
+	Tool    string `json:"tool,omitempty"`
 	Success bool   `json:"success"`
 	Output  string `json:"output"`
 	Error   string `json:"error,omitempty"`
 }
 
+// APIExecutability indica si el programa se puede ejecutar; Reasons explica
+// por qué no (errores con su línea, lenguaje desactivado, herramienta
+// faltante) en el idioma de la petición
+type APIExecutability struct {
+	Allowed bool     `json:"allowed"`
+	Reasons []string `json:"reasons"`
+}
+
 type APIAnalyzeResponse struct {
-	Language        string               `json:"language"`
-	Tokens          []APIToken           `json:"tokens"`
-	ParseTree       []APIParseNode       `json:"parseTree"`
-	SymbolTable     []APISymbol          `json:"symbolTable"`
-	Errors          []APICompilerError   `json:"errors"`
-	CanExecute      bool                 `json:"canExecute"`
-	AnalysisPhases  APIAnalysisPhases    `json:"analysisPhases"`
-	ExecutionResult *APIExecutionResult  `json:"executionResult,omitempty"`
-	ProcessingTime  string               `json:"processingTime"`
+	Language    string             `json:"language"`
+	Tokens      []APIToken         `json:"tokens"`
+	ParseTree   []APIParseNode     `json:"parseTree"`
+	SymbolTable []APISymbol        `json:"symbolTable"`
+	Errors      []APICompilerError `json:"errors"`
+	// true si parseTree superó treeMaxNodes o treeMaxDepth y tiene nodos
+	// "more"
+	ParseTreeTruncated bool `json:"parseTreeTruncated,omitempty"`
+	// Igual que executability.allowed; se conserva por compatibilidad
+	CanExecute      bool                `json:"canExecute"`
+	Executability   APIExecutability    `json:"executability"`
+	AnalysisPhases  APIAnalysisPhases   `json:"analysisPhases"`
+	ExecutionResult *APIExecutionResult `json:"executionResult,omitempty"`
+	// Milisegundos del análisis completo, igual que timing.totalMs
+	ProcessingTime float64   `json:"processingTime"`
+	Timing         APITiming `json:"timing"`
+	// true si la respuesta se tomó de la caché de resultados
+	Cached bool `json:"cached,omitempty"`
+	// Solo si la petición pidió generatedCode
+	GeneratedCode *APIGeneratedCode `json:"generatedCode,omitempty"`
+	// El árbol sintáctico en DOT o Mermaid si la petición pidió treeFormat
+	Tree string `json:"tree,omitempty"`
+	// Los tokens en CSV si la petición pidió tokensFormat "csv"
+	TokensCSV string `json:"tokensCsv,omitempty"`
+	// Con el código más grande que omitTokensOver, tokens queda vacío y
+	// estos son los tokens de cada tipo
+	TokensOmitted bool           `json:"tokensOmitted,omitempty"`
+	TokenCounts   map[string]int `json:"tokenCounts,omitempty"`
+	// Tokens de los tipos de tokenTypes, si la petición pidió una página
+	TokenTotal *int `json:"tokenTotal,omitempty"`
+	// La tabla de símbolos en CSV, HTML o DOT si la petición pidió symbolFormat
+	Symbols string `json:"symbols,omitempty"`
+	// Los diagnósticos en SARIF si la petición pidió errorsFormat "sarif"
+	Sarif *SARIFLog `json:"sarif,omitempty"`
+	// Los veredictos en JUnit XML si la petición pidió testsFormat "junit"
+	TestsJUnit string `json:"testsJunit,omitempty"`
+	// Veredicto si la petición envió expectedOutput
+	Judge *APIJudgeResult `json:"judge,omitempty"`
+	// Veredictos y puntaje si la petición envió testCases
+	TestResults *APITestsResult `json:"testResults,omitempty"`
+	// Líneas, comentarios y complejidad de cada función (ver metrics.go)
+	Metrics *APIMetrics `json:"metrics,omitempty"`
 }
 
 // Convertir tipos internos a tipos de API
-func convertToAPITokens(tokens []Token, originalCode string) []APIToken {
+func convertToAPITokens(tokens []Token, src *sourceIndex) []APIToken {
 	apiTokens := make([]APIToken, len(tokens))
-	
+
 	for i, token := range tokens {
-		line, col := calculateLineColumnFromPosition(token.Start, originalCode)
-		
 		apiTokens[i] = APIToken{
-			Type:     strings.ToUpper(token.Type.String()),
-			Value:    token.Lexeme,
-			Line:     line,
-			Column:   col,
-			Position: token.Start,
+			Type:      strings.ToUpper(token.Type.String()),
+			Value:     token.Lexeme,
+			Line:      token.Line,
+			Column:    token.Column,
+			EndLine:   token.EndLine,
+			EndColumn: token.EndColumn,
+			Position:  src.offset(token.Line, token.Column),
 		}
 	}
 	return apiTokens
 }
 
-func calculateLineColumnFromPosition(pos int, code string) (int, int) {
+// sourceIndex traduce posiciones en bytes a línea, columna y desplazamiento
+// en caracteres. Se construye una vez por petición: recorrer el código desde
+// el inicio para cada nodo, símbolo o error es O(n) por posición
+type sourceIndex struct {
+	code       string
+	lineStarts []int // byte donde empieza cada línea
+	runeStarts []int // carácter donde empieza cada línea
+}
+
+func newSourceIndex(code string) *sourceIndex {
+	lineStarts := computeLineStarts(code)
+	runeStarts := make([]int, len(lineStarts))
+	for i := 1; i < len(lineStarts); i++ {
+		runeStarts[i] = runeStarts[i-1] + utf8.RuneCountInString(code[lineStarts[i-1]:lineStarts[i]])
+	}
+	return &sourceIndex{code: code, lineStarts: lineStarts, runeStarts: runeStarts}
+}
+
+// lineColumn devuelve la línea y columna (base 1, columnas en caracteres)
+// de una posición en bytes
+func (s *sourceIndex) lineColumn(pos int) (int, int) {
+	if pos > len(s.code) {
+		pos = len(s.code)
+	}
 	if pos <= 0 {
 		return 1, 1
 	}
-	
-	line := 1
-	column := 1
-	
-	for i, char := range code {
-		if i >= pos {
-			break
-		}
-		if char == '\n' {
-			line++
-			column = 1
-		} else {
-			column++
-		}
+	line := sort.Search(len(s.lineStarts), func(i int) bool { return s.lineStarts[i] > pos })
+	return line, utf8.RuneCountInString(s.code[s.lineStarts[line-1]:pos]) + 1
+}
+
+// offset convierte una línea y columna en desplazamiento en caracteres: el
+// frontend cuenta posiciones por carácter, y 'ñ' o un emoji ocupan varios bytes
+func (s *sourceIndex) offset(line, column int) int {
+	if line < 1 || line > len(s.runeStarts) {
+		return 0
 	}
-	
-	return line, column
+	return s.runeStarts[line-1] + column - 1
 }
 
-func convertToAPIParseNodes(nodes []ParseNode) []APIParseNode {
+// position devuelve línea, columna y desplazamiento en caracteres de una
+// posición en bytes
+func (s *sourceIndex) position(pos int) (int, int, int) {
+	line, column := s.lineColumn(pos)
+	return line, column, s.offset(line, column)
+}
+
+func convertToAPIParseNodes(nodes []ParseNode, src *sourceIndex) []APIParseNode {
 	apiNodes := make([]APIParseNode, len(nodes))
 	for i, node := range nodes {
+		line, column := src.lineColumn(node.Pos)
+		nodeType := node.Kind
+		if nodeType == "" {
+			nodeType = "node"
+		}
 		apiNodes[i] = APIParseNode{
-			Type:     "node",
+			Type:     nodeType,
 			Value:    node.Label,
-			Children: convertToAPIParseNodes(node.Children),
-			Line:     1, // Se calcularía basándose en tokens
-			Column:   1,
+			Children: convertToAPIParseNodes(node.Children, src),
+			Line:     line,
+			Column:   column,
 		}
 	}
 	return apiNodes
 }
 
-func convertToAPISymbols(symbols []Symbol, originalCode string) []APISymbol {
+func convertToAPISymbols(symbols []Symbol, src *sourceIndex) []APISymbol {
 	apiSymbols := make([]APISymbol, len(symbols))
 	for i, symbol := range symbols {
-		line, column := calculateLineColumnFromPosition(symbol.Pos, originalCode)
+		line, column, offset := src.position(symbol.Pos)
 		
+		symbolType := symbol.Type
+		if symbolType == "" {
+			symbolType = symbol.Kind
+		}
+		scope := symbol.Scope
+		if scope == "" {
+			scope = "global"
+		}
 		apiSymbols[i] = APISymbol{
-			Name:     symbol.Name,
-			Type:     symbol.Kind,
-			Value:    "",
-			Scope:    "global",
-			Line:     line,
-			Column:   column,
-			Category: symbol.Kind,
+			Name:       symbol.Name,
+			Type:       symbolType,
+			Value:      symbol.Value,
+			Scope:      scope,
+			Line:       line,
+			Column:     column,
+			Position:   offset,
+			Category:   symbol.Kind,
+			References: make([]APIPosition, len(symbol.References)),
+		}
+		for j, pos := range symbol.References {
+			refLine, refColumn, refOffset := src.position(pos)
+			apiSymbols[i].References[j] = APIPosition{Line: refLine, Column: refColumn, Position: refOffset}
+		}
+		for _, p := range symbol.Params {
+			apiSymbols[i].Parameters = append(apiSymbols[i].Parameters, APIParameter{Name: p.name, Type: p.typ})
 		}
 	}
 	return apiSymbols
 }
 
-func convertToAPIErrors(errors []CompilerError, originalCode string) []APICompilerError {
+func convertToAPIErrors(errors []CompilerError, src *sourceIndex, locale string) []APICompilerError {
 	apiErrors := make([]APICompilerError, len(errors))
 	
 	for i, err := range errors {
-		line, column := calculateLineColumnFromPosition(err.Pos, originalCode)
+		line, column, offset := src.position(err.Pos)
+		message, messageID := localizeMessage(err.Message, locale)
+		compilerMessage, _ := localizeMessage(err.CompilerMessage, locale)
 		
 		apiErrors[i] = APICompilerError{
-			Type:     err.Type, // Usar el campo Type directamente
-			Message:  err.Message,
-			Line:     line,
-			Column:   column,
-			Position: err.Pos,
-			Severity: err.Severity,
+			Type:            err.Type, // Usar el campo Type directamente
+			Message:         message,
+			Line:            line,
+			Column:          column,
+			Position:        offset,
+			Severity:        err.Severity,
+			Code:            err.Code,
+			Hint:            errorHint(err),
+			MessageID:       messageID,
+			Source:          errorSource(err),
+			CompilerMessage: compilerMessage,
+		}
+		if err.Declaration != nil {
+			declLine, declColumn, declOffset := src.position(*err.Declaration)
+			apiErrors[i].Declaration = &APIPosition{Line: declLine, Column: declColumn, Position: declOffset}
 		}
 	}
 	return apiErrors
 }
 
+// errorSource devuelve quién informó err; "" es del análisis estático
+func errorSource(err CompilerError) string {
+	if err.Source == "" {
+		return "analizador"
+	}
+	return err.Source
+}
+
+// buildAPIResponse convierte el resultado interno del compilador al formato de
+// la API, con los mensajes de error en locale
+func buildAPIResponse(result AnalyzeResponse, src *sourceIndex, locale string) APIAnalyzeResponse {
+	apiResponse := APIAnalyzeResponse{
+		Language:    result.Language,
+		Tokens:      convertToAPITokens(result.Tokens, src),
+		ParseTree:   convertToAPIParseNodes(result.ParseTree, src),
+		SymbolTable: convertToAPISymbols(result.SymbolTable, src),
+		Errors:      convertToAPIErrors(result.Errors, src, locale),
+		CanExecute:  result.Executability.Allowed,
+		Executability: convertToAPIExecutability(result.Executability, locale),
+		AnalysisPhases: APIAnalysisPhases{
+			Lexical: APIAnalysisPhase{
+				Completed:   result.AnalysisPhases.Lexical.Completed,
+				TokensFound: &result.AnalysisPhases.Lexical.TokensFound,
+				ErrorsFound: result.AnalysisPhases.Lexical.ErrorsFound,
+			},
+			Syntax: APIAnalysisPhase{
+				Completed:      result.AnalysisPhases.Syntax.Completed,
+				NodesGenerated: &result.AnalysisPhases.Syntax.NodesGenerated,
+				ErrorsFound:    result.AnalysisPhases.Syntax.ErrorsFound,
+			},
+			Semantic: APIAnalysisPhase{
+				Completed:    result.AnalysisPhases.Semantic.Completed,
+				SymbolsFound: &result.AnalysisPhases.Semantic.SymbolsFound,
+				ErrorsFound:  result.AnalysisPhases.Semantic.ErrorsFound,
+			},
+		},
+		ProcessingTime: durationMs(result.ProcessingTime),
+		Timing:         convertToAPITiming(result.ProcessingTime, result.Timing),
+		Cached:         result.Cached,
+	}
+
+	// Agregar resultado de ejecución si existe
+	if result.ExecutionResult != nil {
+		apiResponse.ExecutionResult = convertToAPIExecutionResult(result.ExecutionResult)
+	}
+	if j := result.Judge; j != nil {
+		apiResponse.Judge = &APIJudgeResult{Verdict: j.Verdict, Compare: j.Compare, Message: j.Message}
+	}
+	if t := result.Tests; t != nil {
+		apiResponse.TestResults = convertToAPITestsResult(t)
+	}
+	if result.Metrics != nil {
+		apiResponse.Metrics = convertToAPIMetrics(*result.Metrics, src)
+	}
+	if gen := result.GeneratedCode; gen != nil {
+		apiResponse.GeneratedCode = &APIGeneratedCode{Kind: gen.Kind, Tool: gen.Tool, Success: gen.Ok, Output: gen.Output}
+		if !gen.Ok {
+			apiResponse.GeneratedCode.Error = gen.Output
+		}
+	}
+
+	return apiResponse
+}
+
+func convertToAPITestsResult(t *TestsResult) *APITestsResult {
+	tests := &APITestsResult{Verdict: t.Verdict, Compare: t.Compare, Score: t.Score, MaxScore: t.MaxScore, Cases: make([]APITestCaseResult, len(t.Cases))}
+	for i, c := range t.Cases {
+		tests.Cases[i] = APITestCaseResult{
+			Verdict:    c.Verdict,
+			Message:    c.Message,
+			Points:     c.Points,
+			Earned:     c.Earned,
+			DurationMs: c.DurationMs,
+			ExitCode:   c.ExitCode,
+			Stdout:     c.Stdout,
+
+			PeakMemoryBytes: c.PeakMemory,
+			UserCPUMs:       c.UserCPUMs,
+			SystemCPUMs:     c.SystemCPUMs,
+		}
+	}
+	return tests
+}
+
+// convertToAPIExecutability traduce los motivos a locale
+func convertToAPIExecutability(ex Executability, locale string) APIExecutability {
+	reasons := make([]string, len(ex.Reasons))
+	for i, reason := range ex.Reasons {
+		reasons[i], _ = localizeMessage(reason, locale)
+	}
+	return APIExecutability{Allowed: ex.Allowed, Reasons: reasons}
+}
+
+func convertToAPIExecutionResult(res *ExecutionResult) *APIExecutionResult {
+	apiResult := &APIExecutionResult{
+		Success:       res.Ok,
+		Output:        res.Output,
+		CompileOutput: res.CompileOutput,
+		RunStdout:     res.RunStdout,
+		RunStderr:     res.RunStderr,
+		ExitCode:      res.ExitCode,
+		DurationMs:    res.DurationMs,
+
+		PeakMemoryBytes: res.PeakMemory,
+		UserCPUMs:       res.UserCPUMs,
+		SystemCPUMs:     res.SystemCPUMs,
+		Breakpoint:      res.Breakpoint,
+		HTML:            res.HTML,
+	}
+	if !res.Ok {
+		apiResult.Error = res.Output
+	}
+	return apiResult
+}
+
 // Handlers HTTP
 func healthHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
@@ -198,69 +773,181 @@ func healthHandler(w http.ResponseWriter, r *http.Request) {
 
 func analyzeHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
 	var req AnalyzeRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		invalidJSON(w)
+		return
+	}
+	id, msg := requestID(r)
+	if msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if executions.active(id) {
+		httpError(w, "An execution with this request id is already running", http.StatusConflict)
 		return
 	}
+	w.Header().Set(requestIDHeader, id)
 
 	// Validar entrada
-	if req.Code == "" {
-		http.Error(w, "Code is required", http.StatusBadRequest)
+	principal := principalFrom(r)
+	if msg := req.validate(principal); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
 		return
 	}
+	req.Locale = requestLocale(req.Locale, r)
+	if status, msg := req.authorize(principal); status != 0 {
+		rejectAnalysis(w, status, msg)
+		return
+	}
+
+	apiResponse := analyzeRequest(req, id, principal, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(apiResponse)
+}
 
+// analyzeRequest analiza una petición ya validada, la registra en el
+// historial y arma la respuesta de /api/v1/analyze; la usan también los
+// trabajos asíncronos y el WebSocket, que pasa onPhase para recibir cada
+// fase al completarse. rid es su X-Request-ID y principal el usuario al que
+// se carga la ejecución
+func analyzeRequest(req AnalyzeRequest, rid string, principal *Principal, onPhase PhaseCallback) APIAnalyzeResponse {
 	// Mapear lenguaje del frontend al backend
 	language := mapLanguage(req.Language)
-	
+
 	// Ejecutar análisis usando el compilador existente
-	result := AnalyzeCode(req.Code, language)
+	opts := req.options(principal)
+	opts.RequestID = rid
+	var result AnalyzeResponse
+	if onPhase != nil {
+		// Las fases se transmiten mientras se analizan: no sirve la caché
+		result = AnalyzeCodeWithProgress(req.Code, language, opts, onPhase)
+	} else {
+		result = AnalyzeCodeCached(req.Code, language, opts)
+	}
+	recordAnalysis(req.Code, result, principal)
 
 	// Convertir resultado interno a formato de API
-	apiResponse := APIAnalyzeResponse{
-		Language:    result.Language,
-		Tokens:      convertToAPITokens(result.Tokens, req.Code),
-		ParseTree:   convertToAPIParseNodes(result.ParseTree),
-		SymbolTable: convertToAPISymbols(result.SymbolTable, req.Code),
-		Errors:      convertToAPIErrors(result.Errors, req.Code),
-		CanExecute:  result.CanExecute,
-		AnalysisPhases: APIAnalysisPhases{
-			Lexical: APIAnalysisPhase{
-				Completed:   result.AnalysisPhases.Lexical.Completed,
-				TokensFound: &result.AnalysisPhases.Lexical.TokensFound,
-				ErrorsFound: result.AnalysisPhases.Lexical.ErrorsFound,
-			},
-			Syntax: APIAnalysisPhase{
-				Completed:      result.AnalysisPhases.Syntax.Completed,
-				NodesGenerated: &result.AnalysisPhases.Syntax.NodesGenerated,
-				ErrorsFound:    result.AnalysisPhases.Syntax.ErrorsFound,
-			},
-			Semantic: APIAnalysisPhase{
-				Completed:    result.AnalysisPhases.Semantic.Completed,
-				SymbolsFound: &result.AnalysisPhases.Semantic.SymbolsFound,
-				ErrorsFound:  result.AnalysisPhases.Semantic.ErrorsFound,
-			},
-		},
-		ProcessingTime: result.ProcessingTime.String(),
+	src := newSourceIndex(req.Code)
+	apiResponse := buildAPIResponse(result, src, req.Locale)
+	if limits := treeLimitsFor(req.TreeMaxNodes, req.TreeMaxDepth); limits.active() {
+		apiResponse.ParseTree, apiResponse.ParseTreeTruncated = limits.convert(result.ParseTree, "", 0, src)
 	}
-
-	// Agregar resultado de ejecución si existe
-	if result.ExecutionResult != nil {
-		apiResponse.ExecutionResult = &APIExecutionResult{
-			Success: result.ExecutionResult.Ok,
-			Output:  result.ExecutionResult.Output,
+	if req.TreeFormat != "" {
+		apiResponse.Tree = renderTree(result.ParseTree, req.TreeFormat)
+	}
+	if req.omitsTokens() {
+		apiResponse.Tokens = []APIToken{}
+		apiResponse.TokensOmitted, apiResponse.TokenCounts = true, countTokens(result.Tokens)
+	} else {
+		if req.pagesTokens() {
+			page, total := req.pageTokens(apiResponse.Tokens)
+			apiResponse.Tokens, apiResponse.TokenTotal = page, &total
+		}
+		apiResponse.TokensCSV = exportTokens(apiResponse.Tokens, result.Language, req.TokensFormat)
+	}
+	if req.SymbolFormat != "" {
+		scopes := symbolScopes(result.SymbolTable, result.ParseTree)
+		apiResponse.Symbols = exportSymbols(apiResponse.SymbolTable, scopes, result.Language, req.SymbolFormat, req.Locale)
+	}
+	if req.groupsDiagnostics() {
+		apiResponse.Errors = groupDiagnostics(apiResponse.Errors)
+	}
+	if req.ErrorsFormat == errorsFormatSARIF {
+		uri := req.FileName
+		if uri == "" {
+			uri = "main"
 		}
-		if !result.ExecutionResult.Ok {
-			apiResponse.ExecutionResult.Error = result.ExecutionResult.Output
+		apiResponse.Sarif = buildSARIF([]sarifFile{{uri: uri, errors: apiResponse.Errors}}, req.Locale)
+	}
+	if req.TestsFormat == testsFormatJUnit && hasVerdicts(apiResponse) {
+		name := req.FileName
+		if name == "" {
+			name = "main"
 		}
+		apiResponse.TestsJUnit = junitReport([]junitSubmission{{name: name, language: result.Language, response: apiResponse}}, req.Locale)
+	}
+	return apiResponse
+}
+
+// lexHandler es el camino rápido para clientes que solo necesitan tokens:
+// no construye el árbol, no hace análisis semántico ni ejecuta el código.
+func lexHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req AnalyzeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w)
+		return
+	}
+	if req.Code == "" {
+		httpError(w, "Code is required", http.StatusBadRequest)
+		return
+	}
+	if req.TokensFormat != "" && !validTokensFormat(req.TokensFormat) {
+		httpError(w, "tokensFormat must be csv or textmate", http.StatusBadRequest)
+		return
+	}
+	if req.OmitTokensOver < 0 {
+		httpError(w, "omitTokensOver must be positive", http.StatusBadRequest)
+		return
+	}
+	if msg := req.invalidTokenPage(); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if name := unknownDiagnostic(req.Diagnostics); name != "" {
+		httpError(w, "diagnostics: unknown diagnostic "+name, http.StatusBadRequest)
+		return
+	}
+	if msg := invalidLocale(req.Locale); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	start := time.Now()
+	language := mapLanguage(req.Language)
+	if language == "" {
+		language = DetectLanguage(req.Code)
+	}
+	if status, msg := authorizeAnalysis(principalFrom(r), language, false); status != 0 {
+		rejectAnalysis(w, status, msg)
+		return
+	}
+	tokens, errors := LexicalAnalysis(req.Code, language)
+	errors = filterDiagnostics(errors, language, diagnosticOverrides(req.Diagnostics))
+	src := newSourceIndex(req.Code)
+
+	response := APILexResponse{
+		Language:       language,
+		Tokens:         []APIToken{},
+		Errors:         convertToAPIErrors(errors, src, requestLocale(req.Locale, r)),
+		ProcessingTime: durationMs(time.Since(start)),
+	}
+	if req.groupsDiagnostics() {
+		response.Errors = groupDiagnostics(response.Errors)
+	}
+	if req.omitsTokens() {
+		response.TokensOmitted, response.TokenCounts = true, countTokens(tokens)
+	} else {
+		response.Tokens = convertToAPITokens(tokens, src)
+		if req.pagesTokens() {
+			page, total := req.pageTokens(response.Tokens)
+			response.Tokens, response.TokenTotal = page, &total
+		}
+		response.TokensCSV = exportTokens(response.Tokens, language, req.TokensFormat)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(apiResponse)
+	json.NewEncoder(w).Encode(response)
 }
 
 func mapLanguage(frontendLang string) string {
@@ -269,8 +956,26 @@ func mapLanguage(frontendLang string) string {
 		return "cpp"
 	case "javascript", "js":
 		return "javascript"
+	case "typescript", "ts":
+		return "typescript"
 	case "python", "py":
 		return "python"
+	case "go", "golang":
+		return "go"
+	case "css":
+		return "css"
+	case "html", "htm":
+		return "html"
+	case "tsql", "t-sql", "sql":
+		return "tsql"
+	case "plsql", "pl/sql":
+		return "plsql"
+	case "pascal", "pas":
+		return "pascal"
+	case "kotlin", "kt":
+		return "kotlin"
+	case "swift":
+		return "swift"
 	case "", "auto":
 		return ""
 	default:
@@ -278,24 +983,128 @@ func mapLanguage(frontendLang string) string {
 	}
 }
 
+// isAllowedOrigin indica si origin está en GlobalConfig.AllowedOrigins, que
+// se aplica a CORS y al WebSocket de streaming. "*" permite cualquier
+// origen y "https://*.ejemplo.edu" cualquier subdominio de ejemplo.edu
+func isAllowedOrigin(origin string) bool {
+	for _, allowed := range GlobalConfig.AllowedOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+		prefix, suffix, wildcard := strings.Cut(allowed, "*")
+		if !wildcard || len(origin) <= len(prefix)+len(suffix) {
+			continue
+		}
+		if strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+			// El comodín cubre solo nombres de host: no puede agregar un
+			// puerto, una ruta ni un usuario
+			sub := origin[len(prefix) : len(origin)-len(suffix)]
+			if !strings.ContainsAny(sub, ":/@") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func main() {
+	InitConfig()
+	// Modo CLI: `compiler-backend analyze ...` analiza archivos sin servidor
+	// y `compiler-backend tokens ...` imprime sus tokens
+	if len(os.Args) > 1 {
+		os.Exit(runCLI(os.Args[1:], os.Stdout, os.Stderr))
+	}
+	if GlobalConfig.HistoryDB != "none" {
+		h, err := openHistory(GlobalConfig.HistoryDB)
+		if err != nil {
+			log.Fatalf("No se pudo abrir el historial %s: %v", GlobalConfig.HistoryDB, err)
+		}
+		history = h
+		if auth, err = openAuth(h.db); err != nil {
+			log.Fatalf("No se pudieron crear las tablas de claves en %s: %v", GlobalConfig.HistoryDB, err)
+		}
+	}
+	if GlobalConfig.AuthRequired && auth == nil && GlobalConfig.JWTSecret == "" {
+		log.Fatalf("AUTH_REQUIRED necesita claves de API (HISTORY_DB) o JWT_SECRET")
+	}
+
+	if GlobalConfig.RedisURL != "" {
+		client, err := newRedisClient(GlobalConfig.RedisURL)
+		if err == nil {
+			err = client.ping()
+		}
+		if err != nil {
+			log.Fatalf("No se pudo conectar a Redis: %v", err)
+		}
+		jobs = newRedisJobQueue(client, GlobalConfig.AsyncWorkers)
+		sharedCache = &redisResultCache{client: client}
+	} else {
+		jobs = newJobQueue(GlobalConfig.AsyncQueueSize, GlobalConfig.AsyncWorkers)
+	}
+	// La primera búsqueda de herramientas tarda; se hace ya para que /readyz
+	// responda rápido desde el primer sondeo
+	go toolchains.snapshot(false)
+	if GlobalConfig.GitHubWebhookSecret != "" {
+		githubPushes = newGitHubQueue(GlobalConfig.AsyncQueueSize)
+	}
+
 	// Configurar rutas
 	mux := http.NewServeMux()
 	
-	// Rutas de la API
-	mux.HandleFunc("/api/v1/health", healthHandler)
-	mux.HandleFunc("/api/v1/analyze", analyzeHandler)
+	// Rutas de la API. Las que pueden ejecutar código se limitan por IP; los
+	// cambios de una sesión no, porque llegan con cada edición del editor.
+	// Todas salvo las de estado y openapi.json identifican al usuario (ver auth.go);
+	// las vistas previas publicadas y los análisis compartidos se abren sin
+	// credenciales (ver previews.go y share.go)
+	limiter := newIPRateLimiter(GlobalConfig.RateLimitPerMinute)
+	mux.HandleFunc(apiPrefix+"/health", healthHandler)
+	mux.HandleFunc(apiPrefix+"/healthz", healthHandler)
+	mux.HandleFunc(apiPrefix+"/readyz", readyHandler)
+	mux.HandleFunc(apiPrefix+"/analyze", requireAuth(limiter.limit(analyzeHandler)))
+	mux.HandleFunc(apiPrefix+"/analyze/async", requireAuth(limiter.limit(analyzeAsyncHandler)))
+	mux.HandleFunc(apiPrefix+"/jobs/", requireAuth(jobHandler))
+	mux.HandleFunc(apiPrefix+"/lex", requireAuth(lexHandler))
+	mux.HandleFunc(apiPrefix+"/highlight", requireAuth(highlightHandler))
+	mux.HandleFunc(apiPrefix+"/analyze/stream", requireAuth(limiter.limit(analyzeStreamHandler)))
+	mux.HandleFunc(apiPrefix+"/analyze/tree", requireAuth(analyzeTreeHandler))
+	mux.HandleFunc(apiPrefix+"/analyze/tree/expand", requireAuth(treeExpandHandler))
+	mux.HandleFunc(apiPrefix+"/analyze/diff", requireAuth(treeDiffHandler))
+	mux.HandleFunc(apiPrefix+"/report", requireAuth(limiter.limit(reportHandler)))
+	mux.HandleFunc(apiPrefix+"/sessions", requireAuth(limiter.limit(sessionsHandler)))
+	mux.HandleFunc(apiPrefix+"/sessions/", requireAuth(sessionHandler))
+	mux.HandleFunc(apiPrefix+"/history", requireAuth(historyHandler))
+	mux.HandleFunc(apiPrefix+"/executions", requireAuth(executionsHandler))
+	mux.HandleFunc(apiPrefix+"/executions/", requireAuth(executionHandler))
+	mux.HandleFunc(apiPrefix+"/toolchains", requireAuth(toolchainsHandler))
+	mux.HandleFunc(apiPrefix+"/hover", requireAuth(hoverHandler))
+	mux.HandleFunc(apiPrefix+"/compare", requireAuth(limiter.limit(compareHandler)))
+	mux.HandleFunc(apiPrefix+"/trace", requireAuth(limiter.limit(traceHandler)))
+	mux.HandleFunc(apiPrefix+"/execute/stream", requireAuth(limiter.limit(executeStreamHandler)))
+	mux.HandleFunc(apiPrefix+"/execute/interactive", requireAuth(limiter.limit(interactiveHandler)))
+	mux.HandleFunc(apiPrefix+"/previews", requireAuth(limiter.limit(previewsHandler)))
+	mux.HandleFunc(apiPrefix+"/previews/", requireAuth(previewHandler))
+	mux.HandleFunc(apiPrefix+"/share", requireAuth(limiter.limit(createShareHandler)))
+	mux.HandleFunc(apiPrefix+"/share/", shareHandler)
+	mux.HandleFunc(apiPrefix+"/me", requireAuth(meHandler))
+	mux.HandleFunc(apiPrefix+"/admin/config", requireAuth(adminConfigHandler))
+	mux.HandleFunc(apiPrefix+"/webhooks/github", githubWebhookHandler)
+	mux.HandleFunc(apiPrefix+"/openapi.json", openAPIHandler)
+	mux.HandleFunc("/preview/", servePreview)
+	mux.HandleFunc("/lti/login", ltiLoginHandler)
+	mux.HandleFunc("/lti/launch", ltiLaunchHandler)
+	mux.HandleFunc("/lti/submit", limiter.limit(ltiSubmitHandler))
+	mux.HandleFunc("/lti/jwks", ltiJWKSHandler)
+	mux.HandleFunc("/", notFoundHandler)
 	
 	// Configurar CORS para permitir conexiones desde el frontend
 	c := cors.New(cors.Options{
-		AllowedOrigins: []string{
-			"http://localhost:3000", // Next.js dev
-			"http://localhost:3001", // Alternativo
-			"https://localhost:3000", // HTTPS local
-		},
+		AllowOriginFunc: isAllowedOrigin,
 		AllowedMethods: []string{
 			http.MethodGet,
 			http.MethodPost,
+			http.MethodPut,
+			http.MethodPatch,
+			http.MethodDelete,
 			http.MethodOptions,
 		},
 		AllowedHeaders: []string{
@@ -305,11 +1114,18 @@ func main() {
 			"Accept-Encoding",
 			"X-CSRF-Token",
 			"Authorization",
+			"X-API-Key",
+			workspaceHeader,
+			requestIDHeader,
 		},
+		ExposedHeaders:   []string{requestIDHeader},
 		AllowCredentials: true,
 	})
 
-	handler := c.Handler(mux)
+	// recoverPanics va por dentro de CORS para que el 500 llegue al
+	// frontend con sus encabezados, y assignRequestID antes que él para que
+	// el error incluya el id (ver apierror.go)
+	handler := c.Handler(assignRequestID(recoverPanics(limitRequest(mux))))
 
 	// Obtener puerto del entorno o usar 8080 por defecto
 	port := os.Getenv("PORT")
@@ -320,7 +1136,31 @@ func main() {
 	fmt.Printf("🚀 Servidor del compilador iniciado en puerto %s\n", port)
 	fmt.Printf("📋 Health check: http://localhost:%s/api/v1/health\n", port)
 	fmt.Printf("🔍 Análisis: http://localhost:%s/api/v1/analyze\n", port)
-	fmt.Printf("🌐 CORS habilitado para: http://localhost:3000\n")
+	fmt.Printf("📬 Análisis asíncrono: http://localhost:%s/api/v1/analyze/async (%d workers, cola de %d)\n", port, GlobalConfig.AsyncWorkers, GlobalConfig.AsyncQueueSize)
+	fmt.Printf("🔤 Solo tokens: http://localhost:%s/api/v1/lex\n", port)
+	fmt.Printf("📡 Streaming: ws://localhost:%s/api/v1/analyze/stream\n", port)
+	fmt.Printf("🗂️  Sesiones: http://localhost:%s/api/v1/sessions\n", port)
+	fmt.Printf("📈 Historial: http://localhost:%s/api/v1/history\n", port)
+	fmt.Printf("🛑 Ejecuciones en curso: http://localhost:%s/api/v1/executions\n", port)
+	fmt.Printf("🧰 Herramientas: http://localhost:%s/api/v1/toolchains\n", port)
+	fmt.Printf("📘 OpenAPI: http://localhost:%s/api/v1/openapi.json\n", port)
+	fmt.Printf("🌐 CORS habilitado para: %s\n", strings.Join(GlobalConfig.AllowedOrigins, ", "))
+	if GlobalConfig.RedisURL != "" {
+		fmt.Printf("🧵 Cola y caché compartidas en Redis\n")
+	}
+	fmt.Printf("⚙️  Backend de ejecución: %s\n", GlobalConfig.ExecutionBackend)
+	if GlobalConfig.JSEngine == EngineEmbedded {
+		fmt.Printf("🧩 JavaScript con el intérprete integrado (máximo %d pasos)\n", GlobalConfig.MaxInterpreterSteps)
+	}
+	fmt.Printf("⏱️  Timeout de ejecución: %s (máximo %s)\n", GlobalConfig.ExecutionTimeout, GlobalConfig.MaxExecutionTimeout)
+	fmt.Printf("🚦 Límites: %d análisis/min por IP, %d ejecuciones simultáneas\n", GlobalConfig.RateLimitPerMinute, GlobalConfig.MaxConcurrentExecutions)
+	processes := "procesos sin límite"
+	if n := enforcedProcessLimit(); n > 0 {
+		processes = fmt.Sprintf("%d procesos", n)
+	} else if GlobalConfig.MaxProcesses > 0 {
+		processes += " (MAX_PROCESSES requiere CGROUP_PARENT o no correr como root)"
+	}
+	fmt.Printf("🧱 Por proceso: %d MB de memoria, %s, %d bytes de salida\n", GlobalConfig.MaxMemoryMB, processes, GlobalConfig.MaxOutputBytes)
 	
 	log.Fatal(http.ListenAndServe(":"+port, handler))
 } 
\ No newline at end of file
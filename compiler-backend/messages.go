@@ -0,0 +1,659 @@
+package main
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ─────────────────────── Mensajes de diagnóstico (es/en) ──────────────────
+//
+// Los analizadores escriben sus mensajes en español, con fmt.Sprintf sobre
+// plantillas fijas. messageCatalog reúne esas plantillas con un
+// identificador estable (messageId en la respuesta) y su traducción. Para
+// servir un mensaje en inglés se reconoce la plantilla española que lo
+// produjo, se extraen sus argumentos y se arma la plantilla inglesa.
+//
+// Los argumentos pueden ser a su vez texto en español: el contexto de un
+// token esperado ("para cerrar el bloque") o lo que se encontró ("fin de
+// archivo"). Esas frases están en messagePhrases, sin identificador, y se
+// traducen de la misma forma. Un mensaje que no coincide con ninguna
+// plantilla, como la salida de un compilador real, se devuelve tal cual
+// salvo su prefijo ("Error sintáctico: ").
+//
+// Un mensaje nuevo debe agregarse al catálogo; sin él queda en español.
+
+// Idiomas en los que se sirven los mensajes; el primero es el original
+var supportedLocales = []string{"es", "en"}
+
+// messageText es una plantilla en cada idioma. En la inglesa %[n]s toma el
+// argumento n de la española cuando el orden cambia
+type messageText struct {
+	ID string
+	ES string
+	EN string
+}
+
+// Prefijos de fase; los del compilador real van con mayúscula
+var messagePrefixes = []messageText{
+	{"", "Error léxico: ", "Lexical error: "},
+	{"", "Error Léxico: ", "Lexical error: "},
+	{"", "Error sintáctico: ", "Syntax error: "},
+	{"", "Error Sintáctico: ", "Syntax error: "},
+	{"", "Error semántico: ", "Semantic error: "},
+	{"", "Error Semántico: ", "Semantic error: "},
+	{"", "Error de Ejecución: ", "Runtime error: "},
+	{"", "Advertencia de flujo: ", "Flow warning: "},
+	{"", "Posible error: ", "Possible bug: "},
+	{"", "Política de seguridad: ", "Security policy: "},
+	{"", "Regla del curso: ", "Course rule: "},
+	{"", "Error: ", "Error: "},
+}
+
+var messageCatalog = []messageText{
+	// Léxicos
+	{"unterminated-string-start", "String no cerrado que comienza con '%s'", "Unterminated string starting with '%s'"},
+	{"unterminated-string-line", "String no cerrado en línea %d", "Unterminated string on line %d"},
+	{"unterminated-multiline-string", "String de múltiples líneas no cerrado en línea %d", "Unterminated multi-line string on line %d"},
+	{"unterminated-sql-string", "String no cerrado que comienza con '%s'; en SQL la comilla se escribe ''", "Unterminated string starting with '%s'; in SQL a quote is written ''"},
+	{"unterminated-pascal-string", "String no cerrado; en Pascal la comilla dentro de una cadena se escribe ''", "Unterminated string; in Pascal a quote inside a string is written ''"},
+	{"unterminated-char-literal", "Caracter literal no cerrado que comienza con '%s'", "Unterminated character literal starting with '%s'"},
+	{"unterminated-template-start", "Template literal no cerrado que comienza con '%s'", "Unterminated template literal starting with '%s'"},
+	{"unterminated-template-line", "Template literal no cerrado en línea %d", "Unterminated template literal on line %d"},
+	{"unterminated-quoted-name", "Nombre entre comillas no cerrado que comienza con '%s'", "Unterminated quoted name starting with '%s'"},
+	{"unterminated-bracketed-name", "Nombre entre corchetes no cerrado; falta ']'", "Unterminated bracketed name; missing ']'"},
+	{"unterminated-block-comment", "Comentario de bloque no cerrado en línea %d", "Unterminated block comment on line %d"},
+	{"unterminated-html-comment", "Comentario HTML no cerrado en línea %d", "Unterminated HTML comment on line %d"},
+	{"unterminated-comment", "Comentario '%s' no cerrado en línea %d", "Unterminated comment '%s' on line %d"},
+	{"unopened-comment", "Caracter '}' sin un '{' que abra el comentario", "Character '}' without a '{' opening the comment"},
+	{"malformed-number-letters", "Número mal formado '%s' - contiene letras", "Malformed number '%s' - contains letters"},
+	{"malformed-number-suffix", "Número mal formado '%s' - número seguido de letras", "Malformed number '%s' - number followed by letters"},
+	{"malformed-decimal", "Número decimal mal formado '%s' - múltiples puntos decimales", "Malformed decimal number '%s' - multiple decimal points"},
+	{"number-prefix-without-digits", "El prefijo '%s' debe ir seguido de dígitos: '%s'", "Prefix '%s' must be followed by digits: '%s'"},
+	{"invalid-digit-for-base", "Dígito '%s' no válido en base %d: '%s'", "Invalid digit '%s' in base %d: '%s'"},
+	{"misplaced-digit-separator", "Separador de dígitos '%s' mal ubicado en '%s'", "Misplaced digit separator '%s' in '%s'"},
+	{"python-leading-zero", "Los enteros no pueden empezar con 0 en Python: '%s'; para octal use el prefijo 0o", "Integers cannot start with 0 in Python: '%s'; use the 0o prefix for octal"},
+	{"exponent-without-digits", "Exponente sin dígitos en '%s'", "Exponent without digits in '%s'"},
+	{"invalid-number-suffix", "Sufijo '%s' no válido en el número '%s'", "Invalid suffix '%s' in number '%s'"},
+	{"malformed-number", "Número mal formado '%s'", "Malformed number '%s'"},
+	{"char-prefix-needs-number", "'%s' debe ir seguido de un número ('#65', '$FF')", "'%s' must be followed by a number ('#65', '$FF')"},
+	{"invalid-character", "Caracter '%s' no es válido en %s", "Character '%s' is not valid in %s"},
+	{"invalid-character", "Caracter '%s' no válido en %s", "Character '%s' is not valid in %s"},
+	{"invalid-character-python-decorator", "Caracter '@' inesperado en Python (no es un decorador válido)", "Unexpected character '@' in Python (not a valid decorator)"},
+	{"invalid-character-js-comment", "Caracter '#' no es válido en JavaScript (use // para comentarios)", "Character '#' is not valid in JavaScript (use // for comments)"},
+	{"invalid-character-pascal-quote", "Caracter '\"' no es válido en Pascal (las cadenas van entre comillas simples)", "Character '\"' is not valid in Pascal (strings use single quotes)"},
+	{"unexpected-character-in", "Caracter '%s' inesperado en %s", "Unexpected character '%s' in %s"},
+	{"unexpected-sequence-in", "Caracter o secuencia inesperada '%s' en %s", "Unexpected character or sequence '%s' in %s"},
+	{"unexpected-sequence", "Caracter o secuencia inesperada '%s'", "Unexpected character or sequence '%s'"},
+	{"unknown-escape", "Secuencia de escape desconocida '%s'", "Unknown escape sequence '%s'"},
+	{"incomplete-escape", "Secuencia de escape incompleta '%s'", "Incomplete escape sequence '%s'"},
+	{"empty-char-literal", "Caracter literal vacío", "Empty character literal"},
+	{"multichar-char-literal", "Caracter literal con %d caracteres %s; las cadenas van entre comillas dobles", "Character literal with %d characters %s; strings use double quotes"},
+	{"non-ascii-bytes", "Los bytes literales solo admiten caracteres ASCII: '%s'", "Byte literals only allow ASCII characters: '%s'"},
+	{"fstring-unclosed-expression", "f-string: falta '}' para cerrar la expresión", "f-string: missing '}' closing the expression"},
+	{"fstring-single-brace", "f-string: '}' sin '{'; para una llave literal use '}}'", "f-string: '}' without '{'; for a literal brace use '}}'"},
+	{"empty-interpolation", "Interpolación vacía '%s'", "Empty interpolation '%s'"},
+	{"mixed-indentation", "Indentación mixta (tabs y espacios) en línea %d", "Mixed indentation (tabs and spaces) on line %d"},
+
+	// Sintácticos: delimitadores
+	{"unmatched-parenthesis", "Paréntesis de cierre sin apertura correspondiente", "Closing parenthesis without a matching opening one"},
+	{"unmatched-brace", "Llave de cierre sin apertura correspondiente", "Closing brace without a matching opening one"},
+	{"unmatched-bracket", "Corchete de cierre sin apertura correspondiente", "Closing bracket without a matching opening one"},
+	{"duplicate-semicolon", "Punto y coma duplicado", "Duplicate semicolon"},
+	{"unclosed-parentheses", "%d paréntesis sin cerrar", "%d unclosed parentheses"},
+	{"unclosed-braces", "%d llaves sin cerrar", "%d unclosed braces"},
+	{"unclosed-brackets", "%d corchetes sin cerrar", "%d unclosed brackets"},
+	{"empty-program", "No se encontraron tokens válidos", "No valid tokens were found"},
+
+	// Sintácticos: tokens esperados
+	{"expected-token", "Se esperaba '%s' %s, se encontró %s", "Expected '%s' %s, found %s"},
+	{"expected-identifier", "Se esperaba un identificador %s, se encontró %s", "Expected an identifier %s, found %s"},
+	{"expected-identifier-before-define", "Se esperaba un identificador a la izquierda de ':='", "Expected an identifier on the left of ':='"},
+	{"expected-name", "Se esperaba el nombre %s, se encontró %s", "Expected the name %s, found %s"},
+	{"expected-member-name", "Se esperaba un nombre de miembro después de '%s'", "Expected a member name after '%s'"},
+	{"expected-expression", "Se esperaba una expresión, se encontró %s", "Expected an expression, found %s"},
+	{"expected-condition", "Se esperaba una expresión como condición de '%s'", "Expected an expression as the condition of '%s'"},
+	{"expected-type", "Se esperaba un tipo", "Expected a type"},
+	{"expected-type-found", "Se esperaba un tipo, se encontró %s", "Expected a type, found %s"},
+	{"expected-statement", "Se esperaba una sentencia, se encontró %s", "Expected a statement, found %s"},
+	{"expected-statement-start", "Se esperaba el comienzo de una sentencia, se encontró %s", "Expected the start of a statement, found %s"},
+	{"expected-statement-end", "Se esperaba el fin de la sentencia, se encontró %s", "Expected the end of the statement, found %s"},
+	{"expected-semicolon-or-newline", "Se esperaba ';' o fin de línea %s, se encontró %s", "Expected ';' or end of line %s, found %s"},
+	{"expected-case-or-default", "Se esperaba 'case' o 'default' dentro de '%s'", "Expected 'case' or 'default' inside '%s'"},
+	{"expected-catch-or-finally", "Se esperaba 'catch' o 'finally' después del bloque 'try'", "Expected 'catch' or 'finally' after the 'try' block"},
+	{"expected-except-or-finally", "Se esperaba 'except' o 'finally' después del bloque 'try'", "Expected 'except' or 'finally' after the 'try' block"},
+	{"expected-except-or-finally", "Se esperaba 'except' o 'finally' en el try, se encontró %s", "Expected 'except' or 'finally' in the try, found %s"},
+	{"expected-index", "Se esperaba un índice entre '[' y ']'", "Expected an index between '[' and ']'"},
+	{"expected-assignment-operator", "Se esperaba ':=' o '=' después de la lista de expresiones", "Expected ':=' or '=' after the expression list"},
+	{"expected-const-value", "Se esperaba '=' con el valor de la constante", "Expected '=' with the constant's value"},
+	{"expected-composite-comma", "Se esperaba ',' antes del salto de línea en el literal compuesto", "Expected ',' before the line break in the composite literal"},
+	{"expected-import-path", "Se esperaba la ruta del paquete entre comillas, se encontró %s", "Expected the package path in quotes, found %s"},
+	{"expected-type-params-close", "Se esperaba '>' al cerrar los parámetros de tipo", "Expected '>' closing the type parameters"},
+	{"expected-interface-member", "Se esperaba el nombre de un miembro de la interfaz, se encontró %s", "Expected the name of an interface member, found %s"},
+	{"expected-enum-member", "Se esperaba un miembro de la enumeración, se encontró %s", "Expected an enum member, found %s"},
+	{"expected-selector", "Se esperaba un selector, se encontró %s", "Expected a selector, found %s"},
+	{"expected-property-name", "Se esperaba el nombre de una propiedad, se encontró %s", "Expected a property name, found %s"},
+	{"expected-attribute-value", "Se esperaba el valor del atributo '%s', se encontró %s", "Expected the value of attribute '%s', found %s"},
+	{"expected-tag-end", "Se esperaba '>' para terminar la etiqueta %s, se encontró %s", "Expected '>' to end the tag %s, found %s"},
+	{"expected-async-target", "Se esperaba 'def', 'for' o 'with' después de 'async'", "Expected 'def', 'for' or 'with' after 'async'"},
+	{"expected-indented-block", "Se esperaba un bloque indentado después de ':'", "Expected an indented block after ':'"},
+	{"expected-decorated-definition", "Se esperaba una definición después del decorador", "Expected a definition after the decorator"},
+	{"expected-def-or-class", "Se esperaba 'def' o 'class' después del decorador", "Expected 'def' or 'class' after the decorator"},
+	{"expected-begin-or-declaration", "Se esperaba 'begin' o una declaración, se encontró %s", "Expected 'begin' or a declaration, found %s"},
+	{"expected-declaration-after", "Se esperaba una declaración después de '%s', se encontró %s", "Expected a declaration after '%s', found %s"},
+	{"expected-label", "Se esperaba una etiqueta, se encontró %s", "Expected a label, found %s"},
+	{"expected-goto-label", "Se esperaba una etiqueta después de 'goto', se encontró %s", "Expected a label after 'goto', found %s"},
+	{"expected-class-end", "Se esperaba 'end' al final de la clase, se encontró %s", "Expected 'end' at the end of the class, found %s"},
+	{"expected-pascal-assignment", "Se esperaba ':=' en la asignación; '=' compara valores en Pascal", "Expected ':=' in the assignment; '=' compares values in Pascal"},
+	{"expected-for-direction", "Se esperaba 'to' o 'downto' en el for, se encontró %s", "Expected 'to' or 'downto' in the for, found %s"},
+	{"expected-alias", "Se esperaba un alias después de AS, se encontró %s", "Expected an alias after AS, found %s"},
+	{"expected-assignment-after", "Se esperaba '=' después de '%s', se encontró %s", "Expected '=' after '%s', found %s"},
+	{"expected-insert-source", "Se esperaba VALUES o SELECT en el INSERT, se encontró %s", "Expected VALUES or SELECT in the INSERT, found %s"},
+	{"expected-join-condition", "Se esperaba ON con la condición de %s, se encontró %s", "Expected ON with the condition of %s, found %s"},
+	{"expected-table-column-separator", "Se esperaba ',' o ')' en la definición de la tabla '%s', se encontró %s", "Expected ',' or ')' in the definition of table '%s', found %s"},
+	{"expected-parameter-name", "Se esperaba el nombre de un parámetro, se encontró %s", "Expected a parameter name, found %s"},
+	{"expected-declare-variable", "Se esperaba una variable (@nombre) después de DECLARE, se encontró %s", "Expected a variable (@name) after DECLARE, found %s"},
+	{"expected-declaration-or-begin", "Se esperaba una declaración o BEGIN, se encontró %s", "Expected a declaration or BEGIN, found %s"},
+
+	// Sintácticos: otros
+	{"unexpected-token-in-expression", "Token inesperado '%s' en una expresión", "Unexpected token '%s' in an expression"},
+	{"unexpected-keyword-in-expression", "Palabra reservada '%s' inesperada en una expresión", "Unexpected reserved word '%s' in an expression"},
+	{"unexpected-token-at-statement-end", "Token inesperado '%s' al final de la sentencia", "Unexpected token '%s' at the end of the statement"},
+	{"unexpected-token-after-decorator", "Token inesperado después del decorador", "Unexpected token after the decorator"},
+	{"unexpected-indent", "Indentación inesperada", "Unexpected indentation"},
+	{"unexpected-indent-start", "Indentación inesperada al inicio del programa", "Unexpected indentation at the start of the program"},
+	{"orphan-clause", "'%s' sin una sentencia compuesta correspondiente", "'%s' without a matching compound statement"},
+	{"uninitialized-constant", "La constante '%s' debe inicializarse", "Constant '%s' must be initialized"},
+	{"go-statement-needs-call", "La expresión de '%s' debe ser una llamada a función", "The expression in '%s' must be a function call"},
+	{"assignment-count-mismatch", "La asignación tiene %d variable(s) pero %d valor(es)", "Assignment has %d variable(s) but %d value(s)"},
+	{"mixed-named-parameters", "Se mezclan parámetros con nombre y sin nombre", "Named and unnamed parameters are mixed"},
+	{"missing-parameter-type", "Falta el tipo del parámetro '%s'", "Missing type for parameter '%s'"},
+	{"semicolon-before-else", "No se permite ';' antes de 'else': termina la sentencia if", "';' is not allowed before 'else': it ends the if statement"},
+	{"plsql-default-assignment", "En PL/SQL el valor inicial de '%s' se asigna con ':=', no con '='", "In PL/SQL the initial value of '%s' is assigned with ':=', not '='"},
+	{"missing-column-type", "Falta el tipo de la columna '%s'", "Missing type for column '%s'"},
+	{"unknown-constraint", "Restricción desconocida %s en la columna '%s'", "Unknown constraint %s on column '%s'"},
+	{"declaration-outside-rule", "La declaración '%s' está fuera de una regla; falta el selector y '{'", "Declaration '%s' is outside a rule; the selector and '{' are missing"},
+	{"property-without-value", "La propiedad '%s' no tiene valor", "Property '%s' has no value"},
+	{"void-element-closed", "<%s> es un elemento vacío y no lleva etiqueta de cierre </%s>", "<%s> is a void element and has no closing tag </%s>"},
+	{"unexpected-closing-tag", "La etiqueta de cierre </%s> no tiene una etiqueta de apertura <%s>", "Closing tag </%s> has no opening tag <%s>"},
+	{"tag-closed-early", "La etiqueta <%s> no se cerró antes de </%s>", "Tag <%s> was not closed before </%s>"},
+	{"unclosed-tag", "La etiqueta <%s> nunca se cerró", "Tag <%s> was never closed"},
+	{"self-closing-element", "<%s/> no cierra el elemento en HTML; usa <%s></%s>", "<%s/> does not close the element in HTML; use <%s></%s>"},
+	{"content-outside-tag", "Contenido inesperado '%s' fuera de una etiqueta", "Unexpected content '%s' outside a tag"},
+
+	// Semánticos
+	{"redeclared-variable", "Variable '%s' ya fue declarada anteriormente en posición %d", "Variable '%s' was already declared at position %d"},
+	{"redeclared-identifier", "'%s' ya fue declarado anteriormente en posición %d", "'%s' was already declared at position %d"},
+	{"undeclared-variable", "Variable '%s' no fue declarada", "Variable '%s' was not declared"},
+	{"undeclared-variable", "Variable '%s' no fue declarada en este lote", "Variable '%s' was not declared in this batch"},
+	{"undeclared-identifier", "Identificador '%s' no fue declarado", "Identifier '%s' was not declared"},
+	{"undeclared-function", "Función '%s' no fue declarada", "Function '%s' was not declared"},
+	{"unused-variable", "Variable '%s' fue declarada pero nunca utilizada", "Variable '%s' is declared but never used"},
+	{"reserved-identifier", "'%s' es una palabra reservada y no puede usarse como identificador", "'%s' is a reserved word and cannot be used as an identifier"},
+	{"annotated-type-mismatch", "La variable '%s' está anotada como '%s' pero se le asigna un valor de tipo '%s'", "Variable '%s' is annotated as '%s' but is assigned a value of type '%s'"},
+	{"assignment-type-mismatch", "No se puede asignar un valor de tipo '%s' a la variable '%s' de tipo '%s'", "Cannot assign a value of type '%s' to variable '%s' of type '%s'"},
+	{"assignment-type-mismatch", "No se puede usar un valor de tipo '%s' como '%s' en la asignación a '%s'", "Cannot use a value of type '%s' as '%s' in the assignment to '%s'"},
+	{"float-to-integer-assignment", "No se puede asignar un valor de tipo 'float64' a la variable '%s' de tipo entero", "Cannot assign a value of type 'float64' to integer variable '%s'"},
+	{"narrowing-conversion", "Conversión implícita de 'double' a 'int' en '%s': se pierde la parte decimal", "Implicit conversion from 'double' to 'int' in '%s': the fractional part is lost"},
+	{"modulo-needs-integers", "El operador '%%' requiere operandos enteros, se recibió '%s' y '%s'", "Operator '%%' requires integer operands, got '%s' and '%s'"},
+	{"modulo-undefined", "El operador '%%' no está definido para '%s'", "Operator '%%' is not defined for '%s'"},
+	{"invalid-operands", "Operandos inválidos para '%s': '%s' y '%s'", "Invalid operands for '%s': '%s' and '%s'"},
+	{"unsupported-operands", "Tipos de operandos no soportados para '%s': '%s' y '%s'", "Unsupported operand types for '%s': '%s' and '%s'"},
+	{"incompatible-operands", "Tipos incompatibles en la operación '%s': '%s' y '%s'", "Incompatible types in operation '%s': '%s' and '%s'"},
+	{"string-literal-sum", "No se pueden sumar dos literales de cadena; use std::string", "Two string literals cannot be added; use std::string"},
+	{"string-arithmetic", "Operación aritmética '%s' con un string: el resultado puede ser NaN", "Arithmetic operation '%s' on a string: the result may be NaN"},
+	{"string-unary", "Operador '%s' aplicado a un string: el resultado puede ser NaN", "Operator '%s' applied to a string: the result may be NaN"},
+	{"invalid-unary", "Operador unario '%s' inválido para el tipo '%s'", "Unary operator '%s' is not valid for type '%s'"},
+	{"argument-count", "La función '%s' espera %s argumento(s) pero recibió %d", "Function '%s' expects %s argument(s) but received %d"},
+	{"argument-type-mismatch", "El argumento %d de '%s' es de tipo '%s' pero el parámetro '%s' es de tipo '%s'", "Argument %d of '%s' has type '%s' but parameter '%s' has type '%s'"},
+	{"unknown-header", "Cabecera '<%s>' desconocida (¿quiso decir '<%s>'?)", "Unknown header '<%s>' (did you mean '<%s>'?)"},
+	{"unknown-header", "Cabecera '<%s>' desconocida: no es de la biblioteca estándar de C ni de C++", "Unknown header '<%s>': not part of the C or C++ standard library"},
+	{"unknown-module", "Módulo '%s' desconocido (¿quiso decir '%s'?)", "Unknown module '%s' (did you mean '%s'?)"},
+	{"unknown-module", "El módulo '%s' no es de la biblioteca estándar de Python; debe estar instalado para ejecutar el programa", "Module '%s' is not part of the Python standard library; it must be installed to run the program"},
+	{"unknown-module", "El módulo '%s' no es un módulo integrado de Node.js; debe instalarse con npm para ejecutar el programa", "Module '%s' is not a Node.js built-in module; it must be installed with npm to run the program"},
+	{"unknown-package", "Paquete '%s' desconocido (¿quiso decir '%s'?)", "Unknown package '%s' (did you mean '%s'?)"},
+	{"unknown-package", "El paquete '%s' no existe en la biblioteca estándar de Go", "Package '%s' does not exist in the Go standard library"},
+	{"assignment-in-condition", "Asignación '=' en la condición del %s: se le asigna un valor a '%s' en lugar de compararlo. Use '==' para comparar, o paréntesis dobles si la asignación es intencional", "Assignment '=' in the %s condition: '%s' is assigned a value instead of being compared. Use '==' to compare, or double parentheses if the assignment is intentional"},
+	{"identity-comparison", "'%s' compara si son el mismo objeto, no si tienen el mismo valor: con el literal %s use '%s'", "'%s' checks whether both are the same object, not whether they have the same value: with the literal %s use '%s'"},
+	{"none-comparison", "Compare con None usando '%s' en lugar de '%s': None es un único objeto y '%s' puede redefinirse con __eq__", "Compare with None using '%s' instead of '%s': None is a single object and '%s' can be overridden with __eq__"},
+	{"switch-fallthrough", "El caso anterior no termina con 'break' y sigue ejecutando el código de '%s'. Agregue 'break;' al final del caso anterior, o un comentario // fallthrough si es intencional", "The previous case does not end with 'break' and goes on to run the code of '%s'. Add 'break;' at the end of the previous case, or a // fallthrough comment if it is intentional"},
+	{"language-not-allowed", "La tarea '%s' se entrega en %s, no en %s", "Assignment '%s' must be written in %s, not %s"},
+	{"missing-function", "La tarea '%s' pide definir la función %s", "Assignment '%s' requires defining the function %s"},
+	{"missing-function", "La función '%s' debe recibir %d parámetro(s), como en %s, pero recibe %d", "Function '%s' must take %d parameter(s), as in %s, but it takes %d"},
+	{"banned-function", "La tarea '%s' no permite usar '%s'", "Assignment '%s' does not allow using '%s'"},
+	{"c-string-comparison", "'%s' entre cadenas de C compara sus direcciones de memoria, no su texto. Use strcmp(%s, %s) %s 0 o guarde las cadenas en std::string", "'%s' between C strings compares their memory addresses, not their text. Use strcmp(%s, %s) %s 0 or store the strings in std::string"},
+	{"not-callable", "'%s' es una variable de tipo '%s' y no se puede llamar como función", "'%s' is a variable of type '%s' and cannot be called as a function"},
+	{"unknown-member", "El tipo '%s' no tiene la propiedad o método '%s'", "Type '%s' has no property or method '%s'"},
+	{"division-by-zero", "División entre cero: el divisor de '%s' siempre vale 0", "Division by zero: the divisor of '%s' is always 0"},
+	{"integer-overflow", "Desbordamiento de entero: la operación '%s' excede el rango de 'int'; el resultado queda truncado en %d", "Integer overflow: operation '%s' exceeds the range of 'int'; the result is truncated to %d"},
+	{"negative-to-unsigned", "El valor %d es negativo y '%s' es de tipo '%s': se convierte en un número positivo muy grande", "Value %d is negative and '%s' has type '%s': it becomes a very large positive number"},
+	{"value-out-of-range", "El valor %d no cabe en el tipo '%s' de '%s' (rango %d a %d)", "Value %d does not fit in type '%s' of '%s' (range %d to %d)"},
+	{"integer-literal-too-large", "El literal entero %s es demasiado grande para cualquier tipo entero", "Integer literal %s is too large for any integer type"},
+	{"unreachable-code", "Código inalcanzable: esta sentencia nunca se ejecuta", "Unreachable code: this statement is never executed"},
+	{"missing-return", "La función '%s' puede terminar sin devolver un valor", "Function '%s' may end without returning a value"},
+	{"infinite-loop", "Bucle infinito: la condición siempre es verdadera y el cuerpo no tiene break, return ni salida del programa", "Infinite loop: the condition is always true and the body has no break, return or program exit"},
+	{"possible-infinite-loop", "Posible bucle infinito: la condición depende de '%s' pero no se modifica dentro del ciclo", "Possible infinite loop: the condition depends on '%s' but it is not modified inside the loop"},
+	{"unknown-property", "Propiedad CSS desconocida '%s'", "Unknown CSS property '%s'"},
+	{"unknown-property", "Propiedad CSS desconocida '%s' (¿quiso decir '%s'?)", "Unknown CSS property '%s' (did you mean '%s'?)"},
+	{"undeclared-custom-property", "Propiedad personalizada '%s' no fue declarada en esta hoja de estilos", "Custom property '%s' is not declared in this stylesheet"},
+	{"duplicate-property", "La propiedad '%s' se repite en el mismo bloque; solo se aplica la última", "Property '%s' is repeated in the same block; only the last one applies"},
+	{"empty-rule", "Bloque de reglas vacío", "Empty rule block"},
+	{"duplicate-attribute", "El atributo '%s' se repite en <%s>", "Attribute '%s' is repeated in <%s>"},
+	{"duplicate-id", "El id '%s' se repite; debe ser único en el documento", "The id '%s' is repeated; it must be unique in the document"},
+	{"unknown-id-reference", "El id '%s' referenciado en %s no existe en el documento", "The id '%s' referenced in %s does not exist in the document"},
+	{"missing-attribute", "Falta el atributo '%s' en <%s>", "Missing attribute '%s' in <%s>"},
+	{"deprecated-element", "El elemento <%s> es obsoleto en HTML5; usa %s", "The <%s> element is obsolete in HTML5; use %s"},
+	{"redeclared-column", "La columna '%s' ya fue declarada en la tabla '%s' en posición %d", "Column '%s' was already declared in table '%s' at position %d"},
+	{"unknown-table", "La tabla '%s' no existe (¿quiso decir '%s'?)", "Table '%s' does not exist (did you mean '%s'?)"},
+	{"undeclared-table", "La tabla '%s' no está declarada en el script", "Table '%s' is not declared in the script"},
+	{"unknown-table-qualifier", "'%s' no es una tabla ni un alias del FROM (¿quiso decir '%s'?)", "'%s' is not a table or alias in the FROM clause (did you mean '%s'?)"},
+	{"unknown-table-qualifier", "'%s' no es una tabla ni un alias del FROM (%s)", "'%s' is not a table or alias in the FROM clause (%s)"},
+	{"unknown-column", "La columna '%s' no existe en '%s' (¿quiso decir '%s'?)", "Column '%s' does not exist in '%s' (did you mean '%s'?)"},
+	{"unknown-column", "La columna '%s' no existe en '%s'", "Column '%s' does not exist in '%s'"},
+	{"insert-value-count", "El INSERT en '%s' indica %d columna(s) pero la fila tiene %d valor(es)", "The INSERT into '%s' lists %d column(s) but the row has %d value(s)"},
+
+	// Política de seguridad
+	{"policy-shell-command", "'%s' ejecuta comandos del sistema", "'%s' runs system commands"},
+	{"policy-network-access", "'%s' accede a la red", "'%s' accesses the network"},
+	{"policy-file-write", "'%s' escribe en '%s', fuera del directorio de trabajo", "'%s' writes to '%s', outside the working directory"},
+	{"policy-busy-loop", "Ciclo infinito sin pausa: ocupa toda la CPU mientras se ejecuta", "Infinite loop without a pause: it keeps the CPU busy while it runs"},
+
+	// Motivos por los que no se ejecuta (ver executability.go y toolchains.go)
+	{"lexical-error-at", "Error léxico en la línea %d", "Lexical error on line %d"},
+	{"syntax-error-at", "Error sintáctico en la línea %d", "Syntax error on line %d"},
+	{"semantic-error-at", "Error semántico en la línea %d", "Semantic error on line %d"},
+	{"error-at", "Error en la línea %d", "Error on line %d"},
+	{"policy-blocks-line", "La política de seguridad bloquea la línea %d (%s)", "The security policy blocks line %d (%s)"},
+	{"no-executor", "No hay un ejecutor para %s", "There is no executor for %s"},
+	{"docker-unsupported", "El ejecutor Docker no soporta %s", "The Docker executor does not support %s"},
+	{"missing-toolchain", "Falta en el servidor: %s", "Missing on the server: %s"},
+	{"language-disabled", "La ejecución de %s está desactivada en el servidor", "Execution of %s is disabled on the server"},
+	{"simulated-execution", "La ejecución real está desactivada: la salida es simulada", "Real execution is disabled: the output is simulated"},
+
+	// Compilador real y límites
+	{"runtime-error", "%s (runtime error %d)", "%s (runtime error %d)"},
+	{"too-many-errors", "Demasiados errores: se muestran los primeros %d y se detuvo el análisis", "Too many errors: showing the first %d and the analysis stopped"},
+}
+
+// messagePhrases son los fragmentos que los mensajes reciben como argumento
+var messagePhrases = []messageText{
+	// Lo que se encontró en lugar del token esperado
+	{"", "fin de archivo", "end of file"},
+	{"", "fin de línea", "end of line"},
+	{"", "el final del código", "the end of the code"},
+
+	// Argumentos de La función '%s' espera %s argumento(s)
+	{"", "al menos %d", "at least %d"},
+	{"", "entre %d y %d", "between %d and %d"},
+
+	// Alternativas a los elementos obsoletos de HTML
+	{"", "%s o %s", "%s or %s"},
+	{"", "%s con %s", "%s with %s"},
+	{"", "contenido normal", "normal content"},
+
+	// Errores en tiempo de ejecución de fpc
+	{"", "Archivo no encontrado", "File not found"},
+	{"", "Archivo no abierto", "File not open"},
+	{"", "Formato numérico inválido al leer", "Invalid numeric format on read"},
+	{"", "División por cero", "Division by zero"},
+	{"", "Valor fuera de rango", "Value out of range"},
+	{"", "Desbordamiento de pila", "Stack overflow"},
+	{"", "Memoria insuficiente", "Out of memory"},
+	{"", "Puntero inválido", "Invalid pointer"},
+	{"", "Operación de punto flotante inválida", "Invalid floating point operation"},
+	{"", "Desbordamiento aritmético", "Arithmetic overflow"},
+	{"", "Violación de acceso (puntero nil o liberado)", "Access violation (nil or freed pointer)"},
+
+	// Contexto de un token esperado
+	{"", "al cerrar %s", "closing %s"},
+	{"", "al cerrar 'catch'", "closing 'catch'"},
+	{"", "al cerrar el conjunto", "closing the set"},
+	{"", "al cerrar el diccionario", "closing the dictionary"},
+	{"", "al cerrar el encabezado de '%s'", "closing the '%s' header"},
+	{"", "al cerrar el literal compuesto", "closing the composite literal"},
+	{"", "al cerrar el objeto", "closing the object"},
+	{"", "al cerrar el tipo", "closing the type"},
+	{"", "al cerrar el índice", "closing the index"},
+	{"", "al cerrar la aserción de tipo", "closing the type assertion"},
+	{"", "al cerrar la condición de '%s'", "closing the '%s' condition"},
+	{"", "al cerrar la constante del registro", "closing the record constant"},
+	{"", "al cerrar la declaración '%s'", "closing the '%s' declaration"},
+	{"", "al cerrar la enumeración", "closing the enumeration"},
+	{"", "al cerrar la expresión", "closing the expression"},
+	{"", "al cerrar la lista de '%s'", "closing the '%s' list"},
+	{"", "al cerrar la lista de argumentos", "closing the argument list"},
+	{"", "al cerrar la lista de parámetros", "closing the parameter list"},
+	{"", "al cerrar la lista", "closing the list"},
+	{"", "al cerrar los argumentos de tipo", "closing the type arguments"},
+	{"", "al cerrar los parámetros del programa", "closing the program parameters"},
+	{"", "al cerrar los parámetros", "closing the parameters"},
+	{"", "al cerrar los índices del arreglo", "closing the array indices"},
+	{"", "al comienzo de la consulta", "at the start of the query"},
+	{"", "al comienzo del bloque", "at the start of the block"},
+	{"", "al final de %s", "at the end of %s"},
+	{"", "al final de la cláusula uses", "at the end of the uses clause"},
+	{"", "al final de la declaración", "at the end of the declaration"},
+	{"", "al final de la sentencia", "at the end of the statement"},
+	{"", "al final del bloque", "at the end of the block"},
+	{"", "al final del encabezado", "at the end of the header"},
+	{"", "al final del registro", "at the end of the record"},
+	{"", "antes de '%s'", "before '%s'"},
+	{"", "antes de la consulta de la vista", "before the view query"},
+	{"", "antes de la fila de valores", "before the row of values"},
+	{"", "antes de la lista de columnas", "before the column list"},
+	{"", "antes de la subconsulta", "before the subquery"},
+	{"", "antes de las columnas de la tabla", "before the table columns"},
+	{"", "antes de los argumentos", "before the arguments"},
+	{"", "antes del cuerpo del ciclo", "before the loop body"},
+	{"", "antes del cuerpo del procedimiento", "before the procedure body"},
+	{"", "como variable del for", "as the for variable"},
+	{"", "después de '%s'", "after '%s'"},
+	{"", "después de %s", "after %s"},
+	{"", "después de la colección del for", "after the for collection"},
+	{"", "después de la condición del %s", "after the %s condition"},
+	{"", "después de la constante '%s'", "after constant '%s'"},
+	{"", "después de la declaración '%s'", "after the '%s' declaration"},
+	{"", "después de la declaración de la función", "after the function declaration"},
+	{"", "después de la declaración", "after the declaration"},
+	{"", "después de la definición de '%s'", "after the definition of '%s'"},
+	{"", "después de la enumeración", "after the enumeration"},
+	{"", "después de la expresión del case", "after the case expression"},
+	{"", "después de la firma de la función", "after the function signature"},
+	{"", "después de la firma de índice", "after the index signature"},
+	{"", "después de la firma del método", "after the method signature"},
+	{"", "después de la función", "after the function"},
+	{"", "después de la propiedad '%s'", "after property '%s'"},
+	{"", "después de la tabla del UPDATE", "after the UPDATE table"},
+	{"", "después de la variable del %s", "after the %s variable"},
+	{"", "después de los valores del case", "after the case values"},
+	{"", "después del 'end' de '%s'", "after the 'end' of '%s'"},
+	{"", "después del 'end' final del programa", "after the program's final 'end'"},
+	{"", "después del alias de tipo", "after the type alias"},
+	{"", "después del campo de la clase", "after the class field"},
+	{"", "después del campo", "after the field"},
+	{"", "después del cuerpo de '%s'", "after the body of '%s'"},
+	{"", "después del encabezado de '%s'", "after the header of '%s'"},
+	{"", "después del encabezado del programa", "after the program header"},
+	{"", "después del límite del for", "after the for limit"},
+	{"", "después del nombre de la expresión de tabla", "after the name of the table expression"},
+	{"", "después del nombre de la variable", "after the variable name"},
+	{"", "después del nombre del campo", "after the field name"},
+	{"", "después del selector", "after the selector"},
+	{"", "después del tipo '%s'", "after type '%s'"},
+	{"", "de la columna", "of the column"},
+	{"", "de la expresión de tabla", "of the table expression"},
+	{"", "de la restricción", "of the constraint"},
+	{"", "de la tabla referenciada", "of the referenced table"},
+	{"", "de la tabla", "of the table"},
+	{"", "de la variable del FOR", "of the FOR variable"},
+	{"", "de la variable o procedimiento", "of the variable or procedure"},
+	{"", "de la vista", "of the view"},
+	{"", "del procedimiento", "of the procedure"},
+	{"", "del tipo", "of the type"},
+	{"", "en %s", "in %s"},
+	{"", "en el CASE", "in the CASE"},
+	{"", "en el alias de tipo", "in the type alias"},
+	{"", "en el campo del registro", "in the record field"},
+	{"", "en el campo del struct", "in the struct field"},
+	{"", "en el ciclo '%s'", "in the '%s' loop"},
+	{"", "en el encabezado de '%s'", "in the '%s' header"},
+	{"", "en el manejador de la excepción", "in the exception handler"},
+	{"", "en el nombre calculado", "in the computed name"},
+	{"", "en el nombre del espacio de nombres", "in the namespace name"},
+	{"", "en el nombre del módulo", "in the module name"},
+	{"", "en el parámetro", "in the parameter"},
+	{"", "en el tipo arreglo", "in the array type"},
+	{"", "en el tipo condicional", "in the conditional type"},
+	{"", "en el tipo función", "in the function type"},
+	{"", "en el tipo map", "in the map type"},
+	{"", "en el tipo", "in the type"},
+	{"", "en la captura de la lambda", "in the lambda capture"},
+	{"", "en la clave calculada", "in the computed key"},
+	{"", "en la cláusula uses", "in the uses clause"},
+	{"", "en la combinación de tablas", "in the table join"},
+	{"", "en la comprensión", "in the comprehension"},
+	{"", "en la conversión de tipo", "in the type conversion"},
+	{"", "en la declaración '%s'", "in the '%s' declaration"},
+	{"", "en la declaración de variables", "in the variable declaration"},
+	{"", "en la declaración del arreglo", "in the array declaration"},
+	{"", "en la declaración del cursor", "in the cursor declaration"},
+	{"", "en la declaración", "in the declaration"},
+	{"", "en la enumeración", "in the enumeration"},
+	{"", "en la expresión '%s'", "in the '%s' expression"},
+	{"", "en la expresión condicional", "in the conditional expression"},
+	{"", "en la función flecha", "in the arrow function"},
+	{"", "en la lista de '%s'", "in the '%s' list"},
+	{"", "en la lista de parámetros", "in the parameter list"},
+	{"", "en la sentencia '%s'", "in the '%s' statement"},
+	{"", "en los parámetros de '%s'", "in the '%s' parameters"},
+	{"", "entre las sentencias", "between the statements"},
+	{"", "para abrir el bloque de '%s'", "to open the '%s' block"},
+	{"", "para abrir el bloque", "to open the block"},
+	{"", "para abrir el cuerpo de la clase", "to open the class body"},
+	{"", "para abrir el cuerpo de la interfaz", "to open the interface body"},
+	{"", "para abrir la enumeración", "to open the enumeration"},
+	{"", "para abrir los parámetros", "to open the parameters"},
+	{"", "para cerrar el %s", "to close the %s"},
+	{"", "para cerrar el bloque '%s'", "to close the '%s' block"},
+	{"", "para cerrar el bloque %s", "to close the %s block"},
+	{"", "para cerrar el bloque de '%s'", "to close the '%s' block"},
+	{"", "para cerrar el bloque", "to close the block"},
+	{"", "para cerrar el ciclo '%s'", "to close the '%s' loop"},
+	{"", "para cerrar el ciclo", "to close the loop"},
+	{"", "para cerrar el cuerpo de la clase", "to close the class body"},
+	{"", "para cerrar el cuerpo de la interfaz", "to close the interface body"},
+	{"", "para cerrar la definición de la tabla", "to close the table definition"},
+	{"", "para cerrar la enumeración", "to close the enumeration"},
+	{"", "para cerrar la expresión", "to close the expression"},
+	{"", "para cerrar la interfaz", "to close the interface"},
+	{"", "para cerrar la lista de columnas", "to close the column list"},
+	{"", "para cerrar la subconsulta", "to close the subquery"},
+	{"", "para cerrar los argumentos", "to close the arguments"},
+	{"", "para cerrar los parámetros de la lambda", "to close the lambda parameters"},
+	{"", "para cerrar los parámetros", "to close the parameters"},
+	{"", "y el tipo del parámetro", "and the parameter type"},
+	{"", "y el tipo del resultado", "and the result type"},
+}
+
+// messageTemplate es una plantilla española lista para reconocer mensajes
+type messageTemplate struct {
+	messageText
+	prefix  string // texto literal antes del primer argumento
+	literal int    // caracteres literales: las más específicas se prueban antes
+	re      *regexp.Regexp
+}
+
+var messageVerb = regexp.MustCompile(`%%|%[sd]`)
+
+func compileMessages(texts []messageText) []messageTemplate {
+	templates := make([]messageTemplate, len(texts))
+	for i, t := range texts {
+		var pattern strings.Builder
+		pattern.WriteString("^")
+		last, literal := 0, 0
+		for _, loc := range messageVerb.FindAllStringIndex(t.ES, -1) {
+			pattern.WriteString(regexp.QuoteMeta(t.ES[last:loc[0]]))
+			literal += loc[0] - last
+			switch t.ES[loc[0]:loc[1]] {
+			case "%%":
+				pattern.WriteString("%")
+				literal++
+			case "%d":
+				pattern.WriteString(`(-?\d+)`)
+			default:
+				pattern.WriteString(`(.*?)`)
+			}
+			last = loc[1]
+		}
+		pattern.WriteString(regexp.QuoteMeta(t.ES[last:]) + "$")
+		literal += len(t.ES) - last
+		prefix := t.ES
+		if loc := messageVerb.FindStringIndex(t.ES); loc != nil {
+			prefix = t.ES[:loc[0]]
+		}
+		templates[i] = messageTemplate{t, prefix, literal, regexp.MustCompile(pattern.String())}
+	}
+	// Con el mismo texto literal se prefiere la que termina en texto fijo:
+	// "Se esperaba un tipo, se encontró %s" antes que "%s (runtime error %d)"
+	sort.SliceStable(templates, func(i, j int) bool { return templates[i].literal > templates[j].literal })
+	return templates
+}
+
+var compiledMessages = sync.OnceValues(func() ([]messageTemplate, []messageTemplate) {
+	return compileMessages(messageCatalog), compileMessages(messagePhrases)
+})
+
+// matchMessage devuelve la plantilla que produjo text y sus argumentos
+func matchMessage(templates []messageTemplate, text string) (*messageTemplate, []string) {
+	for i := range templates {
+		t := &templates[i]
+		if !strings.HasPrefix(text, t.prefix) {
+			continue
+		}
+		if m := t.re.FindStringSubmatch(text); m != nil {
+			return t, m[1:]
+		}
+	}
+	return nil, nil
+}
+
+// renderMessage arma una plantilla traducida con args. %[n]s usa el
+// argumento n; %s y %d, el siguiente
+func renderMessage(template string, args []string) string {
+	var sb strings.Builder
+	next := 0
+	for i := 0; i < len(template); i++ {
+		if template[i] != '%' || i+1 == len(template) {
+			sb.WriteByte(template[i])
+			continue
+		}
+		i++
+		switch c := template[i]; {
+		case c == '%':
+			sb.WriteByte('%')
+		case c == '[':
+			end := strings.IndexByte(template[i:], ']')
+			n, _ := strconv.Atoi(template[i+1 : i+end])
+			if n >= 1 && n <= len(args) {
+				sb.WriteString(args[n-1])
+			}
+			next = n
+			i += end + 1
+		default:
+			if next < len(args) {
+				sb.WriteString(args[next])
+			}
+			next++
+		}
+	}
+	return sb.String()
+}
+
+// translatePhrase traduce un argumento si es una frase conocida; el resto
+// (identificadores, tokens entre comillas) queda igual
+func translatePhrase(text string, depth int) string {
+	if depth > 3 {
+		return text
+	}
+	catalog, phrases := compiledMessages()
+	t, args := matchMessage(phrases, text)
+	if t == nil {
+		if t, args = matchMessage(catalog, text); t == nil {
+			return text
+		}
+	}
+	for i := range args {
+		args[i] = translatePhrase(args[i], depth+1)
+	}
+	return renderMessage(t.EN, args)
+}
+
+// localizeMessage devuelve msg en locale y el identificador de su
+// plantilla, o "" si no está en el catálogo
+func localizeMessage(msg, locale string) (string, string) {
+	prefix, body := messageText{}, msg
+	for _, p := range messagePrefixes {
+		if strings.HasPrefix(msg, p.ES) {
+			prefix, body = p, msg[len(p.ES):]
+			break
+		}
+	}
+	catalog, _ := compiledMessages()
+	t, args := matchMessage(catalog, body)
+	id := ""
+	if t != nil {
+		id = t.ID
+	}
+	if locale != "en" {
+		return msg, id
+	}
+	if t != nil {
+		for i := range args {
+			args[i] = translatePhrase(args[i], 1)
+		}
+		body = renderMessage(t.EN, args)
+	}
+	return prefix.EN + body, id
+}
+
+// ───── Idioma de la petición ─────
+
+// normalizeLocale reduce una etiqueta de idioma ("en-US", "ES") a uno de
+// supportedLocales, o "" si no se sirve
+func normalizeLocale(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if base, _, found := strings.Cut(tag, "-"); found {
+		tag = base
+	}
+	for _, l := range supportedLocales {
+		if tag == l {
+			return l
+		}
+	}
+	return ""
+}
+
+// acceptLanguage elige el idioma soportado con mayor preferencia de una
+// cabecera Accept-Language ("en-US,en;q=0.9,es;q=0.8"), o "" si ninguno
+func acceptLanguage(header string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if v, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if l := normalizeLocale(tag); l != "" && q > bestQ {
+			best, bestQ = l, q
+		}
+	}
+	return best
+}
+
+// requestLocale es el idioma de los mensajes de una petición: el campo
+// locale, la cabecera Accept-Language o GlobalConfig.DefaultLocale
+func requestLocale(requested string, r *http.Request) string {
+	if l := normalizeLocale(requested); l != "" {
+		return l
+	}
+	if r != nil {
+		if l := acceptLanguage(r.Header.Get("Accept-Language")); l != "" {
+			return l
+		}
+	}
+	return currentConfig().DefaultLocale
+}
+
+// invalidLocale devuelve el motivo por el que locale no es válido, o "" si
+// lo es o no se envió
+func invalidLocale(locale string) string {
+	if locale != "" && normalizeLocale(locale) == "" {
+		return "locale must be one of: " + strings.Join(supportedLocales, ", ")
+	}
+	return ""
+}
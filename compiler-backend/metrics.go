@@ -0,0 +1,217 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// ──────────────────────────── Métricas del código ────────────────────────
+//
+// Para la rúbrica de calidad del curso se mide el programa sobre los tokens
+// y el árbol sintáctico: líneas de código, de comentarios y en blanco, la
+// proporción de comentarios y, por función, su complejidad ciclomática y la
+// mayor profundidad de anidamiento. La complejidad es 1 más los puntos de
+// decisión del cuerpo (if, ciclos, casos de un switch, catch, el operador
+// ternario y cada && u ||); las funciones anidadas se miden aparte. Un
+// else if cuenta como una decisión más pero no como un nivel de anidamiento.
+
+type CodeMetrics struct {
+	Lines        int
+	CodeLines    int
+	CommentLines int
+	BlankLines   int
+	// Líneas con comentarios sobre las que no están en blanco
+	CommentRatio float64
+	Functions    []FunctionMetrics
+	// Mayor anidamiento en todo el programa, dentro o fuera de funciones
+	MaxNesting int
+}
+
+type FunctionMetrics struct {
+	Name       string
+	Pos        int
+	Lines      int
+	Complexity int
+	MaxNesting int
+}
+
+// Nodos que declaran una función con cuerpo
+var metricFunctionKinds = map[string]bool{
+	"FunctionDecl": true, "Method": true, "ArrowFunction": true, "Lambda": true,
+	"Procedure": true, "Function": true,
+}
+
+// Nodos que son un punto de decisión; los casos se cuentan aparte
+var decisionKinds = map[string]bool{
+	"If": true, "ElseIf": true, "While": true, "DoWhile": true, "For": true, "ForEach": true,
+	"Repeat": true, "Catch": true, "Conditional": true, "LogicalExpr": true,
+	"CaseBranch": true, "When": true,
+}
+
+// Nodos que abren un nivel de anidamiento
+var nestingKinds = map[string]bool{
+	"If": true, "While": true, "DoWhile": true, "For": true, "ForEach": true, "Repeat": true,
+	"Loop": true, "Switch": true, "Select": true, "Try": true,
+}
+
+// computeMetrics mide code a partir de sus tokens y su árbol
+func computeMetrics(code string, tokens []Token, tree []ParseNode, language string) CodeMetrics {
+	var m CodeMetrics
+	m.Lines = strings.Count(code, "\n") + 1
+	if strings.HasSuffix(code, "\n") {
+		m.Lines--
+	}
+
+	// Una línea con código y un comentario al final cuenta en las dos
+	withCode, withComment := map[int]bool{}, map[int]bool{}
+	for _, t := range tokens {
+		lines := withCode
+		if t.Type == COMMENT {
+			lines = withComment
+		}
+		for l := t.Line; l <= t.EndLine; l++ {
+			lines[l] = true
+		}
+	}
+	m.CodeLines, m.CommentLines = len(withCode), len(withComment)
+	nonBlank := len(withCode)
+	for l := range withComment {
+		if !withCode[l] {
+			nonBlank++
+		}
+	}
+	m.BlankLines = m.Lines - nonBlank
+	if nonBlank > 0 {
+		m.CommentRatio = float64(m.CommentLines) / float64(nonBlank)
+	}
+
+	lineStarts := computeLineStarts(code)
+	lineOf := func(pos int) int {
+		return sort.Search(len(lineStarts), func(i int) bool { return lineStarts[i] > pos })
+	}
+	var walk func(n ParseNode)
+	walk = func(n ParseNode) {
+		if metricFunctionKinds[n.Kind] && hasBlock(n) {
+			fn := FunctionMetrics{Name: n.Label, Pos: n.Pos, Lines: lineOf(n.End-1) - lineOf(n.Pos) + 1, Complexity: 1}
+			if n.Kind == "ArrowFunction" || n.Kind == "Lambda" {
+				fn.Name = "(anónima)"
+			}
+			for _, c := range n.Children {
+				fn.Complexity += decisions(c, language)
+				if d := nesting(c, language, false); d > fn.MaxNesting {
+					fn.MaxNesting = d
+				}
+			}
+			m.Functions = append(m.Functions, fn)
+		}
+		for _, c := range n.Children {
+			walk(c)
+		}
+	}
+	for _, root := range tree {
+		walk(root)
+		if d := nesting(root, language, true); d > m.MaxNesting {
+			m.MaxNesting = d
+		}
+	}
+	return m
+}
+
+func hasBlock(n ParseNode) bool {
+	for _, c := range n.Children {
+		if c.Kind == "Block" {
+			return true
+		}
+	}
+	return false
+}
+
+// decisions cuenta los puntos de decisión de n sin entrar en funciones
+// anidadas
+func decisions(n ParseNode, language string) int {
+	if metricFunctionKinds[n.Kind] && hasBlock(n) {
+		return 0
+	}
+	count := 0
+	// En C++, JavaScript y Go cada case es una decisión (default no); el
+	// case de Pascal es la sentencia y sus ramas son CaseBranch
+	if decisionKinds[n.Kind] || n.Kind == "Case" && n.Label == "case" && language != "pascal" {
+		count++
+	}
+	for _, c := range n.Children {
+		count += decisions(c, language)
+	}
+	return count
+}
+
+// nesting devuelve la mayor profundidad de anidamiento dentro de n; con
+// intoFunctions también mide las funciones anidadas
+func nesting(n ParseNode, language string, intoFunctions bool) int {
+	if !intoFunctions && metricFunctionKinds[n.Kind] && hasBlock(n) {
+		return 0
+	}
+	level := 0
+	// El case de Pascal es una sentencia; en los demás lenguajes el nivel
+	// es el del switch
+	if nestingKinds[n.Kind] || n.Kind == "Case" && language == "pascal" {
+		level = 1
+	}
+	deepest := 0
+	for _, c := range n.Children {
+		d := nesting(c, language, intoFunctions)
+		// else if: el if es la única sentencia del else
+		if n.Kind == "Else" && len(n.Children) == 1 && c.Kind == "If" {
+			d--
+		}
+		if d > deepest {
+			deepest = d
+		}
+	}
+	return level + deepest
+}
+
+// APIMetrics es la sección metrics de la respuesta de /api/v1/analyze
+type APIMetrics struct {
+	Lines         int                  `json:"lines"`
+	CodeLines     int                  `json:"codeLines"`
+	CommentLines  int                  `json:"commentLines"`
+	BlankLines    int                  `json:"blankLines"`
+	CommentRatio  float64              `json:"commentRatio"`
+	FunctionCount int                  `json:"functionCount"`
+	Functions     []APIFunctionMetrics `json:"functions"`
+	MaxNesting    int                  `json:"maxNesting"`
+	// La mayor complejidad ciclomática entre las funciones
+	MaxComplexity int `json:"maxComplexity"`
+}
+
+type APIFunctionMetrics struct {
+	Name       string `json:"name"`
+	Line       int    `json:"line"`
+	Column     int    `json:"column"`
+	Lines      int    `json:"lines"`
+	Complexity int    `json:"complexity"`
+	MaxNesting int    `json:"maxNesting"`
+}
+
+func convertToAPIMetrics(m CodeMetrics, src *sourceIndex) *APIMetrics {
+	api := &APIMetrics{
+		Lines:         m.Lines,
+		CodeLines:     m.CodeLines,
+		CommentLines:  m.CommentLines,
+		BlankLines:    m.BlankLines,
+		CommentRatio:  math.Round(m.CommentRatio*1000) / 1000,
+		FunctionCount: len(m.Functions),
+		Functions:     make([]APIFunctionMetrics, len(m.Functions)),
+		MaxNesting:    m.MaxNesting,
+	}
+	for i, fn := range m.Functions {
+		line, column := src.lineColumn(fn.Pos)
+		api.Functions[i] = APIFunctionMetrics{Name: fn.Name, Line: line, Column: column,
+			Lines: fn.Lines, Complexity: fn.Complexity, MaxNesting: fn.MaxNesting}
+		if fn.Complexity > api.MaxComplexity {
+			api.MaxComplexity = fn.Complexity
+		}
+	}
+	return api
+}
@@ -0,0 +1,645 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ─────────────────────────── Kotlin y Swift ──────────────────────────────
+//
+// Los cursos de desarrollo móvil pegan fragmentos de Kotlin y de Swift, que
+// sin soporte propio se detectaban como JavaScript y daban un error por cada
+// 'val' o 'func'. Los dos lenguajes tienen análisis léxico y un análisis
+// semántico básico, pero no gramática ni ejecutor: la ejecución responde
+// "Real executor no soporta kotlin".
+//
+// Como en Go y TypeScript, las declaraciones se registran antes de la pasada
+// sobre los tokens, pero sin árbol: se reconocen por la secuencia de tokens.
+// Declaran val/var/let, fun/func, los tipos (class, object, struct, enum,
+// protocol...), los parámetros de funciones, constructores y lambdas, las
+// variables de los for y los casos de los enums. Los nombres con mayúscula
+// que el programa no declara son tipos o clases de la biblioteca (Int,
+// Math, UIColor) y no se reportan, igual que los miembros después de un
+// punto (lista.size). Dentro de las cadenas cuentan los usos de las
+// plantillas: "$nombre" y "${a + b}" en Kotlin, "\(nombre)" en Swift.
+
+// ───────────────────────────────── Lexer ─────────────────────────────────
+
+// Las cadenas entre comillas triples ocupan varias líneas; las demás
+// pueden tener otras cadenas dentro de sus plantillas
+var kotlinOrder = []matcher{whitespace, comment, tripleString, kotlinSyntax.interpolatedString, strlit, unclosedString, kotlinNumber, keyword, ident, oper, delim}
+
+var swiftOrder = []matcher{whitespace, comment, tripleString, swiftSyntax.interpolatedString, strlit, unclosedString, swiftNumber, keyword, swiftClosureArgument, ident, oper, delim}
+
+// interpolatedString reconoce una cadena entre comillas dobles cuyas
+// plantillas tienen otras cadenas: "\(nombre ?? "anónimo")". Sin cerrar
+// en la línea la deja a strlit y unclosedString
+func (syntax *mobileSyntax) interpolatedString(_ *LanguagePatterns, s string, p int) (TokenType, string) {
+	if s[p] != '"' {
+		return UNKNOWN, ""
+	}
+	if end := syntax.stringEnd(s, p+1); end >= 0 {
+		return STRING, s[p:end]
+	}
+	return UNKNOWN, ""
+}
+
+// stringEnd devuelve la posición siguiente a la comilla que cierra la
+// cadena cuyo texto empieza en i, o -1 si no se cierra en la línea
+func (syntax *mobileSyntax) stringEnd(s string, i int) int {
+	for i < len(s) {
+		switch {
+		case s[i] == '\n':
+			return -1
+		case s[i] == '"':
+			return i + 1
+		case strings.HasPrefix(s[i:], syntax.interpolation):
+			end := syntax.interpolationEnd(s, i+len(syntax.interpolation))
+			if end < 0 {
+				return -1
+			}
+			i = end + 1
+		case s[i] == '\\':
+			i += 2
+		default:
+			i++
+		}
+	}
+	return -1
+}
+
+// interpolationEnd devuelve la posición del carácter que cierra la
+// plantilla cuya expresión empieza en i, o -1 si no se cierra en la línea
+func (syntax *mobileSyntax) interpolationEnd(s string, i int) int {
+	opening := syntax.interpolation[len(syntax.interpolation)-1]
+	depth := 0
+	for i < len(s) {
+		switch s[i] {
+		case '\n':
+			return -1
+		case opening:
+			depth++
+		case syntax.closing:
+			if depth == 0 {
+				return i
+			}
+			depth--
+		case '"':
+			end := syntax.stringEnd(s, i+1)
+			if end < 0 {
+				return -1
+			}
+			i = end
+			continue
+		}
+		i++
+	}
+	return -1
+}
+
+// swiftClosureArgument reconoce los argumentos implícitos de una clausura
+// ($0, $1) y las proyecciones de los property wrappers ($nombre)
+func swiftClosureArgument(_ *LanguagePatterns, s string, p int) (TokenType, string) {
+	if s[p] != '$' {
+		return UNKNOWN, ""
+	}
+	if lex, ok := matchHere(GeneralPatterns.Identifier, s, p+1); ok {
+		return IDENTIFIER, s[p : p+1+len(lex)]
+	}
+	end := p + 1
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	if end == p+1 {
+		return UNKNOWN, ""
+	}
+	return IDENTIFIER, s[p:end]
+}
+
+func init() {
+	RegisterLanguage(&languageDef{
+		name:     "kotlin",
+		patterns: LanguageSpecificPatterns["kotlin"],
+		matchers: kotlinOrder,
+		register: func(s *SemanticAnalyzer, declared map[string]int, used map[string][]int, syms *[]Symbol) declarationIndex {
+			return s.registerMobileDeclarations(kotlinSyntax, declared, used, syms)
+		},
+		keywords: LanguageKeywords{
+			Builtins: map[string]bool{
+				"println": true, "print": true, "readLine": true, "readln": true,
+				"listOf": true, "mutableListOf": true, "arrayOf": true, "intArrayOf": true,
+				"mapOf": true, "mutableMapOf": true, "setOf": true, "mutableSetOf": true,
+				"emptyList": true, "emptyMap": true, "arrayListOf": true, "hashMapOf": true,
+				"repeat": true, "require": true, "check": true, "error": true, "TODO": true,
+				"maxOf": true, "minOf": true, "lazy": true, "run": true, "with": true,
+				"apply": true, "also": true, "let": true, "takeIf": true, "to": true,
+				"until": true, "downTo": true, "step": true, "it": true, "field": true,
+				"get": true, "set": true, "value": true, "main": true, "args": true,
+			},
+			Reserved: map[string]bool{
+				"as": true, "break": true, "class": true, "continue": true, "do": true,
+				"else": true, "false": true, "for": true, "fun": true, "if": true,
+				"in": true, "interface": true, "is": true, "null": true, "object": true,
+				"package": true, "return": true, "super": true, "this": true, "throw": true,
+				"true": true, "try": true, "typealias": true, "typeof": true, "val": true,
+				"var": true, "when": true, "while": true,
+			},
+		},
+	})
+
+	RegisterLanguage(&languageDef{
+		name:     "swift",
+		patterns: LanguageSpecificPatterns["swift"],
+		matchers: swiftOrder,
+		register: func(s *SemanticAnalyzer, declared map[string]int, used map[string][]int, syms *[]Symbol) declarationIndex {
+			return s.registerMobileDeclarations(swiftSyntax, declared, used, syms)
+		},
+		keywords: LanguageKeywords{
+			Builtins: map[string]bool{
+				"print": true, "debugPrint": true, "readLine": true, "min": true, "max": true,
+				"abs": true, "sqrt": true, "pow": true, "floor": true, "ceil": true, "round": true,
+				"stride": true, "zip": true, "repeatElement": true, "type": true,
+				"precondition": true, "assert": true, "fatalError": true, "swap": true,
+				"newValue": true, "oldValue": true, "error": true,
+			},
+			Reserved: map[string]bool{
+				"associatedtype": true, "class": true, "deinit": true, "enum": true,
+				"extension": true, "fileprivate": true, "func": true, "import": true,
+				"init": true, "inout": true, "internal": true, "let": true, "open": true,
+				"operator": true, "private": true, "protocol": true, "public": true,
+				"static": true, "struct": true, "subscript": true, "typealias": true,
+				"var": true, "break": true, "case": true, "continue": true, "default": true,
+				"defer": true, "do": true, "else": true, "fallthrough": true, "for": true,
+				"guard": true, "if": true, "in": true, "repeat": true, "return": true,
+				"switch": true, "where": true, "while": true, "as": true, "catch": true,
+				"false": true, "is": true, "nil": true, "rethrows": true, "self": true,
+				"Self": true, "super": true, "throw": true, "throws": true, "true": true,
+				"try": true,
+			},
+		},
+	})
+}
+
+// ─────────────────────────── Declaraciones ───────────────────────────────
+
+// mobileSyntax es lo que distingue a Kotlin de Swift en el análisis
+type mobileSyntax struct {
+	// Palabra clave → tipo de símbolo del nombre que la sigue
+	declarations map[string]string
+	// Palabras que abren el cuerpo de un tipo
+	types map[string]bool
+	// Declaraciones seguidas de una lista de parámetros sin nombre propio
+	// (init, constructor, catch)
+	parameterLists map[string]bool
+	// Separa los parámetros de una lambda de su cuerpo: { x -> } o { x in }
+	lambdaArrow string
+	// Abre una interpolación dentro de una cadena (su último carácter se
+	// anida) y el carácter que la cierra
+	interpolation string
+	closing       byte
+	// Kotlin también interpola $nombre sin llaves
+	bareTemplates bool
+}
+
+var kotlinSyntax = &mobileSyntax{
+	declarations: map[string]string{
+		"val": "constant", "var": "var", "fun": "function", "class": "class",
+		"object": "class", "interface": "class", "typealias": "type",
+	},
+	types:          map[string]bool{"class": true, "object": true, "interface": true},
+	parameterLists: map[string]bool{"constructor": true, "catch": true},
+	lambdaArrow:    "->",
+	interpolation:  "${",
+	closing:        '}',
+	bareTemplates:  true,
+}
+
+var swiftSyntax = &mobileSyntax{
+	declarations: map[string]string{
+		"let": "constant", "var": "var", "func": "function", "class": "class",
+		"struct": "class", "enum": "class", "protocol": "class", "typealias": "type",
+		"associatedtype": "type",
+	},
+	types:          map[string]bool{"class": true, "struct": true, "enum": true, "protocol": true, "extension": true},
+	parameterLists: map[string]bool{"init": true, "subscript": true},
+	lambdaArrow:    "in",
+	interpolation:  `\(`,
+	closing:        ')',
+}
+
+// mobileDeclarations son los identificadores que declaran o nombran algo
+// que no es un uso: etiquetas de argumentos, receptores, imports
+type mobileDeclarations map[int]bool
+
+func (mobileDeclarations) declaresAt([]Token, int) bool { return false }
+
+func (d mobileDeclarations) countsUse(tokens []Token, i int, declared map[string]int) bool {
+	tk := tokens[i]
+	if d[tk.Start] || strings.HasPrefix(tk.Lexeme, "$") {
+		return false
+	}
+	_, isDeclared := declared[tk.Lexeme]
+	prev, next := "", ""
+	if i > 0 {
+		prev = tokens[i-1].Lexeme
+	}
+	if i+1 < len(tokens) {
+		next = tokens[i+1].Lexeme
+	}
+	switch {
+	// Miembros: solo se resuelven los que declara el programa
+	case prev == "." || prev == "?." || prev == "::":
+		return isDeclared
+	// Anotaciones, atributos y etiquetas: @Test, #selector, return@forEach
+	case prev == "@" || prev == "#" || next == "@":
+		return false
+	// Argumentos con nombre: f(nombre = x) en Kotlin, f(nombre: x) en Swift
+	case (prev == "(" || prev == ",") && (next == "=" || next == ":"):
+		return false
+	}
+	return isDeclared || !startsUpper(tk.Lexeme)
+}
+
+// Un símbolo sin usos solo merece la advertencia si es una variable local:
+// los parámetros, propiedades, funciones y tipos se usan desde afuera
+func (mobileDeclarations) reportsUnused(name, kind string) bool {
+	return (kind == "var" || kind == "constant") && name != "_"
+}
+
+func startsUpper(name string) bool {
+	for _, r := range name {
+		return unicode.IsUpper(r)
+	}
+	return false
+}
+
+// mobileScanner recorre los tokens significativos de un programa de Kotlin
+// o Swift registrando sus declaraciones
+type mobileScanner struct {
+	toks   []Token
+	syntax *mobileSyntax
+	decls  mobileDeclarations
+	// declare registra un símbolo; un nombre repetido se registra una vez
+	declare func(tk Token, kind string)
+}
+
+func (m *mobileScanner) is(i int, lexeme string) bool {
+	return i >= 0 && i < len(m.toks) && m.toks[i].Lexeme == lexeme
+}
+
+func (m *mobileScanner) isIdent(i int) bool {
+	return i >= 0 && i < len(m.toks) && m.toks[i].Type == IDENTIFIER
+}
+
+// skip marca un identificador que no declara ni usa: etiquetas, receptores
+func (m *mobileScanner) skip(tk Token) { m.decls[tk.Start] = true }
+
+// registerMobileDeclarations agrega a la tabla de símbolos las
+// declaraciones de un programa de Kotlin o Swift y anota los usos dentro de
+// las plantillas de las cadenas. Como en Go, un nombre declarado de nuevo
+// en otro ámbito se registra una sola vez y no se reportan redefiniciones.
+func (s *SemanticAnalyzer) registerMobileDeclarations(syntax *mobileSyntax, declared map[string]int, used map[string][]int, syms *[]Symbol) mobileDeclarations {
+	m := &mobileScanner{toks: significantTokens(s.tokens), syntax: syntax, decls: mobileDeclarations{}}
+	m.declare = func(tk Token, kind string) {
+		m.skip(tk)
+		if tk.Lexeme == "_" {
+			return
+		}
+		if _, exists := declared[tk.Lexeme]; exists {
+			return
+		}
+		declared[tk.Lexeme] = tk.Start
+		*syms = append(*syms, Symbol{Name: tk.Lexeme, Kind: kind, Pos: tk.Start})
+	}
+	m.scan()
+	s.mobileTemplateUses(syntax, m.decls, declared, used)
+	return m.decls
+}
+
+func (m *mobileScanner) scan() {
+	// Las '{' que abren el cuerpo de un tipo: las variables declaradas en
+	// él son propiedades y las funciones, métodos
+	bodies := map[int]string{}
+	var scopes []string
+	container := func() string {
+		if len(scopes) == 0 {
+			return ""
+		}
+		return scopes[len(scopes)-1]
+	}
+
+	for i, tk := range m.toks {
+		switch {
+		case tk.Lexeme == "{":
+			scope := bodies[i]
+			switch {
+			case scope == "":
+				scope = "block"
+				m.lambdaParameters(i)
+			case scope == "enum" && m.syntax == kotlinSyntax:
+				m.enumEntries(i + 1)
+			}
+			scopes = append(scopes, scope)
+			continue
+		case tk.Lexeme == "}":
+			if len(scopes) > 0 {
+				scopes = scopes[:len(scopes)-1]
+			}
+			continue
+		case tk.Type != KEYWORD:
+			continue
+		}
+
+		word := tk.Lexeme
+		switch {
+		case word == "package" || word == "import":
+			m.importNames(i)
+			continue
+		case word == "for":
+			m.loopVariables(i + 1)
+			continue
+		case word == "case" && container() == "enum":
+			m.enumEntries(i + 1)
+			continue
+		case m.syntax.parameterLists[word]:
+			// init?( e init!( en Swift
+			j := i + 1
+			if m.is(j, "?") || m.is(j, "!") {
+				j++
+			}
+			if m.is(j, "(") {
+				m.parameters(j)
+			}
+			continue
+		}
+		if m.syntax.types[word] {
+			if body := m.typeBody(i); body >= 0 {
+				bodies[body] = "type"
+				if word == "enum" || m.is(i-1, "enum") {
+					bodies[body] = "enum"
+				}
+			}
+		}
+		kind := m.syntax.declarations[word]
+		if kind == "" {
+			continue
+		}
+		j := i + 1
+		// fun <T> nombre(...) en Kotlin
+		if m.is(j, "<") {
+			for j < len(m.toks) && !m.is(j, ">") {
+				j++
+			}
+			j++
+		}
+		// fun Tipo.nombre(...): el receptor es un tipo, no un uso
+		if m.isIdent(j) && m.is(j+1, ".") && m.isIdent(j+2) {
+			m.skip(m.toks[j])
+			j += 2
+		}
+		if (kind == "constant" || kind == "var") && m.is(j, "(") {
+			// val (a, b) = par
+			for j++; j < len(m.toks) && !m.is(j, ")"); j++ {
+				if m.isIdent(j) {
+					m.declare(m.toks[j], kind)
+				}
+			}
+			continue
+		}
+		if !m.isIdent(j) {
+			continue
+		}
+		if scope := container(); scope == "type" || scope == "enum" {
+			switch kind {
+			case "constant", "var":
+				kind = "field"
+			case "function":
+				kind = "method"
+			}
+		}
+		name := m.toks[j]
+		m.declare(name, kind)
+		// Parámetros de la función o del constructor primario de Kotlin,
+		// después de los parámetros de tipo
+		if kind == "function" || kind == "method" || word == "class" {
+			for j++; m.is(j, "<") || m.is(j, ">") || m.is(j, ",") || m.isIdent(j); j++ {
+			}
+			if m.is(j, "(") && m.toks[j].Line == name.Line {
+				m.parameters(j)
+			}
+		}
+	}
+}
+
+// importNames marca los nombres de un package o un import; el último de un
+// import (o su alias después de 'as') queda declarado, salvo en import a.*
+func (m *mobileScanner) importNames(at int) {
+	j := at + 1
+	for ; j < len(m.toks) && m.toks[j].Line == m.toks[at].Line; j++ {
+		if m.isIdent(j) {
+			m.skip(m.toks[j])
+		}
+	}
+	if m.toks[at].Lexeme == "import" && m.isIdent(j-1) {
+		m.declare(m.toks[j-1], "import")
+	}
+}
+
+// parameters declara los parámetros de la lista que abre toks[open]: el
+// nombre que precede a ':'. En Swift el nombre puede ir precedido de la
+// etiqueta del argumento (func saludar(a persona: String)), que no es un
+// uso. Los val/var del constructor primario de Kotlin son propiedades.
+func (m *mobileScanner) parameters(open int) {
+	depth := 0
+	for j := open; j < len(m.toks); j++ {
+		switch m.toks[j].Lexeme {
+		case "(", "[", "{":
+			depth++
+			continue
+		case ")", "]", "}":
+			if depth--; depth == 0 {
+				return
+			}
+			continue
+		}
+		if depth != 1 || !m.isIdent(j) || !m.is(j+1, ":") {
+			continue
+		}
+		switch prev := m.toks[j-1]; {
+		case prev.Lexeme == "val" || prev.Lexeme == "var":
+			m.declare(m.toks[j], "field")
+		case prev.Type == IDENTIFIER:
+			m.skip(prev)
+			m.declare(m.toks[j], "parameter")
+		default:
+			m.declare(m.toks[j], "parameter")
+		}
+	}
+}
+
+// loopVariables declara las variables de un for: for (i in 0..9),
+// for (k, v) in pares, for case let x in opcionales
+func (m *mobileScanner) loopVariables(from int) {
+	var names []Token
+	for j := from; j < len(m.toks); j++ {
+		switch tk := m.toks[j]; {
+		case tk.Lexeme == "in":
+			for _, name := range names {
+				m.declare(name, "var")
+			}
+			return
+		case tk.Type == IDENTIFIER:
+			names = append(names, tk)
+		case tk.Lexeme != "(" && tk.Lexeme != ")" && tk.Lexeme != "," &&
+			tk.Lexeme != "case" && tk.Lexeme != "let" && tk.Lexeme != "var":
+			return
+		}
+	}
+}
+
+// lambdaParameters declara los parámetros de la lambda o clausura que abre
+// toks[open], si los tiene: { a, b -> } en Kotlin, { (a, b) in } o
+// { (x: Int) -> Int in } en Swift; los nombres después de ':' o '->' son
+// tipos. Las ramas de un when de Kotlin ({ x -> }) tienen la misma forma y
+// no declaran.
+func (m *mobileScanner) lambdaParameters(open int) {
+	if m.is(open-1, "when") || m.is(open-1, ")") && m.afterWhen(open-1) {
+		return
+	}
+	var names []Token
+	for j := open + 1; j < len(m.toks); j++ {
+		tk := m.toks[j]
+		switch {
+		case tk.Lexeme == m.syntax.lambdaArrow:
+			for _, name := range names {
+				m.declare(name, "parameter")
+			}
+			return
+		case tk.Type == IDENTIFIER:
+			if !m.is(j-1, ":") && !m.is(j-1, "->") {
+				names = append(names, tk)
+			}
+		case tk.Lexeme == "(" || tk.Lexeme == ")" || tk.Lexeme == "," || tk.Lexeme == ":" ||
+			tk.Lexeme == "->" && m.syntax == swiftSyntax:
+		default:
+			return
+		}
+	}
+}
+
+// afterWhen indica si el ')' de toks[close] cierra la condición de un when
+func (m *mobileScanner) afterWhen(close int) bool {
+	depth := 0
+	for j := close; j >= 0; j-- {
+		switch m.toks[j].Lexeme {
+		case ")":
+			depth++
+		case "(":
+			if depth--; depth == 0 {
+				return m.is(j-1, "when")
+			}
+		}
+	}
+	return false
+}
+
+// typeBody devuelve el índice de la '{' que abre el cuerpo del tipo
+// declarado en toks[at], o -1 si no tiene (class Punto(val x: Int))
+func (m *mobileScanner) typeBody(at int) int {
+	depth := 0
+	for j := at + 1; j < len(m.toks); j++ {
+		tk := m.toks[j]
+		switch tk.Lexeme {
+		case "(", "[":
+			depth++
+		case ")", "]":
+			depth--
+		case "{":
+			if depth == 0 {
+				return j
+			}
+		case "}", ";":
+			return -1
+		}
+		// Empieza otra declaración: el tipo no tenía cuerpo
+		if depth == 0 && tk.Type == KEYWORD && (m.syntax.declarations[tk.Lexeme] != "" || m.syntax.types[tk.Lexeme]) {
+			return -1
+		}
+	}
+	return -1
+}
+
+// enumEntries declara los casos de un enum desde toks[from]: las entradas
+// de un enum class de Kotlin (ROJO, VERDE("v");) o las de un case de Swift
+// (case norte, sur = 2, punto(x: Int))
+func (m *mobileScanner) enumEntries(from int) {
+	for j := from; m.isIdent(j); j++ {
+		m.declare(m.toks[j], "case")
+		j++
+		if m.is(j, "(") {
+			for depth := 0; j < len(m.toks); j++ {
+				if m.is(j, "(") {
+					depth++
+				} else if m.is(j, ")") {
+					if depth--; depth == 0 {
+						j++
+						break
+					}
+				}
+			}
+		}
+		if m.is(j, "=") {
+			j += 2
+		}
+		if !m.is(j, ",") {
+			return
+		}
+	}
+}
+
+// mobileTemplateUses anota como usos los nombres de las plantillas de las
+// cadenas. La expresión de cada plantilla se tokeniza con el mismo lexer y
+// se filtra con las mismas reglas que el resto del código.
+func (s *SemanticAnalyzer) mobileTemplateUses(syntax *mobileSyntax, decls mobileDeclarations, declared map[string]int, used map[string][]int) {
+	for _, tk := range s.tokens {
+		if tk.Type != STRING {
+			continue
+		}
+		for _, expr := range syntax.templateExpressions(tk.Lexeme) {
+			inner := significantTokens(Tokenize(tk.Lexeme[expr[0]:expr[1]], s.language))
+			for i, t := range inner {
+				if t.Type == IDENTIFIER && decls.countsUse(inner, i, declared) {
+					used[t.Lexeme] = append(used[t.Lexeme], tk.Start+expr[0]+t.Start)
+				}
+			}
+		}
+	}
+}
+
+// templateExpressions devuelve los rangos [inicio, fin) de las expresiones
+// interpoladas en la cadena lexeme; la de una plantilla sin cerrar llega
+// hasta el final
+func (syntax *mobileSyntax) templateExpressions(lexeme string) [][2]int {
+	var exprs [][2]int
+	for i := 0; i < len(lexeme); i++ {
+		switch {
+		case strings.HasPrefix(lexeme[i:], syntax.interpolation):
+			start := i + len(syntax.interpolation)
+			end := syntax.interpolationEnd(lexeme, start)
+			if end < 0 {
+				end = len(lexeme)
+			}
+			exprs = append(exprs, [2]int{start, end})
+			i = end
+		case lexeme[i] == '\\':
+			// \$ en Kotlin, \\ y \" en Swift
+			i++
+		case syntax.bareTemplates && lexeme[i] == '$':
+			if name, ok := matchHere(GeneralPatterns.Identifier, lexeme, i+1); ok {
+				exprs = append(exprs, [2]int{i + 1, i + 1 + len(name)})
+				i += len(name)
+			}
+		}
+	}
+	return exprs
+}
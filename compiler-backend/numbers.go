@@ -0,0 +1,257 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// ─────────────────────────── Literales numéricos ──────────────────────────
+//
+// GeneralPatterns.Number solo reconoce decimales: 0xFF se partiría en el
+// número 0 y el identificador xFF, y 1_000 en 1 y _000. Cada lenguaje tiene
+// acá su sintaxis de números:
+//
+//	C++         0xFF, 0b1010, 0755, 1'000'000, 10u, 10ULL, 1.5f, 0x1p-3
+//	JavaScript  0xFF, 0b1010, 0o755, 1_000_000, 10n (BigInt)
+//	Python      0xFF, 0b1010, 0o755, 1_000_000, 2j (imaginario)
+//	Go          0xFF, 0b1010, 0o755, 0755, 1_000_000, 2i, 0x1p-3
+//	Kotlin      0xFF, 0b1010, 1_000_000, 10L, 10u, 10uL, 1.5f, 1e3
+//	Swift       0xFF, 0b1010, 0o755, 1_000_000, 0x1p-3
+//
+// El reconocedor toma todo lo que parece parte del número (dígitos, letras,
+// separadores, un punto decimal y el signo del exponente) y lo compara con
+// la sintaxis del lenguaje. Si no coincide, 123abc o 0b102 quedan como un
+// solo token UNKNOWN y malformedNumber explica qué tiene mal, en lugar de
+// un número seguido de un identificador.
+
+// numberSyntax es la forma de los números de un lenguaje
+type numberSyntax struct {
+	// Un literal válido completo
+	valid *regexp.Regexp
+	// Separador de dígitos: ' en C++, _ en los demás
+	separator byte
+	// Un entero decimal que empieza con 0 es octal (C++ y Go); Python no
+	// lo permite y JavaScript lo acepta como octal antiguo
+	leadingZero string
+}
+
+// numberPattern arma la expresión de los números con el separador sep:
+// {D} son dígitos decimales, {H} hexadecimales, {O} octales y {B}
+// binarios, con a lo sumo un separador entre dos dígitos
+func numberPattern(sep, pattern string) *regexp.Regexp {
+	digits := func(class string) string { return "[" + class + "](?:" + sep + "?[" + class + "])*" }
+	pattern = strings.NewReplacer(
+		"{D}", digits("0-9"), "{H}", digits("0-9a-fA-F"), "{O}", digits("0-7"), "{B}", digits("01"),
+	).Replace(pattern)
+	return regexp.MustCompile("^(?:" + pattern + ")$")
+}
+
+var numberSyntaxes = map[string]*numberSyntax{
+	"cpp": {
+		valid: numberPattern("'",
+			`(?:0[xX]{H}|0[bB]{B}|0(?:'?[0-7])*|[1-9](?:'?[0-9])*)(?:[uU](?:ll|LL|[lLzZ])?|(?:ll|LL|[lL])[uU]?|[zZ][uU]?)?`+
+				`|(?:{D}\.(?:{D})?|\.{D})(?:[eE][+-]?{D})?[fFlL]?|{D}[eE][+-]?{D}[fFlL]?`+
+				`|0[xX](?:{H})?(?:\.(?:{H})?)?[pP][+-]?{D}[fFlL]?`),
+		separator: '\'', leadingZero: "octal",
+	},
+	"javascript": {
+		valid: numberPattern("_",
+			`0[xX]{H}n?|0[oO]{O}n?|0[bB]{B}n?|(?:0|[1-9](?:_?[0-9])*)n`+
+				`|(?:{D}\.(?:{D})?|\.{D}|{D})(?:[eE][+-]?{D})?`),
+		separator: '_', leadingZero: "legacy",
+	},
+	"python": {
+		valid: numberPattern("_",
+			`0[xX](?:_?[0-9a-fA-F])+|0[oO](?:_?[0-7])+|0[bB](?:_?[01])+|[1-9](?:_?[0-9])*|0(?:_?0)*`+
+				`|(?:(?:{D}\.(?:{D})?|\.{D})(?:[eE][+-]?{D})?|{D}[eE][+-]?{D}|{D})[jJ]`+
+				`|(?:{D}\.(?:{D})?|\.{D})(?:[eE][+-]?{D})?|{D}[eE][+-]?{D}`),
+		separator: '_', leadingZero: "invalid",
+	},
+	"go": {
+		valid: numberPattern("_",
+			`(?:0[xX](?:_?[0-9a-fA-F])+|0[oO](?:_?[0-7])+|0[bB](?:_?[01])+|0(?:_?[0-7])*|[1-9](?:_?[0-9])*`+
+				`|(?:{D}\.(?:{D})?|\.{D})(?:[eE][+-]?{D})?|{D}[eE][+-]?{D}`+
+				`|0[xX](?:_?{H})?(?:\.(?:{H})?)?[pP][+-]?{D})i?|{D}i`),
+		separator: '_', leadingZero: "octal",
+	},
+	// Kotlin no tiene octales ni números terminados en punto (1.toString())
+	"kotlin": {
+		valid: numberPattern("_",
+			`(?:0[xX]{H}|0[bB]{B}|{D})(?:[uU]L?|L)?`+
+				`|(?:{D}\.{D}|\.{D})(?:[eE][+-]?{D})?[fF]?|{D}[eE][+-]?{D}[fF]?|{D}[fF]`),
+		separator: '_',
+	},
+	"swift": {
+		valid: numberPattern("_",
+			`0[xX]{H}|0[oO]{O}|0[bB]{B}|{D}`+
+				`|{D}\.{D}(?:[eE][+-]?{D})?|{D}[eE][+-]?{D}`+
+				`|0[xX]{H}(?:\.{H})?[pP][+-]?{D}`),
+		separator: '_',
+	},
+}
+
+// TypeScript escribe los números igual que JavaScript
+func init() { numberSyntaxes["typescript"] = numberSyntaxes["javascript"] }
+
+// numberRun devuelve el largo de lo que parece un número en s[p:]: 0 si
+// no empieza con un dígito o con '.' y un dígito
+func numberRun(s string, p int, sep byte) int {
+	isDigit := func(i int) bool { return i < len(s) && s[i] >= '0' && s[i] <= '9' }
+	isWord := func(i int) bool {
+		return i < len(s) && (isDigit(i) || s[i] == '_' || s[i] >= 'a' && s[i] <= 'z' || s[i] >= 'A' && s[i] <= 'Z')
+	}
+	if !isDigit(p) && !(s[p] == '.' && isDigit(p+1)) {
+		return 0
+	}
+	hex := p+1 < len(s) && s[p] == '0' && (s[p+1] == 'x' || s[p+1] == 'X')
+	dots := 0
+	i := p
+	for i < len(s) {
+		switch c := s[i]; {
+		case isWord(i):
+			i++
+		case c == sep && isWord(i+1):
+			i++
+		case c == '.':
+			// El primer punto es decimal salvo en 1..2; un segundo solo si
+			// le sigue un dígito (1.2.3), para no tragar 1.5.toFixed()
+			if i+1 < len(s) && s[i+1] == '.' || dots > 0 && !isDigit(i+1) {
+				return i - p
+			}
+			dots++
+			i++
+		case (c == '+' || c == '-') && isDigit(i+1) && i > p &&
+			(!hex && (s[i-1] == 'e' || s[i-1] == 'E') || s[i-1] == 'p' || s[i-1] == 'P'):
+			i++
+		default:
+			return i - p
+		}
+	}
+	return i - p
+}
+
+// numberMatcher es el reconocedor de números de un lenguaje
+func numberMatcher(language string) matcher {
+	return func(_ *LanguagePatterns, s string, p int) (TokenType, string) {
+		syntax := numberSyntaxes[language]
+		n := numberRun(s, p, syntax.separator)
+		if n == 0 {
+			return UNKNOWN, ""
+		}
+		if lex := s[p : p+n]; syntax.valid.MatchString(lex) {
+			return NUMBER, lex
+		}
+		return UNKNOWN, s[p : p+n]
+	}
+}
+
+// memberNumberMatcher es numberMatcher para los lenguajes en que un entero
+// puede ir seguido de un miembro (10.toDouble() en Kotlin, 5.description
+// en Swift): el punto seguido de una letra ya no es parte del número
+func memberNumberMatcher(language string) matcher {
+	match := numberMatcher(language)
+	return func(lp *LanguagePatterns, s string, p int) (TokenType, string) {
+		typ, lex := match(lp, s, p)
+		if typ != UNKNOWN {
+			return typ, lex
+		}
+		if dot := strings.IndexByte(lex, '.'); dot > 0 && dot+1 < len(lex) && unicode.IsLetter(rune(lex[dot+1])) &&
+			numberSyntaxes[language].valid.MatchString(lex[:dot]) {
+			return NUMBER, lex[:dot]
+		}
+		return typ, lex
+	}
+}
+
+var (
+	cppNumber    = numberMatcher("cpp")
+	jsNumber     = numberMatcher("javascript")
+	pythonNumber = numberMatcher("python")
+	goNumber     = numberMatcher("go")
+	kotlinNumber = memberNumberMatcher("kotlin")
+	swiftNumber  = memberNumberMatcher("swift")
+)
+
+// malformedNumber explica qué tiene mal el token UNKNOWN t si es un número
+// de un lenguaje con numberSyntax
+func malformedNumber(t Token, language string) (CompilerError, bool) {
+	syntax := numberSyntaxes[language]
+	lex := t.Lexeme
+	if syntax == nil || t.Type != UNKNOWN || numberRun(lex, 0, syntax.separator) != len(lex) {
+		return CompilerError{}, false
+	}
+	return CompilerError{
+		Message:  "Error Léxico: " + numberProblem(lex, syntax),
+		Severity: "error",
+		Type:     "lexico",
+		Pos:      t.Start,
+		Code:     CodeMalformedNumber,
+	}, true
+}
+
+// numberProblem describe por qué lex no es un número válido
+func numberProblem(lex string, syntax *numberSyntax) string {
+	sep := string(syntax.separator)
+	lower := strings.ToLower(lex)
+	if strings.Count(lex, ".") > 1 {
+		return fmt.Sprintf("Número decimal mal formado '%s' - múltiples puntos decimales", lex)
+	}
+	// Prefijo de base sin dígitos o con dígitos de otra base
+	for _, b := range []struct {
+		prefix string
+		digits string
+		base   int
+	}{{"0x", "0123456789abcdef", 16}, {"0o", "01234567", 8}, {"0b", "01", 2}} {
+		if !strings.HasPrefix(lower, b.prefix) {
+			continue
+		}
+		body := strings.TrimLeft(lower[2:], sep)
+		if body == "" || !strings.ContainsRune(b.digits, rune(body[0])) {
+			return fmt.Sprintf("El prefijo '%s' debe ir seguido de dígitos: '%s'", lex[:2], lex)
+		}
+		if d := strings.IndexFunc(body, func(r rune) bool { return r >= '0' && r <= '9' && !strings.ContainsRune(b.digits, r) }); d >= 0 {
+			return fmt.Sprintf("Dígito '%s' no válido en base %d: '%s'", body[d:d+1], b.base, lex)
+		}
+	}
+	if strings.Contains(lex, sep+sep) || strings.HasSuffix(lex, sep) ||
+		strings.Contains(lex, sep+".") || strings.Contains(lex, "."+sep) {
+		return fmt.Sprintf("Separador de dígitos '%s' mal ubicado en '%s'", sep, lex)
+	}
+	if digits := strings.ReplaceAll(lex, sep, ""); len(digits) > 1 && digits[0] == '0' && isDecimalDigits(digits) {
+		switch syntax.leadingZero {
+		case "invalid":
+			return fmt.Sprintf("Los enteros no pueden empezar con 0 en Python: '%s'; para octal use el prefijo 0o", lex)
+		case "octal":
+			if d := strings.IndexAny(digits, "89"); d >= 0 {
+				return fmt.Sprintf("Dígito '%s' no válido en base %d: '%s'", digits[d:d+1], 8, lex)
+			}
+		}
+	}
+	// Un exponente al final sin dígitos: 1e, 1e+, 0x1.8p
+	if end := strings.TrimRight(lower, "+-"); strings.HasSuffix(end, "p") ||
+		strings.HasSuffix(end, "e") && !strings.HasPrefix(lower, "0x") {
+		return fmt.Sprintf("Exponente sin dígitos en '%s'", lex)
+	}
+	// Lo que sobra después del número válido más largo
+	valid := 0
+	for i := 1; i <= len(lex); i++ {
+		if syntax.valid.MatchString(lex[:i]) {
+			valid = i
+		}
+	}
+	if valid == 0 {
+		return fmt.Sprintf("Número mal formado '%s'", lex)
+	}
+	return fmt.Sprintf("Sufijo '%s' no válido en el número '%s'", lex[valid:], lex)
+}
+
+func isDecimalDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
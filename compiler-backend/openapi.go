@@ -0,0 +1,397 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ──────────────────────────── Especificación OpenAPI ─────────────────────
+//
+// GET /api/v1/openapi.json describe la API en OpenAPI 3. Los esquemas se
+// generan por reflexión a partir de los tipos de petición y respuesta y sus
+// etiquetas json, así la especificación no se desactualiza cuando cambia un
+// campo: basta con registrar cada ruta nueva en apiOperations. Con ella el
+// frontend y otras herramientas pueden generar clientes tipados.
+
+// Versión de la API; cambia el prefijo /api/v1 solo con cambios
+// incompatibles, los campos nuevos son siempre opcionales
+const (
+	apiVersion = "1.0.0"
+	apiPrefix  = "/api/v1"
+)
+
+// apiParam es un parámetro de ruta o de query string
+type apiParam struct {
+	Name        string
+	In          string // "path" | "query"
+	Type        string // tipo JSON Schema
+	Description string
+}
+
+// apiOperation describe una ruta; Request y Response son valores del tipo
+// del cuerpo (nil si no tiene)
+type apiOperation struct {
+	Method   string
+	Path     string
+	Summary  string
+	Params   []apiParam
+	Request  interface{}
+	Response interface{}
+	Status   int // código de éxito; 0 = 200
+	// Tipos de la respuesta si es texto en lugar de JSON
+	TextContent []string
+	// Respuestas de error posibles además de 405 y, si recibe datos, 400
+	Errors []int
+	// No identifica al usuario: no responde 401 (ver auth.go)
+	Public bool
+}
+
+var apiOperations = []apiOperation{
+	{Method: http.MethodGet, Path: "/health", Summary: "Estado del servidor (sinónimo de /healthz)", Response: HealthResponse{}, Public: true},
+	{Method: http.MethodGet, Path: "/healthz", Summary: "Liveness: el proceso responde", Response: HealthResponse{}, Public: true},
+	{Method: http.MethodGet, Path: "/readyz", Summary: "Readiness: herramientas, directorio temporal, cola y caché; 503 si alguna revisión falla",
+		Response: APIReadinessResponse{}, Errors: []int{http.StatusServiceUnavailable}, Public: true},
+	{Method: http.MethodPost, Path: "/analyze", Summary: "Análisis léxico, sintáctico y semántico y ejecución del código",
+		Request: AnalyzeRequest{}, Response: APIAnalyzeResponse{},
+		Errors: []int{http.StatusForbidden, http.StatusConflict, http.StatusTooManyRequests}},
+	{Method: http.MethodPost, Path: "/analyze/async", Summary: "Deja el análisis en la cola y devuelve el trabajo para consultarlo en /jobs/{id}",
+		Request: AnalyzeRequest{}, Response: APIJob{}, Status: http.StatusAccepted,
+		Errors: []int{http.StatusForbidden, http.StatusConflict, http.StatusTooManyRequests, http.StatusServiceUnavailable}},
+	{Method: http.MethodGet, Path: "/jobs/{id}", Summary: "Estado de un análisis asíncrono y, al terminar, su resultado",
+		Params:   []apiParam{{"id", "path", "string", "Id devuelto por /analyze/async"}},
+		Response: APIJob{}, Errors: []int{http.StatusNotFound}},
+	{Method: http.MethodPost, Path: "/lex", Summary: "Solo la fase léxica, para resaltado de sintaxis",
+		Request: AnalyzeRequest{}, Response: APILexResponse{}, Errors: []int{http.StatusForbidden}},
+	{Method: http.MethodPost, Path: "/highlight", Summary: "El código resaltado con los tokens del lexer, en HTML o con colores ANSI",
+		Params:  []apiParam{{"format", "query", "string", "html (por defecto) o ansi"}},
+		Request: AnalyzeRequest{}, TextContent: []string{"text/html", "text/plain"}},
+	{Method: http.MethodGet, Path: "/analyze/stream",
+		Summary:  "WebSocket: el cliente envía un AnalyzeRequest y recibe un APIStreamMessage por fase",
+		Response: APIStreamMessage{}, Status: http.StatusSwitchingProtocols, Errors: []int{http.StatusTooManyRequests}},
+	{Method: http.MethodPost, Path: "/analyze/tree", Summary: "Árbol sintáctico en DOT (Graphviz) o Mermaid, sin análisis semántico ni ejecución",
+		Params:  []apiParam{{"format", "query", "string", "dot (por defecto) o mermaid"}},
+		Request: AnalyzeRequest{}, TextContent: []string{"text/vnd.graphviz", "text/plain"}},
+	{Method: http.MethodGet, Path: "/analyze/tree", Summary: "Igual que POST /analyze/tree con el código en la query string",
+		Params: []apiParam{
+			{"format", "query", "string", "dot (por defecto) o mermaid"},
+			{"code", "query", "string", "Código a analizar"},
+			{"language", "query", "string", "Lenguaje; si no se indica se detecta"},
+		},
+		TextContent: []string{"text/vnd.graphviz", "text/plain"}},
+	{Method: http.MethodPost, Path: "/analyze/tree/expand", Summary: "Los hijos de un nodo \"more\" del parseTree, acotados por treeMaxNodes y treeMaxDepth",
+		Request: TreeExpandRequest{}, Response: APITreeExpandResponse{}, Errors: []int{http.StatusForbidden}},
+	{Method: http.MethodPost, Path: "/analyze/diff", Summary: "Nodos del árbol sintáctico agregados, quitados y modificados entre dos versiones del código",
+		Request: TreeDiffRequest{}, Response: APITreeDiffResponse{}, Errors: []int{http.StatusForbidden}},
+	{Method: http.MethodPost, Path: "/report", Summary: "Reporte de laboratorio con el código, los tokens, el árbol dibujado, la tabla de símbolos, los errores y la ejecución",
+		Params: []apiParam{
+			{"format", "query", "string", "html (por defecto) o pdf"},
+			{"title", "query", "string", "Título del reporte"},
+			{"author", "query", "string", "Autor, debajo del título"},
+		},
+		Request: AnalyzeRequest{}, TextContent: []string{"text/html", "application/pdf"},
+		Errors: []int{http.StatusForbidden, http.StatusConflict, http.StatusTooManyRequests}},
+	{Method: http.MethodPost, Path: "/sessions", Summary: "Crea una sesión de edición y analiza el código",
+		Request: AnalyzeRequest{}, Response: APISessionResponse{}, Status: http.StatusCreated,
+		Errors: []int{http.StatusForbidden, http.StatusTooManyRequests}},
+	{Method: http.MethodPatch, Path: "/sessions/{id}/code", Summary: "Reanaliza la sesión con el código nuevo o una lista de ediciones",
+		Params:  []apiParam{{"id", "path", "string", "Id devuelto al crear la sesión"}},
+		Request: SessionCodeRequest{}, Response: APISessionResponse{},
+		Errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusTooManyRequests}},
+	{Method: http.MethodDelete, Path: "/sessions/{id}", Summary: "Descarta la sesión",
+		Params: []apiParam{{"id", "path", "string", "Id devuelto al crear la sesión"}},
+		Status: http.StatusNoContent, Errors: []int{http.StatusNotFound}},
+	{Method: http.MethodGet, Path: "/history", Summary: "Historial de análisis, del más reciente al más antiguo",
+		Params: []apiParam{
+			{"language", "query", "string", "Lenguaje del análisis"},
+			{"hash", "query", "string", "SHA-256 del código"},
+			{"since", "query", "string", "Desde esta fecha (RFC 3339 o AAAA-MM-DD)"},
+			{"until", "query", "string", "Hasta esta fecha, sin incluirla"},
+			{"errors", "query", "boolean", "Solo análisis con (true) o sin (false) errores"},
+			{"limit", "query", "integer", "Registros por página (100 por defecto, máximo 1000)"},
+			{"offset", "query", "integer", "Registros a omitir"},
+			{"workspace", "query", "string", "Solo admin fuera de un espacio: análisis de este espacio (vacío, los que no tienen)"},
+		},
+		Response: APIHistoryResponse{}, Errors: []int{http.StatusServiceUnavailable}},
+	{Method: http.MethodGet, Path: "/executions", Summary: "Ejecuciones en curso con su id (X-Request-ID) y el tiempo transcurrido",
+		Response: APIExecutionsResponse{}},
+	{Method: http.MethodDelete, Path: "/executions/{id}", Summary: "Detiene una ejecución en curso",
+		Params: []apiParam{{"id", "path", "string", "X-Request-ID de la petición que la inició"}},
+		Status: http.StatusNoContent, Errors: []int{http.StatusNotFound}},
+	{Method: http.MethodGet, Path: "/toolchains", Summary: "Lenguajes que se pueden ejecutar en este servidor y las herramientas instaladas",
+		Params:   []apiParam{{"refresh", "query", "boolean", "Busca de nuevo las herramientas en lugar de usar la última búsqueda"}},
+		Response: APIToolchainsResponse{}},
+	{Method: http.MethodPost, Path: "/hover", Summary: "Símbolo en una línea y columna: categoría, tipo, declaración y documentación de la biblioteca; null si no hay uno",
+		Request: HoverRequest{}, Response: APIHover{}, Errors: []int{http.StatusForbidden}},
+	{Method: http.MethodPost, Path: "/compare", Summary: "Similitud entre entregas por sus secuencias de tokens normalizadas, con los tramos comunes",
+		Request: CompareRequest{}, Response: APICompareResponse{}, Errors: []int{http.StatusForbidden, http.StatusTooManyRequests}},
+	{Method: http.MethodPost, Path: "/trace", Summary: "Ejecución paso a paso de Python o JavaScript: línea, función y variables de cada paso",
+		Request: TraceRequest{}, Response: APITraceResponse{}, Errors: []int{http.StatusForbidden, http.StatusTooManyRequests}},
+	{Method: http.MethodPost, Path: "/execute/stream",
+		Summary: "Server-Sent Events: la salida del programa en eventos stdout y stderr mientras corre y al final un evento result con la respuesta de /analyze",
+		Request: AnalyzeRequest{}, TextContent: []string{"text/event-stream"},
+		Errors: []int{http.StatusForbidden, http.StatusConflict, http.StatusTooManyRequests}},
+	{Method: http.MethodGet, Path: "/execute/interactive",
+		Summary:  "WebSocket: el cliente envía un InteractiveRequest y luego las teclas; el programa corre con una terminal y su salida vuelve en APIInteractiveMessage",
+		Response: APIInteractiveMessage{}, Status: http.StatusSwitchingProtocols,
+		Errors: []int{http.StatusConflict, http.StatusTooManyRequests}},
+	{Method: http.MethodPost, Path: "/previews", Summary: "Publica un documento HTML en /preview/{id}/ con sus archivos auxiliares hasta que venza",
+		Request: PreviewRequest{}, Response: APIPreview{}, Status: http.StatusCreated,
+		Errors: []int{http.StatusForbidden, http.StatusTooManyRequests}},
+	{Method: http.MethodGet, Path: "/previews", Summary: "Vistas previas vigentes del usuario, de la más nueva a la más vieja",
+		Response: APIPreviewsResponse{}},
+	{Method: http.MethodDelete, Path: "/previews/{id}", Summary: "Retira una vista previa antes de que venza",
+		Params: []apiParam{{"id", "path", "string", "Id devuelto al publicarla"}},
+		Status: http.StatusNoContent, Errors: []int{http.StatusForbidden, http.StatusNotFound}},
+	{Method: http.MethodPost, Path: "/share", Summary: "Analiza el código y guarda el resultado bajo un enlace corto para compartirlo hasta que venza",
+		Params: []apiParam{
+			{"title", "query", "string", "Título que ve quien abre el enlace"},
+			{"ttlSeconds", "query", "integer", "Vigencia; solo puede acortar SHARE_TTL"},
+		},
+		Request: AnalyzeRequest{}, Response: APIShare{}, Status: http.StatusCreated,
+		Errors: []int{http.StatusForbidden, http.StatusConflict, http.StatusTooManyRequests}},
+	{Method: http.MethodGet, Path: "/share/{id}", Summary: "Análisis compartido, sin credenciales; con format=html o pdf, como reporte de laboratorio",
+		Params: []apiParam{
+			{"id", "path", "string", "Id devuelto al compartirlo"},
+			{"format", "query", "string", "html o pdf; sin él, JSON"},
+		},
+		Response: APIShare{}, Errors: []int{http.StatusNotFound}, Public: true},
+	{Method: http.MethodDelete, Path: "/share/{id}", Summary: "Retira un análisis compartido antes de que venza",
+		Params: []apiParam{{"id", "path", "string", "Id devuelto al compartirlo"}},
+		Status: http.StatusNoContent, Errors: []int{http.StatusForbidden, http.StatusNotFound}},
+	{Method: http.MethodGet, Path: "/me", Summary: "Usuario de las credenciales, su cuota y los minutos de ejecución consumidos hoy",
+		Response: APIPrincipalResponse{}},
+	{Method: http.MethodGet, Path: "/admin/config", Summary: "Configuración que se puede cambiar con el servidor en marcha (solo admin)",
+		Response: APIAdminConfig{}, Errors: []int{http.StatusForbidden}},
+	{Method: http.MethodPut, Path: "/admin/config", Summary: "Cambia la configuración sin reiniciar; los campos omitidos no cambian (solo admin)",
+		Request: APIAdminConfig{}, Response: APIAdminConfig{}, Errors: []int{http.StatusForbidden}},
+	{Method: http.MethodPost, Path: "/webhooks/github", Summary: "Evento de un webhook de GitHub: un push analiza los archivos fuente cambiados y publica el resultado en el commit",
+		Params: []apiParam{
+			{"X-GitHub-Event", "header", "string", "push; los demás eventos responden 204"},
+			{"X-Hub-Signature-256", "header", "string", "HMAC-SHA256 del cuerpo con GITHUB_WEBHOOK_SECRET"},
+		},
+		Request: map[string]interface{}{}, Response: APIGitHubPushResponse{}, Status: http.StatusAccepted,
+		Errors: []int{http.StatusUnauthorized, http.StatusNotFound, http.StatusServiceUnavailable}, Public: true},
+	{Method: http.MethodGet, Path: "/openapi.json", Summary: "Esta especificación", Response: map[string]interface{}{}, Public: true},
+}
+
+var (
+	openAPIOnce sync.Once
+	openAPISpec []byte
+)
+
+func openAPIHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	openAPIOnce.Do(func() {
+		openAPISpec, _ = json.MarshalIndent(buildOpenAPISpec(), "", "  ")
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPISpec)
+}
+
+// buildOpenAPISpec genera el documento OpenAPI a partir de apiOperations
+func buildOpenAPISpec() map[string]interface{} {
+	schemas := map[string]interface{}{}
+	paths := map[string]map[string]interface{}{}
+	for _, op := range apiOperations {
+		status := op.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := map[string]interface{}{"description": http.StatusText(status)}
+		if op.Response != nil {
+			success["content"] = jsonContent(schemaFor(reflect.TypeOf(op.Response), schemas))
+		}
+		if len(op.TextContent) > 0 {
+			content := map[string]interface{}{}
+			for _, t := range op.TextContent {
+				content[t] = map[string]interface{}{"schema": map[string]interface{}{"type": "string"}}
+			}
+			success["content"] = content
+		}
+		// Todos los errores son un APIError (ver apierror.go); un pánico en
+		// cualquier handler responde 500 (ver recover.go)
+		failures := append([]int{http.StatusMethodNotAllowed, http.StatusInternalServerError}, op.Errors...)
+		if op.Request != nil || len(op.Params) > 0 {
+			failures = append(failures, http.StatusBadRequest)
+		}
+		if op.Request != nil {
+			// Los rechazos de limitRequest (ver validation.go)
+			failures = append(failures, http.StatusRequestEntityTooLarge)
+		}
+		if !op.Public {
+			failures = append(failures, http.StatusUnauthorized)
+		}
+		responses := map[string]interface{}{strconv.Itoa(status): success}
+		for _, code := range failures {
+			responses[strconv.Itoa(code)] = errorResponse(code, schemas)
+		}
+
+		operation := map[string]interface{}{
+			"summary":     op.Summary,
+			"operationId": operationID(op),
+			"responses":   responses,
+		}
+		if !op.Public {
+			// Las credenciales son opcionales salvo con AUTH_REQUIRED, y el
+			// token de espacio de trabajo se suma a ellas
+			operation["security"] = []interface{}{
+				map[string]interface{}{"bearer": []string{}},
+				map[string]interface{}{"apiKey": []string{}},
+				map[string]interface{}{"bearer": []string{}, "workspace": []string{}},
+				map[string]interface{}{"apiKey": []string{}, "workspace": []string{}},
+				map[string]interface{}{"workspace": []string{}},
+				map[string]interface{}{},
+			}
+		}
+		if len(op.Params) > 0 {
+			var params []interface{}
+			for _, p := range op.Params {
+				params = append(params, map[string]interface{}{
+					"name":        p.Name,
+					"in":          p.In,
+					"required":    p.In == "path",
+					"description": p.Description,
+					"schema":      map[string]interface{}{"type": p.Type},
+				})
+			}
+			operation["parameters"] = params
+		}
+		if op.Request != nil {
+			operation["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content":  jsonContent(schemaFor(reflect.TypeOf(op.Request), schemas)),
+			}
+		}
+
+		path := apiPrefix + op.Path
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+		paths[path][strings.ToLower(op.Method)] = operation
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "Compilador Go Backend",
+			"description": "Análisis léxico, sintáctico y semántico y ejecución de C++, Python, JavaScript y Go",
+			"version":     apiVersion,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"securitySchemes": map[string]interface{}{
+				"bearer": map[string]interface{}{
+					"type": "http", "scheme": "bearer",
+					"description": "Clave de API (cb_...) o token JWT firmado con HS256",
+				},
+				"apiKey": map[string]interface{}{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+				"workspace": map[string]interface{}{
+					"type": "apiKey", "in": "header", "name": workspaceHeader,
+					"description": "Token del espacio de trabajo del curso (WORKSPACES)",
+				},
+			},
+		},
+	}
+}
+
+func jsonContent(schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"application/json": map[string]interface{}{"schema": schema}}
+}
+
+// Los errores se responden con un APIError
+func errorResponse(status int, schemas map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"description": http.StatusText(status),
+		"content":     jsonContent(schemaFor(reflect.TypeOf(APIError{}), schemas)),
+	}
+}
+
+// operationID: "POST /sessions/{id}/code" → "postSessionsIdCode"
+func operationID(op apiOperation) string {
+	id := strings.ToLower(op.Method)
+	for _, part := range strings.FieldsFunc(op.Path, func(r rune) bool { return strings.ContainsRune("/{}.", r) }) {
+		id += strings.ToUpper(part[:1]) + part[1:]
+	}
+	return id
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaFor devuelve el esquema de t. Los structs con nombre se agregan a
+// schemas y se referencian con $ref, lo que también resuelve los tipos
+// recursivos como APIParseNode.
+func schemaFor(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	if t.Kind() == reflect.Ptr {
+		return schemaFor(t.Elem(), schemas)
+	}
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case t.Kind() == reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return map[string]interface{}{"type": "array", "items": schemaFor(t.Elem(), schemas)}
+	case t.Kind() == reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": schemaFor(t.Elem(), schemas)}
+	case t.Kind() == reflect.Struct:
+		if _, ok := schemas[t.Name()]; !ok {
+			schemas[t.Name()] = nil // marca antes de recorrer los campos
+			schemas[t.Name()] = structSchema(t, schemas)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// structSchema recorre los campos exportados con su nombre json. Los structs
+// embebidos sin etiqueta aportan sus campos, como hace encoding/json; los
+// campos con omitempty o de tipo puntero no son obligatorios.
+func structSchema(t reflect.Type, schemas map[string]interface{}) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []string
+	var collect func(t reflect.Type)
+	collect = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := f.Tag.Get("json")
+			if f.Anonymous && tag == "" {
+				collect(f.Type)
+				continue
+			}
+			if !f.IsExported() || tag == "-" {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			if name == "" {
+				name = f.Name
+			}
+			properties[name] = schemaFor(f.Type, schemas)
+			if !strings.Contains(opts, "omitempty") && f.Type.Kind() != reflect.Ptr {
+				required = append(required, name)
+			}
+		}
+	}
+	collect(t)
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
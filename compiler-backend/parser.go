@@ -0,0 +1,2321 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ─────────────────────── Parser descendente recursivo ─────────────────────
+//
+// El parser construye un árbol sintáctico real a partir de los tokens del
+// lexer. C++ y JavaScript comparten la gramática de "familia C" (bloques con
+// llaves, expresiones con precedencia, declaraciones), y TypeScript extiende
+// la de JavaScript (parser_typescript.go); Python usa su propia gramática
+// basada en indentación (parser_python.go). Cada nodo guarda su
+// tipo gramatical (Kind), un texto representativo (Label) y su posición.
+
+// Límite de errores sintácticos reportados por el parser para evitar cascadas
+const maxParserErrors = 20
+
+type Parser struct {
+	src        string
+	language   string
+	raw        []Token // tokens originales, usados para el chequeo de balanceo
+	toks       []Token // tokens significativos (sin comentarios ni desconocidos)
+	pos        int
+	end        int // límite superior del cursor (líneas lógicas en Python)
+	lineStarts []int
+	errors     []CompilerError
+	stmtErr    bool
+	className  string   // clase C++ actual, para reconocer constructores
+	pyLines    []pyLine // líneas lógicas de Python
+	li         int      // línea lógica actual de Python
+	// En encabezados de if/for/switch de Go un '{' abre el bloque y no un
+	// literal compuesto
+	noCompositeLit bool
+	// Reanálisis incremental: índice del token donde empieza la primera
+	// sentencia de nivel superior a analizar, y condición para detenerse al
+	// alcanzar una sentencia del árbol anterior que sigue siendo válida
+	resumeAt int
+	resync   func(pos int) bool
+	// Dentro de `declare` de TypeScript las declaraciones no llevan valor
+	ambient bool
+}
+
+func NewParser(t []Token, lang, src string) *Parser {
+	p := &Parser{src: src, language: lang, raw: t}
+	p.toks = mergeOperatorTokens(significantTokens(t), lang)
+	p.end = len(p.toks)
+	p.lineStarts = computeLineStarts(src)
+	return p
+}
+
+func (p *Parser) Parse() ([]ParseNode, []CompilerError) {
+	errors, cutoff := p.checkBalance()
+
+	root, ok := languageFor(p.language).Parse(p)
+	if !ok {
+		// Sin gramática para el lenguaje: se conserva la lista plana de tokens
+		var n []ParseNode
+		for _, tk := range p.raw {
+			n = append(n, ParseNode{Kind: "Token", Label: tk.Lexeme, Pos: tk.Start, End: tk.End})
+		}
+		return n, errors
+	}
+
+	// Un nodo que no consumió tokens (lo que falta después de un error)
+	// termina donde terminó el token anterior, antes de su Pos; se lo deja
+	// vacío para que src[Pos:End] sea siempre válido
+	emptyBackwardRanges(&root)
+
+	// Después del primer delimitador desbalanceado los errores del parser son
+	// ruido en cascada; se reportan solo los anteriores a ese punto.
+	for _, e := range p.errors {
+		if e.Pos < cutoff {
+			errors = append(errors, e)
+		}
+	}
+	return []ParseNode{root}, errors
+}
+
+// emptyBackwardRanges deja End = Pos en los nodos de n que terminan antes
+// de empezar
+func emptyBackwardRanges(n *ParseNode) {
+	if n.End < n.Pos {
+		n.End = n.Pos
+	}
+	for i := range n.Children {
+		emptyBackwardRanges(&n.Children[i])
+	}
+}
+
+// checkBalance verifica paréntesis, llaves y corchetes sobre los tokens crudos.
+// También devuelve la posición del primer delimitador desbalanceado.
+func (p *Parser) checkBalance() ([]CompilerError, int) {
+	var errors []CompilerError
+	cutoff := len(p.src) + 1
+	var open []Token // delimitadores abiertos
+	markCutoff := func(pos int) {
+		if pos < cutoff {
+			cutoff = pos
+		}
+	}
+
+	parentheses := 0
+	braces := 0
+	brackets := 0
+
+	for i, tk := range p.raw {
+		switch tk.Lexeme {
+		case "(", "{", "[":
+			open = append(open, tk)
+		case ")", "}", "]":
+			// Se busca la apertura correspondiente; las aperturas intermedias
+			// quedaron sin cerrar
+			matched := -1
+			for j := len(open) - 1; j >= 0; j-- {
+				if closingDelimiter[open[j].Lexeme] == tk.Lexeme {
+					matched = j
+					break
+				}
+			}
+			if matched < 0 {
+				markCutoff(tk.Start)
+				break
+			}
+			for _, unclosed := range open[matched+1:] {
+				markCutoff(unclosed.Start)
+			}
+			open = open[:matched]
+		}
+		switch tk.Lexeme {
+		case "(":
+			parentheses++
+		case ")":
+			parentheses--
+			if parentheses < 0 {
+				errors = append(errors, CompilerError{
+					Message:  "Error sintáctico: Paréntesis de cierre sin apertura correspondiente",
+					Severity: "error",
+					Type:     "sintactico",
+					Pos:      tk.Start,
+					Code:     CodeUnmatchedClosing,
+				})
+			}
+		case "{":
+			braces++
+		case "}":
+			braces--
+			if braces < 0 {
+				errors = append(errors, CompilerError{
+					Message:  "Error sintáctico: Llave de cierre sin apertura correspondiente",
+					Severity: "error",
+					Type:     "sintactico",
+					Pos:      tk.Start,
+					Code:     CodeUnmatchedClosing,
+				})
+			}
+		case "[":
+			brackets++
+		case "]":
+			brackets--
+			if brackets < 0 {
+				errors = append(errors, CompilerError{
+					Message:  "Error sintáctico: Corchete de cierre sin apertura correspondiente",
+					Severity: "error",
+					Type:     "sintactico",
+					Pos:      tk.Start,
+					Code:     CodeUnmatchedClosing,
+				})
+			}
+		case ";":
+			if i > 0 && p.raw[i-1].Lexeme == ";" {
+				errors = append(errors, CompilerError{
+					Message:  "Error sintáctico: Punto y coma duplicado",
+					Severity: "warning",
+					Type:     "sintactico",
+					Pos:      tk.Start,
+					Code:     CodeDuplicateSemicolon,
+				})
+			}
+		}
+	}
+
+	// Verificar balanceo al final
+	if parentheses > 0 {
+		errors = append(errors, CompilerError{
+			Message:  fmt.Sprintf("Error sintáctico: %d paréntesis sin cerrar", parentheses),
+			Severity: "error",
+			Type:     "sintactico",
+			Pos:      0,
+			Code:     CodeUnclosedDelimiter,
+		})
+	}
+	if braces > 0 {
+		errors = append(errors, CompilerError{
+			Message:  fmt.Sprintf("Error sintáctico: %d llaves sin cerrar", braces),
+			Severity: "error",
+			Type:     "sintactico",
+			Pos:      0,
+			Code:     CodeUnclosedDelimiter,
+		})
+	}
+	if brackets > 0 {
+		errors = append(errors, CompilerError{
+			Message:  fmt.Sprintf("Error sintáctico: %d corchetes sin cerrar", brackets),
+			Severity: "error",
+			Type:     "sintactico",
+			Pos:      0,
+			Code:     CodeUnclosedDelimiter,
+		})
+	}
+
+	// Error de tokens vacíos
+	if len(p.raw) == 0 {
+		errors = append(errors, CompilerError{
+			Message:  "Error sintáctico: No se encontraron tokens válidos",
+			Severity: "error",
+			Type:     "sintactico",
+			Pos:      0,
+			Code:     CodeEmptyProgram,
+		})
+	}
+
+	for _, unclosed := range open {
+		markCutoff(unclosed.Start)
+	}
+	return errors, cutoff
+}
+
+// ───────────────────────────── Utilidades ────────────────────────────────
+
+var closingDelimiter = map[string]string{"(": ")", "[": "]", "{": "}"}
+
+func significantTokens(tokens []Token) []Token {
+	var out []Token
+	for _, tk := range tokens {
+		if tk.Type == COMMENT || tk.Type == UNKNOWN || tk.Type == WHITESPACE {
+			continue
+		}
+		out = append(out, tk)
+	}
+	return out
+}
+
+// Operadores compuestos que el lexer entrega como tokens separados
+var compoundOperators = map[string][]string{
+	"cpp":        {"<<=", ">>=", "+=", "-=", "*=", "/=", "%=", "&=", "|=", "^=", "..."},
+	"javascript": {">>>=", "**=", "&&=", "||=", "??=", "+=", "-=", "*=", "/=", "%=", "&=", "|=", "^=", "??", "?.", "..."},
+	"typescript": {">>>=", "**=", "&&=", "||=", "??=", "+=", "-=", "*=", "/=", "%=", "&=", "|=", "^=", "??", "?.", "..."},
+	"python":     {"**=", "//=", ">>=", "<<=", "+=", "-=", "*=", "/=", "%=", "&=", "|=", "^=", "@=", "->", ":=", "..."},
+	"go":         {"&^=", "<<=", ">>=", "+=", "-=", "*=", "/=", "%=", "&=", "|=", "^=", "&^", ":=", "..."},
+}
+
+// mergeOperatorTokens une operadores/delimitadores adyacentes que forman un
+// operador compuesto conocido (p. ej. '+' '=' → '+=').
+func mergeOperatorTokens(tokens []Token, lang string) []Token {
+	ops := compoundOperators[lang]
+	var out []Token
+	for i := 0; i < len(tokens); i++ {
+		tk := tokens[i]
+		if tk.Type == OPERATOR || tk.Type == DELIMITER {
+			for _, op := range ops {
+				if !strings.HasPrefix(op, tk.Lexeme) || op == tk.Lexeme {
+					continue
+				}
+				text, j := tk.Lexeme, i
+				for j+1 < len(tokens) && len(text) < len(op) && tokens[j+1].Start == tokens[j].End &&
+					(tokens[j+1].Type == OPERATOR || tokens[j+1].Type == DELIMITER) &&
+					strings.HasPrefix(op, text+tokens[j+1].Lexeme) {
+					j++
+					text += tokens[j].Lexeme
+				}
+				if text == op {
+					tk = Token{Type: OPERATOR, Lexeme: op, Start: tk.Start, End: tokens[j].End,
+						Line: tk.Line, Column: tk.Column, EndLine: tokens[j].EndLine, EndColumn: tokens[j].EndColumn}
+					i = j
+					break
+				}
+			}
+		}
+		out = append(out, tk)
+	}
+	return out
+}
+
+func computeLineStarts(src string) []int {
+	starts := []int{0}
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return starts
+}
+
+// lineOf devuelve la línea (base 1) de una posición en bytes
+func (p *Parser) lineOf(pos int) int {
+	return sort.Search(len(p.lineStarts), func(i int) bool { return p.lineStarts[i] > pos })
+}
+
+func newNode(kind, label string, pos, end int, children ...ParseNode) ParseNode {
+	return ParseNode{Kind: kind, Label: label, Pos: pos, End: end, Children: children}
+}
+
+func (p *Parser) eofToken() Token {
+	at := len(p.src)
+	if p.end < len(p.toks) {
+		at = p.toks[p.end].Start
+	}
+	return Token{Type: UNKNOWN, Lexeme: "", Start: at, End: at}
+}
+
+func (p *Parser) atEnd() bool { return p.pos >= p.end }
+
+func (p *Parser) cur() Token { return p.peek(0) }
+
+func (p *Parser) peek(k int) Token {
+	if p.pos+k >= p.end || p.pos+k < 0 {
+		return p.eofToken()
+	}
+	return p.toks[p.pos+k]
+}
+
+func (p *Parser) is(lexemes ...string) bool {
+	if p.atEnd() {
+		return false
+	}
+	for _, l := range lexemes {
+		if p.toks[p.pos].Lexeme == l {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Parser) next() Token {
+	tk := p.cur()
+	if !p.atEnd() {
+		p.pos++
+	}
+	return tk
+}
+
+func (p *Parser) accept(lexeme string) bool {
+	if p.is(lexeme) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// prevEnd es la posición final del último token consumido
+func (p *Parser) prevEnd() int {
+	if p.pos > 0 && p.pos-1 < len(p.toks) {
+		return p.toks[p.pos-1].End
+	}
+	return 0
+}
+
+func (p *Parser) errorAt(pos int, msg string) {
+	p.errorWithCode(pos, CodeUnexpectedToken, "", msg)
+}
+
+// errorWithCode reporta un error sintáctico con un código y una sugerencia
+// concretos; errorAt usa CodeUnexpectedToken y la sugerencia del catálogo
+func (p *Parser) errorWithCode(pos int, code, hint, msg string) {
+	if p.stmtErr {
+		return
+	}
+	p.stmtErr = true
+	if len(p.errors) >= maxParserErrors {
+		return
+	}
+	p.errors = append(p.errors, CompilerError{
+		Message:  "Error sintáctico: " + msg,
+		Severity: "error",
+		Type:     "sintactico",
+		Pos:      pos,
+		Code:     code,
+		Hint:     hint,
+	})
+}
+
+func (p *Parser) expect(lexeme, context string) bool {
+	if p.accept(lexeme) {
+		return true
+	}
+	p.errorWithCode(p.prevEnd(), CodeUnexpectedToken, "insert:"+lexeme,
+		fmt.Sprintf("Se esperaba '%s' %s, se encontró %s", lexeme, context, p.foundText()))
+	return false
+}
+
+// foundText describe el token actual para los mensajes de error
+func (p *Parser) foundText() string {
+	switch {
+	case !p.atEnd():
+		return "'" + p.cur().Lexeme + "'"
+	case p.end < len(p.toks):
+		return "fin de línea"
+	}
+	return "fin de archivo"
+}
+
+func isName(tk Token) bool { return tk.Type == IDENTIFIER }
+
+func (p *Parser) expectName(context string) Token {
+	if isName(p.cur()) {
+		return p.next()
+	}
+	p.errorAt(p.cur().Start, fmt.Sprintf("Se esperaba un identificador %s, se encontró %s", context, p.foundText()))
+	return Token{Start: p.cur().Start, End: p.cur().Start}
+}
+
+// sourceText devuelve el texto fuente entre dos posiciones, compactando espacios
+func (p *Parser) sourceText(start, end int) string {
+	if start < 0 || end > len(p.src) || start >= end {
+		return ""
+	}
+	return strings.Join(strings.Fields(p.src[start:end]), " ")
+}
+
+// ──────────────────────────── Expresiones ────────────────────────────────
+
+type binaryOp struct {
+	prec  int
+	right bool
+}
+
+var cBinaryOps = map[string]binaryOp{
+	"||": {1, false}, "??": {1, false},
+	"&&": {2, false},
+	"|":  {3, false},
+	"^":  {4, false},
+	"&":  {5, false},
+	"==": {6, false}, "!=": {6, false}, "===": {6, false}, "!==": {6, false},
+	"<": {7, false}, ">": {7, false}, "<=": {7, false}, ">=": {7, false},
+	"instanceof": {7, false}, "in": {7, false},
+	"<<": {8, false}, ">>": {8, false}, ">>>": {8, false},
+	"+": {9, false}, "-": {9, false},
+	"*": {10, false}, "/": {10, false}, "%": {10, false},
+	"**": {11, true},
+}
+
+var pyBinaryOps = map[string]binaryOp{
+	"or":  {1, false},
+	"and": {2, false},
+	"<":   {4, false}, ">": {4, false}, "==": {4, false}, ">=": {4, false}, "<=": {4, false},
+	"!=": {4, false}, "in": {4, false}, "not in": {4, false}, "is": {4, false}, "is not": {4, false},
+	"|":  {5, false},
+	"^":  {6, false},
+	"&":  {7, false},
+	"<<": {8, false}, ">>": {8, false},
+	"+": {9, false}, "-": {9, false},
+	"*": {10, false}, "/": {10, false}, "//": {10, false}, "%": {10, false}, "@": {10, false},
+	"**": {12, true},
+}
+
+var assignmentOps = map[string]bool{
+	"=": true, "+=": true, "-=": true, "*=": true, "/=": true, "%=": true,
+	"&=": true, "|=": true, "^=": true, "<<=": true, ">>=": true, ">>>=": true,
+	"**=": true, "//=": true, "@=": true, "&&=": true, "||=": true, "??=": true,
+}
+
+// currentBinaryOp reconoce el operador binario en la posición actual
+// (incluyendo los operadores de dos palabras de Python) y su longitud.
+func (p *Parser) currentBinaryOp() (string, binaryOp, int, bool) {
+	if p.atEnd() {
+		return "", binaryOp{}, 0, false
+	}
+	lex := p.cur().Lexeme
+	if p.language == "python" {
+		if lex == "not" && p.peek(1).Lexeme == "in" {
+			return "not in", pyBinaryOps["not in"], 2, true
+		}
+		if lex == "is" && p.peek(1).Lexeme == "not" {
+			return "is not", pyBinaryOps["is not"], 2, true
+		}
+		op, ok := pyBinaryOps[lex]
+		return lex, op, 1, ok
+	}
+	if p.language == "go" {
+		// Un operador al inicio de otra línea pertenece a la sentencia siguiente
+		if p.goLineBreak() {
+			return "", binaryOp{}, 0, false
+		}
+		op, ok := goBinaryOps[lex]
+		return lex, op, 1, ok && p.cur().Type == OPERATOR
+	}
+	if lex == "in" && !p.isJS() {
+		return "", binaryOp{}, 0, false
+	}
+	op, ok := cBinaryOps[lex]
+	return lex, op, 1, ok
+}
+
+func (p *Parser) parseExpression() ParseNode {
+	switch p.language {
+	case "python":
+		return p.parsePyTest()
+	case "go":
+		// En Go la asignación es una sentencia, no una expresión
+		return p.parseBinary(1)
+	}
+	return p.parseAssignment()
+}
+
+func (p *Parser) parseAssignment() ParseNode {
+	left := p.parseTernary()
+	if p.atEnd() {
+		return left
+	}
+	if op := p.cur().Lexeme; assignmentOps[op] && p.cur().Type == OPERATOR {
+		p.next()
+		right := p.parseAssignment()
+		return newNode("Assign", op, left.Pos, right.End, left, right)
+	}
+	return left
+}
+
+func (p *Parser) parseTernary() ParseNode {
+	cond := p.parseBinary(1)
+	if !p.is("?") {
+		return cond
+	}
+	p.next()
+	then := p.parseAssignment()
+	p.expect(":", "en la expresión condicional")
+	els := p.parseAssignment()
+	return newNode("Conditional", "?:", cond.Pos, els.End, cond, then, els)
+}
+
+func (p *Parser) parseBinary(minPrec int) ParseNode {
+	left := p.parseUnary()
+	for {
+		// x as T y x satisfies T tienen la precedencia de los relacionales
+		if p.isTS() && minPrec <= 7 && p.is("as", "satisfies") && p.sameLine() {
+			kw := p.next()
+			typ := p.parseTSType()
+			left = newNode("AsExpr", kw.Lexeme, left.Pos, typ.End, left, typ)
+			continue
+		}
+		op, info, width, ok := p.currentBinaryOp()
+		if !ok || info.prec < minPrec {
+			return left
+		}
+		p.pos += width
+		nextMin := info.prec + 1
+		if info.right {
+			nextMin = info.prec
+		}
+		right := p.parseBinary(nextMin)
+		kind := "BinaryExpr"
+		if p.language == "python" && (op == "and" || op == "or") || op == "&&" || op == "||" || op == "??" {
+			kind = "LogicalExpr"
+		}
+		left = newNode(kind, op, left.Pos, right.End, left, right)
+	}
+}
+
+var cUnaryOps = map[string]bool{
+	"!": true, "-": true, "+": true, "~": true, "++": true, "--": true,
+	"*": true, "&": true, "typeof": true, "void": true, "delete": true,
+	"new": true, "await": true, "sizeof": true, "...": true,
+}
+
+func (p *Parser) parseUnary() ParseNode {
+	tk := p.cur()
+	if p.language == "python" {
+		switch tk.Lexeme {
+		case "not":
+			p.next()
+			operand := p.parseBinary(3)
+			return newNode("UnaryExpr", "not", tk.Start, operand.End, operand)
+		case "-", "+", "~":
+			p.next()
+			operand := p.parseBinary(11)
+			return newNode("UnaryExpr", tk.Lexeme, tk.Start, operand.End, operand)
+		case "await":
+			p.next()
+			operand := p.parseUnary()
+			return newNode("UnaryExpr", "await", tk.Start, operand.End, operand)
+		}
+		return p.parsePostfix(p.parsePrimary())
+	}
+
+	if p.language == "go" {
+		if !p.atEnd() && goUnaryOps[tk.Lexeme] && tk.Type == OPERATOR {
+			p.next()
+			operand := p.parseUnary()
+			return newNode("UnaryExpr", tk.Lexeme, tk.Start, operand.End, operand)
+		}
+		return p.parsePostfix(p.parsePrimary())
+	}
+
+	if !p.atEnd() && cUnaryOps[tk.Lexeme] && tk.Type != STRING && tk.Type != NUMBER {
+		p.next()
+		if tk.Lexeme == "new" {
+			return p.parseNew(tk)
+		}
+		if tk.Lexeme == "delete" && p.is("[") {
+			p.next()
+			p.expect("]", "en delete[]")
+		}
+		if tk.Lexeme == "sizeof" && p.is("(") && p.looksLikeCppType(1) {
+			p.next()
+			typ := p.parseCppTypeSpec()
+			p.expect(")", "en sizeof")
+			return newNode("UnaryExpr", "sizeof", tk.Start, p.prevEnd(), typ)
+		}
+		operand := p.parseUnary()
+		kind := "UnaryExpr"
+		if tk.Lexeme == "..." {
+			kind = "Spread"
+		}
+		return newNode(kind, tk.Lexeme, tk.Start, operand.End, operand)
+	}
+	return p.parsePostfix(p.parsePrimary())
+}
+
+// parseNew analiza `new Tipo(args)` en C++ y JavaScript
+func (p *Parser) parseNew(kw Token) ParseNode {
+	var callee ParseNode
+	if p.language == "cpp" {
+		callee = p.parseCppTypeSpec()
+	} else {
+		callee = p.parsePrimary()
+		for p.is(".") {
+			p.next()
+			name := p.next()
+			callee = newNode("Member", name.Lexeme, callee.Pos, name.End, callee)
+		}
+	}
+	node := newNode("New", callee.Label, kw.Start, callee.End, callee)
+	var typeArgs []ParseNode
+	if p.isTS() && p.is("<") {
+		typeArgs = append(typeArgs, p.parseTSTypeArgs())
+		node.End = p.prevEnd()
+	}
+	if p.is("(") {
+		args := p.parseArguments("(", ")")
+		node.Children = append(node.Children, args)
+		node.End = args.End
+	} else if p.is("[") {
+		p.next()
+		size := p.parseExpression()
+		p.expect("]", "en new[]")
+		node.Children = append(node.Children, size)
+		node.End = p.prevEnd()
+	}
+	node.Children = append(node.Children, typeArgs...)
+	return p.parsePostfix(node)
+}
+
+func (p *Parser) parsePostfix(expr ParseNode) ParseNode {
+	for !p.atEnd() {
+		tk := p.cur()
+		if p.language == "go" {
+			if p.goLineBreak() && tk.Lexeme != "." {
+				return expr
+			}
+			switch {
+			case tk.Lexeme == "." && p.peek(1).Lexeme == "(":
+				expr = p.parseGoTypeAssert(expr)
+				continue
+			case tk.Lexeme == "[":
+				expr = p.parseGoIndex(expr)
+				continue
+			case tk.Lexeme == "{":
+				if p.noCompositeLit || !goTypeExpr(expr) {
+					return expr
+				}
+				expr = p.parseGoCompositeLit(expr)
+				continue
+			}
+		}
+		switch {
+		case tk.Lexeme == "(":
+			args := p.parseArguments("(", ")")
+			expr = newNode("Call", expr.Label, expr.Pos, args.End, expr, args)
+		case tk.Lexeme == "[":
+			p.next()
+			var index ParseNode
+			if p.language == "python" {
+				index = p.parsePySubscript()
+			} else {
+				index = p.parseExpression()
+			}
+			p.expect("]", "al cerrar el índice")
+			expr = newNode("Index", "[]", expr.Pos, p.prevEnd(), expr, index)
+		case tk.Lexeme == "." || tk.Lexeme == "->" || tk.Lexeme == "::" || tk.Lexeme == "?.":
+			p.next()
+			if tk.Lexeme == "?." && p.is("(", "[") {
+				continue
+			}
+			if p.is("~") {
+				p.next()
+			}
+			name := p.next()
+			if name.Type != IDENTIFIER && name.Type != KEYWORD {
+				p.errorAt(name.Start, fmt.Sprintf("Se esperaba un nombre de miembro después de '%s'", tk.Lexeme))
+			}
+			expr = newNode("Member", name.Lexeme, expr.Pos, name.End, expr)
+		case (tk.Lexeme == "++" || tk.Lexeme == "--") && p.language != "python":
+			p.next()
+			expr = newNode("PostfixExpr", tk.Lexeme, expr.Pos, tk.End, expr)
+		case tk.Lexeme == "<" && p.language == "cpp" && p.templateArgsAhead():
+			args := p.skipTemplateArgs()
+			expr.Label += args
+			expr.End = p.prevEnd()
+		case tk.Lexeme == "<" && p.isTS() && p.typeArgsAhead():
+			// Llamada genérica f<T>(x): los argumentos de tipo van al final
+			typeArgs := p.parseTSTypeArgs()
+			args := p.parseArguments("(", ")")
+			expr = newNode("Call", expr.Label, expr.Pos, args.End, expr, args, typeArgs)
+		case tk.Lexeme == "!" && p.isTS() && p.sameLine():
+			// Aserción de no nulo: x!
+			p.next()
+			expr = newNode("NonNull", "!", expr.Pos, tk.End, expr)
+		default:
+			return expr
+		}
+	}
+	return expr
+}
+
+// parseArguments analiza una lista de argumentos delimitada
+func (p *Parser) parseArguments(open, close string) ParseNode {
+	start := p.cur().Start
+	p.expect(open, "")
+	args := newNode("Arguments", "", start, start)
+	saved := p.noCompositeLit
+	p.noCompositeLit = false
+	defer func() { p.noCompositeLit = saved }()
+	for !p.atEnd() && !p.is(close) {
+		var arg ParseNode
+		switch p.language {
+		case "python":
+			arg = p.parsePyArgument()
+		case "go":
+			arg = p.parseExpression()
+			if p.is("...") {
+				// f(lista...)
+				dots := p.next()
+				arg = newNode("Spread", "...", arg.Pos, dots.End, arg)
+			}
+		default:
+			arg = p.parseAssignment()
+		}
+		args.Children = append(args.Children, arg)
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.expect(close, "al cerrar la lista de argumentos")
+	args.End = p.prevEnd()
+	return args
+}
+
+var literalKeywords = map[string]bool{
+	"true": true, "false": true, "null": true, "nullptr": true, "undefined": true,
+	"True": true, "False": true, "None": true, "NULL": true, "nil": true,
+}
+
+// Palabras clave que también son válidas como identificadores
+var contextualKeywords = map[string]bool{
+	"of": true, "as": true, "from": true, "async": true, "static": true,
+	"get": true, "set": true, "default": true, "constructor": true,
+}
+
+// Palabras clave de TypeScript que solo son reservadas en posiciones de tipo
+// o al comienzo de una declaración (type, number, readonly...)
+var tsContextualKeywords = map[string]bool{
+	"type": true, "namespace": true, "module": true, "declare": true, "abstract": true,
+	"readonly": true, "keyof": true, "infer": true, "is": true, "asserts": true,
+	"satisfies": true, "unique": true, "override": true, "any": true, "unknown": true,
+	"never": true, "number": true, "string": true, "boolean": true, "symbol": true,
+	"bigint": true, "object": true, "global": true, "accessor": true,
+}
+
+// isContextual indica si la palabra clave puede usarse como identificador
+func (p *Parser) isContextual(lexeme string) bool {
+	return contextualKeywords[lexeme] || p.isTS() && tsContextualKeywords[lexeme]
+}
+
+func (p *Parser) parsePrimary() ParseNode {
+	tk := p.cur()
+	if p.atEnd() {
+		p.errorAt(tk.Start, "Se esperaba una expresión, se encontró fin de archivo")
+		return newNode("Error", "", tk.Start, tk.Start)
+	}
+
+	switch {
+	case tk.Type == NUMBER:
+		p.next()
+		return newNode("Literal", tk.Lexeme, tk.Start, tk.End)
+	case tk.Type == STRING:
+		return p.parseStringLiteral()
+	case literalKeywords[tk.Lexeme]:
+		p.next()
+		return newNode("Literal", tk.Lexeme, tk.Start, tk.End)
+	case tk.Lexeme == "this" || tk.Lexeme == "super":
+		p.next()
+		return newNode("Identifier", tk.Lexeme, tk.Start, tk.End)
+	}
+
+	if p.language == "python" {
+		return p.parsePyAtom()
+	}
+	if p.language == "go" {
+		return p.parseGoOperand()
+	}
+
+	switch tk.Lexeme {
+	case "(":
+		if p.isJS() && p.arrowAhead() {
+			return p.parseArrowFunction()
+		}
+		if p.language == "cpp" && p.looksLikeCppType(1) && p.castAhead() {
+			p.next()
+			typ := p.parseCppTypeSpec()
+			p.expect(")", "en la conversión de tipo")
+			operand := p.parseUnary()
+			return newNode("Cast", typ.Label, tk.Start, operand.End, typ, operand)
+		}
+		p.next()
+		expr := p.parseExpression()
+		for p.language == "cpp" && p.accept(",") {
+			right := p.parseAssignment()
+			expr = newNode("Sequence", ",", expr.Pos, right.End, expr, right)
+		}
+		p.expect(")", "al cerrar la expresión")
+		return expr
+	case "[":
+		if p.language == "cpp" {
+			return p.parseCppLambda()
+		}
+		list := p.parseArguments("[", "]")
+		list.Kind, list.Label = "ArrayLiteral", "[]"
+		return list
+	case "{":
+		if p.language == "cpp" {
+			list := p.parseArguments("{", "}")
+			list.Kind, list.Label = "InitList", "{}"
+			return list
+		}
+		return p.parseObjectLiteral()
+	case "function":
+		return p.parseJSFunction(true)
+	case "async":
+		if p.peek(1).Lexeme == "function" {
+			p.next()
+			fn := p.parseJSFunction(true)
+			fn.Pos = tk.Start
+			return fn
+		}
+		if p.peek(1).Lexeme == "(" || isName(p.peek(1)) && p.peek(2).Lexeme == "=>" {
+			p.next()
+			fn := p.parseArrowFunction()
+			fn.Pos = tk.Start
+			return fn
+		}
+	case "class":
+		if p.isJS() {
+			return p.parseJSClass()
+		}
+	case "<":
+		if p.isTS() {
+			if fn, ok := p.parseTSGenericArrow(); ok {
+				return fn
+			}
+		}
+	}
+
+	if tk.Type == IDENTIFIER || tk.Type == KEYWORD {
+		p.next()
+		if p.isJS() && p.is("=>") {
+			p.pos--
+			return p.parseArrowFunction()
+		}
+		if tk.Type == KEYWORD && !isCppTypeKeyword(tk.Lexeme) && !p.isContextual(tk.Lexeme) {
+			p.errorAt(tk.Start, fmt.Sprintf("Palabra reservada '%s' inesperada en una expresión", tk.Lexeme))
+		}
+		return newNode("Identifier", tk.Lexeme, tk.Start, tk.End)
+	}
+
+	// Los terminadores no se consumen para que la recuperación no salte la
+	// siguiente sentencia
+	if !p.is(";", "}", ")", "]") {
+		p.next()
+	}
+	p.errorAt(tk.Start, fmt.Sprintf("Token inesperado '%s' en una expresión", tk.Lexeme))
+	return newNode("Error", tk.Lexeme, tk.Start, tk.End)
+}
+
+// parseStringLiteral une literales de cadena adyacentes ("a" "b")
+func (p *Parser) parseStringLiteral() ParseNode {
+	first := p.next()
+	node := newNode("Literal", first.Lexeme, first.Start, first.End)
+	for !p.atEnd() && p.cur().Type == STRING {
+		tk := p.next()
+		node.Label += tk.Lexeme
+		node.End = tk.End
+	}
+	return node
+}
+
+func (p *Parser) parseObjectLiteral() ParseNode {
+	start := p.cur().Start
+	p.expect("{", "")
+	obj := newNode("ObjectLiteral", "{}", start, start)
+	for !p.atEnd() && !p.is("}") {
+		if p.is("...") {
+			obj.Children = append(obj.Children, p.parseUnary())
+		} else {
+			obj.Children = append(obj.Children, p.parseObjectProperty())
+		}
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.expect("}", "al cerrar el objeto")
+	obj.End = p.prevEnd()
+	return obj
+}
+
+func (p *Parser) parseObjectProperty() ParseNode {
+	keyTok := p.cur()
+	if p.is("get", "set", "async") && p.peek(1).Lexeme != ":" && p.peek(1).Lexeme != "(" &&
+		p.peek(1).Lexeme != "," && p.peek(1).Lexeme != "}" {
+		p.next()
+	}
+	var key ParseNode
+	if p.is("[") {
+		p.next()
+		key = p.parseAssignment()
+		p.expect("]", "en la clave calculada")
+	} else {
+		tk := p.next()
+		key = newNode("Identifier", tk.Lexeme, tk.Start, tk.End)
+	}
+	switch {
+	case p.is("("):
+		params := p.parseJSParams()
+		method := newNode("Method", key.Label, keyTok.Start, params.End, params)
+		p.annotate(&method)
+		body := p.parseBlock()
+		method.Children = append(method.Children, body)
+		method.End = body.End
+		return method
+	case p.accept(":"):
+		value := p.parseAssignment()
+		return newNode("Property", key.Label, keyTok.Start, value.End, value)
+	case p.is("="):
+		// Valor por defecto en un patrón de desestructuración
+		p.next()
+		value := p.parseAssignment()
+		return newNode("Property", key.Label, keyTok.Start, value.End, value)
+	}
+	return newNode("Property", key.Label, keyTok.Start, key.End)
+}
+
+// ─────────────────────── Gramática familia C ─────────────────────────────
+
+func (p *Parser) parseCProgram() ParseNode {
+	root := newNode("Program", p.language, 0, len(p.src))
+	p.pos = p.resumeAt
+	root.Children = p.parseStatementList(p.resynced)
+	return root
+}
+
+// resynced indica si el análisis incremental llegó a una sentencia de nivel
+// superior del árbol anterior; desde ahí el resto del árbol no cambia
+func (p *Parser) resynced() bool {
+	if p.resync == nil {
+		return false
+	}
+	if p.pyLines != nil {
+		return p.li < len(p.pyLines) && p.resync(p.toks[p.pyLines[p.li].start].Start)
+	}
+	return !p.atEnd() && p.resync(p.cur().Start)
+}
+
+// parseStatementList analiza sentencias hasta que stop() sea verdadero o se
+// agoten los tokens, recuperándose de errores en el siguiente ';' o '}'.
+func (p *Parser) parseStatementList(stop func() bool) []ParseNode {
+	var stmts []ParseNode
+	for !p.atEnd() && !stop() {
+		start := p.pos
+		stmt, ok := p.parseCStatement()
+		if ok {
+			stmts = append(stmts, stmt)
+		}
+		if p.stmtErr {
+			p.synchronize()
+			p.stmtErr = false
+		}
+		if p.pos == start {
+			p.pos++
+		}
+	}
+	return stmts
+}
+
+// Palabras que inician una sentencia; la recuperación se detiene en ellas
+// cuando aparecen al comienzo de una línea nueva.
+var statementStarters = map[string]bool{
+	"if": true, "for": true, "while": true, "do": true, "return": true, "switch": true,
+	"try": true, "throw": true, "break": true, "continue": true, "class": true,
+	"function": true, "var": true, "let": true, "const": true, "struct": true,
+}
+
+func (p *Parser) synchronize() {
+	// La sentencia ya terminó en su ';'
+	if p.pos > 0 && p.pos <= len(p.toks) && p.toks[p.pos-1].Lexeme == ";" {
+		return
+	}
+	for !p.atEnd() {
+		if p.is(";") {
+			p.next()
+			return
+		}
+		if p.is("}", "{") {
+			return
+		}
+		tk := p.cur()
+		if p.language == "go" && p.goLineBreak() {
+			// En Go cada línea nueva comienza otra sentencia
+			return
+		}
+		if p.pos > 0 && (statementStarters[tk.Lexeme] || isCppTypeKeyword(tk.Lexeme)) &&
+			p.lineOf(tk.Start) > p.lineOf(p.prevEnd()-1) {
+			return
+		}
+		p.next()
+	}
+}
+
+// endStatement exige ';' (C++) o aplica la inserción automática de ';' de JS
+func (p *Parser) endStatement(context string) {
+	if p.accept(";") {
+		return
+	}
+	if p.isJS() {
+		if p.atEnd() || p.is("}") || p.lineOf(p.cur().Start) > p.lineOf(p.prevEnd()-1) {
+			return
+		}
+	}
+	p.expect(";", context)
+}
+
+func (p *Parser) parseBlock() ParseNode {
+	start := p.cur().Start
+	if !p.expect("{", "para abrir el bloque") {
+		return newNode("Block", "{}", start, start)
+	}
+	block := newNode("Block", "{}", start, start)
+	block.Children = p.parseStatementList(func() bool { return p.is("}") })
+	p.expect("}", "para cerrar el bloque")
+	block.End = p.prevEnd()
+	return block
+}
+
+// parseBody analiza el cuerpo de una estructura de control (bloque o sentencia)
+func (p *Parser) parseBody() ParseNode {
+	if p.is("{") {
+		return p.parseBlock()
+	}
+	stmt, ok := p.parseCStatement()
+	if !ok {
+		return newNode("Empty", ";", p.prevEnd(), p.prevEnd())
+	}
+	return stmt
+}
+
+func (p *Parser) parseCondition(context string) ParseNode {
+	p.expect("(", "después de '"+context+"'")
+	var cond ParseNode
+	if p.language == "cpp" && p.looksLikeCppDeclAt(p.pos) {
+		cond = p.parseCppDeclaration(false)
+	} else {
+		cond = p.parseExpression()
+	}
+	p.expect(")", "al cerrar la condición de '"+context+"'")
+	return newNode("Condition", "", cond.Pos, cond.End, cond)
+}
+
+func (p *Parser) parseCStatement() (ParseNode, bool) {
+	if p.language == "go" {
+		return p.parseGoStatement()
+	}
+	tk := p.cur()
+	switch tk.Lexeme {
+	case ";":
+		p.next()
+		return ParseNode{}, false
+	case "{":
+		return p.parseBlock(), true
+	case "if":
+		return p.parseIf(), true
+	case "while":
+		p.next()
+		cond := p.parseCondition("while")
+		body := p.parseBody()
+		return newNode("While", "while", tk.Start, body.End, cond, body), true
+	case "do":
+		p.next()
+		body := p.parseBody()
+		p.expect("while", "después del cuerpo de 'do'")
+		cond := p.parseCondition("while")
+		p.endStatement("después de 'do-while'")
+		return newNode("DoWhile", "do", tk.Start, p.prevEnd(), body, cond), true
+	case "for":
+		return p.parseFor(), true
+	case "switch":
+		return p.parseSwitch(), true
+	case "return", "throw":
+		p.next()
+		node := newNode(statementKinds[tk.Lexeme], tk.Lexeme, tk.Start, tk.End)
+		if !p.is(";", "}") && !p.atEnd() &&
+			!(p.isJS() && p.lineOf(p.cur().Start) > p.lineOf(tk.Start)) {
+			value := p.parseExpression()
+			node.Children = append(node.Children, value)
+		}
+		p.endStatement("después de '" + tk.Lexeme + "'")
+		node.End = p.prevEnd()
+		return node, true
+	case "break", "continue":
+		p.next()
+		if isName(p.cur()) && p.lineOf(p.cur().Start) == p.lineOf(tk.Start) {
+			p.next()
+		}
+		p.endStatement("después de '" + tk.Lexeme + "'")
+		return newNode(statementKinds[tk.Lexeme], tk.Lexeme, tk.Start, p.prevEnd()), true
+	case "try":
+		return p.parseTry(), true
+	}
+
+	if p.language == "cpp" {
+		if node, ok, handled := p.parseCppStatement(); handled {
+			return node, ok
+		}
+	} else {
+		if p.isTS() {
+			if node, ok, handled := p.parseTSStatement(); handled {
+				return node, ok
+			}
+		}
+		if node, ok, handled := p.parseJSStatement(); handled {
+			return node, ok
+		}
+	}
+
+	expr := p.parseExpression()
+	p.endStatement("al final de la sentencia")
+	return newNode("ExprStmt", "", expr.Pos, p.prevEnd(), expr), true
+}
+
+// Tipo de nodo de las sentencias de una sola palabra clave
+var statementKinds = map[string]string{
+	"return": "Return", "throw": "Throw", "break": "Break", "continue": "Continue",
+	"pass": "Pass", "global": "Global", "nonlocal": "Nonlocal",
+	"goto": "Goto", "fallthrough": "Fallthrough", "go": "Go", "defer": "Defer",
+}
+
+func (p *Parser) parseIf() ParseNode {
+	kw := p.next()
+	cond := p.parseCondition("if")
+	then := p.parseBody()
+	node := newNode("If", "if", kw.Start, then.End, cond, then)
+	if p.is("else") {
+		elseTok := p.next()
+		body := p.parseBody()
+		node.Children = append(node.Children, newNode("Else", "else", elseTok.Start, body.End, body))
+		node.End = body.End
+	}
+	return node
+}
+
+func (p *Parser) parseFor() ParseNode {
+	kw := p.next()
+	p.expect("(", "después de 'for'")
+
+	// for-of / for-in (JS) y for por rango (C++)
+	if p.forEachAhead() {
+		var target ParseNode
+		if p.language == "cpp" {
+			target = p.parseCppDeclaration(false)
+		} else {
+			target = p.parseForTarget()
+		}
+		sep := p.next()
+		iter := p.parseExpression()
+		p.expect(")", "al cerrar el encabezado de 'for'")
+		body := p.parseBody()
+		return newNode("ForEach", sep.Lexeme, kw.Start, body.End, target, iter, body)
+	}
+
+	header := newNode("ForHeader", "", p.cur().Start, p.cur().Start)
+	// Inicialización
+	if !p.is(";") {
+		var init ParseNode
+		if p.language == "cpp" && p.looksLikeCppDeclAt(p.pos) {
+			init = p.parseCppDeclaration(false)
+		} else if p.isJS() && p.is("var", "let", "const") {
+			init = p.parseJSVarDecl(false)
+		} else {
+			init = p.parseExpressionList()
+		}
+		header.Children = append(header.Children, init)
+	}
+	p.expect(";", "en el encabezado de 'for'")
+	// Condición
+	if !p.is(";") {
+		header.Children = append(header.Children, newNode("Condition", "", p.cur().Start, p.cur().Start, p.parseExpression()))
+	}
+	p.expect(";", "en el encabezado de 'for'")
+	// Actualización
+	if !p.is(")") {
+		header.Children = append(header.Children, p.parseExpressionList())
+	}
+	p.expect(")", "al cerrar el encabezado de 'for'")
+	header.End = p.prevEnd()
+	body := p.parseBody()
+	return newNode("For", "for", kw.Start, body.End, header, body)
+}
+
+// parseExpressionList analiza expresiones separadas por comas (i++, j--)
+func (p *Parser) parseExpressionList() ParseNode {
+	expr := p.parseExpression()
+	for p.accept(",") {
+		right := p.parseExpression()
+		expr = newNode("Sequence", ",", expr.Pos, right.End, expr, right)
+	}
+	return expr
+}
+
+func (p *Parser) parseForTarget() ParseNode {
+	if p.is("var", "let", "const") {
+		kw := p.next()
+		target := p.parseBindingTarget()
+		return newNode("VarDecl", target.Label, kw.Start, target.End, newNode("Type", kw.Lexeme, kw.Start, kw.End), target)
+	}
+	return p.parseBinary(8)
+}
+
+// forEachAhead detecta `for (x of y)`, `for (x in y)` o `for (T x : v)`
+func (p *Parser) forEachAhead() bool {
+	depth := 0
+	for i := p.pos; i < p.end; i++ {
+		switch p.toks[i].Lexeme {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			if depth == 0 {
+				return false
+			}
+			depth--
+		case ";":
+			return false
+		case "of", "in":
+			if depth == 0 && p.isJS() {
+				return true
+			}
+		case ":":
+			if depth == 0 && p.language == "cpp" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *Parser) parseSwitch() ParseNode {
+	kw := p.next()
+	cond := p.parseCondition("switch")
+	body := newNode("Block", "{}", p.cur().Start, p.cur().Start)
+	p.expect("{", "para abrir el bloque de 'switch'")
+	for !p.atEnd() && !p.is("}") {
+		if p.is("case", "default") {
+			label := p.next()
+			node := newNode("Case", label.Lexeme, label.Start, label.End)
+			if label.Lexeme == "case" {
+				node.Children = append(node.Children, p.parseTernary())
+			}
+			p.expect(":", "después de '"+label.Lexeme+"'")
+			node.Children = append(node.Children, p.parseStatementList(func() bool { return p.is("case", "default", "}") })...)
+			node.End = p.prevEnd()
+			body.Children = append(body.Children, node)
+			continue
+		}
+		start := p.pos
+		p.errorAt(p.cur().Start, "Se esperaba 'case' o 'default' dentro de 'switch'")
+		p.synchronize()
+		p.stmtErr = false
+		if p.pos == start {
+			p.next()
+		}
+	}
+	p.expect("}", "para cerrar el bloque de 'switch'")
+	body.End = p.prevEnd()
+	return newNode("Switch", "switch", kw.Start, body.End, cond, body)
+}
+
+func (p *Parser) parseTry() ParseNode {
+	kw := p.next()
+	body := p.parseBlock()
+	node := newNode("Try", "try", kw.Start, body.End, body)
+	for p.is("catch") {
+		c := p.next()
+		catch := newNode("Catch", "catch", c.Start, c.End)
+		if p.accept("(") {
+			if p.language == "cpp" {
+				if p.is("...") {
+					p.next()
+				} else {
+					catch.Children = append(catch.Children, p.parseCppParam())
+				}
+			} else {
+				target := p.parseBindingTarget()
+				p.annotate(&target)
+				catch.Children = append(catch.Children, target)
+			}
+			p.expect(")", "al cerrar 'catch'")
+		}
+		handler := p.parseBlock()
+		catch.Children = append(catch.Children, handler)
+		catch.End = handler.End
+		node.Children = append(node.Children, catch)
+		node.End = catch.End
+	}
+	if p.is("finally") {
+		f := p.next()
+		handler := p.parseBlock()
+		node.Children = append(node.Children, newNode("Finally", "finally", f.Start, handler.End, handler))
+		node.End = handler.End
+	}
+	if len(node.Children) == 1 {
+		p.errorAt(p.prevEnd(), "Se esperaba 'catch' o 'finally' después del bloque 'try'")
+	}
+	return node
+}
+
+// ───────────────────────────── JavaScript ────────────────────────────────
+
+func (p *Parser) parseJSStatement() (ParseNode, bool, bool) {
+	tk := p.cur()
+	switch tk.Lexeme {
+	case "var", "let", "const":
+		decl := p.parseJSVarDecl(true)
+		return decl, true, true
+	case "function":
+		return p.parseJSFunction(false), true, true
+	case "async":
+		if p.peek(1).Lexeme == "function" {
+			p.next()
+			fn := p.parseJSFunction(false)
+			fn.Pos = tk.Start
+			return fn, true, true
+		}
+	case "class":
+		return p.parseJSClass(), true, true
+	case "import":
+		if p.peek(1).Lexeme != "(" {
+			return p.parseJSModuleStatement("Import"), true, true
+		}
+	case "export":
+		p.next()
+		if p.is("{", "*") || p.isTS() && p.is("type") && p.peek(1).Lexeme == "{" {
+			p.pos--
+			return p.parseJSModuleStatement("Export"), true, true
+		}
+		if p.accept("default") {
+			var inner ParseNode
+			switch {
+			case p.is("function"):
+				inner = p.parseJSFunction(true)
+			case p.is("class"):
+				inner = p.parseJSClass()
+			default:
+				inner = p.parseAssignment()
+				p.endStatement("después de 'export default'")
+			}
+			return newNode("Export", "default", tk.Start, inner.End, inner), true, true
+		}
+		inner, ok := p.parseCStatement()
+		if !ok {
+			return ParseNode{}, false, true
+		}
+		return newNode("Export", inner.Label, tk.Start, inner.End, inner), true, true
+	}
+	return ParseNode{}, false, false
+}
+
+// parseJSModuleStatement consume una sentencia import/export completa: termina
+// en ';', en la cadena del módulo o al cerrar la lista de nombres.
+func (p *Parser) parseJSModuleStatement(kind string) ParseNode {
+	kw := p.next()
+	depth := 0
+	for !p.atEnd() && !p.is(";") {
+		tk := p.next()
+		if tk.Lexeme == "{" {
+			depth++
+		} else if tk.Lexeme == "}" {
+			depth--
+			if depth == 0 && !p.is("from") {
+				break
+			}
+		} else if tk.Type == STRING && depth == 0 {
+			break
+		}
+	}
+	end := p.prevEnd()
+	p.endStatement("después de '" + kw.Lexeme + "'")
+	return newNode(kind, p.sourceText(kw.Start, end), kw.Start, end)
+}
+
+func (p *Parser) parseJSVarDecl(terminated bool) ParseNode {
+	kw := p.next()
+	var decls []ParseNode
+	for {
+		target := p.parseBindingTarget()
+		decl := newNode("VarDecl", target.Label, target.Pos, target.End, newNode("Type", kw.Lexeme, kw.Start, kw.End))
+		if target.Kind != "Identifier" {
+			decl.Children = append(decl.Children, target)
+		}
+		if p.isTS() {
+			// let x!: T afirma que la variable se asigna antes de usarse
+			p.accept("!")
+			p.annotate(&decl)
+		}
+		if p.accept("=") {
+			init := p.parseAssignment()
+			decl.Children = append(decl.Children, init)
+			decl.End = init.End
+		} else if kw.Lexeme == "const" && terminated && !p.ambient {
+			p.errorAt(target.End, fmt.Sprintf("La constante '%s' debe inicializarse", target.Label))
+		}
+		decls = append(decls, decl)
+		if !p.accept(",") {
+			break
+		}
+	}
+	if terminated {
+		p.endStatement("después de la declaración")
+	}
+	if len(decls) == 1 {
+		decls[0].Pos = kw.Start
+		return decls[0]
+	}
+	return newNode("DeclGroup", kw.Lexeme, kw.Start, p.prevEnd(), decls...)
+}
+
+// parseBindingTarget analiza un nombre o un patrón de desestructuración
+func (p *Parser) parseBindingTarget() ParseNode {
+	switch {
+	case p.is("{"):
+		pattern := p.parseObjectLiteral()
+		pattern.Kind = "ObjectPattern"
+		return pattern
+	case p.is("["):
+		list := p.parseArguments("[", "]")
+		list.Kind, list.Label = "ArrayPattern", "[]"
+		return list
+	}
+	if tk := p.cur(); p.isTS() && tk.Type == KEYWORD && tsContextualKeywords[tk.Lexeme] {
+		p.next()
+		return newNode("Identifier", tk.Lexeme, tk.Start, tk.End)
+	}
+	name := p.expectName("en la declaración")
+	return newNode("Identifier", name.Lexeme, name.Start, name.End)
+}
+
+func (p *Parser) parseJSParams() ParseNode {
+	start := p.cur().Start
+	params := newNode("Params", "", start, start)
+	p.expect("(", "para abrir los parámetros")
+	for !p.atEnd() && !p.is(")") {
+		pstart := p.cur().Start
+		if p.isTS() {
+			p.skipTSParamModifiers()
+		}
+		rest := p.accept("...")
+		var target ParseNode
+		if p.isTS() && p.is("this") {
+			this := p.next()
+			target = newNode("Identifier", this.Lexeme, this.Start, this.End)
+		} else {
+			target = p.parseBindingTarget()
+		}
+		param := newNode("Param", target.Label, pstart, target.End)
+		if target.Kind != "Identifier" {
+			param.Children = append(param.Children, target)
+		}
+		if rest {
+			param.Label = "..." + param.Label
+		}
+		if p.isTS() {
+			if q := p.cur(); p.accept("?") {
+				param.Children = append(param.Children, optionalMarker(q.Start))
+			}
+			p.annotate(&param)
+		}
+		if p.accept("=") {
+			def := p.parseAssignment()
+			param.Children = append(param.Children, def)
+			param.End = def.End
+		}
+		params.Children = append(params.Children, param)
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.expect(")", "para cerrar los parámetros")
+	params.End = p.prevEnd()
+	return params
+}
+
+func (p *Parser) parseJSFunction(expression bool) ParseNode {
+	kw := p.next()
+	p.accept("*")
+	name := ""
+	if isName(p.cur()) {
+		name = p.next().Lexeme
+	} else if !expression {
+		p.expectName("después de 'function'")
+	}
+	fn := newNode("FunctionDecl", name, kw.Start, p.prevEnd())
+	if p.isTS() && p.is("<") {
+		fn.Children = append(fn.Children, p.parseTSTypeParams())
+	}
+	params := p.parseJSParams()
+	fn.Children = append(fn.Children, params)
+	p.annotate(&fn)
+	if p.isTS() && !p.is("{") && !expression {
+		// Firma de sobrecarga o función ambiental: sin cuerpo
+		p.endStatement("después de la firma de la función")
+		fn.End = p.prevEnd()
+		return fn
+	}
+	body := p.parseBlock()
+	fn.Children = append(fn.Children, body)
+	fn.End = body.End
+	return fn
+}
+
+// arrowAhead detecta `( ... ) =>` a partir del paréntesis actual
+func (p *Parser) arrowAhead() bool {
+	depth := 0
+	for i := p.pos; i < p.end; i++ {
+		switch p.toks[i].Lexeme {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			depth--
+			if depth == 0 {
+				if p.isTS() && i+1 < p.end && p.toks[i+1].Lexeme == ":" {
+					return p.returnTypeArrowAhead(i + 2)
+				}
+				return i+1 < p.end && p.toks[i+1].Lexeme == "=>"
+			}
+		}
+	}
+	return false
+}
+
+func (p *Parser) parseArrowFunction() ParseNode {
+	start := p.cur().Start
+	var params ParseNode
+	if p.is("(") {
+		params = p.parseJSParams()
+	} else {
+		name := p.next()
+		params = newNode("Params", "", name.Start, name.End, newNode("Param", name.Lexeme, name.Start, name.End))
+	}
+	fn := newNode("ArrowFunction", "=>", start, params.End, params)
+	p.annotate(&fn)
+	p.expect("=>", "en la función flecha")
+	var body ParseNode
+	if p.is("{") {
+		body = p.parseBlock()
+	} else {
+		body = p.parseAssignment()
+	}
+	fn.Children = append(fn.Children, body)
+	fn.End = body.End
+	return fn
+}
+
+func (p *Parser) parseJSClass() ParseNode {
+	kw := p.next()
+	name := ""
+	if isName(p.cur()) {
+		name = p.next().Lexeme
+	}
+	node := newNode("ClassDecl", name, kw.Start, kw.End)
+	if p.isTS() && p.is("<") {
+		node.Children = append(node.Children, p.parseTSTypeParams())
+	}
+	if p.accept("extends") {
+		base := p.parsePostfix(p.parsePrimary())
+		node.Children = append(node.Children, newNode("Extends", base.Label, base.Pos, base.End, base))
+	}
+	if p.isTS() {
+		p.parseTSClassHeritage(&node)
+	}
+	body := newNode("ClassBody", "{}", p.cur().Start, p.cur().Start)
+	p.expect("{", "para abrir el cuerpo de la clase")
+	for !p.atEnd() && !p.is("}") {
+		if p.accept(";") {
+			continue
+		}
+		start := p.pos
+		body.Children = append(body.Children, p.parseJSClassMember())
+		if p.stmtErr {
+			p.synchronize()
+			p.stmtErr = false
+		}
+		if p.pos == start {
+			p.next()
+		}
+	}
+	p.expect("}", "para cerrar el cuerpo de la clase")
+	body.End = p.prevEnd()
+	node.Children = append(node.Children, body)
+	node.End = body.End
+	return node
+}
+
+func (p *Parser) parseJSClassMember() ParseNode {
+	start := p.cur().Start
+	if p.isTS() && p.is("@") {
+		return p.parseTSDecorator()
+	}
+	static := false
+	for p.isMemberModifier() {
+		if p.next().Lexeme == "static" {
+			static = true
+		}
+	}
+	if static && p.is("{") {
+		block := p.parseBlock()
+		return newNode("StaticBlock", "static", start, block.End, block)
+	}
+	if p.isTS() && p.is("[") && p.peek(2).Lexeme == ":" {
+		// Firma de índice: [clave: string]: T
+		p.skipBalanced()
+		node := newNode("IndexSignature", p.sourceText(start, p.prevEnd()), start, p.prevEnd())
+		p.annotate(&node)
+		p.endStatement("después de la firma de índice")
+		return node
+	}
+	var name string
+	if p.accept("[") {
+		key := p.parseAssignment()
+		p.expect("]", "en el nombre calculado")
+		name = key.Label
+	} else {
+		name = p.next().Lexeme
+	}
+	var optional []ParseNode
+	if p.isTS() {
+		if q := p.cur(); p.accept("?") {
+			optional = append(optional, optionalMarker(q.Start))
+		} else {
+			p.accept("!")
+		}
+	}
+	if p.is("(") || p.isTS() && p.is("<") {
+		kind := "Method"
+		if name == "constructor" {
+			kind = "Constructor"
+		}
+		method := newNode(kind, name, start, p.prevEnd())
+		if p.is("<") {
+			method.Children = append(method.Children, p.parseTSTypeParams())
+		}
+		params := p.parseJSParams()
+		method.Children = append(method.Children, params)
+		p.annotate(&method)
+		if p.isTS() && !p.is("{") {
+			// Método abstracto o firma de sobrecarga
+			p.endStatement("después de la firma del método")
+			method.End = p.prevEnd()
+			return method
+		}
+		body := p.parseBlock()
+		method.Children = append(method.Children, body)
+		method.End = body.End
+		return method
+	}
+	field := newNode("Field", name, start, p.prevEnd(), optional...)
+	p.annotate(&field)
+	if p.accept("=") {
+		value := p.parseAssignment()
+		field.Children = append(field.Children, value)
+		field.End = value.End
+	}
+	p.endStatement("después del campo de la clase")
+	return field
+}
+
+// isMemberModifier indica si el token actual es un modificador del miembro y
+// no su nombre (un método puede llamarse get o static)
+func (p *Parser) isMemberModifier() bool {
+	if !p.is("static", "async", "get", "set", "*") && !(p.isTS() && tsModifiers[p.cur().Lexeme]) {
+		return false
+	}
+	switch p.peek(1).Lexeme {
+	case "(", "=":
+		return false
+	case ":", "?", "!", ";", "<":
+		return !p.isTS()
+	}
+	return true
+}
+
+// ──────────────────────────────── C++ ────────────────────────────────────
+
+var cppTypeKeywords = map[string]bool{
+	"int": true, "char": true, "bool": true, "float": true, "double": true, "void": true,
+	"long": true, "short": true, "signed": true, "unsigned": true, "auto": true,
+	"const": true, "constexpr": true, "static": true, "inline": true, "virtual": true,
+	"extern": true, "mutable": true, "volatile": true, "register": true, "explicit": true,
+	"friend": true, "typename": true, "struct": true, "enum": true, "union": true,
+	"wchar_t": true, "size_t": true, "decltype": true,
+}
+
+func isCppTypeKeyword(lexeme string) bool { return cppTypeKeywords[lexeme] }
+
+// parseCppStatement maneja las sentencias propias de C++; handled indica si
+// la sentencia fue reconocida.
+func (p *Parser) parseCppStatement() (ParseNode, bool, bool) {
+	tk := p.cur()
+	switch {
+	case strings.HasPrefix(strings.TrimSpace(tk.Lexeme), "#"):
+		return p.parsePreprocessor(), true, true
+	case tk.Lexeme == "using" || tk.Lexeme == "typedef":
+		p.next()
+		for !p.atEnd() && !p.is(";") {
+			p.next()
+		}
+		end := p.prevEnd()
+		p.expect(";", "después de '"+tk.Lexeme+"'")
+		kind := "Using"
+		if tk.Lexeme == "typedef" {
+			kind = "Typedef"
+		}
+		return newNode(kind, p.sourceText(tk.Start, end), tk.Start, p.prevEnd()), true, true
+	case tk.Lexeme == "namespace":
+		p.next()
+		name := ""
+		if isName(p.cur()) {
+			name = p.next().Lexeme
+		}
+		body := p.parseBlock()
+		return newNode("Namespace", name, tk.Start, body.End, body), true, true
+	case tk.Lexeme == "template":
+		p.next()
+		if p.is("<") {
+			p.skipTemplateArgs()
+		}
+		decl, ok := p.parseCStatement()
+		if !ok {
+			return ParseNode{}, false, true
+		}
+		return newNode("Template", decl.Label, tk.Start, decl.End, decl), true, true
+	case (tk.Lexeme == "class" || tk.Lexeme == "struct" || tk.Lexeme == "union") && p.classDefinitionAhead():
+		return p.parseCppClass(), true, true
+	case tk.Lexeme == "enum" && p.enumDefinitionAhead():
+		return p.parseCppEnum(), true, true
+	case (tk.Lexeme == "public" || tk.Lexeme == "private" || tk.Lexeme == "protected") && p.peek(1).Lexeme == ":":
+		p.next()
+		p.next()
+		return newNode("AccessSpecifier", tk.Lexeme, tk.Start, p.prevEnd()), true, true
+	case p.looksLikeCppDeclAt(p.pos):
+		return p.parseCppDeclaration(true), true, true
+	}
+	return ParseNode{}, false, false
+}
+
+// parsePreprocessor consume la directiva completa hasta el final de la línea
+func (p *Parser) parsePreprocessor() ParseNode {
+	tk := p.next()
+	line := p.lineOf(tk.Start)
+	for !p.atEnd() && p.lineOf(p.cur().Start) == line {
+		p.next()
+	}
+	end := p.prevEnd()
+	return newNode("Preprocessor", p.sourceText(tk.Start, end), tk.Start, end)
+}
+
+func (p *Parser) classDefinitionAhead() bool {
+	for i := p.pos + 1; i < p.end; i++ {
+		switch p.toks[i].Lexeme {
+		case "{":
+			return true
+		case ";", "(", ")", "=":
+			return false
+		}
+	}
+	return false
+}
+
+func (p *Parser) enumDefinitionAhead() bool {
+	for i := p.pos + 1; i < p.end; i++ {
+		switch p.toks[i].Lexeme {
+		case "{":
+			return true
+		case ";", "(", "=":
+			return false
+		}
+	}
+	return false
+}
+
+func (p *Parser) parseCppClass() ParseNode {
+	kw := p.next()
+	name := p.expectName("después de '" + kw.Lexeme + "'")
+	p.accept("final")
+	node := newNode("ClassDecl", name.Lexeme, kw.Start, name.End)
+	if p.accept(":") {
+		for !p.atEnd() && !p.is("{") {
+			for p.is("public", "private", "protected", "virtual") {
+				p.next()
+			}
+			base := p.parseCppTypeSpec()
+			node.Children = append(node.Children, newNode("Extends", base.Label, base.Pos, base.End))
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+	outer := p.className
+	p.className = name.Lexeme
+	body := p.parseBlock()
+	p.className = outer
+	body.Kind = "ClassBody"
+	node.Children = append(node.Children, body)
+	// Declaradores opcionales tras la definición: struct P {...} a, b;
+	for !p.atEnd() && !p.is(";") && isName(p.cur()) {
+		p.next()
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.expect(";", "después de la definición de '"+name.Lexeme+"'")
+	node.End = p.prevEnd()
+	return node
+}
+
+func (p *Parser) parseCppEnum() ParseNode {
+	kw := p.next()
+	p.accept("class")
+	p.accept("struct")
+	name := ""
+	if isName(p.cur()) {
+		name = p.next().Lexeme
+	}
+	if p.accept(":") {
+		p.parseCppTypeSpec()
+	}
+	node := newNode("EnumDecl", name, kw.Start, kw.End)
+	p.expect("{", "para abrir la enumeración")
+	for !p.atEnd() && !p.is("}") {
+		item := p.expectName("en la enumeración")
+		member := newNode("Enumerator", item.Lexeme, item.Start, item.End)
+		if p.accept("=") {
+			value := p.parseTernary()
+			member.Children = append(member.Children, value)
+			member.End = value.End
+		}
+		node.Children = append(node.Children, member)
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.expect("}", "para cerrar la enumeración")
+	for !p.atEnd() && isName(p.cur()) {
+		p.next()
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.expect(";", "después de la enumeración")
+	node.End = p.prevEnd()
+	return node
+}
+
+// looksLikeCppType indica si en el desplazamiento k comienza un tipo
+func (p *Parser) looksLikeCppType(k int) bool {
+	tk := p.peek(k)
+	if isCppTypeKeyword(tk.Lexeme) {
+		return true
+	}
+	if !isName(tk) {
+		return false
+	}
+	i := p.pos + k + 1
+	for i+1 < p.end && p.toks[i].Lexeme == "::" && isName(p.toks[i+1]) {
+		i += 2
+	}
+	if i < p.end && p.toks[i].Lexeme == "<" {
+		if j, ok := p.matchTemplateClose(i); ok {
+			i = j + 1
+		}
+	}
+	for i < p.end && (p.toks[i].Lexeme == "*" || p.toks[i].Lexeme == "&" || p.toks[i].Lexeme == "&&") {
+		i++
+	}
+	return i < p.end && (p.toks[i].Lexeme == ")" || isName(p.toks[i]))
+}
+
+// castAhead verifica que después del tipo entre paréntesis venga un operando
+func (p *Parser) castAhead() bool {
+	depth := 0
+	for i := p.pos; i < p.end; i++ {
+		switch p.toks[i].Lexeme {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				if i+1 >= p.end {
+					return false
+				}
+				next := p.toks[i+1]
+				return next.Type == IDENTIFIER || next.Type == NUMBER || next.Type == STRING || next.Lexeme == "("
+			}
+		}
+	}
+	return false
+}
+
+// looksLikeCppDeclAt decide si en la posición i comienza una declaración
+func (p *Parser) looksLikeCppDeclAt(i int) bool {
+	if i >= p.end {
+		return false
+	}
+	tk := p.toks[i]
+	if isCppTypeKeyword(tk.Lexeme) {
+		return true
+	}
+	if tk.Lexeme == "~" && i+2 < p.end && p.toks[i+1].Lexeme == p.className && p.toks[i+2].Lexeme == "(" {
+		return true
+	}
+	if !isName(tk) {
+		return false
+	}
+	// Constructor dentro de la clase: Nombre(...)
+	if tk.Lexeme == p.className && i+1 < p.end && p.toks[i+1].Lexeme == "(" {
+		return true
+	}
+	j := i + 1
+	for j+1 < p.end && p.toks[j].Lexeme == "::" && (isName(p.toks[j+1]) || p.toks[j+1].Lexeme == "~") {
+		if p.toks[j+1].Lexeme == "~" {
+			return j+3 < p.end && p.toks[j+3].Lexeme == "("
+		}
+		j += 2
+	}
+	// Definición fuera de la clase: Clase::Clase(...)
+	if j > i+1 && j < p.end && p.toks[j].Lexeme == "(" && p.toks[j-1].Lexeme == p.toks[j-3].Lexeme {
+		return true
+	}
+	if j < p.end && p.toks[j].Lexeme == "<" {
+		close, ok := p.matchTemplateClose(j)
+		if !ok {
+			return false
+		}
+		j = close + 1
+		for j+1 < p.end && p.toks[j].Lexeme == "::" && isName(p.toks[j+1]) {
+			j += 2
+		}
+	}
+	for j < p.end && (p.toks[j].Lexeme == "*" || p.toks[j].Lexeme == "&" || p.toks[j].Lexeme == "&&" || p.toks[j].Lexeme == "const") {
+		j++
+	}
+	return j < p.end && (isName(p.toks[j]) || p.toks[j].Lexeme == "operator")
+}
+
+// matchTemplateClose busca el '>' que cierra una lista de argumentos de plantilla
+func (p *Parser) matchTemplateClose(i int) (int, bool) {
+	depth := 0
+	line := p.lineOf(p.toks[i].Start)
+	for j := i; j < p.end; j++ {
+		tk := p.toks[j]
+		if p.lineOf(tk.Start) != line {
+			return 0, false
+		}
+		switch tk.Lexeme {
+		case "<":
+			depth++
+		case ">":
+			depth--
+		case ">>":
+			depth -= 2
+		case ";", "{", "}", "&&", "||", "=", "<<", "==", "!=":
+			return 0, false
+		}
+		if depth <= 0 {
+			return j, true
+		}
+	}
+	return 0, false
+}
+
+func (p *Parser) templateArgsAhead() bool {
+	close, ok := p.matchTemplateClose(p.pos)
+	if !ok || close+1 >= p.end {
+		return false
+	}
+	next := p.toks[close+1].Lexeme
+	return next == "(" || next == "::" || next == "{"
+}
+
+// skipTemplateArgs consume `<...>` y devuelve su texto
+func (p *Parser) skipTemplateArgs() string {
+	start := p.cur().Start
+	depth := 0
+	for !p.atEnd() {
+		switch p.next().Lexeme {
+		case "<":
+			depth++
+		case ">":
+			depth--
+		case ">>":
+			depth -= 2
+		}
+		if depth <= 0 {
+			break
+		}
+	}
+	return p.sourceText(start, p.prevEnd())
+}
+
+// parseCppTypeSpec consume especificadores y el nombre del tipo (con plantillas)
+func (p *Parser) parseCppTypeSpec() ParseNode {
+	start := p.cur().Start
+	sawName := false
+	for !p.atEnd() {
+		tk := p.cur()
+		switch {
+		case isCppTypeKeyword(tk.Lexeme):
+			p.next()
+			if tk.Lexeme == "decltype" && p.is("(") {
+				p.parseArguments("(", ")")
+			}
+			if tk.Lexeme != "const" && tk.Lexeme != "static" && tk.Lexeme != "inline" &&
+				tk.Lexeme != "virtual" && tk.Lexeme != "extern" && tk.Lexeme != "constexpr" &&
+				tk.Lexeme != "explicit" && tk.Lexeme != "friend" && tk.Lexeme != "mutable" &&
+				tk.Lexeme != "volatile" && tk.Lexeme != "register" && tk.Lexeme != "typename" &&
+				tk.Lexeme != "struct" && tk.Lexeme != "enum" && tk.Lexeme != "union" {
+				sawName = true
+			}
+			continue
+		case isName(tk) && !sawName:
+			p.next()
+			for p.is("::") && (isName(p.peek(1)) || p.peek(1).Type == KEYWORD) {
+				p.next()
+				p.next()
+			}
+			if p.is("<") {
+				p.skipTemplateArgs()
+				for p.is("::") && isName(p.peek(1)) {
+					p.next()
+					p.next()
+				}
+			}
+			sawName = true
+			continue
+		}
+		break
+	}
+	for p.is("*", "&", "&&", "const") {
+		p.next()
+	}
+	if p.prevEnd() <= start {
+		p.errorAt(start, "Se esperaba un tipo")
+	}
+	return newNode("Type", p.sourceText(start, p.prevEnd()), start, p.prevEnd())
+}
+
+// parseCppDeclaration analiza declaraciones de variables y funciones
+func (p *Parser) parseCppDeclaration(terminated bool) ParseNode {
+	start := p.cur().Start
+
+	// Constructores y destructores: Nombre(...), ~Nombre(...), Clase::Clase(...)
+	if p.is("~") || p.is(p.className) && p.className != "" && p.peek(1).Lexeme == "(" || p.outOfClassCtorAhead() {
+		nameStart := p.cur().Start
+		p.accept("~")
+		for !p.atEnd() && !p.is("(") {
+			p.next()
+		}
+		name := p.sourceText(nameStart, p.prevEnd())
+		return p.parseCppFunctionRest(start, newNode("Type", "", start, start), name)
+	}
+
+	typ := p.parseCppTypeSpec()
+	var decls []ParseNode
+	for {
+		for p.is("*", "&", "&&") {
+			typ.Label += p.next().Lexeme
+		}
+		nameTok := p.cur()
+		var name string
+		switch {
+		case p.is("operator"):
+			p.next()
+			opStart := p.cur().Start
+			if p.is("(") {
+				p.next()
+				p.expect(")", "en 'operator()'")
+			} else {
+				for !p.atEnd() && !p.is("(") {
+					p.next()
+				}
+			}
+			name = "operator" + p.sourceText(opStart, p.prevEnd())
+		case p.is("("):
+			// Declarador entre paréntesis (punteros a función) o constructor anónimo
+			name = typ.Label
+		default:
+			tk := p.expectName("en la declaración")
+			name = tk.Lexeme
+			for p.is("::") {
+				p.next()
+				p.accept("~")
+				name += "::" + p.next().Lexeme
+			}
+		}
+
+		if p.is("(") && p.functionParamsAhead() {
+			return p.parseCppFunctionRest(start, typ, name)
+		}
+
+		decl := newNode("VarDecl", name, nameTok.Start, p.prevEnd(), typ)
+		for p.is("[") {
+			p.next()
+			if !p.is("]") {
+				decl.Children = append(decl.Children, newNode("ArraySize", "[]", p.cur().Start, p.cur().End, p.parseExpression()))
+			}
+			p.expect("]", "en la declaración del arreglo")
+		}
+		switch {
+		case p.accept("="):
+			init := p.parseAssignment()
+			decl.Children = append(decl.Children, init)
+		case p.is("{"):
+			list := p.parseArguments("{", "}")
+			list.Kind, list.Label = "InitList", "{}"
+			decl.Children = append(decl.Children, list)
+		case p.is("("):
+			args := p.parseArguments("(", ")")
+			decl.Children = append(decl.Children, args)
+		}
+		decl.End = p.prevEnd()
+		decls = append(decls, decl)
+		if !p.accept(",") {
+			break
+		}
+	}
+	if terminated {
+		p.expect(";", "después de la declaración")
+	}
+	if len(decls) == 1 {
+		decls[0].Pos = start
+		return decls[0]
+	}
+	return newNode("DeclGroup", typ.Label, start, p.prevEnd(), decls...)
+}
+
+func (p *Parser) outOfClassCtorAhead() bool {
+	if !isName(p.cur()) || p.peek(1).Lexeme != "::" {
+		return false
+	}
+	if p.peek(2).Lexeme == "~" {
+		return true
+	}
+	return p.peek(2).Lexeme == p.cur().Lexeme && p.peek(3).Lexeme == "("
+}
+
+// functionParamsAhead distingue `f(int a)` (función) de `x(5)` (inicialización)
+func (p *Parser) functionParamsAhead() bool {
+	// Lo que sigue al ')' de cierre suele decidirlo
+	depth := 0
+	for i := p.pos; i < p.end; i++ {
+		if p.toks[i].Lexeme == "(" {
+			depth++
+		} else if p.toks[i].Lexeme == ")" {
+			depth--
+			if depth == 0 {
+				if i+1 < p.end {
+					switch p.toks[i+1].Lexeme {
+					case "{", "const", "override", "final", "noexcept", "->", ":":
+						return true
+					}
+				}
+				break
+			}
+		}
+	}
+	next := p.peek(1)
+	if next.Lexeme == ")" || next.Lexeme == "..." || isCppTypeKeyword(next.Lexeme) {
+		return true
+	}
+	return isName(next) && p.looksLikeCppDeclAt(p.pos+1)
+}
+
+func (p *Parser) parseCppFunctionRest(start int, typ ParseNode, name string) ParseNode {
+	params := newNode("Params", "", p.cur().Start, p.cur().Start)
+	p.expect("(", "para abrir los parámetros")
+	for !p.atEnd() && !p.is(")") {
+		if p.is("...") {
+			tk := p.next()
+			params.Children = append(params.Children, newNode("Param", "...", tk.Start, tk.End))
+			break
+		}
+		if p.is("void") && p.peek(1).Lexeme == ")" {
+			p.next()
+			break
+		}
+		params.Children = append(params.Children, p.parseCppParam())
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.expect(")", "para cerrar los parámetros")
+	params.End = p.prevEnd()
+
+	// Calificadores finales: const, override, noexcept, = 0, -> tipo
+	for p.is("const", "override", "final", "noexcept", "volatile", "&", "&&") {
+		p.next()
+	}
+	if p.accept("->") {
+		typ = p.parseCppTypeSpec()
+	}
+	fn := newNode("FunctionDecl", name, start, p.prevEnd(), typ, params)
+	if p.accept("=") {
+		p.next() // 0, default o delete
+		p.expect(";", "después de la declaración de la función")
+		fn.End = p.prevEnd()
+		return fn
+	}
+	if p.accept(";") {
+		fn.Kind = "FunctionProto"
+		fn.End = p.prevEnd()
+		return fn
+	}
+	// Lista de inicialización de miembros en constructores
+	if p.accept(":") {
+		for !p.atEnd() && !p.is("{") {
+			p.next()
+			if p.is("(") {
+				p.parseArguments("(", ")")
+			} else if p.is("{") {
+				p.parseArguments("{", "}")
+			}
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+	outer := p.className
+	p.className = ""
+	body := p.parseBlock()
+	p.className = outer
+	fn.Children = append(fn.Children, body)
+	fn.End = body.End
+	return fn
+}
+
+func (p *Parser) parseCppParam() ParseNode {
+	start := p.cur().Start
+	typ := p.parseCppTypeSpec()
+	param := newNode("Param", "", start, p.prevEnd(), typ)
+	if isName(p.cur()) {
+		param.Label = p.next().Lexeme
+	}
+	for p.is("[") {
+		p.next()
+		for !p.atEnd() && !p.is("]") {
+			p.next()
+		}
+		p.expect("]", "en el parámetro")
+		typ.Label += "[]"
+		param.Children[0] = typ
+	}
+	if p.accept("=") {
+		param.Children = append(param.Children, p.parseAssignment())
+	}
+	param.End = p.prevEnd()
+	return param
+}
+
+func (p *Parser) parseCppLambda() ParseNode {
+	start := p.cur().Start
+	p.next()
+	for !p.atEnd() && !p.is("]") {
+		p.next()
+	}
+	p.expect("]", "en la captura de la lambda")
+	params := newNode("Params", "", p.cur().Start, p.cur().Start)
+	if p.is("(") {
+		p.next()
+		for !p.atEnd() && !p.is(")") {
+			params.Children = append(params.Children, p.parseCppParam())
+			if !p.accept(",") {
+				break
+			}
+		}
+		p.expect(")", "para cerrar los parámetros de la lambda")
+	}
+	for p.is("mutable", "noexcept") {
+		p.next()
+	}
+	if p.accept("->") {
+		p.parseCppTypeSpec()
+	}
+	body := p.parseBlock()
+	return newNode("Lambda", "[]", start, body.End, params, body)
+}
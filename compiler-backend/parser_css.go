@@ -0,0 +1,217 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ─────────────────────────────── CSS ─────────────────────────────────────
+//
+// Una hoja de estilos es una lista de reglas (selectores y un bloque de
+// declaraciones) y de at-rules (@media, @import, @keyframes...). Los valores
+// no se analizan como expresiones: cada declaración guarda su texto, que es
+// lo que necesitan la validación de propiedades y el resumen de la ejecución.
+//
+//	Program → Rule{Selector..., Block{Declaration{Value, Important?}...}}
+//	        → AtRule{Prelude?, Block?}
+
+func (p *Parser) parseCSSProgram() ParseNode {
+	root := newNode("Program", p.language, 0, len(p.src))
+	root.Children = p.parseCSSItems(false)
+	return root
+}
+
+// parseCSSItems analiza reglas y at-rules hasta '}' o el final. Dentro de
+// una regla (declarations) también hay declaraciones, y con el anidamiento
+// de CSS, reglas dentro de reglas.
+func (p *Parser) parseCSSItems(declarations bool) []ParseNode {
+	var items []ParseNode
+	for !p.atEnd() && !p.is("}") {
+		start := p.pos
+		tk := p.cur()
+		switch {
+		case p.accept(";"):
+			// Declaración vacía: a { color: red;; }
+		case tk.Type == KEYWORD && strings.HasPrefix(tk.Lexeme, "@"):
+			items = append(items, p.parseCSSAtRule(declarations))
+		case p.cssBlockAhead():
+			items = append(items, p.parseCSSRule())
+		case declarations:
+			items = append(items, p.parseCSSDeclaration())
+		default:
+			p.errorAt(tk.Start, fmt.Sprintf("La declaración '%s' está fuera de una regla; falta el selector y '{'", tk.Lexeme))
+		}
+		if p.stmtErr {
+			p.cssSynchronize()
+			p.stmtErr = false
+		}
+		if p.pos == start {
+			p.pos++
+		}
+	}
+	return items
+}
+
+// cssBlockAhead indica si lo que sigue abre un bloque antes de terminar en
+// ';' o '}': un selector y no una declaración
+func (p *Parser) cssBlockAhead() bool {
+	depth := 0
+	for i := p.pos; i < p.end; i++ {
+		switch p.toks[i].Lexeme {
+		case "(", "[":
+			depth++
+		case ")", "]":
+			depth--
+		case "{":
+			return depth <= 0
+		case ";", "}":
+			if depth <= 0 {
+				return false
+			}
+		}
+	}
+	return false
+}
+
+// cssSynchronize descarta el resto de la declaración o regla con errores
+func (p *Parser) cssSynchronize() {
+	if p.pos > 0 && p.pos <= len(p.toks) && p.toks[p.pos-1].Lexeme == ";" {
+		return
+	}
+	for !p.atEnd() {
+		switch {
+		case p.is(";"):
+			p.next()
+			return
+		case p.is("}"):
+			return
+		case p.is("{"):
+			p.skipBalanced()
+			return
+		}
+		p.next()
+	}
+}
+
+func (p *Parser) parseCSSRule() ParseNode {
+	start := p.cur().Start
+	rule := newNode("Rule", "", start, start)
+	for {
+		rule.Children = append(rule.Children, p.parseCSSSelector())
+		if !p.accept(",") {
+			break
+		}
+	}
+	rule.Label = p.sourceText(start, p.prevEnd())
+	block := p.parseCSSBlock(true, "después del selector")
+	rule.Children = append(rule.Children, block)
+	rule.End = block.End
+	return rule
+}
+
+// parseCSSSelector toma el texto de un selector hasta ',' o '{'; los
+// argumentos de :not(...) y los atributos [type="text"] van completos
+func (p *Parser) parseCSSSelector() ParseNode {
+	start, first := p.cur().Start, p.pos
+	for !p.atEnd() && !p.is(",", "{", "}", ";") {
+		if p.is("(", "[") {
+			p.skipBalanced()
+			continue
+		}
+		p.next()
+	}
+	if p.pos == first {
+		p.errorAt(start, fmt.Sprintf("Se esperaba un selector, se encontró %s", p.foundText()))
+		return newNode("Selector", "", start, start)
+	}
+	return newNode("Selector", p.sourceText(start, p.prevEnd()), start, p.prevEnd())
+}
+
+func (p *Parser) parseCSSBlock(declarations bool, context string) ParseNode {
+	start := p.cur().Start
+	block := newNode("Block", "{}", start, start)
+	if !p.expect("{", context) {
+		return block
+	}
+	block.Children = p.parseCSSItems(declarations)
+	p.expect("}", "para cerrar el bloque")
+	block.End = p.prevEnd()
+	return block
+}
+
+// parseCSSDeclaration analiza 'propiedad: valor [!important]'; el ';' final
+// es opcional antes de '}'
+func (p *Parser) parseCSSDeclaration() ParseNode {
+	name := p.next()
+	if name.Type != IDENTIFIER {
+		p.errorAt(name.Start, fmt.Sprintf("Se esperaba el nombre de una propiedad, se encontró '%s'", name.Lexeme))
+		return newNode("Error", name.Lexeme, name.Start, name.End)
+	}
+	decl := newNode("Declaration", name.Lexeme, name.Start, name.End)
+	if !p.expect(":", fmt.Sprintf("después de la propiedad '%s'", name.Lexeme)) {
+		return decl
+	}
+	start, first := p.cur().Start, p.pos
+	for !p.atEnd() && !p.is(";", "}", "{") && p.cur().Type != KEYWORD {
+		// Un nombre seguido de ':' en otra línea es la declaración siguiente:
+		// falta el ';' de esta
+		if p.pos > first && p.cur().Type == IDENTIFIER && p.peek(1).Lexeme == ":" &&
+			p.lineOf(p.cur().Start) > p.lineOf(p.prevEnd()-1) {
+			break
+		}
+		if p.is("(", "[") {
+			p.skipBalanced()
+			continue
+		}
+		p.next()
+	}
+	switch {
+	case p.pos > first:
+		decl.Children = append(decl.Children, newNode("Value", p.sourceText(start, p.prevEnd()), start, p.prevEnd()))
+	case !strings.HasPrefix(name.Lexeme, "--"):
+		// Solo las propiedades personalizadas pueden quedar vacías
+		p.errorAt(p.prevEnd(), fmt.Sprintf("La propiedad '%s' no tiene valor", name.Lexeme))
+		return decl
+	}
+	if tk := p.cur(); tk.Type == KEYWORD && strings.HasPrefix(tk.Lexeme, "!") {
+		p.next()
+		decl.Children = append(decl.Children, newNode("Important", "!important", tk.Start, tk.End))
+	}
+	decl.End = p.prevEnd()
+	if !p.atEnd() && !p.is("}") {
+		p.expect(";", "después de la declaración")
+	}
+	return decl
+}
+
+// parseCSSAtRule analiza '@nombre preludio' seguido de un bloque (@media,
+// @keyframes, @font-face) o de ';' (@import, @charset)
+func (p *Parser) parseCSSAtRule(nested bool) ParseNode {
+	kw := p.next()
+	name := strings.ToLower(kw.Lexeme)
+	node := newNode("AtRule", name, kw.Start, kw.End)
+	start, first := p.cur().Start, p.pos
+	for !p.atEnd() && !p.is(";", "{", "}") {
+		if p.is("(", "[") {
+			p.skipBalanced()
+			continue
+		}
+		p.next()
+	}
+	if p.pos > first {
+		node.Children = append(node.Children, newNode("Prelude", p.sourceText(start, p.prevEnd()), start, p.prevEnd()))
+	}
+	if p.is("{") {
+		// @font-face y @page contienen declaraciones; @media y @supports,
+		// reglas (o declaraciones si están anidados en una regla)
+		block := p.parseCSSBlock(nested || cssDeclarationAtRules[name], fmt.Sprintf("después de '%s'", kw.Lexeme))
+		node.Children = append(node.Children, block)
+		node.End = block.End
+		return node
+	}
+	node.End = p.prevEnd()
+	if !p.atEnd() && !p.is("}") {
+		p.expect(";", fmt.Sprintf("después de '%s'", kw.Lexeme))
+	}
+	return node
+}
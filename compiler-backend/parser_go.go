@@ -0,0 +1,983 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ───────────────────────────────── Go ────────────────────────────────────
+//
+// Gramática de Go: cláusula package, declaraciones (import, const, var,
+// type, func), sentencias con inserción automática de ';' al final de línea
+// y literales compuestos. Las expresiones reutilizan el analizador por
+// precedencias con la tabla de operadores de Go.
+
+var goBinaryOps = map[string]binaryOp{
+	"||": {1, false},
+	"&&": {2, false},
+	"==": {3, false}, "!=": {3, false}, "<": {3, false}, "<=": {3, false}, ">": {3, false}, ">=": {3, false},
+	"+": {4, false}, "-": {4, false}, "|": {4, false}, "^": {4, false},
+	"*": {5, false}, "/": {5, false}, "%": {5, false}, "<<": {5, false}, ">>": {5, false},
+	"&": {5, false}, "&^": {5, false},
+}
+
+var goUnaryOps = map[string]bool{"+": true, "-": true, "!": true, "^": true, "*": true, "&": true, "<-": true}
+
+func (p *Parser) parseGoProgram() ParseNode {
+	root := newNode("Program", p.language, 0, len(p.src))
+	p.pos = p.resumeAt
+	root.Children = p.parseStatementList(p.resynced)
+	return root
+}
+
+// goLineBreak indica si el token actual está en una línea posterior al último
+// consumido; en Go el salto de línea termina la sentencia.
+func (p *Parser) goLineBreak() bool {
+	return p.pos > 0 && !p.atEnd() && p.lineOf(p.cur().Start) > p.lineOf(p.prevEnd()-1)
+}
+
+func (p *Parser) goStatementEnd() bool {
+	return p.atEnd() || p.is(";", "}", ")") || p.goLineBreak()
+}
+
+// endGoStatement acepta ';' explícito o el ';' implícito de fin de línea
+func (p *Parser) endGoStatement(context string) {
+	if p.accept(";") || p.goStatementEnd() {
+		return
+	}
+	p.errorAt(p.cur().Start, fmt.Sprintf("Se esperaba ';' o fin de línea %s, se encontró %s", context, p.foundText()))
+}
+
+func (p *Parser) parseGoStatement() (ParseNode, bool) {
+	tk := p.cur()
+	switch tk.Lexeme {
+	case ";":
+		p.next()
+		return ParseNode{}, false
+	case "{":
+		return p.parseBlock(), true
+	case "package":
+		p.next()
+		name := p.expectName("después de 'package'")
+		p.endGoStatement("después de 'package'")
+		return newNode("Package", name.Lexeme, tk.Start, p.prevEnd(),
+			newNode("Identifier", name.Lexeme, name.Start, name.End)), true
+	case "import":
+		return p.parseGoImport(), true
+	case "var", "const", "type":
+		return p.parseGoGenDecl(), true
+	case "func":
+		if isName(p.peek(1)) || p.peek(1).Lexeme == "(" && p.goMethodAhead() {
+			return p.parseGoFuncDecl(), true
+		}
+	case "if":
+		return p.parseGoIf(), true
+	case "for":
+		return p.parseGoFor(), true
+	case "switch", "select":
+		return p.parseGoSwitch(), true
+	case "return":
+		p.next()
+		node := newNode("Return", "return", tk.Start, tk.End)
+		if !p.goStatementEnd() {
+			node.Children = p.parseGoExpressionList()
+		}
+		p.endGoStatement("después de 'return'")
+		node.End = p.prevEnd()
+		return node, true
+	case "break", "continue", "goto", "fallthrough":
+		p.next()
+		node := newNode(statementKinds[tk.Lexeme], tk.Lexeme, tk.Start, tk.End)
+		if tk.Lexeme != "fallthrough" && isName(p.cur()) && !p.goLineBreak() {
+			label := p.next()
+			node.Children = append(node.Children, newNode("Identifier", label.Lexeme, label.Start, label.End))
+		}
+		p.endGoStatement("después de '" + tk.Lexeme + "'")
+		node.End = p.prevEnd()
+		return node, true
+	case "go", "defer":
+		p.next()
+		call := p.parseExpression()
+		if call.Kind != "Call" && call.Kind != "Error" {
+			p.errorAt(call.Pos, fmt.Sprintf("La expresión de '%s' debe ser una llamada a función", tk.Lexeme))
+		}
+		p.endGoStatement("después de '" + tk.Lexeme + "'")
+		return newNode(statementKinds[tk.Lexeme], tk.Lexeme, tk.Start, p.prevEnd(), call), true
+	}
+
+	// Sentencia etiquetada (etiqueta: for ...)
+	if isName(tk) && p.peek(1).Lexeme == ":" {
+		p.next()
+		p.next()
+		node := newNode("Labeled", tk.Lexeme, tk.Start, p.prevEnd())
+		if !p.is("}") {
+			if stmt, ok := p.parseGoStatement(); ok {
+				node.Children = append(node.Children, stmt)
+				node.End = stmt.End
+			}
+		}
+		return node, true
+	}
+
+	stmt := p.parseGoSimpleStatement()
+	p.endGoStatement("al final de la sentencia")
+	return stmt, true
+}
+
+// parseGoExpressionList analiza expresiones separadas por comas (a, b)
+func (p *Parser) parseGoExpressionList() []ParseNode {
+	list := []ParseNode{p.parseExpression()}
+	for p.accept(",") {
+		list = append(list, p.parseExpression())
+	}
+	return list
+}
+
+// goExprGroup agrupa una lista de expresiones en una tupla si tiene varias
+func goExprGroup(list []ParseNode) ParseNode {
+	if len(list) == 1 {
+		return list[0]
+	}
+	return newNode("Tuple", "", list[0].Pos, list[len(list)-1].End, list...)
+}
+
+// parseGoSimpleStatement analiza expresiones, asignaciones, declaraciones
+// cortas (:=), envíos a canales e incrementos.
+func (p *Parser) parseGoSimpleStatement() ParseNode {
+	start := p.cur().Start
+	lhs := p.parseGoExpressionList()
+	if !p.atEnd() && !p.goLineBreak() {
+		tk := p.cur()
+		switch {
+		case tk.Lexeme == ":=":
+			p.next()
+			return p.parseGoShortVarDecl(lhs, start)
+		case assignmentOps[tk.Lexeme] || tk.Lexeme == "&^=":
+			p.next()
+			rhs := p.parseGoExpressionList()
+			if tk.Lexeme == "=" && len(rhs) > 1 && len(lhs) != len(rhs) {
+				p.errorAt(rhs[0].Pos, fmt.Sprintf("La asignación tiene %d variable(s) pero %d valor(es)", len(lhs), len(rhs)))
+			}
+			return newNode("Assign", tk.Lexeme, start, p.prevEnd(), goExprGroup(lhs), goExprGroup(rhs))
+		case tk.Lexeme == "<-":
+			p.next()
+			value := p.parseExpression()
+			return newNode("Send", "<-", start, value.End, goExprGroup(lhs), value)
+		}
+	}
+	if len(lhs) > 1 {
+		p.errorAt(lhs[1].Pos, "Se esperaba ':=' o '=' después de la lista de expresiones")
+	}
+	return newNode("ExprStmt", "", lhs[0].Pos, lhs[0].End, lhs[0])
+}
+
+func (p *Parser) parseGoShortVarDecl(lhs []ParseNode, start int) ParseNode {
+	rhs := p.parseGoExpressionList()
+	var decls []ParseNode
+	for i, target := range lhs {
+		if target.Kind != "Identifier" {
+			p.errorAt(target.Pos, "Se esperaba un identificador a la izquierda de ':='")
+			continue
+		}
+		decl := newNode("VarDecl", target.Label, target.Pos, target.End)
+		if len(rhs) == len(lhs) {
+			decl.Children = append(decl.Children, rhs[i])
+			decl.End = rhs[i].End
+		}
+		decls = append(decls, decl)
+	}
+	if len(decls) == 1 && len(lhs) == 1 && len(rhs) == 1 {
+		return decls[0]
+	}
+	group := newNode("DeclGroup", ":=", start, p.prevEnd(), decls...)
+	if len(rhs) != len(lhs) {
+		// v, err := f(): los valores no se reparten por variable
+		group.Children = append(group.Children, newNode("Values", "", rhs[0].Pos, rhs[len(rhs)-1].End, rhs...))
+	}
+	return group
+}
+
+// ──────────────────────────── Declaraciones ──────────────────────────────
+
+func (p *Parser) parseGoImport() ParseNode {
+	kw := p.next()
+	node := newNode("Import", "import", kw.Start, kw.End)
+	spec := func() {
+		start := p.cur().Start
+		var alias *Token
+		if isName(p.cur()) || p.is(".") {
+			tk := p.next()
+			alias = &tk
+		}
+		path := p.cur()
+		if path.Type != STRING {
+			p.errorAt(path.Start, "Se esperaba la ruta del paquete entre comillas, se encontró "+p.foundText())
+			return
+		}
+		p.next()
+		name := newNode("ImportName", strings.Trim(path.Lexeme, "\"`"), start, path.End)
+		if alias != nil {
+			name.Children = append(name.Children, newNode("Identifier", alias.Lexeme, alias.Start, alias.End))
+		}
+		node.Children = append(node.Children, name)
+	}
+	if p.accept("(") {
+		p.parseGoGroup("import", spec)
+	} else {
+		spec()
+	}
+	p.endGoStatement("después de 'import'")
+	node.End = p.prevEnd()
+	return node
+}
+
+// parseGoGroup analiza las especificaciones de una declaración agrupada
+// `kw ( ... )`, una por línea.
+func (p *Parser) parseGoGroup(kw string, spec func()) {
+	for !p.atEnd() && !p.is(")") {
+		start := p.pos
+		spec()
+		p.endGoStatement("en la declaración '" + kw + "'")
+		if p.stmtErr {
+			p.synchronize()
+			p.stmtErr = false
+		}
+		if p.pos == start {
+			p.next()
+		}
+	}
+	p.expect(")", "al cerrar la declaración '"+kw+"'")
+}
+
+// parseGoGenDecl analiza var, const y type (simples o agrupadas)
+func (p *Parser) parseGoGenDecl() ParseNode {
+	kw := p.next()
+	group := newNode("DeclGroup", kw.Lexeme, kw.Start, kw.End)
+	spec := func() {
+		if kw.Lexeme == "type" {
+			group.Children = append(group.Children, p.parseGoTypeSpec())
+		} else {
+			group.Children = append(group.Children, p.parseGoValueSpec(kw.Lexeme)...)
+		}
+	}
+	if p.accept("(") {
+		p.parseGoGroup(kw.Lexeme, spec)
+	} else {
+		spec()
+	}
+	p.endGoStatement("después de la declaración '" + kw.Lexeme + "'")
+	group.End = p.prevEnd()
+	return group
+}
+
+// parseGoValueSpec analiza `a, b T = x, y` dentro de var/const
+func (p *Parser) parseGoValueSpec(kw string) []ParseNode {
+	names := []Token{p.expectName("en la declaración '" + kw + "'")}
+	for p.accept(",") {
+		names = append(names, p.expectName("en la declaración '"+kw+"'"))
+	}
+	var typ *ParseNode
+	if !p.is("=") && !p.goStatementEnd() {
+		t := p.parseGoType()
+		typ = &t
+	}
+	var values []ParseNode
+	if p.accept("=") {
+		values = p.parseGoExpressionList()
+	} else if kw == "const" && typ != nil {
+		p.errorAt(p.prevEnd(), "Se esperaba '=' con el valor de la constante")
+	}
+
+	var decls []ParseNode
+	for i, name := range names {
+		decl := newNode("VarDecl", name.Lexeme, name.Start, name.End)
+		if typ != nil {
+			decl.Children = append(decl.Children, *typ)
+			decl.End = typ.End
+		}
+		if len(values) == len(names) {
+			decl.Children = append(decl.Children, values[i])
+			decl.End = values[i].End
+		}
+		decls = append(decls, decl)
+	}
+	if len(values) > 0 && len(values) != len(names) {
+		decls = append(decls, newNode("Values", "", values[0].Pos, values[len(values)-1].End, values...))
+	}
+	return decls
+}
+
+// parseGoTypeSpec analiza `Nombre[T any] Tipo` o el alias `Nombre = Tipo`
+func (p *Parser) parseGoTypeSpec() ParseNode {
+	name := p.expectName("en la declaración 'type'")
+	node := newNode("TypeDecl", name.Lexeme, name.Start, name.End)
+	if p.is("[") && isName(p.peek(1)) && p.peek(2).Lexeme != "]" {
+		node.Children = append(node.Children, p.parseGoParams("[", "]"))
+	}
+	p.accept("=")
+	typ := p.parseGoType()
+	node.Children = append(node.Children, typ)
+	node.End = typ.End
+	return node
+}
+
+// goMethodAhead distingue `func (r T) Nombre(` de un literal `func(...)`
+func (p *Parser) goMethodAhead() bool {
+	depth := 0
+	for i := p.pos + 1; i < p.end; i++ {
+		switch p.toks[i].Lexeme {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return i+2 < p.end && isName(p.toks[i+1]) && (p.toks[i+2].Lexeme == "(" || p.toks[i+2].Lexeme == "[")
+			}
+		}
+	}
+	return false
+}
+
+func (p *Parser) parseGoFuncDecl() ParseNode {
+	kw := p.next()
+	kind := "FunctionDecl"
+	var recv *ParseNode
+	if p.is("(") {
+		r := p.parseGoParams("(", ")")
+		r.Kind = "Receiver"
+		recv = &r
+		kind = "Method"
+	}
+	name := p.expectName("después de 'func'")
+	fn := newNode(kind, name.Lexeme, kw.Start, name.End)
+	if recv != nil {
+		fn.Children = append(fn.Children, *recv)
+	}
+	if p.is("[") {
+		typeParams := p.parseGoParams("[", "]")
+		typeParams.Kind = "TypeParams"
+		fn.Children = append(fn.Children, typeParams)
+	}
+	p.parseGoSignature(&fn)
+	if p.is("{") {
+		body := p.parseBlock()
+		fn.Children = append(fn.Children, body)
+		fn.End = body.End
+	}
+	p.endGoStatement("después de la función")
+	return fn
+}
+
+// parseGoSignature agrega los parámetros y resultados a una función
+func (p *Parser) parseGoSignature(fn *ParseNode) {
+	params := p.parseGoParams("(", ")")
+	fn.Children = append(fn.Children, params)
+	fn.End = params.End
+	switch {
+	case p.is("(") && !p.goLineBreak():
+		results := p.parseGoParams("(", ")")
+		results.Kind = "Results"
+		fn.Children = append(fn.Children, results)
+		fn.End = results.End
+	case p.goTypeStart() && !p.goLineBreak():
+		typ := p.parseGoType()
+		fn.Children = append(fn.Children, typ)
+		fn.End = typ.End
+	}
+}
+
+// goTypeStart indica si el token actual puede iniciar un tipo
+func (p *Parser) goTypeStart() bool {
+	return isName(p.cur()) || p.is("*", "[", "map", "chan", "func", "struct", "interface", "<-")
+}
+
+// parseGoParams analiza una lista de parámetros. En Go los nombres pueden
+// agruparse (a, b int) o faltar por completo (int, string): si algún
+// parámetro tiene nombre y tipo, los identificadores sueltos son nombres que
+// comparten el tipo siguiente; si no, son tipos.
+func (p *Parser) parseGoParams(open, close string) ParseNode {
+	start := p.cur().Start
+	params := newNode("Params", "", start, start)
+	p.expect(open, "para abrir los parámetros")
+
+	type entry struct {
+		name     *Token
+		typ      *ParseNode
+		variadic bool
+	}
+	var entries []entry
+	named := false
+	for !p.atEnd() && !p.is(close) {
+		var e entry
+		if isName(p.cur()) && !p.isAt(1, ".") {
+			if p.isAt(1, ",", close) {
+				tk := p.next()
+				e.name = &tk
+			} else {
+				tk := p.next()
+				e.name = &tk
+				named = true
+			}
+		}
+		if e.name == nil || named && !p.is(",", close) {
+			if p.accept("...") {
+				e.variadic = true
+			}
+			typ := p.parseGoType()
+			for close == "]" && p.accept("|") {
+				// Restricción de tipos: ~int | ~string
+				p.parseGoType()
+				typ = newNode("Type", p.sourceText(typ.Pos, p.prevEnd()), typ.Pos, p.prevEnd())
+			}
+			e.typ = &typ
+		}
+		entries = append(entries, e)
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.expect(close, "al cerrar los parámetros")
+	params.End = p.prevEnd()
+
+	for i, e := range entries {
+		if !named {
+			// Solo tipos: func(int, string)
+			typ := e.typ
+			if e.name != nil {
+				t := newNode("Type", e.name.Lexeme, e.name.Start, e.name.End)
+				typ = &t
+			}
+			params.Children = append(params.Children, newNode("Param", goParamLabel("", e.variadic), typ.Pos, typ.End, *typ))
+			continue
+		}
+		if e.name == nil {
+			p.errorAt(e.typ.Pos, "Se mezclan parámetros con nombre y sin nombre")
+			continue
+		}
+		typ := e.typ
+		for j := i + 1; typ == nil && j < len(entries); j++ {
+			typ = entries[j].typ
+			e.variadic = entries[j].variadic
+		}
+		if typ == nil {
+			p.errorAt(e.name.End, fmt.Sprintf("Falta el tipo del parámetro '%s'", e.name.Lexeme))
+			continue
+		}
+		params.Children = append(params.Children,
+			newNode("Param", goParamLabel(e.name.Lexeme, e.variadic), e.name.Start, typ.End, *typ))
+	}
+	return params
+}
+
+func goParamLabel(name string, variadic bool) string {
+	if variadic {
+		return "..." + name
+	}
+	return name
+}
+
+// isAt compara el token en la posición relativa k con los lexemas dados
+func (p *Parser) isAt(k int, lexemes ...string) bool {
+	tk := p.peek(k)
+	for _, l := range lexemes {
+		if tk.Lexeme == l {
+			return true
+		}
+	}
+	return false
+}
+
+// ─────────────────────────────── Tipos ───────────────────────────────────
+
+func (p *Parser) parseGoType() ParseNode {
+	start := p.cur().Start
+	tk := p.cur()
+	switch {
+	case tk.Lexeme == "*" || tk.Lexeme == "~":
+		p.next()
+		p.parseGoType()
+	case tk.Lexeme == "[":
+		p.next()
+		if !p.is("]") && !p.accept("...") {
+			p.parseExpression()
+		}
+		p.expect("]", "en el tipo arreglo")
+		p.parseGoType()
+	case tk.Lexeme == "map":
+		p.next()
+		p.expect("[", "después de 'map'")
+		p.parseGoType()
+		p.expect("]", "en el tipo map")
+		p.parseGoType()
+	case tk.Lexeme == "chan":
+		p.next()
+		p.accept("<-")
+		p.parseGoType()
+	case tk.Lexeme == "<-":
+		p.next()
+		p.expect("chan", "después de '<-'")
+		p.parseGoType()
+	case tk.Lexeme == "func":
+		p.next()
+		fn := newNode("Type", "func", tk.Start, tk.End)
+		p.parseGoSignature(&fn)
+	case tk.Lexeme == "struct":
+		return p.parseGoStruct()
+	case tk.Lexeme == "interface":
+		return p.parseGoInterface()
+	case tk.Lexeme == "(":
+		p.next()
+		p.parseGoType()
+		p.expect(")", "al cerrar el tipo")
+	case isName(tk):
+		p.next()
+		if p.is(".") && isName(p.peek(1)) {
+			p.next()
+			p.next()
+		}
+		if p.is("[") && !p.goLineBreak() {
+			// Argumentos de tipo genérico: Lista[int]
+			p.next()
+			for !p.atEnd() && !p.is("]") {
+				p.parseGoType()
+				if !p.accept(",") {
+					break
+				}
+			}
+			p.expect("]", "al cerrar los argumentos de tipo")
+		}
+	default:
+		p.errorAt(tk.Start, "Se esperaba un tipo, se encontró "+p.foundText())
+		return newNode("Type", "", tk.Start, tk.Start)
+	}
+	return newNode("Type", p.sourceText(start, p.prevEnd()), start, p.prevEnd())
+}
+
+func (p *Parser) parseGoStruct() ParseNode {
+	kw := p.next()
+	node := newNode("Type", "struct", kw.Start, kw.End)
+	p.expect("{", "después de 'struct'")
+	for !p.atEnd() && !p.is("}") {
+		start := p.pos
+		if p.is("*") || isName(p.cur()) && (p.isAt(1, ".", ";", "}") || p.peek(1).Type == STRING ||
+			p.lineOf(p.peek(1).Start) > p.lineOf(p.cur().Start)) {
+			// Campo incrustado: T, *T o pkg.T
+			typ := p.parseGoType()
+			node.Children = append(node.Children, newNode("Field", typ.Label, typ.Pos, typ.End, typ))
+		} else {
+			names := []Token{p.expectName("en el campo del struct")}
+			for p.accept(",") {
+				names = append(names, p.expectName("en el campo del struct"))
+			}
+			typ := p.parseGoType()
+			for _, name := range names {
+				node.Children = append(node.Children, newNode("Field", name.Lexeme, name.Start, typ.End, typ))
+			}
+		}
+		if p.cur().Type == STRING && !p.goLineBreak() {
+			// Etiqueta del campo: `json:"nombre"`
+			p.next()
+		}
+		p.endGoStatement("después del campo")
+		if p.stmtErr {
+			p.synchronize()
+			p.stmtErr = false
+		}
+		if p.pos == start {
+			p.next()
+		}
+	}
+	p.expect("}", "para cerrar el struct")
+	node.End = p.prevEnd()
+	return node
+}
+
+func (p *Parser) parseGoInterface() ParseNode {
+	kw := p.next()
+	node := newNode("Type", "interface", kw.Start, kw.End)
+	p.expect("{", "después de 'interface'")
+	for !p.atEnd() && !p.is("}") {
+		start := p.pos
+		if isName(p.cur()) && p.isAt(1, "(") {
+			name := p.next()
+			method := newNode("MethodSpec", name.Lexeme, name.Start, name.End)
+			p.parseGoSignature(&method)
+			node.Children = append(node.Children, method)
+		} else {
+			// Interfaz incrustada o conjunto de tipos (~int | ~string)
+			typ := p.parseGoType()
+			for p.accept("|") {
+				p.parseGoType()
+			}
+			node.Children = append(node.Children, typ)
+		}
+		p.endGoStatement("en la interfaz")
+		if p.stmtErr {
+			p.synchronize()
+			p.stmtErr = false
+		}
+		if p.pos == start {
+			p.next()
+		}
+	}
+	p.expect("}", "para cerrar la interfaz")
+	node.End = p.prevEnd()
+	return node
+}
+
+// ──────────────────────── Estructuras de control ─────────────────────────
+
+// goHeader analiza el encabezado de if/switch/for, donde un '{' abre el
+// bloque y no un literal compuesto (if x == T{} no es válido sin paréntesis).
+func (p *Parser) goHeader(parse func() ParseNode) ParseNode {
+	saved := p.noCompositeLit
+	p.noCompositeLit = true
+	node := parse()
+	p.noCompositeLit = saved
+	return node
+}
+
+// goCondition convierte una sentencia simple en la condición de if/for
+func (p *Parser) goCondition(stmt ParseNode, context string) ParseNode {
+	if stmt.Kind != "ExprStmt" || len(stmt.Children) == 0 {
+		p.errorAt(stmt.Pos, "Se esperaba una expresión como condición de '"+context+"'")
+		return newNode("Condition", "", stmt.Pos, stmt.End, stmt)
+	}
+	expr := stmt.Children[0]
+	return newNode("Condition", "", expr.Pos, expr.End, expr)
+}
+
+func (p *Parser) parseGoIf() ParseNode {
+	kw := p.next()
+	node := newNode("If", "if", kw.Start, kw.End)
+	stmt := p.goHeader(p.parseGoSimpleStatement)
+	if p.accept(";") {
+		// if v, ok := m[k]; ok { ... }
+		node.Children = append(node.Children, stmt)
+		stmt = p.goHeader(p.parseGoSimpleStatement)
+	}
+	node.Children = append(node.Children, p.goCondition(stmt, "if"))
+	then := p.parseBlock()
+	node.Children = append(node.Children, then)
+	node.End = then.End
+	if p.is("else") {
+		elseTok := p.next()
+		var body ParseNode
+		if p.is("if") {
+			body = p.parseGoIf()
+		} else {
+			body = p.parseBlock()
+		}
+		node.Children = append(node.Children, newNode("Else", "else", elseTok.Start, body.End, body))
+		node.End = body.End
+	}
+	return node
+}
+
+// goRangeAhead detecta `for k, v := range x {`
+func (p *Parser) goRangeAhead() bool {
+	depth := 0
+	for i := p.pos; i < p.end; i++ {
+		switch p.toks[i].Lexeme {
+		case "(", "[":
+			depth++
+		case ")", "]":
+			depth--
+		case "{", ";":
+			if depth <= 0 {
+				return false
+			}
+		case "range":
+			return depth == 0
+		}
+	}
+	return false
+}
+
+func (p *Parser) parseGoFor() ParseNode {
+	kw := p.next()
+	switch {
+	case p.is("{"):
+		// Ciclo infinito: for { ... }
+		body := p.parseBlock()
+		return newNode("For", "for", kw.Start, body.End, body)
+
+	case p.goRangeAhead():
+		target := newNode("Empty", "", p.cur().Start, p.cur().Start)
+		if !p.is("range") {
+			targets := p.goHeader(func() ParseNode { return goExprGroup(p.parseGoExpressionList()) })
+			target = targets
+			if p.accept(":=") {
+				// for i, v := range: las variables se declaran en el ciclo
+				target = goRangeDecl(targets)
+			} else {
+				p.expect("=", "antes de 'range'")
+			}
+		}
+		p.expect("range", "en el ciclo 'for'")
+		iter := p.goHeader(p.parseExpression)
+		body := p.parseBlock()
+		return newNode("ForEach", "range", kw.Start, body.End, target, iter, body)
+	}
+
+	header := newNode("ForHeader", "", p.cur().Start, p.cur().Start)
+	var first *ParseNode
+	if !p.is(";") {
+		stmt := p.goHeader(p.parseGoSimpleStatement)
+		first = &stmt
+	}
+	if !p.is(";") {
+		// for condición { ... }
+		if first != nil {
+			header.Children = append(header.Children, p.goCondition(*first, "for"))
+		}
+	} else {
+		p.next()
+		if first != nil {
+			header.Children = append(header.Children, *first)
+		}
+		if !p.is(";") {
+			cond := p.goHeader(p.parseGoSimpleStatement)
+			header.Children = append(header.Children, p.goCondition(cond, "for"))
+		}
+		p.expect(";", "en el encabezado de 'for'")
+		if !p.is("{") {
+			header.Children = append(header.Children, p.goHeader(p.parseGoSimpleStatement))
+		}
+	}
+	header.End = p.prevEnd()
+	body := p.parseBlock()
+	return newNode("For", "for", kw.Start, body.End, header, body)
+}
+
+// goRangeDecl convierte los destinos de `for k, v := range` en declaraciones
+func goRangeDecl(targets ParseNode) ParseNode {
+	if targets.Kind == "Identifier" {
+		return newNode("VarDecl", targets.Label, targets.Pos, targets.End)
+	}
+	decls := newNode("Tuple", "", targets.Pos, targets.End)
+	for _, t := range targets.Children {
+		if t.Kind == "Identifier" {
+			t = newNode("VarDecl", t.Label, t.Pos, t.End)
+		}
+		decls.Children = append(decls.Children, t)
+	}
+	return decls
+}
+
+// parseGoSwitch analiza switch (de expresión o de tipo) y select
+func (p *Parser) parseGoSwitch() ParseNode {
+	kw := p.next()
+	kind := "Switch"
+	if kw.Lexeme == "select" {
+		kind = "Select"
+	}
+	node := newNode(kind, kw.Lexeme, kw.Start, kw.End)
+	if kind == "Switch" && !p.is("{") {
+		var stmt *ParseNode
+		if !p.is(";") {
+			s := p.goHeader(p.parseGoSimpleStatement)
+			stmt = &s
+		}
+		if p.accept(";") {
+			if stmt != nil {
+				node.Children = append(node.Children, *stmt)
+			}
+			stmt = nil
+			if !p.is("{") {
+				s := p.goHeader(p.parseGoSimpleStatement)
+				stmt = &s
+			}
+		}
+		if stmt != nil {
+			if stmt.Kind == "ExprStmt" {
+				node.Children = append(node.Children, p.goCondition(*stmt, "switch"))
+			} else {
+				// switch v := x.(type)
+				node.Children = append(node.Children, *stmt)
+			}
+		}
+	}
+
+	body := newNode("Block", "{}", p.cur().Start, p.cur().Start)
+	p.expect("{", "para abrir el bloque de '"+kw.Lexeme+"'")
+	for !p.atEnd() && !p.is("}") {
+		if p.is("case", "default") {
+			label := p.next()
+			clause := newNode("Case", label.Lexeme, label.Start, label.End)
+			if label.Lexeme == "case" {
+				if kind == "Select" {
+					clause.Children = append(clause.Children, p.parseGoSimpleStatement())
+				} else {
+					clause.Children = append(clause.Children, p.parseGoExpressionList()...)
+				}
+			}
+			p.expect(":", "después de '"+label.Lexeme+"'")
+			clause.Children = append(clause.Children, p.parseStatementList(func() bool { return p.is("case", "default", "}") })...)
+			clause.End = p.prevEnd()
+			body.Children = append(body.Children, clause)
+			continue
+		}
+		start := p.pos
+		p.errorAt(p.cur().Start, "Se esperaba 'case' o 'default' dentro de '"+kw.Lexeme+"'")
+		p.synchronize()
+		p.stmtErr = false
+		if p.pos == start {
+			p.next()
+		}
+	}
+	p.expect("}", "para cerrar el bloque de '"+kw.Lexeme+"'")
+	body.End = p.prevEnd()
+	node.Children = append(node.Children, body)
+	node.End = body.End
+	return node
+}
+
+// ───────────────────────────── Expresiones ───────────────────────────────
+
+func (p *Parser) parseGoOperand() ParseNode {
+	tk := p.cur()
+	switch tk.Lexeme {
+	case "(":
+		p.next()
+		saved := p.noCompositeLit
+		p.noCompositeLit = false
+		expr := p.parseExpression()
+		p.noCompositeLit = saved
+		p.expect(")", "al cerrar la expresión")
+		return expr
+	case "func":
+		// Literal de función: func(x int) int { ... }
+		p.next()
+		fn := newNode("FunctionDecl", "", tk.Start, tk.End)
+		p.parseGoSignature(&fn)
+		saved := p.noCompositeLit
+		p.noCompositeLit = false
+		body := p.parseBlock()
+		p.noCompositeLit = saved
+		fn.Children = append(fn.Children, body)
+		fn.End = body.End
+		return fn
+	case "[", "map", "chan", "struct", "interface":
+		// Tipos compuestos usados como operando: []int{1, 2}, make(map[string]int)
+		typ := p.parseGoType()
+		if p.is("{") && !p.goLineBreak() {
+			return p.parseGoCompositeLit(typ)
+		}
+		return typ
+	}
+
+	if tk.Type == IDENTIFIER || tk.Type == KEYWORD {
+		p.next()
+		if tk.Type == KEYWORD {
+			p.errorAt(tk.Start, fmt.Sprintf("Palabra reservada '%s' inesperada en una expresión", tk.Lexeme))
+		}
+		return newNode("Identifier", tk.Lexeme, tk.Start, tk.End)
+	}
+
+	if !p.is(";", "}", ")", "]") {
+		p.next()
+	}
+	p.errorAt(tk.Start, fmt.Sprintf("Token inesperado '%s' en una expresión", tk.Lexeme))
+	return newNode("Error", tk.Lexeme, tk.Start, tk.End)
+}
+
+// goTypeExpr indica si una expresión puede nombrar el tipo de un literal
+// compuesto (Punto{...}, pkg.T{...}, Lista[int]{...})
+func goTypeExpr(n ParseNode) bool {
+	switch n.Kind {
+	case "Identifier", "Type":
+		return true
+	case "Member", "Index":
+		return len(n.Children) > 0 && goTypeExpr(n.Children[0])
+	}
+	return false
+}
+
+// parseGoCompositeLit analiza los elementos de T{a, b} o T{k: v}
+func (p *Parser) parseGoCompositeLit(typ ParseNode) ParseNode {
+	lit := newNode("CompositeLit", typ.Label, typ.Pos, typ.End, typ)
+	saved := p.noCompositeLit
+	p.noCompositeLit = false
+	defer func() { p.noCompositeLit = saved }()
+
+	p.expect("{", "")
+	for !p.atEnd() && !p.is("}") {
+		elem := p.parseGoElement()
+		if p.accept(":") {
+			value := p.parseGoElement()
+			elem = newNode("KeyValue", elem.Label, elem.Pos, value.End, elem, value)
+		}
+		lit.Children = append(lit.Children, elem)
+		if !p.accept(",") {
+			if !p.is("}") && p.goLineBreak() {
+				p.errorAt(p.prevEnd(), "Se esperaba ',' antes del salto de línea en el literal compuesto")
+			}
+			break
+		}
+	}
+	p.expect("}", "al cerrar el literal compuesto")
+	lit.End = p.prevEnd()
+	return lit
+}
+
+func (p *Parser) parseGoElement() ParseNode {
+	if p.is("{") {
+		// Tipo omitido en literales anidados: [][]int{{1, 2}, {3}}
+		return p.parseGoCompositeLit(newNode("Type", "", p.cur().Start, p.cur().Start))
+	}
+	return p.parseExpression()
+}
+
+// parseGoIndex analiza a[i], a[i:j], a[i:j:k] y argumentos de tipo f[int]
+func (p *Parser) parseGoIndex(expr ParseNode) ParseNode {
+	open := p.next()
+	saved := p.noCompositeLit
+	p.noCompositeLit = false
+	var parts []ParseNode
+	slice := false
+	for !p.atEnd() && !p.is("]") {
+		if p.accept(":") {
+			slice = true
+			continue
+		}
+		if p.accept(",") {
+			continue
+		}
+		parts = append(parts, p.parseExpression())
+		if !p.is(":", ",") {
+			break
+		}
+	}
+	p.noCompositeLit = saved
+	p.expect("]", "al cerrar el índice")
+
+	var index ParseNode
+	switch {
+	case slice:
+		index = newNode("Slice", ":", open.Start, p.prevEnd(), parts...)
+	case len(parts) == 1:
+		index = parts[0]
+	case len(parts) == 0:
+		p.errorAt(open.End, "Se esperaba un índice entre '[' y ']'")
+		index = newNode("Error", "", open.End, open.End)
+	default:
+		index = newNode("Tuple", "", parts[0].Pos, parts[len(parts)-1].End, parts...)
+	}
+	return newNode("Index", "[]", expr.Pos, p.prevEnd(), expr, index)
+}
+
+// parseGoTypeAssert analiza x.(T) y x.(type) a partir del '.'
+func (p *Parser) parseGoTypeAssert(expr ParseNode) ParseNode {
+	p.next()
+	p.next()
+	var typ ParseNode
+	if p.is("type") {
+		tk := p.next()
+		typ = newNode("Type", "type", tk.Start, tk.End)
+	} else {
+		typ = p.parseGoType()
+	}
+	p.expect(")", "al cerrar la aserción de tipo")
+	return newNode("TypeAssert", typ.Label, expr.Pos, p.prevEnd(), expr, typ)
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ─────────────────────────────── HTML ────────────────────────────────────
+//
+// El documento se arma con una pila de elementos abiertos, como hace el
+// navegador: una etiqueta de apertura apila un elemento y la de cierre
+// desapila hasta su pareja. Los elementos vacíos (<br>, <img>) no se apilan y
+// los de cierre opcional (<p>, <li>) se cierran solos cuando corresponde.
+//
+//	Program → Doctype? Element{Attribute{Value?}..., Text, Element...}...
+
+// htmlOpen es un elemento de la pila junto con el token de su etiqueta
+type htmlOpen struct {
+	node ParseNode
+	tag  Token
+}
+
+func (p *Parser) parseHTMLProgram() ParseNode {
+	root := newNode("Program", p.language, 0, len(p.src))
+	stack := []htmlOpen{{node: root}}
+	add := func(n ParseNode) {
+		top := &stack[len(stack)-1].node
+		top.Children = append(top.Children, n)
+	}
+	// pop cierra el elemento de arriba de la pila y lo agrega a su padre
+	pop := func(end int) {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		top.node.End = end
+		add(top.node)
+	}
+
+	for !p.atEnd() {
+		tk := p.cur()
+		switch {
+		case tk.Type == KEYWORD && strings.HasPrefix(tk.Lexeme, "<!"):
+			p.next()
+			add(newNode("Doctype", tk.Lexeme, tk.Start, tk.End))
+		case tk.Type == KEYWORD && strings.HasPrefix(tk.Lexeme, "</"):
+			name := strings.ToLower(tk.Lexeme[2:])
+			p.parseHTMLTagEnd(tk, true)
+			i := len(stack) - 1
+			for i > 0 && stack[i].node.Label != name {
+				i--
+			}
+			if i == 0 {
+				if htmlVoidElements[name] {
+					p.htmlError(tk.Start, CodeUnexpectedClosingTag, fmt.Sprintf("<%s> es un elemento vacío y no lleva etiqueta de cierre </%s>", name, name))
+				} else {
+					p.htmlError(tk.Start, CodeUnexpectedClosingTag, fmt.Sprintf("La etiqueta de cierre </%s> no tiene una etiqueta de apertura <%s>", name, name))
+				}
+				continue
+			}
+			// Lo abierto después de la pareja queda sin cerrar
+			for len(stack)-1 > i {
+				open := stack[len(stack)-1]
+				if !htmlOptionalEnd[open.node.Label] {
+					p.htmlError(open.tag.Start, CodeUnclosedTag, fmt.Sprintf("La etiqueta <%s> no se cerró antes de </%s>", open.node.Label, name))
+				}
+				pop(tk.Start)
+			}
+			pop(p.prevEnd())
+		case tk.Type == KEYWORD && strings.HasPrefix(tk.Lexeme, "<"):
+			name := strings.ToLower(tk.Lexeme[1:])
+			for len(stack) > 1 && htmlAutoClose[stack[len(stack)-1].node.Label][name] {
+				pop(tk.Start)
+			}
+			elem, selfClosing := p.parseHTMLStartTag()
+			if htmlVoidElements[name] || selfClosing {
+				add(elem)
+			} else {
+				stack = append(stack, htmlOpen{elem, tk})
+			}
+		case tk.Type == STRING:
+			p.next()
+			add(newNode("Text", p.sourceText(tk.Start, tk.End), tk.Start, tk.End))
+		default:
+			p.next()
+			p.htmlError(tk.Start, CodeUnexpectedToken, fmt.Sprintf("Contenido inesperado '%s' fuera de una etiqueta", tk.Lexeme))
+		}
+	}
+	for len(stack) > 1 {
+		open := stack[len(stack)-1]
+		if !htmlOptionalEnd[open.node.Label] {
+			p.htmlError(open.tag.Start, CodeUnclosedTag, fmt.Sprintf("La etiqueta <%s> nunca se cerró", open.node.Label))
+		}
+		pop(len(p.src))
+	}
+	// Las etiquetas sin cerrar se detectan después de su contenido
+	sort.SliceStable(p.errors, func(i, j int) bool { return p.errors[i].Pos < p.errors[j].Pos })
+	return stack[0].node
+}
+
+// parseHTMLStartTag analiza '<nombre atributo="valor" ...>' e indica si la
+// etiqueta terminó en '/>'
+func (p *Parser) parseHTMLStartTag() (ParseNode, bool) {
+	tk := p.next()
+	elem := newNode("Element", strings.ToLower(tk.Lexeme[1:]), tk.Start, tk.End)
+	for !p.atEnd() && p.cur().Type == IDENTIFIER {
+		name := p.next()
+		attr := newNode("Attribute", strings.ToLower(name.Lexeme), name.Start, name.End)
+		if p.accept("=") {
+			if value := p.cur(); value.Type == STRING && !p.atEnd() {
+				p.next()
+				attr.Children = append(attr.Children, newNode("Value", htmlUnquote(value.Lexeme), value.Start, value.End))
+				attr.End = value.End
+			} else {
+				p.htmlError(p.prevEnd(), CodeUnexpectedToken, fmt.Sprintf("Se esperaba el valor del atributo '%s', se encontró %s", name.Lexeme, p.foundText()))
+			}
+		}
+		elem.Children = append(elem.Children, attr)
+	}
+	selfClosing := p.parseHTMLTagEnd(tk, false)
+	if selfClosing && !htmlVoidElements[elem.Label] {
+		// En HTML la barra se ignora: <div/> abre un elemento. Se trata
+		// como cerrado para no arrastrar errores, pero se advierte.
+		p.errors = append(p.errors, CompilerError{
+			Message:  fmt.Sprintf("Error sintáctico: <%s/> no cierra el elemento en HTML; usa <%s></%s>", elem.Label, elem.Label, elem.Label),
+			Severity: "warning",
+			Type:     "sintactico",
+			Pos:      tk.Start,
+			Code:     CodeUnclosedTag,
+		})
+	}
+	elem.End = p.prevEnd()
+	return elem, selfClosing
+}
+
+// parseHTMLTagEnd consume el '>' o '/>' que termina la etiqueta tag. En una
+// etiqueta de cierre (closing) se consume la propia etiqueta antes.
+func (p *Parser) parseHTMLTagEnd(tag Token, closing bool) bool {
+	if closing {
+		p.next()
+		// </div class="x">: los atributos de un cierre se ignoran
+		for !p.atEnd() && !p.is(">", "/>") && p.cur().Type != KEYWORD && p.cur().Type != STRING {
+			p.next()
+		}
+	}
+	switch {
+	case p.accept(">"):
+		return false
+	case p.accept("/>"):
+		return true
+	}
+	p.htmlError(p.prevEnd(), CodeUnexpectedToken, fmt.Sprintf("Se esperaba '>' para terminar la etiqueta %s, se encontró %s", tag.Lexeme, p.foundText()))
+	return false
+}
+
+// htmlError reporta un error de estructura. A diferencia de errorAt, un
+// documento puede tener varios en seguida (una etiqueta sin cerrar por
+// cada elemento de la pila).
+func (p *Parser) htmlError(pos int, code, msg string) {
+	if len(p.errors) >= maxParserErrors {
+		return
+	}
+	p.errors = append(p.errors, CompilerError{
+		Message:  "Error sintáctico: " + msg,
+		Severity: "error",
+		Type:     "sintactico",
+		Pos:      pos,
+		Code:     code,
+	})
+}
+
+// htmlUnquote quita las comillas del valor de un atributo
+func htmlUnquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
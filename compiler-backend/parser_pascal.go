@@ -0,0 +1,985 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ───────────────────────────────── Pascal ────────────────────────────────
+//
+// Un programa Pascal es un encabezado (program nombre;), una cláusula uses
+// opcional y un bloque: las secciones de declaraciones (label, const, type,
+// var y los procedimientos y funciones, que a su vez tienen su propio
+// bloque) seguidas de la sentencia compuesta begin...end. El programa
+// termina con 'end.'.
+//
+// Las palabras clave no distinguen mayúsculas (BEGIN, Begin y begin son la
+// misma), así que se comparan con sqlIs/sqlAccept/sqlExpect igual que las de
+// SQL. El ';' separa sentencias: la última antes de 'end' o 'until' puede
+// no llevarlo.
+//
+//	Program → ProgramHeader, Uses{Unit}, Block
+//	Block → LabelSection, ConstSection{ConstDecl}, TypeSection{TypeDecl{Type}},
+//	        VarSection{VarDecl{Type, valor}}, Procedure | Function, Compound
+//	Procedure/Function → Params{Param{Modifier, Type}}, Returns{Type}, Directive | Block
+//	Type → Identifier | Range | Enumerator... | Field{Type}... | Type (elementos)
+//	Sentencias → Compound, Assign{destino, valor}, ExprStmt, If{cond, sentencia, Else},
+//	             While, Repeat{Block, cond}, For{Identifier, Range, cuerpo},
+//	             ForIn, Case{expr, CaseBranch..., Else}, With, Try{Block, Except | Finally},
+//	             Raise, Goto{LabelRef}, Labeled
+
+// Operadores binarios y su precedencia: los relacionales tienen la menor y
+// los multiplicativos (incluido and) la mayor, así que 'a > 0 and b > 0'
+// necesita paréntesis como en cualquier compilador de Pascal
+var pascalBinaryOps = map[string]int{
+	"=": 1, "<>": 1, "<": 1, "<=": 1, ">": 1, ">=": 1, "in": 1, "is": 1,
+	"+": 2, "-": 2, "or": 2, "xor": 2,
+	"*": 3, "/": 3, "div": 3, "mod": 3, "and": 3, "shl": 3, "shr": 3, "as": 3,
+}
+
+// Precedencia de los operandos de un rango o de un tipo: sin relacionales,
+// para que en 'x: integer = 5' el '=' quede fuera del tipo
+const pascalAdditive = 2
+
+// Palabras que comienzan una sentencia o una sección; después de un error
+// marcan dónde retomar el análisis
+var pascalStatementStarters = makeSet(strings.Fields(`
+	begin if while for repeat case with goto try raise var const type label procedure function
+`))
+
+// Directivas que pueden seguir al encabezado de un procedimiento
+var pascalDirectives = makeSet(strings.Fields(`
+	forward external overload inline cdecl stdcall register assembler virtual override
+	static abstract reintroduce
+`))
+
+func (p *Parser) parsePascalProgram() ParseNode {
+	root := newNode("Program", p.language, 0, len(p.src))
+	if p.sqlIs("program") {
+		kw := p.next()
+		name := p.expectName("después de 'program'")
+		header := newNode("ProgramHeader", name.Lexeme, kw.Start, name.End)
+		if p.accept("(") {
+			// program ejemplo(input, output);
+			for isName(p.cur()) {
+				tk := p.next()
+				header.Children = append(header.Children, newNode("Identifier", tk.Lexeme, tk.Start, tk.End))
+				if !p.accept(",") {
+					break
+				}
+			}
+			p.expect(")", "al cerrar los parámetros del programa")
+		}
+		p.expect(";", "después del encabezado del programa")
+		header.End = p.prevEnd()
+		root.Children = append(root.Children, header)
+		p.pasRecover()
+	}
+	if p.sqlIs("uses") {
+		root.Children = append(root.Children, p.parsePascalUses())
+	}
+	root.Children = append(root.Children, p.parsePascalBlock())
+	if !p.stmtErr {
+		p.expect(".", "después del 'end' final del programa")
+	}
+	// Lo que sigue a 'end.' no se compila
+	return root
+}
+
+// ───────────────────────────── Auxiliares ────────────────────────────────
+
+// pasRecover descarta el resto de una declaración o sentencia con errores
+func (p *Parser) pasRecover() {
+	if p.stmtErr {
+		p.pasSynchronize()
+		p.stmtErr = false
+	}
+}
+
+// pasSynchronize avanza hasta después del próximo ';', o hasta la palabra
+// que cierra el bloque actual o la próxima sentencia que empieza en otra
+// línea
+func (p *Parser) pasSynchronize() {
+	if p.pos > 0 && p.pos <= len(p.toks) && p.toks[p.pos-1].Lexeme == ";" {
+		return
+	}
+	for !p.atEnd() {
+		switch {
+		case p.accept(";"):
+			return
+		case p.sqlIs("end", "until", "except", "finally", "else"):
+			return
+		case p.pasStatementStart() && p.sqlLineStart():
+			return
+		}
+		p.next()
+	}
+}
+
+func (p *Parser) pasStatementStart() bool {
+	return !p.atEnd() && p.cur().Type == KEYWORD && pascalStatementStarters[strings.ToLower(p.cur().Lexeme)]
+}
+
+// pasBlockEnd indica si el token actual cierra una lista de sentencias
+func (p *Parser) pasBlockEnd() bool {
+	return p.atEnd() || p.sqlIs("end", "until", "except", "finally")
+}
+
+// parsePascalNames analiza una lista de nombres separados por comas
+func (p *Parser) parsePascalNames(context string) []Token {
+	var names []Token
+	for {
+		name := p.expectName(context)
+		if p.stmtErr {
+			return names
+		}
+		names = append(names, name)
+		if !p.accept(",") {
+			return names
+		}
+	}
+}
+
+// ──────────────────────────── Declaraciones ──────────────────────────────
+
+func (p *Parser) parsePascalUses() ParseNode {
+	kw := p.next()
+	node := newNode("Uses", "uses", kw.Start, kw.End)
+	for _, name := range p.parsePascalNames("en la cláusula uses") {
+		node.Children = append(node.Children, newNode("Unit", name.Lexeme, name.Start, name.End))
+	}
+	if !p.stmtErr {
+		p.expect(";", "al final de la cláusula uses")
+	}
+	node.End = p.prevEnd()
+	p.pasRecover()
+	return node
+}
+
+// parsePascalBlock analiza las declaraciones de un programa o procedimiento
+// y su sentencia compuesta
+func (p *Parser) parsePascalBlock() ParseNode {
+	start := p.cur().Start
+	block := newNode("Block", "", start, start)
+	for !p.atEnd() {
+		var decl ParseNode
+		switch {
+		case p.sqlIs("label"):
+			decl = p.parsePascalSection("LabelSection", p.parsePascalLabelDecl)
+		case p.sqlIs("const"):
+			decl = p.parsePascalSection("ConstSection", p.parsePascalConstDecl)
+		case p.sqlIs("type"):
+			decl = p.parsePascalSection("TypeSection", p.parsePascalTypeDecl)
+		case p.sqlIs("var"):
+			decl = p.parsePascalSection("VarSection", p.parsePascalVarDecl)
+		case p.sqlIs("procedure", "function", "constructor", "destructor"):
+			decl = p.parsePascalRoutine()
+			p.pasRecover()
+		default:
+			block.Children = append(block.Children, p.parsePascalBody())
+			block.End = p.prevEnd()
+			return block
+		}
+		block.Children = append(block.Children, decl)
+	}
+	block.Children = append(block.Children, p.parsePascalBody())
+	block.End = p.prevEnd()
+	return block
+}
+
+// parsePascalBody analiza el begin...end de un bloque. Si falta 'begin' se
+// reporta y las sentencias se analizan igual hasta el 'end'.
+func (p *Parser) parsePascalBody() ParseNode {
+	if p.sqlIs("begin") {
+		return p.parsePascalCompound()
+	}
+	start := p.cur().Start
+	body := newNode("Compound", "begin", start, start)
+	p.errorAt(start, fmt.Sprintf("Se esperaba 'begin' o una declaración, se encontró %s", p.foundText()))
+	if p.atEnd() {
+		return body
+	}
+	p.stmtErr = false
+	body.Children = p.parsePascalStatements(p.pasBlockEnd)
+	p.sqlExpect("end", "al final del bloque")
+	body.End = p.prevEnd()
+	return body
+}
+
+// parsePascalSection analiza una sección de declaraciones (var, const...)
+// con una declaración por ';' mientras sigan empezando con un nombre
+func (p *Parser) parsePascalSection(kind string, decl func() []ParseNode) ParseNode {
+	kw := p.next()
+	node := newNode(kind, strings.ToLower(kw.Lexeme), kw.Start, kw.End)
+	for !p.atEnd() && (isName(p.cur()) || kind == "LabelSection" && p.cur().Type == NUMBER) {
+		start := p.pos
+		node.Children = append(node.Children, decl()...)
+		if !p.stmtErr {
+			p.expect(";", "al final de la declaración")
+		}
+		p.pasRecover()
+		if p.pos == start {
+			p.pos++
+		}
+	}
+	if len(node.Children) == 0 {
+		p.errorAt(p.cur().Start, fmt.Sprintf("Se esperaba una declaración después de '%s', se encontró %s", node.Label, p.foundText()))
+		p.pasRecover()
+	}
+	node.End = p.prevEnd()
+	return node
+}
+
+// label 10, fin;
+func (p *Parser) parsePascalLabelDecl() []ParseNode {
+	var labels []ParseNode
+	for {
+		tk := p.next()
+		labels = append(labels, newNode("Label", tk.Lexeme, tk.Start, tk.End))
+		if !p.accept(",") {
+			return labels
+		}
+		if !isName(p.cur()) && p.cur().Type != NUMBER {
+			p.errorAt(p.cur().Start, fmt.Sprintf("Se esperaba una etiqueta, se encontró %s", p.foundText()))
+			return labels
+		}
+	}
+}
+
+// const max = 10; const tasa: real = 0.5;
+func (p *Parser) parsePascalConstDecl() []ParseNode {
+	name := p.next()
+	node := newNode("ConstDecl", name.Lexeme, name.Start, name.End)
+	if p.accept(":") {
+		node.Children = append(node.Children, p.parsePascalType())
+	}
+	if p.stmtErr || !p.expect("=", fmt.Sprintf("después de la constante '%s'", name.Lexeme)) {
+		return []ParseNode{node}
+	}
+	node.Children = append(node.Children, p.parsePascalExpr(0))
+	node.End = p.prevEnd()
+	return []ParseNode{node}
+}
+
+// type TPunto = record x, y: real end;
+func (p *Parser) parsePascalTypeDecl() []ParseNode {
+	name := p.next()
+	node := newNode("TypeDecl", name.Lexeme, name.Start, name.End)
+	if !p.expect("=", fmt.Sprintf("después del tipo '%s'", name.Lexeme)) {
+		return []ParseNode{node}
+	}
+	p.sqlAccept("type")
+	node.Children = append(node.Children, p.parsePascalType())
+	node.End = p.prevEnd()
+	return []ParseNode{node}
+}
+
+// var a, b: integer; var total: real = 0;
+func (p *Parser) parsePascalVarDecl() []ParseNode {
+	names := p.parsePascalNames("en la declaración de variables")
+	if p.stmtErr || !p.expect(":", "después del nombre de la variable") {
+		return nil
+	}
+	typ := p.parsePascalType()
+	var init []ParseNode
+	if p.accept("=") {
+		init = append(init, p.parsePascalExpr(0))
+	}
+	var decls []ParseNode
+	for _, name := range names {
+		decls = append(decls, newNode("VarDecl", name.Lexeme, name.Start, p.prevEnd(), append([]ParseNode{typ}, init...)...))
+	}
+	return decls
+}
+
+// parsePascalRoutine analiza un procedimiento o función: encabezado,
+// directivas (forward, overload...) y su bloque
+func (p *Parser) parsePascalRoutine() ParseNode {
+	kw := p.next()
+	kind := "Procedure"
+	if strings.EqualFold(kw.Lexeme, "function") {
+		kind = "Function"
+	}
+	lower := strings.ToLower(kw.Lexeme)
+	name := p.expectName("después de '" + lower + "'")
+	label := name.Lexeme
+	// procedure TLista.Agregar: implementación de un método
+	for !p.stmtErr && p.is(".") && isName(p.peek(1)) {
+		p.next()
+		label += "." + p.next().Lexeme
+	}
+	node := newNode(kind, label, kw.Start, p.prevEnd())
+	if p.stmtErr {
+		return node
+	}
+	if p.is("(") {
+		node.Children = append(node.Children, p.parsePascalParams())
+	}
+	if kind == "Function" && p.accept(":") {
+		typ := p.parsePascalType()
+		node.Children = append(node.Children, newNode("Returns", "", typ.Pos, typ.End, typ))
+	}
+	if p.stmtErr || !p.expect(";", fmt.Sprintf("después del encabezado de '%s'", label)) {
+		node.End = p.prevEnd()
+		return node
+	}
+	body := true
+	for p.cur().Type == IDENTIFIER && pascalDirectives[strings.ToLower(p.cur().Lexeme)] {
+		d := p.next()
+		directive := strings.ToLower(d.Lexeme)
+		node.Children = append(node.Children, newNode("Directive", directive, d.Start, d.End))
+		if directive == "forward" || directive == "external" || directive == "abstract" {
+			body = false
+		}
+		for directive == "external" && !p.atEnd() && !p.is(";") {
+			// external 'libc' name 'printf'
+			p.next()
+		}
+		p.expect(";", fmt.Sprintf("después de '%s'", directive))
+	}
+	if body {
+		node.Children = append(node.Children, p.parsePascalBlock())
+		if !p.stmtErr {
+			p.expect(";", fmt.Sprintf("después del 'end' de '%s'", label))
+		}
+	}
+	node.End = p.prevEnd()
+	return node
+}
+
+// parsePascalParams analiza (a, b: integer; var total: real; const s: string)
+func (p *Parser) parsePascalParams() ParseNode {
+	open := p.next()
+	params := newNode("Params", "", open.Start, open.End)
+	for !p.atEnd() && !p.is(")") {
+		var modifier ParseNode
+		hasModifier := p.sqlIs("var", "const", "out", "constref")
+		if hasModifier {
+			m := p.next()
+			modifier = newNode("Modifier", strings.ToLower(m.Lexeme), m.Start, m.End)
+		}
+		names := p.parsePascalNames("en la lista de parámetros")
+		if p.stmtErr {
+			break
+		}
+		var children []ParseNode
+		if hasModifier {
+			children = append(children, modifier)
+		}
+		if p.accept(":") {
+			children = append(children, p.parsePascalType())
+			if p.accept("=") {
+				children = append(children, p.parsePascalExpr(0))
+			}
+		} else if !hasModifier {
+			// Solo los parámetros var y const pueden no tener tipo
+			p.expect(":", "y el tipo del parámetro")
+			break
+		}
+		for _, name := range names {
+			params.Children = append(params.Children, newNode("Param", name.Lexeme, name.Start, p.prevEnd(), children...))
+		}
+		if !p.accept(";") {
+			break
+		}
+	}
+	if !p.stmtErr {
+		p.expect(")", "al cerrar la lista de parámetros")
+	}
+	params.End = p.prevEnd()
+	return params
+}
+
+// ──────────────────────────────── Tipos ──────────────────────────────────
+
+// parsePascalType analiza un tipo; la etiqueta es su texto ('array[1..10]
+// of integer') y los hijos, los nombres y tipos que menciona
+func (p *Parser) parsePascalType() ParseNode {
+	start := p.cur().Start
+	node := newNode("Type", "", start, start)
+	p.sqlAccept("packed")
+	switch {
+	case p.atEnd():
+		p.errorAt(start, "Se esperaba un tipo, se encontró el final del código")
+		return node
+	case p.sqlIs("array"):
+		p.next()
+		if p.accept("[") {
+			for {
+				node.Children = append(node.Children, p.parsePascalOrdinal())
+				if !p.accept(",") {
+					break
+				}
+			}
+			p.expect("]", "al cerrar los índices del arreglo")
+		}
+		if p.sqlExpect("of", "en la declaración del arreglo") {
+			node.Children = append(node.Children, p.parsePascalType())
+		}
+	case p.sqlIs("record"):
+		p.next()
+		node.Children = p.parsePascalFields()
+		p.sqlExpect("end", "al final del registro")
+	case p.sqlIs("set", "file"):
+		kw := p.next()
+		if p.sqlAccept("of") {
+			node.Children = append(node.Children, p.parsePascalType())
+		} else if strings.EqualFold(kw.Lexeme, "set") {
+			p.sqlExpect("of", "después de 'set'")
+		}
+	case p.is("^"):
+		p.next()
+		node.Children = append(node.Children, p.parsePascalType())
+	case p.is("("):
+		// Enumeración: (rojo, verde, azul)
+		p.next()
+		for _, name := range p.parsePascalNames("en la enumeración") {
+			node.Children = append(node.Children, newNode("Enumerator", name.Lexeme, name.Start, name.End))
+		}
+		p.expect(")", "al cerrar la enumeración")
+	case p.sqlIs("procedure", "function"):
+		// Tipo procedural: function(x: real): real
+		kw := p.next()
+		if p.is("(") {
+			node.Children = append(node.Children, p.parsePascalParams())
+		}
+		if strings.EqualFold(kw.Lexeme, "function") && p.expect(":", "y el tipo del resultado") {
+			node.Children = append(node.Children, p.parsePascalType())
+		}
+		if p.sqlIs("of") && sqlWordIs(p.peek(1), "object") {
+			p.pos += 2
+		}
+	case p.sqlIs("class", "object", "interface"):
+		p.skipPascalClass()
+	default:
+		node.Children = append(node.Children, p.parsePascalOrdinal())
+	}
+	node.End = p.prevEnd()
+	node.Label = p.sourceText(start, node.End)
+	return node
+}
+
+// parsePascalOrdinal analiza un nombre de tipo o un subrango (1..10, 'a'..'z')
+func (p *Parser) parsePascalOrdinal() ParseNode {
+	low := p.parsePascalExpr(pascalAdditive)
+	if !p.accept("..") {
+		return low
+	}
+	high := p.parsePascalExpr(pascalAdditive)
+	return newNode("Range", "..", low.Pos, high.End, low, high)
+}
+
+// parsePascalFields analiza los campos de un registro hasta su 'end'; la
+// parte variante (case ... of) se conserva como un nodo Variant
+func (p *Parser) parsePascalFields() []ParseNode {
+	var fields []ParseNode
+	for !p.atEnd() && !p.sqlIs("end") && !p.stmtErr {
+		if p.sqlIs("case") {
+			kw := p.next()
+			depth := 0
+			for !p.atEnd() && (depth > 0 || !p.sqlIs("end")) {
+				switch {
+				case p.is("("):
+					depth++
+				case p.is(")"):
+					depth--
+				}
+				p.next()
+			}
+			fields = append(fields, newNode("Variant", "case", kw.Start, p.prevEnd()))
+			break
+		}
+		names := p.parsePascalNames("en el campo del registro")
+		if p.stmtErr || !p.expect(":", "después del nombre del campo") {
+			break
+		}
+		typ := p.parsePascalType()
+		for _, name := range names {
+			fields = append(fields, newNode("Field", name.Lexeme, name.Start, typ.End, typ))
+		}
+		if !p.accept(";") {
+			break
+		}
+	}
+	return fields
+}
+
+// skipPascalClass omite el cuerpo de una clase o interfaz de Object Pascal
+// hasta su 'end'; solo los registros y las clases anidadas abren otro nivel
+func (p *Parser) skipPascalClass() {
+	p.next()
+	if p.is(";") || p.sqlIs("of") {
+		// Declaración adelantada (TNodo = class;) o referencia (class of T)
+		if p.sqlAccept("of") {
+			p.expectName("después de 'class of'")
+		}
+		return
+	}
+	if p.is("(") {
+		p.skipBalanced()
+	}
+	if p.is(";") {
+		// TError = class(Exception);
+		return
+	}
+	depth := 1
+	for !p.atEnd() && depth > 0 {
+		switch {
+		case p.sqlIs("end"):
+			depth--
+		case p.sqlIs("record"),
+			p.sqlIs("class", "object") && p.pos > 0 && p.toks[p.pos-1].Lexeme == "=":
+			depth++
+		}
+		p.next()
+	}
+	if depth > 0 {
+		p.errorAt(p.cur().Start, "Se esperaba 'end' al final de la clase, se encontró el final del código")
+	}
+}
+
+// ───────────────────────────── Sentencias ────────────────────────────────
+
+// parsePascalStatements analiza sentencias separadas por ';' hasta que
+// stop() sea verdadero
+func (p *Parser) parsePascalStatements(stop func() bool) []ParseNode {
+	var stmts []ParseNode
+	for !p.atEnd() && !stop() {
+		start := p.pos
+		if p.accept(";") {
+			// Sentencia vacía
+			continue
+		}
+		stmts = append(stmts, p.parsePascalStatement())
+		if !p.stmtErr && !p.accept(";") && !stop() {
+			p.expect(";", "entre las sentencias")
+		}
+		p.pasRecover()
+		if p.pos == start {
+			p.pos++
+		}
+	}
+	return stmts
+}
+
+func (p *Parser) parsePascalCompound() ParseNode {
+	kw := p.next()
+	node := newNode("Compound", "begin", kw.Start, kw.End)
+	node.Children = p.parsePascalStatements(p.pasBlockEnd)
+	p.sqlExpect("end", "para cerrar el bloque 'begin'")
+	node.End = p.prevEnd()
+	return node
+}
+
+// parsePascalStatement analiza una sentencia. Una sentencia vacía (antes de
+// 'else', 'end' o ';') devuelve un nodo Empty.
+func (p *Parser) parsePascalStatement() ParseNode {
+	tk := p.cur()
+	switch {
+	case p.is(";") || p.sqlIs("else") || p.pasBlockEnd():
+		return newNode("Empty", "", tk.Start, tk.Start)
+	case p.sqlIs("begin"):
+		return p.parsePascalCompound()
+	case p.sqlIs("if"):
+		return p.parsePascalIf()
+	case p.sqlIs("while"):
+		p.next()
+		cond := p.parsePascalExpr(0)
+		p.sqlExpect("do", "después de la condición del while")
+		body := p.parsePascalStatement()
+		return newNode("While", "while", tk.Start, p.prevEnd(), cond, body)
+	case p.sqlIs("repeat"):
+		p.next()
+		body := newNode("Block", "", p.cur().Start, p.cur().Start)
+		body.Children = p.parsePascalStatements(p.pasBlockEnd)
+		body.End = p.prevEnd()
+		node := newNode("Repeat", "repeat", tk.Start, tk.End, body)
+		if p.sqlExpect("until", "para cerrar el ciclo 'repeat'") {
+			node.Children = append(node.Children, p.parsePascalExpr(0))
+		}
+		node.End = p.prevEnd()
+		return node
+	case p.sqlIs("for"):
+		return p.parsePascalFor()
+	case p.sqlIs("case"):
+		return p.parsePascalCase()
+	case p.sqlIs("with"):
+		p.next()
+		node := newNode("With", "with", tk.Start, tk.End)
+		for {
+			node.Children = append(node.Children, p.parsePascalExpr(0))
+			if !p.accept(",") {
+				break
+			}
+		}
+		p.sqlExpect("do", "después de 'with'")
+		node.Children = append(node.Children, p.parsePascalStatement())
+		node.End = p.prevEnd()
+		return node
+	case p.sqlIs("goto"):
+		p.next()
+		target := p.next()
+		if !isName(target) && target.Type != NUMBER {
+			p.errorAt(target.Start, fmt.Sprintf("Se esperaba una etiqueta después de 'goto', se encontró '%s'", target.Lexeme))
+		}
+		return newNode("Goto", target.Lexeme, tk.Start, target.End, newNode("LabelRef", target.Lexeme, target.Start, target.End))
+	case p.sqlIs("try"):
+		return p.parsePascalTry()
+	case p.sqlIs("raise"):
+		p.next()
+		node := newNode("Raise", "raise", tk.Start, tk.End)
+		if !p.is(";") && !p.pasBlockEnd() && !p.sqlIs("else") {
+			node.Children = append(node.Children, p.parsePascalExpr(0))
+		}
+		node.End = p.prevEnd()
+		return node
+	case (isName(tk) || tk.Type == NUMBER) && p.peek(1).Lexeme == ":" && p.peek(2).Lexeme != "=":
+		// Sentencia con etiqueta: 10: writeln('fin')
+		p.pos += 2
+		stmt := p.parsePascalStatement()
+		return newNode("Labeled", tk.Lexeme, tk.Start, p.prevEnd(), stmt)
+	case tk.Type == KEYWORD && !p.sqlIs("nil", "true", "false", "not", "inherited"):
+		p.errorAt(tk.Start, fmt.Sprintf("Se esperaba una sentencia, se encontró '%s'", tk.Lexeme))
+		return newNode("Error", "", tk.Start, tk.End)
+	}
+	return p.parsePascalSimple()
+}
+
+// parsePascalSimple analiza una asignación (x := 1) o la llamada a un
+// procedimiento (writeln('hola'))
+func (p *Parser) parsePascalSimple() ParseNode {
+	p.sqlAccept("inherited")
+	target := p.parsePascalPostfix(p.parsePascalPrimary())
+	if p.stmtErr {
+		return target
+	}
+	if p.accept(":=") {
+		value := p.parsePascalExpr(0)
+		return newNode("Assign", ":=", target.Pos, value.End, target, value)
+	}
+	switch {
+	case p.is("="):
+		p.errorAt(p.cur().Start, "Se esperaba ':=' en la asignación; '=' compara valores en Pascal")
+	case target.Kind != "Identifier" && target.Kind != "Call" && target.Kind != "Member":
+		p.errorAt(target.Pos, fmt.Sprintf("Se esperaba una sentencia, se encontró '%s'", p.sourceText(target.Pos, target.End)))
+	}
+	return newNode("ExprStmt", "", target.Pos, target.End, target)
+}
+
+// if c then sentencia [else sentencia]; el else pertenece al if más cercano
+func (p *Parser) parsePascalIf() ParseNode {
+	kw := p.next()
+	cond := p.parsePascalExpr(0)
+	p.sqlExpect("then", "después de la condición del if")
+	then := p.parsePascalStatement()
+	node := newNode("If", "if", kw.Start, p.prevEnd(), cond, then)
+	if p.stmtErr {
+		return node
+	}
+	if p.is(";") && sqlWordIs(p.peek(1), "else") {
+		// El error es solo el ';': el else se analiza igual
+		p.errorAt(p.cur().Start, "No se permite ';' antes de 'else': termina la sentencia if")
+		p.next()
+		p.stmtErr = false
+	}
+	if p.sqlIs("else") {
+		elseTok := p.next()
+		body := p.parsePascalStatement()
+		node.Children = append(node.Children, newNode("Else", "else", elseTok.Start, p.prevEnd(), body))
+	}
+	node.End = p.prevEnd()
+	return node
+}
+
+// for i := 1 to n do ... / for x in lista do ...
+func (p *Parser) parsePascalFor() ParseNode {
+	kw := p.next()
+	name := p.expectName("como variable del for")
+	if p.stmtErr {
+		return newNode("For", "for", kw.Start, p.prevEnd())
+	}
+	control := newNode("Identifier", name.Lexeme, name.Start, name.End)
+	if p.sqlAccept("in") {
+		iter := p.parsePascalExpr(0)
+		p.sqlExpect("do", "después de la colección del for")
+		body := p.parsePascalStatement()
+		return newNode("ForIn", "in", kw.Start, p.prevEnd(), control, iter, body)
+	}
+	node := newNode("For", "for", kw.Start, name.End, control)
+	if !p.expect(":=", "después de la variable del for") {
+		return node
+	}
+	from := p.parsePascalExpr(0)
+	dir := p.cur()
+	if !p.sqlIs("to", "downto") {
+		p.errorAt(dir.Start, fmt.Sprintf("Se esperaba 'to' o 'downto' en el for, se encontró %s", p.foundText()))
+		return node
+	}
+	p.next()
+	to := p.parsePascalExpr(0)
+	node.Children = append(node.Children, newNode("Range", strings.ToLower(dir.Lexeme), from.Pos, to.End, from, to))
+	p.sqlExpect("do", "después del límite del for")
+	node.Children = append(node.Children, p.parsePascalStatement())
+	node.End = p.prevEnd()
+	return node
+}
+
+// case x of 1, 2: ...; 3..5: ...; else ... end
+func (p *Parser) parsePascalCase() ParseNode {
+	kw := p.next()
+	node := newNode("Case", "case", kw.Start, kw.End, p.parsePascalExpr(0))
+	p.sqlExpect("of", "después de la expresión del case")
+	for !p.stmtErr && !p.atEnd() && !p.sqlIs("end", "else", "otherwise") {
+		start := p.cur().Start
+		branch := newNode("CaseBranch", "", start, start)
+		for {
+			branch.Children = append(branch.Children, p.parsePascalOrdinal())
+			if !p.accept(",") {
+				break
+			}
+		}
+		branch.Label = p.sourceText(start, p.prevEnd())
+		p.expect(":", "después de los valores del case")
+		branch.Children = append(branch.Children, p.parsePascalStatement())
+		branch.End = p.prevEnd()
+		node.Children = append(node.Children, branch)
+		if !p.accept(";") {
+			break
+		}
+	}
+	if p.sqlIs("else", "otherwise") {
+		elseTok := p.next()
+		els := newNode("Else", "else", elseTok.Start, elseTok.End)
+		els.Children = p.parsePascalStatements(func() bool { return p.sqlIs("end") })
+		els.End = p.prevEnd()
+		node.Children = append(node.Children, els)
+	}
+	p.sqlExpect("end", "para cerrar el case")
+	node.End = p.prevEnd()
+	return node
+}
+
+// try ... except on E: Exception do ... end / try ... finally ... end
+func (p *Parser) parsePascalTry() ParseNode {
+	kw := p.next()
+	body := newNode("Block", "", p.cur().Start, p.cur().Start)
+	body.Children = p.parsePascalStatements(p.pasBlockEnd)
+	body.End = p.prevEnd()
+	node := newNode("Try", "try", kw.Start, kw.End, body)
+	switch {
+	case p.sqlIs("except"):
+		ekw := p.next()
+		except := newNode("Except", "except", ekw.Start, ekw.End)
+		for p.sqlIs("on") {
+			on := p.next()
+			handler := newNode("Handler", "", on.Start, on.End)
+			if isName(p.cur()) && p.peek(1).Lexeme == ":" {
+				handler.Label = p.next().Lexeme
+				p.next()
+			}
+			handler.Children = append(handler.Children, p.parsePascalType())
+			p.sqlExpect("do", "en el manejador de la excepción")
+			handler.Children = append(handler.Children, p.parsePascalStatement())
+			handler.End = p.prevEnd()
+			except.Children = append(except.Children, handler)
+			p.pasRecover()
+			if !p.accept(";") {
+				break
+			}
+		}
+		if p.sqlAccept("else") || len(except.Children) == 0 {
+			except.Children = append(except.Children, p.parsePascalStatements(func() bool { return p.sqlIs("end") })...)
+		}
+		except.End = p.prevEnd()
+		node.Children = append(node.Children, except)
+	case p.sqlIs("finally"):
+		fkw := p.next()
+		finally := newNode("Finally", "finally", fkw.Start, fkw.End)
+		finally.Children = p.parsePascalStatements(func() bool { return p.sqlIs("end") })
+		finally.End = p.prevEnd()
+		node.Children = append(node.Children, finally)
+	default:
+		p.errorAt(p.cur().Start, fmt.Sprintf("Se esperaba 'except' o 'finally' en el try, se encontró %s", p.foundText()))
+		return node
+	}
+	p.sqlExpect("end", "para cerrar el try")
+	node.End = p.prevEnd()
+	return node
+}
+
+// ───────────────────────────── Expresiones ───────────────────────────────
+
+// pascalBinaryOp devuelve el operador binario en la posición actual
+func (p *Parser) pascalBinaryOp() (string, int, bool) {
+	if p.atEnd() {
+		return "", 0, false
+	}
+	tk := p.cur()
+	if tk.Type != OPERATOR && tk.Type != KEYWORD {
+		return "", 0, false
+	}
+	op := strings.ToLower(tk.Lexeme)
+	prec, ok := pascalBinaryOps[op]
+	return op, prec, ok
+}
+
+// parsePascalExpr analiza una expresión con operadores de precedencia mayor
+// o igual a minPrec
+func (p *Parser) parsePascalExpr(minPrec int) ParseNode {
+	left := p.parsePascalUnary()
+	for !p.stmtErr {
+		op, prec, ok := p.pascalBinaryOp()
+		if !ok || prec < minPrec {
+			break
+		}
+		p.next()
+		right := p.parsePascalExpr(prec + 1)
+		left = newNode("Binary", op, left.Pos, right.End, left, right)
+	}
+	return left
+}
+
+func (p *Parser) parsePascalUnary() ParseNode {
+	tk := p.cur()
+	switch {
+	case p.sqlIs("not"):
+		p.next()
+		operand := p.parsePascalUnary()
+		return newNode("Unary", "not", tk.Start, operand.End, operand)
+	case p.is("-", "+", "@"):
+		p.next()
+		operand := p.parsePascalUnary()
+		return newNode("Unary", tk.Lexeme, tk.Start, operand.End, operand)
+	}
+	return p.parsePascalPostfix(p.parsePascalPrimary())
+}
+
+func (p *Parser) parsePascalPrimary() ParseNode {
+	tk := p.cur()
+	switch {
+	case p.atEnd():
+		p.errorAt(tk.Start, "Se esperaba una expresión, se encontró el final del código")
+		return newNode("Error", "", tk.Start, tk.Start)
+	case tk.Type == NUMBER:
+		p.next()
+		return newNode("Number", tk.Lexeme, tk.Start, tk.End)
+	case tk.Type == STRING:
+		p.next()
+		return newNode("String", tk.Lexeme, tk.Start, tk.End)
+	case p.sqlIs("nil", "true", "false"):
+		p.next()
+		return newNode("Literal", strings.ToLower(tk.Lexeme), tk.Start, tk.End)
+	case isName(tk):
+		p.next()
+		return newNode("Identifier", tk.Lexeme, tk.Start, tk.End)
+	case p.is("(") && isName(p.peek(1)) && p.peek(2).Lexeme == ":":
+		return p.parsePascalRecordInit()
+	case p.is("("):
+		// Expresión entre paréntesis o constante de arreglo: (1, 2, 3)
+		p.next()
+		first := p.parsePascalExpr(0)
+		if !p.is(",") {
+			p.expect(")", "al cerrar la expresión")
+			return first
+		}
+		list := newNode("List", "", tk.Start, tk.End, first)
+		for p.accept(",") {
+			list.Children = append(list.Children, p.parsePascalExpr(0))
+		}
+		p.expect(")", "al cerrar la lista")
+		list.End = p.prevEnd()
+		return list
+	case p.is("["):
+		// Conjunto: [1, 3..5, 'a']
+		p.next()
+		set := newNode("Set", "[]", tk.Start, tk.End)
+		for !p.atEnd() && !p.is("]") {
+			set.Children = append(set.Children, p.parsePascalOrdinal())
+			if !p.accept(",") {
+				break
+			}
+		}
+		p.expect("]", "al cerrar el conjunto")
+		set.End = p.prevEnd()
+		return set
+	}
+	p.errorAt(tk.Start, fmt.Sprintf("Se esperaba una expresión, se encontró '%s'", tk.Lexeme))
+	return newNode("Error", "", tk.Start, tk.End)
+}
+
+// parsePascalRecordInit analiza una constante de registro: (x: 1; y: 2)
+func (p *Parser) parsePascalRecordInit() ParseNode {
+	open := p.next()
+	node := newNode("RecordInit", "", open.Start, open.End)
+	for isName(p.cur()) {
+		name := p.next()
+		p.expect(":", "después del campo")
+		value := p.parsePascalExpr(0)
+		node.Children = append(node.Children, newNode("FieldInit", name.Lexeme, name.Start, value.End, value))
+		if !p.accept(";") {
+			break
+		}
+	}
+	p.expect(")", "al cerrar la constante del registro")
+	node.End = p.prevEnd()
+	return node
+}
+
+// parsePascalPostfix analiza llamadas, índices, campos y desreferencias
+func (p *Parser) parsePascalPostfix(expr ParseNode) ParseNode {
+	for !p.atEnd() && !p.stmtErr {
+		tk := p.cur()
+		switch {
+		case tk.Lexeme == "(":
+			args := p.parsePascalArguments()
+			expr = newNode("Call", expr.Label, expr.Pos, args.End, expr, args)
+		case tk.Lexeme == "[":
+			p.next()
+			index := newNode("Index", "[]", expr.Pos, tk.End, expr)
+			for {
+				index.Children = append(index.Children, p.parsePascalExpr(0))
+				if !p.accept(",") {
+					break
+				}
+			}
+			p.expect("]", "al cerrar el índice")
+			index.End = p.prevEnd()
+			expr = index
+		case tk.Lexeme == "." && (isName(p.peek(1)) || p.peek(1).Type == KEYWORD):
+			p.next()
+			name := p.next()
+			expr = newNode("Member", name.Lexeme, expr.Pos, name.End, expr)
+		case tk.Lexeme == "^":
+			p.next()
+			expr = newNode("Deref", "^", expr.Pos, tk.End, expr)
+		default:
+			return expr
+		}
+	}
+	return expr
+}
+
+// parsePascalArguments analiza los argumentos de una llamada; en write y
+// writeln cada uno puede llevar ancho y decimales (x:8:2)
+func (p *Parser) parsePascalArguments() ParseNode {
+	open := p.next()
+	args := newNode("Arguments", "", open.Start, open.End)
+	for !p.atEnd() && !p.is(")") {
+		arg := p.parsePascalExpr(0)
+		if p.is(":") {
+			format := newNode("Format", ":", arg.Pos, arg.End, arg)
+			for p.accept(":") {
+				format.Children = append(format.Children, p.parsePascalExpr(0))
+			}
+			format.End = p.prevEnd()
+			arg = format
+		}
+		args.Children = append(args.Children, arg)
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.expect(")", "al cerrar la lista de argumentos")
+	args.End = p.prevEnd()
+	return args
+}
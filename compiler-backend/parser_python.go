@@ -0,0 +1,884 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ─────────────────────────── Gramática Python ────────────────────────────
+//
+// Python no tiene llaves: los bloques se determinan por la indentación de
+// cada línea lógica. Una línea lógica termina en el salto de línea salvo que
+// haya paréntesis, corchetes o llaves abiertos o una barra invertida final.
+
+type pyLine struct {
+	indent     int // ancho de la indentación (tab = hasta el siguiente múltiplo de 8)
+	start, end int // rango de tokens de la línea
+}
+
+func (p *Parser) pythonLines() []pyLine {
+	var lines []pyLine
+	depth := 0
+	for i, tk := range p.toks {
+		newLine := i == 0
+		if i > 0 && depth == 0 {
+			prev := p.toks[i-1]
+			continued := strings.Contains(p.src[prev.End:tk.Start], "\\\n")
+			newLine = !continued && p.lineOf(tk.Start) > p.lineOf(prev.End-1)
+		}
+		if newLine {
+			if len(lines) > 0 {
+				lines[len(lines)-1].end = i
+			}
+			lines = append(lines, pyLine{indent: p.indentOf(tk.Start), start: i, end: len(p.toks)})
+		}
+		switch tk.Lexeme {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			if depth > 0 {
+				depth--
+			}
+		}
+	}
+	return lines
+}
+
+func (p *Parser) indentOf(pos int) int {
+	lineStart := p.lineStarts[p.lineOf(pos)-1]
+	width := 0
+	for _, c := range p.src[lineStart:pos] {
+		if c == '\t' {
+			width += 8 - width%8
+		} else {
+			width++
+		}
+	}
+	return width
+}
+
+// enterLine posiciona el cursor en la siguiente línea lógica
+func (p *Parser) enterLine() pyLine {
+	line := p.pyLines[p.li]
+	p.li++
+	p.pos, p.end = line.start, line.end
+	p.stmtErr = false
+	return line
+}
+
+func (p *Parser) parsePythonProgram() ParseNode {
+	p.pyLines = p.pythonLines()
+	p.li = 0
+	root := newNode("Program", "python", 0, len(p.src))
+	if len(p.pyLines) == 0 {
+		return root
+	}
+	if p.resumeAt > 0 {
+		for p.li < len(p.pyLines) && p.pyLines[p.li].start < p.resumeAt {
+			p.li++
+		}
+	} else if p.pyLines[0].indent > 0 {
+		p.errorWithCode(p.toks[p.pyLines[0].start].Start, CodeUnexpectedIndent, "", "Indentación inesperada al inicio del programa")
+		p.stmtErr = false
+	}
+	root.Children = p.parsePyStatementsUntil(p.pyLines[0].indent, p.resynced)
+	return root
+}
+
+// parsePyStatements analiza líneas con la indentación dada hasta encontrar
+// una línea menos indentada.
+func (p *Parser) parsePyStatements(indent int) []ParseNode {
+	return p.parsePyStatementsUntil(indent, func() bool { return false })
+}
+
+// parsePyStatementsUntil es parsePyStatements deteniéndose además cuando
+// stop() es verdadero al comienzo de una línea
+func (p *Parser) parsePyStatementsUntil(indent int, stop func() bool) []ParseNode {
+	var stmts []ParseNode
+	for p.li < len(p.pyLines) {
+		line := p.pyLines[p.li]
+		if line.indent < indent || stop() {
+			break
+		}
+		p.enterLine()
+		if line.indent > indent {
+			p.errorWithCode(p.cur().Start, CodeUnexpectedIndent, "", "Indentación inesperada")
+			p.stmtErr = false
+		}
+		stmts = append(stmts, p.parsePyStatement(line.indent)...)
+	}
+	return stmts
+}
+
+func (p *Parser) parsePyStatement(indent int) []ParseNode {
+	tk := p.cur()
+	switch tk.Lexeme {
+	case "if":
+		return []ParseNode{p.parsePyIf(indent)}
+	case "while":
+		p.next()
+		cond := p.parsePyTest()
+		body := p.parsePySuite(indent)
+		node := newNode("While", "while", tk.Start, body.End, newNode("Condition", "", cond.Pos, cond.End, cond), body)
+		p.parsePyElse(indent, &node)
+		return []ParseNode{node}
+	case "for":
+		return []ParseNode{p.parsePyFor(indent, tk.Start)}
+	case "try":
+		return []ParseNode{p.parsePyTry(indent)}
+	case "with":
+		return []ParseNode{p.parsePyWith(indent, tk.Start)}
+	case "def":
+		return []ParseNode{p.parsePyDef(indent, tk.Start)}
+	case "class":
+		return []ParseNode{p.parsePyClass(indent, tk.Start)}
+	case "async":
+		p.next()
+		switch {
+		case p.is("def"):
+			return []ParseNode{p.parsePyDef(indent, tk.Start)}
+		case p.is("for"):
+			return []ParseNode{p.parsePyFor(indent, tk.Start)}
+		case p.is("with"):
+			return []ParseNode{p.parsePyWith(indent, tk.Start)}
+		}
+		p.errorAt(p.cur().Start, "Se esperaba 'def', 'for' o 'with' después de 'async'")
+		return nil
+	case "@":
+		return []ParseNode{p.parsePyDecorated(indent)}
+	case "elif", "else", "except", "finally":
+		p.errorAt(tk.Start, fmt.Sprintf("'%s' sin una sentencia compuesta correspondiente", tk.Lexeme))
+		// Se analiza su bloque para no reportar además indentación inesperada
+		for !p.atEnd() && !p.is(":") {
+			p.next()
+		}
+		p.parsePySuite(indent)
+		return nil
+	}
+	return p.parsePySimpleStatements()
+}
+
+// parsePySimpleStatements analiza sentencias simples separadas por ';'
+func (p *Parser) parsePySimpleStatements() []ParseNode {
+	var stmts []ParseNode
+	for !p.atEnd() {
+		stmts = append(stmts, p.parsePySimple())
+		if p.stmtErr {
+			break
+		}
+		if !p.accept(";") {
+			break
+		}
+	}
+	if !p.atEnd() && !p.stmtErr {
+		p.errorAt(p.cur().Start, fmt.Sprintf("Token inesperado '%s' al final de la sentencia", p.cur().Lexeme))
+	}
+	return stmts
+}
+
+func (p *Parser) parsePySimple() ParseNode {
+	tk := p.cur()
+	switch tk.Lexeme {
+	case "pass", "break", "continue":
+		p.next()
+		return newNode(statementKinds[tk.Lexeme], tk.Lexeme, tk.Start, tk.End)
+	case "return":
+		p.next()
+		node := newNode("Return", "return", tk.Start, tk.End)
+		if !p.atEnd() && !p.is(";") {
+			value := p.parsePyTestList()
+			node.Children = append(node.Children, value)
+			node.End = value.End
+		}
+		return node
+	case "raise":
+		p.next()
+		node := newNode("Raise", "raise", tk.Start, tk.End)
+		if !p.atEnd() && !p.is(";") {
+			exc := p.parsePyTest()
+			node.Children = append(node.Children, exc)
+			if p.accept("from") {
+				node.Children = append(node.Children, p.parsePyTest())
+			}
+			node.End = p.prevEnd()
+		}
+		return node
+	case "global", "nonlocal":
+		p.next()
+		node := newNode(statementKinds[tk.Lexeme], tk.Lexeme, tk.Start, tk.End)
+		for {
+			name := p.expectName("después de '" + tk.Lexeme + "'")
+			node.Children = append(node.Children, newNode("Identifier", name.Lexeme, name.Start, name.End))
+			if !p.accept(",") {
+				break
+			}
+		}
+		node.End = p.prevEnd()
+		return node
+	case "del":
+		p.next()
+		targets := p.parsePyTestList()
+		return newNode("Del", "del", tk.Start, targets.End, targets)
+	case "assert":
+		p.next()
+		node := newNode("Assert", "assert", tk.Start, tk.End, p.parsePyTest())
+		if p.accept(",") {
+			node.Children = append(node.Children, p.parsePyTest())
+		}
+		node.End = p.prevEnd()
+		return node
+	case "import":
+		p.next()
+		node := newNode("Import", "", tk.Start, tk.End)
+		for {
+			name := p.parsePyDottedName()
+			alias := newNode("ImportName", name, p.cur().Start, p.prevEnd())
+			if p.accept("as") {
+				as := p.expectName("después de 'as'")
+				alias.Label += " as " + as.Lexeme
+			}
+			node.Children = append(node.Children, alias)
+			if !p.accept(",") {
+				break
+			}
+		}
+		node.End = p.prevEnd()
+		node.Label = p.sourceText(tk.Start, node.End)
+		return node
+	case "from":
+		p.next()
+		for p.is(".", "...") {
+			p.next()
+		}
+		if !p.is("import") {
+			p.parsePyDottedName()
+		}
+		p.expect("import", "en la sentencia 'from'")
+		paren := p.accept("(")
+		node := newNode("Import", "", tk.Start, tk.End)
+		if p.is("*") {
+			star := p.next()
+			node.Children = append(node.Children, newNode("ImportName", "*", star.Start, star.End))
+		} else {
+			for !p.atEnd() && !p.is(")") {
+				name := p.expectName("en la lista de 'import'")
+				alias := newNode("ImportName", name.Lexeme, name.Start, name.End)
+				if p.accept("as") {
+					as := p.expectName("después de 'as'")
+					alias.Label += " as " + as.Lexeme
+				}
+				node.Children = append(node.Children, alias)
+				if !p.accept(",") {
+					break
+				}
+			}
+		}
+		if paren {
+			p.expect(")", "al cerrar la lista de 'import'")
+		}
+		node.End = p.prevEnd()
+		node.Label = p.sourceText(tk.Start, node.End)
+		return node
+	}
+
+	// Sentencias de expresión y asignaciones
+	target := p.parsePyTestList()
+	switch {
+	case p.is("="):
+		node := target
+		for p.is("=") {
+			p.next()
+			var value ParseNode
+			if p.is("yield") {
+				value = p.parsePyYield()
+			} else {
+				value = p.parsePyTestList()
+			}
+			node = newNode("Assign", "=", target.Pos, value.End, node, value)
+		}
+		return node
+	case !p.atEnd() && assignmentOps[p.cur().Lexeme] && p.cur().Type == OPERATOR:
+		op := p.next()
+		value := p.parsePyTestList()
+		return newNode("AugAssign", op.Lexeme, target.Pos, value.End, target, value)
+	case p.is(":"):
+		// Asignación anotada: x: int = 5
+		p.next()
+		annotation := p.parsePyTest()
+		node := newNode("AnnAssign", ":", target.Pos, annotation.End, target, annotation)
+		if p.accept("=") {
+			value := p.parsePyTestList()
+			node.Children = append(node.Children, value)
+			node.End = value.End
+		}
+		return node
+	}
+	return newNode("ExprStmt", "", target.Pos, target.End, target)
+}
+
+func (p *Parser) parsePyDottedName() string {
+	start := p.cur().Start
+	p.expectName("en el nombre del módulo")
+	for p.is(".") {
+		p.next()
+		p.expectName("en el nombre del módulo")
+	}
+	return p.sourceText(start, p.prevEnd())
+}
+
+// parsePySuite analiza ':' seguido de un bloque indentado o de sentencias en
+// la misma línea.
+func (p *Parser) parsePySuite(indent int) ParseNode {
+	p.expect(":", "al final del encabezado")
+	block := newNode("Block", ":", p.prevEnd(), p.prevEnd())
+	if p.stmtErr {
+		// Se descarta el resto de la línea para no encadenar errores
+		p.pos = p.end
+	}
+	if !p.atEnd() {
+		block.Children = p.parsePySimpleStatements()
+	} else if p.li < len(p.pyLines) && p.pyLines[p.li].indent > indent {
+		block.Children = p.parsePyStatements(p.pyLines[p.li].indent)
+	} else {
+		p.stmtErr = false
+		p.errorWithCode(block.Pos, CodeUnexpectedIndent, "", "Se esperaba un bloque indentado después de ':'")
+	}
+	if n := len(block.Children); n > 0 {
+		block.End = block.Children[n-1].End
+	}
+	return block
+}
+
+// pyClause avanza a la siguiente línea si es una cláusula (elif, else, ...)
+// con la misma indentación que la sentencia compuesta.
+func (p *Parser) pyClause(indent int, keywords ...string) (Token, bool) {
+	if p.li >= len(p.pyLines) {
+		return Token{}, false
+	}
+	line := p.pyLines[p.li]
+	if line.indent != indent {
+		return Token{}, false
+	}
+	first := p.toks[line.start]
+	for _, kw := range keywords {
+		if first.Lexeme == kw {
+			p.enterLine()
+			p.next()
+			return first, true
+		}
+	}
+	return Token{}, false
+}
+
+func (p *Parser) parsePyElse(indent int, node *ParseNode) {
+	if kw, ok := p.pyClause(indent, "else"); ok {
+		body := p.parsePySuite(indent)
+		node.Children = append(node.Children, newNode("Else", "else", kw.Start, body.End, body))
+		node.End = body.End
+	}
+}
+
+func (p *Parser) parsePyIf(indent int) ParseNode {
+	kw := p.next()
+	cond := p.parsePyTest()
+	body := p.parsePySuite(indent)
+	node := newNode("If", "if", kw.Start, body.End, newNode("Condition", "", cond.Pos, cond.End, cond), body)
+	if elif, ok := p.pyClause(indent, "elif"); ok {
+		// elif se representa como un If anidado dentro de Else
+		p.pos--
+		nested := p.parsePyIf(indent)
+		node.Children = append(node.Children, newNode("Else", "elif", elif.Start, nested.End, nested))
+		node.End = nested.End
+		return node
+	}
+	p.parsePyElse(indent, &node)
+	return node
+}
+
+func (p *Parser) parsePyFor(indent, start int) ParseNode {
+	p.expect("for", "")
+	target := p.parsePyTargetList()
+	p.expect("in", "en el ciclo 'for'")
+	iter := p.parsePyTestList()
+	body := p.parsePySuite(indent)
+	node := newNode("ForEach", "in", start, body.End, target, iter, body)
+	p.parsePyElse(indent, &node)
+	return node
+}
+
+// parsePyTargetList analiza los destinos de un for (se detiene antes de 'in')
+func (p *Parser) parsePyTargetList() ParseNode {
+	first := p.parseBinary(5)
+	if !p.is(",") {
+		return first
+	}
+	tuple := newNode("Tuple", "()", first.Pos, first.End, first)
+	for p.accept(",") {
+		if p.is("in") {
+			break
+		}
+		item := p.parseBinary(5)
+		tuple.Children = append(tuple.Children, item)
+		tuple.End = item.End
+	}
+	return tuple
+}
+
+func (p *Parser) parsePyTry(indent int) ParseNode {
+	kw := p.next()
+	body := p.parsePySuite(indent)
+	node := newNode("Try", "try", kw.Start, body.End, body)
+	handlers := 0
+	for {
+		exc, ok := p.pyClause(indent, "except")
+		if !ok {
+			break
+		}
+		handlers++
+		catch := newNode("Catch", "except", exc.Start, exc.End)
+		p.accept("*")
+		if !p.is(":") {
+			catch.Children = append(catch.Children, p.parsePyTest())
+			if p.accept("as") {
+				name := p.expectName("después de 'as'")
+				catch.Label = "except as " + name.Lexeme
+			}
+		}
+		handler := p.parsePySuite(indent)
+		catch.Children = append(catch.Children, handler)
+		catch.End = handler.End
+		node.Children = append(node.Children, catch)
+		node.End = catch.End
+	}
+	p.parsePyElse(indent, &node)
+	if fin, ok := p.pyClause(indent, "finally"); ok {
+		handlers++
+		handler := p.parsePySuite(indent)
+		node.Children = append(node.Children, newNode("Finally", "finally", fin.Start, handler.End, handler))
+		node.End = handler.End
+	}
+	if handlers == 0 {
+		p.errorAt(body.End, "Se esperaba 'except' o 'finally' después del bloque 'try'")
+	}
+	return node
+}
+
+func (p *Parser) parsePyWith(indent, start int) ParseNode {
+	p.expect("with", "")
+	node := newNode("With", "with", start, start)
+	for {
+		item := p.parsePyTest()
+		if p.accept("as") {
+			target := p.parseBinary(5)
+			item = newNode("WithItem", "as", item.Pos, target.End, item, target)
+		}
+		node.Children = append(node.Children, item)
+		if !p.accept(",") {
+			break
+		}
+	}
+	body := p.parsePySuite(indent)
+	node.Children = append(node.Children, body)
+	node.End = body.End
+	return node
+}
+
+func (p *Parser) parsePyDef(indent, start int) ParseNode {
+	p.expect("def", "")
+	name := p.expectName("después de 'def'")
+	params := p.parsePyParams()
+	node := newNode("FunctionDecl", name.Lexeme, start, params.End, params)
+	if p.accept("->") {
+		ret := p.parsePyTest()
+		node.Children = append(node.Children, newNode("Type", p.sourceText(ret.Pos, ret.End), ret.Pos, ret.End))
+	}
+	body := p.parsePySuite(indent)
+	node.Children = append(node.Children, body)
+	node.End = body.End
+	return node
+}
+
+func (p *Parser) parsePyParams() ParseNode {
+	start := p.cur().Start
+	params := newNode("Params", "", start, start)
+	p.expect("(", "para abrir los parámetros")
+	for !p.atEnd() && !p.is(")") {
+		pstart := p.cur().Start
+		prefix := ""
+		switch {
+		case p.is("*", "**"):
+			prefix = p.next().Lexeme
+			if p.is(",", ")") {
+				params.Children = append(params.Children, newNode("Param", prefix, pstart, p.prevEnd()))
+				if !p.accept(",") {
+					break
+				}
+				continue
+			}
+		case p.is("/"):
+			p.next()
+			if !p.accept(",") {
+				break
+			}
+			continue
+		}
+		name := p.expectName("en la lista de parámetros")
+		param := newNode("Param", prefix+name.Lexeme, pstart, name.End)
+		if p.accept(":") {
+			ann := p.parsePyTest()
+			param.Children = append(param.Children, newNode("Type", p.sourceText(ann.Pos, ann.End), ann.Pos, ann.End))
+		}
+		if p.accept("=") {
+			def := p.parsePyTest()
+			param.Children = append(param.Children, def)
+		}
+		param.End = p.prevEnd()
+		params.Children = append(params.Children, param)
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.expect(")", "para cerrar los parámetros")
+	params.End = p.prevEnd()
+	return params
+}
+
+func (p *Parser) parsePyClass(indent, start int) ParseNode {
+	p.expect("class", "")
+	name := p.expectName("después de 'class'")
+	node := newNode("ClassDecl", name.Lexeme, start, name.End)
+	if p.is("(") {
+		args := p.parseArguments("(", ")")
+		for _, base := range args.Children {
+			node.Children = append(node.Children, newNode("Extends", base.Label, base.Pos, base.End, base))
+		}
+	}
+	body := p.parsePySuite(indent)
+	body.Kind = "ClassBody"
+	node.Children = append(node.Children, body)
+	node.End = body.End
+	return node
+}
+
+func (p *Parser) parsePyDecorated(indent int) ParseNode {
+	var decorators []ParseNode
+	for p.is("@") {
+		at := p.next()
+		expr := p.parsePyTest()
+		decorators = append(decorators, newNode("Decorator", expr.Label, at.Start, expr.End, expr))
+		if !p.atEnd() {
+			p.errorAt(p.cur().Start, "Token inesperado después del decorador")
+		}
+		if p.li >= len(p.pyLines) || p.pyLines[p.li].indent != indent {
+			p.errorAt(at.Start, "Se esperaba una definición después del decorador")
+			return newNode("Decorator", expr.Label, at.Start, expr.End, decorators...)
+		}
+		p.enterLine()
+	}
+	start := p.cur().Start
+	if p.accept("async") {
+		start = p.prevEnd()
+	}
+	var def ParseNode
+	switch {
+	case p.is("def"):
+		def = p.parsePyDef(indent, start)
+	case p.is("class"):
+		def = p.parsePyClass(indent, start)
+	default:
+		p.errorAt(p.cur().Start, "Se esperaba 'def' o 'class' después del decorador")
+		return newNode("Decorator", "", start, start, decorators...)
+	}
+	def.Children = append(decorators, def.Children...)
+	def.Pos = decorators[0].Pos
+	return def
+}
+
+// ─────────────────────── Expresiones Python ──────────────────────────────
+
+func (p *Parser) parsePyTest() ParseNode {
+	if p.is("lambda") {
+		return p.parsePyLambda()
+	}
+	expr := p.parseBinary(1)
+	if p.is(":=") {
+		p.next()
+		value := p.parsePyTest()
+		return newNode("Assign", ":=", expr.Pos, value.End, expr, value)
+	}
+	if p.is("if") {
+		p.next()
+		cond := p.parseBinary(1)
+		p.expect("else", "en la expresión condicional")
+		other := p.parsePyTest()
+		return newNode("Conditional", "if-else", expr.Pos, other.End, cond, expr, other)
+	}
+	return expr
+}
+
+// parsePyTestList analiza expresiones separadas por comas (tuplas sin paréntesis)
+func (p *Parser) parsePyTestList() ParseNode {
+	first := p.parsePyStarOrTest()
+	if !p.is(",") {
+		return first
+	}
+	tuple := newNode("Tuple", "()", first.Pos, first.End, first)
+	for p.accept(",") {
+		if p.atEnd() || p.is("=", ";", ":") || assignmentOps[p.cur().Lexeme] {
+			break
+		}
+		item := p.parsePyStarOrTest()
+		tuple.Children = append(tuple.Children, item)
+		tuple.End = item.End
+	}
+	return tuple
+}
+
+func (p *Parser) parsePyStarOrTest() ParseNode {
+	if p.is("*", "**") {
+		op := p.next()
+		operand := p.parseBinary(5)
+		return newNode("Spread", op.Lexeme, op.Start, operand.End, operand)
+	}
+	return p.parsePyTest()
+}
+
+func (p *Parser) parsePyYield() ParseNode {
+	kw := p.next()
+	node := newNode("Yield", "yield", kw.Start, kw.End)
+	p.accept("from")
+	if !p.atEnd() && !p.is(")", ";") {
+		value := p.parsePyTestList()
+		node.Children = append(node.Children, value)
+		node.End = value.End
+	}
+	return node
+}
+
+func (p *Parser) parsePyLambda() ParseNode {
+	kw := p.next()
+	params := newNode("Params", "", p.cur().Start, p.cur().Start)
+	for !p.atEnd() && !p.is(":") {
+		pstart := p.cur().Start
+		prefix := ""
+		if p.is("*", "**") {
+			prefix = p.next().Lexeme
+		}
+		name := p.expectName("en los parámetros de 'lambda'")
+		param := newNode("Param", prefix+name.Lexeme, pstart, name.End)
+		if p.accept("=") {
+			param.Children = append(param.Children, p.parsePyTest())
+		}
+		params.Children = append(params.Children, param)
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.expect(":", "en la expresión 'lambda'")
+	body := p.parsePyTest()
+	return newNode("Lambda", "lambda", kw.Start, body.End, params, body)
+}
+
+// Prefijos de cadenas de Python (f"", r"", b"", ...)
+var pyStringPrefixes = map[string]bool{
+	"f": true, "r": true, "b": true, "u": true, "rb": true, "br": true, "fr": true, "rf": true,
+	"F": true, "R": true, "B": true, "U": true, "Rb": true, "bR": true, "RB": true, "BR": true,
+	"Fr": true, "fR": true, "FR": true, "rF": true, "Rf": true, "RF": true,
+}
+
+func (p *Parser) parsePyAtom() ParseNode {
+	tk := p.cur()
+	switch {
+	case tk.Type == IDENTIFIER:
+		p.next()
+		if pyStringPrefixes[tk.Lexeme] && p.cur().Type == STRING && p.cur().Start == tk.End {
+			lit := p.parseStringLiteral()
+			lit.Label = tk.Lexeme + lit.Label
+			lit.Pos = tk.Start
+			return lit
+		}
+		return newNode("Identifier", tk.Lexeme, tk.Start, tk.End)
+	case tk.Lexeme == "...":
+		p.next()
+		return newNode("Literal", "...", tk.Start, tk.End)
+	case tk.Lexeme == "(":
+		p.next()
+		if p.accept(")") {
+			return newNode("Tuple", "()", tk.Start, p.prevEnd())
+		}
+		if p.is("yield") {
+			y := p.parsePyYield()
+			p.expect(")", "al cerrar la expresión")
+			return y
+		}
+		first := p.parsePyStarOrTest()
+		var node ParseNode
+		switch {
+		case p.is("for", "async"):
+			node = p.parsePyComprehension("Generator", first, tk.Start)
+		case p.is(","):
+			node = newNode("Tuple", "()", tk.Start, first.End, first)
+			for p.accept(",") {
+				if p.is(")") {
+					break
+				}
+				node.Children = append(node.Children, p.parsePyStarOrTest())
+			}
+		default:
+			node = first
+		}
+		p.expect(")", "al cerrar la expresión")
+		if node.Kind == "Tuple" || node.Kind == "Generator" {
+			node.End = p.prevEnd()
+		}
+		return node
+	case tk.Lexeme == "[":
+		p.next()
+		list := newNode("ListLiteral", "[]", tk.Start, tk.End)
+		if !p.is("]") {
+			first := p.parsePyStarOrTest()
+			if p.is("for", "async") {
+				list = p.parsePyComprehension("ListComp", first, tk.Start)
+			} else {
+				list.Children = append(list.Children, first)
+				for p.accept(",") {
+					if p.is("]") {
+						break
+					}
+					list.Children = append(list.Children, p.parsePyStarOrTest())
+				}
+			}
+		}
+		p.expect("]", "al cerrar la lista")
+		list.End = p.prevEnd()
+		return list
+	case tk.Lexeme == "{":
+		return p.parsePyDictOrSet()
+	case tk.Lexeme == "yield":
+		return p.parsePyYield()
+	}
+
+	if !p.is(")", "]", "}") {
+		p.next()
+	}
+	p.errorAt(tk.Start, fmt.Sprintf("Token inesperado '%s' en una expresión", tk.Lexeme))
+	return newNode("Error", tk.Lexeme, tk.Start, tk.End)
+}
+
+func (p *Parser) parsePyDictOrSet() ParseNode {
+	open := p.next()
+	node := newNode("DictLiteral", "{}", open.Start, open.End)
+	if p.accept("}") {
+		node.End = p.prevEnd()
+		return node
+	}
+	isDict := false
+	for !p.atEnd() && !p.is("}") {
+		if p.is("**") {
+			isDict = true
+			node.Children = append(node.Children, p.parsePyStarOrTest())
+		} else {
+			key := p.parsePyStarOrTest()
+			item := key
+			if p.accept(":") {
+				isDict = true
+				value := p.parsePyTest()
+				item = newNode("Pair", ":", key.Pos, value.End, key, value)
+			}
+			if len(node.Children) == 0 && p.is("for", "async") {
+				kind := "SetComp"
+				if isDict {
+					kind = "DictComp"
+				}
+				node = p.parsePyComprehension(kind, item, open.Start)
+				break
+			}
+			node.Children = append(node.Children, item)
+		}
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.expect("}", "al cerrar el diccionario")
+	node.End = p.prevEnd()
+	if !isDict && node.Kind == "DictLiteral" {
+		node.Kind, node.Label = "SetLiteral", "{}"
+	}
+	return node
+}
+
+func (p *Parser) parsePyComprehension(kind string, elem ParseNode, start int) ParseNode {
+	node := newNode(kind, kind, start, elem.End, elem)
+	for p.is("for", "async") {
+		kw := p.next()
+		if kw.Lexeme == "async" {
+			p.expect("for", "en la comprensión")
+		}
+		target := p.parsePyTargetList()
+		p.expect("in", "en la comprensión")
+		iter := p.parseBinary(1)
+		clause := newNode("CompFor", "for", kw.Start, iter.End, target, iter)
+		for p.is("if") {
+			p.next()
+			cond := p.parseBinary(1)
+			clause.Children = append(clause.Children, newNode("Condition", "if", cond.Pos, cond.End, cond))
+			clause.End = cond.End
+		}
+		node.Children = append(node.Children, clause)
+		node.End = clause.End
+	}
+	return node
+}
+
+// parsePyArgument analiza un argumento de llamada: *a, **k, nombre=valor o expresión
+func (p *Parser) parsePyArgument() ParseNode {
+	if p.is("*", "**") {
+		return p.parsePyStarOrTest()
+	}
+	if isName(p.cur()) && p.peek(1).Lexeme == "=" {
+		name := p.next()
+		p.next()
+		value := p.parsePyTest()
+		return newNode("KeywordArg", name.Lexeme, name.Start, value.End, value)
+	}
+	arg := p.parsePyTest()
+	if p.is("for", "async") {
+		return p.parsePyComprehension("Generator", arg, arg.Pos)
+	}
+	return arg
+}
+
+// parsePySubscript analiza índices y rebanadas: a[i], a[1:2], a[::2], a[i, j]
+func (p *Parser) parsePySubscript() ParseNode {
+	first := p.parsePySliceItem()
+	if !p.is(",") {
+		return first
+	}
+	tuple := newNode("Tuple", "()", first.Pos, first.End, first)
+	for p.accept(",") {
+		if p.is("]") {
+			break
+		}
+		item := p.parsePySliceItem()
+		tuple.Children = append(tuple.Children, item)
+		tuple.End = item.End
+	}
+	return tuple
+}
+
+func (p *Parser) parsePySliceItem() ParseNode {
+	start := p.cur().Start
+	var parts []ParseNode
+	if !p.is(":") {
+		parts = append(parts, p.parsePyTest())
+		if !p.is(":") {
+			return parts[0]
+		}
+	}
+	for p.accept(":") {
+		if !p.is(":", "]", ",") {
+			parts = append(parts, p.parsePyTest())
+		}
+	}
+	return newNode("Slice", ":", start, p.prevEnd(), parts...)
+}
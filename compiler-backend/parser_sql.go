@@ -0,0 +1,1437 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ─────────────────────────────── SQL ─────────────────────────────────────
+//
+// T-SQL (SQL Server) y PL/SQL (Oracle) comparten la gramática de las
+// consultas. SELECT, INSERT, UPDATE, DELETE y CREATE TABLE se analizan por
+// cláusulas (proyección, FROM, JOIN, WHERE, GROUP BY, ORDER BY, columnas)
+// para que el análisis semántico pueda validar tablas y columnas. Los
+// bloques procedurales (DECLARE, BEGIN...END, IF, WHILE, procedimientos)
+// también tienen su nodo; el resto de las sentencias (GRANT, DROP, SET
+// NOCOUNT ON...) se conserva como un nodo Statement con su texto.
+//
+// En T-SQL el ';' es opcional y GO separa lotes; en PL/SQL el ';' es
+// obligatorio, la asignación es ':=' y '/' en su propia línea separa bloques.
+//
+//	Select → Projection{expr | Alias{expr}}, From{Table | Subquery | Join{Table, On}},
+//	         Where, GroupBy, Having, OrderBy{OrderItem}
+//	CreateTable → ColumnDef{Type, Constraint...}, TableConstraint
+
+// Palabras que no pueden ser nombres de tablas, columnas ni alias
+var sqlReserved = makeSet(strings.Fields(`
+	ALL AND AS ASC BEGIN BETWEEN BY CASE CHECK COMMIT CONSTRAINT CREATE CROSS DECLARE
+	DEFAULT DELETE DESC DISTINCT DROP ELSE ELSIF END EXCEPT EXCEPTION EXEC EXECUTE EXISTS
+	FETCH FOR FOREIGN FROM FULL GO GROUP HAVING IF IN INNER INSERT INTERSECT INTO IS JOIN
+	KEY LEFT LIKE LOOP MINUS NOT NULL OFFSET ON OR ORDER OUTER PRIMARY PRINT REFERENCES
+	RETURN RIGHT ROLLBACK SELECT SET THEN TOP UNION UNIQUE UPDATE VALUES WHEN WHERE WHILE WITH
+`))
+
+// Palabras que comienzan una sentencia; en T-SQL, donde el ';' es opcional,
+// marcan el final de la anterior
+var sqlStatementStarters = makeSet(strings.Fields(`
+	ALTER BEGIN CLOSE COMMIT CREATE DEALLOCATE DECLARE DELETE DROP ELSE ELSIF END EXCEPTION
+	EXEC EXECUTE EXIT FETCH FOR GO GRANT IF INSERT LOOP MERGE NULL OPEN PRINT RAISE RAISERROR
+	RETURN REVOKE ROLLBACK SELECT SET THROW TRUNCATE UPDATE USE WHEN WHILE WITH
+`))
+
+// Comienzos de una restricción de columna en CREATE TABLE
+var sqlColumnConstraints = makeSet(strings.Fields(`
+	CONSTRAINT NOT NULL PRIMARY UNIQUE IDENTITY DEFAULT CHECK REFERENCES COLLATE AUTO_INCREMENT GENERATED
+`))
+
+// Comienzos de una restricción de tabla en CREATE TABLE
+var sqlTableConstraints = makeSet(strings.Fields(`CONSTRAINT PRIMARY FOREIGN UNIQUE CHECK INDEX`))
+
+// Operadores binarios y su precedencia; NOT, IN, BETWEEN e IS se tratan en
+// parseSQLExpr
+var sqlBinaryOps = map[string]int{
+	"OR": 1, "AND": 2,
+	"=": 4, "<>": 4, "!=": 4, "<": 4, ">": 4, "<=": 4, ">=": 4, "LIKE": 4, "NOT LIKE": 4,
+	"IN": 4, "NOT IN": 4, "BETWEEN": 4, "NOT BETWEEN": 4, "IS": 4,
+	"+": 5, "-": 5, "||": 5, "&": 5, "|": 5, "^": 5,
+	"*": 6, "/": 6, "%": 6,
+}
+
+func (p *Parser) isPLSQL() bool { return p.language == "plsql" }
+
+func (p *Parser) parseSQLProgram() ParseNode {
+	root := newNode("Program", p.language, 0, len(p.src))
+	root.Children = p.parseSQLStatements(func() bool { return false })
+	return root
+}
+
+// ───────────────────────────── Auxiliares ────────────────────────────────
+
+// sqlIs indica si el token actual es alguna de las palabras dadas, sin
+// distinguir mayúsculas; acepta identificadores porque muchas palabras de
+// SQL (TRAN, ROWS, OUTPUT...) no son reservadas
+func (p *Parser) sqlIs(words ...string) bool {
+	return !p.atEnd() && sqlWordIs(p.cur(), words...)
+}
+
+func sqlWordIs(tk Token, words ...string) bool {
+	if tk.Type != KEYWORD && tk.Type != IDENTIFIER {
+		return false
+	}
+	for _, w := range words {
+		if strings.EqualFold(tk.Lexeme, w) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Parser) sqlAccept(word string) bool {
+	if p.sqlIs(word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *Parser) sqlExpect(word, context string) bool {
+	if p.sqlAccept(word) {
+		return true
+	}
+	p.errorWithCode(p.prevEnd(), CodeUnexpectedToken, "insert:"+word,
+		fmt.Sprintf("Se esperaba '%s' %s, se encontró %s", word, context, p.foundText()))
+	return false
+}
+
+// sqlNameToken indica si el token puede nombrar una tabla, columna o alias
+func sqlNameToken(tk Token) bool {
+	return tk.Type == IDENTIFIER || tk.Type == KEYWORD && !sqlReserved[strings.ToUpper(tk.Lexeme)]
+}
+
+// sqlLineStart indica si el token actual es el primero de su línea
+func (p *Parser) sqlLineStart() bool {
+	return p.pos > 0 && !p.atEnd() && p.lineOf(p.cur().Start) > p.lineOf(p.prevEnd()-1)
+}
+
+func (p *Parser) sqlStatementStart() bool {
+	return !p.atEnd() && sqlStatementStarters[strings.ToUpper(p.cur().Lexeme)] && p.cur().Type == KEYWORD
+}
+
+// parseSQLName analiza un nombre calificado: tabla, dbo.tabla, [mi tabla]
+func (p *Parser) parseSQLName(context string) (Token, bool) {
+	tk := p.cur()
+	if p.atEnd() || !sqlNameToken(tk) {
+		p.errorAt(tk.Start, fmt.Sprintf("Se esperaba el nombre %s, se encontró %s", context, p.foundText()))
+		return tk, false
+	}
+	p.next()
+	for p.is(".") && sqlNameToken(p.peek(1)) {
+		p.next()
+		p.next()
+	}
+	return Token{Type: IDENTIFIER, Lexeme: p.sourceText(tk.Start, p.prevEnd()), Start: tk.Start, End: p.prevEnd()}, true
+}
+
+// parseSQLAlias analiza el alias opcional de una tabla o columna: [AS] nombre
+func (p *Parser) parseSQLAlias() (ParseNode, bool) {
+	start := p.cur().Start
+	explicit := p.sqlAccept("AS")
+	tk := p.cur()
+	if !p.atEnd() && (tk.Type == IDENTIFIER || explicit && (sqlNameToken(tk) || tk.Type == STRING)) {
+		p.next()
+		return newNode("Alias", strings.Trim(tk.Lexeme, `'"[]`), start, tk.End), true
+	}
+	if explicit {
+		p.errorAt(tk.Start, fmt.Sprintf("Se esperaba un alias después de AS, se encontró %s", p.foundText()))
+	}
+	return ParseNode{}, false
+}
+
+// sqlSynchronize descarta el resto de una sentencia con errores hasta su
+// ';' o hasta la próxima sentencia que empieza en otra línea
+func (p *Parser) sqlSynchronize() {
+	if p.pos > 0 && p.pos <= len(p.toks) && p.toks[p.pos-1].Lexeme == ";" {
+		return
+	}
+	for !p.atEnd() {
+		switch {
+		case p.accept(";"):
+			return
+		case p.is("(") && !p.sqlStatementStart():
+			p.skipBalanced()
+			continue
+		case p.sqlIs("END", "GO") || p.sqlStatementStart() && p.sqlLineStart():
+			return
+		}
+		p.next()
+	}
+}
+
+// ───────────────────────────── Sentencias ────────────────────────────────
+
+// parseSQLStatements analiza sentencias hasta que stop() sea verdadero
+func (p *Parser) parseSQLStatements(stop func() bool) []ParseNode {
+	var stmts []ParseNode
+	for !p.atEnd() && !stop() {
+		start := p.pos
+		switch {
+		case p.accept(";"):
+		case p.sqlIs("GO") || p.isPLSQL() && p.is("/") && (p.pos == 0 || p.sqlLineStart()):
+			// Separador de lotes: GO en T-SQL, '/' en SQL*Plus
+			tk := p.next()
+			stmts = append(stmts, newNode("BatchSeparator", strings.ToUpper(tk.Lexeme), tk.Start, tk.End))
+		default:
+			stmts = append(stmts, p.parseSQLStatement())
+			if !p.stmtErr {
+				p.endSQLStatement()
+			}
+		}
+		if p.stmtErr {
+			p.sqlSynchronize()
+			p.stmtErr = false
+		}
+		if p.pos == start {
+			p.pos++
+		}
+	}
+	return stmts
+}
+
+// endSQLStatement consume el ';' final. En PL/SQL es obligatorio; en T-SQL
+// es opcional, pero lo que sigue tiene que comenzar otra sentencia.
+func (p *Parser) endSQLStatement() {
+	if p.accept(";") || p.atEnd() {
+		return
+	}
+	if p.isPLSQL() {
+		p.expect(";", "al final de la sentencia")
+		return
+	}
+	if !p.sqlStatementStart() && !p.is(")") {
+		p.errorAt(p.cur().Start, fmt.Sprintf("Se esperaba el fin de la sentencia, se encontró %s", p.foundText()))
+	}
+}
+
+func (p *Parser) parseSQLStatement() ParseNode {
+	switch {
+	case p.sqlIs("SELECT", "WITH") || p.is("(") && sqlWordIs(p.peek(1), "SELECT"):
+		return p.parseSQLQuery()
+	case p.sqlIs("INSERT"):
+		return p.parseSQLInsert()
+	case p.sqlIs("UPDATE"):
+		return p.parseSQLUpdate()
+	case p.sqlIs("DELETE"):
+		return p.parseSQLDelete()
+	case p.sqlIs("CREATE"):
+		return p.parseSQLCreate()
+	case p.sqlIs("DECLARE"):
+		return p.parseSQLDeclare()
+	case p.sqlIs("BEGIN") && !sqlWordIs(p.peek(1), "TRAN", "TRANSACTION", "DISTRIBUTED"):
+		return p.parseSQLBlock()
+	case p.sqlIs("IF"):
+		return p.parseSQLIf()
+	case p.sqlIs("WHILE"):
+		return p.parseSQLWhile()
+	case p.isPLSQL() && p.sqlIs("LOOP", "FOR"):
+		return p.parseSQLLoop()
+	case p.sqlIs("PRINT", "RETURN"):
+		kw := p.next()
+		kind := "Print"
+		if strings.EqualFold(kw.Lexeme, "RETURN") {
+			kind = "Return"
+		}
+		node := newNode(kind, strings.ToUpper(kw.Lexeme), kw.Start, kw.End)
+		if !p.atEnd() && !p.is(";") && !p.sqlIs("END", "ELSE", "GO") && !(p.sqlStatementStart() && p.sqlLineStart()) {
+			node.Children = append(node.Children, p.parseSQLExpr(0))
+		}
+		node.End = p.prevEnd()
+		return node
+	case p.sqlIs("SET") && p.peek(1).Type == VARIABLE:
+		// SET @x = expresión
+		kw := p.next()
+		target := p.next()
+		node := newNode("Assign", target.Lexeme, kw.Start, target.End)
+		if !p.is("=", "+=", "-=", "*=", "/=") {
+			p.errorAt(p.cur().Start, fmt.Sprintf("Se esperaba '=' después de '%s', se encontró %s", target.Lexeme, p.foundText()))
+			return node
+		}
+		p.next()
+		node.Children = append(node.Children, p.parseSQLExpr(0))
+		node.End = p.prevEnd()
+		return node
+	case p.isPLSQL() && (p.cur().Type == IDENTIFIER || p.cur().Type == VARIABLE):
+		return p.parsePLSQLNameStatement()
+	}
+	return p.parseSQLGeneric()
+}
+
+// parseSQLGeneric conserva como texto una sentencia sin gramática propia
+// (GRANT, DROP, ALTER, EXEC, SET NOCOUNT ON, COMMIT...)
+func (p *Parser) parseSQLGeneric() ParseNode {
+	first := p.next()
+	node := newNode("Statement", strings.ToUpper(first.Lexeme), first.Start, first.End)
+	if first.Type != KEYWORD && first.Type != IDENTIFIER {
+		p.errorAt(first.Start, fmt.Sprintf("Se esperaba el comienzo de una sentencia, se encontró '%s'", first.Lexeme))
+		return node
+	}
+	for !p.atEnd() && !p.is(";") && !p.sqlIs("END", "GO") && !(p.sqlStatementStart() && p.sqlLineStart()) {
+		if p.is("(") {
+			p.skipBalanced()
+			continue
+		}
+		p.next()
+	}
+	node.End = p.prevEnd()
+	node.Label = p.sourceText(first.Start, node.End)
+	return node
+}
+
+// parsePLSQLNameStatement analiza las sentencias de PL/SQL que empiezan con
+// un nombre: asignación (x := 1) o llamada (dbms_output.put_line('hola'))
+func (p *Parser) parsePLSQLNameStatement() ParseNode {
+	name, _ := p.parseSQLName("de la variable o procedimiento")
+	if p.accept(":=") {
+		node := newNode("Assign", name.Lexeme, name.Start, name.End)
+		node.Children = append(node.Children, p.parseSQLExpr(0))
+		node.End = p.prevEnd()
+		return node
+	}
+	call := newNode("Call", name.Lexeme, name.Start, name.End)
+	if p.is("(") {
+		call.Children = p.parseSQLArguments()
+	}
+	call.End = p.prevEnd()
+	return call
+}
+
+// ─────────────────────────────── Consultas ───────────────────────────────
+
+// parseSQLQuery analiza un SELECT con sus UNION/INTERSECT/EXCEPT y el ORDER
+// BY final, que ordena el resultado completo
+func (p *Parser) parseSQLQuery() ParseNode {
+	if p.sqlIs("WITH") {
+		return p.parseSQLWith()
+	}
+	query := p.parseSQLSelectCore()
+	for p.sqlIs("UNION", "INTERSECT", "EXCEPT", "MINUS") {
+		op := p.next()
+		label := strings.ToUpper(op.Lexeme)
+		if p.sqlAccept("ALL") {
+			label += " ALL"
+		}
+		right := p.parseSQLSelectCore()
+		query = newNode("SetOperation", label, query.Pos, right.End, query, right)
+	}
+	if p.sqlIs("ORDER") {
+		query.Children = append(query.Children, p.parseSQLOrderBy())
+	}
+	if p.sqlIs("OFFSET") {
+		kw := p.next()
+		offset := newNode("Offset", "OFFSET", kw.Start, kw.End, p.parseSQLExpr(0))
+		if !p.sqlAccept("ROWS") {
+			p.sqlAccept("ROW")
+		}
+		offset.End = p.prevEnd()
+		query.Children = append(query.Children, offset)
+	}
+	if p.sqlIs("FETCH") {
+		kw := p.next()
+		if !p.sqlAccept("FIRST") {
+			p.sqlExpect("NEXT", "después de FETCH")
+		}
+		fetch := newNode("Fetch", "FETCH", kw.Start, kw.End, p.parseSQLExpr(0))
+		if !p.sqlAccept("ROWS") {
+			p.sqlAccept("ROW")
+		}
+		p.sqlExpect("ONLY", "al final de FETCH")
+		fetch.End = p.prevEnd()
+		query.Children = append(query.Children, fetch)
+	}
+	query.End = p.prevEnd()
+	return query
+}
+
+// parseSQLWith analiza las expresiones de tabla comunes: WITH t AS (SELECT ...)
+func (p *Parser) parseSQLWith() ParseNode {
+	kw := p.next()
+	with := newNode("With", "WITH", kw.Start, kw.End)
+	for {
+		name, ok := p.parseSQLName("de la expresión de tabla")
+		if !ok {
+			return with
+		}
+		cte := newNode("CommonTable", name.Lexeme, name.Start, name.End)
+		if p.is("(") {
+			cte.Children = append(cte.Children, p.parseSQLColumnList())
+		}
+		p.sqlExpect("AS", "después del nombre de la expresión de tabla")
+		cte.Children = append(cte.Children, p.parseSQLSubquery())
+		cte.End = p.prevEnd()
+		with.Children = append(with.Children, cte)
+		if !p.accept(",") {
+			break
+		}
+	}
+	query := p.parseSQLQuery()
+	with.Children = append(with.Children, query)
+	with.End = query.End
+	return with
+}
+
+func (p *Parser) parseSQLSelectCore() ParseNode {
+	if p.is("(") {
+		return p.parseSQLSubquery()
+	}
+	start := p.cur().Start
+	sel := newNode("Select", "SELECT", start, start)
+	if !p.sqlExpect("SELECT", "al comienzo de la consulta") {
+		return sel
+	}
+	if p.sqlAccept("DISTINCT") {
+		sel.Children = append(sel.Children, newNode("Distinct", "DISTINCT", p.toks[p.pos-1].Start, p.prevEnd()))
+	} else {
+		p.sqlAccept("ALL")
+	}
+	if p.sqlIs("TOP") {
+		// T-SQL: TOP 10, TOP (10), TOP 10 PERCENT
+		kw := p.next()
+		top := newNode("Top", "TOP", kw.Start, kw.End, p.parseSQLPrimary())
+		p.sqlAccept("PERCENT")
+		top.End = p.prevEnd()
+		sel.Children = append(sel.Children, top)
+	}
+
+	proj := newNode("Projection", "", p.cur().Start, p.cur().Start)
+	for {
+		proj.Children = append(proj.Children, p.parseSQLSelectItem())
+		if p.stmtErr || !p.accept(",") {
+			break
+		}
+	}
+	proj.End = p.prevEnd()
+	sel.Children = append(sel.Children, proj)
+
+	if p.sqlIs("INTO") {
+		// T-SQL: SELECT ... INTO nueva_tabla; PL/SQL: SELECT ... INTO variables
+		kw := p.next()
+		into := newNode("Into", "INTO", kw.Start, kw.End)
+		for {
+			if tk := p.cur(); tk.Type == VARIABLE {
+				p.next()
+				into.Children = append(into.Children, newNode("Variable", tk.Lexeme, tk.Start, tk.End))
+			} else if name, ok := p.parseSQLName("después de INTO"); ok {
+				into.Children = append(into.Children, newNode("Table", name.Lexeme, name.Start, name.End))
+			}
+			if p.stmtErr || !p.accept(",") {
+				break
+			}
+		}
+		into.End = p.prevEnd()
+		sel.Children = append(sel.Children, into)
+	}
+	if p.sqlIs("FROM") {
+		sel.Children = append(sel.Children, p.parseSQLFrom())
+	}
+	if p.sqlIs("WHERE") {
+		sel.Children = append(sel.Children, p.parseSQLClause("Where"))
+	}
+	if p.sqlIs("GROUP") {
+		kw := p.next()
+		p.sqlExpect("BY", "después de GROUP")
+		group := newNode("GroupBy", "GROUP BY", kw.Start, kw.End)
+		for {
+			group.Children = append(group.Children, p.parseSQLExpr(0))
+			if p.stmtErr || !p.accept(",") {
+				break
+			}
+		}
+		group.End = p.prevEnd()
+		sel.Children = append(sel.Children, group)
+	}
+	if p.sqlIs("HAVING") {
+		sel.Children = append(sel.Children, p.parseSQLClause("Having"))
+	}
+	sel.End = p.prevEnd()
+	return sel
+}
+
+// parseSQLClause analiza una cláusula formada por una palabra y una
+// condición: WHERE, HAVING, ON
+func (p *Parser) parseSQLClause(kind string) ParseNode {
+	kw := p.next()
+	cond := p.parseSQLExpr(0)
+	return newNode(kind, strings.ToUpper(kw.Lexeme), kw.Start, p.prevEnd(), cond)
+}
+
+// parseSQLSelectItem analiza un elemento de la proyección: *, t.*, expresión
+// o expresión con alias
+func (p *Parser) parseSQLSelectItem() ParseNode {
+	if tk := p.cur(); tk.Type == VARIABLE && p.peek(1).Lexeme == "=" {
+		// T-SQL: SELECT @total = COUNT(*) FROM ...
+		p.next()
+		p.next()
+		return newNode("Assign", tk.Lexeme, tk.Start, p.prevEnd(), p.parseSQLExpr(0))
+	}
+	expr := p.parseSQLExpr(0)
+	if expr.Kind == "Star" {
+		return expr
+	}
+	if alias, ok := p.parseSQLAlias(); ok {
+		return newNode("Alias", alias.Label, expr.Pos, alias.End, expr)
+	}
+	return expr
+}
+
+func (p *Parser) parseSQLOrderBy() ParseNode {
+	kw := p.next()
+	p.sqlExpect("BY", "después de ORDER")
+	order := newNode("OrderBy", "ORDER BY", kw.Start, kw.End)
+	for {
+		expr := p.parseSQLExpr(0)
+		item := newNode("OrderItem", "ASC", expr.Pos, expr.End, expr)
+		if p.sqlIs("ASC", "DESC") {
+			item.Label = strings.ToUpper(p.next().Lexeme)
+		}
+		if p.sqlAccept("NULLS") && (p.sqlAccept("FIRST") || p.sqlExpect("LAST", "después de NULLS")) {
+			item.Label += " NULLS " + strings.ToUpper(p.toks[p.pos-1].Lexeme)
+		}
+		item.End = p.prevEnd()
+		order.Children = append(order.Children, item)
+		if p.stmtErr || !p.accept(",") {
+			break
+		}
+	}
+	order.End = p.prevEnd()
+	return order
+}
+
+// parseSQLFrom analiza FROM con sus tablas separadas por comas y sus JOIN
+func (p *Parser) parseSQLFrom() ParseNode {
+	kw := p.next()
+	from := newNode("From", "FROM", kw.Start, kw.End)
+	from.Children = append(from.Children, p.parseSQLTableRef())
+	for !p.stmtErr {
+		switch {
+		case p.accept(","):
+			from.Children = append(from.Children, p.parseSQLTableRef())
+			continue
+		case p.sqlIs("JOIN", "INNER", "LEFT", "RIGHT", "FULL", "CROSS", "NATURAL") || p.sqlIs("OUTER") && sqlWordIs(p.peek(1), "APPLY"):
+			from.Children = append(from.Children, p.parseSQLJoin())
+			continue
+		}
+		break
+	}
+	from.End = p.prevEnd()
+	return from
+}
+
+func (p *Parser) parseSQLJoin() ParseNode {
+	start := p.cur().Start
+	var words []string
+	for p.sqlIs("INNER", "LEFT", "RIGHT", "FULL", "OUTER", "CROSS", "NATURAL") {
+		words = append(words, strings.ToUpper(p.next().Lexeme))
+	}
+	switch {
+	case p.sqlIs("JOIN", "APPLY"):
+		words = append(words, strings.ToUpper(p.next().Lexeme))
+	default:
+		p.sqlExpect("JOIN", "en la combinación de tablas")
+	}
+	join := newNode("Join", strings.Join(words, " "), start, p.prevEnd(), p.parseSQLTableRef())
+	switch {
+	case p.sqlIs("ON"):
+		join.Children = append(join.Children, p.parseSQLClause("On"))
+	case p.sqlIs("USING"):
+		kw := p.next()
+		using := p.parseSQLColumnList()
+		using.Kind, using.Label, using.Pos = "Using", "USING", kw.Start
+		join.Children = append(join.Children, using)
+	case !strings.Contains(join.Label, "CROSS") && !strings.Contains(join.Label, "NATURAL") && !strings.Contains(join.Label, "APPLY"):
+		p.errorWithCode(p.prevEnd(), CodeUnexpectedToken, "insert:ON",
+			fmt.Sprintf("Se esperaba ON con la condición de %s, se encontró %s", join.Label, p.foundText()))
+	}
+	join.End = p.prevEnd()
+	return join
+}
+
+// parseSQLTableRef analiza una tabla (o subconsulta) con su alias opcional
+func (p *Parser) parseSQLTableRef() ParseNode {
+	var ref ParseNode
+	if tk := p.cur(); p.is("(") {
+		ref = p.parseSQLSubquery()
+	} else if tk.Type == VARIABLE {
+		// Variable de tabla de T-SQL: FROM @pedidos
+		p.next()
+		ref = newNode("Variable", tk.Lexeme, tk.Start, tk.End)
+	} else {
+		name, ok := p.parseSQLName("de la tabla")
+		if !ok {
+			return newNode("Table", "", name.Start, name.Start)
+		}
+		ref = newNode("Table", name.Lexeme, name.Start, name.End)
+		if p.is("(") {
+			// Función con valores de tabla: dbo.fn(1)
+			ref.Kind = "Call"
+			ref.Children = p.parseSQLArguments()
+		}
+	}
+	if alias, ok := p.parseSQLAlias(); ok {
+		ref.Children = append(ref.Children, alias)
+	}
+	if p.sqlIs("WITH") && p.peek(1).Lexeme == "(" {
+		// Sugerencias de tabla de T-SQL: WITH (NOLOCK)
+		p.next()
+		p.skipBalanced()
+	}
+	ref.End = p.prevEnd()
+	return ref
+}
+
+// parseSQLSubquery analiza '(' SELECT ... ')'
+func (p *Parser) parseSQLSubquery() ParseNode {
+	open := p.cur()
+	p.expect("(", "antes de la subconsulta")
+	query := p.parseSQLQuery()
+	p.expect(")", "para cerrar la subconsulta")
+	return newNode("Subquery", "", open.Start, p.prevEnd(), query)
+}
+
+// parseSQLColumnList analiza '(' columna, columna... ')'
+func (p *Parser) parseSQLColumnList() ParseNode {
+	open := p.cur()
+	cols := newNode("Columns", "", open.Start, open.End)
+	if !p.expect("(", "antes de la lista de columnas") {
+		return cols
+	}
+	for !p.is(")") {
+		name, ok := p.parseSQLName("de la columna")
+		if !ok {
+			return cols
+		}
+		cols.Children = append(cols.Children, newNode("Column", name.Lexeme, name.Start, name.End))
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.expect(")", "para cerrar la lista de columnas")
+	cols.End = p.prevEnd()
+	return cols
+}
+
+// ───────────────────────── Modificación de datos ─────────────────────────
+
+func (p *Parser) parseSQLInsert() ParseNode {
+	kw := p.next()
+	insert := newNode("Insert", "", kw.Start, kw.End)
+	p.sqlAccept("INTO")
+	if tk := p.cur(); tk.Type == VARIABLE {
+		p.next()
+		insert.Label = tk.Lexeme
+		insert.Children = append(insert.Children, newNode("Variable", tk.Lexeme, tk.Start, tk.End))
+	} else if name, ok := p.parseSQLName("de la tabla"); ok {
+		insert.Label = name.Lexeme
+		insert.Children = append(insert.Children, newNode("Table", name.Lexeme, name.Start, name.End))
+	} else {
+		return insert
+	}
+	if p.is("(") {
+		insert.Children = append(insert.Children, p.parseSQLColumnList())
+	}
+	switch {
+	case p.sqlIs("VALUES"):
+		vkw := p.next()
+		values := newNode("Values", "VALUES", vkw.Start, vkw.End)
+		for {
+			open := p.cur()
+			if !p.is("(") {
+				p.expect("(", "antes de la fila de valores")
+				return insert
+			}
+			row := newNode("Row", "", open.Start, open.End)
+			row.Children = p.parseSQLArguments()
+			row.End = p.prevEnd()
+			values.Children = append(values.Children, row)
+			if p.stmtErr || !p.accept(",") {
+				break
+			}
+		}
+		values.End = p.prevEnd()
+		insert.Children = append(insert.Children, values)
+	case p.sqlIs("SELECT", "WITH") || p.is("("):
+		insert.Children = append(insert.Children, p.parseSQLQuery())
+	case p.sqlIs("DEFAULT"):
+		p.next()
+		p.sqlExpect("VALUES", "después de DEFAULT")
+	case p.sqlIs("EXEC", "EXECUTE"):
+		insert.Children = append(insert.Children, p.parseSQLGeneric())
+	default:
+		p.errorAt(p.cur().Start, fmt.Sprintf("Se esperaba VALUES o SELECT en el INSERT, se encontró %s", p.foundText()))
+	}
+	insert.End = p.prevEnd()
+	return insert
+}
+
+func (p *Parser) parseSQLUpdate() ParseNode {
+	kw := p.next()
+	update := newNode("Update", "", kw.Start, kw.End)
+	table := p.parseSQLTableRef()
+	update.Label = table.Label
+	update.Children = append(update.Children, table)
+	if !p.sqlIs("SET") {
+		p.sqlExpect("SET", "después de la tabla del UPDATE")
+		return update
+	}
+	skw := p.next()
+	set := newNode("Set", "SET", skw.Start, skw.End)
+	for {
+		var target Token
+		if tk := p.cur(); tk.Type == VARIABLE {
+			target = p.next()
+		} else if name, ok := p.parseSQLName("de la columna"); ok {
+			target = name
+		} else {
+			return update
+		}
+		assign := newNode("Assign", target.Lexeme, target.Start, target.End)
+		if !p.is("=", "+=", "-=", "*=", "/=") {
+			p.errorWithCode(p.prevEnd(), CodeUnexpectedToken, "insert:=",
+				fmt.Sprintf("Se esperaba '=' después de '%s', se encontró %s", target.Lexeme, p.foundText()))
+			return update
+		}
+		p.next()
+		assign.Children = append(assign.Children, p.parseSQLExpr(0))
+		assign.End = p.prevEnd()
+		set.Children = append(set.Children, assign)
+		if p.stmtErr || !p.accept(",") {
+			break
+		}
+	}
+	set.End = p.prevEnd()
+	update.Children = append(update.Children, set)
+	if p.sqlIs("FROM") {
+		update.Children = append(update.Children, p.parseSQLFrom())
+	}
+	if p.sqlIs("WHERE") {
+		update.Children = append(update.Children, p.parseSQLClause("Where"))
+	}
+	update.End = p.prevEnd()
+	return update
+}
+
+func (p *Parser) parseSQLDelete() ParseNode {
+	kw := p.next()
+	del := newNode("Delete", "", kw.Start, kw.End)
+	p.sqlAccept("FROM")
+	table := p.parseSQLTableRef()
+	del.Label = table.Label
+	del.Children = append(del.Children, table)
+	if p.sqlIs("FROM") {
+		del.Children = append(del.Children, p.parseSQLFrom())
+	}
+	if p.sqlIs("WHERE") {
+		del.Children = append(del.Children, p.parseSQLClause("Where"))
+	}
+	del.End = p.prevEnd()
+	return del
+}
+
+// ───────────────────────────── Definiciones ──────────────────────────────
+
+func (p *Parser) parseSQLCreate() ParseNode {
+	start := p.pos
+	kw := p.next()
+	if p.sqlAccept("OR") {
+		p.sqlExpect("REPLACE", "después de CREATE OR")
+	}
+	switch {
+	case p.sqlIs("TABLE"):
+		return p.parseSQLCreateTable(kw)
+	case p.sqlIs("VIEW"):
+		p.next()
+		name, ok := p.parseSQLName("de la vista")
+		view := newNode("CreateView", name.Lexeme, kw.Start, name.End)
+		if !ok {
+			return view
+		}
+		if p.is("(") {
+			view.Children = append(view.Children, p.parseSQLColumnList())
+		}
+		p.sqlExpect("AS", "antes de la consulta de la vista")
+		view.Children = append(view.Children, p.parseSQLQuery())
+		view.End = p.prevEnd()
+		return view
+	case p.sqlIs("PROCEDURE", "PROC", "FUNCTION"):
+		return p.parseSQLRoutine(kw)
+	}
+	// CREATE INDEX, SEQUENCE, TRIGGER...
+	p.pos = start
+	return p.parseSQLGeneric()
+}
+
+func (p *Parser) parseSQLCreateTable(kw Token) ParseNode {
+	p.next()
+	name, ok := p.parseSQLName("de la tabla")
+	table := newNode("CreateTable", name.Lexeme, kw.Start, name.End)
+	if !ok || !p.expect("(", "antes de las columnas de la tabla") {
+		return table
+	}
+	for !p.atEnd() && !p.is(")") {
+		if p.cur().Type == KEYWORD && sqlTableConstraints[strings.ToUpper(p.cur().Lexeme)] {
+			table.Children = append(table.Children, p.parseSQLTableConstraint())
+		} else {
+			table.Children = append(table.Children, p.parseSQLColumnDef())
+		}
+		if p.stmtErr || !p.accept(",") {
+			break
+		}
+	}
+	if !p.stmtErr && !p.is(")") {
+		p.errorWithCode(p.prevEnd(), CodeUnexpectedToken, "insert:,",
+			fmt.Sprintf("Se esperaba ',' o ')' en la definición de la tabla '%s', se encontró %s", name.Lexeme, p.foundText()))
+		return table
+	}
+	p.expect(")", "para cerrar la definición de la tabla")
+	table.End = p.prevEnd()
+	return table
+}
+
+// parseSQLColumnDef analiza 'nombre tipo [restricciones]'
+func (p *Parser) parseSQLColumnDef() ParseNode {
+	name, ok := p.parseSQLName("de la columna")
+	col := newNode("ColumnDef", name.Lexeme, name.Start, name.End)
+	if !ok {
+		return col
+	}
+	if p.is(",", ")") {
+		p.errorAt(p.prevEnd(), fmt.Sprintf("Falta el tipo de la columna '%s'", name.Lexeme))
+		return col
+	}
+	col.Children = append(col.Children, p.parseSQLType())
+	for !p.atEnd() && !p.is(",", ")") && !p.stmtErr {
+		if !sqlColumnConstraints[strings.ToUpper(p.cur().Lexeme)] {
+			p.errorAt(p.cur().Start, fmt.Sprintf("Restricción desconocida %s en la columna '%s'", p.foundText(), name.Lexeme))
+			break
+		}
+		col.Children = append(col.Children, p.parseSQLConstraint())
+	}
+	col.End = p.prevEnd()
+	return col
+}
+
+// parseSQLConstraint analiza una restricción de columna: NOT NULL, PRIMARY
+// KEY, DEFAULT 0, IDENTITY(1,1), REFERENCES t(id) ON DELETE CASCADE...
+func (p *Parser) parseSQLConstraint() ParseNode {
+	first := p.next()
+	switch strings.ToUpper(first.Lexeme) {
+	case "CONSTRAINT", "COLLATE":
+		p.parseSQLName("de la restricción")
+	case "NOT":
+		p.sqlExpect("NULL", "después de NOT")
+	case "PRIMARY":
+		if p.sqlExpect("KEY", "después de PRIMARY") && p.sqlIs("CLUSTERED", "NONCLUSTERED") {
+			p.next()
+		}
+	case "DEFAULT":
+		p.parseSQLExpr(5)
+	case "IDENTITY", "CHECK":
+		if p.is("(") {
+			p.skipBalanced()
+		}
+	case "REFERENCES":
+		if _, ok := p.parseSQLName("de la tabla referenciada"); ok && p.is("(") {
+			p.parseSQLColumnList()
+		}
+		for p.sqlAccept("ON") {
+			if !p.sqlAccept("DELETE") {
+				p.sqlExpect("UPDATE", "después de ON")
+			}
+			for p.sqlIs("CASCADE", "SET", "NULL", "NO", "ACTION", "DEFAULT", "RESTRICT") {
+				p.next()
+			}
+		}
+	case "GENERATED":
+		// GENERATED ALWAYS AS IDENTITY, GENERATED BY DEFAULT AS IDENTITY
+		for p.sqlIs("ALWAYS", "BY", "DEFAULT", "ON", "NULL", "AS", "IDENTITY") {
+			p.next()
+		}
+		if p.is("(") {
+			p.skipBalanced()
+		}
+	}
+	return newNode("Constraint", p.sourceText(first.Start, p.prevEnd()), first.Start, p.prevEnd())
+}
+
+func (p *Parser) parseSQLTableConstraint() ParseNode {
+	start := p.cur().Start
+	for !p.atEnd() && !p.is(",", ")") {
+		if p.is("(") {
+			p.skipBalanced()
+			continue
+		}
+		p.next()
+	}
+	return newNode("TableConstraint", p.sourceText(start, p.prevEnd()), start, p.prevEnd())
+}
+
+// parseSQLType analiza un tipo de dato: INT, VARCHAR(50), DECIMAL(10, 2),
+// NVARCHAR(MAX) y, en PL/SQL, empleados.salario%TYPE
+func (p *Parser) parseSQLType() ParseNode {
+	start := p.cur().Start
+	if _, ok := p.parseSQLName("del tipo"); !ok {
+		return newNode("Type", "", start, start)
+	}
+	if p.sqlIs("PRECISION", "VARYING") {
+		p.next()
+	}
+	if p.is("(") {
+		p.skipBalanced()
+	}
+	if p.accept("%") {
+		if !p.sqlAccept("TYPE") {
+			p.sqlExpect("ROWTYPE", "después de '%'")
+		}
+	}
+	return newNode("Type", strings.ToUpper(p.sourceText(start, p.prevEnd())), start, p.prevEnd())
+}
+
+// parseSQLRoutine analiza CREATE PROCEDURE/FUNCTION con sus parámetros y su
+// cuerpo: AS BEGIN...END en T-SQL, IS/AS declaraciones BEGIN...END en PL/SQL
+func (p *Parser) parseSQLRoutine(kw Token) ParseNode {
+	kind := "Procedure"
+	if p.sqlIs("FUNCTION") {
+		kind = "Function"
+	}
+	p.next()
+	name, ok := p.parseSQLName("del procedimiento")
+	routine := newNode(kind, name.Lexeme, kw.Start, name.End)
+	if !ok {
+		return routine
+	}
+	params := newNode("Params", "", p.cur().Start, p.cur().Start)
+	parens := p.accept("(")
+	for !p.atEnd() && (p.cur().Type == VARIABLE || parens && !p.is(")")) {
+		params.Children = append(params.Children, p.parseSQLParam())
+		if p.stmtErr || !p.accept(",") {
+			break
+		}
+	}
+	if parens {
+		p.expect(")", "para cerrar los parámetros")
+	}
+	params.End = p.prevEnd()
+	routine.Children = append(routine.Children, params)
+	if p.sqlIs("RETURNS", "RETURN") {
+		rkw := p.next()
+		ret := newNode("Returns", "RETURNS", rkw.Start, rkw.End, p.parseSQLType())
+		ret.End = p.prevEnd()
+		routine.Children = append(routine.Children, ret)
+	}
+	if !p.sqlAccept("AS") && !p.sqlAccept("IS") {
+		p.sqlExpect("AS", "antes del cuerpo del procedimiento")
+		return routine
+	}
+	if p.isPLSQL() {
+		routine.Children = append(routine.Children, p.parsePLSQLDeclarations()...)
+		routine.Children = append(routine.Children, p.parseSQLBlock())
+	} else if p.sqlIs("BEGIN") {
+		routine.Children = append(routine.Children, p.parseSQLBlock())
+	} else {
+		// Sin BEGIN el cuerpo llega hasta el fin del lote
+		body := newNode("Block", "", p.cur().Start, p.cur().Start)
+		body.Children = p.parseSQLStatements(func() bool { return p.sqlIs("GO") })
+		body.End = p.prevEnd()
+		routine.Children = append(routine.Children, body)
+	}
+	routine.End = p.prevEnd()
+	return routine
+}
+
+// parseSQLParam analiza '@p tipo [= defecto] [OUTPUT]' o, en PL/SQL,
+// 'p [IN | OUT | IN OUT] tipo [:= defecto]'
+func (p *Parser) parseSQLParam() ParseNode {
+	tk := p.cur()
+	if tk.Type != VARIABLE && !sqlNameToken(tk) {
+		p.errorAt(tk.Start, fmt.Sprintf("Se esperaba el nombre de un parámetro, se encontró %s", p.foundText()))
+		return newNode("Param", "", tk.Start, tk.Start)
+	}
+	p.next()
+	param := newNode("Param", tk.Lexeme, tk.Start, tk.End)
+	p.sqlAccept("AS")
+	for p.sqlIs("IN", "OUT", "NOCOPY") {
+		p.next()
+	}
+	param.Children = append(param.Children, p.parseSQLType())
+	if p.accept("=") || p.accept(":=") || p.sqlAccept("DEFAULT") {
+		param.Children = append(param.Children, newNode("Default", "DEFAULT", p.cur().Start, p.cur().Start, p.parseSQLExpr(0)))
+	}
+	for p.sqlIs("OUTPUT", "OUT", "READONLY") {
+		p.next()
+	}
+	param.End = p.prevEnd()
+	return param
+}
+
+// ─────────────────────────── Bloques y control ───────────────────────────
+
+// parseSQLDeclare analiza DECLARE. En T-SQL declara variables '@x tipo [=
+// valor]'; en PL/SQL abre un bloque anónimo con sus declaraciones.
+func (p *Parser) parseSQLDeclare() ParseNode {
+	kw := p.next()
+	decl := newNode("Declare", "DECLARE", kw.Start, kw.End)
+	if p.isPLSQL() {
+		decl.Children = p.parsePLSQLDeclarations()
+		decl.Children = append(decl.Children, p.parseSQLBlock())
+		decl.End = p.prevEnd()
+		return decl
+	}
+	for {
+		tk := p.cur()
+		switch {
+		case tk.Type == VARIABLE:
+			p.next()
+			v := newNode("VarDecl", tk.Lexeme, tk.Start, tk.End)
+			p.sqlAccept("AS")
+			if p.sqlIs("TABLE") {
+				// DECLARE @t TABLE (columnas)
+				tkw := p.next()
+				v.Children = append(v.Children, newNode("Type", "TABLE", tkw.Start, tkw.End))
+				p.skipBalanced()
+			} else {
+				v.Children = append(v.Children, p.parseSQLType())
+			}
+			if p.accept("=") {
+				v.Children = append(v.Children, p.parseSQLExpr(0))
+			}
+			v.End = p.prevEnd()
+			decl.Children = append(decl.Children, v)
+		case sqlNameToken(tk) && sqlWordIs(p.peek(1), "CURSOR"):
+			// DECLARE c CURSOR FOR SELECT ...
+			p.next()
+			p.next()
+			c := newNode("Cursor", tk.Lexeme, tk.Start, tk.End)
+			for !p.atEnd() && !p.sqlIs("FOR") && !p.is(";") {
+				p.next()
+			}
+			if p.sqlExpect("FOR", "en la declaración del cursor") {
+				c.Children = append(c.Children, p.parseSQLQuery())
+			}
+			c.End = p.prevEnd()
+			decl.Children = append(decl.Children, c)
+		default:
+			p.errorAt(tk.Start, fmt.Sprintf("Se esperaba una variable (@nombre) después de DECLARE, se encontró %s", p.foundText()))
+			return decl
+		}
+		if p.stmtErr || !p.accept(",") {
+			break
+		}
+	}
+	decl.End = p.prevEnd()
+	return decl
+}
+
+// parsePLSQLDeclarations analiza la sección de declaraciones de PL/SQL hasta
+// BEGIN: variables 'x tipo [:= valor];', cursores, tipos y excepciones
+func (p *Parser) parsePLSQLDeclarations() []ParseNode {
+	var decls []ParseNode
+	for !p.atEnd() && !p.sqlIs("BEGIN") {
+		start := p.pos
+		tk := p.cur()
+		switch {
+		case p.sqlIs("CURSOR", "TYPE", "SUBTYPE", "PRAGMA", "PROCEDURE", "FUNCTION"):
+			decls = append(decls, p.parseSQLGeneric())
+		case sqlNameToken(tk) && sqlWordIs(p.peek(1), "EXCEPTION"):
+			p.next()
+			p.next()
+			decls = append(decls, newNode("ExceptionDecl", tk.Lexeme, tk.Start, p.prevEnd()))
+		case sqlNameToken(tk):
+			p.next()
+			v := newNode("VarDecl", tk.Lexeme, tk.Start, tk.End)
+			constant := p.sqlAccept("CONSTANT")
+			v.Children = append(v.Children, p.parseSQLType())
+			if constant {
+				v.Children[0].Label = "CONSTANT " + v.Children[0].Label
+			}
+			if p.sqlAccept("NOT") {
+				p.sqlExpect("NULL", "después de NOT")
+			}
+			if p.accept(":=") || p.sqlAccept("DEFAULT") {
+				v.Children = append(v.Children, p.parseSQLExpr(0))
+			} else if p.is("=") {
+				p.errorWithCode(p.cur().Start, CodeUnexpectedToken, "replace::=",
+					fmt.Sprintf("En PL/SQL el valor inicial de '%s' se asigna con ':=', no con '='", tk.Lexeme))
+			}
+			v.End = p.prevEnd()
+			decls = append(decls, v)
+		default:
+			p.errorAt(tk.Start, fmt.Sprintf("Se esperaba una declaración o BEGIN, se encontró %s", p.foundText()))
+		}
+		if !p.stmtErr {
+			p.expect(";", "al final de la declaración")
+		}
+		if p.stmtErr {
+			p.sqlSynchronize()
+			p.stmtErr = false
+		}
+		if p.pos == start {
+			p.pos++
+		}
+	}
+	return decls
+}
+
+// parseSQLBlock analiza BEGIN ... END. T-SQL tiene además BEGIN TRY/CATCH;
+// PL/SQL, la sección EXCEPTION con sus manejadores WHEN ... THEN
+func (p *Parser) parseSQLBlock() ParseNode {
+	kw := p.cur()
+	block := newNode("Block", "BEGIN", kw.Start, kw.End)
+	if !p.sqlExpect("BEGIN", "al comienzo del bloque") {
+		return block
+	}
+	if p.sqlIs("TRY", "CATCH") {
+		block.Label = strings.ToUpper(p.next().Lexeme)
+	}
+	block.Children = p.parseSQLStatements(func() bool { return p.sqlIs("END") || p.isPLSQL() && p.sqlIs("EXCEPTION") })
+	if p.isPLSQL() && p.sqlIs("EXCEPTION") {
+		ekw := p.next()
+		exc := newNode("Exception", "EXCEPTION", ekw.Start, ekw.End)
+		for p.sqlIs("WHEN") {
+			wkw := p.next()
+			start := p.cur().Start
+			for !p.atEnd() && !p.sqlIs("THEN") {
+				p.next()
+			}
+			handler := newNode("Handler", p.sourceText(start, p.prevEnd()), wkw.Start, wkw.End)
+			p.sqlExpect("THEN", "en el manejador de la excepción")
+			handler.Children = p.parseSQLStatements(func() bool { return p.sqlIs("END", "WHEN") })
+			handler.End = p.prevEnd()
+			exc.Children = append(exc.Children, handler)
+		}
+		exc.End = p.prevEnd()
+		block.Children = append(block.Children, exc)
+	}
+	p.sqlExpect("END", "para cerrar el bloque BEGIN")
+	if block.Label != "BEGIN" {
+		p.sqlExpect(block.Label, "después de END")
+	}
+	if p.isPLSQL() && p.cur().Type == IDENTIFIER {
+		// END nombre_del_procedimiento;
+		p.next()
+	}
+	block.End = p.prevEnd()
+	return block
+}
+
+// parseSQLBody analiza el cuerpo de IF o WHILE en T-SQL: un bloque BEGIN o
+// una sola sentencia
+func (p *Parser) parseSQLBody() ParseNode {
+	if p.sqlIs("BEGIN") && !sqlWordIs(p.peek(1), "TRAN", "TRANSACTION") {
+		return p.parseSQLBlock()
+	}
+	stmt := p.parseSQLStatement()
+	p.accept(";")
+	return stmt
+}
+
+// parsePLSQLStatementsUntil analiza sentencias de PL/SQL hasta alguna de las
+// palabras dadas
+func (p *Parser) parsePLSQLStatementsUntil(kind string, words ...string) ParseNode {
+	start := p.cur().Start
+	node := newNode(kind, "", start, start)
+	node.Children = p.parseSQLStatements(func() bool { return p.sqlIs(words...) })
+	node.End = p.prevEnd()
+	return node
+}
+
+func (p *Parser) parseSQLIf() ParseNode {
+	kw := p.next()
+	node := newNode("If", "IF", kw.Start, kw.End, p.parseSQLExpr(0))
+	if !p.isPLSQL() {
+		node.Children = append(node.Children, p.parseSQLBody())
+		if p.sqlAccept("ELSE") {
+			node.Children = append(node.Children, p.parseSQLBody())
+		}
+		node.End = p.prevEnd()
+		return node
+	}
+	// IF c THEN ... ELSIF c THEN ... ELSE ... END IF
+	p.sqlExpect("THEN", "después de la condición del IF")
+	node.Children = append(node.Children, p.parsePLSQLStatementsUntil("Then", "ELSIF", "ELSE", "END"))
+	for p.sqlIs("ELSIF") {
+		ekw := p.next()
+		elsif := newNode("ElseIf", "ELSIF", ekw.Start, ekw.End, p.parseSQLExpr(0))
+		p.sqlExpect("THEN", "después de la condición del ELSIF")
+		elsif.Children = append(elsif.Children, p.parsePLSQLStatementsUntil("Then", "ELSIF", "ELSE", "END"))
+		elsif.End = p.prevEnd()
+		node.Children = append(node.Children, elsif)
+	}
+	if p.sqlAccept("ELSE") {
+		node.Children = append(node.Children, p.parsePLSQLStatementsUntil("Else", "END"))
+	}
+	if p.sqlExpect("END", "para cerrar el IF") {
+		p.sqlExpect("IF", "después de END")
+	}
+	node.End = p.prevEnd()
+	return node
+}
+
+func (p *Parser) parseSQLWhile() ParseNode {
+	kw := p.next()
+	node := newNode("While", "WHILE", kw.Start, kw.End, p.parseSQLExpr(0))
+	if p.isPLSQL() {
+		node.Children = append(node.Children, p.parsePLSQLLoopBody())
+	} else {
+		node.Children = append(node.Children, p.parseSQLBody())
+	}
+	node.End = p.prevEnd()
+	return node
+}
+
+// parseSQLLoop analiza los ciclos de PL/SQL: LOOP ... END LOOP y
+// FOR i IN [REVERSE] a..b LOOP ... END LOOP (o FOR r IN (SELECT ...))
+func (p *Parser) parseSQLLoop() ParseNode {
+	if p.sqlIs("LOOP") {
+		start := p.cur().Start
+		body := p.parsePLSQLLoopBody()
+		return newNode("Loop", "LOOP", start, body.End, body)
+	}
+	kw := p.next()
+	name, _ := p.parseSQLName("de la variable del FOR")
+	node := newNode("For", name.Lexeme, kw.Start, name.End)
+	p.sqlExpect("IN", "después de la variable del FOR")
+	p.sqlAccept("REVERSE")
+	switch {
+	case p.is("("):
+		node.Children = append(node.Children, p.parseSQLSubquery())
+	default:
+		from := p.parseSQLExpr(0)
+		if p.accept("..") {
+			node.Children = append(node.Children, newNode("Range", "..", from.Pos, p.prevEnd(), from, p.parseSQLExpr(0)))
+		} else {
+			// FOR r IN cursor
+			node.Children = append(node.Children, from)
+		}
+	}
+	node.Children = append(node.Children, p.parsePLSQLLoopBody())
+	node.End = p.prevEnd()
+	return node
+}
+
+func (p *Parser) parsePLSQLLoopBody() ParseNode {
+	p.sqlExpect("LOOP", "antes del cuerpo del ciclo")
+	body := p.parsePLSQLStatementsUntil("Block", "END")
+	if p.sqlExpect("END", "para cerrar el ciclo") {
+		p.sqlExpect("LOOP", "después de END")
+	}
+	body.End = p.prevEnd()
+	return body
+}
+
+// ───────────────────────────── Expresiones ───────────────────────────────
+
+// sqlBinaryOp devuelve el operador binario en la posición actual y cuántos
+// tokens ocupa (NOT IN, NOT LIKE y NOT BETWEEN ocupan dos)
+func (p *Parser) sqlBinaryOp() (string, int, int, bool) {
+	if p.atEnd() {
+		return "", 0, 0, false
+	}
+	tk := p.cur()
+	op := tk.Lexeme
+	if tk.Type == KEYWORD || tk.Type == IDENTIFIER {
+		op = strings.ToUpper(op)
+		if op == "NOT" && sqlWordIs(p.peek(1), "IN", "LIKE", "BETWEEN") {
+			op += " " + strings.ToUpper(p.peek(1).Lexeme)
+			return op, sqlBinaryOps[op], 2, true
+		}
+		if tk.Type == IDENTIFIER {
+			return "", 0, 0, false
+		}
+	} else if tk.Type != OPERATOR {
+		return "", 0, 0, false
+	}
+	prec, ok := sqlBinaryOps[op]
+	return op, prec, 1, ok
+}
+
+// parseSQLExpr analiza una expresión con operadores de precedencia mayor o
+// igual a minPrec
+func (p *Parser) parseSQLExpr(minPrec int) ParseNode {
+	left := p.parseSQLUnary()
+	for !p.stmtErr {
+		op, prec, width, ok := p.sqlBinaryOp()
+		if !ok || prec < minPrec {
+			break
+		}
+		p.pos += width
+		switch op {
+		case "IS":
+			label := "IS NULL"
+			if p.sqlAccept("NOT") {
+				label = "IS NOT NULL"
+			}
+			p.sqlExpect("NULL", "después de IS")
+			left = newNode("IsNull", label, left.Pos, p.prevEnd(), left)
+		case "IN", "NOT IN":
+			var list ParseNode
+			if p.is("(") && sqlWordIs(p.peek(1), "SELECT", "WITH") {
+				list = p.parseSQLSubquery()
+			} else {
+				open := p.cur()
+				if !p.is("(") {
+					p.expect("(", "después de "+op)
+					return left
+				}
+				list = newNode("List", "", open.Start, open.End)
+				list.Children = p.parseSQLArguments()
+				list.End = p.prevEnd()
+			}
+			left = newNode("In", op, left.Pos, p.prevEnd(), left, list)
+		case "BETWEEN", "NOT BETWEEN":
+			low := p.parseSQLExpr(prec + 1)
+			p.sqlExpect("AND", "en "+op)
+			high := p.parseSQLExpr(prec + 1)
+			left = newNode("Between", op, left.Pos, p.prevEnd(), left, low, high)
+		default:
+			right := p.parseSQLExpr(prec + 1)
+			left = newNode("Binary", op, left.Pos, right.End, left, right)
+		}
+	}
+	return left
+}
+
+func (p *Parser) parseSQLUnary() ParseNode {
+	tk := p.cur()
+	switch {
+	case p.sqlIs("NOT"):
+		p.next()
+		operand := p.parseSQLExpr(3)
+		return newNode("Unary", "NOT", tk.Start, operand.End, operand)
+	case p.is("-", "+", "~"):
+		p.next()
+		operand := p.parseSQLUnary()
+		return newNode("Unary", tk.Lexeme, tk.Start, operand.End, operand)
+	case p.sqlIs("EXISTS"):
+		p.next()
+		sub := p.parseSQLSubquery()
+		return newNode("Exists", "EXISTS", tk.Start, sub.End, sub)
+	}
+	return p.parseSQLPrimary()
+}
+
+func (p *Parser) parseSQLPrimary() ParseNode {
+	tk := p.cur()
+	switch {
+	case p.atEnd():
+		p.errorAt(tk.Start, "Se esperaba una expresión, se encontró el final del código")
+		return newNode("Error", "", tk.Start, tk.Start)
+	case tk.Type == NUMBER:
+		p.next()
+		return newNode("Number", tk.Lexeme, tk.Start, tk.End)
+	case tk.Type == STRING:
+		p.next()
+		return newNode("String", tk.Lexeme, tk.Start, tk.End)
+	case tk.Type == VARIABLE:
+		p.next()
+		return newNode("Variable", tk.Lexeme, tk.Start, tk.End)
+	case p.sqlIs("NULL", "TRUE", "FALSE", "DEFAULT"):
+		p.next()
+		return newNode("Literal", strings.ToUpper(tk.Lexeme), tk.Start, tk.End)
+	case p.sqlIs("CASE"):
+		return p.parseSQLCase()
+	case p.is("*"):
+		p.next()
+		return newNode("Star", "*", tk.Start, tk.End)
+	case p.is("("):
+		if sqlWordIs(p.peek(1), "SELECT", "WITH") {
+			return p.parseSQLSubquery()
+		}
+		p.next()
+		inner := p.parseSQLExpr(0)
+		p.expect(")", "para cerrar la expresión")
+		return inner
+	case sqlNameToken(tk) || p.sqlIs("LEFT", "RIGHT") && p.peek(1).Lexeme == "(":
+		p.next()
+		// Nombre calificado: tabla.columna, esquema.tabla.columna, t.*
+		for p.is(".") {
+			next := p.peek(1)
+			if next.Lexeme == "*" {
+				p.next()
+				p.next()
+				return newNode("Star", p.sourceText(tk.Start, p.prevEnd()), tk.Start, p.prevEnd())
+			}
+			if !sqlNameToken(next) {
+				break
+			}
+			p.next()
+			p.next()
+		}
+		name := p.sourceText(tk.Start, p.prevEnd())
+		if p.is("(") {
+			call := newNode("Call", strings.ToUpper(name), tk.Start, tk.End)
+			call.Children = p.parseSQLArguments()
+			call.End = p.prevEnd()
+			if p.sqlIs("OVER") {
+				// Funciones de ventana: ROW_NUMBER() OVER (ORDER BY ...)
+				p.next()
+				p.skipBalanced()
+				call.End = p.prevEnd()
+			}
+			return call
+		}
+		return newNode("Column", name, tk.Start, p.prevEnd())
+	}
+	p.errorAt(tk.Start, fmt.Sprintf("Se esperaba una expresión, se encontró %s", p.foundText()))
+	return newNode("Error", tk.Lexeme, tk.Start, tk.End)
+}
+
+// parseSQLArguments analiza '(' argumentos ')' de una llamada o una fila de
+// VALUES; COUNT(*), COUNT(DISTINCT x) y CAST(x AS tipo) incluidos
+func (p *Parser) parseSQLArguments() []ParseNode {
+	var args []ParseNode
+	p.expect("(", "antes de los argumentos")
+	for !p.atEnd() && !p.is(")") && !p.stmtErr {
+		p.sqlAccept("DISTINCT")
+		arg := p.parseSQLExpr(0)
+		switch {
+		case p.sqlAccept("AS"):
+			// CAST(x AS VARCHAR(10))
+			arg = newNode("Cast", "", arg.Pos, arg.End, arg, p.parseSQLType())
+			arg.End = p.prevEnd()
+		case p.sqlAccept("FROM"):
+			// EXTRACT(YEAR FROM fecha), TRIM('x' FROM nombre)
+			args = append(args, arg)
+			arg = p.parseSQLExpr(0)
+		}
+		args = append(args, arg)
+		if !p.accept(",") {
+			break
+		}
+	}
+	if !p.stmtErr {
+		p.expect(")", "para cerrar los argumentos")
+	}
+	return args
+}
+
+// parseSQLCase analiza CASE [expr] WHEN ... THEN ... [ELSE ...] END
+func (p *Parser) parseSQLCase() ParseNode {
+	kw := p.next()
+	node := newNode("Case", "CASE", kw.Start, kw.End)
+	if !p.sqlIs("WHEN") {
+		node.Children = append(node.Children, p.parseSQLExpr(0))
+	}
+	for p.sqlIs("WHEN") && !p.stmtErr {
+		wkw := p.next()
+		cond := p.parseSQLExpr(0)
+		p.sqlExpect("THEN", "en el CASE")
+		result := p.parseSQLExpr(0)
+		node.Children = append(node.Children, newNode("When", "WHEN", wkw.Start, p.prevEnd(), cond, result))
+	}
+	if p.sqlIs("ELSE") {
+		ekw := p.next()
+		value := p.parseSQLExpr(0)
+		node.Children = append(node.Children, newNode("Else", "ELSE", ekw.Start, p.prevEnd(), value))
+	}
+	p.sqlExpect("END", "para cerrar el CASE")
+	node.End = p.prevEnd()
+	return node
+}
@@ -0,0 +1,569 @@
+package main
+
+import "fmt"
+
+// ───────────────────────────── TypeScript ────────────────────────────────
+//
+// TypeScript usa la gramática de JavaScript más las anotaciones de tipo, las
+// interfaces, los alias de tipo, los enums y los genéricos. Los tipos no se
+// analizan a fondo: el parser los reconoce para saber dónde terminan y los
+// guarda como nodos TypeAnnotation con su texto; la verificación de tipos la
+// hace tsc al ejecutar (ver compileAndRunTS).
+
+// Modificadores de parámetros y miembros de clase
+var tsModifiers = map[string]bool{
+	"public": true, "private": true, "protected": true, "readonly": true,
+	"abstract": true, "declare": true, "override": true, "accessor": true,
+}
+
+// isJS indica si el lenguaje usa la gramática de JavaScript
+func (p *Parser) isJS() bool { return p.language == "javascript" || p.language == "typescript" }
+
+func (p *Parser) isTS() bool { return p.language == "typescript" }
+
+// sameLine indica si el token actual está en la misma línea que el anterior
+func (p *Parser) sameLine() bool {
+	return !p.atEnd() && p.lineOf(p.cur().Start) == p.lineOf(p.prevEnd()-1)
+}
+
+// ──────────────────────────────── Tipos ──────────────────────────────────
+
+// parseTSType consume un tipo completo y devuelve su nodo TypeAnnotation
+func (p *Parser) parseTSType() ParseNode {
+	start := p.cur().Start
+	p.skipTSType()
+	end := p.prevEnd()
+	if end < start {
+		end = start
+	}
+	return newNode("TypeAnnotation", p.sourceText(start, end), start, end)
+}
+
+// annotate agrega a node la anotación `: Tipo` si la hay
+func (p *Parser) annotate(node *ParseNode) {
+	if !p.isTS() || !p.accept(":") {
+		return
+	}
+	typ := p.parseTSType()
+	node.Children = append(node.Children, typ)
+	node.End = typ.End
+}
+
+func (p *Parser) skipTSType() {
+	p.skipTSUnion()
+	// Tipo condicional: T extends U ? X : Y
+	if p.is("extends") {
+		p.next()
+		p.skipTSUnion()
+		if p.accept("?") {
+			p.skipTSType()
+			p.expect(":", "en el tipo condicional")
+			p.skipTSType()
+		}
+	}
+}
+
+// skipTSUnion consume uniones e intersecciones: A | B & C
+func (p *Parser) skipTSUnion() {
+	if !p.accept("|") {
+		p.accept("&")
+	}
+	for {
+		p.skipTSPostfix()
+		if !p.is("|", "&") {
+			return
+		}
+		p.next()
+	}
+}
+
+// skipTSPostfix consume un tipo seguido de T[] o T[K]
+func (p *Parser) skipTSPostfix() {
+	p.skipTSPrimary()
+	for p.is("[") && p.sameLine() {
+		p.next()
+		if !p.is("]") {
+			p.skipTSType()
+		}
+		p.expect("]", "en el tipo")
+	}
+}
+
+func (p *Parser) skipTSPrimary() {
+	tk := p.cur()
+	switch {
+	case p.atEnd():
+		p.errorAt(tk.Start, "Se esperaba un tipo, se encontró fin de archivo")
+	case p.is("("):
+		// Tipo entre paréntesis o tipo función: (a: T) => U
+		p.skipBalanced()
+		if p.accept("=>") {
+			p.skipTSType()
+		}
+	case p.is("<"), p.is("new"):
+		// Tipo función genérico o de constructor: <T>(x: T) => T, new () => T
+		p.accept("new")
+		if p.is("<") {
+			p.skipAngles()
+		}
+		if p.is("(") {
+			p.skipBalanced()
+		}
+		p.expect("=>", "en el tipo función")
+		p.skipTSType()
+	case p.is("[", "{"):
+		// Tupla, tipo objeto o tipo mapeado
+		p.skipBalanced()
+	case p.is("keyof", "typeof", "readonly", "unique", "infer"):
+		p.next()
+		p.skipTSPostfix()
+	case p.is("-"):
+		p.next()
+		if p.cur().Type == NUMBER {
+			p.next()
+		}
+	case tk.Type == STRING || tk.Type == NUMBER:
+		p.next()
+	case tk.Type == IDENTIFIER || tk.Type == KEYWORD:
+		p.next()
+		if tk.Lexeme == "asserts" && isName(p.cur()) {
+			p.next()
+		}
+		for p.is(".") {
+			p.next()
+			p.next()
+		}
+		if p.is("<") && p.sameLine() {
+			p.skipAngles()
+		}
+		// Predicado de tipo: x is string
+		if p.is("is") {
+			p.next()
+			p.skipTSType()
+		}
+	default:
+		p.errorAt(tk.Start, fmt.Sprintf("Se esperaba un tipo, se encontró '%s'", tk.Lexeme))
+		if !p.is(";", "}", ")", "]", "=") {
+			p.next()
+		}
+	}
+}
+
+// skipBalanced consume desde el delimitador actual hasta su cierre
+func (p *Parser) skipBalanced() {
+	depth := 0
+	for !p.atEnd() {
+		tk := p.next()
+		switch tk.Lexeme {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			depth--
+		}
+		if depth <= 0 {
+			return
+		}
+	}
+}
+
+// angleDelta es lo que abre o cierra un token en una lista <...>; el lexer
+// entrega '>>' y '>>>' juntos en Array<Array<T>>
+func angleDelta(lex string) int {
+	switch lex {
+	case "<":
+		return 1
+	case ">", ">>", ">>>":
+		return -len(lex)
+	}
+	return 0
+}
+
+// skipAngles consume una lista de parámetros o argumentos de tipo <...>
+func (p *Parser) skipAngles() {
+	depth := 0
+	for !p.atEnd() && !p.is(";") {
+		depth += angleDelta(p.next().Lexeme)
+		if depth <= 0 {
+			return
+		}
+	}
+	p.errorAt(p.prevEnd(), "Se esperaba '>' al cerrar los parámetros de tipo")
+}
+
+// parseTSTypeParams analiza <T, U extends X = Y> en una declaración
+func (p *Parser) parseTSTypeParams() ParseNode {
+	start := p.cur().Start
+	p.skipAngles()
+	return newNode("TypeParams", p.sourceText(start, p.prevEnd()), start, p.prevEnd())
+}
+
+// parseTSTypeArgs analiza los argumentos de tipo de f<T>() o new Map<K, V>()
+func (p *Parser) parseTSTypeArgs() ParseNode {
+	params := p.parseTSTypeParams()
+	params.Kind = "TypeArgs"
+	return params
+}
+
+// Tokens que pueden aparecer dentro de los argumentos de tipo de una llamada
+var tsTypeArgTokens = map[string]bool{
+	",": true, ".": true, "[": true, "]": true, "|": true, "&": true,
+}
+
+// typeArgsAhead distingue f<T>(x) de una comparación: dentro de <...> solo
+// hay tokens de tipos y después viene '('
+func (p *Parser) typeArgsAhead() bool {
+	depth := 0
+	for i := p.pos; i < p.end; i++ {
+		tk := p.toks[i]
+		if d := angleDelta(tk.Lexeme); d != 0 {
+			depth += d
+			if depth < 0 {
+				return false
+			}
+			if depth == 0 {
+				return i+1 < p.end && p.toks[i+1].Lexeme == "("
+			}
+			continue
+		}
+		if tk.Type != IDENTIFIER && tk.Type != KEYWORD && tk.Type != STRING && tk.Type != NUMBER && !tsTypeArgTokens[tk.Lexeme] {
+			return false
+		}
+	}
+	return false
+}
+
+// ───────────────────────────── Sentencias ────────────────────────────────
+
+// parseTSStatement maneja las declaraciones propias de TypeScript; handled
+// indica si la sentencia fue reconocida
+func (p *Parser) parseTSStatement() (ParseNode, bool, bool) {
+	tk := p.cur()
+	next := p.peek(1)
+	switch tk.Lexeme {
+	case "@":
+		return p.parseTSDecorator(), true, true
+	case "interface":
+		if isName(next) {
+			return p.parseTSInterface(), true, true
+		}
+	case "type":
+		if isName(next) && (p.peek(2).Lexeme == "=" || p.peek(2).Lexeme == "<") {
+			return p.parseTSTypeAlias(), true, true
+		}
+	case "enum":
+		return p.parseTSEnum(), true, true
+	case "const":
+		if next.Lexeme == "enum" {
+			p.next()
+			node := p.parseTSEnum()
+			node.Pos = tk.Start
+			return node, true, true
+		}
+	case "abstract":
+		if next.Lexeme == "class" {
+			p.next()
+			node := p.parseJSClass()
+			node.Pos = tk.Start
+			return node, true, true
+		}
+	case "namespace", "module":
+		if (isName(next) || next.Type == STRING) && p.lineOf(next.Start) == p.lineOf(tk.Start) {
+			return p.parseTSNamespace(), true, true
+		}
+	case "declare":
+		if p.lineOf(next.Start) == p.lineOf(tk.Start) && (next.Type == KEYWORD || next.Lexeme == "global") {
+			return p.parseTSDeclare(), true, true
+		}
+	}
+	return ParseNode{}, false, false
+}
+
+// parseTSDecorator analiza @decorador o @decorador(args) antes de una clase
+// o de un miembro
+func (p *Parser) parseTSDecorator() ParseNode {
+	at := p.next()
+	expr := p.parsePostfix(p.parsePrimary())
+	return newNode("Decorator", expr.Label, at.Start, expr.End, expr)
+}
+
+// parseTSInterface analiza interface Nombre<T> extends A, B { miembros }
+func (p *Parser) parseTSInterface() ParseNode {
+	kw := p.next()
+	name := p.next()
+	node := newNode("InterfaceDecl", name.Lexeme, kw.Start, name.End)
+	if p.is("<") {
+		node.Children = append(node.Children, p.parseTSTypeParams())
+	}
+	if p.accept("extends") {
+		ext := newNode("Extends", "", p.cur().Start, p.cur().Start)
+		for {
+			typ := p.parseTSType()
+			ext.Children = append(ext.Children, typ)
+			if !p.accept(",") {
+				break
+			}
+		}
+		ext.End = p.prevEnd()
+		ext.Label = p.sourceText(ext.Pos, ext.End)
+		node.Children = append(node.Children, ext)
+	}
+	body := newNode("InterfaceBody", "{}", p.cur().Start, p.cur().Start)
+	p.expect("{", "para abrir el cuerpo de la interfaz")
+	body.Children = p.parseTSMembers()
+	p.expect("}", "para cerrar el cuerpo de la interfaz")
+	body.End = p.prevEnd()
+	node.Children = append(node.Children, body)
+	node.End = body.End
+	return node
+}
+
+// parseTSMembers analiza las propiedades y métodos de una interfaz hasta '}'
+func (p *Parser) parseTSMembers() []ParseNode {
+	var members []ParseNode
+	for !p.atEnd() && !p.is("}") {
+		start := p.pos
+		if member, ok := p.parseTSMember(); ok {
+			members = append(members, member)
+		}
+		if !p.accept(";") {
+			p.accept(",")
+		}
+		if p.pos == start {
+			p.next()
+		}
+	}
+	return members
+}
+
+// parseTSMember analiza un miembro de interfaz: x?: T, m(a: T): U,
+// [clave: string]: T o una firma de llamada (a: T): U
+func (p *Parser) parseTSMember() (ParseNode, bool) {
+	tk := p.cur()
+	if p.is("readonly") && !isMemberSuffix(p.peek(1).Lexeme) {
+		p.next()
+	}
+	if p.is("[", "(", "<") {
+		// Firma de índice, de llamada o genérica: todo es parte del tipo
+		start := p.cur().Start
+		if p.is("[") {
+			p.skipBalanced()
+		} else {
+			p.skipTSMethodSignature()
+		}
+		node := newNode("IndexSignature", p.sourceText(start, p.prevEnd()), tk.Start, p.prevEnd())
+		p.annotate(&node)
+		return node, true
+	}
+	name := p.next()
+	if name.Type != IDENTIFIER && name.Type != KEYWORD && name.Type != STRING && name.Type != NUMBER {
+		p.errorAt(name.Start, fmt.Sprintf("Se esperaba el nombre de un miembro de la interfaz, se encontró '%s'", name.Lexeme))
+		return ParseNode{}, false
+	}
+	q := p.cur()
+	optional := p.accept("?")
+	if p.is("(", "<") {
+		start := p.cur().Start
+		p.skipTSMethodSignature()
+		sig := newNode("TypeAnnotation", p.sourceText(start, p.prevEnd()), start, p.prevEnd())
+		return newNode("MethodSignature", name.Lexeme, tk.Start, sig.End, sig), true
+	}
+	node := newNode("PropertySignature", name.Lexeme, tk.Start, name.End)
+	if optional {
+		node.Children = append(node.Children, optionalMarker(q.Start))
+	}
+	p.annotate(&node)
+	return node, true
+}
+
+// isMemberSuffix indica si el token sigue al nombre de un miembro; sirve
+// para distinguir el modificador readonly de un miembro llamado readonly
+func isMemberSuffix(lex string) bool {
+	switch lex {
+	case ":", "?", "(", "<", ";", ",", "}":
+		return true
+	}
+	return false
+}
+
+// skipTSMethodSignature consume <T>(params): Tipo
+func (p *Parser) skipTSMethodSignature() {
+	if p.is("<") {
+		p.skipAngles()
+	}
+	if p.is("(") {
+		p.skipBalanced()
+	}
+	if p.accept(":") {
+		p.skipTSType()
+	}
+}
+
+// parseTSTypeAlias analiza type Nombre<T> = Tipo;
+func (p *Parser) parseTSTypeAlias() ParseNode {
+	kw := p.next()
+	name := p.next()
+	node := newNode("TypeAlias", name.Lexeme, kw.Start, name.End)
+	if p.is("<") {
+		node.Children = append(node.Children, p.parseTSTypeParams())
+	}
+	p.expect("=", "en el alias de tipo")
+	typ := p.parseTSType()
+	node.Children = append(node.Children, typ)
+	p.endStatement("después del alias de tipo")
+	node.End = p.prevEnd()
+	return node
+}
+
+// parseTSEnum analiza enum Nombre { A, B = 2, "C" = "c" }
+func (p *Parser) parseTSEnum() ParseNode {
+	kw := p.next()
+	name := p.expectName("después de 'enum'")
+	node := newNode("EnumDecl", name.Lexeme, kw.Start, name.End)
+	p.expect("{", "para abrir la enumeración")
+	for !p.atEnd() && !p.is("}") {
+		item := p.next()
+		if item.Type != IDENTIFIER && item.Type != STRING {
+			p.errorAt(item.Start, fmt.Sprintf("Se esperaba un miembro de la enumeración, se encontró '%s'", item.Lexeme))
+		}
+		member := newNode("Enumerator", item.Lexeme, item.Start, item.End)
+		if p.accept("=") {
+			value := p.parseAssignment()
+			member.Children = append(member.Children, value)
+			member.End = value.End
+		}
+		node.Children = append(node.Children, member)
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.expect("}", "para cerrar la enumeración")
+	node.End = p.prevEnd()
+	return node
+}
+
+// parseTSNamespace analiza namespace A.B { ... } y module "x" { ... }
+func (p *Parser) parseTSNamespace() ParseNode {
+	kw := p.next()
+	start := p.cur().Start
+	p.next()
+	for p.accept(".") {
+		p.expectName("en el nombre del espacio de nombres")
+	}
+	name := p.sourceText(start, p.prevEnd())
+	if !p.is("{") {
+		// declare module "x"; sin cuerpo
+		p.endStatement("después de '" + kw.Lexeme + "'")
+		return newNode("Namespace", name, kw.Start, p.prevEnd())
+	}
+	body := p.parseBlock()
+	return newNode("Namespace", name, kw.Start, body.End, body)
+}
+
+// parseTSDeclare analiza una declaración ambiental: declare const x: T;
+// declare function f(): T; declare global { ... }. Describen código que
+// existe en otra parte, por eso no llevan valor ni cuerpo.
+func (p *Parser) parseTSDeclare() ParseNode {
+	kw := p.next()
+	saved := p.ambient
+	p.ambient = true
+	defer func() { p.ambient = saved }()
+	var inner ParseNode
+	if p.is("global") {
+		g := p.next()
+		body := p.parseBlock()
+		inner = newNode("Namespace", "global", g.Start, body.End, body)
+	} else {
+		var ok bool
+		if inner, ok = p.parseCStatement(); !ok {
+			return newNode("Declare", "", kw.Start, p.prevEnd())
+		}
+	}
+	return newNode("Declare", inner.Label, kw.Start, inner.End, inner)
+}
+
+// parseTSClassHeritage analiza los argumentos de tipo de la clase base y la
+// lista implements
+func (p *Parser) parseTSClassHeritage(node *ParseNode) {
+	if n := len(node.Children); n > 0 && node.Children[n-1].Kind == "Extends" && p.is("<") {
+		ext := &node.Children[n-1]
+		args := p.parseTSTypeArgs()
+		ext.Children = append(ext.Children, args)
+		ext.End = args.End
+	}
+	if !p.is("implements") {
+		return
+	}
+	kw := p.next()
+	impl := newNode("Implements", "", kw.Start, kw.End)
+	for {
+		typ := p.parseTSType()
+		impl.Children = append(impl.Children, typ)
+		if !p.accept(",") {
+			break
+		}
+	}
+	impl.End = p.prevEnd()
+	impl.Label = p.sourceText(impl.Children[0].Pos, impl.End)
+	node.Children = append(node.Children, impl)
+}
+
+// returnTypeArrowAhead detecta el tipo de retorno de una función flecha,
+// (a: T): U => ..., revisando desde el token que sigue a ':'
+func (p *Parser) returnTypeArrowAhead(i int) bool {
+	depth, angles := 0, 0
+	for ; i < p.end; i++ {
+		lex := p.toks[i].Lexeme
+		angles += angleDelta(lex)
+		switch {
+		case lex == "(" || lex == "[" || lex == "{":
+			depth++
+		case lex == ")" || lex == "]" || lex == "}":
+			depth--
+			if depth < 0 {
+				return false
+			}
+		case depth == 0 && lex == "=>":
+			return true
+		case depth == 0 && angles <= 0 && (lex == ";" || lex == "," || lex == "="):
+			return false
+		}
+	}
+	return false
+}
+
+// parseTSGenericArrow analiza <T>(x: T): T => x; devuelve false si '<' no
+// inicia una función flecha genérica
+func (p *Parser) parseTSGenericArrow() (ParseNode, bool) {
+	saved, savedErrs := p.pos, len(p.errors)
+	start := p.cur().Start
+	typeParams := p.parseTSTypeParams()
+	if !p.is("(") || !p.arrowAhead() {
+		p.pos, p.errors = saved, p.errors[:savedErrs]
+		return ParseNode{}, false
+	}
+	fn := p.parseArrowFunction()
+	fn.Pos = start
+	fn.Children = append([]ParseNode{typeParams}, fn.Children...)
+	return fn, true
+}
+
+// optionalMarker marca un parámetro o miembro opcional (a?: T); como uno con
+// valor por defecto, no es obligatorio en las llamadas
+func optionalMarker(pos int) ParseNode {
+	return newNode("Optional", "?", pos, pos+1)
+}
+
+// skipTSParamModifiers consume los modificadores de un parámetro del
+// constructor (private readonly x: T) y sus decoradores
+func (p *Parser) skipTSParamModifiers() {
+	for {
+		switch {
+		case p.is("@"):
+			p.parseTSDecorator()
+		case tsModifiers[p.cur().Lexeme] && (isName(p.peek(1)) || p.peek(1).Type == KEYWORD):
+			p.next()
+		default:
+			return
+		}
+	}
+}
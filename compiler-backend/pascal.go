@@ -0,0 +1,507 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ───────────────────────────────── Pascal ────────────────────────────────
+//
+// Pascal estándar y el dialecto de Free Pascal. Las palabras clave y los
+// nombres no distinguen mayúsculas: Total y TOTAL son la misma variable.
+// Las cadenas van entre comillas simples ('It''s') y pueden unirse con
+// caracteres por código (#13#10); los comentarios son { }, (* *) y //.
+//
+// El parser (parser_pascal.go) arma el árbol por bloques; el análisis
+// semántico recorre esos bloques con un alcance por programa, procedimiento
+// y función.
+
+// ───────────────────────────────── Lexer ─────────────────────────────────
+
+var pascalPatterns = struct {
+	String, Number *regexp.Regexp
+}{
+	// 'It''s', #65 y 'línea'#13#10 forman una sola cadena
+	String: regexp.MustCompile(`^(?:'(?:[^'\n]|'')*'|#\d+|#\$[0-9a-fA-F]+)+`),
+	// $FF hexadecimal, %1010 binario, &17 octal; el punto necesita un dígito
+	// después para que 1..10 sea un rango
+	Number: regexp.MustCompile(`^(?:\$[0-9a-fA-F]+|%[01]+|&[0-7]+|\d+(?:\.\d+)?(?:[eE][+-]?\d+)?)`),
+}
+
+func pascalString(_ *LanguagePatterns, s string, p int) (TokenType, string) {
+	if lex, ok := matchHere(pascalPatterns.String, s, p); ok {
+		return STRING, lex
+	}
+	return UNKNOWN, ""
+}
+
+func pascalNumber(_ *LanguagePatterns, s string, p int) (TokenType, string) {
+	if lex, ok := matchHere(pascalPatterns.Number, s, p); ok {
+		return NUMBER, lex
+	}
+	return UNKNOWN, ""
+}
+
+var pascalOrder = []matcher{whitespace, comment, pascalString, pascalNumber, keyword, ident, oper, delim}
+
+// Unidades cuyos nombres están en pascalBuiltins; con cualquier otra no se
+// sabe qué declara y los nombres sin declarar no se reportan
+var pascalKnownUnits = makeSet(strings.Fields(`system crt sysutils math strutils`))
+
+// Tipos, procedimientos y funciones predefinidos de System y de las unidades
+// conocidas, en minúsculas
+var pascalBuiltins = makeSet(strings.Fields(`
+	integer shortint smallint longint int64 byte word longword cardinal qword int8 int16 int32
+	uint8 uint16 uint32 uint64 sizeint ptrint real single double extended comp currency
+	boolean bytebool wordbool longbool char ansichar widechar string shortstring ansistring
+	widestring unicodestring pchar pointer text textfile variant tobject tclass exception
+	write writeln read readln inc dec length setlength copy delete insert pos concat upcase
+	chr ord succ pred low high abs sqr sqrt sin cos arctan exp ln round trunc int frac odd
+	random randomize halt exit break continue new dispose getmem freemem sizeof assign
+	assignfile reset rewrite append close closefile eof eoln val str fillchar move include
+	exclude assigned pi maxint maxlongint input output stderr paramcount paramstr self
+	inttostr strtoint strtointdef trystrtoint floattostr strtofloat trystrtofloat format
+	formatfloat trim trimleft trimright uppercase lowercase quotedstr stringreplace comparetext
+	comparestr sametext booltostr strtobool inttohex now date time datetostr timetostr
+	datetimetostr sleep fileexists extractfilename freeandnil econverterror edivbyzero
+	erangeerror ezerodivide einouterror eaccessviolation
+	power intpower max min floor ceil log10 log2 logn tan arcsin arccos sign isnan isinfinite
+	infinity nan ensurerange inrange sum mean divmod
+	clrscr clreol gotoxy readkey keypressed delay textcolor textbackground wherex wherey
+	black blue green cyan red magenta brown lightgray darkgray lightblue lightgreen lightcyan
+	lightred lightmagenta yellow white
+	reversestring dupestring leftstr rightstr midstr posex ansireplacestr ansicontainsstr
+	ansistartsstr ansiendsstr padleft padright
+`))
+
+func init() {
+	RegisterLanguage(&languageDef{
+		name:     "pascal",
+		patterns: LanguageSpecificPatterns["pascal"],
+		matchers: pascalOrder,
+		keywords: LanguageKeywords{Builtins: pascalBuiltins},
+		parse:    (*Parser).parsePascalProgram,
+		analyze:  (*SemanticAnalyzer).analyzePascal,
+		execute:  compileAndRunPascal,
+		errors:   parsePascalErrors,
+	})
+}
+
+// ─────────────────────────────── Semántica ───────────────────────────────
+
+// pascalAnalysis es el estado del recorrido: la pila de alcances (nombre en
+// minúsculas → posición en syms) y cuántos bloques abiertos hay en los que
+// un nombre sin declarar puede ser un campo (with, métodos) o venir de una
+// unidad desconocida
+type pascalAnalysis struct {
+	syms    []Symbol
+	errors  []CompilerError
+	scopes  []map[string]int
+	path    []string     // procedimiento o función de cada alcance
+	forward map[int]bool // procedimientos declarados con forward
+	open    int
+}
+
+// analyzePascal registra las declaraciones de cada bloque en su alcance y
+// resuelve cada nombre contra el alcance más cercano que lo declara. Como
+// en Pascal, un nombre tiene que declararse antes de usarse.
+func (s *SemanticAnalyzer) analyzePascal() ([]Symbol, []CompilerError) {
+	a := &pascalAnalysis{forward: make(map[int]bool)}
+	a.push("")
+	for _, n := range s.tree {
+		a.walk(n)
+	}
+	a.pop()
+	for i := range a.syms {
+		sort.Ints(a.syms[i].References)
+	}
+	sort.SliceStable(a.errors, func(i, j int) bool { return a.errors[i].Pos < a.errors[j].Pos })
+	return a.syms, a.errors
+}
+
+func (a *pascalAnalysis) report(pos int, severity, code, format string, args ...any) {
+	a.errors = append(a.errors, CompilerError{
+		Message:  "Error semántico: " + fmt.Sprintf(format, args...),
+		Severity: severity,
+		Type:     "semantico",
+		Pos:      pos,
+		Code:     code,
+	})
+}
+
+func (a *pascalAnalysis) push(routine string) {
+	a.scopes = append(a.scopes, make(map[string]int))
+	a.path = append(a.path, routine)
+}
+
+// pop cierra el alcance actual y advierte las variables que nada usó
+func (a *pascalAnalysis) pop() {
+	scope := a.scopes[len(a.scopes)-1]
+	a.scopes = a.scopes[:len(a.scopes)-1]
+	a.path = a.path[:len(a.path)-1]
+	for _, i := range scope {
+		if sym := a.syms[i]; sym.Kind == "variable" && len(sym.References) == 0 {
+			a.report(sym.Pos, "warning", CodeUnusedVariable, "Variable '%s' fue declarada pero nunca utilizada", sym.Name)
+		}
+	}
+}
+
+// declare agrega el nombre al alcance actual; devuelve su posición en syms
+// o -1 si ya estaba declarado en el mismo alcance. La implementación de un
+// procedimiento declarado con forward no es una redeclaración.
+func (a *pascalAnalysis) declare(name, kind, typ string, pos int) int {
+	scope := a.scopes[len(a.scopes)-1]
+	key := strings.ToLower(name)
+	if prev, ok := scope[key]; ok {
+		if a.forward[prev] && a.syms[prev].Kind == kind {
+			delete(a.forward, prev)
+			return prev
+		}
+		a.report(pos, "error", CodeRedeclaredVariable, "'%s' ya fue declarado anteriormente en posición %d", name, a.syms[prev].Pos)
+		return -1
+	}
+	scope[key] = len(a.syms)
+	a.syms = append(a.syms, Symbol{Name: name, Kind: kind, Type: typ, Pos: pos, Scope: a.scopeName()})
+	return len(a.syms) - 1
+}
+
+// scopeName es el nombre del alcance actual: Externo.Interno para un
+// procedimiento anidado, "" en el programa
+func (a *pascalAnalysis) scopeName() string {
+	var names []string
+	for _, name := range a.path {
+		if name != "" && (len(names) == 0 || names[len(names)-1] != name) {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ".")
+}
+
+// use resuelve un nombre desde el alcance más interno hacia afuera
+func (a *pascalAnalysis) use(name string, pos int) {
+	key := strings.ToLower(name)
+	for i := len(a.scopes) - 1; i >= 0; i-- {
+		if j, ok := a.scopes[i][key]; ok {
+			a.syms[j].References = append(a.syms[j].References, pos)
+			return
+		}
+	}
+	if pascalBuiltins[key] || a.open > 0 {
+		return
+	}
+	a.report(pos, "error", CodeUndeclaredVariable, "Identificador '%s' no fue declarado", name)
+}
+
+// pascalTypeOf es el texto del primer hijo Type del nodo
+func pascalTypeOf(n ParseNode) string {
+	for _, c := range n.Children {
+		if c.Kind == "Type" {
+			return c.Label
+		}
+	}
+	return ""
+}
+
+func (a *pascalAnalysis) walkChildren(n ParseNode) {
+	for _, c := range n.Children {
+		a.walk(c)
+	}
+}
+
+func (a *pascalAnalysis) walk(n ParseNode) {
+	switch n.Kind {
+	case "ProgramHeader":
+		a.declare(n.Label, "program", "", n.Pos)
+	case "Uses":
+		for _, u := range n.Children {
+			if !pascalKnownUnits[strings.ToLower(u.Label)] {
+				a.open++
+				return
+			}
+		}
+	case "Identifier":
+		a.use(n.Label, n.Pos)
+	case "LabelRef", "Labeled":
+		a.use(n.Label, n.Pos)
+		a.walkChildren(n)
+	case "Label":
+		a.declare(n.Label, "label", "", n.Pos)
+	case "ConstDecl":
+		// El valor se evalúa antes de que exista la constante
+		a.walkChildren(n)
+		a.declare(n.Label, "constant", pascalTypeOf(n), n.Pos)
+	case "VarDecl":
+		a.walkChildren(n)
+		a.declare(n.Label, "variable", pascalTypeOf(n), n.Pos)
+	case "TypeSection":
+		// Los tipos de una sección se conocen entre sí: PNodo = ^TNodo puede
+		// ir antes que TNodo
+		for _, t := range n.Children {
+			a.declare(t.Label, "type", pascalTypeOf(t), t.Pos)
+		}
+		for _, t := range n.Children {
+			a.walkChildren(t)
+		}
+	case "Enumerator":
+		a.declare(n.Label, "constant", "", n.Pos)
+	case "Field", "FieldInit":
+		// El nombre del campo pertenece al registro, no al alcance
+		a.walkChildren(n)
+	case "Member":
+		// Solo el objeto es un nombre del alcance: en p.x, x es un campo
+		a.walkChildren(n)
+	case "Procedure", "Function":
+		a.walkRoutine(n)
+	case "With":
+		// Dentro del cuerpo, los campos del registro se usan sin prefijo
+		last := len(n.Children) - 1
+		for _, c := range n.Children[:max(last, 0)] {
+			a.walk(c)
+		}
+		a.open++
+		if last >= 0 {
+			a.walk(n.Children[last])
+		}
+		a.open--
+	case "Handler":
+		// on E: Exception do ...: E existe solo en el manejador
+		a.push(a.path[len(a.path)-1])
+		if n.Label != "" {
+			a.declare(n.Label, "parameter", pascalTypeOf(n), n.Pos)
+		}
+		a.walkChildren(n)
+		a.pop()
+	default:
+		a.walkChildren(n)
+	}
+}
+
+// walkRoutine declara un procedimiento o función en el alcance actual y
+// recorre sus parámetros y su bloque en un alcance propio. Dentro de una
+// función, Result es otro nombre del valor que devuelve.
+func (a *pascalAnalysis) walkRoutine(n ParseNode) {
+	kind := strings.ToLower(n.Kind)
+	method := strings.Contains(n.Label, ".")
+	var result string
+	isForward := false
+	for _, c := range n.Children {
+		switch c.Kind {
+		case "Returns":
+			result = pascalTypeOf(c)
+			a.walkChildren(c)
+		case "Directive":
+			isForward = isForward || c.Label == "forward"
+		}
+	}
+	self := -1
+	if !method {
+		self = a.declare(n.Label, kind, result, n.Pos)
+		if self >= 0 && isForward {
+			a.forward[self] = true
+		}
+	}
+	a.push(n.Label)
+	if method {
+		// Los campos de la clase se usan sin prefijo
+		a.open++
+		defer func() { a.open-- }()
+	}
+	if kind == "function" && self >= 0 {
+		a.scopes[len(a.scopes)-1]["result"] = self
+	}
+	for _, c := range n.Children {
+		switch c.Kind {
+		case "Params":
+			for _, param := range c.Children {
+				a.walkChildren(param)
+				a.declare(param.Label, "parameter", pascalTypeOf(param), param.Pos)
+			}
+		case "Block":
+			a.walk(c)
+		}
+	}
+	a.pop()
+}
+
+// ─────────────────────────────── Ejecución ───────────────────────────────
+
+// Opciones de fpc: sin el logo, solo errores y advertencias, y con la
+// información de líneas (-gl) para que los errores en tiempo de ejecución
+// digan en qué línea ocurrieron
+var fpcFlags = []string{"-l-", "-v0ew", "-gl"}
+
+// compileAndRunPascal compila con fpc y ejecuta el binario; los errores de
+// compilación detienen la ejecución igual que los de g++
+func compileAndRunPascal(timeout time.Duration, limits processLimits, code string, input ProgramInput) ExecutionResult {
+	dir, err := os.MkdirTemp("", "pascal-run-*")
+	if err != nil {
+		return ExecutionResult{Output: err.Error(), Ok: false}
+	}
+	defer os.RemoveAll(dir)
+
+	if err := os.WriteFile(filepath.Join(dir, "main.pas"), []byte(code), 0600); err != nil {
+		return ExecutionResult{Output: err.Error(), Ok: false}
+	}
+	if err := input.writeFiles(dir); err != nil {
+		return ExecutionResult{Output: err.Error(), Ok: false}
+	}
+
+	ctx, cancel := context.WithTimeout(input.parent(), timeout)
+	defer cancel()
+
+	compile := exec.CommandContext(ctx, "fpc", append(fpcFlags, "-oprog", "main.pas")...)
+	prepareTool(compile, dir)
+	built := runLimited(ctx, compile, limits)
+	if !built.Ok() {
+		return built.compileFailure(timeout, limits)
+	}
+
+	return input.runEach(timeout, limits, dir, built.Output, func(ctx context.Context) *exec.Cmd {
+		return exec.CommandContext(ctx, filepath.Join(dir, "prog"), input.Args...)
+	})
+}
+
+// ─────────────────────────── Errores de fpc ──────────────────────────────
+//
+// fpc informa cada mensaje con su archivo, línea y columna:
+//
+//	main.pas(6,5) Fatal: Syntax error, ";" expected but "=" found
+//	main.pas(9,3) Error: Identifier not found "totl"
+//	main.pas(4,3) Warning: Variable "x" does not seem to be initialized
+//
+// y termina con "Fatal: Compilation aborted", que no agrega nada. Un error en
+// tiempo de ejecución es "Runtime error 200 at $..." o, con SysUtils, una
+// excepción sin manejar; la traza de -gl dice la línea.
+
+var (
+	fpcDiagnostic = regexp.MustCompile(`\.(?:pas|pp)\((\d+)(?:,(\d+))?\) (Fatal|Error|Warning|Note|Hint): (.*)`)
+	fpcRuntime    = regexp.MustCompile(`^Runtime error (\d+) at`)
+	fpcTraceLine  = regexp.MustCompile(`line (\d+) of \S+\.(?:pas|pp)`)
+)
+
+// Mensajes de fpc que son errores léxicos
+var fpcLexical = []string{
+	"Illegal character", "String exceeds line", "Unterminated string", "Comment level",
+	"Invalid integer", "Invalid float", "Invalid digit",
+}
+
+// Mensajes de fpc que son errores de sintaxis
+var fpcSyntax = []string{
+	"Syntax error", "expected but", "Illegal expression", "Unexpected end of file",
+}
+
+// Errores en tiempo de ejecución más comunes de la RTL de Free Pascal
+var fpcRuntimeErrors = map[int]string{
+	2:   "Archivo no encontrado",
+	103: "Archivo no abierto",
+	106: "Formato numérico inválido al leer",
+	200: "División por cero",
+	201: "Valor fuera de rango",
+	202: "Desbordamiento de pila",
+	203: "Memoria insuficiente",
+	204: "Puntero inválido",
+	207: "Operación de punto flotante inválida",
+	215: "Desbordamiento aritmético",
+	216: "Violación de acceso (puntero nil o liberado)",
+}
+
+// parsePascalErrors convierte los mensajes de fpc y los errores en tiempo
+// de ejecución del programa en errores categorizados
+func parsePascalErrors(output string) []CompilerError {
+	var errors []CompilerError
+	lines := strings.Split(output, "\n")
+	// traceLine es la primera línea de main.pas en la traza que sigue a un
+	// error en tiempo de ejecución
+	traceLine := func(from int) int {
+		for _, next := range lines[from:] {
+			if matches := fpcTraceLine.FindStringSubmatch(next); len(matches) > 1 {
+				n, _ := strconv.Atoi(matches[1])
+				return n
+			}
+		}
+		return 1
+	}
+
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+
+		if matches := fpcDiagnostic.FindStringSubmatch(line); len(matches) > 4 {
+			lineNum, _ := strconv.Atoi(matches[1])
+			column := 1
+			if matches[2] != "" {
+				column, _ = strconv.Atoi(matches[2])
+			}
+			level, msg := matches[3], matches[4]
+
+			severity := "error"
+			if level == "Warning" || level == "Note" || level == "Hint" {
+				severity = "warning"
+			}
+			errorType, message := "semantico", "Error Semántico: "+msg
+			switch {
+			case containsAny(msg, fpcLexical):
+				errorType, message = "lexico", "Error Léxico: "+msg
+			case containsAny(msg, fpcSyntax):
+				errorType, message = "sintactico", "Error Sintáctico: "+strings.TrimPrefix(msg, "Syntax error, ")
+			}
+
+			errors = append(errors, CompilerError{
+				Message:  message,
+				Severity: severity,
+				Type:     errorType,
+				Line:     lineNum,
+				Column:   column,
+				Code:     CodeCompilerError,
+			})
+			continue
+		}
+
+		// Errores en tiempo de ejecución
+		if matches := fpcRuntime.FindStringSubmatch(line); len(matches) > 1 {
+			code, _ := strconv.Atoi(matches[1])
+			msg := fmt.Sprintf("Runtime error %d", code)
+			if desc, ok := fpcRuntimeErrors[code]; ok {
+				msg = fmt.Sprintf("%s (runtime error %d)", desc, code)
+			}
+			errors = append(errors, CompilerError{
+				Message:  "Error Semántico: " + msg,
+				Severity: "error",
+				Type:     "semantico",
+				Line:     traceLine(i + 1),
+				Code:     CodeRuntimeError,
+			})
+			continue
+		}
+		// An unhandled exception occurred at $...:
+		// EConvertError: "12x" is an invalid integer
+		if strings.HasPrefix(line, "An unhandled exception occurred") && i+1 < len(lines) {
+			errors = append(errors, CompilerError{
+				Message:  "Error Semántico: " + strings.TrimSpace(lines[i+1]),
+				Severity: "error",
+				Type:     "semantico",
+				Line:     traceLine(i + 2),
+				Code:     CodeRuntimeError,
+			})
+		}
+	}
+
+	return errors
+}
+
+// containsAny indica si s contiene alguno de los fragmentos
+func containsAny(s string, parts []string) bool {
+	for _, part := range parts {
+		if strings.Contains(s, part) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+)
+
+// ────────────────────────────── Escritor de PDF ──────────────────────────
+//
+// Lo mínimo para que /api/v1/report entregue un PDF sin depender de un
+// navegador ni de herramientas instaladas: páginas A4 con texto en las
+// fuentes estándar del visor (Helvetica y Courier, que no se incrustan),
+// líneas y rectángulos. El texto se codifica en WinAnsi, que cubre el
+// español; los caracteres fuera de esa tabla se escriben como '?'.
+
+const (
+	pdfPageWidth  = 595.0
+	pdfPageHeight = 842.0
+)
+
+// Fuentes del documento, en el orden de sus recursos /F1, /F2, /F3
+const (
+	pdfHelvetica = iota + 1
+	pdfHelveticaBold
+	pdfCourier
+)
+
+var pdfFontNames = []string{"", "Helvetica", "Helvetica-Bold", "Courier"}
+
+// Ancho de un carácter de Courier en unidades del tamaño de la fuente
+const pdfCourierWidth = 0.6
+
+type pdfDocument struct {
+	pages []*bytes.Buffer
+	page  *bytes.Buffer
+}
+
+// newPage agrega una página vacía; lo que se dibuja después va en ella
+func (d *pdfDocument) newPage() {
+	d.page = &bytes.Buffer{}
+	d.pages = append(d.pages, d.page)
+}
+
+// text escribe s con la línea base en (x, y), medidos desde la esquina
+// inferior izquierda como en PDF
+func (d *pdfDocument) text(x, y float64, font int, size float64, s string) {
+	fmt.Fprintf(d.page, "BT /F%d %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfString(s))
+}
+
+// color cambia el color del texto y del relleno; gray el del trazo
+func (d *pdfDocument) color(r, g, b float64) {
+	fmt.Fprintf(d.page, "%.3f %.3f %.3f rg\n", r, g, b)
+}
+
+func (d *pdfDocument) line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(d.page, "%.2f %.2f m %.2f %.2f l S\n", x1, y1, x2, y2)
+}
+
+// rect dibuja el borde de un rectángulo; fill además lo rellena con el color
+// actual
+func (d *pdfDocument) rect(x, y, w, h float64, fill bool) {
+	op := "S"
+	if fill {
+		op = "B"
+	}
+	fmt.Fprintf(d.page, "%.2f %.2f %.2f %.2f re %s\n", x, y, w, h, op)
+}
+
+func (d *pdfDocument) lineWidth(w float64) {
+	fmt.Fprintf(d.page, "%.2f w\n", w)
+}
+
+func (d *pdfDocument) gray(level float64) {
+	fmt.Fprintf(d.page, "%.3f G\n", level)
+}
+
+// bytes devuelve el archivo: catálogo, árbol de páginas, fuentes y una
+// página con su contenido comprimido por cada newPage
+func (d *pdfDocument) bytes() []byte {
+	if len(d.pages) == 0 {
+		d.newPage()
+	}
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	// 1: catálogo, 2: páginas, 3-5: fuentes, luego página y contenido de a pares
+	const firstPage = 6
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	fonts := make([]string, 0, len(pdfFontNames)-1)
+	for i, name := range pdfFontNames[1:] {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name))
+		fonts = append(fonts, fmt.Sprintf("/F%d %d 0 R", i+1, i+3))
+	}
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << %s >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, strings.Join(fonts, " "), firstPage+2*i+1))
+		var z bytes.Buffer
+		w := zlib.NewWriter(&z)
+		w.Write(page.Bytes())
+		w.Close()
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", z.Len(), z.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes()
+}
+
+// Caracteres de WinAnsi entre 0x80 y 0x9F, donde difiere de Latin-1
+var pdfWinAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92,
+	'“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+// pdfString codifica s en WinAnsi y escapa lo que cerraría la cadena
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		case pdfWinAnsi[r] != 0:
+			fmt.Fprintf(&b, "\\%03o", pdfWinAnsi[r])
+		case r == '\t':
+			b.WriteByte(' ')
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
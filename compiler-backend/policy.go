@@ -0,0 +1,542 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ────────────────────────── Política de seguridad ──────────────────────────
+//
+// Antes de ejecutar el programa se busca en el árbol sintáctico lo que puede
+// dañar al servidor o a otros usuarios: comandos del sistema, acceso a la
+// red, escritura de archivos fuera del directorio de trabajo y ciclos
+// infinitos que ocupan la CPU sin pausa. Cada regla es un diagnóstico SEC y
+// tiene una acción por despliegue (GlobalConfig.SecurityPolicy): "block" lo
+// reporta como error y no ejecuta el programa, "warn" solo advierte y "off"
+// no lo reporta. A diferencia del resto, una petición no puede desactivar
+// estos diagnósticos ni cambiar su severidad.
+//
+// Las reglas reconocen nombres (módulos, funciones), no flujo de datos: una
+// ruta armada en tiempo de ejecución o un alias de os.system no se detectan.
+// La política reduce los accidentes y los abusos evidentes; el aislamiento
+// real es el de Docker y los límites del proceso.
+
+// Acciones de una regla
+const (
+	PolicyBlock = "block"
+	PolicyWarn  = "warn"
+	PolicyOff   = "off"
+)
+
+// SecurityPolicyConfig asigna una acción a cada código SEC
+type SecurityPolicyConfig map[string]string
+
+func defaultSecurityPolicy() SecurityPolicyConfig {
+	return SecurityPolicyConfig{
+		CodeShellCommand:  PolicyBlock,
+		CodeNetworkAccess: PolicyBlock,
+		CodeFileWrite:     PolicyBlock,
+		CodeBusyLoop:      PolicyWarn,
+	}
+}
+
+// parseSecurityPolicy lee SECURITY_POLICY: una lista separada por comas de
+// regla:acción sobre los valores por defecto ("network-access:warn,
+// busy-loop:block"); la regla se indica por código o nombre y "*" las
+// abarca a todas. Las entradas que no se entienden se ignoran
+func parseSecurityPolicy(v string) SecurityPolicyConfig {
+	policy := defaultSecurityPolicy()
+	for _, entry := range strings.Split(v, ",") {
+		name, action, found := strings.Cut(strings.TrimSpace(entry), ":")
+		action = strings.ToLower(strings.TrimSpace(action))
+		if !found || action != PolicyBlock && action != PolicyWarn && action != PolicyOff {
+			continue
+		}
+		if name = strings.TrimSpace(name); name == "*" {
+			for code := range policy {
+				policy[code] = action
+			}
+			continue
+		}
+		if code, ok := diagnosticCode(name); ok {
+			if _, isRule := policy[code]; isRule {
+				policy[code] = action
+			}
+		}
+	}
+	return policy
+}
+
+// Módulos, bibliotecas y cabeceras cuyo solo uso ya cae en una regla
+var policyModules = map[string]map[string]string{
+	"python": {
+		"subprocess": CodeShellCommand, "pty": CodeShellCommand,
+		"socket": CodeNetworkAccess, "socketserver": CodeNetworkAccess, "ssl": CodeNetworkAccess,
+		"urllib.request": CodeNetworkAccess, "http.client": CodeNetworkAccess, "http.server": CodeNetworkAccess,
+		"requests": CodeNetworkAccess, "ftplib": CodeNetworkAccess, "smtplib": CodeNetworkAccess,
+		"telnetlib": CodeNetworkAccess, "asyncio.streams": CodeNetworkAccess,
+	},
+	"javascript": {
+		"child_process": CodeShellCommand, "net": CodeNetworkAccess, "http": CodeNetworkAccess,
+		"https": CodeNetworkAccess, "http2": CodeNetworkAccess, "dgram": CodeNetworkAccess, "tls": CodeNetworkAccess,
+	},
+	"go": {
+		"os/exec": CodeShellCommand, "net": CodeNetworkAccess, "net/http": CodeNetworkAccess,
+		"net/rpc": CodeNetworkAccess, "net/smtp": CodeNetworkAccess,
+	},
+	"cpp": {
+		"sys/socket.h": CodeNetworkAccess, "netinet/in.h": CodeNetworkAccess, "arpa/inet.h": CodeNetworkAccess,
+		"netdb.h": CodeNetworkAccess, "winsock2.h": CodeNetworkAccess, "winsock.h": CodeNetworkAccess,
+	},
+	"pascal": {
+		"process": CodeShellCommand,
+		"sockets": CodeNetworkAccess, "ssockets": CodeNetworkAccess, "fphttpclient": CodeNetworkAccess,
+	},
+}
+
+// Funciones que caen en una regla aunque su módulo sea de uso general (os,
+// stdlib.h); en Pascal los nombres van en minúsculas
+var policyCalls = map[string]map[string]string{
+	"python": {
+		"os.system": CodeShellCommand, "os.popen": CodeShellCommand, "os.fork": CodeShellCommand,
+		"os.execl": CodeShellCommand, "os.execlp": CodeShellCommand, "os.execv": CodeShellCommand,
+		"os.execvp": CodeShellCommand, "os.execve": CodeShellCommand, "os.spawnl": CodeShellCommand,
+		"os.spawnv": CodeShellCommand, "os.posix_spawn": CodeShellCommand,
+	},
+	"javascript": {
+		"fetch": CodeNetworkAccess,
+	},
+	"go": {
+		"syscall.Exec": CodeShellCommand, "syscall.ForkExec": CodeShellCommand,
+	},
+	"cpp": {
+		"system": CodeShellCommand, "std.system": CodeShellCommand, "popen": CodeShellCommand,
+		"_popen": CodeShellCommand, "fork": CodeShellCommand, "execl": CodeShellCommand,
+		"execlp": CodeShellCommand, "execle": CodeShellCommand, "execv": CodeShellCommand,
+		"execvp": CodeShellCommand, "execve": CodeShellCommand,
+	},
+	"pascal": {
+		"fpsystem": CodeShellCommand, "shell": CodeShellCommand, "executeprocess": CodeShellCommand,
+		"runcommand": CodeShellCommand, "fpexecv": CodeShellCommand, "fpexecve": CodeShellCommand,
+		"fpfork": CodeShellCommand,
+	},
+}
+
+// Comandos de consola habituales en ejercicios (system("pause")) que no
+// hacen nada peligroso
+var consoleCommands = map[string]bool{"pause": true, "cls": true, "clear": true}
+
+// fileWrite describe una función que escribe archivos: paths argumentos
+// desde first son rutas y mode es la posición del modo de apertura ("w",
+// "a"), o -1 si siempre escribe
+type fileWrite struct {
+	first, paths, mode int
+}
+
+var policyFileWrites = map[string]map[string]fileWrite{
+	"python": {
+		"open": {0, 1, 1}, "os.remove": {0, 1, -1}, "os.unlink": {0, 1, -1}, "os.rmdir": {0, 1, -1},
+		"os.mkdir": {0, 1, -1}, "os.makedirs": {0, 1, -1}, "os.rename": {0, 2, -1},
+		"os.replace": {0, 2, -1}, "shutil.rmtree": {0, 1, -1}, "shutil.copy": {1, 1, -1},
+		"shutil.copyfile": {1, 1, -1}, "shutil.move": {0, 2, -1},
+	},
+	"javascript": jsFileWrites(map[string]fileWrite{
+		"writeFile": {0, 1, -1}, "appendFile": {0, 1, -1}, "unlink": {0, 1, -1}, "rm": {0, 1, -1},
+		"rmdir": {0, 1, -1}, "mkdir": {0, 1, -1}, "rename": {0, 2, -1}, "copyFile": {1, 1, -1},
+		"createWriteStream": {0, 1, -1}, "truncate": {0, 1, -1},
+	}),
+	"go": {
+		"os.WriteFile": {0, 1, -1}, "ioutil.WriteFile": {0, 1, -1}, "os.Create": {0, 1, -1},
+		"os.OpenFile": {0, 1, -1}, "os.Remove": {0, 1, -1}, "os.RemoveAll": {0, 1, -1},
+		"os.Mkdir": {0, 1, -1}, "os.MkdirAll": {0, 1, -1}, "os.Rename": {0, 2, -1},
+		"os.Truncate": {0, 1, -1},
+	},
+	"cpp": {
+		"fopen": {0, 1, 1}, "freopen": {0, 1, 1}, "remove": {0, 1, -1}, "std.remove": {0, 1, -1},
+		"rename": {0, 2, -1}, "std.rename": {0, 2, -1},
+		// Constructores: std::ofstream f("ruta")
+		"ofstream": {0, 1, -1}, "fstream": {0, 1, -1},
+	},
+	"pascal": {
+		// Assign solo asocia la ruta; escribe si el programa usa Rewrite o Append
+		"assign": {1, 1, -1}, "assignfile": {1, 1, -1}, "deletefile": {0, 1, -1},
+		"renamefile": {0, 2, -1}, "createdir": {0, 1, -1}, "removedir": {0, 1, -1},
+	},
+}
+
+// jsFileWrites agrega cada función de fs en sus formas fs.x, fs.xSync,
+// fs.promises.x y la importada sola
+func jsFileWrites(names map[string]fileWrite) map[string]fileWrite {
+	calls := map[string]fileWrite{}
+	for name, fw := range names {
+		for _, prefix := range []string{"", "fs.", "fs.promises.", "fsPromises."} {
+			calls[prefix+name] = fw
+			calls[prefix+name+"Sync"] = fw
+		}
+	}
+	return calls
+}
+
+// Llamadas que detienen el programa un tiempo o hasta recibir entrada; un
+// ciclo infinito que las usa no ocupa la CPU. Se comparan en minúsculas y
+// sin el módulo
+var policyWaitCalls = map[string]bool{
+	"sleep": true, "usleep": true, "nanosleep": true, "sleep_for": true, "sleep_until": true,
+	"delay": true, "input": true, "raw_input": true, "readline": true, "readln": true,
+	"read": true, "scanf": true, "getline": true, "getchar": true, "scan": true, "scanln": true,
+	"readstring": true, "wait": true, "accept": true, "recv": true,
+}
+
+var (
+	includeDirective = regexp.MustCompile(`#\s*include\s*[<"]([^>"]+)[>"]`)
+	jsImportSource   = regexp.MustCompile("[\"'`]([^\"'`]+)[\"'`]\\s*;?$")
+)
+
+type policyChecker struct {
+	language string
+	policy   SecurityPolicyConfig
+	errors   []CompilerError
+	// Pascal: el programa abre algún archivo para escribir
+	rewrites bool
+}
+
+// checkSecurityPolicy devuelve los diagnósticos SEC de tree según policy
+func checkSecurityPolicy(tree []ParseNode, language string, policy SecurityPolicyConfig) []CompilerError {
+	pc := &policyChecker{language: semanticLanguage(language), policy: policy}
+	if pc.language == "pascal" {
+		for _, n := range tree {
+			pc.rewrites = pc.rewrites || callsAny(n, "rewrite", "append")
+		}
+	}
+	for _, n := range tree {
+		pc.walk(n)
+	}
+	return pc.errors
+}
+
+// policyBlocks indica si algún diagnóstico de la política impide ejecutar
+func policyBlocks(errors []CompilerError) bool {
+	for _, err := range errors {
+		if err.Severity == "error" {
+			return true
+		}
+	}
+	return false
+}
+
+func (pc *policyChecker) report(pos int, code, format string, args ...interface{}) {
+	severity := "warning"
+	switch pc.policy[code] {
+	case "", PolicyOff:
+		return
+	case PolicyBlock:
+		severity = "error"
+	}
+	pc.errors = append(pc.errors, CompilerError{
+		Message:  "Política de seguridad: " + fmt.Sprintf(format, args...),
+		Severity: severity,
+		Type:     "semantico",
+		Pos:      pos,
+		Code:     code,
+	})
+}
+
+func (pc *policyChecker) walk(n ParseNode) {
+	switch n.Kind {
+	case "Import", "Uses", "Preprocessor":
+		pc.imports(n)
+	case "Call":
+		pc.call(n)
+	case "VarDecl":
+		pc.streamDecl(n)
+	case "While", "For", "DoWhile", "Repeat":
+		pc.loop(n)
+	}
+	for _, c := range n.Children {
+		pc.walk(c)
+	}
+}
+
+// ───── Módulos ─────
+
+func (pc *policyChecker) imports(n ParseNode) {
+	switch pc.language {
+	case "python":
+		if rest, found := strings.CutPrefix(n.Label, "from "); found {
+			pc.module(strings.Fields(rest)[0], n.Pos)
+			return
+		}
+		for _, c := range n.Children {
+			name, _, _ := strings.Cut(c.Label, " as ")
+			pc.module(name, n.Pos)
+		}
+	case "javascript":
+		if m := jsImportSource.FindStringSubmatch(n.Label); m != nil {
+			pc.module(m[1], n.Pos)
+		}
+	case "cpp":
+		if m := includeDirective.FindStringSubmatch(n.Label); m != nil {
+			pc.module(m[1], n.Pos)
+		}
+	case "go", "pascal":
+		for _, c := range n.Children {
+			pc.module(c.Label, c.Pos)
+		}
+	}
+}
+
+// module reporta name si su uso cae en una regla; en Python también cuenta
+// el paquete que lo contiene (urllib.request.urlopen)
+func (pc *policyChecker) module(name string, pos int) {
+	modules := policyModules[pc.language]
+	key := strings.TrimPrefix(name, "node:")
+	if pc.language == "pascal" {
+		key = strings.ToLower(key)
+	}
+	for {
+		if code, ok := modules[key]; ok {
+			pc.reportRule(pos, code, name)
+			return
+		}
+		parent, _, found := cutLast(key, ".")
+		if pc.language != "python" || !found {
+			return
+		}
+		key = parent
+	}
+}
+
+func (pc *policyChecker) reportRule(pos int, code, name string) {
+	switch code {
+	case CodeShellCommand:
+		pc.report(pos, code, "'%s' ejecuta comandos del sistema", name)
+	case CodeNetworkAccess:
+		pc.report(pos, code, "'%s' accede a la red", name)
+	}
+}
+
+// ───── Llamadas ─────
+
+func (pc *policyChecker) call(n ParseNode) {
+	name := calleeName(n)
+	if name == "" {
+		return
+	}
+	key := name
+	if pc.language == "pascal" {
+		key = strings.ToLower(name)
+	}
+	args := callArguments(n)
+	if pc.language == "javascript" && name == "require" && len(args) > 0 {
+		if p, ok := stringLiteral(args[0]); ok {
+			pc.module(p, n.Pos)
+		}
+		return
+	}
+	if code, ok := policyCalls[pc.language][key]; ok {
+		if len(args) == 1 && code == CodeShellCommand {
+			if cmd, ok := stringLiteral(args[0]); ok && consoleCommands[strings.ToLower(cmd)] {
+				return
+			}
+		}
+		pc.reportRule(n.Pos, code, name)
+		return
+	}
+	if fw, ok := policyFileWrites[pc.language][key]; ok {
+		if pc.language == "pascal" && strings.HasPrefix(key, "assign") && !pc.rewrites {
+			return
+		}
+		pc.fileWrite(n.Pos, name, fw, args)
+	}
+}
+
+// streamDecl revisa las declaraciones std::ofstream f("ruta")
+func (pc *policyChecker) streamDecl(n ParseNode) {
+	if pc.language != "cpp" || len(n.Children) < 2 || n.Children[0].Kind != "Type" {
+		return
+	}
+	name := strings.TrimPrefix(n.Children[0].Label, "std::")
+	fw, ok := policyFileWrites["cpp"][name]
+	if !ok {
+		return
+	}
+	for _, c := range n.Children[1:] {
+		if c.Kind == "Arguments" {
+			pc.fileWrite(n.Pos, n.Children[0].Label, fw, c.Children)
+		}
+	}
+}
+
+func (pc *policyChecker) fileWrite(pos int, name string, fw fileWrite, args []ParseNode) {
+	if fw.mode >= 0 {
+		mode, ok := "", false
+		if fw.mode < len(args) {
+			mode, ok = stringLiteral(args[fw.mode])
+		}
+		if !ok || !strings.ContainsAny(mode, "wax+") {
+			return
+		}
+	}
+	for i := fw.first; i < fw.first+fw.paths && i < len(args); i++ {
+		if p, ok := stringLiteral(args[i]); ok && outsideWorkdir(p) {
+			pc.report(pos, CodeFileWrite, "'%s' escribe en '%s', fuera del directorio de trabajo", name, p)
+			return
+		}
+	}
+}
+
+// outsideWorkdir indica si la ruta sale del directorio de trabajo: absoluta,
+// relativa al usuario (~) o que sube con ".."
+func outsideWorkdir(p string) bool {
+	p = strings.ReplaceAll(p, `\`, "/")
+	switch p {
+	case "/dev/null", "/dev/stdout", "/dev/stderr":
+		return false
+	}
+	if strings.HasPrefix(p, "/") || strings.HasPrefix(p, "~") || len(p) >= 2 && p[1] == ':' {
+		return true
+	}
+	clean := path.Clean(p)
+	return clean == ".." || strings.HasPrefix(clean, "../")
+}
+
+// ───── Ciclos sin pausa ─────
+
+func (pc *policyChecker) loop(n ParseNode) {
+	var body ParseNode
+	infinite := false
+	switch {
+	case pc.language == "pascal" && n.Kind == "While" && len(n.Children) == 2:
+		body = n.Children[1]
+		infinite = alwaysTrue(ParseNode{Children: n.Children[:1]})
+	case pc.language == "pascal" && n.Kind == "Repeat" && len(n.Children) == 2:
+		body = n.Children[0]
+		infinite = strings.EqualFold(n.Children[1].Label, "false")
+	case pc.language == "plsql" && n.Kind == "For":
+		// FOR i IN a..b y FOR r IN (SELECT ...) siempre terminan
+		return
+	case pc.language != "pascal":
+		var cond *ParseNode
+		cond, body, _, _ = loopParts(n)
+		infinite = cond == nil || alwaysTrue(*cond)
+	}
+	if !infinite || pc.leaves(body) || waits(body) {
+		return
+	}
+	pc.report(n.Pos, CodeBusyLoop, "Ciclo infinito sin pausa: ocupa toda la CPU mientras se ejecuta")
+}
+
+// leaves indica si el cuerpo puede salir del ciclo
+func (pc *policyChecker) leaves(body ParseNode) bool {
+	if containsKind(body, "Break") {
+		return true
+	}
+	if pc.language == "pascal" {
+		return callsAny(body, "break", "exit", "halt")
+	}
+	return (&flowBuilder{language: pc.language}).escapes(body)
+}
+
+// waits indica si el cuerpo espera un tiempo o una entrada
+func waits(n ParseNode) bool {
+	switch n.Kind {
+	case "Select":
+		return true
+	case "UnaryExpr":
+		if n.Label == "<-" {
+			return true
+		}
+	case "Identifier":
+		if n.Label == "cin" {
+			return true
+		}
+	case "Call":
+		name := calleeName(n)
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			name = name[i+1:]
+		}
+		if policyWaitCalls[strings.ToLower(name)] {
+			return true
+		}
+	}
+	for _, c := range n.Children {
+		if waits(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// ───── Árbol ─────
+
+// calleeName devuelve el nombre completo de la función llamada
+// ("os.system", "std.this_thread.sleep_for"), o "" si no es un nombre
+func calleeName(n ParseNode) string {
+	if len(n.Children) > 0 && n.Children[0].Kind == "Member" {
+		return qualifiedName(n.Children[0])
+	}
+	return n.Label
+}
+
+func qualifiedName(n ParseNode) string {
+	switch n.Kind {
+	case "Identifier":
+		return n.Label
+	case "Member":
+		if len(n.Children) > 0 {
+			if q := qualifiedName(n.Children[0]); q != "" {
+				return q + "." + n.Label
+			}
+		}
+	}
+	return ""
+}
+
+func callArguments(n ParseNode) []ParseNode {
+	for _, c := range n.Children {
+		if c.Kind == "Arguments" {
+			return c.Children
+		}
+	}
+	return nil
+}
+
+// callsAny indica si el árbol llama a alguna de names (en minúsculas)
+func callsAny(n ParseNode, names ...string) bool {
+	if n.Kind == "Call" {
+		name := strings.ToLower(calleeName(n))
+		for _, want := range names {
+			if name == want {
+				return true
+			}
+		}
+	}
+	for _, c := range n.Children {
+		if callsAny(c, names...) {
+			return true
+		}
+	}
+	return false
+}
+
+// stringLiteral devuelve el texto de un literal de cadena sin comillas ni
+// prefijos (r"...", b'...')
+func stringLiteral(n ParseNode) (string, bool) {
+	if n.Kind != "Literal" && n.Kind != "String" {
+		return "", false
+	}
+	s := strings.TrimLeft(n.Label, "rRbBuUfF")
+	if len(s) < 2 || !strings.ContainsRune("\"'`", rune(s[0])) || s[len(s)-1] != s[0] {
+		return "", false
+	}
+	return s[1 : len(s)-1], true
+}
+
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
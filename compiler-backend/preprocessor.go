@@ -0,0 +1,171 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ─────────────────────────── Preprocesador C++ ───────────────────────────
+//
+// Las directivas (#include <iostream>, #define N 10) se tokenizan como una
+// sola línea completa de tipo PREPROCESSOR, incluyendo las continuaciones
+// con '\'. Antes del análisis semántico los nombres de macros se registran
+// como símbolos y las macros de objeto (#define N 10) se expanden en los
+// tokens, para que N no se reporte como variable no declarada y el resto
+// del análisis vea el valor real.
+
+// Directive es una directiva ya separada en nombre y argumentos:
+// "#  define N 10" → {Name: "define", Args: "N 10"}
+type Directive struct {
+	Name string
+	Args string
+}
+
+// Macro definida con #define. Params es nil para las macros de objeto
+type Macro struct {
+	Name   string
+	Params []string
+	Body   string
+	Pos    int // posición del nombre en el código
+}
+
+// directive reconoce una directiva que empieza en p: '#' como primer carácter
+// no blanco de la línea, hasta el final de la línea o un comentario
+func directive(_ *LanguagePatterns, s string, p int) (TokenType, string) {
+	if p >= len(s) || s[p] != '#' {
+		return UNKNOWN, ""
+	}
+	for i := p - 1; i >= 0 && s[i] != '\n'; i-- {
+		if s[i] != ' ' && s[i] != '\t' {
+			return UNKNOWN, ""
+		}
+	}
+	end := p
+	var quote byte
+	for end < len(s) {
+		c := s[end]
+		switch {
+		case quote != 0:
+			if c == '\\' && end+1 < len(s) && s[end+1] != '\n' {
+				end++
+			} else if c == quote || c == '\n' {
+				quote = 0
+				if c == '\n' {
+					continue
+				}
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '\\' && strings.HasPrefix(s[end+1:], "\n"):
+			end++ // continuación de línea
+		case c == '\\' && strings.HasPrefix(s[end+1:], "\r\n"):
+			end += 2
+		case c == '\n' || strings.HasPrefix(s[end:], "//") || strings.HasPrefix(s[end:], "/*"):
+			return PREPROCESSOR, strings.TrimRight(s[p:end], " \t\r")
+		}
+		end++
+	}
+	return PREPROCESSOR, strings.TrimRight(s[p:end], " \t\r")
+}
+
+// ParseDirective separa el nombre y los argumentos de una directiva
+func ParseDirective(lexeme string) Directive {
+	text := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(lexeme), "#"))
+	text = strings.NewReplacer("\\\r\n", " ", "\\\n", " ").Replace(text)
+	name := text
+	args := ""
+	if i := strings.IndexFunc(text, func(r rune) bool { return !unicode.IsLetter(r) }); i >= 0 {
+		name, args = text[:i], strings.TrimSpace(text[i:])
+	}
+	return Directive{Name: name, Args: args}
+}
+
+// parseMacro interpreta los argumentos de un #define; pos es la posición de
+// la directiva y se usa para ubicar el nombre de la macro
+func parseMacro(lexeme string, pos int) (Macro, bool) {
+	d := ParseDirective(lexeme)
+	if d.Name != "define" {
+		return Macro{}, false
+	}
+	name, ok := matchHere(GeneralPatterns.Identifier, d.Args, 0)
+	if !ok {
+		return Macro{}, false
+	}
+	m := Macro{Name: name, Pos: pos}
+	if i := strings.Index(lexeme, name); i >= 0 {
+		m.Pos = pos + i
+	}
+	rest := d.Args[len(name):]
+	// Solo es una macro de función si '(' sigue inmediatamente al nombre
+	if strings.HasPrefix(rest, "(") {
+		close := strings.IndexByte(rest, ')')
+		if close < 0 {
+			return m, true
+		}
+		m.Params = []string{}
+		for _, param := range strings.Split(rest[1:close], ",") {
+			if param = strings.TrimSpace(param); param != "" {
+				m.Params = append(m.Params, param)
+			}
+		}
+		rest = rest[close+1:]
+	}
+	m.Body = strings.TrimSpace(rest)
+	return m, true
+}
+
+// CollectMacros devuelve las macros definidas por las directivas de tokens,
+// aplicando los #undef en orden
+func CollectMacros(tokens []Token) map[string]Macro {
+	macros := make(map[string]Macro)
+	for _, tk := range tokens {
+		if tk.Type != PREPROCESSOR {
+			continue
+		}
+		if m, ok := parseMacro(tk.Lexeme, tk.Start); ok {
+			macros[m.Name] = m
+		} else if d := ParseDirective(tk.Lexeme); d.Name == "undef" {
+			delete(macros, strings.TrimSpace(d.Args))
+		}
+	}
+	return macros
+}
+
+// ExpandObjectMacros reemplaza cada uso de una macro de objeto por los
+// tokens de su definición. Los tokens expandidos conservan la posición del
+// uso, así los errores que provoquen apuntan a la línea donde se escribió la
+// macro. Como en C, una macro no se vuelve a expandir dentro de sí misma.
+func ExpandObjectMacros(tokens []Token, macros map[string]Macro) []Token {
+	hasObjectMacros := false
+	for _, m := range macros {
+		if m.Params == nil {
+			hasObjectMacros = true
+			break
+		}
+	}
+	if !hasObjectMacros {
+		return tokens
+	}
+	out := make([]Token, 0, len(tokens))
+	for _, tk := range tokens {
+		out = append(out, expandToken(tk, macros, map[string]bool{})...)
+	}
+	return out
+}
+
+func expandToken(tk Token, macros map[string]Macro, expanding map[string]bool) []Token {
+	m, ok := macros[tk.Lexeme]
+	if tk.Type != IDENTIFIER || !ok || m.Params != nil || expanding[m.Name] {
+		return []Token{tk}
+	}
+	expanding[m.Name] = true
+	defer delete(expanding, m.Name)
+
+	var out []Token
+	for _, body := range Tokenize(m.Body, "cpp") {
+		body.Start, body.End = tk.Start, tk.End
+		body.Line, body.Column, body.EndLine, body.EndColumn = tk.Line, tk.Column, tk.EndLine, tk.EndColumn
+		out = append(out, expandToken(body, macros, expanding)...)
+	}
+	return out
+}
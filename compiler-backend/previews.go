@@ -0,0 +1,319 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ────────────────────────────── Vistas previas ────────────────────────────
+//
+// Una vista previa publica un documento HTML en una URL propia para abrirlo
+// en otra pestaña o compartirlo, cosa que el srcdoc de executionResult.html
+// no permite. Se sirve desde el mismo servidor en /preview/{id}/, con los
+// archivos auxiliares (style.css, script.js) al lado para que las rutas
+// relativas del documento funcionen; el id es aleatorio y es lo único que
+// hace falta para verla. Cada una vence a los PREVIEW_TTL segundos.
+//
+//   POST   /api/v1/previews       publica un documento
+//   GET    /api/v1/previews       las vistas previas del usuario autenticado
+//   DELETE /api/v1/previews/{id}  la retira antes de que venza
+//   GET    /preview/{id}/{file}   el documento o uno de sus archivos
+//
+// El documento comparte el origen de la API, así que se sirve con la
+// directiva sandbox de Content-Security-Policy: el navegador le da un origen
+// opaco, sin acceso a las credenciales ni al almacenamiento del frontend, y
+// no puede enviar formularios ni conectarse a otros servidores.
+
+// PreviewRequest es el cuerpo de POST /api/v1/previews
+type PreviewRequest struct {
+	Code  string        `json:"code"`
+	Files []ProgramFile `json:"files,omitempty"`
+	// Vigencia en segundos; 0 o más que PREVIEW_TTL usa PREVIEW_TTL
+	TTLSeconds int `json:"ttlSeconds,omitempty"`
+}
+
+// APIPreview es una vista previa publicada; url es relativa al servidor
+type APIPreview struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Title     string    `json:"title,omitempty"`
+	Files     []string  `json:"files"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type APIPreviewsResponse struct {
+	Previews []APIPreview `json:"previews"`
+}
+
+// Política con la que se sirve una vista previa; los archivos del propio
+// documento se cargan aunque el origen sea opaco
+const previewCSP = "sandbox " + htmlSandbox + "; default-src 'self' https: data: blob: 'unsafe-inline'; " +
+	"connect-src 'none'; form-action 'none'; base-uri 'none'"
+
+// Documento de una vista previa dentro de su directorio
+const previewIndex = "index.html"
+
+type preview struct {
+	id      string
+	title   string
+	files   map[string]string // nombre → contenido, con previewIndex
+	created time.Time
+	expires time.Time
+	// Quien la publicó: solo él la lista y la retira; "" si es anónima
+	account string
+}
+
+func (p *preview) api() APIPreview {
+	names := make([]string, 0, len(p.files))
+	for name := range p.files {
+		if name != previewIndex {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return APIPreview{ID: p.id, URL: "/preview/" + p.id + "/", Title: p.title, Files: names,
+		CreatedAt: p.created.UTC(), ExpiresAt: p.expires.UTC()}
+}
+
+type previewStore struct {
+	mu       sync.Mutex
+	previews map[string]*preview
+}
+
+var previews = &previewStore{previews: make(map[string]*preview)}
+
+// add registra p con un id nuevo, descartando antes las vencidas y, si se
+// alcanzó GlobalConfig.MaxPreviews, la que vence primero
+func (st *previewStore) add(p *preview) error {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return err
+	}
+	p.id = hex.EncodeToString(buf)
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	now := time.Now()
+	soonest := ""
+	for id, old := range st.previews {
+		if now.After(old.expires) {
+			delete(st.previews, id)
+		} else if soonest == "" || old.expires.Before(st.previews[soonest].expires) {
+			soonest = id
+		}
+	}
+	if len(st.previews) >= GlobalConfig.MaxPreviews && soonest != "" {
+		delete(st.previews, soonest)
+	}
+	st.previews[p.id] = p
+	return nil
+}
+
+// get devuelve la vista previa si existe y no venció
+func (st *previewStore) get(id string) *preview {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	p, ok := st.previews[id]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(p.expires) {
+		delete(st.previews, id)
+		return nil
+	}
+	return p
+}
+
+// list devuelve las vigentes de account, de la más nueva a la más vieja.
+// Las anónimas no se listan: el id es lo que da acceso a cada una
+func (st *previewStore) list(account string) []APIPreview {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	now := time.Now()
+	list := []APIPreview{}
+	if account == "" {
+		return list
+	}
+	for _, p := range st.previews {
+		if p.account == account && now.Before(p.expires) {
+			list = append(list, p.api())
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	return list
+}
+
+// remove retira la vista previa id de account; found es false si no existe
+// y owned si existe pero es de otro usuario
+func (st *previewStore) remove(id, account string) (found, owned bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	p, ok := st.previews[id]
+	if !ok || time.Now().After(p.expires) {
+		return false, false
+	}
+	if p.account != account {
+		return true, false
+	}
+	delete(st.previews, id)
+	return true, true
+}
+
+// previewAccount es la cuenta a la que pertenecen las vistas previas de r
+func previewAccount(r *http.Request) string {
+	if p := principalFrom(r); p != nil {
+		return p.Account
+	}
+	return ""
+}
+
+// previewsHandler atiende /api/v1/previews
+func previewsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(APIPreviewsResponse{Previews: previews.list(previewAccount(r))})
+	case http.MethodPost:
+		createPreview(w, r)
+	default:
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func createPreview(w http.ResponseWriter, r *http.Request) {
+	var req PreviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w)
+		return
+	}
+	if req.Code == "" {
+		httpError(w, "Code is required", http.StatusBadRequest)
+		return
+	}
+	if msg := codeTooLarge(req.Code); msg != "" {
+		writeAPIError(w, http.StatusRequestEntityTooLarge, "code_too_large", msg)
+		return
+	}
+	if req.TTLSeconds < 0 {
+		httpError(w, "ttlSeconds must be positive", http.StatusBadRequest)
+		return
+	}
+	if msg := invalidFiles(req.Files); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	for _, f := range req.Files {
+		if f.Name == previewIndex {
+			httpError(w, "files: "+previewIndex+" is the previewed document", http.StatusBadRequest)
+			return
+		}
+	}
+	if status, msg := authorizeAnalysis(principalFrom(r), "html", false); status != 0 {
+		rejectAnalysis(w, status, msg)
+		return
+	}
+
+	page := newHTMLPage(req.Code)
+	ttl := GlobalConfig.PreviewTTL
+	if d := time.Duration(req.TTLSeconds) * time.Second; d > 0 && d < ttl {
+		ttl = d
+	}
+	now := time.Now()
+	p := &preview{
+		title:   page.title,
+		files:   map[string]string{previewIndex: page.render("", true)},
+		created: now,
+		expires: now.Add(ttl),
+		account: previewAccount(r),
+	}
+	for _, f := range req.Files {
+		p.files[f.Name] = f.Content
+	}
+	if err := previews.add(p); err != nil {
+		httpError(w, "Could not create preview", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(p.api())
+}
+
+// previewHandler atiende /api/v1/previews/{id}
+func previewHandler(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, apiPrefix+"/previews/")
+	if r.Method != http.MethodDelete {
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	found, owned := previews.remove(id, previewAccount(r))
+	switch {
+	case !found:
+		httpError(w, "Preview not found", http.StatusNotFound)
+	case !owned:
+		httpError(w, "Preview belongs to another user", http.StatusForbidden)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// servePreview atiende /preview/{id}/{file}: sin credenciales, el id basta
+func servePreview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id, name, hasSlash := strings.Cut(strings.TrimPrefix(r.URL.Path, "/preview/"), "/")
+	p := previews.get(id)
+	if p == nil {
+		httpError(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if !hasSlash {
+		// Sin la barra final las rutas relativas no quedarían dentro del
+		// directorio de la vista previa
+		http.Redirect(w, r, "/preview/"+id+"/", http.StatusMovedPermanently)
+		return
+	}
+	if name == "" {
+		name = previewIndex
+	}
+	content, ok := p.files[name]
+	if !ok {
+		httpError(w, "Not found", http.StatusNotFound)
+		return
+	}
+	contentType := previewContentTypes[path.Ext(name)]
+	if contentType == "" {
+		contentType = "text/plain; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Security-Policy", previewCSP)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("Cache-Control", "no-store")
+	if r.Method == http.MethodGet {
+		w.Write([]byte(content))
+	}
+}
+
+// Tipos de los archivos de una vista previa; los demás se sirven como
+// texto, así un archivo no puede hacerse pasar por otro documento
+var previewContentTypes = map[string]string{
+	".html": "text/html; charset=utf-8",
+	".htm":  "text/html; charset=utf-8",
+	".css":  "text/css; charset=utf-8",
+	".js":   "text/javascript; charset=utf-8",
+	".mjs":  "text/javascript; charset=utf-8",
+	".json": "application/json",
+	".svg":  "image/svg+xml",
+	".txt":  "text/plain; charset=utf-8",
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ──────────────────────── Entrada del programa ───────────────────────────
+//
+// Muchos ejercicios leen los argumentos de la línea de comandos. args en la
+// petición llega al programa ejecutado como sys.argv[1:] en Python,
+// process.argv.slice(2) en JavaScript y TypeScript, argv[1..argc-1] en C++ y
+// os.Args[1:] en Go, tanto en el ejecutor local como en Docker. Los
+// argumentos se pasan sin shell: no se expanden comodines ni variables.
+//
+// env agrega variables de entorno al programa, solo las que permite
+// GlobalConfig.AllowedEnvVars: así una petición no puede cambiar PATH ni
+// LD_PRELOAD. files son archivos auxiliares (nombre y contenido) que se
+// escriben en el directorio de trabajo antes de ejecutar, para los
+// programas que leen open("datos.txt"). Cada ejecución corre en su propio
+// directorio temporal, que se borra al terminar.
+//
+// stdin es la entrada estándar del programa. Con casos de prueba (judge.go)
+// hay una entrada por caso: el programa se compila una sola vez y se ejecuta
+// con cada una, en orden. La compilación y cada ejecución tienen su propio
+// límite de tiempo.
+
+// Límites de args para que una petición no arme una línea de comandos
+// enorme
+const (
+	maxProgramArgs     = 64
+	maxProgramArgBytes = 4096
+)
+
+// Límites de env, files y stdin
+const (
+	maxProgramEnvVars    = 32
+	maxProgramFiles      = 16
+	maxProgramFilesBytes = 1 << 20
+	maxProgramStdinBytes = 1 << 20
+)
+
+// ProgramInput es lo que recibe el programa además de su código
+type ProgramInput struct {
+	Args  []string
+	Env   map[string]string
+	Files []ProgramFile
+	// Entrada estándar de cada ejecución; vacío = una ejecución sin entrada
+	Stdin []string
+	// Al cancelarlo se detienen la compilación y la ejecución (ver
+	// executions.go); nil = context.Background()
+	Context context.Context
+	// Semilla y reloj fijos (ver deterministic.go)
+	Deterministic bool
+	// Recibe la salida del programa mientras corre, sin la de la compilación
+	// (ver execstream.go); nil = solo se devuelve al terminar
+	Output OutputFunc
+	// Con Terminal el programa se ejecuta una vez, conectado a ella en
+	// lugar de a Stdin (ver interactive.go)
+	Terminal *terminal
+}
+
+// OutputFunc recibe un fragmento de la salida de un programa; stream es
+// "stdout" o "stderr". chunk solo es válido durante la llamada
+type OutputFunc func(stream string, chunk []byte)
+
+// ProgramFile es un archivo auxiliar del directorio de trabajo
+type ProgramFile struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// invalidArgs devuelve el motivo por el que args no es válido, o "" si lo es
+func invalidArgs(args []string) string {
+	if len(args) > maxProgramArgs {
+		return fmt.Sprintf("args must have at most %d elements", maxProgramArgs)
+	}
+	for _, a := range args {
+		if len(a) > maxProgramArgBytes {
+			return fmt.Sprintf("each arg must be at most %d bytes", maxProgramArgBytes)
+		}
+		if strings.ContainsRune(a, 0) {
+			return "args must not contain NUL bytes"
+		}
+	}
+	return ""
+}
+
+var envVarName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// envVarAllowed indica si name está en GlobalConfig.AllowedEnvVars; una
+// entrada terminada en * permite todos los nombres con ese prefijo
+func envVarAllowed(name string) bool {
+	for _, allowed := range GlobalConfig.AllowedEnvVars {
+		if prefix, ok := strings.CutSuffix(allowed, "*"); ok && strings.HasPrefix(name, prefix) || allowed == name {
+			return true
+		}
+	}
+	return false
+}
+
+// invalidEnv devuelve el motivo por el que env no es válido, o "" si lo es
+func invalidEnv(env map[string]string) string {
+	if len(env) > maxProgramEnvVars {
+		return fmt.Sprintf("env must have at most %d variables", maxProgramEnvVars)
+	}
+	for name, value := range env {
+		if !envVarName.MatchString(name) {
+			return "env: invalid variable name " + name
+		}
+		if !envVarAllowed(name) {
+			return "env: variable " + name + " is not allowed"
+		}
+		if len(value) > maxProgramArgBytes || strings.ContainsRune(value, 0) {
+			return fmt.Sprintf("env: value of %s must be at most %d bytes without NUL bytes", name, maxProgramArgBytes)
+		}
+	}
+	return ""
+}
+
+var programFileName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// Nombres que los ejecutores ya usan en el directorio de trabajo
+var reservedFileNames = map[string]bool{
+	"main.cpp": true, "main.py": true, "main.js": true, "main.ts": true, "main.go": true,
+	"prog": true, "out": true,
+	deterministicPythonFile: true, deterministicJSFile: true, deterministicCPPFile: true,
+}
+
+// invalidFiles devuelve el motivo por el que files no es válido, o "" si lo
+// es. Los nombres no pueden tener directorios
+func invalidFiles(files []ProgramFile) string {
+	if len(files) > maxProgramFiles {
+		return fmt.Sprintf("files must have at most %d elements", maxProgramFiles)
+	}
+	total := 0
+	seen := map[string]bool{}
+	for _, f := range files {
+		if !programFileName.MatchString(f.Name) || strings.HasPrefix(f.Name, "snippet-") {
+			return "files: invalid file name " + f.Name
+		}
+		if reservedFileNames[f.Name] {
+			return "files: " + f.Name + " is reserved for the program"
+		}
+		if seen[f.Name] {
+			return "files: duplicate file name " + f.Name
+		}
+		seen[f.Name] = true
+		total += len(f.Content)
+	}
+	if total > maxProgramFilesBytes {
+		return fmt.Sprintf("files must be at most %d bytes in total", maxProgramFilesBytes)
+	}
+	return ""
+}
+
+// invalidStdin devuelve el motivo por el que las entradas no son válidas, o
+// "" si lo son; el límite es para todas juntas
+func invalidStdin(stdins []string) string {
+	total := 0
+	for _, s := range stdins {
+		total += len(s)
+	}
+	if total > maxProgramStdinBytes {
+		return fmt.Sprintf("stdin must be at most %d bytes in total", maxProgramStdinBytes)
+	}
+	return ""
+}
+
+// parent devuelve el contexto del que dependen los límites de tiempo
+func (in ProgramInput) parent() context.Context {
+	if in.Context == nil {
+		return context.Background()
+	}
+	return in.Context
+}
+
+// writeFiles escribe los archivos auxiliares en dir
+func (in ProgramInput) writeFiles(dir string) error {
+	for _, f := range in.Files {
+		if err := os.WriteFile(filepath.Join(dir, f.Name), []byte(f.Content), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// envList devuelve env como NOMBRE=valor, ordenadas por nombre
+func (in ProgramInput) envList() []string {
+	list := make([]string, 0, len(in.Env))
+	for name, value := range in.Env {
+		list = append(list, name+"="+value)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// programBaseEnv es el entorno mínimo de un programa que corre en dir, como
+// el del ejecutor Docker: el del servidor tiene secretos (JWT_SECRET,
+// GITHUB_TOKEN, REDIS_URL) que el programa podría imprimir. HOME es el
+// propio directorio de trabajo, que se borra al terminar, así un programa no
+// ve lo que otro dejó en su configuración
+func programBaseEnv(dir string) []string {
+	lang := os.Getenv("LANG")
+	if lang == "" {
+		lang = "C.UTF-8"
+	}
+	return []string{"PATH=" + os.Getenv("PATH"), "HOME=" + dir, "LANG=" + lang}
+}
+
+// prepare ejecuta cmd en dir con el entorno mínimo más las variables de env
+func (in ProgramInput) prepare(cmd *exec.Cmd, dir string) {
+	cmd.Dir = dir
+	cmd.Env = append(programBaseEnv(dir), in.envList()...)
+}
+
+// prepareTool ejecuta cmd, un compilador o un generador, en dir con el
+// entorno mínimo más extra: tampoco ellos heredan los secretos del servidor
+// ni opciones como GOFLAGS, y no ven las variables de env del programa
+func prepareTool(cmd *exec.Cmd, dir string, extra ...string) {
+	cmd.Dir = dir
+	cmd.Env = append(programBaseEnv(dir), extra...)
+}
+
+// goBuildEnv mantiene la caché de go build del servidor: con HOME en el
+// directorio de trabajo cada compilación empezaría con una caché vacía y
+// volvería a compilar la biblioteca estándar
+func goBuildEnv() []string {
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return nil
+	}
+	return []string{"GOCACHE=" + filepath.Join(cache, "go-build")}
+}
+
+// runEach ejecuta el programa ya compilado una vez por cada entrada de Stdin,
+// cada vez con su propio límite de tiempo. newCmd crea el comando sobre el
+// contexto de esa ejecución; compileOutput es lo que imprimió la compilación
+func (in ProgramInput) runEach(timeout time.Duration, limits processLimits, dir, compileOutput string, newCmd func(ctx context.Context) *exec.Cmd) ExecutionResult {
+	if in.Terminal != nil {
+		ctx, cancel := context.WithTimeout(in.parent(), timeout)
+		defer cancel()
+		cmd := newCmd(ctx)
+		in.prepare(cmd, dir)
+		return in.Terminal.run(ctx, cmd, limits).runExecution(compileOutput, timeout, limits)
+	}
+	stdins := in.Stdin
+	if len(stdins) == 0 {
+		stdins = []string{""}
+	}
+	cases := make([]ExecutionResult, 0, len(stdins))
+	for _, stdin := range stdins {
+		ctx, cancel := context.WithTimeout(in.parent(), timeout)
+		cmd := newCmd(ctx)
+		in.prepare(cmd, dir)
+		if stdin != "" {
+			cmd.Stdin = strings.NewReader(stdin)
+		}
+		cases = append(cases, runWatched(ctx, cmd, limits, in.Output).runExecution(compileOutput, timeout, limits))
+		cancel()
+	}
+	return combineRuns(cases)
+}
+
+// combineRuns resume varias ejecuciones del mismo programa: el resultado es
+// el de la primera que falló (o la primera, si ninguna falló), con todas en
+// Runs. Con una sola ejecución la devuelve tal cual
+func combineRuns(runs []ExecutionResult) ExecutionResult {
+	if len(runs) == 1 {
+		return runs[0]
+	}
+	res := runs[0]
+	for _, r := range runs {
+		if !r.Ok {
+			res = r
+			break
+		}
+	}
+	for _, r := range runs {
+		res.Transient = res.Transient || r.Transient
+	}
+	res.Runs = runs
+	return res
+}
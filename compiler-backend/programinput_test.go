@@ -0,0 +1,97 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestInvalidEnv comprueba que solo se aceptan las variables de
+// EXECUTION_ENV_ALLOWLIST (por defecto LANG, LC_ALL, TZ y APP_*)
+func TestInvalidEnv(t *testing.T) {
+	cases := []struct {
+		name  string
+		env   map[string]string
+		valid bool
+	}{
+		{"permitida", map[string]string{"TZ": "UTC"}, true},
+		{"prefijo", map[string]string{"APP_MODO": "prueba"}, true},
+		{"path", map[string]string{"PATH": "/tmp"}, false},
+		{"ld_preload", map[string]string{"LD_PRELOAD": "/tmp/x.so"}, false},
+		{"prefijo_sin_guion", map[string]string{"APPMODO": "x"}, false},
+		{"nombre_invalido", map[string]string{"1X": "x"}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			msg := invalidEnv(c.env)
+			if (msg == "") != c.valid {
+				t.Errorf("invalidEnv(%v) = %q, válida esperada: %v", c.env, msg, c.valid)
+			}
+		})
+	}
+}
+
+// TestProgramEnvironment comprueba que el programa no hereda el entorno del
+// servidor: recibe el entorno mínimo, HOME en su directorio de trabajo y las
+// variables de env
+func TestProgramEnvironment(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secreto")
+	t.Setenv("GOFLAGS", "-race")
+
+	input := ProgramInput{Env: map[string]string{"APP_MODO": "prueba"}}
+	result := runTemp(10*time.Second, limitsFor("python"), input, ".sh", "env; pwd\n", "sh")
+	if !result.Ok {
+		t.Fatalf("la ejecución falló: %s", result.Output)
+	}
+	lines := strings.Split(strings.TrimSpace(result.Output), "\n")
+	dir := lines[len(lines)-1]
+	env := map[string]string{}
+	for _, line := range lines[:len(lines)-1] {
+		if name, value, ok := strings.Cut(line, "="); ok {
+			env[name] = value
+		}
+	}
+	for _, name := range []string{"JWT_SECRET", "GOFLAGS"} {
+		if _, ok := env[name]; ok {
+			t.Errorf("el programa recibió %s", name)
+		}
+	}
+	if env["APP_MODO"] != "prueba" {
+		t.Errorf("APP_MODO = %q, esperado prueba", env["APP_MODO"])
+	}
+	if env["HOME"] != dir {
+		t.Errorf("HOME = %q, esperado el directorio de trabajo %q", env["HOME"], dir)
+	}
+}
+
+// TestToolEnvironment comprueba que los compiladores tampoco heredan los
+// secretos ni GOFLAGS del servidor, y que go build sigue compilando con él
+func TestToolEnvironment(t *testing.T) {
+	t.Setenv("REDIS_URL", "redis://:clave@localhost")
+	t.Setenv("GOFLAGS", "-mod=vendor")
+
+	dir := t.TempDir()
+	cmd := exec.Command("env")
+	prepareTool(cmd, dir, goBuildEnv()...)
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"REDIS_URL=", "GOFLAGS="} {
+		if strings.Contains(string(out), name) {
+			t.Errorf("el compilador recibió %s", name)
+		}
+	}
+	if !strings.Contains(string(out), "HOME="+dir+"\n") {
+		t.Errorf("HOME no es el directorio de trabajo:\n%s", out)
+	}
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go no está instalado")
+	}
+	result := compileAndRunGo(time.Minute, limitsFor("go"), "package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Println(\"hola\") }\n", ProgramInput{})
+	if !result.Ok || strings.TrimSpace(result.Output) != "hola" {
+		t.Errorf("go build con el entorno mínimo: %s", result.Output)
+	}
+}
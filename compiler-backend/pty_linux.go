@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+// ptySupported indica si el servidor puede dar una terminal a un programa
+const ptySupported = true
+
+// openPTY crea una pseudoterminal: el servidor lee y escribe en master y el
+// programa usa slave como su terminal
+func openPTY() (master, slave *os.File, err error) {
+	master, err = os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	var unlock int32
+	var n uint32
+	if err = ptyIoctl(master, syscall.TIOCSPTLCK, unsafe.Pointer(&unlock)); err == nil {
+		err = ptyIoctl(master, syscall.TIOCGPTN, unsafe.Pointer(&n))
+	}
+	if err == nil {
+		slave, err = os.OpenFile("/dev/pts/"+strconv.Itoa(int(n)), os.O_RDWR|syscall.O_NOCTTY|syscall.O_CLOEXEC, 0)
+	}
+	if err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+	return master, slave, nil
+}
+
+// setWinsize cambia las columnas y filas que ve el programa; con 0 se deja
+// el tamaño anterior
+func setWinsize(master *os.File, cols, rows int) error {
+	if cols <= 0 || rows <= 0 {
+		return nil
+	}
+	ws := struct{ Row, Col, X, Y uint16 }{Row: uint16(rows), Col: uint16(cols)}
+	return ptyIoctl(master, syscall.TIOCSWINSZ, unsafe.Pointer(&ws))
+}
+
+// attachTerminal hace de slave la entrada, la salida y la terminal de
+// control de cmd. El programa abre su propia sesión, que también es su
+// grupo de procesos: processSandbox.kill sigue alcanzando a sus hijos
+func attachTerminal(cmd *exec.Cmd, slave *os.File) {
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = slave, slave, slave
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	// setpgid falla en quien ya lidera una sesión
+	cmd.SysProcAttr.Setpgid = false
+	cmd.SysProcAttr.Setsid = true
+	cmd.SysProcAttr.Setctty = true
+	cmd.SysProcAttr.Ctty = 0
+}
+
+// ptyIoctl usa SyscallConn para no sacar a master del poller: Fd() lo
+// dejaría bloqueante y una lectura pendiente no terminaría al cerrarlo
+func ptyIoctl(f *os.File, req uintptr, arg unsafe.Pointer) error {
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var errno syscall.Errno
+	err = conn.Control(func(fd uintptr) {
+		_, _, errno = syscall.Syscall(syscall.SYS_IOCTL, fd, req, uintptr(arg))
+	})
+	if err != nil {
+		return err
+	}
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
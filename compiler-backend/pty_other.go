@@ -0,0 +1,20 @@
+//go:build !linux
+
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// Fuera de Linux no hay sesiones interactivas (ver interactive.go)
+const ptySupported = false
+
+var errNoPTY = errors.New("las sesiones interactivas solo están disponibles en Linux")
+
+func openPTY() (master, slave *os.File, err error) { return nil, nil, errNoPTY }
+
+func setWinsize(*os.File, int, int) error { return errNoPTY }
+
+func attachTerminal(*exec.Cmd, *os.File) {}
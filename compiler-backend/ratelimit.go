@@ -0,0 +1,116 @@
+package main
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ──────────────────────────── Límites de uso ─────────────────────────────
+//
+// Cada análisis puede compilar y ejecutar código, así que un cliente en un
+// bucle podría lanzar procesos sin límite. Se limitan las peticiones por IP
+// (cubeta de fichas de GlobalConfig.RateLimitPerMinute por minuto) y las
+// ejecuciones simultáneas en todo el servidor.
+
+type ipRateLimiter struct {
+	mu        sync.Mutex
+	perMinute int
+	clients   map[string]*clientBucket
+	lastSweep time.Time
+}
+
+type clientBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newIPRateLimiter(perMinute int) *ipRateLimiter {
+	return &ipRateLimiter{perMinute: perMinute, clients: make(map[string]*clientBucket), lastSweep: time.Now()}
+}
+
+// allow consume una ficha de la IP; si no quedan devuelve cuánto esperar
+func (l *ipRateLimiter) allow(ip string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	rate := float64(l.perMinute) / float64(time.Minute) // fichas por nanosegundo
+	// Una cubeta inactiva durante un minuto ya está llena: se descarta
+	if now.Sub(l.lastSweep) > time.Minute {
+		for key, b := range l.clients {
+			if now.Sub(b.last) > time.Minute {
+				delete(l.clients, key)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.clients[ip]
+	if !ok {
+		b = &clientBucket{tokens: float64(l.perMinute), last: now}
+		l.clients[ip] = b
+	}
+	b.tokens = math.Min(float64(l.perMinute), b.tokens+float64(now.Sub(b.last))*rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / rate)
+	}
+	b.tokens--
+	return true, 0
+}
+
+// limit rechaza con 429 las peticiones de una IP que superó su límite
+func (l *ipRateLimiter) limit(next http.HandlerFunc) http.HandlerFunc {
+	if l.perMinute <= 0 {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if ok, wait := l.allow(clientIP(r), time.Now()); !ok {
+			retry := int(math.Ceil(wait.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(retry))
+			writeAPIErrorDetails(w, http.StatusTooManyRequests, "rate_limited", "Too many requests",
+				map[string]interface{}{"retryAfterSeconds": retry})
+			return
+		}
+		next(w, r)
+	}
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+var (
+	executionSlots     chan struct{}
+	executionSlotsOnce sync.Once
+)
+
+// limitedExecutor ocupa uno de los GlobalConfig.MaxConcurrentExecutions
+// lugares mientras ejecuta; si no se libera ninguno dentro del timeout de la
+// petición, responde que el servidor está ocupado sin ejecutar nada
+type limitedExecutor struct {
+	Executor
+	wait time.Duration
+}
+
+func (le limitedExecutor) Execute(code string, symbols []Symbol) ExecutionResult {
+	executionSlotsOnce.Do(func() {
+		executionSlots = make(chan struct{}, GlobalConfig.MaxConcurrentExecutions)
+	})
+	timer := time.NewTimer(le.wait)
+	defer timer.Stop()
+	select {
+	case executionSlots <- struct{}{}:
+		defer func() { <-executionSlots }()
+		return le.Executor.Execute(code, symbols)
+	case <-timer.C:
+		return ExecutionResult{Output: "Servidor ocupado: demasiadas ejecuciones simultáneas, intente de nuevo", Ok: false, Transient: true}
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"runtime/debug"
+)
+
+// ────────────────────────── Recuperación de pánicos ──────────────────────
+//
+// Un patrón nil en una regla, un índice fuera de rango en el parser de un
+// lenguaje poco probado: con el código de los estudiantes es fácil llegar a
+// un pánico. net/http lo recupera pero cierra la conexión, y el frontend
+// solo ve un error de red. recoverPanics atiende antes que todos los
+// handlers, registra el pánico con su traza y la petición, y responde 500
+// con un APIError. Si el handler ya había empezado a responder (SSE) solo se
+// corta la respuesta; un WebSocket se cierra.
+//
+// Los pánicos de otras goroutines no llegan al handler: tiran el servidor.
+// Las del análisis (ver AnalyzeCodeWithProgress) usan goSafe, que repite el
+// pánico en la goroutine que espera el resultado, y los workers de la cola
+// marcan el trabajo con el error en lugar de detenerse.
+
+// capturedPanic es un pánico recuperado junto con la traza del lugar donde
+// ocurrió, para no perderla al repetirlo en otra goroutine
+type capturedPanic struct {
+	value interface{}
+	stack []byte
+}
+
+func (p *capturedPanic) Error() string {
+	return fmt.Sprint(p.value)
+}
+
+// capturePanic envuelve el valor r de recover() con la traza actual; un
+// pánico ya capturado conserva la original
+func capturePanic(r interface{}) *capturedPanic {
+	if p, ok := r.(*capturedPanic); ok {
+		return p
+	}
+	return &capturedPanic{value: r, stack: debug.Stack()}
+}
+
+// goSafe corre f en otra goroutine y devuelve una función que espera a que
+// termine. Si f entra en pánico, la espera repite el pánico en la goroutine
+// que espera
+func goSafe(f func()) (wait func()) {
+	done := make(chan struct{})
+	var failure *capturedPanic
+	go func() {
+		defer close(done)
+		defer func() {
+			if r := recover(); r != nil {
+				failure = capturePanic(r)
+			}
+		}()
+		f()
+	}()
+	return func() {
+		<-done
+		if failure != nil {
+			panic(failure)
+		}
+	}
+}
+
+// logPanic registra p con lo que hace falta para reproducirlo
+func logPanic(context string, p *capturedPanic) {
+	log.Printf("panic: %s: %v\n%s", context, p.value, p.stack)
+}
+
+// panicWriter recuerda si ya se empezó a responder y la conexión si se tomó
+// para un WebSocket
+type panicWriter struct {
+	http.ResponseWriter
+	wrote    bool
+	hijacked net.Conn
+}
+
+func (pw *panicWriter) WriteHeader(status int) {
+	pw.wrote = true
+	pw.ResponseWriter.WriteHeader(status)
+}
+
+func (pw *panicWriter) Write(b []byte) (int, error) {
+	pw.wrote = true
+	return pw.ResponseWriter.Write(b)
+}
+
+// Flush y Unwrap mantienen el SSE de execstream.go, que usa
+// http.ResponseController
+func (pw *panicWriter) Flush() {
+	pw.wrote = true
+	http.NewResponseController(pw.ResponseWriter).Flush()
+}
+
+func (pw *panicWriter) Unwrap() http.ResponseWriter {
+	return pw.ResponseWriter
+}
+
+// Hijack lo usa el upgrader de gorilla/websocket
+func (pw *panicWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(pw.ResponseWriter).Hijack()
+	if err == nil {
+		pw.wrote, pw.hijacked = true, conn
+	}
+	return conn, rw, err
+}
+
+// recoverPanics responde 500 con un APIError si next entra en pánico
+func recoverPanics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pw := &panicWriter{ResponseWriter: w}
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				// El handler cortó la respuesta a propósito
+				panic(rec)
+			}
+			// assignRequestID ya dejó el id en la petición
+			id := r.Header.Get(requestIDHeader)
+			logPanic(fmt.Sprintf("%s %s (request %q, %s)", r.Method, r.URL.Path, id, r.RemoteAddr), capturePanic(rec))
+			switch {
+			case pw.hijacked != nil:
+				pw.hijacked.Close()
+			case !pw.wrote:
+				writeAPIError(w, http.StatusInternalServerError, "internal_error", "Internal server error")
+			default:
+				panic(http.ErrAbortHandler)
+			}
+		}()
+		next.ServeHTTP(pw, r)
+	})
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ───────────────────────────────── Redis ─────────────────────────────────
+//
+// Con REDIS_URL varias instancias del servidor comparten la cola de análisis
+// asíncronos (ver redisJobQueue en jobs.go) y la caché de resultados, así en
+// un examen se pueden agregar instancias detrás de un balanceador sin perder
+// los trabajos ni las respuestas ya calculadas. Solo se usan unos pocos
+// comandos (GET, SET, DEL, LPUSH, BRPOP, LLEN, LPOS), por eso el protocolo
+// (RESP) se implementa aquí en lugar de agregar una dependencia.
+
+// Prefijo de todas las claves, para compartir el servidor Redis con otras
+// aplicaciones
+const redisPrefix = "compiler:"
+
+// Tiempo máximo de cada comando, salvo BRPOP que espera trabajos
+const redisTimeout = 5 * time.Second
+
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+// redisClient mantiene un conjunto de conexiones a un servidor Redis
+type redisClient struct {
+	addr     string
+	username string
+	password string
+	db       int
+	idle     chan *redisConn
+}
+
+type redisConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// newRedisClient interpreta una URL redis://[usuario:contraseña@]host[:puerto][/db]
+func newRedisClient(rawURL string) (*redisClient, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "redis" || u.Hostname() == "" {
+		return nil, fmt.Errorf("REDIS_URL must be redis://[user:password@]host[:port][/db]")
+	}
+	c := &redisClient{addr: u.Host, idle: make(chan *redisConn, 16)}
+	if u.Port() == "" {
+		c.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		c.username = u.User.Username()
+		c.password, _ = u.User.Password()
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		if c.db, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("REDIS_URL: invalid database %q", db)
+		}
+	}
+	return c, nil
+}
+
+// dial abre una conexión nueva, autenticada y con la base elegida
+func (c *redisClient) dial() (*redisConn, error) {
+	conn, err := net.DialTimeout("tcp", c.addr, redisTimeout)
+	if err != nil {
+		return nil, err
+	}
+	rc := &redisConn{conn: conn, r: bufio.NewReader(conn)}
+	if c.password != "" {
+		args := []string{"AUTH", c.password}
+		if c.username != "" {
+			args = []string{"AUTH", c.username, c.password}
+		}
+		if _, err := rc.do(redisTimeout, args...); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if c.db != 0 {
+		if _, err := rc.do(redisTimeout, "SELECT", strconv.Itoa(c.db)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return rc, nil
+}
+
+// do ejecuta un comando en una conexión libre. Las respuestas son string,
+// int64, nil (valor inexistente) o []interface{}; los errores de Redis son
+// redisError
+func (c *redisClient) do(args ...string) (interface{}, error) {
+	var rc *redisConn
+	select {
+	case rc = <-c.idle:
+	default:
+		var err error
+		if rc, err = c.dial(); err != nil {
+			return nil, err
+		}
+	}
+	reply, err := rc.do(redisTimeout, args...)
+	var rerr redisError
+	if err != nil && !errors.As(err, &rerr) {
+		// Error de red: la conexión quedó en un estado desconocido
+		rc.conn.Close()
+		return nil, err
+	}
+	select {
+	case c.idle <- rc:
+	default:
+		rc.conn.Close()
+	}
+	return reply, err
+}
+
+// ping comprueba la conexión al iniciar el servidor
+func (c *redisClient) ping() error {
+	_, err := c.do("PING")
+	return err
+}
+
+// do envía un comando y lee su respuesta con un límite de timeout
+func (rc *redisConn) do(timeout time.Duration, args ...string) (interface{}, error) {
+	rc.conn.SetDeadline(time.Now().Add(timeout))
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(rc.conn, b.String()); err != nil {
+		return nil, err
+	}
+	return rc.read()
+}
+
+// read lee una respuesta RESP
+func (rc *redisConn) read() (interface{}, error) {
+	line, err := rc.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("redis: empty reply")
+	}
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(rc.r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = rc.read(); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redis: unexpected reply %q", line)
+}
+
+// redisResultCache es el segundo nivel de la caché de resultados, compartido
+// por todas las instancias; nil si no se configuró Redis
+type redisResultCache struct {
+	client *redisClient
+}
+
+var sharedCache *redisResultCache
+
+// Tiempo que se guarda cada resultado en Redis
+const redisResultTTL = time.Hour
+
+func (c *redisResultCache) get(key string) (AnalyzeResponse, bool) {
+	var result AnalyzeResponse
+	if c == nil {
+		return result, false
+	}
+	reply, err := c.client.do("GET", redisPrefix+"result:"+key)
+	data, ok := reply.(string)
+	if err != nil || !ok {
+		return result, false
+	}
+	if err := json.Unmarshal([]byte(data), &result); err != nil {
+		return result, false
+	}
+	return result, true
+}
+
+func (c *redisResultCache) put(key string, result AnalyzeResponse) {
+	if c == nil {
+		return
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	if _, err := c.client.do("SET", redisPrefix+"result:"+key, string(data), "EX", strconv.Itoa(int(redisResultTTL.Seconds()))); err != nil {
+		log.Printf("redis: no se pudo guardar el resultado en la caché: %v", err)
+	}
+}
@@ -0,0 +1,651 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ───────────────────────────── Reporte completo ──────────────────────────
+//
+// POST /api/v1/report?format=html|pdf analiza un AnalyzeRequest como
+// /api/v1/analyze y arma con el resultado el reporte que se entrega en el
+// laboratorio: el código con sus números de línea, los tokens, el árbol
+// sintáctico dibujado, la tabla de símbolos, los errores y la salida de la
+// ejecución. El HTML es un único archivo sin recursos externos (el árbol va
+// como SVG dentro de la página) y el PDF lo genera pdf.go, sin navegador ni
+// herramientas instaladas. title y author en la query string completan el
+// encabezado.
+//
+// El árbol se dibuja con una disposición simple: las hojas ocupan columnas
+// consecutivas y cada nodo queda centrado sobre sus hijos. Un árbol de más
+// de reportTreeMaxNodes nodos no se dibuja (no se podría leer); para
+// esos está /api/v1/analyze/tree.
+
+const (
+	reportFormatHTML = "html"
+	reportFormatPDF  = "pdf"
+)
+
+var reportContentTypes = map[string]string{
+	reportFormatHTML: "text/html; charset=utf-8",
+	reportFormatPDF:  "application/pdf",
+}
+
+const reportTreeMaxNodes = 400
+
+// Medidas del dibujo del árbol en puntos: columna de una hoja, distancia
+// entre niveles y la caja de cada nodo, con el texto acortado para caber
+const (
+	treeSlotWidth   = 84.0
+	treeLevelHeight = 56.0
+	treeBoxWidth    = 78.0
+	treeBoxHeight   = 34.0
+	treeBoxRunes    = 12
+)
+
+// Textos del reporte en cada idioma
+type reportText struct {
+	Title, Author, Language, Generated                       string
+	Source, Tokens, Tree, Symbols, Errors, Execution         string
+	Type, Value, Line, Column, Phase, Severity, Code, Detail string
+	NoSymbols, NoErrors, NotExecuted, TreeTooLarge           string
+	ExitCode, Duration, Verdict, Score, Page                 string
+}
+
+var reportTexts = map[string]reportText{
+	"es": {
+		Title: "Reporte de laboratorio", Author: "Autor", Language: "Lenguaje", Generated: "Generado",
+		Source: "Código fuente", Tokens: "Tokens", Tree: "Árbol sintáctico", Symbols: "Tabla de símbolos",
+		Errors: "Errores", Execution: "Ejecución",
+		Type: "Tipo", Value: "Valor", Line: "Línea", Column: "Columna", Phase: "Fase", Severity: "Severidad",
+		Code: "Código", Detail: "Mensaje",
+		NoSymbols: "El programa no declara símbolos.", NoErrors: "El análisis no encontró errores.",
+		NotExecuted:  "El programa no se ejecutó.",
+		TreeTooLarge: "El árbol tiene %d nodos, demasiados para dibujarlo; /api/v1/analyze/tree lo exporta para Graphviz.",
+		ExitCode:     "Código de salida", Duration: "Duración", Verdict: "Veredicto", Score: "Puntaje", Page: "Página",
+	},
+	"en": {
+		Title: "Lab report", Author: "Author", Language: "Language", Generated: "Generated",
+		Source: "Source code", Tokens: "Tokens", Tree: "Parse tree", Symbols: "Symbol table",
+		Errors: "Errors", Execution: "Execution",
+		Type: "Type", Value: "Value", Line: "Line", Column: "Column", Phase: "Phase", Severity: "Severity",
+		Code: "Code", Detail: "Message",
+		NoSymbols: "The program declares no symbols.", NoErrors: "The analysis found no errors.",
+		NotExecuted:  "The program was not run.",
+		TreeTooLarge: "The tree has %d nodes, too many to draw; /api/v1/analyze/tree exports it for Graphviz.",
+		ExitCode:     "Exit code", Duration: "Duration", Verdict: "Verdict", Score: "Score", Page: "Page",
+	},
+}
+
+// labReport es lo que muestra el reporte: la respuesta de la API más el
+// árbol y los alcances, que la API no incluye con sus rangos
+type labReport struct {
+	title, author string
+	locale        string
+	generated     time.Time
+	code          string
+	response      APIAnalyzeResponse
+	tree          []ParseNode
+	scopes        []string
+	text          reportText
+}
+
+// treeBox es un nodo del árbol ya ubicado: x en columnas y depth en niveles
+type treeBox struct {
+	kind, label   string
+	x             float64
+	depth, parent int
+}
+
+// layoutTree ubica los nodos de nodes; devuelve también la cantidad de
+// columnas y de niveles que ocupan
+func layoutTree(nodes []ParseNode) (boxes []treeBox, columns, levels int) {
+	var place func(n ParseNode, depth, parent int) float64
+	place = func(n ParseNode, depth, parent int) float64 {
+		id := len(boxes)
+		kind, label := treeNodeText(n)
+		boxes = append(boxes, treeBox{kind: shortenRunes(kind, treeBoxRunes), label: shortenRunes(label, treeBoxRunes), depth: depth, parent: parent})
+		if depth+1 > levels {
+			levels = depth + 1
+		}
+		if len(n.Children) == 0 {
+			boxes[id].x = float64(columns)
+			columns++
+			return boxes[id].x
+		}
+		first := place(n.Children[0], depth+1, id)
+		last := first
+		for _, c := range n.Children[1:] {
+			last = place(c, depth+1, id)
+		}
+		boxes[id].x = (first + last) / 2
+		return boxes[id].x
+	}
+	for _, n := range nodes {
+		place(n, 0, -1)
+	}
+	return boxes, columns, levels
+}
+
+func shortenRunes(s string, max int) string {
+	if runes := []rune(s); len(runes) > max {
+		return string(runes[:max-1]) + "…"
+	}
+	return s
+}
+
+// reportHandler atiende /api/v1/report; sin format responde HTML
+func reportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req AnalyzeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w)
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = reportFormatHTML
+	}
+	if _, ok := reportContentTypes[format]; !ok {
+		httpError(w, "format must be html or pdf", http.StatusBadRequest)
+		return
+	}
+	id, msg := requestID(r)
+	if msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if executions.active(id) {
+		httpError(w, "An execution with this request id is already running", http.StatusConflict)
+		return
+	}
+	w.Header().Set(requestIDHeader, id)
+	if req.Code == "" {
+		httpError(w, "Code is required", http.StatusBadRequest)
+		return
+	}
+	if req.TimeoutSeconds < 0 {
+		httpError(w, "timeoutSeconds must be positive", http.StatusBadRequest)
+		return
+	}
+	if name := unknownDiagnostic(req.Diagnostics); name != "" {
+		httpError(w, "diagnostics: unknown diagnostic "+name, http.StatusBadRequest)
+		return
+	}
+	if msg := invalidSeverityOverride(req.SeverityOverrides); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if req.MaxErrors < 0 {
+		httpError(w, "maxErrors must be positive", http.StatusBadRequest)
+		return
+	}
+	if msg := invalidArgs(req.Args); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if msg := invalidEnv(req.Env); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if msg := invalidFiles(req.Files); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if msg := req.invalidJudgeRequest(principalFrom(r)); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if msg := invalidLocale(req.Locale); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	req.Locale = requestLocale(req.Locale, r)
+	principal := principalFrom(r)
+	if status, msg := req.authorize(principal); status != 0 {
+		rejectAnalysis(w, status, msg)
+		return
+	}
+
+	opts := req.options(principal)
+	opts.RequestID = id
+	result := AnalyzeCodeCached(req.Code, mapLanguage(req.Language), opts)
+	recordAnalysis(req.Code, result, principal)
+
+	text, ok := reportTexts[req.Locale]
+	if !ok {
+		text = reportTexts["es"]
+	}
+	rep := labReport{
+		title:     r.URL.Query().Get("title"),
+		author:    r.URL.Query().Get("author"),
+		locale:    req.Locale,
+		generated: time.Now(),
+		code:      req.Code,
+		response:  buildAPIResponse(result, newSourceIndex(req.Code), req.Locale),
+		tree:      result.ParseTree,
+		scopes:    symbolScopes(result.SymbolTable, result.ParseTree),
+		text:      text,
+	}
+	if rep.title == "" {
+		rep.title = text.Title
+	}
+
+	w.Header().Set("Content-Type", reportContentTypes[format])
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"reporte-%s.%s\"", result.Language, format))
+	if format == reportFormatPDF {
+		w.Write(rep.pdf())
+		return
+	}
+	w.Write([]byte(rep.html()))
+}
+
+// ──────────────────────────────── HTML ───────────────────────────────────
+
+// Estilo del reporte; los colores de los tokens usan las clases de
+// highlight.go
+const reportStyle = symbolTableStyle +
+	`h2{border-bottom:1px solid #ccc;padding-bottom:.2em;margin-top:1.6em}.meta{color:#555}` +
+	`pre{margin:0;font-family:Menlo,Consolas,monospace;font-size:.85em}` +
+	`table.source td{border:none;padding:0 .6em;vertical-align:top}table.source td.num{color:#999}` +
+	`.token-KEYWORD{color:#a626a4}.token-STRING{color:#50a14f}.token-NUMBER,.token-CONSTANT{color:#986801}` +
+	`.token-COMMENT{color:#a0a1a7;font-style:italic}.token-FUNCTION{color:#4078f2}.token-CLASS{color:#0184bc}` +
+	`.token-PREPROCESSOR{color:#c18401}.token-UNKNOWN{color:#e45649;text-decoration:underline wavy}` +
+	`.tree{overflow:auto;border:1px solid #ddd}.error td{color:#b00020}.warning td{color:#8a6d00}` +
+	`.output{background:#f6f6f6;padding:.6em;white-space:pre-wrap}` +
+	`@media print{.tree{overflow:visible}h2{break-after:avoid}}`
+
+// html genera la página completa
+func (rep labReport) html() string {
+	t, res := rep.text, rep.response
+	var b strings.Builder
+	fmt.Fprintf(&b, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n<style>%s</style>\n</head>\n<body>\n",
+		html.EscapeString(rep.title), reportStyle)
+	fmt.Fprintf(&b, "<h1>%s</h1>\n<p class=\"meta\">", html.EscapeString(rep.title))
+	if rep.author != "" {
+		fmt.Fprintf(&b, "%s: %s · ", t.Author, html.EscapeString(rep.author))
+	}
+	fmt.Fprintf(&b, "%s: %s · %s: %s</p>\n", t.Language, html.EscapeString(res.Language), t.Generated, rep.generated.Format("2006-01-02 15:04"))
+
+	fmt.Fprintf(&b, "<h2>1. %s</h2>\n<table class=\"source\"><tr><td class=\"num\"><pre>", t.Source)
+	for i := range rep.lines() {
+		fmt.Fprintf(&b, "%d\n", i+1)
+	}
+	fmt.Fprintf(&b, "</pre></td><td><pre>%s</pre></td></tr></table>\n", highlightCode(rep.code, res.Language, highlightHTML))
+
+	fmt.Fprintf(&b, "<h2>2. %s (%d)</h2>\n<table>\n<thead><tr><th>#</th><th>%s</th><th>%s</th><th>%s</th><th>%s</th></tr></thead>\n<tbody>\n",
+		t.Tokens, len(res.Tokens), t.Type, t.Value, t.Line, t.Column)
+	for i, tok := range res.Tokens {
+		fmt.Fprintf(&b, "<tr><td class=\"num\">%d</td><td>%s</td><td><code>%s</code></td><td class=\"num\">%d</td><td class=\"num\">%d</td></tr>\n",
+			i+1, tok.Type, html.EscapeString(tok.Value), tok.Line, tok.Column)
+	}
+	b.WriteString("</tbody>\n</table>\n")
+
+	fmt.Fprintf(&b, "<h2>3. %s</h2>\n", t.Tree)
+	if n := countNodes(rep.tree); n > reportTreeMaxNodes {
+		fmt.Fprintf(&b, "<p>%s</p>\n", fmt.Sprintf(t.TreeTooLarge, n))
+	} else {
+		fmt.Fprintf(&b, "<div class=\"tree\">%s</div>\n", treeSVG(rep.tree))
+	}
+
+	fmt.Fprintf(&b, "<h2>4. %s</h2>\n", t.Symbols)
+	if len(res.SymbolTable) == 0 {
+		fmt.Fprintf(&b, "<p>%s</p>\n", t.NoSymbols)
+	} else {
+		writeSymbolTable(&b, res.SymbolTable, rep.scopes, rep.symbolHeadings()[1:])
+	}
+
+	fmt.Fprintf(&b, "<h2>5. %s (%d)</h2>\n", t.Errors, len(res.Errors))
+	if len(res.Errors) == 0 {
+		fmt.Fprintf(&b, "<p>%s</p>\n", t.NoErrors)
+	} else {
+		fmt.Fprintf(&b, "<table>\n<thead><tr><th>%s</th><th>%s</th><th>%s</th><th>%s</th><th>%s</th><th>%s</th></tr></thead>\n<tbody>\n",
+			t.Line, t.Column, t.Phase, t.Severity, t.Code, t.Detail)
+		for _, e := range res.Errors {
+			fmt.Fprintf(&b, "<tr class=\"%s\"><td class=\"num\">%d</td><td class=\"num\">%d</td><td>%s</td><td>%s</td><td><code>%s</code></td><td>%s</td></tr>\n",
+				html.EscapeString(e.Severity), e.Line, e.Column, html.EscapeString(e.Type), html.EscapeString(e.Severity),
+				html.EscapeString(e.Code), html.EscapeString(e.Message))
+		}
+		b.WriteString("</tbody>\n</table>\n")
+	}
+
+	fmt.Fprintf(&b, "<h2>6. %s</h2>\n", t.Execution)
+	for _, line := range rep.executionSummary() {
+		fmt.Fprintf(&b, "<p>%s</p>\n", html.EscapeString(line))
+	}
+	if out := rep.executionOutput(); out != "" {
+		fmt.Fprintf(&b, "<pre class=\"output\">%s</pre>\n", html.EscapeString(out))
+	}
+	b.WriteString("</body>\n</html>\n")
+	return b.String()
+}
+
+// symbolHeadings son el título y las columnas de la tabla de símbolos en el
+// idioma del reporte (ver symbolexport.go)
+func (rep labReport) symbolHeadings() []string {
+	if headings, ok := symbolTableHeadings[rep.locale]; ok {
+		return headings
+	}
+	return symbolTableHeadings["es"]
+}
+
+// treeSVG dibuja el árbol con layoutTree
+func treeSVG(nodes []ParseNode) string {
+	boxes, columns, levels := layoutTree(nodes)
+	width := float64(columns) * treeSlotWidth
+	height := float64(levels-1)*treeLevelHeight + treeBoxHeight + 8
+	var b strings.Builder
+	fmt.Fprintf(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%.0f\" height=\"%.0f\" viewBox=\"0 0 %.0f %.0f\" font-family=\"Menlo,Consolas,monospace\" font-size=\"10\">\n",
+		width, height, width, height)
+	center := func(box treeBox) (float64, float64) {
+		return box.x*treeSlotWidth + treeSlotWidth/2, float64(box.depth)*treeLevelHeight + 4
+	}
+	for _, box := range boxes {
+		if box.parent < 0 {
+			continue
+		}
+		x, y := center(box)
+		px, py := center(boxes[box.parent])
+		fmt.Fprintf(&b, "<line x1=\"%.1f\" y1=\"%.1f\" x2=\"%.1f\" y2=\"%.1f\" stroke=\"#888\"/>\n", px, py+treeBoxHeight, x, y)
+	}
+	for _, box := range boxes {
+		x, y := center(box)
+		fmt.Fprintf(&b, "<rect x=\"%.1f\" y=\"%.1f\" width=\"%.0f\" height=\"%.0f\" rx=\"6\" fill=\"#f4f6fb\" stroke=\"#5b6b8c\"/>\n",
+			x-treeBoxWidth/2, y, treeBoxWidth, treeBoxHeight)
+		textY := y + treeBoxHeight/2 + 4
+		if box.label != "" {
+			textY = y + 14
+			fmt.Fprintf(&b, "<text x=\"%.1f\" y=\"%.1f\" text-anchor=\"middle\" fill=\"#555\">%s</text>\n", x, y+28, html.EscapeString(box.label))
+		}
+		fmt.Fprintf(&b, "<text x=\"%.1f\" y=\"%.1f\" text-anchor=\"middle\" font-weight=\"bold\">%s</text>\n", x, textY, html.EscapeString(box.kind))
+	}
+	b.WriteString("</svg>")
+	return b.String()
+}
+
+// executionSummary resume la ejecución, el veredicto y el puntaje, o por
+// qué no se ejecutó
+func (rep labReport) executionSummary() []string {
+	t, res := rep.text, rep.response
+	exec := res.ExecutionResult
+	if exec == nil {
+		lines := []string{t.NotExecuted}
+		return append(lines, res.Executability.Reasons...)
+	}
+	var lines []string
+	summary := fmt.Sprintf("%s: %d ms", t.Duration, exec.DurationMs)
+	if exec.ExitCode != nil {
+		summary = fmt.Sprintf("%s: %d · %s", t.ExitCode, *exec.ExitCode, summary)
+	}
+	lines = append(lines, summary)
+	// Error suele repetir lo último de la salida, que ya se muestra debajo
+	if exec.Error != "" && !strings.Contains(exec.Output, exec.Error) {
+		lines = append(lines, exec.Error)
+	}
+	if res.Judge != nil {
+		lines = append(lines, fmt.Sprintf("%s: %s %s", t.Verdict, res.Judge.Verdict, res.Judge.Message))
+	}
+	if tests := res.TestResults; tests != nil {
+		lines = append(lines, fmt.Sprintf("%s: %s · %s: %d / %d", t.Verdict, tests.Verdict, t.Score, tests.Score, tests.MaxScore))
+	}
+	return lines
+}
+
+// lines son las líneas del código; el salto de línea final no agrega una
+func (rep labReport) lines() []string {
+	return strings.Split(strings.TrimSuffix(rep.code, "\n"), "\n")
+}
+
+func (rep labReport) executionOutput() string {
+	if exec := rep.response.ExecutionResult; exec != nil {
+		return exec.Output
+	}
+	return ""
+}
+
+// ──────────────────────────────── PDF ────────────────────────────────────
+
+// Márgenes y texto de cuerpo del PDF: Courier, para que las tablas y el
+// código se alineen contando caracteres
+const (
+	reportMargin     = 50.0
+	reportFontSize   = 8.0
+	reportLineHeight = 10.5
+)
+
+// reportColumns es la cantidad de caracteres de Courier que caben en una
+// línea de texto
+var reportColumns = int(math.Floor(reportTextWidth / (pdfCourierWidth * reportFontSize)))
+
+const reportTextWidth = pdfPageWidth - 2*reportMargin
+
+// pdfFlow escribe de arriba hacia abajo y pasa a otra página al llegar al
+// margen inferior
+type pdfFlow struct {
+	doc pdfDocument
+	y   float64
+}
+
+func (f *pdfFlow) page() {
+	f.doc.newPage()
+	f.y = pdfPageHeight - reportMargin
+}
+
+// room pasa a otra página si no quedan h puntos
+func (f *pdfFlow) room(h float64) {
+	if f.y-h < reportMargin {
+		f.page()
+	}
+}
+
+func (f *pdfFlow) heading(s string) {
+	f.y -= 10
+	f.room(30)
+	f.y -= 14
+	f.doc.text(reportMargin, f.y, pdfHelveticaBold, 13, s)
+	f.doc.gray(0.7)
+	f.doc.line(reportMargin, f.y-4, pdfPageWidth-reportMargin, f.y-4)
+	f.y -= 14
+}
+
+// mono escribe las líneas en Courier; las que no caben se cortan y siguen
+// en la línea siguiente con sangría
+func (f *pdfFlow) mono(lines ...string) {
+	for _, line := range lines {
+		runes := []rune(strings.ReplaceAll(line, "\t", "    "))
+		indent := ""
+		for {
+			n := reportColumns - len(indent)
+			chunk := runes
+			if len(chunk) > n {
+				chunk = runes[:n]
+			}
+			f.room(reportLineHeight)
+			f.y -= reportLineHeight
+			f.doc.text(reportMargin, f.y, pdfCourier, reportFontSize, indent+string(chunk))
+			runes = runes[len(chunk):]
+			if len(runes) == 0 {
+				break
+			}
+			indent = "      "
+		}
+	}
+}
+
+// monoTable escribe filas con columnas de ancho fijo; la última ocupa el
+// resto de la línea
+func (f *pdfFlow) monoTable(widths []int, header []string, rows [][]string) {
+	row := func(cells []string) string {
+		var b strings.Builder
+		for i, cell := range cells {
+			cell = strings.NewReplacer("\n", `\n`, "\r", `\r`).Replace(cell)
+			if i == len(cells)-1 {
+				b.WriteString(cell)
+				break
+			}
+			cell = shortenRunes(cell, widths[i])
+			b.WriteString(cell + strings.Repeat(" ", widths[i]-len([]rune(cell))+1))
+		}
+		return b.String()
+	}
+	f.mono(row(header))
+	f.doc.gray(0.8)
+	f.doc.line(reportMargin, f.y-3, pdfPageWidth-reportMargin, f.y-3)
+	f.y -= 3
+	for _, cells := range rows {
+		f.mono(row(cells))
+	}
+}
+
+// pdf genera el documento con las mismas secciones que el HTML
+func (rep labReport) pdf() []byte {
+	t, res := rep.text, rep.response
+	var f pdfFlow
+	f.page()
+
+	f.y -= 18
+	f.doc.text(reportMargin, f.y, pdfHelveticaBold, 18, rep.title)
+	meta := fmt.Sprintf("%s: %s · %s: %s", t.Language, res.Language, t.Generated, rep.generated.Format("2006-01-02 15:04"))
+	if rep.author != "" {
+		meta = fmt.Sprintf("%s: %s · %s", t.Author, rep.author, meta)
+	}
+	f.y -= 16
+	f.doc.text(reportMargin, f.y, pdfHelvetica, 10, meta)
+	f.y -= 6
+
+	f.heading("1. " + t.Source)
+	lines := rep.lines()
+	width := len(strconv.Itoa(len(lines)))
+	for i, line := range lines {
+		f.mono(fmt.Sprintf("%*d  %s", width, i+1, strings.TrimRight(line, "\r")))
+	}
+
+	f.heading(fmt.Sprintf("2. %s (%d)", t.Tokens, len(res.Tokens)))
+	rows := make([][]string, len(res.Tokens))
+	for i, tok := range res.Tokens {
+		rows[i] = []string{strconv.Itoa(i + 1), fmt.Sprintf("%d:%d", tok.Line, tok.Column), tok.Type, tok.Value}
+	}
+	f.monoTable([]int{5, 13, 12}, []string{"#", t.Line + ":" + t.Column, t.Type, t.Value}, rows)
+
+	f.heading("3. " + t.Tree)
+	rep.pdfTree(&f)
+
+	f.heading("4. " + t.Symbols)
+	if len(res.SymbolTable) == 0 {
+		f.mono(t.NoSymbols)
+	} else {
+		rows = make([][]string, len(res.SymbolTable))
+		for i, s := range res.SymbolTable {
+			rows[i] = []string{s.Name, s.Category, s.Type, rep.scopes[i], fmt.Sprintf("%d:%d", s.Line, s.Column), symbolReferences(s)}
+		}
+		headings := rep.symbolHeadings()
+		f.monoTable([]int{16, 10, 12, 18, 13},
+			[]string{headings[1], headings[2], headings[3], headings[5], t.Line + ":" + t.Column, headings[9]}, rows)
+	}
+
+	f.heading(fmt.Sprintf("5. %s (%d)", t.Errors, len(res.Errors)))
+	if len(res.Errors) == 0 {
+		f.mono(t.NoErrors)
+	} else {
+		rows = make([][]string, len(res.Errors))
+		for i, e := range res.Errors {
+			rows[i] = []string{fmt.Sprintf("%d:%d", e.Line, e.Column), e.Severity, e.Code, e.Message}
+		}
+		f.monoTable([]int{13, 9, 7}, []string{t.Line + ":" + t.Column, t.Severity, t.Code, t.Detail}, rows)
+	}
+
+	f.heading("6. " + t.Execution)
+	f.mono(rep.executionSummary()...)
+	if out := rep.executionOutput(); out != "" {
+		f.y -= reportLineHeight / 2
+		f.mono(strings.Split(strings.TrimRight(out, "\n"), "\n")...)
+	}
+
+	// Pie de página con el número de cada una, ya sabiendo cuántas son
+	for i, page := range f.doc.pages {
+		f.doc.page = page
+		f.doc.color(0.4, 0.4, 0.4)
+		f.doc.text(reportMargin, reportMargin/2, pdfHelvetica, 8, fmt.Sprintf("%s · %s %d / %d", rep.title, t.Page, i+1, len(f.doc.pages)))
+	}
+	return f.doc.bytes()
+}
+
+// Escala mínima del dibujo del árbol en el PDF: el dibujo es vectorial y
+// se puede ampliar en el visor, pero si para caber en el ancho de la página
+// hay que achicarlo más se escribe como un esquema con sangría
+const reportTreeMinScale = 0.3
+
+// pdfTree dibuja el árbol en cajas o, si no cabe, lo escribe como esquema
+func (rep labReport) pdfTree(f *pdfFlow) {
+	n := countNodes(rep.tree)
+	if n > reportTreeMaxNodes {
+		f.mono(fmt.Sprintf(rep.text.TreeTooLarge, n))
+		return
+	}
+	boxes, columns, levels := layoutTree(rep.tree)
+	if len(boxes) == 0 {
+		return
+	}
+	scale := reportTextWidth / (float64(columns) * treeSlotWidth)
+	if scale > 1 {
+		scale = 1
+	}
+	height := (float64(levels-1)*treeLevelHeight + treeBoxHeight) * scale
+	if scale < reportTreeMinScale || height > pdfPageHeight-2*reportMargin {
+		var lines []string
+		walkTree(rep.tree, func(id, parent int, node ParseNode) {
+			kind, label := treeNodeText(node)
+			lines = append(lines, strings.Repeat("  ", boxes[id].depth)+strings.TrimSpace(kind+" "+label))
+		})
+		f.mono(lines...)
+		return
+	}
+
+	f.room(height + 8)
+	f.y -= 4
+	top := f.y
+	at := func(box treeBox) (float64, float64) {
+		return reportMargin + (box.x*treeSlotWidth+treeSlotWidth/2)*scale, top - float64(box.depth)*treeLevelHeight*scale
+	}
+	f.doc.lineWidth(0.6 * scale)
+	f.doc.gray(0.55)
+	for _, box := range boxes {
+		if box.parent >= 0 {
+			x, y := at(box)
+			px, py := at(boxes[box.parent])
+			f.doc.line(px, py-treeBoxHeight*scale, x, y)
+		}
+	}
+	size := 8 * scale
+	for _, box := range boxes {
+		x, y := at(box)
+		f.doc.gray(0.36)
+		f.doc.color(0.957, 0.965, 0.984)
+		f.doc.rect(x-treeBoxWidth/2*scale, y-treeBoxHeight*scale, treeBoxWidth*scale, treeBoxHeight*scale, true)
+		f.doc.color(0, 0, 0)
+		centered := func(s string, baseline float64) {
+			w := float64(len([]rune(s))) * pdfCourierWidth * size
+			f.doc.text(x-w/2, baseline, pdfCourier, size, s)
+		}
+		if box.label == "" {
+			centered(box.kind, y-treeBoxHeight/2*scale-size/3)
+			continue
+		}
+		centered(box.kind, y-14*scale)
+		f.doc.color(0.33, 0.33, 0.33)
+		centered(box.label, y-27*scale)
+	}
+	f.doc.color(0, 0, 0)
+	f.doc.lineWidth(1)
+	f.y = top - height - 4
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"path/filepath"
+	"sort"
+)
+
+// ─────────────────────────────────── SARIF ───────────────────────────────
+//
+// Los diagnósticos también se entregan en SARIF 2.1.0, el formato que
+// aceptan GitHub code scanning y los visores de VS Code y otros editores:
+// `analyze --sarif` en la línea de comandos (un resultado por diagnóstico de
+// todos los archivos) y errorsFormat "sarif" en /api/v1/analyze, que agrega
+// el documento en sarif.
+//
+// Cada código del catálogo (SEM004, LNT001...) es una regla con su nombre
+// legible y la fase (léxico, sintáctico, semántico) como etiqueta; un
+// diagnóstico sin código usa su fase como regla. error y warning conservan
+// su nivel y cualquier otra severidad es note. La declaración a la que se
+// refiere un error va en relatedLocations, y también las demás apariciones
+// de un diagnóstico agrupado con groupDiagnostics.
+
+const (
+	errorsFormatSARIF = "sarif"
+	sarifVersion      = "2.1.0"
+	sarifSchema       = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// Nivel de SARIF de cada severidad; las demás son "note"
+var sarifLevels = map[string]string{"error": "error", "warning": "warning"}
+
+type SARIFLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+	// Las columnas de la API cuentan caracteres, no bytes ni UTF-16
+	ColumnKind string `json:"columnKind"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name            string      `json:"name"`
+	SemanticVersion string      `json:"semanticVersion"`
+	Rules           []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string              `json:"id"`
+	Name                 string              `json:"name,omitempty"`
+	ShortDescription     *sarifMessage       `json:"shortDescription,omitempty"`
+	DefaultConfiguration sarifRuleLevel      `json:"defaultConfiguration"`
+	Properties           sarifRuleProperties `json:"properties"`
+}
+
+type sarifRuleLevel struct {
+	Level string `json:"level"`
+}
+
+type sarifRuleProperties struct {
+	Tags []string `json:"tags"`
+}
+
+type sarifResult struct {
+	RuleID           string            `json:"ruleId"`
+	RuleIndex        int               `json:"ruleIndex"`
+	Level            string            `json:"level"`
+	Message          sarifMessage      `json:"message"`
+	Locations        []sarifLocation   `json:"locations"`
+	RelatedLocations []sarifLocation   `json:"relatedLocations,omitempty"`
+	Properties       map[string]string `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	ID               *int                  `json:"id,omitempty"`
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifact `json:"artifactLocation"`
+	Region           sarifRegion   `json:"region"`
+}
+
+type sarifArtifact struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+}
+
+// sarifFile son los diagnósticos de un archivo; uri es su ruta relativa a
+// la raíz del repositorio
+type sarifFile struct {
+	uri    string
+	errors []APICompilerError
+}
+
+// validErrorsFormat indica si format es un formato de exportación conocido
+func validErrorsFormat(format string) bool {
+	return format == errorsFormatSARIF
+}
+
+// buildSARIF arma un run con los diagnósticos de files; las reglas son los
+// códigos que aparecen, ordenados
+func buildSARIF(files []sarifFile, locale string) *SARIFLog {
+	// La primera aparición de cada regla decide su nivel y su fase
+	first := map[string]APICompilerError{}
+	for _, f := range files {
+		for _, e := range f.errors {
+			if _, seen := first[sarifRuleID(e)]; !seen {
+				first[sarifRuleID(e)] = e
+			}
+		}
+	}
+	ids := make([]string, 0, len(first))
+	for id := range first {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	index := map[string]int{}
+	rules := make([]sarifRule, len(ids))
+	for i, id := range ids {
+		index[id] = i
+		e := first[id]
+		rules[i] = sarifRule{
+			ID:                   id,
+			DefaultConfiguration: sarifRuleLevel{Level: sarifLevel(e.Severity)},
+			Properties:           sarifRuleProperties{Tags: []string{e.Type}},
+		}
+		if info, ok := errorCatalog[id]; ok {
+			rules[i].Name = info.Name
+			rules[i].ShortDescription = &sarifMessage{Text: info.Name}
+		}
+	}
+
+	declared := map[string]string{"es": "declarada aquí", "en": "declared here"}[locale]
+	if declared == "" {
+		declared = "declarada aquí"
+	}
+	// Las demás apariciones de un diagnóstico agrupado (groupDiagnostics)
+	repeated := map[string]string{"es": "también aquí", "en": "also here"}[locale]
+	if repeated == "" {
+		repeated = "también aquí"
+	}
+	results := []sarifResult{}
+	for _, f := range files {
+		uri := filepath.ToSlash(f.uri)
+		for _, e := range f.errors {
+			id := sarifRuleID(e)
+			result := sarifResult{
+				RuleID:     id,
+				RuleIndex:  index[id],
+				Level:      sarifLevel(e.Severity),
+				Message:    sarifMessage{Text: e.Message},
+				Locations:  []sarifLocation{{PhysicalLocation: sarifPosition(uri, e.Line, e.Column)}},
+				Properties: map[string]string{"category": e.Type, "source": e.Source},
+			}
+			if e.Hint != "" {
+				result.Properties["hint"] = e.Hint
+			}
+			if d := e.Declaration; d != nil {
+				related := len(result.RelatedLocations) + 1
+				result.RelatedLocations = append(result.RelatedLocations, sarifLocation{
+					ID:               &related,
+					PhysicalLocation: sarifPosition(uri, d.Line, d.Column),
+					Message:          &sarifMessage{Text: declared},
+				})
+			}
+			for _, p := range e.RelatedPositions {
+				related := len(result.RelatedLocations) + 1
+				result.RelatedLocations = append(result.RelatedLocations, sarifLocation{
+					ID:               &related,
+					PhysicalLocation: sarifPosition(uri, p.Line, p.Column),
+					Message:          &sarifMessage{Text: repeated},
+				})
+			}
+			results = append(results, result)
+		}
+	}
+
+	return &SARIFLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool:       sarifTool{Driver: sarifDriver{Name: "compiler-backend", SemanticVersion: apiVersion, Rules: rules}},
+			Results:    results,
+			ColumnKind: "unicodeCodePoints",
+		}},
+	}
+}
+
+// sarifRuleID es el código del diagnóstico o, si no tiene, su fase
+func sarifRuleID(e APICompilerError) string {
+	if e.Code != "" {
+		return e.Code
+	}
+	return e.Type
+}
+
+func sarifLevel(severity string) string {
+	if level, ok := sarifLevels[severity]; ok {
+		return level
+	}
+	return "note"
+}
+
+// sarifPosition ubica line:column en uri; SARIF numera desde 1
+func sarifPosition(uri string, line, column int) sarifPhysicalLocation {
+	return sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifact{URI: uri},
+		Region:           sarifRegion{StartLine: max(line, 1), StartColumn: max(column, 1)},
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import "strings"
+
+// ───────────────────────── Declaraciones de Go ───────────────────────────
+//
+// En Go el nombre declarado no siempre sigue a una palabra clave (a, b := f(),
+// parámetros agrupados, campos de struct), por eso las declaraciones se
+// obtienen del árbol sintáctico en lugar de la secuencia de tokens.
+
+// registerDeclarations agrega a la tabla de símbolos las declaraciones del
+// árbol y devuelve las posiciones de los identificadores que declaran, para
+// que la pasada sobre los tokens no los cuente como usos. Go permite volver a
+// declarar un nombre en otro ámbito (err, i), así que solo se registra la
+// primera declaración y no se reportan redefiniciones.
+func (s *SemanticAnalyzer) registerDeclarations(declared map[string]int, syms *[]Symbol) map[int]bool {
+	positions := make(map[int]bool)
+	add := func(name, kind string, pos int) {
+		positions[pos] = true
+		if name == "" || name == "_" {
+			return
+		}
+		if _, exists := declared[name]; exists {
+			return
+		}
+		declared[name] = pos
+		*syms = append(*syms, Symbol{Name: name, Kind: kind, Pos: pos})
+	}
+
+	var walk func(n ParseNode, constant bool)
+	walk = func(n ParseNode, constant bool) {
+		switch n.Kind {
+		case "Package":
+			for _, c := range n.Children {
+				positions[c.Pos] = true
+			}
+			return
+		case "ImportName":
+			if len(n.Children) > 0 {
+				add(n.Children[0].Label, "package", n.Children[0].Pos)
+			} else {
+				add(n.Label[strings.LastIndex(n.Label, "/")+1:], "package", n.Pos)
+			}
+			return
+		case "DeclGroup":
+			constant = n.Label == "const"
+		case "VarDecl":
+			kind := "var"
+			if constant {
+				kind = "constant"
+			}
+			add(n.Label, kind, n.Pos)
+		case "TypeDecl":
+			add(n.Label, "type", n.Pos)
+		case "FunctionDecl", "Method":
+			if n.Label != "" {
+				kind := "function"
+				if n.Kind == "Method" {
+					kind = "method"
+				}
+				add(n.Label, kind, s.goNamePos(n))
+			}
+		case "MethodSpec":
+			add(n.Label, "method", n.Pos)
+		case "Param":
+			add(strings.TrimPrefix(n.Label, "..."), "parameter", n.Pos)
+		case "Field":
+			// Los campos incrustados (struct{ Base }) no declaran un nombre nuevo
+			if len(n.Children) == 0 || n.Children[0].Pos != n.Pos {
+				add(n.Label, "field", n.Pos)
+			}
+		case "Labeled":
+			add(n.Label, "label", n.Pos)
+		}
+		for _, c := range n.Children {
+			walk(c, constant)
+		}
+	}
+	for _, n := range s.tree {
+		walk(n, false)
+	}
+	return positions
+}
+
+// goNamePos ubica el nombre de una función o método, que en el nodo queda
+// después de 'func' y del receptor
+func (s *SemanticAnalyzer) goNamePos(fn ParseNode) int {
+	start := fn.Pos
+	if len(fn.Children) > 0 && fn.Children[0].Kind == "Receiver" {
+		start = fn.Children[0].End
+	}
+	for _, tk := range s.tokens {
+		if tk.Start >= start && tk.Lexeme == fn.Label {
+			return tk.Start
+		}
+	}
+	return fn.Pos
+}
+
+// goReportsUnused indica si un símbolo de Go sin usos merece la advertencia:
+// funciones, tipos y parámetros sin usar son habituales y válidos en Go
+func goReportsUnused(name, kind string) bool {
+	return (kind == "var" || kind == "constant") && name != "_"
+}
+
+// goDeclarations son las posiciones que devuelve registerDeclarations: los
+// nombres declarados ya están registrados y los selectores (fmt.Println,
+// p.X) no se resuelven en la tabla de símbolos
+type goDeclarations map[int]bool
+
+func (goDeclarations) declaresAt([]Token, int) bool { return false }
+
+func (d goDeclarations) countsUse(tokens []Token, i int, _ map[string]int) bool {
+	return !d[tokens[i].Start] && (i == 0 || tokens[i-1].Lexeme != ".")
+}
+
+func (goDeclarations) reportsUnused(name, kind string) bool { return goReportsUnused(name, kind) }
+
+func init() {
+	RegisterLanguage(&languageDef{
+		name:     "go",
+		patterns: LanguageSpecificPatterns["go"],
+		matchers: goOrder,
+		parse:    (*Parser).parseGoProgram,
+		register: func(s *SemanticAnalyzer, declared map[string]int, _ map[string][]int, syms *[]Symbol) declarationIndex {
+			return goDeclarations(s.registerDeclarations(declared, syms))
+		},
+		keywords: LanguageKeywords{
+			Builtins: map[string]bool{
+				"append": true, "cap": true, "clear": true, "close": true, "complex": true,
+				"copy": true, "delete": true, "imag": true, "len": true, "make": true,
+				"max": true, "min": true, "new": true, "panic": true, "print": true,
+				"println": true, "real": true, "recover": true,
+				"bool": true, "byte": true, "complex64": true, "complex128": true, "error": true,
+				"float32": true, "float64": true, "int": true, "int8": true, "int16": true,
+				"int32": true, "int64": true, "rune": true, "string": true, "uint": true,
+				"uint8": true, "uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+				"any": true, "comparable": true, "iota": true, "_": true,
+			},
+			Reserved: map[string]bool{
+				"break": true, "case": true, "chan": true, "const": true, "continue": true,
+				"default": true, "defer": true, "else": true, "fallthrough": true, "for": true,
+				"func": true, "go": true, "goto": true, "if": true, "import": true,
+				"interface": true, "map": true, "package": true, "range": true, "return": true,
+				"select": true, "struct": true, "switch": true, "type": true, "var": true,
+			},
+		},
+		execute: compileAndRunGo,
+		errors:  parseGoErrors,
+	})
+}
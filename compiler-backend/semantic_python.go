@@ -0,0 +1,262 @@
+package main
+
+// ─────────────────────── Declaraciones de Python ─────────────────────────
+//
+// En Python el nombre declarado casi nunca sigue a una palabra clave: las
+// asignaciones (a, b = 1, 2), los parámetros, las variables de un for o de
+// una comprensión y los nombres después de 'as' declaran sin más. Por eso,
+// como en Go y Kotlin, las declaraciones se registran antes de recorrer los
+// usos, y un nombre asignado de nuevo no es una redefinición.
+
+// pythonDeclarations son las posiciones de los identificadores que declaran
+// o que no son usos (argumentos con nombre, global x) y los nombres que no
+// merecen la advertencia de sin usar
+type pythonDeclarations struct {
+	positions map[int]bool
+	quiet     map[string]bool
+}
+
+func (pythonDeclarations) declaresAt([]Token, int) bool { return false }
+
+func (d pythonDeclarations) countsUse(tokens []Token, i int, _ map[string]int) bool {
+	return !d.positions[tokens[i].Start]
+}
+
+// Los parámetros, los métodos y las variables de un for, un except o un
+// with se pueden quedar sin usar: los métodos se llaman desde afuera y en
+// un for muchas veces solo importa la repetición
+func (d pythonDeclarations) reportsUnused(name, kind string) bool {
+	return kind != "import" && kind != "parameter" && !d.quiet[name] && name != "_"
+}
+
+// pythonScanner recorre los tokens significativos de un programa de Python
+// registrando sus declaraciones
+type pythonScanner struct {
+	toks  []Token
+	decls pythonDeclarations
+	// declare registra un símbolo; un nombre repetido se registra una vez
+	declare func(tk Token, kind string)
+}
+
+func (p *pythonScanner) is(i int, lexeme string) bool {
+	return i >= 0 && i < len(p.toks) && p.toks[i].Lexeme == lexeme
+}
+
+func (p *pythonScanner) isIdent(i int) bool {
+	return i >= 0 && i < len(p.toks) && p.toks[i].Type == IDENTIFIER
+}
+
+// skip marca un identificador que no declara ni usa
+func (p *pythonScanner) skip(tk Token) { p.decls.positions[tk.Start] = true }
+
+// registerPythonDeclarations agrega a la tabla de símbolos las importaciones
+// y las declaraciones de un programa de Python
+func (s *SemanticAnalyzer) registerPythonDeclarations(declared map[string]int, syms *[]Symbol) pythonDeclarations {
+	p := &pythonScanner{
+		toks:  significantTokens(s.tokens),
+		decls: pythonDeclarations{positions: map[int]bool{}, quiet: map[string]bool{}},
+	}
+	p.declare = func(tk Token, kind string) {
+		p.skip(tk)
+		if tk.Lexeme == "_" {
+			return
+		}
+		if _, exists := declared[tk.Lexeme]; exists {
+			return
+		}
+		declared[tk.Lexeme] = tk.Start
+		*syms = append(*syms, Symbol{Name: tk.Lexeme, Kind: kind, Pos: tk.Start})
+	}
+	for _, imp := range collectImports(s.tokens, s.language) {
+		for _, pos := range imp.names {
+			p.decls.positions[pos] = true
+		}
+		for _, name := range imp.bound {
+			p.declare(name, "import")
+		}
+	}
+	p.scan()
+	return p.decls
+}
+
+func (p *pythonScanner) scan() {
+	depth := 0
+	// Columnas de los 'class' cuyo cuerpo incluye la sentencia actual: un
+	// def más adentro es un método
+	var classes []int
+	// Primer token del destino de la próxima asignación: el comienzo de la
+	// sentencia o lo que sigue al '=' anterior (a = b = 0)
+	target := 0
+	for i, tk := range p.toks {
+		if depth == 0 && (i == 0 || tk.Line != p.toks[i-1].Line || p.is(i-1, ";")) {
+			target = i
+			for len(classes) > 0 && tk.Column <= classes[len(classes)-1] {
+				classes = classes[:len(classes)-1]
+			}
+		}
+		switch tk.Lexeme {
+		case "(", "[", "{":
+			depth++
+			continue
+		case ")", "]", "}":
+			if depth > 0 {
+				depth--
+			}
+			continue
+		case "=":
+			p.assignment(i, depth, target)
+			if depth == 0 {
+				target = i + 1
+			}
+			continue
+		}
+		if tk.Type != KEYWORD {
+			continue
+		}
+		switch tk.Lexeme {
+		case "class":
+			if p.isIdent(i + 1) {
+				p.declare(p.toks[i+1], "class")
+				classes = append(classes, tk.Column)
+			}
+		case "def":
+			if !p.isIdent(i + 1) {
+				continue
+			}
+			name := p.toks[i+1]
+			p.declare(name, "function")
+			if len(classes) > 0 && tk.Column > classes[len(classes)-1] {
+				p.decls.quiet[name.Lexeme] = true
+			}
+			if p.is(i+2, "(") {
+				p.parameters(i + 2)
+			}
+		case "lambda":
+			p.lambdaParameters(i + 1)
+		case "for":
+			p.loopVariables(i + 1)
+		case "as":
+			// except E as e, with open(f) as archivo; los alias de import ya
+			// están registrados
+			if p.isIdent(i+1) && !p.decls.positions[p.toks[i+1].Start] {
+				p.declare(p.toks[i+1], "var")
+				p.decls.quiet[p.toks[i+1].Lexeme] = true
+			}
+		case "global", "nonlocal":
+			for j := i + 1; p.isIdent(j) && p.toks[j].Line == tk.Line; j += 2 {
+				p.skip(p.toks[j])
+				if !p.is(j+1, ",") {
+					break
+				}
+			}
+		}
+	}
+}
+
+// assignment procesa el '=' de toks[eq]: dentro de paréntesis es un
+// argumento con nombre (print(x, end="")), pegado a un operador es una
+// asignación compuesta (x += 1, que usa x) o := y si no declara los nombres
+// de toks[target:eq]
+func (p *pythonScanner) assignment(eq, depth, target int) {
+	if eq == 0 {
+		return
+	}
+	prev := p.toks[eq-1]
+	if prev.End == p.toks[eq].Start && (prev.Type == OPERATOR || prev.Lexeme == ":") {
+		if prev.Lexeme == ":" && p.isIdent(eq-2) {
+			p.declare(p.toks[eq-2], "var")
+		}
+		return
+	}
+	if depth > 0 {
+		if prev.Type == IDENTIFIER && (p.is(eq-2, "(") || p.is(eq-2, ",")) {
+			p.skip(prev)
+		}
+		return
+	}
+	for j := target; j < eq; j++ {
+		tk := p.toks[j]
+		if tk.Type != IDENTIFIER {
+			continue
+		}
+		// a[i] = x, f(x).y = z: todo lo que está entre los corchetes o
+		// paréntesis son usos
+		if p.is(j+1, "[") || p.is(j+1, "(") {
+			j = p.closing(j + 1)
+			continue
+		}
+		// obj.attr = x usa obj; x: int = 1 usa int
+		if p.is(j+1, ".") || j > target && (p.is(j-1, ".") || p.is(j-1, ":")) {
+			continue
+		}
+		p.declare(tk, "var")
+	}
+}
+
+// closing devuelve el índice del token que cierra el que abre toks[open]
+func (p *pythonScanner) closing(open int) int {
+	depth := 0
+	for j := open; j < len(p.toks); j++ {
+		switch p.toks[j].Lexeme {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			if depth--; depth == 0 {
+				return j
+			}
+		}
+	}
+	return len(p.toks)
+}
+
+// parameters declara los parámetros de la lista que abre toks[open]: los
+// nombres al comienzo de cada uno (a, *args, **kw), no sus anotaciones ni
+// sus valores por defecto
+func (p *pythonScanner) parameters(open int) {
+	end := p.closing(open)
+	depth := 0
+	for j := open; j < end; j++ {
+		switch p.toks[j].Lexeme {
+		case "(", "[", "{":
+			depth++
+			continue
+		case ")", "]", "}":
+			depth--
+			continue
+		}
+		if depth == 1 && p.isIdent(j) && (p.is(j-1, "(") || p.is(j-1, ",") || p.is(j-1, "*") || p.is(j-1, "**")) {
+			p.declare(p.toks[j], "parameter")
+		}
+	}
+}
+
+// lambdaParameters declara los parámetros de una lambda, hasta su ':'
+func (p *pythonScanner) lambdaParameters(from int) {
+	for j := from; j < len(p.toks) && !p.is(j, ":"); j++ {
+		if p.isIdent(j) && (j == from || p.is(j-1, ",") || p.is(j-1, "*") || p.is(j-1, "**")) {
+			p.declare(p.toks[j], "parameter")
+		}
+	}
+}
+
+// loopVariables declara las variables de un for o de una comprensión, hasta
+// su 'in': for i, (j, k) in pares
+func (p *pythonScanner) loopVariables(from int) {
+	var names []Token
+	for j := from; j < len(p.toks); j++ {
+		tk := p.toks[j]
+		switch {
+		case tk.Lexeme == "in":
+			for _, name := range names {
+				p.declare(name, "var")
+				p.decls.quiet[name.Lexeme] = true
+			}
+			return
+		case tk.Type == IDENTIFIER:
+			names = append(names, tk)
+		case tk.Lexeme != "(" && tk.Lexeme != ")" && tk.Lexeme != "[" && tk.Lexeme != "]" && tk.Lexeme != ",":
+			// for self.i in ...: el destino no es un nombre
+			return
+		}
+	}
+}
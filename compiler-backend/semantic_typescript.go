@@ -0,0 +1,350 @@
+package main
+
+import "strings"
+
+// ────────────────────── Declaraciones de TypeScript ──────────────────────
+//
+// En TypeScript el nombre declarado no siempre sigue a let/const/function
+// (parámetros con tipo, interfaces, enums, imports con nombre), y los tipos
+// mencionan nombres que no son variables (T, Record, string). Como en Go,
+// las declaraciones se obtienen del árbol sintáctico; además se devuelven los
+// rangos de las anotaciones de tipo, donde un nombre solo cuenta como uso si
+// está declarado en el programa.
+
+// tsDeclarations resume lo que la pasada sobre los tokens necesita del árbol
+type tsDeclarations struct {
+	positions map[int]bool    // identificadores que declaran o nombran miembros
+	types     [][2]int        // rangos [inicio, fin) de anotaciones y declaraciones de tipos
+	external  map[string]bool // exportados o ambientales (declare): se usan fuera del archivo
+}
+
+// inType indica si la posición está dentro de una anotación de tipo
+func (d tsDeclarations) inType(pos int) bool {
+	for _, r := range d.types {
+		if pos >= r[0] && pos < r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// registerTSDeclarations agrega a la tabla de símbolos las declaraciones del
+// árbol. Como en Go, un nombre declarado de nuevo en otro ámbito (i, err) se
+// registra una sola vez y no se reportan redefiniciones: tsc lo hace con las
+// reglas de ámbito completas.
+func (s *SemanticAnalyzer) registerTSDeclarations(declared map[string]int, syms *[]Symbol) tsDeclarations {
+	decls := tsDeclarations{positions: make(map[int]bool), external: make(map[string]bool)}
+	add := func(name, kind string, pos int) {
+		decls.positions[pos] = true
+		if name == "" {
+			return
+		}
+		if _, exists := declared[name]; exists {
+			return
+		}
+		declared[name] = pos
+		*syms = append(*syms, Symbol{Name: name, Kind: kind, Pos: pos})
+	}
+	// skip marca el nombre de un miembro o una clave, que no es un uso
+	skip := func(name string, start int) {
+		decls.positions[s.tsNamePos(start, name)] = true
+	}
+
+	var walk func(n ParseNode)
+	// target registra los nombres de un destino: x, { a, b: c }, [x, ...y]
+	var target func(n ParseNode, kind string)
+	target = func(n ParseNode, kind string) {
+		switch n.Kind {
+		case "Identifier":
+			add(n.Label, kind, n.Pos)
+		case "ObjectPattern":
+			for _, prop := range n.Children {
+				switch {
+				case prop.Kind != "Property":
+					target(prop, kind)
+				case len(prop.Children) > 0 && s.tsFollowedBy(prop.Pos, prop.Label, ":"):
+					// { clave: nombre }
+					skip(prop.Label, prop.Pos)
+					target(prop.Children[0], kind)
+				default:
+					add(prop.Label, kind, s.tsNamePos(prop.Pos, prop.Label))
+					for _, c := range prop.Children {
+						walk(c)
+					}
+				}
+			}
+		case "ArrayPattern":
+			for _, c := range n.Children {
+				target(c, kind)
+			}
+		case "Spread":
+			for _, c := range n.Children {
+				target(c, kind)
+			}
+		case "Assign":
+			// Valor por defecto dentro de un patrón: [x = 1]
+			target(n.Children[0], kind)
+			walk(n.Children[1])
+		}
+	}
+
+	walk = func(n ParseNode) {
+		switch n.Kind {
+		case "TypeAnnotation", "TypeParams", "TypeArgs", "Implements":
+			decls.types = append(decls.types, [2]int{n.Pos, n.End})
+			return
+		case "InterfaceDecl":
+			// Los miembros se recorren para no contar sus nombres como usos
+			add(n.Label, "interface", s.tsNamePos(n.Pos, n.Label))
+			decls.types = append(decls.types, [2]int{n.Pos, n.End})
+		case "TypeAlias":
+			add(n.Label, "type", s.tsNamePos(n.Pos, n.Label))
+			decls.types = append(decls.types, [2]int{n.Pos, n.End})
+			return
+		case "IndexSignature":
+			decls.types = append(decls.types, [2]int{n.Pos, n.End})
+			return
+		case "EnumDecl":
+			add(n.Label, "enum", s.tsNamePos(n.Pos, n.Label))
+		case "Enumerator":
+			skip(n.Label, n.Pos)
+		case "Namespace":
+			name := n.Label
+			if i := strings.Index(name, "."); i >= 0 {
+				name = name[:i]
+			}
+			if !strings.HasPrefix(name, `"`) && !strings.HasPrefix(name, "'") {
+				add(name, "namespace", s.tsNamePos(n.Pos, name))
+			}
+		case "Import":
+			s.tsImportNames(n, add)
+			return
+		case "Export", "Declare":
+			if n.Label == "default" {
+				skip("default", n.Pos)
+			}
+			for _, c := range n.Children {
+				decls.external[c.Label] = true
+				for _, d := range c.Children {
+					if c.Kind == "DeclGroup" {
+						decls.external[d.Label] = true
+					}
+				}
+			}
+			// export { a as b }: b es el nombre exportado, no un uso
+			for i, tk := range s.tokens {
+				if len(n.Children) > 0 {
+					break
+				}
+				if tk.Start > n.Pos && tk.Start < n.End && s.tokens[i-1].Lexeme == "as" {
+					decls.positions[tk.Start] = true
+				}
+			}
+		case "VarDecl":
+			kind := "var"
+			if len(n.Children) > 0 && n.Children[0].Label == "const" {
+				kind = "constant"
+			}
+			for _, c := range n.Children {
+				switch c.Kind {
+				case "Type":
+				case "ObjectPattern", "ArrayPattern":
+					target(c, kind)
+				default:
+					walk(c)
+				}
+			}
+			if n.Label != "{}" && n.Label != "[]" {
+				add(n.Label, kind, s.tsNamePos(n.Pos, n.Label))
+			}
+			return
+		case "FunctionDecl", "ClassDecl":
+			kind := "function"
+			if n.Kind == "ClassDecl" {
+				kind = "class"
+			}
+			if n.Label != "" {
+				add(n.Label, kind, s.tsNamePos(n.Pos, n.Label))
+			}
+		case "Method", "Constructor", "Field", "PropertySignature", "MethodSignature":
+			skip(n.Label, n.Pos)
+		case "Param":
+			name := strings.TrimPrefix(n.Label, "...")
+			if len(n.Children) > 0 && (n.Children[0].Kind == "ObjectPattern" || n.Children[0].Kind == "ArrayPattern") {
+				target(n.Children[0], "parameter")
+				for _, c := range n.Children[1:] {
+					walk(c)
+				}
+				return
+			}
+			if name != "this" {
+				add(name, "parameter", s.tsNamePos(n.Pos, name))
+			}
+		case "Catch":
+			if len(n.Children) > 1 {
+				target(n.Children[0], "parameter")
+			}
+		case "Property":
+			// Clave de un objeto literal: { clave: valor }
+			if s.tsFollowedBy(n.Pos, n.Label, ":") {
+				skip(n.Label, n.Pos)
+			}
+		}
+		for _, c := range n.Children {
+			walk(c)
+		}
+	}
+	for _, n := range s.tree {
+		walk(n)
+	}
+	return decls
+}
+
+// tsImportNames registra los nombres locales de un import: el predeterminado,
+// el espacio de nombres (* as x) y los importados con nombre ({ a, b as c }).
+// El nombre original antes de 'as' pertenece al módulo y no se registra.
+func (s *SemanticAnalyzer) tsImportNames(n ParseNode, add func(name, kind string, pos int)) {
+	for i, tk := range s.tokens {
+		if tk.Start < n.Pos || tk.Start >= n.End || tk.Type != IDENTIFIER {
+			continue
+		}
+		if i+1 < len(s.tokens) && s.tokens[i+1].Lexeme == "as" {
+			add("", "", tk.Start)
+			continue
+		}
+		add(tk.Lexeme, "import", tk.Start)
+	}
+}
+
+// tsNamePos ubica el primer token con el nombre dado desde start
+func (s *SemanticAnalyzer) tsNamePos(start int, name string) int {
+	for _, tk := range s.tokens {
+		if tk.Start >= start && tk.Lexeme == name {
+			return tk.Start
+		}
+	}
+	return start
+}
+
+// tsFollowedBy indica si el nombre que aparece desde start va seguido del
+// lexema next (la clave de { a: b } frente al atajo { a })
+func (s *SemanticAnalyzer) tsFollowedBy(start int, name, next string) bool {
+	for i, tk := range s.tokens {
+		if tk.Start >= start && tk.Lexeme == name {
+			return i+1 < len(s.tokens) && s.tokens[i+1].Lexeme == next
+		}
+	}
+	return false
+}
+
+// reportsUnused indica si un símbolo de TypeScript sin usos merece la
+// advertencia: los parámetros, imports y tipos sin usar son habituales
+// (firmas de callbacks) y lo exportado se usa desde otros módulos
+func (d tsDeclarations) reportsUnused(name, kind string) bool {
+	return (kind == "var" || kind == "constant" || kind == "function") && !d.external[name]
+}
+
+// semanticLanguage es el lenguaje con el que se hacen el chequeo de tipos y
+// el análisis de flujo: TypeScript usa las reglas de JavaScript y deja las
+// anotaciones a tsc
+func semanticLanguage(lang string) string {
+	if lang == "typescript" {
+		return "javascript"
+	}
+	return lang
+}
+
+// Globales del entorno de tsc (lib.es2020 y Node) además de los de
+// JavaScript
+var tsGlobals = []string{
+	"Promise", "Map", "Set", "WeakMap", "WeakSet", "Symbol", "BigInt", "Error",
+	"TypeError", "RangeError", "RegExp", "Reflect", "Proxy", "Intl", "globalThis",
+	"process", "require", "module", "exports", "Buffer", "structuredClone",
+	"encodeURIComponent", "decodeURIComponent", "queueMicrotask",
+}
+
+func (tsDeclarations) declaresAt([]Token, int) bool { return false }
+
+// countsUse: en un tipo solo cuentan los nombres del programa (User), no los
+// parámetros de tipo ni los tipos globales (T, Record)
+func (d tsDeclarations) countsUse(tokens []Token, i int, declared map[string]int) bool {
+	_, isDeclared := declared[tokens[i].Lexeme]
+	return !d.positions[tokens[i].Start] && (i == 0 || tokens[i-1].Lexeme != ".") &&
+		(isDeclared || !d.inType(tokens[i].Start))
+}
+
+// jsNodeBuiltins son los predefinidos de JavaScript más los globales de
+// lib.es2020 y Node, que node también define
+func jsNodeBuiltins() map[string]bool {
+	builtins := map[string]bool{}
+	for name := range jsBuiltins {
+		builtins[name] = true
+	}
+	for _, name := range tsGlobals {
+		builtins[name] = true
+	}
+	return builtins
+}
+
+// registerJSDeclarations registra las declaraciones de JavaScript o
+// TypeScript, que comparten el árbol sintáctico, y los usos dentro de los
+// template literals
+func (s *SemanticAnalyzer) registerJSDeclarations(declared map[string]int, used map[string][]int, syms *[]Symbol) tsDeclarations {
+	decls := s.registerTSDeclarations(declared, syms)
+	s.jsTemplateUses(s.tokens, decls, declared, used)
+	return decls
+}
+
+// jsTemplateUses anota los usos de las expresiones interpoladas en los
+// template literals de tokens (`Total: ${total(x)}`), que el lexer entrega
+// como una sola cadena. Las interpolaciones pueden tener otros templates
+func (s *SemanticAnalyzer) jsTemplateUses(tokens []Token, decls tsDeclarations, declared map[string]int, used map[string][]int) {
+	for _, tk := range tokens {
+		if tk.Type != STRING || !strings.HasPrefix(tk.Lexeme, "`") {
+			continue
+		}
+		for i := 1; i < len(tk.Lexeme); i++ {
+			switch {
+			case tk.Lexeme[i] == '\\':
+				i++
+			case strings.HasPrefix(tk.Lexeme[i:], "${"):
+				end := jsInterpolationEnd(tk.Lexeme, i+2)
+				if end < 0 {
+					return
+				}
+				// Las posiciones de los tokens internos son las del código
+				line, col := advanceLineColumn(tk.Line, tk.Column, tk.Lexeme[:i+2])
+				inner := significantTokens(tokenizeFrom(tk.Lexeme[:end], s.language, i+2, line, col, nil))
+				for j := range inner {
+					inner[j].Start += tk.Start
+					inner[j].End += tk.Start
+				}
+				for j, t := range inner {
+					// Las claves de un objeto ({ a: 1 }) no están en el árbol
+					key := j > 0 && j+1 < len(inner) && inner[j+1].Lexeme == ":" &&
+						(inner[j-1].Lexeme == "{" || inner[j-1].Lexeme == ",")
+					if t.Type == IDENTIFIER && !key && decls.countsUse(inner, j, declared) {
+						used[t.Lexeme] = append(used[t.Lexeme], t.Start)
+					}
+				}
+				s.jsTemplateUses(inner, decls, declared, used)
+				i = end
+			}
+		}
+	}
+}
+
+func init() {
+	RegisterLanguage(&languageDef{
+		name:     "typescript",
+		patterns: LanguageSpecificPatterns["typescript"],
+		matchers: jsOrder,
+		parse:    (*Parser).parseCProgram,
+		register: func(s *SemanticAnalyzer, declared map[string]int, used map[string][]int, syms *[]Symbol) declarationIndex {
+			return s.registerJSDeclarations(declared, used, syms)
+		},
+		keywords: LanguageKeywords{Builtins: jsNodeBuiltins(), Reserved: jsReserved},
+		execute:  compileAndRunTS,
+		errors:   parseTypeScriptErrors,
+	})
+}
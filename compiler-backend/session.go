@@ -0,0 +1,367 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// ──────────────────────────────── Sesiones ────────────────────────────────
+//
+// Una sesión guarda el último análisis de un documento del editor para que
+// cada cambio se reanalice de forma incremental (ver AnalysisSnapshot) en
+// lugar de tokenizar y analizar el archivo completo en cada tecla.
+//
+//   POST   /api/v1/sessions           crea la sesión y analiza el código
+//   PATCH  /api/v1/sessions/{id}/code aplica el código nuevo o ediciones
+//   DELETE /api/v1/sessions/{id}      descarta la sesión
+
+// SessionCodeRequest actualiza el código de una sesión: el texto completo en
+// Code o una lista de Edits aplicadas en orden sobre la versión anterior
+type SessionCodeRequest struct {
+	Code           *string       `json:"code,omitempty"`
+	Edits          []SessionEdit `json:"edits,omitempty"`
+	TimeoutSeconds int           `json:"timeoutSeconds,omitempty"`
+	Execute        *bool         `json:"execute,omitempty"`
+}
+
+// SessionEdit reemplaza el texto entre Start y End (posiciones en caracteres,
+// como el campo position de tokens y errores) por Text
+type SessionEdit struct {
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Text  string `json:"text"`
+}
+
+// Respuesta de las rutas de sesión: el análisis completo más el id
+type APISessionResponse struct {
+	SessionID string `json:"sessionId"`
+	APIAnalyzeResponse
+}
+
+type analysisSession struct {
+	mu       sync.Mutex // serializa los cambios de un mismo documento
+	snapshot AnalysisSnapshot
+	lastUsed time.Time // protegido por sessionStore.mu
+	// Diagnósticos, severidades, presupuesto de errores, entrada del
+	// programa, modo juez e idioma de la petición que creó la sesión; se
+	// aplican a todos los cambios
+	diagnostics map[string]bool
+	severities  map[string]string
+	maxErrors   int
+	args        []string
+	env         map[string]string
+	files       []ProgramFile
+	judge       *JudgeOptions
+	stdin       string
+	testCases   []TestCase
+	profile     *AssignmentProfile
+	locale      string
+	// Usuario que creó la sesión: sus cambios se cargan a su cuota y solo él
+	// puede enviarlos; nil si la sesión es anónima
+	principal *Principal
+}
+
+type sessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]*analysisSession
+}
+
+var sessions = &sessionStore{sessions: make(map[string]*analysisSession)}
+
+// create registra una sesión nueva, descartando antes las vencidas y, si se
+// alcanzó GlobalConfig.MaxSessions, la usada hace más tiempo
+func (st *sessionStore) create() (string, *analysisSession, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", nil, err
+	}
+	id := hex.EncodeToString(buf)
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	now := time.Now()
+	oldestID := ""
+	for sid, s := range st.sessions {
+		if now.Sub(s.lastUsed) > GlobalConfig.SessionTTL {
+			delete(st.sessions, sid)
+		} else if oldestID == "" || s.lastUsed.Before(st.sessions[oldestID].lastUsed) {
+			oldestID = sid
+		}
+	}
+	if len(st.sessions) >= GlobalConfig.MaxSessions && oldestID != "" {
+		delete(st.sessions, oldestID)
+	}
+	s := &analysisSession{lastUsed: now}
+	st.sessions[id] = s
+	return id, s, nil
+}
+
+// get devuelve la sesión si existe y no venció, renovando su uso
+func (st *sessionStore) get(id string) *analysisSession {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	s, ok := st.sessions[id]
+	if !ok {
+		return nil
+	}
+	if time.Since(s.lastUsed) > GlobalConfig.SessionTTL {
+		delete(st.sessions, id)
+		return nil
+	}
+	s.lastUsed = time.Now()
+	return s
+}
+
+func (st *sessionStore) remove(id string) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	_, ok := st.sessions[id]
+	delete(st.sessions, id)
+	return ok
+}
+
+// applyEdit aplica una edición con posiciones en caracteres sobre code
+func applyEdit(code string, e SessionEdit) (string, error) {
+	if e.Start < 0 || e.End < e.Start {
+		return "", errors.New("invalid edit range")
+	}
+	start, end := -1, -1
+	n := 0
+	for i := range code {
+		if n == e.Start {
+			start = i
+		}
+		if n == e.End {
+			end = i
+			break
+		}
+		n++
+	}
+	total := utf8.RuneCountInString(code)
+	if e.Start == total {
+		start = len(code)
+	}
+	if e.End == total {
+		end = len(code)
+	}
+	if start < 0 || end < 0 {
+		return "", errors.New("invalid edit range")
+	}
+	return code[:start] + e.Text + code[end:], nil
+}
+
+// sessionsHandler crea una sesión a partir de un AnalyzeRequest
+func sessionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req AnalyzeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w)
+		return
+	}
+	rid, msg := requestID(r)
+	if msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if executions.active(rid) {
+		httpError(w, "An execution with this request id is already running", http.StatusConflict)
+		return
+	}
+	w.Header().Set(requestIDHeader, rid)
+	if req.Code == "" {
+		httpError(w, "Code is required", http.StatusBadRequest)
+		return
+	}
+	if req.TimeoutSeconds < 0 {
+		httpError(w, "timeoutSeconds must be positive", http.StatusBadRequest)
+		return
+	}
+	if name := unknownDiagnostic(req.Diagnostics); name != "" {
+		httpError(w, "diagnostics: unknown diagnostic "+name, http.StatusBadRequest)
+		return
+	}
+	if msg := invalidSeverityOverride(req.SeverityOverrides); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if req.MaxErrors < 0 {
+		httpError(w, "maxErrors must be positive", http.StatusBadRequest)
+		return
+	}
+	if msg := invalidArgs(req.Args); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if msg := invalidEnv(req.Env); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if msg := invalidFiles(req.Files); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if msg := req.invalidJudgeRequest(principalFrom(r)); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if msg := invalidLocale(req.Locale); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	// El lenguaje queda fijo durante toda la sesión
+	language := mapLanguage(req.Language)
+	if language == "" {
+		language = DetectLanguage(req.Code)
+	}
+	principal := principalFrom(r)
+	if status, msg := authorizeAnalysis(principal, language, req.Execute == nil || *req.Execute); status != 0 {
+		rejectAnalysis(w, status, msg)
+		return
+	}
+	id, session, err := sessions.create()
+	if err != nil {
+		httpError(w, "Could not create session", http.StatusInternalServerError)
+		return
+	}
+
+	session.mu.Lock()
+	opts := req.options(principal)
+	opts.Snapshot = &session.snapshot
+	session.diagnostics = opts.Diagnostics
+	session.severities = opts.SeverityOverrides
+	session.maxErrors = opts.MaxErrors
+	session.args = opts.Args
+	session.env = opts.Env
+	session.files = opts.Files
+	session.judge = opts.Judge
+	session.stdin = opts.Stdin
+	session.testCases = opts.TestCases
+	session.profile = opts.Profile
+	session.locale = requestLocale(req.Locale, r)
+	session.principal = principal
+	opts.RequestID = rid
+	result := AnalyzeCodeWithProgress(req.Code, language, opts, nil)
+	session.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(APISessionResponse{
+		SessionID:          id,
+		APIAnalyzeResponse: buildAPIResponse(result, newSourceIndex(req.Code), session.locale),
+	})
+}
+
+// sessionHandler atiende /api/v1/sessions/{id} y /api/v1/sessions/{id}/code
+func sessionHandler(w http.ResponseWriter, r *http.Request) {
+	id, sub, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, apiPrefix+"/sessions/"), "/")
+	switch {
+	case sub == "" && r.Method == http.MethodDelete:
+		if !sessions.remove(id) {
+			httpError(w, "Session not found", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case sub == "code" && r.Method == http.MethodPatch:
+		updateSessionCode(w, r, id)
+	case sub == "" || sub == "code":
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		httpError(w, "Not found", http.StatusNotFound)
+	}
+}
+
+func updateSessionCode(w http.ResponseWriter, r *http.Request, id string) {
+	session := sessions.get(id)
+	if session == nil {
+		httpError(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	var req SessionCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w)
+		return
+	}
+	rid, msg := requestID(r)
+	if msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if executions.active(rid) {
+		httpError(w, "An execution with this request id is already running", http.StatusConflict)
+		return
+	}
+	w.Header().Set(requestIDHeader, rid)
+	if req.Code == nil && len(req.Edits) == 0 {
+		httpError(w, "code or edits is required", http.StatusBadRequest)
+		return
+	}
+	if req.TimeoutSeconds < 0 {
+		httpError(w, "timeoutSeconds must be positive", http.StatusBadRequest)
+		return
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if p := principalFrom(r); session.principal != nil && (p == nil || p.Account != session.principal.Account) {
+		httpError(w, "Session belongs to another user", http.StatusForbidden)
+		return
+	}
+	if req.Execute == nil || *req.Execute {
+		if status, msg := authorizeAnalysis(session.principal, session.snapshot.language, true); status != 0 {
+			rejectAnalysis(w, status, msg)
+			return
+		}
+	}
+
+	code := session.snapshot.code
+	if req.Code != nil {
+		code = *req.Code
+	}
+	for _, e := range req.Edits {
+		var err error
+		if code, err = applyEdit(code, e); err != nil {
+			httpError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	// Las ediciones pueden hacer crecer el código más allá del límite
+	if msg := codeTooLarge(code); msg != "" {
+		writeAPIError(w, http.StatusRequestEntityTooLarge, "code_too_large", msg)
+		return
+	}
+
+	opts := AnalyzeRequest{TimeoutSeconds: req.TimeoutSeconds, Execute: req.Execute}.options(session.principal)
+	opts.Snapshot = &session.snapshot
+	opts.Diagnostics = session.diagnostics
+	opts.SeverityOverrides = session.severities
+	opts.MaxErrors = session.maxErrors
+	opts.Args = session.args
+	opts.Env = session.env
+	opts.Files = session.files
+	opts.RequestID = rid
+	opts.Judge = session.judge
+	opts.Stdin = session.stdin
+	opts.TestCases = session.testCases
+	opts.Profile = session.profile
+	result := AnalyzeCodeWithProgress(code, session.snapshot.language, opts, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(APISessionResponse{
+		SessionID:          id,
+		APIAnalyzeResponse: buildAPIResponse(result, newSourceIndex(code), session.locale),
+	})
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ─────────────────────────── Enlaces compartidos ─────────────────────────
+//
+// En lugar de una captura de pantalla, el estudiante comparte un enlace
+// con su análisis: POST /api/v1/share recibe el mismo cuerpo que
+// /api/v1/analyze, lo analiza (casi siempre sale de la caché, porque el
+// estudiante acaba de enviarlo) y guarda el código con su resultado bajo un
+// id corto. Quien tenga el enlace lo ve sin credenciales:
+//
+//   GET    /api/v1/share/{id}              el análisis en JSON
+//   GET    /api/v1/share/{id}?format=html  el reporte de laboratorio
+//   GET    /api/v1/share/{id}?format=pdf   (ver report.go)
+//   DELETE /api/v1/share/{id}              lo retira quien lo compartió
+//
+// Como el servidor hace el análisis, el resultado compartido no se puede
+// editar a mano. Los enlaces se guardan en memoria, vencen a los SHARE_TTL
+// segundos y, si hay MAX_SHARES, el que vence primero deja lugar al nuevo.
+
+// Alfabeto de los ids: sin 0/o ni 1/l/i, que se confunden al dictarlos
+const shareAlphabet = "23456789abcdefghjkmnpqrstuvwxyz"
+
+// Largo del id: 10 caracteres de 31 son unos 50 bits, suficiente para que
+// no se pueda recorrer
+const shareIDLength = 10
+
+// APIShare es un análisis compartido; url es relativa al servidor
+type APIShare struct {
+	ID        string             `json:"id"`
+	URL       string             `json:"url"`
+	Title     string             `json:"title,omitempty"`
+	User      string             `json:"user,omitempty"`
+	Code      string             `json:"code"`
+	CreatedAt time.Time          `json:"createdAt"`
+	ExpiresAt time.Time          `json:"expiresAt"`
+	Result    APIAnalyzeResponse `json:"result"`
+}
+
+type share struct {
+	id    string
+	title string
+	user  string
+	code  string
+	// Para el reporte: el árbol y los alcances que la API no incluye
+	response APIAnalyzeResponse
+	tree     []ParseNode
+	scopes   []string
+	locale   string
+	created  time.Time
+	expires  time.Time
+	// Quien lo compartió: solo él lo retira; "" si es anónimo
+	account string
+}
+
+func (s *share) api() APIShare {
+	return APIShare{ID: s.id, URL: apiPrefix + "/share/" + s.id, Title: s.title, User: s.user, Code: s.code,
+		CreatedAt: s.created.UTC(), ExpiresAt: s.expires.UTC(), Result: s.response}
+}
+
+type shareStore struct {
+	mu     sync.Mutex
+	shares map[string]*share
+}
+
+var shares = &shareStore{shares: make(map[string]*share)}
+
+// newShareID devuelve un id corto de shareAlphabet
+func newShareID() (string, error) {
+	buf := make([]byte, shareIDLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i, b := range buf {
+		// 256 no es múltiplo de 31: el sesgo es menor al 1% por carácter
+		buf[i] = shareAlphabet[int(b)%len(shareAlphabet)]
+	}
+	return string(buf), nil
+}
+
+// add registra s con un id nuevo, descartando antes los vencidos y, si se
+// alcanzó GlobalConfig.MaxShares, el que vence primero
+func (st *shareStore) add(s *share) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	for {
+		id, err := newShareID()
+		if err != nil {
+			return err
+		}
+		if _, taken := st.shares[id]; !taken {
+			s.id = id
+			break
+		}
+	}
+	now := time.Now()
+	soonest := ""
+	for id, old := range st.shares {
+		if now.After(old.expires) {
+			delete(st.shares, id)
+		} else if soonest == "" || old.expires.Before(st.shares[soonest].expires) {
+			soonest = id
+		}
+	}
+	if len(st.shares) >= GlobalConfig.MaxShares && soonest != "" {
+		delete(st.shares, soonest)
+	}
+	st.shares[s.id] = s
+	return nil
+}
+
+// get devuelve el análisis compartido si existe y no venció
+func (st *shareStore) get(id string) *share {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	s, ok := st.shares[id]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(s.expires) {
+		delete(st.shares, id)
+		return nil
+	}
+	return s
+}
+
+// remove retira el análisis id de account; found es false si no existe y
+// owned si existe pero es de otro usuario. Los anónimos solo vencen
+func (st *shareStore) remove(id, account string) (found, owned bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	s, ok := st.shares[id]
+	if !ok || time.Now().After(s.expires) {
+		return false, false
+	}
+	if s.account == "" || s.account != account {
+		return true, false
+	}
+	delete(st.shares, id)
+	return true, true
+}
+
+// createShareHandler atiende POST /api/v1/share; title y ttlSeconds van en
+// la query string
+func createShareHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req AnalyzeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w)
+		return
+	}
+	ttl := GlobalConfig.ShareTTL
+	if v := r.URL.Query().Get("ttlSeconds"); v != "" {
+		seconds, err := strconv.Atoi(v)
+		if err != nil || seconds <= 0 {
+			httpError(w, "ttlSeconds must be positive", http.StatusBadRequest)
+			return
+		}
+		if d := time.Duration(seconds) * time.Second; d < ttl {
+			ttl = d
+		}
+	}
+	id, msg := requestID(r)
+	if msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if executions.active(id) {
+		httpError(w, "An execution with this request id is already running", http.StatusConflict)
+		return
+	}
+	w.Header().Set(requestIDHeader, id)
+	if req.Code == "" {
+		httpError(w, "Code is required", http.StatusBadRequest)
+		return
+	}
+	if req.TimeoutSeconds < 0 {
+		httpError(w, "timeoutSeconds must be positive", http.StatusBadRequest)
+		return
+	}
+	if name := unknownDiagnostic(req.Diagnostics); name != "" {
+		httpError(w, "diagnostics: unknown diagnostic "+name, http.StatusBadRequest)
+		return
+	}
+	if msg := invalidSeverityOverride(req.SeverityOverrides); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if req.MaxErrors < 0 {
+		httpError(w, "maxErrors must be positive", http.StatusBadRequest)
+		return
+	}
+	if msg := invalidArgs(req.Args); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if msg := invalidEnv(req.Env); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if msg := invalidFiles(req.Files); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if msg := req.invalidJudgeRequest(principalFrom(r)); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if msg := invalidLocale(req.Locale); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	req.Locale = requestLocale(req.Locale, r)
+	principal := principalFrom(r)
+	if status, msg := req.authorize(principal); status != 0 {
+		rejectAnalysis(w, status, msg)
+		return
+	}
+
+	opts := req.options(principal)
+	opts.RequestID = id
+	result := AnalyzeCodeCached(req.Code, mapLanguage(req.Language), opts)
+	recordAnalysis(req.Code, result, principal)
+
+	now := time.Now()
+	s := &share{
+		title:    r.URL.Query().Get("title"),
+		code:     req.Code,
+		response: buildAPIResponse(result, newSourceIndex(req.Code), req.Locale),
+		tree:     result.ParseTree,
+		scopes:   symbolScopes(result.SymbolTable, result.ParseTree),
+		locale:   req.Locale,
+		created:  now,
+		expires:  now.Add(ttl),
+	}
+	if principal != nil {
+		s.user, s.account = principal.User, principal.Account
+	}
+	if err := shares.add(s); err != nil {
+		httpError(w, "Could not create share", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", s.api().URL)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(s.api())
+}
+
+// shareHandler atiende /api/v1/share/{id}: sin credenciales, el id basta
+// para verlo
+func shareHandler(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, apiPrefix+"/share/")
+	if id == "" || strings.Contains(id, "/") {
+		httpError(w, "Not found", http.StatusNotFound)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodDelete:
+		// Retirarlo sí requiere saber quién lo pide
+		requireAuth(func(w http.ResponseWriter, r *http.Request) {
+			found, owned := shares.remove(id, previewAccount(r))
+			switch {
+			case !found:
+				httpError(w, "Share not found", http.StatusNotFound)
+			case !owned:
+				httpError(w, "Share belongs to another user", http.StatusForbidden)
+			default:
+				w.WriteHeader(http.StatusNoContent)
+			}
+		})(w, r)
+		return
+	default:
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s := shares.get(id)
+	if s == nil {
+		httpError(w, "Share not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.api())
+		return
+	}
+	if _, ok := reportContentTypes[format]; !ok {
+		httpError(w, "format must be html or pdf", http.StatusBadRequest)
+		return
+	}
+
+	text, ok := reportTexts[s.locale]
+	if !ok {
+		text = reportTexts["es"]
+	}
+	rep := labReport{
+		title:     s.title,
+		author:    s.user,
+		locale:    s.locale,
+		generated: s.created,
+		code:      s.code,
+		response:  s.response,
+		tree:      s.tree,
+		scopes:    s.scopes,
+		text:      text,
+	}
+	if rep.title == "" {
+		rep.title = text.Title
+	}
+	w.Header().Set("Content-Type", reportContentTypes[format])
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"reporte-%s.%s\"", s.id, format))
+	if format == reportFormatPDF {
+		w.Write(rep.pdf())
+		return
+	}
+	w.Write([]byte(rep.html()))
+}
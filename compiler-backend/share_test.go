@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testShares deja una lista de enlaces vacía en shares mientras dura el test
+func testShares(t *testing.T, ttl time.Duration, max int) {
+	t.Helper()
+	saved, savedTTL, savedMax := shares, GlobalConfig.ShareTTL, GlobalConfig.MaxShares
+	shares = &shareStore{shares: make(map[string]*share)}
+	GlobalConfig.ShareTTL, GlobalConfig.MaxShares = ttl, max
+	t.Cleanup(func() { shares, GlobalConfig.ShareTTL, GlobalConfig.MaxShares = saved, savedTTL, savedMax })
+}
+
+// TestShareLifecycle comprueba que cualquiera con el id ve el análisis
+// compartido y que solo quien lo compartió lo retira
+func TestShareLifecycle(t *testing.T) {
+	store := testAuthStore(t)
+	testShares(t, time.Hour, 10)
+	anaKey, _, err := store.createKey("ana", 0, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	betoKey, _, err := store.createKey("beto", 0, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := `{"code": "x = 1\nprint(x)\n", "language": "python", "execute": false}`
+	r := httptest.NewRequest(http.MethodPost, apiPrefix+"/share?title=Tarea+1&ttlSeconds=60", strings.NewReader(body))
+	r.Header.Set("X-API-Key", anaKey)
+	w := httptest.NewRecorder()
+	requireAuth(createShareHandler)(w, r)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("estado %d: %s", w.Code, w.Body.String())
+	}
+	var created APIShare
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	if ttl := created.ExpiresAt.Sub(created.CreatedAt); ttl != time.Minute {
+		t.Errorf("vence a los %v, esperado 1m", ttl)
+	}
+
+	steps := []struct {
+		name   string
+		method string
+		key    string
+		status int
+	}{
+		{"ver_sin_credenciales", http.MethodGet, "", http.StatusOK},
+		{"retirar_anonimo", http.MethodDelete, "", http.StatusForbidden},
+		{"retirar_otro_usuario", http.MethodDelete, betoKey, http.StatusForbidden},
+		{"ver_todavia", http.MethodGet, "", http.StatusOK},
+		{"retirar_propio", http.MethodDelete, anaKey, http.StatusNoContent},
+		{"ver_retirado", http.MethodGet, "", http.StatusNotFound},
+	}
+	for _, s := range steps {
+		r := httptest.NewRequest(s.method, created.URL, nil)
+		if s.key != "" {
+			r.Header.Set("X-API-Key", s.key)
+		}
+		w := httptest.NewRecorder()
+		shareHandler(w, r)
+		if w.Code != s.status {
+			t.Fatalf("%s: estado %d, esperado %d: %s", s.name, w.Code, s.status, w.Body.String())
+		}
+		if s.method == http.MethodGet && s.status == http.StatusOK {
+			var got APIShare
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Code != "x = 1\nprint(x)\n" || got.Title != "Tarea 1" || got.User != "ana" {
+				t.Errorf("%s: %+v", s.name, got)
+			}
+		}
+	}
+}
+
+// TestShareExpiry comprueba que ttlSeconds no alarga SHARE_TTL, que un
+// enlace vencido deja de verse y que con MAX_SHARES lleno se descarta el
+// que vence primero
+func TestShareExpiry(t *testing.T) {
+	testShares(t, time.Hour, 2)
+
+	cases := []struct {
+		name   string
+		query  string
+		status int
+		ttl    time.Duration
+	}{
+		{"por_defecto", "", http.StatusCreated, time.Hour},
+		{"mas_corto", "?ttlSeconds=30", http.StatusCreated, 30 * time.Second},
+		{"mas_largo_que_share_ttl", "?ttlSeconds=86400", http.StatusCreated, time.Hour},
+		{"cero", "?ttlSeconds=0", http.StatusBadRequest, 0},
+		{"no_numerico", "?ttlSeconds=mucho", http.StatusBadRequest, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			body := `{"code": "print(1)\n", "language": "python", "execute": false}`
+			w := httptest.NewRecorder()
+			createShareHandler(w, httptest.NewRequest(http.MethodPost, apiPrefix+"/share"+c.query, strings.NewReader(body)))
+			if w.Code != c.status {
+				t.Fatalf("estado %d, esperado %d: %s", w.Code, c.status, w.Body.String())
+			}
+			if c.status != http.StatusCreated {
+				return
+			}
+			var created APIShare
+			if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+				t.Fatal(err)
+			}
+			if ttl := created.ExpiresAt.Sub(created.CreatedAt); ttl != c.ttl {
+				t.Errorf("vence a los %v, esperado %v", ttl, c.ttl)
+			}
+		})
+	}
+
+	now := time.Now()
+	shares = &shareStore{shares: make(map[string]*share)}
+	expired := &share{code: "vencido", created: now.Add(-2 * time.Hour), expires: now.Add(-time.Hour)}
+	soon := &share{code: "pronto", created: now, expires: now.Add(time.Minute)}
+	later := &share{code: "despues", created: now, expires: now.Add(time.Hour)}
+	for _, s := range []*share{expired, soon, later} {
+		if err := shares.add(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w := httptest.NewRecorder()
+	shareHandler(w, httptest.NewRequest(http.MethodGet, apiPrefix+"/share/"+expired.id, nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("vencido: estado %d, esperado 404", w.Code)
+	}
+	newest := &share{code: "nuevo", created: now, expires: now.Add(time.Hour)}
+	if err := shares.add(newest); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		s    *share
+		kept bool
+	}{{soon, false}, {later, true}, {newest, true}} {
+		if kept := shares.get(c.s.id) != nil; kept != c.kept {
+			t.Errorf("%s conservado: %v, esperado %v", c.s.code, kept, c.kept)
+		}
+	}
+}
@@ -0,0 +1,788 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ───────────────────────────────── SQL ───────────────────────────────────
+//
+// Dos dialectos: T-SQL (SQL Server, "tsql") y PL/SQL (Oracle, "plsql").
+// Las palabras clave no distinguen mayúsculas; los nombres pueden ir entre
+// corchetes ([mi tabla]) o comillas dobles, y las variables empiezan con @
+// en T-SQL o con ':' las variables de enlace de PL/SQL.
+//
+// El parser (parser_sql.go) arma el árbol por cláusulas; el análisis
+// semántico registra tablas, vistas, procedimientos y variables.
+
+// ───────────────────────────────── Lexer ─────────────────────────────────
+
+var sqlPatterns = struct {
+	String, Number, Identifier, Variable *regexp.Regexp
+}{
+	// 'O''Brien': la comilla se escapa duplicándola; N'...' es Unicode
+	String: regexp.MustCompile(`^[Nn]?'(?:[^']|'')*'`),
+	// Sin el punto final de otros lenguajes: 1..10 es un rango de PL/SQL
+	Number: regexp.MustCompile(`^(?:\d+(?:\.\d+)?|\.\d+)(?:[eE][+-]?\d+)?`),
+	// [nombre], "nombre", #temporal, ##global y los nombres de Oracle con $ y #
+	Identifier: regexp.MustCompile(`^(?:\[[^\]\n]*\]|"[^"\n]*"|#{1,2}[\p{L}_][\p{L}\p{N}_$#]*|[\p{L}_][\p{L}\p{N}_$#]*)`),
+	// @variable, @@ROWCOUNT y :enlace (pero no ':=')
+	Variable: regexp.MustCompile(`^(?:@@?[\p{L}_][\p{L}\p{N}_$#]*|:[\p{L}_][\p{L}\p{N}_]*)`),
+}
+
+func sqlString(_ *LanguagePatterns, s string, p int) (TokenType, string) {
+	if lex, ok := matchHere(sqlPatterns.String, s, p); ok {
+		return STRING, lex
+	}
+	return UNKNOWN, ""
+}
+
+func sqlNumber(_ *LanguagePatterns, s string, p int) (TokenType, string) {
+	if lex, ok := matchHere(sqlPatterns.Number, s, p); ok {
+		return NUMBER, lex
+	}
+	return UNKNOWN, ""
+}
+
+func sqlIdent(_ *LanguagePatterns, s string, p int) (TokenType, string) {
+	if lex, ok := matchHere(sqlPatterns.Identifier, s, p); ok {
+		return IDENTIFIER, lex
+	}
+	return UNKNOWN, ""
+}
+
+func sqlVariable(_ *LanguagePatterns, s string, p int) (TokenType, string) {
+	if lex, ok := matchHere(sqlPatterns.Variable, s, p); ok {
+		return VARIABLE, lex
+	}
+	return UNKNOWN, ""
+}
+
+// Las cadenas van antes que los nombres: N'texto' no es el nombre N
+var sqlOrder = []matcher{whitespace, comment, sqlString, sqlNumber, sqlVariable, keyword, sqlIdent, oper, delim}
+
+func init() {
+	// Los dos dialectos comparten lexer, gramática y análisis; las
+	// diferencias se consultan con s.language
+	for _, name := range []string{"tsql", "plsql"} {
+		RegisterLanguage(&languageDef{
+			name:     name,
+			patterns: LanguageSpecificPatterns[name],
+			matchers: sqlOrder,
+			parse:    (*Parser).parseSQLProgram,
+			analyze:  (*SemanticAnalyzer).analyzeSQL,
+		})
+	}
+}
+
+// ─────────────────────────────── Semántica ───────────────────────────────
+
+// analyzeSQL registra las declaraciones del script según el dialecto y
+// después valida las tablas y columnas de las consultas contra el esquema
+// que declaran sus CREATE TABLE
+func (s *SemanticAnalyzer) analyzeSQL() ([]Symbol, []CompilerError) {
+	var syms []Symbol
+	var errors []CompilerError
+	schema := make(sqlSchema)
+	if s.language == "plsql" {
+		s.registerPLSQLDeclarations(&syms, &errors, schema)
+	} else {
+		s.registerTSQLDeclarations(&syms, &errors, schema)
+	}
+	s.checkSQLReferences(schema, &syms, &errors)
+	for i := range syms {
+		sort.Ints(syms[i].References)
+	}
+	sort.SliceStable(errors, func(i, j int) bool { return errors[i].Pos < errors[j].Pos })
+	return syms, errors
+}
+
+// registerSQLObject registra una tabla, vista o procedimiento del árbol. Las
+// columnas de CREATE TABLE quedan en el esquema y como símbolos
+// 'tabla.columna' con su tipo.
+func registerSQLObject(n ParseNode, syms *[]Symbol, errors *[]CompilerError, schema sqlSchema) {
+	kind := map[string]string{
+		"CreateTable": "table", "CreateView": "view", "Procedure": "procedure", "Function": "function",
+	}[n.Kind]
+	if kind == "" || n.Label == "" {
+		return
+	}
+	sym := Symbol{Name: n.Label, Kind: kind, Pos: n.Pos}
+	for _, c := range n.Children {
+		if c.Kind == "Returns" && len(c.Children) > 0 {
+			sym.Type = c.Children[0].Label
+		}
+	}
+	*syms = append(*syms, sym)
+	if kind != "table" && kind != "view" {
+		return
+	}
+	table := &sqlTable{name: n.Label, sym: len(*syms) - 1}
+	schema[sqlBaseName(n.Label)] = table
+	if kind == "view" {
+		// Las columnas de una vista dependen de su consulta: no se validan
+		return
+	}
+	table.columns = make(map[string]int)
+	for _, c := range n.Children {
+		if c.Kind != "ColumnDef" || c.Label == "" {
+			continue
+		}
+		key := sqlBaseName(c.Label)
+		if prev, ok := table.columns[key]; ok {
+			*errors = append(*errors, CompilerError{
+				Message:  fmt.Sprintf("Error semántico: La columna '%s' ya fue declarada en la tabla '%s' en posición %d", c.Label, n.Label, (*syms)[prev].Pos),
+				Severity: "error",
+				Type:     "semantico",
+				Pos:      c.Pos,
+				Code:     CodeRedeclaredVariable,
+			})
+			continue
+		}
+		table.columns[key] = len(*syms)
+		table.order = append(table.order, sqlUnquote(c.Label))
+		*syms = append(*syms, Symbol{Name: n.Label + "." + c.Label, Kind: "column", Type: sqlTypeOf(c), Pos: c.Pos})
+	}
+}
+
+// sqlTypeOf es el tipo declarado en el primer hijo Type del nodo
+func sqlTypeOf(n ParseNode) string {
+	for _, c := range n.Children {
+		if c.Kind == "Type" {
+			return c.Label
+		}
+	}
+	return ""
+}
+
+// registerTSQLDeclarations registra los objetos y las variables de T-SQL.
+// Las variables (@x) viven hasta el fin del lote (GO): usar una sin
+// declararla en el lote es un error, y declararla dos veces también.
+func (s *SemanticAnalyzer) registerTSQLDeclarations(syms *[]Symbol, errors *[]CompilerError, schema sqlSchema) {
+	report := func(pos int, severity, code, format string, args ...any) {
+		*errors = append(*errors, CompilerError{
+			Message:  "Error semántico: " + fmt.Sprintf(format, args...),
+			Severity: severity,
+			Type:     "semantico",
+			Pos:      pos,
+			Code:     code,
+		})
+	}
+	scope := make(map[string]int) // @variable en minúsculas → posición en syms
+	var batch []int               // variables declaradas en el lote actual
+	endBatch := func() {
+		for _, i := range batch {
+			if sym := (*syms)[i]; sym.Kind == "variable" && len(sym.References) == 0 {
+				report(sym.Pos, "warning", CodeUnusedVariable, "Variable '%s' fue declarada pero nunca utilizada", sym.Name)
+			}
+		}
+		scope, batch = make(map[string]int), nil
+	}
+	use := func(name string, pos int) {
+		if strings.HasPrefix(name, "@@") {
+			// Variables del sistema: @@ROWCOUNT, @@ERROR, @@IDENTITY...
+			return
+		}
+		if i, ok := scope[strings.ToLower(name)]; ok {
+			(*syms)[i].References = append((*syms)[i].References, pos)
+			return
+		}
+		report(pos, "error", CodeUndeclaredVariable, "Variable '%s' no fue declarada en este lote", name)
+	}
+
+	var walk func(n ParseNode)
+	walk = func(n ParseNode) {
+		switch n.Kind {
+		case "BatchSeparator":
+			endBatch()
+			return
+		case "VarDecl", "Param":
+			// El valor inicial se evalúa antes de que exista la variable
+			for _, c := range n.Children {
+				walk(c)
+			}
+			key := strings.ToLower(n.Label)
+			if prev, ok := scope[key]; ok {
+				report(n.Pos, "error", CodeRedeclaredVariable, "Variable '%s' ya fue declarada anteriormente en posición %d", n.Label, (*syms)[prev].Pos)
+				return
+			}
+			kind := "variable"
+			if n.Kind == "Param" {
+				kind = "parameter"
+			}
+			scope[key] = len(*syms)
+			batch = append(batch, len(*syms))
+			*syms = append(*syms, Symbol{Name: n.Label, Kind: kind, Type: sqlTypeOf(n), Pos: n.Pos})
+			return
+		case "Variable":
+			use(n.Label, n.Pos)
+		case "Assign":
+			if strings.HasPrefix(n.Label, "@") {
+				use(n.Label, n.Pos)
+			}
+		default:
+			registerSQLObject(n, syms, errors, schema)
+		}
+		for _, c := range n.Children {
+			walk(c)
+		}
+	}
+	for _, n := range s.tree {
+		walk(n)
+	}
+	endBatch()
+}
+
+// registerPLSQLDeclarations registra los objetos y las variables de PL/SQL.
+// Cada procedimiento, función o bloque DECLARE es el alcance de sus
+// declaraciones.
+func (s *SemanticAnalyzer) registerPLSQLDeclarations(syms *[]Symbol, errors *[]CompilerError, schema sqlSchema) {
+	var walk func(n ParseNode)
+	walk = func(n ParseNode) {
+		registerSQLObject(n, syms, errors, schema)
+		switch n.Kind {
+		case "Procedure", "Function", "Declare":
+			plsqlScope(n, syms, errors)
+		}
+		for _, c := range n.Children {
+			walk(c)
+		}
+	}
+	for _, n := range s.tree {
+		walk(n)
+	}
+}
+
+// plsqlScope registra los parámetros y variables declarados en el bloque n.
+// Los nombres de las consultas pueden ser columnas o variables, así que no
+// se reportan nombres sin declarar: solo variables que nada menciona.
+func plsqlScope(n ParseNode, syms *[]Symbol, errors *[]CompilerError) {
+	declared := make(map[string]int) // nombre en minúsculas → posición en syms
+	declare := func(d ParseNode, kind string) {
+		key := strings.ToLower(d.Label)
+		if prev, ok := declared[key]; ok {
+			*errors = append(*errors, CompilerError{
+				Message:  fmt.Sprintf("Error semántico: Variable '%s' ya fue declarada anteriormente en posición %d", d.Label, (*syms)[prev].Pos),
+				Severity: "error",
+				Type:     "semantico",
+				Pos:      d.Pos,
+				Code:     CodeRedeclaredVariable,
+			})
+			return
+		}
+		declared[key] = len(*syms)
+		*syms = append(*syms, Symbol{Name: d.Label, Kind: kind, Type: sqlTypeOf(d), Pos: d.Pos})
+	}
+	for _, c := range n.Children {
+		switch c.Kind {
+		case "Params":
+			for _, p := range c.Children {
+				declare(p, "parameter")
+			}
+		case "VarDecl":
+			declare(c, "variable")
+		}
+	}
+	plsqlReferences(n, declared, *syms)
+	for _, i := range declared {
+		if sym := (*syms)[i]; sym.Kind == "variable" && len(sym.References) == 0 {
+			*errors = append(*errors, CompilerError{
+				Message:  fmt.Sprintf("Error semántico: Variable '%s' fue declarada pero nunca utilizada", sym.Name),
+				Severity: "warning",
+				Type:     "semantico",
+				Pos:      sym.Pos,
+				Code:     CodeUnusedVariable,
+			})
+		}
+	}
+}
+
+// plsqlReferences agrega a los símbolos declarados cada nombre del bloque
+// que los menciona: columnas (v_total, rec.campo), asignaciones y destinos
+// de SELECT INTO
+func plsqlReferences(n ParseNode, declared map[string]int, syms []Symbol) {
+	for _, c := range n.Children {
+		switch c.Kind {
+		case "Column", "Assign", "Table", "Variable":
+			name := strings.ToLower(strings.TrimPrefix(c.Label, ":"))
+			if dot := strings.IndexByte(name, '.'); dot >= 0 {
+				name = name[:dot]
+			}
+			if i, ok := declared[name]; ok {
+				syms[i].References = append(syms[i].References, c.Pos)
+			}
+		}
+		plsqlReferences(c, declared, syms)
+	}
+}
+
+// ──────────────────────────────── Esquema ────────────────────────────────
+//
+// El esquema son las tablas que declara el propio script. Las consultas se
+// validan contra él: una tabla que no existe pero se parece a una declarada
+// es un error de tipeo, y lo mismo una columna que no está en su tabla. Si
+// el script no declara tablas se supone que trabaja sobre una base existente
+// y no se valida nada.
+
+// sqlTable es una tabla del esquema; columns es nil si sus columnas no se
+// conocen (vistas, SELECT INTO, expresiones WITH)
+type sqlTable struct {
+	name    string
+	sym     int            // posición en la tabla de símbolos; -1 si no tiene
+	columns map[string]int // columna en minúsculas → posición en syms
+	order   []string       // columnas en orden de declaración
+}
+
+// sqlSchema indexa las tablas por su nombre sin esquema ni corchetes, en
+// minúsculas: dbo.[Clientes] y clientes son la misma tabla
+type sqlSchema map[string]*sqlTable
+
+func sqlUnquote(name string) string {
+	return strings.Trim(name, `[]"`)
+}
+
+// sqlBaseName es el nombre de un objeto sin su esquema, sin corchetes ni
+// comillas y en minúsculas
+func sqlBaseName(name string) string {
+	if dot := strings.LastIndexByte(name, '.'); dot >= 0 {
+		name = name[dot+1:]
+	}
+	return strings.ToLower(sqlUnquote(name))
+}
+
+// Tablas del sistema que ningún script declara
+func sqlSystemTable(name string) bool {
+	low := strings.ToLower(name)
+	return strings.HasPrefix(low, "#") || strings.HasPrefix(low, "sys.") ||
+		strings.HasPrefix(low, "information_schema.") || low == "dual" ||
+		strings.HasPrefix(low, "all_") || strings.HasPrefix(low, "user_") || strings.HasPrefix(low, "dba_")
+}
+
+// Nombres que parecen columnas pero son valores del sistema
+var sqlPseudoColumns = makeSet(strings.Fields(`
+	sysdate systimestamp rownum rowid level user current_date current_timestamp
+	current_user session_user system_user localtimestamp sqlcode sqlerrm
+`))
+
+// Funciones cuyo primer argumento es una palabra (DATEADD(day, ...)) o un
+// tipo (CONVERT(INT, ...)) y no una columna
+var sqlKeywordArgument = makeSet(strings.Fields(`
+	DATEADD DATEDIFF DATEDIFF_BIG DATEPART DATENAME DATETRUNC DATE_BUCKET EXTRACT CONVERT TRY_CONVERT
+`))
+
+// sqlSuggestion devuelve el nombre de candidates más parecido a name, o ""
+// si ninguno está a dos cambios o menos
+func sqlSuggestion(name string, candidates []string) string {
+	best, bestDist := "", 3
+	low := strings.ToLower(sqlUnquote(name))
+	for _, c := range candidates {
+		d := editDistance(low, strings.ToLower(c))
+		if d < bestDist && d < len(low) {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
+
+// sqlSource es una tabla del FROM con su alias; table es nil si sus columnas
+// no se conocen (subconsultas, tablas externas, variables de tabla)
+type sqlSource struct {
+	name, alias string
+	table       *sqlTable
+}
+
+// sqlChecker valida las referencias de las consultas contra el esquema
+type sqlChecker struct {
+	schema sqlSchema
+	syms   *[]Symbol
+	errors *[]CompilerError
+	// Nombres que pueden aparecer en una consulta sin ser columnas: las
+	// variables y parámetros de PL/SQL
+	variables map[string]bool
+}
+
+// checkSQLReferences valida las tablas y columnas de SELECT, INSERT, UPDATE
+// y DELETE
+func (s *SemanticAnalyzer) checkSQLReferences(schema sqlSchema, syms *[]Symbol, errors *[]CompilerError) {
+	declared := false
+	for _, t := range schema {
+		declared = declared || t.columns != nil
+	}
+	if !declared {
+		return
+	}
+	c := &sqlChecker{schema: schema, syms: syms, errors: errors, variables: make(map[string]bool)}
+	for _, sym := range *syms {
+		if sym.Kind == "variable" || sym.Kind == "parameter" {
+			c.variables[strings.ToLower(sym.Name)] = true
+		}
+	}
+	// Las tablas creadas por la consulta misma (SELECT INTO en T-SQL, WITH)
+	// existen aunque no se conozcan sus columnas
+	var collect func(n ParseNode)
+	collect = func(n ParseNode) {
+		switch {
+		case n.Kind == "CommonTable":
+			c.addTable(n.Label)
+		case n.Kind == "Into" && s.language == "tsql":
+			for _, t := range n.Children {
+				if t.Kind == "Table" {
+					c.addTable(t.Label)
+				}
+			}
+		case n.Kind == "For":
+			// FOR r IN (SELECT ...): r.campo es un registro
+			c.variables[strings.ToLower(n.Label)] = true
+		}
+		for _, ch := range n.Children {
+			collect(ch)
+		}
+	}
+	for _, n := range s.tree {
+		collect(n)
+	}
+	for _, n := range s.tree {
+		c.walk(n, nil)
+	}
+}
+
+func (c *sqlChecker) addTable(name string) {
+	if _, ok := c.schema[sqlBaseName(name)]; !ok {
+		c.schema[sqlBaseName(name)] = &sqlTable{name: name, sym: -1}
+	}
+}
+
+func (c *sqlChecker) report(pos int, severity, code, hint, msg string) {
+	*c.errors = append(*c.errors, CompilerError{
+		Message:  "Error semántico: " + msg,
+		Severity: severity,
+		Type:     "semantico",
+		Pos:      pos,
+		Code:     code,
+		Hint:     hint,
+	})
+}
+
+// walk busca las sentencias que usan tablas; outer son las tablas de las
+// consultas que contienen a n (subconsultas correlacionadas)
+func (c *sqlChecker) walk(n ParseNode, outer []sqlSource) {
+	switch n.Kind {
+	case "Select":
+		c.checkSelect(n, outer)
+		return
+	case "Insert":
+		c.checkInsert(n)
+		return
+	case "Update", "Delete":
+		c.checkModify(n, outer)
+		return
+	case "CreateTable":
+		return
+	}
+	for _, ch := range n.Children {
+		c.walk(ch, outer)
+	}
+}
+
+// resolveTable busca la tabla del nodo en el esquema, registra el uso y
+// reporta las que no existen
+func (c *sqlChecker) resolveTable(n ParseNode) *sqlTable {
+	if t, ok := c.schema[sqlBaseName(n.Label)]; ok {
+		if t.sym >= 0 {
+			(*c.syms)[t.sym].References = append((*c.syms)[t.sym].References, n.Pos)
+		}
+		return t
+	}
+	if sqlSystemTable(n.Label) {
+		return nil
+	}
+	var names []string
+	for _, t := range c.schema {
+		names = append(names, sqlUnquote(t.name[strings.LastIndexByte(t.name, '.')+1:]))
+	}
+	sort.Strings(names)
+	if suggestion := sqlSuggestion(sqlBaseName(n.Label), names); suggestion != "" {
+		c.report(n.Pos, "error", CodeUnknownTable, "replace:"+suggestion,
+			fmt.Sprintf("La tabla '%s' no existe (¿quiso decir '%s'?)", n.Label, suggestion))
+	} else {
+		c.report(n.Pos, "warning", CodeUnknownTable, "",
+			fmt.Sprintf("La tabla '%s' no está declarada en el script", n.Label))
+	}
+	return nil
+}
+
+// source convierte una tabla del FROM (tabla, subconsulta, función o
+// variable de tabla) en una fuente con su alias
+func (c *sqlChecker) source(n ParseNode, outer []sqlSource) sqlSource {
+	src := sqlSource{name: n.Label}
+	for _, ch := range n.Children {
+		if ch.Kind == "Alias" {
+			src.alias = strings.ToLower(ch.Label)
+		}
+	}
+	switch n.Kind {
+	case "Table":
+		src.table = c.resolveTable(n)
+	case "Subquery":
+		// Una tabla derivada no ve a las otras tablas del mismo FROM
+		c.walk(n, outer)
+	case "Call":
+		c.checkExpr(n, outer, nil)
+	}
+	return src
+}
+
+// fromSources devuelve las tablas de una cláusula FROM, incluidas las de sus
+// JOIN
+func (c *sqlChecker) fromSources(from ParseNode, outer []sqlSource) []sqlSource {
+	var sources []sqlSource
+	for _, ch := range from.Children {
+		if ch.Kind == "Join" && len(ch.Children) > 0 {
+			ch = ch.Children[0]
+		}
+		sources = append(sources, c.source(ch, outer))
+	}
+	return sources
+}
+
+// checkJoinConditions valida los ON de un FROM, que ven todas sus tablas
+func (c *sqlChecker) checkJoinConditions(from ParseNode, scope []sqlSource) {
+	for _, ch := range from.Children {
+		if ch.Kind != "Join" {
+			continue
+		}
+		for _, cond := range ch.Children[1:] {
+			c.checkExpr(cond, scope, nil)
+		}
+	}
+}
+
+func (c *sqlChecker) checkSelect(sel ParseNode, outer []sqlSource) {
+	var scope []sqlSource
+	for _, ch := range sel.Children {
+		if ch.Kind == "From" {
+			scope = c.fromSources(ch, outer)
+			c.checkJoinConditions(ch, append(scope, outer...))
+		}
+	}
+	scope = append(scope, outer...)
+	// ORDER BY puede usar los alias de la proyección
+	aliases := make(map[string]bool)
+	for _, ch := range sel.Children {
+		if ch.Kind == "Projection" {
+			for _, item := range ch.Children {
+				if item.Kind == "Alias" {
+					aliases[strings.ToLower(item.Label)] = true
+				}
+			}
+		}
+	}
+	for _, ch := range sel.Children {
+		switch ch.Kind {
+		case "From", "Into":
+		case "OrderBy":
+			c.checkExpr(ch, scope, aliases)
+		default:
+			c.checkExpr(ch, scope, nil)
+		}
+	}
+}
+
+func (c *sqlChecker) checkInsert(n ParseNode) {
+	var table *sqlTable
+	columns := -1
+	for _, ch := range n.Children {
+		switch ch.Kind {
+		case "Table":
+			table = c.resolveTable(ch)
+			if table != nil && table.columns != nil {
+				columns = len(table.order)
+			}
+		case "Columns":
+			columns = len(ch.Children)
+			for _, col := range ch.Children {
+				c.checkColumnOf(col, table)
+			}
+		case "Values":
+			for _, row := range ch.Children {
+				if columns >= 0 && len(row.Children) != columns {
+					c.report(row.Pos, "error", CodeArgumentCount, "",
+						fmt.Sprintf("El INSERT en '%s' indica %d columna(s) pero la fila tiene %d valor(es)", n.Label, columns, len(row.Children)))
+				}
+				c.checkExpr(row, nil, nil)
+			}
+		default:
+			c.walk(ch, nil)
+		}
+	}
+}
+
+// checkModify valida UPDATE y DELETE: la tabla modificada puede ser un
+// alias del FROM (UPDATE c SET ... FROM Clientes c)
+func (c *sqlChecker) checkModify(n ParseNode, outer []sqlSource) {
+	if len(n.Children) == 0 {
+		return
+	}
+	var scope []sqlSource
+	for _, ch := range n.Children {
+		if ch.Kind == "From" {
+			scope = c.fromSources(ch, outer)
+		}
+	}
+	target := n.Children[0]
+	var table *sqlTable
+	if src, ok := c.findSource(scope, strings.ToLower(sqlUnquote(target.Label))); ok {
+		table = src.table
+	} else {
+		src := c.source(target, outer)
+		table = src.table
+		scope = append([]sqlSource{src}, scope...)
+	}
+	scope = append(scope, outer...)
+	for _, ch := range n.Children[1:] {
+		switch ch.Kind {
+		case "From":
+			c.checkJoinConditions(ch, scope)
+		case "Set":
+			for _, assign := range ch.Children {
+				if !strings.HasPrefix(assign.Label, "@") {
+					col := newNode("Column", assign.Label, assign.Pos, assign.End)
+					c.checkColumnOf(col, table)
+				}
+				for _, value := range assign.Children {
+					c.checkExpr(value, scope, nil)
+				}
+			}
+		default:
+			c.checkExpr(ch, scope, nil)
+		}
+	}
+}
+
+// findSource busca la tabla del FROM con ese alias o nombre
+func (c *sqlChecker) findSource(scope []sqlSource, name string) (sqlSource, bool) {
+	for _, src := range scope {
+		if src.alias == name || src.alias == "" && sqlBaseName(src.name) == name {
+			return src, true
+		}
+	}
+	for _, src := range scope {
+		if sqlBaseName(src.name) == name {
+			return src, true
+		}
+	}
+	return sqlSource{}, false
+}
+
+// checkExpr valida las columnas de una expresión o cláusula
+func (c *sqlChecker) checkExpr(n ParseNode, scope []sqlSource, aliases map[string]bool) {
+	switch n.Kind {
+	case "Column":
+		c.checkColumn(n, scope, aliases)
+		return
+	case "Star":
+		if dot := strings.LastIndexByte(n.Label, '.'); dot > 0 {
+			c.checkQualifier(n, n.Label[:dot], scope)
+		}
+		return
+	case "Select", "Subquery", "Exists", "With", "SetOperation":
+		c.walk(n, scope)
+		return
+	case "Call":
+		if sqlKeywordArgument[n.Label] && len(n.Children) > 0 {
+			for _, arg := range n.Children[1:] {
+				c.checkExpr(arg, scope, aliases)
+			}
+			return
+		}
+	case "Type":
+		return
+	}
+	for _, ch := range n.Children {
+		c.checkExpr(ch, scope, aliases)
+	}
+}
+
+// checkQualifier busca la tabla o alias que califica una columna (c en
+// c.Nombre) y la devuelve si existe
+func (c *sqlChecker) checkQualifier(n ParseNode, qualifier string, scope []sqlSource) (sqlSource, bool) {
+	name := sqlBaseName(qualifier)
+	if src, ok := c.findSource(scope, name); ok {
+		return src, true
+	}
+	if c.variables[name] || len(scope) == 0 {
+		return sqlSource{}, false
+	}
+	var names []string
+	for _, src := range scope {
+		if src.alias != "" {
+			names = append(names, src.alias)
+		} else {
+			names = append(names, sqlUnquote(src.name))
+		}
+	}
+	hint := ""
+	msg := fmt.Sprintf("'%s' no es una tabla ni un alias del FROM (%s)", qualifier, strings.Join(names, ", "))
+	if suggestion := sqlSuggestion(name, names); suggestion != "" {
+		hint = "replace:" + suggestion
+		msg = fmt.Sprintf("'%s' no es una tabla ni un alias del FROM (¿quiso decir '%s'?)", qualifier, suggestion)
+	}
+	c.report(n.Pos, "error", CodeUnknownTable, hint, msg)
+	return sqlSource{}, false
+}
+
+// checkColumn valida una columna, calificada (c.Nombre) o no (Nombre)
+func (c *sqlChecker) checkColumn(n ParseNode, scope []sqlSource, aliases map[string]bool) {
+	if dot := strings.LastIndexByte(n.Label, '.'); dot > 0 {
+		if src, ok := c.checkQualifier(n, n.Label[:dot], scope); ok {
+			c.checkColumnOf(newNode("Column", n.Label[dot+1:], n.Pos, n.End), src.table)
+		}
+		return
+	}
+	name := sqlBaseName(n.Label)
+	if aliases[name] || c.variables[name] || sqlPseudoColumns[name] || len(scope) == 0 {
+		return
+	}
+	var candidates, tables []string
+	for _, src := range scope {
+		if src.table == nil || src.table.columns == nil {
+			// Una tabla de columnas desconocidas puede tenerla
+			return
+		}
+		if i, ok := src.table.columns[name]; ok {
+			(*c.syms)[i].References = append((*c.syms)[i].References, n.Pos)
+			return
+		}
+		candidates = append(candidates, src.table.order...)
+		tables = append(tables, sqlUnquote(src.table.name))
+	}
+	c.unknownColumn(n, strings.Join(tables, ", "), candidates)
+}
+
+// checkColumnOf valida una columna de una tabla conocida
+func (c *sqlChecker) checkColumnOf(col ParseNode, table *sqlTable) {
+	if table == nil || table.columns == nil {
+		return
+	}
+	if i, ok := table.columns[sqlBaseName(col.Label)]; ok {
+		(*c.syms)[i].References = append((*c.syms)[i].References, col.Pos)
+		return
+	}
+	c.unknownColumn(col, sqlUnquote(table.name), table.order)
+}
+
+func (c *sqlChecker) unknownColumn(col ParseNode, tables string, candidates []string) {
+	name := col.Label
+	if dot := strings.LastIndexByte(name, '.'); dot >= 0 {
+		name = name[dot+1:]
+	}
+	if suggestion := sqlSuggestion(name, candidates); suggestion != "" {
+		c.report(col.Pos, "error", CodeUnknownColumn, "replace:"+suggestion,
+			fmt.Sprintf("La columna '%s' no existe en '%s' (¿quiso decir '%s'?)", name, tables, suggestion))
+		return
+	}
+	c.report(col.Pos, "error", CodeUnknownColumn, "",
+		fmt.Sprintf("La columna '%s' no existe en '%s'", name, tables))
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+)
+
+// Mensajes enviados por el endpoint de streaming. Cada fase del análisis se
+// envía como un mensaje "phase" independiente y al final se envía "complete"
+// con la respuesta completa (idéntica a la de /api/v1/analyze).
+type APIStreamMessage struct {
+	Type    string              `json:"type"` // "phase" | "complete" | "error"
+	Phase   string              `json:"phase,omitempty"`
+	Data    *APIStreamPhaseData `json:"data,omitempty"`
+	Result  *APIAnalyzeResponse `json:"result,omitempty"`
+	Message string              `json:"message,omitempty"`
+}
+
+type APIStreamPhaseData struct {
+	Tokens          []APIToken          `json:"tokens,omitempty"`
+	ParseTree       []APIParseNode      `json:"parseTree,omitempty"`
+	SymbolTable     []APISymbol         `json:"symbolTable,omitempty"`
+	Errors          []APICompilerError  `json:"errors"`
+	Phase           *APIAnalysisPhase   `json:"analysisPhase,omitempty"`
+	CanExecute      *bool               `json:"canExecute,omitempty"`
+	Executability   *APIExecutability   `json:"executability,omitempty"`
+	ExecutionResult *APIExecutionResult `json:"executionResult,omitempty"`
+}
+
+var streamUpgrader = websocket.Upgrader{
+	// El origen ya es validado por la configuración CORS del servidor para
+	// las peticiones HTTP; aquí se aplica la misma lista de orígenes.
+	CheckOrigin: func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		return origin == "" || isAllowedOrigin(origin)
+	},
+}
+
+// analyzeStreamHandler recibe un AnalyzeRequest como primer mensaje del
+// WebSocket y envía el resultado de cada fase en cuanto termina.
+func analyzeStreamHandler(w http.ResponseWriter, r *http.Request) {
+	rid, msg := requestID(r)
+	if msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if executions.active(rid) {
+		httpError(w, "An execution with this request id is already running", http.StatusConflict)
+		return
+	}
+	conn, err := streamUpgrader.Upgrade(w, r, http.Header{requestIDHeader: {rid}})
+	if err != nil {
+		log.Printf("stream: no se pudo establecer el WebSocket: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	var req AnalyzeRequest
+	// Un mensaje más grande cierra la conexión con el código 1009
+	if limit := GlobalConfig.MaxRequestBytes; limit > 0 {
+		conn.SetReadLimit(limit)
+	}
+	if err := conn.ReadJSON(&req); err != nil {
+		conn.WriteJSON(APIStreamMessage{Type: "error", Message: "Invalid JSON"})
+		return
+	}
+	if msg := codeTooLarge(req.Code); msg != "" {
+		conn.WriteJSON(APIStreamMessage{Type: "error", Message: msg})
+		return
+	}
+	if msg := unsupportedLanguage(req.Language); msg != "" {
+		conn.WriteJSON(APIStreamMessage{Type: "error", Message: msg})
+		return
+	}
+	principal := principalFrom(r)
+	if msg := req.validate(principal); msg != "" {
+		conn.WriteJSON(APIStreamMessage{Type: "error", Message: msg})
+		return
+	}
+
+	if _, msg := req.authorize(principal); msg != "" {
+		conn.WriteJSON(APIStreamMessage{Type: "error", Message: msg})
+		return
+	}
+
+	src := newSourceIndex(req.Code)
+	locale := requestLocale(req.Locale, r)
+	req.Locale = locale
+	sentErrors := 0
+
+	onPhase := func(phase string, partial *AnalyzeResponse) {
+		// Solo se envían los errores nuevos de cada fase
+		newErrors := convertToAPIErrors(partial.Errors[sentErrors:], src, locale)
+		sentErrors = len(partial.Errors)
+
+		data := &APIStreamPhaseData{Errors: newErrors}
+		switch phase {
+		case "lexical":
+			data.Tokens = convertToAPITokens(partial.Tokens, src)
+			data.Phase = &APIAnalysisPhase{
+				Completed:   partial.AnalysisPhases.Lexical.Completed,
+				TokensFound: &partial.AnalysisPhases.Lexical.TokensFound,
+				ErrorsFound: partial.AnalysisPhases.Lexical.ErrorsFound,
+			}
+		case "syntax":
+			data.ParseTree = convertToAPIParseNodes(partial.ParseTree, src)
+			data.Phase = &APIAnalysisPhase{
+				Completed:      partial.AnalysisPhases.Syntax.Completed,
+				NodesGenerated: &partial.AnalysisPhases.Syntax.NodesGenerated,
+				ErrorsFound:    partial.AnalysisPhases.Syntax.ErrorsFound,
+			}
+		case "semantic":
+			data.SymbolTable = convertToAPISymbols(partial.SymbolTable, src)
+			data.Phase = &APIAnalysisPhase{
+				Completed:    partial.AnalysisPhases.Semantic.Completed,
+				SymbolsFound: &partial.AnalysisPhases.Semantic.SymbolsFound,
+				ErrorsFound:  partial.AnalysisPhases.Semantic.ErrorsFound,
+			}
+			executability := convertToAPIExecutability(partial.Executability, locale)
+			data.CanExecute, data.Executability = &executability.Allowed, &executability
+		case "execution":
+			if partial.ExecutionResult != nil {
+				data.ExecutionResult = convertToAPIExecutionResult(partial.ExecutionResult)
+			}
+			executability := convertToAPIExecutability(partial.Executability, locale)
+			data.CanExecute, data.Executability = &executability.Allowed, &executability
+		}
+
+		if err := conn.WriteJSON(APIStreamMessage{Type: "phase", Phase: phase, Data: data}); err != nil {
+			log.Printf("stream: error enviando fase %s: %v", phase, err)
+		}
+	}
+
+	apiResponse := analyzeRequest(req, rid, principal, onPhase)
+	conn.WriteJSON(APIStreamMessage{Type: "complete", Result: &apiResponse})
+}
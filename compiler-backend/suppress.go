@@ -0,0 +1,108 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ─────────────────────── Comentarios de supresión ────────────────────────
+//
+// Un comentario puede silenciar los diagnósticos semánticos de su propia
+// línea, como los linters de cada lenguaje:
+//
+//   int temp = 0; // compilador:ignore unused
+//   x = valor     # noqa: SEM002
+//
+// Después de compilador:ignore van los diagnósticos separados por espacios
+// o comas; después de noqa, con dos puntos, como en flake8. Se nombran por
+// código (SEM002), por nombre del catálogo (unused-variable) o por su primera
+// palabra (unused). Sin nombres se silencian todos los de la línea. A
+// diferencia de DISABLED_DIAGNOSTICS (diagnostics.go) vale solo para esa
+// línea y lo decide quien escribe el código.
+
+const (
+	ignoreMarker = "compilador:ignore"
+	noqaMarker   = "noqa"
+)
+
+// parseSuppression lee un comentario; ok indica si tiene una marca y names
+// los diagnósticos que silencia (vacío: todos)
+func parseSuppression(comment string) (names []string, ok bool) {
+	lower := strings.ToLower(comment)
+	if i := strings.Index(lower, ignoreMarker); i >= 0 {
+		return suppressionNames(lower[i+len(ignoreMarker):]), true
+	}
+	if i := strings.Index(lower, noqaMarker); i >= 0 {
+		rest := strings.TrimLeft(lower[i+len(noqaMarker):], " \t")
+		if rest, found := strings.CutPrefix(rest, ":"); found {
+			return suppressionNames(rest), true
+		}
+		return nil, true
+	}
+	return nil, false
+}
+
+// suppressionNames toma los nombres hasta la primera palabra que no puede
+// serlo, como el cierre de un comentario de bloque (*/, -->)
+func suppressionNames(s string) []string {
+	var names []string
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+		if strings.IndexFunc(f, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' }) >= 0 {
+			break
+		}
+		names = append(names, f)
+	}
+	return names
+}
+
+// suppresses indica si names incluye el diagnóstico code
+func suppresses(names []string, code string) bool {
+	if len(names) == 0 {
+		return true
+	}
+	name := errorCatalog[code].Name
+	for _, n := range names {
+		if strings.EqualFold(n, code) || n == name || name != "" && strings.HasPrefix(name, n+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// applySuppressions quita de errs los diagnósticos silenciados por los
+// comentarios de tokens. La línea de cada diagnóstico es la del último token
+// que empieza en su posición o antes
+func applySuppressions(tokens []Token, errs []CompilerError) []CompilerError {
+	lines := map[int][]string{}
+	for _, t := range tokens {
+		if t.Type != COMMENT {
+			continue
+		}
+		if names, ok := parseSuppression(t.Lexeme); ok {
+			if existing, seen := lines[t.Line]; seen && len(existing) == 0 {
+				continue
+			}
+			if len(names) == 0 {
+				lines[t.Line] = []string{}
+			} else {
+				lines[t.Line] = append(lines[t.Line], names...)
+			}
+		}
+	}
+	if len(lines) == 0 {
+		return errs
+	}
+
+	var kept []CompilerError
+	for _, e := range errs {
+		i := sort.Search(len(tokens), func(i int) bool { return tokens[i].Start > e.Pos })
+		if i > 0 {
+			if names, ok := lines[tokens[i-1].Line]; ok && suppresses(names, e.Code) {
+				continue
+			}
+		}
+		kept = append(kept, e)
+	}
+	return kept
+}
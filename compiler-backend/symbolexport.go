@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ──────────────────── Exportar la tabla de símbolos ─────────────────────
+//
+// El curso pide entregar la tabla de símbolos como un archivo aparte.
+// symbolFormat en /api/v1/analyze la devuelve además en symbols: "csv" para
+// hojas de cálculo, "html" como una página completa lista para abrir o
+// imprimir, y "dot" como un grafo de Graphviz con los alcances anidados y
+// los símbolos que declara cada uno.
+//
+// Solo Pascal registra el alcance de cada símbolo; en los demás lenguajes se
+// deduce del árbol: las funciones, métodos y clases que contienen la
+// declaración, separados por puntos como en Pascal (Clase.metodo).
+
+const (
+	symbolFormatCSV  = "csv"
+	symbolFormatHTML = "html"
+	symbolFormatDOT  = "dot"
+)
+
+// validSymbolFormat indica si format es un formato de exportación conocido
+func validSymbolFormat(format string) bool {
+	return format == symbolFormatCSV || format == symbolFormatHTML || format == symbolFormatDOT
+}
+
+// exportSymbols devuelve symbols en format; scopes es el alcance de cada
+// símbolo (ver symbolScopes) y locale el idioma de los encabezados del HTML
+func exportSymbols(symbols []APISymbol, scopes []string, language, format, locale string) string {
+	switch format {
+	case symbolFormatCSV:
+		return symbolsCSV(symbols, scopes)
+	case symbolFormatHTML:
+		return symbolsHTML(symbols, scopes, language, locale)
+	case symbolFormatDOT:
+		return symbolsDOT(symbols, scopes)
+	}
+	return ""
+}
+
+// Nodos del árbol que abren un alcance con nombre
+var scopeKinds = map[string]bool{
+	"FunctionDecl": true, "Method": true, "ClassDecl": true,
+	"Procedure": true, "Function": true,
+}
+
+// symbolScopes devuelve el alcance de cada símbolo: el que registró el
+// analizador o, si no registró ninguno, el de las declaraciones del árbol
+// que lo contienen ("global" fuera de todas)
+func symbolScopes(symbols []Symbol, tree []ParseNode) []string {
+	scopes := make([]string, len(symbols))
+	for i, s := range symbols {
+		if s.Scope != "" {
+			scopes[i] = s.Scope
+			continue
+		}
+		var chain []string
+		nodes := tree
+		for {
+			inner := -1
+			for j, n := range nodes {
+				// La declaración de f está dentro del nodo de f, pero f
+				// pertenece al alcance que la contiene
+				if s.Pos >= n.Pos && s.Pos < n.End && !(scopeKinds[n.Kind] && n.Label == s.Name) {
+					inner = j
+					break
+				}
+			}
+			if inner < 0 {
+				break
+			}
+			n := nodes[inner]
+			if scopeKinds[n.Kind] && n.Label != "" {
+				chain = append(chain, n.Label)
+			}
+			nodes = n.Children
+		}
+		scopes[i] = strings.Join(chain, ".")
+		if scopes[i] == "" {
+			scopes[i] = "global"
+		}
+	}
+	return scopes
+}
+
+// symbolParams escribe los parámetros como "n: int, m"
+func symbolParams(s APISymbol) string {
+	parts := make([]string, len(s.Parameters))
+	for i, p := range s.Parameters {
+		parts[i] = p.Name
+		if p.Type != "" {
+			parts[i] += ": " + p.Type
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// symbolReferences escribe cada uso como línea:columna
+func symbolReferences(s APISymbol) string {
+	parts := make([]string, len(s.References))
+	for i, r := range s.References {
+		parts[i] = fmt.Sprintf("%d:%d", r.Line, r.Column)
+	}
+	return strings.Join(parts, " ")
+}
+
+// symbolsCSV escribe una fila por símbolo con las columnas de APISymbol
+func symbolsCSV(symbols []APISymbol, scopes []string) string {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	w.Write([]string{"name", "category", "type", "value", "scope", "line", "column", "position", "parameters", "references"})
+	for i, s := range symbols {
+		w.Write([]string{s.Name, s.Category, s.Type, s.Value, scopes[i],
+			strconv.Itoa(s.Line), strconv.Itoa(s.Column), strconv.Itoa(s.Position),
+			symbolParams(s), symbolReferences(s)})
+	}
+	w.Flush()
+	return b.String()
+}
+
+// Título y encabezados de la tabla HTML en cada idioma
+var symbolTableHeadings = map[string][]string{
+	"es": {"Tabla de símbolos", "Nombre", "Categoría", "Tipo", "Valor", "Alcance", "Línea", "Columna", "Parámetros", "Usos"},
+	"en": {"Symbol table", "Name", "Category", "Type", "Value", "Scope", "Line", "Column", "Parameters", "References"},
+}
+
+const symbolTableStyle = `body{font-family:Helvetica,Arial,sans-serif;margin:2em}` +
+	`table{border-collapse:collapse}th,td{border:1px solid #999;padding:.3em .6em;text-align:left}` +
+	`th{background:#eee}td.num{text-align:right}code{font-family:Menlo,Consolas,monospace}`
+
+// symbolsHTML genera una página completa, sin recursos externos
+func symbolsHTML(symbols []APISymbol, scopes []string, language, locale string) string {
+	headings, ok := symbolTableHeadings[locale]
+	if !ok {
+		locale, headings = "es", symbolTableHeadings["es"]
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "<!DOCTYPE html>\n<html lang=\"%s\">\n<head>\n<meta charset=\"utf-8\">\n", locale)
+	fmt.Fprintf(&b, "<title>%s (%s)</title>\n<style>%s</style>\n</head>\n<body>\n", headings[0], html.EscapeString(language), symbolTableStyle)
+	fmt.Fprintf(&b, "<h1>%s (%s)</h1>\n", headings[0], html.EscapeString(language))
+	writeSymbolTable(&b, symbols, scopes, headings[1:])
+	b.WriteString("</body>\n</html>\n")
+	return b.String()
+}
+
+// writeSymbolTable escribe la <table> de los símbolos con los encabezados
+// columns; también la usa el reporte (ver report.go)
+func writeSymbolTable(b *strings.Builder, symbols []APISymbol, scopes []string, columns []string) {
+	b.WriteString("<table>\n<thead><tr>")
+	for _, h := range columns {
+		fmt.Fprintf(b, "<th>%s</th>", h)
+	}
+	b.WriteString("</tr></thead>\n<tbody>\n")
+	for i, s := range symbols {
+		fmt.Fprintf(b, "<tr><td><code>%s</code></td><td>%s</td><td>%s</td><td><code>%s</code></td><td>%s</td>"+
+			"<td class=\"num\">%d</td><td class=\"num\">%d</td><td><code>%s</code></td><td>%s</td></tr>\n",
+			html.EscapeString(s.Name), html.EscapeString(s.Category), html.EscapeString(s.Type),
+			html.EscapeString(s.Value), html.EscapeString(scopes[i]), s.Line, s.Column,
+			html.EscapeString(symbolParams(s)), html.EscapeString(symbolReferences(s)))
+	}
+	b.WriteString("</tbody>\n</table>\n")
+}
+
+// symbolsDOT genera un digraph con un nodo por alcance, unido al que lo
+// contiene, y un nodo por símbolo unido a su alcance
+func symbolsDOT(symbols []APISymbol, scopes []string) string {
+	// Cada alcance y todos sus prefijos: A.m también necesita a A
+	present := map[string]bool{"global": true}
+	for _, scope := range scopes {
+		parts := strings.Split(scope, ".")
+		for i := range parts {
+			present[strings.Join(parts[:i+1], ".")] = true
+		}
+	}
+	names := make([]string, 0, len(present))
+	for scope := range present {
+		names = append(names, scope)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("digraph scopes {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [fontname=\"Helvetica\"];\n")
+	for _, scope := range names {
+		label := scope[strings.LastIndex(scope, ".")+1:]
+		fmt.Fprintf(&b, "  \"scope:%s\" [label=\"%s\", shape=folder, style=filled, fillcolor=\"#eeeeee\"];\n",
+			dotEscape(scope), dotEscape(label))
+		if scope == "global" {
+			continue
+		}
+		parent := "global"
+		if i := strings.LastIndex(scope, "."); i >= 0 {
+			parent = scope[:i]
+		}
+		fmt.Fprintf(&b, "  \"scope:%s\" -> \"scope:%s\";\n", dotEscape(parent), dotEscape(scope))
+	}
+	for i, s := range symbols {
+		label := s.Name
+		if s.Type != "" && s.Type != s.Category {
+			label += ": " + s.Type
+		}
+		label = dotEscape(label) + `\n` + dotEscape(fmt.Sprintf("%s, línea %d", s.Category, s.Line))
+		fmt.Fprintf(&b, "  s%d [label=\"%s\", shape=box, style=rounded];\n", i, label)
+		fmt.Fprintf(&b, "  \"scope:%s\" -> s%d [style=dashed, arrowhead=none];\n", dotEscape(scopes[i]), i)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
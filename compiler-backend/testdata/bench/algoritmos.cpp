@@ -0,0 +1,56 @@
+#include <iostream>
+#include <vector>
+#include <string>
+#include <algorithm>
+
+using namespace std;
+
+// Ordenamiento por mezcla sobre un vector de enteros
+void mezclar(vector<int>& v, int izq, int medio, int der) {
+    vector<int> tmp;
+    int i = izq, j = medio + 1;
+    while (i <= medio && j <= der) {
+        if (v[i] <= v[j]) tmp.push_back(v[i++]);
+        else tmp.push_back(v[j++]);
+    }
+    while (i <= medio) tmp.push_back(v[i++]);
+    while (j <= der) tmp.push_back(v[j++]);
+    for (int k = 0; k < (int)tmp.size(); k++) v[izq + k] = tmp[k];
+}
+
+void ordenar(vector<int>& v, int izq, int der) {
+    if (izq >= der) return;
+    int medio = (izq + der) / 2;
+    ordenar(v, izq, medio);
+    ordenar(v, medio + 1, der);
+    mezclar(v, izq, medio, der);
+}
+
+class Cuenta {
+private:
+    string titular;
+    double saldo;
+public:
+    Cuenta(const string& t, double s) : titular(t), saldo(s) {}
+    void depositar(double monto) { if (monto > 0) saldo += monto; }
+    bool retirar(double monto) {
+        if (monto > saldo) return false;
+        saldo -= monto;
+        return true;
+    }
+    double getSaldo() const { return saldo; }
+};
+
+int main() {
+    vector<int> datos = {38, 27, 43, 3, 9, 82, 10};
+    ordenar(datos, 0, datos.size() - 1);
+    for (int x : datos) cout << x << " ";
+    cout << endl;
+
+    Cuenta c("Ana", 100.5);
+    c.depositar(50);
+    if (!c.retirar(500)) cout << "Fondos insuficientes" << endl;
+    cout << "Saldo: " << c.getSaldo() << endl;
+    /* fin del programa */
+    return 0;
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Palabra y cuántas veces aparece
+type conteo struct {
+	palabra string
+	veces   int
+}
+
+func frecuencias(texto string) []conteo {
+	mapa := map[string]int{}
+	for _, p := range strings.Fields(strings.ToLower(texto)) {
+		mapa[strings.Trim(p, ".,;:")]++
+	}
+	lista := make([]conteo, 0, len(mapa))
+	for p, n := range mapa {
+		lista = append(lista, conteo{p, n})
+	}
+	sort.Slice(lista, func(i, j int) bool {
+		if lista[i].veces != lista[j].veces {
+			return lista[i].veces > lista[j].veces
+		}
+		return lista[i].palabra < lista[j].palabra
+	})
+	return lista
+}
+
+func busquedaBinaria(v []int, x int) int {
+	izq, der := 0, len(v)-1
+	for izq <= der {
+		medio := (izq + der) / 2
+		switch {
+		case v[medio] == x:
+			return medio
+		case v[medio] < x:
+			izq = medio + 1
+		default:
+			der = medio - 1
+		}
+	}
+	return -1
+}
+
+func main() {
+	for _, c := range frecuencias("el perro y el gato. El gato duerme") {
+		fmt.Printf("%s: %d\n", c.palabra, c.veces)
+	}
+	fmt.Println(busquedaBinaria([]int{1, 3, 5, 7, 9, 11}, 7))
+}
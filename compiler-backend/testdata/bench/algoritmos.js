@@ -0,0 +1,42 @@
+// Algoritmos básicos y una clase sencilla
+function esPrimo(n) {
+  if (n < 2) return false;
+  for (let d = 2; d * d <= n; d++) {
+    if (n % d === 0) return false;
+  }
+  return true;
+}
+
+const fibonacci = (n) => {
+  const serie = [];
+  let [a, b] = [0, 1];
+  while (serie.length < n) {
+    serie.push(a);
+    [a, b] = [b, a + b];
+  }
+  return serie;
+};
+
+class Inventario {
+  constructor() {
+    this.productos = new Map();
+  }
+
+  agregar(nombre, cantidad = 1) {
+    const actual = this.productos.get(nombre) ?? 0;
+    this.productos.set(nombre, actual + cantidad);
+  }
+
+  total() {
+    let suma = 0;
+    for (const [, cantidad] of this.productos) suma += cantidad;
+    return suma;
+  }
+}
+
+const inv = new Inventario();
+inv.agregar("lápiz", 3);
+inv.agregar("cuaderno");
+const primos = Array.from({ length: 50 }, (_, i) => i).filter(esPrimo);
+console.log(`Primos: ${primos.join(", ")}`);
+console.log("Fibonacci:", fibonacci(10), "Total:", inv.total());
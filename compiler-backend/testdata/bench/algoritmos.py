@@ -0,0 +1,56 @@
+# Algoritmos básicos y una clase sencilla
+import math
+
+
+def es_primo(n):
+    if n < 2:
+        return False
+    for d in range(2, int(math.sqrt(n)) + 1):
+        if n % d == 0:
+            return False
+    return True
+
+
+def fibonacci(n):
+    a, b = 0, 1
+    serie = []
+    while len(serie) < n:
+        serie.append(a)
+        a, b = b, a + b
+    return serie
+
+
+class Pila:
+    """Pila con una lista"""
+
+    def __init__(self):
+        self.items = []
+
+    def apilar(self, x):
+        self.items.append(x)
+
+    def desapilar(self):
+        if not self.items:
+            raise IndexError("pila vacía")
+        return self.items.pop()
+
+    def __len__(self):
+        return len(self.items)
+
+
+def balanceado(texto):
+    pares = {')': '(', ']': '[', '}': '{'}
+    pila = Pila()
+    for c in texto:
+        if c in '([{':
+            pila.apilar(c)
+        elif c in pares:
+            if len(pila) == 0 or pila.desapilar() != pares[c]:
+                return False
+    return len(pila) == 0
+
+
+primos = [n for n in range(50) if es_primo(n)]
+print(f"Primos: {primos}")
+print("Fibonacci:", fibonacci(10))
+print(balanceado("{[()()]}"), balanceado("(]"))
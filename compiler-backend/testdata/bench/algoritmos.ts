@@ -0,0 +1,43 @@
+// Algoritmos básicos con tipos
+interface Estudiante {
+  nombre: string;
+  notas: number[];
+}
+
+type Resumen = { nombre: string; promedio: number; aprobado: boolean };
+
+function promedio(notas: number[]): number {
+  if (notas.length === 0) return 0;
+  return notas.reduce((a, b) => a + b, 0) / notas.length;
+}
+
+function resumir(estudiantes: Estudiante[], minimo: number = 61): Resumen[] {
+  return estudiantes.map((e) => {
+    const p = promedio(e.notas);
+    return { nombre: e.nombre, promedio: p, aprobado: p >= minimo };
+  });
+}
+
+class Cola<T> {
+  private items: T[] = [];
+
+  encolar(x: T): void {
+    this.items.push(x);
+  }
+
+  desencolar(): T | undefined {
+    return this.items.shift();
+  }
+
+  get tamaño(): number {
+    return this.items.length;
+  }
+}
+
+const grupo: Estudiante[] = [
+  { nombre: "Ana", notas: [80, 95, 70] },
+  { nombre: "Luis", notas: [50, 60, 55] },
+];
+const cola = new Cola<Resumen>();
+for (const r of resumir(grupo)) cola.encolar(r);
+console.log(`En cola: ${cola.tamaño}`, cola.desencolar());
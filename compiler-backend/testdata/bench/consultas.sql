@@ -0,0 +1,29 @@
+-- Consultas sobre las notas del curso
+CREATE TABLE Estudiantes (
+    Id INT PRIMARY KEY IDENTITY(1, 1),
+    Nombre NVARCHAR(100) NOT NULL,
+    Carnet VARCHAR(12) UNIQUE
+);
+
+CREATE TABLE Notas (
+    EstudianteId INT REFERENCES Estudiantes(Id),
+    Curso VARCHAR(20) NOT NULL,
+    Nota DECIMAL(5, 2) CHECK (Nota BETWEEN 0 AND 100)
+);
+
+DECLARE @minimo DECIMAL(5, 2) = 61;
+
+SELECT e.Nombre, AVG(n.Nota) AS Promedio
+FROM Estudiantes e
+INNER JOIN Notas n ON n.EstudianteId = e.Id
+WHERE n.Curso = 'Compiladores'
+GROUP BY e.Nombre
+HAVING AVG(n.Nota) >= @minimo
+ORDER BY Promedio DESC;
+
+UPDATE Notas SET Nota = Nota + 5 WHERE Curso = 'Compiladores' AND Nota < 61;
+
+IF EXISTS (SELECT 1 FROM Notas WHERE Nota IS NULL)
+BEGIN
+    PRINT 'Hay notas pendientes';
+END
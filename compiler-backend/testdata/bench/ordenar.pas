@@ -0,0 +1,46 @@
+program Ordenar;
+{ Ordenamiento de burbuja y búsqueda lineal }
+const
+  N = 8;
+type
+  TVector = array[1..N] of Integer;
+var
+  datos: TVector;
+  i: Integer;
+
+procedure Burbuja(var v: TVector);
+var
+  i, j, tmp: Integer;
+begin
+  for i := 1 to N - 1 do
+    for j := 1 to N - i do
+      if v[j] > v[j + 1] then
+      begin
+        tmp := v[j];
+        v[j] := v[j + 1];
+        v[j + 1] := tmp;
+      end;
+end;
+
+function Buscar(const v: TVector; x: Integer): Integer;
+var
+  i: Integer;
+begin
+  Buscar := -1;
+  for i := 1 to N do
+    if v[i] = x then
+    begin
+      Buscar := i;
+      Exit;
+    end;
+end;
+
+begin
+  datos[1] := 38; datos[2] := 27; datos[3] := 43; datos[4] := 3;
+  datos[5] := 9; datos[6] := 82; datos[7] := 10; datos[8] := 1;
+  Burbuja(datos);
+  for i := 1 to N do
+    Write(datos[i], ' ');
+  WriteLn;
+  WriteLn('Posición de 43: ', Buscar(datos, 43));
+end.
@@ -0,0 +1,39 @@
+// Parámetros, variables de ciclos, tipos de la biblioteca y default de un
+// switch: este programa no tiene diagnósticos
+#include <iostream>
+#include <string>
+#include <vector>
+using namespace std;
+
+struct Punto {
+    int x;
+    int y;
+};
+
+int sumar(const vector<int>& valores, const Punto& p) {
+    int total = 0, extra = p.x + p.y;
+    for (int i = 0; i < (int)valores.size(); i++) {
+        total += valores[i];
+    }
+    for (int i = 0; i < 2; i++) {
+        total += extra;
+    }
+    return total;
+}
+
+int main() {
+    std::string nombre = "Ana";
+    vector<int> v;
+    v.push_back(3);
+    v.push_back(4);
+    Punto p = {1, 2};
+    switch (v.size()) {
+    case 0:
+        cout << "vacío" << endl;
+        break;
+    default:
+        cout << nombre << ": " << sumar(v, p) << endl;
+        break;
+    }
+    return 0;
+}
@@ -0,0 +1,13 @@
+#include <iostream>
+using namespace std;
+
+int suma(int a, int b) {
+    return a + b
+}
+
+int main() {
+    int x = 5;
+    int sinUso;
+    cout << suma(x, y) << endl;
+    string s = "sin cerrar;
+    return 0;
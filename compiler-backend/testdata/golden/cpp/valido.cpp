@@ -0,0 +1,18 @@
+#include <iostream>
+using namespace std;
+
+int factorial(int n) {
+    if (n <= 1) {
+        return 1;
+    }
+    return n * factorial(n - 1);
+}
+
+int main() {
+    int total = 0;
+    for (int i = 1; i <= 5; i++) {
+        total += factorial(i);
+    }
+    cout << "Total: " << total << endl;
+    return 0;
+}
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func dividir(a, b int) int {
+	return a / b
+
+func main() {
+	resultado := dividir(10, 2
+	fmt.Println(resultado, valor)
+}
@@ -0,0 +1,25 @@
+package main
+
+import "fmt"
+
+type Pila struct {
+	elementos []int
+}
+
+func (p *Pila) Apilar(v int) {
+	p.elementos = append(p.elementos, v)
+}
+
+func (p *Pila) Desapilar() int {
+	v := p.elementos[len(p.elementos)-1]
+	p.elementos = p.elementos[:len(p.elementos)-1]
+	return v
+}
+
+func main() {
+	p := &Pila{}
+	for i := 0; i < 3; i++ {
+		p.Apilar(i * i)
+	}
+	fmt.Println(p.Desapilar())
+}
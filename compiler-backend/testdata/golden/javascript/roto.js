@@ -0,0 +1,11 @@
+function saludar(nombre {
+  console.log("Hola, " + nombre);
+}
+
+let contador = 0;
+contador++;
+saludar(usuario);
+const x = [1, 2, 3;
+if (contador > 0 {
+  console.log("listo");
+}
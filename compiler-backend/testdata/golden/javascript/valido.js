@@ -0,0 +1,16 @@
+// Filtra y resume una lista de productos
+const productos = [
+  { nombre: "lápiz", precio: 2.5 },
+  { nombre: "cuaderno", precio: 12 },
+];
+
+function total(lista) {
+  let suma = 0;
+  for (const p of lista) {
+    suma += p.precio;
+  }
+  return suma;
+}
+
+const caros = productos.filter((p) => p.precio > 10);
+console.log(`Total: ${total(productos)}`, caros.length);
@@ -0,0 +1,13 @@
+fun promedio(notas: List<Int>): Double {
+    val suma = notas.sum()
+    val sinUsar = 0
+    return suma / cantidad
+}
+
+fun main() {
+    val notas = listOf(7, 8, 10)
+    println("Promedio de ${notas.size} notas: ${promedio(notas)} $faltante")
+    val binario = 0b102
+    val texto = "sin cerrar
+    imprimir(notas)
+}
@@ -0,0 +1,45 @@
+package com.curso.movil
+
+import kotlin.math.sqrt
+
+// Inventario de una tienda con clases de datos y lambdas
+data class Producto(val nombre: String, val precio: Double, var stock: Int = 0)
+
+enum class Categoria { COMIDA, BEBIDA, OTRO }
+
+class Inventario {
+    private val productos = mutableListOf<Producto>()
+
+    fun agregar(producto: Producto) {
+        productos.add(producto)
+    }
+
+    fun total(): Double = productos.sumOf { it.precio * it.stock }
+
+    fun caros(limite: Double) = productos.filter { p -> p.precio > limite }
+}
+
+fun Double.redondear(): Double = Math.round(this * 100) / 100.0
+
+fun main() {
+    val inventario = Inventario()
+    inventario.agregar(Producto("Café", 3.5, stock = 10))
+    inventario.agregar(Producto(nombre = "Té", precio = 2.25))
+
+    val categoria = Categoria.BEBIDA
+    val mensaje = when (categoria) {
+        Categoria.COMIDA -> "comida"
+        else -> "otra"
+    }
+
+    for (i in 1..3) {
+        println("Vuelta $i de 3: ${inventario.total().redondear()}")
+    }
+    val raiz = sqrt(16.0)
+    val pares = listOf(1, 2, 3, 4).map { n -> n * 2 }
+    println("Mensaje: $mensaje, raíz: $raiz, pares: $pares")
+    println(inventario.caros(3.0).size)
+    val hex = 0xFF_FF
+    val largo = 1_000_000L
+    println(hex + largo)
+}
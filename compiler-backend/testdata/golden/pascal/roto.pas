@@ -0,0 +1,12 @@
+program Roto;
+
+var
+  x: integer;
+  y: integer
+
+begin
+  x := 5;
+  if x > 3 then
+    writeln('mayor')
+  z := x + 1;
+  writeln('fin);
@@ -0,0 +1,15 @@
+program Promedio;
+
+var
+  notas: array[1..3] of integer;
+  i, suma: integer;
+
+begin
+  notas[1] := 70;
+  notas[2] := 85;
+  notas[3] := 90;
+  suma := 0;
+  for i := 1 to 3 do
+    suma := suma + notas[i];
+  writeln('Promedio: ', suma div 3);
+end.
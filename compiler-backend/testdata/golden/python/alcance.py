@@ -0,0 +1,33 @@
+# Parámetros, variables de ciclos y nombres ligados con 'as' están
+# declarados antes de usarse: este programa no tiene diagnósticos
+contador = 0
+
+
+def registrar(nombre, *extras, veces=1, **opciones):
+    global contador
+    contador += veces
+    return nombre, len(extras), opciones
+
+
+def pares(valores):
+    return [(i, v * 2) for i, v in enumerate(valores) if v % 2 == 0]
+
+
+class Inventario:
+    def __init__(self, items):
+        self.items = items
+
+    def total(self):
+        suma = 0
+        for _, (cantidad, precio) in self.items.items():
+            suma += cantidad * precio
+        return suma
+
+
+inventario = Inventario({"lápiz": (3, 2.5), "cuaderno": (2, 12)})
+doble = lambda x: x * 2
+try:
+    print(registrar("ana", 1, 2, veces=2, modo="rápido"))
+    print(pares([1, 2, 3, 4]), doble(inventario.total()), contador)
+except ValueError as error:
+    print(error)
@@ -0,0 +1,10 @@
+def maximo(valores)
+    mayor = valores[0]
+    for v in valores:
+        if v > mayor:
+            mayor = v
+    return mayor
+
+print(maximo([3, 1, 4])
+print(total)
+texto = 'sin cerrar
@@ -0,0 +1,17 @@
+def promedio(notas):
+    if not notas:
+        return 0
+    return sum(notas) / len(notas)
+
+
+class Estudiante:
+    def __init__(self, nombre, notas):
+        self.nombre = nombre
+        self.notas = notas
+
+    def aprobado(self):
+        return promedio(self.notas) >= 61
+
+
+alumno = Estudiante("Ana", [70, 85, 90])
+print(alumno.nombre, alumno.aprobado())
@@ -0,0 +1,11 @@
+func area(base: Double, altura: Double) -> Double {
+    let mitad = 0.5
+    let sinUsar = 1
+    return mitad * base * alto
+}
+
+let resultado = area(base: 3, altura: 4)
+print("Área: \(resultado) \(desconocido)")
+let octal = 0o78
+let texto = "sin cerrar
+imprimir(resultado)
@@ -0,0 +1,51 @@
+import Foundation
+
+// Agenda de contactos con structs, enums y clausuras
+enum Tipo {
+    case personal, trabajo
+    case otro(nombre: String)
+}
+
+struct Contacto {
+    let nombre: String
+    var telefono: String?
+    let tipo: Tipo
+
+    func descripcion() -> String {
+        return "\(nombre) (\(telefono ?? "sin teléfono"))"
+    }
+}
+
+class Agenda {
+    private var contactos: [Contacto] = []
+
+    func agregar(_ contacto: Contacto) {
+        contactos.append(contacto)
+    }
+
+    func buscar(por nombre: String) -> Contacto? {
+        return contactos.first { $0.nombre == nombre }
+    }
+
+    var cantidad: Int { contactos.count }
+}
+
+let agenda = Agenda()
+agenda.agregar(Contacto(nombre: "Ana", telefono: "555-0101", tipo: .personal))
+agenda.agregar(Contacto(nombre: "Luis", telefono: nil, tipo: .trabajo))
+
+if let ana = agenda.buscar(por: "Ana") {
+    print(ana.descripcion())
+}
+
+guard agenda.cantidad > 0 else {
+    fatalError("agenda vacía")
+}
+
+let nombres = ["Ana", "Luis"].map { n in n.uppercased() }
+for (indice, nombre) in nombres.enumerated() {
+    print("\(indice + 1). \(nombre)")
+}
+let mascara = 0b1010_1010
+let grande = 1_000_000
+print(mascara, grande)
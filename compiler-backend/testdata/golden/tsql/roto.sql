@@ -0,0 +1,8 @@
+SELECT Nombre, Nota,
+FROM Estudiantes
+WHERE Nota >= ;
+
+INSERT INTO Estudiantes (Id, Nombre VALUES (2, 'Luis');
+
+UPDATE Estudiantes SET Nota = 'alta' WHERE Id = 1
+SELECT * FROM ;
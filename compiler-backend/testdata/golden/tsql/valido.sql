@@ -0,0 +1,12 @@
+CREATE TABLE Estudiantes (
+    Id INT PRIMARY KEY,
+    Nombre VARCHAR(100) NOT NULL,
+    Nota DECIMAL(5, 2)
+);
+
+INSERT INTO Estudiantes (Id, Nombre, Nota) VALUES (1, 'Ana', 85.5);
+
+SELECT Nombre, Nota
+FROM Estudiantes
+WHERE Nota >= 61
+ORDER BY Nota DESC;
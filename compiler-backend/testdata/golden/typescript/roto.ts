@@ -0,0 +1,10 @@
+interface Persona {
+  nombre: string;
+  edad: number;
+
+function mayorDeEdad(p: Persona): boolean {
+  return p.edad >= 18
+}
+
+let edad: number = "veinte";
+console.log(mayorDeEdad(persona));
@@ -0,0 +1,14 @@
+interface Punto {
+  x: number;
+  y: number;
+}
+
+function distancia(a: Punto, b: Punto): number {
+  const dx = a.x - b.x;
+  const dy = a.y - b.y;
+  return Math.sqrt(dx * dx + dy * dy);
+}
+
+const origen: Punto = { x: 0, y: 0 };
+const destino: Punto = { x: 3, y: 4 };
+console.log(distancia(origen, destino));
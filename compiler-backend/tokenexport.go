@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/csv"
+	"strconv"
+	"strings"
+)
+
+// ──────────────────────── Exportar la lista de tokens ────────────────────
+//
+// tokensFormat en /api/v1/analyze y /api/v1/lex devuelve además los tokens
+// en un formato que otras herramientas leen sin conocer esta API: "csv"
+// agrega tokensCsv, una tabla para hojas de cálculo, y "textmate" completa
+// el campo scope de cada token con el nombre de ámbito de TextMate
+// (keyword.control.cpp, string.quoted.double.python...) que usan los temas
+// de VS Code, Sublime y otros editores para colorear.
+
+const (
+	tokensFormatCSV      = "csv"
+	tokensFormatTextMate = "textmate"
+)
+
+// validTokensFormat indica si format es un formato de exportación conocido
+func validTokensFormat(format string) bool {
+	return format == tokensFormatCSV || format == tokensFormatTextMate
+}
+
+// tokensCSV escribe una fila por token con las mismas columnas que APIToken
+func tokensCSV(tokens []APIToken) string {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	w.Write([]string{"type", "value", "line", "column", "endLine", "endColumn", "position"})
+	for _, t := range tokens {
+		w.Write([]string{t.Type, t.Value,
+			strconv.Itoa(t.Line), strconv.Itoa(t.Column),
+			strconv.Itoa(t.EndLine), strconv.Itoa(t.EndColumn),
+			strconv.Itoa(t.Position)})
+	}
+	w.Flush()
+	return b.String()
+}
+
+// Sufijo de los ámbitos de cada lenguaje, como en las gramáticas de TextMate
+var textMateLanguages = map[string]string{
+	"javascript": "js",
+	"typescript": "ts",
+	"tsql":       "sql",
+	"plsql":      "sql",
+}
+
+// Palabras clave según su papel; las de SQL se buscan en minúsculas
+var (
+	textMateControl = wordSet("if else elif elsif for foreach while do loop switch case default break continue return goto " +
+		"try catch except finally throw raise yield await with pass exit then when end begin go select defer fallthrough range")
+	textMateStorage = wordSet("int char float double bool boolean void long short unsigned signed auto string " +
+		"var let const def func function class struct enum interface union typedef type namespace package " +
+		"procedure proc trigger table view index cursor map chan number integer varchar varchar2 nvarchar date")
+	textMateModifiers = wordSet("static public private protected virtual extern inline constexpr mutable volatile " +
+		"register explicit friend override final async export abstract readonly declare global nonlocal")
+	textMateLanguageConstants = wordSet("true false null nullptr undefined none nil iota this self super")
+	textMateWordOperators     = wordSet("and or not in is new delete typeof instanceof sizeof like between exists as")
+)
+
+func wordSet(words string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
+
+// textMateScope devuelve el ámbito de TextMate de un token
+func textMateScope(t APIToken, language string) string {
+	lang := language
+	if suffix, ok := textMateLanguages[language]; ok {
+		lang = suffix
+	}
+	value := t.Value
+	word := strings.ToLower(value)
+
+	var scope string
+	switch t.Type {
+	case "KEYWORD":
+		switch {
+		case language == "html":
+			scope = "entity.name.tag"
+		case language == "css" && strings.HasPrefix(value, "@"):
+			scope = "keyword.control.at-rule"
+		case textMateLanguageConstants[word]:
+			scope = "constant.language"
+		case textMateControl[word]:
+			scope = "keyword.control"
+		case textMateStorage[word]:
+			scope = "storage.type"
+		case textMateModifiers[word]:
+			scope = "storage.modifier"
+		case textMateWordOperators[word]:
+			scope = "keyword.operator"
+		default:
+			scope = "keyword.other"
+		}
+	case "COMMENT":
+		switch {
+		case strings.HasPrefix(value, "/*"), strings.HasPrefix(value, "<!--"):
+			scope = "comment.block"
+		case strings.HasPrefix(value, "#"):
+			scope = "comment.line.number-sign"
+		case strings.HasPrefix(value, "--"):
+			scope = "comment.line.double-dash"
+		default:
+			scope = "comment.line.double-slash"
+		}
+	case "STRING":
+		// Sin el prefijo: f"..." y u8"..." son cadenas entre comillas dobles
+		prefix, value := literalPrefix(value, language)
+		switch {
+		case strings.HasSuffix(prefix, "R"):
+			scope = "string.quoted.other"
+		case strings.HasPrefix(value, `"""`), strings.HasPrefix(value, "'''"):
+			scope = "string.quoted.triple"
+		case strings.HasPrefix(value, `"`):
+			scope = "string.quoted.double"
+		case strings.HasPrefix(value, "'"), strings.HasPrefix(value, "N'"):
+			scope = "string.quoted.single"
+		case strings.HasPrefix(value, "`"):
+			scope = "string.template"
+		default:
+			// El texto entre etiquetas de HTML también es un STRING
+			scope = "string.unquoted"
+		}
+	case "NUMBER":
+		scope = "constant.numeric"
+	case "IDENTIFIER", "VARIABLE":
+		switch {
+		case language == "html":
+			scope = "entity.other.attribute-name"
+		case textMateLanguageConstants[word]:
+			scope = "variable.language"
+		default:
+			scope = "variable.other"
+		}
+	case "CONSTANT":
+		scope = "variable.other.constant"
+	case "FUNCTION":
+		scope = "entity.name.function"
+	case "CLASS":
+		scope = "entity.name.type.class"
+	case "OPERATOR":
+		scope = "keyword.operator"
+	case "DELIMITER":
+		switch value {
+		case "{", "}":
+			scope = "punctuation.section.block"
+		case "(", ")":
+			scope = "punctuation.section.parens"
+		case "[", "]":
+			scope = "punctuation.section.brackets"
+		case ";":
+			scope = "punctuation.terminator.statement"
+		case ",":
+			scope = "punctuation.separator.delimiter"
+		case ".":
+			scope = "punctuation.accessor"
+		default:
+			scope = "punctuation.separator"
+		}
+	case "PREPROCESSOR":
+		scope = "meta.preprocessor"
+	default:
+		scope = "invalid.illegal"
+	}
+	if lang == "" || lang == "unknown" {
+		return scope
+	}
+	return scope + "." + lang
+}
+
+// exportTokens aplica tokensFormat a la lista de tokens de una respuesta:
+// con "textmate" completa el scope de cada token y con "csv" devuelve la
+// tabla
+func exportTokens(tokens []APIToken, language, format string) (csvText string) {
+	switch format {
+	case tokensFormatCSV:
+		return tokensCSV(tokens)
+	case tokensFormatTextMate:
+		for i := range tokens {
+			tokens[i].Scope = textMateScope(tokens[i], language)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"strings"
+)
+
+// ─────────────────────────── Páginas de tokens ───────────────────────────
+//
+// Un archivo grande tiene decenas de miles de tokens y el frontend casi
+// nunca los necesita todos a la vez. tokenTypes deja solo los tokens de esos
+// tipos (["COMMENT", "STRING"]) y tokenOffset y tokenLimit devuelven una
+// página de los que quedan; tokenTotal dice cuántos hay en total para pedir
+// las siguientes. Se aplican en /api/v1/analyze y /api/v1/lex, y tokensCsv
+// exporta solo la página.
+//
+// Una petición con tokenLimit ya acota la respuesta, así que omitTokensOver
+// (ver tokenstream.go) no se le aplica.
+
+// tokenTypeNames son los tipos que acepta tokenTypes, como en APIToken.Type
+func tokenTypeNames() []string {
+	var names []string
+	for t := UNKNOWN; t <= PREPROCESSOR; t++ {
+		if t != WHITESPACE {
+			names = append(names, t.String())
+		}
+	}
+	return names
+}
+
+// unknownTokenType devuelve el primer tipo de types que no existe, o ""
+func unknownTokenType(types []string) string {
+	known := map[string]bool{}
+	for _, name := range tokenTypeNames() {
+		known[name] = true
+	}
+	for _, t := range types {
+		if !known[strings.ToUpper(t)] {
+			return t
+		}
+	}
+	return ""
+}
+
+// invalidTokenPage valida tokenOffset, tokenLimit y tokenTypes; devuelve
+// el mensaje de error o ""
+func (req AnalyzeRequest) invalidTokenPage() string {
+	if req.TokenOffset < 0 {
+		return "tokenOffset must be positive"
+	}
+	if req.TokenLimit < 0 {
+		return "tokenLimit must be positive"
+	}
+	if t := unknownTokenType(req.TokenTypes); t != "" {
+		return "tokenTypes: unknown token type " + t + "; expected one of " + strings.Join(tokenTypeNames(), ", ")
+	}
+	return ""
+}
+
+// pagesTokens indica si la petición pide solo una parte de los tokens
+func (req AnalyzeRequest) pagesTokens() bool {
+	return req.TokenOffset > 0 || req.TokenLimit > 0 || len(req.TokenTypes) > 0
+}
+
+// omitsTokens indica si la respuesta a req lleva tokenCounts en lugar de
+// tokens
+func (req AnalyzeRequest) omitsTokens() bool {
+	return req.TokenLimit == 0 && omitsTokens(req.Code, req.OmitTokensOver)
+}
+
+// pageTokens deja de tokens los de los tipos de req y de ellos la página
+// que pide; total es cuántos había antes de paginar
+func (req AnalyzeRequest) pageTokens(tokens []APIToken) (page []APIToken, total int) {
+	if len(req.TokenTypes) > 0 {
+		types := map[string]bool{}
+		for _, t := range req.TokenTypes {
+			types[strings.ToUpper(t)] = true
+		}
+		filtered := []APIToken{}
+		for _, t := range tokens {
+			if types[t.Type] {
+				filtered = append(filtered, t)
+			}
+		}
+		tokens = filtered
+	}
+	total = len(tokens)
+	start := req.TokenOffset
+	if start > total {
+		start = total
+	}
+	end := total
+	if req.TokenLimit > 0 && start+req.TokenLimit < end {
+		end = start + req.TokenLimit
+	}
+	return tokens[start:end], total
+}
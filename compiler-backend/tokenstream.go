@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ─────────────────────────── Tokens de entradas grandes ──────────────────
+//
+// Tokenize necesita el código entero en memoria y devuelve todos los tokens
+// en un slice; con un archivo de varios MB eso es el código, los tokens y,
+// en la API, un JSON varias veces más grande. TokenizeReader lee el código
+// por bloques y entrega cada token a una función apenas lo reconoce, así la
+// memoria queda acotada por el bloque y el token más largo.
+//
+// Un token solo se acepta si termina antes del final de lo leído (o si ya no
+// hay más que leer): un identificador cortado por el bloque se reconocería
+// a medias. Si no, se lee más y se vuelve a intentar. Un token que no cabe en
+// maxStreamToken (un comentario de bloque sin cerrar de varios MB) se corta
+// ahí, que es lo único en que la salida difiere de la de Tokenize.
+//
+// En la API, omitTokensOver (o OMIT_TOKENS_OVER) hace que /api/v1/analyze y
+// /api/v1/lex omitan la lista de tokens cuando el código supera ese tamaño y
+// devuelvan solo tokenCounts y los diagnósticos. Desde la línea de comandos,
+// `compiler-backend tokens archivo` imprime los tokens de a uno por línea.
+
+const (
+	// Bytes que se leen de cada vez
+	streamChunk = 64 << 10
+	// Lo más largo que puede ser un token antes de cortarlo
+	maxStreamToken = 1 << 20
+)
+
+// errStopTokens lo devuelve emit para dejar de tokenizar sin que
+// TokenizeReader informe un error
+var errStopTokens = errors.New("stop")
+
+// TokenizeReader tokeniza lo que lee de r, en el lenguaje lang o, si está
+// vacío, en el que se detecta con el primer bloque. Cada token pasa por emit
+// en orden, con las mismas posiciones que daría Tokenize; si emit devuelve
+// un error se detiene y lo devuelve, salvo errStopTokens
+func TokenizeReader(r io.Reader, lang string, emit func(Token) error) error {
+	return streamTokens(r, lang, func(tk Token, position int) error { return emit(tk) })
+}
+
+// streamTokens es TokenizeReader; emit recibe además el desplazamiento en
+// caracteres del token (APIToken.Position)
+func streamTokens(r io.Reader, lang string, emit func(tk Token, position int) error) error {
+	chunk := make([]byte, streamChunk)
+	var src string // lo leído desde base
+	base, pos, eof := 0, 0, false
+	// fill descarta lo ya tokenizado y agrega un bloque
+	fill := func() error {
+		n, err := r.Read(chunk)
+		src = src[pos:] + string(chunk[:n])
+		base, pos = base+pos, 0
+		if err == io.EOF {
+			eof = true
+		} else if err != nil {
+			return err
+		}
+		return nil
+	}
+
+	for !eof && len(src) < streamChunk {
+		if err := fill(); err != nil {
+			return err
+		}
+	}
+	if lang == "" {
+		lang = DetectLanguage(src)
+	}
+	language := languageFor(lang)
+	lp := language.Patterns()
+	matchers := language.Matchers()
+
+	line, col, runes := 1, 1, 0
+	for pos < len(src) || !eof {
+		// Tener siempre un bloque por delante evita reintentar casi todos
+		// los tokens cerca del final de lo leído
+		if !eof && len(src)-pos < streamChunk {
+			if err := fill(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		typ, lex := UNKNOWN, ""
+		for _, fn := range matchers {
+			if typ, lex = fn(&lp, src, pos); lex != "" {
+				break
+			}
+		}
+		if lex == "" {
+			_, size := utf8.DecodeRuneInString(src[pos:])
+			lex = src[pos : pos+size]
+		}
+		// El reconocedor puede mirar un carácter más allá del token (el
+		// final de una palabra); sin ese margen el token podría seguir
+		if !eof && pos+len(lex)+utf8.UTFMax > len(src) && len(src)-pos < maxStreamToken {
+			if err := fill(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		endLine, endCol := advanceLineColumn(line, col, lex)
+		if typ != WHITESPACE {
+			start := base + pos
+			tk := Token{Type: typ, Lexeme: lex, Start: start, End: start + len(lex),
+				Line: line, Column: col, EndLine: endLine, EndColumn: endCol}
+			if err := emit(tk, runes); err == errStopTokens {
+				return nil
+			} else if err != nil {
+				return err
+			}
+		}
+		pos += len(lex)
+		line, col = endLine, endCol
+		runes += utf8.RuneCountInString(lex)
+	}
+	return nil
+}
+
+// countTokens cuenta los tokens de cada tipo, con los nombres de APIToken.Type
+func countTokens(tokens []Token) map[string]int {
+	counts := map[string]int{}
+	for _, t := range tokens {
+		counts[strings.ToUpper(t.Type.String())]++
+	}
+	return counts
+}
+
+// omitTokensOver es el tamaño del código a partir del cual la respuesta no
+// lleva los tokens: el de la petición o, si no lo indica, OMIT_TOKENS_OVER.
+// 0 no los omite nunca
+func omitTokensOver(requested int) int {
+	if requested > 0 {
+		return requested
+	}
+	return GlobalConfig.OmitTokensOver
+}
+
+// omitsTokens indica si la respuesta al código code lleva solo tokenCounts
+// (ver también AnalyzeRequest.omitsTokens)
+func omitsTokens(code string, requested int) bool {
+	limit := omitTokensOver(requested)
+	return limit > 0 && len(code) > limit
+}
+
+// runTokensCLI atiende `compiler-backend tokens [opciones] archivo|-`:
+// imprime cada token en JSON, uno por línea, a medida que lo reconoce, o
+// con --count solo cuántos hay de cada tipo
+func runTokensCLI(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fset := flag.NewFlagSet("tokens", flag.ContinueOnError)
+	fset.SetOutput(stderr)
+	language := fset.String("language", "", "lenguaje del archivo (por defecto según la extensión o detectado)")
+	count := fset.Bool("count", false, "imprime solo cuántos tokens hay de cada tipo")
+	fset.Usage = func() {
+		fmt.Fprintf(stderr, "uso: %s tokens [opciones] archivo|-\n", filepath.Base(os.Args[0]))
+		fset.PrintDefaults()
+	}
+	if err := fset.Parse(args); err == flag.ErrHelp {
+		return exitClean
+	} else if err != nil {
+		return exitUsage
+	}
+	if fset.NArg() != 1 {
+		fset.Usage()
+		return exitUsage
+	}
+
+	lang := mapLanguage(*language)
+	in := stdin
+	if name := fset.Arg(0); name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitUsage
+		}
+		defer f.Close()
+		in = f
+		if lang == "" {
+			lang = cliExtensions[strings.ToLower(filepath.Ext(name))]
+		}
+	}
+	if _, ok := languages[lang]; lang != "" && !ok {
+		fmt.Fprintln(stderr, "lenguaje desconocido:", *language)
+		return exitUsage
+	}
+
+	out := bufio.NewWriter(stdout)
+	defer out.Flush()
+	enc := json.NewEncoder(out)
+	counts := map[string]int{}
+	err := streamTokens(in, lang, func(tk Token, position int) error {
+		typ := strings.ToUpper(tk.Type.String())
+		if *count {
+			counts[typ]++
+			return nil
+		}
+		return enc.Encode(APIToken{Type: typ, Value: tk.Lexeme, Line: tk.Line, Column: tk.Column,
+			EndLine: tk.EndLine, EndColumn: tk.EndColumn, Position: position})
+	})
+	if err != nil {
+		out.Flush()
+		fmt.Fprintln(stderr, err)
+		return exitUsage
+	}
+	if *count {
+		types := make([]string, 0, len(counts))
+		for typ := range counts {
+			types = append(types, typ)
+		}
+		sort.Strings(types)
+		for _, typ := range types {
+			fmt.Fprintf(out, "%-12s %d\n", typ, counts[typ])
+		}
+	}
+	return exitClean
+}
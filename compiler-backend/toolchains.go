@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ───────────────────────── Herramientas instaladas ───────────────────────
+//
+// Que el servidor analice un lenguaje no significa que pueda ejecutarlo: el
+// ejecutor local necesita g++, node, tsc, go o fpc en el host y el de Docker
+// necesita docker. GET /api/v1/toolchains informa, por lenguaje, si se puede
+// ejecutar, con qué motor y por qué no, junto con la ruta y la versión de
+// cada herramienta, para que el frontend deshabilite los lenguajes que no se
+// ejecutarían. Las herramientas se buscan una vez cada toolchainProbeTTL
+// (?refresh=true fuerza una búsqueda nueva); el resto del informe sale de la
+// configuración vigente.
+
+// Tiempo que se reutiliza una búsqueda de herramientas
+const toolchainProbeTTL = 5 * time.Minute
+
+// Tiempo máximo del comando que informa la versión de una herramienta
+const toolchainVersionTimeout = 5 * time.Second
+
+// Motores de ejecución que informa /api/v1/toolchains
+const (
+	EngineDocker    = "docker"
+	EngineBuiltin   = "builtin"   // resumen de la hoja de estilos (CSS) o documento para mostrar (HTML)
+	EngineSimulated = "simulated" // ejecución real desactivada
+)
+
+// toolVersionArgs son los argumentos que imprimen la versión de cada
+// herramienta; se conserva la primera línea de la salida
+var toolVersionArgs = map[string][]string{
+	"g++":     {"--version"},
+	"python3": {"--version"},
+	"node":    {"--version"},
+	"tsc":     {"--version"},
+	"go":      {"version"},
+	"fpc":     {"-iV"},
+	"docker":  {"--version"},
+}
+
+// localToolchains son las herramientas que usa el ejecutor local de cada
+// lenguaje (ver languages.go); los que no están no se ejecutan en el host
+var localToolchains = map[string][]string{
+	"cpp":        {"g++"},
+	"python":     {"python3"},
+	"javascript": {"node"},
+	"typescript": {"tsc", "node"},
+	"go":         {"go"},
+	"pascal":     {"fpc"},
+}
+
+// APIToolStatus es una herramienta buscada en el host
+type APIToolStatus struct {
+	Name      string `json:"name"`
+	Available bool   `json:"available"`
+	Path      string `json:"path,omitempty"`
+	Version   string `json:"version,omitempty"`
+}
+
+// APILanguageToolchain indica si se puede ejecutar un lenguaje. Engine es
+// native, docker, embedded (intérprete integrado), builtin o simulated;
+// Reason explica por qué no se ejecuta
+type APILanguageToolchain struct {
+	Language   string          `json:"language"`
+	Executable bool            `json:"executable"`
+	Engine     string          `json:"engine,omitempty"`
+	Reason     string          `json:"reason,omitempty"`
+	Tools      []APIToolStatus `json:"tools,omitempty"`
+}
+
+// Respuesta de GET /api/v1/toolchains
+type APIToolchainsResponse struct {
+	Backend       string                 `json:"backend"`
+	RealExecution bool                   `json:"realExecution"`
+	CheckedAt     time.Time              `json:"checkedAt"`
+	Languages     []APILanguageToolchain `json:"languages"`
+}
+
+type toolchainProbe struct {
+	mu      sync.Mutex
+	tools   map[string]APIToolStatus
+	checked time.Time
+}
+
+var toolchains = &toolchainProbe{}
+
+// snapshot devuelve las herramientas encontradas y cuándo se buscaron; las
+// busca de nuevo si la búsqueda venció o si refresh
+func (p *toolchainProbe) snapshot(refresh bool) (map[string]APIToolStatus, time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if refresh || p.tools == nil || time.Since(p.checked) > toolchainProbeTTL {
+		p.tools = probeTools()
+		p.checked = time.Now().UTC()
+	}
+	return p.tools, p.checked
+}
+
+// probeTools busca todas las herramientas a la vez: cada versión puede
+// tardar (node, docker)
+func probeTools() map[string]APIToolStatus {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	tools := make(map[string]APIToolStatus, len(toolVersionArgs))
+	for name, args := range toolVersionArgs {
+		wg.Add(1)
+		go func(name string, args []string) {
+			defer wg.Done()
+			status := probeTool(name, args)
+			mu.Lock()
+			tools[name] = status
+			mu.Unlock()
+		}(name, args)
+	}
+	wg.Wait()
+	return tools
+}
+
+func probeTool(name string, args []string) APIToolStatus {
+	status := APIToolStatus{Name: name}
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return status
+	}
+	status.Path = path
+	ctx, cancel := context.WithTimeout(context.Background(), toolchainVersionTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, args...).CombinedOutput()
+	if err != nil {
+		// Está en el PATH pero no funciona (un enlace roto, un wrapper sin
+		// su instalación): no sirve para ejecutar
+		return status
+	}
+	status.Available = true
+	status.Version, _, _ = strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return status
+}
+
+// toolchainFor decide si config puede ejecutar language con las
+// herramientas tools
+func toolchainFor(language string, config CompilerConfig, tools map[string]APIToolStatus) APILanguageToolchain {
+	tc := APILanguageToolchain{Language: language}
+	var needed []string
+	switch {
+	case language == "css" || language == "html":
+		tc.Engine = EngineBuiltin
+	case !config.EnableRealExecution && language == "python":
+		tc.Engine = EngineEmbedded
+	case !config.EnableRealExecution:
+		tc.Engine, tc.Reason = EngineSimulated, "La ejecución real está desactivada: la salida es simulada"
+	case language == "javascript" && config.JSEngine == EngineEmbedded:
+		tc.Engine = EngineEmbedded
+	case config.ExecutionBackend == BackendDocker:
+		tc.Engine, needed = EngineDocker, []string{"docker"}
+		if _, ok := dockerCommands[language]; !ok {
+			tc.Reason = "El ejecutor Docker no soporta " + language
+		}
+	case language == "python" && !tools["python3"].Available:
+		// Sin python3 el ejecutor local usa el intérprete integrado
+		tc.Engine, needed = EngineEmbedded, []string{"python3"}
+	default:
+		tc.Engine, needed = EngineNative, localToolchains[language]
+		if needed == nil {
+			tc.Engine, tc.Reason = "", "No hay un ejecutor para "+language
+		}
+	}
+
+	var missing []string
+	for _, name := range needed {
+		status := tools[name]
+		tc.Tools = append(tc.Tools, status)
+		if !status.Available && tc.Engine != EngineEmbedded {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 && tc.Reason == "" {
+		tc.Reason = "Falta en el servidor: " + strings.Join(missing, ", ")
+	}
+	// Lo que decidió el administrador explica más que lo que falta instalar
+	if !config.languageAllowed(language) {
+		tc.Reason = fmt.Sprintf("La ejecución de %s está desactivada en el servidor", language)
+	}
+	tc.Executable = tc.Reason == ""
+	return tc
+}
+
+// toolchainsHandler atiende GET /api/v1/toolchains
+func toolchainsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	refresh := false
+	if v := r.URL.Query().Get("refresh"); v != "" {
+		var err error
+		if refresh, err = strconv.ParseBool(v); err != nil {
+			httpError(w, "refresh must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+
+	tools, checked := toolchains.snapshot(refresh)
+	config := currentConfig()
+	response := APIToolchainsResponse{
+		Backend:       config.ExecutionBackend,
+		RealExecution: config.EnableRealExecution,
+		CheckedAt:     checked,
+	}
+	names := make([]string, 0, len(languages))
+	for name := range languages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		response.Languages = append(response.Languages, toolchainFor(name, config, tools))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,407 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ─────────────────────────── Ejecución paso a paso ───────────────────────
+//
+// POST /api/v1/trace ejecuta un programa de Python o JavaScript con su
+// intérprete integrado (ver interp.go) y devuelve la lista de pasos para un
+// visualizador al estilo de Python Tutor: en cada sentencia ejecutada, en
+// cada llamada y retorno de una función del programa y en la excepción que
+// lo termina, la línea, la función, la profundidad de la pila y el valor de
+// las variables locales (y las globales dentro de una función) con la
+// representación que imprimiría el lenguaje. Las funciones, clases y
+// módulos no se listan, ni los nombres que ya define el entorno.
+//
+// La traza se corta en maxSteps pasos: el programa se detiene ahí y la
+// respuesta indica truncated. Los demás límites de ejecución se aplican
+// igual que en /api/v1/analyze.
+//
+// El mismo registro implementa el breakpoint de /api/v1/analyze: con
+// breakpoint el programa se ejecuta hasta la primera vez que llega a esa
+// línea, se detiene antes de ejecutarla y el resultado de la ejecución trae
+// ese único paso.
+
+// TraceRequest es el cuerpo de POST /api/v1/trace
+type TraceRequest struct {
+	Code     string `json:"code"`
+	Language string `json:"language"`
+	Stdin    string `json:"stdin,omitempty"`
+	// Pasos máximos de la traza; 0 usa MAX_TRACE_STEPS, que también es el
+	// máximo
+	MaxSteps       int `json:"maxSteps,omitempty"`
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+}
+
+// Eventos de un paso
+const (
+	traceLine      = "line"      // antes de ejecutar la sentencia
+	traceCall      = "call"      // al entrar a una función, en la línea de la llamada
+	traceReturn    = "return"    // al salir de una función, con el valor devuelto
+	traceException = "exception" // la excepción sin capturar que termina el programa
+)
+
+// Largo máximo de la representación de un valor en la traza
+const traceValueMax = 200
+
+// APITraceStep es un paso de la traza. StdoutLength es cuánto de stdout
+// había impreso el programa hasta ese paso
+type APITraceStep struct {
+	Event        string            `json:"event"`
+	Line         int               `json:"line"`
+	Function     string            `json:"function,omitempty"`
+	Depth        int               `json:"depth"`
+	Locals       map[string]string `json:"locals"`
+	Globals      map[string]string `json:"globals,omitempty"`
+	ReturnValue  string            `json:"returnValue,omitempty"`
+	Exception    string            `json:"exception,omitempty"`
+	StdoutLength int               `json:"stdoutLength"`
+}
+
+// Respuesta de POST /api/v1/trace
+type APITraceResponse struct {
+	Language  string         `json:"language"`
+	Steps     []APITraceStep `json:"steps"`
+	Truncated bool           `json:"truncated"`
+	Stdout    string         `json:"stdout"`
+	Stderr    string         `json:"stderr"`
+	// nil si el programa no terminó: la traza se cortó o venció el tiempo
+	ExitCode *int `json:"exitCode"`
+	TimedOut bool `json:"timedOut"`
+}
+
+// traceRecorder acumula los pasos de un programa interpretado; se activa
+// con interpBudget.trace
+type traceRecorder struct {
+	steps     []APITraceStep
+	max       int
+	truncated bool
+	// Mientras se arma un paso: representar un valor puede ejecutar código
+	// del programa (__repr__) que no debe agregar pasos
+	busy bool
+	// Última línea registrada, la de las llamadas y retornos
+	line int
+	// Nombres globales que define el entorno y no el programa
+	hidden map[string]bool
+	// Línea del breakpoint: solo se registra el primer paso en ella y ahí
+	// se detiene el programa; 0 registra todos
+	stopAt int
+}
+
+// skip indica si el evento no se registra; se consulta antes de armar el
+// paso, que con un breakpoint casi nunca hace falta
+func (t *traceRecorder) skip(event string, line int) bool {
+	return t.busy || t.stopAt > 0 && (event != traceLine || line != t.stopAt)
+}
+
+// add agrega un paso; si ya hay max detiene el programa con stopTrace
+func (t *traceRecorder) add(b *interpBudget, step APITraceStep) {
+	if len(t.steps) == t.max {
+		t.truncated = true
+		panic(stopTrace)
+	}
+	if step.Event == traceLine {
+		t.line = step.Line
+	}
+	step.StdoutLength = b.out.stdoutLen()
+	t.steps = append(t.steps, step)
+	if t.stopAt > 0 {
+		panic(stopTrace)
+	}
+}
+
+func traceValue(s string) string {
+	if len(s) <= traceValueMax {
+		return s
+	}
+	cut := traceValueMax
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "…"
+}
+
+// ───── Python ─────
+
+// traceEvent registra un paso con las variables de s
+func (it *pyInterp) traceEvent(event string, line int, s *pyScope, ret pyValue) {
+	t := it.b.trace
+	if t.skip(event, line) {
+		return
+	}
+	t.busy = true
+	defer func() { t.busy = false }()
+	step := APITraceStep{Event: event, Line: line, Function: it.frame().name, Depth: len(it.frames) - 1,
+		Locals: it.traceVars(s.vars)}
+	if s != it.globals {
+		step.Globals = it.traceVars(it.globals.vars)
+	}
+	if event == traceReturn {
+		step.ReturnValue = it.traceRepr(ret)
+	}
+	t.add(it.b, step)
+}
+
+func (it *pyInterp) traceLine(n *ParseNode, s *pyScope) {
+	line, _ := it.index.lineColumn(n.Pos)
+	it.traceEvent(traceLine, line, s, nil)
+}
+
+// traceException registra la excepción que termina el programa, en la
+// línea donde se lanzó
+func (it *pyInterp) traceException(exc *pyInstance) {
+	t := it.b.trace
+	if t.skip(traceException, 0) || it.isInstance(exc, it.types["SystemExit"]) {
+		return
+	}
+	line := t.line
+	if n := len(exc.traceback); n > 0 {
+		line, _ = it.index.lineColumn(exc.traceback[n-1].node.Pos)
+	}
+	t.busy = true
+	step := APITraceStep{Event: traceException, Line: line, Function: it.frame().name, Depth: len(it.frames) - 1,
+		Locals: it.traceVars(it.globals.vars), Exception: strings.TrimSuffix(it.exceptionLine(exc), "\n")}
+	t.busy = false
+	t.add(it.b, step)
+}
+
+func (it *pyInterp) traceVars(vars map[string]pyValue) map[string]string {
+	out := make(map[string]string, len(vars))
+	for name, v := range vars {
+		switch v.(type) {
+		case *pyFunction, *pyClass, *pyModule:
+			continue
+		}
+		if strings.HasPrefix(name, "__") {
+			continue
+		}
+		out[name] = it.traceRepr(v)
+	}
+	return out
+}
+
+// traceRepr es repr(v); si el __repr__ del programa lanza una excepción se
+// muestra el nombre de la clase
+func (it *pyInterp) traceRepr(v pyValue) (s string) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(*pyRaise); !ok {
+				panic(r)
+			}
+			s = "<" + it.typeName(v) + ">"
+		}
+	}()
+	return traceValue(it.repr(v))
+}
+
+// ───── JavaScript ─────
+
+// traceEvent registra un paso con las variables visibles desde scope hasta
+// el ámbito de su función
+func (it *jsInterp) traceEvent(event string, line int, scope *jsScope, ret jsValue) {
+	t := it.b.trace
+	if t.skip(event, line) {
+		return
+	}
+	t.busy = true
+	defer func() { t.busy = false }()
+	step := APITraceStep{Event: event, Line: line, Depth: it.depth, Locals: map[string]string{}}
+	s := scope
+	for ; s != nil; s = s.parent {
+		for name, b := range s.vars {
+			if _, seen := step.Locals[name]; !seen && it.traceVisible(s, name, b.value) {
+				step.Locals[name] = it.traceInspect(b.value)
+			}
+		}
+		if s.function {
+			break
+		}
+	}
+	if s != nil && s.fn != nil {
+		step.Function = s.fn.name
+		if step.Function == "" {
+			step.Function = "<anonymous>"
+		}
+		step.Globals = map[string]string{}
+		for name, b := range it.global.vars {
+			if it.traceVisible(it.global, name, b.value) {
+				step.Globals[name] = it.traceInspect(b.value)
+			}
+		}
+	}
+	if event == traceReturn {
+		step.ReturnValue = it.traceInspect(ret)
+	}
+	t.add(it.b, step)
+}
+
+func (it *jsInterp) traceLine(n *ParseNode, scope *jsScope) {
+	line, _ := it.index.lineColumn(n.Pos)
+	it.traceEvent(traceLine, line, scope, nil)
+}
+
+// traceException registra la excepción que termina el programa, en la
+// línea donde se lanzó
+func (it *jsInterp) traceException(thrown *jsThrow) {
+	t := it.b.trace
+	if t.skip(traceException, 0) {
+		return
+	}
+	line, _ := it.index.lineColumn(thrown.pos)
+	t.busy = true
+	step := APITraceStep{Event: traceException, Line: line, Locals: map[string]string{}}
+	for name, b := range it.global.vars {
+		if it.traceVisible(it.global, name, b.value) {
+			step.Locals[name] = it.traceInspect(b.value)
+		}
+	}
+	// Un Error se muestra con su stack; basta la primera línea
+	step.Exception, _, _ = strings.Cut(it.traceInspect(thrown.value), "\n")
+	t.busy = false
+	t.add(it.b, step)
+}
+
+// traceVisible indica si la variable name de s se muestra en la traza
+func (it *jsInterp) traceVisible(s *jsScope, name string, v jsValue) bool {
+	if _, ok := v.(*jsFunction); ok {
+		return false
+	}
+	if s == it.global {
+		return !it.b.trace.hidden[name]
+	}
+	return name != "arguments" || s.fn == nil
+}
+
+func (it *jsInterp) traceInspect(v jsValue) (s string) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(*jsThrow); !ok {
+				panic(r)
+			}
+			s = "[object]"
+		}
+	}()
+	return traceValue(it.inspect(v))
+}
+
+// traceHideGlobals marca como del entorno los nombres globales definidos
+// hasta ahora, antes de ejecutar el programa
+func (it *jsInterp) traceHideGlobals() {
+	it.b.trace.hidden = make(map[string]bool, len(it.global.vars))
+	for name := range it.global.vars {
+		it.b.trace.hidden[name] = true
+	}
+}
+
+// ───── Breakpoints ─────
+
+// breakpointExecutor ejecuta el programa con su intérprete integrado hasta
+// la línea del breakpoint y agrega al resultado el estado en ese punto
+type breakpointExecutor struct {
+	embeddedExecutor
+	line int
+}
+
+func (e breakpointExecutor) Execute(code string, symbols []Symbol) ExecutionResult {
+	recorder := &traceRecorder{max: 1, stopAt: e.line}
+	res := traceExecutor{e.embeddedExecutor, recorder}.Execute(code, symbols)
+	if len(recorder.steps) > 0 {
+		res.Breakpoint = &recorder.steps[0]
+	}
+	return res
+}
+
+// ───── Endpoint ─────
+
+// traceExecutor ejecuta el programa con su intérprete integrado y la traza
+// activada
+type traceExecutor struct {
+	embeddedExecutor
+	trace *traceRecorder
+}
+
+func (e traceExecutor) Execute(code string, _ []Symbol) ExecutionResult {
+	run := embeddedInterpreters[e.language](code)
+	return runEmbedded(e.timeout, limitsFor(e.language), e.input, func(b *interpBudget, input ProgramInput, stdin string) int {
+		b.trace = e.trace
+		return run(b, input, stdin)
+	})
+}
+
+// traceHandler atiende POST /api/v1/trace
+func traceHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req TraceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w)
+		return
+	}
+	if req.Code == "" {
+		httpError(w, "Code is required", http.StatusBadRequest)
+		return
+	}
+	if msg := codeTooLarge(req.Code); msg != "" {
+		writeAPIError(w, http.StatusRequestEntityTooLarge, "code_too_large", msg)
+		return
+	}
+	if msg := invalidStdin([]string{req.Stdin}); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if req.MaxSteps < 0 {
+		httpError(w, "maxSteps must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	language := mapLanguage(req.Language)
+	if language == "" {
+		language = DetectLanguage(req.Code)
+	}
+	if embeddedInterpreters[language] == nil {
+		writeAPIError(w, http.StatusBadRequest, "unsupported_language",
+			"tracing is only available for python and javascript, not "+language)
+		return
+	}
+	principal := principalFrom(r)
+	if status, msg := authorizeAnalysis(principal, language, true); status != 0 {
+		rejectAnalysis(w, status, msg)
+		return
+	}
+
+	maxSteps := GlobalConfig.MaxTraceSteps
+	if req.MaxSteps > 0 && req.MaxSteps < maxSteps {
+		maxSteps = req.MaxSteps
+	}
+	recorder := &traceRecorder{steps: []APITraceStep{}, max: maxSteps}
+	timeout := ExecutionTimeoutFor(req.TimeoutSeconds)
+	input := ProgramInput{Stdin: []string{req.Stdin}, Context: r.Context()}
+	start := time.Now()
+	res := limitedExecutor{traceExecutor{embeddedExecutor{language, timeout, input}, recorder}, timeout}.Execute(req.Code, nil)
+	principal.recordExecution(time.Since(start))
+
+	response := APITraceResponse{
+		Language:  language,
+		Steps:     recorder.steps,
+		Truncated: recorder.truncated,
+		Stdout:    res.RunStdout,
+		Stderr:    res.RunStderr,
+		ExitCode:  res.ExitCode,
+		TimedOut:  res.TimedOut,
+	}
+	if res.Transient && !res.TimedOut && res.RunStdout == "" && res.RunStderr == "" {
+		// El servidor estaba ocupado y no se ejecutó nada
+		response.Stderr = res.Output
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"hash/fnv"
+	"net/http"
+)
+
+// ──────────────────────── Diferencias entre versiones ────────────────────
+//
+// POST /api/v1/analyze/diff compara los árboles sintácticos de dos versiones
+// del código, para que el frontend muestre qué cambió entre un intento y el
+// siguiente: qué sentencias y expresiones se agregaron, cuáles se quitaron y
+// cuáles cambiaron de valor (otro nombre, otro literal, otro operador). A
+// diferencia de un diff de texto, mover una línea de lugar por una llave o
+// cambiar la indentación no es un cambio.
+//
+// Los hijos de cada par de nodos se alinean por la subsecuencia común más
+// larga de subárboles idénticos; lo que queda entre dos subárboles iguales
+// se empareja en orden por tipo de nodo y se compara recursivamente. Un nodo
+// agregado o quitado se informa una sola vez, sin su contenido.
+
+// TreeDiffRequest es el cuerpo de POST /api/v1/analyze/diff
+type TreeDiffRequest struct {
+	Before   string `json:"before"`
+	After    string `json:"after"`
+	Language string `json:"language"`
+}
+
+// Tipos de cambio
+const (
+	changeAdded    = "added"
+	changeRemoved  = "removed"
+	changeModified = "modified"
+)
+
+// APITreeNodeRef ubica un nodo en una de las versiones
+type APITreeNodeRef struct {
+	Label     string `json:"label"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+	EndLine   int    `json:"endLine"`
+	EndColumn int    `json:"endColumn"`
+}
+
+// APITreeChange es un nodo agregado (solo after), quitado (solo before) o
+// modificado (los dos, con distinta etiqueta)
+type APITreeChange struct {
+	Change string          `json:"change"`
+	Kind   string          `json:"kind"`
+	Before *APITreeNodeRef `json:"before,omitempty"`
+	After  *APITreeNodeRef `json:"after,omitempty"`
+}
+
+// Respuesta de POST /api/v1/analyze/diff
+type APITreeDiffResponse struct {
+	Language string          `json:"language"`
+	Changes  []APITreeChange `json:"changes"`
+	Added    int             `json:"added"`
+	Removed  int             `json:"removed"`
+	Modified int             `json:"modified"`
+}
+
+// hashedNode es un nodo con el hash de su subárbol, para reconocer en O(1)
+// los subárboles idénticos
+type hashedNode struct {
+	node     *ParseNode
+	hash     uint64
+	children []*hashedNode
+}
+
+func hashTree(n *ParseNode) *hashedNode {
+	h := fnv.New64a()
+	h.Write([]byte(n.Kind))
+	h.Write([]byte{0})
+	h.Write([]byte(n.Label))
+	hn := &hashedNode{node: n}
+	var buf [8]byte
+	for i := range n.Children {
+		c := hashTree(&n.Children[i])
+		hn.children = append(hn.children, c)
+		binary.LittleEndian.PutUint64(buf[:], c.hash)
+		h.Write(buf[:])
+	}
+	hn.hash = h.Sum64()
+	return hn
+}
+
+// treeDiff acumula los cambios entre dos árboles
+type treeDiff struct {
+	before, after *sourceIndex
+	changes       []APITreeChange
+}
+
+func treeNodeRef(src *sourceIndex, n *ParseNode) *APITreeNodeRef {
+	line, column := src.lineColumn(n.Pos)
+	endLine, endColumn := src.lineColumn(n.End)
+	return &APITreeNodeRef{Label: n.Label, Line: line, Column: column, EndLine: endLine, EndColumn: endColumn}
+}
+
+// node compara dos nodos del mismo tipo
+func (d *treeDiff) node(a, b *hashedNode) {
+	if a.hash == b.hash {
+		return
+	}
+	if a.node.Label != b.node.Label {
+		d.changes = append(d.changes, APITreeChange{Change: changeModified, Kind: a.node.Kind,
+			Before: treeNodeRef(d.before, a.node), After: treeNodeRef(d.after, b.node)})
+	}
+	d.children(a.children, b.children)
+}
+
+// Celdas máximas de la tabla de la subsecuencia común: más allá (miles de
+// sentencias cambiadas a la vez) los hijos se comparan solo con gap
+const maxTreeDiffCells = 1 << 22
+
+// children alinea dos listas de hijos: los subárboles idénticos de la
+// subsecuencia común más larga quedan fijos y los tramos entre ellos se
+// comparan con gap
+func (d *treeDiff) children(a, b []*hashedNode) {
+	// Lo común al principio y al final no hace falta en la tabla: en un
+	// cambio típico solo queda el tramo editado
+	for len(a) > 0 && len(b) > 0 && a[0].hash == b[0].hash {
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1].hash == b[len(b)-1].hash {
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+	if len(a) == 0 || len(b) == 0 || len(a)*len(b) > maxTreeDiffCells {
+		d.gap(a, b)
+		return
+	}
+
+	// lcs[i][j] es la subsecuencia común más larga de a[i:] y b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i].hash == b[j].hash:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	i, j, gapA, gapB := 0, 0, 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i].hash == b[j].hash:
+			d.gap(a[gapA:i], b[gapB:j])
+			i, j = i+1, j+1
+			gapA, gapB = i, j
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	d.gap(a[gapA:], b[gapB:])
+}
+
+// gap compara los hijos entre dos subárboles idénticos: empareja en orden
+// los del mismo tipo y el resto se quitó o se agregó
+func (d *treeDiff) gap(a, b []*hashedNode) {
+	next := 0
+	for _, x := range a {
+		k := next
+		for k < len(b) && b[k].node.Kind != x.node.Kind {
+			k++
+		}
+		if k == len(b) {
+			d.changes = append(d.changes, APITreeChange{Change: changeRemoved, Kind: x.node.Kind, Before: treeNodeRef(d.before, x.node)})
+			continue
+		}
+		for _, y := range b[next:k] {
+			d.added(y)
+		}
+		d.node(x, b[k])
+		next = k + 1
+	}
+	for _, y := range b[next:] {
+		d.added(y)
+	}
+}
+
+func (d *treeDiff) added(y *hashedNode) {
+	d.changes = append(d.changes, APITreeChange{Change: changeAdded, Kind: y.node.Kind, After: treeNodeRef(d.after, y.node)})
+}
+
+// diffTrees devuelve los cambios de before a after
+func diffTrees(before, after []ParseNode, beforeSrc, afterSrc *sourceIndex) []APITreeChange {
+	hash := func(nodes []ParseNode) []*hashedNode {
+		hashed := make([]*hashedNode, len(nodes))
+		for i := range nodes {
+			hashed[i] = hashTree(&nodes[i])
+		}
+		return hashed
+	}
+	d := &treeDiff{before: beforeSrc, after: afterSrc, changes: []APITreeChange{}}
+	d.children(hash(before), hash(after))
+	return d.changes
+}
+
+// treeDiffHandler atiende POST /api/v1/analyze/diff
+func treeDiffHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req TreeDiffRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w)
+		return
+	}
+	if req.Before == "" && req.After == "" {
+		httpError(w, "before or after is required", http.StatusBadRequest)
+		return
+	}
+	for _, code := range []string{req.Before, req.After} {
+		if msg := codeTooLarge(code); msg != "" {
+			writeAPIError(w, http.StatusRequestEntityTooLarge, "code_too_large", msg)
+			return
+		}
+	}
+
+	language := mapLanguage(req.Language)
+	if language == "" {
+		language = DetectLanguage(req.After)
+	}
+	if language == "unknown" {
+		language = DetectLanguage(req.Before)
+	}
+	if status, msg := authorizeAnalysis(principalFrom(r), language, false); status != 0 {
+		rejectAnalysis(w, status, msg)
+		return
+	}
+
+	beforeTree, _ := NewParser(Tokenize(req.Before, language), language, req.Before).Parse()
+	afterTree, _ := NewParser(Tokenize(req.After, language), language, req.After).Parse()
+	response := APITreeDiffResponse{
+		Language: language,
+		Changes:  diffTrees(beforeTree, afterTree, newSourceIndex(req.Before), newSourceIndex(req.After)),
+	}
+	for _, c := range response.Changes {
+		switch c.Change {
+		case changeAdded:
+			response.Added++
+		case changeRemoved:
+			response.Removed++
+		case changeModified:
+			response.Modified++
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
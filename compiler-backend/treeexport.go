@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ────────────────────── Exportar el árbol sintáctico ─────────────────────
+//
+// El árbol se puede pedir como texto de Graphviz (DOT) o como diagrama de
+// flujo de Mermaid, para que el frontend o el estudiante lo dibujen con una
+// herramienta de diagramas en vez de calcular la disposición de los nodos.
+// /api/v1/analyze recibe treeFormat y devuelve el texto en tree; la ruta
+// /api/v1/analyze/tree?format=dot solo tokeniza y construye el árbol, sin
+// análisis semántico ni ejecución, y responde el texto directamente. Acepta
+// el código en un AnalyzeRequest (POST) o en la query string (GET), así un
+// enlace basta para abrir el diagrama.
+
+const (
+	treeFormatDOT     = "dot"
+	treeFormatMermaid = "mermaid"
+)
+
+// Tipo de contenido de la respuesta de /analyze/tree para cada formato
+var treeContentTypes = map[string]string{
+	treeFormatDOT:     "text/vnd.graphviz; charset=utf-8",
+	treeFormatMermaid: "text/plain; charset=utf-8",
+}
+
+// Las etiquetas largas (una cadena, un selector) se acortan para que no
+// deformen el diagrama
+const treeLabelMaxRunes = 40
+
+// validTreeFormat indica si format es un formato de exportación conocido
+func validTreeFormat(format string) bool {
+	_, ok := treeContentTypes[format]
+	return ok
+}
+
+// renderTree devuelve el árbol en el formato pedido
+func renderTree(nodes []ParseNode, format string) string {
+	if format == treeFormatMermaid {
+		return renderTreeMermaid(nodes)
+	}
+	return renderTreeDOT(nodes)
+}
+
+// treeNodeText es el texto de un nodo: su tipo y, debajo, su etiqueta si
+// agrega algo
+func treeNodeText(n ParseNode) (kind, label string) {
+	kind = n.Kind
+	if kind == "" {
+		kind = "node"
+	}
+	label = strings.Join(strings.Fields(n.Label), " ")
+	if label == kind {
+		label = ""
+	}
+	if runes := []rune(label); len(runes) > treeLabelMaxRunes {
+		label = string(runes[:treeLabelMaxRunes-1]) + "…"
+	}
+	return kind, label
+}
+
+// walkTree numera los nodos en preorden y llama a visit con el id de cada
+// nodo y el de su padre (-1 en las raíces)
+func walkTree(nodes []ParseNode, visit func(id, parent int, n ParseNode)) {
+	next := 0
+	var walk func(nodes []ParseNode, parent int)
+	walk = func(nodes []ParseNode, parent int) {
+		for _, n := range nodes {
+			id := next
+			next++
+			visit(id, parent, n)
+			walk(n.Children, id)
+		}
+	}
+	walk(nodes, -1)
+}
+
+// renderTreeDOT genera un digraph con un nodo por ParseNode
+func renderTreeDOT(nodes []ParseNode) string {
+	var b strings.Builder
+	b.WriteString("digraph AST {\n")
+	b.WriteString("  node [shape=box, style=rounded, fontname=\"Helvetica\"];\n")
+	walkTree(nodes, func(id, parent int, n ParseNode) {
+		kind, label := treeNodeText(n)
+		text := dotEscape(kind)
+		if label != "" {
+			text += `\n` + dotEscape(label)
+		}
+		fmt.Fprintf(&b, "  n%d [label=\"%s\"];\n", id, text)
+		if parent >= 0 {
+			fmt.Fprintf(&b, "  n%d -> n%d;\n", parent, id)
+		}
+	})
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// renderTreeMermaid genera un flowchart de arriba hacia abajo
+func renderTreeMermaid(nodes []ParseNode) string {
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+	walkTree(nodes, func(id, parent int, n ParseNode) {
+		kind, label := treeNodeText(n)
+		text := mermaidEscape(kind)
+		if label != "" {
+			text += "<br/>" + mermaidEscape(label)
+		}
+		fmt.Fprintf(&b, "  n%d[\"%s\"]\n", id, text)
+		if parent >= 0 {
+			fmt.Fprintf(&b, "  n%d --> n%d\n", parent, id)
+		}
+	})
+	return b.String()
+}
+
+var dotReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+func dotEscape(s string) string { return dotReplacer.Replace(s) }
+
+// Mermaid no admite comillas dentro de la etiqueta y trataría < > como
+// HTML: se escriben con sus códigos de entidad, y # primero porque los
+// introduce
+var mermaidReplacer = strings.NewReplacer(`#`, `#35;`, `"`, `#quot;`, `<`, `#lt;`, `>`, `#gt;`)
+
+func mermaidEscape(s string) string { return mermaidReplacer.Replace(s) }
+
+// analyzeTreeHandler atiende /api/v1/analyze/tree?format=dot|mermaid; sin
+// format responde DOT
+func analyzeTreeHandler(w http.ResponseWriter, r *http.Request) {
+	var req AnalyzeRequest
+	switch r.Method {
+	case http.MethodGet:
+		req.Code = r.URL.Query().Get("code")
+		req.Language = r.URL.Query().Get("language")
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			invalidJSON(w)
+			return
+		}
+	default:
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = treeFormatDOT
+	}
+	if !validTreeFormat(format) {
+		httpError(w, "format must be dot or mermaid", http.StatusBadRequest)
+		return
+	}
+	if req.Code == "" {
+		httpError(w, "Code is required", http.StatusBadRequest)
+		return
+	}
+
+	language := mapLanguage(req.Language)
+	if language == "" {
+		language = DetectLanguage(req.Code)
+	}
+	tree, _ := NewParser(Tokenize(req.Code, language), language, req.Code).Parse()
+
+	w.Header().Set("Content-Type", treeContentTypes[format])
+	w.Write([]byte(renderTree(tree, format)))
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ─────────────────────── Árboles sintácticos grandes ─────────────────────
+//
+// El árbol de un archivo largo tiene decenas de miles de nodos y en JSON
+// pesa varios MB, aunque el frontend solo muestre los primeros niveles.
+// treeMaxNodes y treeMaxDepth (o PARSE_TREE_MAX_NODES y
+// PARSE_TREE_MAX_DEPTH) acotan el parseTree de /api/v1/analyze: los nodos
+// se eligen por niveles, así todos los de arriba entran antes que los de
+// abajo, y los hijos que no entran se reemplazan por un nodo "more":
+//
+//	{ "type": "more", "value": "... 154 more nodes", "children": [],
+//	  "more": { "nodes": 154, "path": "0/3", "from": 2 } }
+//
+// path son los índices desde la raíz hasta el nodo cuyos hijos se
+// resumieron ("" es la raíz) y from el primer hijo resumido.
+// POST /api/v1/analyze/tree/expand con el mismo código, path y from
+// devuelve esos hijos, acotados de la misma forma.
+
+// Tipo de los nodos que resumen a los que no entraron
+const moreNodeType = "more"
+
+// APIParseMore describe los nodos que resume un nodo "more"
+type APIParseMore struct {
+	// Nodos resumidos, con todos sus descendientes
+	Nodes int `json:"nodes"`
+	// Nodo cuyos hijos se resumieron y el primero de ellos
+	Path string `json:"path"`
+	From int    `json:"from"`
+}
+
+// TreeExpandRequest es el cuerpo de POST /api/v1/analyze/tree/expand
+type TreeExpandRequest struct {
+	Code     string `json:"code"`
+	Language string `json:"language"`
+	// El more.path y more.from del nodo "more" que se expande
+	Path string `json:"path"`
+	From int    `json:"from,omitempty"`
+	// 0 usa PARSE_TREE_MAX_NODES y PARSE_TREE_MAX_DEPTH
+	TreeMaxNodes int `json:"treeMaxNodes,omitempty"`
+	TreeMaxDepth int `json:"treeMaxDepth,omitempty"`
+}
+
+// Respuesta de POST /api/v1/analyze/tree/expand: los hijos del nodo path
+// desde from; total es cuántos hijos tiene
+type APITreeExpandResponse struct {
+	Language  string         `json:"language"`
+	Path      string         `json:"path"`
+	From      int            `json:"from"`
+	Total     int            `json:"total"`
+	Nodes     []APIParseNode `json:"nodes"`
+	Truncated bool           `json:"truncated,omitempty"`
+}
+
+// treeLimits son los topes de un árbol de la respuesta; 0 no limita
+type treeLimits struct {
+	maxNodes, maxDepth int
+}
+
+// treeLimitsFor usa los topes pedidos o, si no se indican, los de
+// PARSE_TREE_MAX_NODES y PARSE_TREE_MAX_DEPTH
+func treeLimitsFor(maxNodes, maxDepth int) treeLimits {
+	if maxNodes == 0 {
+		maxNodes = GlobalConfig.MaxTreeNodes
+	}
+	if maxDepth == 0 {
+		maxDepth = GlobalConfig.MaxTreeDepth
+	}
+	return treeLimits{maxNodes: maxNodes, maxDepth: maxDepth}
+}
+
+func (l treeLimits) active() bool {
+	return l.maxNodes > 0 || l.maxDepth > 0
+}
+
+// invalidTreeLimits valida treeMaxNodes y treeMaxDepth; devuelve el mensaje
+// de error o ""
+func invalidTreeLimits(maxNodes, maxDepth int) string {
+	if maxNodes < 0 {
+		return "treeMaxNodes must be positive"
+	}
+	if maxDepth < 0 {
+		return "treeMaxDepth must be positive"
+	}
+	return ""
+}
+
+// convert convierte nodes[from:], los hijos del nodo path, respetando los
+// topes; truncated indica si se resumió algún nodo
+func (l treeLimits) convert(nodes []ParseNode, path string, from int, src *sourceIndex) (out []APIParseNode, truncated bool) {
+	// Cuántos hijos de cada lista entran, recorriendo por niveles; la clave
+	// es el primer nodo de la lista. Como los hermanos se recorren juntos,
+	// los que entran son siempre los primeros
+	keep := map[*ParseNode]int{}
+	type level struct {
+		nodes []ParseNode
+		depth int
+	}
+	budget := l.maxNodes
+	queue := []level{{nodes[from:], 1}}
+	for len(queue) > 0 {
+		lv := queue[0]
+		queue = queue[1:]
+		if len(lv.nodes) == 0 || (l.maxDepth > 0 && lv.depth > l.maxDepth) {
+			continue
+		}
+		n := len(lv.nodes)
+		if l.maxNodes > 0 {
+			n = min(n, budget)
+			budget -= n
+		}
+		keep[&lv.nodes[0]] = n
+		for i := 0; i < n; i++ {
+			queue = append(queue, level{lv.nodes[i].Children, lv.depth + 1})
+		}
+	}
+
+	var build func(nodes []ParseNode, path string, offset int) []APIParseNode
+	build = func(nodes []ParseNode, path string, offset int) []APIParseNode {
+		out := []APIParseNode{}
+		n := 0
+		if len(nodes) > 0 {
+			n = keep[&nodes[0]]
+		}
+		for i, node := range nodes[:n] {
+			line, column := src.lineColumn(node.Pos)
+			nodeType := node.Kind
+			if nodeType == "" {
+				nodeType = "node"
+			}
+			out = append(out, APIParseNode{
+				Type:     nodeType,
+				Value:    node.Label,
+				Children: build(node.Children, childPath(path, offset+i), 0),
+				Line:     line,
+				Column:   column,
+			})
+		}
+		if n < len(nodes) {
+			truncated = true
+			omitted := countNodes(nodes[n:])
+			line, column := src.lineColumn(nodes[n].Pos)
+			out = append(out, APIParseNode{
+				Type:     moreNodeType,
+				Value:    fmt.Sprintf("... %d more nodes", omitted),
+				Children: []APIParseNode{},
+				Line:     line,
+				Column:   column,
+				More:     &APIParseMore{Nodes: omitted, Path: path, From: offset + n},
+			})
+		}
+		return out
+	}
+	out = build(nodes[from:], path, from)
+	return out, truncated
+}
+
+// childPath es el path del hijo i del nodo path
+func childPath(path string, i int) string {
+	if path == "" {
+		return strconv.Itoa(i)
+	}
+	return path + "/" + strconv.Itoa(i)
+}
+
+// subtreeAt devuelve los hijos del nodo path de tree ("" es la raíz)
+func subtreeAt(tree []ParseNode, path string) ([]ParseNode, bool) {
+	if path == "" {
+		return tree, true
+	}
+	nodes := tree
+	for _, part := range strings.Split(path, "/") {
+		i, err := strconv.Atoi(part)
+		if err != nil || i < 0 || i >= len(nodes) {
+			return nil, false
+		}
+		nodes = nodes[i].Children
+	}
+	return nodes, true
+}
+
+// treeExpandHandler atiende POST /api/v1/analyze/tree/expand
+func treeExpandHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		httpError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req TreeExpandRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		invalidJSON(w)
+		return
+	}
+	if req.Code == "" {
+		httpError(w, "Code is required", http.StatusBadRequest)
+		return
+	}
+	if msg := codeTooLarge(req.Code); msg != "" {
+		writeAPIError(w, http.StatusRequestEntityTooLarge, "code_too_large", msg)
+		return
+	}
+	if msg := invalidTreeLimits(req.TreeMaxNodes, req.TreeMaxDepth); msg != "" {
+		httpError(w, msg, http.StatusBadRequest)
+		return
+	}
+	if req.From < 0 {
+		httpError(w, "from must be positive", http.StatusBadRequest)
+		return
+	}
+
+	language := mapLanguage(req.Language)
+	if language == "" {
+		language = DetectLanguage(req.Code)
+	}
+	if status, msg := authorizeAnalysis(principalFrom(r), language, false); status != 0 {
+		rejectAnalysis(w, status, msg)
+		return
+	}
+
+	tree, _ := NewParser(Tokenize(req.Code, language), language, req.Code).Parse()
+	nodes, ok := subtreeAt(tree, req.Path)
+	if !ok {
+		httpError(w, "path does not name a node of the parse tree", http.StatusBadRequest)
+		return
+	}
+	if req.From > len(nodes) {
+		httpError(w, "from is past the last child", http.StatusBadRequest)
+		return
+	}
+
+	limits := treeLimitsFor(req.TreeMaxNodes, req.TreeMaxDepth)
+	response := APITreeExpandResponse{Language: language, Path: req.Path, From: req.From, Total: len(nodes)}
+	response.Nodes, response.Truncated = limits.convert(nodes, req.Path, req.From, newSourceIndex(req.Code))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,1146 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ─────────────────────── Inferencia y chequeo de tipos ────────────────────
+//
+// El verificador recorre el árbol sintáctico infiriendo tipos a partir de
+// literales, declaraciones y llamadas conocidas. Solo reporta errores cuando
+// los tipos involucrados se conocen con certeza: un tipo "unknown" nunca
+// produce diagnósticos.
+
+// Categorías internas de tipos
+const (
+	tUnknown  = "unknown"
+	tInt      = "int"
+	tFloat    = "float"
+	tChar     = "char"
+	tBool     = "bool"
+	tString   = "string"
+	tCString  = "const char*" // literal de cadena en C++
+	tNull     = "null"
+	tArray    = "array"
+	tObject   = "object"
+	tFunction = "function"
+	tDict     = "dict"
+	tTuple    = "tuple"
+	tSet      = "set"
+)
+
+type funcSignature struct {
+	minArgs, maxArgs int // maxArgs < 0: variádica
+	returnType       string
+	pos              int
+	// Parámetros en orden, sin los variádicos; typ es tUnknown si el tipo
+	// no se declara o no se conoce
+	params []funcParam
+}
+
+type funcParam struct {
+	name, typ string
+}
+
+type TypeChecker struct {
+	language string
+	scopes   []map[string]string
+	funcs    map[string][]funcSignature
+	errors   []CompilerError
+	// Profundidad de bloques condicionales: una reasignación dentro de una
+	// rama que cambia el tipo deja la variable con tipo desconocido.
+	branchDepth int
+	// Tipo visible de cada símbolo (primera declaración), para la tabla de símbolos
+	SymbolTypes map[string]string
+	// Valores de las constantes, paralelo a scopes (ver constfold.go)
+	consts []map[string]constValue
+	// Dentro de un grupo const de Go
+	inConstGroup bool
+	// Valor inicial constante de cada símbolo (primera declaración)
+	SymbolValues map[string]string
+	// Posición de la declaración de cada nombre, la que registró el análisis
+	// de identificadores; los diagnósticos de llamadas la referencian
+	Declarations map[string]int
+}
+
+func NewTypeChecker(lang string) *TypeChecker {
+	return &TypeChecker{
+		language:     lang,
+		funcs:        make(map[string][]funcSignature),
+		SymbolTypes:  make(map[string]string),
+		SymbolValues: make(map[string]string),
+	}
+}
+
+func (tc *TypeChecker) Check(tree []ParseNode) []CompilerError {
+	tc.scopes = []map[string]string{{}}
+	tc.consts = []map[string]constValue{{}}
+	for _, n := range tree {
+		tc.collectFunctions(n)
+	}
+	for _, n := range tree {
+		tc.walk(n)
+	}
+	return tc.errors
+}
+
+func (tc *TypeChecker) report(pos int, code, severity, format string, args ...interface{}) {
+	tc.errors = append(tc.errors, CompilerError{
+		Message:  "Error semántico: " + fmt.Sprintf(format, args...),
+		Severity: severity,
+		Type:     "semantico",
+		Pos:      pos,
+		Code:     code,
+	})
+}
+
+// reportDeclared es report con la posición de la declaración involucrada
+func (tc *TypeChecker) reportDeclared(pos, decl int, code, severity, format string, args ...interface{}) {
+	tc.report(pos, code, severity, format, args...)
+	tc.errors[len(tc.errors)-1].Declaration = &decl
+}
+
+// ─────────────────────────────── Ámbitos ─────────────────────────────────
+
+// isIdentifierName descarta etiquetas de nodos que no son nombres de
+// variables (patrones de desestructuración, declaraciones incompletas)
+func isIdentifierName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if r != '_' && r != '$' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (tc *TypeChecker) push() {
+	tc.scopes = append(tc.scopes, map[string]string{})
+	tc.consts = append(tc.consts, map[string]constValue{})
+}
+
+func (tc *TypeChecker) pop() {
+	tc.scopes = tc.scopes[:len(tc.scopes)-1]
+	tc.consts = tc.consts[:len(tc.consts)-1]
+}
+
+func (tc *TypeChecker) define(name, typ string) {
+	if !isIdentifierName(name) {
+		return
+	}
+	tc.scopes[len(tc.scopes)-1][name] = typ
+	delete(tc.consts[len(tc.consts)-1], name)
+	if _, ok := tc.SymbolTypes[name]; !ok && typ != tUnknown {
+		tc.SymbolTypes[name] = tc.displayType(typ)
+	}
+}
+
+func (tc *TypeChecker) lookup(name string) (string, bool) {
+	for i := len(tc.scopes) - 1; i >= 0; i-- {
+		if t, ok := tc.scopes[i][name]; ok {
+			return t, true
+		}
+	}
+	return tUnknown, false
+}
+
+// assign actualiza el tipo de una variable existente en su ámbito
+func (tc *TypeChecker) assign(name, typ string) {
+	for i := len(tc.scopes) - 1; i >= 0; i-- {
+		if current, ok := tc.scopes[i][name]; ok {
+			if tc.branchDepth > 0 && current != typ {
+				typ = tUnknown
+			}
+			tc.scopes[i][name] = typ
+			return
+		}
+	}
+	tc.define(name, typ)
+}
+
+// displayType devuelve el nombre del tipo en la terminología del lenguaje
+func (tc *TypeChecker) displayType(t string) string {
+	switch tc.language {
+	case "javascript":
+		switch t {
+		case tInt, tFloat:
+			return "number"
+		case tBool:
+			return "boolean"
+		case tArray:
+			return "Array"
+		}
+	case "python":
+		switch t {
+		case tString:
+			return "str"
+		case tArray:
+			return "list"
+		case tNull:
+			return "NoneType"
+		}
+	case "cpp":
+		if t == tFloat {
+			return "double"
+		}
+	case "go":
+		switch t {
+		case tFloat:
+			return "float64"
+		case tChar:
+			return "rune"
+		case tNull:
+			return "nil"
+		case tArray:
+			return "slice"
+		case tDict:
+			return "map"
+		}
+	}
+	return t
+}
+
+// declaredCategory reduce el tipo escrito en una declaración a su categoría
+func (tc *TypeChecker) declaredCategory(decl string) string {
+	switch tc.language {
+	case "cpp":
+		return cppTypeCategory(decl)
+	case "go":
+		return goTypeCategory(decl)
+	}
+	return pyAnnotationCategory(decl)
+}
+
+// ────────────────────────── Firmas de funciones ──────────────────────────
+
+func (tc *TypeChecker) collectFunctions(n ParseNode) {
+	switch n.Kind {
+	case "FunctionDecl", "FunctionProto":
+		if n.Label != "" && !strings.Contains(n.Label, "::") {
+			tc.funcs[n.Label] = append(tc.funcs[n.Label], tc.signatureOf(n))
+		}
+		return
+	case "ClassDecl", "ClassBody":
+		// Los métodos no se validan como funciones libres
+		return
+	}
+	for _, c := range n.Children {
+		tc.collectFunctions(c)
+	}
+}
+
+func (tc *TypeChecker) signatureOf(fn ParseNode) funcSignature {
+	sig := funcSignature{returnType: tUnknown, pos: fn.Pos}
+	for _, c := range fn.Children {
+		switch c.Kind {
+		case "Type":
+			if tc.language == "cpp" || tc.language == "go" {
+				sig.returnType = tc.declaredCategory(c.Label)
+			}
+		case "Params":
+			for _, param := range c.Children {
+				switch {
+				case strings.HasPrefix(param.Label, "*") || strings.HasPrefix(param.Label, "...") || param.Label == "...":
+					sig.maxArgs = -1
+					continue
+				case param.Label == "this":
+					// El parámetro this de TypeScript no se pasa en la llamada
+					continue
+				}
+				if sig.maxArgs >= 0 {
+					sig.maxArgs++
+					sig.params = append(sig.params, funcParam{strings.TrimLeft(param.Label, "*&"), tc.paramType(param)})
+				}
+				if !paramHasDefault(param) {
+					sig.minArgs++
+				}
+			}
+		}
+	}
+	return sig
+}
+
+func paramHasDefault(param ParseNode) bool {
+	for _, c := range param.Children {
+		if c.Kind != "Type" && c.Kind != "TypeAnnotation" && c.Kind != "ObjectPattern" && c.Kind != "ArrayPattern" {
+			return true
+		}
+	}
+	return false
+}
+
+// ───────────────────────────── Recorrido ─────────────────────────────────
+
+func (tc *TypeChecker) walk(n ParseNode) {
+	switch n.Kind {
+	case "FunctionDecl", "Method", "Constructor", "ArrowFunction", "Lambda":
+		tc.push()
+		for _, c := range n.Children {
+			// Receptor y resultados con nombre de Go también son variables locales
+			if c.Kind == "Params" || c.Kind == "Receiver" || c.Kind == "Results" {
+				for _, param := range c.Children {
+					tc.define(strings.TrimLeft(param.Label, "*."), tc.paramType(param))
+				}
+			}
+		}
+		for _, c := range n.Children {
+			if c.Kind != "Params" && c.Kind != "Receiver" && c.Kind != "Results" && c.Kind != "Type" {
+				tc.walk(c)
+			}
+		}
+		tc.pop()
+		return
+	case "Block", "ClassBody":
+		if tc.language != "python" {
+			tc.push()
+			defer tc.pop()
+		}
+	case "If", "While", "DoWhile", "For", "Switch", "Select", "Try":
+		tc.branchDepth++
+		defer func() { tc.branchDepth-- }()
+	case "DeclGroup":
+		if n.Label == "const" {
+			tc.inConstGroup = true
+			defer func() { tc.inConstGroup = false }()
+		}
+	case "VarDecl":
+		tc.checkVarDecl(n)
+		return
+	case "Assign":
+		tc.checkAssign(n)
+		return
+	case "AnnAssign":
+		tc.checkAnnAssign(n)
+		return
+	case "AugAssign":
+		if len(n.Children) == 2 {
+			op := strings.TrimSuffix(n.Label, "=")
+			result := tc.binaryType(op, tc.infer(n.Children[0]), tc.infer(n.Children[1]), n.Pos)
+			tc.checkConstantOps(n)
+			if id := n.Children[0]; id.Kind == "Identifier" {
+				tc.assign(id.Label, result)
+				tc.assignConst(id.Label, constValue{}, false)
+			}
+		}
+		return
+	case "ForEach":
+		tc.push()
+		tc.branchDepth++
+		defer func() {
+			tc.branchDepth--
+			tc.pop()
+		}()
+		if len(n.Children) >= 2 {
+			iter := tc.infer(n.Children[1])
+			elem := tUnknown
+			if iter == tString && tc.language == "python" {
+				elem = tString
+			}
+			tc.defineTargets(n.Children[0], elem)
+		}
+		for _, c := range n.Children[min(2, len(n.Children)):] {
+			tc.walk(c)
+		}
+		return
+	case "ExprStmt", "Return", "Condition", "Throw", "Raise", "Values", "Go", "Defer", "Send":
+		for _, c := range n.Children {
+			tc.infer(c)
+		}
+		return
+	}
+	for _, c := range n.Children {
+		tc.walk(c)
+	}
+}
+
+func (tc *TypeChecker) paramType(param ParseNode) string {
+	for _, c := range param.Children {
+		if c.Kind == "Type" {
+			return tc.declaredCategory(c.Label)
+		}
+	}
+	return tUnknown
+}
+
+// defineTargets registra las variables de un destino (x o x, y)
+func (tc *TypeChecker) defineTargets(target ParseNode, typ string) {
+	switch target.Kind {
+	case "Identifier":
+		tc.define(target.Label, typ)
+	case "VarDecl":
+		declared := typ
+		for _, c := range target.Children {
+			if c.Kind == "Type" && tc.language != "python" && tc.declaredCategory(c.Label) != tUnknown {
+				declared = tc.declaredCategory(c.Label)
+			}
+		}
+		tc.define(target.Label, declared)
+	case "Tuple", "ArrayPattern", "ObjectPattern", "ListLiteral":
+		for _, c := range target.Children {
+			tc.defineTargets(c, tUnknown)
+		}
+	case "Property":
+		tc.define(target.Label, tUnknown)
+	}
+}
+
+func (tc *TypeChecker) checkVarDecl(n ParseNode) {
+	declared := tUnknown
+	typeLabel := ""
+	isArray := false
+	var init *ParseNode
+	for i, c := range n.Children {
+		switch c.Kind {
+		case "Type":
+			typeLabel = c.Label
+			if tc.language == "cpp" || tc.language == "go" {
+				declared = tc.declaredCategory(c.Label)
+			}
+		case "ArraySize":
+			isArray = true
+		case "TypeAnnotation":
+			// Las anotaciones de TypeScript las verifica tsc
+		case "ObjectPattern", "ArrayPattern":
+			// Desestructuración: los nombres se registran sin tipo
+			tc.defineTargets(c, tUnknown)
+		case "InitList", "Arguments":
+			for _, arg := range c.Children {
+				tc.infer(arg)
+			}
+		default:
+			init = &n.Children[i]
+		}
+	}
+
+	if isArray {
+		declared = tArray
+	}
+	if init != nil {
+		value := tc.infer(*init)
+		if declared == tUnknown || declared == "auto" {
+			declared = value
+		} else if !isArray {
+			tc.checkCompatible(declared, value, n.Label, init.Pos)
+		}
+	}
+	if declared == "auto" {
+		declared = tUnknown
+	}
+	tc.define(n.Label, declared)
+	if init != nil && !isArray {
+		tc.declareValue(n.Label, typeLabel, declared, *init)
+	}
+}
+
+// declareValue evalúa el valor inicial de una declaración y, si la variable
+// es constante, lo deja disponible para las expresiones siguientes
+func (tc *TypeChecker) declareValue(name, typeLabel, declared string, init ParseNode) {
+	v, ok := tc.fold(init)
+	if !ok {
+		return
+	}
+	if v, ok = v.convert(declared); !ok {
+		return
+	}
+	if tc.language == "cpp" {
+		v = tc.checkIntRange(typeLabel, name, v, init.Pos)
+	}
+	tc.recordValue(name, v)
+
+	isConst := false
+	switch tc.language {
+	case "cpp":
+		for _, w := range strings.Fields(typeLabel) {
+			isConst = isConst || w == "const" || w == "constexpr"
+		}
+	case "javascript":
+		isConst = typeLabel == "const"
+	case "go":
+		isConst = tc.inConstGroup
+	}
+	if isConst {
+		tc.defineConst(name, v)
+	}
+}
+
+func (tc *TypeChecker) checkAssign(n ParseNode) {
+	if len(n.Children) != 2 {
+		return
+	}
+	target, valueNode := n.Children[0], n.Children[1]
+	value := tc.infer(valueNode)
+	if n.Label != "=" && n.Label != ":=" {
+		value = tc.binaryType(strings.TrimSuffix(n.Label, "="), tc.infer(target), value, n.Pos)
+		tc.checkConstantOps(n)
+	}
+	switch target.Kind {
+	case "Identifier":
+		// Lenguajes con tipado estático: la variable conserva su tipo
+		if tc.language == "cpp" || tc.language == "go" {
+			if declared, ok := tc.lookup(target.Label); ok {
+				tc.checkCompatible(declared, value, target.Label, valueNode.Pos)
+				return
+			}
+		}
+		tc.assign(target.Label, value)
+		if tc.language == "python" {
+			v, ok := tc.fold(valueNode)
+			if ok && n.Label == "=" {
+				tc.recordValue(target.Label, v)
+			}
+			tc.assignConst(target.Label, v, ok && n.Label == "=" && tc.branchDepth == 0 && isPyConstantName(target.Label))
+		}
+	case "Tuple":
+		tc.defineTargets(target, tUnknown)
+	case "Assign":
+		// Asignación encadenada: a = b = valor
+		tc.checkAssign(target)
+	default:
+		tc.infer(target)
+	}
+}
+
+// checkAnnAssign valida anotaciones de Python (x: int = "a")
+func (tc *TypeChecker) checkAnnAssign(n ParseNode) {
+	if len(n.Children) < 2 {
+		return
+	}
+	target := n.Children[0]
+	declared := pyAnnotationCategory(n.Children[1].Label)
+	var folded constValue
+	isConst := false
+	if len(n.Children) > 2 {
+		value := tc.infer(n.Children[2])
+		if v, ok := tc.fold(n.Children[2]); ok && target.Kind == "Identifier" {
+			if v, ok = v.convert(declared); ok {
+				tc.recordValue(target.Label, v)
+				folded, isConst = v, tc.branchDepth == 0 && isPyConstantName(target.Label)
+			}
+		}
+		if declared != tUnknown && value != tUnknown && value != tNull && !compatiblePy(declared, value) {
+			tc.report(n.Children[2].Pos, CodeTypeMismatch, "warning", "La variable '%s' está anotada como '%s' pero se le asigna un valor de tipo '%s'",
+				target.Label, tc.displayType(declared), tc.displayType(value))
+		}
+	}
+	if target.Kind == "Identifier" {
+		tc.define(target.Label, declared)
+		if isConst {
+			tc.defineConst(target.Label, folded)
+		}
+	}
+}
+
+func compatiblePy(declared, value string) bool {
+	return declared == value || declared == tFloat && (value == tInt || value == tBool) || declared == tInt && value == tBool
+}
+
+// checkCompatible valida una asignación con tipo declarado (C++ y Go)
+func (tc *TypeChecker) checkCompatible(declared, value, name string, pos int) {
+	if declared == tUnknown || value == tUnknown {
+		return
+	}
+	if tc.language == "go" {
+		tc.goCheckCompatible(declared, value, name, pos)
+		return
+	}
+	if tc.language != "cpp" {
+		return
+	}
+	numeric := func(t string) bool { return t == tInt || t == tFloat || t == tChar || t == tBool }
+	switch {
+	case numeric(declared) && value == tString || declared != tBool && numeric(declared) && value == tCString:
+		tc.report(pos, CodeTypeMismatch, "error", "No se puede asignar un valor de tipo '%s' a la variable '%s' de tipo '%s'", value, name, declared)
+	case declared == tString && (numeric(value) || value == tNull):
+		tc.report(pos, CodeTypeMismatch, "error", "No se puede asignar un valor de tipo '%s' a la variable '%s' de tipo 'string'", tc.displayType(value), name)
+	case declared == tInt && value == tFloat:
+		tc.report(pos, CodeNarrowingConversion, "warning", "Conversión implícita de 'double' a 'int' en '%s': se pierde la parte decimal", name)
+	}
+}
+
+// ───────────────────────────── Inferencia ────────────────────────────────
+
+func (tc *TypeChecker) infer(n ParseNode) string {
+	switch n.Kind {
+	case "Literal":
+		if tc.language == "cpp" {
+			tc.checkIntLiteral(n)
+		}
+		return tc.literalType(n.Label)
+	case "Identifier":
+		t, _ := tc.lookup(n.Label)
+		return t
+	case "BinaryExpr":
+		if len(n.Children) != 2 {
+			return tUnknown
+		}
+		t := tc.binaryType(n.Label, tc.infer(n.Children[0]), tc.infer(n.Children[1]), n.Pos)
+		tc.checkConstantOps(n)
+		return t
+	case "LogicalExpr":
+		l, r := tc.infer(n.Children[0]), tc.infer(n.Children[1])
+		if tc.language == "cpp" {
+			return tBool
+		}
+		if l == r {
+			return l
+		}
+		return tUnknown
+	case "UnaryExpr":
+		if len(n.Children) == 0 {
+			return tUnknown
+		}
+		operand := tc.infer(n.Children[0])
+		return tc.unaryType(n.Label, operand, n.Pos)
+	case "PostfixExpr":
+		return tc.infer(n.Children[0])
+	case "Conditional":
+		types := make([]string, len(n.Children))
+		for i, c := range n.Children {
+			types[i] = tc.infer(c)
+		}
+		if len(types) == 3 && types[1] == types[2] {
+			return types[1]
+		}
+		return tUnknown
+	case "Assign":
+		tc.checkAssign(n)
+		return tc.infer(n.Children[len(n.Children)-1])
+	case "Call":
+		return tc.checkCall(n)
+	case "Member":
+		return tc.memberType(n)
+	case "Index":
+		target := tc.infer(n.Children[0])
+		for _, c := range n.Children[1:] {
+			tc.infer(c)
+		}
+		if target == tString && tc.language == "go" {
+			// s[i] es un byte; s[i:j] sigue siendo string
+			if len(n.Children) > 1 && n.Children[1].Kind == "Slice" {
+				return tString
+			}
+			return tInt
+		}
+		if target == tString && tc.language != "cpp" {
+			return tString
+		}
+		if target == tString {
+			return tChar
+		}
+		return tUnknown
+	case "ArrayLiteral", "ListLiteral", "ListComp":
+		tc.inferChildren(n)
+		return tArray
+	case "ObjectLiteral", "New":
+		tc.inferChildren(n)
+		return tObject
+	case "DictLiteral", "DictComp":
+		tc.inferChildren(n)
+		return tDict
+	case "SetLiteral", "SetComp":
+		tc.inferChildren(n)
+		return tSet
+	case "Tuple":
+		tc.inferChildren(n)
+		return tTuple
+	case "CompositeLit":
+		tc.inferChildren(n)
+		switch {
+		case strings.HasPrefix(n.Label, "map["):
+			return tDict
+		case strings.HasPrefix(n.Label, "["):
+			return tArray
+		}
+		return tObject
+	case "ArrowFunction", "Lambda", "FunctionDecl":
+		tc.walk(n)
+		return tFunction
+	case "Cast":
+		return cppTypeCategory(n.Label)
+	}
+	tc.inferChildren(n)
+	return tUnknown
+}
+
+func (tc *TypeChecker) inferChildren(n ParseNode) {
+	for _, c := range n.Children {
+		tc.infer(c)
+	}
+}
+
+func (tc *TypeChecker) literalType(lex string) string {
+	switch lex {
+	case "true", "false", "True", "False":
+		return tBool
+	case "null", "nullptr", "None", "undefined", "NULL", "nil":
+		return tNull
+	}
+	if lex == "" {
+		return tUnknown
+	}
+	switch c := lex[0]; {
+	case c >= '0' && c <= '9' || c == '.':
+		low := strings.ToLower(lex)
+		if strings.HasPrefix(low, "0x") {
+			return tInt
+		}
+		if strings.ContainsAny(low, ".e") || tc.language == "cpp" && strings.HasSuffix(low, "f") {
+			return tFloat
+		}
+		return tInt
+	case c == '\'' && (tc.language == "cpp" || tc.language == "go"):
+		return tChar
+	case c == '"' && tc.language == "cpp":
+		return tCString
+	case c == '"' || c == '\'' || c == '`':
+		return tString
+	}
+	// Prefijos de cadena de Python: f"...", b"..."
+	if tc.language == "python" && strings.ContainsAny(lex, "\"'") {
+		return tString
+	}
+	return tUnknown
+}
+
+func isNumericType(t string) bool { return t == tInt || t == tFloat }
+
+// binaryType calcula el tipo de una operación binaria reportando operandos inválidos
+func (tc *TypeChecker) binaryType(op, l, r string, pos int) string {
+	switch op {
+	case "==", "!=", "===", "!==", "<", ">", "<=", ">=", "in", "not in", "is", "is not", "instanceof":
+		return tBool
+	case "&&", "||":
+		return tBool
+	case "<<", ">>":
+		if tc.language == "cpp" && !isNumericType(l) {
+			// Operadores de flujo (cout << x)
+			return tUnknown
+		}
+	}
+	if l == tUnknown || r == tUnknown {
+		return tUnknown
+	}
+
+	switch tc.language {
+	case "cpp":
+		return tc.cppBinaryType(op, l, r, pos)
+	case "python":
+		return tc.pyBinaryType(op, l, r, pos)
+	case "go":
+		return tc.goBinaryType(op, l, r, pos)
+	case "javascript":
+		return tc.jsBinaryType(op, l, r, pos)
+	}
+	return tUnknown
+}
+
+func (tc *TypeChecker) cppBinaryType(op, l, r string, pos int) string {
+	arith := func(t string) bool { return t == tInt || t == tFloat || t == tChar || t == tBool }
+	switch {
+	case arith(l) && arith(r):
+		if op == "%" && (l == tFloat || r == tFloat) {
+			tc.report(pos, CodeInvalidOperands, "error", "El operador '%%' requiere operandos enteros, se recibió '%s' y '%s'", tc.displayType(l), tc.displayType(r))
+			return tUnknown
+		}
+		if l == tFloat || r == tFloat {
+			return tFloat
+		}
+		return tInt
+	case op == "+" && (l == tString && (r == tString || r == tCString || r == tChar) || r == tString && (l == tCString || l == tChar)):
+		return tString
+	case l == tString && arith(r) || r == tString && arith(l):
+		tc.report(pos, CodeInvalidOperands, "error", "Operandos inválidos para '%s': '%s' y '%s'", op, tc.displayType(l), tc.displayType(r))
+	case l == tCString && r == tCString && op == "+":
+		tc.report(pos, CodeInvalidOperands, "error", "No se pueden sumar dos literales de cadena; use std::string")
+	}
+	return tUnknown
+}
+
+func (tc *TypeChecker) pyBinaryType(op, l, r string, pos int) string {
+	numeric := func(t string) bool { return t == tInt || t == tFloat || t == tBool }
+	switch {
+	case numeric(l) && numeric(r):
+		if op == "/" || l == tFloat || r == tFloat {
+			return tFloat
+		}
+		return tInt
+	case op == "+" && l == r && (l == tString || l == tArray || l == tTuple):
+		return l
+	case op == "*" && (l == tString || l == tArray) && r == tInt || op == "*" && (r == tString || r == tArray) && l == tInt:
+		if l == tInt {
+			return r
+		}
+		return l
+	case op == "%" && l == tString:
+		return tString
+	case l == tNull || r == tNull || l == tString || r == tString:
+		tc.report(pos, CodeInvalidOperands, "error", "Tipos de operandos no soportados para '%s': '%s' y '%s'", op, tc.displayType(l), tc.displayType(r))
+	}
+	return tUnknown
+}
+
+func (tc *TypeChecker) goBinaryType(op, l, r string, pos int) string {
+	numeric := func(t string) bool { return t == tInt || t == tFloat || t == tChar }
+	switch {
+	case numeric(l) && numeric(r):
+		if op == "%" && (l == tFloat || r == tFloat) {
+			tc.report(pos, CodeInvalidOperands, "error", "El operador '%%' no está definido para '%s'", tc.displayType(tFloat))
+			return tUnknown
+		}
+		if l == tFloat || r == tFloat {
+			return tFloat
+		}
+		if l == tChar && r == tChar {
+			return tChar
+		}
+		return tInt
+	case op == "+" && l == tString && r == tString:
+		return tString
+	case l == tString && numeric(r) || r == tString && numeric(l) || l == tBool && r != tBool || r == tBool && l != tBool:
+		tc.report(pos, CodeInvalidOperands, "error", "Tipos incompatibles en la operación '%s': '%s' y '%s'", op, tc.displayType(l), tc.displayType(r))
+	}
+	return tUnknown
+}
+
+// goCheckCompatible valida asignaciones en Go, donde no hay conversiones implícitas
+func (tc *TypeChecker) goCheckCompatible(declared, value, name string, pos int) {
+	numeric := func(t string) bool { return t == tInt || t == tFloat || t == tChar }
+	switch {
+	case numeric(declared) && (value == tString || value == tBool),
+		declared == tString && (numeric(value) || value == tBool || value == tNull),
+		declared == tBool && value != tBool:
+		tc.report(pos, CodeTypeMismatch, "error", "No se puede usar un valor de tipo '%s' como '%s' en la asignación a '%s'",
+			tc.displayType(value), tc.displayType(declared), name)
+	case declared == tInt && value == tFloat:
+		tc.report(pos, CodeTypeMismatch, "error", "No se puede asignar un valor de tipo 'float64' a la variable '%s' de tipo entero", name)
+	}
+}
+
+func (tc *TypeChecker) jsBinaryType(op, l, r string, pos int) string {
+	switch {
+	case op == "+" && (l == tString || r == tString):
+		return tString
+	case isNumericType(l) && isNumericType(r):
+		return tFloat
+	case op != "+" && (l == tString || r == tString) && (op == "-" || op == "*" || op == "/" || op == "%" || op == "**"):
+		tc.report(pos, CodeInvalidOperands, "warning", "Operación aritmética '%s' con un string: el resultado puede ser NaN", op)
+		return tFloat
+	}
+	return tUnknown
+}
+
+func (tc *TypeChecker) unaryType(op, operand string, pos int) string {
+	switch op {
+	case "!", "not":
+		return tBool
+	case "typeof":
+		return tString
+	case "-", "+", "~":
+		if operand == tString && op != "+" {
+			if tc.language == "javascript" {
+				tc.report(pos, CodeInvalidOperands, "warning", "Operador '%s' aplicado a un string: el resultado puede ser NaN", op)
+				return tFloat
+			}
+			tc.report(pos, CodeInvalidOperands, "error", "Operador unario '%s' inválido para el tipo '%s'", op, tc.displayType(operand))
+			return tUnknown
+		}
+		if isNumericType(operand) || operand == tChar {
+			return operand
+		}
+	}
+	return tUnknown
+}
+
+// Tipos de retorno de funciones integradas conocidas
+var builtinReturnTypes = map[string]map[string]string{
+	"python": {
+		"len": tInt, "int": tInt, "float": tFloat, "str": tString, "input": tString,
+		"bool": tBool, "list": tArray, "dict": tDict, "tuple": tTuple, "set": tSet,
+		"abs": tUnknown, "round": tInt, "sorted": tArray, "repr": tString, "chr": tString, "ord": tInt,
+	},
+	"javascript": {
+		"parseInt": tInt, "parseFloat": tFloat, "String": tString, "Number": tFloat,
+		"Boolean": tBool, "isNaN": tBool, "prompt": tString,
+	},
+	"go": {
+		"len": tInt, "cap": tInt, "string": tString, "int": tInt, "float64": tFloat,
+	},
+}
+
+func (tc *TypeChecker) checkCall(n ParseNode) string {
+	callee := n.Children[0]
+	var args []ParseNode
+	if len(n.Children) > 1 {
+		args = n.Children[1].Children
+	}
+	argTypes := make([]string, len(args))
+	for i, a := range args {
+		if a.Kind == "KeywordArg" {
+			tc.infer(a.Children[0])
+			argTypes[i] = tUnknown
+		} else {
+			argTypes[i] = tc.infer(a)
+		}
+	}
+
+	switch callee.Kind {
+	case "Identifier":
+		if sigs, ok := tc.funcs[callee.Label]; ok {
+			if _, shadowed := tc.lookupLocal(callee.Label); !shadowed {
+				return tc.checkArgCount(callee.Label, sigs, args, argTypes, n.Pos)
+			}
+		}
+		if t, ok := tc.lookup(callee.Label); ok && !callableType(t) {
+			tc.reportNotCallable(callee, t)
+			return tUnknown
+		}
+		if t, ok := builtinReturnTypes[tc.language][callee.Label]; ok {
+			return t
+		}
+	case "Member":
+		tc.memberType(callee)
+	default:
+		tc.infer(callee)
+	}
+	return tUnknown
+}
+
+// callableType indica si un valor del tipo t podría llamarse: un número,
+// un string o una lista nunca son funciones
+func callableType(t string) bool {
+	switch t {
+	case tInt, tFloat, tChar, tBool, tString, tCString, tNull, tArray, tDict, tTuple, tSet:
+		return false
+	}
+	return true
+}
+
+func (tc *TypeChecker) reportNotCallable(callee ParseNode, t string) {
+	const format = "'%s' es una variable de tipo '%s' y no se puede llamar como función"
+	if decl, ok := tc.Declarations[callee.Label]; ok {
+		tc.reportDeclared(callee.Pos, decl, CodeNotCallable, "error", format, callee.Label, tc.displayType(t))
+		return
+	}
+	tc.report(callee.Pos, CodeNotCallable, "error", format, callee.Label, tc.displayType(t))
+}
+
+// lookupLocal busca un nombre fuera del ámbito global (variables que ocultan funciones)
+func (tc *TypeChecker) lookupLocal(name string) (string, bool) {
+	for i := len(tc.scopes) - 1; i >= 1; i-- {
+		if t, ok := tc.scopes[i][name]; ok {
+			return t, true
+		}
+	}
+	return "", false
+}
+
+func (tc *TypeChecker) checkArgCount(name string, sigs []funcSignature, args []ParseNode, argTypes []string, pos int) string {
+	for _, a := range args {
+		if a.Kind == "Spread" {
+			return sigs[0].returnType
+		}
+	}
+	if tc.language == "go" && len(args) == 1 && args[0].Kind == "Call" {
+		// f(g()) reparte los resultados múltiples de g entre los parámetros de f
+		return sigs[0].returnType
+	}
+	count := len(args)
+	for _, sig := range sigs {
+		if count >= sig.minArgs && (sig.maxArgs < 0 || count <= sig.maxArgs) {
+			if len(sigs) == 1 {
+				// Con sobrecargas no se sabe cuál se llama
+				tc.checkArgTypes(name, sig, args, argTypes)
+			}
+			return sig.returnType
+		}
+	}
+	sig := sigs[0]
+	expected := fmt.Sprintf("%d", sig.minArgs)
+	switch {
+	case sig.maxArgs < 0:
+		expected = fmt.Sprintf("al menos %d", sig.minArgs)
+	case sig.maxArgs != sig.minArgs:
+		expected = fmt.Sprintf("entre %d y %d", sig.minArgs, sig.maxArgs)
+	}
+	severity := "error"
+	if tc.language == "javascript" {
+		// JavaScript no valida la cantidad de argumentos en tiempo de ejecución
+		severity = "warning"
+	}
+	tc.reportDeclared(pos, sig.pos, CodeArgumentCount, severity, "La función '%s' espera %s argumento(s) pero recibió %d", name, expected, count)
+	return sig.returnType
+}
+
+// checkArgTypes compara cada argumento con el tipo declarado de su
+// parámetro en C++ y Go, donde el compilador rechaza la llamada
+func (tc *TypeChecker) checkArgTypes(name string, sig funcSignature, args []ParseNode, argTypes []string) {
+	if tc.language != "cpp" && tc.language != "go" {
+		return
+	}
+	for i, param := range sig.params {
+		if i >= len(args) {
+			return
+		}
+		if args[i].Kind == "KeywordArg" || !tc.incompatibleArgument(param.typ, argTypes[i]) {
+			continue
+		}
+		tc.reportDeclared(args[i].Pos, sig.pos, CodeTypeMismatch, "error",
+			"El argumento %d de '%s' es de tipo '%s' pero el parámetro '%s' es de tipo '%s'",
+			i+1, name, tc.displayType(argTypes[i]), param.name, tc.displayType(param.typ))
+	}
+}
+
+// incompatibleArgument indica si un valor de tipo arg no puede pasarse a un
+// parámetro de tipo param: las mismas combinaciones que checkCompatible
+// rechaza en una asignación
+func (tc *TypeChecker) incompatibleArgument(param, arg string) bool {
+	if param == tUnknown || arg == tUnknown {
+		return false
+	}
+	if tc.language == "go" {
+		numeric := func(t string) bool { return t == tInt || t == tFloat || t == tChar }
+		return numeric(param) && (arg == tString || arg == tBool) ||
+			param == tString && (numeric(arg) || arg == tBool || arg == tNull) ||
+			param == tBool && arg != tBool
+	}
+	numeric := func(t string) bool { return t == tInt || t == tFloat || t == tChar || t == tBool }
+	return numeric(param) && arg == tString || param != tBool && numeric(param) && arg == tCString ||
+		param == tString && (numeric(arg) || arg == tNull)
+}
+
+// Métodos disponibles para los tipos primitivos, usados para detectar llamadas
+// a métodos inexistentes (let x = 1; x.toUpperCase()).
+var primitiveMethods = map[string]map[string][]string{
+	"javascript": {
+		tInt:   {"toFixed", "toString", "toPrecision", "toExponential", "toLocaleString", "valueOf"},
+		tFloat: {"toFixed", "toString", "toPrecision", "toExponential", "toLocaleString", "valueOf"},
+		tString: {"at", "charAt", "charCodeAt", "codePointAt", "concat", "endsWith", "includes", "indexOf",
+			"lastIndexOf", "localeCompare", "match", "matchAll", "normalize", "padEnd", "padStart", "repeat",
+			"replace", "replaceAll", "search", "slice", "split", "startsWith", "substring", "substr",
+			"toLowerCase", "toUpperCase", "toLocaleLowerCase", "toLocaleUpperCase", "toString", "trim",
+			"trimStart", "trimEnd", "valueOf", "length"},
+		tBool: {"toString", "valueOf"},
+		tArray: {"at", "concat", "copyWithin", "entries", "every", "fill", "filter", "find", "findIndex",
+			"findLast", "findLastIndex", "flat", "flatMap", "forEach", "includes", "indexOf", "join", "keys",
+			"lastIndexOf", "map", "pop", "push", "reduce", "reduceRight", "reverse", "shift", "slice", "some",
+			"sort", "splice", "toString", "unshift", "values", "length", "toSorted", "toReversed", "with"},
+	},
+	"python": {
+		tInt: {"bit_length", "bit_count", "to_bytes", "from_bytes", "conjugate", "real", "imag",
+			"numerator", "denominator", "as_integer_ratio", "is_integer"},
+		tFloat: {"is_integer", "as_integer_ratio", "hex", "fromhex", "conjugate", "real", "imag"},
+		tString: {"capitalize", "casefold", "center", "count", "encode", "endswith", "expandtabs", "find",
+			"format", "format_map", "index", "isalnum", "isalpha", "isascii", "isdecimal", "isdigit",
+			"isidentifier", "islower", "isnumeric", "isprintable", "isspace", "istitle", "isupper", "join",
+			"ljust", "lower", "lstrip", "maketrans", "partition", "removeprefix", "removesuffix", "replace",
+			"rfind", "rindex", "rjust", "rpartition", "rsplit", "rstrip", "split", "splitlines",
+			"startswith", "strip", "swapcase", "title", "translate", "upper", "zfill"},
+		tArray: {"append", "clear", "copy", "count", "extend", "index", "insert", "pop", "remove",
+			"reverse", "sort"},
+		tDict: {"clear", "copy", "fromkeys", "get", "items", "keys", "pop", "popitem", "setdefault",
+			"update", "values"},
+	},
+}
+
+func (tc *TypeChecker) memberType(n ParseNode) string {
+	if len(n.Children) == 0 {
+		return tUnknown
+	}
+	objType := tc.infer(n.Children[0])
+	methods, ok := primitiveMethods[tc.language][objType]
+	if !ok {
+		return tUnknown
+	}
+	for _, m := range methods {
+		if m == n.Label {
+			if n.Label == "length" {
+				return tInt
+			}
+			return tUnknown
+		}
+	}
+	tc.report(n.End-len(n.Label), CodeUnknownMember, "error", "El tipo '%s' no tiene la propiedad o método '%s'", tc.displayType(objType), n.Label)
+	return tUnknown
+}
+
+// ────────────────────────── Tipos declarados ─────────────────────────────
+
+// cppTypeCategory reduce un tipo declarado de C++ a su categoría interna
+func cppTypeCategory(decl string) string {
+	t := strings.TrimSpace(decl)
+	if strings.ContainsAny(t, "*&<[") {
+		if strings.HasSuffix(t, "&") && !strings.ContainsAny(t, "*<") {
+			t = strings.TrimSpace(strings.TrimSuffix(t, "&"))
+		} else {
+			return tUnknown
+		}
+	}
+	var words []string
+	for _, w := range strings.Fields(t) {
+		switch w {
+		case "const", "static", "constexpr", "inline", "volatile", "mutable", "register", "extern", "unsigned", "signed":
+			continue
+		}
+		words = append(words, w)
+	}
+	if len(words) == 0 {
+		// "unsigned" solo equivale a unsigned int
+		if strings.Contains(t, "unsigned") || strings.Contains(t, "signed") {
+			return tInt
+		}
+		return tUnknown
+	}
+	switch strings.Join(words, " ") {
+	case "int", "long", "short", "long long", "long int", "short int", "long long int", "size_t":
+		return tInt
+	case "float", "double", "long double":
+		return tFloat
+	case "char", "wchar_t":
+		return tChar
+	case "bool":
+		return tBool
+	case "string", "std::string":
+		return tString
+	case "auto":
+		return "auto"
+	}
+	return tUnknown
+}
+
+// goTypeCategory reduce un tipo de Go a su categoría interna
+func goTypeCategory(decl string) string {
+	switch strings.TrimSpace(decl) {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64",
+		"uintptr", "byte", "rune":
+		return tInt
+	case "float32", "float64":
+		return tFloat
+	case "string":
+		return tString
+	case "bool":
+		return tBool
+	}
+	return tUnknown
+}
+
+func pyAnnotationCategory(ann string) string {
+	switch ann {
+	case "int":
+		return tInt
+	case "float":
+		return tFloat
+	case "str":
+		return tString
+	case "bool":
+		return tBool
+	case "list":
+		return tArray
+	case "dict":
+		return tDict
+	}
+	return tUnknown
+}
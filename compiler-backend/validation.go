@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ───────────────────────── Límites de las peticiones ─────────────────────
+//
+// Un cuerpo enorme (un archivo de varios megabytes pegado por error, o un
+// cliente malicioso) ocupa memoria al decodificarlo y segundos de análisis
+// antes de que un handler pueda rechazarlo. limitRequest atiende antes que
+// todos los handlers: rechaza con 413 los cuerpos de más de MaxRequestBytes
+// y, en las peticiones que envían código, el código de más de MaxFileSize
+// (413) y un lenguaje que el servidor no analiza (400); details informa el
+// límite o los lenguajes que sí se analizan. AllowedLanguages no se valida
+// aquí: los lenguajes que no se ejecutan igual se analizan (ver
+// executability.go).
+
+// limitRequest aplica MaxRequestBytes, MaxFileSize y la lista de lenguajes
+// a los cuerpos de next; el cuerpo se lee completo y los handlers lo
+// reciben intacto
+func limitRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil || r.Body == http.NoBody || r.Method == http.MethodGet || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+		limit := GlobalConfig.MaxRequestBytes
+		if limit > 0 && r.ContentLength > limit {
+			writeAPIErrorDetails(w, http.StatusRequestEntityTooLarge, "request_too_large", bodyTooLarge(limit),
+				map[string]interface{}{"limit": limit})
+			return
+		}
+		body := io.Reader(r.Body)
+		if limit > 0 {
+			// Sin Content-Length (chunked) el límite se nota al leer
+			body = io.LimitReader(r.Body, limit+1)
+		}
+		data, err := io.ReadAll(body)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, "invalid_body", "Could not read the request body")
+			return
+		}
+		if limit > 0 && int64(len(data)) > limit {
+			writeAPIErrorDetails(w, http.StatusRequestEntityTooLarge, "request_too_large", bodyTooLarge(limit),
+				map[string]interface{}{"limit": limit})
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(data))
+
+		var input struct {
+			Code     *string `json:"code"`
+			Language *string `json:"language"`
+		}
+		// Un JSON inválido lo informa el handler, que sabe qué esperaba
+		if json.Unmarshal(data, &input) == nil {
+			if input.Code != nil {
+				if msg := codeTooLarge(*input.Code); msg != "" {
+					writeAPIErrorDetails(w, http.StatusRequestEntityTooLarge, "code_too_large", msg,
+						map[string]interface{}{"size": len(*input.Code), "limit": GlobalConfig.MaxFileSize})
+					return
+				}
+			}
+			if input.Language != nil {
+				if msg := unsupportedLanguage(*input.Language); msg != "" {
+					writeAPIErrorDetails(w, http.StatusBadRequest, "unsupported_language", msg,
+						map[string]interface{}{"language": *input.Language, "supported": supportedLanguages()})
+					return
+				}
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func bodyTooLarge(limit int64) string {
+	return fmt.Sprintf("Request body exceeds the limit of %d bytes", limit)
+}
+
+// codeTooLarge devuelve el motivo por el que code supera MaxFileSize, o ""
+func codeTooLarge(code string) string {
+	if max := GlobalConfig.MaxFileSize; max > 0 && len(code) > max {
+		return fmt.Sprintf("code is %d bytes; the limit is %d bytes", len(code), max)
+	}
+	return ""
+}
+
+// unsupportedLanguage devuelve el motivo por el que el servidor no analiza
+// name, o "" si lo analiza; "" y "auto" piden detectarlo
+func unsupportedLanguage(name string) string {
+	lang := mapLanguage(name)
+	if lang == "" {
+		return ""
+	}
+	if _, ok := languages[lang]; ok {
+		return ""
+	}
+	return fmt.Sprintf("language %q is not supported; use auto or one of: %s", name, strings.Join(supportedLanguages(), ", "))
+}
+
+// supportedLanguages son los lenguajes que analiza el servidor, ordenados
+func supportedLanguages() []string {
+	names := make([]string, 0, len(languages))
+	for known := range languages {
+		names = append(names, known)
+	}
+	sort.Strings(names)
+	return names
+}
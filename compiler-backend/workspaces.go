@@ -0,0 +1,157 @@
+package main
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ──────────────────────────── Espacios de trabajo ────────────────────────
+//
+// Un despliegue puede atender a varios cursos o secciones con políticas
+// distintas. WORKSPACES apunta a un archivo JSON o YAML (ver customrules.go)
+// con un espacio por curso:
+//
+//	workspaces:
+//	  compiladores-a:
+//	    name: Compiladores 2024, sección A
+//	    token: ws_3f9c2a7d41b8e605
+//	    languages: [cpp, python]
+//	    dailyMinutes: 20
+//	    profiles:
+//	      factorial: {...}   # como en ASSIGNMENT_PROFILES
+//
+// El frontend del curso envía el token en X-Workspace-Token (o, al abrir un
+// WebSocket, en el parámetro workspace_token), junto con las credenciales
+// del usuario si las hay. La petición queda entonces dentro del espacio:
+//
+//   - solo puede usar sus lenguajes, además de los que permita el usuario;
+//   - la ejecución se carga a una cuota propia del espacio: dailyMinutes por
+//     usuario (sin dailyMinutes, la del usuario), y las peticiones anónimas
+//     comparten una sola. La de un usuario autenticado también se descuenta
+//     de su cuota personal: entrar a un espacio no le da minutos extra;
+//   - assignmentProfile elige entre los perfiles del espacio, no los de
+//     ASSIGNMENT_PROFILES;
+//   - el historial registra el espacio y GET /api/v1/history solo muestra
+//     sus análisis.
+//
+// Un token desconocido responde 401. Sin token la petición sigue las reglas
+// generales del servidor.
+
+const (
+	workspaceHeader = "X-Workspace-Token"
+	// Los tokens se comparten con todo el curso, pero adivinarlos no debe
+	// ser posible
+	minWorkspaceTokenLength = 16
+)
+
+type Workspace struct {
+	ID           string                        `json:"-"`
+	Name         string                        `json:"name"`
+	Token        string                        `json:"token"`
+	Languages    []string                      `json:"languages"`
+	DailyMinutes float64                       `json:"dailyMinutes"`
+	Profiles     map[string]*AssignmentProfile `json:"profiles"`
+}
+
+type workspacesFile struct {
+	Workspaces map[string]*Workspace `json:"workspaces"`
+}
+
+// loadWorkspaces lee y valida el archivo de espacios path
+func loadWorkspaces(path string) (map[string]*Workspace, error) {
+	var file workspacesFile
+	if err := decodeConfigFile(path, &file); err != nil {
+		return nil, err
+	}
+	tokens := map[string]string{}
+	for id, ws := range file.Workspaces {
+		if ws == nil {
+			return nil, fmt.Errorf("workspace %s is empty", id)
+		}
+		if strings.Contains(id, "/") {
+			return nil, fmt.Errorf("workspace %s: the id cannot contain '/'", id)
+		}
+		ws.ID = id
+		if ws.Name == "" {
+			ws.Name = id
+		}
+		if len(ws.Token) < minWorkspaceTokenLength {
+			return nil, fmt.Errorf("workspace %s: token must have at least %d characters", id, minWorkspaceTokenLength)
+		}
+		if other, ok := tokens[ws.Token]; ok {
+			return nil, fmt.Errorf("workspaces %s and %s have the same token", other, id)
+		}
+		tokens[ws.Token] = id
+		for i, name := range ws.Languages {
+			if ws.Languages[i] = mapLanguage(name); !cliLanguage(ws.Languages[i]) {
+				return nil, fmt.Errorf("workspace %s: unknown language %s", id, name)
+			}
+		}
+		if ws.DailyMinutes < 0 {
+			return nil, fmt.Errorf("workspace %s: dailyMinutes must not be negative", id)
+		}
+		if err := compileAssignmentProfiles(ws.Profiles); err != nil {
+			return nil, fmt.Errorf("workspace %s: %v", id, err)
+		}
+	}
+	return file.Workspaces, nil
+}
+
+// workspaceToken devuelve el token de espacio de r, del encabezado o, solo
+// al abrir un WebSocket, del parámetro workspace_token
+func workspaceToken(r *http.Request) string {
+	if token := r.Header.Get(workspaceHeader); token != "" {
+		return token
+	}
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return r.URL.Query().Get("workspace_token")
+	}
+	return ""
+}
+
+// findWorkspace devuelve el espacio de token, o nil si ninguno lo tiene
+func findWorkspace(token string) *Workspace {
+	var found *Workspace
+	// Se comparan todos para no revelar por el tiempo cuál coincide
+	for _, ws := range GlobalConfig.Workspaces {
+		if subtle.ConstantTimeCompare([]byte(ws.Token), []byte(token)) == 1 {
+			found = ws
+		}
+	}
+	return found
+}
+
+// scope devuelve el usuario p (nil si es anónimo) dentro de ws: su consumo
+// se registra aparte con la cuota del espacio si la tiene, y también cuenta
+// en la cuota personal de p
+func (ws *Workspace) scope(p *Principal) *Principal {
+	scoped := &Principal{
+		Account:      "ws:" + ws.ID,
+		User:         ws.Name,
+		DailyMinutes: ws.DailyMinutes,
+		Workspace:    ws.ID,
+		workspace:    ws,
+	}
+	if p != nil {
+		scoped.Account += "/" + p.Account
+		scoped.User = p.User
+		scoped.Languages = p.Languages
+		scoped.Admin = p.Admin
+		scoped.personal = p
+		if ws.DailyMinutes == 0 {
+			scoped.DailyMinutes = p.DailyMinutes
+		}
+	}
+	return scoped
+}
+
+// assignmentProfiles son los perfiles que puede elegir p: los de su espacio
+// o, fuera de uno, los de ASSIGNMENT_PROFILES
+func (p *Principal) assignmentProfiles() map[string]*AssignmentProfile {
+	if p != nil && p.workspace != nil {
+		return p.workspace.Profiles
+	}
+	return currentConfig().AssignmentProfiles
+}
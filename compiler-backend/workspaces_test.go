@@ -0,0 +1,85 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// TestWorkspaceChargesPersonalQuota comprueba que un usuario que agotó su
+// cuota personal no puede seguir ejecutando dentro de un espacio, aunque el
+// espacio tenga minutos propios
+func TestWorkspaceChargesPersonalQuota(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "auth.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	store, err := openAuth(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := auth
+	auth = store
+	defer func() { auth = saved }()
+
+	user := &Principal{Account: "key:ana", User: "ana", DailyMinutes: 5}
+	ws := &Workspace{ID: "compiladores-a", Name: "Compiladores", DailyMinutes: 20}
+	scoped := ws.scope(user)
+
+	if status, msg := authorizeAnalysis(scoped, "python", true); status != 0 {
+		t.Fatalf("rechazada antes de consumir: %d %s", status, msg)
+	}
+	if err := store.addUsage(user.Account, 5*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := authorizeAnalysis(user, "python", true); status != http.StatusTooManyRequests {
+		t.Fatalf("fuera del espacio: %d, esperado %d", status, http.StatusTooManyRequests)
+	}
+	if status, _ := authorizeAnalysis(scoped, "python", true); status != http.StatusTooManyRequests {
+		t.Fatalf("dentro del espacio: %d, esperado %d", status, http.StatusTooManyRequests)
+	}
+	if used, _ := scoped.executionUsage(); used != 0 {
+		t.Errorf("el espacio registra %v, esperado 0", used)
+	}
+}
+
+// TestLoadWorkspacesLanguages comprueba que los lenguajes de un espacio se
+// traducen a los del backend y que un nombre desconocido impide cargarlo
+func TestLoadWorkspacesLanguages(t *testing.T) {
+	cases := []struct {
+		name      string
+		languages string
+		want      []string
+	}{
+		{"nombres_del_frontend", `["c++", "py"]`, []string{"cpp", "python"}},
+		{"error_de_tipeo", `["python", "pyhton"]`, nil},
+		{"desconocido", `["cobol"]`, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "workspaces.json")
+			data := `{"workspaces": {"curso": {"token": "token-del-curso-2024", "languages": ` + c.languages + `}}}`
+			if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+				t.Fatal(err)
+			}
+			workspaces, err := loadWorkspaces(path)
+			if c.want == nil {
+				if err == nil {
+					t.Fatalf("se cargó con lenguajes %v", workspaces["curso"].Languages)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := workspaces["curso"].Languages; !slices.Equal(got, c.want) {
+				t.Errorf("lenguajes %v, esperados %v", got, c.want)
+			}
+		})
+	}
+}
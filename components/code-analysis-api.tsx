@@ -906,6 +906,11 @@ const CodeAnalysisAPI = React.memo(({
                       <span className="font-medium">
                         {result.executionResult.success ? 'Ejecución exitosa' : 'Error en ejecución'}
                       </span>
+                      {result.executionResult.exitCode !== undefined && (
+                        <span className="text-sm text-muted-foreground">
+                          código {result.executionResult.exitCode} · {result.executionResult.durationMs ?? 0} ms
+                        </span>
+                      )}
                     </div>
                     
                     {result.executionResult.output && (
@@ -159,10 +159,15 @@ export default function CodeEditor({
 function getMonacoLanguage(language: string): string {
   const languageMap: { [key: string]: string } = {
     'javascript': 'javascript',
+    'typescript': 'typescript',
     'python': 'python',
     'cpp': 'cpp',
     'c++': 'cpp',
+    'go': 'go',
+    'kotlin': 'kotlin',
+    'swift': 'swift',
     'html': 'html',
+    'css': 'css',
     'pascal': 'pascal',
     'sql': 'sql',
     'tsql': 'sql',
@@ -43,8 +43,8 @@ export default function EditorLayout() {
     const languageMap: { [key: string]: string } = {
       'js': 'javascript',
       'jsx': 'javascript', 
-      'ts': 'javascript',
-      'tsx': 'javascript',
+      'ts': 'typescript',
+      'tsx': 'typescript',
       'py': 'python',
       'cpp': 'cpp',
       'cxx': 'cpp',
@@ -54,9 +54,13 @@ export default function EditorLayout() {
       'hpp': 'cpp',
       'html': 'html',
       'htm': 'html',
+      'css': 'css',
       'sql': 'tsql',
       'pas': 'pascal',
       'pascal': 'pascal',
+      'kt': 'kotlin',
+      'kts': 'kotlin',
+      'swift': 'swift',
       'txt': language // Mantener el lenguaje actual para archivos .txt
     };
 
@@ -59,13 +59,13 @@ export default function FileUpload({ onCodeLoad }: FileUploadProps) {
     const txtFiles = fileList.filter(file => {
       const extension = file.name.toLowerCase().split('.').pop();
       return extension === 'txt' || extension === 'js' || extension === 'py' || 
-             extension === 'cpp' || extension === 'html' || extension === 'sql' || 
-             extension === 'pas' || extension === 'pascal';
+             extension === 'cpp' || extension === 'go' || extension === 'html' || extension === 'sql' || 
+             extension === 'pas' || extension === 'pascal' || extension === 'kt' || extension === 'swift';
     });
 
     if (txtFiles.length === 0) {
       toast.error("Formato no válido", {
-        description: "Solo se permiten archivos de código (.txt, .js, .py, .cpp, .html, .sql, .pas)",
+        description: "Solo se permiten archivos de código (.txt, .js, .py, .cpp, .go, .html, .sql, .pas, .kt, .swift)",
       });
       return;
     }
@@ -172,7 +172,7 @@ export default function FileUpload({ onCodeLoad }: FileUploadProps) {
                   Arrastra archivos aquí o <span className="text-primary cursor-pointer">selecciona archivos</span>
                 </p>
                 <p className="text-xs text-muted-foreground">
-                  Formatos soportados: .txt, .js, .py, .cpp, .html, .sql, .pas
+                  Formatos soportados: .txt, .js, .py, .cpp, .go, .html, .sql, .pas
                 </p>
               </div>
               
@@ -180,7 +180,7 @@ export default function FileUpload({ onCodeLoad }: FileUploadProps) {
                 ref={fileInputRef}
                 type="file"
                 multiple
-                accept=".txt,.js,.py,.cpp,.html,.sql,.pas,.pascal"
+                accept=".txt,.js,.py,.cpp,.go,.html,.sql,.pas,.pascal,.kt,.swift"
                 onChange={handleFileInput}
                 className="hidden"
               />
@@ -4,25 +4,53 @@ export interface Token {
   value: string;
   line: number;
   column: number;
+  endLine: number;
+  endColumn: number;
   position: number;
+  scope?: string; // Ámbito de TextMate si se pidió tokensFormat: 'textmate'
 }
 
 export interface ParseNode {
-  type: string;
+  type: string; // 'more' en los nodos que resumen los que no entraron
   value: string;
   children: ParseNode[];
   line: number;
   column: number;
+  more?: ParseNodeMore; // Solo en los nodos 'more'
+}
+
+// Los nodos que resume un nodo 'more'; se piden con expandParseTree
+export interface ParseNodeMore {
+  nodes: number;
+  path: string;
+  from: number;
+}
+
+export interface TreeExpandResponse {
+  language: string;
+  path: string;
+  from: number;
+  total: number; // Hijos del nodo path
+  nodes: ParseNode[];
+  truncated?: boolean;
+}
+
+export interface SourcePosition {
+  line: number;
+  column: number;
+  position: number; // desplazamiento en caracteres, como Token.position
 }
 
 export interface Symbol {
   name: string;
   type: string;
-  value: string;
+  value: string; // valor inicial si es una expresión constante ("" si no)
   scope: string;
   line: number;
   column: number;
+  position: number; // declaración del símbolo ("ir a la definición")
   category: string;
+  references: SourcePosition[]; // cada uso del símbolo ("buscar usos")
 }
 
 export interface CompilerError {
@@ -32,6 +60,9 @@ export interface CompilerError {
   column: number;
   position: number;
   severity: 'error' | 'warning' | 'info';
+  code?: string; // Código estable (LEX001, SEM004...) para enlazar a la documentación
+  hint?: string; // Sugerencia legible por máquina (p. ej. "insert:;")
+  relatedPositions?: SourcePosition[]; // Con groupDiagnostics: las demás apariciones del mismo diagnóstico
 }
 
 export interface AnalysisPhase {
@@ -48,10 +79,77 @@ export interface AnalysisPhases {
   semantic: AnalysisPhase;
 }
 
+// output combina todo; compileOutput y runStdout/runStderr separan las fases.
+// exitCode falta si el programa no llegó a terminar por sí mismo
 export interface ExecutionResult {
   success: boolean;
   output: string;
   error?: string;
+  compileOutput?: string;
+  runStdout?: string;
+  runStderr?: string;
+  exitCode?: number;
+  durationMs?: number; // Tiempo real
+  peakMemoryBytes?: number; // Memoria residente máxima, según el kernel
+  userCpuMs?: number;
+  systemCpuMs?: number;
+}
+
+export type CompareMode = 'exact' | 'trimmed' | 'tokens' | 'float';
+export type Verdict = 'AC' | 'WA' | 'TLE' | 'RE' | 'CE';
+
+// Veredicto del modo juez: la salida estándar comparada con expectedOutput
+export interface JudgeResult {
+  verdict: Verdict;
+  compare: CompareMode;
+  message?: string; // Primera diferencia (WA) o código de salida (RE)
+}
+
+export interface TestCase {
+  stdin: string;
+  expectedOutput: string;
+  points?: number; // 1 por defecto
+}
+
+export interface TestCaseResult {
+  verdict: Verdict;
+  message?: string;
+  points: number;
+  earned: number; // points si el caso es AC, 0 si no
+  durationMs: number;
+  exitCode?: number;
+  stdout?: string; // Lo que imprimió el programa con la entrada del caso
+  peakMemoryBytes?: number;
+  userCpuMs?: number;
+  systemCpuMs?: number;
+}
+
+// Veredictos de los casos de prueba; verdict es el del primer caso que no pasó
+export interface TestResults {
+  verdict: Verdict;
+  compare: CompareMode;
+  score: number;
+  maxScore: number;
+  cases: TestCaseResult[];
+}
+
+// Ensamblador de g++ -S (C++) o bytecode de python3 -m dis / node --print-bytecode
+export interface GeneratedCode {
+  kind?: 'assembly' | 'bytecode';
+  tool?: string;
+  success: boolean;
+  output: string;
+  error?: string;
+}
+
+// Milisegundos de cada fase; la suma puede superar totalMs porque algunas
+// corren a la vez, y en una respuesta de la caché las fases son 0
+export interface AnalysisTiming {
+  totalMs: number;
+  lexicalMs: number;
+  syntaxMs: number;
+  semanticMs: number;
+  executionMs: number; // con la compilación
 }
 
 export interface AnalyzeResponse {
@@ -60,15 +158,197 @@ export interface AnalyzeResponse {
   parseTree: ParseNode[];
   symbolTable: Symbol[];
   errors: CompilerError[];
+  parseTreeTruncated?: boolean; // true si parseTree tiene nodos 'more'
   canExecute: boolean;
   analysisPhases: AnalysisPhases;
   executionResult?: ExecutionResult;
-  processingTime: string;
+  processingTime: number; // en milisegundos
+  timing?: AnalysisTiming;
+  cached?: boolean; // true si el servidor respondió desde su caché de resultados
+  generatedCode?: GeneratedCode; // Solo si la petición pidió generatedCode
+  tree?: string; // Árbol en DOT o Mermaid si la petición pidió treeFormat
+  tokensCsv?: string; // Solo con tokensFormat: 'csv'
+  tokensOmitted?: boolean; // true si el código superó omitTokensOver: tokens llega vacío
+  tokenCounts?: Record<string, number>; // Tokens de cada tipo, solo con tokensOmitted
+  tokenTotal?: number; // Tokens de los tipos pedidos, si se pidió una página
+  judge?: JudgeResult; // Solo si la petición envió expectedOutput
+  testResults?: TestResults; // Solo si la petición envió testCases
+}
+
+export type TreeFormat = 'dot' | 'mermaid';
+export type TokensFormat = 'csv' | 'textmate';
+export type TokenTypeName =
+  | 'UNKNOWN' | 'COMMENT' | 'STRING' | 'NUMBER' | 'KEYWORD' | 'IDENTIFIER' | 'FUNCTION'
+  | 'CLASS' | 'VARIABLE' | 'CONSTANT' | 'OPERATOR' | 'DELIMITER' | 'PREPROCESSOR';
+
+// Una página de los tokens de /api/v1/lex o /api/v1/analyze
+export interface TokenPage {
+  tokenTypes?: TokenTypeName[];
+  tokenOffset?: number;
+  tokenLimit?: number;
+}
+
+export interface ProgramFile {
+  name: string; // Sin directorios: datos.txt
+  content: string;
 }
 
 export interface AnalyzeRequest {
   code: string;
   language: string;
+  timeoutSeconds?: number; // Límite de ejecución; el servidor lo acota a su máximo
+  execute?: boolean; // false: solo análisis, sin ejecutar el código
+  args?: string[]; // Argumentos de línea de comandos del programa (sys.argv, process.argv, argv)
+  env?: Record<string, string>; // Variables de entorno permitidas por EXECUTION_ENV_ALLOWLIST
+  files?: ProgramFile[]; // Archivos auxiliares en el directorio de trabajo
+  generatedCode?: boolean; // true: agrega el ensamblador o bytecode del programa
+  treeFormat?: TreeFormat; // Agrega el árbol sintáctico en ese formato
+  treeMaxNodes?: number; // Nodos de parseTree; los demás se resumen en nodos 'more'
+  treeMaxDepth?: number; // Niveles de parseTree
+  tokensFormat?: TokensFormat; // 'csv' agrega tokensCsv; 'textmate' completa token.scope
+  omitTokensOver?: number; // Bytes de código a partir de los cuales solo llega tokenCounts
+  tokenTypes?: TokenTypeName[]; // Solo los tokens de esos tipos
+  tokenOffset?: number; // Tokens (ya filtrados) que se saltan
+  tokenLimit?: number; // Tokens que se devuelven; tokenTotal dice cuántos hay
+  diagnostics?: Record<string, boolean>; // Código o nombre ('SEM002', 'unused-variable') -> activado
+  severityOverrides?: Record<string, 'error' | 'warning'>; // Código, nombre o severidad -> nueva severidad
+  maxErrors?: number; // Diagnósticos antes de detener el análisis (LIM001)
+  groupDiagnostics?: boolean; // Agrupa los diagnósticos idénticos en uno con relatedPositions
+  expectedOutput?: string; // Modo juez: salida estándar esperada del programa
+  compare?: CompareMode; // Cómo se compara con expectedOutput (por defecto 'trimmed')
+  tolerance?: number; // Error admitido con compare: 'float' (por defecto 1e-6)
+  stdin?: string; // Entrada estándar del programa
+  testCases?: TestCase[]; // Se compila una vez y se ejecuta con cada caso
+}
+
+export interface AnalyzeOptions {
+  timeoutSeconds?: number;
+  execute?: boolean;
+  args?: string[];
+  env?: Record<string, string>;
+  files?: ProgramFile[];
+  generatedCode?: boolean;
+  treeFormat?: TreeFormat;
+  treeMaxNodes?: number;
+  treeMaxDepth?: number;
+  tokensFormat?: TokensFormat;
+  omitTokensOver?: number;
+  tokenTypes?: TokenTypeName[];
+  tokenOffset?: number;
+  tokenLimit?: number;
+  diagnostics?: Record<string, boolean>;
+  severityOverrides?: Record<string, 'error' | 'warning'>;
+  maxErrors?: number;
+  groupDiagnostics?: boolean;
+  expectedOutput?: string;
+  compare?: CompareMode;
+  tolerance?: number;
+  stdin?: string;
+  testCases?: TestCase[];
+}
+
+export interface LexResponse {
+  language: string;
+  tokens: Token[];
+  errors: CompilerError[];
+  processingTime: number; // en milisegundos
+  tokensCsv?: string; // Solo con tokensFormat: 'csv'
+  tokensOmitted?: boolean;
+  tokenCounts?: Record<string, number>;
+  tokenTotal?: number;
+}
+
+export interface SessionEdit {
+  start: number; // posiciones en caracteres, como Token.position
+  end: number;
+  text: string;
+}
+
+export interface SessionResponse extends AnalyzeResponse {
+  sessionId: string;
+}
+
+export interface HistoryEntry {
+  id: number;
+  codeHash: string; // SHA-256 del código; el servidor no guarda el código
+  language: string;
+  lexicalErrors: number;
+  syntaxErrors: number;
+  semanticErrors: number;
+  warnings: number;
+  canExecute: boolean;
+  executionOk?: boolean; // ausente si no se ejecutó
+  durationMs: number;
+  cached: boolean;
+  timestamp: string;
+}
+
+export interface HistoryFilter {
+  language?: string;
+  hash?: string;
+  since?: string; // RFC 3339 o AAAA-MM-DD
+  until?: string;
+  errors?: boolean;
+  limit?: number;
+  offset?: number;
+}
+
+export interface HistoryResponse {
+  entries: HistoryEntry[];
+  total: number;
+}
+
+// Trabajo de análisis asíncrono (POST /api/v1/analyze/async, GET /api/v1/jobs/{id})
+export interface AnalysisJob {
+  id: string;
+  requestId: string;
+  status: 'queued' | 'running' | 'done';
+  position?: number;
+  createdAt: string;
+  startedAt?: string;
+  finishedAt?: string;
+  result?: AnalyzeResponse;
+  error?: APIError; // si el análisis falló en el servidor
+}
+
+// Ejecución en curso (GET /api/v1/executions)
+export interface RunningExecution {
+  id: string;
+  language: string;
+  startedAt: string;
+  elapsedMs: number;
+}
+
+export interface ExecutionsResponse {
+  executions: RunningExecution[];
+}
+
+// Cuerpo de todas las respuestas de error del servidor
+export interface APIError {
+  code: string; // estable: invalid_json, rate_limited, not_found...
+  message: string;
+  details?: Record<string, unknown>;
+  requestId?: string; // X-Request-ID, para buscar la petición en el registro
+}
+
+// Error de una respuesta del servidor con su APIError
+export class APIRequestError extends Error {
+  constructor(public status: number, public apiError: APIError) {
+    super(`Error del servidor (${status}): ${apiError.message}`);
+    this.name = 'APIRequestError';
+  }
+}
+
+// serverError lee el APIError de una respuesta fallida; si no llegó JSON
+// (un proxy intermedio), usa el estado HTTP
+async function serverError(response: Response): Promise<APIRequestError> {
+  let apiError: APIError = { code: 'error', message: response.statusText };
+  try {
+    apiError = await response.json();
+  } catch {
+    // Sin cuerpo JSON
+  }
+  return new APIRequestError(response.status, apiError);
 }
 
 // Configuración de la API
@@ -82,9 +362,17 @@ function mapLanguageToBackend(frontendLanguage: string): string {
     'cpp': 'cpp',
     'javascript': 'javascript',
     'js': 'javascript',
+    'typescript': 'typescript',
+    'ts': 'typescript',
     'python': 'python',
     'py': 'python',
+    'go': 'go',
+    'golang': 'go',
+    'kotlin': 'kotlin',
+    'kt': 'kotlin',
+    'swift': 'swift',
     'html': 'html',
+    'css': 'css',
     'pascal': 'pascal',
     'PL/SQL': 'plsql',
     'plsql': 'plsql',
@@ -105,26 +393,29 @@ export class APICompilerAnalyzer {
     this.baseUrl = baseUrl || API_BASE_URL;
   }
 
-  async analyzeCode(code: string, language: string = 'auto'): Promise<AnalyzeResponse> {
+  // requestId (X-Request-ID) permite detener la ejecución con stopExecution
+  async analyzeCode(code: string, language: string = 'auto', options: AnalyzeOptions = {}, requestId?: string): Promise<AnalyzeResponse> {
     try {
       // Mapear el lenguaje del frontend al formato del backend
       const backendLanguage = mapLanguageToBackend(language);
       
       const request: AnalyzeRequest = {
         code: code, // No usar trim() para preservar indentación
-        language: backendLanguage
+        language: backendLanguage,
+        ...options
       };
 
       const response = await fetch(`${this.baseUrl}/api/v1/analyze`, {
         method: 'POST',
         headers: {
           'Content-Type': 'application/json',
+          ...(requestId ? { 'X-Request-ID': requestId } : {}),
         },
         body: JSON.stringify(request),
       });
 
       if (!response.ok) {
-        throw new Error(`Error del servidor: ${response.status} ${response.statusText}`);
+        throw await serverError(response);
       }
 
       const result: AnalyzeResponse = await response.json();
@@ -153,11 +444,165 @@ export class APICompilerAnalyzer {
           syntax: { completed: false, errorsFound: 0 },
           semantic: { completed: false, errorsFound: 1 }
         },
-        processingTime: '0ms'
+        processingTime: 0
       };
     }
   }
 
+  // Camino rápido: solo tokens y errores léxicos (resaltado de sintaxis);
+  // con page, solo esa página de los tokens de un archivo grande
+  async lexCode(code: string, language: string = 'auto', page: TokenPage = {}): Promise<LexResponse> {
+    const request: AnalyzeRequest = { code, language: mapLanguageToBackend(language), ...page };
+    const response = await fetch(`${this.baseUrl}/api/v1/lex`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify(request),
+    });
+
+    if (!response.ok) {
+      throw await serverError(response);
+    }
+    return response.json();
+  }
+
+  // Código con cada token en un <span class="token-TIPO">, según el lexer del servidor
+  async highlightCode(code: string, language: string = 'auto'): Promise<string> {
+    const request: AnalyzeRequest = { code, language: mapLanguageToBackend(language) };
+    const response = await fetch(`${this.baseUrl}/api/v1/highlight?format=html`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify(request),
+    });
+
+    if (!response.ok) {
+      throw await serverError(response);
+    }
+    return response.text();
+  }
+
+  // Árbol sintáctico como texto de Graphviz o Mermaid, listo para dibujar
+  async getTreeDiagram(code: string, language: string = 'auto', format: TreeFormat = 'mermaid'): Promise<string> {
+    const request: AnalyzeRequest = { code, language: mapLanguageToBackend(language) };
+    const response = await fetch(`${this.baseUrl}/api/v1/analyze/tree?format=${format}`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify(request),
+    });
+
+    if (!response.ok) {
+      throw await serverError(response);
+    }
+    return response.text();
+  }
+
+  // Hijos de un nodo 'more' del parseTree, con el mismo código que se analizó
+  async expandParseTree(code: string, language: string, more: ParseNodeMore, limits: { treeMaxNodes?: number; treeMaxDepth?: number } = {}): Promise<TreeExpandResponse> {
+    const response = await fetch(`${this.baseUrl}/api/v1/analyze/tree/expand`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify({ code, language: mapLanguageToBackend(language), path: more.path, from: more.from, ...limits }),
+    });
+
+    if (!response.ok) {
+      throw await serverError(response);
+    }
+    return response.json();
+  }
+
+  // Sesiones: el servidor reanaliza solo la región modificada del documento
+  async createSession(code: string, language: string = 'auto', options: AnalyzeOptions = {}): Promise<SessionResponse> {
+    const request: AnalyzeRequest = { code, language: mapLanguageToBackend(language), ...options };
+    return this.sessionRequest('POST', '/api/v1/sessions', request);
+  }
+
+  async updateSession(sessionId: string, change: { code?: string; edits?: SessionEdit[] }, options: AnalyzeOptions = {}): Promise<SessionResponse> {
+    return this.sessionRequest('PATCH', `/api/v1/sessions/${sessionId}/code`, { ...change, ...options });
+  }
+
+  async deleteSession(sessionId: string): Promise<void> {
+    await fetch(`${this.baseUrl}/api/v1/sessions/${sessionId}`, { method: 'DELETE' });
+  }
+
+  private async sessionRequest(method: string, path: string, body: object): Promise<SessionResponse> {
+    const response = await fetch(`${this.baseUrl}${path}`, {
+      method,
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify(body),
+    });
+
+    if (!response.ok) {
+      throw await serverError(response);
+    }
+    return response.json();
+  }
+
+  // Historial de análisis para el panel de la clase
+  async getHistory(filter: HistoryFilter = {}): Promise<HistoryResponse> {
+    const params = new URLSearchParams();
+    for (const [key, value] of Object.entries(filter)) {
+      if (value !== undefined && value !== '') {
+        params.set(key, key === 'language' ? mapLanguageToBackend(String(value)) : String(value));
+      }
+    }
+    const response = await fetch(`${this.baseUrl}/api/v1/history?${params}`);
+
+    if (!response.ok) {
+      throw await serverError(response);
+    }
+    return response.json();
+  }
+
+  // Análisis asíncrono: deja el código en la cola y devuelve el trabajo
+  async analyzeCodeAsync(code: string, language: string = 'auto', options: AnalyzeOptions = {}): Promise<AnalysisJob> {
+    const response = await fetch(`${this.baseUrl}/api/v1/analyze/async`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify({ code, language: mapLanguageToBackend(language), ...options }),
+    });
+
+    if (!response.ok) {
+      throw await serverError(response);
+    }
+    return response.json();
+  }
+
+  async getJob(jobId: string): Promise<AnalysisJob> {
+    const response = await fetch(`${this.baseUrl}/api/v1/jobs/${jobId}`);
+
+    if (!response.ok) {
+      throw await serverError(response);
+    }
+    return response.json();
+  }
+
+  // Ejecuciones en curso; el id es el encabezado X-Request-ID de la petición
+  async listExecutions(): Promise<ExecutionsResponse> {
+    const response = await fetch(`${this.baseUrl}/api/v1/executions`);
+
+    if (!response.ok) {
+      throw await serverError(response);
+    }
+    return response.json();
+  }
+
+  // Detiene una ejecución; false si ya había terminado
+  async stopExecution(requestId: string): Promise<boolean> {
+    const response = await fetch(`${this.baseUrl}/api/v1/executions/${encodeURIComponent(requestId)}`, { method: 'DELETE' });
+    return response.ok;
+  }
+
   async checkHealth(): Promise<{ status: string; service: string }> {
     try {
       const response = await fetch(`${this.baseUrl}/api/v1/health`);
@@ -1,8 +1,13 @@
 export const languages = [
     { value: 'javascript', label: 'JavaScript' },
+    { value: 'typescript', label: 'TypeScript' },
     { value: 'html', label: 'HTML' },
+    { value: 'css', label: 'CSS' },
     { value: 'python', label: 'Python' },
     { value: 'cpp', label: 'C++' },
+    { value: 'go', label: 'Go' },
+    { value: 'kotlin', label: 'Kotlin' },
+    { value: 'swift', label: 'Swift' },
     { value: 'pascal', label: 'Pascal' },
     { value: 'plsql', label: 'PL/SQL' },
     { value: 'tsql', label: 'T-SQL' },
@@ -23,7 +23,7 @@ const LANGUAGE_SIGNATURES: { [key: string]: LanguageSignature } = {
       /window\./,
       /\$\(/  // jQuery
     ],
-    fileExtensions: ['js', 'jsx', 'ts', 'tsx'],
+    fileExtensions: ['js', 'jsx'],
     weight: 1,
     exclusivePatterns: [
       /console\.(log|error|warn|info)\s*\(/,
@@ -32,6 +32,24 @@ const LANGUAGE_SIGNATURES: { [key: string]: LanguageSignature } = {
     ]
   },
   
+  typescript: {
+    keywords: ['interface', 'type', 'enum', 'implements', 'readonly', 'namespace', 'keyof', 'as', 'private', 'public'],
+    patterns: [
+      /:\s*(number|string|boolean|void|any|unknown)\b/,
+      /interface\s+\w+\s*(<[^>]*>)?\s*(extends\s+[\w, ]+)?\s*{/,
+      /type\s+\w+\s*(<[^>]*>)?\s*=/,
+      /enum\s+\w+\s*{/,
+      /\w+<[\w\[\], ]+>\s*\(/
+    ],
+    fileExtensions: ['ts', 'tsx', 'mts', 'cts'],
+    weight: 1.2,
+    exclusivePatterns: [
+      /interface\s+\w+\s*{/,
+      /\)\s*:\s*(number|string|boolean|void)\s*(=>|{)/,
+      /(let|const)\s+\w+\s*:\s*\w+/
+    ]
+  },
+
   python: {
     keywords: ['def', 'import', 'from', 'print', 'if', 'elif', 'else', 'for', 'while', 'class', 'self', 'pass', 'lambda'],
     patterns: [
@@ -74,6 +92,63 @@ const LANGUAGE_SIGNATURES: { [key: string]: LanguageSignature } = {
     ]
   },
   
+  go: {
+    keywords: ['package', 'func', 'import', 'fmt', 'var', 'struct', 'interface', 'chan', 'defer', 'range'],
+    patterns: [
+      /^\s*package\s+\w+/m,
+      /func\s+(\([^)]*\)\s*)?\w+\s*\(/,
+      /\w+\s*:=/,
+      /fmt\.\w+\s*\(/,
+      /type\s+\w+\s+(struct|interface)\s*{/,
+      /for\s+.*range\s+/
+    ],
+    fileExtensions: ['go'],
+    weight: 1,
+    exclusivePatterns: [
+      /^\s*package\s+main\b/m,
+      /func\s+main\s*\(\s*\)\s*{/,
+      /fmt\.Print(ln|f)?\s*\(/
+    ]
+  },
+
+  kotlin: {
+    keywords: ['fun', 'val', 'var', 'println', 'when', 'data', 'object', 'companion', 'override', 'listOf'],
+    patterns: [
+      /fun\s+(<[^>]*>\s*)?[\w.]+\s*\(/,
+      /^\s*val\s+\w+\s*[:=]/m,
+      /\bwhen\s*(\([^)]*\))?\s*{/,
+      /\w+\s*->\s*/,
+      /"[^"\n]*\$\{?\w+/,
+      /:\s*(Int|String|Double|Boolean|Unit)\b/
+    ],
+    fileExtensions: ['kt', 'kts'],
+    weight: 1,
+    exclusivePatterns: [
+      /^\s*fun\s+main\s*\(/m,
+      /^\s*(data|sealed)\s+class\s+\w+/m,
+      /(^|[^.\w])println\s*\(/m
+    ]
+  },
+
+  swift: {
+    keywords: ['func', 'let', 'var', 'guard', 'struct', 'protocol', 'extension', 'import', 'print', 'nil'],
+    patterns: [
+      /func\s+\w+\s*(<[^>]*>)?\([^)]*\)/,
+      /^\s*let\s+\w+\s*[:=]/m,
+      /\)\s*->\s*\w+/,
+      /"[^"\n]*\\\(/,
+      /\?\?/,
+      /:\s*(Int|String|Double|Bool)\b/
+    ],
+    fileExtensions: ['swift'],
+    weight: 1,
+    exclusivePatterns: [
+      /^\s*import\s+(Foundation|UIKit|SwiftUI)\b/m,
+      /\b(guard|if)\s+let\s+\w+/,
+      /func\s+\w+\s*\([^)]*\)\s*->/
+    ]
+  },
+  
   html: {
     keywords: ['<html>', '<head>', '<body>', '<div>', '<p>', '<a>', '<img>', '<!DOCTYPE', '<script>', '<style>'],
     patterns: [
@@ -94,6 +169,24 @@ const LANGUAGE_SIGNATURES: { [key: string]: LanguageSignature } = {
       /<\/html>/i
     ]
   },
+
+  css: {
+    keywords: ['@media', '@import', '@keyframes', '!important', 'color', 'margin', 'padding', 'display'],
+    patterns: [
+      /^\s*[.#]?[\w-]+[^{};=]*\{/m,
+      /^\s*[\w-]+\s*:\s*[^;{}]+;/m,
+      /@media\s*\(/,
+      /\d+(px|em|rem|vh|vw)\b/,
+      /#[0-9a-fA-F]{3,6}\b/
+    ],
+    fileExtensions: ['css'],
+    weight: 1,
+    exclusivePatterns: [
+      /@(media|keyframes|font-face)\b/,
+      /^\s*--[\w-]+\s*:/m,
+      /:\s*var\(--[\w-]+\)/
+    ]
+  },
   
   pascal: {
     keywords: ['program', 'begin', 'end', 'var', 'const', 'procedure', 'function', 'if', 'then', 'else', 'while', 'do'],
@@ -41,4 +41,4 @@ $env:PORT=8080
 
 # Ejecutar el servidor
 Write-Host "🌟 Iniciando servidor en puerto $env:PORT..."
-go run main.go compiler.go
\ No newline at end of file
+go run .
\ No newline at end of file
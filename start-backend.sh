@@ -44,4 +44,4 @@ export PORT=8080
 
 # Ejecutar el servidor
 echo "🌟 Iniciando servidor en puerto $PORT..."
-go run main.go compiler.go 
\ No newline at end of file
+go run . 
\ No newline at end of file